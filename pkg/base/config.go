@@ -147,6 +147,11 @@ type Config struct {
 	// SSLCertsDir is the path to the certificate/key directory.
 	SSLCertsDir string
 
+	// TLSOptions restricts the TLS versions and cipher suites of the
+	// connections to and from the RPC and SQL servers. It does not apply to
+	// the Admin UI.
+	TLSOptions security.TLSOptions
+
 	// User running this process. It could be the user under which
 	// the server is running or the user passed in client calls.
 	User string
@@ -208,6 +213,7 @@ func (cfg *Config) InitDefaults() {
 	cfg.AdvertiseAddr = cfg.Addr
 	cfg.HTTPAddr = defaultHTTPAddr
 	cfg.SSLCertsDir = DefaultCertsDirectory
+	cfg.TLSOptions = security.TLSOptions{}
 	cfg.certificateManager = lazyCertificateManager{}
 	cfg.HeartbeatInterval = defaultHeartbeatInterval
 }
@@ -364,7 +370,7 @@ func (cfg *Config) GetClientTLSConfig() (*tls.Config, error) {
 	if err != nil {
 		return nil, wrapError(err)
 	}
-	return tlsCfg, nil
+	return cfg.TLSOptions.Restrict(tlsCfg), nil
 }
 
 // GetUIClientTLSConfig returns the client TLS config for Admin UI clients, initializing it if needed.
@@ -407,7 +413,7 @@ func (cfg *Config) GetServerTLSConfig() (*tls.Config, error) {
 	if err != nil {
 		return nil, wrapError(err)
 	}
-	return tlsCfg, nil
+	return cfg.TLSOptions.Restrict(tlsCfg), nil
 }
 
 // GetUIServerTLSConfig returns the server TLS config for the Admin UI, initializing it if needed.
@@ -55,6 +55,9 @@ const (
 	csvNullIf    = "nullif"
 	csvSkip      = "skip"
 
+	csvMaxRejectedRows = "max_rejected_rows"
+	csvRejectedRowsDir = "rejected_rows_dir"
+
 	mysqlOutfileRowSep   = "rows_terminated_by"
 	mysqlOutfileFieldSep = "fields_terminated_by"
 	mysqlOutfileEnclose  = "fields_enclosed_by"
@@ -80,6 +83,9 @@ var importOptionExpectValues = map[string]sql.KVStringOptValidate{
 	csvNullIf:    sql.KVStringOptRequireValue,
 	csvSkip:      sql.KVStringOptRequireValue,
 
+	csvMaxRejectedRows: sql.KVStringOptRequireValue,
+	csvRejectedRowsDir: sql.KVStringOptRequireValue,
+
 	mysqlOutfileRowSep:   sql.KVStringOptRequireValue,
 	mysqlOutfileFieldSep: sql.KVStringOptRequireValue,
 	mysqlOutfileEnclose:  sql.KVStringOptRequireValue,
@@ -181,6 +187,9 @@ func MakeSimpleTableDescriptor(
 			if def.Computed.Expr != nil {
 				return nil, pgerror.Unimplemented("import.computed", "computed columns not supported: %s", tree.AsString(def))
 			}
+			if def.IsIdentity() {
+				return nil, pgerror.Unimplemented("import.identity", "identity columns not supported: %s", tree.AsString(def))
+			}
 
 			if err := sql.SimplifySerialInColumnDefWithRowID(ctx, def, &create.Table); err != nil {
 				return nil, err
@@ -416,7 +425,7 @@ func importJobDescription(
 	stmt.Options = nil
 	for k, v := range opts {
 		switch k {
-		case importOptionTransform:
+		case importOptionTransform, csvRejectedRowsDir:
 			clean, err := storageccl.SanitizeExportStorageURI(v)
 			if err != nil {
 				return "", err
@@ -562,6 +571,25 @@ func importPlanHook(
 				}
 				format.Csv.Skip = uint32(skip)
 			}
+
+			if override, ok := opts[csvMaxRejectedRows]; ok {
+				max, err := strconv.ParseInt(override, 10, 64)
+				if err != nil {
+					return pgerror.Wrapf(err, pgerror.CodeSyntaxError, "invalid %s value", csvMaxRejectedRows)
+				}
+				if max < 0 {
+					return pgerror.NewErrorf(pgerror.CodeSyntaxError, "%s must be >= 0", csvMaxRejectedRows)
+				}
+				format.Csv.MaxRejectedRows = max
+			}
+
+			if override, ok := opts[csvRejectedRowsDir]; ok {
+				if format.Csv.MaxRejectedRows == 0 {
+					return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+						"%s requires a positive %s", csvRejectedRowsDir, csvMaxRejectedRows)
+				}
+				format.Csv.RejectedRowsDir = override
+			}
 		case "MYSQLOUTFILE":
 			telemetry.Count("import.format.mysqlout")
 			format.Format = roachpb.IOFileFormat_MysqlOutfile
@@ -654,6 +682,15 @@ func importPlanHook(
 			return pgerror.Unimplemented("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
 
+		if format.Format != roachpb.IOFileFormat_CSV {
+			for _, opt := range []string{csvMaxRejectedRows, csvRejectedRowsDir} {
+				if _, ok := opts[opt]; ok {
+					return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+						"%s is only supported for the CSV format", opt)
+				}
+			}
+		}
+
 		if format.Format != roachpb.IOFileFormat_CSV {
 			if !p.ExecCfg().Settings.Version.IsActive(cluster.VersionImportFormats) {
 				return errors.Errorf("Using %s requires all nodes to be upgraded to %s",
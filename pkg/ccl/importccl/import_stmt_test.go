@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/csv"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -20,6 +21,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"runtime"
 	"strings"
 	"sync"
@@ -616,7 +618,45 @@ COPY t (a, b, c) FROM stdin;
 			}(),
 		},
 
+		{
+			name:   "malformed rows",
+			create: `a int8 primary key, b int8`,
+			with:   `WITH max_rejected_rows = '2'`,
+			typ:    "CSV",
+			data: `1,1
+2,x
+3
+4,4`,
+			query: map[string][][]string{
+				`SELECT * FROM t`: {{"1", "1"}, {"4", "4"}},
+			},
+		},
+
 		// Error
+		{
+			name:   "too many malformed rows",
+			create: `a int8 primary key, b int8`,
+			with:   `WITH max_rejected_rows = '1'`,
+			typ:    "CSV",
+			data: `1,1
+2,x
+3
+4,4`,
+			err: `more than 1 rows rejected`,
+		},
+		{
+			name:   "rejected rows dir without max",
+			create: `a int8 primary key`,
+			with:   `WITH rejected_rows_dir = 'nodelocal:///rejected'`,
+			typ:    "CSV",
+			err:    `rejected_rows_dir requires a positive max_rejected_rows`,
+		},
+		{
+			name: "max rejected rows not csv",
+			typ:  "PGDUMP",
+			with: `WITH max_rejected_rows = '1'`,
+			err:  `max_rejected_rows is only supported for the CSV format`,
+		},
 		{
 			name:   "unsupported import format",
 			create: `b bytes`,
@@ -1475,7 +1515,11 @@ func BenchmarkConvertRecord(b *testing.B) {
 		}
 	}()
 
-	c := &csvInputReader{recordCh: recordCh, tableDesc: tableDesc.TableDesc()}
+	c := &csvInputReader{
+		recordCh:  recordCh,
+		tableDesc: tableDesc.TableDesc(),
+		rejected:  newCSVRejectedRows(roachpb.CSVOptions{}),
+	}
 	// start up workers.
 	for i := 0; i < runtime.NumCPU(); i++ {
 		group.Go(func() error {
@@ -1974,6 +2018,57 @@ func TestImportMVCCChecksums(t *testing.T) {
 	sqlDB.Exec(t, `UPDATE d.t SET c = 2 WHERE a = 1`)
 }
 
+// TestImportCSVRejectedRows verifies that the rows skipped in an input file
+// are written to the rejected_rows_dir.
+func TestImportCSVRejectedRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	data := "1,a\n2\n3,c\nx,d\n5,e,f\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "data.csv"), []byte(data), 0666); err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Exec(t, `CREATE DATABASE d`)
+	sqlDB.Exec(t, `IMPORT TABLE d.t (a INT8 PRIMARY KEY, b STRING) CSV DATA ('nodelocal:///data.csv')
+		WITH max_rejected_rows = '3', rejected_rows_dir = 'nodelocal:///rejected'`)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM d.t`, [][]string{{"1", "a"}, {"3", "c"}})
+
+	f, err := os.Open(filepath.Join(dir, "rejected", "data.csv.0.rejected"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		row    string
+		err    string
+		fields []string
+	}{
+		{"2", `row 2: expected 2 fields, got 1`, []string{"2"}},
+		{"4", `row 4: parse "a" as INT8`, []string{"x", "d"}},
+		{"5", `row 5: expected 2 fields, got 3`, []string{"5", "e", "f"}},
+	}
+	if len(records) != len(expected) {
+		t.Fatalf("expected %d rejected rows, got %v", len(expected), records)
+	}
+	for i, e := range expected {
+		r := records[i]
+		if r[0] != e.row || !strings.Contains(r[1], e.err) || !reflect.DeepEqual(r[2:], e.fields) {
+			t.Errorf("%d: expected row %s with %q and %v, got %v", i, e.row, e.err, e.fields, r)
+		}
+	}
+}
+
 func TestImportMysql(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -9,10 +9,17 @@
 package importccl
 
 import (
+	"bytes"
 	"context"
+	"fmt"
 	"io"
+	"net/url"
+	"path"
 	"runtime"
+	"sort"
+	"strconv"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
@@ -21,6 +28,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/pkg/errors"
 )
@@ -34,6 +43,7 @@ type csvInputReader struct {
 	opts         roachpb.CSVOptions
 	tableDesc    *sqlbase.TableDescriptor
 	expectedCols int
+	rejected     *csvRejectedRows
 }
 
 var _ inputConverter = &csvInputReader{}
@@ -52,6 +62,7 @@ func newCSVInputReader(
 		tableDesc:    tableDesc,
 		recordCh:     make(chan csvRecord),
 		batchSize:    500,
+		rejected:     newCSVRejectedRows(opts),
 	}
 }
 
@@ -61,9 +72,12 @@ func (c *csvInputReader) start(group ctxgroup.Group) {
 		defer tracing.FinishSpan(span)
 
 		defer close(c.kvCh)
-		return ctxgroup.GroupWorkers(ctx, runtime.NumCPU(), func(ctx context.Context) error {
+		if err := ctxgroup.GroupWorkers(ctx, runtime.NumCPU(), func(ctx context.Context) error {
 			return c.convertRecordWorker(ctx)
-		})
+		}); err != nil {
+			return err
+		}
+		return c.rejected.save(ctx, c.flowCtx.Settings)
 	})
 }
 
@@ -142,7 +156,11 @@ func (c *csvInputReader) readFile(
 			// Line has the optional trailing comma, ignore the empty field.
 			record = record[:c.expectedCols]
 		} else {
-			return errors.Errorf("row %d: expected %d fields, got %d", i, c.expectedCols, len(record))
+			err := errors.Errorf("row %d: expected %d fields, got %d", i, c.expectedCols, len(record))
+			if err := c.rejected.add(inputIdx, inputName, int64(i), record, err); err != nil {
+				return err
+			}
+			continue
 		}
 		c.batch.r = append(c.batch.r, record)
 	}
@@ -172,18 +190,11 @@ func (c *csvInputReader) convertRecordWorker(ctx context.Context) error {
 	for batch := range c.recordCh {
 		for batchIdx, record := range batch.r {
 			rowNum := int64(batch.rowOffset + batchIdx)
-			for i, v := range record {
-				col := conv.visibleCols[i]
-				if c.opts.NullEncoding != nil && v == *c.opts.NullEncoding {
-					conv.datums[i] = tree.DNull
-				} else {
-					var err error
-					conv.datums[i], err = tree.ParseDatumStringAs(conv.visibleColTypes[i], v, conv.evalCtx)
-					if err != nil {
-						return wrapRowErr(err, batch.file, rowNum, pgerror.CodeSyntaxError,
-							"parse %q as %s", col.Name, col.Type.SQLString())
-					}
+			if err := c.parseRecord(conv, record, batch.file, rowNum); err != nil {
+				if err := c.rejected.add(batch.fileIndex, batch.file, rowNum, record, err); err != nil {
+					return err
 				}
+				continue
 			}
 			if err := conv.row(ctx, batch.fileIndex, rowNum); err != nil {
 				return wrapRowErr(err, batch.file, rowNum, pgerror.CodeDataExceptionError, "")
@@ -192,3 +203,137 @@ func (c *csvInputReader) convertRecordWorker(ctx context.Context) error {
 	}
 	return conv.sendBatch(ctx)
 }
+
+// parseRecord parses the fields of a CSV record into the datums of the row
+// converter.
+func (c *csvInputReader) parseRecord(
+	conv *rowConverter, record []string, file string, rowNum int64,
+) error {
+	for i, v := range record {
+		col := conv.visibleCols[i]
+		if c.opts.NullEncoding != nil && v == *c.opts.NullEncoding {
+			conv.datums[i] = tree.DNull
+		} else {
+			var err error
+			conv.datums[i], err = tree.ParseDatumStringAs(conv.visibleColTypes[i], v, conv.evalCtx)
+			if err != nil {
+				return wrapRowErr(err, file, rowNum, pgerror.CodeSyntaxError,
+					"parse %q as %s", col.Name, col.Type.SQLString())
+			}
+		}
+	}
+	return nil
+}
+
+// csvRejectedRows keeps track of the malformed rows of the input files, which
+// have the wrong number of fields or a field which cannot be parsed. Up to
+// MaxRejectedRows of them are skipped in each file, rather than failing the
+// import. If RejectedRowsDir is set, the rows skipped in each file are written
+// to a file of that directory, once all the files have been converted.
+type csvRejectedRows struct {
+	opts roachpb.CSVOptions
+	mu   struct {
+		syncutil.Mutex
+		files map[int32]*csvRejectedFile
+	}
+}
+
+// csvRejectedFile holds the rows skipped in an input file. rows is only
+// populated if the rows are to be saved.
+type csvRejectedFile struct {
+	name  string
+	count int64
+	rows  []csvRejectedRow
+}
+
+type csvRejectedRow struct {
+	rowNum int64
+	err    string
+	record []string
+}
+
+func newCSVRejectedRows(opts roachpb.CSVOptions) *csvRejectedRows {
+	r := &csvRejectedRows{opts: opts}
+	r.mu.files = make(map[int32]*csvRejectedFile)
+	return r
+}
+
+// add skips a malformed row of an input file. rowErr, the reason the row was
+// rejected, is returned if the file already has MaxRejectedRows skipped rows.
+func (r *csvRejectedRows) add(
+	fileIndex int32, file string, rowNum int64, record []string, rowErr error,
+) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.mu.files[fileIndex]
+	if f == nil {
+		f = &csvRejectedFile{name: file}
+		r.mu.files[fileIndex] = f
+	}
+	if f.count >= r.opts.MaxRejectedRows {
+		if r.opts.MaxRejectedRows == 0 {
+			return rowErr
+		}
+		return pgerror.Wrapf(rowErr, pgerror.CodeDataExceptionError,
+			"more than %d rows rejected", r.opts.MaxRejectedRows)
+	}
+	f.count++
+	if r.opts.RejectedRowsDir != "" {
+		f.rows = append(f.rows, csvRejectedRow{rowNum: rowNum, err: rowErr.Error(), record: record})
+	}
+	return nil
+}
+
+// rejectedFileName returns the name of the file to which the rows skipped in
+// an input file are written.
+func rejectedFileName(fileIndex int32, file string) string {
+	base := "input"
+	if uri, err := url.Parse(file); err == nil {
+		if b := path.Base(uri.Path); b != "." && b != "/" {
+			base = b
+		}
+	}
+	return fmt.Sprintf("%s.%d.rejected", base, fileIndex)
+}
+
+// save writes the rows skipped in each input file, ordered by row number, to a
+// file of RejectedRowsDir. Each line of the file is a CSV record made of the
+// row number, the reason the row was rejected and the fields of the row.
+func (r *csvRejectedRows) save(ctx context.Context, settings *cluster.Settings) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for fileIndex, f := range r.mu.files {
+		if f.count > 0 {
+			// Don't log the file name here because it could leak auth information.
+			log.Infof(ctx, "skipped %d malformed rows in input file %d", f.count, fileIndex)
+		}
+	}
+	if r.opts.RejectedRowsDir == "" || len(r.mu.files) == 0 {
+		return nil
+	}
+	es, err := storageccl.ExportStorageFromURI(ctx, r.opts.RejectedRowsDir, settings)
+	if err != nil {
+		return err
+	}
+	defer es.Close()
+	for fileIndex, f := range r.mu.files {
+		sort.Slice(f.rows, func(i, j int) bool { return f.rows[i].rowNum < f.rows[j].rowNum })
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		for _, row := range f.rows {
+			record := append([]string{strconv.FormatInt(row.rowNum, 10), row.err}, row.record...)
+			if err := w.Write(record); err != nil {
+				return err
+			}
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+		name := rejectedFileName(fileIndex, f.name)
+		if err := es.WriteFile(ctx, name, bytes.NewReader(buf.Bytes())); err != nil {
+			return errors.Wrapf(err, "writing rejected rows to %s", name)
+		}
+	}
+	return nil
+}
@@ -183,6 +183,11 @@ func grantRolePlanHook(
 		}
 	}
 
+	sql.LogRoleChange(ctx, p.ExecCfg(), sql.AuthLogGrantRole, p.User(), struct {
+		Roles       []string
+		Members     []string
+		AdminOption bool
+	}{grant.Roles.ToStrings(), grant.Members.ToStrings(), grant.AdminOption})
 	return sql.NewZeroNode(nil /* columns */), nil
 }
 
@@ -274,6 +279,11 @@ func revokeRolePlanHook(
 		}
 	}
 
+	sql.LogRoleChange(ctx, p.ExecCfg(), sql.AuthLogRevokeRole, p.User(), struct {
+		Roles       []string
+		Members     []string
+		AdminOption bool
+	}{revoke.Roles.ToStrings(), revoke.Members.ToStrings(), revoke.AdminOption})
 	return sql.NewZeroNode(nil /* columns */), nil
 }
 
@@ -162,6 +162,7 @@ func init() {
 
 	cockroachCmd.AddCommand(
 		StartCmd,
+		StartSingleNodeCmd,
 		initCmd,
 		certCmd,
 		quitCmd,
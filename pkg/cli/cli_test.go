@@ -2314,6 +2314,7 @@ SELECT concat('crdb_internal.', table_name) as name FROM [ SHOW TABLES FROM crdb
 'ranges',
 'ranges_no_leases',
 'predefined_comments',
+'privileges',
 'session_trace',
 'session_variables',
 'tables'
@@ -2361,14 +2362,20 @@ writing ` + os.DevNull + `
   debug/liveness.json
   debug/settings.json
   debug/reports/problemranges.json
+  debug/crdb_internal.cluster_inflight_trace_spans.txt
+  debug/crdb_internal.cluster_locks.txt
+  debug/crdb_internal.cluster_network_latencies.txt
   debug/crdb_internal.cluster_queries.txt
   debug/crdb_internal.cluster_sessions.txt
   debug/crdb_internal.cluster_settings.txt
+  debug/crdb_internal.constraint_violations.txt
+  debug/crdb_internal.deadlocks.txt
   debug/crdb_internal.jobs.txt
   debug/crdb_internal.kv_node_status.txt
   debug/crdb_internal.kv_store_status.txt
   debug/crdb_internal.schema_changes.txt
   debug/crdb_internal.partitions.txt
+  debug/crdb_internal.super_region_violations.txt
   debug/crdb_internal.zones.txt
   debug/nodes/1/status.json
   debug/nodes/1/crdb_internal.feature_usage.txt
@@ -2377,12 +2384,18 @@ writing ` + os.DevNull + `
   debug/nodes/1/crdb_internal.gossip_network.txt
   debug/nodes/1/crdb_internal.gossip_nodes.txt
   debug/nodes/1/crdb_internal.leases.txt
+  debug/nodes/1/crdb_internal.node_statement_plans.txt
   debug/nodes/1/crdb_internal.node_statement_statistics.txt
   debug/nodes/1/crdb_internal.node_build_info.txt
+  debug/nodes/1/crdb_internal.node_inflight_trace_spans.txt
+  debug/nodes/1/crdb_internal.node_memory_monitors.txt
   debug/nodes/1/crdb_internal.node_metrics.txt
+  debug/nodes/1/crdb_internal.node_network_latencies.txt
   debug/nodes/1/crdb_internal.node_queries.txt
+  debug/nodes/1/crdb_internal.node_role_statistics.txt
   debug/nodes/1/crdb_internal.node_runtime_info.txt
   debug/nodes/1/crdb_internal.node_sessions.txt
+  debug/nodes/1/crdb_internal.node_tls_connections.txt
   debug/nodes/1/details.json
   debug/nodes/1/gossip.json
   debug/nodes/1/enginestats.json
@@ -2408,6 +2421,10 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/18.json
   debug/nodes/1/ranges/19.json
   debug/nodes/1/ranges/20.json
+  debug/nodes/1/ranges/21.json
+  debug/nodes/1/ranges/22.json
+  debug/nodes/1/ranges/23.json
+  debug/nodes/1/ranges/24.json
   debug/schema/defaultdb@details.json
   debug/schema/postgres@details.json
   debug/schema/system@details.json
@@ -2418,11 +2435,15 @@ writing ` + os.DevNull + `
   debug/schema/system/lease.json
   debug/schema/system/locations.json
   debug/schema/system/namespace.json
+  debug/schema/system/pinned_plans.json
   debug/schema/system/rangelog.json
   debug/schema/system/role_members.json
+  debug/schema/system/schedule_runs.json
+  debug/schema/system/schedules.json
   debug/schema/system/settings.json
   debug/schema/system/table_statistics.json
   debug/schema/system/ui.json
+  debug/schema/system/user_auth_state.json
   debug/schema/system/users.json
   debug/schema/system/web_sessions.json
   debug/schema/system/zones.json
@@ -505,6 +505,36 @@ a public network without combining it with --listen-addr.`,
 		Description: CertsDir.Description,
 	}
 
+	TLSMinVersion = FlagInfo{
+		Name: "tls-min-version",
+		Description: `
+Minimum TLS version of the connections to and from the RPC and SQL servers
+of the node. The server.rpc.tls.min_version and server.sql.tls.min_version
+cluster settings can further restrict the versions accepted from clients.
+Version 1.3 requires a binary built with go1.13 or later.
+<PRE>
+
+  1.2
+  1.3
+
+</PRE>`,
+	}
+
+	TLSCipherSuites = FlagInfo{
+		Name: "tls-cipher-suites",
+		Description: `
+Comma-separated list of the cipher suites that can be used by the connections
+to and from the RPC and SQL servers of the node, using the IANA names of the
+suites, for example:
+<PRE>
+
+  TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+
+</PRE>
+The server.rpc.tls.cipher_suites and server.sql.tls.cipher_suites cluster
+settings can further restrict the suites accepted from clients.`,
+	}
+
 	CAKey = FlagInfo{
 		Name:        "ca-key",
 		EnvVar:      "COCKROACH_CA_KEY",
@@ -247,6 +247,9 @@ func tryRangeIDKey(kv engine.MVCCKeyValue) (string, error) {
 	case bytes.Equal(suffix, keys.LocalRangeLastGCSuffix):
 		msg = &hlc.Timestamp{}
 
+	case bytes.Equal(suffix, keys.LocalRangeGCHintSuffix):
+		msg = &roachpb.GCHint{}
+
 	case bytes.Equal(suffix, keys.LocalRaftTombstoneSuffix):
 		msg = &roachpb.RaftTombstone{}
 
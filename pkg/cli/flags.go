@@ -216,11 +216,13 @@ func init() {
 		return setDefaultStderrVerbosity(cmd, log.Severity_WARNING)
 	}
 
-	// Add a pre-run command for `start`.
-	AddPersistentPreRunE(StartCmd, func(cmd *cobra.Command, _ []string) error {
-		extraServerFlagInit()
-		return setDefaultStderrVerbosity(cmd, log.Severity_INFO)
-	})
+	// Add a pre-run command for `start` and `start-single-node`.
+	for _, cmd := range []*cobra.Command{StartCmd, StartSingleNodeCmd} {
+		AddPersistentPreRunE(cmd, func(cmd *cobra.Command, _ []string) error {
+			extraServerFlagInit()
+			return setDefaultStderrVerbosity(cmd, log.Severity_INFO)
+		})
+	}
 
 	// Map any flags registered in the standard "flag" package into the
 	// top-level cockroach command.
@@ -277,8 +279,8 @@ func init() {
 	// avoid printing some messages to standard output in that case.
 	_, startCtx.inBackground = envutil.EnvString(backgroundEnvVar, 1)
 
-	{
-		f := StartCmd.Flags()
+	for _, cmd := range []*cobra.Command{StartCmd, StartSingleNodeCmd} {
+		f := cmd.Flags()
 
 		// Server flags.
 		VarFlag(f, addrSetter{&startCtx.serverListenAddr, &serverListenPort}, cliflags.ListenAddr)
@@ -336,8 +338,16 @@ func init() {
 		// variables, but share the same default.
 		StringFlag(f, &startCtx.serverSSLCertsDir, cliflags.ServerCertsDir, startCtx.serverSSLCertsDir)
 
-		// Cluster joining flags.
+		// TLS restrictions.
+		VarFlag(f, tlsVersionValue{&serverCfg.TLSOptions}, cliflags.TLSMinVersion)
+		VarFlag(f, cipherSuitesValue{&serverCfg.TLSOptions}, cliflags.TLSCipherSuites)
+
+		// Cluster joining flags. start-single-node rejects --join, which is
+		// only defined so that it can report a helpful error.
 		VarFlag(f, &serverCfg.JoinList, cliflags.Join)
+		if cmd == StartSingleNodeCmd {
+			_ = f.MarkHidden(cliflags.Join.Name)
+		}
 
 		// Engine flags.
 		VarFlag(f, cacheSizeValue, cliflags.Cache)
@@ -353,7 +363,7 @@ func init() {
 	}
 
 	// Log flags.
-	for _, cmd := range []*cobra.Command{demoCmd, StartCmd} {
+	for _, cmd := range []*cobra.Command{demoCmd, StartSingleNodeCmd, StartCmd} {
 		f := cmd.Flags()
 		VarFlag(f, &startCtx.logDir, cliflags.LogDir)
 		startCtx.logDirFlag = f.Lookup(cliflags.LogDir.Name)
@@ -405,6 +415,8 @@ func init() {
 		debugZipCmd,
 		dumpCmd,
 		genHAProxyCmd,
+		genKubernetesCmd,
+		genSystemdCmd,
 		quitCmd,
 		sqlShellCmd,
 		/* StartCmd is covered above */
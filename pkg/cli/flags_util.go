@@ -24,6 +24,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/status"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -33,6 +34,59 @@ import (
 	"github.com/pkg/errors"
 )
 
+// tlsVersionValue implements pflag.Value for the --tls-min-version flag.
+type tlsVersionValue struct {
+	opts *security.TLSOptions
+}
+
+// Type implements the pflag.Value interface.
+func (v tlsVersionValue) Type() string { return "<version>" }
+
+// String implements the pflag.Value interface.
+func (v tlsVersionValue) String() string {
+	if v.opts.MinVersion == 0 {
+		return ""
+	}
+	return security.TLSVersionName(v.opts.MinVersion)
+}
+
+// Set implements the pflag.Value interface.
+func (v tlsVersionValue) Set(value string) error {
+	version, err := security.ParseTLSVersion(value)
+	if err != nil {
+		return err
+	}
+	v.opts.MinVersion = version
+	return nil
+}
+
+// cipherSuitesValue implements pflag.Value for the --tls-cipher-suites flag.
+type cipherSuitesValue struct {
+	opts *security.TLSOptions
+}
+
+// Type implements the pflag.Value interface.
+func (v cipherSuitesValue) Type() string { return "<suite>,..." }
+
+// String implements the pflag.Value interface.
+func (v cipherSuitesValue) String() string {
+	names := make([]string, len(v.opts.CipherSuites))
+	for i, id := range v.opts.CipherSuites {
+		names[i] = security.CipherSuiteName(id)
+	}
+	return strings.Join(names, ",")
+}
+
+// Set implements the pflag.Value interface.
+func (v cipherSuitesValue) Set(value string) error {
+	suites, err := security.ParseCipherSuites(value)
+	if err != nil {
+		return err
+	}
+	v.opts.CipherSuites = suites
+	return nil
+}
+
 type localityList []roachpb.LocalityAddress
 
 // Type implements the pflag.Value interface.
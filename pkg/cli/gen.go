@@ -15,6 +15,7 @@
 package cli
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"io"
@@ -23,6 +24,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sqlmigrations"
@@ -237,10 +240,46 @@ Output the list of cluster settings known to this binary.
 	},
 }
 
+// fetchNodeStatuses retrieves the status of all the nodes in the cluster
+// reached through the client flags. It is used by the commands that
+// generate deployment files from the live cluster topology.
+func fetchNodeStatuses(ctx context.Context) ([]statuspb.NodeStatus, error) {
+	conn, _, finish, err := getClientGRPCConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer finish()
+	c := serverpb.NewStatusClient(conn)
+
+	nodeStatuses, err := c.Nodes(ctx, &serverpb.NodesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return nodeStatuses.Nodes, nil
+}
+
+// writeGenOutput calls write with a writer for the file at path, or for
+// stdout if path is "-".
+func writeGenOutput(path string, write func(w io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		// Return earliest error, but still close the file.
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
 var genCmd = &cobra.Command{
 	Use:   "gen [command]",
 	Short: "generate auxiliary files",
-	Long:  "Generate manpages, example shell settings, example databases, etc.",
+	Long:  "Generate manpages, example shell settings, example databases, deployment files, etc.",
 	RunE:  usageAndErr,
 }
 
@@ -249,6 +288,8 @@ var genCmds = []*cobra.Command{
 	genAutocompleteCmd,
 	genExamplesCmd,
 	genHAProxyCmd,
+	genSystemdCmd,
+	genKubernetesCmd,
 	genSettingsListCmd,
 	genEncryptionKeyCmd,
 }
@@ -261,6 +302,12 @@ func init() {
 	genHAProxyCmd.PersistentFlags().StringVar(&haProxyPath, "out", "haproxy.cfg",
 		"path to generated haproxy configuration file")
 	VarFlag(genHAProxyCmd.Flags(), &haProxyLocality, cliflags.Locality)
+	genSystemdCmd.PersistentFlags().StringVar(&systemdPath, "out", ".",
+		"directory where the generated systemd units are written")
+	VarFlag(genSystemdCmd.Flags(), &systemdLocality, cliflags.Locality)
+	genKubernetesCmd.PersistentFlags().StringVar(&kubernetesPath, "out", "cockroachdb-statefulset.yaml",
+		"path to generated Kubernetes manifest")
+	VarFlag(genKubernetesCmd.Flags(), &kubernetesLocality, cliflags.Locality)
 	genEncryptionKeyCmd.PersistentFlags().IntVarP(&aesSize, "size", "s", 128,
 		"AES key size for encryption at rest (one of: 128, 192, 256)")
 	genEncryptionKeyCmd.PersistentFlags().BoolVar(&overwriteKey, "overwrite", false,
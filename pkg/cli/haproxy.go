@@ -20,7 +20,6 @@ import (
 	"html/template"
 	"io"
 	"io/ioutil"
-	"os"
 	"regexp"
 	"sort"
 	"strings"
@@ -28,7 +27,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
-	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -140,8 +138,12 @@ func localityMatches(locality roachpb.Locality, desired roachpb.Locality) (bool,
 	return true, nil
 }
 
-func filterByLocality(nodeInfos []haProxyNodeInfo) ([]haProxyNodeInfo, error) {
-	if len(haProxyLocality.Tiers) == 0 {
+// filterByLocality returns the nodes whose locality matches the desired
+// locality, as described in the help text of `gen haproxy`.
+func filterByLocality(
+	nodeInfos []haProxyNodeInfo, desired roachpb.Locality,
+) ([]haProxyNodeInfo, error) {
+	if len(desired.Tiers) == 0 {
 		// No filter.
 		return nodeInfos, nil
 	}
@@ -158,7 +160,7 @@ func filterByLocality(nodeInfos []haProxyNodeInfo) ([]haProxyNodeInfo, error) {
 		// Save seen locality.
 		availableLocalities[l.String()] = struct{}{}
 
-		matches, err := localityMatches(l, haProxyLocality)
+		matches, err := localityMatches(l, desired)
 		if err != nil {
 			return nil, err
 		}
@@ -176,7 +178,7 @@ func filterByLocality(nodeInfos []haProxyNodeInfo) ([]haProxyNodeInfo, error) {
 			i++
 		}
 		sort.Strings(seenLocalities)
-		return nil, fmt.Errorf("no nodes match locality filter %s. Found localities: %v", haProxyLocality.String(), seenLocalities)
+		return nil, fmt.Errorf("no nodes match locality filter %s. Found localities: %v", desired.String(), seenLocalities)
 	}
 
 	return result, nil
@@ -191,46 +193,20 @@ func runGenHAProxyCmd(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	conn, _, finish, err := getClientGRPCConn(ctx)
+	nodeStatuses, err := fetchNodeStatuses(ctx)
 	if err != nil {
 		return err
 	}
-	defer finish()
-	c := serverpb.NewStatusClient(conn)
 
-	nodeStatuses, err := c.Nodes(ctx, &serverpb.NodesRequest{})
+	nodeInfos := nodeStatusesToNodeInfos(nodeStatuses)
+	filteredNodeInfos, err := filterByLocality(nodeInfos, haProxyLocality)
 	if err != nil {
 		return err
 	}
 
-	var w io.Writer
-	var f *os.File
-	if haProxyPath == "-" {
-		w = os.Stdout
-	} else if f, err = os.OpenFile(haProxyPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755); err != nil {
-		return err
-	} else {
-		w = f
-	}
-
-	nodeInfos := nodeStatusesToNodeInfos(nodeStatuses.Nodes)
-	filteredNodeInfos, err := filterByLocality(nodeInfos)
-	if err != nil {
-		return err
-	}
-
-	err = configTemplate.Execute(w, filteredNodeInfos)
-	if err != nil {
-		// Return earliest error, but still close the file.
-		_ = f.Close()
-		return err
-	}
-
-	if f != nil {
-		return f.Close()
-	}
-
-	return nil
+	return writeGenOutput(haProxyPath, func(w io.Writer) error {
+		return configTemplate.Execute(w, filteredNodeInfos)
+	})
 }
 
 const haProxyTemplate = `
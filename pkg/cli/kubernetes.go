@@ -0,0 +1,325 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/spf13/cobra"
+)
+
+var kubernetesPath string
+var kubernetesLocality roachpb.Locality
+
+var genKubernetesCmd = &cobra.Command{
+	Use:     "kubernetes",
+	Aliases: []string{"k8s"},
+	Short:   "generate an example Kubernetes manifest for the connected cluster",
+	Long: `This command generates an example Kubernetes manifest that deploys a
+cluster with the same topology as the cluster reached through the client
+flags. The manifest is written to --out. Use "--out -" for stdout.
+
+The manifest contains one StatefulSet per distinct node locality, with as
+many replicas as there are nodes in that locality, started with the same
+'--locality' flag. The image is that of the version run by the nodes. If
+the client flags use a secure connection, the nodes of the manifest use the
+certificates stored in the "cockroachdb.node" secret.
+
+Nodes to include can be filtered with '--locality', as described in the
+help of 'cockroach gen haproxy'.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runGenKubernetesCmd),
+}
+
+// kubernetesStatefulSet describes a StatefulSet of the generated manifest.
+type kubernetesStatefulSet struct {
+	Name     string
+	Replicas int
+	Locality string
+}
+
+// kubernetesManifestInfo contains the information used to generate the
+// manifest.
+type kubernetesManifestInfo struct {
+	Image        string
+	Secure       bool
+	GRPCPort     string
+	HTTPPort     string
+	JoinAddrs    string
+	StatefulSets []kubernetesStatefulSet
+}
+
+// kubernetesMaxJoinPodsPerSet is the number of pods of each StatefulSet
+// listed in the --join flag of the nodes.
+const kubernetesMaxJoinPodsPerSet = 3
+
+// kubernetesName turns a locality into a string usable in the name of a
+// Kubernetes object, which may only contain lowercase alphanumerical
+// characters and '-'.
+func kubernetesName(locality roachpb.Locality) string {
+	var b strings.Builder
+	for _, tier := range locality.Tiers {
+		for _, r := range strings.ToLower(tier.Value) {
+			if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+				b.WriteRune(r)
+			} else {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteByte('-')
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// nodeStatusesToKubernetesManifestInfo groups the nodes by locality into
+// StatefulSets.
+func nodeStatusesToKubernetesManifestInfo(
+	statuses []statuspb.NodeStatus, secure bool,
+) kubernetesManifestInfo {
+	info := kubernetesManifestInfo{
+		Image:    "cockroachdb/cockroach:latest",
+		Secure:   secure,
+		GRPCPort: base.DefaultPort,
+		HTTPPort: base.DefaultHTTPPort,
+	}
+
+	counts := make(map[string]int)
+	localities := make(map[string]roachpb.Locality)
+	for _, status := range statuses {
+		l := status.Desc.Locality.String()
+		counts[l]++
+		localities[l] = status.Desc.Locality
+	}
+	if len(statuses) > 0 {
+		buildInfo := statuses[0].BuildInfo
+		if strings.HasPrefix(buildInfo.Type, "release") && buildInfo.Tag != "" {
+			info.Image = "cockroachdb/cockroach:" + buildInfo.Tag
+		}
+	}
+
+	sortedLocalities := make([]string, 0, len(counts))
+	for l := range counts {
+		sortedLocalities = append(sortedLocalities, l)
+	}
+	sort.Strings(sortedLocalities)
+
+	usedNames := make(map[string]struct{})
+	var joinAddrs []string
+	for i, l := range sortedLocalities {
+		name := "cockroachdb"
+		if suffix := kubernetesName(localities[l]); suffix != "" {
+			name += "-" + suffix
+		}
+		if _, ok := usedNames[name]; ok {
+			name = fmt.Sprintf("%s-%d", name, i+1)
+		}
+		usedNames[name] = struct{}{}
+
+		info.StatefulSets = append(info.StatefulSets, kubernetesStatefulSet{
+			Name:     name,
+			Replicas: counts[l],
+			Locality: l,
+		})
+		for j := 0; j < counts[l] && j < kubernetesMaxJoinPodsPerSet; j++ {
+			joinAddrs = append(joinAddrs, fmt.Sprintf("%s-%d.cockroachdb", name, j))
+		}
+	}
+	info.JoinAddrs = strings.Join(joinAddrs, ",")
+	return info
+}
+
+func runGenKubernetesCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	manifestTemplate, err := template.New("kubernetes template").Parse(kubernetesTemplate)
+	if err != nil {
+		return err
+	}
+
+	nodeStatuses, err := fetchNodeStatuses(ctx)
+	if err != nil {
+		return err
+	}
+
+	filteredStatuses, err := filterStatusesByLocality(nodeStatuses, kubernetesLocality)
+	if err != nil {
+		return err
+	}
+	info := nodeStatusesToKubernetesManifestInfo(filteredStatuses, !baseCfg.Insecure)
+
+	return writeGenOutput(kubernetesPath, func(w io.Writer) error {
+		return manifestTemplate.Execute(w, info)
+	})
+}
+
+const kubernetesTemplate = `# Generated by "cockroach gen kubernetes". Review the resources, the
+# storage and the scheduling constraints before using it.
+apiVersion: v1
+kind: Service
+metadata:
+  name: cockroachdb-public
+  labels:
+    app: cockroachdb
+spec:
+  ports:
+  - port: {{.GRPCPort}}
+    targetPort: {{.GRPCPort}}
+    name: grpc
+  - port: {{.HTTPPort}}
+    targetPort: {{.HTTPPort}}
+    name: http
+  selector:
+    app: cockroachdb
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: cockroachdb
+  labels:
+    app: cockroachdb
+  annotations:
+    service.alpha.kubernetes.io/tolerate-unready-endpoints: "true"
+    prometheus.io/scrape: "true"
+    prometheus.io/path: "_status/vars"
+    prometheus.io/port: "{{.HTTPPort}}"
+spec:
+  ports:
+  - port: {{.GRPCPort}}
+    targetPort: {{.GRPCPort}}
+    name: grpc
+  - port: {{.HTTPPort}}
+    targetPort: {{.HTTPPort}}
+    name: http
+  publishNotReadyAddresses: true
+  clusterIP: None
+  selector:
+    app: cockroachdb
+---
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: cockroachdb-budget
+  labels:
+    app: cockroachdb
+spec:
+  selector:
+    matchLabels:
+      app: cockroachdb
+  maxUnavailable: 1
+{{- range .StatefulSets}}
+---
+apiVersion: apps/v1beta1
+kind: StatefulSet
+metadata:
+  name: {{.Name}}
+spec:
+  serviceName: "cockroachdb"
+  replicas: {{.Replicas}}
+  selector:
+    matchLabels:
+      app: cockroachdb
+      statefulset: {{.Name}}
+  template:
+    metadata:
+      labels:
+        app: cockroachdb
+        statefulset: {{.Name}}
+    spec:
+      affinity:
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 100
+            podAffinityTerm:
+              labelSelector:
+                matchExpressions:
+                - key: app
+                  operator: In
+                  values:
+                  - cockroachdb
+              topologyKey: kubernetes.io/hostname
+      containers:
+      - name: cockroachdb
+        image: {{$.Image}}
+        imagePullPolicy: IfNotPresent
+        ports:
+        - containerPort: {{$.GRPCPort}}
+          name: grpc
+        - containerPort: {{$.HTTPPort}}
+          name: http
+        livenessProbe:
+          httpGet:
+            path: "/health"
+            port: http
+{{- if $.Secure}}
+            scheme: HTTPS
+{{- end}}
+          initialDelaySeconds: 30
+          periodSeconds: 5
+        readinessProbe:
+          httpGet:
+            path: "/health?ready=1"
+            port: http
+{{- if $.Secure}}
+            scheme: HTTPS
+{{- end}}
+          initialDelaySeconds: 10
+          periodSeconds: 5
+          failureThreshold: 2
+        volumeMounts:
+        - name: datadir
+          mountPath: /cockroach/cockroach-data
+{{- if $.Secure}}
+        - name: certs
+          mountPath: /cockroach/cockroach-certs
+{{- end}}
+        command:
+          - "/bin/bash"
+          - "-ecx"
+          - "exec /cockroach/cockroach start --logtostderr {{if $.Secure}}--certs-dir /cockroach/cockroach-certs{{else}}--insecure{{end}} --advertise-host $(hostname -f) --http-addr 0.0.0.0 --join {{$.JoinAddrs}}{{if .Locality}} --locality {{.Locality}}{{end}} --cache 25% --max-sql-memory 25%"
+      terminationGracePeriodSeconds: 60
+      volumes:
+      - name: datadir
+        persistentVolumeClaim:
+          claimName: datadir
+{{- if $.Secure}}
+      - name: certs
+        secret:
+          secretName: cockroachdb.node
+          defaultMode: 256
+{{- end}}
+  podManagementPolicy: Parallel
+  updateStrategy:
+    type: RollingUpdate
+  volumeClaimTemplates:
+  - metadata:
+      name: datadir
+    spec:
+      accessModes:
+        - "ReadWriteOnce"
+      resources:
+        requests:
+          storage: 100Gi
+{{- end}}
+`
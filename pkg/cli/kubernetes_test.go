@@ -0,0 +1,88 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/build"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestNodeStatusesToKubernetesManifestInfo(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	status := func(locality string, buildType string) statuspb.NodeStatus {
+		var l roachpb.Locality
+		if locality != "" {
+			if err := l.Set(locality); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return statuspb.NodeStatus{
+			Desc:      roachpb.NodeDescriptor{Locality: l},
+			BuildInfo: build.Info{Tag: "v19.1.0", Type: buildType},
+		}
+	}
+
+	testCases := []struct {
+		input    []statuspb.NodeStatus
+		expected kubernetesManifestInfo
+	}{
+		{
+			[]statuspb.NodeStatus{status("", "development"), status("", "development")},
+			kubernetesManifestInfo{
+				Image:     "cockroachdb/cockroach:latest",
+				JoinAddrs: "cockroachdb-0.cockroachdb,cockroachdb-1.cockroachdb",
+				StatefulSets: []kubernetesStatefulSet{
+					{Name: "cockroachdb", Replicas: 2},
+				},
+			},
+		},
+		{
+			[]statuspb.NodeStatus{
+				status("region=us-west,zone=B", "release"),
+				status("region=us-east,zone=a", "release"),
+				status("region=us-west,zone=B", "release"),
+				status("region=us-west,zone=B", "release"),
+				status("region=us-west,zone=B", "release"),
+				status("region=us.west,zone=b", "release"),
+			},
+			kubernetesManifestInfo{
+				Image: "cockroachdb/cockroach:v19.1.0",
+				JoinAddrs: "cockroachdb-us-east-a-0.cockroachdb," +
+					"cockroachdb-us-west-b-0.cockroachdb,cockroachdb-us-west-b-1.cockroachdb," +
+					"cockroachdb-us-west-b-2.cockroachdb,cockroachdb-us-west-b-3-0.cockroachdb",
+				StatefulSets: []kubernetesStatefulSet{
+					{Name: "cockroachdb-us-east-a", Replicas: 1, Locality: "region=us-east,zone=a"},
+					{Name: "cockroachdb-us-west-b", Replicas: 4, Locality: "region=us-west,zone=B"},
+					{Name: "cockroachdb-us-west-b-3", Replicas: 1, Locality: "region=us.west,zone=b"},
+				},
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		tc.expected.GRPCPort = "26257"
+		tc.expected.HTTPPort = "8080"
+		res := nodeStatusesToKubernetesManifestInfo(tc.input, false /* secure */)
+		if !reflect.DeepEqual(res, tc.expected) {
+			t.Errorf("%d: unexpected output %+v, expected %+v", i, res, tc.expected)
+		}
+	}
+}
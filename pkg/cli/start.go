@@ -84,6 +84,37 @@ uninitialized, specify the --join flag to point to any healthy node
 	RunE:    maybeShoutError(MaybeDecorateGRPCError(runStart)),
 }
 
+// StartSingleNodeCmd starts a node which runs a single-node cluster.
+var StartSingleNodeCmd = &cobra.Command{
+	Use:   "start-single-node",
+	Short: "start a single-node cluster",
+	Long: `
+Start a CockroachDB node which runs a single-node cluster, for local
+development and testing. It accepts the flags of the start command,
+except --join.
+
+On its first start, the node initializes a new cluster which keeps a
+single replica of the data, so that no ranges are reported as
+under-replicated, and which uses a shorter garbage collection TTL.
+Other nodes cannot join the cluster; use the start command to start
+the nodes of a multi-node cluster.
+`,
+	Example: `  cockroach start-single-node --insecure --store=path=/mnt/ssd1`,
+	Args:    cobra.NoArgs,
+	RunE:    maybeShoutError(MaybeDecorateGRPCError(runStartSingleNode)),
+}
+
+func runStartSingleNode(cmd *cobra.Command, args []string) error {
+	if cmd.Flags().Lookup(cliflags.Join.Name).Changed {
+		return errors.Errorf("--%s cannot be used with start-single-node; "+
+			"use the start command to start the nodes of a multi-node cluster", cliflags.Join.Name)
+	}
+	serverCfg.SingleNode = true
+	// The logging setup checks the --log-dir flag of the start command.
+	startCtx.logDirFlag = cmd.Flags().Lookup(cliflags.LogDir.Name)
+	return runStart(cmd, args)
+}
+
 // maxSizePerProfile is the maximum total size in bytes for profiles per
 // profile type.
 var maxSizePerProfile = envutil.EnvOrDefaultInt64(
@@ -129,6 +129,27 @@ func TestStartArgChecking(t *testing.T) {
 	}
 }
 
+func TestStartSingleNodeRejectsJoin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(save server.Config) { serverCfg = save }(serverCfg)
+	defer initCLIDefaults()
+
+	f := StartSingleNodeCmd.Flags()
+	if err := f.Parse([]string{"--join=localhost:26257"}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { f.Lookup("join").Changed = false }()
+
+	err := runStartSingleNode(StartSingleNodeCmd, nil)
+	if !testutils.IsError(err, "--join cannot be used with start-single-node") {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if serverCfg.SingleNode {
+		t.Fatal("expected the server configuration to be left unchanged")
+	}
+}
+
 func TestGCProfiles(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/sdnotify"
 	"github.com/cockroachdb/cockroach/pkg/util/sysutil"
+	"github.com/spf13/cobra"
 	"golang.org/x/sys/unix"
 )
 
@@ -62,7 +63,9 @@ func handleSignalDuringShutdown(sig os.Signal) {
 var startBackground bool
 
 func init() {
-	BoolFlag(StartCmd.Flags(), &startBackground, cliflags.Background, false)
+	for _, cmd := range []*cobra.Command{StartCmd, StartSingleNodeCmd} {
+		BoolFlag(cmd.Flags(), &startBackground, cliflags.Background, false)
+	}
 }
 
 func maybeRerunBackground() (bool, error) {
@@ -0,0 +1,222 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/spf13/cobra"
+)
+
+var systemdPath string
+var systemdLocality roachpb.Locality
+
+var genSystemdCmd = &cobra.Command{
+	Use:   "systemd",
+	Short: "generate systemd units for the nodes of the connected cluster",
+	Long: `This command generates one systemd unit file per node of the cluster
+reached through the client flags. The unit for node N is written to
+"cockroach-nodeN.service" in the --out directory. Use "--out -" to print
+all the units to stdout.
+
+The command line in each unit is the one the node was started with. The
+"--background" flag is removed, and a "--join" flag listing the addresses
+advertised by all the nodes is added if the node was started without one.
+Relative paths on the command line are resolved against the working
+directory of the unit, which should be adjusted to match the deployment.
+
+Nodes to include can be filtered with '--locality', as described in the
+help of 'cockroach gen haproxy'.
+`,
+	Args: cobra.NoArgs,
+	RunE: MaybeDecorateGRPCError(runGenSystemdCmd),
+}
+
+// systemdUnitInfo contains the information used to generate the unit
+// file of a node.
+type systemdUnitInfo struct {
+	NodeID    roachpb.NodeID
+	ExecStart string
+}
+
+// filterStatusesByLocality returns the statuses of the nodes whose locality
+// matches the desired locality.
+func filterStatusesByLocality(
+	statuses []statuspb.NodeStatus, desired roachpb.Locality,
+) ([]statuspb.NodeStatus, error) {
+	nodeInfos, err := filterByLocality(nodeStatusesToNodeInfos(statuses), desired)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[roachpb.NodeID]struct{}, len(nodeInfos))
+	for _, info := range nodeInfos {
+		keep[info.NodeID] = struct{}{}
+	}
+	result := make([]statuspb.NodeStatus, 0, len(nodeInfos))
+	for _, status := range statuses {
+		if _, ok := keep[status.Desc.NodeID]; ok {
+			result = append(result, status)
+		}
+	}
+	return result, nil
+}
+
+// argIsFlag returns whether the command line argument arg sets the flag
+// described by info.
+func argIsFlag(arg string, info cliflags.FlagInfo) bool {
+	if arg == "--"+info.Name || strings.HasPrefix(arg, "--"+info.Name+"=") {
+		return true
+	}
+	return info.Shorthand != "" &&
+		!strings.HasPrefix(arg, "--") && strings.HasPrefix(arg, "-"+info.Shorthand)
+}
+
+// systemdQuote quotes a command line argument for use in the ExecStart
+// directive of a unit file. The characters that systemd expands in
+// ExecStart, '%' and '$', are escaped.
+func systemdQuote(arg string) string {
+	arg = strings.Replace(arg, "%", "%%", -1)
+	arg = strings.Replace(arg, "$", "$$", -1)
+	if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\;") {
+		return arg
+	}
+	arg = strings.Replace(arg, `\`, `\\`, -1)
+	arg = strings.Replace(arg, `"`, `\"`, -1)
+	arg = strings.Replace(arg, "\n", `\n`, -1)
+	return `"` + arg + `"`
+}
+
+// systemdExecStart returns the ExecStart directive of the unit of a node
+// started with the given command line. joinAddrs is used in a --join flag
+// if a node started with "start" did not specify one.
+func systemdExecStart(args []string, joinAddrs []string) string {
+	if len(args) == 0 {
+		args = []string{"cockroach", "start"}
+	}
+	quoted := make([]string, 0, len(args)+1)
+	hasJoin := false
+	for _, arg := range args {
+		if argIsFlag(arg, cliflags.Background) {
+			continue
+		}
+		if argIsFlag(arg, cliflags.Join) {
+			hasJoin = true
+		}
+		quoted = append(quoted, systemdQuote(arg))
+	}
+	if !hasJoin && len(args) > 1 && args[1] == "start" && len(joinAddrs) > 0 {
+		flag := fmt.Sprintf("--%s=%s", cliflags.Join.Name, strings.Join(joinAddrs, ","))
+		quoted = append(quoted, systemdQuote(flag))
+	}
+	return strings.Join(quoted, " ")
+}
+
+// nodeStatusesToSystemdUnitInfos returns the unit information of the nodes
+// in statuses. All the nodes in allStatuses are used to join the cluster.
+func nodeStatusesToSystemdUnitInfos(
+	statuses []statuspb.NodeStatus, allStatuses []statuspb.NodeStatus,
+) []systemdUnitInfo {
+	sorted := append([]statuspb.NodeStatus(nil), allStatuses...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Desc.NodeID < sorted[j].Desc.NodeID
+	})
+	joinAddrs := make([]string, len(sorted))
+	for i := range sorted {
+		joinAddrs[i] = sorted[i].Desc.Address.AddressField
+	}
+
+	unitInfos := make([]systemdUnitInfo, len(statuses))
+	for i, status := range statuses {
+		unitInfos[i].NodeID = status.Desc.NodeID
+		unitInfos[i].ExecStart = systemdExecStart(status.Args, joinAddrs)
+	}
+	return unitInfos
+}
+
+func runGenSystemdCmd(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	unitTemplate, err := template.New("systemd template").Parse(systemdTemplate)
+	if err != nil {
+		return err
+	}
+
+	nodeStatuses, err := fetchNodeStatuses(ctx)
+	if err != nil {
+		return err
+	}
+
+	filteredStatuses, err := filterStatusesByLocality(nodeStatuses, systemdLocality)
+	if err != nil {
+		return err
+	}
+	unitInfos := nodeStatusesToSystemdUnitInfos(filteredStatuses, nodeStatuses)
+
+	if systemdPath == "-" {
+		return writeGenOutput(systemdPath, func(w io.Writer) error {
+			for _, info := range unitInfos {
+				fmt.Fprintf(w, "# %s\n", systemdUnitName(info.NodeID))
+				if err := unitTemplate.Execute(w, info); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	for _, info := range unitInfos {
+		path := filepath.Join(systemdPath, systemdUnitName(info.NodeID))
+		if err := writeGenOutput(path, func(w io.Writer) error {
+			return unitTemplate.Execute(w, info)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func systemdUnitName(nodeID roachpb.NodeID) string {
+	return fmt.Sprintf("cockroach-node%d.service", nodeID)
+}
+
+const systemdTemplate = `[Unit]
+Description=CockroachDB node {{.NodeID}}
+Requires=network.target
+
+[Service]
+Type=notify
+# Relative paths in ExecStart are resolved against this directory.
+WorkingDirectory=/var/lib/cockroach
+ExecStart={{.ExecStart}}
+TimeoutStopSec=60
+Restart=always
+RestartSec=10
+StandardOutput=syslog
+StandardError=syslog
+SyslogIdentifier=cockroach
+User=cockroach
+
+[Install]
+WantedBy=default.target
+`
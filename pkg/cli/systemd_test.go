@@ -0,0 +1,93 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cli
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/status/statuspb"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSystemdExecStart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	join := []string{"a:26257", "b:26257"}
+	testCases := []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "cockroach start --join=a:26257,b:26257"},
+		{
+			[]string{"/usr/local/bin/cockroach", "start", "--insecure", "--background"},
+			"/usr/local/bin/cockroach start --insecure --join=a:26257,b:26257",
+		},
+		{
+			[]string{"cockroach", "start", "--background=true", "--join", "c"},
+			"cockroach start --join c",
+		},
+		{
+			[]string{"cockroach", "start", "--join=c"},
+			"cockroach start --join=c",
+		},
+		{
+			[]string{"cockroach", "start", "-jc"},
+			"cockroach start -jc",
+		},
+		{
+			[]string{"cockroach", "start-single-node", "--insecure"},
+			"cockroach start-single-node --insecure",
+		},
+		{
+			[]string{"cockroach", "start", "--join=c", "--cache=25%", "--store=path=/my data"},
+			`cockroach start --join=c --cache=25%% "--store=path=/my data"`,
+		},
+		{
+			[]string{"cockroach", "start", "--join=c", `--attrs=a"b\c`, "--locality=$x"},
+			`cockroach start --join=c "--attrs=a\"b\\c" --locality=$$x`,
+		},
+	}
+
+	for i, tc := range testCases {
+		if res := systemdExecStart(tc.args, join); res != tc.expected {
+			t.Errorf("%d: expected %q, got %q", i, tc.expected, res)
+		}
+	}
+}
+
+func TestNodeStatusesToSystemdUnitInfos(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	status := func(nodeID roachpb.NodeID, addr string) statuspb.NodeStatus {
+		return statuspb.NodeStatus{
+			Desc: roachpb.NodeDescriptor{
+				NodeID:  nodeID,
+				Address: util.UnresolvedAddr{AddressField: addr},
+			},
+			Args: []string{"cockroach", "start", "--insecure"},
+		}
+	}
+	all := []statuspb.NodeStatus{status(3, "c"), status(1, "a"), status(2, "b")}
+
+	expected := []systemdUnitInfo{
+		{NodeID: 2, ExecStart: "cockroach start --insecure --join=a,b,c"},
+	}
+	if res := nodeStatusesToSystemdUnitInfos(all[2:], all); !reflect.DeepEqual(res, expected) {
+		t.Fatalf("unexpected output %v, expected %v", res, expected)
+	}
+}
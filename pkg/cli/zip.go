@@ -56,10 +56,15 @@ requires the cluster to be live.
 
 // Tables containing cluster-wide info that are collected in a debug zip.
 var debugZipTablesPerCluster = []string{
+	"crdb_internal.cluster_inflight_trace_spans",
+	"crdb_internal.cluster_locks",
+	"crdb_internal.cluster_network_latencies",
 	"crdb_internal.cluster_queries",
 	"crdb_internal.cluster_sessions",
 	"crdb_internal.cluster_settings",
+	"crdb_internal.constraint_violations",
 
+	"crdb_internal.deadlocks",
 	"crdb_internal.jobs",
 
 	"crdb_internal.kv_node_status",
@@ -67,6 +72,7 @@ var debugZipTablesPerCluster = []string{
 
 	"crdb_internal.schema_changes",
 	"crdb_internal.partitions",
+	"crdb_internal.super_region_violations",
 	"crdb_internal.zones",
 }
 
@@ -81,12 +87,18 @@ var debugZipTablesPerNode = []string{
 
 	"crdb_internal.leases",
 
+	"crdb_internal.node_statement_plans",
 	"crdb_internal.node_statement_statistics",
 	"crdb_internal.node_build_info",
+	"crdb_internal.node_inflight_trace_spans",
+	"crdb_internal.node_memory_monitors",
 	"crdb_internal.node_metrics",
+	"crdb_internal.node_network_latencies",
 	"crdb_internal.node_queries",
+	"crdb_internal.node_role_statistics",
 	"crdb_internal.node_runtime_info",
 	"crdb_internal.node_sessions",
+	"crdb_internal.node_tls_connections",
 }
 
 type zipper struct {
@@ -358,6 +358,10 @@ var specs = []stmtSpec{
 		replace: map[string]string{"string_or_placeholder 'WITH'": "name 'WITH'", "'PASSWORD' string_or_placeholder": "'PASSWORD' password"},
 		unlink:  []string{"name", "password"},
 	},
+	{
+		name:   "alter_read_mode_database_stmt",
+		inline: []string{"transaction_read_mode"},
+	},
 	{
 		name:    "alter_sequence_options_stmt",
 		inline:  []string{"sequence_option_list", "sequence_option_elem"},
@@ -1016,7 +1020,7 @@ var specs = []stmtSpec{
 	},
 	{
 		name:   "table_ref",
-		inline: []string{"opt_ordinality", "opt_alias_clause", "opt_expr_list", "opt_column_list", "name_list", "alias_clause"},
+		inline: []string{"opt_ordinality", "opt_alias_clause", "opt_expr_list", "opt_column_list", "name_list", "alias_clause", "opt_tablesample_clause", "opt_repeatable_clause"},
 		replace: map[string]string{
 			"select_with_parens": "'(' select_stmt ')'",
 			"opt_index_flags":    "( '@' index_name | )",
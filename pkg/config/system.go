@@ -242,6 +242,14 @@ func (s *SystemConfig) GetLargestObjectID(maxID uint32) (uint32, error) {
 // or database, specified by key.id). It is the caller's
 // responsibility to ensure that the range does not need to be split.
 func (s *SystemConfig) GetZoneConfigForKey(key roachpb.RKey) (*ZoneConfig, error) {
+	objectID, keySuffix := ZoneObjectIDForKey(key)
+	return s.getZoneConfigForKey(objectID, keySuffix)
+}
+
+// ZoneObjectIDForKey returns the ID of the object whose zone config applies to
+// the given key, along with the remainder of the key, which determines the
+// subzone of the zone config which applies to it, if any.
+func ZoneObjectIDForKey(key roachpb.RKey) (objectID uint32, keySuffix []byte) {
 	objectID, keySuffix, ok := DecodeObjectID(key)
 	if !ok {
 		// Not in the structured data namespace.
@@ -266,8 +274,7 @@ func (s *SystemConfig) GetZoneConfigForKey(key roachpb.RKey) (*ZoneConfig, error
 			objectID = keys.SystemRangesID
 		}
 	}
-
-	return s.getZoneConfigForKey(objectID, keySuffix)
+	return objectID, keySuffix
 }
 
 // GetZoneConfigForObject returns the zone ID for a given object ID.
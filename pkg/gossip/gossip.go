@@ -428,6 +428,16 @@ func (g *Gossip) SetCullInterval(interval time.Duration) {
 	g.cullInterval = interval
 }
 
+// RefuseIncomingConnections causes the gossip server to refuse the
+// connections of other nodes, with an error mentioning the given reason.
+// Since a node cannot join a cluster without being gossiped to, this keeps
+// other nodes from joining the cluster through this node.
+func (g *Gossip) RefuseIncomingConnections(reason string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.mu.refuseReason = reason
+}
+
 // SetStorage provides an instance of the Storage interface
 // for reading and writing gossip bootstrap data from persistent
 // storage. This should be invoked as early in the lifecycle of a
@@ -729,6 +729,40 @@ func TestGossipJoinTwoClusters(t *testing.T) {
 	g[1].mu.Unlock()
 }
 
+// TestGossipRefuseIncomingConnections verifies that the gossip server
+// refuses the connections of other nodes after RefuseIncomingConnections.
+func TestGossipRefuseIncomingConnections(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.TODO())
+
+	local := startGossip(1, stopper, t, metric.NewRegistry())
+	local.RefuseIncomingConnections("single-node cluster")
+	local.mu.RLock()
+	addr := local.mu.is.NodeAddr
+	local.mu.RUnlock()
+
+	rpcCtx := newInsecureRPCContext(stopper)
+	conn, err := rpcCtx.GRPCDial(addr.String()).Connect(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := NewGossipClient(conn).Gossip(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stream.Send(&Request{
+		NodeID: 2,
+		Addr:   util.MakeUnresolvedAddr("tcp", "remote:26257"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); !testutils.IsError(err,
+		"gossip connection refused from node at remote:26257: single-node cluster") {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
 // Test propagation of gossip infos in both directions across an existing
 // gossip connection.
 func TestGossipPropagation(t *testing.T) {
@@ -59,6 +59,9 @@ type server struct {
 		// composable. There's an open proposal to add them:
 		// https://github.com/golang/go/issues/16620
 		ready chan struct{}
+		// refuseReason, if set, is the reason for which the incoming
+		// connections are refused. See RefuseIncomingConnections.
+		refuseReason string
 	}
 	tighten chan struct{} // Sent on when we may want to tighten the network
 
@@ -113,6 +116,12 @@ func (s *server) Gossip(stream Gossip_GossipServer) error {
 	if (args.ClusterID != uuid.UUID{}) && args.ClusterID != s.clusterID.Get() {
 		return errors.Errorf("gossip connection refused from different cluster %s", args.ClusterID)
 	}
+	s.mu.RLock()
+	refuseReason := s.mu.refuseReason
+	s.mu.RUnlock()
+	if refuseReason != "" {
+		return errors.Errorf("gossip connection refused from node at %s: %s", args.Addr, refuseReason)
+	}
 
 	ctx, cancel := context.WithCancel(s.AnnotateCtx(stream.Context()))
 	defer cancel()
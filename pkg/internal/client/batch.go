@@ -554,6 +554,18 @@ func (b *Batch) Del(keys ...interface{}) {
 //
 // key can be either a byte slice or a string.
 func (b *Batch) DelRange(s, e interface{}, returnKeys bool) {
+	b.delRange(s, e, returnKeys, false /* gcHint */)
+}
+
+// DelRangeWithGCHint is like DelRange, and additionally leaves a hint for the
+// GC queue on the ranges which have no live data left after the deletion, so
+// that their MVCC versions can be removed with a range deletion once they
+// expire. It is meant for the deletion of the data of whole tables or indexes.
+func (b *Batch) DelRangeWithGCHint(s, e interface{}, returnKeys bool) {
+	b.delRange(s, e, returnKeys, true /* gcHint */)
+}
+
+func (b *Batch) delRange(s, e interface{}, returnKeys, gcHint bool) {
 	begin, err := marshalKey(s)
 	if err != nil {
 		b.initResult(0, 0, notRaw, err)
@@ -564,7 +576,9 @@ func (b *Batch) DelRange(s, e interface{}, returnKeys bool) {
 		b.initResult(0, 0, notRaw, err)
 		return
 	}
-	b.appendReqs(roachpb.NewDeleteRange(begin, end, returnKeys))
+	req := roachpb.NewDeleteRange(begin, end, returnKeys).(*roachpb.DeleteRangeRequest)
+	req.UpdateRangeDeleteGCHint = gcHint
+	b.appendReqs(req)
 	b.initResult(1, 0, notRaw, nil)
 }
 
@@ -112,6 +112,14 @@ type Config struct {
 	// If MaxMsgsPerBatch <= 0 then no limit is enforced.
 	MaxMsgsPerBatch int
 
+	// MaxKeysPerBatchReq is the maximum number of keys that each batch is
+	// allowed to touch during one of its requests, which is set as the
+	// MaxSpanRequestKeys of the batches. The requests which are not completed
+	// because of this limit receive a response with a ResumeSpan and are
+	// expected to be resent by the client. If MaxKeysPerBatchReq <= 0 then no
+	// limit is enforced.
+	MaxKeysPerBatchReq int
+
 	// MaxWait is the maximum amount of time a message should wait in a batch
 	// before being sent. If MaxWait is <= 0 then no wait timeout is enforced.
 	// It is inadvisable to disable both MaxIdle and MaxWait.
@@ -258,7 +266,7 @@ func (b *RequestBatcher) sendDone(ba *batch) {
 func (b *RequestBatcher) sendBatch(ctx context.Context, ba *batch) {
 	b.cfg.Stopper.RunWorker(ctx, func(ctx context.Context) {
 		defer b.sendDone(ba)
-		resp, pErr := b.cfg.Sender.Send(ctx, ba.batchRequest())
+		resp, pErr := b.cfg.Sender.Send(ctx, ba.batchRequest(&b.cfg))
 		for i, r := range ba.reqs {
 			res := Response{}
 			if resp != nil && i < len(resp.Responses) {
@@ -417,11 +425,14 @@ func (b *batch) rangeID() roachpb.RangeID {
 	return b.reqs[0].rangeID
 }
 
-func (b *batch) batchRequest() roachpb.BatchRequest {
+func (b *batch) batchRequest(cfg *Config) roachpb.BatchRequest {
 	req := roachpb.BatchRequest{
 		// Preallocate the Requests slice.
 		Requests: make([]roachpb.RequestUnion, 0, len(b.reqs)),
 	}
+	if cfg.MaxKeysPerBatchReq > 0 {
+		req.MaxSpanRequestKeys = int64(cfg.MaxKeysPerBatchReq)
+	}
 	for _, r := range b.reqs {
 		req.Add(r.req)
 	}
@@ -235,6 +235,52 @@ func TestBatcherSend(t *testing.T) {
 	}
 }
 
+func TestMaxKeysPerBatchReq(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(context.Background())
+	sc := make(chanSender)
+	b := New(Config{
+		MaxIdle:            50 * time.Millisecond,
+		MaxWait:            50 * time.Millisecond,
+		MaxMsgsPerBatch:    2,
+		MaxKeysPerBatchReq: 5,
+		Sender:             sc,
+		Stopper:            stopper,
+	})
+	span := roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("c")}
+	resumeSpan := roachpb.Span{Key: roachpb.Key("b"), EndKey: roachpb.Key("c")}
+	var g errgroup.Group
+	for i := 0; i < 2; i++ {
+		g.Go(func() error {
+			resp, err := b.Send(context.Background(), 1, &roachpb.ResolveIntentRangeRequest{
+				RequestHeader: roachpb.RequestHeaderFromSpan(span),
+			})
+			if err != nil {
+				return err
+			}
+			if rs := resp.Header().ResumeSpan; rs == nil || !rs.EqualValue(resumeSpan) {
+				return fmt.Errorf("expected resume span %s, got %v", resumeSpan, rs)
+			}
+			return nil
+		})
+	}
+	// The limit is passed to the sender, whose responses hold the resume spans.
+	s := <-sc
+	assert.Len(t, s.ba.Requests, 2)
+	assert.Equal(t, int64(5), s.ba.MaxSpanRequestKeys)
+	br := &roachpb.BatchResponse{}
+	for range s.ba.Requests {
+		resp := &roachpb.ResolveIntentRangeResponse{}
+		resp.ResumeSpan = &resumeSpan
+		br.Add(resp)
+	}
+	s.respChan <- batchResp{br: br}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestSendAfterStopped(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	stopper := stop.NewStopper()
@@ -17,6 +17,7 @@ package client
 import (
 	"context"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -34,6 +35,11 @@ import (
 // Txn is an in-progress distributed database transaction. A Txn is safe for
 // concurrent use by multiple goroutines.
 type Txn struct {
+	// bytesWritten is the number of bytes of the keys and values written by the
+	// requests successfully sent through this transaction. Accessed atomically,
+	// and kept first for alignment.
+	bytesWritten int64
+
 	db *DB
 
 	// typ indicates the type of transaction.
@@ -767,6 +773,32 @@ func (txn *Txn) IsRetryableErrMeantForTxn(
 	return errTxnID == txn.mu.ID
 }
 
+// BytesWritten returns the number of bytes of the keys and values written by
+// the requests successfully sent through this transaction so far, including
+// by the previous epochs of the transaction.
+func (txn *Txn) BytesWritten() int64 {
+	return atomic.LoadInt64(&txn.bytesWritten)
+}
+
+// mutationBytes returns the number of bytes of the keys and values written by
+// the point writes of the batch.
+func mutationBytes(ba *roachpb.BatchRequest) int64 {
+	var n int
+	for _, ru := range ba.Requests {
+		switch r := ru.GetInner().(type) {
+		case *roachpb.PutRequest:
+			n += len(r.Key) + len(r.Value.RawBytes)
+		case *roachpb.ConditionalPutRequest:
+			n += len(r.Key) + len(r.Value.RawBytes)
+		case *roachpb.InitPutRequest:
+			n += len(r.Key) + len(r.Value.RawBytes)
+		case *roachpb.DeleteRequest:
+			n += len(r.Key)
+		}
+	}
+	return int64(n)
+}
+
 // Send runs the specified calls synchronously in a single batch and
 // returns any errors. If the transaction is read-only or has already
 // been successfully committed or aborted, a potential trailing
@@ -791,6 +823,9 @@ func (txn *Txn) Send(
 	txn.mu.Unlock()
 	br, pErr := txn.db.sendUsingSender(ctx, ba, sender)
 	if pErr == nil {
+		if n := mutationBytes(&ba); n != 0 {
+			atomic.AddInt64(&txn.bytesWritten, n)
+		}
 		return br, nil
 	}
 
@@ -914,8 +949,14 @@ func (txn *Txn) replaceSenderIfTxnAbortedLocked(
 	// transaction, even once the proto is reset.
 	txn.recordPreviousTxnIDLocked(txn.mu.ID)
 	txn.mu.ID = newTxn.ID
-	// Create a new txn sender.
+	// Create a new txn sender. The restarts of the aborted transaction count
+	// towards the restarts of the new one.
+	oldMeta, err := txn.mu.sender.GetMeta(ctx, AnyTxnStatus)
+	if err != nil {
+		log.Fatalf(ctx, "unexpected error from GetMeta(AnyTxnStatus): %s", err)
+	}
 	meta := roachpb.MakeTxnCoordMeta(*newTxn)
+	meta.Restarts = oldMeta.Restarts
 	txn.mu.sender = txn.db.factory.TransactionalSender(txn.typ, meta)
 }
 
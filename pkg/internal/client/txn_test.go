@@ -199,6 +199,28 @@ func TestCommitTransactionOnce(t *testing.T) {
 	}
 }
 
+// TestTxnBytesWritten verifies that a transaction counts the bytes of the point
+// writes it sends, but not of its reads.
+func TestTxnBytesWritten(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	clock := hlc.NewClock(hlc.UnixNano, time.Nanosecond)
+	db := NewDB(testutils.MakeAmbientCtx(), newTestTxnFactory(nil), clock)
+	txn := NewTxn(ctx, db, 0 /* gatewayNodeID */, RootTxn)
+
+	b := txn.NewBatch()
+	b.Put("a", "value")
+	b.Del("bb")
+	b.Get("ccc")
+	if err := txn.Run(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	value := roachpb.MakeValueFromString("value")
+	if expected, n := int64(len("a")+len(value.RawBytes)+len("bb")), txn.BytesWritten(); n != expected {
+		t.Errorf("expected %d bytes written, got %d", expected, n)
+	}
+}
+
 // TestAbortMutatingTransaction verifies that transaction is aborted
 // upon failed invocation of the retryable func.
 func TestAbortMutatingTransaction(t *testing.T) {
@@ -30,6 +30,7 @@ var _ Details = RestoreDetails{}
 var _ Details = SchemaChangeDetails{}
 var _ Details = ChangefeedDetails{}
 var _ Details = CreateStatsDetails{}
+var _ Details = ConstraintConformanceDetails{}
 
 // ProgressDetails is a marker interface for job progress details proto structs.
 type ProgressDetails interface{}
@@ -39,6 +40,7 @@ var _ ProgressDetails = RestoreProgress{}
 var _ ProgressDetails = SchemaChangeProgress{}
 var _ ProgressDetails = ChangefeedProgress{}
 var _ ProgressDetails = CreateStatsProgress{}
+var _ ProgressDetails = ConstraintConformanceProgress{}
 
 // Type returns the payload's job type.
 func (p *Payload) Type() Type {
@@ -64,6 +66,8 @@ func DetailsType(d isPayload_Details) Type {
 			return TypeAutoCreateStats
 		}
 		return TypeCreateStats
+	case *Payload_ConstraintConformance:
+		return TypeConstraintConformance
 	default:
 		panic(fmt.Sprintf("Payload.Type called on a payload with an unknown details type: %T", d))
 	}
@@ -90,6 +94,8 @@ func WrapProgressDetails(details ProgressDetails) interface {
 		return &Progress_Changefeed{Changefeed: &d}
 	case CreateStatsProgress:
 		return &Progress_CreateStats{CreateStats: &d}
+	case ConstraintConformanceProgress:
+		return &Progress_ConstraintConformance{ConstraintConformance: &d}
 	default:
 		panic(fmt.Sprintf("WrapProgressDetails: unknown details type %T", d))
 	}
@@ -111,6 +117,8 @@ func (p *Payload) UnwrapDetails() Details {
 		return *d.Changefeed
 	case *Payload_CreateStats:
 		return *d.CreateStats
+	case *Payload_ConstraintConformance:
+		return *d.ConstraintConformance
 	default:
 		return nil
 	}
@@ -132,6 +140,8 @@ func (p *Progress) UnwrapDetails() ProgressDetails {
 		return *d.Changefeed
 	case *Progress_CreateStats:
 		return *d.CreateStats
+	case *Progress_ConstraintConformance:
+		return *d.ConstraintConformance
 	default:
 		return nil
 	}
@@ -166,6 +176,8 @@ func WrapPayloadDetails(details Details) interface {
 		return &Payload_Changefeed{Changefeed: &d}
 	case CreateStatsDetails:
 		return &Payload_CreateStats{CreateStats: &d}
+	case ConstraintConformanceDetails:
+		return &Payload_ConstraintConformance{ConstraintConformance: &d}
 	default:
 		panic(fmt.Sprintf("jobs.WrapPayloadDetails: unknown details type %T", d))
 	}
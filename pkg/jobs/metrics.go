@@ -22,7 +22,8 @@ import (
 
 // Metrics are for production monitoring of each job type.
 type Metrics struct {
-	Changefeed metric.Struct
+	Changefeed            metric.Struct
+	ConstraintConformance *ConstraintConformanceMetrics
 }
 
 // MetricStruct implements the metric.Struct interface.
@@ -30,6 +31,7 @@ func (Metrics) MetricStruct() {}
 
 // InitHooks initializes the metrics for job monitoring.
 func (m *Metrics) InitHooks(histogramWindowInterval time.Duration) {
+	m.ConstraintConformance = makeConstraintConformanceMetrics()
 	if MakeChangefeedMetricsHook != nil {
 		m.Changefeed = MakeChangefeedMetricsHook(histogramWindowInterval)
 	}
@@ -38,3 +40,60 @@ func (m *Metrics) InitHooks(histogramWindowInterval time.Duration) {
 // MakeChangefeedMetricsHook allows for registration of changefeed metrics from
 // ccl code.
 var MakeChangefeedMetricsHook func(time.Duration) metric.Struct
+
+var (
+	metaConstraintConformanceViolations = metric.Metadata{
+		Name:        "jobs.constraint_conformance.violations",
+		Help:        "Number of constraints and lease preferences of zone configs violated by some of their ranges",
+		Measurement: "Violations",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaConstraintConformanceViolatingRanges = metric.Metadata{
+		Name:        "jobs.constraint_conformance.violating_ranges",
+		Help:        "Number of ranges whose replicas violate the constraints of their zone config",
+		Measurement: "Ranges",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaConstraintConformanceLeaseViolatingRanges = metric.Metadata{
+		Name:        "jobs.constraint_conformance.lease_violating_ranges",
+		Help:        "Number of ranges whose lease violates the lease preferences of their zone config",
+		Measurement: "Ranges",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaConstraintConformanceMaxViolationDuration = metric.Metadata{
+		Name:        "jobs.constraint_conformance.max_violation_duration",
+		Help:        "Duration of the oldest ongoing violation of a constraint or lease preference",
+		Measurement: "Duration",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+)
+
+// ConstraintConformanceMetrics are the results of the last check of the
+// constraint conformance job. They are only maintained by the node running the
+// job, and are zero on the other nodes.
+type ConstraintConformanceMetrics struct {
+	Violations           *metric.Gauge
+	ViolatingRanges      *metric.Gauge
+	LeaseViolatingRanges *metric.Gauge
+	MaxViolationDuration *metric.Gauge
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (*ConstraintConformanceMetrics) MetricStruct() {}
+
+func makeConstraintConformanceMetrics() *ConstraintConformanceMetrics {
+	return &ConstraintConformanceMetrics{
+		Violations:           metric.NewGauge(metaConstraintConformanceViolations),
+		ViolatingRanges:      metric.NewGauge(metaConstraintConformanceViolatingRanges),
+		LeaseViolatingRanges: metric.NewGauge(metaConstraintConformanceLeaseViolatingRanges),
+		MaxViolationDuration: metric.NewGauge(metaConstraintConformanceMaxViolationDuration),
+	}
+}
+
+// Reset zeroes the metrics, when the job stops running on this node.
+func (m *ConstraintConformanceMetrics) Reset() {
+	m.Violations.Update(0)
+	m.ViolatingRanges.Update(0)
+	m.LeaseViolatingRanges.Update(0)
+	m.MaxViolationDuration.Update(0)
+}
@@ -112,6 +112,8 @@ var (
 	// LocalRangeFrozenStatusSuffix is the suffix for a frozen status.
 	// No longer used; exists only to reserve the key so we don't use it.
 	LocalRangeFrozenStatusSuffix = []byte("fzn-")
+	// LocalRangeGCHintSuffix is the suffix for the GC hint.
+	LocalRangeGCHintSuffix = []byte("gch-")
 	// LocalRangeLastGCSuffix is the suffix for the last GC.
 	LocalRangeLastGCSuffix = []byte("lgc-")
 	// LocalRangeAppliedStateSuffix is the suffix for the range applied state
@@ -323,6 +325,10 @@ const (
 	LivenessRangesID       = 22
 	RoleMembersTableID     = 23
 	CommentsTableID        = 24
+	UserAuthStateTableID   = 25
+	PinnedPlansTableID     = 26
+	SchedulesTableID       = 27
+	ScheduleRunsTableID    = 28
 
 	// CommentType is type for system.comments
 	DatabaseCommentType = 0
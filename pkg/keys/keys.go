@@ -283,6 +283,12 @@ func RangeLastGCKey(rangeID roachpb.RangeID) roachpb.Key {
 	return MakeRangeIDPrefixBuf(rangeID).RangeLastGCKey()
 }
 
+// RangeGCHintKey returns a system-local key for the GC hint of the range,
+// which is left by the deletions of all of its data.
+func RangeGCHintKey(rangeID roachpb.RangeID) roachpb.Key {
+	return MakeRangeIDPrefixBuf(rangeID).RangeGCHintKey()
+}
+
 // RangeTxnSpanGCThresholdKey returns a system-local key for last used GC
 // threshold on the txn span.
 func RangeTxnSpanGCThresholdKey(rangeID roachpb.RangeID) roachpb.Key {
@@ -930,6 +936,11 @@ func (b RangeIDPrefixBuf) RangeLastGCKey() roachpb.Key {
 	return append(b.replicatedPrefix(), LocalRangeLastGCSuffix...)
 }
 
+// RangeGCHintKey returns a system-local key for the GC hint.
+func (b RangeIDPrefixBuf) RangeGCHintKey() roachpb.Key {
+	return append(b.replicatedPrefix(), LocalRangeGCHintSuffix...)
+}
+
 // RangeTxnSpanGCThresholdKey returns a system-local key for last used GC
 // threshold on the txn span.
 func (b RangeIDPrefixBuf) RangeTxnSpanGCThresholdKey() roachpb.Key {
@@ -159,6 +159,7 @@ var (
 		{name: "RangeTxnSpanGCThreshold", suffix: LocalTxnSpanGCThresholdSuffix},
 		{name: "RangeFrozenStatus", suffix: LocalRangeFrozenStatusSuffix},
 		{name: "RangeLastGC", suffix: LocalRangeLastGCSuffix},
+		{name: "RangeGCHint", suffix: LocalRangeGCHintSuffix},
 	}
 
 	rangeSuffixDict = []struct {
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
@@ -45,6 +46,16 @@ const (
 	opTxnCoordSender = "txn coordinator send"
 )
 
+// priorityEscalationRestarts is the number of restarts after which the
+// priority of a transaction is escalated, so that transactions starved by
+// contention eventually win their conflicts and make progress.
+var priorityEscalationRestarts = settings.RegisterNonNegativeIntSetting(
+	"kv.transaction.priority_escalation_restarts",
+	"if non-zero, the number of restarts after which a transaction is "+
+		"automatically given the highest priority",
+	10,
+)
+
 // txnState represents states relating to whether Begin/EndTxn requests need to
 // be sent.
 //go:generate stringer -type=txnState
@@ -134,6 +145,12 @@ type TxnCoordSender struct {
 		// userPriority is the txn's priority. Used when restarting the transaction.
 		userPriority roachpb.UserPriority
 
+		// restarts counts the restarts of the transaction, including those of
+		// the previous incarnations of the transaction when it was aborted. It
+		// is used to escalate the priority of transactions that keep
+		// restarting.
+		restarts int32
+
 		// onFinishFn is a closure invoked when state changes to done or aborted.
 		onFinishFn func(error)
 	}
@@ -150,7 +167,8 @@ type TxnCoordSender struct {
 	// additional heap allocations necessary.
 	interceptorStack []txnInterceptor
 	interceptorAlloc struct {
-		arr [7]txnInterceptor
+		arr [8]txnInterceptor
+		txnWriteBuffer
 		txnHeartbeater
 		txnSeqNumAllocator
 		txnIntentCollector
@@ -243,6 +261,10 @@ type TxnMetrics struct {
 	AutoRetries *metric.Counter // Auto retries which avoid client-side restarts
 	Durations   *metric.Histogram
 
+	// PriorityEscalations is the number of transactions whose priority was
+	// escalated after repeated restarts.
+	PriorityEscalations *metric.Counter
+
 	// Restarts is the number of times we had to restart the transaction.
 	Restarts *metric.Histogram
 
@@ -286,6 +308,12 @@ var (
 		Measurement: "Retries",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaPriorityEscalationsRates = metric.Metadata{
+		Name:        "txn.priority_escalations",
+		Help:        "Number of KV transactions whose priority was escalated after repeated restarts",
+		Measurement: "KV Transactions",
+		Unit:        metric.Unit_COUNT,
+	}
 	metaDurationsHistograms = metric.Metadata{
 		Name:        "txn.durations",
 		Help:        "KV transaction durations",
@@ -386,6 +414,7 @@ func MakeTxnMetrics(histogramWindow time.Duration) TxnMetrics {
 		Commits1PC:                    metric.NewCounter(metaCommits1PCRates),
 		AutoRetries:                   metric.NewCounter(metaAutoRetriesRates),
 		Durations:                     metric.NewLatency(metaDurationsHistograms, histogramWindow),
+		PriorityEscalations:           metric.NewCounter(metaPriorityEscalationsRates),
 		Restarts:                      metric.NewHistogram(metaRestartsHistogram, histogramWindow, 100, 3),
 		RestartsWriteTooOld:           telemetry.NewCounterWithMetric(metaRestartsWriteTooOld),
 		RestartsWriteTooOldMulti:      telemetry.NewCounterWithMetric(metaRestartsWriteTooOldMulti),
@@ -483,6 +512,9 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 	}
 	// Some interceptors are only needed by roots.
 	if typ == client.RootTxn {
+		tcs.interceptorAlloc.txnWriteBuffer = txnWriteBuffer{
+			st: tcf.st,
+		}
 		tcs.interceptorAlloc.txnHeartbeater.init(
 			&tcs.mu.Mutex,
 			&tcs.mu.txn,
@@ -527,6 +559,12 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 	switch typ {
 	case client.RootTxn:
 		tcs.interceptorAlloc.arr = [...]txnInterceptor{
+			// The write buffer sits at the top of the stack so that buffered
+			// writes are invisible to all other interceptors until they're
+			// flushed. This way, the heartbeater only begins the transaction,
+			// and the sequence number allocator only assigns sequence numbers,
+			// to writes that are actually sent.
+			&tcs.interceptorAlloc.txnWriteBuffer,
 			&tcs.interceptorAlloc.txnHeartbeater,
 			// Various interceptors below rely on sequence number allocation,
 			// so the sequence number allocator is near the top of the stack.
@@ -584,6 +622,7 @@ func (tcf *TxnCoordSenderFactory) TransactionalSender(
 	}
 
 	tcs.augmentMetaLocked(context.TODO(), meta)
+	tcs.mu.restarts = meta.Restarts
 	return tcs
 }
 
@@ -604,8 +643,16 @@ func (tc *TxnCoordSender) GetMeta(
 	tc.mu.Lock()
 	defer tc.mu.Unlock()
 	// Copy mutable state so access is safe for the caller.
+	if opt == client.OnlyPending && tc.mu.txnState == txnPending {
+		// The meta is about to be handed to a leaf transaction, which needs to
+		// be able to see all of the writes performed by the transaction so far.
+		if pErr := tc.flushWriteBufferLocked(ctx); pErr != nil {
+			return roachpb.TxnCoordMeta{}, pErr.GoError()
+		}
+	}
 	var meta roachpb.TxnCoordMeta
 	meta.Txn = tc.mu.txn
+	meta.Restarts = tc.mu.restarts
 	for _, reqInt := range tc.interceptorStack {
 		reqInt.populateMetaLocked(&meta)
 	}
@@ -671,6 +718,18 @@ func (tc *TxnCoordSender) OnFinish(onFinishFn func(error)) {
 	tc.mu.onFinishFn = onFinishFn
 }
 
+// flushWriteBufferLocked sends any writes buffered by the txnWriteBuffer
+// through the rest of the interceptor stack.
+func (tc *TxnCoordSender) flushWriteBufferLocked(ctx context.Context) *roachpb.Error {
+	if tc.interceptorAlloc.txnWriteBuffer.bufferedWritesLen() == 0 {
+		return nil
+	}
+	var ba roachpb.BatchRequest
+	ba.Txn = tc.mu.txn.Clone()
+	br, pErr := tc.interceptorAlloc.txnWriteBuffer.flushLocked(ctx, ba)
+	return tc.updateStateLocked(ctx, ba, br, pErr)
+}
+
 // DisablePipelining is part of the client.TxnSender interface.
 func (tc *TxnCoordSender) DisablePipelining() error {
 	tc.mu.Lock()
@@ -741,7 +800,12 @@ func (tc *TxnCoordSender) Send(
 	}
 
 	if ba.IsSingleEndTransactionRequest() && !tc.interceptorAlloc.txnIntentCollector.haveIntents() {
-		return nil, tc.commitReadOnlyTxnLocked(ctx, ba)
+		// A commit needs to flush any buffered writes, but a rollback can
+		// simply discard them.
+		if tc.interceptorAlloc.txnWriteBuffer.bufferedWritesLen() == 0 ||
+			!ba.Requests[0].GetEndTransaction().Commit {
+			return nil, tc.commitReadOnlyTxnLocked(ctx, ba)
+		}
 	}
 
 	startNs := tc.clock.PhysicalNow()
@@ -988,6 +1052,8 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 	}
 	errTxnID := pErr.GetTxn().ID
 	newTxn := roachpb.PrepareTransactionForRetry(ctx, pErr, tc.mu.userPriority, tc.clock)
+	tc.mu.restarts++
+	tc.maybeEscalatePriorityLocked(ctx, &newTxn)
 
 	// We'll pass a TransactionRetryWithProtoRefreshError up to the next layer.
 	retErr := roachpb.NewTransactionRetryWithProtoRefreshError(
@@ -1017,6 +1083,29 @@ func (tc *TxnCoordSender) handleRetryableErrLocked(
 	return retErr
 }
 
+// maybeEscalatePriorityLocked gives the highest priority to a transaction
+// about to be restarted once it has been restarted
+// kv.transaction.priority_escalation_restarts times. The restarts are counted
+// by tc.mu.restarts rather than by the epoch of newTxn, since a transaction
+// restarted after an abort starts over at epoch zero. Transactions that were
+// explicitly given the lowest priority, or an explicit priority for testing,
+// are left alone.
+func (tc *TxnCoordSender) maybeEscalatePriorityLocked(
+	ctx context.Context, newTxn *roachpb.Transaction,
+) {
+	threshold := priorityEscalationRestarts.Get(&tc.st.SV)
+	if threshold == 0 || int64(tc.mu.restarts) < threshold || tc.mu.userPriority < 0 {
+		return
+	}
+	if newTxn.Priority == enginepb.MaxTxnPriority || newTxn.Priority == enginepb.MinTxnPriority {
+		return
+	}
+	log.VEventf(ctx, 2, "escalating the priority of txn %s after %d restarts",
+		newTxn.ID.Short(), tc.mu.restarts)
+	newTxn.UpgradePriority(enginepb.MaxTxnPriority)
+	tc.metrics.PriorityEscalations.Inc(1)
+}
+
 // updateStateLocked updates the transaction state in both the success and error
 // cases. It also updates retryable errors with the updated transaction for use
 // by client restarts.
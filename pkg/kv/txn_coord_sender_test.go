@@ -851,6 +851,83 @@ func TestTxnCoordSenderTxnUpdatedOnError(t *testing.T) {
 	}
 }
 
+// TestTxnCoordSenderPriorityEscalation verifies that the priority of a
+// transaction is escalated once it has been restarted
+// kv.transaction.priority_escalation_restarts times, whether the restarts
+// bump its epoch or abort it and start a new transaction.
+func TestTxnCoordSenderPriorityEscalation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCases := []struct {
+		name    string
+		err     roachpb.ErrorDetailInterface
+		aborted bool
+	}{
+		{name: "retry", err: &roachpb.TransactionRetryError{}},
+		{name: "abort", err: &roachpb.TransactionAbortedError{}, aborted: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			stopper := stop.NewStopper()
+			defer stopper.Stop(ctx)
+
+			manual := hlc.NewManualClock(123)
+			clock := hlc.NewClock(manual.UnixNano, 20*time.Nanosecond)
+			st := cluster.MakeTestingClusterSettings()
+			priorityEscalationRestarts.Override(&st.SV, 3)
+
+			var senderFn client.SenderFunc = func(
+				_ context.Context, ba roachpb.BatchRequest,
+			) (*roachpb.BatchResponse, *roachpb.Error) {
+				return nil, roachpb.NewErrorWithTxn(tc.err, ba.Txn)
+			}
+			ambient := log.AmbientContext{Tracer: tracing.NewTracer()}
+			metrics := MakeTxnMetrics(metric.TestSampleInterval)
+			tsf := NewTxnCoordSenderFactory(
+				TxnCoordSenderFactoryConfig{
+					AmbientCtx: ambient,
+					Settings:   st,
+					Clock:      clock,
+					Stopper:    stopper,
+					Metrics:    metrics,
+				},
+				senderFn,
+			)
+			db := client.NewDB(ambient, tsf, clock)
+			txn := client.NewTxn(ctx, db, 0 /* gatewayNodeID */, client.RootTxn)
+
+			prevID := txn.Serialize().ID
+			for i := 1; i <= 4; i++ {
+				err := txn.Put(ctx, roachpb.Key("a"), []byte("value"))
+				if _, ok := err.(*roachpb.TransactionRetryWithProtoRefreshError); !ok {
+					t.Fatalf("%d: expected a retryable error, got %v", i, err)
+				}
+				proto := txn.Serialize()
+				if tc.aborted {
+					// An aborted transaction is replaced by a new one, which
+					// starts over at epoch zero.
+					if proto.ID == prevID || proto.Epoch != 0 {
+						t.Fatalf("%d: expected a new transaction at epoch 0, got %s", i, proto)
+					}
+					prevID = proto.ID
+				} else if proto.Epoch != enginepb.TxnEpoch(i) {
+					t.Fatalf("%d: expected epoch %d, got %d", i, i, proto.Epoch)
+				}
+				escalated := proto.Priority == enginepb.MaxTxnPriority
+				if expEscalated := i >= 3; escalated != expEscalated {
+					t.Errorf("%d: expected escalated priority: %t, got priority %d",
+						i, expEscalated, proto.Priority)
+				}
+			}
+			// The escalation is only counted once.
+			if c := metrics.PriorityEscalations.Count(); c != 1 {
+				t.Errorf("expected 1 priority escalation, got %d", c)
+			}
+		})
+	}
+}
+
 // TestTxnMultipleCoord checks that multiple txn coordinators can be
 // used for reads by a single transaction, and their state can be combined.
 func TestTxnMultipleCoord(t *testing.T) {
@@ -0,0 +1,362 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/google/btree"
+)
+
+// The degree of the bufferedWrites btree.
+const txnWriteBufferBtreeDegree = 32
+
+var bufferedWritesEnabled = settings.RegisterBoolSetting(
+	"kv.transaction.write_buffering.enabled",
+	"if enabled, transactional writes are buffered on the gateway until commit "+
+		"or until a read depends on them",
+	false,
+)
+var bufferedWritesMaxBufferSize = settings.RegisterByteSizeSetting(
+	"kv.transaction.write_buffering.max_buffer_size",
+	"maximum number of bytes of keys and values buffered by a transaction "+
+		"before its buffered writes are flushed",
+	1<<20, /* 1 MB */
+)
+
+// txnWriteBuffer is a txnInterceptor that holds on to a transaction's point
+// writes on the gateway instead of sending them to KV as they're issued. The
+// buffered writes are flushed in the same batch as the transaction's
+// EndTransaction(Commit=true) request, which lets most small transactions
+// commit using a single round trip and without leaving intents behind them.
+//
+// Only Put and Delete requests are buffered. Only the last write to each key
+// is retained, so a transaction that writes the same key multiple times lays
+// down a single intent. Writes that must be evaluated to determine their
+// outcome (e.g. ConditionalPut, InitPut or Increment) can't be buffered; they
+// force the buffer to be flushed in front of them, as do:
+// - reads other than Gets that overlap a buffered write,
+// - batches that would push the buffer over its size limit,
+// - batches that carry a key limit, which can't be split up faithfully.
+//
+// Gets for buffered keys are served directly from the buffer, so the
+// transaction reads its own writes without a round trip to KV.
+//
+// The interceptor sits at the top of the interceptor stack, which means that
+// the interceptors beneath it never see a buffered write until the write is
+// flushed. In particular, BeginTransaction requests, sequence numbers and
+// intent spans are all assigned when the writes are flushed.
+//
+// Leaf transactions never see the buffered writes of their root, so the
+// buffer is flushed before a TxnCoordMeta is handed out to a leaf. See
+// TxnCoordSender.GetMeta.
+type txnWriteBuffer struct {
+	st      *cluster.Settings
+	wrapped lockedSender
+
+	bufferedWrites *btree.BTree
+	bwSizeBytes    int64         // byte size of all keys and values in bufferedWrites
+	tmpBW1, tmpBW2 bufferedWrite // avoid allocs
+}
+
+// bufferedWrite is a Put or Delete request held in the txnWriteBuffer. The
+// request and the memory it references are owned by the buffer.
+type bufferedWrite struct {
+	key roachpb.Key
+	req roachpb.Request
+}
+
+// Less implements the btree.Item interface.
+func (a *bufferedWrite) Less(b btree.Item) bool {
+	return a.key.Compare(b.(*bufferedWrite).key) < 0
+}
+
+// size returns the number of bytes accounted for the buffered write.
+func (a *bufferedWrite) size() int64 {
+	size := int64(len(a.key))
+	if put, ok := a.req.(*roachpb.PutRequest); ok {
+		size += int64(len(put.Value.RawBytes))
+	}
+	return size
+}
+
+// SendLocked implements the lockedSender interface.
+func (twb *txnWriteBuffer) SendLocked(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	if rArgs, ok := ba.GetArg(roachpb.EndTransaction); ok {
+		if et := rArgs.(*roachpb.EndTransactionRequest); !et.Commit {
+			// The buffered writes were never sent, so there's nothing for a
+			// rollback to clean up on their behalf.
+			twb.clearLocked()
+			return twb.wrapped.SendLocked(ctx, ba)
+		}
+	}
+
+	enabled := bufferedWritesEnabled.Get(&twb.st.SV)
+	if !enabled && twb.bufferedWritesLen() == 0 {
+		return twb.wrapped.SendLocked(ctx, ba)
+	}
+	if !enabled || twb.needsFlush(ba) {
+		return twb.flushLocked(ctx, ba)
+	}
+	return twb.bufferLocked(ctx, ba)
+}
+
+// needsFlush returns whether the batch can't be evaluated without first
+// flushing the buffered writes.
+func (twb *txnWriteBuffer) needsFlush(ba roachpb.BatchRequest) bool {
+	if ba.MaxSpanRequestKeys != 0 {
+		return twb.bufferedWritesLen() > 0 || ba.IsTransactionWrite()
+	}
+	sizeBytes := twb.bwSizeBytes
+	maxSizeBytes := bufferedWritesMaxBufferSize.Get(&twb.st.SV)
+	for i, ru := range ba.Requests {
+		req := ru.GetInner()
+		switch t := req.(type) {
+		case *roachpb.PutRequest:
+			if t.Inline {
+				return true
+			}
+			sizeBytes += int64(len(t.Key) + len(t.Value.RawBytes))
+			if sizeBytes > maxSizeBytes {
+				return true
+			}
+		case *roachpb.DeleteRequest:
+			sizeBytes += int64(len(t.Key))
+			if sizeBytes > maxSizeBytes {
+				return true
+			}
+		case *roachpb.GetRequest:
+			// Gets are either served from the buffer or sent to KV.
+		default:
+			if roachpb.IsTransactionWrite(req) || !roachpb.IsTransactional(req) {
+				return true
+			}
+			if twb.overlapsBuffered(req.Header().Span()) || overlapsBatchWrites(ba, i, req.Header().Span()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// overlapsBuffered returns whether the span overlaps any buffered write.
+func (twb *txnWriteBuffer) overlapsBuffered(span roachpb.Span) bool {
+	if twb.bufferedWritesLen() == 0 {
+		return false
+	}
+	overlaps := false
+	r := span.AsRange()
+	twb.tmpBW1.key, twb.tmpBW2.key = roachpb.Key(r.Start), roachpb.Key(r.End)
+	twb.bufferedWrites.AscendRange(&twb.tmpBW1, &twb.tmpBW2, func(btree.Item) bool {
+		overlaps = true
+		return false
+	})
+	return overlaps
+}
+
+// overlapsBatchWrites returns whether the span overlaps the key of any Put or
+// Delete in the batch, other than the request at index skip.
+func overlapsBatchWrites(ba roachpb.BatchRequest, skip int, span roachpb.Span) bool {
+	for i, ru := range ba.Requests {
+		if i == skip {
+			continue
+		}
+		switch t := ru.GetInner().(type) {
+		case *roachpb.PutRequest, *roachpb.DeleteRequest:
+			if span.ContainsKey(t.Header().Key) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bufferLocked adds the batch's writes to the buffer, serves the batch's Gets
+// on buffered keys from the buffer and sends the rest of the batch through the
+// wrapped lockedSender. The responses are then reassembled in the order of the
+// original batch.
+func (twb *txnWriteBuffer) bufferLocked(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	br := ba.CreateReply()
+	br.Txn = ba.Txn
+
+	// Indexes in the original batch of the requests that are sent on.
+	var sentIdxs []int
+	sent := ba
+	sent.Requests = nil
+	for i, ru := range ba.Requests {
+		switch t := ru.GetInner().(type) {
+		case *roachpb.PutRequest:
+			put := *t
+			put.Key = append(roachpb.Key(nil), t.Key...)
+			put.Value.RawBytes = append([]byte(nil), t.Value.RawBytes...)
+			twb.insertBufferedWriteLocked(&put)
+		case *roachpb.DeleteRequest:
+			del := *t
+			del.Key = append(roachpb.Key(nil), t.Key...)
+			twb.insertBufferedWriteLocked(&del)
+		case *roachpb.GetRequest:
+			if resp, ok := twb.getLocked(t.Key); ok {
+				br.Responses[i].MustSetInner(resp)
+				break
+			}
+			sentIdxs = append(sentIdxs, i)
+			sent.Add(t)
+		default:
+			sentIdxs = append(sentIdxs, i)
+			sent.Add(t)
+		}
+	}
+	if len(sentIdxs) > 0 {
+		log.VEventf(ctx, 2, "sending %d of %d requests; %d writes buffered",
+			len(sentIdxs), len(ba.Requests), twb.bufferedWritesLen())
+	} else {
+		log.VEventf(ctx, 2, "batch served by write buffer; %d writes buffered",
+			twb.bufferedWritesLen())
+		return br, nil
+	}
+
+	sentBr, pErr := twb.wrapped.SendLocked(ctx, sent)
+	if pErr != nil {
+		if pErr.Index != nil {
+			pErr.Index.Index = int32(sentIdxs[pErr.Index.Index])
+		}
+		return nil, pErr
+	}
+	for j, i := range sentIdxs {
+		br.Responses[i] = sentBr.Responses[j]
+	}
+	sentBr.Responses = br.Responses
+	return sentBr, nil
+}
+
+// getLocked returns the response to a Get on the given key if a write to the
+// key is buffered.
+func (twb *txnWriteBuffer) getLocked(key roachpb.Key) (*roachpb.GetResponse, bool) {
+	if twb.bufferedWritesLen() == 0 {
+		return nil, false
+	}
+	twb.tmpBW1.key = key
+	item := twb.bufferedWrites.Get(&twb.tmpBW1)
+	if item == nil {
+		return nil, false
+	}
+	resp := &roachpb.GetResponse{}
+	if put, ok := item.(*bufferedWrite).req.(*roachpb.PutRequest); ok {
+		value := put.Value
+		value.RawBytes = append([]byte(nil), value.RawBytes...)
+		resp.Value = &value
+	}
+	return resp, true
+}
+
+// flushLocked prepends all buffered writes to the batch and sends it through
+// the wrapped lockedSender. The buffer is only emptied once the batch
+// succeeds. If it fails, the writes are kept so that they are flushed again
+// with the next batch: the transaction may well go on after an error like a
+// ConditionFailedError on one of the client's requests. Errors that restart
+// the transaction discard the writes through epochBumpedLocked.
+func (twb *txnWriteBuffer) flushLocked(
+	ctx context.Context, ba roachpb.BatchRequest,
+) (*roachpb.BatchResponse, *roachpb.Error) {
+	flushed := twb.bufferedWritesLen()
+	if flushed == 0 {
+		return twb.wrapped.SendLocked(ctx, ba)
+	}
+	log.VEventf(ctx, 2, "flushing %d buffered writes", flushed)
+
+	reqs := make([]roachpb.RequestUnion, flushed, flushed+len(ba.Requests))
+	i := 0
+	twb.bufferedWrites.Ascend(func(item btree.Item) bool {
+		reqs[i].MustSetInner(item.(*bufferedWrite).req)
+		i++
+		return true
+	})
+	ba.Requests = append(reqs, ba.Requests...)
+
+	br, pErr := twb.wrapped.SendLocked(ctx, ba)
+	if pErr != nil {
+		// Hide the fact that this interceptor added new requests to the
+		// batch. Errors on the flushed writes themselves can't be attributed
+		// to any of the client's requests.
+		if pErr.Index != nil {
+			if pErr.Index.Index < int32(flushed) {
+				pErr.Index = nil
+			} else {
+				pErr.Index.Index -= int32(flushed)
+			}
+		}
+		return nil, pErr
+	}
+	twb.clearLocked()
+	br.Responses = br.Responses[flushed:]
+	return br, nil
+}
+
+// setWrapped implements the txnInterceptor interface.
+func (twb *txnWriteBuffer) setWrapped(wrapped lockedSender) { twb.wrapped = wrapped }
+
+// populateMetaLocked implements the txnReqInterceptor interface.
+func (twb *txnWriteBuffer) populateMetaLocked(meta *roachpb.TxnCoordMeta) {}
+
+// augmentMetaLocked implements the txnReqInterceptor interface.
+func (twb *txnWriteBuffer) augmentMetaLocked(meta roachpb.TxnCoordMeta) {}
+
+// epochBumpedLocked implements the txnReqInterceptor interface.
+func (twb *txnWriteBuffer) epochBumpedLocked() {
+	twb.clearLocked()
+}
+
+// closeLocked implements the txnReqInterceptor interface.
+func (twb *txnWriteBuffer) closeLocked() {
+	twb.clearLocked()
+}
+
+// bufferedWritesLen returns the number of writes that are buffered.
+func (twb *txnWriteBuffer) bufferedWritesLen() int {
+	if twb.bufferedWrites == nil {
+		return 0
+	}
+	return twb.bufferedWrites.Len()
+}
+
+// insertBufferedWriteLocked inserts the write into the buffer, replacing any
+// earlier write to the same key.
+func (twb *txnWriteBuffer) insertBufferedWriteLocked(req roachpb.Request) {
+	if twb.bufferedWrites == nil {
+		// Lazily initialize btree.
+		twb.bufferedWrites = btree.New(txnWriteBufferBtreeDegree)
+	}
+	w := &bufferedWrite{key: req.Header().Key, req: req}
+	if old := twb.bufferedWrites.ReplaceOrInsert(w); old != nil {
+		twb.bwSizeBytes -= old.(*bufferedWrite).size()
+	}
+	twb.bwSizeBytes += w.size()
+}
+
+// clearLocked discards all buffered writes.
+func (twb *txnWriteBuffer) clearLocked() {
+	if twb.bufferedWrites != nil {
+		twb.bufferedWrites.Clear(true /* addNodesToFreelist */)
+	}
+	twb.bwSizeBytes = 0
+}
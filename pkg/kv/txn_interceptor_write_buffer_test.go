@@ -0,0 +1,396 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package kv
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func makeMockTxnWriteBuffer() (txnWriteBuffer, *mockLockedSender) {
+	st := cluster.MakeTestingClusterSettings()
+	bufferedWritesEnabled.Override(&st.SV, true)
+	mockSender := &mockLockedSender{}
+	return txnWriteBuffer{
+		st:      st,
+		wrapped: mockSender,
+	}, mockSender
+}
+
+func makePutRequest(key roachpb.Key, value string) *roachpb.PutRequest {
+	return &roachpb.PutRequest{
+		RequestHeader: roachpb.RequestHeader{Key: key},
+		Value:         roachpb.MakeValueFromString(value),
+	}
+}
+
+// TestTxnWriteBufferBuffersWrites tests that txnWriteBuffer buffers Puts and
+// Deletes, serves Gets on buffered keys locally, and flushes the buffered
+// writes in front of a committing EndTransaction.
+func TestTxnWriteBufferBuffersWrites(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	twb, mockSender := makeMockTxnWriteBuffer()
+
+	txn := makeTxnProto()
+	keyA, keyB, keyC := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	ba.Add(makePutRequest(keyA, "a1"))
+	ba.Add(makePutRequest(keyB, "b1"))
+	ba.Add(makePutRequest(keyA, "a2"))
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		t.Fatal("unexpected call to wrapped sender")
+		return nil, nil
+	})
+
+	br, pErr := twb.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.Len(t, br.Responses, 3)
+	require.Equal(t, 2, twb.bufferedWritesLen())
+
+	// Gets on buffered keys are served from the buffer while other Gets are
+	// sent to KV.
+	ba.Requests = nil
+	ba.Add(&roachpb.DeleteRequest{RequestHeader: roachpb.RequestHeader{Key: keyB}})
+	ba.Add(&roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: keyA}})
+	ba.Add(&roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: keyB}})
+	ba.Add(&roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: keyC}})
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 1)
+		require.Equal(t, keyC, ba.Requests[0].GetGet().Key)
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		val := roachpb.MakeValueFromString("c0")
+		br.Responses[0].GetGet().Value = &val
+		return br, nil
+	})
+
+	br, pErr = twb.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.Len(t, br.Responses, 4)
+	require.IsType(t, &roachpb.DeleteResponse{}, br.Responses[0].GetInner())
+	valA, err := br.Responses[1].GetGet().Value.GetBytes()
+	require.NoError(t, err)
+	require.Equal(t, []byte("a2"), valA)
+	require.Nil(t, br.Responses[2].GetGet().Value)
+	valC, err := br.Responses[3].GetGet().Value.GetBytes()
+	require.NoError(t, err)
+	require.Equal(t, []byte("c0"), valC)
+	require.Equal(t, 2, twb.bufferedWritesLen())
+
+	// Committing flushes the buffered writes in key order.
+	ba.Requests = nil
+	ba.Add(&roachpb.EndTransactionRequest{Commit: true})
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 3)
+		require.IsType(t, &roachpb.PutRequest{}, ba.Requests[0].GetInner())
+		require.IsType(t, &roachpb.DeleteRequest{}, ba.Requests[1].GetInner())
+		require.IsType(t, &roachpb.EndTransactionRequest{}, ba.Requests[2].GetInner())
+		require.Equal(t, keyA, ba.Requests[0].GetPut().Key)
+		valA, err := ba.Requests[0].GetPut().Value.GetBytes()
+		require.NoError(t, err)
+		require.Equal(t, []byte("a2"), valA)
+		require.Equal(t, keyB, ba.Requests[1].GetDelete().Key)
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		br.Txn.Status = roachpb.COMMITTED
+		return br, nil
+	})
+
+	br, pErr = twb.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.Len(t, br.Responses, 1)
+	require.IsType(t, &roachpb.EndTransactionResponse{}, br.Responses[0].GetInner())
+	require.Equal(t, 0, twb.bufferedWritesLen())
+}
+
+// TestTxnWriteBufferFlushesOnConflict tests that txnWriteBuffer flushes its
+// buffered writes in front of requests that can't be evaluated without them.
+func TestTxnWriteBufferFlushesOnConflict(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	txn := makeTxnProto()
+	keyA, keyB, keyC := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+
+	testCases := []struct {
+		name  string
+		req   roachpb.Request
+		flush bool
+	}{
+		{
+			name:  "non-overlapping scan",
+			req:   &roachpb.ScanRequest{RequestHeader: roachpb.RequestHeader{Key: keyB, EndKey: keyC}},
+			flush: false,
+		},
+		{
+			name:  "overlapping scan",
+			req:   &roachpb.ScanRequest{RequestHeader: roachpb.RequestHeader{Key: keyA, EndKey: keyC}},
+			flush: true,
+		},
+		{
+			name:  "conditional put",
+			req:   &roachpb.ConditionalPutRequest{RequestHeader: roachpb.RequestHeader{Key: keyC}},
+			flush: true,
+		},
+		{
+			name:  "delete range",
+			req:   &roachpb.DeleteRangeRequest{RequestHeader: roachpb.RequestHeader{Key: keyB, EndKey: keyC}},
+			flush: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			twb, mockSender := makeMockTxnWriteBuffer()
+
+			var ba roachpb.BatchRequest
+			ba.Header = roachpb.Header{Txn: &txn}
+			ba.Add(makePutRequest(keyA, "a1"))
+			_, pErr := twb.SendLocked(ctx, ba)
+			require.Nil(t, pErr)
+			require.Equal(t, 1, twb.bufferedWritesLen())
+
+			ba.Requests = nil
+			ba.Add(tc.req)
+
+			mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+				if tc.flush {
+					require.Len(t, ba.Requests, 2)
+					require.IsType(t, &roachpb.PutRequest{}, ba.Requests[0].GetInner())
+				} else {
+					require.Len(t, ba.Requests, 1)
+				}
+				br := ba.CreateReply()
+				br.Txn = ba.Txn
+				return br, nil
+			})
+
+			br, pErr := twb.SendLocked(ctx, ba)
+			require.Nil(t, pErr)
+			require.Len(t, br.Responses, 1)
+			if tc.flush {
+				require.Equal(t, 0, twb.bufferedWritesLen())
+			} else {
+				require.Equal(t, 1, twb.bufferedWritesLen())
+			}
+		})
+	}
+}
+
+// TestTxnWriteBufferErrorIndex tests that txnWriteBuffer hides the requests
+// it adds to or removes from a batch from the index of returned errors.
+func TestTxnWriteBufferErrorIndex(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	twb, mockSender := makeMockTxnWriteBuffer()
+
+	txn := makeTxnProto()
+	keyA, keyB, keyC := roachpb.Key("a"), roachpb.Key("b"), roachpb.Key("c")
+
+	// Requests that aren't buffered are sent on with their error index
+	// translated back to the original batch.
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	ba.Add(makePutRequest(keyA, "a1"))
+	ba.Add(&roachpb.GetRequest{RequestHeader: roachpb.RequestHeader{Key: keyB}})
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 1)
+		pErr := roachpb.NewErrorf("boom")
+		pErr.SetErrorIndex(0)
+		return nil, pErr
+	})
+
+	_, pErr := twb.SendLocked(ctx, ba)
+	require.NotNil(t, pErr)
+	require.NotNil(t, pErr.Index)
+	require.Equal(t, int32(1), pErr.Index.Index)
+
+	// Errors on flushed writes are not attributed to any request, errors on
+	// the client's requests are shifted past the flushed writes.
+	for _, errIdx := range []int32{0, 1} {
+		ba.Requests = nil
+		ba.Add(makePutRequest(keyA, "a1"))
+		_, pErr := twb.SendLocked(ctx, ba)
+		require.Nil(t, pErr)
+		require.Equal(t, 1, twb.bufferedWritesLen())
+
+		ba.Requests = nil
+		ba.Add(&roachpb.ConditionalPutRequest{RequestHeader: roachpb.RequestHeader{Key: keyC}})
+
+		mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+			require.Len(t, ba.Requests, 2)
+			pErr := roachpb.NewErrorf("boom")
+			pErr.SetErrorIndex(errIdx)
+			return nil, pErr
+		})
+
+		_, pErr = twb.SendLocked(ctx, ba)
+		require.NotNil(t, pErr)
+		if errIdx == 0 {
+			require.Nil(t, pErr.Index)
+		} else {
+			require.NotNil(t, pErr.Index)
+			require.Equal(t, int32(0), pErr.Index.Index)
+		}
+		// The writes are kept to be flushed again.
+		require.Equal(t, 1, twb.bufferedWritesLen())
+	}
+}
+
+// TestTxnWriteBufferFlushError tests that txnWriteBuffer keeps its buffered
+// writes when a flush fails, so that a transaction which goes on after the
+// error commits them.
+func TestTxnWriteBufferFlushError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	twb, mockSender := makeMockTxnWriteBuffer()
+
+	txn := makeTxnProto()
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	ba.Add(makePutRequest(keyA, "a1"))
+	_, pErr := twb.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.Equal(t, 1, twb.bufferedWritesLen())
+
+	// The ConditionalPut flushes the buffer, and fails.
+	ba.Requests = nil
+	ba.Add(&roachpb.ConditionalPutRequest{RequestHeader: roachpb.RequestHeader{Key: keyB}})
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 2)
+		require.IsType(t, &roachpb.PutRequest{}, ba.Requests[0].GetInner())
+		require.IsType(t, &roachpb.ConditionalPutRequest{}, ba.Requests[1].GetInner())
+		pErr := roachpb.NewError(&roachpb.ConditionFailedError{})
+		pErr.SetErrorIndex(1)
+		return nil, pErr
+	})
+
+	_, pErr = twb.SendLocked(ctx, ba)
+	require.NotNil(t, pErr)
+	require.IsType(t, &roachpb.ConditionFailedError{}, pErr.GetDetail())
+	require.NotNil(t, pErr.Index)
+	require.Equal(t, int32(0), pErr.Index.Index)
+	require.Equal(t, 1, twb.bufferedWritesLen())
+
+	// The transaction goes on and commits, which flushes the Put.
+	ba.Requests = nil
+	ba.Add(&roachpb.EndTransactionRequest{Commit: true})
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 2)
+		require.IsType(t, &roachpb.PutRequest{}, ba.Requests[0].GetInner())
+		require.Equal(t, keyA, ba.Requests[0].GetPut().Key)
+		require.IsType(t, &roachpb.EndTransactionRequest{}, ba.Requests[1].GetInner())
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		br.Txn.Status = roachpb.COMMITTED
+		return br, nil
+	})
+
+	br, pErr := twb.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.Len(t, br.Responses, 1)
+	require.Equal(t, 0, twb.bufferedWritesLen())
+}
+
+// TestTxnWriteBufferRollback tests that a rollback discards buffered writes.
+func TestTxnWriteBufferRollback(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	twb, mockSender := makeMockTxnWriteBuffer()
+
+	txn := makeTxnProto()
+	keyA := roachpb.Key("a")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	ba.Add(makePutRequest(keyA, "a1"))
+	_, pErr := twb.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.Equal(t, 1, twb.bufferedWritesLen())
+
+	ba.Requests = nil
+	ba.Add(&roachpb.EndTransactionRequest{Commit: false})
+
+	mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+		require.Len(t, ba.Requests, 1)
+		require.IsType(t, &roachpb.EndTransactionRequest{}, ba.Requests[0].GetInner())
+
+		br := ba.CreateReply()
+		br.Txn = ba.Txn
+		br.Txn.Status = roachpb.ABORTED
+		return br, nil
+	})
+
+	_, pErr = twb.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.Equal(t, 0, twb.bufferedWritesLen())
+}
+
+// TestTxnWriteBufferDisabled tests that txnWriteBuffer passes batches through
+// untouched when write buffering is disabled, and that disabling it flushes
+// any writes buffered while it was enabled.
+func TestTxnWriteBufferDisabled(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	twb, mockSender := makeMockTxnWriteBuffer()
+
+	txn := makeTxnProto()
+	keyA, keyB := roachpb.Key("a"), roachpb.Key("b")
+
+	var ba roachpb.BatchRequest
+	ba.Header = roachpb.Header{Txn: &txn}
+	ba.Add(makePutRequest(keyA, "a1"))
+	_, pErr := twb.SendLocked(ctx, ba)
+	require.Nil(t, pErr)
+	require.Equal(t, 1, twb.bufferedWritesLen())
+
+	bufferedWritesEnabled.Override(&twb.st.SV, false)
+
+	for _, expLen := range []int{2, 1} {
+		ba.Requests = nil
+		ba.Add(makePutRequest(keyB, "b1"))
+
+		mockSender.MockSend(func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+			require.Len(t, ba.Requests, expLen)
+
+			br := ba.CreateReply()
+			br.Txn = ba.Txn
+			return br, nil
+		})
+
+		br, pErr := twb.SendLocked(ctx, ba)
+		require.Nil(t, pErr)
+		require.Len(t, br.Responses, 1)
+		require.Equal(t, 0, twb.bufferedWritesLen())
+	}
+}
@@ -355,6 +355,16 @@ func (v Value) EqualData(o Value) bool {
 	return bytes.Equal(v.RawBytes[checksumSize:], o.RawBytes[checksumSize:])
 }
 
+// TagAndDataBytes returns the tag and encoded data of the receiver, i.e. its
+// raw bytes without the checksum header. Since the checksum covers the key,
+// this is suitable to compare values stored under different keys.
+func (v Value) TagAndDataBytes() []byte {
+	if len(v.RawBytes) < headerSize {
+		return nil
+	}
+	return v.RawBytes[tagPos:]
+}
+
 // SetBytes sets the bytes and tag field of the receiver and clears the checksum.
 func (v *Value) SetBytes(b []byte) {
 	v.RawBytes = make([]byte, headerSize+len(b))
@@ -793,6 +803,7 @@ func (meta *TxnCoordMeta) StripRootToLeaf() *TxnCoordMeta {
 	meta.CommandCount = 0
 	meta.RefreshReads = nil
 	meta.RefreshWrites = nil
+	meta.Restarts = 0
 	return meta
 }
 
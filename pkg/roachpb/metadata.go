@@ -345,6 +345,17 @@ func (Locality) Type() string {
 	return "Locality"
 }
 
+// Find returns the value of the tier with the given key, and whether the
+// Locality has such a tier.
+func (l Locality) Find(key string) (value string, ok bool) {
+	for i := range l.Tiers {
+		if l.Tiers[i].Key == key {
+			return l.Tiers[i].Value, true
+		}
+	}
+	return "", false
+}
+
 // Equals returns whether the two Localities are equivalent.
 //
 // Because Locality Tiers are hierarchically ordered, if two Localities contain
@@ -141,6 +141,27 @@ func TestLocalityConversions(t *testing.T) {
 	}
 }
 
+func TestLocalityFind(t *testing.T) {
+	var l Locality
+	if err := l.Set("region=us-east1,zone=us-east1-b"); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		key      string
+		expected string
+		ok       bool
+	}{
+		{"region", "us-east1", true},
+		{"zone", "us-east1-b", true},
+		{"rack", "", false},
+	}
+	for _, tc := range testCases {
+		if value, ok := l.Find(tc.key); value != tc.expected || ok != tc.ok {
+			t.Errorf("%s: expected (%q, %t), got (%q, %t)", tc.key, tc.expected, tc.ok, value, ok)
+		}
+	}
+}
+
 func TestDiversityScore(t *testing.T) {
 	// Keys are not considered for score, just the order, so we don't need to
 	// specify them.
@@ -43,6 +43,45 @@ type RemoteClockMetrics struct {
 // minute old.
 const avgLatencyMeasurementAge = 20.0
 
+// latencyHistorySize is the number of round-trip latency measurements kept for
+// each remote node. With the default heartbeat interval of 3 seconds, this
+// covers the last 5 minutes.
+const latencyHistorySize = 100
+
+// LatencySample is a round-trip latency measurement.
+type LatencySample struct {
+	// Time is the physical time at which the measurement was recorded.
+	Time    time.Time
+	Latency time.Duration
+}
+
+// latencyHistory is a ring buffer of the latest round-trip latency
+// measurements to a remote node.
+type latencyHistory struct {
+	samples [latencyHistorySize]LatencySample
+	// next is the position of the next sample in samples, and n the number of
+	// samples.
+	next, n int
+}
+
+func (h *latencyHistory) add(sample LatencySample) {
+	h.samples[h.next] = sample
+	h.next = (h.next + 1) % latencyHistorySize
+	if h.n < latencyHistorySize {
+		h.n++
+	}
+}
+
+// all returns the samples, oldest first.
+func (h *latencyHistory) all() []LatencySample {
+	res := make([]LatencySample, 0, h.n)
+	start := (h.next - h.n + latencyHistorySize) % latencyHistorySize
+	for i := 0; i < h.n; i++ {
+		res = append(res, h.samples[(start+i)%latencyHistorySize])
+	}
+	return res
+}
+
 var (
 	metaClockOffsetMeanNanos = metric.Metadata{
 		Name:        "clock-offset.meannanos",
@@ -74,6 +113,7 @@ type RemoteClockMonitor struct {
 		syncutil.RWMutex
 		offsets        map[string]RemoteOffset
 		latenciesNanos map[string]ewma.MovingAverage
+		latencyHistory map[string]*latencyHistory
 	}
 
 	metrics RemoteClockMetrics
@@ -89,6 +129,7 @@ func newRemoteClockMonitor(
 	}
 	r.mu.offsets = make(map[string]RemoteOffset)
 	r.mu.latenciesNanos = make(map[string]ewma.MovingAverage)
+	r.mu.latencyHistory = make(map[string]*latencyHistory)
 	if histogramWindowInterval == 0 {
 		histogramWindowInterval = time.Duration(math.MaxInt64)
 	}
@@ -131,6 +172,34 @@ func (r *RemoteClockMonitor) AllLatencies() map[string]time.Duration {
 	return result
 }
 
+// AllLatencyHistories returns a map of the latest round-trip latency
+// measurements to each remote node address, oldest first. At most
+// latencyHistorySize measurements are kept for each address.
+func (r *RemoteClockMonitor) AllLatencyHistories() map[string][]LatencySample {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make(map[string][]LatencySample, len(r.mu.latencyHistory))
+	for addr, h := range r.mu.latencyHistory {
+		result[addr] = h.all()
+	}
+	return result
+}
+
+// AllOffsets returns a map of all current clock offset measurements that are
+// not stale.
+func (r *RemoteClockMonitor) AllOffsets() map[string]RemoteOffset {
+	now := r.clock.PhysicalTime()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := make(map[string]RemoteOffset, len(r.mu.offsets))
+	for addr, offset := range r.mu.offsets {
+		if !offset.isStale(r.offsetTTL, now) {
+			result[addr] = offset
+		}
+	}
+	return result
+}
+
 // UpdateOffset is a thread-safe way to update the remote clock and latency
 // measurements.
 //
@@ -178,6 +247,12 @@ func (r *RemoteClockMonitor) UpdateOffset(
 			r.mu.latenciesNanos[addr] = latencyAvg
 		}
 		latencyAvg.Add(float64(roundTripLatency.Nanoseconds()))
+		h, ok := r.mu.latencyHistory[addr]
+		if !ok {
+			h = &latencyHistory{}
+			r.mu.latencyHistory[addr] = h
+		}
+		h.add(LatencySample{Time: r.clock.PhysicalTime(), Latency: roundTripLatency})
 		r.metrics.LatencyHistogramNanos.RecordValue(roundTripLatency.Nanoseconds())
 	}
 
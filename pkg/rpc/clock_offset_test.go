@@ -229,3 +229,70 @@ func TestLatencies(t *testing.T) {
 		}
 	}
 }
+
+func TestAllOffsets(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	clock := hlc.NewClock(hlc.NewManualClock(123).UnixNano, time.Nanosecond)
+	monitor := newRemoteClockMonitor(clock, time.Hour, 0)
+
+	fresh := RemoteOffset{
+		Offset:      5,
+		Uncertainty: 10,
+		MeasuredAt:  monitor.clock.PhysicalTime().UnixNano(),
+	}
+	stale := RemoteOffset{
+		Offset:      7,
+		Uncertainty: 10,
+		MeasuredAt:  monitor.clock.PhysicalTime().Add(-(monitor.offsetTTL + 1)).UnixNano(),
+	}
+	monitor.UpdateOffset(context.Background(), "fresh", fresh, 0)
+	monitor.UpdateOffset(context.Background(), "stale", stale, 0)
+
+	offsets := monitor.AllOffsets()
+	if len(offsets) != 1 || offsets["fresh"] != fresh {
+		t.Fatalf("expected only the fresh offset %v, got %v", fresh, offsets)
+	}
+}
+
+func TestLatencyHistory(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	manual := hlc.NewManualClock(123)
+	clock := hlc.NewClock(manual.UnixNano, time.Nanosecond)
+	monitor := newRemoteClockMonitor(clock, time.Hour, 0)
+
+	// Measurements without a latency are not recorded.
+	monitor.UpdateOffset(context.Background(), "a", RemoteOffset{}, 0)
+	if h := monitor.AllLatencyHistories(); len(h) != 0 {
+		t.Fatalf("expected no history, got %v", h)
+	}
+
+	// Only the latest latencyHistorySize measurements are kept, oldest first.
+	const n = latencyHistorySize + 5
+	for i := 1; i <= n; i++ {
+		manual.Increment(1)
+		monitor.UpdateOffset(context.Background(), "a", RemoteOffset{}, time.Duration(i))
+	}
+	monitor.UpdateOffset(context.Background(), "b", RemoteOffset{}, 7)
+
+	histories := monitor.AllLatencyHistories()
+	if len(histories) != 2 {
+		t.Fatalf("expected 2 histories, got %v", histories)
+	}
+	a := histories["a"]
+	if len(a) != latencyHistorySize {
+		t.Fatalf("expected %d samples, got %d", latencyHistorySize, len(a))
+	}
+	for i, s := range a {
+		if expected := time.Duration(n - latencyHistorySize + 1 + i); s.Latency != expected {
+			t.Fatalf("%d: expected latency %s, got %s", i, expected, s.Latency)
+		}
+		if i > 0 && !s.Time.After(a[i-1].Time) {
+			t.Fatalf("%d: samples are not ordered by time: %v", i, a)
+		}
+	}
+	if b := histories["b"]; len(b) != 1 || b[0].Latency != 7 {
+		t.Fatalf("unexpected history %v", b)
+	}
+}
@@ -178,7 +178,18 @@ func NewServerWithInterceptor(
 		if err != nil {
 			panic(err)
 		}
-		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		if ctx.ServerTLSOptions != nil {
+			// The configurations returned for the restricted handshakes are
+			// derived from tlsConfig rather than from the copy made by
+			// credentials.NewTLS, so they need to negotiate HTTP/2 too.
+			tlsConfig = tlsConfig.Clone()
+			tlsConfig.NextProtos = append(tlsConfig.NextProtos, "h2")
+			tlsConfig = security.RestrictDynamically(tlsConfig, ctx.ServerTLSOptions)
+		}
+		opts = append(opts, grpc.Creds(trackedCredentials{
+			TransportCredentials: credentials.NewTLS(tlsConfig),
+			registry:             ctx.TLSConns,
+		}))
 	}
 
 	var unaryInterceptor grpc.UnaryServerInterceptor
@@ -363,6 +374,13 @@ type Context struct {
 	heartbeatTimeout  time.Duration
 	HeartbeatCB       func()
 
+	// ServerTLSOptions, if set, returns the restrictions applied to the TLS
+	// handshakes of the RPC server created with NewServer, in addition to
+	// those of base.Config.TLSOptions. It is called for every handshake.
+	ServerTLSOptions func() security.TLSOptions
+	// TLSConns tracks the TLS connections of the RPC servers and clients.
+	TLSConns *TLSConnRegistry
+
 	rpcCompression bool
 
 	localInternalClient roachpb.InternalClient
@@ -399,6 +417,7 @@ func NewContext(
 		},
 		rpcCompression: enableRPCCompression,
 		version:        version,
+		TLSConns:       NewTLSConnRegistry(),
 	}
 	var cancel context.CancelFunc
 	ctx.masterCtx, cancel = context.WithCancel(ambient.AnnotateCtx(context.Background()))
@@ -575,7 +594,10 @@ func (ctx *Context) GRPCDialOptions() ([]grpc.DialOption, error) {
 		if err != nil {
 			return nil, err
 		}
-		dialOpts = append(dialOpts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(trackedCredentials{
+			TransportCredentials: credentials.NewTLS(tlsConfig),
+			registry:             ctx.TLSConns,
+		}))
 	}
 
 	// The limiting factor for lowering the max message size is the fact
@@ -0,0 +1,171 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"sort"
+	"sync"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"google.golang.org/grpc/credentials"
+)
+
+// The kinds of the connections tracked by a TLSConnRegistry.
+const (
+	// TLSConnSQL is a connection of a SQL client.
+	TLSConnSQL = "sql"
+	// TLSConnRPCInbound is a connection accepted by the RPC server.
+	TLSConnRPCInbound = "rpc-inbound"
+	// TLSConnRPCOutbound is a connection to the RPC server of another node.
+	TLSConnRPCOutbound = "rpc-outbound"
+)
+
+// TLSConnInfo describes the parameters negotiated by an active TLS
+// connection.
+type TLSConnInfo struct {
+	// Kind is one of the TLSConn* constants.
+	Kind        string
+	RemoteAddr  string
+	Version     uint16
+	CipherSuite uint16
+}
+
+// TLSConnRegistry keeps track of the active TLS connections of a node, so
+// that the parameters they negotiated can be reported.
+type TLSConnRegistry struct {
+	mu struct {
+		syncutil.Mutex
+		nextID int64
+		conns  map[int64]TLSConnInfo
+	}
+}
+
+// NewTLSConnRegistry creates a TLSConnRegistry.
+func NewTLSConnRegistry() *TLSConnRegistry {
+	r := &TLSConnRegistry{}
+	r.mu.conns = make(map[int64]TLSConnInfo)
+	return r
+}
+
+// Register records an active connection, whose handshake must be complete.
+// The returned function must be called once the connection is closed.
+func (r *TLSConnRegistry) Register(
+	kind string, remoteAddr net.Addr, state tls.ConnectionState,
+) (unregister func()) {
+	info := TLSConnInfo{
+		Kind:        kind,
+		Version:     state.Version,
+		CipherSuite: state.CipherSuite,
+	}
+	if remoteAddr != nil {
+		info.RemoteAddr = remoteAddr.String()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	id := r.mu.nextID
+	r.mu.nextID++
+	r.mu.conns[id] = info
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.mu.conns, id)
+	}
+}
+
+// Conns returns the active connections, in the order in which they were
+// registered.
+func (r *TLSConnRegistry) Conns() []TLSConnInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]int64, 0, len(r.mu.conns))
+	for id := range r.mu.conns {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	res := make([]TLSConnInfo, len(ids))
+	for i, id := range ids {
+		res[i] = r.mu.conns[id]
+	}
+	return res
+}
+
+// trackedCredentials wraps the TLS credentials of the gRPC connections to
+// register them in a TLSConnRegistry.
+type trackedCredentials struct {
+	credentials.TransportCredentials
+	registry *TLSConnRegistry
+}
+
+var _ credentials.TransportCredentials = trackedCredentials{}
+
+// ClientHandshake implements the credentials.TransportCredentials interface.
+func (c trackedCredentials) ClientHandshake(
+	ctx context.Context, authority string, rawConn net.Conn,
+) (net.Conn, credentials.AuthInfo, error) {
+	conn, authInfo, err := c.TransportCredentials.ClientHandshake(ctx, authority, rawConn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.track(TLSConnRPCOutbound, conn, authInfo), authInfo, nil
+}
+
+// ServerHandshake implements the credentials.TransportCredentials interface.
+func (c trackedCredentials) ServerHandshake(
+	rawConn net.Conn,
+) (net.Conn, credentials.AuthInfo, error) {
+	conn, authInfo, err := c.TransportCredentials.ServerHandshake(rawConn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.track(TLSConnRPCInbound, conn, authInfo), authInfo, nil
+}
+
+// Clone implements the credentials.TransportCredentials interface.
+func (c trackedCredentials) Clone() credentials.TransportCredentials {
+	return trackedCredentials{
+		TransportCredentials: c.TransportCredentials.Clone(),
+		registry:             c.registry,
+	}
+}
+
+func (c trackedCredentials) track(
+	kind string, conn net.Conn, authInfo credentials.AuthInfo,
+) net.Conn {
+	tlsInfo, ok := authInfo.(credentials.TLSInfo)
+	if !ok {
+		return conn
+	}
+	return &trackedConn{
+		Conn:       conn,
+		unregister: c.registry.Register(kind, conn.RemoteAddr(), tlsInfo.State),
+	}
+}
+
+// trackedConn is a connection which is unregistered from a TLSConnRegistry
+// when it is closed.
+type trackedConn struct {
+	net.Conn
+	once       sync.Once
+	unregister func()
+}
+
+// Close implements the net.Conn interface.
+func (c *trackedConn) Close() error {
+	c.once.Do(c.unregister)
+	return c.Conn.Close()
+}
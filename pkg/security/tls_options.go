@@ -0,0 +1,182 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// cipherSuiteNames are the names of the cipher suites which can be
+// negotiated, i.e. those in the list of newBaseTLSConfig. The names are
+// those of the IANA registry, as used by the constants of crypto/tls.
+var cipherSuiteNames = map[uint16]string{
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305:    "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305",
+	tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305:  "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA:      "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA:    "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA:      "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA:    "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256:         "TLS_RSA_WITH_AES_128_GCM_SHA256",
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384:         "TLS_RSA_WITH_AES_256_GCM_SHA384",
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA:            "TLS_RSA_WITH_AES_128_CBC_SHA",
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA:            "TLS_RSA_WITH_AES_256_CBC_SHA",
+}
+
+// TLSVersionName returns the name of a TLS version, e.g. "1.2".
+func TLSVersionName(version uint16) string {
+	switch version {
+	case tls.VersionTLS10:
+		return "1.0"
+	case tls.VersionTLS11:
+		return "1.1"
+	}
+	if name, ok := tlsVersionNames[version]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (0x%04x)", version)
+}
+
+// CipherSuiteName returns the name of a cipher suite, e.g.
+// "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256".
+func CipherSuiteName(id uint16) string {
+	if name, ok := cipherSuiteNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown (0x%04x)", id)
+}
+
+// ParseTLSVersion parses the name of a TLS version, as returned by
+// TLSVersionName. The empty string results in 0, which does not restrict
+// the TLS versions.
+func ParseTLSVersion(s string) (uint16, error) {
+	if s == "" {
+		return 0, nil
+	}
+	var supported []string
+	for version, name := range tlsVersionNames {
+		if name == s {
+			return version, nil
+		}
+		supported = append(supported, name)
+	}
+	sort.Strings(supported)
+	return 0, errors.Errorf("unsupported TLS version %q (supported versions: %s)",
+		s, strings.Join(supported, ", "))
+}
+
+// ParseCipherSuites parses a comma-separated list of cipher suite names, as
+// returned by CipherSuiteName. The empty string results in an empty list,
+// which does not restrict the cipher suites.
+func ParseCipherSuites(s string) ([]uint16, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var res []uint16
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		found := false
+		for id, n := range cipherSuiteNames {
+			if strings.EqualFold(n, name) {
+				res = append(res, id)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("unsupported cipher suite %q", name)
+		}
+	}
+	return res, nil
+}
+
+// TLSOptions restricts the TLS versions and cipher suites which can be
+// negotiated by a TLS configuration. The zero value does not restrict
+// anything.
+type TLSOptions struct {
+	// MinVersion, if non-zero, is the minimum TLS version.
+	MinVersion uint16
+	// CipherSuites, if non-empty, are the cipher suites which can be used
+	// with TLS 1.2. The cipher suites of TLS 1.3 are not configurable.
+	CipherSuites []uint16
+}
+
+// IsZero returns true if the options do not restrict anything.
+func (o TLSOptions) IsZero() bool {
+	return o.MinVersion == 0 && len(o.CipherSuites) == 0
+}
+
+// Restrict returns cfg if the options do not restrict anything, and
+// otherwise a copy of cfg which cannot negotiate the TLS versions older than
+// o.MinVersion nor the cipher suites not in o.CipherSuites. The
+// restrictions only ever remove versions and cipher suites accepted by cfg,
+// so that restricting a configuration multiple times results in the
+// intersection of the options.
+func (o TLSOptions) Restrict(cfg *tls.Config) *tls.Config {
+	if cfg == nil || o.IsZero() {
+		return cfg
+	}
+	cfg = cfg.Clone()
+	if o.MinVersion > cfg.MinVersion {
+		cfg.MinVersion = o.MinVersion
+	}
+	if len(o.CipherSuites) > 0 {
+		// The order of the suites of cfg, which determines their preference,
+		// is preserved. Note that suites must not be nil, which would
+		// result in the default suites of crypto/tls rather than in
+		// handshake failures if the intersection is empty.
+		suites := make([]uint16, 0, len(cfg.CipherSuites))
+		for _, id := range cfg.CipherSuites {
+			for _, allowed := range o.CipherSuites {
+				if id == allowed {
+					suites = append(suites, id)
+					break
+				}
+			}
+		}
+		cfg.CipherSuites = suites
+	}
+	return cfg
+}
+
+// RestrictDynamically returns a copy of the server configuration cfg which,
+// for every TLS handshake, is restricted with the options returned by
+// options at the time of the handshake. This allows options which can be
+// changed at runtime, such as cluster settings, to apply to the listeners
+// which were configured only once.
+func RestrictDynamically(cfg *tls.Config, options func() TLSOptions) *tls.Config {
+	if cfg == nil {
+		return nil
+	}
+	base := cfg.Clone()
+	cfg = cfg.Clone()
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		o := options()
+		if o.IsZero() {
+			// Use cfg.
+			return nil, nil
+		}
+		return o.Restrict(base), nil
+	}
+	return cfg
+}
@@ -0,0 +1,100 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package security_test
+
+import (
+	"crypto/tls"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseTLSOptions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	if v, err := security.ParseTLSVersion(""); err != nil || v != 0 {
+		t.Fatalf("expected 0, found %d (%v)", v, err)
+	}
+	if v, err := security.ParseTLSVersion("1.2"); err != nil || v != tls.VersionTLS12 {
+		t.Fatalf("expected TLS 1.2, found %d (%v)", v, err)
+	}
+	if _, err := security.ParseTLSVersion("1.0"); !testutils.IsError(err, `unsupported TLS version "1.0"`) {
+		t.Fatalf("unexpected error %v", err)
+	}
+
+	suites, err := security.ParseCipherSuites(
+		" TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls_rsa_with_aes_256_cbc_sha")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []uint16{
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_AES_256_CBC_SHA,
+	}
+	if !reflect.DeepEqual(expected, suites) {
+		t.Fatalf("expected %v, found %v", expected, suites)
+	}
+	if _, err := security.ParseCipherSuites("TLS_FOO"); !testutils.IsError(err, `unsupported cipher suite "TLS_FOO"`) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
+func TestTLSOptionsRestrict(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	cfg := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+		},
+	}
+
+	if res := (security.TLSOptions{}).Restrict(cfg); res != cfg {
+		t.Fatal("expected empty options to leave the configuration unchanged")
+	}
+
+	res := security.TLSOptions{
+		CipherSuites: []uint16{
+			tls.TLS_RSA_WITH_AES_128_CBC_SHA, tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+		},
+	}.Restrict(cfg)
+	expected := []uint16{
+		tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls.TLS_RSA_WITH_AES_128_CBC_SHA,
+	}
+	if !reflect.DeepEqual(expected, res.CipherSuites) {
+		t.Fatalf("expected %v, found %v", expected, res.CipherSuites)
+	}
+	if len(cfg.CipherSuites) != 3 {
+		t.Fatalf("expected the original configuration to be unchanged, found %v", cfg.CipherSuites)
+	}
+
+	// An empty intersection must not fall back to the default suites.
+	res = security.TLSOptions{
+		CipherSuites: []uint16{tls.TLS_RSA_WITH_AES_256_CBC_SHA},
+	}.Restrict(cfg)
+	if res.CipherSuites == nil || len(res.CipherSuites) != 0 {
+		t.Fatalf("expected an empty non-nil list of cipher suites, found %v", res.CipherSuites)
+	}
+
+	// MinVersion only ever raises the minimum version.
+	res = security.TLSOptions{MinVersion: tls.VersionTLS11}.Restrict(cfg)
+	if res.MinVersion != tls.VersionTLS12 {
+		t.Fatalf("expected TLS 1.2, found %d", res.MinVersion)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !go1.13
+
+// This file exists because TLS 1.3 is only negotiated by default as of go1.13.
+// It holds the TLS versions which can be required with go1.12 and prior and
+// carries the appropriate build constraint.
+
+package security
+
+import "crypto/tls"
+
+// tlsVersionNames are the names of the TLS versions which can be required
+// with TLSOptions.MinVersion. Older versions are never negotiated, see
+// newBaseTLSConfig.
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS12: "1.2",
+}
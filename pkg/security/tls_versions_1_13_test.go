@@ -0,0 +1,41 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build go1.13
+
+package security_test
+
+import (
+	"crypto/tls"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseTLSVersion13(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	v, err := security.ParseTLSVersion("1.3")
+	if err != nil || v != tls.VersionTLS13 {
+		t.Fatalf("expected TLS 1.3, found %d (%v)", v, err)
+	}
+	if name := security.TLSVersionName(v); name != "1.3" {
+		t.Fatalf("expected 1.3, found %s", name)
+	}
+	cfg := security.TLSOptions{MinVersion: v}.Restrict(&tls.Config{MinVersion: tls.VersionTLS12})
+	if cfg.MinVersion != tls.VersionTLS13 {
+		t.Fatalf("expected TLS 1.3, found %d", cfg.MinVersion)
+	}
+}
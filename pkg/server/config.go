@@ -148,6 +148,12 @@ type Config struct {
 	// multiple comma-separated addresses, kept for backward-compatibility.
 	JoinList base.JoinListType
 
+	// SingleNode, if set, causes the server to run a single-node cluster: it
+	// initializes the cluster on its first start, configures it to keep a
+	// single replica of the data, and refuses the gossip connections of
+	// other nodes so that they cannot join it.
+	SingleNode bool
+
 	// RetryOptions controls the retry behavior of the server.
 	RetryOptions retry.Options
 
@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logtags"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
@@ -830,10 +831,20 @@ func (n *Node) recordJoinEvent() {
 	}
 
 	logEventType := sql.EventLogNodeRestart
-	lastUp := n.lastUp
+	var event eventpb.EventPayload = &eventpb.NodeRestart{
+		NodeDescriptor: n.Descriptor,
+		ClusterID:      n.clusterID.Get(),
+		StartedAt:      n.startedAt,
+		LastUp:         n.lastUp,
+	}
 	if n.initialBoot {
 		logEventType = sql.EventLogNodeJoin
-		lastUp = n.startedAt
+		event = &eventpb.NodeJoin{
+			NodeDescriptor: n.Descriptor,
+			ClusterID:      n.clusterID.Get(),
+			StartedAt:      n.startedAt,
+			LastUp:         n.startedAt,
+		}
 	}
 
 	n.stopper.RunWorker(context.Background(), func(bgCtx context.Context) {
@@ -849,12 +860,7 @@ func (n *Node) recordJoinEvent() {
 					logEventType,
 					int32(n.Descriptor.NodeID),
 					int32(n.Descriptor.NodeID),
-					struct {
-						Descriptor roachpb.NodeDescriptor
-						ClusterID  uuid.UUID
-						StartedAt  int64
-						LastUp     int64
-					}{n.Descriptor, n.clusterID.Get(), n.startedAt, lastUp},
+					event,
 				)
 			}); err != nil {
 				log.Warningf(ctx, "%s: unable to log %s event: %s", n, logEventType, err)
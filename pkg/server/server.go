@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/rpc"
 	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/debug"
 	"github.com/cockroachdb/cockroach/pkg/server/goroutinedumper"
 	"github.com/cockroachdb/cockroach/pkg/server/heapprofiler"
@@ -69,6 +70,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/httputil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logtags"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
@@ -114,6 +116,40 @@ var (
 		false,
 	)
 
+	backwardClockJumpPolicy = settings.RegisterEnumSetting(
+		"server.clock.backward_jump_policy",
+		"how to react to backward clock jumps > max_offset/10 (warn: log a warning; "+
+			"pause: block the clock until the wall time catches up; fatal: cause a panic)",
+		"warn",
+		map[int64]string{
+			int64(hlc.BackwardClockJumpWarn):  "warn",
+			int64(hlc.BackwardClockJumpPause): "pause",
+			int64(hlc.BackwardClockJumpFatal): "fatal",
+		},
+	)
+
+	rpcTLSMinVersion = settings.RegisterValidatedStringSetting(
+		"server.rpc.tls.min_version",
+		"the minimum TLS version accepted by the RPC server, in addition to the restrictions "+
+			"of --tls-min-version (example: 1.2)",
+		"",
+		func(_ *settings.Values, s string) error {
+			_, err := security.ParseTLSVersion(s)
+			return err
+		},
+	)
+
+	rpcTLSCipherSuites = settings.RegisterValidatedStringSetting(
+		"server.rpc.tls.cipher_suites",
+		"comma-separated list of the cipher suites accepted by the RPC server, in addition to "+
+			"the restrictions of --tls-cipher-suites (example: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)",
+		"",
+		func(_ *settings.Values, s string) error {
+			_, err := security.ParseCipherSuites(s)
+			return err
+		},
+	)
+
 	persistHLCUpperBoundInterval = settings.RegisterDurationSetting(
 		"server.clock.persist_upper_bound_interval",
 		"the interval between persisting the wall time upper bound of the clock. The clock "+
@@ -254,6 +290,10 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		}
 	}
 
+	s.rpcContext.ServerTLSOptions = func() security.TLSOptions {
+		return rpcTLSOptions(&st.SV)
+	}
+
 	s.grpc = newGRPCServer(s.rpcContext)
 
 	s.gossip = gossip.New(
@@ -266,6 +306,9 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		s.registry,
 		s.cfg.Locality,
 	)
+	if s.cfg.SingleNode {
+		s.gossip.RefuseIncomingConnections("this node runs a single-node cluster")
+	}
 	s.nodeDialer = nodedialer.New(s.rpcContext, gossip.AddressResolver(s.gossip))
 
 	// A custom RetryOptions is created which uses stopper.ShouldQuiesce() as
@@ -618,6 +661,7 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 		VirtualSchemas:          virtualSchemas,
 		HistogramWindowInterval: s.cfg.HistogramWindowInterval(),
 		RangeDescriptorCache:    s.distSender.RangeDescriptorCache(),
+		RootMemoryMonitor:       &rootSQLMemoryMonitor,
 		LeaseHolderCache:        s.distSender.LeaseHolderCache(),
 		TestingKnobs:            sqlExecutorTestingKnobs,
 
@@ -651,7 +695,23 @@ func NewServer(cfg Config, stopper *stop.Stopper) (*Server, error) {
 			loggerCtx, s.cfg.SQLAuditLogDirName, "sql-audit", true /*enableGc*/, true, /*forceSyncWrites*/
 		),
 
+		SessionsLogger: log.NewSecondaryLogger(
+			loggerCtx, s.cfg.SQLAuditLogDirName, "sessions", true /*enableGc*/, true, /*forceSyncWrites*/
+		),
+
+		PrivilegesLogger: log.NewSecondaryLogger(
+			loggerCtx, s.cfg.SQLAuditLogDirName, "privileges", true /*enableGc*/, true, /*forceSyncWrites*/
+		),
+
+		SQLSchemaLogger: log.NewSecondaryLogger(
+			loggerCtx, s.cfg.SQLAuditLogDirName, "sql-schema", true /*enableGc*/, true, /*forceSyncWrites*/
+		),
+
 		QueryCache: querycache.New(s.cfg.SQLQueryCacheSize),
+
+		PinnedPlans: sql.NewPinnedPlanCache(),
+
+		HistoricalResults: sql.NewHistoricalResultCache(st),
 	}
 
 	if sqlSchemaChangerTestingKnobs := s.cfg.TestingKnobs.SQLSchemaChanger; sqlSchemaChangerTestingKnobs != nil {
@@ -858,6 +918,15 @@ func (s *singleListener) Addr() net.Addr {
 	return s.conn.LocalAddr()
 }
 
+// rpcTLSOptions returns the TLS restrictions of the RPC server set through
+// cluster settings. The settings are validated when they are set.
+func rpcTLSOptions(sv *settings.Values) security.TLSOptions {
+	var opts security.TLSOptions
+	opts.MinVersion, _ = security.ParseTLSVersion(rpcTLSMinVersion.Get(sv))
+	opts.CipherSuites, _ = security.ParseCipherSuites(rpcTLSCipherSuites.Get(sv))
+	return opts
+}
+
 // startMonitoringForwardClockJumps starts a background task to monitor forward
 // clock jumps based on a cluster setting
 func (s *Server) startMonitoringForwardClockJumps(ctx context.Context) {
@@ -879,6 +948,17 @@ func (s *Server) startMonitoringForwardClockJumps(ctx context.Context) {
 	log.Info(ctx, "monitoring forward clock jumps based on server.clock.forward_jump_check_enabled")
 }
 
+// startEnforcingBackwardClockJumpPolicy configures the reaction of the clock
+// to backward clock jumps based on a cluster setting.
+func (s *Server) startEnforcingBackwardClockJumpPolicy() {
+	setPolicy := func() {
+		s.clock.SetBackwardClockJumpPolicy(
+			hlc.BackwardClockJumpPolicy(backwardClockJumpPolicy.Get(&s.st.SV)))
+	}
+	backwardClockJumpPolicy.SetOnChange(&s.st.SV, setPolicy)
+	setPolicy()
+}
+
 // ensureClockMonotonicity sleeps till the wall time reaches
 // prevHLCUpperBound. prevHLCUpperBound > 0 implies we need to guarantee HLC
 // monotonicity across server restarts. prevHLCUpperBound is the last
@@ -1087,10 +1167,14 @@ func (s *Server) Start(ctx context.Context) error {
 	if !s.st.Initialized {
 		return errors.New("must pass initialized ClusterSettings")
 	}
+	if s.cfg.SingleNode && len(s.cfg.GossipBootstrapResolvers) > 0 {
+		return errors.New("a single-node cluster cannot join other nodes")
+	}
 	ctx = s.AnnotateCtx(ctx)
 
 	s.startTime = timeutil.Now()
 	s.startMonitoringForwardClockJumps(ctx)
+	s.startEnforcingBackwardClockJumpPolicy()
 
 	uiTLSConfig, err := s.cfg.GetUIServerTLSConfig()
 	if err != nil {
@@ -1422,7 +1506,9 @@ func (s *Server) Start(ctx context.Context) error {
 			return err
 		}
 
-		log.Infof(ctx, "**** add additional nodes by specifying --join=%s", s.cfg.AdvertiseAddr)
+		if !s.cfg.SingleNode {
+			log.Infof(ctx, "**** add additional nodes by specifying --join=%s", s.cfg.AdvertiseAddr)
+		}
 	} else {
 		// We have no existing stores and we've been told to join a cluster. Wait
 		// for the initServer to bootstrap the cluster or connect to an existing
@@ -1618,6 +1704,10 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Start the background thread which runs the constraint conformance job
+	// when it is enabled.
+	sql.StartConstraintConformanceJob(ctx, s.stopper, s.execCfg)
+
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
 	// We have to do this after actually starting up the server to be able to
@@ -1651,8 +1741,20 @@ func (s *Server) Start(ctx context.Context) error {
 		}
 	}
 	log.Infof(ctx, "done ensuring all necessary migrations have run")
+
+	// A new single-node cluster is configured before it serves its first
+	// SQL connection.
+	if doBootstrap && s.cfg.SingleNode {
+		if err := s.configureSingleNodeCluster(ctx); err != nil {
+			return errors.Wrap(err, "failed to configure the single-node cluster")
+		}
+	}
 	close(serveSQL)
 
+	// Start running the scheduled statements, now that system.schedules is
+	// guaranteed to exist.
+	sql.NewStatementScheduler(s.execCfg).Start(ctx, s.stopper)
+
 	log.Info(ctx, "serving sql connections")
 	// Start servicing SQL connections.
 
@@ -1784,6 +1886,31 @@ func (s *Server) bootstrapCluster(ctx context.Context) error {
 	})
 }
 
+// singleNodeZoneConfigStmts configure the zones of a new single-node
+// cluster: the data only has one replica, so that the ranges are not
+// reported as under-replicated, and the GC TTL of the default zone is
+// shortened so that the old versions of the data do not pile up during
+// local development and testing.
+var singleNodeZoneConfigStmts = []string{
+	"ALTER RANGE default CONFIGURE ZONE USING num_replicas = 1, gc.ttlseconds = 600",
+	"ALTER RANGE meta CONFIGURE ZONE USING num_replicas = 1",
+	"ALTER RANGE liveness CONFIGURE ZONE USING num_replicas = 1",
+	"ALTER RANGE system CONFIGURE ZONE USING num_replicas = 1",
+	"ALTER DATABASE system CONFIGURE ZONE USING num_replicas = 1",
+	"ALTER TABLE system.public.jobs CONFIGURE ZONE USING num_replicas = 1",
+}
+
+// configureSingleNodeCluster applies the defaults of single-node clusters
+// to a cluster this node has just bootstrapped.
+func (s *Server) configureSingleNodeCluster(ctx context.Context) error {
+	for _, stmt := range singleNodeZoneConfigStmts {
+		if _, err := s.internalExecutor.Exec(ctx, "single-node-zones", nil /* txn */, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Server) doDrain(
 	ctx context.Context, modes []serverpb.DrainMode, setTo bool,
 ) ([]serverpb.DrainMode, error) {
@@ -1867,8 +1994,10 @@ func (s *Server) Undrain(ctx context.Context, off []serverpb.DrainMode) []server
 func (s *Server) Decommission(ctx context.Context, setTo bool, nodeIDs []roachpb.NodeID) error {
 	eventLogger := sql.MakeEventLogger(s.execCfg)
 	eventType := sql.EventLogNodeDecommissioned
+	var event eventpb.EventPayload = &eventpb.NodeDecommissioned{}
 	if !setTo {
 		eventType = sql.EventLogNodeRecommissioned
+		event = &eventpb.NodeRecommissioned{}
 	}
 	for _, nodeID := range nodeIDs {
 		changeCommitted, err := s.nodeLiveness.SetDecommissioning(ctx, nodeID, setTo)
@@ -1884,7 +2013,7 @@ func (s *Server) Decommission(ctx context.Context, setTo bool, nodeIDs []roachpb
 			// than to slow down future node liveness transactions.
 			if err := s.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
 				return eventLogger.InsertEventRecord(
-					ctx, txn, eventType, int32(nodeID), int32(s.NodeID()), struct{}{},
+					ctx, txn, eventType, int32(nodeID), int32(s.NodeID()), event,
 				)
 			}); err != nil {
 				log.Errorf(ctx, "unable to record %s event for node %d: %s", eventType, nodeID, err)
@@ -32,6 +32,7 @@ import (
 	"regexp"
 	"runtime"
 	"runtime/pprof"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -1620,6 +1621,355 @@ func (s *statusServer) ListSessions(
 	return response, nil
 }
 
+// ListLocalLocks returns the contended locks on the stores of this node,
+// together with the requests waiting for them.
+func (s *statusServer) ListLocalLocks(
+	ctx context.Context, req *serverpb.ListLocksRequest,
+) (*serverpb.ListLocksResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	if !debug.GatewayRemoteAllowed(ctx, s.st) {
+		return nil, remoteDebuggingErr
+	}
+
+	sessionUser, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isSuperUser(ctx, sessionUser) {
+		return nil, grpcstatus.Errorf(
+			codes.PermissionDenied, "client user %q does not have permission to view locks", sessionUser)
+	}
+
+	nodeID := s.gossip.NodeID.Get()
+	response := &serverpb.ListLocksResponse{
+		Locks: make([]serverpb.Lock, 0),
+	}
+	err = s.stores.VisitStores(func(store *storage.Store) error {
+		for _, ck := range store.IntentResolver().ContendedKeys() {
+			lock := serverpb.Lock{
+				NodeID:      nodeID,
+				StoreID:     store.Ident.StoreID,
+				Key:         ck.Key,
+				HolderTxnID: ck.Holder.ID,
+				Waiters:     make([]serverpb.LockWaiter, len(ck.Waiters)),
+			}
+			for i, w := range ck.Waiters {
+				lock.Waiters[i].WaitStart = w.WaitStart
+				if w.Txn != nil {
+					txnID := w.Txn.ID
+					lock.Waiters[i].TxnID = &txnID
+				}
+			}
+			response.Locks = append(response.Locks, lock)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListLocks returns the contended locks on all nodes in the cluster.
+func (s *statusServer) ListLocks(
+	ctx context.Context, req *serverpb.ListLocksRequest,
+) (*serverpb.ListLocksResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	if !debug.GatewayRemoteAllowed(ctx, s.st) {
+		return nil, remoteDebuggingErr
+	}
+
+	ctx = s.AnnotateCtx(ctx)
+
+	response := &serverpb.ListLocksResponse{
+		Locks:  make([]serverpb.Lock, 0),
+		Errors: make([]serverpb.ListLocksError, 0),
+	}
+
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.ListLocalLocks(ctx, req)
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		locks := nodeResp.(*serverpb.ListLocksResponse)
+		response.Locks = append(response.Locks, locks.Locks...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		errResponse := serverpb.ListLocksError{NodeID: nodeID, Message: err.Error()}
+		response.Errors = append(response.Errors, errResponse)
+	}
+
+	if err := s.iterateNodes(ctx, "lock list", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		err := serverpb.ListLocksError{Message: err.Error()}
+		response.Errors = append(response.Errors, err)
+	}
+	return response, nil
+}
+
+// ListLocalDeadlocks returns the deadlocks recently detected on the stores of
+// this node.
+func (s *statusServer) ListLocalDeadlocks(
+	ctx context.Context, req *serverpb.ListDeadlocksRequest,
+) (*serverpb.ListDeadlocksResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	if !debug.GatewayRemoteAllowed(ctx, s.st) {
+		return nil, remoteDebuggingErr
+	}
+
+	sessionUser, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isSuperUser(ctx, sessionUser) {
+		return nil, grpcstatus.Errorf(
+			codes.PermissionDenied, "client user %q does not have permission to view deadlocks", sessionUser)
+	}
+
+	nodeID := s.gossip.NodeID.Get()
+	response := &serverpb.ListDeadlocksResponse{
+		Deadlocks: make([]serverpb.Deadlock, 0),
+	}
+	err = s.stores.VisitStores(func(store *storage.Store) error {
+		for _, d := range store.GetTxnWaitDeadlocks().Deadlocks() {
+			response.Deadlocks = append(response.Deadlocks, serverpb.Deadlock{
+				NodeID:          nodeID,
+				StoreID:         store.Ident.StoreID,
+				DetectedAt:      d.Time,
+				VictimPolicy:    d.Policy.String(),
+				PusherTxnID:     d.Pusher.ID,
+				PusherKey:       d.Pusher.Key,
+				VictimTxnID:     d.Victim.ID,
+				VictimKey:       d.Victim.Key,
+				DependentTxnIDs: d.Dependents,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ListDeadlocks returns the deadlocks recently detected on all nodes in the
+// cluster.
+func (s *statusServer) ListDeadlocks(
+	ctx context.Context, req *serverpb.ListDeadlocksRequest,
+) (*serverpb.ListDeadlocksResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	if !debug.GatewayRemoteAllowed(ctx, s.st) {
+		return nil, remoteDebuggingErr
+	}
+
+	ctx = s.AnnotateCtx(ctx)
+
+	response := &serverpb.ListDeadlocksResponse{
+		Deadlocks: make([]serverpb.Deadlock, 0),
+		Errors:    make([]serverpb.ListDeadlocksError, 0),
+	}
+
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.ListLocalDeadlocks(ctx, req)
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		deadlocks := nodeResp.(*serverpb.ListDeadlocksResponse)
+		response.Deadlocks = append(response.Deadlocks, deadlocks.Deadlocks...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		errResponse := serverpb.ListDeadlocksError{NodeID: nodeID, Message: err.Error()}
+		response.Errors = append(response.Errors, errResponse)
+	}
+
+	if err := s.iterateNodes(ctx, "deadlock list", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		err := serverpb.ListDeadlocksError{Message: err.Error()}
+		response.Errors = append(response.Errors, err)
+	}
+	return response, nil
+}
+
+// ListLocalTraceSpans returns the tracing spans of this node which have not
+// finished yet.
+func (s *statusServer) ListLocalTraceSpans(
+	ctx context.Context, req *serverpb.ListTraceSpansRequest,
+) (*serverpb.ListTraceSpansResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	if !debug.GatewayRemoteAllowed(ctx, s.st) {
+		return nil, remoteDebuggingErr
+	}
+
+	sessionUser, err := userFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !s.isSuperUser(ctx, sessionUser) {
+		return nil, grpcstatus.Errorf(
+			codes.PermissionDenied, "client user %q does not have permission to view trace spans", sessionUser)
+	}
+
+	nodeID := s.gossip.NodeID.Get()
+	response := &serverpb.ListTraceSpansResponse{
+		Spans: make([]serverpb.TraceSpan, 0),
+	}
+	tr, ok := s.AmbientContext.Tracer.(*tracing.Tracer)
+	if !ok {
+		return response, nil
+	}
+	for _, sp := range tr.ActiveSpans() {
+		response.Spans = append(response.Spans, serverpb.TraceSpan{
+			NodeID:       nodeID,
+			TraceID:      sp.TraceID,
+			SpanID:       sp.SpanID,
+			ParentSpanID: sp.ParentSpanID,
+			Operation:    sp.Operation,
+			Start:        sp.StartTime,
+			Tags:         sp.Tags,
+		})
+	}
+	return response, nil
+}
+
+// ListTraceSpans returns the tracing spans which have not finished yet on all
+// nodes in the cluster.
+func (s *statusServer) ListTraceSpans(
+	ctx context.Context, req *serverpb.ListTraceSpansRequest,
+) (*serverpb.ListTraceSpansResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+	if !debug.GatewayRemoteAllowed(ctx, s.st) {
+		return nil, remoteDebuggingErr
+	}
+
+	ctx = s.AnnotateCtx(ctx)
+
+	response := &serverpb.ListTraceSpansResponse{
+		Spans:  make([]serverpb.TraceSpan, 0),
+		Errors: make([]serverpb.ListTraceSpansError, 0),
+	}
+
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.ListLocalTraceSpans(ctx, req)
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		spans := nodeResp.(*serverpb.ListTraceSpansResponse)
+		response.Spans = append(response.Spans, spans.Spans...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		errResponse := serverpb.ListTraceSpansError{NodeID: nodeID, Message: err.Error()}
+		response.Errors = append(response.Errors, errResponse)
+	}
+
+	if err := s.iterateNodes(ctx, "trace span list", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		err := serverpb.ListTraceSpansError{Message: err.Error()}
+		response.Errors = append(response.Errors, err)
+	}
+	return response, nil
+}
+
+// ListLocalNetworkLatencies returns the round-trip latencies measured by this
+// node to its peers.
+func (s *statusServer) ListLocalNetworkLatencies(
+	ctx context.Context, req *serverpb.ListNetworkLatenciesRequest,
+) (*serverpb.ListNetworkLatenciesResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+
+	nodeID := s.gossip.NodeID.Get()
+	response := &serverpb.ListNetworkLatenciesResponse{
+		Latencies: make([]serverpb.NetworkLatency, 0),
+	}
+	remoteClocks := s.rpcCtx.RemoteClocks
+	if remoteClocks == nil {
+		return response, nil
+	}
+
+	// The latencies are measured by address. Map them back to the nodes known
+	// to the liveness.
+	peerIDs := make(map[string]roachpb.NodeID)
+	for peerID := range s.nodeLiveness.GetIsLiveMap() {
+		if addr, err := s.gossip.GetNodeIDAddress(peerID); err == nil {
+			peerIDs[addr.String()] = peerID
+		}
+	}
+	averages := remoteClocks.AllLatencies()
+	for addr, samples := range remoteClocks.AllLatencyHistories() {
+		latency := serverpb.NetworkLatency{
+			NodeID:       nodeID,
+			PeerNodeID:   peerIDs[addr],
+			PeerAddress:  addr,
+			LatencyNanos: averages[addr].Nanoseconds(),
+			History:      make([]serverpb.NetworkLatency_Sample, len(samples)),
+		}
+		for i, sample := range samples {
+			latency.History[i] = serverpb.NetworkLatency_Sample{
+				Time:         sample.Time,
+				LatencyNanos: sample.Latency.Nanoseconds(),
+			}
+		}
+		response.Latencies = append(response.Latencies, latency)
+	}
+	sort.Slice(response.Latencies, func(i, j int) bool {
+		return response.Latencies[i].PeerAddress < response.Latencies[j].PeerAddress
+	})
+	return response, nil
+}
+
+// ListNetworkLatencies returns the round-trip latencies measured by all the
+// nodes in the cluster to their peers.
+func (s *statusServer) ListNetworkLatencies(
+	ctx context.Context, req *serverpb.ListNetworkLatenciesRequest,
+) (*serverpb.ListNetworkLatenciesResponse, error) {
+	ctx = propagateGatewayMetadata(ctx)
+
+	ctx = s.AnnotateCtx(ctx)
+
+	response := &serverpb.ListNetworkLatenciesResponse{
+		Latencies: make([]serverpb.NetworkLatency, 0),
+		Errors:    make([]serverpb.ListNetworkLatenciesError, 0),
+	}
+
+	dialFn := func(ctx context.Context, nodeID roachpb.NodeID) (interface{}, error) {
+		client, err := s.dialNode(ctx, nodeID)
+		return client, err
+	}
+	nodeFn := func(ctx context.Context, client interface{}, _ roachpb.NodeID) (interface{}, error) {
+		status := client.(serverpb.StatusClient)
+		return status.ListLocalNetworkLatencies(ctx, req)
+	}
+	responseFn := func(_ roachpb.NodeID, nodeResp interface{}) {
+		latencies := nodeResp.(*serverpb.ListNetworkLatenciesResponse)
+		response.Latencies = append(response.Latencies, latencies.Latencies...)
+	}
+	errorFn := func(nodeID roachpb.NodeID, err error) {
+		errResponse := serverpb.ListNetworkLatenciesError{NodeID: nodeID, Message: err.Error()}
+		response.Errors = append(response.Errors, errResponse)
+	}
+
+	if err := s.iterateNodes(ctx, "network latency list", dialFn, nodeFn, responseFn, errorFn); err != nil {
+		err := serverpb.ListNetworkLatenciesError{Message: err.Error()}
+		response.Errors = append(response.Errors, err)
+	}
+	sort.Slice(response.Latencies, func(i, j int) bool {
+		a, b := &response.Latencies[i], &response.Latencies[j]
+		if a.NodeID != b.NodeID {
+			return a.NodeID < b.NodeID
+		}
+		return a.PeerAddress < b.PeerAddress
+	})
+	return response, nil
+}
+
 // CancelSession responds to a session cancellation request by canceling the
 // target session's associated context.
 func (s *statusServer) CancelSession(
@@ -366,10 +366,10 @@ func (mr *MetricsRecorder) GetMetricsMetadata() map[string]metric.Metadata {
 	return metrics
 }
 
-// getNetworkActivity produces three maps detailing information about
-// network activity between this node and all other nodes. The maps
-// are incoming throughput, outgoing throughput, and average
-// latency. Throughputs are stored as bytes, and latencies as nanos.
+// getNetworkActivity produces a map detailing information about network
+// activity between this node and all other nodes: incoming throughput,
+// outgoing throughput, average latency and measured clock offset.
+// Throughputs are stored as bytes, and latencies and offsets as nanos.
 func (mr *MetricsRecorder) getNetworkActivity(
 	ctx context.Context,
 ) map[roachpb.NodeID]statuspb.NodeStatus_NetworkActivity {
@@ -379,8 +379,10 @@ func (mr *MetricsRecorder) getNetworkActivity(
 
 		throughputMap := mr.rpcContext.GetStatsMap()
 		var currentAverages map[string]time.Duration
+		var currentOffsets map[string]rpc.RemoteOffset
 		if mr.rpcContext.RemoteClocks != nil {
 			currentAverages = mr.rpcContext.RemoteClocks.AllLatencies()
+			currentOffsets = mr.rpcContext.RemoteClocks.AllOffsets()
 		}
 		for nodeID, entry := range isLiveMap {
 			address, err := mr.gossip.GetNodeIDAddress(nodeID)
@@ -401,6 +403,9 @@ func (mr *MetricsRecorder) getNetworkActivity(
 				if latency, ok := currentAverages[key]; ok {
 					na.Latency = latency.Nanoseconds()
 				}
+				if offset, ok := currentOffsets[key]; ok {
+					na.ClockOffset = offset.Offset
+				}
 			}
 			activity[nodeID] = na
 		}
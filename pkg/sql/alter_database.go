@@ -0,0 +1,156 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type alterDatabaseSetReadModeNode struct {
+	n      *tree.AlterDatabaseSetReadMode
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// AlterDatabaseSetReadMode puts a database in, or out of, read-only mode.
+// Privileges: superuser.
+//   Notes: postgres has no equivalent; it only supports read-only
+//          transactions.
+func (p *planner) AlterDatabaseSetReadMode(
+	ctx context.Context, n *tree.AlterDatabaseSetReadMode,
+) (planNode, error) {
+	if n.Name == "" {
+		return nil, errEmptyDatabaseName
+	}
+
+	if err := p.RequireSuperUser(ctx, "ALTER DATABASE ... SET READ"); err != nil {
+		return nil, err
+	}
+
+	dbDesc, err := p.ResolveUncachedDatabaseByName(ctx, string(n.Name), true /*required*/)
+	if err != nil {
+		return nil, err
+	}
+
+	if dbDesc.ID == keys.SystemDatabaseID {
+		return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"the read mode of the %s database cannot be changed", dbDesc.Name)
+	}
+
+	return &alterDatabaseSetReadModeNode{n: n, dbDesc: dbDesc}, nil
+}
+
+func (n *alterDatabaseSetReadModeNode) startExec(params runParams) error {
+	p := params.p
+	if n.dbDesc.ReadOnly == n.n.ReadOnly {
+		// Noop.
+		return nil
+	}
+
+	n.dbDesc.ReadOnly = n.n.ReadOnly
+	if err := n.dbDesc.Validate(); err != nil {
+		return err
+	}
+
+	descKey := sqlbase.MakeDescMetadataKey(n.dbDesc.ID)
+	descDesc := sqlbase.WrapDescriptor(n.dbDesc)
+	if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+		log.VEventf(params.ctx, 2, "Put %s -> %s", descKey, descDesc)
+	}
+	if err := p.txn.Put(params.ctx, descKey, descDesc); err != nil {
+		return err
+	}
+	p.Tables().addUncommittedDatabaseReadMode(n.dbDesc)
+
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		p.txn,
+		EventLogAlterDatabase,
+		int32(n.dbDesc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		&eventpb.AlterDatabase{
+			DatabaseName: n.n.Name.String(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+		},
+	)
+}
+
+func (n *alterDatabaseSetReadModeNode) Next(runParams) (bool, error) { return false, nil }
+func (n *alterDatabaseSetReadModeNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *alterDatabaseSetReadModeNode) Close(context.Context)        {}
+
+// checkDatabaseWritable returns an error if the given privilege is one that
+// is needed to modify the data or the schema of the given database or table,
+// and the database, or the database containing the table, is in read-only
+// mode.
+func (p *planner) checkDatabaseWritable(
+	ctx context.Context, descriptor sqlbase.DescriptorProto, priv privilege.Kind,
+) error {
+	switch priv {
+	case privilege.CREATE, privilege.DROP, privilege.INSERT, privilege.DELETE, privilege.UPDATE:
+	default:
+		return nil
+	}
+
+	var tableDesc *sqlbase.TableDescriptor
+	var dbID sqlbase.ID
+	switch d := descriptor.(type) {
+	case *sqlbase.DatabaseDescriptor:
+		dbID = d.ID
+	case *sqlbase.TableDescriptor:
+		tableDesc = d
+	case *sqlbase.MutableTableDescriptor:
+		tableDesc = d.TableDesc()
+	case *sqlbase.ImmutableTableDescriptor:
+		tableDesc = d.TableDesc()
+	default:
+		return nil
+	}
+	if tableDesc != nil {
+		if tableDesc.IsVirtualTable() {
+			return nil
+		}
+		dbID = tableDesc.ParentID
+	}
+	if dbID == sqlbase.InvalidID || dbID == keys.SystemDatabaseID {
+		return nil
+	}
+
+	dbDesc, err := p.Tables().getDatabaseDescForReadMode(ctx, p.txn, dbID)
+	if err != nil {
+		return err
+	}
+	if !dbDesc.ReadOnly {
+		return nil
+	}
+	if tableDesc != nil {
+		return pgerror.NewErrorf(pgerror.CodeReadOnlyDatabaseError,
+			"cannot modify %q: database %q is read-only", tableDesc.Name, dbDesc.Name).SetHintf(
+			"use ALTER DATABASE %s SET READ WRITE to make it writable", tree.NameString(dbDesc.Name))
+	}
+	return pgerror.NewErrorf(pgerror.CodeReadOnlyDatabaseError,
+		"cannot modify database %q: it is read-only", dbDesc.Name).SetHintf(
+		"use ALTER DATABASE %s SET READ WRITE to make it writable", tree.NameString(dbDesc.Name))
+}
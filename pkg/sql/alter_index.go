@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/gogo/protobuf/proto"
 )
 
@@ -116,15 +117,14 @@ func (n *alterIndexNode) startExec(params runParams) error {
 		EventLogAlterIndex,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName  string
-			IndexName  string
-			Statement  string
-			User       string
-			MutationID uint32
-		}{
-			n.n.Index.Table.FQString(), n.indexDesc.Name, n.n.String(),
-			params.SessionData().User, uint32(mutationID),
+		&eventpb.AlterIndex{
+			TableName: n.n.Index.Table.FQString(),
+			IndexName: n.indexDesc.Name,
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+			MutationID: uint32(mutationID),
 		},
 	)
 }
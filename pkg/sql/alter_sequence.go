@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type alterSequenceNode struct {
@@ -65,11 +66,13 @@ func (n *alterSequenceNode) startExec(params runParams) error {
 		EventLogAlterSequence,
 		int32(n.seqDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			SequenceName string
-			Statement    string
-			User         string
-		}{n.n.Name.FQString(), n.n.String(), params.SessionData().User},
+		&eventpb.AlterSequence{
+			SequenceName: n.n.Name.FQString(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+		},
 	)
 }
 
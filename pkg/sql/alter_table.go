@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/gogo/protobuf/proto"
 	"golang.org/x/text/language"
@@ -684,14 +685,15 @@ func (n *alterTableNode) startExec(params runParams) error {
 		EventLogAlterTable,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName           string
-			Statement           string
-			User                string
-			MutationID          uint32
-			CascadeDroppedViews []string
-		}{n.n.Table.FQString(), n.n.String(),
-			params.SessionData().User, uint32(mutationID), droppedViews},
+		&eventpb.AlterTable{
+			TableName: n.n.Table.FQString(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+			MutationID:          uint32(mutationID),
+			CascadeDroppedViews: droppedViews,
+		},
 	)
 }
 
@@ -783,6 +785,11 @@ func applyColumnMutation(
 		}
 
 	case *tree.AlterTableSetDefault:
+		if col.IsIdentity() {
+			// The default value of an identity column comes from its sequence.
+			return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"column %q of relation %q is an identity column", col.Name, tableDesc.Name)
+		}
 		if len(col.UsesSequenceIds) > 0 {
 			if err := removeSequenceDependencies(tableDesc, col, params); err != nil {
 				return err
@@ -814,6 +821,10 @@ func applyColumnMutation(
 		}
 
 	case *tree.AlterTableDropNotNull:
+		if col.IsIdentity() {
+			return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"column %q of relation %q is an identity column", col.Name, tableDesc.Name)
+		}
 		col.Nullable = true
 
 	case *tree.AlterTableDropStored:
@@ -821,6 +832,12 @@ func applyColumnMutation(
 			return pgerror.NewErrorf(pgerror.CodeInvalidColumnDefinitionError,
 				"column %q is not a computed column", col.Name)
 		}
+		if col.Virtual {
+			// The values of the column were never stored, so there is nothing
+			// to keep once the expression is dropped.
+			return pgerror.NewErrorf(pgerror.CodeInvalidColumnDefinitionError,
+				"column %q is a virtual computed column", col.Name)
+		}
 		col.ComputeExpr = nil
 	}
 	return nil
@@ -899,10 +916,16 @@ func injectTableStats(
 				return err
 			}
 		}
-		var name interface{}
+		var name, expression, predicate interface{}
 		if s.Name != "" {
 			name = s.Name
 		}
+		if s.Expression != "" {
+			expression = s.Expression
+		}
+		if s.Predicate != "" {
+			predicate = s.Predicate
+		}
 		if _ /* rows */, err := params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
 			params.ctx,
 			"insert-stats",
@@ -915,8 +938,10 @@ func injectTableStats(
 					"rowCount",
 					"distinctCount",
 					"nullCount",
-					histogram
-				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+					histogram,
+					expression,
+					predicate
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
 			desc.ID,
 			name,
 			columnIDs,
@@ -925,6 +950,8 @@ func injectTableStats(
 			s.DistinctCount,
 			s.NullCount,
 			histogram,
+			expression,
+			predicate,
 		); err != nil {
 			return pgerror.Wrapf(err, pgerror.CodeDataExceptionError,
 				"failed to insert stats")
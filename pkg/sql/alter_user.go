@@ -23,7 +23,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 )
 
-// alterUserSetPasswordNode represents an ALTER USER ... WITH PASSWORD statement.
+// alterUserSetPasswordNode represents an ALTER USER ... WITH PASSWORD or an
+// ALTER USER ... VALID UNTIL statement.
 type alterUserSetPasswordNode struct {
 	userAuthInfo
 	ifExists bool
@@ -49,6 +50,12 @@ func (p *planner) AlterUserSetPassword(
 	if err != nil {
 		return nil, err
 	}
+	if n.ValidUntil != nil {
+		ua.validUntil, err = p.TypeAsString(n.ValidUntil, "ALTER USER")
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	return &alterUserSetPasswordNode{
 		userAuthInfo: ua,
@@ -63,7 +70,7 @@ type alterUserSetPasswordRun struct {
 }
 
 func (n *alterUserSetPasswordNode) startExec(params runParams) error {
-	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve()
+	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve(&params.extendedEvalCtx.Settings.SV)
 	if err != nil {
 		return err
 	}
@@ -79,22 +86,59 @@ func (n *alterUserSetPasswordNode) startExec(params runParams) error {
 			"cluster in insecure mode; user cannot use password authentication")
 	}
 
-	n.run.rowsAffected, err = params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
-		params.ctx,
-		"update-user",
-		params.p.txn,
-		`UPDATE system.users SET "hashedPassword" = $2 WHERE username = $1 AND "isRole" = false`,
-		normalizedUsername,
-		hashedPassword,
-	)
-	if err != nil {
-		return err
+	ie := params.extendedEvalCtx.ExecCfg.InternalExecutor
+	if n.password != nil {
+		n.run.rowsAffected, err = ie.Exec(
+			params.ctx,
+			"update-user",
+			params.p.txn,
+			`UPDATE system.users SET "hashedPassword" = $2 WHERE username = $1 AND "isRole" = false`,
+			normalizedUsername,
+			hashedPassword,
+		)
+		if err != nil {
+			return err
+		}
+	} else {
+		// Only the expiration of the password changes; the user must still
+		// exist.
+		row, err := ie.QueryRow(
+			params.ctx,
+			"check-user",
+			params.p.txn,
+			`SELECT 1 FROM system.users WHERE username = $1 AND "isRole" = false`,
+			normalizedUsername,
+		)
+		if err != nil {
+			return err
+		}
+		if row != nil {
+			n.run.rowsAffected = 1
+		}
 	}
-	if n.run.rowsAffected == 0 && !n.ifExists {
-		return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
-			"user %s does not exist", normalizedUsername)
+	if n.run.rowsAffected == 0 {
+		if !n.ifExists {
+			return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+				"user %s does not exist", normalizedUsername)
+		}
+		return nil
+	}
+
+	LogRoleChange(params.ctx, params.extendedEvalCtx.ExecCfg, AuthLogAlterRole, params.p.User(),
+		struct {
+			RoleName          string
+			PasswordChanged   bool
+			ValidUntilChanged bool
+		}{normalizedUsername, n.password != nil, n.validUntil != nil})
+
+	if n.validUntil != nil {
+		validUntil, err := resolveValidUntil(n.validUntil)
+		if err != nil {
+			return err
+		}
+		return setPasswordValidUntil(params.ctx, ie, params.p.txn, normalizedUsername, validUntil)
 	}
-	return err
+	return nil
 }
 
 func (*alterUserSetPasswordNode) Next(runParams) (bool, error) { return false, nil }
@@ -91,6 +91,14 @@ var logicalPlanCollectionPeriod = settings.RegisterNonNegativeDurationSetting(
 	5*time.Minute,
 )
 
+// stmtCPUTimeEnable determines whether to measure the CPU time consumed
+// by each statement.
+var stmtCPUTimeEnable = settings.RegisterBoolSetting(
+	"sql.metrics.statement_details.cpu_time.enabled",
+	"measure the CPU time consumed by each statement; this pins the session to an OS thread while the statement runs",
+	false,
+)
+
 func (s stmtKey) String() string {
 	return s.flags() + s.stmt
 }
@@ -121,6 +129,7 @@ func (a *appStats) recordStatement(
 	numRows int,
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
+	stats topLevelQueryStats,
 ) {
 	if a == nil || !stmtStatsEnable.Get(&a.st.SV) {
 		return
@@ -155,6 +164,12 @@ func (a *appStats) recordStatement(
 	s.data.RunLat.Record(s.data.Count, runLat)
 	s.data.ServiceLat.Record(s.data.Count, svcLat)
 	s.data.OverheadLat.Record(s.data.Count, ovhLat)
+	s.data.BytesRead.Record(s.data.Count, float64(stats.bytesRead))
+	s.data.BytesWritten.Record(s.data.Count, float64(stats.bytesWritten))
+	if stats.cpuTimeMeasured {
+		s.data.CPUTimeCount++
+		s.data.CPUTime.Record(s.data.CPUTimeCount, stats.cpuTime.Seconds())
+	}
 	s.Unlock()
 }
 
@@ -164,15 +179,23 @@ func (a *appStats) getStatsForStmt(
 ) *stmtStats {
 	// Extend the statement key with various characteristics, so
 	// that we use separate buckets for the different situations.
-	key := stmtKey{failed: err != nil, distSQLUsed: distSQLUsed, optUsed: optimizerUsed}
+	key := stmtKey{
+		stmt:        stmtFingerprint(stmt),
+		failed:      err != nil,
+		distSQLUsed: distSQLUsed,
+		optUsed:     optimizerUsed,
+	}
+	return a.getStatsForStmtWithKey(key, createIfNonexistent)
+}
+
+// stmtFingerprint returns the anonymized string under which the
+// statistics of the statement are collected.
+func stmtFingerprint(stmt *Statement) string {
 	if stmt.AnonymizedStr != "" {
 		// Use the cached anonymized string.
-		key.stmt = stmt.AnonymizedStr
-	} else {
-		key.stmt = anonymizeStmt(stmt.AST)
+		return stmt.AnonymizedStr
 	}
-
-	return a.getStatsForStmtWithKey(key, createIfNonexistent)
+	return anonymizeStmt(stmt.AST)
 }
 
 func (a *appStats) getStatsForStmtWithKey(key stmtKey, createIfNonexistent bool) *stmtStats {
@@ -188,8 +211,11 @@ func (a *appStats) getStatsForStmtWithKey(key stmtKey, createIfNonexistent bool)
 	return s
 }
 
+// anonymizeStmt returns the key under which the statistics of a statement
+// are recorded. It must remain the fingerprint of the statement, so that
+// clients can match their statements against the statistics.
 func anonymizeStmt(ast tree.Statement) string {
-	return tree.AsStringWithFlags(ast, tree.FmtHideConstants)
+	return parser.Fingerprint(ast)
 }
 
 // sqlStats carries per-application statistics for all applications on
@@ -202,6 +228,62 @@ type sqlStats struct {
 	lastReset time.Time
 	// apps is the container for all the per-application statistics objects.
 	apps map[string]*appStats
+
+	// roles holds the resources consumed by the statements of each user,
+	// for chargeback reporting. Unlike the per-application statistics,
+	// these are not cleared by resetStats and thus accumulate since the
+	// node started.
+	roles struct {
+		syncutil.Mutex
+		m map[string]*roleStats
+	}
+
+	// plans holds the EXPLAIN ANALYZE plans captured for the slowest
+	// fingerprints; see plan_capture.go. Like the per-role statistics,
+	// they are not cleared by resetStats; they expire instead.
+	plans struct {
+		syncutil.Mutex
+		// requested contains the fingerprints whose plan should be
+		// captured on their next execution.
+		requested map[planCaptureKey]struct{}
+		// captured contains the plans captured for each fingerprint,
+		// oldest first.
+		captured map[planCaptureKey][]capturedPlan
+	}
+}
+
+// roleStats holds the resources consumed by the statements of one user.
+type roleStats struct {
+	syncutil.Mutex
+
+	stmtCount    int64
+	bytesRead    int64
+	bytesWritten int64
+	cpuTime      time.Duration
+}
+
+// record accumulates the resources consumed by one statement.
+func (r *roleStats) record(stats topLevelQueryStats) {
+	r.Lock()
+	r.stmtCount++
+	r.bytesRead += stats.bytesRead
+	r.bytesWritten += stats.bytesWritten
+	r.cpuTime += stats.cpuTime
+	r.Unlock()
+}
+
+func (s *sqlStats) getStatsForRole(user string) *roleStats {
+	s.roles.Lock()
+	defer s.roles.Unlock()
+	if r, ok := s.roles.m[user]; ok {
+		return r
+	}
+	if s.roles.m == nil {
+		s.roles.m = make(map[string]*roleStats)
+	}
+	r := &roleStats{}
+	s.roles.m[user] = r
+	return r
 }
 
 func (s *sqlStats) getStatsForApplication(appName string) *appStats {
@@ -409,6 +491,16 @@ func quantizeCounts(d *roachpb.StatementStatistics) {
 	d.RunLat.SquaredDiffs = (d.RunLat.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
 	d.ServiceLat.SquaredDiffs = (d.ServiceLat.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
 	d.OverheadLat.SquaredDiffs = (d.OverheadLat.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
+	d.BytesRead.SquaredDiffs = (d.BytesRead.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
+	d.BytesWritten.SquaredDiffs = (d.BytesWritten.SquaredDiffs / oldCountMinusOne) * newCountMinusOne
+
+	// The CPU time is only recorded for some of the executions, so it
+	// has its own count.
+	if oldCPUCount := d.CPUTimeCount; oldCPUCount > 0 {
+		newCPUCount := telemetry.Bucket10(oldCPUCount)
+		d.CPUTimeCount = newCPUCount
+		d.CPUTime.SquaredDiffs = (d.CPUTime.SquaredDiffs / float64(oldCPUCount-1)) * float64(newCPUCount-1)
+	}
 
 	d.MaxRetries = telemetry.Bucket10(d.MaxRetries)
 
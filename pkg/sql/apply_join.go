@@ -291,7 +291,16 @@ func (a *applyJoinNode) Next(params runParams) (bool, error) {
 func (a *applyJoinNode) runRightSidePlan(params runParams, plan *planTop) error {
 	a.run.curRightRow = 0
 	a.run.rightRows.Clear(params.ctx)
-	rowResultWriter := NewRowResultWriter(a.run.rightRows)
+	return runPlanInsidePlan(params, plan, a.run.rightRows)
+}
+
+// runPlanInsidePlan runs a planTop as part of the execution of an outer plan,
+// and appends its result rows to rowContainer. The plan is always run locally,
+// in the transaction of the outer plan.
+func runPlanInsidePlan(
+	params runParams, plan *planTop, rowContainer *rowcontainer.RowContainer,
+) error {
+	rowResultWriter := NewRowResultWriter(rowContainer)
 	recv := MakeDistSQLReceiver(
 		params.ctx, rowResultWriter, tree.Rows,
 		params.extendedEvalCtx.ExecCfg.RangeDescriptorCache,
@@ -335,7 +344,6 @@ func (a *applyJoinNode) runRightSidePlan(params runParams, plan *planTop) error
 		return recv.commErr
 	}
 	return rowResultWriter.err
-
 }
 
 func (a *applyJoinNode) Values() tree.Datums {
@@ -0,0 +1,171 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// The events below are written to two dedicated log channels, kept
+// separate from the main log so that they can be retained and shipped for
+// compliance purposes:
+//
+//  - the sessions log (cockroach-sessions.log) receives the connection and
+//    authentication events;
+//  - the privileges log (cockroach-privileges.log) receives the changes to
+//    users, roles, role memberships and privileges.
+//
+// Each category of events is enabled by its own cluster setting. Every
+// entry has the format:
+//
+//   <counter> <event type> <JSON payload>
+//
+// where the counter, added by the secondary logger, makes it possible to
+// detect missing entries.
+
+var logSQLConnections = settings.RegisterBoolSetting(
+	"server.auth_log.sql_connections.enabled",
+	"if set, log SQL client connect and disconnect events to the sessions log "+
+		"(note: may hinder performance on loaded nodes)",
+	false,
+)
+
+var logSQLAuthentication = settings.RegisterBoolSetting(
+	"server.auth_log.sql_sessions.enabled",
+	"if set, log SQL client authentication successes and failures to the sessions log "+
+		"(note: may hinder performance on loaded nodes)",
+	false,
+)
+
+var logRoleChanges = settings.RegisterBoolSetting(
+	"sql.log.role_changes.enabled",
+	"if set, log the creation, modification and removal of users and roles, "+
+		"and the changes to role memberships, to the privileges log",
+	false,
+)
+
+var logPrivilegeChanges = settings.RegisterBoolSetting(
+	"sql.log.privilege_changes.enabled",
+	"if set, log the privileges granted and revoked with GRANT and REVOKE to the privileges log",
+	false,
+)
+
+// AuthLogEventType is the type of an event written to the sessions or
+// privileges log.
+type AuthLogEventType string
+
+const (
+	// AuthLogClientConnectionStart is recorded when a SQL client connects.
+	AuthLogClientConnectionStart AuthLogEventType = "client_connection_start"
+	// AuthLogClientConnectionEnd is recorded when a SQL client connection is
+	// closed.
+	AuthLogClientConnectionEnd AuthLogEventType = "client_connection_end"
+	// AuthLogClientAuthenticationOk is recorded when a SQL client
+	// authenticates successfully.
+	AuthLogClientAuthenticationOk AuthLogEventType = "client_authentication_ok"
+	// AuthLogClientAuthenticationFailed is recorded when a SQL client fails
+	// to authenticate.
+	AuthLogClientAuthenticationFailed AuthLogEventType = "client_authentication_failed"
+
+	// AuthLogCreateRole is recorded when a user or a role is created.
+	AuthLogCreateRole AuthLogEventType = "create_role"
+	// AuthLogAlterRole is recorded when the password of a user is changed.
+	AuthLogAlterRole AuthLogEventType = "alter_role"
+	// AuthLogDropRole is recorded when a user or a role is dropped.
+	AuthLogDropRole AuthLogEventType = "drop_role"
+	// AuthLogGrantRole is recorded when a role is granted to a user or role.
+	AuthLogGrantRole AuthLogEventType = "grant_role"
+	// AuthLogRevokeRole is recorded when a role is revoked from a user or
+	// role.
+	AuthLogRevokeRole AuthLogEventType = "revoke_role"
+	// AuthLogGrantPrivileges is recorded when privileges are granted.
+	AuthLogGrantPrivileges AuthLogEventType = "grant_privileges"
+	// AuthLogRevokePrivileges is recorded when privileges are revoked.
+	AuthLogRevokePrivileges AuthLogEventType = "revoke_privileges"
+)
+
+// LogConnectionEvent records a client connection event to the sessions
+// log, if server.auth_log.sql_connections.enabled is set.
+func LogConnectionEvent(
+	ctx context.Context, execCfg *ExecutorConfig, eventType AuthLogEventType, info interface{},
+) {
+	if !logSQLConnections.Get(&execCfg.Settings.SV) {
+		return
+	}
+	logAuthEvent(ctx, execCfg.SessionsLogger, eventType, info)
+}
+
+// LogAuthenticationEvent records a client authentication event to the
+// sessions log, if server.auth_log.sql_sessions.enabled is set.
+func LogAuthenticationEvent(
+	ctx context.Context, execCfg *ExecutorConfig, eventType AuthLogEventType, info interface{},
+) {
+	if !logSQLAuthentication.Get(&execCfg.Settings.SV) {
+		return
+	}
+	logAuthEvent(ctx, execCfg.SessionsLogger, eventType, info)
+}
+
+// LogRoleChange records a change to a user, a role or a role membership to
+// the privileges log, if sql.log.role_changes.enabled is set. The user
+// performing the change is reported along with info.
+func LogRoleChange(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	eventType AuthLogEventType,
+	user string,
+	info interface{},
+) {
+	if !logRoleChanges.Get(&execCfg.Settings.SV) {
+		return
+	}
+	logAuthEvent(ctx, execCfg.PrivilegesLogger, eventType, authLogChange{User: user, Info: info})
+}
+
+// logPrivilegeChange records a GRANT or a REVOKE to the privileges log, if
+// sql.log.privilege_changes.enabled is set.
+func (p *planner) logPrivilegeChange(
+	ctx context.Context, eventType AuthLogEventType, info interface{},
+) {
+	if !logPrivilegeChanges.Get(&p.ExecCfg().Settings.SV) {
+		return
+	}
+	logAuthEvent(ctx, p.ExecCfg().PrivilegesLogger, eventType,
+		authLogChange{User: p.User(), Info: info})
+}
+
+// authLogChange is the payload of the events of the privileges log.
+type authLogChange struct {
+	// User is the user who performed the change.
+	User string
+	Info interface{}
+}
+
+func logAuthEvent(
+	ctx context.Context, logger *log.SecondaryLogger, eventType AuthLogEventType, info interface{},
+) {
+	payload, err := json.Marshal(info)
+	if err != nil {
+		// The payloads are made of plain structs, so this is not expected;
+		// still, the event must not be lost.
+		log.Warningf(ctx, "unable to encode %s event: %v", eventType, err)
+		payload = []byte("{}")
+	}
+	logger.Logf(ctx, "%s %s", eventType, payload)
+}
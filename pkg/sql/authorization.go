@@ -82,6 +82,12 @@ func (p *planner) CheckPrivilege(
 	// permission check).
 	p.maybeAudit(descriptor, privilege)
 
+	// Statements that modify a read-only database or its tables are rejected
+	// here for the same reason, whatever the privileges of the user.
+	if err := p.checkDatabaseWritable(ctx, descriptor, privilege); err != nil {
+		return err
+	}
+
 	user := p.SessionData().User
 	privs := descriptor.GetPrivileges()
 
@@ -113,6 +119,37 @@ func (p *planner) CheckPrivilege(
 		user, privilege, descriptor.TypeName(), descriptor.GetName())
 }
 
+// CheckColumnPrivilege verifies that the current user has been granted the
+// given privilege on a column of a table. Privileges granted on the table as a
+// whole are not considered; callers are expected to check those first.
+func (p *planner) CheckColumnPrivilege(
+	ctx context.Context,
+	table *sqlbase.TableDescriptor,
+	col *sqlbase.ColumnDescriptor,
+	privilege privilege.Kind,
+) error {
+	user := p.SessionData().User
+	if privs := col.Privileges; privs != nil {
+		if privs.CheckPrivilege(user, privilege) || privs.CheckPrivilege(sqlbase.PublicRole, privilege) {
+			return nil
+		}
+
+		memberOf, err := p.MemberOfWithAdminOption(ctx, user)
+		if err != nil {
+			return err
+		}
+		for role := range memberOf {
+			if privs.CheckPrivilege(role, privilege) {
+				return nil
+			}
+		}
+	}
+
+	return pgerror.NewErrorf(pgerror.CodeInsufficientPrivilegeError,
+		"user %s does not have %s privilege on column %s of relation %s",
+		user, privilege, col.Name, table.GetName())
+}
+
 // CheckAnyPrivilege implements the AuthorizationAccessor interface.
 func (p *planner) CheckAnyPrivilege(ctx context.Context, descriptor sqlbase.DescriptorProto) error {
 	user := p.SessionData().User
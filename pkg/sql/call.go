@@ -0,0 +1,168 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/plpgsql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// routineMaxDepth is the maximum nesting depth of procedure and function
+// calls: the statements executed by a routine can call other routines, or the
+// same routine again.
+const routineMaxDepth = 32
+
+// routineDepthKey is the context key under which the current nesting depth of
+// procedure and function calls is stored.
+type routineDepthKey struct{}
+
+type callNode struct {
+	n       *tree.Call
+	dbDesc  *sqlbase.DatabaseDescriptor
+	routine *plpgsql.Routine
+	args    []tree.TypedExpr
+}
+
+// Call invokes a procedure. The statements of the procedure are executed
+// as the current user, with the database of the procedure as the current
+// database.
+// Privileges: any privilege on the database of the procedure.
+//   notes: postgres requires EXECUTE on the procedure.
+func (p *planner) Call(ctx context.Context, n *tree.Call) (planNode, error) {
+	dbDesc, desc, _, err := p.resolveProcedure(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedFunctionError,
+			"procedure %q does not exist", n.Name.Table())
+	}
+
+	if err := p.CheckAnyPrivilege(ctx, dbDesc); err != nil {
+		return nil, err
+	}
+
+	if len(n.Args) != len(desc.Params) {
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedFunctionError,
+			"procedure %q expects %d arguments, got %d", desc.Name, len(desc.Params), len(n.Args))
+	}
+
+	routine, err := makeRoutine(desc)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]tree.TypedExpr, len(n.Args))
+	for i := range desc.Params {
+		// The arguments are cast to the types of the parameters when the
+		// procedure is invoked, so they only need to be of a type that can be
+		// cast.
+		args[i], err = p.analyzeExpr(
+			ctx, n.Args[i], nil, tree.IndexedVarHelper{}, desc.Params[i].Type.ToDatumType(), false, "CALL")
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &callNode{n: n, dbDesc: dbDesc, routine: routine, args: args}, nil
+}
+
+func (n *callNode) startExec(params runParams) error {
+	args := make(tree.Datums, len(n.args))
+	for i, arg := range n.args {
+		d, err := arg.Eval(params.EvalContext())
+		if err != nil {
+			return err
+		}
+		args[i] = d
+	}
+
+	ctx, err := enterRoutine(params.ctx, "procedure")
+	if err != nil {
+		return err
+	}
+	ie := routineInternalExecutor(params.p, n.dbDesc.Name)
+	_, err = plpgsql.Exec(ctx, params.EvalContext(), ie, params.p.txn, n.routine, args)
+	return err
+}
+
+func (n *callNode) Next(runParams) (bool, error) { return false, nil }
+func (n *callNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *callNode) Close(context.Context)        {}
+
+// makeRoutine returns the routine that the plpgsql interpreter executes for a
+// procedure or a function, after parsing its body.
+func makeRoutine(desc *sqlbase.RoutineDescriptor) (*plpgsql.Routine, error) {
+	routine := &plpgsql.Routine{Name: desc.Name}
+	language := tree.FunctionLangSQL
+	if desc.Language == sqlbase.RoutineDescriptor_PLPGSQL {
+		language = tree.FunctionLangPLpgSQL
+	}
+	var err error
+	switch {
+	case desc.Kind == sqlbase.RoutineDescriptor_PROCEDURE:
+		routine.Body, err = parser.ParseProcedureBody(language, desc.Body)
+	case language == tree.FunctionLangSQL:
+		routine.SQLBody, err = parser.ParseSQLFunctionBody(desc.Body)
+	default:
+		routine.Body, err = parser.ParseRoutineBody(desc.Body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if desc.Kind == sqlbase.RoutineDescriptor_FUNCTION {
+		if routine.ReturnType, err = parser.ParseType(desc.ReturnType.SQLString()); err != nil {
+			return nil, err
+		}
+	}
+	for i := range desc.Params {
+		param := &desc.Params[i]
+		typ, err := parser.ParseType(param.Type.SQLString())
+		if err != nil {
+			return nil, err
+		}
+		routine.Params = append(routine.Params, plpgsql.Param{Name: param.Name, Type: typ})
+	}
+	return routine, nil
+}
+
+// enterRoutine returns the context in which the statements of a procedure or
+// a function are executed, which records the nesting depth of routine calls.
+func enterRoutine(ctx context.Context, kind string) (context.Context, error) {
+	depth, _ := ctx.Value(routineDepthKey{}).(int)
+	if depth >= routineMaxDepth {
+		return nil, pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"%s call nesting depth exceeds the maximum of %d", kind, routineMaxDepth)
+	}
+	return context.WithValue(ctx, routineDepthKey{}, depth+1), nil
+}
+
+// routineInternalExecutor returns an executor for the SQL statements in the
+// body of a trigger, a procedure or a function. The statements are executed
+// as the current user, with the given database as the current database.
+func routineInternalExecutor(p *planner, database string) *SessionBoundInternalExecutor {
+	sd := *p.SessionData()
+	sd.Database = database
+	ie := p.EvalContext().InternalExecutor.(*SessionBoundInternalExecutor)
+	res := &SessionBoundInternalExecutor{impl: ie.impl}
+	res.impl.sessionData = &sd
+	// Let the statements see the schema changes made by the transaction.
+	res.impl.tcModifier = p.Tables()
+	return res
+}
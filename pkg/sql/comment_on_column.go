@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type commentOnColumnNode struct {
@@ -79,24 +80,26 @@ func (n *commentOnColumnNode) startExec(params runParams) error {
 		}
 	}
 
+	event := &eventpb.CommentOnColumn{
+		TableName:  n.tableDesc.Name,
+		ColumnName: string(n.n.ColumnItem.ColumnName),
+		CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+			Statement: n.n.String(),
+			User:      params.SessionData().User,
+		},
+	}
+	if n.n.Comment != nil {
+		event.Comment = *n.n.Comment
+	} else {
+		event.NullComment = true
+	}
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
 		params.p.txn,
 		EventLogCommentOnColumn,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName  string
-			ColumnName string
-			Statement  string
-			User       string
-			Comment    *string
-		}{
-			n.tableDesc.Name,
-			string(n.n.ColumnItem.ColumnName),
-			n.n.String(),
-			params.SessionData().User,
-			n.n.Comment},
+		event,
 	)
 }
 
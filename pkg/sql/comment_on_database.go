@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type commentOnDatabaseNode struct {
@@ -72,22 +73,25 @@ func (n *commentOnDatabaseNode) startExec(params runParams) error {
 		}
 	}
 
+	event := &eventpb.CommentOnDatabase{
+		DatabaseName: n.n.Name.String(),
+		CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+			Statement: n.n.String(),
+			User:      params.SessionData().User,
+		},
+	}
+	if n.n.Comment != nil {
+		event.Comment = *n.n.Comment
+	} else {
+		event.NullComment = true
+	}
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
 		params.p.txn,
 		EventLogCommentOnDatabase,
 		int32(n.dbDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			DatabaseName string
-			Statement    string
-			User         string
-			Comment      *string
-		}{
-			n.n.Name.String(),
-			n.n.String(),
-			params.SessionData().User,
-			n.n.Comment},
+		event,
 	)
 }
 
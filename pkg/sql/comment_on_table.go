@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type commentOnTableNode struct {
@@ -70,22 +71,25 @@ func (n *commentOnTableNode) startExec(params runParams) error {
 		}
 	}
 
+	event := &eventpb.CommentOnTable{
+		TableName: n.n.Table.FQString(),
+		CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+			Statement: n.n.String(),
+			User:      params.SessionData().User,
+		},
+	}
+	if n.n.Comment != nil {
+		event.Comment = *n.n.Comment
+	} else {
+		event.NullComment = true
+	}
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
 		params.p.txn,
 		EventLogCommentOnTable,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName string
-			Statement string
-			User      string
-			Comment   *string
-		}{
-			n.n.Table.FQString(),
-			n.n.String(),
-			params.SessionData().User,
-			n.n.Comment},
+		event,
 	)
 }
 
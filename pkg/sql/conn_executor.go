@@ -324,6 +324,7 @@ func (s *Server) Start(ctx context.Context, stopper *stop.Stopper) {
 		}
 	})
 	s.PeriodicallyClearStmtStats(ctx, stopper)
+	s.periodicallySelectPlansToCapture(ctx, stopper)
 }
 
 // ResetStatementStats resets the executor's collected statement statistics.
@@ -517,9 +518,10 @@ func (s *Server) newConnExecutor(
 			nodeID: s.cfg.NodeID.Get(),
 			clock:  s.cfg.Clock,
 			// Future transaction's monitors will inherits from sessionRootMon.
-			connMon:  &sessionRootMon,
-			tracer:   s.cfg.AmbientCtx.Tracer,
-			settings: s.cfg.Settings,
+			connMon:     &sessionRootMon,
+			tracer:      s.cfg.AmbientCtx.Tracer,
+			sessionData: sd,
+			settings:    s.cfg.Settings,
 		},
 		parallelizeQueue: MakeParallelizeQueue(NewSpanBasedDependencyAnalyzer()),
 		memMetrics:       memMetrics,
@@ -1391,8 +1393,13 @@ func (ex *connExecutor) execCmd(ctx context.Context) error {
 		// command and only sends Syncs once it received some data. But we ignore
 		// flush commands (just like we ignore any other commands) when skipping
 		// to the next batch.
-		if err := ex.clientComm.Flush(pos); err != nil {
-			return err
+		// If the client pipelined the rest of the batch, though, there's no need
+		// to flush now: the Sync ending it has been received already, and it'll
+		// flush the results as soon as the commands before it are skipped.
+		if !ex.stmtBuf.batchEndBuffered() {
+			if err := ex.clientComm.Flush(pos); err != nil {
+				return err
+			}
 		}
 		if err := ex.stmtBuf.seekToNextBatch(); err != nil {
 			return err
@@ -2055,6 +2062,8 @@ func (ex *connExecutor) txnStateTransitionsApplyWrapper(
 
 		// Wait for the cache to reflect the dropped databases if any.
 		ex.extraTxnState.tables.waitForCacheToDropDatabases(ex.Ctx())
+		// Wait for the cache to reflect the new read mode of databases if any.
+		ex.extraTxnState.tables.waitForCacheToUpdateDatabaseReadModes(ex.Ctx())
 
 		fallthrough
 	case txnRestart, txnAborted:
@@ -324,8 +324,9 @@ func (ex *connExecutor) execStmtInOpenState(
 					// TODO(radu): it would be nice if the parser would figure out this
 					// string and store it in tree.Prepare.
 					SQL:             tree.AsStringWithFlags(s.Statement, tree.FmtParsable),
-					AST:             s.Statement,
-					NumPlaceholders: stmt.NumPlaceholders,
+					AST:              s.Statement,
+					NumPlaceholders:  stmt.NumPlaceholders,
+					PlaceholderNames: stmt.PlaceholderNames,
 				},
 			},
 			typeHints,
@@ -755,6 +756,13 @@ func (ex *connExecutor) execStmtInParallel(
 	if err := ex.parallelizeQueue.Add(params, func() error {
 		res := &bufferedCommandResult{errOnly: true}
 
+		// The writes of the transaction are not attributed to the
+		// statement, since the transaction is shared with the other
+		// parallel statements.
+		var tracker stmtResourceTracker
+		tracker.start(ex.server.cfg.Settings, nil /* txn */)
+		defer tracker.stop(&planner.curPlan.stats)
+
 		defer func() {
 			planner.maybeLogStatement(ctx, "par-exec" /* lbl */, res.RowsAffected(), res.Err())
 		}()
@@ -790,6 +798,7 @@ func (ex *connExecutor) execStmtInParallel(
 		err = ex.execWithDistSQLEngine(ctx, planner, stmt.AST.StatementType(), res, distributePlan)
 		ex.sessionTracing.TraceExecEnd(ctx, res.Err(), res.RowsAffected())
 		planner.statsCollector.PhaseTimes()[plannerEndExecStmt] = timeutil.Now()
+		tracker.stop(&planner.curPlan.stats)
 
 		// Record the statement summary. This also closes the plan if the
 		// plan has not been closed earlier.
@@ -869,6 +878,13 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 	ex.sessionTracing.TracePlanStart(ctx, stmt.AST.StatementTag())
 	planner.statsCollector.PhaseTimes()[plannerStartLogicalPlan] = timeutil.Now()
 
+	// Measure the resources consumed by the statement, including its
+	// planning; the measurement is saved just before the statement
+	// summary is recorded below.
+	var tracker stmtResourceTracker
+	tracker.start(ex.server.cfg.Settings, planner.txn)
+	defer tracker.stop(&planner.curPlan.stats)
+
 	// Prepare the plan. Note, the error is processed below. Everything
 	// between here and there needs to happen even if there's an error.
 	err := ex.makeExecPlan(ctx, planner)
@@ -900,6 +916,13 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		return nil
 	}
 
+	if planner.SessionData().EnforceGatewayRegion {
+		if err := planner.checkGatewayRegion(ctx); err != nil {
+			res.SetError(err)
+			return nil
+		}
+	}
+
 	var cols sqlbase.ResultColumns
 	if stmt.AST.StatementType() == tree.Rows {
 		cols = planColumns(planner.curPlan.plan)
@@ -954,9 +977,10 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		planner.curPlan.flags.Set(planFlagDistSQLLocal)
 	}
 	ex.sessionTracing.TraceExecStart(ctx, "distributed")
-	err = ex.execWithDistSQLEngine(ctx, planner, stmt.AST.StatementType(), res, distributePlan)
+	err = ex.execWithHistoricalResultCache(ctx, planner, stmt.AST.StatementType(), res, distributePlan)
 	ex.sessionTracing.TraceExecEnd(ctx, res.Err(), res.RowsAffected())
 	planner.statsCollector.PhaseTimes()[plannerEndExecStmt] = timeutil.Now()
+	tracker.stop(&planner.curPlan.stats)
 
 	// Record the statement summary. This also closes the plan if the
 	// plan has not been closed earlier.
@@ -1051,12 +1075,13 @@ func canFallbackFromOpt(err error, optMode sessiondata.OptimizerMode, stmt *Stat
 		return isSetVar
 	}
 
-	// If the statement is EXPLAIN (OPT), then don't fallback (we want to return
-	// the error, not show a plan from the heuristic planner).
+	// If the statement is EXPLAIN (OPT) or EXPLAIN (GIST), then don't fallback
+	// (we want to return the error, not show a plan from the heuristic planner).
 	// TODO(radu): this is hacky and doesn't handle an EXPLAIN (OPT) inside
 	// a larger query.
 	if e, ok := stmt.AST.(*tree.Explain); ok {
-		if opts, err := e.ParseOptions(); err == nil && opts.Mode == tree.ExplainOpt {
+		if opts, err := e.ParseOptions(); err == nil &&
+			(opts.Mode == tree.ExplainOpt || opts.Mode == tree.ExplainGist) {
 			return false
 		}
 	}
@@ -1079,6 +1104,14 @@ func (ex *connExecutor) execWithDistSQLEngine(
 	res RestrictedCommandResult,
 	distribute bool,
 ) error {
+	captureCtx, capture := ex.maybeStartPlanCapture(ctx, planner.stmt)
+	if capture != nil {
+		defer func(ctx context.Context) {
+			capture.finish(ctx, &ex.server.sqlStats, distribute, res.Err())
+		}(ctx)
+		ctx = captureCtx
+	}
+
 	recv := MakeDistSQLReceiver(
 		ctx, res, stmtType,
 		ex.server.cfg.RangeDescriptorCache, ex.server.cfg.LeaseHolderCache,
@@ -1088,6 +1121,7 @@ func (ex *connExecutor) execWithDistSQLEngine(
 		},
 		&ex.sessionTracing,
 	)
+	recv.stats = &planner.curPlan.stats
 	defer recv.Release()
 
 	evalCtx := planner.ExtendedEvalContext()
@@ -1116,6 +1150,9 @@ func (ex *connExecutor) execWithDistSQLEngine(
 		}
 	}
 	recv.discardRows = planner.discardRows
+	if capture != nil {
+		capture.setupPlanningCtx(planCtx)
+	}
 	// We pass in whether or not we wanted to distribute this plan, which tells
 	// the planner whether or not to plan remote table readers.
 	ex.server.cfg.DistSQLPlanner.PlanAndRun(
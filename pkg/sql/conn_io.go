@@ -411,6 +411,11 @@ func (buf *StmtBuf) Push(ctx context.Context, cmd Command) error {
 func (buf *StmtBuf) curCmd() (Command, CmdPos, error) {
 	buf.mu.Lock()
 	defer buf.mu.Unlock()
+	return buf.curCmdLocked()
+}
+
+// curCmdLocked is like curCmd, but the caller must hold buf.mu.
+func (buf *StmtBuf) curCmdLocked() (Command, CmdPos, error) {
 	for {
 		if buf.mu.closed {
 			return nil, 0, io.EOF
@@ -494,39 +499,47 @@ func (buf *StmtBuf) advanceOne() {
 // slot.
 func (buf *StmtBuf) seekToNextBatch() error {
 	buf.mu.Lock()
-	curPos := buf.mu.curPos
-	cmdIdx, err := buf.translatePosLocked(curPos)
+	defer buf.mu.Unlock()
+	cmdIdx, err := buf.translatePosLocked(buf.mu.curPos)
 	if err != nil {
-		buf.mu.Unlock()
 		return err
 	}
 	if cmdIdx == len(buf.mu.data) {
-		buf.mu.Unlock()
 		return pgerror.NewAssertionErrorf("invalid seek start point")
 	}
-	buf.mu.Unlock()
 
-	var foundSync bool
-	for !foundSync {
-		buf.advanceOne()
-		_, pos, err := buf.curCmd()
+	// The lock is held throughout, so that skipping over commands that are
+	// already buffered (which is generally the case when the client pipelines
+	// its commands) doesn't contend with the writer.
+	for {
+		buf.mu.curPos++
+		cmd, _, err := buf.curCmdLocked()
 		if err != nil {
 			return err
 		}
-		buf.mu.Lock()
-		cmdIdx, err := buf.translatePosLocked(pos)
-		if err != nil {
-			buf.mu.Unlock()
-			return err
+		if _, ok := cmd.(Sync); ok {
+			return nil
 		}
+	}
+}
 
-		if _, ok := buf.mu.data[cmdIdx].(Sync); ok {
-			foundSync = true
+// batchEndBuffered returns whether the Sync command ending the current batch
+// has already been pushed into the buffer; if it has, seekToNextBatch() will
+// not block. Like for seekToNextBatch(), a Sync under the cursor doesn't
+// count.
+func (buf *StmtBuf) batchEndBuffered() bool {
+	buf.mu.Lock()
+	defer buf.mu.Unlock()
+	cmdIdx, err := buf.translatePosLocked(buf.mu.curPos)
+	if err != nil {
+		return false
+	}
+	for i := cmdIdx + 1; i < len(buf.mu.data); i++ {
+		if _, ok := buf.mu.data[i].(Sync); ok {
+			return true
 		}
-
-		buf.mu.Unlock()
 	}
-	return nil
+	return false
 }
 
 // rewind resets the buffer's position to pos.
@@ -341,3 +341,40 @@ func TestStmtBufBatching(t *testing.T) {
 		t.Fatalf("expected pos to be %d, got: %d", 9, pos)
 	}
 }
+
+func TestStmtBufBatchEndBuffered(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	buf := NewStmtBuf()
+	ctx := context.TODO()
+
+	s1, err := parser.ParseOne("SELECT 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mustPush(ctx, t, buf, ExecStmt{Statement: s1})
+	mustPush(ctx, t, buf, ExecStmt{Statement: s1})
+	if buf.batchEndBuffered() {
+		t.Fatal("expected the end of the batch not to be buffered")
+	}
+
+	mustPush(ctx, t, buf, Sync{})
+	if !buf.batchEndBuffered() {
+		t.Fatal("expected the end of the batch to be buffered")
+	}
+
+	// Go to the Sync. It starts the next batch, which doesn't have an end yet.
+	if err := buf.seekToNextBatch(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.batchEndBuffered() {
+		t.Fatal("expected the end of the batch not to be buffered")
+	}
+
+	mustPush(ctx, t, buf, ExecStmt{Statement: s1})
+	mustPush(ctx, t, buf, Sync{})
+	if !buf.batchEndBuffered() {
+		t.Fatal("expected the end of the batch to be buffered")
+	}
+}
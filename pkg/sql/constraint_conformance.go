@@ -0,0 +1,550 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+// constraintConformanceInterval is the interval at which the constraint
+// conformance job checks the ranges. The job only runs while it is set.
+var constraintConformanceInterval = settings.RegisterNonNegativeDurationSetting(
+	"kv.constraint_conformance.interval",
+	"the interval at which a job checks that the replicas and leases of all the ranges "+
+		"satisfy the constraints and lease preferences of their zone configs (0 disables the job)",
+	0,
+)
+
+// constraintConformanceStartInterval is the interval at which each node makes
+// sure that the constraint conformance job is running while it is enabled. It
+// is also the longest the job waits before noticing a change of
+// constraintConformanceInterval.
+const constraintConformanceStartInterval = time.Minute
+
+// StartConstraintConformanceJob makes sure that the constraint conformance job
+// is running while kv.constraint_conformance.interval is set: the node starts
+// the job if no other node is running it. The job stops by itself, and
+// succeeds, once the setting is reset.
+func StartConstraintConformanceJob(
+	ctx context.Context, stopper *stop.Stopper, execCfg *ExecutorConfig,
+) {
+	changed := make(chan struct{}, 1)
+	constraintConformanceInterval.SetOnChange(&execCfg.Settings.SV, func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	})
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ticker := time.NewTicker(constraintConformanceStartInterval)
+		defer ticker.Stop()
+		for {
+			if constraintConformanceInterval.Get(&execCfg.Settings.SV) != 0 {
+				if err := maybeStartConstraintConformanceJob(ctx, execCfg); err != nil {
+					log.Warningf(ctx, "unable to start the constraint conformance job: %v", err)
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-changed:
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+func maybeStartConstraintConformanceJob(ctx context.Context, execCfg *ExecutorConfig) error {
+	ids, err := liveConstraintConformanceJobs(ctx, execCfg, nil /* txn */)
+	if err != nil || len(ids) > 0 {
+		return err
+	}
+	_, _, err = execCfg.JobRegistry.StartJob(ctx, nil /* resultsCh */, jobs.Record{
+		Description: "constraint conformance",
+		Username:    security.RootUser,
+		Details:     jobspb.ConstraintConformanceDetails{},
+		Progress:    jobspb.ConstraintConformanceProgress{},
+	})
+	return err
+}
+
+// liveConstraintConformanceJobs returns the IDs of the constraint conformance
+// jobs which are pending, running or paused, in increasing order. Two nodes
+// can start the job concurrently; the job with the smallest ID is the one
+// which keeps running.
+func liveConstraintConformanceJobs(
+	ctx context.Context, execCfg *ExecutorConfig, txn *client.Txn,
+) ([]int64, error) {
+	rows, err := execCfg.InternalExecutor.Query(
+		ctx, "constraint-conformance-jobs", txn,
+		`SELECT job_id FROM crdb_internal.jobs WHERE job_type = $1 AND status IN ($2, $3, $4) ORDER BY job_id`,
+		jobspb.TypeConstraintConformance.String(), jobs.StatusPending, jobs.StatusRunning, jobs.StatusPaused,
+	)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int64, len(rows))
+	for i, r := range rows {
+		ids[i] = int64(tree.MustBeDInt(r[0]))
+	}
+	return ids, nil
+}
+
+// constraintConformanceResumer runs the constraint conformance job, which
+// checks the ranges every kv.constraint_conformance.interval and records the
+// violations in its progress.
+type constraintConformanceResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &constraintConformanceResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *constraintConformanceResumer) Resume(
+	ctx context.Context, phs interface{}, _ chan<- tree.Datums,
+) error {
+	execCfg := phs.(PlanHookState).ExecCfg()
+	metrics := execCfg.JobRegistry.MetricsStruct().ConstraintConformance
+	defer metrics.Reset()
+
+	var lastCheck time.Time
+	for {
+		interval := constraintConformanceInterval.Get(&execCfg.Settings.SV)
+		if interval == 0 {
+			return nil
+		}
+		if timeutil.Since(lastCheck) >= interval {
+			ids, err := liveConstraintConformanceJobs(ctx, execCfg, nil /* txn */)
+			if err != nil {
+				log.Warningf(ctx, "unable to list the constraint conformance jobs: %v", err)
+			} else if len(ids) > 0 && ids[0] != *r.job.ID() {
+				log.Infof(ctx, "constraint conformance job superseded by job %d", ids[0])
+				return nil
+			}
+			lastCheck = timeutil.Now()
+			if err := r.check(ctx, execCfg, metrics, lastCheck); err != nil {
+				if _, ok := errors.Cause(err).(*jobs.InvalidStatusError); ok {
+					// The job was paused or canceled.
+					return err
+				}
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				log.Warningf(ctx, "constraint conformance check failed: %v", err)
+			}
+		}
+		wait := interval - timeutil.Since(lastCheck)
+		if wait > constraintConformanceStartInterval {
+			wait = constraintConformanceStartInterval
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// check checks all the ranges and records the violations in the progress of
+// the job. The violations which were already recorded by the previous check
+// keep their start time.
+func (r *constraintConformanceResumer) check(
+	ctx context.Context,
+	execCfg *ExecutorConfig,
+	metrics *jobs.ConstraintConformanceMetrics,
+	now time.Time,
+) error {
+	cfg := execCfg.Gossip.GetSystemConfig()
+	if cfg == nil {
+		return errors.New("system config not yet available")
+	}
+	var ranges []client.KeyValue
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		ranges, err = ScanMetaKVs(ctx, txn, roachpb.Span{Key: keys.MinKey, EndKey: keys.MaxKey})
+		return err
+	}); err != nil {
+		return err
+	}
+
+	c := makeConformanceChecker(cfg, execCfg.Gossip, execCfg.DB)
+	var desc roachpb.RangeDescriptor
+	for i := range ranges {
+		if err := ranges[i].ValueProto(&desc); err != nil {
+			return err
+		}
+		if err := c.checkRange(ctx, &desc); err != nil {
+			return err
+		}
+	}
+
+	prevProgress := r.job.Progress()
+	progress := c.progress(prevProgress.GetConstraintConformance(), now)
+	if err := r.job.SetProgress(ctx, progress); err != nil {
+		return err
+	}
+	var maxDuration time.Duration
+	for _, v := range progress.Violations {
+		if d := now.Sub(timeutil.FromUnixMicros(v.StartMicros)); d > maxDuration {
+			maxDuration = d
+		}
+	}
+	metrics.Violations.Update(int64(len(progress.Violations)))
+	metrics.ViolatingRanges.Update(c.violatingRanges)
+	metrics.LeaseViolatingRanges.Update(c.leaseViolatingRanges)
+	metrics.MaxViolationDuration.Update(maxDuration.Nanoseconds())
+	return nil
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *constraintConformanceResumer) OnFailOrCancel(context.Context, *client.Txn) error {
+	return nil
+}
+
+// OnSuccess is part of the jobs.Resumer interface.
+func (r *constraintConformanceResumer) OnSuccess(context.Context, *client.Txn) error {
+	return nil
+}
+
+// OnTerminal is part of the jobs.Resumer interface.
+func (r *constraintConformanceResumer) OnTerminal(
+	context.Context, jobs.Status, chan<- tree.Datums,
+) {
+}
+
+func init() {
+	jobs.RegisterConstructor(
+		jobspb.TypeConstraintConformance,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &constraintConformanceResumer{job: job}
+		},
+	)
+}
+
+// conformanceZoneKey identifies a zone config: the zone config of the object
+// zoneID, or, if indexID is set, its subzone for the index and the partition.
+type conformanceZoneKey struct {
+	zoneID    uint32
+	indexID   uint32
+	partition string
+}
+
+type conformanceViolationKey struct {
+	conformanceZoneKey
+	typ    jobspb.ConstraintConformanceProgress_ViolationType
+	config string
+}
+
+// conformanceZoneEntry is the result of getZoneConfig for an object.
+type conformanceZoneEntry struct {
+	zoneID, placeholderID uint32
+	zone, placeholder     *config.ZoneConfig
+}
+
+// conformanceChecker checks the replicas and leases of ranges against the
+// constraints and lease preferences of their zone configs.
+type conformanceChecker struct {
+	cfg    *config.SystemConfig
+	g      *gossip.Gossip
+	db     *client.DB
+	zones  map[uint32]conformanceZoneEntry
+	stores map[roachpb.StoreID]*roachpb.StoreDescriptor
+
+	violations           map[conformanceViolationKey]*jobspb.ConstraintConformanceProgress_Violation
+	checkedRanges        int64
+	violatingRanges      int64
+	leaseViolatingRanges int64
+}
+
+func makeConformanceChecker(
+	cfg *config.SystemConfig, g *gossip.Gossip, db *client.DB,
+) conformanceChecker {
+	return conformanceChecker{
+		cfg:        cfg,
+		g:          g,
+		db:         db,
+		zones:      make(map[uint32]conformanceZoneEntry),
+		stores:     make(map[roachpb.StoreID]*roachpb.StoreDescriptor),
+		violations: make(map[conformanceViolationKey]*jobspb.ConstraintConformanceProgress_Violation),
+	}
+}
+
+// zoneConfigForRange returns the zone config which applies to the range
+// starting at key, like config.SystemConfig.GetZoneConfigForKey, along with
+// the identification of that zone config.
+func (c *conformanceChecker) zoneConfigForRange(
+	key roachpb.RKey,
+) (*config.ZoneConfig, conformanceZoneKey, error) {
+	objectID, keySuffix := config.ZoneObjectIDForKey(key)
+	e, ok := c.zones[objectID]
+	if !ok {
+		getKey := func(key roachpb.Key) (*roachpb.Value, error) {
+			return c.cfg.GetValue(key), nil
+		}
+		var err error
+		e.zoneID, e.zone, e.placeholderID, e.placeholder, err = getZoneConfig(
+			objectID, getKey, false /* getInheritedDefault */)
+		if err == errNoZoneConfigApplies {
+			e.zoneID, e.zone = keys.RootNamespaceID, config.DefaultZoneConfigRef()
+		} else if err != nil {
+			return nil, conformanceZoneKey{}, err
+		} else if err := completeZoneConfig(e.zone, e.zoneID, getKey); err != nil {
+			return nil, conformanceZoneKey{}, err
+		}
+		c.zones[objectID] = e
+	}
+
+	subzones, subzonesID := e.zone, e.zoneID
+	if e.placeholder != nil {
+		subzones, subzonesID = e.placeholder, e.placeholderID
+	}
+	if subzone := subzones.GetSubzoneForKeySuffix(keySuffix); subzone != nil {
+		// The zone configs are cached: inherit into a copy.
+		zone := subzone.Config
+		if indexSubzone := subzones.GetSubzone(subzone.IndexID, ""); indexSubzone != nil {
+			zone.InheritFromParent(indexSubzone.Config)
+		}
+		zone.InheritFromParent(*e.zone)
+		return &zone, conformanceZoneKey{
+			zoneID:    subzonesID,
+			indexID:   subzone.IndexID,
+			partition: subzone.PartitionName,
+		}, nil
+	}
+	return e.zone, conformanceZoneKey{zoneID: e.zoneID}, nil
+}
+
+// store returns the descriptor of a store, or nil if it is not known.
+func (c *conformanceChecker) store(storeID roachpb.StoreID) *roachpb.StoreDescriptor {
+	if s, ok := c.stores[storeID]; ok {
+		return s
+	}
+	var s *roachpb.StoreDescriptor
+	var desc roachpb.StoreDescriptor
+	if err := c.g.GetInfoProto(gossip.MakeStoreKey(storeID), &desc); err == nil {
+		s = &desc
+	}
+	c.stores[storeID] = s
+	return s
+}
+
+func (c *conformanceChecker) leaseholder(
+	ctx context.Context, desc *roachpb.RangeDescriptor,
+) (roachpb.StoreID, error) {
+	key := desc.StartKey.AsRawKey()
+	if desc.StartKey.Equal(roachpb.RKeyMin) {
+		key = keys.LocalMax
+	}
+	b := &client.Batch{}
+	b.AddRawRequest(&roachpb.LeaseInfoRequest{
+		RequestHeader: roachpb.RequestHeader{Key: key},
+	})
+	if err := c.db.Run(ctx, b); err != nil {
+		return 0, err
+	}
+	resp := b.RawResponse().Responses[0].GetInner().(*roachpb.LeaseInfoResponse)
+	return resp.Lease.Replica.StoreID, nil
+}
+
+func (c *conformanceChecker) checkRange(ctx context.Context, desc *roachpb.RangeDescriptor) error {
+	c.checkedRanges++
+	zone, zoneKey, err := c.zoneConfigForRange(desc.StartKey)
+	if err != nil {
+		return err
+	}
+	stores := make([]*roachpb.StoreDescriptor, len(desc.Replicas))
+	for i, r := range desc.Replicas {
+		stores[i] = c.store(r.StoreID)
+	}
+
+	violating := false
+	for _, constraints := range zone.Constraints {
+		if constraintsSatisfied(constraints, stores) {
+			continue
+		}
+		violating = true
+		str, err := yamlMarshalFlow(config.ConstraintsList{
+			Constraints: []config.Constraints{constraints},
+		})
+		if err != nil {
+			return err
+		}
+		c.addViolation(zoneKey, jobspb.ConstraintConformanceProgress_CONSTRAINT, str, desc.RangeID)
+	}
+	if violating {
+		c.violatingRanges++
+	}
+
+	if len(zone.LeasePreferences) == 0 {
+		return nil
+	}
+	leaseholder, err := c.leaseholder(ctx, desc)
+	if err != nil {
+		// The range may have been merged or split since it was scanned. It
+		// is checked again next time.
+		log.VEventf(ctx, 2, "r%d: unable to look up the lease: %v", desc.RangeID, err)
+		return nil
+	}
+	if !leasePreferencesSatisfied(zone.LeasePreferences, leaseholder, stores) {
+		str, err := yamlMarshalFlow(zone.LeasePreferences)
+		if err != nil {
+			return err
+		}
+		c.addViolation(zoneKey, jobspb.ConstraintConformanceProgress_LEASE_PREFERENCE, str, desc.RangeID)
+		c.leaseViolatingRanges++
+	}
+	return nil
+}
+
+func (c *conformanceChecker) addViolation(
+	zoneKey conformanceZoneKey,
+	typ jobspb.ConstraintConformanceProgress_ViolationType,
+	configStr string,
+	rangeID roachpb.RangeID,
+) {
+	configStr = strings.TrimSpace(configStr)
+	k := conformanceViolationKey{conformanceZoneKey: zoneKey, typ: typ, config: configStr}
+	v, ok := c.violations[k]
+	if !ok {
+		v = &jobspb.ConstraintConformanceProgress_Violation{
+			ZoneID:         zoneKey.zoneID,
+			IndexID:        zoneKey.indexID,
+			Partition:      zoneKey.partition,
+			Type:           typ,
+			Config:         configStr,
+			ExampleRangeID: rangeID,
+		}
+		c.violations[k] = v
+	}
+	v.ViolatingRanges++
+}
+
+// progress returns the results of the check, given those of the previous
+// check.
+func (c *conformanceChecker) progress(
+	prev *jobspb.ConstraintConformanceProgress, now time.Time,
+) jobspb.ConstraintConformanceProgress {
+	nowMicros := timeutil.ToUnixMicros(now)
+	starts := make(map[conformanceViolationKey]int64)
+	if prev != nil {
+		for _, v := range prev.Violations {
+			k := conformanceViolationKey{
+				conformanceZoneKey: conformanceZoneKey{
+					zoneID: v.ZoneID, indexID: v.IndexID, partition: v.Partition,
+				},
+				typ:    v.Type,
+				config: v.Config,
+			}
+			starts[k] = v.StartMicros
+		}
+	}
+
+	res := jobspb.ConstraintConformanceProgress{
+		CheckedMicros: nowMicros,
+		CheckedRanges: c.checkedRanges,
+	}
+	for k, v := range c.violations {
+		v.StartMicros = nowMicros
+		if start, ok := starts[k]; ok {
+			v.StartMicros = start
+		}
+		res.Violations = append(res.Violations, *v)
+	}
+	sort.Slice(res.Violations, func(i, j int) bool {
+		a, b := &res.Violations[i], &res.Violations[j]
+		if a.ZoneID != b.ZoneID {
+			return a.ZoneID < b.ZoneID
+		}
+		if a.IndexID != b.IndexID {
+			return a.IndexID < b.IndexID
+		}
+		if a.Partition != b.Partition {
+			return a.Partition < b.Partition
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.Config < b.Config
+	})
+	return res
+}
+
+// constraintsSatisfied returns whether the replicas on the given stores
+// satisfy a set of constraints: c.NumReplicas of them, or all of them if it is
+// zero, must match all the constraints. The replicas on unknown stores are
+// assumed not to match.
+func constraintsSatisfied(c config.Constraints, stores []*roachpb.StoreDescriptor) bool {
+	matching := 0
+	for _, s := range stores {
+		if s != nil && storeMatchesConstraints(*s, c.Constraints) {
+			matching++
+		}
+	}
+	if c.NumReplicas == 0 {
+		return matching == len(stores)
+	}
+	return matching >= int(c.NumReplicas)
+}
+
+// leasePreferencesSatisfied returns whether the lease is on a store which
+// matches the first lease preference that the replicas of the range can
+// satisfy. The preferences are violated if no replica can satisfy them.
+func leasePreferencesSatisfied(
+	prefs []config.LeasePreference, leaseholder roachpb.StoreID, stores []*roachpb.StoreDescriptor,
+) bool {
+	for _, pref := range prefs {
+		satisfiable := false
+		for _, s := range stores {
+			if s != nil && storeMatchesConstraints(*s, pref.Constraints) {
+				if s.StoreID == leaseholder {
+					return true
+				}
+				satisfiable = true
+			}
+		}
+		if satisfiable {
+			return false
+		}
+	}
+	return false
+}
+
+func storeMatchesConstraints(store roachpb.StoreDescriptor, constraints []config.Constraint) bool {
+	for _, c := range constraints {
+		if !config.StoreMatchesConstraint(store, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func conformanceTestStore(id roachpb.StoreID, region string) *roachpb.StoreDescriptor {
+	return &roachpb.StoreDescriptor{
+		StoreID: id,
+		Node: roachpb.NodeDescriptor{
+			Locality: roachpb.Locality{Tiers: []roachpb.Tier{{Key: "region", Value: region}}},
+		},
+	}
+}
+
+func parseConformanceConstraints(t *testing.T, short ...string) []config.Constraint {
+	res := make([]config.Constraint, len(short))
+	for i, s := range short {
+		if err := res[i].FromString(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return res
+}
+
+func TestConstraintsSatisfied(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stores := []*roachpb.StoreDescriptor{
+		conformanceTestStore(1, "east"),
+		conformanceTestStore(2, "east"),
+		conformanceTestStore(3, "west"),
+	}
+	testCases := []struct {
+		constraints []string
+		numReplicas int32
+		stores      []*roachpb.StoreDescriptor
+		expected    bool
+	}{
+		{[]string{"+region=east"}, 2, stores, true},
+		{[]string{"+region=east"}, 3, stores, false},
+		{[]string{"+region=west"}, 1, stores, true},
+		// Without NumReplicas, the constraints apply to all the replicas.
+		{[]string{"+region=east"}, 0, stores, false},
+		{[]string{"-region=north"}, 0, stores, true},
+		{[]string{"-region=west"}, 0, stores, false},
+		// The replicas on unknown stores don't match.
+		{[]string{"-region=north"}, 0, append(stores[:2:2], nil), false},
+	}
+	for i, tc := range testCases {
+		c := config.Constraints{
+			NumReplicas: tc.numReplicas,
+			Constraints: parseConformanceConstraints(t, tc.constraints...),
+		}
+		if res := constraintsSatisfied(c, tc.stores); res != tc.expected {
+			t.Errorf("%d: %s: expected %t, got %t", i, tc.constraints, tc.expected, res)
+		}
+	}
+}
+
+func TestLeasePreferencesSatisfied(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stores := []*roachpb.StoreDescriptor{
+		conformanceTestStore(1, "east"),
+		conformanceTestStore(2, "west"),
+	}
+	prefs := func(regions ...string) []config.LeasePreference {
+		var res []config.LeasePreference
+		for _, r := range regions {
+			res = append(res, config.LeasePreference{
+				Constraints: parseConformanceConstraints(t, "+region="+r),
+			})
+		}
+		return res
+	}
+	testCases := []struct {
+		prefs       []config.LeasePreference
+		leaseholder roachpb.StoreID
+		expected    bool
+	}{
+		{prefs("east"), 1, true},
+		{prefs("east"), 2, false},
+		// The lease should be on the first preference that a replica satisfies.
+		{prefs("north", "west", "east"), 2, true},
+		{prefs("north", "west", "east"), 1, false},
+		// If no replica satisfies the preferences, they are violated.
+		{prefs("north"), 1, false},
+	}
+	for i, tc := range testCases {
+		if res := leasePreferencesSatisfied(tc.prefs, tc.leaseholder, stores); res != tc.expected {
+			t.Errorf("%d: expected %t, got %t", i, tc.expected, res)
+		}
+	}
+}
+
+// TestConformanceCheckerProgress verifies that the violations which persist
+// from one check to the next keep their start time.
+func TestConformanceCheckerProgress(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	t1 := timeutil.Unix(1000, 0)
+	t2 := t1.Add(time.Hour)
+	constraint := jobspb.ConstraintConformanceProgress_CONSTRAINT
+	lease := jobspb.ConstraintConformanceProgress_LEASE_PREFERENCE
+
+	c := makeConformanceChecker(nil /* cfg */, nil /* g */, nil /* db */)
+	c.addViolation(conformanceZoneKey{zoneID: 52}, constraint, "[+region=east]", 10)
+	c.addViolation(conformanceZoneKey{zoneID: 52}, constraint, "[+region=east]", 11)
+	first := c.progress(nil /* prev */, t1)
+	if len(first.Violations) != 1 {
+		t.Fatalf("expected 1 violation, got %+v", first.Violations)
+	}
+	if v := first.Violations[0]; v.ViolatingRanges != 2 || v.ExampleRangeID != 10 ||
+		v.StartMicros != timeutil.ToUnixMicros(t1) {
+		t.Errorf("unexpected violation %+v", v)
+	}
+
+	c = makeConformanceChecker(nil /* cfg */, nil /* g */, nil /* db */)
+	c.addViolation(conformanceZoneKey{zoneID: 52, indexID: 1, partition: "p"}, lease, "[[+region=east]]", 12)
+	c.addViolation(conformanceZoneKey{zoneID: 52}, constraint, "[+region=east]", 11)
+	second := c.progress(&first, t2)
+	if len(second.Violations) != 2 {
+		t.Fatalf("expected 2 violations, got %+v", second.Violations)
+	}
+	if v := second.Violations[0]; v.IndexID != 0 || v.StartMicros != timeutil.ToUnixMicros(t1) {
+		t.Errorf("expected the constraint violation to have started at %s, got %+v", t1, v)
+	}
+	if v := second.Violations[1]; v.IndexID != 1 || v.Partition != "p" ||
+		v.StartMicros != timeutil.ToUnixMicros(t2) {
+		t.Errorf("expected the lease violation to have started at %s, got %+v", t2, v)
+	}
+	if second.CheckedMicros != timeutil.ToUnixMicros(t2) {
+		t.Errorf("expected the check time to be %s, got %d", t2, second.CheckedMicros)
+	}
+}
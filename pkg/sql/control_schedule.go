@@ -0,0 +1,95 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+type controlScheduleNode struct {
+	n       *tree.ControlSchedule
+	numRows int
+}
+
+// ControlSchedule pauses or resumes a schedule.
+// Privileges: superuser.
+func (p *planner) ControlSchedule(ctx context.Context, n *tree.ControlSchedule) (planNode, error) {
+	if err := p.RequireSuperUser(ctx,
+		tree.ScheduleCommandToStatement[n.Command]+" SCHEDULE"); err != nil {
+		return nil, err
+	}
+	return &controlScheduleNode{n: n}, nil
+}
+
+// FastPathResults implements the planNodeFastPath inteface.
+func (n *controlScheduleNode) FastPathResults() (int, bool) {
+	return n.numRows, true
+}
+
+func (n *controlScheduleNode) startExec(params runParams) error {
+	ie := params.extendedEvalCtx.ExecCfg.InternalExecutor
+	row, err := ie.QueryRow(
+		params.ctx, "control-schedule-lookup", params.p.txn,
+		`SELECT recurrence, next_run FROM system.schedules WHERE name = $1`, string(n.n.Name),
+	)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"schedule %q does not exist", n.n.Name)
+	}
+	paused := row[1] == tree.DNull
+
+	// Pausing a paused schedule and resuming an active one are no-ops. In
+	// particular, resuming an active schedule does not postpone its next run.
+	switch n.n.Command {
+	case tree.PauseSchedule:
+		if paused {
+			return nil
+		}
+		n.numRows, err = ie.Exec(
+			params.ctx, "pause-schedule", params.p.txn,
+			`UPDATE system.schedules SET next_run = NULL WHERE name = $1`, string(n.n.Name),
+		)
+	case tree.ResumeSchedule:
+		if !paused {
+			return nil
+		}
+		s, err := parseRecurrence(string(tree.MustBeDString(row[0])))
+		if err != nil {
+			return err
+		}
+		n.numRows, err = ie.Exec(
+			params.ctx, "resume-schedule", params.p.txn,
+			`UPDATE system.schedules SET next_run = $2 WHERE name = $1`,
+			string(n.n.Name), s.Next(timeutil.Now()),
+		)
+		return err
+	default:
+		err = pgerror.NewAssertionErrorf("unhandled schedule command %v", n.n.Command)
+	}
+	return err
+}
+
+func (*controlScheduleNode) Next(runParams) (bool, error) { return false, nil }
+
+func (*controlScheduleNode) Values() tree.Datums { return nil }
+
+func (*controlScheduleNode) Close(context.Context) {}
@@ -15,14 +15,15 @@
 package sql
 
 import (
-	"bytes"
 	"context"
 	"io"
+	"io/ioutil"
 	"strconv"
 	"time"
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -44,24 +45,37 @@ import (
 // Incoming data is buffered and batched; batches are turned into insertNodes
 // that are executed. INSERT privileges are required on the destination table.
 //
+// By default, a malformed row aborts the COPY. The max_rejected_rows option
+// allows that many malformed rows to be skipped instead; if the
+// rejected_rows_table option is also given, the skipped rows are inserted in
+// that table, along with the reason they were rejected. See
+// copyRejectedRowsColumns.
+//
 // See: https://www.postgresql.org/docs/current/static/sql-copy.html
 // and: https://www.postgresql.org/docs/current/static/protocol-flow.html#PROTOCOL-COPY
 type copyMachine struct {
 	table         tree.TableExpr
 	columns       tree.NameList
 	resultColumns sqlbase.ResultColumns
-	// buf is used to parse input data into rows. It also accumulates a partial
-	// row between protocol messages.
-	buf bytes.Buffer
 	// rows accumulates a batch of rows to be eventually inserted.
 	rows []tree.Exprs
 	// insertedRows keeps track of the total number of rows inserted by the
 	// machine.
 	insertedRows int
+	// maxRejectedRows is the number of malformed rows which can be skipped
+	// before the COPY fails, and rejectedRows the number of rows skipped so
+	// far.
+	maxRejectedRows, rejectedRows int64
+	// rejectedTable, if set, is the table in which the skipped rows are
+	// inserted. rejected accumulates the skipped rows of the current batch; it
+	// is accounted for in rowsMemAcc too.
+	rejectedTable tree.TableExpr
+	rejected      []tree.Exprs
 	// rowsMemAcc accounts for memory used by `rows`.
 	rowsMemAcc mon.BoundAccount
-	// bufMemAcc accounts for memory used by `buf`; it is kept in sync with
-	// buf.Cap().
+	// bufMemAcc accounts for memory used by the buffer of the CopyReader
+	// decoding the input data; it is kept in sync with the capacity of the
+	// buffer holding the current row.
 	bufMemAcc mon.BoundAccount
 
 	// conn is the pgwire connection from which data is to be read.
@@ -83,6 +97,21 @@ type copyMachine struct {
 	parsingEvalCtx *tree.EvalContext
 }
 
+const (
+	copyOptionMaxRejectedRows   = "max_rejected_rows"
+	copyOptionRejectedRowsTable = "rejected_rows_table"
+)
+
+var copyOptionExpectValues = map[string]KVStringOptValidate{
+	copyOptionMaxRejectedRows:   KVStringOptRequireValue,
+	copyOptionRejectedRowsTable: KVStringOptRequireValue,
+}
+
+// copyRejectedRowsColumns are the columns of the rejected_rows_table in which
+// the line of the input at which a rejected row starts, the data of the row
+// and the reason it was rejected are inserted.
+var copyRejectedRowsColumns = tree.NameList{"line", "data", "error"}
+
 // newCopyMachine creates a new copyMachine.
 func newCopyMachine(
 	ctx context.Context,
@@ -125,11 +154,59 @@ func newCopyMachine(
 	for i := range cols {
 		c.resultColumns[i] = sqlbase.ResultColumn{Typ: cols[i].Type.ToDatumType()}
 	}
+	if err := c.processOptions(ctx, n.Options); err != nil {
+		return nil, err
+	}
 	c.rowsMemAcc = c.p.extendedEvalCtx.Mon.MakeBoundAccount()
 	c.bufMemAcc = c.p.extendedEvalCtx.Mon.MakeBoundAccount()
 	return c, nil
 }
 
+// processOptions validates the options of the COPY statement and configures
+// the handling of the malformed rows accordingly.
+func (c *copyMachine) processOptions(ctx context.Context, options tree.KVOptions) error {
+	optsFn, err := c.p.TypeAsStringOpts(options, copyOptionExpectValues)
+	if err != nil {
+		return err
+	}
+	opts, err := optsFn()
+	if err != nil {
+		return err
+	}
+	if v, ok := opts[copyOptionMaxRejectedRows]; ok {
+		c.maxRejectedRows, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || c.maxRejectedRows < 0 {
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"invalid %s value: %q", copyOptionMaxRejectedRows, v)
+		}
+	}
+	v, ok := opts[copyOptionRejectedRowsTable]
+	if !ok {
+		return nil
+	}
+	if c.maxRejectedRows == 0 {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"%s requires a positive %s", copyOptionRejectedRowsTable, copyOptionMaxRejectedRows)
+	}
+	tn, err := parser.ParseTableName(v)
+	if err != nil {
+		return err
+	}
+	tableDesc, err := ResolveExistingObject(ctx, &c.p, tn, true /*required*/, requireTableDesc)
+	if err != nil {
+		return err
+	}
+	if err := c.p.CheckPrivilege(ctx, tableDesc, privilege.INSERT); err != nil {
+		return err
+	}
+	if _, err := c.p.processColumns(tableDesc, copyRejectedRowsColumns,
+		true /* ensureColumns */, false /* allowMutations */); err != nil {
+		return err
+	}
+	c.rejectedTable = tn
+	return nil
+}
+
 // copyTxnOpt contains information about the transaction in which the copying
 // should take place. Can be empty, in which case the copyMachine is responsible
 // for managing its own transactions.
@@ -154,37 +231,50 @@ func (c *copyMachine) run(ctx context.Context) error {
 		return err
 	}
 
-	// Read from the connection until we see an ClientMsgCopyDone.
-	readBuf := pgwirebase.ReadBuffer{}
+	typs := make([]types.T, len(c.resultColumns))
+	for i := range c.resultColumns {
+		typs[i] = c.resultColumns[i].Typ
+	}
+	in := &copyInReader{conn: c.conn}
+	rd := parser.NewCopyReader(
+		in, typs, c.parsingEvalCtx, parser.DefaultCopyOptions(parser.CopyFormatText))
+
+	// When this many rows are in the copy buffer, they are inserted.
+	const copyBatchRowSize = 100
 
-Loop:
 	for {
-		typ, _, err := readBuf.ReadTypedMsg(c.conn.Rd())
+		row, err := rd.Read()
+		if err == io.EOF {
+			break
+		}
+		if rowErr, ok := err.(*parser.CopyRowError); ok && c.maxRejectedRows > 0 {
+			if c.rejectedRows >= c.maxRejectedRows {
+				return pgerror.Wrapf(err, pgerror.CodeBadCopyFileFormatError,
+					"more than %d rows rejected", c.maxRejectedRows)
+			}
+			err = c.addRejectedRow(ctx, rd, rowErr)
+		} else if err == nil {
+			err = c.addRow(ctx, row)
+		}
 		if err != nil {
 			return err
 		}
-
-		switch typ {
-		case pgwirebase.ClientMsgCopyData:
-			if err := c.processCopyData(
-				ctx, string(readBuf.Msg), c.p.EvalContext(), false, /* final */
-			); err != nil {
-				return err
-			}
-		case pgwirebase.ClientMsgCopyDone:
-			if err := c.processCopyData(
-				ctx, "" /* data */, c.p.EvalContext(), true, /* final */
-			); err != nil {
+		if err := c.bufMemAcc.ResizeTo(ctx, int64(cap(rd.Raw()))); err != nil {
+			return err
+		}
+		if len(c.rows)+len(c.rejected) >= copyBatchRowSize {
+			if err := c.insertRows(ctx); err != nil {
 				return err
 			}
-			break Loop
-		case pgwirebase.ClientMsgCopyFail:
-			return pgerror.NewErrorf(pgerror.CodeDataExceptionError,
-				"client canceled COPY")
-		case pgwirebase.ClientMsgFlush, pgwirebase.ClientMsgSync:
-			// Spec says to "ignore Flush and Sync messages received during copy-in mode".
-		default:
-			return pgwirebase.NewUnrecognizedMsgTypeErr(typ)
+		}
+	}
+	// The data following an end-of-data marker, if any, is ignored.
+	if _, err := io.Copy(ioutil.Discard, in); err != nil {
+		return err
+	}
+	if len(c.rows) > 0 || len(c.rejected) > 0 {
+		if err := c.insertRows(ctx); err != nil {
+			return err
 		}
 	}
 
@@ -197,72 +287,45 @@ Loop:
 	return c.conn.SendCommandComplete(tag)
 }
 
-const (
-	nullString = `\N`
-	lineDelim  = '\n'
-)
-
-var (
-	fieldDelim = []byte{'\t'}
-)
-
-// processCopyData buffers incoming data and, once the buffer fills up, inserts
-// the accumulated rows.
-//
-// Args:
-// final: If set, buffered data is written even if the buffer is not full.
-func (c *copyMachine) processCopyData(
-	ctx context.Context, data string, evalCtx *tree.EvalContext, final bool,
-) (retErr error) {
-	// At the end, adjust the mem accounting to reflect what's left in the buffer.
-	defer func() {
-		if err := c.bufMemAcc.ResizeTo(ctx, int64(c.buf.Cap())); err != nil && retErr == nil {
-			retErr = err
-		}
-	}()
+// copyInReader is an io.Reader returning the data of the CopyData messages
+// received from the client, until a CopyDone message is received.
+type copyInReader struct {
+	conn    pgwirebase.Conn
+	readBuf pgwirebase.ReadBuffer
+	// data is the part of the last CopyData message which hasn't been read yet.
+	data []byte
+	done bool
+}
 
-	// When this many rows are in the copy buffer, they are inserted.
-	const copyBatchRowSize = 100
+var _ io.Reader = &copyInReader{}
 
-	if len(data) > (c.buf.Cap() - c.buf.Len()) {
-		// If it looks like the buffer will need to allocate to accommodate data,
-		// account for the memory here. This is not particularly accurate - we don't
-		// know how much the buffer will actually grow by.
-		if err := c.bufMemAcc.ResizeTo(ctx, int64(len(data))); err != nil {
-			return err
+// Read is part of the io.Reader interface.
+func (r *copyInReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.done {
+			return 0, io.EOF
 		}
-	}
-	c.buf.WriteString(data)
-	for c.buf.Len() > 0 {
-		line, err := c.buf.ReadBytes(lineDelim)
+		typ, _, err := r.readBuf.ReadTypedMsg(r.conn.Rd())
 		if err != nil {
-			if err != io.EOF {
-				return err
-			} else if !final {
-				// Put the incomplete row back in the buffer, to be processed next time.
-				c.buf.Write(line)
-				break
-			}
-		} else {
-			// Remove lineDelim from end.
-			line = line[:len(line)-1]
-			// Remove a single '\r' at EOL, if present.
-			if len(line) > 0 && line[len(line)-1] == '\r' {
-				line = line[:len(line)-1]
-			}
-		}
-		if c.buf.Len() == 0 && bytes.Equal(line, []byte(`\.`)) {
-			break
+			return 0, err
 		}
-		if err := c.addRow(ctx, line); err != nil {
-			return err
+		switch typ {
+		case pgwirebase.ClientMsgCopyData:
+			r.data = r.readBuf.Msg
+		case pgwirebase.ClientMsgCopyDone:
+			r.done = true
+		case pgwirebase.ClientMsgCopyFail:
+			return 0, pgerror.NewErrorf(pgerror.CodeDataExceptionError,
+				"client canceled COPY")
+		case pgwirebase.ClientMsgFlush, pgwirebase.ClientMsgSync:
+			// Spec says to "ignore Flush and Sync messages received during copy-in mode".
+		default:
+			return 0, pgwirebase.NewUnrecognizedMsgTypeErr(typ)
 		}
 	}
-	// Only do work if we have a full batch of rows or this is the end.
-	if ln := len(c.rows); ln == 0 || (ln < copyBatchRowSize && !final) {
-		return nil
-	}
-	return c.insertRows(ctx)
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
 }
 
 // preparePlanner resets the planner so that it can be used for execution.
@@ -304,23 +367,47 @@ func (c *copyMachine) preparePlanner(ctx context.Context) func(context.Context,
 }
 
 // insertRows transforms the buffered rows into an insertNode and executes it.
+// The buffered rejected rows, if any, are inserted in the same transaction.
 func (c *copyMachine) insertRows(ctx context.Context) (retErr error) {
 	cleanup := c.preparePlanner(ctx)
 	defer func() {
 		retErr = cleanup(ctx, retErr)
 	}()
 
-	vc := &tree.ValuesClause{Rows: c.rows}
-	numRows := len(c.rows)
-	// Reuse the same backing array once the Insert is complete.
+	rows, rejected := c.rows, c.rejected
+	// Reuse the same backing arrays once the Inserts are complete.
 	c.rows = c.rows[:0]
+	c.rejected = c.rejected[:0]
 	c.rowsMemAcc.Clear(ctx)
 
+	if len(rejected) > 0 {
+		// The transaction must not be committed by this first Insert.
+		autoCommit := c.p.autoCommit
+		c.p.autoCommit = false
+		err := c.execInsert(ctx, c.rejectedTable, copyRejectedRowsColumns, rejected)
+		c.p.autoCommit = autoCommit
+		if err != nil {
+			return err
+		}
+	}
+	if len(rows) > 0 {
+		if err := c.execInsert(ctx, c.table, c.columns, rows); err != nil {
+			return err
+		}
+		c.insertedRows += len(rows)
+	}
+	return nil
+}
+
+// execInsert inserts the given rows in the columns of a table.
+func (c *copyMachine) execInsert(
+	ctx context.Context, table tree.TableExpr, columns tree.NameList, rows []tree.Exprs,
+) error {
 	in := tree.Insert{
-		Table:   c.table,
-		Columns: c.columns,
+		Table:   table,
+		Columns: columns,
 		Rows: &tree.Select{
-			Select: vc,
+			Select: &tree.ValuesClause{Rows: rows},
 		},
 		Returning: tree.AbsentReturningClause,
 	}
@@ -338,57 +425,23 @@ func (c *copyMachine) insertRows(ctx context.Context) (retErr error) {
 	if err := startExec(params, insertNode); err != nil {
 		return err
 	}
-	rows, err := countRowsAffected(params, insertNode)
+	numRows, err := countRowsAffected(params, insertNode)
 	if err != nil {
 		return err
 	}
-	if rows != numRows {
+	if numRows != len(rows) {
 		log.Fatalf(params.ctx, "didn't insert all buffered rows and yet no error was reported. "+
-			"Inserted %d out of %d rows.", rows, numRows)
+			"Inserted %d out of %d rows.", numRows, len(rows))
 	}
-	c.insertedRows += rows
-
 	return nil
 }
 
-func (c *copyMachine) addRow(ctx context.Context, line []byte) error {
-	var err error
-	parts := bytes.Split(line, fieldDelim)
-	if len(parts) != len(c.resultColumns) {
-		return pgerror.NewErrorf(pgerror.CodeProtocolViolationError,
-			"expected %d values, got %d", len(c.resultColumns), len(parts))
-	}
-	exprs := make(tree.Exprs, len(parts))
-	for i, part := range parts {
-		s := string(part)
-		if s == nullString {
-			exprs[i] = tree.DNull
-			continue
-		}
-		switch t := c.resultColumns[i].Typ; t {
-		case types.Bytes,
-			types.Date,
-			types.Interval,
-			types.INet,
-			types.String,
-			types.Timestamp,
-			types.TimestampTZ,
-			types.UUID:
-			s, err = decodeCopy(s)
-			if err != nil {
-				return err
-			}
-		}
-		d, err := tree.ParseStringAs(c.resultColumns[i].Typ, s, c.parsingEvalCtx)
-		if err != nil {
-			return err
-		}
-
-		sz := d.Size()
-		if err := c.rowsMemAcc.Grow(ctx, int64(sz)); err != nil {
+func (c *copyMachine) addRow(ctx context.Context, row tree.Datums) error {
+	exprs := make(tree.Exprs, len(row))
+	for i, d := range row {
+		if err := c.rowsMemAcc.Grow(ctx, int64(d.Size())); err != nil {
 			return err
 		}
-
 		exprs[i] = d
 	}
 	if err := c.rowsMemAcc.Grow(ctx, int64(unsafe.Sizeof(exprs))); err != nil {
@@ -399,99 +452,28 @@ func (c *copyMachine) addRow(ctx context.Context, line []byte) error {
 	return nil
 }
 
-// decodeCopy unescapes a single COPY field.
-//
-// See: https://www.postgresql.org/docs/9.5/static/sql-copy.html#AEN74432
-func decodeCopy(in string) (string, error) {
-	var buf bytes.Buffer
-	start := 0
-	for i, n := 0, len(in); i < n; i++ {
-		if in[i] != '\\' {
-			continue
-		}
-		buf.WriteString(in[start:i])
-		i++
-		if i >= n {
-			return "", pgerror.NewErrorf(pgerror.CodeSyntaxError,
-				"unknown escape sequence: %q", in[i-1:])
-		}
-
-		ch := in[i]
-		if decodedChar := decodeMap[ch]; decodedChar != 0 {
-			buf.WriteByte(decodedChar)
-		} else if ch == 'x' {
-			// \x can be followed by 1 or 2 hex digits.
-			i++
-			if i >= n {
-				return "", pgerror.NewErrorf(pgerror.CodeSyntaxError,
-					"unknown escape sequence: %q", in[i-2:])
-			}
-			ch = in[i]
-			digit, ok := decodeHexDigit(ch)
-			if !ok {
-				return "", pgerror.NewErrorf(pgerror.CodeSyntaxError,
-					"unknown escape sequence: %q", in[i-2:i])
-			}
-			if i+1 < n {
-				if v, ok := decodeHexDigit(in[i+1]); ok {
-					i++
-					digit <<= 4
-					digit += v
-				}
-			}
-			buf.WriteByte(digit)
-		} else if ch >= '0' && ch <= '7' {
-			digit, _ := decodeOctDigit(ch)
-			// 1 to 2 more octal digits follow.
-			if i+1 < n {
-				if v, ok := decodeOctDigit(in[i+1]); ok {
-					i++
-					digit <<= 3
-					digit += v
-				}
-			}
-			if i+1 < n {
-				if v, ok := decodeOctDigit(in[i+1]); ok {
-					i++
-					digit <<= 3
-					digit += v
-				}
-			}
-			buf.WriteByte(digit)
-		} else {
-			return "", pgerror.NewErrorf(pgerror.CodeSyntaxError,
-				"unknown escape sequence: %q", in[i-1:i+1])
+// addRejectedRow skips the row which the reader failed to decode. If a
+// rejected_rows_table was specified, the row is buffered to be inserted in it.
+func (c *copyMachine) addRejectedRow(
+	ctx context.Context, rd *parser.CopyReader, rowErr *parser.CopyRowError,
+) error {
+	c.rejectedRows++
+	if c.rejectedTable == nil {
+		return nil
+	}
+	exprs := tree.Exprs{
+		tree.NewDInt(tree.DInt(rowErr.Line)),
+		tree.NewDString(string(rd.Raw())),
+		tree.NewDString(rowErr.Error()),
+	}
+	for _, e := range exprs {
+		if err := c.rowsMemAcc.Grow(ctx, int64(e.(tree.Datum).Size())); err != nil {
+			return err
 		}
-		start = i + 1
 	}
-	buf.WriteString(in[start:])
-	return buf.String(), nil
-}
-
-func decodeDigit(c byte, onlyOctal bool) (byte, bool) {
-	switch {
-	case c >= '0' && c <= '7':
-		return c - '0', true
-	case !onlyOctal && c >= '8' && c <= '9':
-		return c - '0', true
-	case !onlyOctal && c >= 'a' && c <= 'f':
-		return c - 'a' + 10, true
-	case !onlyOctal && c >= 'A' && c <= 'F':
-		return c - 'A' + 10, true
-	default:
-		return 0, false
+	if err := c.rowsMemAcc.Grow(ctx, int64(unsafe.Sizeof(exprs))); err != nil {
+		return err
 	}
-}
-
-func decodeOctDigit(c byte) (byte, bool) { return decodeDigit(c, true) }
-func decodeHexDigit(c byte) (byte, bool) { return decodeDigit(c, false) }
-
-var decodeMap = map[byte]byte{
-	'b':  '\b',
-	'f':  '\f',
-	'n':  '\n',
-	'r':  '\r',
-	't':  '\t',
-	'v':  '\v',
-	'\\': '\\',
+	c.rejected = append(c.rejected, exprs)
+	return nil
 }
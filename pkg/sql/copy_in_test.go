@@ -16,6 +16,7 @@ package sql_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"math/rand"
@@ -27,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
@@ -308,6 +310,110 @@ func TestCopyError(t *testing.T) {
 	}
 }
 
+// TestCopyRejectedRows verifies that malformed rows are skipped, and saved in
+// the rejected_rows_table, up to max_rejected_rows.
+func TestCopyRejectedRows(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params, _ := tests.CreateTestServerParams()
+	s, db, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := db.Exec(`
+		CREATE DATABASE d;
+		SET DATABASE = d;
+		CREATE TABLE t (i INT PRIMARY KEY, s STRING);
+		CREATE TABLE rejected (line INT, data STRING, error STRING);
+	`); err != nil {
+		t.Fatal(err)
+	}
+
+	copyRows := func(options string, rows [][]interface{}) error {
+		txn, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		stmt, err := txn.Prepare(`COPY t FROM STDIN WITH ` + options)
+		if err != nil {
+			_ = txn.Rollback()
+			return err
+		}
+		for _, row := range rows {
+			if _, err := stmt.Exec(row...); err != nil {
+				_ = txn.Rollback()
+				return err
+			}
+		}
+		if err := stmt.Close(); err != nil {
+			_ = txn.Rollback()
+			return err
+		}
+		return txn.Commit()
+	}
+
+	rows := [][]interface{}{{1, "a"}, {"x", "b"}, {3, "c"}, {"y", "d"}}
+	const options = `max_rejected_rows = '2', rejected_rows_table = 'rejected'`
+	if err := copyRows(options, rows); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+	if err := db.QueryRow(`SELECT count(*) FROM t`).Scan(&count); err != nil {
+		t.Fatal(err)
+	} else if count != 2 {
+		t.Fatalf("expected 2 rows, got %d", count)
+	}
+	res, err := db.Query(`SELECT line, data, error FROM rejected ORDER BY line`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Close()
+	expected := []struct {
+		line int
+		data string
+	}{{2, "x\tb"}, {4, "y\td"}}
+	var i int
+	for ; res.Next(); i++ {
+		var line int
+		var data, errMsg string
+		if err := res.Scan(&line, &data, &errMsg); err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(expected) {
+			break
+		}
+		if line != expected[i].line || data != expected[i].data {
+			t.Errorf("%d: expected line %d with %q, got line %d with %q",
+				i, expected[i].line, expected[i].data, line, data)
+		}
+		if !testutils.IsError(errors.New(errMsg), fmt.Sprintf(`line %d, column 1: .*invalid syntax`, line)) {
+			t.Errorf("%d: unexpected error message %q", i, errMsg)
+		}
+	}
+	if err := res.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if i != len(expected) {
+		t.Fatalf("expected %d rejected rows, got %d", len(expected), i)
+	}
+
+	// One more malformed row than max_rejected_rows fails the COPY.
+	rows = [][]interface{}{{5, "e"}, {"x", "f"}}
+	if err := copyRows(`max_rejected_rows = '0'`, rows); !testutils.IsError(err, `line 2, column 1`) {
+		t.Fatalf("expected a parse error, got %v", err)
+	}
+	if err := copyRows(`max_rejected_rows = '1'`, append(rows, []interface{}{"", "g"})); !testutils.IsError(
+		err, `more than 1 rows rejected: line 3, column 1`,
+	) {
+		t.Fatalf("expected too many rejected rows, got %v", err)
+	}
+	if err := copyRows(`rejected_rows_table = 'rejected'`, rows); !testutils.IsError(
+		err, `rejected_rows_table requires a positive max_rejected_rows`,
+	) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
+
 // TestCopyOne verifies that only one COPY can run at once.
 func TestCopyOne(t *testing.T) {
 	defer leaktest.AfterTest(t)()
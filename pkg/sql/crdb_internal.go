@@ -28,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/gossip"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
@@ -41,10 +42,13 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 const crdbInternalName = "crdb_internal"
@@ -63,40 +67,53 @@ const crdbInternalName = "crdb_internal"
 var crdbInternal = virtualSchema{
 	name: crdbInternalName,
 	tableDefs: map[sqlbase.ID]virtualSchemaDef{
-		sqlbase.CrdbInternalBackwardDependenciesTableID: crdbInternalBackwardDependenciesTable,
-		sqlbase.CrdbInternalBuildInfoTableID:            crdbInternalBuildInfoTable,
-		sqlbase.CrdbInternalBuiltinFunctionsTableID:     crdbInternalBuiltinFunctionsTable,
-		sqlbase.CrdbInternalClusterQueriesTableID:       crdbInternalClusterQueriesTable,
-		sqlbase.CrdbInternalClusterSessionsTableID:      crdbInternalClusterSessionsTable,
-		sqlbase.CrdbInternalClusterSettingsTableID:      crdbInternalClusterSettingsTable,
-		sqlbase.CrdbInternalCreateStmtsTableID:          crdbInternalCreateStmtsTable,
-		sqlbase.CrdbInternalFeatureUsageID:              crdbInternalFeatureUsage,
-		sqlbase.CrdbInternalForwardDependenciesTableID:  crdbInternalForwardDependenciesTable,
-		sqlbase.CrdbInternalGossipNodesTableID:          crdbInternalGossipNodesTable,
-		sqlbase.CrdbInternalGossipAlertsTableID:         crdbInternalGossipAlertsTable,
-		sqlbase.CrdbInternalGossipLivenessTableID:       crdbInternalGossipLivenessTable,
-		sqlbase.CrdbInternalGossipNetworkTableID:        crdbInternalGossipNetworkTable,
-		sqlbase.CrdbInternalIndexColumnsTableID:         crdbInternalIndexColumnsTable,
-		sqlbase.CrdbInternalJobsTableID:                 crdbInternalJobsTable,
-		sqlbase.CrdbInternalKVNodeStatusTableID:         crdbInternalKVNodeStatusTable,
-		sqlbase.CrdbInternalKVStoreStatusTableID:        crdbInternalKVStoreStatusTable,
-		sqlbase.CrdbInternalLeasesTableID:               crdbInternalLeasesTable,
-		sqlbase.CrdbInternalLocalQueriesTableID:         crdbInternalLocalQueriesTable,
-		sqlbase.CrdbInternalLocalSessionsTableID:        crdbInternalLocalSessionsTable,
-		sqlbase.CrdbInternalLocalMetricsTableID:         crdbInternalLocalMetricsTable,
-		sqlbase.CrdbInternalPartitionsTableID:           crdbInternalPartitionsTable,
-		sqlbase.CrdbInternalPredefinedCommentsTableID:   crdbInternalPredefinedCommentsTable,
-		sqlbase.CrdbInternalRangesNoLeasesTableID:       crdbInternalRangesNoLeasesTable,
-		sqlbase.CrdbInternalRangesViewID:                crdbInternalRangesView,
-		sqlbase.CrdbInternalRuntimeInfoTableID:          crdbInternalRuntimeInfoTable,
-		sqlbase.CrdbInternalSchemaChangesTableID:        crdbInternalSchemaChangesTable,
-		sqlbase.CrdbInternalSessionTraceTableID:         crdbInternalSessionTraceTable,
-		sqlbase.CrdbInternalSessionVariablesTableID:     crdbInternalSessionVariablesTable,
-		sqlbase.CrdbInternalStmtStatsTableID:            crdbInternalStmtStatsTable,
-		sqlbase.CrdbInternalTableColumnsTableID:         crdbInternalTableColumnsTable,
-		sqlbase.CrdbInternalTableIndexesTableID:         crdbInternalTableIndexesTable,
-		sqlbase.CrdbInternalTablesTableID:               crdbInternalTablesTable,
-		sqlbase.CrdbInternalZonesTableID:                crdbInternalZonesTable,
+		sqlbase.CrdbInternalBackwardDependenciesTableID:      crdbInternalBackwardDependenciesTable,
+		sqlbase.CrdbInternalBuildInfoTableID:                 crdbInternalBuildInfoTable,
+		sqlbase.CrdbInternalBuiltinFunctionsTableID:          crdbInternalBuiltinFunctionsTable,
+		sqlbase.CrdbInternalClusterInflightTraceSpansTableID: crdbInternalClusterInflightTraceSpansTable,
+		sqlbase.CrdbInternalClusterLocksTableID:              crdbInternalClusterLocksTable,
+		sqlbase.CrdbInternalClusterNetworkLatenciesTableID:   crdbInternalClusterNetworkLatenciesTable,
+		sqlbase.CrdbInternalClusterQueriesTableID:            crdbInternalClusterQueriesTable,
+		sqlbase.CrdbInternalClusterSessionsTableID:           crdbInternalClusterSessionsTable,
+		sqlbase.CrdbInternalClusterSettingsTableID:           crdbInternalClusterSettingsTable,
+		sqlbase.CrdbInternalConstraintViolationsTableID:      crdbInternalConstraintViolationsTable,
+		sqlbase.CrdbInternalCreateStmtsTableID:               crdbInternalCreateStmtsTable,
+		sqlbase.CrdbInternalDeadlocksTableID:                 crdbInternalDeadlocksTable,
+		sqlbase.CrdbInternalFeatureUsageID:                   crdbInternalFeatureUsage,
+		sqlbase.CrdbInternalForwardDependenciesTableID:       crdbInternalForwardDependenciesTable,
+		sqlbase.CrdbInternalGossipNodesTableID:               crdbInternalGossipNodesTable,
+		sqlbase.CrdbInternalGossipAlertsTableID:              crdbInternalGossipAlertsTable,
+		sqlbase.CrdbInternalGossipLivenessTableID:            crdbInternalGossipLivenessTable,
+		sqlbase.CrdbInternalGossipNetworkTableID:             crdbInternalGossipNetworkTable,
+		sqlbase.CrdbInternalIndexColumnsTableID:              crdbInternalIndexColumnsTable,
+		sqlbase.CrdbInternalJobsTableID:                      crdbInternalJobsTable,
+		sqlbase.CrdbInternalKVNodeStatusTableID:              crdbInternalKVNodeStatusTable,
+		sqlbase.CrdbInternalKVStoreStatusTableID:             crdbInternalKVStoreStatusTable,
+		sqlbase.CrdbInternalLeasesTableID:                    crdbInternalLeasesTable,
+		sqlbase.CrdbInternalLocalQueriesTableID:              crdbInternalLocalQueriesTable,
+		sqlbase.CrdbInternalLocalSessionsTableID:             crdbInternalLocalSessionsTable,
+		sqlbase.CrdbInternalLocalMetricsTableID:              crdbInternalLocalMetricsTable,
+		sqlbase.CrdbInternalNodeInflightTraceSpansTableID:    crdbInternalNodeInflightTraceSpansTable,
+		sqlbase.CrdbInternalNodeMemoryMonitorsTableID:        crdbInternalNodeMemoryMonitorsTable,
+		sqlbase.CrdbInternalNodeNetworkLatenciesTableID:      crdbInternalNodeNetworkLatenciesTable,
+		sqlbase.CrdbInternalNodeRoleStatsTableID:             crdbInternalNodeRoleStatsTable,
+		sqlbase.CrdbInternalNodeStmtPlansTableID:             crdbInternalNodeStmtPlansTable,
+		sqlbase.CrdbInternalNodeTLSConnectionsTableID:        crdbInternalNodeTLSConnectionsTable,
+		sqlbase.CrdbInternalPartitionsTableID:                crdbInternalPartitionsTable,
+		sqlbase.CrdbInternalPredefinedCommentsTableID:        crdbInternalPredefinedCommentsTable,
+		sqlbase.CrdbInternalPrivilegesTableID:                crdbInternalPrivilegesTable,
+		sqlbase.CrdbInternalRangesNoLeasesTableID:            crdbInternalRangesNoLeasesTable,
+		sqlbase.CrdbInternalRangesViewID:                     crdbInternalRangesView,
+		sqlbase.CrdbInternalRuntimeInfoTableID:               crdbInternalRuntimeInfoTable,
+		sqlbase.CrdbInternalSchemaChangesTableID:             crdbInternalSchemaChangesTable,
+		sqlbase.CrdbInternalSessionTraceTableID:              crdbInternalSessionTraceTable,
+		sqlbase.CrdbInternalSessionVariablesTableID:          crdbInternalSessionVariablesTable,
+		sqlbase.CrdbInternalStmtStatsTableID:                 crdbInternalStmtStatsTable,
+		sqlbase.CrdbInternalSuperRegionViolationsTableID:     crdbInternalSuperRegionViolationsTable,
+		sqlbase.CrdbInternalTableColumnsTableID:              crdbInternalTableColumnsTable,
+		sqlbase.CrdbInternalTableIndexesTableID:              crdbInternalTableIndexesTable,
+		sqlbase.CrdbInternalTablesTableID:                    crdbInternalTablesTable,
+		sqlbase.CrdbInternalZonesTableID:                     crdbInternalZonesTable,
 	},
 	validWithNoDatabaseContext: true,
 }
@@ -195,6 +212,121 @@ CREATE TABLE crdb_internal.node_runtime_info (
 	},
 }
 
+var crdbInternalNodeTLSConnectionsTable = virtualSchemaTable{
+	comment: `parameters negotiated by the active TLS connections (RAM, local node only)`,
+	schema: `
+CREATE TABLE crdb_internal.node_tls_connections (
+  node_id        INT NOT NULL,
+  kind           STRING NOT NULL,
+  remote_address STRING NOT NULL,
+  tls_version    STRING NOT NULL,
+  cipher_suite   STRING NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.node_tls_connections"); err != nil {
+			return err
+		}
+
+		rpcCtx := p.ExecCfg().RPCContext
+		if rpcCtx == nil {
+			return nil
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		for _, conn := range rpcCtx.TLSConns.Conns() {
+			if err := addRow(
+				nodeID,
+				tree.NewDString(conn.Kind),
+				tree.NewDString(conn.RemoteAddr),
+				tree.NewDString(security.TLSVersionName(conn.Version)),
+				tree.NewDString(security.CipherSuiteName(conn.CipherSuite)),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var crdbInternalPrivilegesTable = virtualSchemaTable{
+	comment: `effective privileges of every user and role on databases and tables, including those ` +
+		`inherited through role membership and the public role (KV scan; expensive!)`,
+	schema: `
+CREATE TABLE crdb_internal.privileges (
+  database_name  STRING NOT NULL,
+  schema_name    STRING NOT NULL,
+  table_name     STRING,          -- NULL for privileges on the database
+  grantee        STRING NOT NULL, -- user or role holding the privilege
+  privilege_type STRING NOT NULL,
+  granted_to     STRING NOT NULL  -- role the privilege was granted to: grantee, one of its roles, or public
+)`,
+	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.privileges"); err != nil {
+			return err
+		}
+
+		// Compute, for every user or role that can be granted privileges,
+		// the users and roles that hold these privileges.
+		users, err := p.GetAllUsersAndRoles(ctx)
+		if err != nil {
+			return err
+		}
+		userNames := make([]string, 0, len(users))
+		for u := range users {
+			userNames = append(userNames, u)
+		}
+		sort.Strings(userNames)
+		holders := make(map[string][]tree.Datum)
+		for _, u := range userNames {
+			grantees, err := p.effectiveGrantees(ctx, u)
+			if err != nil {
+				return err
+			}
+			uStr := tree.NewDString(u)
+			for _, g := range grantees {
+				holders[g] = append(holders[g], uStr)
+			}
+		}
+
+		addPrivileges := func(
+			dbNameStr, scNameStr, tbNameStr tree.Datum, privs *sqlbase.PrivilegeDescriptor,
+		) error {
+			for _, u := range privs.Show() {
+				grantedToStr := tree.NewDString(u.User)
+				for _, holderStr := range holders[u.User] {
+					for _, priv := range u.Privileges {
+						if err := addRow(
+							dbNameStr,
+							scNameStr,
+							tbNameStr,
+							holderStr,
+							tree.NewDString(priv),
+							grantedToStr,
+						); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			return nil
+		}
+
+		if err := forEachDatabaseDesc(ctx, p, dbContext, func(db *sqlbase.DatabaseDescriptor) error {
+			dbNameStr := tree.NewDString(db.Name)
+			return forEachSchemaName(ctx, p, db, func(scName string) error {
+				return addPrivileges(dbNameStr, tree.NewDString(scName), tree.DNull, db.Privileges)
+			})
+		}); err != nil {
+			return err
+		}
+		// Virtual tables are readable by everyone and are omitted.
+		return forEachTableDesc(ctx, p, dbContext, hideVirtual,
+			func(db *sqlbase.DatabaseDescriptor, scName string, table *sqlbase.TableDescriptor) error {
+				return addPrivileges(
+					tree.NewDString(db.Name), tree.NewDString(scName), tree.NewDString(table.Name), table.Privileges)
+			})
+	},
+}
+
 // TODO(tbg): prefix with kv_.
 var crdbInternalTablesTable = virtualSchemaTable{
 	comment: `table descriptors accessible by current user, including non-public and virtual (KV scan; expensive!)`,
@@ -591,7 +723,13 @@ CREATE TABLE crdb_internal.node_statement_statistics (
   service_lat_avg     FLOAT NOT NULL,
   service_lat_var     FLOAT NOT NULL,
   overhead_lat_avg    FLOAT NOT NULL,
-  overhead_lat_var    FLOAT NOT NULL
+  overhead_lat_var    FLOAT NOT NULL,
+  bytes_read_avg      FLOAT NOT NULL,
+  bytes_read_var      FLOAT NOT NULL,
+  bytes_written_avg   FLOAT NOT NULL,
+  bytes_written_var   FLOAT NOT NULL,
+  cpu_time_avg        FLOAT,
+  cpu_time_var        FLOAT
 )`,
 	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
 		if err := p.RequireSuperUser(ctx, "access application statistics"); err != nil {
@@ -645,6 +783,13 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 				if s.data.SensitiveInfo.LastErr != "" {
 					errString = tree.NewDString(s.data.SensitiveInfo.LastErr)
 				}
+				// The CPU time is only known if it was measured for some of
+				// the executions.
+				cpuTimeAvg, cpuTimeVar := tree.DNull, tree.DNull
+				if s.data.CPUTimeCount > 0 {
+					cpuTimeAvg = tree.NewDFloat(tree.DFloat(s.data.CPUTime.Mean))
+					cpuTimeVar = tree.NewDFloat(tree.DFloat(s.data.CPUTime.GetVariance(s.data.CPUTimeCount)))
+				}
 				err := addRow(
 					nodeID,
 					tree.NewDString(appName),
@@ -667,6 +812,12 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 					tree.NewDFloat(tree.DFloat(s.data.ServiceLat.GetVariance(s.data.Count))),
 					tree.NewDFloat(tree.DFloat(s.data.OverheadLat.Mean)),
 					tree.NewDFloat(tree.DFloat(s.data.OverheadLat.GetVariance(s.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.data.BytesRead.Mean)),
+					tree.NewDFloat(tree.DFloat(s.data.BytesRead.GetVariance(s.data.Count))),
+					tree.NewDFloat(tree.DFloat(s.data.BytesWritten.Mean)),
+					tree.NewDFloat(tree.DFloat(s.data.BytesWritten.GetVariance(s.data.Count))),
+					cpuTimeAvg,
+					cpuTimeVar,
 				)
 				s.Unlock()
 				if err != nil {
@@ -678,6 +829,105 @@ CREATE TABLE crdb_internal.node_statement_statistics (
 	},
 }
 
+// crdbInternalNodeRoleStatsTable exposes the resources consumed by the
+// statements of each user on this node since it started, for chargeback
+// reporting.
+var crdbInternalNodeRoleStatsTable = virtualSchemaTable{
+	comment: `per-user resource consumption (RAM; local node only)`,
+	schema: `
+CREATE TABLE crdb_internal.node_role_statistics (
+  node_id         INT NOT NULL,
+  user_name       STRING NOT NULL,
+  statement_count INT NOT NULL,
+  cpu_time        FLOAT NOT NULL,
+  bytes_read      INT NOT NULL,
+  bytes_written   INT NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "access role statistics"); err != nil {
+			return err
+		}
+
+		sqlStats := p.statsCollector.SQLStats()
+		if sqlStats == nil {
+			return pgerror.NewAssertionErrorf(
+				"cannot access sql statistics from this context")
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+
+		// Retrieve the user names and sort them to ensure the output is
+		// deterministic.
+		var users []string
+		sqlStats.roles.Lock()
+		for u := range sqlStats.roles.m {
+			users = append(users, u)
+		}
+		sqlStats.roles.Unlock()
+		sort.Strings(users)
+
+		for _, user := range users {
+			r := sqlStats.getStatsForRole(user)
+			r.Lock()
+			row := tree.Datums{
+				nodeID,
+				tree.NewDString(user),
+				tree.NewDInt(tree.DInt(r.stmtCount)),
+				tree.NewDFloat(tree.DFloat(r.cpuTime.Seconds())),
+				tree.NewDInt(tree.DInt(r.bytesRead)),
+				tree.NewDInt(tree.DInt(r.bytesWritten)),
+			}
+			r.Unlock()
+			if err := addRow(row...); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+var crdbInternalNodeStmtPlansTable = virtualSchemaTable{
+	comment: `EXPLAIN ANALYZE plans captured for the slowest statements (RAM; local node only)`,
+	schema: `
+CREATE TABLE crdb_internal.node_statement_plans (
+  node_id          INT NOT NULL,
+  application_name STRING NOT NULL,
+  key              STRING NOT NULL,
+  captured_at      TIMESTAMP NOT NULL,
+  distributed      BOOL NOT NULL,
+  run_lat          FLOAT NOT NULL,
+  plan_url         STRING NOT NULL,
+  plan_json        STRING NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "access captured plans"); err != nil {
+			return err
+		}
+
+		sqlStats := p.statsCollector.SQLStats()
+		if sqlStats == nil {
+			return pgerror.NewAssertionErrorf(
+				"cannot access sql statistics from this context")
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+
+		for _, c := range sqlStats.getCapturedPlans() {
+			if err := addRow(
+				nodeID,
+				tree.NewDString(c.appName),
+				tree.NewDString(c.fingerprint),
+				tree.MakeDTimestamp(c.capturedAt, time.Microsecond),
+				tree.MakeDBool(tree.DBool(c.distributed)),
+				tree.NewDFloat(tree.DFloat(c.runLat.Seconds())),
+				tree.NewDString(c.planURL),
+				tree.NewDString(c.planJSON),
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalSessionTraceTable exposes the latest trace collected on this
 // session (via SET TRACING={ON/OFF})
 //
@@ -1019,6 +1269,511 @@ func populateSessionsTable(
 	return nil
 }
 
+// crdbInternalClusterLocksTable exposes the contended locks on the entire
+// cluster, with one row per request waiting for a lock. Only the intents
+// on which other requests are queued in the contention queue are listed;
+// uncontended intents are not tracked in memory and thus not visible here.
+var crdbInternalClusterLocksTable = virtualSchemaTable{
+	comment: "contended locks and the requests waiting for them (cluster RPC; expensive!)",
+	schema: `
+CREATE TABLE crdb_internal.cluster_locks (
+  node_id           INT NOT NULL, -- the node on which the requests are waiting
+  store_id          INT,          -- the store on which the requests are waiting
+  key               BYTES,        -- the locked key
+  pretty_key        STRING,       -- the locked key, pretty-printed
+  holder_txn_id     STRING,       -- the ID of the transaction holding the lock
+  holder_session_id STRING,       -- the ID of the session running the holder, if known
+  holder_query      STRING,       -- the queries running in the holder session, if known
+  waiter_position   INT,          -- the position of the waiter in the queue (0 is pushing the holder)
+  waiter_txn_id     STRING,       -- the ID of the waiting transaction; NULL for non-transactional requests
+  waiter_session_id STRING,       -- the ID of the session running the waiter, if known
+  waiter_query      STRING,       -- the queries running in the waiter session, if known
+  wait_start        TIMESTAMP     -- the time at which the waiter started waiting
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.cluster_locks"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.StatusServer.ListLocks(ctx, &serverpb.ListLocksRequest{})
+		if err != nil {
+			return err
+		}
+
+		// Map the transactions to the sessions running them, so that holders
+		// and waiters can be related to their SQL activity.
+		lookupSession, err := makeTxnSessionLookup(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		for _, lock := range response.Locks {
+			holderSession, holderQuery := lookupSession(&lock.HolderTxnID)
+			lockRow := []tree.Datum{
+				tree.NewDInt(tree.DInt(lock.NodeID)),
+				tree.NewDInt(tree.DInt(lock.StoreID)),
+				tree.NewDBytes(tree.DBytes(lock.Key)),
+				tree.NewDString(keys.PrettyPrint(nil /* valDirs */, lock.Key)),
+				tree.NewDString(lock.HolderTxnID.String()),
+				holderSession,
+				holderQuery,
+			}
+			if len(lock.Waiters) == 0 {
+				// The queue emptied while the lock was being reported.
+				if err := addRow(append(lockRow,
+					tree.DNull, // waiter_position
+					tree.DNull, // waiter_txn_id
+					tree.DNull, // waiter_session_id
+					tree.DNull, // waiter_query
+					tree.DNull, // wait_start
+				)...); err != nil {
+					return err
+				}
+				continue
+			}
+			for i, waiter := range lock.Waiters {
+				waiterTxnID := tree.DNull
+				if waiter.TxnID != nil {
+					waiterTxnID = tree.NewDString(waiter.TxnID.String())
+				}
+				waiterSession, waiterQuery := lookupSession(waiter.TxnID)
+				row := append(lockRow[:len(lockRow):len(lockRow)],
+					tree.NewDInt(tree.DInt(i)),
+					waiterTxnID,
+					waiterSession,
+					waiterQuery,
+					tree.MakeDTimestamp(waiter.WaitStart, time.Microsecond),
+				)
+				if err := addRow(row...); err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, rpcErr := range response.Errors {
+			log.Warning(ctx, rpcErr.Message)
+			if rpcErr.NodeID != 0 {
+				// Add a row with this node ID, the error for the holder query,
+				// and nulls for all other columns.
+				if err := addRow(
+					tree.NewDInt(tree.DInt(rpcErr.NodeID)), // node ID
+					tree.DNull,                             // store ID
+					tree.DNull,                             // key
+					tree.DNull,                             // pretty_key
+					tree.DNull,                             // holder_txn_id
+					tree.DNull,                             // holder_session_id
+					tree.NewDString("-- "+rpcErr.Message),  // holder_query
+					tree.DNull,                             // waiter_position
+					tree.DNull,                             // waiter_txn_id
+					tree.DNull,                             // waiter_session_id
+					tree.DNull,                             // waiter_query
+					tree.DNull,                             // wait_start
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// makeTxnSessionLookup returns a function mapping the ID of a transaction to
+// the session running it, and the queries active in that session. Both are
+// NULL if the transaction is not running on behalf of a SQL session.
+func makeTxnSessionLookup(
+	ctx context.Context, p *planner,
+) (func(txnID *uuid.UUID) (id, queries tree.Datum), error) {
+	sessionsResp, err := p.extendedEvalCtx.StatusServer.ListSessions(
+		ctx, &serverpb.ListSessionsRequest{Username: ""})
+	if err != nil {
+		return nil, err
+	}
+	type sessionInfo struct {
+		id, queries tree.Datum
+	}
+	sessionsByTxn := make(map[uuid.UUID]sessionInfo)
+	for _, session := range sessionsResp.Sessions {
+		if session.KvTxnID == nil || len(session.ID) != 16 {
+			continue
+		}
+		var queries bytes.Buffer
+		for idx, query := range session.ActiveQueries {
+			if idx > 0 {
+				queries.WriteString("; ")
+			}
+			queries.WriteString(query.Sql)
+		}
+		sessionsByTxn[*session.KvTxnID] = sessionInfo{
+			id:      tree.NewDString(BytesToClusterWideID(session.ID).String()),
+			queries: tree.NewDString(queries.String()),
+		}
+	}
+	return func(txnID *uuid.UUID) (id, queries tree.Datum) {
+		if txnID != nil {
+			if s, ok := sessionsByTxn[*txnID]; ok {
+				return s.id, s.queries
+			}
+		}
+		return tree.DNull, tree.DNull
+	}, nil
+}
+
+// crdbInternalDeadlocksTable exposes the deadlocks recently detected by the
+// txn wait queues of the entire cluster, with one row per deadlock. Each
+// store keeps a bounded log of the deadlocks it detected, so older
+// deadlocks are eventually forgotten. The sessions and queries are those
+// running the transactions at the time the table is read, if any: a
+// victim, in particular, is usually gone by then.
+var crdbInternalDeadlocksTable = virtualSchemaTable{
+	comment: "recently detected deadlocks (cluster RPC; expensive!)",
+	schema: `
+CREATE TABLE crdb_internal.deadlocks (
+  node_id           INT NOT NULL, -- the node on which the deadlock was detected
+  store_id          INT,          -- the store on which the deadlock was detected
+  detected_at       TIMESTAMP,    -- the time at which the deadlock was detected
+  victim_policy     STRING,       -- the victim selection policy which was in effect
+  pusher_txn_id     STRING,       -- the ID of the transaction whose push detected the deadlock
+  pusher_key        BYTES,        -- the key of the transaction record of the pusher
+  pusher_pretty_key STRING,       -- the key of the transaction record of the pusher, pretty-printed
+  pusher_session_id STRING,       -- the ID of the session running the pusher, if still running
+  pusher_query      STRING,       -- the queries running in the pusher session, if still running
+  victim_txn_id     STRING,       -- the ID of the transaction aborted to break the deadlock
+  victim_key        BYTES,        -- the key of the transaction record of the victim
+  victim_pretty_key STRING,       -- the key of the transaction record of the victim, pretty-printed
+  victim_session_id STRING,       -- the ID of the session running the victim, if still running
+  victim_query      STRING,       -- the queries running in the victim session, if still running
+  dependent_txn_ids STRING[]      -- the transactions waiting on the pusher, including the rest of the cycle
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.deadlocks"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.StatusServer.ListDeadlocks(ctx, &serverpb.ListDeadlocksRequest{})
+		if err != nil {
+			return err
+		}
+		lookupSession, err := makeTxnSessionLookup(ctx, p)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range response.Deadlocks {
+			pusherSession, pusherQuery := lookupSession(&d.PusherTxnID)
+			victimSession, victimQuery := lookupSession(&d.VictimTxnID)
+			dependents := tree.NewDArray(types.String)
+			for _, id := range d.DependentTxnIDs {
+				if err := dependents.Append(tree.NewDString(id.String())); err != nil {
+					return err
+				}
+			}
+			if err := addRow(
+				tree.NewDInt(tree.DInt(d.NodeID)),
+				tree.NewDInt(tree.DInt(d.StoreID)),
+				tree.MakeDTimestamp(d.DetectedAt, time.Microsecond),
+				tree.NewDString(d.VictimPolicy),
+				tree.NewDString(d.PusherTxnID.String()),
+				tree.NewDBytes(tree.DBytes(d.PusherKey)),
+				tree.NewDString(keys.PrettyPrint(nil /* valDirs */, d.PusherKey)),
+				pusherSession,
+				pusherQuery,
+				tree.NewDString(d.VictimTxnID.String()),
+				tree.NewDBytes(tree.DBytes(d.VictimKey)),
+				tree.NewDString(keys.PrettyPrint(nil /* valDirs */, d.VictimKey)),
+				victimSession,
+				victimQuery,
+				dependents,
+			); err != nil {
+				return err
+			}
+		}
+
+		for _, rpcErr := range response.Errors {
+			log.Warning(ctx, rpcErr.Message)
+			if rpcErr.NodeID != 0 {
+				// Add a row with this node ID, the error for the pusher query,
+				// and nulls for all other columns.
+				if err := addRow(
+					tree.NewDInt(tree.DInt(rpcErr.NodeID)), // node ID
+					tree.DNull,                             // store ID
+					tree.DNull,                             // detected_at
+					tree.DNull,                             // victim_policy
+					tree.DNull,                             // pusher_txn_id
+					tree.DNull,                             // pusher_key
+					tree.DNull,                             // pusher_pretty_key
+					tree.DNull,                             // pusher_session_id
+					tree.NewDString("-- "+rpcErr.Message),  // pusher_query
+					tree.DNull,                             // victim_txn_id
+					tree.DNull,                             // victim_key
+					tree.DNull,                             // victim_pretty_key
+					tree.DNull,                             // victim_session_id
+					tree.DNull,                             // victim_query
+					tree.DNull,                             // dependent_txn_ids
+				); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
+
+const inflightTraceSpansSchemaPattern = `
+CREATE TABLE crdb_internal.%s (
+  node_id        INT NOT NULL, -- the node on which the span was started
+  trace_id       INT,          -- the ID of the trace to which the span belongs
+  span_id        INT,          -- the ID of the span
+  parent_span_id INT,          -- the ID of the parent span; 0 for a root span
+  operation      STRING,       -- the name of the traced operation
+  start          TIMESTAMP,    -- the start time of the span
+  duration       INTERVAL,     -- the time elapsed since the start of the span
+  tags           JSON          -- the tags of the span
+)`
+
+// crdbInternalNodeInflightTraceSpansTable exposes the tracing spans of the
+// current node which have not finished yet. Only real spans are tracked,
+// hence when tracing is not enabled, the table only lists the spans which
+// are being recorded.
+var crdbInternalNodeInflightTraceSpansTable = virtualSchemaTable{
+	comment: "active tracing spans (RAM; local node only)",
+	schema:  fmt.Sprintf(inflightTraceSpansSchemaPattern, "node_inflight_trace_spans"),
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.node_inflight_trace_spans"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.StatusServer.ListLocalTraceSpans(ctx, &serverpb.ListTraceSpansRequest{})
+		if err != nil {
+			return err
+		}
+		return populateInflightTraceSpansTable(ctx, addRow, response)
+	},
+}
+
+// crdbInternalClusterInflightTraceSpansTable exposes the tracing spans of
+// the entire cluster which have not finished yet.
+var crdbInternalClusterInflightTraceSpansTable = virtualSchemaTable{
+	comment: "active tracing spans (cluster RPC; expensive!)",
+	schema:  fmt.Sprintf(inflightTraceSpansSchemaPattern, "cluster_inflight_trace_spans"),
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.cluster_inflight_trace_spans"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.StatusServer.ListTraceSpans(ctx, &serverpb.ListTraceSpansRequest{})
+		if err != nil {
+			return err
+		}
+		return populateInflightTraceSpansTable(ctx, addRow, response)
+	},
+}
+
+func populateInflightTraceSpansTable(
+	ctx context.Context, addRow func(...tree.Datum) error, response *serverpb.ListTraceSpansResponse,
+) error {
+	now := timeutil.Now()
+	for _, sp := range response.Spans {
+		tags := json.NewObjectBuilder(len(sp.Tags))
+		for k, v := range sp.Tags {
+			tags.Add(k, json.FromString(v))
+		}
+		if err := addRow(
+			tree.NewDInt(tree.DInt(sp.NodeID)),
+			tree.NewDInt(tree.DInt(sp.TraceID)),
+			tree.NewDInt(tree.DInt(sp.SpanID)),
+			tree.NewDInt(tree.DInt(sp.ParentSpanID)),
+			tree.NewDString(sp.Operation),
+			tree.MakeDTimestamp(sp.Start, time.Microsecond),
+			&tree.DInterval{Duration: duration.MakeDuration(now.Sub(sp.Start).Nanoseconds(), 0, 0)},
+			tree.NewDJSON(tags.Build()),
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, rpcErr := range response.Errors {
+		log.Warning(ctx, rpcErr.Message)
+		if rpcErr.NodeID != 0 {
+			// Add a row with this node ID, the error for the operation, and
+			// nulls for all other columns.
+			if err := addRow(
+				tree.NewDInt(tree.DInt(rpcErr.NodeID)), // node ID
+				tree.DNull,                             // trace_id
+				tree.DNull,                             // span_id
+				tree.DNull,                             // parent_span_id
+				tree.NewDString("-- "+rpcErr.Message),  // operation
+				tree.DNull,                             // start
+				tree.DNull,                             // duration
+				tree.DNull,                             // tags
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+const networkLatenciesSchemaPattern = `
+CREATE TABLE crdb_internal.%s (
+  node_id       INT NOT NULL, -- the node which measured the latency
+  peer_node_id  INT,          -- the peer node, or NULL if its address is not that of a known node
+  peer_address  STRING,       -- the address of the peer node
+  latency       INTERVAL,     -- the moving average of the round-trip latency; NULL until there are enough measurements
+  min_latency   INTERVAL,     -- the lowest recent measurement
+  max_latency   INTERVAL,     -- the highest recent measurement
+  samples       INT,          -- the number of recent measurements
+  last_measured TIMESTAMP     -- the time of the latest measurement
+)`
+
+// crdbInternalNodeNetworkLatenciesTable exposes the round-trip latencies
+// measured by the current node to its peers.
+var crdbInternalNodeNetworkLatenciesTable = virtualSchemaTable{
+	comment: "round-trip latencies to other nodes (RAM; local node only)",
+	schema:  fmt.Sprintf(networkLatenciesSchemaPattern, "node_network_latencies"),
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.node_network_latencies"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.StatusServer.ListLocalNetworkLatencies(
+			ctx, &serverpb.ListNetworkLatenciesRequest{})
+		if err != nil {
+			return err
+		}
+		return populateNetworkLatenciesTable(ctx, addRow, response)
+	},
+}
+
+// crdbInternalClusterNetworkLatenciesTable exposes the round-trip latencies
+// measured by every node of the cluster to its peers. Comparing the latency
+// from a node to another with the latency in the opposite direction reveals
+// asymmetric network degradations.
+var crdbInternalClusterNetworkLatenciesTable = virtualSchemaTable{
+	comment: "round-trip latencies between nodes (cluster RPC; expensive!)",
+	schema:  fmt.Sprintf(networkLatenciesSchemaPattern, "cluster_network_latencies"),
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.cluster_network_latencies"); err != nil {
+			return err
+		}
+		response, err := p.extendedEvalCtx.StatusServer.ListNetworkLatencies(
+			ctx, &serverpb.ListNetworkLatenciesRequest{})
+		if err != nil {
+			return err
+		}
+		return populateNetworkLatenciesTable(ctx, addRow, response)
+	},
+}
+
+func populateNetworkLatenciesTable(
+	ctx context.Context,
+	addRow func(...tree.Datum) error,
+	response *serverpb.ListNetworkLatenciesResponse,
+) error {
+	makeInterval := func(nanos int64) tree.Datum {
+		return &tree.DInterval{Duration: duration.MakeDuration(nanos, 0, 0)}
+	}
+	for _, l := range response.Latencies {
+		peerNodeID := tree.DNull
+		if l.PeerNodeID != 0 {
+			peerNodeID = tree.NewDInt(tree.DInt(l.PeerNodeID))
+		}
+		latency := tree.DNull
+		if l.LatencyNanos != 0 {
+			latency = makeInterval(l.LatencyNanos)
+		}
+		minLatency, maxLatency, lastMeasured := tree.DNull, tree.DNull, tree.DNull
+		if len(l.History) > 0 {
+			min, max := l.History[0].LatencyNanos, l.History[0].LatencyNanos
+			for _, sample := range l.History[1:] {
+				if sample.LatencyNanos < min {
+					min = sample.LatencyNanos
+				}
+				if sample.LatencyNanos > max {
+					max = sample.LatencyNanos
+				}
+			}
+			minLatency, maxLatency = makeInterval(min), makeInterval(max)
+			lastMeasured = tree.MakeDTimestamp(l.History[len(l.History)-1].Time, time.Microsecond)
+		}
+		if err := addRow(
+			tree.NewDInt(tree.DInt(l.NodeID)),
+			peerNodeID,
+			tree.NewDString(l.PeerAddress),
+			latency,
+			minLatency,
+			maxLatency,
+			tree.NewDInt(tree.DInt(len(l.History))),
+			lastMeasured,
+		); err != nil {
+			return err
+		}
+	}
+
+	for _, rpcErr := range response.Errors {
+		log.Warning(ctx, rpcErr.Message)
+		if rpcErr.NodeID != 0 {
+			// Add a row with this node ID, the error for the operation, and
+			// nulls for all other columns.
+			if err := addRow(
+				tree.NewDInt(tree.DInt(rpcErr.NodeID)), // node ID
+				tree.DNull,                             // peer_node_id
+				tree.NewDString("-- "+rpcErr.Message),  // peer_address
+				tree.DNull,                             // latency
+				tree.DNull,                             // min_latency
+				tree.DNull,                             // max_latency
+				tree.DNull,                             // samples
+				tree.DNull,                             // last_measured
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// crdbInternalNodeMemoryMonitorsTable exposes the tree of the started memory
+// monitors of the local node, rooted at the SQL root monitor, so that the
+// components (sessions, queries, flows, caches) holding SQL memory can be
+// identified. The parent_id of a monitor refers to the monitor it reserves its
+// budget from, and is NULL for the root.
+var crdbInternalNodeMemoryMonitorsTable = virtualSchemaTable{
+	comment: "memory monitors of the local node and their usage (RAM; local node only)",
+	schema: `
+CREATE TABLE crdb_internal.node_memory_monitors (
+  node_id      INT NOT NULL,
+  level        INT NOT NULL,
+  id           INT NOT NULL,
+  parent_id    INT,
+  name         STRING NOT NULL,
+  used         INT NOT NULL,
+  max_used     INT NOT NULL,
+  reserved     INT NOT NULL,
+  budget       INT NOT NULL
+)`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.node_memory_monitors"); err != nil {
+			return err
+		}
+		root := p.ExecCfg().RootMemoryMonitor
+		if root == nil {
+			return nil
+		}
+		nodeID := tree.NewDInt(tree.DInt(int64(p.ExecCfg().NodeID.Get())))
+		return root.TraverseTree(func(s mon.MonitorState) error {
+			parentID := tree.DNull
+			if s.ParentID != 0 {
+				parentID = tree.NewDInt(tree.DInt(s.ParentID))
+			}
+			return addRow(
+				nodeID,
+				tree.NewDInt(tree.DInt(s.Level)),
+				tree.NewDInt(tree.DInt(s.ID)),
+				parentID,
+				tree.NewDString(s.Name),
+				tree.NewDInt(tree.DInt(s.Used)),
+				tree.NewDInt(tree.DInt(s.MaxUsed)),
+				tree.NewDInt(tree.DInt(s.Reserved)),
+				tree.NewDInt(tree.DInt(s.Budget)),
+			)
+		})
+	},
+}
+
 // crdbInternalLocalMetricsTable exposes a snapshot of the metrics on the
 // current node.
 var crdbInternalLocalMetricsTable = virtualSchemaTable{
@@ -1909,6 +2664,219 @@ CREATE TABLE crdb_internal.zones (
 	},
 }
 
+// crdbInternalConstraintViolationsTable reports the constraints and lease
+// preferences of the zone configs which are violated by some of their ranges,
+// as of the last check of the constraint conformance job. It is empty while
+// the job is not running, see kv.constraint_conformance.interval.
+var crdbInternalConstraintViolationsTable = virtualSchemaTable{
+	comment: "zone config constraints and lease preferences violated by ranges, as of the last check of the constraint conformance job (KV scan)",
+	schema: `
+CREATE TABLE crdb_internal.constraint_violations (
+  zone_id          INT NOT NULL,
+  zone_name        STRING,
+  type             STRING NOT NULL,
+  config           STRING NOT NULL,
+  violating_ranges INT NOT NULL,
+  example_range_id INT NOT NULL,
+  violation_start  TIMESTAMP NOT NULL,
+  duration         INTERVAL NOT NULL,
+  checked_at       TIMESTAMP NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		if err := p.RequireSuperUser(ctx, "read crdb_internal.constraint_violations"); err != nil {
+			return err
+		}
+		execCfg := p.ExecCfg()
+		ids, err := liveConstraintConformanceJobs(ctx, execCfg, p.txn)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		row, err := execCfg.InternalExecutor.QueryRow(
+			ctx, "crdb-internal-constraint-violations-table", p.txn,
+			`SELECT progress FROM system.jobs WHERE id = $1`, ids[0])
+		if err != nil || row == nil {
+			return err
+		}
+		progress, err := jobs.UnmarshalProgress(row[0])
+		if err != nil {
+			return err
+		}
+		report := progress.GetConstraintConformance()
+		if report == nil || report.CheckedMicros == 0 {
+			return nil
+		}
+
+		namespace, err := p.getAllNames(ctx)
+		if err != nil {
+			return err
+		}
+		resolveID := func(id uint32) (parentID uint32, name string, err error) {
+			if entry, ok := namespace[sqlbase.ID(id)]; ok {
+				return uint32(entry.parentID), entry.name, nil
+			}
+			return 0, "", pgerror.NewAssertionErrorf(
+				"object with ID %d does not exist", log.Safe(id))
+		}
+		zoneName := func(v *jobspb.ConstraintConformanceProgress_Violation) tree.Datum {
+			zs, err := config.ZoneSpecifierFromID(v.ZoneID, resolveID)
+			if err != nil {
+				// The object has been dropped since the check.
+				return tree.DNull
+			}
+			if v.IndexID != 0 {
+				table, err := sqlbase.GetTableDescFromID(ctx, p.txn, sqlbase.ID(v.ZoneID))
+				if err != nil {
+					return tree.DNull
+				}
+				index, err := table.FindIndexByID(sqlbase.IndexID(v.IndexID))
+				if err != nil {
+					return tree.DNull
+				}
+				zs.TableOrIndex.Index = tree.UnrestrictedName(index.Name)
+				zs.Partition = tree.Name(v.Partition)
+			}
+			return tree.NewDString(config.CLIZoneSpecifier(&zs))
+		}
+
+		checked := timeutil.FromUnixMicros(report.CheckedMicros)
+		checkedAt := tree.MakeDTimestamp(checked, time.Microsecond)
+		for i := range report.Violations {
+			v := &report.Violations[i]
+			start := timeutil.FromUnixMicros(v.StartMicros)
+			if err := addRow(
+				tree.NewDInt(tree.DInt(v.ZoneID)),
+				zoneName(v),
+				tree.NewDString(strings.ToLower(v.Type.String())),
+				tree.NewDString(v.Config),
+				tree.NewDInt(tree.DInt(v.ViolatingRanges)),
+				tree.NewDInt(tree.DInt(v.ExampleRangeID)),
+				tree.MakeDTimestamp(start, time.Microsecond),
+				&tree.DInterval{Duration: duration.MakeDuration(checked.Sub(start).Nanoseconds(), 0, 0)},
+				checkedAt,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// crdbInternalSuperRegionViolationsTable reports the zone configs whose
+// replicas may leave the super regions in which they are homed. The zone
+// configs are validated when they are set, but they can be invalidated
+// later by a change to the super regions or to the zone configs they
+// inherit from.
+var crdbInternalSuperRegionViolationsTable = virtualSchemaTable{
+	comment: "zone configurations violating the super regions of sql.zone_configs.super_regions (KV scan)",
+	schema: `
+CREATE TABLE crdb_internal.super_region_violations (
+  zone_id      INT NOT NULL,
+  zone_name    STRING,
+  super_region STRING NOT NULL,
+  error        STRING NOT NULL
+)
+`,
+	populate: func(ctx context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		superRegions, err := parseSuperRegions(superRegionsSetting.Get(&p.ExecCfg().Settings.SV))
+		if err != nil {
+			return err
+		}
+		if len(superRegions) == 0 {
+			return nil
+		}
+		namespace, err := p.getAllNames(ctx)
+		if err != nil {
+			return err
+		}
+		resolveID := func(id uint32) (parentID uint32, name string, err error) {
+			if entry, ok := namespace[sqlbase.ID(id)]; ok {
+				return uint32(entry.parentID), entry.name, nil
+			}
+			return 0, "", pgerror.NewAssertionErrorf(
+				"object with ID %d does not exist", log.Safe(id))
+		}
+		addViolation := func(id tree.Datum, zs *tree.ZoneSpecifier, zone *config.ZoneConfig) error {
+			superRegion, err := superRegions.check(zone)
+			if err == nil {
+				return nil
+			}
+			zoneName := tree.DNull
+			if zs != nil {
+				zoneName = tree.NewDString(config.CLIZoneSpecifier(zs))
+			}
+			return addRow(id, zoneName, tree.NewDString(superRegion), tree.NewDString(err.Error()))
+		}
+
+		rows, err := p.ExtendedEvalContext().ExecCfg.InternalExecutor.Query(
+			ctx, "crdb-internal-super-region-violations-table", p.txn, `SELECT id, config FROM system.zones`)
+		if err != nil {
+			return err
+		}
+		for _, r := range rows {
+			id := uint32(tree.MustBeDInt(r[0]))
+
+			var zoneSpecifier *tree.ZoneSpecifier
+			zs, err := config.ZoneSpecifierFromID(id, resolveID)
+			if err == nil {
+				zoneSpecifier = &zs
+			}
+
+			var configProto config.ZoneConfig
+			if err := protoutil.Unmarshal([]byte(*r[1].(*tree.DBytes)), &configProto); err != nil {
+				return err
+			}
+
+			// The zone configs are checked once their missing fields have been
+			// inherited from their parents.
+			if !configProto.IsSubzonePlaceholder() {
+				_, zone, _, err := GetZoneConfigInTxn(ctx, p.txn,
+					id, nil /* index */, "" /* partition */, false /* getInheritedDefault */)
+				if err != nil {
+					return err
+				}
+				if err := addViolation(r[0], zoneSpecifier, zone); err != nil {
+					return err
+				}
+			}
+
+			if len(configProto.Subzones) > 0 {
+				table, err := sqlbase.GetTableDescFromID(ctx, p.txn, sqlbase.ID(id))
+				if err != nil {
+					return err
+				}
+				for _, s := range configProto.Subzones {
+					index, err := table.FindIndexByID(sqlbase.IndexID(s.IndexID))
+					if err != nil {
+						if err == sqlbase.ErrIndexGCMutationsList {
+							continue
+						}
+						return err
+					}
+					_, _, subzone, err := GetZoneConfigInTxn(ctx, p.txn,
+						id, index, s.PartitionName, false /* getInheritedDefault */)
+					if err != nil {
+						return err
+					}
+					if subzone == nil {
+						continue
+					}
+					if zoneSpecifier != nil {
+						zs := zs
+						zs.TableOrIndex.Index = tree.UnrestrictedName(index.Name)
+						zs.Partition = tree.Name(s.PartitionName)
+						zoneSpecifier = &zs
+					}
+					if err := addViolation(r[0], zoneSpecifier, &subzone.Config); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	},
+}
+
 // crdbInternalGossipNodesTable exposes local information about the cluster nodes.
 var crdbInternalGossipNodesTable = virtualSchemaTable{
 	comment: "locally known gossiped node details (RAM; local node only)",
@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type createDatabaseNode struct {
@@ -92,11 +93,13 @@ func (n *createDatabaseNode) startExec(params runParams) error {
 			EventLogCreateDatabase,
 			int32(desc.ID),
 			int32(params.extendedEvalCtx.NodeID),
-			struct {
-				DatabaseName string
-				Statement    string
-				User         string
-			}{n.n.Name.String(), n.n.String(), params.SessionData().User},
+			&eventpb.CreateDatabase{
+				DatabaseName: n.n.Name.String(),
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					Statement: n.n.String(),
+					User:      params.SessionData().User,
+				},
+			},
 		); err != nil {
 			return err
 		}
@@ -0,0 +1,140 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type createFunctionNode struct {
+	n      *tree.CreateFunction
+	tn     tree.TableName
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateFunction creates a user-defined function. Functions are stored in the
+// descriptor of their database, like procedures, and can be overloaded with
+// different parameter types.
+// Privileges: CREATE on database.
+//   notes: postgres requires CREATE on the schema.
+func (p *planner) CreateFunction(ctx context.Context, n *tree.CreateFunction) (planNode, error) {
+	if n.Name.NumParts > 3 || n.Name.Star {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidNameError,
+			"invalid function name: %s", &n.Name)
+	}
+	tn := n.Name.ToTableName()
+	dbDesc, err := p.ResolveUncachedDatabase(ctx, &tn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	// The names of built-in functions are resolved first, so a function with
+	// the same name could not be called.
+	name := tree.NewUnresolvedName(tn.Table())
+	if name.ResolveBuiltinFunction(p.CurrentSearchPath()) != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeDuplicateFunctionError,
+			"function %q is a built-in function", tn.Table())
+	}
+
+	return &createFunctionNode{n: n, tn: tn, dbDesc: dbDesc}, nil
+}
+
+func (n *createFunctionNode) startExec(params runParams) error {
+	routine := sqlbase.RoutineDescriptor{
+		Name: n.tn.Table(),
+		Kind: sqlbase.RoutineDescriptor_FUNCTION,
+		Body: n.n.Body,
+	}
+	if n.n.Language == tree.FunctionLangPLpgSQL {
+		routine.Language = sqlbase.RoutineDescriptor_PLPGSQL
+	}
+	var err error
+	if routine.Params, err = routineParams(n.n.Params); err != nil {
+		return err
+	}
+	if routine.ReturnType, err = routineColumnType(n.n.ReturnType); err != nil {
+		return err
+	}
+
+	if err := n.addOrReplace(routine); err != nil {
+		return err
+	}
+	if err := params.p.writeDatabaseRoutines(params.ctx, n.dbDesc); err != nil {
+		return err
+	}
+
+	// Record this function creation in the event log. This is an auditable
+	// log event and is recorded in the same transaction as the database
+	// descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreateFunction,
+		int32(n.dbDesc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		&eventpb.CreateFunction{
+			FunctionName: n.tn.FQString(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+		},
+	)
+}
+
+// addOrReplace adds a function to the routines of the database. With OR
+// REPLACE, an existing function with the same parameter types is replaced,
+// provided that it returns the same type.
+func (n *createFunctionNode) addOrReplace(routine sqlbase.RoutineDescriptor) error {
+	for i := range n.dbDesc.Routines {
+		existing := &n.dbDesc.Routines[i]
+		if existing.Name != routine.Name {
+			continue
+		}
+		if existing.Kind != sqlbase.RoutineDescriptor_FUNCTION {
+			return pgerror.NewErrorf(pgerror.CodeDuplicateFunctionError,
+				"a procedure named %q already exists", routine.Name)
+		}
+		if !existing.HasSameParamTypes(&routine) {
+			continue
+		}
+		if !n.n.Replace {
+			return pgerror.NewErrorf(pgerror.CodeDuplicateFunctionError,
+				"function %q already exists with the same parameter types", routine.Name)
+		}
+		if !existing.ReturnType.ToDatumType().Equivalent(routine.ReturnType.ToDatumType()) {
+			return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+				"cannot change the return type of existing function %q", routine.Name)
+		}
+		*existing = routine
+		return nil
+	}
+	n.dbDesc.Routines = append(n.dbDesc.Routines, routine)
+	return nil
+}
+
+func (n *createFunctionNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createFunctionNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createFunctionNode) Close(context.Context)        {}
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type createIndexNode struct {
@@ -146,15 +147,14 @@ func (n *createIndexNode) startExec(params runParams) error {
 		EventLogCreateIndex,
 		int32(n.tableDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName  string
-			IndexName  string
-			Statement  string
-			User       string
-			MutationID uint32
-		}{
-			n.n.Table.FQString(), indexName, n.n.String(),
-			params.SessionData().User, uint32(mutationID),
+		&eventpb.CreateIndex{
+			TableName: n.n.Table.FQString(),
+			IndexName: indexName,
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+			MutationID: uint32(mutationID),
 		},
 	)
 }
@@ -0,0 +1,188 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type createProcedureNode struct {
+	n      *tree.CreateProcedure
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateProcedure creates a procedure. Procedures are stored in the
+// descriptor of their database.
+// Privileges: CREATE on database.
+//   notes: postgres requires CREATE on the schema.
+func (p *planner) CreateProcedure(ctx context.Context, n *tree.CreateProcedure) (planNode, error) {
+	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &createProcedureNode{n: n, dbDesc: dbDesc}, nil
+}
+
+func (n *createProcedureNode) startExec(params runParams) error {
+	routine := sqlbase.RoutineDescriptor{
+		Name: n.n.Name.Table(),
+		Kind: sqlbase.RoutineDescriptor_PROCEDURE,
+		Body: n.n.Body,
+	}
+	if n.n.Language == tree.FunctionLangPLpgSQL {
+		routine.Language = sqlbase.RoutineDescriptor_PLPGSQL
+	}
+	var err error
+	if routine.Params, err = routineParams(n.n.Params); err != nil {
+		return err
+	}
+
+	if existing, idx := n.dbDesc.FindRoutineByName(routine.Name); idx != -1 {
+		if existing.Kind != sqlbase.RoutineDescriptor_PROCEDURE {
+			return pgerror.NewErrorf(pgerror.CodeDuplicateFunctionError,
+				"a function named %q already exists", routine.Name)
+		}
+		if !n.n.Replace {
+			return pgerror.NewErrorf(pgerror.CodeDuplicateFunctionError,
+				"procedure %q already exists", routine.Name)
+		}
+		n.dbDesc.Routines[idx] = routine
+	} else {
+		n.dbDesc.Routines = append(n.dbDesc.Routines, routine)
+	}
+	if err := params.p.writeDatabaseRoutines(params.ctx, n.dbDesc); err != nil {
+		return err
+	}
+
+	// Record this procedure creation in the event log. This is an auditable
+	// log event and is recorded in the same transaction as the database
+	// descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreateProcedure,
+		int32(n.dbDesc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		&eventpb.CreateProcedure{
+			ProcedureName: n.n.Name.FQString(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+		},
+	)
+}
+
+func (n *createProcedureNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createProcedureNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createProcedureNode) Close(context.Context)        {}
+
+// routineParams converts the parameters of a routine to the parameters stored
+// in the routine descriptor.
+func routineParams(params tree.FunctionParams) ([]sqlbase.RoutineDescriptor_Param, error) {
+	res := make([]sqlbase.RoutineDescriptor_Param, len(params))
+	for i, param := range params {
+		for _, prev := range params[:i] {
+			if param.Name != "" && param.Name == prev.Name {
+				return nil, pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+					"parameter name %q used more than once", param.Name)
+			}
+		}
+		typ, err := routineColumnType(param.Type)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = sqlbase.RoutineDescriptor_Param{Name: string(param.Name), Type: typ}
+	}
+	return res, nil
+}
+
+// routineColumnType converts the type of a routine parameter, or of the value
+// returned by a function, to the column type stored in the routine descriptor.
+func routineColumnType(t coltypes.CastTargetType) (sqlbase.ColumnType, error) {
+	colTyp, err := sqlbase.DatumTypeToColumnType(coltypes.CastTargetToDatumType(t))
+	if err != nil {
+		return sqlbase.ColumnType{}, err
+	}
+	if ct, ok := t.(coltypes.T); ok {
+		return sqlbase.PopulateTypeAttrs(colTyp, ct)
+	}
+	return colTyp, nil
+}
+
+// resolveProcedure resolves the name of an existing procedure, and returns
+// the descriptor of its database and the procedure, or nil if the procedure
+// does not exist.
+func (p *planner) resolveProcedure(
+	ctx context.Context, tn *ObjectName,
+) (dbDesc *sqlbase.DatabaseDescriptor, routine *sqlbase.RoutineDescriptor, idx int, err error) {
+	dbDesc, err = p.resolveRoutineDatabase(ctx, tn)
+	if err != nil || dbDesc == nil {
+		return nil, nil, -1, err
+	}
+	routine, idx = dbDesc.FindRoutineByName(tn.Table())
+	if routine == nil || routine.Kind != sqlbase.RoutineDescriptor_PROCEDURE {
+		return dbDesc, nil, -1, nil
+	}
+	return dbDesc, routine, idx, nil
+}
+
+// resolveRoutineDatabase resolves the prefix of the name of an existing
+// routine, and returns the descriptor of the database which stores the
+// routine, or nil if there is no such database.
+func (p *planner) resolveRoutineDatabase(
+	ctx context.Context, tn *ObjectName,
+) (*sqlbase.DatabaseDescriptor, error) {
+	var found bool
+	var scMeta tree.SchemaMeta
+	var err error
+	p.runWithOptions(resolveFlags{skipCache: true}, func() {
+		found, scMeta, err = tn.ResolveTarget(ctx, p, p.CurrentDatabase(), p.CurrentSearchPath())
+	})
+	if err != nil || !found || tn.Schema() != tree.PublicSchema {
+		return nil, err
+	}
+	return scMeta.(*sqlbase.DatabaseDescriptor), nil
+}
+
+// writeDatabaseRoutines writes the descriptor of a database whose routines
+// were changed.
+func (p *planner) writeDatabaseRoutines(
+	ctx context.Context, dbDesc *sqlbase.DatabaseDescriptor,
+) error {
+	if err := dbDesc.Validate(); err != nil {
+		return err
+	}
+
+	descKey := sqlbase.MakeDescMetadataKey(dbDesc.ID)
+	descDesc := sqlbase.WrapDescriptor(dbDesc)
+	if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+		log.VEventf(ctx, 2, "Put %s -> %s", descKey, descDesc)
+	}
+	return p.txn.Put(ctx, descKey, descDesc)
+}
@@ -0,0 +1,127 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+type createScheduleNode struct {
+	n     *tree.CreateSchedule
+	owner string
+
+	run createScheduleRun
+}
+
+type createScheduleRun struct {
+	rowsAffected int
+}
+
+// CreateSchedule creates a schedule.
+// Privileges: superuser.
+func (p *planner) CreateSchedule(ctx context.Context, n *tree.CreateSchedule) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "CREATE SCHEDULE"); err != nil {
+		return nil, err
+	}
+
+	if _, err := parser.ParseOne(n.Statement); err != nil {
+		return nil, pgerror.Wrapf(err, pgerror.CodeSyntaxError,
+			"invalid statement for schedule %q", n.Name)
+	}
+	if _, err := parseRecurrence(n.Recurrence); err != nil {
+		return nil, err
+	}
+
+	owner := string(n.Owner)
+	if owner == "" {
+		owner = p.User()
+	}
+	return &createScheduleNode{n: n, owner: owner}, nil
+}
+
+// parseRecurrence parses the cron expression of a schedule, and checks that
+// it matches at least once.
+func parseRecurrence(recurrence string) (*cron.Schedule, error) {
+	s, err := cron.Parse(recurrence)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgerror.CodeInvalidParameterValueError, "invalid recurrence")
+	}
+	if s.Next(timeutil.Now()).IsZero() {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"recurrence %q never matches", recurrence)
+	}
+	return s, nil
+}
+
+func (n *createScheduleNode) startExec(params runParams) error {
+	ie := params.extendedEvalCtx.ExecCfg.InternalExecutor
+	row, err := ie.QueryRow(
+		params.ctx, "create-schedule-owner", params.p.txn,
+		`SELECT 1 FROM system.users WHERE username = $1`, n.owner,
+	)
+	if err != nil {
+		return err
+	}
+	if row == nil {
+		return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"user or role %s does not exist", n.owner)
+	}
+
+	row, err = ie.QueryRow(
+		params.ctx, "create-schedule-lookup", params.p.txn,
+		`SELECT 1 FROM system.schedules WHERE name = $1`, string(n.n.Name),
+	)
+	if err != nil {
+		return err
+	}
+	if row != nil {
+		if n.n.IfNotExists {
+			return nil
+		}
+		return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+			"schedule %q already exists", n.n.Name)
+	}
+
+	s, err := parseRecurrence(n.n.Recurrence)
+	if err != nil {
+		return err
+	}
+	n.run.rowsAffected, err = ie.Exec(
+		params.ctx, "create-schedule", params.p.txn,
+		`INSERT INTO system.schedules (name, owner, database_name, statement, recurrence, next_run)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		string(n.n.Name), n.owner, params.p.CurrentDatabase(), n.n.Statement, n.n.Recurrence,
+		s.Next(timeutil.Now()),
+	)
+	return err
+}
+
+// Next implements the planNode interface.
+func (*createScheduleNode) Next(runParams) (bool, error) { return false, nil }
+
+// Values implements the planNode interface.
+func (*createScheduleNode) Values() tree.Datums { return tree.Datums{} }
+
+// Close implements the planNode interface.
+func (*createScheduleNode) Close(context.Context) {}
+
+// FastPathResults implements the planNodeFastPath interface.
+func (n *createScheduleNode) FastPathResults() (int, bool) { return n.run.rowsAffected, true }
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type createSequenceNode struct {
@@ -117,11 +118,13 @@ func doCreateSequence(
 		EventLogCreateSequence,
 		int32(desc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			SequenceName string
-			Statement    string
-			User         string
-		}{name.FQString(), context, params.SessionData().User},
+		&eventpb.CreateSequence{
+			SequenceName: name.FQString(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: context,
+				User:      params.SessionData().User,
+			},
+		},
 	)
 }
 
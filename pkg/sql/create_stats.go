@@ -17,6 +17,7 @@ package sql
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/jobs"
@@ -28,12 +29,14 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -152,7 +155,13 @@ func (n *createStatsNode) startJob(ctx context.Context, resultsCh chan<- tree.Da
 
 	// Identify which columns we should create statistics for.
 	var createStatsColLists []jobspb.CreateStatsDetails_ColList
-	if len(n.ColumnNames) == 0 {
+	if n.Expr != nil {
+		colList, err := createStatsExprColumns(ctx, &n.p.semaCtx, tableDesc, n.Expr)
+		if err != nil {
+			return err
+		}
+		createStatsColLists = []jobspb.CreateStatsDetails_ColList{colList}
+	} else if len(n.ColumnNames) == 0 {
 		if createStatsColLists, err = createStatsDefaultColumns(tableDesc); err != nil {
 			return err
 		}
@@ -168,11 +177,30 @@ func (n *createStatsNode) startJob(ctx context.Context, resultsCh chan<- tree.Da
 				return pgerror.UnimplementedWithIssueErrorf(35844,
 					"CREATE STATISTICS is not supported for JSON columns")
 			}
+			if columns[i].Virtual {
+				return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+					"CREATE STATISTICS is not supported for virtual computed column %q; "+
+						"use ON (%s) instead", columns[i].Name, *columns[i].ComputeExpr)
+			}
 			columnIDs[i] = columns[i].ID
 		}
 		createStatsColLists = []jobspb.CreateStatsDetails_ColList{{IDs: columnIDs}}
 	}
 
+	// Determine the rows of a partial statistic, if any.
+	var predicate string
+	if n.Options.UsingExtremes {
+		if n.Expr != nil || len(createStatsColLists) != 1 || len(createStatsColLists[0].IDs) != 1 {
+			return pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+				"USING EXTREMES requires a single column")
+		}
+		if predicate, err = createStatsExtremesPredicate(
+			ctx, n.p, tableDesc, createStatsColLists[0].IDs[0],
+		); err != nil {
+			return err
+		}
+	}
+
 	// Evaluate the AS OF time, if any.
 	var asOf *hlc.Timestamp
 	if n.Options.AsOf.Expr != nil {
@@ -219,6 +247,7 @@ func (n *createStatsNode) startJob(ctx context.Context, resultsCh chan<- tree.Da
 			Statement:       n.String(),
 			AsOf:            asOf,
 			MaxFractionIdle: n.Options.Throttling,
+			Predicate:       predicate,
 		},
 		Progress: jobspb.CreateStatsProgress{},
 	})
@@ -289,10 +318,12 @@ func createStatsDefaultColumns(
 	}
 
 	// Add all remaining non-json columns in the table, up to maxNonIndexCols.
+	// Virtual columns are skipped, since their values are not stored.
 	nonIdxCols := 0
 	for i := 0; i < len(desc.Columns) && nonIdxCols < maxNonIndexCols; i++ {
 		col := &desc.Columns[i]
-		if col.Type.SemanticType != sqlbase.ColumnType_JSONB && !requestedCols.Contains(int(col.ID)) {
+		if col.Type.SemanticType != sqlbase.ColumnType_JSONB && !col.Virtual &&
+			!requestedCols.Contains(int(col.ID)) {
 			columns = append(
 				columns, jobspb.CreateStatsDetails_ColList{IDs: []sqlbase.ColumnID{col.ID}},
 			)
@@ -303,6 +334,115 @@ func createStatsDefaultColumns(
 	return columns, nil
 }
 
+// createStatsExprColumns returns the column list of the statistics on an
+// expression of the columns of the table. The expression must be immutable,
+// so that the statistics describe the values it takes when evaluated by a
+// query. It is recorded with unqualified column names.
+func createStatsExprColumns(
+	ctx context.Context,
+	semaCtx *tree.SemaContext,
+	desc *ImmutableTableDescriptor,
+	expr tree.Expr,
+) (jobspb.CreateStatsDetails_ColList, error) {
+	sources := sqlbase.MultiSourceInfo{sqlbase.NewSourceInfoForSingleTable(
+		tree.MakeUnqualifiedTableName(tree.Name(desc.Name)),
+		sqlbase.ResultColumnsFromColDescs(desc.Columns),
+	)}
+	expr, err := dequalifyColumnRefs(ctx, sources, expr)
+	if err != nil {
+		return jobspb.CreateStatsDetails_ColList{}, err
+	}
+
+	// Replace column references with typed dummies to allow typechecking.
+	replacedExpr, colIDs, err := replaceVars(
+		sqlbase.NewMutableExistingTableDescriptor(desc.TableDescriptor), expr,
+	)
+	if err != nil {
+		return jobspb.CreateStatsDetails_ColList{}, err
+	}
+	if len(colIDs) == 0 {
+		return jobspb.CreateStatsDetails_ColList{}, pgerror.NewError(
+			pgerror.CodeInvalidParameterValueError,
+			"statistics expression must reference at least one column")
+	}
+	typedExpr, err := sqlbase.SanitizeVarFreeExpr(
+		replacedExpr, types.Any, "statistics expression", semaCtx, false, /* allowImpure */
+	)
+	if err != nil {
+		return jobspb.CreateStatsDetails_ColList{}, err
+	}
+	if typedExpr.ResolvedType().Equivalent(types.JSON) {
+		return jobspb.CreateStatsDetails_ColList{}, pgerror.UnimplementedWithIssueErrorf(35844,
+			"CREATE STATISTICS is not supported for JSON expressions")
+	}
+
+	colList := jobspb.CreateStatsDetails_ColList{
+		IDs:        make([]sqlbase.ColumnID, 0, len(colIDs)),
+		Expression: tree.Serialize(expr),
+	}
+	for id := range colIDs {
+		colList.IDs = append(colList.IDs, id)
+	}
+	sort.Slice(colList.IDs, func(i, j int) bool { return colList.IDs[i] < colList.IDs[j] })
+	return colList, nil
+}
+
+// createStatsExtremesPredicate returns the predicate of the rows of a partial
+// statistic collected USING EXTREMES on the given column: the rows whose value
+// is outside of the range of the values seen by the most recent full
+// statistic on the column. Those are typically the rows inserted since that
+// statistic at the end of an ascending key, e.g. a timestamp.
+//
+// The range is determined by reading the table as of the creation time of the
+// full statistic, which must hence be more recent than the GC TTL.
+func createStatsExtremesPredicate(
+	ctx context.Context, p *planner, desc *ImmutableTableDescriptor, colID sqlbase.ColumnID,
+) (string, error) {
+	col, err := desc.FindColumnByID(colID)
+	if err != nil {
+		return "", err
+	}
+	tableStats, err := p.ExecCfg().TableStatsCache.GetTableStats(ctx, desc.ID)
+	if err != nil {
+		return "", err
+	}
+	var full *stats.TableStatistic
+	for _, stat := range tableStats {
+		if len(stat.ColumnIDs) == 1 && stat.ColumnIDs[0] == colID &&
+			stat.Expression == "" && stat.Predicate == "" {
+			full = stat
+			break
+		}
+	}
+	if full == nil {
+		return "", pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"USING EXTREMES requires full statistics on column %q", col.Name)
+	}
+
+	row, err := p.ExecCfg().InternalExecutor.QueryRow(
+		ctx, "create-stats-extremes", nil, /* txn */
+		fmt.Sprintf(`SELECT min(%[1]s), max(%[1]s) FROM [%[2]d AS t] AS OF SYSTEM TIME '%[3]d'`,
+			tree.NameString(col.Name), desc.ID, full.CreatedAt.UnixNano()),
+	)
+	if err != nil {
+		return "", pgerror.Wrapf(err, pgerror.CodeObjectNotInPrerequisiteStateError,
+			"cannot determine the values seen by the statistics on column %q", col.Name)
+	}
+
+	colRef := &tree.ColumnItem{ColumnName: tree.Name(col.Name)}
+	var pred tree.Expr
+	if row[0] == tree.DNull {
+		// The full statistic did not see any value.
+		pred = &tree.ComparisonExpr{Operator: tree.IsDistinctFrom, Left: colRef, Right: tree.DNull}
+	} else {
+		pred = &tree.OrExpr{
+			Left:  &tree.ComparisonExpr{Operator: tree.LT, Left: colRef, Right: row[0]},
+			Right: &tree.ComparisonExpr{Operator: tree.GT, Left: colRef, Right: row[1]},
+		}
+	}
+	return tree.Serialize(pred), nil
+}
+
 // createStatsResumer implements the jobs.Resumer interface for CreateStats
 // jobs. A new instance is created for each job. evalCtx is populated inside
 // createStatsResumer.Resume so it can be used in createStatsResumer.OnSuccess
@@ -461,10 +601,12 @@ func (r *createStatsResumer) OnSuccess(ctx context.Context, _ *client.Txn) error
 			EventLogCreateStatistics,
 			int32(details.Table.ID),
 			int32(r.evalCtx.NodeID),
-			struct {
-				TableName string
-				Statement string
-			}{details.FQTableName, details.Statement},
+			&eventpb.CreateStatistics{
+				TableName: details.FQTableName,
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					Statement: details.Statement,
+				},
+			},
 		)
 	})
 }
@@ -34,6 +34,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/pkg/errors"
 )
 
@@ -201,11 +202,13 @@ func (n *createTableNode) startExec(params runParams) error {
 		EventLogCreateTable,
 		int32(desc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			TableName string
-			Statement string
-			User      string
-		}{n.n.Table.FQString(), n.n.String(), params.SessionData().User},
+		&eventpb.CreateTable{
+			TableName: n.n.Table.FQString(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+		},
 	); err != nil {
 		return err
 	}
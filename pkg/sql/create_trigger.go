@@ -0,0 +1,122 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type createTriggerNode struct {
+	n         *tree.CreateTrigger
+	tableDesc *sqlbase.MutableTableDescriptor
+}
+
+// CreateTrigger creates a trigger.
+// Privileges: CREATE on table.
+//   notes: postgres requires TRIGGER on the table.
+func (p *planner) CreateTrigger(ctx context.Context, n *tree.CreateTrigger) (planNode, error) {
+	tableDesc, err := p.ResolveMutableTableDescriptor(ctx, &n.Table, true /* required */, requireTableDesc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := p.CheckPrivilege(ctx, tableDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &createTriggerNode{n: n, tableDesc: tableDesc}, nil
+}
+
+func (n *createTriggerNode) startExec(params runParams) error {
+	if _, _, err := n.tableDesc.FindTriggerByName(string(n.n.Name)); err == nil {
+		return pgerror.NewErrorf(pgerror.CodeDuplicateObjectError,
+			"trigger %q for relation %q already exists", n.n.Name, n.tableDesc.Name)
+	}
+
+	trigger := sqlbase.TriggerDescriptor{
+		Name: string(n.n.Name),
+		Body: n.n.Body,
+	}
+	if n.n.ActionTime == tree.TriggerAfter {
+		trigger.ActionTime = sqlbase.TriggerDescriptor_AFTER
+	}
+	if n.n.Level == tree.TriggerStatement {
+		trigger.Level = sqlbase.TriggerDescriptor_STATEMENT
+	}
+	for _, event := range n.n.Events {
+		e := triggerEventToDesc(event)
+		if trigger.HasEvent(e) {
+			return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"trigger event %s specified more than once", event)
+		}
+		trigger.Events = append(trigger.Events, e)
+	}
+
+	body, err := parser.ParseTriggerBody(n.n.Body)
+	if err != nil {
+		return err
+	}
+	if err := validateTriggerBody(n.tableDesc.TableDesc(), &trigger, body); err != nil {
+		return err
+	}
+
+	n.tableDesc.Triggers = append(n.tableDesc.Triggers, trigger)
+	if err := params.p.writeSchemaChange(params.ctx, n.tableDesc, sqlbase.InvalidMutationID); err != nil {
+		return err
+	}
+
+	// Record this trigger creation in the event log. This is an auditable log
+	// event and is recorded in the same transaction as the table descriptor
+	// update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogCreateTrigger,
+		int32(n.tableDesc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		&eventpb.CreateTrigger{
+			TableName:   n.n.Table.FQString(),
+			TriggerName: trigger.Name,
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+		},
+	)
+}
+
+func (n *createTriggerNode) Next(runParams) (bool, error) { return false, nil }
+func (n *createTriggerNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *createTriggerNode) Close(context.Context)        {}
+
+// triggerEventToDesc converts a trigger event to its descriptor
+// representation.
+func triggerEventToDesc(event tree.TriggerEvent) sqlbase.TriggerDescriptor_Event {
+	switch event {
+	case tree.TriggerUpdate:
+		return sqlbase.TriggerDescriptor_UPDATE
+	case tree.TriggerDelete:
+		return sqlbase.TriggerDescriptor_DELETE
+	default:
+		return sqlbase.TriggerDescriptor_INSERT
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"regexp"
 
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -43,7 +44,17 @@ var userTableName = tree.NewTableName("system", "users")
 //   notes: postgres allows the creation of users with an empty password. We do
 //          as well, but disallow password authentication for these users.
 func (p *planner) CreateUser(ctx context.Context, n *tree.CreateUser) (planNode, error) {
-	return p.CreateUserNode(ctx, n.Name, n.Password, n.IfNotExists, false /* isRole */, "CREATE USER")
+	node, err := p.CreateUserNode(ctx, n.Name, n.Password, n.IfNotExists, false /* isRole */, "CREATE USER")
+	if err != nil {
+		return nil, err
+	}
+	if n.ValidUntil != nil {
+		node.validUntil, err = p.TypeAsString(n.ValidUntil, "CREATE USER")
+		if err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
 }
 
 // CreateUserNode creates a "create user" plan node. This can be called from CREATE USER or CREATE ROLE.
@@ -72,7 +83,7 @@ func (p *planner) CreateUserNode(
 }
 
 func (n *CreateUserNode) startExec(params runParams) error {
-	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve()
+	normalizedUsername, hashedPassword, err := n.userAuthInfo.resolve(&params.extendedEvalCtx.Settings.SV)
 	if err != nil {
 		return err
 	}
@@ -137,6 +148,23 @@ func (n *CreateUserNode) startExec(params runParams) error {
 		)
 	}
 
+	LogRoleChange(params.ctx, params.extendedEvalCtx.ExecCfg, AuthLogCreateRole, params.p.User(),
+		struct {
+			RoleName    string
+			IsRole      bool
+			HasPassword bool
+		}{normalizedUsername, n.isRole, len(hashedPassword) > 0})
+
+	if n.validUntil != nil {
+		validUntil, err := resolveValidUntil(n.validUntil)
+		if err != nil {
+			return err
+		}
+		return setPasswordValidUntil(
+			params.ctx, params.extendedEvalCtx.ExecCfg.InternalExecutor, params.p.txn,
+			normalizedUsername, validUntil,
+		)
+	}
 	return nil
 }
 
@@ -194,6 +222,8 @@ var errNoUserNameSpecified = errors.New("no username specified")
 type userAuthInfo struct {
 	name     func() (string, error)
 	password func() (string, error)
+	// validUntil is nil if no expiration was specified for the password.
+	validUntil func() (string, error)
 }
 
 func (p *planner) getUserAuthInfo(nameE, passwordE tree.Expr, ctx string) (userAuthInfo, error) {
@@ -211,8 +241,9 @@ func (p *planner) getUserAuthInfo(nameE, passwordE tree.Expr, ctx string) (userA
 	return userAuthInfo{name: name, password: password}, nil
 }
 
-// resolve returns the actual user name and (hashed) password.
-func (ua *userAuthInfo) resolve() (string, []byte, error) {
+// resolve returns the actual user name and (hashed) password. The password
+// is checked against the password rules configured in the cluster settings.
+func (ua *userAuthInfo) resolve(sv *settings.Values) (string, []byte, error) {
 	name, err := ua.name()
 	if err != nil {
 		return "", nil, err
@@ -234,6 +265,9 @@ func (ua *userAuthInfo) resolve() (string, []byte, error) {
 		if resolvedPassword == "" {
 			return "", nil, security.ErrEmptyPassword
 		}
+		if err := validatePassword(sv, normalizedUsername, resolvedPassword); err != nil {
+			return "", nil, err
+		}
 
 		hashedPassword, err = security.HashPassword(resolvedPassword)
 		if err != nil {
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 // createViewNode represents a CREATE VIEW statement.
@@ -184,11 +185,13 @@ func (n *createViewNode) startExec(params runParams) error {
 		EventLogCreateView,
 		int32(desc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			ViewName  string
-			Statement string
-			User      string
-		}{n.n.Name.FQString(), n.n.String(), params.SessionData().User},
+		&eventpb.CreateView{
+			ViewName: n.n.Name.FQString(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+		},
 	)
 }
 
@@ -215,6 +218,7 @@ func (n *createViewNode) makeViewTableDesc(
 	desc := InitTableDescriptor(id, parentID, viewName,
 		params.p.txn.CommitTimestamp(), privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.n.AsSource, tree.FmtParsable)
+	desc.ViewCheckOption = n.n.CheckOption
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
 		if err != nil {
@@ -253,6 +257,7 @@ func MakeViewTableDesc(
 	viewName := n.Name.Table()
 	desc := InitTableDescriptor(id, parentID, viewName, creationTime, privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.AsSource, tree.FmtParsable)
+	desc.ViewCheckOption = n.CheckOption
 
 	for i, colRes := range resultColumns {
 		colType, err := coltypes.DatumTypeToColumnType(colRes.Typ)
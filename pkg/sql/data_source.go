@@ -109,7 +109,7 @@ func (p *planner) getDataSource(
 		tn := t
 
 		// If there's a CTE with this name, it takes priority over the normal flow.
-		ds, foundCTE, err := p.getCTEDataSource(tn)
+		ds, foundCTE, err := p.getCTEDataSource(ctx, tn)
 		if foundCTE || err != nil {
 			return ds, err
 		}
@@ -167,6 +167,15 @@ func (p *planner) getDataSource(
 	case *tree.AliasedTableExpr:
 		// Alias clause: source AS alias(cols...)
 
+		if t.Lateral {
+			return planDataSource{}, pgerror.UnimplementedWithIssueDetailErrorf(24560, "lateral",
+				"LATERAL is only supported by the cost-based optimizer")
+		}
+		if t.Sample != nil {
+			return planDataSource{}, pgerror.Unimplemented("tablesample",
+				"TABLESAMPLE is only supported by the cost-based optimizer")
+		}
+
 		if t.IndexFlags != nil {
 			indexFlags = t.IndexFlags
 		}
@@ -335,7 +344,52 @@ func (p *planner) getPlanForDesc(
 		plan: scan,
 	}
 	ds.info.NumBackfillColumns = scan.numBackfillColumns
-	return ds, nil
+	return p.projectVirtualColumns(ctx, ds, scan.cols)
+}
+
+// projectVirtualColumns computes the values of the virtual computed columns
+// produced by a table scan. These values are not stored, so the scan always
+// returns NULL for them; instead, the data source is wrapped in a renderNode
+// which replaces each of them with its computed expression over the other
+// columns. cols are the descriptors of the columns of the data source.
+func (p *planner) projectVirtualColumns(
+	ctx context.Context, src planDataSource, cols []sqlbase.ColumnDescriptor,
+) (planDataSource, error) {
+	hasVirtual := false
+	for i := range cols {
+		if cols[i].Virtual {
+			hasVirtual = true
+			break
+		}
+	}
+	if !hasVirtual {
+		return src, nil
+	}
+
+	r := &renderNode{
+		source:     src,
+		sourceInfo: sqlbase.MultiSourceInfo{src.info},
+	}
+	r.ivarHelper = tree.MakeIndexedVarHelper(r, len(src.info.SourceColumns))
+	for i, col := range src.info.SourceColumns {
+		var expr tree.TypedExpr = r.ivarHelper.IndexedVar(i)
+		if cols[i].Virtual {
+			raw, err := parser.ParseExpr(*cols[i].ComputeExpr)
+			if err != nil {
+				return planDataSource{}, err
+			}
+			expr, err = p.analyzeExpr(ctx, raw, r.sourceInfo, r.ivarHelper,
+				col.Typ, true /* requireType */, "computed column")
+			if err != nil {
+				return planDataSource{}, err
+			}
+		}
+		r.addRenderColumn(expr, symbolicExprStr(expr), col)
+	}
+
+	info := *src.info
+	info.SourceColumns = r.columns
+	return planDataSource{info: &info, plan: r}, nil
 }
 
 // getViewPlan builds a planDataSource for the view specified by the
@@ -127,6 +127,9 @@ func (p *planner) Delete(
 		// TODO(dan): This could be made tighter, just the rows needed for RETURNING
 		// exprs.
 		requestedCols = desc.Columns
+	} else if desc.HasTrigger(sqlbase.TriggerDescriptor_DELETE) {
+		// The triggers can reference any column of the deleted rows.
+		requestedCols = desc.Columns
 	}
 
 	// Create the table deleter, which does the bulk of the work.
@@ -200,6 +203,9 @@ type deleteRun struct {
 	// the interleaved fast path (all interleaved tables have no indexes and ON DELETE CASCADE).
 	fastPathInterleaved bool
 
+	// triggers are the triggers fired for the deleted rows, if any.
+	triggers *rowTriggers
+
 	// rowCount is the number of rows in the current batch.
 	rowCount int
 
@@ -233,6 +239,18 @@ func (d *deleteNode) startExec(params runParams) error {
 			params.EvalContext().Mon.MakeBoundAccount(),
 			sqlbase.ColTypeInfoFromResCols(d.columns), 0)
 	}
+
+	var err error
+	d.run.triggers, err = makeRowTriggers(d.run.td.tableDesc(), sqlbase.TriggerDescriptor_DELETE)
+	if err != nil {
+		return err
+	}
+	if d.run.triggers != nil {
+		if err := d.run.triggers.fireBeforeStatement(params); err != nil {
+			return err
+		}
+	}
+
 	return d.run.td.init(params.p.txn, params.EvalContext())
 }
 
@@ -281,8 +299,6 @@ func (d *deleteNode) BatchedNext(params runParams) (bool, error) {
 			return false, err
 		}
 
-		d.run.rowCount++
-
 		// Are we done yet with the current batch?
 		if d.run.td.curBatchSize() >= maxDeleteBatchSize {
 			break
@@ -311,6 +327,19 @@ func (d *deleteNode) BatchedNext(params runParams) (bool, error) {
 		d.run.done = true
 	}
 
+	// Now that the rows of the batch have been written, fire the AFTER
+	// triggers, and the statement-level ones after the last batch.
+	if d.run.triggers != nil {
+		if err := d.run.triggers.fireAfter(params); err != nil {
+			return false, err
+		}
+		if d.run.done {
+			if err := d.run.triggers.fireAfterStatement(params); err != nil {
+				return false, err
+			}
+		}
+	}
+
 	// Possibly initiate a run of CREATE STATISTICS.
 	params.ExecCfg().StatsRefresher.NotifyMutation(
 		d.run.td.tableDesc().ID,
@@ -321,12 +350,29 @@ func (d *deleteNode) BatchedNext(params runParams) (bool, error) {
 }
 
 // processSourceRow processes one row from the source for deletion and, if
-// result rows are needed, saves it in the result row container
+// result rows are needed, saves it in the result row container. The row is
+// skipped if a BEFORE trigger returns NULL.
 func (d *deleteNode) processSourceRow(params runParams, sourceVals tree.Datums) error {
+	// Fire the BEFORE triggers, which can skip the row.
+	var oldRow tree.Datums
+	if d.run.triggers != nil {
+		oldRow = d.run.triggers.makeRow(d.run.td.rd.FetchColIDtoRowIndex, sourceVals)
+		if ok, err := d.run.triggers.fireBefore(
+			params, triggerRows{old: oldRow}, nil /* colIDtoRowIndex */, sourceVals,
+		); err != nil || !ok {
+			return err
+		}
+	}
+
 	// Queue the deletion in the KV batch.
 	if err := d.run.td.row(params.ctx, sourceVals, d.run.traceKV); err != nil {
 		return err
 	}
+	d.run.rowCount++
+
+	if d.run.triggers != nil {
+		d.run.triggers.queueAfter(triggerRows{old: oldRow})
+	}
 
 	// If result rows need to be accumulated, do it.
 	if d.run.rows != nil {
@@ -441,5 +487,9 @@ func canDeleteFastInterleaved(table *ImmutableTableDescriptor, fkTables row.FkTa
 
 // enableAutoCommit is part of the autoCommitNode interface.
 func (d *deleteNode) enableAutoCommit() {
-	d.run.td.enableAutoCommit()
+	// The AFTER triggers are fired after the last batch has been written, so
+	// the transaction cannot be committed along with it.
+	if !d.run.td.tableDesc().HasTrigger(sqlbase.TriggerDescriptor_DELETE) {
+		d.run.td.enableAutoCommit()
+	}
 }
@@ -77,6 +77,11 @@ func maybeCreateDeleteFastNode(
 		return nil, false
 	}
 
+	// Triggers must be fired for every deleted row.
+	if desc.HasTrigger(sqlbase.TriggerDescriptor_DELETE) {
+		return nil, false
+	}
+
 	// Check whether the source plan is "simple": that it contains no remaining
 	// filtering, limiting, sorting, etc. Note that this logic must be kept in
 	// sync with the logic for setting scanNode.isDeleteSource (see doExpandPlan.)
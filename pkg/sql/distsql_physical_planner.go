@@ -87,6 +87,10 @@ type DistSQLPlanner struct {
 	// pool of workers.
 	runnerChan chan runnerRequest
 
+	// setupStreams are the SetupFlows streams used to set up flows on the nodes
+	// which support them.
+	setupStreams flowSetupStreams
+
 	// gossip handle used to check node version compatibility and to construct
 	// the spanResolver.
 	gossip *gossip.Gossip
@@ -175,6 +179,7 @@ func NewDistSQLPlanner(
 	dsp.nodeHealth.isLive = liveness.IsLive
 
 	dsp.initRunners()
+	dsp.setupStreams.init(stopper, nodeDialer)
 	return dsp
 }
 
@@ -522,6 +527,10 @@ type PlanningCtx struct {
 	// noEvalSubqueries indicates that the plan expects any subqueries to not
 	// be replaced by evaluation. Should only be set by EXPLAIN.
 	noEvalSubqueries bool
+
+	// saveDiagram, if set, is called with the diagram of the physical plan
+	// before it is run. It is used to capture plans; see plan_capture.go.
+	saveDiagram func(distsqlpb.FlowDiagram)
 }
 
 var _ distsqlplan.ExprContext = &PlanningCtx{}
@@ -868,6 +877,9 @@ func initTableReaderSpec(
 		IsCheck:    n.isCheck,
 		Visibility: n.colCfg.visibility.toDistSQLScanVisibility(),
 
+		LockingWaitPolicy: toDistSQLScanLockingWaitPolicy(n.lockingWaitPolicy),
+		Sample:            n.sample,
+
 		// Retain the capacity of the spans slice.
 		Spans: s.Spans[:0],
 	}
@@ -1052,6 +1064,54 @@ func (dsp *DistSQLPlanner) CheckNodeHealthAndVersion(
 	return err
 }
 
+// sampleRanges returns the parts of the given spans which belong to the ranges
+// selected by the given SYSTEM sample. A range is selected based on its start
+// key, so a REPEATABLE sample selects the same ranges as long as the ranges of
+// the table don't change.
+func (dsp *DistSQLPlanner) sampleRanges(
+	planCtx *PlanningCtx, spans roachpb.Spans, sample *distsqlpb.TableSampleSpec,
+) (roachpb.Spans, error) {
+	ctx := planCtx.ctx
+	it := planCtx.spanIter
+	if it == nil {
+		// Local planning contexts don't have a span resolver iterator.
+		it = dsp.spanResolver.NewSpanResolverIterator(planCtx.ExtendedEvalCtx.Txn)
+	}
+	var res roachpb.Spans
+	for _, span := range spans {
+		var rspan roachpb.RSpan
+		var err error
+		if rspan.Key, err = keys.Addr(span.Key); err != nil {
+			return nil, err
+		}
+		if rspan.EndKey, err = keys.Addr(span.EndKey); err != nil {
+			return nil, err
+		}
+
+		for it.Seek(ctx, span, kv.Ascending); ; it.Next(ctx) {
+			if !it.Valid() {
+				return nil, it.Error()
+			}
+			desc := it.Desc()
+			if sample.Selects(desc.StartKey) {
+				// Limit the piece of the range to the span we are sampling.
+				startKey, endKey := desc.StartKey, desc.EndKey
+				if startKey.Less(rspan.Key) {
+					startKey = rspan.Key
+				}
+				if rspan.EndKey.Less(endKey) {
+					endKey = rspan.EndKey
+				}
+				res = append(res, roachpb.Span{Key: startKey.AsRawKey(), EndKey: endKey.AsRawKey()})
+			}
+			if !desc.EndKey.Less(rspan.EndKey) {
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
 // createTableReaders generates a plan consisting of table reader processors,
 // one for each node that has spans that we are reading.
 // overridesResultColumns is optional.
@@ -1065,12 +1125,28 @@ func (dsp *DistSQLPlanner) createTableReaders(
 		return PhysicalPlan{}, err
 	}
 
+	spans := n.spans
+	if n.sample != nil && n.sample.Method == distsqlpb.TableSampleSpec_SYSTEM {
+		// Only read the ranges that are part of the sample.
+		spans, err = dsp.sampleRanges(planCtx, spans, n.sample)
+		if err != nil {
+			return PhysicalPlan{}, err
+		}
+		if len(spans) == 0 {
+			types, err := getTypesForPlanResult(n, nil /* planToStreamColMap */)
+			if err != nil {
+				return PhysicalPlan{}, err
+			}
+			return dsp.createValuesPlan(types, 0 /* numRows */, nil /* rawBytes */)
+		}
+	}
+
 	var spanPartitions []SpanPartition
 	if planCtx.isLocal {
-		spanPartitions = []SpanPartition{{dsp.nodeDesc.NodeID, n.spans}}
+		spanPartitions = []SpanPartition{{dsp.nodeDesc.NodeID, spans}}
 	} else if n.hardLimit == 0 && n.softLimit == 0 {
 		// No limit - plan all table readers where their data live.
-		spanPartitions, err = dsp.PartitionSpans(planCtx, n.spans)
+		spanPartitions, err = dsp.PartitionSpans(planCtx, spans)
 		if err != nil {
 			return PhysicalPlan{}, err
 		}
@@ -1080,11 +1156,11 @@ func (dsp *DistSQLPlanner) createTableReaders(
 		// limits since the TableReader will still read too eagerly in the soft
 		// limit case. To prevent this we'll need a new mechanism on the execution
 		// side to modulate table reads.
-		nodeID, err := dsp.getNodeIDForScan(planCtx, n.spans, n.reverse)
+		nodeID, err := dsp.getNodeIDForScan(planCtx, spans, n.reverse)
 		if err != nil {
 			return PhysicalPlan{}, err
 		}
-		spanPartitions = []SpanPartition{{nodeID, n.spans}}
+		spanPartitions = []SpanPartition{{nodeID, spans}}
 	}
 
 	var p PhysicalPlan
@@ -1221,7 +1297,13 @@ func (dsp *DistSQLPlanner) selectRenders(
 // addSorters adds sorters corresponding to a sortNode and updates the plan to
 // reflect the sort node.
 func (dsp *DistSQLPlanner) addSorters(p *PhysicalPlan, n *sortNode) {
+	dsp.addSortStage(p, n)
+	dsp.addSortProjection(p, n)
+}
 
+// addSortStage adds the sorters corresponding to a sortNode, if sorting is
+// needed. The plan still has the columns of the source of the sortNode.
+func (dsp *DistSQLPlanner) addSortStage(p *PhysicalPlan, n *sortNode) {
 	matchLen := planPhysicalProps(n.plan).computeMatch(n.ordering)
 
 	if matchLen < len(n.ordering) {
@@ -1240,7 +1322,11 @@ func (dsp *DistSQLPlanner) addSorters(p *PhysicalPlan, n *sortNode) {
 			ordering,
 		)
 	}
+}
 
+// addSortProjection updates a plan with the columns of the source of a
+// sortNode to reflect the sortNode.
+func (dsp *DistSQLPlanner) addSortProjection(p *PhysicalPlan, n *sortNode) {
 	if len(n.columns) != len(p.PlanToStreamColMap) {
 		// In cases like:
 		//   SELECT a FROM t ORDER BY b
@@ -1918,8 +2004,13 @@ func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 	// the corresponding projection.
 	// The internal schema of the join reader is:
 	//    <input columns>... <table columns>...
+	// except for semi and anti joins, which only produce the input columns.
 	numLeftCols := len(plan.ResultTypes)
 	numOutCols := numLeftCols + len(n.table.cols)
+	includeTableCols := n.joinType != sqlbase.LeftSemiJoin && n.joinType != sqlbase.LeftAntiJoin
+	if !includeTableCols {
+		numOutCols = numLeftCols
+	}
 	post := distsqlpb.PostProcessSpec{Projection: true}
 
 	post.OutputColumns = make([]uint32, numOutCols)
@@ -1929,10 +2020,16 @@ func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 		types[i] = plan.ResultTypes[i]
 		post.OutputColumns[i] = uint32(i)
 	}
+	// tableColOrdinals are the internal columns of the processor which
+	// correspond to n.table.cols.
+	tableColOrdinals := make([]int, len(n.table.cols))
 	for i := range n.table.cols {
-		types[numLeftCols+i] = n.table.cols[i].Type
 		ord := tableOrdinal(n.table.desc, n.table.cols[i].ID, n.table.colCfg.visibility)
-		post.OutputColumns[numLeftCols+i] = uint32(numLeftCols + ord)
+		tableColOrdinals[i] = numLeftCols + ord
+		if includeTableCols {
+			types[numLeftCols+i] = n.table.cols[i].Type
+			post.OutputColumns[numLeftCols+i] = uint32(tableColOrdinals[i])
+		}
 	}
 
 	// Map the columns of the lookupJoinNode to the result streams of the
@@ -1940,18 +2037,20 @@ func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 	planToStreamColMap := makePlanToStreamColMap(len(n.columns))
 	copy(planToStreamColMap, plan.PlanToStreamColMap)
 	numInputNodeCols := len(planColumns(n.input))
-	for i := range n.table.cols {
-		planToStreamColMap[numInputNodeCols+i] = numLeftCols + i
+	if includeTableCols {
+		for i := range n.table.cols {
+			planToStreamColMap[numInputNodeCols+i] = numLeftCols + i
+		}
 	}
 
 	// Set the ON condition.
 	if n.onCond != nil {
 		// Note that the ON condition refers to the *internal* columns of the
 		// processor (before the OutputColumns projection).
-		indexVarMap := makePlanToStreamColMap(len(n.columns))
+		indexVarMap := makePlanToStreamColMap(numInputNodeCols + len(n.table.cols))
 		copy(indexVarMap, plan.PlanToStreamColMap)
 		for i := range n.table.cols {
-			indexVarMap[numInputNodeCols+i] = int(post.OutputColumns[numLeftCols+i])
+			indexVarMap[numInputNodeCols+i] = tableColOrdinals[i]
 		}
 		var err error
 		joinReaderSpec.OnExpr, err = distsqlplan.MakeExpression(
@@ -2341,16 +2440,7 @@ func (dsp *DistSQLPlanner) createPlanForNode(
 		}
 
 	case *limitNode:
-		plan, err = dsp.createPlanForNode(planCtx, n.plan)
-		if err != nil {
-			return PhysicalPlan{}, err
-		}
-		if err := n.evalLimit(planCtx.EvalContext()); err != nil {
-			return PhysicalPlan{}, err
-		}
-		if err := plan.AddLimit(n.count, n.offset, planCtx, dsp.nodeDesc.NodeID); err != nil {
-			return PhysicalPlan{}, err
-		}
+		plan, err = dsp.createPlanForLimit(planCtx, n)
 
 	case *distinctNode:
 		plan, err = dsp.createPlanForDistinct(planCtx, n)
@@ -2680,6 +2770,63 @@ func createDistinctSpec(n *distinctNode, cols []int) *distsqlpb.DistinctSpec {
 	}
 }
 
+func (dsp *DistSQLPlanner) createPlanForLimit(
+	planCtx *PlanningCtx, n *limitNode,
+) (PhysicalPlan, error) {
+	if !n.withTies {
+		plan, err := dsp.createPlanForNode(planCtx, n.plan)
+		if err != nil {
+			return PhysicalPlan{}, err
+		}
+		if err := n.evalLimit(planCtx.EvalContext()); err != nil {
+			return PhysicalPlan{}, err
+		}
+		if err := plan.AddLimit(n.count, n.offset, planCtx, dsp.nodeDesc.NodeID); err != nil {
+			return PhysicalPlan{}, err
+		}
+		return plan, nil
+	}
+
+	// The peers of the last row within the limit are found using the ordering
+	// columns, which may not be part of the results of the sortNode (for
+	// example in SELECT a FROM t ORDER BY b FETCH FIRST 1 ROW WITH TIES). In
+	// that case, the limit is placed before the projection of the sortNode.
+	sort, _ := n.plan.(*sortNode)
+	var plan PhysicalPlan
+	var err error
+	if sort != nil {
+		plan, err = dsp.createPlanForNode(planCtx, sort.plan)
+		if err != nil {
+			return PhysicalPlan{}, err
+		}
+		dsp.addSortStage(&plan, sort)
+	} else {
+		plan, err = dsp.createPlanForNode(planCtx, n.plan)
+		if err != nil {
+			return PhysicalPlan{}, err
+		}
+	}
+	for _, o := range n.tiesOrdering {
+		if o.ColIdx >= len(plan.PlanToStreamColMap) || plan.PlanToStreamColMap[o.ColIdx] == -1 {
+			return PhysicalPlan{}, pgerror.Unimplemented("fetch.ties.distinct",
+				"FETCH ... WITH TIES is not supported with ORDER BY columns which are not part of the results of DISTINCT ON")
+		}
+	}
+	if err := n.evalLimit(planCtx.EvalContext()); err != nil {
+		return PhysicalPlan{}, err
+	}
+	ordering := distsqlpb.ConvertToMappedSpecOrdering(n.tiesOrdering, plan.PlanToStreamColMap)
+	if err := plan.AddLimitWithTies(
+		n.count, n.offset, ordering, planCtx, dsp.nodeDesc.NodeID,
+	); err != nil {
+		return PhysicalPlan{}, err
+	}
+	if sort != nil {
+		dsp.addSortProjection(&plan, sort)
+	}
+	return plan, nil
+}
+
 func (dsp *DistSQLPlanner) createPlanForDistinct(
 	planCtx *PlanningCtx, n *distinctNode,
 ) (PhysicalPlan, error) {
@@ -2734,6 +2881,23 @@ func createProjectSetSpec(
 	for i, n := range n.numColsPerGen {
 		spec.NumColsPerGen[i] = uint32(n)
 	}
+	if n.bounds != nil {
+		spec.LowerBounds = make([]distsqlpb.Expression, len(n.bounds))
+		spec.UpperBounds = make([]distsqlpb.Expression, len(n.bounds))
+		for i, b := range n.bounds {
+			var err error
+			if b.Lo != nil {
+				if spec.LowerBounds[i], err = distsqlplan.MakeExpression(b.Lo, planCtx, nil); err != nil {
+					return nil, err
+				}
+			}
+			if b.Hi != nil {
+				if spec.UpperBounds[i], err = distsqlplan.MakeExpression(b.Hi, planCtx, nil); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
 	return &spec, nil
 }
 
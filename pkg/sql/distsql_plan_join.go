@@ -362,6 +362,12 @@ func useInterleavedJoin(n *joinNode) bool {
 		return false
 	}
 
+	// We cannot do an interleaved join of sampled tables, since the
+	// interleaved reader joiner doesn't support sampling.
+	if ancestor.sample != nil || descendant.sample != nil {
+		return false
+	}
+
 	var ancestorEqIndices []int
 	var descendantEqIndices []int
 	// We are guaranteed that both of the sources are scan nodes from
@@ -21,7 +21,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs"
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	sqlstats "github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util"
@@ -35,6 +37,9 @@ type requestedStat struct {
 	histogram           bool
 	histogramMaxBuckets int
 	name                string
+	// expression, if set, is the expression of the columns which the
+	// statistic is on.
+	expression string
 }
 
 const histogramSamples = 10000
@@ -80,16 +85,75 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 		return PhysicalPlan{}, err
 	}
 
+	// Only keep the rows of partial statistics.
+	if details.Predicate != "" {
+		filter, err := dsp.analyzeStatsExpr(planCtx, &scan, details.Predicate, types.Bool)
+		if err != nil {
+			return PhysicalPlan{}, err
+		}
+		if err := p.AddFilter(filter, planCtx, p.PlanToStreamColMap); err != nil {
+			return PhysicalPlan{}, err
+		}
+	}
+
+	// Render the expressions of the expression statistics as additional
+	// columns, after the scanned ones.
+	numScanCols := len(p.ResultTypes)
+	exprStreamCols := make([]int, len(stats))
+	var renderExprs []tree.TypedExpr
+	for i, s := range stats {
+		if s.expression == "" {
+			continue
+		}
+		if renderExprs == nil {
+			renderExprs = make([]tree.TypedExpr, numScanCols, numScanCols+1)
+			for colIdx, streamColIdx := range p.PlanToStreamColMap {
+				if streamColIdx != -1 {
+					renderExprs[streamColIdx] = scan.filterVars.IndexedVar(colIdx)
+				}
+			}
+		}
+		expr, err := dsp.analyzeStatsExpr(planCtx, &scan, s.expression, types.Any)
+		if err != nil {
+			return PhysicalPlan{}, err
+		}
+		exprStreamCols[i] = len(renderExprs)
+		renderExprs = append(renderExprs, expr)
+	}
+	if renderExprs != nil {
+		outTypes := append([]sqlbase.ColumnType(nil), p.ResultTypes...)
+		for _, expr := range renderExprs[numScanCols:] {
+			typ, err := sqlbase.DatumTypeToColumnType(expr.ResolvedType())
+			if err != nil {
+				return PhysicalPlan{}, err
+			}
+			outTypes = append(outTypes, typ)
+		}
+		if err := p.AddRendering(renderExprs, planCtx, p.PlanToStreamColMap, outTypes); err != nil {
+			return PhysicalPlan{}, err
+		}
+	}
+
 	sketchSpecs := make([]distsqlpb.SketchSpec, len(stats))
-	sampledColumnIDs := make([]sqlbase.ColumnID, scan.valNeededForCol.Len())
+	sampledColumnIDs := make([]sqlbase.ColumnID, len(p.ResultTypes))
 	for i, s := range stats {
 		spec := distsqlpb.SketchSpec{
 			SketchType:          distsqlpb.SketchType_HLL_PLUS_PLUS_V1,
 			GenerateHistogram:   s.histogram,
 			HistogramMaxBuckets: uint32(s.histogramMaxBuckets),
-			Columns:             make([]uint32, len(s.columns)),
 			StatName:            s.name,
+			Predicate:           details.Predicate,
 		}
+		if s.expression != "" {
+			// The sketch is on the rendered expression, which is not a table
+			// column.
+			spec.Columns = []uint32{uint32(exprStreamCols[i])}
+			spec.Expression = s.expression
+			spec.ColumnIDs = s.columns
+			sketchSpecs[i] = spec
+			continue
+		}
+		spec.Columns = make([]uint32, len(s.columns))
 		for i, colID := range s.columns {
 			colIdx, ok := scan.colIdxMap[colID]
 			if !ok {
@@ -141,14 +205,19 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	}
 
 	var rowsExpected uint64
-	if len(tableStats) > 0 {
+	for _, stat := range tableStats {
+		if stat.Predicate != "" {
+			// Partial statistics do not count all the rows.
+			continue
+		}
 		overhead := sqlstats.AutomaticStatisticsFractionStaleRows.Get(&dsp.st.SV)
 		// Convert to a signed integer first to make the linter happy.
 		rowsExpected = uint64(int64(
 			// The total expected number of rows is the same number that was measured
 			// most recently, plus some overhead for possible insertions.
-			float64(tableStats[0].RowCount) * (1 + overhead),
+			float64(stat.RowCount) * (1 + overhead),
 		))
+		break
 	}
 
 	// Set up the final SampleAggregator stage.
@@ -175,6 +244,25 @@ func (dsp *DistSQLPlanner) createStatsPlan(
 	return p, nil
 }
 
+// analyzeStatsExpr parses the SQL text of the expression or the predicate of
+// a statistic, and resolves and type checks it against the columns of the
+// given scan.
+func (dsp *DistSQLPlanner) analyzeStatsExpr(
+	planCtx *PlanningCtx, scan *scanNode, expr string, typ types.T,
+) (tree.TypedExpr, error) {
+	parsed, err := parser.ParseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	source := sqlbase.NewSourceInfoForSingleTable(
+		tree.MakeUnqualifiedTableName(tree.Name(scan.desc.Name)), scan.resultColumns,
+	)
+	return planCtx.planner.analyzeExpr(
+		planCtx.ctx, parsed, sqlbase.MultiSourceInfo{source}, scan.filterVars,
+		typ, typ != types.Any /* requireType */, "statistics",
+	)
+}
+
 func (dsp *DistSQLPlanner) createPlanForCreateStats(
 	planCtx *PlanningCtx, job *jobs.Job,
 ) (PhysicalPlan, error) {
@@ -189,6 +277,7 @@ func (dsp *DistSQLPlanner) createPlanForCreateStats(
 			histogram:           histogram,
 			histogramMaxBuckets: histogramBuckets,
 			name:                details.Name,
+			expression:          details.ColumnLists[i].Expression,
 		}
 	}
 
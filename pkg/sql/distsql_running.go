@@ -161,6 +161,15 @@ func (dsp *DistSQLPlanner) Run(
 
 	flows := plan.GenerateFlowSpecs(dsp.nodeDesc.NodeID /* gateway */)
 
+	if planCtx.saveDiagram != nil {
+		diagram, err := distsqlpb.GeneratePlanDiagram(flows)
+		if err != nil {
+			log.Infof(ctx, "Error generating diagram: %s", err)
+		} else {
+			planCtx.saveDiagram(diagram)
+		}
+	}
+
 	if logPlanDiagram {
 		log.VEvent(ctx, 1, "creating plan diagram")
 		json, url, err := distsqlpb.GeneratePlanDiagramURL(flows)
@@ -202,6 +211,12 @@ func (dsp *DistSQLPlanner) Run(
 		}
 		req := setupReq
 		req.Flow = *flowSpec
+		if dsp.useFlowSetupStream(ctx, nodeID) {
+			// The request is sent without blocking on the response, which
+			// is sent on resultChan.
+			dsp.setupStreams.setupFlow(ctx, nodeID, &req, resultChan)
+			continue
+		}
 		runReq := runnerRequest{
 			ctx:        ctx,
 			nodeDialer: dsp.nodeDialer,
@@ -222,7 +237,14 @@ func (dsp *DistSQLPlanner) Run(
 	// Now wait for all the flows to be scheduled on remote nodes. Note that we
 	// are not waiting for the flows themselves to complete.
 	for i := 0; i < len(flows)-1; i++ {
-		res := <-resultChan
+		var res runnerResult
+		select {
+		case res = <-resultChan:
+		case <-ctx.Done():
+			// The requests sent on the SetupFlows streams are not canceled
+			// along with the query, so we stop waiting for their responses.
+			res.err = ctx.Err()
+		}
 		if firstErr == nil {
 			firstErr = res.err
 		}
@@ -339,6 +361,10 @@ type DistSQLReceiver struct {
 	// A handler for clock signals arriving from remote nodes. This should update
 	// this node's clock.
 	updateClock func(observedTs hlc.Timestamp)
+
+	// stats, if set, accumulates the resources reported by the processors
+	// via ProducerMetas.
+	stats *topLevelQueryStats
 }
 
 // errWrap is a container for an error, for use with atomic.Value, which
@@ -456,6 +482,7 @@ func (r *DistSQLReceiver) clone() *DistSQLReceiver {
 		updateClock: r.updateClock,
 		stmtType:    tree.Rows,
 		tracing:     r.tracing,
+		stats:       r.stats,
 	}
 	return ret
 }
@@ -509,6 +536,9 @@ func (r *DistSQLReceiver) Push(
 				r.resultWriter.SetError(err)
 			}
 		}
+		if meta.Metrics != nil && r.stats != nil {
+			r.stats.bytesRead += meta.Metrics.BytesRead
+		}
 		if len(meta.TraceData) > 0 {
 			span := opentracing.SpanFromContext(r.ctx)
 			if span == nil {
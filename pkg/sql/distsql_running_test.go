@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -182,6 +183,57 @@ func TestDistSQLRunningInAbortedTxn(t *testing.T) {
 	}
 }
 
+// Test that remote flows are set up on the SetupFlows stream, and with the
+// SetupFlow RPC when the stream is disabled.
+func TestDistSQLSetupFlowsStream(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	tc := serverutils.StartTestCluster(t, 2, base.TestClusterArgs{
+		ReplicationMode: base.ReplicationManual,
+		ServerArgs:      base.TestServerArgs{UseDatabase: "test"},
+	})
+	defer tc.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(tc.ServerConn(0))
+	sqlDB.Exec(t, `CREATE DATABASE test`)
+	sqlDB.Exec(t, `CREATE TABLE nums (num INT PRIMARY KEY)`)
+	sqlDB.Exec(t, `INSERT INTO nums SELECT generate_series(1, 100)`)
+	sqlDB.Exec(t, `ALTER TABLE nums SPLIT AT VALUES (50)`)
+	// Move the first range to the second node so that the query is distributed.
+	testutils.SucceedsSoon(t, func() error {
+		_, err := tc.ServerConn(0).Exec(fmt.Sprintf(
+			`ALTER TABLE nums EXPERIMENTAL_RELOCATE VALUES (ARRAY[%d], 1)`,
+			tc.Server(1).GetFirstStoreID()))
+		return err
+	})
+	sqlDB.Exec(t, `SET distsql = always`)
+
+	dsp := tc.Server(0).ExecutorConfig().(ExecutorConfig).DistSQLPlanner
+	numStreams := func() int {
+		dsp.setupStreams.mu.Lock()
+		defer dsp.setupStreams.mu.Unlock()
+		return len(dsp.setupStreams.mu.streams)
+	}
+
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			sqlDB.Exec(t, `SET CLUSTER SETTING sql.distsql.streaming_flow_setup.enabled = $1`, enabled)
+			for i := 0; i < 3; i++ {
+				sqlDB.CheckQueryResults(t, `SELECT count(*), sum(num) FROM nums`, [][]string{{"100", "5050"}})
+			}
+			expected := 0
+			if enabled {
+				// All the flows on the second node are set up on a single stream.
+				expected = 1
+			}
+			if n := numStreams(); n != expected {
+				t.Fatalf("expected %d SetupFlows streams, got %d", expected, n)
+			}
+		})
+	}
+}
+
 // Test that the DistSQLReceiver overwrites previous errors as "better" errors
 // come along.
 func TestDistSQLReceiverErrorRanking(t *testing.T) {
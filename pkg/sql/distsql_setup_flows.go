@@ -0,0 +1,241 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/gossip"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/rpc/nodedialer"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlplan"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+var streamingFlowSetup = settings.RegisterBoolSetting(
+	"sql.distsql.streaming_flow_setup.enabled",
+	"if set, remote flows are set up on a stream to each node shared by all the "+
+		"queries, instead of with an RPC per flow",
+	true,
+)
+
+// useFlowSetupStream returns true if the flow of a query should be set up on
+// the given node using the SetupFlows stream. The stream is used if the node
+// implements it and the query is not traced: the flows set up on the stream
+// don't have a parent span, since the stream is shared by many queries.
+func (dsp *DistSQLPlanner) useFlowSetupStream(ctx context.Context, nodeID roachpb.NodeID) bool {
+	if !streamingFlowSetup.Get(&dsp.st.SV) {
+		return false
+	}
+	if sp := opentracing.SpanFromContext(ctx); sp != nil && tracing.IsRecording(sp) {
+		return false
+	}
+	var v distsqlpb.DistSQLVersionGossipInfo
+	if err := dsp.gossip.GetInfoProto(gossip.MakeDistSQLNodeVersionKey(nodeID), &v); err != nil {
+		return false
+	}
+	return v.Version >= distsqlrun.SetupFlowsVersion
+}
+
+// flowSetupStreams maintains a SetupFlows stream to each of the nodes on which
+// this node sets up flows. A stream is opened when a flow is first set up on
+// a node, and reopened when a flow is set up after the stream broke.
+type flowSetupStreams struct {
+	stopper    *stop.Stopper
+	nodeDialer *nodedialer.Dialer
+
+	mu struct {
+		syncutil.Mutex
+		streams map[roachpb.NodeID]*flowSetupStream
+	}
+}
+
+func (s *flowSetupStreams) init(stopper *stop.Stopper, nodeDialer *nodedialer.Dialer) {
+	s.stopper = stopper
+	s.nodeDialer = nodeDialer
+	s.mu.streams = make(map[roachpb.NodeID]*flowSetupStream)
+}
+
+// setupFlow sends a request to set up a flow on the given node. The request is
+// released once it has been sent, and the result is sent on resultChan when
+// the node responds. The request can't be canceled once it has been sent; if
+// the query is canceled, the flow is cleaned up by the remote node when its
+// streams time out.
+func (s *flowSetupStreams) setupFlow(
+	ctx context.Context,
+	nodeID roachpb.NodeID,
+	req *distsqlpb.SetupFlowRequest,
+	resultChan chan<- runnerResult,
+) {
+	defer distsqlplan.ReleaseSetupFlowRequest(req)
+
+	var msg distsqlpb.SetupFlowsRequest
+	err := msg.SetSetupFlowRequest(req)
+	if err == nil {
+		var stream *flowSetupStream
+		if stream, err = s.getStream(ctx, nodeID); err == nil {
+			err = stream.send(&msg, resultChan)
+		}
+	}
+	if err != nil {
+		resultChan <- runnerResult{nodeID: nodeID, err: err}
+	}
+}
+
+// getStream returns the stream to the given node, opening it if needed.
+func (s *flowSetupStreams) getStream(
+	ctx context.Context, nodeID roachpb.NodeID,
+) (*flowSetupStream, error) {
+	s.mu.Lock()
+	stream, ok := s.mu.streams[nodeID]
+	s.mu.Unlock()
+	if ok {
+		return stream, nil
+	}
+
+	conn, err := s.nodeDialer.Dial(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	// The stream outlives the query that opens it.
+	streamCtx, cancel := s.stopper.WithCancelOnQuiesce(context.Background())
+	client, err := distsqlpb.NewDistSQLClient(conn).SetupFlows(streamCtx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	stream = &flowSetupStream{streams: s, nodeID: nodeID, client: client, cancel: cancel}
+	stream.mu.pending = make(map[uint64]chan<- runnerResult)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.mu.streams[nodeID]; ok {
+		// Another query opened a stream concurrently.
+		_ = client.CloseSend()
+		cancel()
+		return existing, nil
+	}
+	if err := s.stopper.RunAsyncTask(streamCtx, "distsql-setup-flows", stream.receive); err != nil {
+		cancel()
+		return nil, err
+	}
+	s.mu.streams[nodeID] = stream
+	return stream, nil
+}
+
+// flowSetupStream is a SetupFlows stream to a node.
+type flowSetupStream struct {
+	streams *flowSetupStreams
+	nodeID  roachpb.NodeID
+	client  distsqlpb.DistSQL_SetupFlowsClient
+	cancel  func()
+
+	// sendMu serializes the calls to client.Send.
+	sendMu syncutil.Mutex
+
+	mu struct {
+		syncutil.Mutex
+		nextID uint64
+		// pending maps the ids of the requests which have been sent to the
+		// channels on which their results are sent.
+		pending map[uint64]chan<- runnerResult
+		// err is set once the stream is broken.
+		err error
+	}
+}
+
+// send sends a request on the stream. Unless an error is returned, the result
+// of the request is sent on resultChan, either when the response is received
+// or when the stream breaks.
+func (s *flowSetupStream) send(
+	msg *distsqlpb.SetupFlowsRequest, resultChan chan<- runnerResult,
+) error {
+	s.mu.Lock()
+	if err := s.mu.err; err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	s.mu.nextID++
+	msg.RequestID = s.mu.nextID
+	s.mu.pending[msg.RequestID] = resultChan
+	s.mu.Unlock()
+
+	s.sendMu.Lock()
+	err := s.client.Send(msg)
+	s.sendMu.Unlock()
+	if err == nil {
+		return nil
+	}
+	s.close(err)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.mu.pending[msg.RequestID]; ok {
+		delete(s.mu.pending, msg.RequestID)
+		return err
+	}
+	// The result has already been sent.
+	return nil
+}
+
+// receive receives the responses on the stream until it breaks.
+func (s *flowSetupStream) receive(ctx context.Context) {
+	for {
+		resp, err := s.client.Recv()
+		if err != nil {
+			log.VEventf(ctx, 1, "SetupFlows stream to n%d broke: %v", s.nodeID, err)
+			s.close(err)
+			return
+		}
+		s.mu.Lock()
+		resultChan, ok := s.mu.pending[resp.RequestID]
+		delete(s.mu.pending, resp.RequestID)
+		s.mu.Unlock()
+		if !ok {
+			log.Errorf(ctx, "unexpected response %d on the SetupFlows stream to n%d", resp.RequestID, s.nodeID)
+			continue
+		}
+		resultChan <- runnerResult{nodeID: s.nodeID, err: resp.Response.Error.ErrorDetail()}
+	}
+}
+
+// close closes a stream on which an error occurred. The pending requests fail
+// with the error, and the next flows set up on the node open a new stream.
+// Only the first call has an effect.
+func (s *flowSetupStream) close(err error) {
+	s.streams.mu.Lock()
+	if s.streams.mu.streams[s.nodeID] == s {
+		delete(s.streams.mu.streams, s.nodeID)
+	}
+	s.streams.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.err != nil {
+		return
+	}
+	s.mu.err = err
+	s.cancel()
+	for id, resultChan := range s.mu.pending {
+		resultChan <- runnerResult{nodeID: s.nodeID, err: err}
+		delete(s.mu.pending, id)
+	}
+}
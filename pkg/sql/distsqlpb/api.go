@@ -15,9 +15,13 @@
 package distsqlpb
 
 import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
 )
 
 // StreamID identifies a stream; it may be local to a flow or it may cross
@@ -75,3 +79,43 @@ func MakeEvalContext(evalCtx tree.EvalContext) EvalContext {
 	}
 	return res
 }
+
+// setupFlowCompressionThreshold is the size of the encoded SetupFlowRequest
+// above which SetSetupFlowRequest compresses it.
+const setupFlowCompressionThreshold = 4 << 10 /* 4KB */
+
+// SetSetupFlowRequest sets the request to send on the SetupFlows stream.
+// Large requests are compressed.
+func (m *SetupFlowsRequest) SetSetupFlowRequest(req *SetupFlowRequest) error {
+	m.SetupFlowRequest, m.CompressedSetupFlowRequest = nil, nil
+	if req.Size() < setupFlowCompressionThreshold {
+		m.SetupFlowRequest = req
+		return nil
+	}
+	b, err := protoutil.Marshal(req)
+	if err != nil {
+		return err
+	}
+	m.CompressedSetupFlowRequest = snappy.Encode(nil, b)
+	return nil
+}
+
+// GetSetupFlowRequest returns the request received on the SetupFlows stream,
+// decompressing it if needed.
+func (m *SetupFlowsRequest) GetSetupFlowRequest() (*SetupFlowRequest, error) {
+	if m.CompressedSetupFlowRequest == nil {
+		if m.SetupFlowRequest == nil {
+			return nil, pgerror.NewAssertionErrorf("no SetupFlowRequest in SetupFlowsRequest")
+		}
+		return m.SetupFlowRequest, nil
+	}
+	b, err := snappy.Decode(nil, m.CompressedSetupFlowRequest)
+	if err != nil {
+		return nil, errors.Wrap(err, "decompressing SetupFlowRequest")
+	}
+	req := &SetupFlowRequest{}
+	if err := protoutil.Unmarshal(b, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
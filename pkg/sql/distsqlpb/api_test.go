@@ -0,0 +1,76 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlpb
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+)
+
+func TestSetupFlowsRequestCompression(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, size := range []int{10, setupFlowCompressionThreshold * 4} {
+		t.Run(fmt.Sprint(size), func(t *testing.T) {
+			req := &SetupFlowRequest{
+				Version: 23,
+				Flow: FlowSpec{
+					Processors: []ProcessorSpec{{
+						Core: ProcessorCoreUnion{Values: &ValuesCoreSpec{
+							RawBytes: [][]byte{bytes.Repeat([]byte("a"), size)},
+						}},
+					}},
+				},
+			}
+			var msg SetupFlowsRequest
+			if err := msg.SetSetupFlowRequest(req); err != nil {
+				t.Fatal(err)
+			}
+			compressed := size >= setupFlowCompressionThreshold
+			if (msg.CompressedSetupFlowRequest != nil) != compressed {
+				t.Fatalf("expected compressed=%t, got %+v", compressed, msg)
+			}
+			if compressed && len(msg.CompressedSetupFlowRequest) >= size {
+				t.Errorf("request of size %d compressed to %d bytes", size, len(msg.CompressedSetupFlowRequest))
+			}
+
+			// Send the message over the wire.
+			b, err := protoutil.Marshal(&msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var received SetupFlowsRequest
+			if err := protoutil.Unmarshal(b, &received); err != nil {
+				t.Fatal(err)
+			}
+			res, err := received.GetSetupFlowRequest()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.String() != req.String() {
+				t.Fatalf("expected %s, got %s", req, res)
+			}
+		})
+	}
+
+	var msg SetupFlowsRequest
+	if _, err := msg.GetSetupFlowRequest(); err == nil {
+		t.Fatal("expected error for empty request")
+	}
+}
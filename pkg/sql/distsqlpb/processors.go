@@ -78,6 +78,21 @@ func (spec *WindowerSpec_Frame_BoundType) initFromAST(bt tree.WindowFrameBoundTy
 	}
 }
 
+func (spec *WindowerSpec_Frame_Exclusion) initFromAST(e tree.WindowFrameExclusion) {
+	switch e {
+	case tree.NoExclusion:
+		*spec = WindowerSpec_Frame_NO_EXCLUSION
+	case tree.ExcludeCurrentRow:
+		*spec = WindowerSpec_Frame_EXCLUDE_CURRENT_ROW
+	case tree.ExcludeGroup:
+		*spec = WindowerSpec_Frame_EXCLUDE_GROUP
+	case tree.ExcludeTies:
+		*spec = WindowerSpec_Frame_EXCLUDE_TIES
+	default:
+		panic("unexpected WindowFrameExclusion")
+	}
+}
+
 // If offset exprs are present, we evaluate them and save the encoded results
 // in the spec.
 func (spec *WindowerSpec_Frame_Bounds) initFromAST(
@@ -199,6 +214,7 @@ func isNegative(evalCtx *tree.EvalContext, offset tree.Datum) bool {
 // offset expressions if present in the frame.
 func (spec *WindowerSpec_Frame) InitFromAST(f *tree.WindowFrame, evalCtx *tree.EvalContext) error {
 	spec.Mode.initFromAST(f.Mode)
+	spec.Exclusion.initFromAST(f.Exclusion)
 	return spec.Bounds.initFromAST(f.Bounds, f.Mode, evalCtx)
 }
 
@@ -232,6 +248,21 @@ func (spec WindowerSpec_Frame_BoundType) convertToAST() tree.WindowFrameBoundTyp
 	}
 }
 
+func (spec WindowerSpec_Frame_Exclusion) convertToAST() tree.WindowFrameExclusion {
+	switch spec {
+	case WindowerSpec_Frame_NO_EXCLUSION:
+		return tree.NoExclusion
+	case WindowerSpec_Frame_EXCLUDE_CURRENT_ROW:
+		return tree.ExcludeCurrentRow
+	case WindowerSpec_Frame_EXCLUDE_GROUP:
+		return tree.ExcludeGroup
+	case WindowerSpec_Frame_EXCLUDE_TIES:
+		return tree.ExcludeTies
+	default:
+		panic("unexpected WindowerSpec_Frame_Exclusion")
+	}
+}
+
 // convertToAST produces tree.WindowFrameBounds based on
 // WindowerSpec_Frame_Bounds. Note that it might not be fully equivalent to
 // original - if offsetExprs were present in original tree.WindowFrameBounds,
@@ -248,5 +279,37 @@ func (spec WindowerSpec_Frame_Bounds) convertToAST() tree.WindowFrameBounds {
 
 // ConvertToAST produces a tree.WindowFrame given a WindoweSpec_Frame.
 func (spec *WindowerSpec_Frame) ConvertToAST() *tree.WindowFrame {
-	return &tree.WindowFrame{Mode: spec.Mode.convertToAST(), Bounds: spec.Bounds.convertToAST()}
+	return &tree.WindowFrame{
+		Mode:      spec.Mode.convertToAST(),
+		Bounds:    spec.Bounds.convertToAST(),
+		Exclusion: spec.Exclusion.convertToAST(),
+	}
+}
+
+// Selects returns whether the row (or the range) with the given key is part of
+// the sample. The decision only depends on the seed and on the key, so the
+// table readers of a distributed scan agree on the sample without any
+// coordination, and a REPEATABLE sample is stable across executions.
+func (s *TableSampleSpec) Selects(key []byte) bool {
+	// Hash the seed and the key with FNV-1a, then mix the bits of the hash with
+	// the splitmix64 finalizer, since FNV-1a alone doesn't spread the small
+	// differences between consecutive keys over the high bits.
+	const offset64, prime64 = 14695981039346656037, 1099511628211
+	h := uint64(offset64)
+	for i := uint(0); i < 64; i += 8 {
+		h ^= uint64(s.Seed>>i) & 0xff
+		h *= prime64
+	}
+	for _, b := range key {
+		h ^= uint64(b)
+		h *= prime64
+	}
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	// Compare the top 53 bits of the hash, which are uniformly distributed in
+	// [0, 2^53), with the sampling rate.
+	return float64(h>>11) < s.Percent/100*(1<<53)
 }
@@ -0,0 +1,82 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package distsqlpb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestTableSampleSpecSelects(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numKeys = 10000
+	rowKeys := make([][]byte, numKeys)
+	for i := range rowKeys {
+		k := keys.MakeTablePrefix(53)
+		k = encoding.EncodeUvarintAscending(k, 1 /* index ID */)
+		rowKeys[i] = encoding.EncodeVarintAscending(k, int64(i))
+	}
+	count := func(s *TableSampleSpec) int {
+		n := 0
+		for _, k := range rowKeys {
+			if s.Selects(k) {
+				n++
+			}
+		}
+		return n
+	}
+
+	for _, percent := range []float64{0, 1, 10, 50, 99, 100} {
+		t.Run(fmt.Sprint(percent), func(t *testing.T) {
+			for seed := int64(0); seed < 3; seed++ {
+				s := &TableSampleSpec{Method: TableSampleSpec_BERNOULLI, Percent: percent, Seed: seed}
+				n := count(s)
+				switch percent {
+				case 0, 100:
+					if expected := int(percent / 100 * numKeys); n != expected {
+						t.Fatalf("seed %d: expected %d rows, got %d", seed, expected, n)
+					}
+				default:
+					// Allow for a deviation of 5 standard deviations.
+					expected := percent / 100 * numKeys
+					if dev := float64(n) - expected; dev*dev > 25*expected*(1-percent/100) {
+						t.Fatalf("seed %d: expected about %.0f rows, got %d", seed, expected, n)
+					}
+				}
+				if again := count(s); again != n {
+					t.Fatalf("seed %d: the sample is not stable: %d vs %d rows", seed, n, again)
+				}
+			}
+		})
+	}
+
+	// Different seeds select different rows.
+	a := &TableSampleSpec{Percent: 50, Seed: 1}
+	b := &TableSampleSpec{Percent: 50, Seed: 2}
+	same := 0
+	for _, k := range rowKeys {
+		if a.Selects(k) == b.Selects(k) {
+			same++
+		}
+	}
+	if same == numKeys {
+		t.Fatal("expected different seeds to select different rows")
+	}
+}
@@ -698,7 +698,7 @@ func (p *PhysicalPlan) AddLimit(
 		limitZero = true
 	}
 
-	if len(p.ResultRouters) == 1 {
+	if len(p.ResultRouters) == 1 && len(p.GetLastStagePost().LimitTiesOrdering.Columns) == 0 {
 		// We only have one processor producing results. Just update its PostProcessSpec.
 		// SELECT FROM (SELECT OFFSET 10 LIMIT 1000) OFFSET 5 LIMIT 20 becomes
 		// SELECT OFFSET 10+5 LIMIT min(1000, 20).
@@ -768,6 +768,44 @@ func (p *PhysicalPlan) AddLimit(
 	return nil
 }
 
+// AddLimitWithTies is like AddLimit, but the rows which are peers of the last
+// row within the limit according to the given ordering are also returned. The
+// results of the current plan must be ordered according to the ordering, which
+// refers to the plan's result columns. The limit is always performed by a
+// separate processor placed on the given node.
+func (p *PhysicalPlan) AddLimitWithTies(
+	count int64,
+	offset int64,
+	ordering distsqlpb.Ordering,
+	exprCtx ExprContext,
+	node roachpb.NodeID,
+) error {
+	if count == 0 || count == math.MaxInt64 || len(ordering.Columns) == 0 {
+		// There are no peers to return.
+		return p.AddLimit(count, offset, exprCtx, node)
+	}
+	if count < 0 {
+		return errors.Errorf("negative limit")
+	}
+	if offset < 0 {
+		return errors.Errorf("negative offset")
+	}
+	// Note that the limit can't be merged into the PostProcessSpec of the last
+	// stage, even if there is only one processor producing results: a sorter
+	// uses its limit as a hint to only keep the top rows.
+	p.AddSingleGroupStage(
+		node,
+		distsqlpb.ProcessorCoreUnion{Noop: &distsqlpb.NoopCoreSpec{}},
+		distsqlpb.PostProcessSpec{
+			Offset:            uint64(offset),
+			Limit:             uint64(count),
+			LimitTiesOrdering: ordering,
+		},
+		p.ResultTypes,
+	)
+	return nil
+}
+
 // PopulateEndpoints processes p.Streams and adds the corresponding
 // StreamEndpointSpecs to the processors' input and output specs. This should be
 // used when the plan is completed and ready to be executed.
@@ -380,6 +380,9 @@ type ProducerMetadata struct {
 	// SamplerProgress contains incremental progress information from the sampler
 	// processor.
 	SamplerProgress *distsqlpb.RemoteProducerMetadata_SamplerProgress
+	// Metrics contains the resources consumed by a processor which reads from
+	// KV, to be attributed to the statement on the gateway.
+	Metrics *distsqlpb.RemoteProducerMetadata_Metrics
 }
 
 // RowChannel is a thin layer over a RowChannelMsg channel, which can be used to
@@ -257,6 +257,7 @@ func TestClusterFlow(t *testing.T) {
 	}
 	metas = ignoreMisplannedRanges(metas)
 	metas = ignoreTxnCoordMeta(metas)
+	metas = ignoreMetricsMeta(metas)
 	if len(metas) != 0 {
 		t.Fatalf("unexpected metadata (%d): %+v", len(metas), metas)
 	}
@@ -301,6 +302,18 @@ func ignoreTxnCoordMeta(metas []ProducerMetadata) []ProducerMetadata {
 	return res
 }
 
+// ignoreMetricsMeta takes a slice of metadata and returns the entries excluding
+// the metrics.
+func ignoreMetricsMeta(metas []ProducerMetadata) []ProducerMetadata {
+	res := make([]ProducerMetadata, 0)
+	for _, m := range metas {
+		if m.Metrics == nil {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
 // TestLimitedBufferingDeadlock sets up a scenario which leads to deadlock if
 // a single consumer can block the entire router (#17097).
 func TestLimitedBufferingDeadlock(t *testing.T) {
@@ -510,6 +523,7 @@ func TestLimitedBufferingDeadlock(t *testing.T) {
 	}
 	metas = ignoreMisplannedRanges(metas)
 	metas = ignoreTxnCoordMeta(metas)
+	metas = ignoreMetricsMeta(metas)
 	if len(metas) != 0 {
 		t.Errorf("unexpected metadata (%d): %+v", len(metas), metas)
 	}
@@ -754,6 +768,7 @@ func BenchmarkInfrastructure(b *testing.B) {
 						}
 						metas = ignoreMisplannedRanges(metas)
 						metas = ignoreTxnCoordMeta(metas)
+						metas = ignoreMetricsMeta(metas)
 						if len(metas) != 0 {
 							b.Fatalf("unexpected metadata (%d): %+v", len(metas), metas)
 						}
@@ -80,6 +80,9 @@ func newColBatchScan(
 	if flowCtx.nodeID == 0 {
 		return nil, errors.Errorf("attempting to create a colBatchScan with uninitialized NodeID")
 	}
+	if spec.Sample != nil && spec.Sample.Method == distsqlpb.TableSampleSpec_BERNOULLI {
+		return nil, errors.Errorf("BERNOULLI table samples are not supported")
+	}
 
 	limitHint := limitHint(spec.LimitHint, post)
 
@@ -105,6 +108,7 @@ func newColBatchScan(
 	); err != nil {
 		return nil, err
 	}
+	fetcher.SetLockWaitPolicy(kvWaitPolicy(spec.LockingWaitPolicy))
 
 	nSpans := len(spec.Spans)
 	spans := make(roachpb.Spans, nSpans)
@@ -387,6 +387,11 @@ func newColOperator(
 		return nil, err
 	}
 
+	if len(post.LimitTiesOrdering.Columns) > 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeDataExceptionError,
+			"unable to columnarize limit with ties")
+	}
+
 	if !post.Filter.Empty() {
 		if columnTypes == nil {
 			return nil, pgerror.NewErrorf(pgerror.CodeDataExceptionError,
@@ -99,6 +99,7 @@ func newIndexJoiner(
 	); err != nil {
 		return nil, err
 	}
+	ij.alloc.EnableBatchGrowth()
 	if _, _, err := initRowFetcher(
 		&ij.fetcher,
 		&ij.desc,
@@ -221,6 +222,7 @@ func (ij *indexJoiner) outputStatsToTrace() {
 	jrs := &JoinReaderStats{
 		InputStats:       is,
 		IndexLookupStats: ils,
+		BytesRead:        ij.fetcher.GetBytesRead(),
 	}
 	if sp := opentracing.SpanFromContext(ij.Ctx); sp != nil {
 		tracing.SetSpanStats(sp, jrs)
@@ -228,10 +230,13 @@ func (ij *indexJoiner) outputStatsToTrace() {
 }
 
 func (ij *indexJoiner) generateMeta(ctx context.Context) []ProducerMetadata {
+	trailingMeta := []ProducerMetadata{{
+		Metrics: &distsqlpb.RemoteProducerMetadata_Metrics{BytesRead: ij.fetcher.GetBytesRead()},
+	}}
 	if meta := getTxnCoordMeta(ctx, ij.flowCtx.txn); meta != nil {
-		return []ProducerMetadata{{TxnCoordMeta: meta}}
+		trailingMeta = append(trailingMeta, ProducerMetadata{TxnCoordMeta: meta})
 	}
-	return nil
+	return trailingMeta
 }
 
 // DrainMeta is part of the MetadataSource interface.
@@ -16,6 +16,7 @@ package distsqlrun
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
@@ -24,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/scrub"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -47,7 +49,8 @@ const (
 	jrPerformingLookup
 	// jrCollectingOutputRows means we are collecting the result of the index
 	// lookup to be emitted, while preserving the order of the input, and
-	// optionally rendering rows for unmatched inputs for left outer joins.
+	// optionally rendering rows for unmatched inputs for left outer and anti
+	// joins.
 	jrCollectingOutputRows
 	// jrEmittingRows means we are emitting the results of the index lookup.
 	jrEmittingRows
@@ -194,6 +197,7 @@ func newJoinReader(
 		return nil, errors.Errorf("joinreader index does not cover all columns")
 	}
 
+	jr.alloc.EnableBatchGrowth()
 	_, _, err = initRowFetcher(
 		&jr.fetcher, &jr.desc, int(spec.IndexIdx), jr.colIdxMap, false, /* reverse */
 		jr.neededRightCols(), false /* isCheck */, &jr.alloc,
@@ -424,6 +428,7 @@ func (jr *joinReader) performLookup() (joinReaderState, *ProducerMetadata) {
 
 	// Iterate over the lookup results, map them to the input rows, and emit the
 	// rendered rows.
+	isSemiOrAnti := jr.joinType == sqlbase.LeftSemiJoin || jr.joinType == sqlbase.LeftAntiJoin
 	for _, lookupRow := range jr.lookupRows {
 		if jr.indexFilter.expr != nil {
 			// Apply index filter.
@@ -437,16 +442,28 @@ func (jr *joinReader) performLookup() (joinReaderState, *ProducerMetadata) {
 			}
 		}
 		for _, inputRowIdx := range jr.keyToInputRowIndices[lookupRow.key] {
+			if isSemiOrAnti && len(jr.inputRowIdxToOutputRows[inputRowIdx]) > 0 {
+				// The input row already has a match; semi and anti joins only care
+				// about the existence of one.
+				continue
+			}
 			renderedRow, err := jr.render(jr.inputRows[inputRowIdx], lookupRow.row)
 			if err != nil {
 				jr.MoveToDraining(err)
 				return jrStateUnknown, jr.DrainHelper()
 			}
-			if renderedRow != nil {
-				rowCopy := jr.out.rowAlloc.CopyRow(renderedRow)
-				jr.inputRowIdxToOutputRows[inputRowIdx] = append(
-					jr.inputRowIdxToOutputRows[inputRowIdx], rowCopy)
+			if renderedRow == nil {
+				continue
+			}
+			if isSemiOrAnti {
+				// Semi and anti joins only output the input row. For anti joins, it
+				// is only used to remember that the input row has a match.
+				jr.inputRowIdxToOutputRows[inputRowIdx] = sqlbase.EncDatumRows{jr.inputRows[inputRowIdx]}
+				continue
 			}
+			rowCopy := jr.out.rowAlloc.CopyRow(renderedRow)
+			jr.inputRowIdxToOutputRows[inputRowIdx] = append(
+				jr.inputRowIdxToOutputRows[inputRowIdx], rowCopy)
 		}
 	}
 
@@ -459,16 +476,18 @@ func (jr *joinReader) performLookup() (joinReaderState, *ProducerMetadata) {
 
 // collectOutputRows iterates over jr.inputRowIdxToOutputRows and adds output
 // rows to jr.Emit, rendering rows for unmatched inputs if the join is a left
-// outer join, while preserving the input order.
+// outer join, or emitting the unmatched inputs if the join is an anti join,
+// while preserving the input order.
 func (jr *joinReader) collectOutputRows() joinReaderState {
 	for i, outputRows := range jr.inputRowIdxToOutputRows {
-		if len(outputRows) == 0 {
-			if jr.joinType == sqlbase.LeftOuterJoin {
-				if row := jr.renderUnmatchedRow(jr.inputRows[i], leftSide); row != nil {
-					jr.toEmit = append(jr.toEmit, jr.out.rowAlloc.CopyRow(row))
-				}
+		switch {
+		case len(outputRows) == 0 && jr.joinType == sqlbase.LeftOuterJoin:
+			if row := jr.renderUnmatchedRow(jr.inputRows[i], leftSide); row != nil {
+				jr.toEmit = append(jr.toEmit, jr.out.rowAlloc.CopyRow(row))
 			}
-		} else {
+		case len(outputRows) == 0 && jr.joinType == sqlbase.LeftAntiJoin:
+			jr.toEmit = append(jr.toEmit, jr.inputRows[i])
+		case jr.joinType != sqlbase.LeftAntiJoin:
 			jr.toEmit = append(jr.toEmit, outputRows...)
 		}
 	}
@@ -528,6 +547,7 @@ func (jrs *JoinReaderStats) Stats() map[string]string {
 	for k, v := range toMerge {
 		statsMap[k] = v
 	}
+	statsMap[joinReaderTagPrefix+bytesReadTagSuffix] = humanizeutil.IBytes(jrs.BytesRead)
 	return statsMap
 }
 
@@ -537,7 +557,7 @@ func (jrs *JoinReaderStats) StatsForQueryPlan() []string {
 		jrs.InputStats.StatsForQueryPlan(""),
 		jrs.IndexLookupStats.StatsForQueryPlan("index ")...,
 	)
-	return is
+	return append(is, fmt.Sprintf("%s: %s", bytesReadQueryPlanSuffix, humanizeutil.IBytes(jrs.BytesRead)))
 }
 
 // outputStatsToTrace outputs the collected joinReader stats to the trace. Will
@@ -555,6 +575,7 @@ func (jr *joinReader) outputStatsToTrace() {
 	jrs := &JoinReaderStats{
 		InputStats:       is,
 		IndexLookupStats: ils,
+		BytesRead:        jr.fetcher.GetBytesRead(),
 	}
 	if sp := opentracing.SpanFromContext(jr.Ctx); sp != nil {
 		tracing.SetSpanStats(sp, jrs)
@@ -562,10 +583,13 @@ func (jr *joinReader) outputStatsToTrace() {
 }
 
 func (jr *joinReader) generateMeta(ctx context.Context) []ProducerMetadata {
+	trailingMeta := []ProducerMetadata{{
+		Metrics: &distsqlpb.RemoteProducerMetadata_Metrics{BytesRead: jr.fetcher.GetBytesRead()},
+	}}
 	if meta := getTxnCoordMeta(ctx, jr.flowCtx.txn); meta != nil {
-		return []ProducerMetadata{{TxnCoordMeta: meta}}
+		trailingMeta = append(trailingMeta, ProducerMetadata{TxnCoordMeta: meta})
 	}
-	return nil
+	return trailingMeta
 }
 
 // DrainMeta is part of the MetadataSource interface.
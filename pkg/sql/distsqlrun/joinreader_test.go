@@ -267,6 +267,76 @@ func TestJoinReader(t *testing.T) {
 			outputTypes: sqlbase.OneIntCol,
 			expected:    "[['two']]",
 		},
+		{
+			description: "Test semi lookup join on primary index",
+			post: distsqlpb.PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1},
+			},
+			input: [][]tree.Datum{
+				{tree.NewDInt(1), tree.NewDInt(0)},
+				{tree.NewDInt(20), tree.NewDInt(0)},
+				{tree.DNull, tree.NewDInt(0)},
+				{tree.NewDInt(5), tree.NewDInt(0)},
+			},
+			lookupCols:  []uint32{0},
+			joinType:    sqlbase.LeftSemiJoin,
+			inputTypes:  sqlbase.TwoIntCols,
+			outputTypes: sqlbase.TwoIntCols,
+			expected:    "[[1 0] [5 0]]",
+		},
+		{
+			description: "Test semi lookup join with onExpr",
+			post: distsqlpb.PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1},
+			},
+			input: [][]tree.Datum{
+				{tree.NewDInt(1), tree.NewDInt(9)},
+				{tree.NewDInt(5), tree.NewDInt(3)},
+			},
+			lookupCols:  []uint32{0},
+			joinType:    sqlbase.LeftSemiJoin,
+			inputTypes:  sqlbase.TwoIntCols,
+			outputTypes: sqlbase.TwoIntCols,
+			onExpr:      "@4 > @2",
+			expected:    "[[5 3]]",
+		},
+		{
+			description: "Test anti lookup join on primary index",
+			post: distsqlpb.PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1},
+			},
+			input: [][]tree.Datum{
+				{tree.NewDInt(1), tree.NewDInt(0)},
+				{tree.NewDInt(20), tree.NewDInt(0)},
+				{tree.DNull, tree.NewDInt(0)},
+				{tree.NewDInt(5), tree.NewDInt(0)},
+			},
+			lookupCols:  []uint32{0},
+			joinType:    sqlbase.LeftAntiJoin,
+			inputTypes:  sqlbase.TwoIntCols,
+			outputTypes: sqlbase.TwoIntCols,
+			expected:    "[[20 0] [NULL 0]]",
+		},
+		{
+			description: "Test anti lookup join with onExpr",
+			post: distsqlpb.PostProcessSpec{
+				Projection:    true,
+				OutputColumns: []uint32{0, 1},
+			},
+			input: [][]tree.Datum{
+				{tree.NewDInt(1), tree.NewDInt(9)},
+				{tree.NewDInt(5), tree.NewDInt(3)},
+			},
+			lookupCols:  []uint32{0},
+			joinType:    sqlbase.LeftAntiJoin,
+			inputTypes:  sqlbase.TwoIntCols,
+			outputTypes: sqlbase.TwoIntCols,
+			onExpr:      "@4 > @2",
+			expected:    "[[1 9]]",
+		},
 	}
 	for i, td := range []*sqlbase.TableDescriptor{tdSecondary, tdFamily, tdInterleaved} {
 		for _, c := range testCases {
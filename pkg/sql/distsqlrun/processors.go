@@ -93,6 +93,17 @@ type ProcOutputHelper struct {
 	maxRowIdx uint64
 
 	rowIdx uint64
+
+	// tiesOrdering is set if the rows following the last row within the limit
+	// which are peers of it according to this ordering are also emitted. The
+	// processor produces its rows in this order.
+	tiesOrdering sqlbase.ColumnOrdering
+	// tiesRow is a copy of the last row within the limit, which is set while
+	// its peers are being emitted.
+	tiesRow   sqlbase.EncDatumRow
+	tiesAlloc sqlbase.DatumAlloc
+	types     []sqlbase.ColumnType
+	evalCtx   *tree.EvalContext
 }
 
 // Reset resets this ProcOutputHelper, retaining allocated memory in its slices.
@@ -190,6 +201,16 @@ func (h *ProcOutputHelper) Init(
 	} else {
 		h.maxRowIdx = h.offset + post.Limit
 	}
+	if len(post.LimitTiesOrdering.Columns) > 0 && h.maxRowIdx != math.MaxUint64 {
+		for _, c := range post.LimitTiesOrdering.Columns {
+			if int(c.ColIdx) >= h.numInternalCols {
+				return errors.Errorf("invalid ties ordering column %d (only %d available)", c.ColIdx, h.numInternalCols)
+			}
+		}
+		h.tiesOrdering = distsqlpb.ConvertToColumnOrdering(post.LimitTiesOrdering)
+		h.types = types
+		h.evalCtx = evalCtx
+	}
 
 	return nil
 }
@@ -208,6 +229,12 @@ func (h *ProcOutputHelper) neededColumns() (colIdxs util.FastIntSet) {
 		colIdxs.Add(int(c))
 	}
 
+	// The columns of the ties ordering are compared to the last row within
+	// the limit.
+	for _, c := range h.tiesOrdering {
+		colIdxs.Add(c.ColIdx)
+	}
+
 	for i := 0; i < h.numInternalCols; i++ {
 		// See if filter requires this column.
 		if h.filter != nil && h.filter.vars.IndexedVarUsed(i) {
@@ -334,7 +361,7 @@ func (h *ProcOutputHelper) EmitRow(
 			r == DrainRequested)
 		return r, nil
 	}
-	if h.rowIdx == h.maxRowIdx {
+	if h.rowIdx == h.maxRowIdx && h.tiesRow == nil {
 		log.VEventf(ctx, 1, "hit row limit; asking producer to drain")
 		return DrainRequested, nil
 	}
@@ -355,10 +382,14 @@ func (h *ProcOutputHelper) EmitRow(
 // moreRowsOK=false can be returned at the same time: the row that satisfies the
 // limit is returned at the same time as a DrainRequested status. In that case,
 // the caller is supposed to both deal with the row and start draining.
+//
+// If the limit is extended to the peers of the last row within it, that row
+// doesn't satisfy the limit; the limit is reached at the first row which is not
+// one of its peers, which is not returned.
 func (h *ProcOutputHelper) ProcessRow(
 	ctx context.Context, row sqlbase.EncDatumRow,
 ) (_ sqlbase.EncDatumRow, moreRowsOK bool, _ error) {
-	if h.rowIdx >= h.maxRowIdx {
+	if h.rowIdx >= h.maxRowIdx && h.tiesRow == nil {
 		return nil, false, nil
 	}
 
@@ -375,11 +406,35 @@ func (h *ProcOutputHelper) ProcessRow(
 			return nil, true, nil
 		}
 	}
-	h.rowIdx++
-	if h.rowIdx <= h.offset {
-		// Suppress row.
-		return nil, true, nil
+	if h.tiesRow != nil {
+		// The limit was reached; only the peers of the last row within the
+		// limit are emitted.
+		cmp, err := row.Compare(h.types, &h.tiesAlloc, h.tiesOrdering, h.evalCtx, h.tiesRow)
+		if err != nil {
+			return nil, false, err
+		}
+		if cmp != 0 {
+			h.tiesRow = nil
+			return nil, false, nil
+		}
+	} else {
+		h.rowIdx++
+		if h.rowIdx <= h.offset {
+			// Suppress row.
+			return nil, true, nil
+		}
+		if h.rowIdx == h.maxRowIdx && h.tiesOrdering != nil {
+			// The row may be reused by the caller, so its datums are decoded
+			// in the copy.
+			h.tiesRow = h.rowAlloc.CopyRow(row)
+			for _, c := range h.tiesOrdering {
+				if err := h.tiesRow[c.ColIdx].EnsureDecoded(&h.types[c.ColIdx], &h.tiesAlloc); err != nil {
+					return nil, false, err
+				}
+			}
+		}
 	}
+	moreRowsOK = h.rowIdx < h.maxRowIdx || h.tiesRow != nil
 
 	if len(h.renderExprs) > 0 {
 		// Rendering.
@@ -397,11 +452,11 @@ func (h *ProcOutputHelper) ProcessRow(
 		}
 	} else {
 		// No rendering or projection.
-		return row, h.rowIdx < h.maxRowIdx, nil
+		return row, moreRowsOK, nil
 	}
 
 	// If this row satisfies the limit, the caller is told to drain.
-	return h.outputRow, h.rowIdx < h.maxRowIdx, nil
+	return h.outputRow, moreRowsOK, nil
 }
 
 // Close signals to the output that there will be no more rows.
@@ -412,6 +467,7 @@ func (h *ProcOutputHelper) Close() {
 // consumerClosed stops output of additional rows from ProcessRow.
 func (h *ProcOutputHelper) consumerClosed() {
 	h.rowIdx = h.maxRowIdx
+	h.tiesRow = nil
 }
 
 // ProcessorBase is supposed to be embedded by Processors. It provides
@@ -259,6 +259,49 @@ func TestPostProcess(t *testing.T) {
 			expNeededCols: []int{0, 1, 2},
 			expected:      "[[1 2 3] [1 2 4]]",
 		},
+
+		// Limit with ties.
+		{
+			post: distsqlpb.PostProcessSpec{
+				Limit:             2,
+				LimitTiesOrdering: distsqlpb.Ordering{Columns: []distsqlpb.Ordering_Column{{ColIdx: 0}}},
+			},
+			outputTypes:   sqlbase.ThreeIntCols,
+			expNeededCols: []int{0, 1, 2},
+			expected:      "[[0 1 2] [0 1 3] [0 1 4] [0 2 3] [0 2 4] [0 3 4]]",
+		},
+		{
+			post: distsqlpb.PostProcessSpec{
+				Limit:             3,
+				LimitTiesOrdering: distsqlpb.Ordering{Columns: []distsqlpb.Ordering_Column{{ColIdx: 0}, {ColIdx: 1}}},
+			},
+			outputTypes:   sqlbase.ThreeIntCols,
+			expNeededCols: []int{0, 1, 2},
+			expected:      "[[0 1 2] [0 1 3] [0 1 4]]",
+		},
+		{
+			post: distsqlpb.PostProcessSpec{
+				Limit:             4,
+				LimitTiesOrdering: distsqlpb.Ordering{Columns: []distsqlpb.Ordering_Column{{ColIdx: 0}, {ColIdx: 1}}},
+			},
+			outputTypes:   sqlbase.ThreeIntCols,
+			expNeededCols: []int{0, 1, 2},
+			expected:      "[[0 1 2] [0 1 3] [0 1 4] [0 2 3] [0 2 4]]",
+		},
+
+		// Offset + limit with ties + projection.
+		{
+			post: distsqlpb.PostProcessSpec{
+				Offset:            3,
+				Limit:             1,
+				LimitTiesOrdering: distsqlpb.Ordering{Columns: []distsqlpb.Ordering_Column{{ColIdx: 0}}},
+				Projection:        true,
+				OutputColumns:     []uint32{2},
+			},
+			outputTypes:   sqlbase.OneIntCol,
+			expNeededCols: []int{0, 2},
+			expected:      "[[3] [4] [4]]",
+		},
 	}
 
 	for tcIdx, tc := range testCases {
@@ -16,6 +16,7 @@ package distsqlrun
 
 import (
 	"context"
+	"math"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
@@ -58,6 +59,11 @@ type projectSetProcessor struct {
 	// thus also whether NULLs should be emitted instead.
 	done []bool
 
+	// bounds contains, for each `expr`, the bounds on the values needed
+	// from its generator, which are passed to the generators implementing
+	// tree.BoundedValueGenerator.
+	bounds []tree.ValueGeneratorBounds
+
 	// emitCount is used to track the number of rows that have been
 	// emitted from Next().
 	emitCount int64
@@ -120,9 +126,41 @@ func (ps *projectSetProcessor) Start(ctx context.Context) context.Context {
 		}
 		ps.exprHelpers[i] = &helper
 	}
+	if err := ps.initBounds(); err != nil {
+		ps.MoveToDraining(err)
+	}
 	return ctx
 }
 
+// initBounds evaluates the bounds of the spec. Additionally, when the
+// output of the processor is only limited, no generator needs to produce
+// more values than the number of rows after which the processor stops.
+func (ps *projectSetProcessor) initBounds() error {
+	ps.bounds = make([]tree.ValueGeneratorBounds, len(ps.spec.Exprs))
+	evalBound := func(expr distsqlpb.Expression) (tree.Datum, error) {
+		var helper exprHelper
+		if err := helper.init(expr, nil /* types */, ps.evalCtx); err != nil || expr.Empty() {
+			return nil, err
+		}
+		return helper.eval(nil /* row */)
+	}
+	for i := range ps.spec.LowerBounds {
+		var err error
+		if ps.bounds[i].Lo, err = evalBound(ps.spec.LowerBounds[i]); err != nil {
+			return err
+		}
+		if ps.bounds[i].Hi, err = evalBound(ps.spec.UpperBounds[i]); err != nil {
+			return err
+		}
+	}
+	if ps.out.filter == nil && ps.out.maxRowIdx < math.MaxInt64 {
+		for i := range ps.bounds {
+			ps.bounds[i].Limit = int64(ps.out.maxRowIdx)
+		}
+	}
+	return nil
+}
+
 // nextInputRow returns the next row or metadata from ps.input. It also
 // initializes the value generators for that row.
 func (ps *projectSetProcessor) nextInputRow() (sqlbase.EncDatumRow, *ProducerMetadata, error) {
@@ -147,6 +185,9 @@ func (ps *projectSetProcessor) nextInputRow() (sqlbase.EncDatumRow, *ProducerMet
 			if gen == nil {
 				gen = builtins.EmptyGenerator()
 			}
+			if bg, ok := gen.(tree.BoundedValueGenerator); ok {
+				bg.SetBounds(ps.bounds[i])
+			}
 			if err := gen.Start(); err != nil {
 				return nil, nil, err
 			}
@@ -291,9 +291,12 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				histogram = &h
 			}
 
-			columnIDs := make([]sqlbase.ColumnID, len(si.spec.Columns))
-			for i, c := range si.spec.Columns {
-				columnIDs[i] = s.sampledCols[c]
+			columnIDs := si.spec.ColumnIDs
+			if si.spec.Expression == "" {
+				columnIDs = make([]sqlbase.ColumnID, len(si.spec.Columns))
+				for i, c := range si.spec.Columns {
+					columnIDs[i] = s.sampledCols[c]
+				}
 			}
 
 			// Delete old stats that have been superseded.
@@ -303,6 +306,8 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				txn,
 				s.tableID,
 				columnIDs,
+				si.spec.Expression,
+				si.spec.Predicate,
 			); err != nil {
 				return err
 			}
@@ -315,6 +320,8 @@ func (s *sampleAggregator) writeResults(ctx context.Context) error {
 				s.tableID,
 				si.spec.StatName,
 				columnIDs,
+				si.spec.Expression,
+				si.spec.Predicate,
 				si.numRows,
 				int64(si.sketch.Estimate()),
 				si.numNulls,
@@ -44,6 +44,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/log/logtags"
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	opentracing "github.com/opentracing/opentracing-go"
@@ -76,12 +77,16 @@ import (
 //
 // ATTENTION: When updating these fields, add to version_history.txt explaining
 // what changed.
-const Version distsqlpb.DistSQLVersion = 22
+const Version distsqlpb.DistSQLVersion = 23
 
 // MinAcceptedVersion is the oldest version that the server is
 // compatible with; see above.
 const MinAcceptedVersion distsqlpb.DistSQLVersion = 21
 
+// SetupFlowsVersion is the first version of the servers that implement the
+// SetupFlows RPC.
+const SetupFlowsVersion distsqlpb.DistSQLVersion = 23
+
 // minFlowDrainWait is the minimum amount of time a draining server allows for
 // any incoming flows to be registered. It acts as a grace period in which the
 // draining server waits for its gossiped draining state to be received by other
@@ -559,22 +564,72 @@ func (ds *ServerImpl) SetupFlow(
 	ctx context.Context, req *distsqlpb.SetupFlowRequest,
 ) (*distsqlpb.SimpleResponse, error) {
 	log.VEventf(ctx, 1, "received SetupFlow request from n%v for flow %v", req.Flow.Gateway, req.Flow.FlowID)
-	parentSpan := opentracing.SpanFromContext(ctx)
+	return ds.setupAndScheduleFlow(opentracing.SpanFromContext(ctx), req), nil
+}
 
-	// Note: the passed context will be canceled when this RPC completes, so we
-	// can't associate it with the flow.
-	ctx = ds.AnnotateCtx(context.Background())
+// setupAndScheduleFlow sets up a flow requested by another node and schedules
+// it. Errors are returned in the response so that they are packaged correctly
+// over the wire; if they were returned by the RPCs, they would become part of
+// an rpc error.
+func (ds *ServerImpl) setupAndScheduleFlow(
+	parentSpan opentracing.Span, req *distsqlpb.SetupFlowRequest,
+) *distsqlpb.SimpleResponse {
+	// Note: the context of the RPC will be canceled when the RPC completes, so
+	// we can't associate it with the flow.
+	ctx := ds.AnnotateCtx(context.Background())
 	ctx, f, err := ds.setupFlow(ctx, parentSpan, &ds.memMonitor, req, nil /* syncFlowConsumer */, LocalState{})
 	if err == nil {
 		err = ds.flowScheduler.ScheduleFlow(ctx, f)
 	}
 	if err != nil {
-		// We return flow deployment errors in the response so that they are
-		// packaged correctly over the wire. If we return them directly to this
-		// function, they become part of an rpc error.
-		return &distsqlpb.SimpleResponse{Error: distsqlpb.NewError(err)}, nil
+		return &distsqlpb.SimpleResponse{Error: distsqlpb.NewError(err)}
+	}
+	return &distsqlpb.SimpleResponse{}
+}
+
+// SetupFlows is part of the DistSQLServer interface.
+func (ds *ServerImpl) SetupFlows(stream distsqlpb.DistSQL_SetupFlowsServer) error {
+	ctx := ds.AnnotateCtx(stream.Context())
+	// The requests are processed concurrently, since setting up a flow can
+	// block (e.g. on the flow scheduler's queue); sendMu serializes the
+	// responses.
+	var sendMu syncutil.Mutex
+	var sendErr error
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		if err := ds.Stopper.RunAsyncTask(ctx, "distsqlrun.ServerImpl: setup flow", func(ctx context.Context) {
+			defer wg.Done()
+			resp := distsqlpb.SetupFlowsResponse{RequestID: msg.RequestID}
+			req, err := msg.GetSetupFlowRequest()
+			if err != nil {
+				resp.Response.Error = distsqlpb.NewError(err)
+			} else {
+				log.VEventf(ctx, 1, "received SetupFlows request from n%v for flow %v",
+					req.Flow.Gateway, req.Flow.FlowID)
+				// The flows set up on the stream are not traced, since the
+				// stream is shared by many queries. Queries whose trace is
+				// recorded use SetupFlow instead.
+				resp.Response = *ds.setupAndScheduleFlow(nil /* parentSpan */, req)
+			}
+			sendMu.Lock()
+			defer sendMu.Unlock()
+			if sendErr == nil {
+				sendErr = stream.Send(&resp)
+			}
+		}); err != nil {
+			wg.Done()
+			return err
+		}
 	}
-	return &distsqlpb.SimpleResponse{}, nil
 }
 
 func (ds *ServerImpl) flowStreamInt(
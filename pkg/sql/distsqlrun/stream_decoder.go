@@ -118,6 +118,9 @@ func (sd *StreamDecoder) AddMessage(msg *distsqlpb.ProducerMessage) error {
 			case *distsqlpb.RemoteProducerMetadata_SamplerProgress_:
 				meta.SamplerProgress = v.SamplerProgress
 
+			case *distsqlpb.RemoteProducerMetadata_Metrics_:
+				meta.Metrics = v.Metrics
+
 			case *distsqlpb.RemoteProducerMetadata_Error:
 				meta.Err = v.Error.ErrorDetail()
 
@@ -104,6 +104,10 @@ func (se *StreamEncoder) AddMetadata(meta ProducerMetadata) {
 		enc.Value = &distsqlpb.RemoteProducerMetadata_SamplerProgress_{
 			SamplerProgress: meta.SamplerProgress,
 		}
+	} else if meta.Metrics != nil {
+		enc.Value = &distsqlpb.RemoteProducerMetadata_Metrics_{
+			Metrics: meta.Metrics,
+		}
 	} else {
 		enc.Value = &distsqlpb.RemoteProducerMetadata_Error{
 			Error: distsqlpb.NewError(meta.Err),
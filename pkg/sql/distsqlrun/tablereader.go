@@ -16,6 +16,7 @@ package distsqlrun
 
 import (
 	"context"
+	"fmt"
 	"sync"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -23,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 	"github.com/opentracing/opentracing-go"
@@ -51,6 +53,11 @@ type tableReader struct {
 
 	ignoreMisplannedRanges bool
 
+	// sample is set if the tableReader only returns a BERNOULLI sample of the
+	// rows. SYSTEM samples are applied when planning the spans of the
+	// tableReader.
+	sample *distsqlpb.TableSampleSpec
+
 	// input is really the fetcher below, possibly wrapped in a stats generator.
 	input RowSource
 	// fetcher is the underlying Fetcher, should only be used for
@@ -114,12 +121,20 @@ func newTableReader(
 	neededColumns := tr.out.neededColumns()
 
 	columnIdxMap := spec.Table.ColumnIdxMapWithMutations(returnMutations)
+	tr.alloc.EnableBatchGrowth()
+	// The allocator is only used by the fetcher, which decodes the immutable
+	// buffers of the KV responses, so the decoded datums can reference them.
+	tr.alloc.ReferenceDecodedBuffers()
 	if _, _, err := initRowFetcher(
 		&tr.fetcher, &spec.Table, int(spec.IndexIdx), columnIdxMap, spec.Reverse,
 		neededColumns, spec.IsCheck, &tr.alloc, spec.Visibility,
 	); err != nil {
 		return nil, err
 	}
+	tr.fetcher.SetLockWaitPolicy(kvWaitPolicy(spec.LockingWaitPolicy))
+	if spec.Sample != nil && spec.Sample.Method == distsqlpb.TableSampleSpec_BERNOULLI {
+		tr.sample = spec.Sample
+	}
 
 	nSpans := len(spec.Spans)
 	if cap(tr.spans) >= nSpans {
@@ -206,6 +221,21 @@ func initRowFetcher(
 	return index, isSecondaryIndex, nil
 }
 
+// kvWaitPolicy returns the wait policy of the KV requests that implement
+// the given wait policy of a scan.
+func kvWaitPolicy(p distsqlpb.ScanLockingWaitPolicy) roachpb.WaitPolicy {
+	switch p {
+	case distsqlpb.ScanLockingWaitPolicy_BLOCK:
+		return roachpb.WaitPolicy_BLOCK
+	case distsqlpb.ScanLockingWaitPolicy_SKIP_LOCKED:
+		return roachpb.WaitPolicy_SKIP_LOCKED
+	case distsqlpb.ScanLockingWaitPolicy_ERROR:
+		return roachpb.WaitPolicy_ERROR
+	default:
+		panic(fmt.Sprintf("unknown wait policy %s", p))
+	}
+}
+
 func (tr *tableReader) generateTrailingMeta(ctx context.Context) []ProducerMetadata {
 	trailingMeta := tr.generateMeta(ctx)
 	tr.InternalClose()
@@ -279,6 +309,10 @@ func (tr *tableReader) Next() (sqlbase.EncDatumRow, *ProducerMetadata) {
 			break
 		}
 
+		if tr.sample != nil && !tr.sample.Selects(tr.fetcher.RowKey()) {
+			continue
+		}
+
 		if outRow := tr.ProcessRowHelper(row); outRow != nil {
 			return outRow, nil
 		}
@@ -298,12 +332,17 @@ const tableReaderTagPrefix = "tablereader."
 
 // Stats implements the SpanStats interface.
 func (trs *TableReaderStats) Stats() map[string]string {
-	return trs.InputStats.Stats(tableReaderTagPrefix)
+	statsMap := trs.InputStats.Stats(tableReaderTagPrefix)
+	statsMap[tableReaderTagPrefix+bytesReadTagSuffix] = humanizeutil.IBytes(trs.BytesRead)
+	return statsMap
 }
 
 // StatsForQueryPlan implements the DistSQLSpanStats interface.
 func (trs *TableReaderStats) StatsForQueryPlan() []string {
-	return trs.InputStats.StatsForQueryPlan("" /* prefix */)
+	return append(
+		trs.InputStats.StatsForQueryPlan("" /* prefix */),
+		fmt.Sprintf("%s: %s", bytesReadQueryPlanSuffix, humanizeutil.IBytes(trs.BytesRead)),
+	)
 }
 
 // outputStatsToTrace outputs the collected tableReader stats to the trace. Will
@@ -314,7 +353,10 @@ func (tr *tableReader) outputStatsToTrace() {
 		return
 	}
 	if sp := opentracing.SpanFromContext(tr.Ctx); sp != nil {
-		tracing.SetSpanStats(sp, &TableReaderStats{InputStats: is})
+		tracing.SetSpanStats(sp, &TableReaderStats{
+			InputStats: is,
+			BytesRead:  tr.fetcher.GetBytesRead(),
+		})
 	}
 }
 
@@ -326,6 +368,9 @@ func (tr *tableReader) generateMeta(ctx context.Context) []ProducerMetadata {
 			trailingMeta = append(trailingMeta, ProducerMetadata{Ranges: ranges})
 		}
 	}
+	trailingMeta = append(trailingMeta, ProducerMetadata{
+		Metrics: &distsqlpb.RemoteProducerMetadata_Metrics{BytesRead: tr.fetcher.GetBytesRead()},
+	})
 	if meta := getTxnCoordMeta(ctx, tr.flowCtx.txn); meta != nil {
 		trailingMeta = append(trailingMeta, ProducerMetadata{TxnCoordMeta: meta})
 	}
@@ -161,10 +161,16 @@ func TestTableReader(t *testing.T) {
 				}
 
 				var res sqlbase.EncDatumRows
+				var bytesRead int64
 				for {
 					row, meta := results.Next()
-					if meta != nil && meta.TxnCoordMeta == nil {
-						t.Fatalf("unexpected metadata: %+v", meta)
+					if meta != nil {
+						if meta.Metrics != nil {
+							bytesRead += meta.Metrics.BytesRead
+						} else if meta.TxnCoordMeta == nil {
+							t.Fatalf("unexpected metadata: %+v", meta)
+						}
+						continue
 					}
 					if row == nil {
 						break
@@ -174,6 +180,9 @@ func TestTableReader(t *testing.T) {
 				if result := res.String(tr.OutputTypes()); result != c.expected {
 					t.Errorf("invalid results: %s, expected %s'", result, c.expected)
 				}
+				if bytesRead == 0 {
+					t.Errorf("expected the tableReader to report the KV bytes it read")
+				}
 			})
 		})
 	}
@@ -275,8 +284,8 @@ ALTER TABLE t EXPERIMENTAL_RELOCATE VALUES (ARRAY[2], 1), (ARRAY[1], 2), (ARRAY[
 		for _, m := range metas {
 			if len(m.Ranges) > 0 {
 				misplannedRanges = m.Ranges
-			} else if m.TxnCoordMeta == nil {
-				t.Fatalf("expected only txn coord meta or misplanned ranges, got: %+v", metas)
+			} else if m.TxnCoordMeta == nil && m.Metrics == nil {
+				t.Fatalf("expected only txn coord meta, metrics or misplanned ranges, got: %+v", metas)
 			}
 		}
 		if len(misplannedRanges) != 2 {
@@ -453,7 +462,7 @@ func BenchmarkTableReader(b *testing.B) {
 				count := 0
 				for {
 					row, meta := tr.Next()
-					if meta != nil && meta.TxnCoordMeta == nil {
+					if meta != nil && meta.TxnCoordMeta == nil && meta.Metrics == nil {
 						b.Fatalf("unexpected metadata: %+v", meta)
 					}
 					if row == nil {
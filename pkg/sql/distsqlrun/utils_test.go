@@ -110,14 +110,20 @@ func (r *RowDisposer) Types() []sqlbase.ColumnType {
 	return nil
 }
 
-// NextNoMeta is a version of Next which fails the test if
-// it encounters any metadata.
+// NextNoMeta is a version of Next which fails the test if it encounters any
+// metadata, other than the metrics which the processors reading from KV
+// always emit.
 func (rb *RowBuffer) NextNoMeta(tb testing.TB) sqlbase.EncDatumRow {
-	row, meta := rb.Next()
-	if meta != nil {
-		tb.Fatalf("unexpected metadata: %v", meta)
+	for {
+		row, meta := rb.Next()
+		if meta != nil && meta.Metrics != nil {
+			continue
+		}
+		if meta != nil {
+			tb.Fatalf("unexpected metadata: %v", meta)
+		}
+		return row
 	}
-	return row
 }
 
 // GetRowsNoMeta returns the rows in the buffer; it fails the test if it
@@ -202,6 +208,11 @@ func (ds *MockDistSQLServer) SetupFlow(
 	return nil, nil
 }
 
+// SetupFlows is part of the DistSQLServer interface.
+func (ds *MockDistSQLServer) SetupFlows(stream distsqlpb.DistSQL_SetupFlowsServer) error {
+	return nil
+}
+
 // FlowStream is part of the DistSQLServer interface.
 func (ds *MockDistSQLServer) FlowStream(stream distsqlpb.DistSQL_FlowStreamServer) error {
 	donec := make(chan error)
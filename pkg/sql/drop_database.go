@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type dropDatabaseNode struct {
@@ -201,12 +202,14 @@ func (n *dropDatabaseNode) startExec(params runParams) error {
 		EventLogDropDatabase,
 		int32(n.dbDesc.ID),
 		int32(params.extendedEvalCtx.NodeID),
-		struct {
-			DatabaseName         string
-			Statement            string
-			User                 string
-			DroppedSchemaObjects []string
-		}{n.n.Name.String(), n.n.String(), p.SessionData().User, tbNameStrings},
+		&eventpb.DropDatabase{
+			DatabaseName: n.n.Name.String(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      p.SessionData().User,
+			},
+			DroppedSchemaObjects: tbNameStrings,
+		},
 	)
 }
 
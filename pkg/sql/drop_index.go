@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/pkg/errors"
 )
 
@@ -252,14 +253,15 @@ func (p *planner) dropIndexByName(
 		EventLogDropIndex,
 		int32(tableDesc.ID),
 		int32(p.extendedEvalCtx.NodeID),
-		struct {
-			TableName           string
-			IndexName           string
-			Statement           string
-			User                string
-			MutationID          uint32
-			CascadeDroppedViews []string
-		}{tn.FQString(), string(idxName), jobDesc, p.SessionData().User, uint32(mutationID),
-			droppedViews},
+		&eventpb.DropIndex{
+			TableName: tn.FQString(),
+			IndexName: string(idxName),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: jobDesc,
+				User:      p.SessionData().User,
+			},
+			MutationID:          uint32(mutationID),
+			CascadeDroppedViews: droppedViews,
+		},
 	)
 }
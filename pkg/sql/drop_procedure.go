@@ -0,0 +1,84 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+type dropProcedureNode struct {
+	n      *tree.DropProcedure
+	dbDesc *sqlbase.DatabaseDescriptor
+	// idx is the index of the procedure in the routines of the database.
+	idx int
+}
+
+// DropProcedure drops a procedure.
+// Privileges: CREATE on database.
+//   notes: postgres requires ownership of the procedure.
+func (p *planner) DropProcedure(ctx context.Context, n *tree.DropProcedure) (planNode, error) {
+	dbDesc, routine, idx, err := p.resolveProcedure(ctx, &n.Name)
+	if err != nil {
+		return nil, err
+	}
+	if routine == nil {
+		if n.IfExists {
+			return newZeroNode(nil /* columns */), nil
+		}
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedFunctionError,
+			"procedure %q does not exist", n.Name.Table())
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &dropProcedureNode{n: n, dbDesc: dbDesc, idx: idx}, nil
+}
+
+func (n *dropProcedureNode) startExec(params runParams) error {
+	n.dbDesc.Routines = append(n.dbDesc.Routines[:n.idx], n.dbDesc.Routines[n.idx+1:]...)
+	if err := params.p.writeDatabaseRoutines(params.ctx, n.dbDesc); err != nil {
+		return err
+	}
+
+	// Record this procedure deletion in the event log. This is an auditable
+	// log event and is recorded in the same transaction as the database
+	// descriptor update.
+	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
+		params.ctx,
+		params.p.txn,
+		EventLogDropProcedure,
+		int32(n.dbDesc.ID),
+		int32(params.extendedEvalCtx.NodeID),
+		&eventpb.DropProcedure{
+			ProcedureName: n.n.Name.FQString(),
+			CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+				Statement: n.n.String(),
+				User:      params.SessionData().User,
+			},
+		},
+	)
+}
+
+func (n *dropProcedureNode) Next(runParams) (bool, error) { return false, nil }
+func (n *dropProcedureNode) Values() tree.Datums          { return tree.Datums{} }
+func (n *dropProcedureNode) Close(context.Context)        {}
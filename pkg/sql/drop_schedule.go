@@ -0,0 +1,78 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+type dropScheduleNode struct {
+	n *tree.DropSchedule
+
+	run dropScheduleRun
+}
+
+type dropScheduleRun struct {
+	rowsAffected int
+}
+
+// DropSchedule drops a schedule along with its run history.
+// Privileges: superuser.
+func (p *planner) DropSchedule(ctx context.Context, n *tree.DropSchedule) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "DROP SCHEDULE"); err != nil {
+		return nil, err
+	}
+	return &dropScheduleNode{n: n}, nil
+}
+
+func (n *dropScheduleNode) startExec(params runParams) error {
+	ie := params.extendedEvalCtx.ExecCfg.InternalExecutor
+	name := string(n.n.Name)
+	var err error
+	n.run.rowsAffected, err = ie.Exec(
+		params.ctx, "drop-schedule", params.p.txn,
+		`DELETE FROM system.schedules WHERE name = $1`, name,
+	)
+	if err != nil {
+		return err
+	}
+	if n.run.rowsAffected == 0 {
+		if n.n.IfExists {
+			return nil
+		}
+		return pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"schedule %q does not exist", n.n.Name)
+	}
+	_, err = ie.Exec(
+		params.ctx, "drop-schedule-runs", params.p.txn,
+		`DELETE FROM system.schedule_runs WHERE schedule_name = $1`, name,
+	)
+	return err
+}
+
+// Next implements the planNode interface.
+func (*dropScheduleNode) Next(runParams) (bool, error) { return false, nil }
+
+// Values implements the planNode interface.
+func (*dropScheduleNode) Values() tree.Datums { return tree.Datums{} }
+
+// Close implements the planNode interface.
+func (*dropScheduleNode) Close(context.Context) {}
+
+// FastPathResults implements the planNodeFastPath interface.
+func (n *dropScheduleNode) FastPathResults() (int, bool) { return n.run.rowsAffected, true }
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 )
 
 type dropSequenceNode struct {
@@ -74,11 +75,13 @@ func (n *dropSequenceNode) startExec(params runParams) error {
 			EventLogDropSequence,
 			int32(droppedDesc.ID),
 			int32(params.extendedEvalCtx.NodeID),
-			struct {
-				SequenceName string
-				Statement    string
-				User         string
-			}{toDel.tn.FQString(), n.n.String(), params.SessionData().User},
+			&eventpb.DropSequence{
+				SequenceName: toDel.tn.FQString(),
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					Statement: n.n.String(),
+					User:      params.SessionData().User,
+				},
+			},
 		); err != nil {
 			return err
 		}
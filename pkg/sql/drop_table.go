@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 )
@@ -130,13 +131,14 @@ func (n *dropTableNode) startExec(params runParams) error {
 			EventLogDropTable,
 			int32(droppedDesc.ID),
 			int32(params.extendedEvalCtx.NodeID),
-			struct {
-				TableName           string
-				Statement           string
-				User                string
-				CascadeDroppedViews []string
-			}{toDel.tn.FQString(), n.n.String(),
-				params.SessionData().User, droppedViews},
+			&eventpb.DropTable{
+				TableName: toDel.tn.FQString(),
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					Statement: n.n.String(),
+					User:      params.SessionData().User,
+				},
+				CascadeDroppedViews: droppedViews,
+			},
 		); err != nil {
 			return err
 		}
@@ -193,6 +193,7 @@ func (n *DropUserNode) startExec(params runParams) error {
 			return errors.Errorf("%s %s does not exist", entryType, normalizedUsername)
 		}
 		numUsersDeleted += rowsAffected
+		userDeleted := rowsAffected > 0
 
 		// Drop all role memberships involving the user/role.
 		rowsAffected, err = params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
@@ -207,6 +208,25 @@ func (n *DropUserNode) startExec(params runParams) error {
 		}
 
 		numRoleMembershipsDeleted += rowsAffected
+
+		// Drop the password expiration and the failed login attempts.
+		if _, err := params.extendedEvalCtx.ExecCfg.InternalExecutor.Exec(
+			params.ctx,
+			"drop-user-auth-state",
+			params.p.txn,
+			`DELETE FROM system.user_auth_state WHERE username = $1`,
+			normalizedUsername,
+		); err != nil {
+			return err
+		}
+
+		if userDeleted {
+			LogRoleChange(params.ctx, params.extendedEvalCtx.ExecCfg, AuthLogDropRole, params.p.User(),
+				struct {
+					RoleName string
+					IsRole   bool
+				}{normalizedUsername, n.isRole})
+		}
 	}
 
 	if numRoleMembershipsDeleted > 0 {
@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/pkg/errors"
 )
 
@@ -95,12 +96,14 @@ func (n *dropViewNode) startExec(params runParams) error {
 			EventLogDropView,
 			int32(droppedDesc.ID),
 			int32(params.extendedEvalCtx.NodeID),
-			struct {
-				ViewName            string
-				Statement           string
-				User                string
-				CascadeDroppedViews []string
-			}{toDel.tn.FQString(), n.n.String(), params.SessionData().User, cascadeDroppedViews},
+			&eventpb.DropView{
+				ViewName: toDel.tn.FQString(),
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					Statement: n.n.String(),
+					User:      params.SessionData().User,
+				},
+				CascadeDroppedViews: cascadeDroppedViews,
+			},
 		); err != nil {
 			return err
 		}
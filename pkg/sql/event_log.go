@@ -18,22 +18,30 @@ package sql
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"reflect"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 )
 
 // EventLogType represents an event type that can be recorded in the event log.
 type EventLogType string
 
-// NOTE: When you add a new event type here. Please manually add it to
+// NOTE: When you add a new event type here, register the type of its payload
+// in the init function below. Please also manually add it to
 // pkg/ui/src/util/eventTypes.ts so that it will be recognized in the UI.
 const (
 	// EventLogCreateDatabase is recorded when a database is created.
 	EventLogCreateDatabase EventLogType = "create_database"
 	// EventLogDropDatabase is recorded when a database is dropped.
 	EventLogDropDatabase EventLogType = "drop_database"
+	// EventLogAlterDatabase is recorded when a database is altered.
+	EventLogAlterDatabase EventLogType = "alter_database"
 
 	// EventLogCreateTable is recorded when a table is created.
 	EventLogCreateTable EventLogType = "create_table"
@@ -69,6 +77,19 @@ const (
 	// EventLogAlterSequence is recorded when a sequence is altered.
 	EventLogAlterSequence EventLogType = "alter_sequence"
 
+	// EventLogCreateTrigger is recorded when a trigger is created.
+	EventLogCreateTrigger EventLogType = "create_trigger"
+	// EventLogDropTrigger is recorded when a trigger is dropped.
+	EventLogDropTrigger EventLogType = "drop_trigger"
+
+	// EventLogCreateProcedure is recorded when a procedure is created.
+	EventLogCreateProcedure EventLogType = "create_procedure"
+	// EventLogDropProcedure is recorded when a procedure is dropped.
+	EventLogDropProcedure EventLogType = "drop_procedure"
+	// EventLogCreateFunction is recorded when a user-defined function is
+	// created.
+	EventLogCreateFunction EventLogType = "create_function"
+
 	// EventLogReverseSchemaChange is recorded when an in-progress schema change
 	// encounters a problem and is reversed.
 	EventLogReverseSchemaChange EventLogType = "reverse_schema_change"
@@ -102,42 +123,164 @@ const (
 	// EventLogCreateStatistics is recorded when statistics are collected for a
 	// table.
 	EventLogCreateStatistics EventLogType = "create_statistics"
+
+	// EventLogUserLoginLocked is recorded when a user is prevented from
+	// logging in after too many failed login attempts.
+	EventLogUserLoginLocked EventLogType = "user_login_locked"
+
+	// EventLogUnsafeUpsertDescriptor is recorded when a descriptor is written
+	// with crdb_internal.unsafe_upsert_descriptor().
+	EventLogUnsafeUpsertDescriptor EventLogType = "unsafe_upsert_descriptor"
+	// EventLogUnsafeDeleteDescriptor is recorded when a descriptor is deleted
+	// with crdb_internal.unsafe_delete_descriptor().
+	EventLogUnsafeDeleteDescriptor EventLogType = "unsafe_delete_descriptor"
+	// EventLogUnsafeUpsertNamespaceEntry is recorded when a namespace entry is
+	// written with crdb_internal.unsafe_upsert_namespace_entry().
+	EventLogUnsafeUpsertNamespaceEntry EventLogType = "unsafe_upsert_namespace_entry"
+	// EventLogUnsafeDeleteNamespaceEntry is recorded when a namespace entry is
+	// deleted with crdb_internal.unsafe_delete_namespace_entry().
+	EventLogUnsafeDeleteNamespaceEntry EventLogType = "unsafe_delete_namespace_entry"
+
+	// EventLogChangeDatabasePrivilege is recorded when privileges are granted
+	// on or revoked from a database.
+	EventLogChangeDatabasePrivilege EventLogType = "change_database_privilege"
+	// EventLogChangeTablePrivilege is recorded when privileges are granted on
+	// or revoked from a table, a view or a sequence.
+	EventLogChangeTablePrivilege EventLogType = "change_table_privilege"
 )
 
-// EventLogSetClusterSettingDetail is the json details for a settings change.
-type EventLogSetClusterSettingDetail struct {
-	SettingName string
-	Value       string
-	User        string
+func init() {
+	RegisterEventType(EventLogCreateDatabase, &eventpb.CreateDatabase{})
+	RegisterEventType(EventLogDropDatabase, &eventpb.DropDatabase{})
+	RegisterEventType(EventLogAlterDatabase, &eventpb.AlterDatabase{})
+	RegisterEventType(EventLogCreateTable, &eventpb.CreateTable{})
+	RegisterEventType(EventLogDropTable, &eventpb.DropTable{})
+	RegisterEventType(EventLogTruncateTable, &eventpb.TruncateTable{})
+	RegisterEventType(EventLogAlterTable, &eventpb.AlterTable{})
+	RegisterEventType(EventLogCommentOnColumn, &eventpb.CommentOnColumn{})
+	RegisterEventType(EventLogCommentOnDatabase, &eventpb.CommentOnDatabase{})
+	RegisterEventType(EventLogCommentOnTable, &eventpb.CommentOnTable{})
+	RegisterEventType(EventLogCreateIndex, &eventpb.CreateIndex{})
+	RegisterEventType(EventLogDropIndex, &eventpb.DropIndex{})
+	RegisterEventType(EventLogAlterIndex, &eventpb.AlterIndex{})
+	RegisterEventType(EventLogCreateView, &eventpb.CreateView{})
+	RegisterEventType(EventLogDropView, &eventpb.DropView{})
+	RegisterEventType(EventLogCreateSequence, &eventpb.CreateSequence{})
+	RegisterEventType(EventLogDropSequence, &eventpb.DropSequence{})
+	RegisterEventType(EventLogAlterSequence, &eventpb.AlterSequence{})
+	RegisterEventType(EventLogCreateTrigger, &eventpb.CreateTrigger{})
+	RegisterEventType(EventLogDropTrigger, &eventpb.DropTrigger{})
+	RegisterEventType(EventLogCreateProcedure, &eventpb.CreateProcedure{})
+	RegisterEventType(EventLogDropProcedure, &eventpb.DropProcedure{})
+	RegisterEventType(EventLogCreateFunction, &eventpb.CreateFunction{})
+	RegisterEventType(EventLogReverseSchemaChange, &eventpb.ReverseSchemaChange{})
+	RegisterEventType(EventLogFinishSchemaChange, &eventpb.FinishSchemaChange{})
+	RegisterEventType(EventLogFinishSchemaRollback, &eventpb.FinishSchemaChangeRollback{})
+	RegisterEventType(EventLogNodeJoin, &eventpb.NodeJoin{})
+	RegisterEventType(EventLogNodeRestart, &eventpb.NodeRestart{})
+	RegisterEventType(EventLogNodeDecommissioned, &eventpb.NodeDecommissioned{})
+	RegisterEventType(EventLogNodeRecommissioned, &eventpb.NodeRecommissioned{})
+	RegisterEventType(EventLogSetClusterSetting, &eventpb.SetClusterSetting{})
+	RegisterEventType(EventLogSetZoneConfig, &eventpb.SetZoneConfig{})
+	RegisterEventType(EventLogRemoveZoneConfig, &eventpb.RemoveZoneConfig{})
+	RegisterEventType(EventLogCreateStatistics, &eventpb.CreateStatistics{})
+	RegisterEventType(EventLogUserLoginLocked, &eventpb.UserLoginLocked{})
+	RegisterEventType(EventLogUnsafeUpsertDescriptor, &eventpb.UnsafeRepair{})
+	RegisterEventType(EventLogUnsafeDeleteDescriptor, &eventpb.UnsafeRepair{})
+	RegisterEventType(EventLogUnsafeUpsertNamespaceEntry, &eventpb.UnsafeRepair{})
+	RegisterEventType(EventLogUnsafeDeleteNamespaceEntry, &eventpb.UnsafeRepair{})
+	RegisterEventType(EventLogChangeDatabasePrivilege, &eventpb.ChangeDatabasePrivilege{})
+	RegisterEventType(EventLogChangeTablePrivilege, &eventpb.ChangeTablePrivilege{})
+}
+
+// eventTypes maps the registered event types to an instance of the type of
+// their payload.
+var eventTypes = make(map[EventLogType]eventpb.EventPayload)
+
+// RegisterEventType registers an event type along with the type of its
+// payload. Only the registered event types can be recorded in the event
+// log. It must be called from an init function.
+func RegisterEventType(eventType EventLogType, payload eventpb.EventPayload) {
+	if _, ok := eventTypes[eventType]; ok {
+		panic(fmt.Sprintf("event type %q already registered", eventType))
+	}
+	eventTypes[eventType] = payload
 }
 
+// EventLogChannel returns the logging channel of the given event type, and
+// whether the event type is registered.
+func EventLogChannel(eventType EventLogType) (eventpb.Channel, bool) {
+	payload, ok := eventTypes[eventType]
+	if !ok {
+		return 0, false
+	}
+	return payload.LoggingChannel(), true
+}
+
+var exportEvents = settings.RegisterBoolSetting(
+	"server.eventlog.export.enabled",
+	"if set, the events recorded in the event log are also written to the log "+
+		"of their channel: the changes to the SQL schema to the SQL schema log, the "+
+		"privilege changes to the privileges log and the session events to the sessions log",
+	false,
+)
+
 // An EventLogger exposes methods used to record events to the event table.
 type EventLogger struct {
 	*InternalExecutor
+	execCfg *ExecutorConfig
 }
 
 // MakeEventLogger constructs a new EventLogger.
 func MakeEventLogger(execCfg *ExecutorConfig) EventLogger {
-	return EventLogger{InternalExecutor: execCfg.InternalExecutor}
+	return EventLogger{InternalExecutor: execCfg.InternalExecutor, execCfg: execCfg}
 }
 
 // InsertEventRecord inserts a single event into the event log as part of the
-// provided transaction.
+// provided transaction. The payload must have the type registered for the
+// event type.
+//
+// Once the transaction commits, the event is also written to the main log
+// and, if server.eventlog.export.enabled is set, to the log of its channel.
 func (ev EventLogger) InsertEventRecord(
 	ctx context.Context,
 	txn *client.Txn,
 	eventType EventLogType,
 	targetID, reportingID int32,
-	info interface{},
+	info eventpb.EventPayload,
 ) error {
+	registered, ok := eventTypes[eventType]
+	if !ok {
+		return errors.Errorf("unregistered event type %q", eventType)
+	}
+	if reflect.TypeOf(info) != reflect.TypeOf(registered) {
+		return errors.Errorf("invalid payload %T for event type %q", info, eventType)
+	}
+
+	// The common details have their own columns in system.eventlog, so they
+	// are only filled in once the info column is encoded.
+	common := info.CommonDetails()
+	*common = eventpb.CommonEventDetails{}
+	infoBytes, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	common.Timestamp = timeutil.Now().UnixNano()
+	common.EventType = string(eventType)
+	exportedBytes, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
 	// Record event record insertion in local log output.
 	txn.AddCommitTrigger(func(ctx context.Context) {
 		log.Infof(
-			ctx, "Event: %q, target: %d, info: %+v",
+			ctx, "Event: %q, target: %d, info: %s",
 			eventType,
 			targetID,
-			info,
+			infoBytes,
 		)
+		ev.exportEvent(ctx, info.LoggingChannel(), eventType, exportedBytes)
 	})
 
 	const insertEventTableStmt = `
@@ -148,21 +291,8 @@ VALUES(
   now(), $1, $2, $3, $4
 )
 `
-	args := []interface{}{
-		eventType,
-		targetID,
-		reportingID,
-		nil, // info
-	}
-	if info != nil {
-		infoBytes, err := json.Marshal(info)
-		if err != nil {
-			return err
-		}
-		args[3] = string(infoBytes)
-	}
-
-	rows, err := ev.Exec(ctx, "log-event", txn, insertEventTableStmt, args...)
+	rows, err := ev.Exec(ctx, "log-event", txn, insertEventTableStmt,
+		eventType, targetID, reportingID, string(infoBytes))
 	if err != nil {
 		return err
 	}
@@ -171,3 +301,28 @@ VALUES(
 	}
 	return nil
 }
+
+// exportEvent writes an event to the log of its channel, if
+// server.eventlog.export.enabled is set. The entries have the same format
+// as the entries of the sessions and privileges logs.
+func (ev EventLogger) exportEvent(
+	ctx context.Context, channel eventpb.Channel, eventType EventLogType, payload []byte,
+) {
+	if ev.execCfg == nil || !exportEvents.Get(&ev.execCfg.Settings.SV) {
+		return
+	}
+	var logger *log.SecondaryLogger
+	switch channel {
+	case eventpb.ChannelSQLSchema:
+		logger = ev.execCfg.SQLSchemaLogger
+	case eventpb.ChannelPrivileges:
+		logger = ev.execCfg.PrivilegesLogger
+	case eventpb.ChannelSessions:
+		logger = ev.execCfg.SessionsLogger
+	}
+	if logger == nil {
+		// The OPS events are only written to the main log.
+		return
+	}
+	logger.Logf(ctx, "%s %s", eventType, payload)
+}
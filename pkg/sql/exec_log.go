@@ -21,7 +21,9 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -72,11 +74,12 @@ import (
 //      events. This is needed for auditing.
 //  - the full text of the query.
 //    - audit logs really ought to only "identify the data that's accessed but
-//      without revealing PII". We don't know how to separate those two things
-//      yet so the audit log contains the full SQL of the query even though
-//      this may yield PII. This may need to be addressed later.
+//      without revealing PII". By default, the audit log contains the full SQL
+//      of the query even though this may yield PII; the literals can be
+//      redacted with the sql.log.redaction_mode cluster setting.
 //  - the placeholder values. Useful for queries using placehodlers.
 //    - "{}" when there are no placeholders.
+//    - redacted like the literals of the query.
 //  - the query execution time in milliseconds. For troubleshooting.
 //  - the number of rows that were produced. For troubleshooting.
 //  - the status of the query (OK for success, ERROR or full error
@@ -90,6 +93,26 @@ var logStatementsExecuteEnabled = settings.RegisterBoolSetting(
 	false,
 )
 
+const (
+	logRedactionOff = iota
+	logRedactionRedact
+	logRedactionHash
+)
+
+// logRedactionMode determines whether the literals of the statements written
+// to the exec and audit logs are redacted. See parser.Redact.
+var logRedactionMode = settings.RegisterEnumSetting(
+	"sql.log.redaction_mode",
+	"determines how the literals of the statements written to the execution and audit logs "+
+		"are redacted (off: not redacted; redact: replaced with _; hash: replaced with a hash of their value)",
+	"off",
+	map[int64]string{
+		logRedactionOff:    "off",
+		logRedactionRedact: "redact",
+		logRedactionHash:   "hash",
+	},
+)
+
 // maybeLogStatement conditionally records the current statement
 // (p.curPlan) to the exec / audit logs.
 func (p *planner) maybeLogStatement(ctx context.Context, lbl string, rows int, err error) {
@@ -136,9 +159,18 @@ func (p *planner) maybeLogStatementInternal(
 		logTrigger = buf.String()
 	}
 
-	stmtStr := p.curPlan.AST.String()
-
-	plStr := p.extendedEvalCtx.Placeholders.Values.String()
+	var stmtStr, plStr string
+	switch logRedactionMode.Get(&p.execCfg.Settings.SV) {
+	case logRedactionRedact:
+		stmtStr = parser.Redact(p.curPlan.AST, parser.RedactLiterals)
+		plStr = p.extendedEvalCtx.Placeholders.Values.StringWithFlags(tree.FmtRedactConstants)
+	case logRedactionHash:
+		stmtStr = parser.Redact(p.curPlan.AST, parser.HashLiterals)
+		plStr = p.extendedEvalCtx.Placeholders.Values.StringWithFlags(tree.FmtHashConstants)
+	default:
+		stmtStr = p.curPlan.AST.String()
+		plStr = p.extendedEvalCtx.Placeholders.Values.String()
+	}
 
 	age := float64(timeutil.Now().Sub(startTime).Nanoseconds()) / 1e6
 
@@ -57,6 +57,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -375,27 +376,37 @@ type nodeStatusGenerator interface {
 type ExecutorConfig struct {
 	Settings *cluster.Settings
 	NodeInfo
-	Locality         roachpb.Locality
-	AmbientCtx       log.AmbientContext
-	DB               *client.DB
-	Gossip           *gossip.Gossip
-	DistSender       *kv.DistSender
-	RPCContext       *rpc.Context
-	LeaseManager     *LeaseManager
-	Clock            *hlc.Clock
-	DistSQLSrv       *distsqlrun.ServerImpl
-	StatusServer     serverpb.StatusServer
-	MetricsRecorder  nodeStatusGenerator
-	SessionRegistry  *SessionRegistry
-	JobRegistry      *jobs.Registry
-	VirtualSchemas   *VirtualSchemaHolder
-	DistSQLPlanner   *DistSQLPlanner
-	TableStatsCache  *stats.TableStatisticsCache
-	StatsRefresher   *stats.Refresher
-	ExecLogger       *log.SecondaryLogger
-	AuditLogger      *log.SecondaryLogger
-	InternalExecutor *InternalExecutor
-	QueryCache       *querycache.C
+	Locality          roachpb.Locality
+	AmbientCtx        log.AmbientContext
+	DB                *client.DB
+	Gossip            *gossip.Gossip
+	DistSender        *kv.DistSender
+	RPCContext        *rpc.Context
+	LeaseManager      *LeaseManager
+	Clock             *hlc.Clock
+	DistSQLSrv        *distsqlrun.ServerImpl
+	StatusServer      serverpb.StatusServer
+	MetricsRecorder   nodeStatusGenerator
+	SessionRegistry   *SessionRegistry
+	JobRegistry       *jobs.Registry
+	VirtualSchemas    *VirtualSchemaHolder
+	DistSQLPlanner    *DistSQLPlanner
+	TableStatsCache   *stats.TableStatisticsCache
+	StatsRefresher    *stats.Refresher
+	ExecLogger        *log.SecondaryLogger
+	AuditLogger       *log.SecondaryLogger
+	SessionsLogger    *log.SecondaryLogger
+	PrivilegesLogger  *log.SecondaryLogger
+	SQLSchemaLogger   *log.SecondaryLogger
+	InternalExecutor  *InternalExecutor
+	QueryCache        *querycache.C
+	PinnedPlans       *PinnedPlanCache
+	HistoricalResults *HistoricalResultCache
+
+	// RootMemoryMonitor is the root SQL memory monitor, which is only used to
+	// report the tree of monitors in crdb_internal.node_memory_monitors. It
+	// can be nil.
+	RootMemoryMonitor *mon.BytesMonitor
 
 	TestingKnobs              ExecutorTestingKnobs
 	PGWireTestingKnobs        *PGWireTestingKnobs
@@ -1740,6 +1751,10 @@ func (m *sessionDataMutator) SetSafeUpdates(val bool) {
 	m.data.SafeUpdates = val
 }
 
+func (m *sessionDataMutator) SetEnforceGatewayRegion(val bool) {
+	m.data.EnforceGatewayRegion = val
+}
+
 func (m *sessionDataMutator) SetSearchPath(val sessiondata.SearchPath) {
 	m.data.SearchPath = val
 }
@@ -1756,6 +1771,10 @@ func (m *sessionDataMutator) SetStmtTimeout(timeout time.Duration) {
 	m.data.StmtTimeout = timeout
 }
 
+func (m *sessionDataMutator) SetTraceParent(val string) {
+	m.data.TraceParent = val
+}
+
 func (m *sessionDataMutator) SetAllowPrepareAsOptPlan(val bool) {
 	m.data.AllowPrepareAsOptPlan = val
 }
@@ -1811,10 +1830,11 @@ func (s *sqlStatsCollectorImpl) RecordStatement(
 	numRows int,
 	err error,
 	parseLat, planLat, runLat, svcLat, ovhLat float64,
+	stats topLevelQueryStats,
 ) {
 	s.appStats.recordStatement(
 		stmt, samplePlanDescription, distSQLUsed, optUsed, automaticRetryCount, numRows, err,
-		parseLat, planLat, runLat, svcLat, ovhLat)
+		parseLat, planLat, runLat, svcLat, ovhLat, stats)
 }
 
 // SQLStats is part of the sqlStatsCollector interface.
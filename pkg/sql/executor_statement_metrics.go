@@ -16,12 +16,16 @@ package sql
 
 import (
 	"context"
+	"runtime"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/sysutil"
 )
 
 // SQL execution is separated in 3+ phases:
@@ -95,6 +99,69 @@ var _ metric.Struct = EngineMetrics{}
 // MetricStruct is part of the metric.Struct interface.
 func (EngineMetrics) MetricStruct() {}
 
+// topLevelQueryStats collects the resources consumed by the execution of
+// a statement.
+type topLevelQueryStats struct {
+	// bytesRead is the number of bytes read from KV by the processors of
+	// the statement, on all the nodes.
+	bytesRead int64
+	// bytesWritten is the number of bytes of the mutations sent to KV by
+	// the statement's transaction on the gateway.
+	bytesWritten int64
+	// cpuTime is the CPU time consumed on the gateway by the goroutine
+	// running the statement. It is only set if cpuTimeMeasured is set.
+	cpuTime         time.Duration
+	cpuTimeMeasured bool
+}
+
+// stmtResourceTracker measures the CPU time and the KV writes of a
+// statement on the gateway. The CPU time is only measured when this is
+// enabled by the cluster setting and supported by the platform; for this
+// purpose the goroutine is pinned to its OS thread between start() and
+// stop(), which must thus be called from the same goroutine.
+type stmtResourceTracker struct {
+	running bool
+	txn     *client.Txn
+	// bytesWrittenStart is the value of txn.BytesWritten() at start().
+	bytesWrittenStart int64
+	// cpuStart is the CPU time of the thread at start(). It is only
+	// valid if measureCPU is set.
+	cpuStart   time.Duration
+	measureCPU bool
+}
+
+// start begins the measurement. txn, if non-nil, is the transaction
+// whose writes are attributed to the statement.
+func (t *stmtResourceTracker) start(st *cluster.Settings, txn *client.Txn) {
+	*t = stmtResourceTracker{running: true, txn: txn}
+	if txn != nil {
+		t.bytesWrittenStart = txn.BytesWritten()
+	}
+	if sysutil.ThreadCPUTimeSupported && stmtCPUTimeEnable.Get(&st.SV) {
+		runtime.LockOSThread()
+		t.measureCPU = true
+		t.cpuStart = sysutil.ThreadCPUTime()
+	}
+}
+
+// stop ends the measurement and saves the resources consumed since
+// start() into stats. It is a no-op if the measurement is not running,
+// so that it can also be deferred as a catch-all.
+func (t *stmtResourceTracker) stop(stats *topLevelQueryStats) {
+	if !t.running {
+		return
+	}
+	t.running = false
+	if t.txn != nil {
+		stats.bytesWritten = t.txn.BytesWritten() - t.bytesWrittenStart
+	}
+	if t.measureCPU {
+		stats.cpuTime = sysutil.ThreadCPUTime() - t.cpuStart
+		stats.cpuTimeMeasured = true
+		runtime.UnlockOSThread()
+	}
+}
+
 func (ex *connExecutor) maybeSavePlan(
 	ctx context.Context, p *planner,
 ) *roachpb.ExplainTreePlanNode {
@@ -165,12 +232,14 @@ func (ex *connExecutor) recordStatementSummary(
 	planner.curPlan.execErr = err
 	planner.curPlan.close(ctx)
 
+	stats := planner.curPlan.stats
 	planner.statsCollector.RecordStatement(
 		stmt, planner.curPlan.savedPlanForStats,
 		flags.IsSet(planFlagDistributed), flags.IsSet(planFlagOptUsed),
 		automaticRetryCount, rowsAffected, err,
-		parseLat, planLat, runLat, svcLat, execOverhead,
+		parseLat, planLat, runLat, svcLat, execOverhead, stats,
 	)
+	planner.statsCollector.SQLStats().getStatsForRole(planner.User()).record(stats)
 
 	if log.V(2) {
 		// ages since significant epochs
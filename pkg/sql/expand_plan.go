@@ -195,6 +195,9 @@ func doExpandPlan(
 		}
 		n.left, err = doExpandPlan(ctx, p, params, n.left)
 
+	case *recursiveCTENode:
+		n.initial, err = doExpandPlan(ctx, p, noParams, n.initial)
+
 	case *filterNode:
 		plan, err = expandFilterNode(ctx, p, params, n)
 
@@ -369,12 +372,21 @@ func doExpandPlan(
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *callNode:
+	case *createFunctionNode:
+	case *createProcedureNode:
+	case *createTriggerNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *dropProcedureNode:
+	case *dropTriggerNode:
+	case *dropScheduleNode:
+	case *controlScheduleNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -733,6 +745,9 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 		n.right = p.simplifyOrderings(n.right, nil)
 		n.left = p.simplifyOrderings(n.left, nil)
 
+	case *recursiveCTENode:
+		n.initial = p.simplifyOrderings(n.initial, nil)
+
 	case *filterNode:
 		n.source.plan = p.simplifyOrderings(n.source.plan, usefulOrdering)
 		n.computePhysicalProps(p.EvalContext())
@@ -883,12 +898,21 @@ func (p *planner) simplifyOrderings(plan planNode, usefulOrdering sqlbase.Column
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *callNode:
+	case *createFunctionNode:
+	case *createProcedureNode:
+	case *createTriggerNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *dropProcedureNode:
+	case *dropTriggerNode:
+	case *dropScheduleNode:
+	case *controlScheduleNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -69,6 +69,9 @@ func (p *planner) Explain(ctx context.Context, n *tree.Explain) (planNode, error
 	case tree.ExplainOpt:
 		return nil, errors.New("EXPLAIN (OPT) only supported with the cost-based optimizer")
 
+	case tree.ExplainGist:
+		return nil, errors.New("EXPLAIN (GIST) only supported with the cost-based optimizer")
+
 	default:
 		return nil, fmt.Errorf("unsupported EXPLAIN mode: %d", opts.Mode)
 	}
@@ -0,0 +1,206 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// gatewayRegionTables collects the tables accessed by a plan, for the
+// purpose of checking that they are all constrained to the region of the
+// gateway.
+type gatewayRegionTables struct {
+	ids   []sqlbase.ID
+	descs map[sqlbase.ID]*sqlbase.TableDescriptor
+}
+
+// add records a table. Virtual tables are not stored in the KV layer and
+// are ignored.
+func (t *gatewayRegionTables) add(desc *sqlbase.TableDescriptor) {
+	if _, ok := t.descs[desc.ID]; ok || desc.IsVirtualTable() {
+		return
+	}
+	t.ids = append(t.ids, desc.ID)
+	t.descs[desc.ID] = desc
+}
+
+// addByID records the table with the given ID.
+func (t *gatewayRegionTables) addByID(
+	ctx context.Context, p *planner, id sqlbase.ID,
+) (*sqlbase.TableDescriptor, error) {
+	if desc, ok := t.descs[id]; ok {
+		return desc, nil
+	}
+	desc, err := sqlbase.GetTableDescFromID(ctx, p.txn, id)
+	if err != nil {
+		return nil, err
+	}
+	t.add(desc)
+	return desc, nil
+}
+
+// addMutated records a table written by a statement, along with the tables
+// accessed by the foreign key checks and cascades of the writes: the tables
+// referenced by the table, and, transitively, the tables referencing it.
+// The latter are recorded whether or not their references cascade, so the
+// recorded tables are a superset of the accessed ones.
+func (t *gatewayRegionTables) addMutated(
+	ctx context.Context, p *planner, desc *sqlbase.TableDescriptor,
+) error {
+	t.add(desc)
+	for _, idx := range desc.AllNonDropIndexes() {
+		if idx.ForeignKey.IsSet() {
+			if _, err := t.addByID(ctx, p, idx.ForeignKey.Table); err != nil {
+				return err
+			}
+		}
+	}
+	visited := map[sqlbase.ID]bool{desc.ID: true}
+	for queue := []*sqlbase.TableDescriptor{desc}; len(queue) > 0; queue = queue[1:] {
+		for _, idx := range queue[0].AllNonDropIndexes() {
+			for _, ref := range idx.ReferencedBy {
+				if visited[ref.Table] {
+					continue
+				}
+				visited[ref.Table] = true
+				refDesc, err := t.addByID(ctx, p, ref.Table)
+				if err != nil {
+					return err
+				}
+				queue = append(queue, refDesc)
+			}
+		}
+	}
+	return nil
+}
+
+// checkGatewayRegion implements the enforce_gateway_region session
+// variable: it returns an error if the current plan accesses a table whose
+// replicas are not all constrained to the region of the gateway, according
+// to the zone configs of the table and of its indexes and partitions.
+//
+// Queries which would need to leave the region of the gateway are rejected
+// before their execution starts, rather than redirected.
+func (p *planner) checkGatewayRegion(ctx context.Context) error {
+	region, ok := p.EvalContext().Locality.Find("region")
+	if !ok {
+		return pgerror.NewError(pgerror.CodeObjectNotInPrerequisiteStateError,
+			"enforce_gateway_region is set, but no region is set on the locality flag on this node")
+	}
+
+	tables := gatewayRegionTables{descs: make(map[sqlbase.ID]*sqlbase.TableDescriptor)}
+	observer := planObserver{
+		enterNode: func(ctx context.Context, _ string, plan planNode) (bool, error) {
+			switch n := plan.(type) {
+			case *scanNode:
+				tables.add(n.desc.TableDesc())
+			case *lookupJoinNode:
+				tables.add(n.table.desc.TableDesc())
+			case *insertNode:
+				return true, tables.addMutated(ctx, p, n.run.ti.tableDesc().TableDesc())
+			case *updateNode:
+				return true, tables.addMutated(ctx, p, n.run.tu.tableDesc().TableDesc())
+			case *deleteNode:
+				return true, tables.addMutated(ctx, p, n.run.td.tableDesc().TableDesc())
+			case *upsertNode:
+				return true, tables.addMutated(ctx, p, n.run.tw.tableDesc().TableDesc())
+			case *deleteRangeNode:
+				return true, tables.addMutated(ctx, p, n.desc.TableDesc())
+			}
+			return true, nil
+		},
+	}
+	for i := range p.curPlan.subqueryPlans {
+		if err := walkPlan(ctx, p.curPlan.subqueryPlans[i].plan, observer); err != nil {
+			return err
+		}
+	}
+	if err := walkPlan(ctx, p.curPlan.plan, observer); err != nil {
+		return err
+	}
+
+	getKey := func(key roachpb.Key) (*roachpb.Value, error) {
+		kv, err := p.txn.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return kv.Value, nil
+	}
+	for _, id := range tables.ids {
+		zoneID, zone, _, placeholder, err := getZoneConfig(uint32(id), getKey, false /* getInheritedDefault */)
+		if err != nil {
+			return err
+		}
+		if err := completeZoneConfig(zone, zoneID, getKey); err != nil {
+			return err
+		}
+		ok := constrainedToRegions(zone, *zone.NumReplicas, region)
+		subzones := zone.Subzones
+		if placeholder != nil {
+			subzones = placeholder.Subzones
+		}
+		for i := range subzones {
+			if cfg := &subzones[i].Config; ok && !cfg.InheritedConstraints {
+				ok = constrainedToRegions(cfg, *zone.NumReplicas, region)
+			}
+		}
+		if !ok {
+			return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError,
+				"query accesses table %s, whose replicas are not all constrained to the gateway region %s",
+				tables.descs[id].Name, region,
+			).SetHintf("constrain the replicas of the table with ALTER TABLE ... CONFIGURE ZONE "+
+				"USING constraints = '[+region=%s]', or SET enforce_gateway_region = off", region)
+		}
+	}
+	return nil
+}
+
+// constrainedToRegions returns whether the constraints of the zone config
+// require all the replicas to be in the given regions, given the total number
+// of replicas.
+func constrainedToRegions(zone *config.ZoneConfig, numReplicas int32, regions ...string) bool {
+	requiresRegion := func(c config.Constraints) bool {
+		for _, constraint := range c.Constraints {
+			if constraint.Type != config.Constraint_REQUIRED || constraint.Key != "region" {
+				continue
+			}
+			for _, region := range regions {
+				if constraint.Value == region {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	// The constraints either apply to all the replicas, or each of them
+	// applies to a number of replicas. In the latter case, the replicas
+	// which are not covered by any constraint can be in any region.
+	var constrained int32
+	for _, c := range zone.Constraints {
+		if !requiresRegion(c) {
+			continue
+		}
+		if c.NumReplicas == 0 {
+			return true
+		}
+		constrained += c.NumReplicas
+	}
+	return constrained > 0 && constrained >= numReplicas
+}
@@ -17,9 +17,11 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/pkg/errors"
 )
 
@@ -33,9 +35,9 @@ import (
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
 func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
-	return p.changePrivileges(ctx, n.Targets, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
+	return p.changePrivileges(ctx, n, n.Targets, n.Columns, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
 		privDesc.Grant(grantee, n.Privileges)
-	})
+	}, false /* revokeFromColumns */, AuthLogGrantPrivileges, n.Privileges)
 }
 
 // Revoke removes privileges from users.
@@ -47,18 +49,39 @@ func (p *planner) Grant(ctx context.Context, n *tree.Grant) (planNode, error) {
 // Privileges: GRANT on database/table/view.
 //   Notes: postgres requires the object owner.
 //          mysql requires the "grant option" and the same privileges, and sometimes superuser.
+//
+// As in Postgres, revoking a privilege on a table also revokes it on each of
+// the table's columns.
 func (p *planner) Revoke(ctx context.Context, n *tree.Revoke) (planNode, error) {
-	return p.changePrivileges(ctx, n.Targets, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
+	return p.changePrivileges(ctx, n, n.Targets, n.Columns, n.Grantees, func(privDesc *sqlbase.PrivilegeDescriptor, grantee string) {
 		privDesc.Revoke(grantee, n.Privileges)
-	})
+	}, true /* revokeFromColumns */, AuthLogRevokePrivileges, n.Privileges)
 }
 
 func (p *planner) changePrivileges(
 	ctx context.Context,
+	stmt tree.Statement,
 	targets tree.TargetList,
+	columns tree.NameList,
 	grantees tree.NameList,
 	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
+	revokeFromColumns bool,
+	eventType AuthLogEventType,
+	privileges privilege.List,
 ) (planNode, error) {
+	if columns != nil {
+		if targets.Databases != nil {
+			return nil, pgerror.NewError(pgerror.CodeInvalidGrantOperationError,
+				"column privileges can only be granted on tables")
+		}
+		for _, priv := range privileges {
+			if priv != privilege.ALL && priv != privilege.SELECT {
+				return nil, pgerror.NewErrorf(pgerror.CodeInvalidGrantOperationError,
+					"invalid privilege type %s for column", priv)
+			}
+		}
+	}
+
 	// Check whether grantees exists
 	users, err := p.GetAllUsersAndRoles(ctx)
 	if err != nil {
@@ -92,15 +115,36 @@ func (p *planner) changePrivileges(
 		if err := p.CheckPrivilege(ctx, descriptor, privilege.GRANT); err != nil {
 			return nil, err
 		}
-		privileges := descriptor.GetPrivileges()
-		for _, grantee := range grantees {
-			changePrivilege(privileges, string(grantee))
-		}
+		if columns != nil {
+			if err := changeColumnPrivileges(descriptor, columns, grantees, changePrivilege); err != nil {
+				return nil, err
+			}
+		} else {
+			privileges := descriptor.GetPrivileges()
+			for _, grantee := range grantees {
+				changePrivilege(privileges, string(grantee))
+			}
 
-		// Validate privilege descriptors directly as the db/table level Validate
-		// may fix up the descriptor.
-		if err := privileges.Validate(descriptor.GetID()); err != nil {
-			return nil, err
+			// Validate privilege descriptors directly as the db/table level Validate
+			// may fix up the descriptor.
+			if err := privileges.Validate(descriptor.GetID()); err != nil {
+				return nil, err
+			}
+
+			if d, ok := descriptor.(*sqlbase.MutableTableDescriptor); ok && revokeFromColumns {
+				for i := range d.Columns {
+					col := &d.Columns[i]
+					if col.Privileges == nil {
+						continue
+					}
+					for _, grantee := range grantees {
+						changePrivilege(col.Privileges, string(grantee))
+					}
+					if len(col.Privileges.Users) == 0 {
+						col.Privileges = nil
+					}
+				}
+			}
 		}
 
 		switch d := descriptor.(type) {
@@ -125,5 +169,94 @@ func (p *planner) changePrivileges(
 	if err := p.txn.Run(ctx, b); err != nil {
 		return nil, err
 	}
+
+	// Record the changes in the event log, one event per descriptor, as part
+	// of the transaction updating the descriptors.
+	var granted, revoked []string
+	if eventType == AuthLogRevokePrivileges {
+		revoked = privileges.SortedNames()
+	} else {
+		granted = privileges.SortedNames()
+	}
+	sqlDetails := eventpb.CommonSQLEventDetails{Statement: tree.AsString(stmt), User: p.User()}
+	eventLogger := MakeEventLogger(p.ExecCfg())
+	for _, descriptor := range descriptors {
+		var logType EventLogType
+		var event eventpb.EventPayload
+		switch d := descriptor.(type) {
+		case *sqlbase.DatabaseDescriptor:
+			logType = EventLogChangeDatabasePrivilege
+			event = &eventpb.ChangeDatabasePrivilege{
+				DatabaseName:          d.Name,
+				CommonSQLEventDetails: sqlDetails,
+				Grantees:              grantees.ToStrings(),
+				GrantedPrivileges:     granted,
+				RevokedPrivileges:     revoked,
+			}
+		case *sqlbase.MutableTableDescriptor:
+			logType = EventLogChangeTablePrivilege
+			event = &eventpb.ChangeTablePrivilege{
+				TableName:             d.Name,
+				CommonSQLEventDetails: sqlDetails,
+				Columns:               columns.ToStrings(),
+				Grantees:              grantees.ToStrings(),
+				GrantedPrivileges:     granted,
+				RevokedPrivileges:     revoked,
+			}
+		default:
+			continue
+		}
+		if err := eventLogger.InsertEventRecord(
+			ctx, p.txn, logType, int32(descriptor.GetID()), int32(p.extendedEvalCtx.NodeID), event,
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	p.logPrivilegeChange(ctx, eventType, struct {
+		Targets    string
+		Columns    []string
+		Grantees   []string
+		Privileges string
+	}{tree.AsString(&targets), columns.ToStrings(), grantees.ToStrings(), privileges.String()})
 	return newZeroNode(nil /* columns */), nil
 }
+
+// changeColumnPrivileges applies changePrivilege to the privileges of the
+// given columns of a table, for each of the grantees.
+func changeColumnPrivileges(
+	descriptor sqlbase.DescriptorProto,
+	columns tree.NameList,
+	grantees tree.NameList,
+	changePrivilege func(*sqlbase.PrivilegeDescriptor, string),
+) error {
+	d, ok := descriptor.(*sqlbase.MutableTableDescriptor)
+	if !ok || !d.IsTable() {
+		return pgerror.NewErrorf(pgerror.CodeInvalidGrantOperationError,
+			"column privileges can only be granted on tables, not on %s %s",
+			descriptor.TypeName(), descriptor.GetName())
+	}
+	for _, name := range columns {
+		col, err := d.FindActiveColumnByName(string(name))
+		if err != nil {
+			return err
+		}
+		if col.Privileges == nil {
+			col.Privileges = &sqlbase.PrivilegeDescriptor{}
+		}
+		for _, grantee := range grantees {
+			changePrivilege(col.Privileges, string(grantee))
+		}
+		// SELECT is the only column privilege, so ALL is stored as SELECT.
+		for i := range col.Privileges.Users {
+			u := &col.Privileges.Users[i]
+			if u.Privileges&privilege.ALL.Mask() != 0 {
+				u.Privileges = privilege.SELECT.Mask()
+			}
+		}
+		if len(col.Privileges.Users) == 0 {
+			col.Privileges = nil
+		}
+	}
+	return nil
+}
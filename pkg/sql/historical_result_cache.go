@@ -0,0 +1,314 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+var historicalResultCacheEnabled = settings.RegisterBoolSetting(
+	"sql.historical_result_cache.enabled",
+	"if set, the results of SELECT statements run AS OF SYSTEM TIME are cached on "+
+		"the gateway node and reused by identical statements at the same timestamp",
+	false,
+)
+
+var historicalResultCacheSize = settings.RegisterByteSizeSetting(
+	"sql.historical_result_cache.max_size",
+	"the maximum memory used by the results cached on each node for statements "+
+		"run AS OF SYSTEM TIME; results which use more than a sixteenth of it are not cached",
+	64<<20, /* 64 MiB */
+)
+
+// HistoricalResultCache is a node-local LRU cache of the results of SELECT
+// statements run AS OF SYSTEM TIME at a fixed timestamp. The data read at
+// a historical timestamp never changes, so dashboards repeatedly running
+// the same historical queries (e.g. with follower reads) can be served
+// from the cache.
+//
+// The cache is keyed on the canonical text of the statement, the values of
+// its placeholders, the timestamp and the session variables which affect
+// name resolution and evaluation. The canonical text retains the constants
+// which are stripped from the statement's fingerprint. Statements are
+// still planned when their result is cached, so privileges and name
+// resolution are checked as usual; only execution is skipped.
+//
+// A nil HistoricalResultCache caches nothing.
+type HistoricalResultCache struct {
+	st *cluster.Settings
+
+	mu struct {
+		syncutil.Mutex
+		cache *cache.UnorderedCache
+		// size is the memory used by the cached results.
+		size int64
+	}
+}
+
+// historicalResultKey is the key of a result in a HistoricalResultCache.
+type historicalResultKey struct {
+	stmt         string
+	placeholders string
+	ts           hlc.Timestamp
+	user         string
+	database     string
+	searchPath   string
+	location     string
+}
+
+func (k *historicalResultKey) memoryEstimate() int64 {
+	return int64(len(k.stmt) + len(k.placeholders) + len(k.user) + len(k.database) +
+		len(k.searchPath) + len(k.location))
+}
+
+// historicalResult is a result cached in a HistoricalResultCache.
+type historicalResult struct {
+	rows []tree.Datums
+	// size is the estimated memory used by the rows.
+	size int64
+}
+
+// NewHistoricalResultCache creates an empty HistoricalResultCache.
+func NewHistoricalResultCache(st *cluster.Settings) *HistoricalResultCache {
+	c := &HistoricalResultCache{st: st}
+	c.mu.cache = cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(_ int, _, _ interface{}) bool {
+			return c.mu.size > historicalResultCacheSize.Get(&st.SV)
+		},
+		OnEvicted: func(key, value interface{}) {
+			k := key.(historicalResultKey)
+			c.mu.size -= k.memoryEstimate() + value.(*historicalResult).size
+		},
+	})
+	return c
+}
+
+// maxResultSize returns the size above which results are not cached, or 0
+// if the cache is disabled.
+func (c *HistoricalResultCache) maxResultSize() int64 {
+	if c == nil || !historicalResultCacheEnabled.Get(&c.st.SV) {
+		return 0
+	}
+	return historicalResultCacheSize.Get(&c.st.SV) / 16
+}
+
+// lookup returns the result cached for the given key, if any.
+func (c *HistoricalResultCache) lookup(key historicalResultKey) (*historicalResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.mu.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return v.(*historicalResult), true
+}
+
+// add caches the result for the given key.
+func (c *HistoricalResultCache) add(key historicalResultKey, res *historicalResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.cache.Del(key)
+	c.mu.size += key.memoryEstimate() + res.size
+	c.mu.cache.Add(key, res)
+}
+
+// Len returns the number of cached results.
+func (c *HistoricalResultCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mu.cache.Len()
+}
+
+// historicalResultCacheKey returns the key under which the result of the
+// statement being executed by the planner is cached, and false if the result
+// can't be cached. It must be called once the statement has been planned.
+func (ex *connExecutor) historicalResultCacheKey(
+	ctx context.Context, p *planner,
+) (historicalResultKey, bool) {
+	if p.semaCtx.AsOfTimestamp == nil || !ex.implicitTxn() {
+		return historicalResultKey{}, false
+	}
+	if _, ok := p.stmt.AST.(*tree.Select); !ok {
+		return historicalResultKey{}, false
+	}
+	if !isHistoricalPlanCacheable(ctx, &p.curPlan) {
+		return historicalResultKey{}, false
+	}
+	sd := ex.sessionData
+	return historicalResultKey{
+		stmt:         p.stmt.AST.String(),
+		placeholders: p.semaCtx.Placeholders.Values.StringWithFlags(tree.FmtParsable),
+		ts:           *p.semaCtx.AsOfTimestamp,
+		user:         sd.User,
+		database:     sd.Database,
+		searchPath:   sd.SearchPath.String(),
+		location:     sd.DataConversion.Location.String(),
+	}, true
+}
+
+// isHistoricalPlanCacheable returns whether the result of the plan is
+// guaranteed to be the same at every execution at the same historical
+// timestamp. This isn't the case for plans which read virtual tables,
+// which don't support AS OF SYSTEM TIME, or which use impure functions or
+// functions which depend on the state of the session.
+func isHistoricalPlanCacheable(ctx context.Context, plan *planTop) bool {
+	cacheable := true
+	v := impureFuncVisitor{}
+	observer := planObserver{
+		enterNode: func(_ context.Context, _ string, plan planNode) (bool, error) {
+			if _, ok := plan.(*delayedNode); ok {
+				cacheable = false
+			}
+			return cacheable, nil
+		},
+		expr: func(_ observeVerbosity, _, _ string, _ int, expr tree.Expr) {
+			if cacheable && expr != nil {
+				tree.WalkExprConst(&v, expr)
+				cacheable = !v.found
+			}
+		},
+	}
+	if err := walkPlan(ctx, plan.plan, observer); err != nil || !cacheable {
+		return false
+	}
+	for i := range plan.subqueryPlans {
+		if err := walkPlan(ctx, plan.subqueryPlans[i].plan, observer); err != nil || !cacheable {
+			return false
+		}
+	}
+	return true
+}
+
+// impureFuncVisitor finds the impure functions, and the functions whose
+// evaluation depends on the planner, in an expression.
+type impureFuncVisitor struct {
+	found bool
+}
+
+var _ tree.Visitor = &impureFuncVisitor{}
+
+func (v *impureFuncVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if f, ok := expr.(*tree.FuncExpr); ok && (f.IsImpure() || f.IsDistSQLBlacklist()) {
+		v.found = true
+	}
+	return !v.found, expr
+}
+
+func (*impureFuncVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// execWithHistoricalResultCache is like execWithDistSQLEngine, but serves the
+// result of the statement from the HistoricalResultCache if possible, and
+// caches it otherwise.
+func (ex *connExecutor) execWithHistoricalResultCache(
+	ctx context.Context,
+	planner *planner,
+	stmtType tree.StatementType,
+	res RestrictedCommandResult,
+	distribute bool,
+) error {
+	c := ex.server.cfg.HistoricalResults
+	maxSize := c.maxResultSize()
+	if maxSize == 0 {
+		return ex.execWithDistSQLEngine(ctx, planner, stmtType, res, distribute)
+	}
+	key, ok := ex.historicalResultCacheKey(ctx, planner)
+	if !ok {
+		return ex.execWithDistSQLEngine(ctx, planner, stmtType, res, distribute)
+	}
+	if ok, err := c.serveHistoricalResult(ctx, key, res); ok {
+		return err
+	}
+	w := &historicalResultWriter{RestrictedCommandResult: res, maxSize: maxSize}
+	err := ex.execWithDistSQLEngine(ctx, planner, stmtType, w, distribute)
+	if err == nil {
+		w.maybeCache(c, key)
+	}
+	return err
+}
+
+// serveHistoricalResult writes the result cached for the given key to res,
+// if any, and returns whether it did.
+func (c *HistoricalResultCache) serveHistoricalResult(
+	ctx context.Context, key historicalResultKey, res RestrictedCommandResult,
+) (bool, error) {
+	cached, ok := c.lookup(key)
+	if !ok {
+		telemetry.Inc(sqltelemetry.HistoricalResultCacheMissCounter)
+		return false, nil
+	}
+	telemetry.Inc(sqltelemetry.HistoricalResultCacheHitCounter)
+	log.VEventf(ctx, 2, "using the %d rows cached for the statement", len(cached.rows))
+	for _, row := range cached.rows {
+		if err := res.AddRow(ctx, row); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// historicalResultWriter is a RestrictedCommandResult which tees the rows of
+// a result into a historicalResult, until the result gets larger than
+// maxSize.
+type historicalResultWriter struct {
+	RestrictedCommandResult
+	maxSize int64
+	result  historicalResult
+	// tooLarge is set once the result has been found to be too large to be
+	// cached.
+	tooLarge bool
+}
+
+// AddRow is part of the RestrictedCommandResult interface.
+func (w *historicalResultWriter) AddRow(ctx context.Context, row tree.Datums) error {
+	if !w.tooLarge {
+		size := rowcontainer.SizeOfDatums + rowcontainer.SizeOfDatum*int64(len(row))
+		for _, d := range row {
+			size += int64(d.Size())
+		}
+		if w.result.size+size > w.maxSize {
+			w.tooLarge = true
+			w.result = historicalResult{}
+		} else {
+			// The row can't be retained, but the datums themselves are immutable.
+			w.result.rows = append(w.result.rows, append(tree.Datums(nil), row...))
+			w.result.size += size
+		}
+	}
+	return w.RestrictedCommandResult.AddRow(ctx, row)
+}
+
+// maybeCache caches the result written to w, unless the execution
+// failed or the result was too large.
+func (w *historicalResultWriter) maybeCache(c *HistoricalResultCache, key historicalResultKey) {
+	if w.tooLarge || w.Err() != nil {
+		return
+	}
+	c.add(key, &w.result)
+}
@@ -0,0 +1,105 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestHistoricalResultCache(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{UseDatabase: "test"})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE test`)
+	sqlDB.Exec(t, `CREATE TABLE kv (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO kv VALUES (1, 10), (2, 20)`)
+	var ts string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts)
+	// The row written after the timestamp is never visible.
+	sqlDB.Exec(t, `INSERT INTO kv VALUES (3, 30)`)
+
+	c := s.ExecutorConfig().(ExecutorConfig).HistoricalResults
+	hits := func() int32 {
+		return telemetry.GetFeatureCounts()["sql.exec.historical_result_cache.hit"]
+	}
+
+	testData := []struct {
+		query    string
+		args     []interface{}
+		expected [][]string
+		// cached is set if the result is expected to be cached.
+		cached bool
+	}{
+		{`SELECT k, v FROM kv AS OF SYSTEM TIME %s ORDER BY k`, nil,
+			[][]string{{"1", "10"}, {"2", "20"}}, true},
+		{`SELECT k, v FROM kv AS OF SYSTEM TIME %s WHERE k > 1`, nil,
+			[][]string{{"2", "20"}}, true},
+		{`SELECT k, v FROM kv AS OF SYSTEM TIME %s WHERE k > 0 ORDER BY k`, nil,
+			[][]string{{"1", "10"}, {"2", "20"}}, true},
+		{`SELECT v FROM kv AS OF SYSTEM TIME %s WHERE k = $1`, []interface{}{1},
+			[][]string{{"10"}}, true},
+		{`SELECT v FROM kv AS OF SYSTEM TIME %s WHERE k = $1`, []interface{}{2},
+			[][]string{{"20"}}, true},
+		{`SELECT count(*) FROM kv AS OF SYSTEM TIME %s WHERE random() < 2`, nil,
+			[][]string{{"2"}}, false},
+		{`SELECT count(*), current_setting('database') FROM kv AS OF SYSTEM TIME %s`, nil,
+			[][]string{{"2", "test"}}, false},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			historicalResultCacheEnabled.Override(&s.ClusterSettings().SV, enabled)
+			for _, d := range testData {
+				query := fmt.Sprintf(d.query, ts)
+				before, beforeHits := c.Len(), hits()
+				for i := 0; i < 3; i++ {
+					if res := sqlDB.QueryStr(t, query, d.args...); !reflect.DeepEqual(res, d.expected) {
+						t.Fatalf("%s: expected %v, got %v", query, d.expected, res)
+					}
+				}
+				expected, expectedHits := before, beforeHits
+				if enabled && d.cached {
+					expected, expectedHits = before+1, beforeHits+2
+				}
+				if n := c.Len(); n != expected {
+					t.Errorf("%s: expected %d cached results, got %d", query, expected, n)
+				}
+				if n := hits(); n != expectedHits {
+					t.Errorf("%s: expected %d hits, got %d", query, expectedHits, n)
+				}
+			}
+		})
+	}
+
+	// Statements which don't read at a fixed timestamp are not cached.
+	before := c.Len()
+	sqlDB.CheckQueryResults(t, `SELECT count(*) FROM kv`, [][]string{{"3"}})
+	if n := c.Len(); n != before {
+		t.Errorf("expected %d cached results, got %d", before, n)
+	}
+}
@@ -291,6 +291,33 @@ https://www.postgresql.org/docs/9.5/infoschema-column-privileges.html`,
 					}
 				}
 			}
+			// Privileges granted on individual columns, unless they are already
+			// granted on the whole table.
+			for i := range table.Columns {
+				cd := &table.Columns[i]
+				if cd.Privileges == nil {
+					continue
+				}
+				for _, u := range cd.Privileges.Users {
+					for _, priv := range privilege.ListFromBitField(u.Privileges) {
+						if table.Privileges.CheckPrivilege(u.User, priv) {
+							continue
+						}
+						if err := addRow(
+							tree.DNull,                     // grantor
+							tree.NewDString(u.User),        // grantee
+							dbNameStr,                      // table_catalog
+							scNameStr,                      // table_schema
+							tree.NewDString(table.Name),    // table_name
+							tree.NewDString(cd.Name),       // column_name
+							tree.NewDString(priv.String()), // privilege_type
+							tree.DNull,                     // is_grantable
+						); err != nil {
+							return err
+						}
+					}
+				}
+			}
 			return nil
 		})
 	},
@@ -1216,12 +1243,16 @@ CREATE TABLE information_schema.views (
 				// while Postgres would more accurately print `SELECT b AS a FROM foo`.
 				// TODO(a-robinson): Insert column aliases into view query once we
 				// have a semantic query representation to work with (#10083).
+				checkOption := tree.DNull
+				if table.ViewCheckOption {
+					checkOption = tree.NewDString("CASCADED")
+				}
 				return addRow(
 					tree.NewDString(db.Name),         // table_catalog
 					tree.NewDString(scName),          // table_schema
 					tree.NewDString(table.Name),      // table_name
 					tree.NewDString(table.ViewQuery), // view_definition
-					tree.DNull,                       // check_option
+					checkOption,                      // check_option
 					noString,                         // is_updatable
 					noString,                         // is_insertable_into
 					noString,                         // is_trigger_updatable
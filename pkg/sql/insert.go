@@ -230,6 +230,10 @@ func (p *planner) Insert(
 					return nil, sqlbase.CannotWriteToComputedColError(insertCols[maxInsertIdx].Name)
 				}
 				arityChecked = true
+				values, err = applyOverridingForInsert(n.Overriding, insertCols[:numExprs], values)
+				if err != nil {
+					return nil, err
+				}
 			}
 			src, err = fillDefaults(defaultExprs, insertCols, values)
 			if err != nil {
@@ -259,6 +263,13 @@ func (p *planner) Insert(
 		if numExprs > maxInsertIdx {
 			return nil, sqlbase.CannotWriteToComputedColError(insertCols[maxInsertIdx].Name)
 		}
+		if !n.DefaultValues() {
+			if _, err := applyOverridingForInsert(
+				n.Overriding, insertCols[:numExprs], nil, /* values */
+			); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// The required types may not have been matched exactly by the planning.
@@ -357,6 +368,9 @@ type insertRun struct {
 	// serve as input for indexed vars contained in the computeExprs.
 	iVarContainerForComputedCols sqlbase.RowIndexedVarContainer
 
+	// triggers are the triggers fired for the inserted rows, if any.
+	triggers *rowTriggers
+
 	// rowCount is the number of rows in the current batch.
 	rowCount int
 
@@ -433,6 +447,17 @@ func (n *insertNode) startExec(params runParams) error {
 		}
 	}
 
+	var err error
+	n.run.triggers, err = makeRowTriggers(n.run.ti.tableDesc(), sqlbase.TriggerDescriptor_INSERT)
+	if err != nil {
+		return err
+	}
+	if n.run.triggers != nil {
+		if err := n.run.triggers.fireBeforeStatement(params); err != nil {
+			return err
+		}
+	}
+
 	return n.run.ti.init(params.p.txn, params.EvalContext())
 }
 
@@ -482,8 +507,6 @@ func (n *insertNode) BatchedNext(params runParams) (bool, error) {
 			return false, err
 		}
 
-		n.run.rowCount++
-
 		// Are we done yet with the current batch?
 		if n.run.ti.curBatchSize() >= maxInsertBatchSize {
 			break
@@ -512,6 +535,19 @@ func (n *insertNode) BatchedNext(params runParams) (bool, error) {
 		n.run.done = true
 	}
 
+	// Now that the rows of the batch have been written, fire the AFTER
+	// triggers, and the statement-level ones after the last batch.
+	if n.run.triggers != nil {
+		if err := n.run.triggers.fireAfter(params); err != nil {
+			return false, err
+		}
+		if n.run.done {
+			if err := n.run.triggers.fireAfterStatement(params); err != nil {
+				return false, err
+			}
+		}
+	}
+
 	// Possibly initiate a run of CREATE STATISTICS.
 	params.ExecCfg().StatsRefresher.NotifyMutation(n.run.ti.tableDesc().ID, n.run.rowCount)
 
@@ -519,7 +555,8 @@ func (n *insertNode) BatchedNext(params runParams) (bool, error) {
 }
 
 // processSourceRow processes one row from the source for insertion and, if
-// result rows are needed, saves it in the result row container.
+// result rows are needed, saves it in the result row container. The row is
+// skipped if a BEFORE trigger returns NULL.
 func (n *insertNode) processSourceRow(params runParams, sourceVals tree.Datums) error {
 	// Process the incoming row tuple and generate the full inserted
 	// row. This fills in the defaults, computes computed columns, and
@@ -538,6 +575,16 @@ func (n *insertNode) processSourceRow(params runParams, sourceVals tree.Datums)
 		return err
 	}
 
+	// Fire the BEFORE triggers, which can modify the row or skip it.
+	if n.run.triggers != nil {
+		newRow := n.run.triggers.makeRow(n.run.ti.ri.InsertColIDtoRowIndex, rowVals)
+		if ok, err := n.run.triggers.fireBefore(
+			params, triggerRows{new: newRow}, n.run.ti.ri.InsertColIDtoRowIndex, rowVals,
+		); err != nil || !ok {
+			return err
+		}
+	}
+
 	// Run the CHECK constraints, if any. CheckHelper will either evaluate the
 	// constraints itself, or else inspect boolean columns from the input that
 	// contain the results of evaluation.
@@ -563,6 +610,13 @@ func (n *insertNode) processSourceRow(params runParams, sourceVals tree.Datums)
 	if err = n.run.ti.row(params.ctx, rowVals, n.run.traceKV); err != nil {
 		return err
 	}
+	n.run.rowCount++
+
+	if n.run.triggers != nil {
+		n.run.triggers.queueAfter(triggerRows{
+			new: n.run.triggers.makeRow(n.run.ti.ri.InsertColIDtoRowIndex, rowVals),
+		})
+	}
 
 	// If result rows need to be accumulated, do it.
 	if n.run.rows != nil {
@@ -600,7 +654,11 @@ func (n *insertNode) Close(ctx context.Context) {
 
 // enableAutoCommit is part of the autoCommitNode interface.
 func (n *insertNode) enableAutoCommit() {
-	n.run.ti.enableAutoCommit()
+	// The AFTER triggers are fired after the last batch has been written, so
+	// the transaction cannot be committed along with it.
+	if !n.run.ti.tableDesc().HasTrigger(sqlbase.TriggerDescriptor_INSERT) {
+		n.run.ti.enableAutoCommit()
+	}
 }
 
 // GenerateInsertRow prepares a row tuple for insertion. It fills in default
@@ -765,6 +823,7 @@ func extractInsertSource(
 	limit := s.Limit
 	orderBy := s.OrderBy
 	with := s.With
+	locking := s.Locking
 
 	// Be careful to not unwrap expressions with a WITH clause. These
 	// need to be handled generically.
@@ -793,9 +852,10 @@ func extractInsertSource(
 			}
 			limit = s.Select.Limit
 		}
+		locking = append(locking, s.Select.Locking...)
 	}
 
-	if with == nil && orderBy == nil && limit == nil {
+	if with == nil && orderBy == nil && limit == nil && locking == nil {
 		values, _ := wrapped.(*tree.ValuesClause)
 		if values != nil {
 			return wrapped, &tree.ValuesClauseWithNames{ValuesClause: *values, Names: colNames}, nil
@@ -803,7 +863,9 @@ func extractInsertSource(
 		return wrapped, nil, nil
 	}
 	return &tree.ParenSelect{
-		Select: &tree.Select{Select: wrapped, OrderBy: orderBy, Limit: limit, With: with},
+		Select: &tree.Select{
+			Select: wrapped, OrderBy: orderBy, Limit: limit, Locking: locking, With: with,
+		},
 	}, nil, nil
 }
 
@@ -895,6 +957,76 @@ func fillDefaults(
 	return ret, nil
 }
 
+// applyOverridingForInsert checks the values provided by an INSERT statement
+// for the identity columns among insertCols, the columns receiving the source
+// values, according to the OVERRIDING clause:
+// - without OVERRIDING, only DEFAULT can be provided for the GENERATED ALWAYS
+//   AS IDENTITY columns.
+// - with OVERRIDING SYSTEM VALUE, any value can be provided.
+// - with OVERRIDING USER VALUE, the provided values are replaced by DEFAULT.
+//
+// values is the VALUES clause providing the values, or nil if the source is
+// another kind of statement. The function returns the VALUES clause with the
+// replaced values, if any, or an error.
+func applyOverridingForInsert(
+	overriding tree.OverridingKind,
+	insertCols []sqlbase.ColumnDescriptor,
+	values *tree.ValuesClauseWithNames,
+) (*tree.ValuesClauseWithNames, error) {
+	ret := values
+	for i := range insertCols {
+		col := &insertCols[i]
+		if !col.IsIdentity() {
+			continue
+		}
+		switch overriding {
+		case tree.OverridingNone:
+			if col.IsGeneratedAlwaysAsIdentity() && !valuesAreDefault(values, i) {
+				return nil, sqlbase.NewGeneratedAlwaysAsIdentityColumnOverrideError(col.Name)
+			}
+		case tree.OverridingUserValue:
+			if values == nil {
+				return nil, pgerror.Unimplemented("insert.overriding_user_value",
+					"OVERRIDING USER VALUE is only supported with a VALUES clause")
+			}
+			if ret == values {
+				// Do not modify the statement, which may be reused.
+				ret = &tree.ValuesClauseWithNames{
+					ValuesClause: tree.ValuesClause{Rows: make([]tree.Exprs, len(values.Rows))},
+					Names:        values.Names,
+				}
+				for j, tuple := range values.Rows {
+					ret.Rows[j] = append(tree.Exprs(nil), tuple...)
+				}
+			}
+			for _, tuple := range ret.Rows {
+				if i < len(tuple) {
+					tuple[i] = tree.DefaultVal{}
+				}
+			}
+		}
+	}
+	return ret, nil
+}
+
+// valuesAreDefault returns whether all the values at the given position in
+// the tuples of the VALUES clause are DEFAULT, or false if values is nil.
+func valuesAreDefault(values *tree.ValuesClauseWithNames, idx int) bool {
+	if values == nil {
+		return false
+	}
+	for _, tuple := range values.Rows {
+		if idx >= len(tuple) {
+			// The mismatched length is reported by fillDefaults.
+			continue
+		}
+		if _, ok := tuple[idx].(tree.DefaultVal); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func checkNumExprs(isUpsert bool, numExprs, numCols int, specifiedTargets bool) error {
 	// It is ok to be missing exprs if !specifiedTargets, because the missing
 	// columns will be filled in by DEFAULT expressions.
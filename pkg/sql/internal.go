@@ -419,8 +419,9 @@ const (
 	// This is equivalent to passing internalExecUseFixedUserSession with
 	// the result of newInternalSessionUserArgs(security.RootUser).
 	internalExecRootSession
-	// internalExecFixedUser will use the provided username in
-	// SessionArgs and reset everything else as per internalExecRootSession.
+	// internalExecFixedUser will use the provided username and, if
+	// provided in SessionDefaults, current database in SessionArgs and reset
+	// everything else as per internalExecRootSession.
 	internalExecFixedUserSession
 )
 
@@ -450,17 +451,21 @@ func (ie *internalExecutorImpl) execInternal(
 		}
 	}
 
+	database := "system"
 	switch sessionMode {
 	case internalExecFixedUserSession:
 		if !sargs.isDefined() {
 			log.Fatal(ctx, "programming error: mode fixed user with undefined sargs")
 		}
-		// Clear all fields except user.
+		// Clear all fields except user and, if provided, the current database.
+		if db, ok := sargs.SessionDefaults["database"]; ok {
+			database = db
+		}
 		sargs = SessionArgs{User: sargs.User}
 		fallthrough
 	case internalExecRootSession:
 		sargs.SessionDefaults = map[string]string{
-			"database":         "system",
+			"database":         database,
 			"application_name": InternalAppNamePrefix + "-" + opName,
 		}
 	}
@@ -0,0 +1,171 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeywordCategory is the category of a SQL keyword, which determines the
+// contexts in which the keyword can be used as an identifier without quotes.
+// The categories are those of PostgreSQL.
+type KeywordCategory int
+
+const (
+	// UnreservedKeyword is the category of keywords that can be used as any
+	// kind of identifier.
+	UnreservedKeyword KeywordCategory = iota
+	// ColNameKeyword is the category of keywords that can be used as column
+	// names, but not as function or type names.
+	ColNameKeyword
+	// TypeFuncNameKeyword is the category of keywords that can be used as
+	// function or type names, but not as column names.
+	TypeFuncNameKeyword
+	// ReservedKeyword is the category of keywords that cannot be used as
+	// identifiers.
+	ReservedKeyword
+)
+
+var keywordCategoryNames = [...]string{
+	UnreservedKeyword:   "unreserved",
+	ColNameKeyword:      "col_name",
+	TypeFuncNameKeyword: "type_func_name",
+	ReservedKeyword:     "reserved",
+}
+
+var keywordCategoryCodes = [...]string{
+	UnreservedKeyword:   "U",
+	ColNameKeyword:      "C",
+	TypeFuncNameKeyword: "T",
+	ReservedKeyword:     "R",
+}
+
+// Descriptions as reported by pg_get_keywords, see
+// src/backend/utils/adt/misc.c in pg's sources.
+var keywordCategoryDescriptions = [...]string{
+	UnreservedKeyword:   "unreserved",
+	ColNameKeyword:      "unreserved (cannot be function or type name)",
+	TypeFuncNameKeyword: "reserved (can be function or type name)",
+	ReservedKeyword:     "reserved",
+}
+
+// String returns the name of the category in the grammar, e.g.
+// "type_func_name".
+func (c KeywordCategory) String() string {
+	if c < 0 || int(c) >= len(keywordCategoryNames) {
+		return fmt.Sprintf("KeywordCategory(%d)", int(c))
+	}
+	return keywordCategoryNames[c]
+}
+
+// Code returns the one-letter code of the category, as reported in the
+// catcode column of pg_get_keywords.
+func (c KeywordCategory) Code() string {
+	return keywordCategoryCodes[c]
+}
+
+// Description returns the description of the category, as reported in the
+// catdesc column of pg_get_keywords.
+func (c KeywordCategory) Description() string {
+	return keywordCategoryDescriptions[c]
+}
+
+func keywordCategoryFromCode(code string) KeywordCategory {
+	for c, s := range keywordCategoryCodes {
+		if s == code {
+			return KeywordCategory(c)
+		}
+	}
+	panic(fmt.Sprintf("unknown keyword category code %q", code))
+}
+
+// KeywordInfo describes a SQL keyword.
+type KeywordInfo struct {
+	// Name is the keyword in lower case.
+	Name     string
+	Category KeywordCategory
+	// Lookahead is true if the lexer looks at the keyword when deciding the
+	// token of the keyword before it, such as TIME in WITH TIME ZONE.
+	// Lookahead keywords must be quoted to be used as identifiers in some
+	// contexts even when they are unreserved.
+	Lookahead bool
+}
+
+// MustQuote returns true if identifiers spelled like the keyword are quoted
+// when they are formatted. This is the case of all keywords but the
+// unreserved keywords that are not lookahead keywords.
+func (k KeywordInfo) MustQuote() bool {
+	return isReservedKeyword(k.Name)
+}
+
+// keywordInfos contains the description of all keywords, sorted by name.
+var keywordInfos []KeywordInfo
+
+// keywordIndexes maps the keywords to their index in keywordInfos.
+var keywordIndexes map[string]int
+
+func init() {
+	keywordInfos = make([]KeywordInfo, len(KeywordNames))
+	for i, name := range KeywordNames {
+		_, lookahead := lookaheadKeywordSet[name]
+		keywordInfos[i] = KeywordInfo{
+			Name:      name,
+			Category:  keywordCategoryFromCode(KeywordsCategories[name]),
+			Lookahead: lookahead,
+		}
+	}
+	sort.Slice(keywordInfos, func(i, j int) bool {
+		return keywordInfos[i].Name < keywordInfos[j].Name
+	})
+	keywordIndexes = make(map[string]int, len(keywordInfos))
+	for i := range keywordInfos {
+		keywordIndexes[keywordInfos[i].Name] = i
+	}
+}
+
+// Keywords returns the descriptions of all SQL keywords, sorted by name.
+func Keywords() []KeywordInfo {
+	return append([]KeywordInfo(nil), keywordInfos...)
+}
+
+// LookupKeyword returns the description of the given SQL keyword, which is
+// case insensitive. ok is false if the word is not a keyword.
+func LookupKeyword(word string) (info KeywordInfo, ok bool) {
+	i, ok := keywordIndexes[strings.ToLower(word)]
+	if !ok {
+		return KeywordInfo{}, false
+	}
+	return keywordInfos[i], true
+}
+
+// KeywordsInCategory returns the names of the keywords of the given category,
+// sorted.
+func KeywordsInCategory(c KeywordCategory) []string {
+	var res []string
+	for i := range keywordInfos {
+		if keywordInfos[i].Category == c {
+			res = append(res, keywordInfos[i].Name)
+		}
+	}
+	return res
+}
+
+// LookaheadKeywords returns the names of the keywords which the lexer looks
+// ahead at, sorted. See KeywordInfo.Lookahead.
+func LookaheadKeywords() []string {
+	return append([]string(nil), lookaheadKeywords...)
+}
@@ -0,0 +1,95 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package lex_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+)
+
+func TestLookupKeyword(t *testing.T) {
+	testData := []struct {
+		word      string
+		ok        bool
+		category  lex.KeywordCategory
+		lookahead bool
+		mustQuote bool
+	}{
+		{"select", true, lex.ReservedKeyword, false, true},
+		{"SELECT", true, lex.ReservedKeyword, false, true},
+		{"abort", true, lex.UnreservedKeyword, false, false},
+		{"between", true, lex.ColNameKeyword, true, true},
+		{"of", true, lex.UnreservedKeyword, true, true},
+		{"like", true, lex.TypeFuncNameKeyword, true, true},
+		{"int", true, lex.ColNameKeyword, false, true},
+		{"foo", false, 0, false, false},
+	}
+	for _, d := range testData {
+		t.Run(d.word, func(t *testing.T) {
+			kw, ok := lex.LookupKeyword(d.word)
+			if ok != d.ok {
+				t.Fatalf("expected ok=%t, got %t", d.ok, ok)
+			}
+			if !ok {
+				return
+			}
+			if kw.Category != d.category {
+				t.Errorf("expected category %s, got %s", d.category, kw.Category)
+			}
+			if kw.Lookahead != d.lookahead {
+				t.Errorf("expected lookahead=%t, got %t", d.lookahead, kw.Lookahead)
+			}
+			if kw.MustQuote() != d.mustQuote {
+				t.Errorf("expected MustQuote()=%t, got %t", d.mustQuote, kw.MustQuote())
+			}
+		})
+	}
+}
+
+func TestKeywords(t *testing.T) {
+	kws := lex.Keywords()
+	if len(kws) != len(lex.KeywordNames) {
+		t.Fatalf("expected %d keywords, got %d", len(lex.KeywordNames), len(kws))
+	}
+	if !sort.SliceIsSorted(kws, func(i, j int) bool { return kws[i].Name < kws[j].Name }) {
+		t.Errorf("keywords are not sorted")
+	}
+	count := 0
+	for _, c := range []lex.KeywordCategory{
+		lex.UnreservedKeyword, lex.ColNameKeyword, lex.TypeFuncNameKeyword, lex.ReservedKeyword,
+	} {
+		names := lex.KeywordsInCategory(c)
+		if len(names) == 0 {
+			t.Errorf("no keywords in category %s", c)
+		}
+		for _, name := range names {
+			if kw, _ := lex.LookupKeyword(name); kw.Category != c {
+				t.Errorf("keyword %s is listed in category %s, but has category %s", name, c, kw.Category)
+			}
+		}
+		count += len(names)
+	}
+	if count != len(kws) {
+		t.Errorf("expected %d keywords in the categories, got %d", len(kws), count)
+	}
+	// The lookahead keywords must all be keywords.
+	for _, name := range lex.LookaheadKeywords() {
+		if kw, ok := lex.LookupKeyword(name); !ok || !kw.Lookahead {
+			t.Errorf("lookahead keyword %s is not a lookahead keyword", name)
+		}
+	}
+}
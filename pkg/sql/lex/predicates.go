@@ -41,6 +41,16 @@ func IsHexDigit(ch int) bool {
 		(ch >= 'A' && ch <= 'F')
 }
 
+// lookaheadKeywordSet is the set of lookaheadKeywords, which is generated
+// from the grammar in lookahead_keywords.go.
+var lookaheadKeywordSet = func() map[string]struct{} {
+	m := make(map[string]struct{}, len(lookaheadKeywords))
+	for _, s := range lookaheadKeywords {
+		m[s] = struct{}{}
+	}
+	return m
+}()
+
 // reservedOrLookaheadKeywords are the reserved keywords plus those keywords for
 // which we need one token of lookahead extra to determine their token type.
 var reservedOrLookaheadKeywords = make(map[string]struct{})
@@ -49,16 +59,7 @@ func init() {
 	for s := range reservedKeywords {
 		reservedOrLookaheadKeywords[s] = struct{}{}
 	}
-	for _, s := range []string{
-		"between",
-		"ilike",
-		"in",
-		"like",
-		"of",
-		"ordinality",
-		"similar",
-		"time",
-	} {
+	for s := range lookaheadKeywordSet {
 		reservedOrLookaheadKeywords[s] = struct{}{}
 	}
 }
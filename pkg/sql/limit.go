@@ -19,8 +19,10 @@ import (
 	"fmt"
 	"math"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
 // limitNode represents a node that limits the number of rows
@@ -32,6 +34,15 @@ type limitNode struct {
 	evaluated  bool
 	count      int64
 	offset     int64
+
+	// withTies is set for FETCH FIRST ... WITH TIES; the rows which are peers
+	// of the last row within the limit according to tiesOrdering are also
+	// returned.
+	withTies bool
+	// tiesOrdering is the ordering of the ORDER BY clause. The column indexes
+	// refer to the columns of the source of the sortNode below this node (or
+	// to the columns of the source of this node, if the sortNode was elided).
+	tiesOrdering sqlbase.ColumnOrdering
 }
 
 // limit constructs a limitNode based on the LIMIT and OFFSET clauses.
@@ -41,7 +52,7 @@ func (p *planner) Limit(ctx context.Context, n *tree.Limit) (*limitNode, error)
 		return nil, nil
 	}
 
-	res := limitNode{}
+	res := limitNode{withTies: n.WithTies}
 
 	data := []struct {
 		name string
@@ -72,6 +83,17 @@ func (p *planner) Limit(ctx context.Context, n *tree.Limit) (*limitNode, error)
 	return &res, nil
 }
 
+// initTies sets up a WITH TIES limit to use the ordering of the given
+// sortNode, which must be the source of the limitNode.
+func (n *limitNode) initTies(sort *sortNode) error {
+	if sort == nil {
+		return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"WITH TIES cannot be specified without ORDER BY clause")
+	}
+	n.tiesOrdering = sort.ordering
+	return nil
+}
+
 func (n *limitNode) startExec(params runParams) error {
 	panic("limitNode cannot be run in local mode")
 }
@@ -25,7 +25,7 @@ type lookupJoinNode struct {
 	input planNode
 	table *scanNode
 
-	// joinType is either INNER or LEFT_OUTER.
+	// joinType is INNER, LEFT_OUTER, LEFT_SEMI or LEFT_ANTI.
 	joinType sqlbase.JoinType
 
 	// keyCols identifies the columns from the input which are used for the
@@ -34,11 +34,13 @@ type lookupJoinNode struct {
 	keyCols []int
 
 	// columns are the produced columns, namely the input clumns and the
-	// columns in the table scanNode.
+	// columns in the table scanNode (except for semi and anti joins, which only
+	// produce the input columns).
 	columns sqlbase.ResultColumns
 
 	// onCond is any ON condition to be used in conjunction with the implicit
-	// equality condition on keyCols.
+	// equality condition on keyCols. It refers to the input columns followed
+	// by the columns in the table scanNode.
 	onCond tree.TypedExpr
 
 	props physicalProps
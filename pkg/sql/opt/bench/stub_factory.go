@@ -42,6 +42,9 @@ func (f *stubFactory) ConstructScan(
 	hardLimit int64,
 	reverse bool,
 	maxResults uint64,
+	lockingStrength tree.LockingStrength,
+	lockingWaitPolicy tree.LockingWaitPolicy,
+	sample *tree.TableSample,
 	reqOrdering exec.OutputOrdering,
 ) (exec.Node, error) {
 	return struct{}{}, nil
@@ -181,6 +184,12 @@ func (f *stubFactory) ConstructLimit(
 	return struct{}{}, nil
 }
 
+func (f *stubFactory) ConstructLimitWithTies(
+	input exec.Node, limit tree.TypedExpr, ordering sqlbase.ColumnOrdering,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
 func (f *stubFactory) ConstructMax1Row(input exec.Node) (exec.Node, error) {
 	return struct{}{}, nil
 }
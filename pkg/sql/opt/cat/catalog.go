@@ -98,4 +98,10 @@ type Catalog interface {
 	// CheckPrivilege verifies that the current user has the given privilege on
 	// the given catalog object. If not, then CheckPrivilege returns an error.
 	CheckPrivilege(ctx context.Context, o Object, priv privilege.Kind) error
+
+	// CheckColumnPrivilege verifies that the current user has been granted the
+	// given privilege on the column of the given table with the given ordinal.
+	// Only privileges granted on the column itself are considered; privileges
+	// on the table must be checked with CheckPrivilege.
+	CheckColumnPrivilege(ctx context.Context, tab Table, ord int, priv privilege.Kind) error
 }
@@ -87,6 +87,20 @@ type Column interface {
 	// computed columns, but they can depend on all other columns, including
 	// columns with default values.
 	ComputedExprStr() string
+
+	// IsVirtual returns true if the column is a virtual computed column. The
+	// values of a virtual column are not stored; they are computed from
+	// ComputedExprStr whenever the table is scanned.
+	IsVirtual() bool
+
+	// IsIdentity returns true if the column is an identity column, whose
+	// default value is generated from a sequence.
+	IsIdentity() bool
+
+	// IsGeneratedAlwaysAsIdentity returns true if the column is an identity
+	// column defined as GENERATED ALWAYS, which only accepts values provided
+	// by INSERT statements with the OVERRIDING SYSTEM VALUE clause.
+	IsGeneratedAlwaysAsIdentity() bool
 }
 
 // IsMutationColumn is a convenience function that returns true if the column at
@@ -57,6 +57,10 @@ type Table interface {
 	// foreign key defined on another table (or this one if self-referential).
 	IsReferenced() bool
 
+	// HasTriggers returns true if this table has at least one trigger, which
+	// must be fired for every row that is inserted, updated, or deleted.
+	HasTriggers() bool
+
 	// ColumnCount returns the number of public columns in the table. Public
 	// columns are not currently being added or dropped from the table. This
 	// method should be used when mutation columns can be ignored (the common
@@ -170,6 +174,21 @@ type TableStatistic interface {
 	// any column in the statistic.
 	NullCount() uint64
 
+	// Expression returns the SQL text of the expression of the columns which
+	// the statistic is on, or the empty string if the statistic is on the
+	// columns themselves. The expression refers to the columns of the
+	// statistic by name. DistinctCount and NullCount are then those of the
+	// values of the expression.
+	Expression() string
+
+	// Predicate returns the SQL text of the predicate satisfied by the rows
+	// which the statistic was collected on if it is a partial statistic, or
+	// the empty string otherwise. A partial statistic is collected on the rows
+	// whose values are outside of the range of the values seen by the most
+	// recent full statistic on the same column (see CREATE STATISTICS ...
+	// USING EXTREMES), hence its counts add up to those of the full statistic.
+	Predicate() string
+
 	// TODO(radu): add Histogram().
 }
 
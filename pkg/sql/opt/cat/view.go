@@ -38,6 +38,10 @@ type View interface {
 	// ColumnNames returns the name of the column at the ith ordinal position
 	// within the view, where i < ColumnNameCount.
 	ColumnName(i int) tree.Name
+
+	// CheckOption returns true if the view was created WITH CHECK OPTION, in
+	// which case rows written through the view must be visible through it.
+	CheckOption() bool
 }
 
 // FormatView nicely formats a catalog view using a treeprinter for debugging
@@ -58,4 +62,7 @@ func FormatView(view View, tp treeprinter.Node) {
 	child := tp.Childf("VIEW %s%s", view.Name().TableName, buf.String())
 
 	child.Child(view.Query())
+	if view.CheckOption() {
+		child.Child("WITH CHECK OPTION")
+	}
 }
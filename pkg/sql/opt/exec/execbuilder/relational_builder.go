@@ -185,6 +185,9 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.LimitExpr, *memo.OffsetExpr:
 		ep, err = b.buildLimitOffset(e)
 
+	case *memo.LimitWithTiesExpr:
+		ep, err = b.buildLimitWithTies(t)
+
 	case *memo.SortExpr:
 		ep, err = b.buildSort(t)
 
@@ -392,6 +395,9 @@ func (b *Builder) buildScan(scan *memo.ScanExpr) (execPlan, error) {
 		// HardLimit.Reverse() is taken into account by ScanIsReverse.
 		ordering.ScanIsReverse(scan, &scan.RequiredPhysical().Ordering),
 		b.indexConstraintMaxResults(scan),
+		scan.LockingStrength,
+		scan.LockingWaitPolicy,
+		scan.Sample,
 		res.reqOrdering(scan),
 	)
 	if err != nil {
@@ -862,6 +868,12 @@ func (b *Builder) extractAggregateConstArgs(agg opt.ScalarExpr) tree.Datums {
 	switch agg.Op() {
 	case opt.StringAggOp:
 		return tree.Datums{memo.ExtractConstDatum(agg.Child(1))}
+	case opt.PercentileDiscOp, opt.PercentileContOp, opt.ModeOp:
+		args := make(tree.Datums, agg.ChildCount()-1)
+		for i := range args {
+			args[i] = memo.ExtractConstDatum(agg.Child(i + 1))
+		}
+		return args
 	default:
 		return nil
 	}
@@ -1055,6 +1067,40 @@ func (b *Builder) buildLimitOffset(e memo.RelExpr) (execPlan, error) {
 	return execPlan{root: node, outputCols: input.outputCols}, nil
 }
 
+func (b *Builder) buildLimitWithTies(limit *memo.LimitWithTiesExpr) (execPlan, error) {
+	input, err := b.buildRelational(limit.Input)
+	if err != nil {
+		return execPlan{}, err
+	}
+	expr, err := b.buildScalar(nil, limit.Limit)
+	if err != nil {
+		return execPlan{}, err
+	}
+	// The peers of the last row within the limit are found by comparing the
+	// ordering columns; any column of each group of equivalent columns will do.
+	ordering := make(opt.Ordering, 0, len(limit.Ordering.Columns))
+	for i := range limit.Ordering.Columns {
+		c := &limit.Ordering.Columns[i]
+		found := false
+		for col, ok := c.Group.Next(0); ok; col, ok = c.Group.Next(col + 1) {
+			if _, ok := input.outputCols.Get(col); ok {
+				ordering = append(ordering, opt.MakeOrderingColumn(opt.ColumnID(col), c.Descending))
+				found = true
+				break
+			}
+		}
+		if !found {
+			return execPlan{}, pgerror.NewAssertionErrorf(
+				"no column of ordering group %s in input", c.Group)
+		}
+	}
+	node, err := b.factory.ConstructLimitWithTies(input.root, expr, input.sqlOrdering(ordering))
+	if err != nil {
+		return execPlan{}, err
+	}
+	return execPlan{root: node, outputCols: input.outputCols}, nil
+}
+
 func (b *Builder) buildSort(sort *memo.SortExpr) (execPlan, error) {
 	input, err := b.buildRelational(sort.Input)
 	if err != nil {
@@ -1152,6 +1198,11 @@ func (b *Builder) buildLookupJoin(join *memo.LookupJoinExpr) (execPlan, error) {
 	allCols := joinOutputMap(input.outputCols, lookupColMap)
 
 	res := execPlan{outputCols: allCols}
+	if join.JoinType == opt.SemiJoinOp || join.JoinType == opt.AntiJoinOp {
+		// For semi and anti join, only the left columns are output. The lookup
+		// columns are only used by the ON condition.
+		res.outputCols = input.outputCols
+	}
 
 	ctx := buildScalarCtx{
 		ivh:     tree.MakeIndexedVarHelper(nil /* container */, allCols.Len()),
@@ -1553,6 +1604,10 @@ func (b *Builder) canUseDeleteRange(del *memo.DeleteExpr) bool {
 		// is possible, because the integrity of those references must be checked.
 		return false
 	}
+	if tab.HasTriggers() {
+		// Triggers must be fired for every deleted row.
+		return false
+	}
 
 	// Check for simple Scan input operator without a limit; anything else is not
 	// supported by a range delete.
@@ -1720,7 +1775,14 @@ func (b *Builder) getEnvData() exec.ExplainEnvData {
 func (b *Builder) buildExplain(explain *memo.ExplainExpr) (execPlan, error) {
 	var node exec.Node
 
-	if explain.Options.Mode == tree.ExplainOpt {
+	if explain.Options.Mode == tree.ExplainGist {
+		gist := memo.MakePlanGist(b.mem, explain.Input)
+		var err error
+		node, err = b.factory.ConstructExplainOpt(gist.String(), exec.ExplainEnvData{})
+		if err != nil {
+			return execPlan{}, err
+		}
+	} else if explain.Options.Mode == tree.ExplainOpt {
 		fmtFlags := memo.ExprFmtHideAll
 		switch {
 		case explain.Options.Flags.Contains(tree.ExplainFlagVerbose):
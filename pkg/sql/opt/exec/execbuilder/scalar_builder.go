@@ -268,7 +268,17 @@ func (b *Builder) buildFunction(
 			return nil, err
 		}
 	}
-	funcRef := tree.WrapFunction(fn.Name)
+	var funcRef tree.ResolvableFunctionReference
+	if _, ok := tree.FunDefs[fn.Name]; ok {
+		funcRef = tree.WrapFunction(fn.Name)
+	} else {
+		// User-defined functions are not registered with the built-in
+		// functions; their definition is only needed for its properties.
+		funcRef = tree.ResolvableFunctionReference{FunctionReference: &tree.FunctionDefinition{
+			Name:               fn.Name,
+			FunctionProperties: *fn.Properties,
+		}}
+	}
 	return tree.NewTypedFuncExpr(
 		funcRef,
 		0, /* aggQualifier */
@@ -58,6 +58,11 @@ type Factory interface {
 	//     the scan.
 	//   - If maxResults > 0, the scan is guaranteed to return at most maxResults
 	//     rows.
+	//   - lockingStrength and lockingWaitPolicy represent the row-level locking
+	//     mode of the scan, as requested by a locking clause like FOR UPDATE.
+	//   - If sample is not nil, only a random sample of the rows is returned, as
+	//     requested by a TABLESAMPLE clause. Its arguments can contain
+	//     placeholders and are evaluated by the factory.
 	ConstructScan(
 		table cat.Table,
 		index cat.Index,
@@ -66,6 +71,9 @@ type Factory interface {
 		hardLimit int64,
 		reverse bool,
 		maxResults uint64,
+		lockingStrength tree.LockingStrength,
+		lockingWaitPolicy tree.LockingWaitPolicy,
+		sample *tree.TableSample,
 		reqOrdering OutputOrdering,
 	) (Node, error)
 
@@ -246,6 +254,15 @@ type Factory interface {
 	// set to nil.
 	ConstructLimit(input Node, limit, offset tree.TypedExpr) (Node, error)
 
+	// ConstructLimitWithTies returns a node that implements FETCH FIRST ...
+	// WITH TIES on the results of the given node: it returns the first limit
+	// rows, plus the rows which follow them and are peers of the last one
+	// according to the given ordering. The input must be sorted according to
+	// the ordering.
+	ConstructLimitWithTies(
+		input Node, limit tree.TypedExpr, ordering sqlbase.ColumnOrdering,
+	) (Node, error)
+
 	// ConstructMax1Row returns a node that permits at most one row from the
 	// given input node, returning an error at runtime if the node tries to return
 	// more than one row.
@@ -216,6 +216,18 @@ func (m *Memo) checkExpr(e opt.Expr) {
 			))
 		}
 
+		switch e.Op() {
+		case opt.PercentileDiscOp, opt.PercentileContOp, opt.ModeOp:
+			for i := 1; i < e.ChildCount(); i++ {
+				if !CanExtractConstDatum(e.Child(i)) {
+					panic(pgerror.NewAssertionErrorf(
+						"constant arguments to %s must always be constant, but got %s",
+						log.Safe(e.Op()), log.Safe(e.Child(i).Op()),
+					))
+				}
+			}
+		}
+
 		if opt.IsJoinOp(e) {
 			checkFilters(*e.Child(2).(*FiltersExpr))
 		}
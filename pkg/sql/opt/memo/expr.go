@@ -284,6 +284,24 @@ func (sf *ScanFlags) Empty() bool {
 	return !sf.NoIndexJoin && !sf.ForceIndex
 }
 
+// IsNonBlocking returns true if the scan doesn't wait on rows that are locked
+// by other transactions, as requested by FOR UPDATE SKIP LOCKED or NOWAIT.
+// Such a scan has to read all of its rows itself: index, lookup and zigzag
+// joins fetch their rows with the default wait policy and would block on the
+// locked ones.
+func (s *ScanPrivate) IsNonBlocking() bool {
+	return s.LockingWaitPolicy != tree.LockWaitBlock
+}
+
+// IsSampled returns true if the scan returns a random sample of the rows of
+// the table, as requested by a TABLESAMPLE clause. Such a scan has to read the
+// primary index: the sample is chosen among the primary index keys, so a scan
+// of a secondary index, or a join which fetches the rows by key, would return
+// a different set of rows.
+func (s *ScanPrivate) IsSampled() bool {
+	return s.Sample != nil
+}
+
 // JoinFlags stores restrictions on the join execution method, derived from
 // hints for a join specified in the query (see tree.JoinTableExpr).
 type JoinFlags struct {
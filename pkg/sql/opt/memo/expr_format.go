@@ -17,6 +17,7 @@ package memo
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"unicode"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
@@ -238,6 +239,11 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 			tp.Childf("internal-ordering: %s", t.Ordering)
 		}
 
+	case *LimitWithTiesExpr:
+		if !f.HasFlags(ExprFmtHideOrderings) && !t.Ordering.Any() {
+			tp.Childf("internal-ordering: %s", t.Ordering)
+		}
+
 	case *OffsetExpr:
 		if !f.HasFlags(ExprFmtHideOrderings) && !t.Ordering.Any() {
 			tp.Childf("internal-ordering: %s", t.Ordering)
@@ -276,6 +282,20 @@ func (f *ExprFmtCtx) formatRelational(e RelExpr, tp treeprinter.Node) {
 				tp.Childf("flags: force-index=%s%s", idx.Name(), dir)
 			}
 		}
+		if t.LockingStrength != tree.ForNone {
+			locking := t.LockingStrength.String()
+			if t.LockingWaitPolicy != tree.LockWaitBlock {
+				locking += "," + t.LockingWaitPolicy.String()
+			}
+			tp.Childf("locking: %s", strings.Replace(strings.ToLower(locking), " ", "-", -1))
+		}
+		if t.Sample != nil {
+			sample := fmt.Sprintf("%s (%s)", strings.ToLower(t.Sample.Method.String()), t.Sample.Percent)
+			if t.Sample.Repeatable != nil {
+				sample += fmt.Sprintf(" repeatable (%s)", t.Sample.Repeatable)
+			}
+			tp.Childf("sample: %s", sample)
+		}
 
 	case *LookupJoinExpr:
 		if !t.Flags.Empty() {
@@ -492,6 +492,14 @@ func (h *hasher) HashShowTraceType(val tree.ShowTraceType) {
 	h.HashString(string(val))
 }
 
+func (h *hasher) HashLockingStrength(val tree.LockingStrength) {
+	h.HashUint64(uint64(val))
+}
+
+func (h *hasher) HashLockingWaitPolicy(val tree.LockingWaitPolicy) {
+	h.HashUint64(uint64(val))
+}
+
 func (h *hasher) HashTupleOrdinal(val TupleOrdinal) {
 	h.HashUint64(uint64(val))
 }
@@ -756,6 +764,14 @@ func (h *hasher) IsShowTraceTypeEqual(l, r tree.ShowTraceType) bool {
 	return l == r
 }
 
+func (h *hasher) IsLockingStrengthEqual(l, r tree.LockingStrength) bool {
+	return l == r
+}
+
+func (h *hasher) IsLockingWaitPolicyEqual(l, r tree.LockingWaitPolicy) bool {
+	return l == r
+}
+
 func (h *hasher) IsTupleOrdinalEqual(l, r TupleOrdinal) bool {
 	return l == r
 }
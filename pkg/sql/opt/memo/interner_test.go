@@ -327,6 +327,17 @@ func TestInterner(t *testing.T) {
 			{val1: tree.ShowTraceKV, val2: tree.ShowTraceRaw, equal: false},
 		}},
 
+		{hashFn: in.hasher.HashLockingStrength, eqFn: in.hasher.IsLockingStrengthEqual, variations: []testVariation{
+			{val1: tree.ForNone, val2: tree.ForNone, equal: true},
+			{val1: tree.ForUpdate, val2: tree.ForUpdate, equal: true},
+			{val1: tree.ForShare, val2: tree.ForUpdate, equal: false},
+		}},
+
+		{hashFn: in.hasher.HashLockingWaitPolicy, eqFn: in.hasher.IsLockingWaitPolicyEqual, variations: []testVariation{
+			{val1: tree.LockWaitBlock, val2: tree.LockWaitBlock, equal: true},
+			{val1: tree.LockWaitSkip, val2: tree.LockWaitError, equal: false},
+		}},
+
 		{hashFn: in.hasher.HashTupleOrdinal, eqFn: in.hasher.IsTupleOrdinalEqual, variations: []testVariation{
 			{val1: TupleOrdinal(0), val2: TupleOrdinal(0), equal: true},
 			{val1: TupleOrdinal(0), val2: TupleOrdinal(1), equal: false},
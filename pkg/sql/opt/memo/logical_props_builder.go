@@ -795,6 +795,65 @@ func (b *logicalPropsBuilder) buildLimitProps(limit *LimitExpr, rel *props.Relat
 	}
 }
 
+func (b *logicalPropsBuilder) buildLimitWithTiesProps(
+	limit *LimitWithTiesExpr, rel *props.Relational,
+) {
+	BuildSharedProps(b.mem, limit, &rel.Shared)
+
+	inputProps := limit.Input.Relational()
+
+	haveConstLimit := false
+	constLimit := int64(math.MaxUint32)
+	if cnst, ok := limit.Limit.(*ConstExpr); ok {
+		haveConstLimit = true
+		constLimit = int64(*cnst.Value.(*tree.DInt))
+	}
+
+	// Side Effects
+	// ------------
+	// Negative limits can trigger a runtime error.
+	if constLimit < 0 || !haveConstLimit {
+		rel.CanHaveSideEffects = true
+	}
+
+	// Output Columns
+	// --------------
+	// Output columns are inherited from input.
+	rel.OutputCols = inputProps.OutputCols
+
+	// Not Null Columns
+	// ----------------
+	// Not null columns are inherited from input.
+	rel.NotNullCols = inputProps.NotNullCols
+
+	// Outer Columns
+	// -------------
+	// Outer columns were already derived by buildSharedProps.
+
+	// Functional Dependencies
+	// -----------------------
+	// Inherit functional dependencies from input. Unlike Limit, a limit of one
+	// row doesn't guarantee a single row, since there can be ties.
+	rel.FuncDeps.CopyFrom(&inputProps.FuncDeps)
+
+	// Cardinality
+	// -----------
+	// The ties can make the number of rows exceed the limit, so the limit only
+	// lowers the minimum number of rows.
+	rel.Cardinality = inputProps.Cardinality
+	if constLimit <= 0 {
+		rel.Cardinality = props.ZeroCardinality
+	} else if constLimit < math.MaxUint32 {
+		rel.Cardinality = rel.Cardinality.AsLowAs(uint32(constLimit))
+	}
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildLimitWithTies(limit, rel)
+	}
+}
+
 func (b *logicalPropsBuilder) buildOffsetProps(offset *OffsetExpr, rel *props.Relational) {
 	BuildSharedProps(b.mem, offset, &rel.Shared)
 
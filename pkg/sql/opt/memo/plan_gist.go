@@ -0,0 +1,90 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package memo
+
+import "github.com/cockroachdb/cockroach/pkg/sql/opt"
+
+// MakePlanGist returns the gist of the plan rooted at the given expression.
+// The expression must be part of the lowest cost tree of an optimized memo,
+// i.e. it must not have been reached through the NextExpr links of a group.
+func MakePlanGist(mem *Memo, e RelExpr) *opt.PlanGist {
+	g := &opt.PlanGist{Op: e.Op(), Args: PlanGistArgs(mem.Metadata(), e)}
+	PlanGistInputs(mem, e, func(in RelExpr) {
+		g.Inputs = append(g.Inputs, MakePlanGist(mem, in))
+	})
+	return g
+}
+
+// PlanGistArgs returns the arguments of the given expression in a PlanGist:
+// the tables and indexes it uses, and its join type.
+func PlanGistArgs(md *opt.Metadata, e RelExpr) []string {
+	switch t := e.(type) {
+	case *ScanExpr:
+		return []string{opt.PlanGistIndexArg(md, t.Table, t.Index)}
+
+	case *VirtualScanExpr:
+		return []string{opt.PlanGistTableArg(t.Table)}
+
+	case *IndexJoinExpr:
+		return []string{opt.PlanGistTableArg(t.Table)}
+
+	case *LookupJoinExpr:
+		return []string{t.JoinType.String(), opt.PlanGistIndexArg(md, t.Table, t.Index)}
+
+	case *MergeJoinExpr:
+		return []string{t.JoinType.String()}
+
+	case *ZigzagJoinExpr:
+		return []string{
+			opt.PlanGistIndexArg(md, t.LeftTable, t.LeftIndex),
+			opt.PlanGistIndexArg(md, t.RightTable, t.RightIndex),
+		}
+
+	case *InsertExpr:
+		return []string{opt.PlanGistTableArg(t.Table)}
+
+	case *UpdateExpr:
+		return []string{opt.PlanGistTableArg(t.Table)}
+
+	case *UpsertExpr:
+		return []string{opt.PlanGistTableArg(t.Table)}
+
+	case *DeleteExpr:
+		return []string{opt.PlanGistTableArg(t.Table)}
+	}
+	return nil
+}
+
+// PlanGistInputs calls fn with each relational input of the given
+// expression, in order. These are its relational children, as well as the
+// relational expressions of the subqueries in its scalar children.
+func PlanGistInputs(mem *Memo, e opt.Expr, fn func(RelExpr)) {
+	for i, n := 0, e.ChildCount(); i < n; i++ {
+		switch t := e.Child(i).(type) {
+		case RelExpr:
+			fn(t)
+
+		case ScalarPropsExpr:
+			// Skip scalar expressions without subqueries, which don't have
+			// relational inputs.
+			if t.ScalarProps(mem).HasSubquery {
+				PlanGistInputs(mem, t, fn)
+			}
+
+		default:
+			PlanGistInputs(mem, t, fn)
+		}
+	}
+}
@@ -19,8 +19,10 @@ import (
 	"reflect"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/constraint"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -30,6 +32,7 @@ import (
 )
 
 var statsAnnID = opt.NewTableAnnID()
+var exprStatsAnnID = opt.NewTableAnnID()
 
 // statisticsBuilder is responsible for building the statistics that are
 // used by the coster to estimate the cost of expressions.
@@ -326,6 +329,9 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, e RelExpr) *props.Column
 	case opt.LimitOp:
 		return sb.colStatLimit(colSet, e.(*LimitExpr))
 
+	case opt.LimitWithTiesOp:
+		return sb.colStatLimitWithTies(colSet, e.(*LimitWithTiesExpr))
+
 	case opt.OffsetOp:
 		return sb.colStatOffset(colSet, e.(*OffsetExpr))
 
@@ -433,25 +439,53 @@ func (sb *statisticsBuilder) makeTableStatistics(tabID opt.TableID) *props.Stati
 	// Make now and annotate the metadata table with it for next time.
 	tab := sb.md.Table(tabID)
 	stats = &props.Statistics{}
-	if tab.StatisticCount() == 0 {
-		// No statistics.
-		stats.RowCount = unknownRowCount
-	} else {
-		// Get the RowCount from the most recent statistic. Stats are ordered
-		// with most recent first.
-		stats.RowCount = float64(tab.Statistic(0).RowCount())
-
-		// Add all the column statistics, using the most recent statistic for each
-		// column set. Stats are ordered with most recent first.
-		for i := 0; i < tab.StatisticCount(); i++ {
-			stat := tab.Statistic(i)
-			var cols opt.ColSet
-			for i := 0; i < stat.ColumnCount(); i++ {
-				cols.Add(int(tabID.ColumnID(stat.ColumnOrdinal(i))))
+	stats.RowCount = unknownRowCount
+
+	// Add all the column statistics, using the most recent full statistic for
+	// each column set. Stats are ordered with most recent first, so the partial
+	// statistics collected since then, which count the values outside of the
+	// range seen by the full statistic (see CREATE STATISTICS ... USING
+	// EXTREMES), are found before it. The statistics on expressions are only
+	// used for the filters on the same expressions, see
+	// selectivityFromExpressionStats.
+	rowCountSet := false
+	newRowCount := 0.0
+	partialStats := make(map[string]cat.TableStatistic)
+	for i := 0; i < tab.StatisticCount(); i++ {
+		stat := tab.Statistic(i)
+		if stat.Predicate() == "" && !rowCountSet {
+			// Get the RowCount from the most recent full statistic, adding the
+			// rows inserted since then as counted by the partial statistics.
+			stats.RowCount = float64(stat.RowCount()) + newRowCount
+			rowCountSet = true
+		}
+		if stat.Expression() != "" {
+			continue
+		}
+		var cols opt.ColSet
+		for i := 0; i < stat.ColumnCount(); i++ {
+			cols.Add(int(tabID.ColumnID(stat.ColumnOrdinal(i))))
+		}
+		key := cols.String()
+		if stat.Predicate() != "" {
+			if _, ok := stats.ColStats.Lookup(cols); ok {
+				// The partial statistic predates the full statistic.
+				continue
 			}
-			if colStat, ok := stats.ColStats.Add(cols); ok {
-				colStat.DistinctCount = float64(stat.DistinctCount())
-				colStat.NullCount = float64(stat.NullCount())
+			if _, ok := partialStats[key]; !ok {
+				partialStats[key] = stat
+				if !rowCountSet {
+					newRowCount = max(newRowCount, float64(stat.RowCount()))
+				}
+			}
+			continue
+		}
+		if colStat, ok := stats.ColStats.Add(cols); ok {
+			colStat.DistinctCount = float64(stat.DistinctCount())
+			colStat.NullCount = float64(stat.NullCount())
+			if partial, ok := partialStats[key]; ok {
+				colStat.DistinctCount += float64(partial.DistinctCount())
+				colStat.NullCount += float64(partial.NullCount())
 			}
 		}
 	}
@@ -519,6 +553,14 @@ func (sb *statisticsBuilder) buildScan(scan *ScanExpr, relProps *props.Relationa
 		s.ApplySelectivity(sb.selectivityFromNullCounts(cols, scan, s, inputRowCount))
 	}
 
+	if scan.Sample != nil {
+		// Apply the sampling percentage, unless it is only known at execution
+		// time (e.g. when it is a placeholder).
+		if pct, ok := scan.Sample.Percent.(*tree.DFloat); ok && *pct >= 0 && *pct <= 100 {
+			s.ApplySelectivity(float64(*pct) / 100)
+		}
+	}
+
 	sb.finalizeFromCardinality(relProps)
 }
 
@@ -593,7 +635,7 @@ func (sb *statisticsBuilder) buildSelect(sel *SelectExpr, relProps *props.Relati
 
 	// Calculate distinct counts for constrained columns
 	// -------------------------------------------------
-	numUnappliedConjuncts, constrainedCols := sb.applyFilter(sel.Filters, sel, relProps)
+	numUnappliedConjuncts, constrainedCols, exprSelectivity := sb.applyFilter(sel.Filters, sel, relProps)
 
 	// Try to reduce the number of columns used for selectivity
 	// calculation based on functional dependencies.
@@ -609,6 +651,7 @@ func (sb *statisticsBuilder) buildSelect(sel *SelectExpr, relProps *props.Relati
 	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, sel, s))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &relProps.FuncDeps, sel, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
+	s.ApplySelectivity(exprSelectivity)
 
 	// Update distinct counts based on equivalencies; this should happen after
 	// selectivityFromDistinctCounts and selectivityFromEquivalencies.
@@ -802,7 +845,7 @@ func (sb *statisticsBuilder) buildJoin(
 
 	// Calculate distinct counts for constrained columns in the ON conditions
 	// ----------------------------------------------------------------------
-	numUnappliedConjuncts, constrainedCols := sb.applyFilter(h.filters, join, relProps)
+	numUnappliedConjuncts, constrainedCols, exprSelectivity := sb.applyFilter(h.filters, join, relProps)
 
 	// Try to reduce the number of columns used for selectivity
 	// calculation based on functional dependencies.
@@ -822,6 +865,7 @@ func (sb *statisticsBuilder) buildJoin(
 	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, join, s))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &h.filtersFD, join, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
+	s.ApplySelectivity(exprSelectivity)
 
 	// Update distinct counts based on equivalencies; this should happen after
 	// selectivityFromDistinctCounts and selectivityFromEquivalencies.
@@ -1283,7 +1327,7 @@ func (sb *statisticsBuilder) buildZigzagJoin(
 	// still have corresponding filters in zigzag.On. So we don't need
 	// to iterate through FixedCols here if we are already processing the ON
 	// clause.
-	numUnappliedConjuncts, constrainedCols := sb.applyFilter(zigzag.On, zigzag, relProps)
+	numUnappliedConjuncts, constrainedCols, exprSelectivity := sb.applyFilter(zigzag.On, zigzag, relProps)
 
 	// Application of constraints on inverted indexes needs to be handled a
 	// little differently since a constraint on an inverted index key column
@@ -1321,6 +1365,7 @@ func (sb *statisticsBuilder) buildZigzagJoin(
 	s.ApplySelectivity(sb.selectivityFromDistinctCounts(constrainedCols, zigzag, s))
 	s.ApplySelectivity(sb.selectivityFromEquivalencies(equivReps, &relProps.FuncDeps, zigzag, s))
 	s.ApplySelectivity(sb.selectivityFromUnappliedConjuncts(numUnappliedConjuncts))
+	s.ApplySelectivity(exprSelectivity)
 
 	// Update distinct counts based on equivalencies; this should happen after
 	// selectivityFromDistinctCounts and selectivityFromEquivalencies.
@@ -1626,6 +1671,53 @@ func (sb *statisticsBuilder) colStatLimit(
 	return colStat
 }
 
+// +---------------+
+// | LimitWithTies |
+// +---------------+
+
+func (sb *statisticsBuilder) buildLimitWithTies(
+	limit *LimitWithTiesExpr, relProps *props.Relational,
+) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	inputStats := &limit.Input.Relational().Stats
+
+	// Copy row count from input.
+	s.RowCount = inputStats.RowCount
+
+	// Update row count if limit is a constant and row count is non-zero. The
+	// ties are ignored: they are expected to be few.
+	if cnst, ok := limit.Limit.(*ConstExpr); ok && inputStats.RowCount > 0 {
+		hardLimit := *cnst.Value.(*tree.DInt)
+		if hardLimit > 0 {
+			s.RowCount = min(float64(hardLimit), inputStats.RowCount)
+			s.Selectivity = s.RowCount / inputStats.RowCount
+		}
+	}
+
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatLimitWithTies(
+	colSet opt.ColSet, limit *LimitWithTiesExpr,
+) *props.ColumnStatistic {
+	relProps := limit.Relational()
+	s := &relProps.Stats
+	inputStats := &limit.Input.Relational().Stats
+	colStat := sb.copyColStatFromChild(colSet, limit, s)
+
+	// Scale distinct count based on the selectivity of the limit operation.
+	colStat.ApplySelectivity(s.Selectivity, inputStats.RowCount)
+	if colSet.SubsetOf(relProps.NotNullCols) {
+		colStat.NullCount = 0
+	}
+	return colStat
+}
+
 // +--------+
 // | Offset |
 // +--------+
@@ -2136,9 +2228,15 @@ func countJSONPaths(conjunct *FiltersItem) int {
 // Equalities between two variables (e.g., var1=var2) are handled separately.
 // See applyEquivalencies and selectivityFromEquivalencies for details.
 //
+// The filters without constraints which compare an expression with constants
+// are estimated with the statistics on that expression, if any. Their
+// combined selectivity is returned as exprSelectivity. See
+// selectivityFromExpressionStats for details.
+//
 func (sb *statisticsBuilder) applyFilter(
 	filters FiltersExpr, e RelExpr, relProps *props.Relational,
-) (numUnappliedConjuncts float64, constrainedCols opt.ColSet) {
+) (numUnappliedConjuncts float64, constrainedCols opt.ColSet, exprSelectivity float64) {
+	exprSelectivity = 1
 	applyConjunct := func(conjunct *FiltersItem) {
 		if isEqualityWithTwoVars(conjunct.Condition) {
 			// We'll handle equalities later.
@@ -2180,6 +2278,10 @@ func (sb *statisticsBuilder) applyFilter(
 			} else {
 				numUnappliedConjuncts += n
 			}
+		} else if sel, ok := sb.selectivityFromExpressionStats(
+			conjunct.Condition, scalarProps.OuterCols,
+		); ok {
+			exprSelectivity *= sel
 		} else {
 			numUnappliedConjuncts++
 		}
@@ -2189,7 +2291,7 @@ func (sb *statisticsBuilder) applyFilter(
 		applyConjunct(&filters[i])
 	}
 
-	return numUnappliedConjuncts, constrainedCols
+	return numUnappliedConjuncts, constrainedCols, exprSelectivity
 }
 
 func (sb *statisticsBuilder) applyIndexConstraint(
@@ -2608,6 +2710,174 @@ func (sb *statisticsBuilder) selectivityFromUnappliedConjuncts(
 	return math.Pow(unknownFilterSelectivity, numUnappliedConjuncts)
 }
 
+// exprStat is a statistic on an expression, with the parsed expression.
+type exprStat struct {
+	expr tree.Expr
+	stat cat.TableStatistic
+}
+
+// tableExpressionStats returns the full statistics on expressions of the given
+// table, most recent first. They are parsed lazily and cached in the metadata.
+func (sb *statisticsBuilder) tableExpressionStats(tabID opt.TableID) []exprStat {
+	if stats, ok := sb.md.TableAnnotation(tabID, exprStatsAnnID).([]exprStat); ok {
+		// Already parsed.
+		return stats
+	}
+	tab := sb.md.Table(tabID)
+	stats := make([]exprStat, 0)
+	for i := 0; i < tab.StatisticCount(); i++ {
+		stat := tab.Statistic(i)
+		if stat.Expression() == "" || stat.Predicate() != "" {
+			continue
+		}
+		expr, err := parser.ParseExpr(stat.Expression())
+		if err != nil {
+			// The statistic is ignored: the filters on its expression are
+			// estimated as if it did not exist.
+			continue
+		}
+		stats = append(stats, exprStat{expr: expr, stat: stat})
+	}
+	sb.md.SetTableAnnotation(tabID, exprStatsAnnID, stats)
+	return stats
+}
+
+// selectivityFromExpressionStats returns the selectivity of a conjunct of the
+// form expr = const, expr IN (consts...), expr IS NULL or expr IS NOT NULL,
+// where expr is an expression on the columns of a table with a statistic on
+// that expression, e.g. lower(s) = 'foo' after CREATE STATISTICS ON
+// (lower(s)). Such conjuncts have no constraints, and would otherwise be
+// estimated with unknownFilterSelectivity. It returns ok=false if there is no
+// matching statistic.
+//
+// In the absence of histograms, the values are assumed to be distributed
+// uniformly, so the selectivity of an equality is the fraction of non-null
+// values divided by the distinct count of the expression.
+func (sb *statisticsBuilder) selectivityFromExpressionStats(
+	cond opt.ScalarExpr, outerCols opt.ColSet,
+) (selectivity float64, ok bool) {
+	var expr opt.ScalarExpr
+	var numValues int
+	switch t := cond.(type) {
+	case *EqExpr:
+		if opt.IsConstValueOp(t.Right) {
+			expr, numValues = t.Left, 1
+		} else if opt.IsConstValueOp(t.Left) {
+			expr, numValues = t.Right, 1
+		}
+	case *InExpr:
+		if CanExtractConstTuple(t.Right) {
+			expr, numValues = t.Left, len(t.Right.(*TupleExpr).Elems)
+		}
+	case *IsExpr, *IsNotExpr:
+		if cond.Child(1).Op() == opt.NullOp {
+			expr = cond.Child(0).(opt.ScalarExpr)
+		}
+	}
+	if expr == nil || expr.Op() == opt.VariableOp || outerCols.Empty() {
+		return 0, false
+	}
+
+	// The expression must only refer to the columns of a single table.
+	col, _ := outerCols.Next(0)
+	tabID := sb.md.ColumnMeta(opt.ColumnID(col)).Table
+	if tabID == 0 {
+		return 0, false
+	}
+	for c, ok := outerCols.Next(0); ok; c, ok = outerCols.Next(c + 1) {
+		if sb.md.ColumnMeta(opt.ColumnID(c)).Table != tabID {
+			return 0, false
+		}
+	}
+
+	for _, s := range sb.tableExpressionStats(tabID) {
+		if !sb.matchStatExpr(s.expr, expr, tabID) {
+			continue
+		}
+		rowCount := float64(s.stat.RowCount())
+		if rowCount == 0 {
+			return 0, true
+		}
+		nullFrac := min(float64(s.stat.NullCount())/rowCount, 1)
+		switch cond.Op() {
+		case opt.IsOp:
+			return nullFrac, true
+		case opt.IsNotOp:
+			return 1 - nullFrac, true
+		}
+		distinctCount := max(float64(s.stat.DistinctCount()), 1)
+		return min(float64(numValues)*(1-nullFrac)/distinctCount, 1), true
+	}
+	return 0, false
+}
+
+// matchStatExpr returns true if the parsed expression of a statistic on table
+// tabID computes the same value as the given scalar expression. Its column
+// references are resolved by name against the columns of the table.
+func (sb *statisticsBuilder) matchStatExpr(
+	statExpr tree.Expr, e opt.ScalarExpr, tabID opt.TableID,
+) bool {
+	switch t := statExpr.(type) {
+	case *tree.ParenExpr:
+		return sb.matchStatExpr(t.Expr, e, tabID)
+
+	case *tree.UnresolvedName:
+		v, ok := e.(*VariableExpr)
+		if !ok || t.NumParts != 1 || sb.md.ColumnMeta(v.Col).Table != tabID {
+			return false
+		}
+		col := sb.md.Table(tabID).Column(tabID.ColumnOrdinal(v.Col))
+		return string(col.ColName()) == t.Parts[0]
+
+	case tree.Constant:
+		if !opt.IsConstValueOp(e) {
+			return false
+		}
+		d := ExtractConstDatum(e)
+		if d == tree.DNull {
+			return false
+		}
+		statDatum, err := t.ResolveAsType(&tree.SemaContext{}, e.DataType())
+		if err != nil {
+			return false
+		}
+		return statDatum.ResolvedType().Equivalent(d.ResolvedType()) &&
+			statDatum.Compare(sb.evalCtx, d) == 0
+
+	case *tree.FuncExpr:
+		f, ok := e.(*FunctionExpr)
+		if !ok || t.Type != 0 || t.Filter != nil || t.WindowDef != nil ||
+			len(t.Exprs) != len(f.Args) || t.Func.String() != f.Name {
+			return false
+		}
+		for i := range t.Exprs {
+			if !sb.matchStatExpr(t.Exprs[i], f.Args[i], tabID) {
+				return false
+			}
+		}
+		return true
+
+	case *tree.CastExpr:
+		c, ok := e.(*CastExpr)
+		return ok && t.Type.String() == c.TargetTyp.String() &&
+			sb.matchStatExpr(t.Expr, c.Input, tabID)
+
+	case *tree.BinaryExpr:
+		if op, ok := opt.BinaryOpReverseMap[e.Op()]; !ok || op != t.Operator {
+			return false
+		}
+		return sb.matchStatExpr(t.Left, e.Child(0).(opt.ScalarExpr), tabID) &&
+			sb.matchStatExpr(t.Right, e.Child(1).(opt.ScalarExpr), tabID)
+
+	case *tree.UnaryExpr:
+		if op, ok := opt.UnaryOpReverseMap[e.Op()]; !ok || op != t.Operator {
+			return false
+		}
+		return sb.matchStatExpr(t.Expr, e.Child(0).(opt.ScalarExpr), tabID)
+	}
+	return false
+}
+
 // tryReduceCols is used to determine which columns to use for selectivity
 // calculation.
 //
@@ -15,6 +15,7 @@
 package memo
 
 import (
+	"math"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
@@ -23,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/props"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/testutils/testcat"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
 )
 
@@ -250,6 +252,111 @@ func TestGetStatsFromConstraint(t *testing.T) {
 	)
 }
 
+// Test that the partial statistics are merged into the full statistics, and
+// that the statistics on expressions are used for the filters on the same
+// expressions.
+func TestExpressionAndPartialStats(t *testing.T) {
+	evalCtx := tree.MakeTestingEvalContext(cluster.MakeTestingClusterSettings())
+
+	catalog := testcat.New()
+	if _, err := catalog.ExecuteDDL("CREATE TABLE t (a INT, s STRING)"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := catalog.ExecuteDDL(
+		`ALTER TABLE t INJECT STATISTICS '[
+		{
+			"columns": ["a"],
+			"created_at": "2018-01-01 1:00:00.00000+00:00",
+			"row_count": 1000,
+			"distinct_count": 100
+		},
+		{
+			"columns": ["s"],
+			"created_at": "2018-01-01 1:00:00.00000+00:00",
+			"row_count": 1000,
+			"distinct_count": 500
+		},
+		{
+			"columns": ["s"],
+			"created_at": "2018-01-01 1:30:00.00000+00:00",
+			"row_count": 1000,
+			"distinct_count": 10,
+			"null_count": 100,
+			"expression": "lower(s)"
+		},
+		{
+			"columns": ["a"],
+			"created_at": "2018-01-01 2:00:00.00000+00:00",
+			"row_count": 200,
+			"distinct_count": 50,
+			"predicate": "(a < 1) OR (a > 100)"
+		}
+	]'`); err != nil {
+		t.Fatal(err)
+	}
+
+	var mem Memo
+	mem.Init(&evalCtx)
+	tab := catalog.Table(tree.NewUnqualifiedTableName("t"))
+	tabID := mem.Metadata().AddTable(tab)
+	aCol, sCol := tabID.ColumnID(0), tabID.ColumnID(1)
+
+	sb := &statisticsBuilder{}
+	sb.init(&evalCtx, mem.Metadata())
+
+	// The rows counted by the partial statistic on a are added to the most
+	// recent full statistic, which is the statistic on lower(s).
+	stats := sb.makeTableStatistics(tabID)
+	if stats.RowCount != 1200 {
+		t.Fatalf("expected 1200 rows, found %g", stats.RowCount)
+	}
+	colStat, ok := stats.ColStats.Lookup(util.MakeFastIntSet(int(aCol)))
+	if !ok || colStat.DistinctCount != 150 {
+		t.Fatalf("expected 150 distinct values of a, found %+v", colStat)
+	}
+	colStat, ok = stats.ColStats.Lookup(util.MakeFastIntSet(int(sCol)))
+	if !ok || colStat.DistinctCount != 500 {
+		t.Fatalf("expected 500 distinct values of s, found %+v", colStat)
+	}
+
+	fn := func(name string) opt.ScalarExpr {
+		return &FunctionExpr{
+			Args:            ScalarListExpr{&VariableExpr{Col: sCol}},
+			FunctionPrivate: FunctionPrivate{Name: name, Typ: types.String},
+		}
+	}
+	str := func(s string) opt.ScalarExpr {
+		return &ConstExpr{Value: tree.NewDString(s), Typ: types.String}
+	}
+	testCases := []struct {
+		cond        opt.ScalarExpr
+		ok          bool
+		selectivity float64
+	}{
+		{cond: &EqExpr{Left: fn("lower"), Right: str("foo")}, ok: true, selectivity: 0.09},
+		{cond: &EqExpr{Left: str("foo"), Right: fn("lower")}, ok: true, selectivity: 0.09},
+		{
+			cond:        &InExpr{Left: fn("lower"), Right: &TupleExpr{Elems: ScalarListExpr{str("a"), str("b")}}},
+			ok:          true,
+			selectivity: 0.18,
+		},
+		{cond: &IsExpr{Left: fn("lower"), Right: &NullExpr{Typ: types.Unknown}}, ok: true, selectivity: 0.1},
+		{cond: &IsNotExpr{Left: fn("lower"), Right: &NullExpr{Typ: types.Unknown}}, ok: true, selectivity: 0.9},
+		{cond: &EqExpr{Left: fn("upper"), Right: str("foo")}, ok: false},
+		{cond: &EqExpr{Left: &VariableExpr{Col: sCol}, Right: str("foo")}, ok: false},
+	}
+	for i, tc := range testCases {
+		selectivity, ok := sb.selectivityFromExpressionStats(tc.cond, util.MakeFastIntSet(int(sCol)))
+		if ok != tc.ok {
+			t.Fatalf("%d: expected ok=%t, found %t", i, tc.ok, ok)
+		}
+		if ok && math.Abs(selectivity-tc.selectivity) > 1e-9 {
+			t.Fatalf("%d: expected selectivity %g, found %g", i, tc.selectivity, selectivity)
+		}
+	}
+}
+
 func TestTranslateColSet(t *testing.T) {
 	test := func(t *testing.T, colSetIn opt.ColSet, from opt.ColList, to opt.ColList, expected opt.ColSet) {
 		t.Helper()
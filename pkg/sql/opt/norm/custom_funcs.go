@@ -283,6 +283,74 @@ func (c *CustomFuncs) HasZeroOrOneRow(input memo.RelExpr) bool {
 	return input.Relational().Cardinality.IsZeroOrOne()
 }
 
+// HasZeroOrOneRowPerOuterRow returns true if the input expression returns at
+// most one row for each combination of values of its outer columns. This is
+// the case when the input is a Select (possibly wrapped in projections) whose
+// filters equate the columns of a key of its input with expressions that only
+// depend on the outer columns. For example:
+//
+//   SELECT (SELECT y FROM xy WHERE x = a.k + 1) FROM a
+//
+// The outer column a.k has a single value for each evaluation of the
+// subquery, so x is constant as well, and since x is a key of xy, the
+// subquery returns at most one row. The key can also be a lax key, since the
+// equalities reject nulls.
+func (c *CustomFuncs) HasZeroOrOneRowPerOuterRow(input memo.RelExpr) bool {
+	// Projections don't change the number of rows.
+	for {
+		project, ok := input.(*memo.ProjectExpr)
+		if !ok {
+			break
+		}
+		input = project.Input
+	}
+	sel, ok := input.(*memo.SelectExpr)
+	if !ok {
+		return false
+	}
+
+	inputCols := sel.Input.Relational().OutputCols
+	var constCols opt.ColSet
+	for i := range sel.Filters {
+		eq, ok := sel.Filters[i].Condition.(*memo.EqExpr)
+		if !ok {
+			continue
+		}
+		if col, ok := c.outerConstCol(eq.Left, eq.Right, inputCols); ok {
+			constCols.Add(int(col))
+		} else if col, ok := c.outerConstCol(eq.Right, eq.Left, inputCols); ok {
+			constCols.Add(int(col))
+		}
+	}
+	if constCols.Empty() {
+		return false
+	}
+
+	var fd props.FuncDepSet
+	fd.CopyFrom(&sel.Relational().FuncDeps)
+	fd.AddConstants(constCols)
+	fd.MakeNotNull(constCols)
+	return fd.HasMax1Row()
+}
+
+// outerConstCol returns the column referenced by the given variable if it is
+// one of the input columns and if the given value only depends on outer
+// columns (and so is constant for each evaluation of the expression).
+func (c *CustomFuncs) outerConstCol(
+	variable, value opt.ScalarExpr, inputCols opt.ColSet,
+) (_ opt.ColumnID, ok bool) {
+	v, ok := variable.(*memo.VariableExpr)
+	if !ok || !inputCols.Contains(int(v.Col)) {
+		return 0, false
+	}
+	var shared props.Shared
+	memo.BuildSharedProps(c.mem, value, &shared)
+	if shared.OuterCols.Intersects(inputCols) || shared.HasSubquery || shared.CanHaveSideEffects {
+		return 0, false
+	}
+	return v.Col, true
+}
+
 // CanHaveZeroRows returns true if the input expression might return zero rows.
 func (c *CustomFuncs) CanHaveZeroRows(input memo.RelExpr) bool {
 	return input.Relational().Cardinality.CanBeZero()
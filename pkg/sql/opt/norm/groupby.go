@@ -191,6 +191,8 @@ func (c *CustomFuncs) replaceAggInputVar(agg opt.ScalarExpr, v opt.ScalarExpr) o
 		return c.f.DynamicConstruct(agg.Op(), v).(opt.ScalarExpr)
 	case 2:
 		return c.f.DynamicConstruct(agg.Op(), v, agg.Child(1)).(opt.ScalarExpr)
+	case 3:
+		return c.f.DynamicConstruct(agg.Op(), v, agg.Child(1), agg.Child(2)).(opt.ScalarExpr)
 	default:
 		panic(pgerror.NewAssertionErrorf("unhandled number of aggregate children"))
 	}
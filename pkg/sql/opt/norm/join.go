@@ -267,11 +267,13 @@ func (c *CustomFuncs) GetEquivColsWithEquivType(
 		return res
 	}
 
-	// Compute all equivalent columns.
-	eqCols := util.MakeFastIntSet(int(col))
-	for i := range filters {
-		eqCols = filters[i].ScalarProps(c.mem).FuncDeps.ComputeEquivClosure(eqCols)
-	}
+	// Compute all equivalent columns. The equivalencies of all the filters
+	// are combined first, so that transitive equalities spanning several
+	// conjuncts (e.g. a=b AND c=d AND b=c) are taken into account regardless
+	// of the order of the conjuncts.
+	var equivFD props.FuncDepSet
+	c.addEquivFromFilters(filters, nil /* skip */, &equivFD)
+	eqCols := equivFD.ComputeEquivGroup(col)
 
 	eqCols.ForEach(func(i int) {
 		// Only include columns that have the same type as col.
@@ -284,6 +286,70 @@ func (c *CustomFuncs) GetEquivColsWithEquivType(
 	return res
 }
 
+// addEquivFromFilters adds the equivalencies implied by each of the given
+// filters, except for the skip item (if non-nil), to fdset.
+func (c *CustomFuncs) addEquivFromFilters(
+	filters memo.FiltersExpr, skip *memo.FiltersItem, fdset *props.FuncDepSet,
+) {
+	for i := range filters {
+		if &filters[i] != skip {
+			fdset.AddEquivFrom(&filters[i].ScalarProps(c.mem).FuncDeps)
+		}
+	}
+}
+
+// IsRedundantJoinEquality returns true if the given join filter item, which
+// must be an equality between two columns, is implied by the equivalencies of
+// the join inputs and of the other join filters. For example, in:
+//
+//   SELECT * FROM a JOIN b ON a.x=b.x AND a.y=b.y AND a.x=a.y AND b.x=b.y
+//
+// any one of the four equalities is implied by the other three. The item is
+// only considered redundant if the columns it equates are part of a group of
+// equivalent columns which are guaranteed to be not null, since equivalencies
+// also hold between NULL values while the equality filter does not.
+func (c *CustomFuncs) IsRedundantJoinEquality(
+	left, right memo.RelExpr, on memo.FiltersExpr, item *memo.FiltersItem,
+) bool {
+	eq := item.Condition.(*memo.EqExpr)
+	leftCol := eq.Left.(*memo.VariableExpr).Col
+	rightCol := eq.Right.(*memo.VariableExpr).Col
+	if leftCol == rightCol {
+		// An x=x filter is not implied by other equalities; it only filters out
+		// NULL values of x.
+		return false
+	}
+
+	// Comparisons between columns of different types may not be transitive,
+	// so only equalities between columns of the same type are removed.
+	md := c.f.Metadata()
+	if !md.ColumnMeta(leftCol).Type.Equivalent(md.ColumnMeta(rightCol).Type) {
+		return false
+	}
+
+	leftProps := left.Relational()
+	rightProps := right.Relational()
+	var equivFD props.FuncDepSet
+	equivFD.AddEquivFrom(&leftProps.FuncDeps)
+	equivFD.AddEquivFrom(&rightProps.FuncDeps)
+	c.addEquivFromFilters(on, item, &equivFD)
+	group := equivFD.ComputeEquivGroup(leftCol)
+	if !group.Contains(int(rightCol)) {
+		return false
+	}
+
+	notNullCols := leftProps.NotNullCols.Union(rightProps.NotNullCols)
+	for i := range on {
+		if &on[i] == item {
+			continue
+		}
+		if constraints := on[i].ScalarProps(c.mem).Constraints; constraints != nil {
+			notNullCols.UnionWith(constraints.ExtractNotNullCols(c.f.evalCtx))
+		}
+	}
+	return group.Intersects(notNullCols)
+}
+
 // eqConditionsToColMap returns a map of left columns to right columns
 // that are being equated in the specified conditions. leftCols is used
 // to identify which column is a left column.
@@ -527,8 +593,9 @@ func (c *CustomFuncs) deriveUnfilteredCols(in memo.RelExpr) opt.ColSet {
 	// TODO(andyk): Could add other cases, such as outer joins and union.
 	switch t := in.(type) {
 	case *memo.ScanExpr:
-		// All un-limited, unconstrained output columns are unfiltered columns.
-		if t.HardLimit == 0 && t.Constraint == nil {
+		// All un-limited, unconstrained and unsampled output columns are
+		// unfiltered columns.
+		if t.HardLimit == 0 && t.Constraint == nil && !t.IsSampled() {
 			relational.Rule.UnfilteredCols = relational.OutputCols
 		}
 
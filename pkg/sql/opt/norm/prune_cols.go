@@ -81,6 +81,17 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 	var cols opt.ColSet
 	tabMeta := c.mem.Metadata().TableMeta(private.Table)
 
+	// Triggers can reference any column of the OLD row, so none of the
+	// FetchCols can be pruned.
+	if tabMeta.Table.HasTriggers() {
+		for ord, col := range private.FetchCols {
+			if col != 0 {
+				cols.Add(int(tabMeta.MetaID.ColumnID(ord)))
+			}
+		}
+		return cols
+	}
+
 	// familyCols returns the columns in the given family.
 	familyCols := func(fam cat.Family) opt.ColSet {
 		var colSet opt.ColSet
@@ -429,7 +440,7 @@ func DerivePruneCols(e memo.RelExpr) opt.ColSet {
 			relProps.Rule.PruneCols = relProps.OutputCols.Difference(groupingColSet)
 		}
 
-	case opt.LimitOp, opt.OffsetOp:
+	case opt.LimitOp, opt.LimitWithTiesOp, opt.OffsetOp:
 		// Any pruneable input columns can potentially be pruned, as long as
 		// they're not used as an ordering column.
 		inputPruneCols := DerivePruneCols(e.Child(0).(memo.RelExpr))
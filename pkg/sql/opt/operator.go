@@ -192,6 +192,9 @@ var AggregateOpReverseMap = map[Operator]string{
 	JsonAggOp:         "json_agg",
 	JsonbAggOp:        "jsonb_agg",
 	StringAggOp:       "string_agg",
+	PercentileDiscOp:  "percentile_disc_impl",
+	PercentileContOp:  "percentile_cont_impl",
+	ModeOp:            "mode_impl",
 	ConstAggOp:        "any_not_null",
 	ConstNotNullAggOp: "any_not_null",
 	AnyNotNullAggOp:   "any_not_null",
@@ -254,7 +257,7 @@ func AggregateIgnoresNulls(op Operator) bool {
 	switch op {
 	case AvgOp, BoolAndOp, BoolOrOp, CountOp, MaxOp, MinOp, SumIntOp, SumOp,
 		SqrDiffOp, VarianceOp, StdDevOp, XorAggOp, ConstNotNullAggOp,
-		AnyNotNullAggOp, StringAggOp:
+		AnyNotNullAggOp, StringAggOp, PercentileDiscOp, PercentileContOp, ModeOp:
 		return true
 	}
 	return false
@@ -269,7 +272,8 @@ func AggregateIsNullOnEmpty(op Operator) bool {
 	switch op {
 	case AvgOp, BoolAndOp, BoolOrOp, MaxOp, MinOp, SumIntOp, SumOp, SqrDiffOp,
 		VarianceOp, StdDevOp, XorAggOp, ConstAggOp, ConstNotNullAggOp, ArrayAggOp,
-		ConcatAggOp, JsonAggOp, JsonbAggOp, AnyNotNullAggOp, StringAggOp:
+		ConcatAggOp, JsonAggOp, JsonbAggOp, AnyNotNullAggOp, StringAggOp,
+		PercentileDiscOp, PercentileContOp, ModeOp:
 		return true
 	}
 	return false
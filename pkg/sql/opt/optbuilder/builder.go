@@ -17,6 +17,7 @@ package optbuilder
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/optgen/exprgen"
@@ -98,9 +99,24 @@ type Builder struct {
 	// are referenced multiple times in the same query.
 	views map[cat.View]*tree.Select
 
+	// colPrivErrs contains, for tables on which the current user only has
+	// column-level SELECT privileges, the error to raise when each column is
+	// referenced (nil if the column can be referenced). It is indexed by the
+	// ordinal of the column.
+	colPrivErrs map[opt.TableID][]error
+
 	// subquery contains a pointer to the subquery which is currently being built
 	// (if any).
 	subquery *subquery
+
+	// locking contains the row-level locking items which apply to the data
+	// sources being built (if any). See lockingSpec.
+	locking lockingSpec
+
+	// sample contains the type checked TABLESAMPLE clause of the data source
+	// being built (if any). It only applies to the table scanned directly by
+	// the data source, and is consumed by buildScan.
+	sample *tree.TableSample
 }
 
 // New creates a new Builder structure initialized with the given
@@ -43,7 +43,7 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 	}
 
 	if del.With != nil {
-		inScope = b.buildCTE(del.With.CTEList, inScope)
+		inScope = b.buildCTE(del.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -53,16 +53,16 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(del.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab, resName := b.resolveTable(tn, privilege.DELETE)
+	ds, resName := b.resolveMutationTarget(tn, privilege.DELETE)
 	if alias == nil {
 		alias = &resName
 	}
 
 	// Check Select permission as well, since existing values must be read.
-	b.checkPrivilege(tn, tab, privilege.SELECT)
+	b.checkPrivilege(tn, ds, privilege.SELECT)
 
 	var mb mutationBuilder
-	mb.init(b, opt.DeleteOp, tab, *alias)
+	mb.init(b, opt.DeleteOp, ds, *alias)
 
 	// Build the input expression that selects the rows that will be deleted:
 	//
@@ -65,6 +65,10 @@ func (b *Builder) buildExplain(explain *tree.Explain, inScope *scope) (outScope
 		}
 		cols = sqlbase.ExplainOptColumns
 
+	case tree.ExplainGist:
+		telemetry.Inc(sqltelemetry.ExplainGistUseCounter)
+		cols = sqlbase.ExplainOptColumns
+
 	default:
 		panic(pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
 			"EXPLAIN ANALYZE does not support RETURNING NOTHING statements"))
@@ -543,14 +543,31 @@ func (b *Builder) constructAggregate(name string, args []opt.ScalarExpr) opt.Sca
 	case "jsonb_agg":
 		return b.factory.ConstructJsonbAgg(args[0])
 	case "string_agg":
-		if !memo.CanExtractConstDatum(args[1]) {
+		b.checkConstAggregateArgs(args)
+		return b.factory.ConstructStringAgg(args[0], args[1])
+	case "percentile_disc_impl":
+		b.checkConstAggregateArgs(args)
+		return b.factory.ConstructPercentileDisc(args[0], args[1], args[2])
+	case "percentile_cont_impl":
+		b.checkConstAggregateArgs(args)
+		return b.factory.ConstructPercentileCont(args[0], args[1], args[2])
+	case "mode_impl":
+		b.checkConstAggregateArgs(args)
+		return b.factory.ConstructMode(args[0], args[1])
+	}
+	panic(fmt.Sprintf("unhandled aggregate: %s", name))
+}
+
+// checkConstAggregateArgs checks that the arguments of an aggregate following
+// the first one are constant.
+func (b *Builder) checkConstAggregateArgs(args []opt.ScalarExpr) {
+	for _, arg := range args[1:] {
+		if !memo.CanExtractConstDatum(arg) {
 			panic(builderError{
 				fmt.Errorf("unimplemented: aggregate functions with multiple non-constant expressions are not supported"),
 			})
 		}
-		return b.factory.ConstructStringAgg(args[0], args[1])
 	}
-	panic(fmt.Sprintf("unhandled aggregate: %s", name))
 }
 
 func isAggregate(def *tree.FunctionDefinition) bool {
@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 )
 
@@ -140,7 +141,7 @@ func init() {
 // and thereby scrambles the input ordering.
 func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope) {
 	if ins.With != nil {
-		inScope = b.buildCTE(ins.With.CTEList, inScope)
+		inScope = b.buildCTE(ins.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -150,7 +151,7 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(ins.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab, resName := b.resolveTable(tn, privilege.INSERT)
+	ds, resName := b.resolveMutationTarget(tn, privilege.INSERT)
 	if alias == nil {
 		alias = &resName
 	}
@@ -158,20 +159,25 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	if ins.OnConflict != nil {
 		// UPSERT and INDEX ON CONFLICT will read from the table to check for
 		// duplicates.
-		b.checkPrivilege(tn, tab, privilege.SELECT)
+		b.checkPrivilege(tn, ds, privilege.SELECT)
 
 		if !ins.OnConflict.DoNothing {
 			// UPSERT and INDEX ON CONFLICT DO UPDATE may modify rows if the
 			// DO NOTHING clause is not present.
-			b.checkPrivilege(tn, tab, privilege.UPDATE)
+			b.checkPrivilege(tn, ds, privilege.UPDATE)
 		}
 	}
 
 	var mb mutationBuilder
 	if ins.OnConflict != nil && ins.OnConflict.IsUpsertAlias() {
-		mb.init(b, opt.UpsertOp, tab, *alias)
+		mb.init(b, opt.UpsertOp, ds, *alias)
 	} else {
-		mb.init(b, opt.InsertOp, tab, *alias)
+		mb.init(b, opt.InsertOp, ds, *alias)
+	}
+
+	if mb.view != nil && ins.OnConflict != nil {
+		panic(viewNotUpdatableError(mb.view.view, mb.op,
+			"UPSERT and INSERT ... ON CONFLICT are not supported on views."))
 	}
 
 	// Compute target columns in two cases:
@@ -223,6 +229,10 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 		mb.buildInputForInsert(inScope, nil /* rows */)
 	}
 
+	// Check or ignore the values provided for identity columns, depending on
+	// the OVERRIDING clause.
+	mb.applyOverridingForInsert(ins)
+
 	// Add default columns that were not explicitly specified by name or
 	// implicitly targeted by input columns. This includes columns undergoing
 	// write mutations, if they have a default value.
@@ -259,11 +269,17 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 		// insert rows that are not filtered.
 		mb.buildInsert(returning)
 
-	// Case 3: UPSERT statement.
-	case ins.OnConflict.IsUpsertAlias():
+	// Case 3: UPSERT statement, or an INSERT..ON CONFLICT..DO UPDATE statement
+	// that is equivalent to one.
+	case ins.OnConflict.IsUpsertAlias() || mb.isUpsertEquivalent(ins.OnConflict):
 		// Add columns which will be updated by the Upsert when a conflict occurs.
-		// These are derived from the insert columns.
-		mb.setUpsertCols(ins.Columns)
+		// These are derived from the insert columns, or from the SET clause if
+		// it was specified.
+		upsertCols := ins.Columns
+		if !ins.OnConflict.IsUpsertAlias() {
+			upsertCols, _ = ins.OnConflict.ExcludedPassthroughCols()
+		}
+		mb.setUpsertCols(upsertCols)
 
 		// Check whether the existing rows need to be fetched in order to detect
 		// conflicts.
@@ -299,6 +315,65 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	return mb.outScope
 }
 
+// isUpsertEquivalent returns true if the given INSERT..ON CONFLICT..DO UPDATE
+// clause has the same semantics as the UPSERT short form restricted to the
+// columns it assigns. This is the case when:
+//
+//   1. The conflict columns are the primary key columns.
+//   2. There is no WHERE clause, and every SET expression has the form
+//      `c = excluded.c`.
+//   3. No assigned column is a key column, a computed column or a GENERATED
+//      ALWAYS AS IDENTITY column. Those cases raise errors or have special
+//      semantics, so they are left to the general code path.
+//
+// Such statements are built like UPSERT, which enables them to use a blind KV
+// Put when needExistingRows allows it.
+func (mb *mutationBuilder) isUpsertEquivalent(onConflict *tree.OnConflict) bool {
+	if onConflict == nil || onConflict.DoNothing {
+		return false
+	}
+	// If the target table is itself called "excluded", then the SET
+	// expressions are ambiguous, and the general path reports the error.
+	if mb.alias.TableName == excludedTableName.TableName {
+		return false
+	}
+	assigned, ok := onConflict.ExcludedPassthroughCols()
+	if !ok {
+		return false
+	}
+
+	primary := mb.tab.Index(cat.PrimaryIndex)
+	if len(onConflict.Columns) != primary.LaxKeyColumnCount() {
+		return false
+	}
+	var keyOrds util.FastIntSet
+	for i, n := 0, primary.LaxKeyColumnCount(); i < n; i++ {
+		keyOrds.Add(primary.Column(i).Ordinal)
+	}
+	var conflictOrds util.FastIntSet
+	for _, name := range onConflict.Columns {
+		ord := cat.FindTableColumnByName(mb.tab, name)
+		if ord == -1 {
+			return false
+		}
+		conflictOrds.Add(ord)
+	}
+	if !conflictOrds.Equals(keyOrds) {
+		return false
+	}
+
+	for _, name := range assigned {
+		ord := cat.FindTableColumnByName(mb.tab, name)
+		if ord == -1 || keyOrds.Contains(ord) {
+			return false
+		}
+		if tabCol := mb.tab.Column(ord); tabCol.IsComputed() || tabCol.IsGeneratedAlwaysAsIdentity() {
+			return false
+		}
+	}
+	return true
+}
+
 // needExistingRows returns true if an Upsert statement needs to fetch existing
 // rows in order to detect conflicts. In some cases, it is not necessary to
 // fetch existing rows, and then the KV Put operation can be used to blindly
@@ -478,14 +553,21 @@ func (mb *mutationBuilder) addTargetTableColsForInsert(maxCols int) {
 	// Only consider non-mutation columns, since mutation columns are hidden from
 	// the SQL user.
 	numCols := 0
-	for i, n := 0, mb.tab.ColumnCount(); i < n && numCols < maxCols; i++ {
-		// Skip hidden columns.
-		if mb.tab.Column(i).IsHidden() {
-			continue
+	if mb.view != nil {
+		// The columns of a view are targeted in the order the view defines them.
+		for ; numCols < len(mb.view.tabOrds) && numCols < maxCols; numCols++ {
+			mb.addTargetCol(mb.view.tabOrds[numCols])
 		}
+	} else {
+		for i, n := 0, mb.tab.ColumnCount(); i < n && numCols < maxCols; i++ {
+			// Skip hidden columns.
+			if mb.tab.Column(i).IsHidden() {
+				continue
+			}
 
-		mb.addTargetCol(i)
-		numCols++
+			mb.addTargetCol(i)
+			numCols++
+		}
 	}
 
 	// Ensure that the number of input columns does not exceed the number of
@@ -524,6 +606,12 @@ func (mb *mutationBuilder) buildInputForInsert(inScope *scope, inputRows *tree.S
 		for i, colID := range mb.targetColList {
 			desiredTypes[i] = mb.md.ColumnMeta(colID).Type
 		}
+	} else if mb.view != nil {
+		// Input columns are mapped to the view's columns.
+		desiredTypes = make([]types.T, len(mb.view.tabOrds))
+		for i, ord := range mb.view.tabOrds {
+			desiredTypes[i] = mb.tab.Column(ord).DatumType()
+		}
 	} else {
 		// Do not target mutation columns.
 		desiredTypes = make([]types.T, 0, mb.tab.ColumnCount())
@@ -569,6 +657,59 @@ func (mb *mutationBuilder) buildInputForInsert(inScope *scope, inputRows *tree.S
 	}
 }
 
+// applyOverridingForInsert applies the rules of the OVERRIDING clause to the
+// values provided by the Insert input for the identity columns:
+//
+//   - without OVERRIDING, only DEFAULT can be provided for the GENERATED ALWAYS
+//     AS IDENTITY columns.
+//   - with OVERRIDING SYSTEM VALUE, any value can be provided.
+//   - with OVERRIDING USER VALUE, the provided values are ignored; the default
+//     values are synthesized by addDefaultColsForInsert instead.
+//
+func (mb *mutationBuilder) applyOverridingForInsert(ins *tree.Insert) {
+	if ins.Overriding == tree.OverridingSystemValue {
+		return
+	}
+
+	values := mb.extractValuesInput(ins.Rows)
+	for i, colID := range mb.targetColList {
+		ord := mb.tabID.ColumnOrdinal(colID)
+		tabCol := mb.tab.Column(ord)
+		if !tabCol.IsIdentity() {
+			continue
+		}
+
+		switch ins.Overriding {
+		case tree.OverridingNone:
+			if tabCol.IsGeneratedAlwaysAsIdentity() && !valuesAreDefault(values, i) {
+				panic(builderError{
+					sqlbase.NewGeneratedAlwaysAsIdentityColumnOverrideError(string(tabCol.ColName())),
+				})
+			}
+
+		case tree.OverridingUserValue:
+			// Unname the input column, so that computed columns refer to the
+			// synthesized default column instead.
+			mb.outScope.cols[mb.insertOrds[ord]].name = ""
+			mb.insertOrds[ord] = -1
+		}
+	}
+}
+
+// valuesAreDefault returns whether all the values at the given position in
+// the tuples of the VALUES clause are DEFAULT, or false if values is nil.
+func valuesAreDefault(values *tree.ValuesClause, idx int) bool {
+	if values == nil {
+		return false
+	}
+	for _, tuple := range values.Rows {
+		if _, ok := tuple[idx].(tree.DefaultVal); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // addDefaultColsForInsert wraps an Insert input expression with a Project
 // operator containing any default (or nullable) columns that are not yet part
 // of the target column list. This includes mutation columns, since they must
@@ -599,6 +740,10 @@ func (mb *mutationBuilder) addComputedColsForInsert() {
 // buildInsert constructs an Insert operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildInsert(returning tree.ReturningExprs) {
+	// Ensure that inserted rows are visible through the target view, if it was
+	// created WITH CHECK OPTION.
+	mb.addViewCheckOption()
+
 	// Add any check constraint boolean columns to the input.
 	mb.addCheckConstraintCols()
 
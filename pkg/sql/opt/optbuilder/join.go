@@ -35,12 +35,26 @@ import (
 // return values.
 func (b *Builder) buildJoin(join *tree.JoinTableExpr, inScope *scope) (outScope *scope) {
 	leftScope := b.buildDataSource(join.Left, nil /* indexFlags */, inScope)
-	rightScope := b.buildDataSource(join.Right, nil /* indexFlags */, inScope)
+
+	joinType := sqlbase.JoinTypeFromAstString(join.JoinType)
+
+	// A LATERAL right side can refer to the columns of the left side, so it is
+	// built in the left scope.
+	lateral := isLateral(join.Right)
+	rightInScope := inScope
+	if lateral {
+		telemetry.Inc(sqltelemetry.LateralJoinUseCounter)
+		if joinType != sqlbase.InnerJoin && joinType != sqlbase.LeftOuterJoin {
+			panic(pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+				"the combining JOIN type must be INNER or LEFT for a LATERAL reference"))
+		}
+		rightInScope = leftScope
+	}
+	rightScope := b.buildDataSource(join.Right, nil /* indexFlags */, rightInScope)
 
 	// Check that the same table name is not used on both sides.
 	b.validateJoinTableNames(leftScope, rightScope)
 
-	joinType := sqlbase.JoinTypeFromAstString(join.JoinType)
 	var flags memo.JoinFlags
 	switch join.Hint {
 	case "":
@@ -75,7 +89,7 @@ func (b *Builder) buildJoin(join *tree.JoinTableExpr, inScope *scope) (outScope
 		outScope = inScope.push()
 
 		var jb usingJoinBuilder
-		jb.init(b, joinType, flags, leftScope, rightScope, outScope)
+		jb.init(b, joinType, flags, lateral, leftScope, rightScope, outScope)
 
 		switch t := cond.(type) {
 		case tree.NaturalJoinCond:
@@ -107,7 +121,7 @@ func (b *Builder) buildJoin(join *tree.JoinTableExpr, inScope *scope) (outScope
 		left := leftScope.expr.(memo.RelExpr)
 		right := rightScope.expr.(memo.RelExpr)
 		outScope.expr = b.constructJoin(
-			joinType, left, right, filters, &memo.JoinPrivate{Flags: flags},
+			joinType, left, right, filters, &memo.JoinPrivate{Flags: flags}, lateral,
 		)
 		return outScope
 
@@ -171,16 +185,26 @@ func (b *Builder) findJoinColsToValidate(scope *scope) util.FastIntSet {
 	return ords
 }
 
+// constructJoin constructs a join of the given type. If isLateral is true, the
+// right input can refer to the columns of the left input, and an apply join is
+// constructed.
 func (b *Builder) constructJoin(
 	joinType sqlbase.JoinType,
 	left, right memo.RelExpr,
 	on memo.FiltersExpr,
 	private *memo.JoinPrivate,
+	isLateral bool,
 ) memo.RelExpr {
 	switch joinType {
 	case sqlbase.InnerJoin:
+		if isLateral {
+			return b.factory.ConstructInnerJoinApply(left, right, on, private)
+		}
 		return b.factory.ConstructInnerJoin(left, right, on, private)
 	case sqlbase.LeftOuterJoin:
+		if isLateral {
+			return b.factory.ConstructLeftJoinApply(left, right, on, private)
+		}
 		return b.factory.ConstructLeftJoin(left, right, on, private)
 	case sqlbase.RightOuterJoin:
 		return b.factory.ConstructRightJoin(left, right, on, private)
@@ -257,6 +281,7 @@ type usingJoinBuilder struct {
 	b          *Builder
 	joinType   sqlbase.JoinType
 	joinFlags  memo.JoinFlags
+	isLateral  bool
 	filters    memo.FiltersExpr
 	leftScope  *scope
 	rightScope *scope
@@ -279,11 +304,13 @@ func (jb *usingJoinBuilder) init(
 	b *Builder,
 	joinType sqlbase.JoinType,
 	flags memo.JoinFlags,
+	isLateral bool,
 	leftScope, rightScope, outScope *scope,
 ) {
 	jb.b = b
 	jb.joinType = joinType
 	jb.joinFlags = flags
+	jb.isLateral = isLateral
 	jb.leftScope = leftScope
 	jb.rightScope = rightScope
 	jb.outScope = outScope
@@ -355,6 +382,7 @@ func (jb *usingJoinBuilder) finishBuild() {
 		jb.rightScope.expr.(memo.RelExpr),
 		jb.filters,
 		&memo.JoinPrivate{Flags: jb.joinFlags},
+		jb.isLateral,
 	)
 
 	if !jb.ifNullCols.Empty() {
@@ -417,6 +445,13 @@ func (jb *usingJoinBuilder) findUsingColumn(cols []scopeColumn, name tree.Name)
 // either the left or right column value, or, in the case of a FULL JOIN, an
 // IFNULL(left, right) expression.
 func (jb *usingJoinBuilder) addEqualityCondition(leftCol, rightCol *scopeColumn) {
+	// The join condition references both columns.
+	for _, col := range []*scopeColumn{leftCol, rightCol} {
+		if col.privErr != nil {
+			panic(builderError{col.privErr})
+		}
+	}
+
 	// First, check if the comparison would even be valid.
 	if !leftCol.typ.Equivalent(rightCol.typ) {
 		if _, found := tree.FindEqualComparisonFunction(leftCol.typ, rightCol.typ); !found {
@@ -16,6 +16,7 @@ package optbuilder
 
 import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 )
@@ -32,6 +33,11 @@ func (b *Builder) buildLimit(limit *tree.Limit, parentScope, inScope *scope) {
 	// context.
 	defer b.semaCtx.Properties.Restore(b.semaCtx.Properties)
 
+	if limit.WithTies && inScope.ordering.Empty() {
+		panic(pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"WITH TIES cannot be specified without ORDER BY clause"))
+	}
+
 	if limit.Offset != nil {
 		op := "OFFSET"
 		b.assertNoAggregationOrWindowing(limit.Offset, op)
@@ -49,7 +55,11 @@ func (b *Builder) buildLimit(limit *tree.Limit, parentScope, inScope *scope) {
 		parentScope.context = op
 		texpr := parentScope.resolveAndRequireType(limit.Count, types.Int)
 		input := inScope.expr.(memo.RelExpr)
-		limit := b.buildScalar(texpr, parentScope, nil, nil, nil)
-		inScope.expr = b.factory.ConstructLimit(input, limit, inScope.makeOrderingChoice())
+		count := b.buildScalar(texpr, parentScope, nil, nil, nil)
+		if limit.WithTies {
+			inScope.expr = b.factory.ConstructLimitWithTies(input, count, inScope.makeOrderingChoice())
+		} else {
+			inScope.expr = b.factory.ConstructLimit(input, count, inScope.makeOrderingChoice())
+		}
 	}
 }
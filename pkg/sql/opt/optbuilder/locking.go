@@ -0,0 +1,135 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// lockingSpec maintains a collection of FOR [KEY] UPDATE/SHARE items that
+// apply to the data sources of the query being built. The items are
+// accumulated as the builder descends into the FROM clause: an item without
+// targets applies to all the data sources, while an item with targets only
+// applies to the data sources with a matching alias. When a data source is a
+// subquery or a view, the items which apply to it are passed down without
+// their targets, so that they apply to all the tables used in it.
+//
+// The semantics follow Postgres: the locking clauses of a query apply to all
+// the tables used in the views and subqueries in its FROM clause, but not to
+// its WITH queries, and a table affected by more than one locking clause is
+// processed as if it was only specified by the strongest one.
+type lockingSpec []*tree.LockingItem
+
+// noRowLocking indicates that no row-level locking has been specified.
+var noRowLocking lockingSpec
+
+// isSet returns whether the spec contains any locking items.
+func (lm lockingSpec) isSet() bool {
+	return len(lm) != 0
+}
+
+// get returns the combined row-level locking mode of the items in the spec:
+// the strongest of their strengths and of their wait policies.
+func (lm lockingSpec) get() (tree.LockingStrength, tree.LockingWaitPolicy) {
+	var strength tree.LockingStrength
+	var waitPolicy tree.LockingWaitPolicy
+	for _, li := range lm {
+		strength = strength.Max(li.Strength)
+		waitPolicy = waitPolicy.Max(li.WaitPolicy)
+	}
+	return strength, waitPolicy
+}
+
+// append returns a new spec with the given items appended to the items of the
+// receiver. The receiver is not modified.
+func (lm lockingSpec) append(items ...*tree.LockingItem) lockingSpec {
+	if len(items) == 0 {
+		return lm
+	}
+	res := make(lockingSpec, 0, len(lm)+len(items))
+	res = append(res, lm...)
+	return append(res, items...)
+}
+
+// filter returns the items of the spec that apply to the data source with the
+// given alias, stripped of their targets.
+func (lm lockingSpec) filter(alias tree.Name) lockingSpec {
+	var res lockingSpec
+	for _, li := range lm {
+		if len(li.Targets) == 0 {
+			res = append(res, li)
+			continue
+		}
+		for i := range li.Targets {
+			if li.Targets[i].TableName == alias {
+				res = append(res, &tree.LockingItem{Strength: li.Strength, WaitPolicy: li.WaitPolicy})
+				break
+			}
+		}
+	}
+	return res
+}
+
+// lockingClauseError returns an error for a locking clause which is used
+// with an unsupported construct of the query.
+func (lm lockingSpec) lockingClauseError(format string) error {
+	strength, _ := lm.get()
+	return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError, format, strength)
+}
+
+// validateLockingTargets checks that the targets of the locking items of the
+// current query are unqualified names of data sources in its FROM clause.
+func (b *Builder) validateLockingTargets(fromScope *scope) {
+	for _, li := range b.locking {
+		for i := range li.Targets {
+			target := &li.Targets[i]
+			if target.ExplicitSchema || target.ExplicitCatalog {
+				panic(pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"%s must specify unqualified relation names", li.Strength))
+			}
+			found := false
+			for j := range fromScope.cols {
+				if fromScope.cols[j].table.TableName == target.TableName {
+					found = true
+					break
+				}
+			}
+			if !found {
+				panic(pgerror.NewErrorf(pgerror.CodeUndefinedTableError,
+					"relation %q in %s clause not found in FROM clause",
+					tree.ErrString(&target.TableName), li.Strength))
+			}
+		}
+	}
+}
+
+// validateLockingInSelectClause checks that the row-level locking of the
+// current query is compatible with the given SELECT clause.
+func (b *Builder) validateLockingInSelectClause(sel *tree.SelectClause, fromScope *scope) {
+	switch {
+	case sel.Distinct:
+		panic(b.locking.lockingClauseError("%s is not allowed with DISTINCT clause"))
+	case len(sel.GroupBy) > 0:
+		panic(b.locking.lockingClauseError("%s is not allowed with GROUP BY clause"))
+	case sel.Having != nil:
+		panic(b.locking.lockingClauseError("%s is not allowed with HAVING clause"))
+	case fromScope.hasAggregates():
+		panic(b.locking.lockingClauseError("%s is not allowed with aggregate functions"))
+	case len(fromScope.srfs) > 0:
+		panic(b.locking.lockingClauseError(
+			"%s is not allowed with set-returning functions in the target list"))
+	}
+}
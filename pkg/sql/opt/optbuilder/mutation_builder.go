@@ -49,6 +49,11 @@ type mutationBuilder struct {
 	// resolved table name if no alias was specified.
 	alias tree.TableName
 
+	// view describes the view targeted by the mutation statement, if it targets
+	// a view rather than a table. In that case, tab is the underlying table of
+	// the view, and the view columns map to columns of that table.
+	view *mutationView
+
 	// outScope contains the current set of columns that are in scope, as well as
 	// the output expression as it is incrementally built. Once the final mutation
 	// expression is completed, it will be contained in outScope.expr. Columns,
@@ -124,12 +129,22 @@ type mutationBuilder struct {
 	parsedExprs []tree.Expr
 }
 
-func (mb *mutationBuilder) init(b *Builder, op opt.Operator, tab cat.Table, alias tree.TableName) {
+// init initializes the mutationBuilder for a statement that targets the given
+// data source, which is either a table or an automatically updatable view.
+func (mb *mutationBuilder) init(
+	b *Builder, op opt.Operator, ds cat.DataSource, alias tree.TableName,
+) {
 	mb.b = b
 	mb.md = b.factory.Metadata()
 	mb.op = op
-	mb.tab = tab
 	mb.alias = alias
+
+	// Mutations of a view are applied to its underlying table.
+	tab, ok := ds.(cat.Table)
+	if !ok {
+		tab = mb.initView(ds.(cat.View), op)
+	}
+	mb.tab = tab
 	mb.targetColList = make(opt.ColList, 0, tab.DeletableColumnCount())
 
 	// Allocate segmented array of scope column ordinals.
@@ -183,7 +198,13 @@ func (mb *mutationBuilder) buildInputForUpdateOrDelete(
 	//
 	//   UPDATE abc SET a=b
 	//
-	inputTabID := mb.md.AddTableWithAlias(mb.tab, &mb.alias)
+	// If the target is a view, then the table is scanned using the name by
+	// which the view query refers to it, so that the view filter can be built.
+	alias := mb.alias
+	if mb.view != nil {
+		alias = mb.view.tabName
+	}
+	inputTabID := mb.md.AddTableWithAlias(mb.tab, &alias)
 
 	// FROM
 	mb.outScope = mb.b.buildScan(
@@ -194,6 +215,11 @@ func (mb *mutationBuilder) buildInputForUpdateOrDelete(
 		inScope,
 	)
 
+	// Only rows that are visible through the target view can be mutated.
+	if mb.view != nil {
+		mb.buildViewFilter()
+	}
+
 	// WHERE
 	mb.b.buildWhere(where, mb.outScope)
 
@@ -211,8 +237,9 @@ func (mb *mutationBuilder) buildInputForUpdateOrDelete(
 
 	mb.outScope = projectionsScope
 
-	// Set list of columns that will be fetched by the input expression.
-	for i := range mb.outScope.cols {
+	// Set list of columns that will be fetched by the input expression. These
+	// are the table columns projected by the scan, which come first.
+	for i := range mb.fetchOrds {
 		mb.fetchOrds[i] = scopeOrdinal(i)
 	}
 }
@@ -222,8 +249,15 @@ func (mb *mutationBuilder) buildInputForUpdateOrDelete(
 func (mb *mutationBuilder) addTargetColsByName(names tree.NameList) {
 	for _, name := range names {
 		// Determine the ordinal position of the named column in the table and
-		// add it as a target column.
-		if ord := cat.FindTableColumnByName(mb.tab, name); ord != -1 {
+		// add it as a target column. If the target is a view, then the name
+		// refers to a view column, which maps to a table column.
+		ord := -1
+		if mb.view != nil {
+			ord = mb.view.findViewColByName(name)
+		} else {
+			ord = cat.FindTableColumnByName(mb.tab, name)
+		}
+		if ord != -1 {
 			mb.addTargetCol(ord)
 			continue
 		}
@@ -258,16 +292,29 @@ func (mb *mutationBuilder) addTargetCol(ord int) {
 	mb.targetColList = append(mb.targetColList, colID)
 }
 
+// checkIdentityColForUpdate raises an error if the given target column of an
+// Update is a GENERATED ALWAYS AS IDENTITY column, which can only be updated to
+// DEFAULT.
+func (mb *mutationBuilder) checkIdentityColForUpdate(colID opt.ColumnID) {
+	tabCol := mb.tab.Column(mb.tabID.ColumnOrdinal(colID))
+	if tabCol.IsGeneratedAlwaysAsIdentity() {
+		panic(builderError{
+			sqlbase.NewGeneratedAlwaysAsIdentityColumnUpdateError(string(tabCol.ColName())),
+		})
+	}
+}
+
 // extractValuesInput tests whether the given input is a VALUES clause with no
-// WITH, ORDER BY, or LIMIT modifier. If so, it's returned, otherwise nil is
-// returned.
+// WITH, ORDER BY, LIMIT or locking modifier. If so, it's returned, otherwise
+// nil is returned.
 func (mb *mutationBuilder) extractValuesInput(inputRows *tree.Select) *tree.ValuesClause {
 	if inputRows == nil {
 		return nil
 	}
 
 	// Only extract a simple VALUES clause with no modifiers.
-	if inputRows.With != nil || inputRows.OrderBy != nil || inputRows.Limit != nil {
+	if inputRows.With != nil || inputRows.OrderBy != nil || inputRows.Limit != nil ||
+		inputRows.Locking != nil {
 		return nil
 	}
 
@@ -393,9 +440,12 @@ func (mb *mutationBuilder) addSynthesizedCols(
 		// Remember ordinal position of the new scope column.
 		scopeOrds[i] = scopeOrdinal(len(projectionsScope.cols) - 1)
 
-		// Add corresponding target column.
-		mb.targetColList = append(mb.targetColList, tabColID)
-		mb.targetColSet.Add(int(tabColID))
+		// Add corresponding target column, unless the input already targeted
+		// it (see applyOverridingForInsert).
+		if !mb.targetColSet.Contains(int(tabColID)) {
+			mb.targetColList = append(mb.targetColList, tabColID)
+			mb.targetColSet.Add(int(tabColID))
+		}
 	}
 
 	if projectionsScope != nil {
@@ -540,21 +590,23 @@ func (mb *mutationBuilder) addCheckConstraintCols() {
 // value that the mutation applies.
 func (mb *mutationBuilder) disambiguateColumns() {
 	// Determine the set of scope columns that will have their names preserved.
+	// Set the fully qualified table name of preserved columns, since computed
+	// column expressions will reference table names, not alias names. Also set
+	// the table column name, which differs from the scope column name if the
+	// column is exposed by a view under another name.
 	var preserve util.FastIntSet
 	for i, n := 0, mb.tab.DeletableColumnCount(); i < n; i++ {
 		scopeOrd := mb.mapToReturnScopeOrd(i)
 		if scopeOrd != -1 {
 			preserve.Add(int(scopeOrd))
+			mb.outScope.cols[scopeOrd].table = *mb.tab.Name()
+			mb.outScope.cols[scopeOrd].name = mb.tab.Column(i).ColName()
 		}
 	}
 
-	// Clear names of all non-preserved columns. Set the fully qualified table
-	// name of preserved columns, since computed column expressions will reference
-	// table names, not alias names.
+	// Clear names of all non-preserved columns.
 	for i := range mb.outScope.cols {
-		if preserve.Contains(i) {
-			mb.outScope.cols[i].table = *mb.tab.Name()
-		} else {
+		if !preserve.Contains(i) {
 			mb.outScope.cols[i].clearName()
 		}
 	}
@@ -658,18 +710,32 @@ func (mb *mutationBuilder) buildReturning(returning tree.ReturningExprs) {
 	//   3. Mark hidden columns.
 	//   4. Project columns in same order as defined in table schema.
 	//
+	// If the target is a view, then only the view columns can be referenced,
+	// with the names and in the order defined by the view.
 	inScope := mb.outScope.replace()
 	inScope.expr = mb.outScope.expr
-	inScope.cols = make([]scopeColumn, 0, mb.tab.ColumnCount())
-	for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
-		tabCol := mb.tab.Column(i)
-		inScope.cols = append(inScope.cols, scopeColumn{
-			name:   tabCol.ColName(),
-			table:  mb.alias,
-			typ:    tabCol.DatumType(),
-			id:     mb.tabID.ColumnID(i),
-			hidden: tabCol.IsHidden(),
-		})
+	if mb.view != nil {
+		inScope.cols = make([]scopeColumn, 0, len(mb.view.tabOrds))
+		for i, ord := range mb.view.tabOrds {
+			inScope.cols = append(inScope.cols, scopeColumn{
+				name:  mb.view.colNames[i],
+				table: mb.alias,
+				typ:   mb.tab.Column(ord).DatumType(),
+				id:    mb.tabID.ColumnID(ord),
+			})
+		}
+	} else {
+		inScope.cols = make([]scopeColumn, 0, mb.tab.ColumnCount())
+		for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
+			tabCol := mb.tab.Column(i)
+			inScope.cols = append(inScope.cols, scopeColumn{
+				name:   tabCol.ColName(),
+				table:  mb.alias,
+				typ:    tabCol.DatumType(),
+				id:     mb.tabID.ColumnID(i),
+				hidden: tabCol.IsHidden(),
+			})
+		}
 	}
 
 	// Construct the Project operator that projects the RETURNING expressions.
@@ -0,0 +1,275 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package optbuilder
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// mutationView describes a view that is the target of an INSERT, UPDATE, or
+// DELETE statement. Only simple views are automatically updatable: views that
+// select plain columns from a single table, optionally filtered by a WHERE
+// clause. For example:
+//
+//   CREATE VIEW v AS SELECT a AS x, b AS y FROM abc WHERE c > 0
+//
+// A mutation of such a view is built as a mutation of its underlying table:
+// the view columns are mapped to the table columns they expose, and only rows
+// which pass the view filter are updated or deleted:
+//
+//   UPDATE v SET x=1 WHERE y=2
+//   =>
+//   UPDATE abc SET a=1 WHERE c > 0 AND b=2
+//
+// If the view was created WITH CHECK OPTION, then rows which are inserted or
+// updated through the view must also pass the view filter, or an error is
+// raised.
+type mutationView struct {
+	view cat.View
+
+	// tabName is the name by which the view query refers to the underlying
+	// table. It is the table alias if one was specified, or else the fully
+	// qualified name of the table.
+	tabName tree.TableName
+
+	// colNames are the names of the view columns, and tabOrds are the ordinal
+	// positions of the table columns that they expose.
+	colNames tree.NameList
+	tabOrds  []int
+
+	// where is the filter of the view query, or nil if it does not have one.
+	where tree.Expr
+}
+
+// initView checks that the given view is automatically updatable, and sets
+// mb.view to describe it. It returns the underlying table of the view, which
+// is the table that will be mutated.
+func (mb *mutationBuilder) initView(view cat.View, op opt.Operator) cat.Table {
+	notUpdatable := func(detail string) {
+		panic(viewNotUpdatableError(view, op, detail))
+	}
+
+	sel := mb.b.parseView(view)
+	for {
+		if sel.With != nil {
+			notUpdatable("Views containing WITH are not automatically updatable.")
+		}
+		if sel.Limit != nil {
+			notUpdatable("Views containing LIMIT or OFFSET are not automatically updatable.")
+		}
+		paren, ok := sel.Select.(*tree.ParenSelect)
+		if !ok {
+			break
+		}
+		sel = paren.Select
+	}
+
+	var clause *tree.SelectClause
+	switch t := sel.Select.(type) {
+	case *tree.SelectClause:
+		clause = t
+	case *tree.UnionClause:
+		notUpdatable("Views containing UNION, INTERSECT, or EXCEPT are not automatically updatable.")
+	default:
+		notUpdatable("Views that do not select from a single table are not automatically updatable.")
+	}
+	if clause.Distinct || clause.DistinctOn != nil {
+		notUpdatable("Views containing DISTINCT are not automatically updatable.")
+	}
+	if len(clause.GroupBy) != 0 {
+		notUpdatable("Views containing GROUP BY are not automatically updatable.")
+	}
+	if clause.Having != nil {
+		notUpdatable("Views containing HAVING are not automatically updatable.")
+	}
+	if clause.From.AsOf.Expr != nil {
+		notUpdatable("Views containing AS OF SYSTEM TIME are not automatically updatable.")
+	}
+
+	// The view must select from a single table, which may be aliased.
+	var tn *tree.TableName
+	var alias tree.Name
+	if len(clause.From.Tables) == 1 {
+		switch t := clause.From.Tables[0].(type) {
+		case *tree.AliasedTableExpr:
+			if !t.Ordinality && len(t.As.Cols) == 0 {
+				tn, _ = t.Expr.(*tree.TableName)
+				alias = t.As.Alias
+			}
+		case *tree.TableName:
+			tn = t
+		}
+	}
+	if tn == nil {
+		notUpdatable("Views that do not select from a single table are not automatically updatable.")
+	}
+	ds, resName, err := mb.b.catalog.ResolveDataSource(mb.b.ctx, tn)
+	if err != nil {
+		panic(builderError{err})
+	}
+	tab, ok := ds.(cat.Table)
+	if !ok {
+		notUpdatable("Views that do not select from a single table are not automatically updatable.")
+	}
+
+	// As when selecting from a view, privileges are only checked on the view,
+	// not on the underlying table. Still add a dependency on the table so that
+	// cached plans are invalidated if it changes.
+	mb.md.AddDataSourceDependency(tn, tab, 0 /* priv */)
+
+	mb.view = &mutationView{view: view, tabName: resName}
+	if alias != "" {
+		mb.view.tabName = tree.MakeUnqualifiedTableName(alias)
+	}
+
+	// Every view column must expose a column of the table.
+	mb.view.colNames = make(tree.NameList, len(clause.Exprs))
+	mb.view.tabOrds = make([]int, len(clause.Exprs))
+	for i := range clause.Exprs {
+		expr := &clause.Exprs[i]
+		ord := -1
+		name, ok := tree.StripParens(expr.Expr).(*tree.UnresolvedName)
+		if ok && !name.Star {
+			ord = cat.FindTableColumnByName(tab, tree.Name(name.Parts[0]))
+		}
+		if ord == -1 {
+			notUpdatable("Views that return columns that are not columns of their base " +
+				"table are not automatically updatable.")
+		}
+
+		switch {
+		case i < view.ColumnNameCount():
+			mb.view.colNames[i] = view.ColumnName(i)
+		case expr.As != "":
+			mb.view.colNames[i] = tree.Name(expr.As)
+		default:
+			mb.view.colNames[i] = tree.Name(name.Parts[0])
+		}
+		mb.view.tabOrds[i] = ord
+	}
+
+	if clause.Where != nil {
+		mb.view.where = clause.Where.Expr
+	}
+	return tab
+}
+
+// viewNotUpdatableError returns an error stating that a mutation operator
+// cannot target the given view, for the reason given in the detail.
+func viewNotUpdatableError(view cat.View, op opt.Operator, detail string) error {
+	var verb string
+	switch op {
+	case opt.InsertOp, opt.UpsertOp:
+		verb = "insert into"
+	case opt.UpdateOp:
+		verb = "update"
+	default:
+		verb = "delete from"
+	}
+	return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+		"cannot %s view %q", verb, view.Name().TableName).SetDetailf("%s", detail)
+}
+
+// findViewColByName returns the ordinal position of the table column that is
+// exposed by the view column with the given name, or -1 if the view does not
+// have such a column.
+func (v *mutationView) findViewColByName(name tree.Name) int {
+	for i := range v.colNames {
+		if v.colNames[i] == name {
+			return v.tabOrds[i]
+		}
+	}
+	return -1
+}
+
+// buildViewFilter wraps the mutation input expression, which scans the
+// underlying table of the target view, with a Select operator that filters out
+// rows which are not visible through the view. It then makes the view columns
+// available in outScope, so that they can be referenced by the statement's
+// WHERE, ORDER BY, and SET expressions. The table columns themselves can only
+// be referenced through the view columns.
+func (mb *mutationBuilder) buildViewFilter() {
+	if mb.view.where != nil {
+		mb.buildViewExpr(func() {
+			mb.b.buildWhere(&tree.Where{Type: tree.AstWhere, Expr: mb.view.where}, mb.outScope)
+		})
+	}
+
+	n := len(mb.outScope.cols)
+	for i, ord := range mb.view.tabOrds {
+		col := mb.outScope.cols[ord]
+		col.name = mb.view.colNames[i]
+		col.table = mb.alias
+		col.hidden = false
+		mb.outScope.cols = append(mb.outScope.cols, col)
+	}
+	for i := 0; i < n; i++ {
+		mb.outScope.cols[i].clearName()
+	}
+}
+
+// addViewCheckOption wraps the mutation input expression with a Select
+// operator that raises an error for every inserted or updated row which is not
+// visible through the target view, if that view was created WITH CHECK OPTION.
+// The check is performed using the final values of the row:
+//
+//   CASE WHEN <view filter> THEN true ELSE crdb_internal.force_error(...) = 0 END
+func (mb *mutationBuilder) addViewCheckOption() {
+	if mb.view == nil || mb.view.where == nil || !mb.view.view.CheckOption() {
+		return
+	}
+
+	// Disambiguate names so that references in the view filter refer to the
+	// correct columns, and then qualify them the same way the view query does.
+	mb.disambiguateColumns()
+	for i, n := 0, mb.tab.DeletableColumnCount(); i < n; i++ {
+		if scopeOrd := mb.mapToReturnScopeOrd(i); scopeOrd != -1 {
+			mb.outScope.cols[scopeOrd].table = mb.view.tabName
+		}
+	}
+
+	violation := &tree.FuncExpr{
+		Func: tree.WrapFunction("crdb_internal.force_error"),
+		Exprs: tree.Exprs{
+			tree.NewStrVal(pgerror.CodeWithCheckOptionViolationError),
+			tree.NewStrVal(fmt.Sprintf(
+				"new row violates check option for view %q", mb.view.view.Name().TableName)),
+		},
+	}
+	check := &tree.CaseExpr{
+		Whens: []*tree.When{{Cond: mb.view.where, Val: tree.DBoolTrue}},
+		Else:  &tree.ComparisonExpr{Operator: tree.EQ, Left: violation, Right: tree.NewDInt(0)},
+	}
+	mb.buildViewExpr(func() {
+		mb.b.buildWhere(&tree.Where{Type: tree.AstWhere, Expr: check}, mb.outScope)
+	})
+}
+
+// buildViewExpr calls the given function to build an expression that is part
+// of the target view's query. As when building the view in a SELECT statement,
+// the SELECT privilege is not checked on the data sources that the expression
+// references.
+func (mb *mutationBuilder) buildViewExpr(fn func()) {
+	if !mb.b.skipSelectPrivilegeChecks {
+		mb.b.skipSelectPrivilegeChecks = true
+		defer func() { mb.b.skipSelectPrivilegeChecks = false }()
+	}
+	fn()
+}
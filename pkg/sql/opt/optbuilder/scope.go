@@ -97,6 +97,13 @@ type cteSource struct {
 	// to only having a single reference to a given CTE, so if this is set then
 	// this CTE has already been referenced and may not be referenced again.
 	used bool
+
+	// inline is set for CTEs declared as NOT MATERIALIZED. Each reference to
+	// such a CTE after the first one builds a new copy of def in defScope,
+	// which sees the same CTEs as the statement of the CTE did.
+	inline   bool
+	def      *tree.CTE
+	defScope *scope
 }
 
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
@@ -759,7 +766,11 @@ func (s *scope) Resolve(
 ) (tree.ColumnResolutionResult, error) {
 	if colHint >= 0 {
 		// Column was found by FindSourceProvidingColumn above.
-		return srcMeta.(*scopeColumn), nil
+		col := srcMeta.(*scopeColumn)
+		if col.privErr != nil {
+			return nil, col.privErr
+		}
+		return col, nil
 	}
 
 	// Otherwise, a table is known but not the column yet.
@@ -767,6 +778,9 @@ func (s *scope) Resolve(
 	for i := range inScope.cols {
 		col := &inScope.cols[i]
 		if col.name == colName && sourceNameMatches(*prefix, col.table) {
+			if col.privErr != nil {
+				return nil, col.privErr
+			}
 			return col, nil
 		}
 	}
@@ -983,6 +997,13 @@ func (s *scope) replaceAggregate(f *tree.FuncExpr, def *tree.FunctionDefinition)
 	}
 
 	f = typedFunc.(*tree.FuncExpr)
+	if def.OrderedSetImpl != "" {
+		// Type checking replaced the ordered-set aggregate by the aggregate which
+		// implements it.
+		if def, err = f.Func.Resolve(s.builder.semaCtx.SearchPath); err != nil {
+			panic(builderError{err})
+		}
+	}
 
 	private := memo.FunctionPrivate{
 		Name:       def.Name,
@@ -1103,6 +1124,12 @@ func (s *scope) replaceSubquery(
 	defer func() { s.builder.subquery = outer }()
 	s.builder.subquery = &subq
 
+	// The locking clauses of the enclosing query don't apply to the tables in
+	// subqueries used as expressions.
+	locking := s.builder.locking
+	defer func() { s.builder.locking = locking }()
+	s.builder.locking = noRowLocking
+
 	outScope := s.builder.buildStmt(sub.Select, s)
 	ord := outScope.ordering
 
@@ -60,6 +60,11 @@ type scopeColumn struct {
 	// exprStr contains a stringified representation of expr, or the original
 	// column name if expr is nil. It is populated lazily inside getExprStr().
 	exprStr string
+
+	// privErr is the error to raise if this column is referenced, because the
+	// current user does not have the privilege to select it. It is only set for
+	// table columns when the user has column-level privileges on the table.
+	privErr error
 }
 
 // clearName sets the empty table and column name. This is used to make the
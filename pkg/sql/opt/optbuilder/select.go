@@ -55,7 +55,19 @@ func (b *Builder) buildDataSource(
 			indexFlags = source.IndexFlags
 		}
 
+		// Only the locking items which target the data source (or no data
+		// source in particular) apply to it.
+		alias := source.As.Alias
+		if tn, ok := source.Expr.(*tree.TableName); ok && alias == "" {
+			alias = tn.TableName
+		}
+		locking := b.locking
+		b.locking = locking.filter(alias)
+		if source.Sample != nil {
+			b.sample = b.buildTableSample(source.Sample)
+		}
 		outScope = b.buildDataSource(source.Expr, indexFlags, inScope)
+		b.locking = locking
 
 		if source.Ordinality {
 			outScope = b.buildWithOrdinality("ordinality", outScope)
@@ -74,8 +86,23 @@ func (b *Builder) buildDataSource(
 
 		// CTEs take precedence over other data sources.
 		if cte := inScope.resolveCTE(tn); cte != nil {
+			if b.sample != nil {
+				panic(tableSampleError())
+			}
 			if cte.used {
-				panic(unimplementedWithIssueDetailf(21084, "", "unsupported multiple use of CTE clause %q", tn))
+				if !cte.inline || cte.expr.Relational().CanMutate {
+					panic(unimplementedWithIssueDetailf(21084, "", "unsupported multiple use of CTE clause %q", tn))
+				}
+				// The CTE is inlined: build another copy of its statement, with
+				// new output columns. Locking clauses don't apply to CTEs.
+				locking := b.locking
+				b.locking = noRowLocking
+				cteScope := b.buildStmt(cte.def.Stmt, cte.defScope)
+				b.locking = locking
+				outScope = inScope.push()
+				outScope.expr = cteScope.expr
+				outScope.cols = b.getCTEColumns(cte.def, cteScope)
+				return outScope
 			}
 			cte.used = true
 
@@ -88,14 +115,26 @@ func (b *Builder) buildDataSource(
 			return outScope
 		}
 
-		ds, resName := b.resolveDataSource(tn, privilege.SELECT)
+		ds, resName, colPrivErrs := b.resolveDataSourceForSelect(tn)
 		switch t := ds.(type) {
 		case cat.Table:
 			tabID := b.factory.Metadata().AddTableWithAlias(t, &resName)
+			if colPrivErrs != nil {
+				if b.colPrivErrs == nil {
+					b.colPrivErrs = make(map[opt.TableID][]error)
+				}
+				b.colPrivErrs[tabID] = colPrivErrs
+			}
 			return b.buildScan(tabID, nil /* ordinals */, indexFlags, excludeMutations, inScope)
 		case cat.View:
+			if b.sample != nil {
+				panic(tableSampleError())
+			}
 			return b.buildView(t, inScope)
 		case cat.Sequence:
+			if b.sample != nil {
+				panic(tableSampleError())
+			}
 			return b.buildSequenceSelect(t, inScope)
 		default:
 			panic(pgerror.NewAssertionErrorf("unknown DataSource type %T", ds))
@@ -119,7 +158,10 @@ func (b *Builder) buildDataSource(
 		return outScope
 
 	case *tree.StatementSource:
+		locking := b.locking
+		b.locking = noRowLocking
 		outScope = b.buildStmt(source.Statement, inScope)
+		b.locking = locking
 		if len(outScope.cols) == 0 {
 			panic(pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
 				"statement source \"%v\" does not return any columns", source.Statement))
@@ -144,6 +186,35 @@ func (b *Builder) buildDataSource(
 
 // buildView parses the view query text and builds it as a Select expression.
 func (b *Builder) buildView(view cat.View, inScope *scope) (outScope *scope) {
+	sel := b.parseView(view)
+
+	// When building the view, we don't want to check for the SELECT privilege
+	// on the underlying tables, just on the view itself. Checking on the
+	// underlying tables as well would defeat the purpose of having separate
+	// SELECT privileges on the view, which is intended to allow for exposing
+	// some subset of a restricted table's data to less privileged users.
+	if !b.skipSelectPrivilegeChecks {
+		b.skipSelectPrivilegeChecks = true
+		defer func() { b.skipSelectPrivilegeChecks = false }()
+	}
+
+	outScope = b.buildSelect(sel, nil /* desiredTypes */, &scope{builder: b})
+
+	// Update data source name to be the name of the view. And if view columns
+	// are specified, then update names of output columns.
+	hasCols := view.ColumnNameCount() > 0
+	for i := range outScope.cols {
+		outScope.cols[i].table = *view.Name()
+		if hasCols {
+			outScope.cols[i].name = view.ColumnName(i)
+		}
+	}
+
+	return outScope
+}
+
+// parseView returns the AST of the query that defines the given view.
+func (b *Builder) parseView(view cat.View) *tree.Select {
 	// Cache the AST so that multiple references won't need to reparse.
 	if b.views == nil {
 		b.views = make(map[cat.View]*tree.Select)
@@ -169,30 +240,7 @@ func (b *Builder) buildView(view cat.View, inScope *scope) (outScope *scope) {
 		// Keep track of referenced views for EXPLAIN (opt, env).
 		b.factory.Metadata().AddView(view)
 	}
-
-	// When building the view, we don't want to check for the SELECT privilege
-	// on the underlying tables, just on the view itself. Checking on the
-	// underlying tables as well would defeat the purpose of having separate
-	// SELECT privileges on the view, which is intended to allow for exposing
-	// some subset of a restricted table's data to less privileged users.
-	if !b.skipSelectPrivilegeChecks {
-		b.skipSelectPrivilegeChecks = true
-		defer func() { b.skipSelectPrivilegeChecks = false }()
-	}
-
-	outScope = b.buildSelect(sel, nil /* desiredTypes */, &scope{builder: b})
-
-	// Update data source name to be the name of the view. And if view columns
-	// are specified, then update names of output columns.
-	hasCols := view.ColumnNameCount() > 0
-	for i := range outScope.cols {
-		outScope.cols[i].table = *view.Name()
-		if hasCols {
-			outScope.cols[i].name = view.ColumnName(i)
-		}
-	}
-
-	return outScope
+	return sel
 }
 
 // renameSource applies an AS clause to the columns in scope.
@@ -327,6 +375,7 @@ func (b *Builder) buildScan(
 	}
 
 	var tabColIDs opt.ColSet
+	colPrivErrs := b.colPrivErrs[tabID]
 	outScope = inScope.push()
 	outScope.cols = make([]scopeColumn, 0, colCount)
 	for i := 0; i < colCount; i++ {
@@ -348,17 +397,28 @@ func (b *Builder) buildScan(
 			hidden:   col.IsHidden() || isMutation,
 			mutation: isMutation,
 		})
+		if ord < len(colPrivErrs) {
+			outScope.cols[len(outScope.cols)-1].privErr = colPrivErrs[ord]
+		}
 	}
 
+	sample := b.sample
+	b.sample = nil
+
 	if tab.IsVirtualTable() {
 		if indexFlags != nil {
 			panic(pgerror.NewErrorf(pgerror.CodeSyntaxError,
 				"index flags not allowed with virtual tables"))
 		}
+		if sample != nil {
+			panic(tableSampleError())
+		}
 		private := memo.VirtualScanPrivate{Table: tabID, Cols: tabColIDs}
 		outScope.expr = b.factory.ConstructVirtualScan(&private)
 	} else {
 		private := memo.ScanPrivate{Table: tabID, Cols: tabColIDs}
+		private.LockingStrength, private.LockingWaitPolicy = b.locking.get()
+		private.Sample = sample
 
 		if indexFlags != nil {
 			private.Flags.NoIndexJoin = indexFlags.NoIndexJoin
@@ -380,6 +440,11 @@ func (b *Builder) buildScan(
 					}
 					panic(builderError{err})
 				}
+				if sample != nil && idx != cat.PrimaryIndex {
+					// Sampled scans always read the primary index.
+					panic(pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+						"TABLESAMPLE cannot be used with a secondary index"))
+				}
 				private.Flags.ForceIndex = true
 				private.Flags.Index = idx
 				private.Flags.Direction = indexFlags.Direction
@@ -387,10 +452,85 @@ func (b *Builder) buildScan(
 		}
 
 		outScope.expr = b.factory.ConstructScan(&private)
+		outScope = b.projectVirtualCols(tabID, outScope)
 	}
 	return outScope
 }
 
+// projectVirtualCols computes the values of the virtual computed columns
+// produced by a scan. The values of these columns are not stored, so the scan
+// itself always returns NULL for them; instead, each one is replaced by its
+// computed expression over the other columns of the table, keeping its position
+// and name in the scope. If the scan doesn't produce any virtual column,
+// projectVirtualCols returns the given scope unchanged.
+func (b *Builder) projectVirtualCols(tabID opt.TableID, inScope *scope) (outScope *scope) {
+	tab := b.factory.Metadata().Table(tabID)
+	for i := range inScope.cols {
+		tabCol := tab.Column(tabID.ColumnOrdinal(inScope.cols[i].id))
+		if !tabCol.IsVirtual() {
+			continue
+		}
+
+		if outScope == nil {
+			outScope = inScope.replace()
+			outScope.appendColumnsFromScope(inScope)
+		}
+		expr, err := parser.ParseExpr(tabCol.ComputedExprStr())
+		if err != nil {
+			panic(builderError{err})
+		}
+		texpr := inScope.resolveAndRequireType(expr, tabCol.DatumType())
+		scopeCol := &outScope.cols[i]
+		scopeCol.expr = texpr
+		b.buildScalar(texpr, inScope, outScope, scopeCol, nil)
+	}
+
+	if outScope == nil {
+		return inScope
+	}
+	b.constructProjectForScope(inScope, outScope)
+	return outScope
+}
+
+// tableSampleError returns the error for a TABLESAMPLE clause which is applied
+// to a data source which is not a table.
+func tableSampleError() error {
+	return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+		"TABLESAMPLE clause can only be applied to tables")
+}
+
+// buildTableSample type checks the arguments of the given TABLESAMPLE clause.
+// The arguments can't reference columns; they are evaluated right away if they
+// are constant, and otherwise when the scan is executed.
+func (b *Builder) buildTableSample(sample *tree.TableSample) *tree.TableSample {
+	// We need to save and restore the previous value of the field in
+	// semaCtx in case we are recursively called within a subquery
+	// context.
+	defer b.semaCtx.Properties.Restore(b.semaCtx.Properties)
+	b.semaCtx.Properties.Require("TABLESAMPLE", tree.RejectSpecial|tree.RejectSubqueries)
+
+	buildArg := func(arg tree.Expr) tree.Expr {
+		texpr, err := tree.TypeCheckAndRequire(arg, b.semaCtx, types.Float, "TABLESAMPLE")
+		if err != nil {
+			panic(builderError{err})
+		}
+		if tree.ContainsVars(texpr) || !tree.IsConst(b.evalCtx, texpr) {
+			return texpr
+		}
+		d, err := texpr.Eval(b.evalCtx)
+		if err != nil {
+			panic(builderError{err})
+		}
+		return d
+	}
+
+	res := &tree.TableSample{Method: sample.Method, Percent: buildArg(sample.Percent)}
+	if sample.Repeatable != nil {
+		res.Repeatable = buildArg(sample.Repeatable)
+	}
+	return res
+}
+
 func (b *Builder) buildSequenceSelect(seq cat.Sequence, inScope *scope) (outScope *scope) {
 	tn := seq.SequenceName()
 	md := b.factory.Metadata()
@@ -442,13 +582,23 @@ func (b *Builder) buildWithOrdinality(colName string, inScope *scope) (outScope
 	return inScope
 }
 
-func (b *Builder) buildCTE(ctes []*tree.CTE, inScope *scope) (outScope *scope) {
+func (b *Builder) buildCTE(with *tree.With, inScope *scope) (outScope *scope) {
+	if with.Recursive {
+		panic(unimplementedWithIssueDetailf(21085, "", "WITH RECURSIVE is not supported"))
+	}
+	ctes := with.CTEList
 	outScope = inScope.push()
 
 	outScope.ctes = make(map[string]*cteSource)
 	for i := range ctes {
+		if ctes[i].Mtr.Set && ctes[i].Mtr.Materialize {
+			panic(unimplementedWithIssueDetailf(21084, "materialized",
+				"MATERIALIZED common table expressions are not supported"))
+		}
+		locking := b.locking
+		b.locking = noRowLocking
 		cteScope := b.buildStmt(ctes[i].Stmt, outScope)
-		cols := cteScope.cols
+		b.locking = locking
 		name := ctes[i].Name.Alias
 
 		if _, ok := outScope.ctes[name.String()]; ok {
@@ -457,36 +607,23 @@ func (b *Builder) buildCTE(ctes []*tree.CTE, inScope *scope) (outScope *scope) {
 			})
 		}
 
-		// Names for the output columns can optionally be specified.
-		if ctes[i].Name.Cols != nil {
-			if len(cteScope.cols) != len(ctes[i].Name.Cols) {
-				panic(builderError{
-					fmt.Errorf(
-						"source %q has %d columns available but %d columns specified",
-						name, len(cteScope.cols), len(ctes[i].Name.Cols),
-					),
-				})
-			}
-
-			cols = make([]scopeColumn, len(cteScope.cols))
-			tableName := tree.MakeUnqualifiedTableName(ctes[i].Name.Alias)
-			copy(cols, cteScope.cols)
-			for j := range cols {
-				cols[j].name = ctes[i].Name.Cols[j]
-				cols[j].table = tableName
-			}
-		}
-
-		if len(cols) == 0 {
-			panic(pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
-				"WITH clause %q does not have a RETURNING clause", tree.ErrString(&name)))
-		}
-
-		outScope.ctes[ctes[i].Name.Alias.String()] = &cteSource{
+		cte := &cteSource{
 			name: ctes[i].Name,
-			cols: cols,
+			cols: b.getCTEColumns(ctes[i], cteScope),
 			expr: cteScope.expr,
 		}
+		if ctes[i].Mtr.Set && !ctes[i].Mtr.Materialize {
+			// Later references are built in a scope that does not see the CTEs
+			// that follow this one.
+			cte.inline = true
+			cte.def = ctes[i]
+			cte.defScope = inScope.push()
+			cte.defScope.ctes = make(map[string]*cteSource, len(outScope.ctes))
+			for alias, source := range outScope.ctes {
+				cte.defScope.ctes[alias] = source
+			}
+		}
+		outScope.ctes[name.String()] = cte
 	}
 
 	telemetry.Inc(sqltelemetry.CteUseCounter)
@@ -494,6 +631,39 @@ func (b *Builder) buildCTE(ctes []*tree.CTE, inScope *scope) (outScope *scope) {
 	return outScope
 }
 
+// getCTEColumns returns the output columns of a CTE, given the scope its
+// statement was built in. Names for the output columns can optionally be
+// specified by the CTE.
+func (b *Builder) getCTEColumns(cte *tree.CTE, cteScope *scope) []scopeColumn {
+	cols := cteScope.cols
+	name := cte.Name.Alias
+
+	if cte.Name.Cols != nil {
+		if len(cteScope.cols) != len(cte.Name.Cols) {
+			panic(builderError{
+				fmt.Errorf(
+					"source %q has %d columns available but %d columns specified",
+					name, len(cteScope.cols), len(cte.Name.Cols),
+				),
+			})
+		}
+
+		cols = make([]scopeColumn, len(cteScope.cols))
+		tableName := tree.MakeUnqualifiedTableName(cte.Name.Alias)
+		copy(cols, cteScope.cols)
+		for j := range cols {
+			cols[j].name = cte.Name.Cols[j]
+			cols[j].table = tableName
+		}
+	}
+
+	if len(cols) == 0 {
+		panic(pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"WITH clause %q does not have a RETURNING clause", tree.ErrString(&name)))
+	}
+	return cols
+}
+
 // checkCTEUsage ensures that a CTE that contains a mutation (like INSERT) is
 // used at least once by the query. Otherwise, it might not be executed.
 func (b *Builder) checkCTEUsage(inScope *scope) {
@@ -544,6 +714,7 @@ func (b *Builder) buildSelect(
 	orderBy := stmt.OrderBy
 	limit := stmt.Limit
 	with := stmt.With
+	locking := noRowLocking.append(stmt.Locking...)
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		stmt = s.Select
@@ -573,12 +744,20 @@ func (b *Builder) buildSelect(
 			}
 			limit = stmt.Limit
 		}
+		locking = locking.append(stmt.Locking...)
 	}
 
+	// The locking items of this query apply in addition to the ones inherited
+	// from the enclosing query, if this is a subquery in its FROM clause.
+	inherited := b.locking
+	defer func() { b.locking = inherited }()
+
 	if with != nil {
-		inScope = b.buildCTE(with.CTEList, inScope)
+		b.locking = noRowLocking
+		inScope = b.buildCTE(with, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
+	b.locking = inherited.append(locking...)
 
 	// NB: The case statements are sorted lexicographically.
 	switch t := stmt.Select.(type) {
@@ -629,7 +808,15 @@ func (b *Builder) buildSelect(
 func (b *Builder) buildSelectClause(
 	sel *tree.SelectClause, orderBy tree.OrderBy, desiredTypes []types.T, inScope *scope,
 ) (outScope *scope) {
+	sel, orderBy, err := tree.ExpandGroupingSets(b.semaCtx.SearchPath, sel, orderBy)
+	if err != nil {
+		panic(builderError{err})
+	}
+
 	fromScope := b.buildFrom(sel.From, inScope)
+	if b.locking.isSet() {
+		b.validateLockingTargets(fromScope)
+	}
 	b.buildWhere(sel.Where, fromScope)
 
 	projectionsScope := fromScope.replace()
@@ -645,6 +832,9 @@ func (b *Builder) buildSelectClause(
 	havingExpr := b.analyzeHaving(sel.Having, fromScope)
 	orderByScope := b.analyzeOrderBy(orderBy, fromScope, projectionsScope)
 	distinctOnScope := b.analyzeDistinctOnArgs(sel.DistinctOn, fromScope, projectionsScope)
+	if b.locking.isSet() {
+		b.validateLockingInSelectClause(sel, fromScope)
+	}
 
 	if b.needsAggregation(sel, fromScope) {
 		outScope = b.buildAggregation(
@@ -728,10 +918,27 @@ func (b *Builder) buildWhere(where *tree.Where, inScope *scope) {
 	)
 }
 
-// buildFromTables recursively builds a series of InnerJoin expressions that
-// join together the given FROM tables. The tables are joined in the reverse
-// order that they appear in the list, with the innermost join involving the
-// tables at the end of the list. For example:
+// buildFromTables builds a series of InnerJoin expressions that join together
+// the given FROM tables.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildFromTables(tables tree.TableExprs, inScope *scope) (outScope *scope) {
+	// If there are any LATERAL data sources, the tables are joined left-deep
+	// instead, so that the LATERAL ones can refer to the tables preceding them.
+	for i := range tables {
+		if isLateral(tables[i]) {
+			telemetry.Inc(sqltelemetry.LateralJoinUseCounter)
+			return b.buildFromWithLateral(tables, inScope)
+		}
+	}
+	return b.buildFromTablesRightDeep(tables, inScope)
+}
+
+// buildFromTablesRightDeep recursively builds a series of InnerJoin expressions
+// that join together the given FROM tables. The tables are joined in the
+// reverse order that they appear in the list, with the innermost join
+// involving the tables at the end of the list. For example:
 //
 //   SELECT * FROM a,b,c
 //
@@ -741,7 +948,9 @@ func (b *Builder) buildWhere(where *tree.Where, inScope *scope) {
 //
 // See Builder.buildStmt for a description of the remaining input and
 // return values.
-func (b *Builder) buildFromTables(tables tree.TableExprs, inScope *scope) (outScope *scope) {
+func (b *Builder) buildFromTablesRightDeep(
+	tables tree.TableExprs, inScope *scope,
+) (outScope *scope) {
 	outScope = b.buildDataSource(tables[0], nil /* indexFlags */, inScope)
 
 	// Recursively build table join.
@@ -749,7 +958,7 @@ func (b *Builder) buildFromTables(tables tree.TableExprs, inScope *scope) (outSc
 	if len(tables) == 0 {
 		return outScope
 	}
-	tableScope := b.buildFromTables(tables, inScope)
+	tableScope := b.buildFromTablesRightDeep(tables, inScope)
 
 	// Check that the same table name is not used multiple times.
 	b.validateJoinTableNames(outScope, tableScope)
@@ -762,6 +971,60 @@ func (b *Builder) buildFromTables(tables tree.TableExprs, inScope *scope) (outSc
 	return outScope
 }
 
+// buildFromWithLateral builds a series of InnerJoin expressions that join
+// together the given FROM tables, some of which are LATERAL. The tables are
+// joined in the order that they appear in the list, with the innermost join
+// involving the first two tables. For example:
+//
+//   SELECT * FROM a, LATERAL (SELECT * FROM b WHERE b.x = a.x), c
+//
+// is joined like:
+//
+//   SELECT * FROM (a JOIN LATERAL (SELECT * FROM b WHERE b.x = a.x) ON true) JOIN c ON true
+//
+// A LATERAL table is built in a scope which has the columns of the tables
+// preceding it, and is joined to them with an InnerJoinApply, which the
+// optimizer then tries to decorrelate.
+//
+// See Builder.buildStmt for a description of the remaining input and
+// return values.
+func (b *Builder) buildFromWithLateral(tables tree.TableExprs, inScope *scope) (outScope *scope) {
+	outScope = b.buildDataSource(tables[0], nil /* indexFlags */, inScope)
+	for _, table := range tables[1:] {
+		lateral := isLateral(table)
+		tableInScope := inScope
+		if lateral {
+			tableInScope = outScope
+		}
+		tableScope := b.buildDataSource(table, nil /* indexFlags */, tableInScope)
+
+		// Check that the same table name is not used multiple times.
+		b.validateJoinTableNames(outScope, tableScope)
+
+		outScope.appendColumnsFromScope(tableScope)
+
+		left := outScope.expr.(memo.RelExpr)
+		right := tableScope.expr.(memo.RelExpr)
+		if lateral {
+			outScope.expr = b.factory.ConstructInnerJoinApply(
+				left, right, memo.TrueFilter, memo.EmptyJoinPrivate,
+			)
+		} else {
+			outScope.expr = b.factory.ConstructInnerJoin(
+				left, right, memo.TrueFilter, memo.EmptyJoinPrivate,
+			)
+		}
+	}
+	return outScope
+}
+
+// isLateral returns true if the table expression is a LATERAL subquery or
+// function call.
+func isLateral(table tree.TableExpr) bool {
+	ate, ok := table.(*tree.AliasedTableExpr)
+	return ok && ate.Lateral
+}
+
 // validateAsOf ensures that any AS OF SYSTEM TIME timestamp is consistent with
 // that of the root statement.
 func (b *Builder) validateAsOf(asOf tree.AsOfClause) {
@@ -31,6 +31,9 @@ import (
 func (b *Builder) buildUnion(
 	clause *tree.UnionClause, desiredTypes []types.T, inScope *scope,
 ) (outScope *scope) {
+	if b.locking.isSet() {
+		panic(b.locking.lockingClauseError("%s is not allowed with UNION/INTERSECT/EXCEPT"))
+	}
 	leftScope := b.buildSelect(clause.Left, desiredTypes, inScope)
 	rightScope := b.buildSelect(clause.Right, desiredTypes, inScope)
 
@@ -77,7 +77,7 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	}
 
 	if upd.With != nil {
-		inScope = b.buildCTE(upd.With.CTEList, inScope)
+		inScope = b.buildCTE(upd.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -87,16 +87,16 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(upd.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab, resName := b.resolveTable(tn, privilege.UPDATE)
+	ds, resName := b.resolveMutationTarget(tn, privilege.UPDATE)
 	if alias == nil {
 		alias = &resName
 	}
 
 	// Check Select permission as well, since existing values must be read.
-	b.checkPrivilege(tn, tab, privilege.SELECT)
+	b.checkPrivilege(tn, ds, privilege.SELECT)
 
 	var mb mutationBuilder
-	mb.init(b, opt.UpdateOp, tab, *alias)
+	mb.init(b, opt.UpdateOp, ds, *alias)
 
 	// Build the input expression that selects the rows that will be updated:
 	//
@@ -221,6 +221,8 @@ func (mb *mutationBuilder) addUpdateCols(exprs tree.UpdateExprs) {
 		// Allow right side of SET to be DEFAULT.
 		if _, ok := expr.(tree.DefaultVal); ok {
 			expr = mb.parseDefaultOrComputedExpr(targetColID)
+		} else {
+			mb.checkIdentityColForUpdate(targetColID)
 		}
 
 		// Add new column to the projections scope.
@@ -245,6 +247,7 @@ func (mb *mutationBuilder) addUpdateCols(exprs tree.UpdateExprs) {
 
 				// Type check and rename columns.
 				for i := range subqueryScope.cols {
+					mb.checkIdentityColForUpdate(mb.targetColList[n])
 					scopeColOrd := scopeOrdinal(len(projectionsScope.cols) + i)
 					checkCol(&subqueryScope.cols[i], scopeColOrd, mb.targetColList[n])
 					n++
@@ -322,6 +325,10 @@ func (mb *mutationBuilder) addComputedColsForUpdate() {
 // buildUpdate constructs an Update operator, possibly wrapped by a Project
 // operator that corresponds to the given RETURNING clause.
 func (mb *mutationBuilder) buildUpdate(returning tree.ReturningExprs) {
+	// Ensure that updated rows are still visible through the target view, if it
+	// was created WITH CHECK OPTION.
+	mb.addViewCheckOption()
+
 	mb.addCheckConstraintCols()
 
 	private := mb.makeMutationPrivate(returning != nil)
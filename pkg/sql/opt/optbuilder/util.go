@@ -91,6 +91,9 @@ func (b *Builder) expandStar(
 		for i := range inScope.cols {
 			col := &inScope.cols[i]
 			if col.table == *src && !col.hidden {
+				if col.privErr != nil {
+					panic(builderError{col.privErr})
+				}
 				exprs = append(exprs, col)
 				aliases = append(aliases, string(col.name))
 			}
@@ -103,6 +106,9 @@ func (b *Builder) expandStar(
 		for i := range inScope.cols {
 			col := &inScope.cols[i]
 			if !col.hidden {
+				if col.privErr != nil {
+					panic(builderError{col.privErr})
+				}
 				exprs = append(exprs, col)
 				aliases = append(aliases, string(col.name))
 			}
@@ -439,6 +445,22 @@ func (b *Builder) resolveTable(
 	return tab, resName
 }
 
+// resolveMutationTarget returns the data source in the catalog with the given
+// name, which is the target of an INSERT, UPDATE, or DELETE statement. If the
+// name does not resolve to a table or a view, or if the current user does not
+// have the given privilege, then resolveMutationTarget raises an error. Whether
+// a view can be mutated is checked later, by mutationBuilder.init.
+func (b *Builder) resolveMutationTarget(
+	tn *tree.TableName, priv privilege.Kind,
+) (cat.DataSource, tree.TableName) {
+	ds, resName := b.resolveDataSource(tn, priv)
+	switch ds.(type) {
+	case cat.Table, cat.View:
+		return ds, resName
+	}
+	panic(builderError{sqlbase.NewWrongObjectTypeError(tn, "table")})
+}
+
 // resolveDataSource returns the data source in the catalog with the given name.
 // If the name does not resolve to a table, or if the current user does not have
 // the given privilege, then resolveDataSource raises an error.
@@ -453,6 +475,53 @@ func (b *Builder) resolveDataSource(
 	return ds, resName
 }
 
+// resolveDataSourceForSelect is similar to resolveDataSource with the SELECT
+// privilege, except that if the data source is a table on which the current
+// user does not have the SELECT privilege, but has the SELECT privilege on some
+// of its columns, then no error is raised. Instead, the returned slice
+// contains, for each column ordinal, the error to raise if that column is
+// referenced, or nil if it can be. The slice is nil if the user has the SELECT
+// privilege on the whole data source.
+func (b *Builder) resolveDataSourceForSelect(
+	tn *tree.TableName,
+) (cat.DataSource, cat.DataSourceName, []error) {
+	ds, resName, err := b.catalog.ResolveDataSource(b.ctx, tn)
+	if err != nil {
+		panic(builderError{err})
+	}
+	if b.skipSelectPrivilegeChecks {
+		b.checkPrivilege(tn, ds, privilege.SELECT)
+		return ds, resName, nil
+	}
+
+	err = b.catalog.CheckPrivilege(b.ctx, ds, privilege.SELECT)
+	if err == nil {
+		b.factory.Metadata().AddDataSourceDependency(tn, ds, privilege.SELECT)
+		return ds, resName, nil
+	}
+	tab, ok := ds.(cat.Table)
+	if !ok {
+		panic(builderError{err})
+	}
+
+	colPrivErrs := make([]error, tab.ColumnCount())
+	anyAllowed := false
+	for ord := range colPrivErrs {
+		colPrivErrs[ord] = b.catalog.CheckColumnPrivilege(b.ctx, tab, ord, privilege.SELECT)
+		if colPrivErrs[ord] == nil {
+			anyAllowed = true
+		}
+	}
+	if !anyAllowed {
+		panic(builderError{err})
+	}
+
+	// The table-level check fails when the metadata is checked for freshness,
+	// so a memo built with column-level privileges is never reused as is.
+	b.factory.Metadata().AddDataSourceDependency(tn, ds, privilege.SELECT)
+	return ds, resName, colPrivErrs
+}
+
 // resolveDataSourceFromRef returns the data source in the catalog that matches
 // the given TableRef spec. If no data source matches, or if the current user
 // does not have the given privilege, then resolveDataSourceFromRef raises an
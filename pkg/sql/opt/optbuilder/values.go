@@ -31,6 +31,9 @@ import (
 func (b *Builder) buildValuesClause(
 	values *tree.ValuesClause, desiredTypes []types.T, inScope *scope,
 ) (outScope *scope) {
+	if b.locking.isSet() {
+		panic(b.locking.lockingClauseError("%s cannot be applied to VALUES"))
+	}
 	var numCols int
 	if len(values.Rows) > 0 {
 		numCols = len(values.Rows[0])
@@ -139,6 +139,11 @@ func init() {
 		buildChildReqOrdering: limitOrOffsetBuildChildReqOrdering,
 		buildProvidedOrdering: limitOrOffsetBuildProvided,
 	}
+	funcMap[opt.LimitWithTiesOp] = funcs{
+		canProvideOrdering:    limitOrOffsetCanProvideOrdering,
+		buildChildReqOrdering: limitOrOffsetBuildChildReqOrdering,
+		buildProvidedOrdering: limitOrOffsetBuildProvided,
+	}
 	funcMap[opt.OffsetOp] = funcs{
 		canProvideOrdering:    limitOrOffsetCanProvideOrdering,
 		buildChildReqOrdering: limitOrOffsetBuildChildReqOrdering,
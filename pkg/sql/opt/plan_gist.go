@@ -0,0 +1,289 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package opt
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// PlanGist describes the shape of a query plan: the operators of the plan,
+// the tables, indexes and join types they use, and how they are nested. It
+// does not describe the columns, filters, constraints or cost estimates of
+// the plan, so plans of the same statement which only differ by their
+// constants have the same gist. The gist of a plan is formatted as:
+//
+//   <op>[<args>](<input>, <input>, ...)
+//
+// For example:
+//
+//   sort(lookup-join[inner-join,t2@2](scan[t1@1]))
+//
+// Tables are identified by their position in the metadata of the query
+// (t1 is the first table referenced by the query) and indexes by their
+// stable ID, so a gist is only meaningful for the statement it was computed
+// for. The relational expressions of subqueries are inputs of the operator
+// which contains the subquery.
+type PlanGist struct {
+	Op Operator
+	// Args are the tables and indexes used by the operator, as formatted by
+	// PlanGistTableArg and PlanGistIndexArg, and its join type, if any.
+	Args   []string
+	Inputs []*PlanGist
+}
+
+// PlanGistTableArg returns the PlanGist argument which identifies the given
+// table.
+func PlanGistTableArg(tabID TableID) string {
+	return "t" + strconv.Itoa(tabID.index()+1)
+}
+
+// PlanGistIndexArg returns the PlanGist argument which identifies the given
+// index of the given table.
+func PlanGistIndexArg(md *Metadata, tabID TableID, indexOrd int) string {
+	id := md.Table(tabID).Index(indexOrd).ID()
+	return PlanGistTableArg(tabID) + "@" + strconv.FormatUint(uint64(id), 10)
+}
+
+// planGistArgTable returns the table identified by the given argument, as a
+// position in the metadata of the query, or -1 if the argument doesn't
+// identify a table.
+func planGistArgTable(arg string) int {
+	if len(arg) < 2 || arg[0] != 't' {
+		return -1
+	}
+	if i := strings.IndexByte(arg, '@'); i >= 0 {
+		arg = arg[:i]
+	}
+	n, err := strconv.Atoi(arg[1:])
+	if err != nil || n < 1 {
+		return -1
+	}
+	return n - 1
+}
+
+// Format writes the gist to the given buffer.
+func (g *PlanGist) Format(buf *bytes.Buffer) {
+	buf.WriteString(g.Op.String())
+	if len(g.Args) > 0 {
+		buf.WriteByte('[')
+		buf.WriteString(strings.Join(g.Args, ","))
+		buf.WriteByte(']')
+	}
+	if len(g.Inputs) > 0 {
+		buf.WriteByte('(')
+		for i, in := range g.Inputs {
+			if i > 0 {
+				buf.WriteString(", ")
+			}
+			in.Format(buf)
+		}
+		buf.WriteByte(')')
+	}
+}
+
+// String returns the formatted gist.
+func (g *PlanGist) String() string {
+	var buf bytes.Buffer
+	g.Format(&buf)
+	return buf.String()
+}
+
+// PlanGistArgTables returns the set of tables identified by the given
+// arguments of an operator, as positions in the metadata of the query.
+func PlanGistArgTables(args []string) util.FastIntSet {
+	var res util.FastIntSet
+	for _, arg := range args {
+		if t := planGistArgTable(arg); t >= 0 {
+			res.Add(t)
+		}
+	}
+	return res
+}
+
+// Tables returns the set of tables used by the plan, as positions in the
+// metadata of the query.
+func (g *PlanGist) Tables() util.FastIntSet {
+	res := PlanGistArgTables(g.Args)
+	for _, in := range g.Inputs {
+		res.UnionWith(in.Tables())
+	}
+	return res
+}
+
+// Signatures returns the signatures of all the operators of the plan. See
+// PlanGistSignature.
+func (g *PlanGist) Signatures() map[string]struct{} {
+	res := make(map[string]struct{})
+	var visit func(g *PlanGist) util.FastIntSet
+	visit = func(g *PlanGist) util.FastIntSet {
+		inputTables := make([]util.FastIntSet, len(g.Inputs))
+		var tables util.FastIntSet
+		for i, in := range g.Inputs {
+			inputTables[i] = visit(in)
+			tables.UnionWith(inputTables[i])
+		}
+		res[PlanGistSignature(g.Op, g.Args, inputTables)] = struct{}{}
+		tables.UnionWith(PlanGistArgTables(g.Args))
+		return tables
+	}
+	visit(g)
+	return res
+}
+
+// PlanGistSignature returns the signature of an operator of a plan: its
+// operator and arguments, along with the tables used by each of its inputs.
+// Unlike a full PlanGist, the signature of an operator can be computed
+// before the inputs of the operator are planned, since logically equivalent
+// inputs use the same tables. inputTables are the tables used by each input,
+// as returned by PlanGist.Tables.
+func PlanGistSignature(op Operator, args []string, inputTables []util.FastIntSet) string {
+	var buf bytes.Buffer
+	buf.WriteString(op.String())
+	buf.WriteByte('[')
+	buf.WriteString(strings.Join(args, ","))
+	buf.WriteByte(']')
+	for _, tables := range inputTables {
+		buf.WriteByte('{')
+		first := true
+		tables.ForEach(func(t int) {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			buf.WriteString(strconv.Itoa(t + 1))
+		})
+		buf.WriteByte('}')
+	}
+	return buf.String()
+}
+
+// operatorsByName maps the names of the operators to the operators.
+var operatorsByName = func() map[string]Operator {
+	m := make(map[string]Operator, NumOperators)
+	for op := Operator(1); op < NumOperators; op++ {
+		m[op.String()] = op
+	}
+	return m
+}()
+
+// ParsePlanGist parses a formatted PlanGist.
+func ParsePlanGist(s string) (*PlanGist, error) {
+	p := planGistParser{s: s}
+	g, err := p.parseNode()
+	if err == nil {
+		p.skipSpaces()
+		if p.pos < len(p.s) {
+			err = p.errorf("unexpected %q", p.s[p.pos:])
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+type planGistParser struct {
+	s   string
+	pos int
+}
+
+func (p *planGistParser) errorf(format string, args ...interface{}) error {
+	return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+		"invalid plan gist %q: %s", p.s, fmt.Sprintf(format, args...))
+}
+
+func (p *planGistParser) skipSpaces() {
+	for p.pos < len(p.s) && p.s[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space character, or 0 at the end of the gist.
+func (p *planGistParser) peek() byte {
+	p.skipSpaces()
+	if p.pos == len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// word returns the next word, which contains letters, digits, '-' and '@'.
+func (p *planGistParser) word() string {
+	p.skipSpaces()
+	start := p.pos
+	for ; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '@') {
+			break
+		}
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *planGistParser) parseNode() (*PlanGist, error) {
+	name := p.word()
+	if name == "" {
+		if p.pos == len(p.s) {
+			return nil, p.errorf("unexpected end of gist")
+		}
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	op, ok := operatorsByName[name]
+	if !ok {
+		return nil, p.errorf("unknown operator %s", name)
+	}
+	g := &PlanGist{Op: op}
+	if p.peek() == '[' {
+		p.pos++
+		for {
+			arg := p.word()
+			if arg == "" {
+				return nil, p.errorf("invalid arguments for %s", name)
+			}
+			g.Args = append(g.Args, arg)
+			if c := p.peek(); c == ']' {
+				p.pos++
+				break
+			} else if c != ',' {
+				return nil, p.errorf("invalid arguments for %s", name)
+			}
+			p.pos++
+		}
+	}
+	if p.peek() == '(' {
+		p.pos++
+		for {
+			in, err := p.parseNode()
+			if err != nil {
+				return nil, err
+			}
+			g.Inputs = append(g.Inputs, in)
+			if c := p.peek(); c == ')' {
+				p.pos++
+				break
+			} else if c != ',' {
+				return nil, p.errorf("invalid inputs for %s", name)
+			}
+			p.pos++
+		}
+	}
+	return g, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package opt_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestParsePlanGist(t *testing.T) {
+	testData := []struct {
+		in       string
+		expected string
+	}{
+		{`scan[t1@1]`, ``},
+		{`values`, ``},
+		{`sort(lookup-join[inner-join,t2@2](scan[t1@1]))`, ``},
+		{`inner-join(scan[t1@1], scan[t2@1])`, ``},
+		{`select( scan [ t1@2 ] )`, `select(scan[t1@2])`},
+		{`zigzag-join[t1@2,t1@3]`, ``},
+	}
+	for _, d := range testData {
+		t.Run(d.in, func(t *testing.T) {
+			g, err := opt.ParsePlanGist(d.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := d.expected
+			if expected == "" {
+				expected = d.in
+			}
+			if s := g.String(); s != expected {
+				t.Errorf("expected %s, got %s", expected, s)
+			}
+		})
+	}
+}
+
+func TestParsePlanGistError(t *testing.T) {
+	testData := []struct {
+		in    string
+		error string
+	}{
+		{``, `unexpected end of gist`},
+		{`foo`, `unknown operator foo`},
+		{`scan[`, `invalid arguments for scan`},
+		{`scan[t1@1`, `invalid arguments for scan`},
+		{`select(scan[t1@1]`, `invalid inputs for select`},
+		{`select(`, `unexpected end of gist`},
+		{`scan[t1@1] scan`, `unexpected "scan"`},
+	}
+	for _, d := range testData {
+		t.Run(d.in, func(t *testing.T) {
+			_, err := opt.ParsePlanGist(d.in)
+			if !testutils.IsError(err, d.error) {
+				t.Fatalf("expected error %q, got %v", d.error, err)
+			}
+		})
+	}
+}
+
+func TestPlanGistSignatures(t *testing.T) {
+	g, err := opt.ParsePlanGist(`sort(lookup-join[inner-join,t2@2](scan[t1@1]))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tables := g.Tables().String(); tables != "(0,1)" {
+		t.Errorf("expected tables (0,1), got %s", tables)
+	}
+	sigs := g.Signatures()
+	for _, expected := range []string{
+		`scan[t1@1]`,
+		`lookup-join[inner-join,t2@2]{1}`,
+		`sort[]{1,2}`,
+	} {
+		if _, ok := sigs[expected]; !ok {
+			t.Errorf("missing signature %s in %v", expected, sigs)
+		}
+	}
+	if len(sigs) != 3 {
+		t.Errorf("expected 3 signatures, got %v", sigs)
+	}
+}
@@ -101,6 +101,7 @@ func (t TableID) index() int {
 // Currently, the following annotations are in use:
 //   - WeakKeys: weak keys derived from the base table
 //   - Stats: statistics derived from the base table
+//   - ExprStats: statistics on expressions of the base table
 //
 // To add an additional annotation, increase the value of maxTableAnnIDCount and
 // add a call to NewTableAnnID.
@@ -113,7 +114,7 @@ var tableAnnIDCount TableAnnID
 // called. Calling more than this number of times results in a panic. Having
 // a maximum enables a static annotation array to be inlined into the metadata
 // table struct.
-const maxTableAnnIDCount = 2
+const maxTableAnnIDCount = 3
 
 // TableMeta stores information about one of the tables stored in the metadata.
 type TableMeta struct {
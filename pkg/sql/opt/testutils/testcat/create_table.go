@@ -332,6 +332,16 @@ func (tt *Table) addColumn(def *tree.ColumnTableDef) {
 	if def.Computed.Expr != nil {
 		s := tree.Serialize(def.Computed.Expr)
 		col.ComputedExpr = &s
+		col.Virtual = def.Computed.Virtual
+	}
+
+	if def.IsIdentity() {
+		// The test catalog has no sequences, so identity columns get their
+		// values from unique_rowid() instead.
+		s := "unique_rowid()"
+		col.DefaultExpr = &s
+		col.Identity = true
+		col.Always = def.Identity.Always
 	}
 
 	tt.Columns = append(tt.Columns, col)
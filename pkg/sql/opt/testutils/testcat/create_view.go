@@ -30,6 +30,7 @@ func (tc *Catalog) CreateView(stmt *tree.CreateView) *View {
 		ViewName:    stmt.Name,
 		QueryText:   fmtCtx.CloseAndGetString(),
 		ColumnNames: stmt.ColumnNames,
+		WithCheck:   stmt.CheckOption,
 	}
 
 	// Add the new view to the catalog.
@@ -188,6 +188,18 @@ func (tc *Catalog) CheckPrivilege(ctx context.Context, o cat.Object, priv privil
 	return nil
 }
 
+// CheckColumnPrivilege is part of the cat.Catalog interface.
+func (tc *Catalog) CheckColumnPrivilege(
+	ctx context.Context, tab cat.Table, ord int, priv privilege.Kind,
+) error {
+	t := tab.(*Table)
+	if t.Revoked {
+		return fmt.Errorf("user does not have privilege to access %v.%v",
+			t.TabName, t.Column(ord).ColName())
+	}
+	return nil
+}
+
 func (tc *Catalog) resolveSchema(toResolve *cat.SchemaName) (cat.Schema, cat.SchemaName, error) {
 	if string(toResolve.CatalogName) != testDB {
 		return nil, cat.SchemaName{}, pgerror.NewErrorf(pgerror.CodeInvalidSchemaNameError,
@@ -409,6 +421,7 @@ type View struct {
 	ViewName    cat.DataSourceName
 	QueryText   string
 	ColumnNames tree.NameList
+	WithCheck   bool
 
 	// If Revoked is true, then the user has had privileges on the view revoked.
 	Revoked bool
@@ -456,6 +469,11 @@ func (tv *View) ColumnName(i int) tree.Name {
 	return tv.ColumnNames[i]
 }
 
+// CheckOption is part of the cat.View interface.
+func (tv *View) CheckOption() bool {
+	return tv.WithCheck
+}
+
 // Table implements the cat.Table interface for testing purposes.
 type Table struct {
 	TabID      cat.StableID
@@ -528,6 +546,11 @@ func (tt *Table) IsReferenced() bool {
 	return tt.referenced
 }
 
+// HasTriggers is part of the cat.Table interface.
+func (tt *Table) HasTriggers() bool {
+	return false
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (tt *Table) ColumnCount() int {
 	return len(tt.Columns) - tt.writeOnlyColCount - tt.deleteOnlyColCount
@@ -715,6 +738,9 @@ type Column struct {
 	ColType      sqlbase.ColumnType
 	DefaultExpr  *string
 	ComputedExpr *string
+	Virtual      bool
+	Identity     bool
+	Always       bool
 }
 
 var _ cat.Column = &Column{}
@@ -769,6 +795,21 @@ func (tc *Column) IsComputed() bool {
 	return tc.ComputedExpr != nil
 }
 
+// IsVirtual is part of the cat.Column interface.
+func (tc *Column) IsVirtual() bool {
+	return tc.Virtual
+}
+
+// IsIdentity is part of the cat.Column interface.
+func (tc *Column) IsIdentity() bool {
+	return tc.Identity
+}
+
+// IsGeneratedAlwaysAsIdentity is part of the cat.Column interface.
+func (tc *Column) IsGeneratedAlwaysAsIdentity() bool {
+	return tc.Identity && tc.Always
+}
+
 // DefaultExprStr is part of the cat.Column interface.
 func (tc *Column) DefaultExprStr() string {
 	return *tc.DefaultExpr
@@ -821,6 +862,16 @@ func (ts *TableStat) NullCount() uint64 {
 	return ts.js.NullCount
 }
 
+// Expression is part of the cat.TableStatistic interface.
+func (ts *TableStat) Expression() string {
+	return ts.js.Expression
+}
+
+// Predicate is part of the cat.TableStatistic interface.
+func (ts *TableStat) Predicate() string {
+	return ts.js.Predicate
+}
+
 // TableStats is a slice of TableStat pointers.
 type TableStats []*TableStat
 
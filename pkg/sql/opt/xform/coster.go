@@ -192,6 +192,9 @@ func (c *coster) ComputeCost(candidate memo.RelExpr, required *physical.Required
 	case opt.LimitOp:
 		cost = c.computeLimitCost(candidate.(*memo.LimitExpr))
 
+	case opt.LimitWithTiesOp:
+		cost = c.computeLimitWithTiesCost(candidate.(*memo.LimitWithTiesExpr))
+
 	case opt.OffsetOp:
 		cost = c.computeOffsetCost(candidate.(*memo.OffsetExpr))
 
@@ -478,6 +481,15 @@ func (c *coster) computeLimitCost(limit *memo.LimitExpr) memo.Cost {
 	return cost
 }
 
+func (c *coster) computeLimitWithTiesCost(limit *memo.LimitWithTiesExpr) memo.Cost {
+	// Add the CPU cost of emitting the rows and of comparing them with the last
+	// row within the limit.
+	rowCount := limit.Relational().Stats.RowCount
+	cost := memo.Cost(rowCount) * cpuCostFactor
+	cost += memo.Cost(rowCount*float64(len(limit.Ordering.Columns))) * cpuCostFactor
+	return cost
+}
+
 func (c *coster) computeOffsetCost(offset *memo.OffsetExpr) memo.Cost {
 	// Add the CPU cost of emitting the rows.
 	cost := memo.Cost(offset.Relational().Stats.RowCount) * cpuCostFactor
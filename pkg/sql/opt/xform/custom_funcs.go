@@ -72,6 +72,12 @@ func (c *CustomFuncs) IsCanonicalScan(scan *memo.ScanPrivate) bool {
 //       rows from the table. See ConstrainScans and LimitScans for cases where
 //       index joins are introduced into the memo.
 func (c *CustomFuncs) GenerateIndexScans(grp memo.RelExpr, scanPrivate *memo.ScanPrivate) {
+	// A sampled scan chooses its rows among the primary index keys, so it
+	// can't use a secondary index.
+	if scanPrivate.IsSampled() {
+		return
+	}
+
 	// Iterate over all secondary indexes.
 	var iter scanIndexIter
 	iter.init(c.e.mem, scanPrivate)
@@ -94,7 +100,7 @@ func (c *CustomFuncs) GenerateIndexScans(grp memo.RelExpr, scanPrivate *memo.Sca
 		// operator that provides the columns missing from the index. Note that
 		// if ForceIndex=true, scanIndexIter only returns the one index that is
 		// being forced, so no need to check that here.
-		if !scanPrivate.Flags.ForceIndex {
+		if !scanPrivate.Flags.ForceIndex || scanPrivate.IsNonBlocking() {
 			continue
 		}
 
@@ -189,6 +195,11 @@ func (c *CustomFuncs) GenerateConstrainedScans(
 	var iter scanIndexIter
 	iter.init(c.e.mem, scanPrivate)
 	for iter.next() {
+		// A sampled scan can only be constrained on the primary index.
+		if scanPrivate.IsSampled() && iter.indexOrdinal != cat.PrimaryIndex {
+			continue
+		}
+
 		// Check whether the filter can constrain the index.
 		constraint, remaining, ok := c.tryConstrainIndex(
 			filters, scanPrivate.Table, iter.indexOrdinal, false /* isInverted */)
@@ -216,7 +227,7 @@ func (c *CustomFuncs) GenerateConstrainedScans(
 
 		// Otherwise, construct an IndexJoin operator that provides the columns
 		// missing from the index.
-		if scanPrivate.Flags.NoIndexJoin {
+		if scanPrivate.Flags.NoIndexJoin || scanPrivate.IsNonBlocking() {
 			continue
 		}
 
@@ -256,6 +267,10 @@ func (c *CustomFuncs) HasInvertedIndexes(scanPrivate *memo.ScanPrivate) bool {
 func (c *CustomFuncs) GenerateInvertedIndexScans(
 	grp memo.RelExpr, scanPrivate *memo.ScanPrivate, filters memo.FiltersExpr,
 ) {
+	if scanPrivate.IsNonBlocking() || scanPrivate.IsSampled() {
+		return
+	}
+
 	var sb indexScanBuilder
 	sb.init(c, scanPrivate.Table)
 
@@ -463,6 +478,12 @@ func (c *CustomFuncs) CanLimitConstrainedScan(
 		return false
 	}
 
+	if scanPrivate.IsSampled() {
+		// The limit applies to the sampled rows, so it can't be pushed into
+		// the scan.
+		return false
+	}
+
 	if scanPrivate.Constraint == nil {
 		// This is not a constrained scan, so skip it. The PushLimitIntoScan rule
 		// is responsible for limited unconstrained scans.
@@ -488,6 +509,10 @@ func (c *CustomFuncs) GenerateLimitedScans(
 	limit tree.Datum,
 	required physical.OrderingChoice,
 ) {
+	if scanPrivate.IsSampled() {
+		return
+	}
+
 	limitVal := int64(*limit.(*tree.DInt))
 
 	var sb indexScanBuilder
@@ -521,7 +546,7 @@ func (c *CustomFuncs) GenerateLimitedScans(
 
 		// Otherwise, try to construct an IndexJoin operator that provides the
 		// columns missing from the index.
-		if scanPrivate.Flags.NoIndexJoin {
+		if scanPrivate.Flags.NoIndexJoin || scanPrivate.IsNonBlocking() {
 			continue
 		}
 
@@ -688,6 +713,11 @@ func (c *CustomFuncs) GenerateMergeJoins(
 //     "sides" (in this example x,y on the left and z on the right) but there is
 //     no overlap.
 //
+//  3. The join is a semi or anti join. Only the input columns are output, so
+//     the index only needs to contain the columns used by the ON condition; a
+//     single LookupJoin expression is generated, which only looks up these
+//     columns (and the key columns).
+//
 func (c *CustomFuncs) GenerateLookupJoins(
 	grp memo.RelExpr,
 	joinType opt.Operator,
@@ -696,7 +726,8 @@ func (c *CustomFuncs) GenerateLookupJoins(
 	on memo.FiltersExpr,
 	joinPrivate *memo.JoinPrivate,
 ) {
-	if joinPrivate.Flags.DisallowLookupJoin {
+	if joinPrivate.Flags.DisallowLookupJoin || scanPrivate.IsNonBlocking() ||
+		scanPrivate.IsSampled() {
 		return
 	}
 	inputProps := input.Relational()
@@ -740,6 +771,24 @@ func (c *CustomFuncs) GenerateLookupJoins(
 
 		lookupJoin.On = memo.ExtractRemainingJoinFilters(on, lookupJoin.KeyCols, rightSideCols)
 
+		if joinType == opt.SemiJoinOp || joinType == opt.AntiJoinOp {
+			// Case 3 (see function comment).
+			lookupCols := lookupJoin.On.OuterCols(c.e.mem).Intersection(scanPrivate.Cols)
+			if !lookupCols.SubsetOf(iter.indexCols()) {
+				// The ON condition needs columns which are not in the index; we
+				// don't generate index joins for semi and anti joins.
+				continue
+			}
+			// The key columns are also retrieved so that there is at least one
+			// lookup column.
+			for _, col := range rightSideCols {
+				lookupCols.Add(int(col))
+			}
+			lookupJoin.Cols = lookupCols.Union(inputProps.OutputCols)
+			c.e.mem.AddLookupJoinToGroup(&lookupJoin, grp)
+			continue
+		}
+
 		if iter.isCovering() {
 			// Case 1 (see function comment).
 			lookupJoin.Cols = scanPrivate.Cols.Union(inputProps.OutputCols)
@@ -942,7 +991,8 @@ func (c *CustomFuncs) GenerateZigzagJoins(
 ) {
 
 	// Short circuit unless zigzag joins are explicitly enabled.
-	if !c.e.evalCtx.SessionData.ZigzagJoinEnabled {
+	if !c.e.evalCtx.SessionData.ZigzagJoinEnabled || scanPrivate.IsNonBlocking() ||
+		scanPrivate.IsSampled() {
 		return
 	}
 
@@ -1164,7 +1214,8 @@ func (c *CustomFuncs) GenerateInvertedIndexZigzagJoins(
 	grp memo.RelExpr, scanPrivate *memo.ScanPrivate, filters memo.FiltersExpr,
 ) {
 	// Short circuit unless zigzag joins are explicitly enabled.
-	if !c.e.evalCtx.SessionData.ZigzagJoinEnabled {
+	if !c.e.evalCtx.SessionData.ZigzagJoinEnabled || scanPrivate.IsNonBlocking() ||
+		scanPrivate.IsSampled() {
 		return
 	}
 
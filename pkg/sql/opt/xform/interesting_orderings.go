@@ -45,7 +45,7 @@ func DeriveInterestingOrderings(e memo.RelExpr) opt.OrderingSet {
 	case opt.GroupByOp, opt.ScalarGroupByOp:
 		res = interestingOrderingsForGroupBy(e)
 
-	case opt.LimitOp, opt.OffsetOp:
+	case opt.LimitOp, opt.LimitWithTiesOp, opt.OffsetOp:
 		res = interestingOrderingsForLimit(e)
 
 	default:
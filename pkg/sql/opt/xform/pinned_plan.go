@@ -0,0 +1,95 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package xform
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/props/physical"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// pinnedPlanPenalty is the cost added to every expression that is not part of
+// a pinned plan. It is large enough to dominate the cost of any realistic
+// plan, so that the lowest cost plan has as few such expressions as possible,
+// but small enough that the costs of plans with the same number of such
+// expressions can still be compared.
+const pinnedPlanPenalty = memo.Cost(1e15)
+
+// PinPlan makes the optimizer choose the plan with the given gist, if it is
+// one of the plans it considers. Otherwise, the optimizer chooses the plan
+// that is the closest to the pinned plan, in that it has the fewest operators
+// which are not part of the pinned plan; callers can compare the gist of the
+// resulting plan with the pinned gist to detect this case. PinPlan must be
+// called after Init and before Optimize; it replaces the coster of the
+// optimizer by one that wraps it.
+func (o *Optimizer) PinPlan(gist *opt.PlanGist) {
+	o.SetCoster(&pinnedPlanCoster{
+		mem:        o.mem,
+		inner:      o.coster,
+		signatures: gist.Signatures(),
+		tables:     make(map[memo.RelExpr]util.FastIntSet),
+	})
+}
+
+// pinnedPlanCoster is a Coster that penalizes the expressions which are not
+// part of a pinned plan. An expression is considered to be part of the
+// pinned plan when the plan has an operator with the same signature (see
+// opt.PlanGistSignature).
+type pinnedPlanCoster struct {
+	mem   *memo.Memo
+	inner Coster
+
+	// signatures are the signatures of the operators of the pinned plan.
+	signatures map[string]struct{}
+
+	// tables caches the tables used by the groups of the memo, keyed by the
+	// first expression of their group.
+	tables map[memo.RelExpr]util.FastIntSet
+}
+
+var _ Coster = &pinnedPlanCoster{}
+
+// ComputeCost is part of the Coster interface.
+func (c *pinnedPlanCoster) ComputeCost(
+	candidate memo.RelExpr, required *physical.Required,
+) memo.Cost {
+	cost := c.inner.ComputeCost(candidate, required)
+	var inputTables []util.FastIntSet
+	memo.PlanGistInputs(c.mem, candidate, func(in memo.RelExpr) {
+		inputTables = append(inputTables, c.groupTables(in))
+	})
+	args := memo.PlanGistArgs(c.mem.Metadata(), candidate)
+	if _, ok := c.signatures[opt.PlanGistSignature(candidate.Op(), args, inputTables)]; !ok {
+		cost += pinnedPlanPenalty
+	}
+	return cost
+}
+
+// groupTables returns the tables used by the group of the given expression.
+// Since the expressions of a group are logically equivalent, they use the
+// same tables.
+func (c *pinnedPlanCoster) groupTables(e memo.RelExpr) util.FastIntSet {
+	first := e.FirstExpr()
+	if tables, ok := c.tables[first]; ok {
+		return tables
+	}
+	tables := opt.PlanGistArgTables(memo.PlanGistArgs(c.mem.Metadata(), first))
+	memo.PlanGistInputs(c.mem, first, func(in memo.RelExpr) {
+		tables.UnionWith(c.groupTables(in))
+	})
+	c.tables[first] = tables
+	return tables
+}
@@ -187,6 +187,18 @@ func (oc *optCatalog) CheckPrivilege(ctx context.Context, o cat.Object, priv pri
 	}
 }
 
+// CheckColumnPrivilege is part of the cat.Catalog interface.
+func (oc *optCatalog) CheckColumnPrivilege(
+	ctx context.Context, tab cat.Table, ord int, priv privilege.Kind,
+) error {
+	t, ok := tab.(*optTable)
+	if !ok {
+		return pgerror.NewAssertionErrorf("invalid table type: %T", tab)
+	}
+	col := &t.desc.DeletableColumns()[ord]
+	return oc.planner.CheckColumnPrivilege(ctx, t.desc.TableDesc(), col, priv)
+}
+
 // dataSourceForDesc returns a data source wrapper for the given descriptor.
 // The wrapper might come from the cache, or it may be created now.
 func (oc *optCatalog) dataSourceForDesc(
@@ -371,6 +383,11 @@ func (ov *optView) ColumnName(i int) tree.Name {
 	return tree.Name(ov.desc.Columns[i].Name)
 }
 
+// CheckOption is part of the cat.View interface.
+func (ov *optView) CheckOption() bool {
+	return ov.desc.ViewCheckOption
+}
+
 // optSequence is a wrapper around sqlbase.ImmutableTableDescriptor that
 // implements the cat.Object and cat.DataSource interfaces.
 type optSequence struct {
@@ -639,6 +656,11 @@ func (ot *optTable) IsReferenced() bool {
 	return false
 }
 
+// HasTriggers is part of the cat.Table interface.
+func (ot *optTable) HasTriggers() bool {
+	return len(ot.desc.Triggers) != 0
+}
+
 // ColumnCount is part of the cat.Table interface.
 func (ot *optTable) ColumnCount() int {
 	return len(ot.desc.Columns)
@@ -909,6 +931,8 @@ type optTableStat struct {
 	rowCount       uint64
 	distinctCount  uint64
 	nullCount      uint64
+	expression     string
+	predicate      string
 }
 
 var _ cat.TableStatistic = &optTableStat{}
@@ -918,6 +942,8 @@ func (os *optTableStat) init(tab *optTable, stat *stats.TableStatistic) (ok bool
 	os.rowCount = stat.RowCount
 	os.distinctCount = stat.DistinctCount
 	os.nullCount = stat.NullCount
+	os.expression = stat.Expression
+	os.predicate = stat.Predicate
 	os.columnOrdinals = make([]int, len(stat.ColumnIDs))
 	for i, c := range stat.ColumnIDs {
 		var ok bool
@@ -933,8 +959,9 @@ func (os *optTableStat) init(tab *optTable, stat *stats.TableStatistic) (ok bool
 
 func (os *optTableStat) equals(other *optTableStat) bool {
 	// Two table statistics are considered equal if they have been created at the
-	// same time, on the same set of columns.
-	if os.createdAt != other.createdAt || len(os.columnOrdinals) != len(other.columnOrdinals) {
+	// same time, on the same set of columns, expression and rows.
+	if os.createdAt != other.createdAt || len(os.columnOrdinals) != len(other.columnOrdinals) ||
+		os.expression != other.expression || os.predicate != other.predicate {
 		return false
 	}
 	for i, c := range os.columnOrdinals {
@@ -975,6 +1002,16 @@ func (os *optTableStat) NullCount() uint64 {
 	return os.nullCount
 }
 
+// Expression is part of the cat.TableStatistic interface.
+func (os *optTableStat) Expression() string {
+	return os.expression
+}
+
+// Predicate is part of the cat.TableStatistic interface.
+func (os *optTableStat) Predicate() string {
+	return os.predicate
+}
+
 // optFamily is a wrapper around sqlbase.ColumnFamilyDescriptor that keeps a
 // reference to the table wrapper.
 type optFamily struct {
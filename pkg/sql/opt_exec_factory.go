@@ -17,10 +17,13 @@ package sql
 import (
 	"context"
 	"fmt"
+	"math"
+	"math/rand"
 	"strconv"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
@@ -75,6 +78,9 @@ func (ef *execFactory) ConstructScan(
 	hardLimit int64,
 	reverse bool,
 	maxResults uint64,
+	lockingStrength tree.LockingStrength,
+	lockingWaitPolicy tree.LockingWaitPolicy,
+	sample *tree.TableSample,
 	reqOrdering exec.OutputOrdering,
 ) (exec.Node, error) {
 	tabDesc := table.(*optTable).desc
@@ -102,6 +108,14 @@ func (ef *execFactory) ConstructScan(
 	scan.hardLimit = hardLimit
 	scan.reverse = reverse
 	scan.maxResults = maxResults
+	scan.lockingStrength = lockingStrength
+	scan.lockingWaitPolicy = lockingWaitPolicy
+	if sample != nil {
+		var err error
+		if scan.sample, err = ef.evalTableSample(sample); err != nil {
+			return nil, err
+		}
+	}
 	scan.parallelScansEnabled = sqlbase.ParallelScans.Get(&ef.planner.extendedEvalCtx.Settings.SV)
 	var err error
 	scan.spans, err = spansFromConstraint(
@@ -124,6 +138,51 @@ func (ef *execFactory) ConstructScan(
 	return scan, nil
 }
 
+// evalTableSample evaluates the arguments of a TABLESAMPLE clause. If no
+// REPEATABLE seed is specified, a random one is used.
+func (ef *execFactory) evalTableSample(sample *tree.TableSample) (*distsqlpb.TableSampleSpec, error) {
+	res := &distsqlpb.TableSampleSpec{}
+	switch sample.Method {
+	case tree.TableSampleSystem:
+		res.Method = distsqlpb.TableSampleSpec_SYSTEM
+	case tree.TableSampleBernoulli:
+		res.Method = distsqlpb.TableSampleSpec_BERNOULLI
+	default:
+		return nil, pgerror.NewAssertionErrorf("unknown sampling method %d", sample.Method)
+	}
+
+	evalCtx := ef.planner.EvalContext()
+	percent, err := sample.Percent.(tree.TypedExpr).Eval(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	if percent == tree.DNull {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidTablesampleArgumentError,
+			"TABLESAMPLE parameter cannot be null")
+	}
+	res.Percent = float64(*percent.(*tree.DFloat))
+	// Note that this also rejects NaN.
+	if !(res.Percent >= 0 && res.Percent <= 100) {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidTablesampleArgumentError,
+			"sample percentage must be between 0 and 100")
+	}
+
+	if sample.Repeatable == nil {
+		res.Seed = rand.Int63()
+		return res, nil
+	}
+	seed, err := sample.Repeatable.(tree.TypedExpr).Eval(evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	if seed == tree.DNull {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidTablesampleRepeatError,
+			"TABLESAMPLE REPEATABLE parameter cannot be null")
+	}
+	res.Seed = int64(math.Float64bits(float64(*seed.(*tree.DFloat))))
+	return res, nil
+}
+
 // ConstructVirtualScan is part of the exec.Factory interface.
 func (ef *execFactory) ConstructVirtualScan(table cat.Table) (exec.Node, error) {
 	tn := table.Name()
@@ -160,6 +219,11 @@ func (ef *execFactory) ConstructFilter(
 		s.props.ordering = sqlbase.ColumnOrdering(reqOrdering)
 		return s, nil
 	}
+	// The filter can bound the values produced by the generators of a
+	// projectSetNode; it remains applied by the filterNode.
+	if ps, ok := n.(*projectSetNode); ok {
+		ps.constrainGenerators(ef.planner.EvalContext(), filter)
+	}
 	// Create a filterNode.
 	src := asDataSource(n)
 	f := &filterNode{
@@ -576,6 +640,7 @@ func (ef *execFactory) ConstructIndexJoin(
 	tableScan.index = &primaryIndex
 	tableScan.isSecondaryIndex = false
 	tableScan.disableBatchLimit()
+	tableScan.lockingStrength = scan.lockingStrength
 
 	primaryKeyColumns, colIDtoRowIndex := processIndexJoinColumns(tableScan, scan)
 	primaryKeyPrefix := roachpb.Key(sqlbase.MakeIndexKeyPrefix(tabDesc.TableDesc(), tableScan.index.ID))
@@ -635,7 +700,10 @@ func (ef *execFactory) ConstructLookupJoin(
 		n.keyCols[i] = int(c)
 	}
 	inputCols := planColumns(input.(planNode))
-	scanCols := planColumns(tableScan)
+	var scanCols sqlbase.ResultColumns
+	if joinType != sqlbase.LeftSemiJoin && joinType != sqlbase.LeftAntiJoin {
+		scanCols = planColumns(tableScan)
+	}
 	n.columns = make(sqlbase.ResultColumns, 0, len(inputCols)+len(scanCols))
 	n.columns = append(n.columns, inputCols...)
 	n.columns = append(n.columns, scanCols...)
@@ -770,6 +838,28 @@ func (ef *execFactory) ConstructLimit(
 	}, nil
 }
 
+// ConstructLimitWithTies is part of the exec.Factory interface.
+func (ef *execFactory) ConstructLimitWithTies(
+	input exec.Node, limit tree.TypedExpr, ordering sqlbase.ColumnOrdering,
+) (exec.Node, error) {
+	plan := input.(planNode)
+	// As in ConstructLimit, fold the limit into an existing limitNode that has
+	// just an offset. The offset is applied first, so the ordering refers to
+	// the same columns.
+	if l, ok := plan.(*limitNode); ok && l.countExpr == nil {
+		l.countExpr = limit
+		l.withTies = true
+		l.tiesOrdering = ordering
+		return l, nil
+	}
+	return &limitNode{
+		plan:         plan,
+		countExpr:    limit,
+		withTies:     true,
+		tiesOrdering: ordering,
+	}, nil
+}
+
 // ConstructMax1Row is part of the exec.Factory interface.
 func (ef *execFactory) ConstructMax1Row(input exec.Node) (exec.Node, error) {
 	plan := input.(planNode)
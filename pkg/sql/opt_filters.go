@@ -264,6 +264,11 @@ func (p *planner) propagateFilters(
 			return plan, extraFilter, err
 		}
 
+	case *recursiveCTENode:
+		if n.initial, err = p.triggerFilterPropagation(ctx, n.initial); err != nil {
+			return plan, extraFilter, err
+		}
+
 	case *createTableNode:
 		if n.n.As() {
 			if n.sourcePlan, err = p.triggerFilterPropagation(ctx, n.sourcePlan); err != nil {
@@ -360,6 +365,9 @@ func (p *planner) propagateFilters(
 		if n.source, err = p.triggerFilterPropagation(ctx, n.source); err != nil {
 			return plan, extraFilter, err
 		}
+		// The filter remains applied above the projectSetNode, but it can
+		// also bound the values produced by the generators.
+		n.constrainGenerators(p.EvalContext(), extraFilter)
 
 	case *alterIndexNode:
 	case *alterTableNode:
@@ -379,6 +387,11 @@ func (p *planner) propagateFilters(
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *callNode:
+	case *createFunctionNode:
+	case *createProcedureNode:
+	case *createTriggerNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *deleteRangeNode:
 	case *dropDatabaseNode:
@@ -386,6 +399,10 @@ func (p *planner) propagateFilters(
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *dropProcedureNode:
+	case *dropTriggerNode:
+	case *dropScheduleNode:
+	case *controlScheduleNode:
 	case *DropUserNode:
 	case *hookFnNode:
 	case *valuesNode:
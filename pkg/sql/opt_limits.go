@@ -70,7 +70,9 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 		if !soft && numRows < count {
 			count = numRows
 		}
-		p.applyLimit(n.plan, getLimit(count, n.offset), false /* soft */)
+		// The peers of the last row within a WITH TIES limit are also
+		// needed, so the limit can only be propagated as a "soft" limit.
+		p.applyLimit(n.plan, getLimit(count, n.offset), n.withTies /* soft */)
 
 	case *sortNode:
 		if n.needSort {
@@ -119,6 +121,10 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *max1RowNode:
 		p.setUnlimited(n.plan)
 
+	case *recursiveCTENode:
+		// The rows of the initial term are all fed to the recursive term.
+		p.setUnlimited(n.initial)
+
 	case *joinNode:
 		p.setUnlimited(n.left.plan)
 		p.setUnlimited(n.right.plan)
@@ -217,12 +223,21 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *callNode:
+	case *createFunctionNode:
+	case *createProcedureNode:
+	case *createTriggerNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *dropProcedureNode:
+	case *dropTriggerNode:
+	case *dropScheduleNode:
+	case *controlScheduleNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
@@ -43,11 +43,27 @@ func setNeededColumns(plan planNode, needed []bool) {
 		}
 
 	case *limitNode:
+		if _, ok := n.plan.(*sortNode); !ok && n.withTies {
+			// The ordering columns are needed to find the peers of the last row
+			// within the limit. If the source is a sortNode, it requires them
+			// already.
+			sourceNeeded := make([]bool, len(needed))
+			copy(sourceNeeded, needed)
+			for _, o := range n.tiesOrdering {
+				sourceNeeded[o.ColIdx] = true
+			}
+			needed = sourceNeeded
+		}
 		setNeededColumns(n.plan, needed)
 
 	case *max1RowNode:
 		setNeededColumns(n.plan, needed)
 
+	case *recursiveCTENode:
+		// All the columns of the initial term are needed for the working
+		// table of the recursive term.
+		setNeededColumns(n.initial, allColumns(n.initial))
+
 	case *spoolNode:
 		setNeededColumns(n.source, needed)
 
@@ -273,12 +289,21 @@ func setNeededColumns(plan planNode, needed []bool) {
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *callNode:
+	case *createFunctionNode:
+	case *createProcedureNode:
+	case *createTriggerNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *dropSequenceNode:
+	case *dropProcedureNode:
+	case *dropTriggerNode:
+	case *dropScheduleNode:
+	case *controlScheduleNode:
 	case *DropUserNode:
 	case *zeroNode:
 	case *unaryNode:
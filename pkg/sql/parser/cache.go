@@ -0,0 +1,260 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"reflect"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/cache"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// MaxCacheableStatementLen is the length above which the SQL text of a
+// statement is not considered for caching by a Cache. Very long statements
+// are unlikely to be repeated verbatim, and would use too much memory.
+const MaxCacheableStatementLen = 4096
+
+// A Cache is an LRU cache of parse results, keyed by the SQL text and the
+// ParseOptions. It avoids paying the cost of lexing and
+// parsing for workloads that repeatedly send the same statements without
+// placeholders.
+//
+// Since the planner is free to modify the ASTs it is given, the cache
+// hands out a deep copy of the cached statements on every hit. Only
+// successful parses are cached.
+//
+// The cache is safe for concurrent use by multiple goroutines. It is also
+// safe to use the cache through a nil reference, where it will act like a
+// valid cache with no capacity.
+type Cache struct {
+	// size is the maximum number of entries in the cache, accessed
+	// atomically.
+	size int64
+
+	mu    syncutil.Mutex
+	cache *cache.UnorderedCache
+}
+
+type cacheKey struct {
+	sql  string
+	opts ParseOptions
+}
+
+// NewCache creates a new Cache holding the results for at most size
+// distinct SQL strings.
+func NewCache(size int) *Cache {
+	c := &Cache{size: int64(size)}
+	c.cache = cache.NewUnorderedCache(cache.Config{
+		Policy: cache.CacheLRU,
+		ShouldEvict: func(s int, key, value interface{}) bool {
+			return int64(s) > atomic.LoadInt64(&c.size)
+		},
+	})
+	return c
+}
+
+// SetSize changes the maximum number of SQL strings held by the cache. If
+// the cache currently holds more entries than that, it is cleared. A size of
+// zero disables the cache.
+func (c *Cache) SetSize(size int) {
+	atomic.StoreInt64(&c.size, int64(size))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cache.Len() > size {
+		c.cache.Clear()
+	}
+}
+
+// Parse is like (*Parser).Parse, but consults the cache first.
+func (c *Cache) Parse(sql string) (Statements, error) {
+	return c.ParseWithOptions(sql, ParseOptions{})
+}
+
+// ParseWithInt is like (*Parser).ParseWithInt, but consults the cache first.
+func (c *Cache) ParseWithInt(sql string, nakedIntType *coltypes.TInt) (Statements, error) {
+	return c.ParseWithOptions(sql, ParseOptions{NakedIntType: nakedIntType})
+}
+
+// ParseWithOptions is like (*Parser).ParseWithOptions, but consults the cache
+// first. The statements returned along with an error under
+// ParseOptions.ErrorRecovery are not cached.
+func (c *Cache) ParseWithOptions(sql string, opts ParseOptions) (Statements, error) {
+	// The default types are filled in, so that the options which result in the
+	// same statements share a single entry.
+	opts.NakedIntType, opts.NakedSerialType = opts.nakedTypes()
+	cacheable := c != nil && len(sql) <= MaxCacheableStatementLen && atomic.LoadInt64(&c.size) > 0
+	key := cacheKey{sql: sql, opts: opts}
+	if cacheable {
+		if stmts, ok := c.lookup(key); ok {
+			return copyStatements(stmts), nil
+		}
+	}
+
+	stmts, err := parseWithPooledParser(sql, opts)
+	if err != nil {
+		return stmts, err
+	}
+	if cacheable {
+		// The caller owns the statements we return; the cache keeps its own
+		// copy, which is never handed out.
+		c.add(key, copyStatements(stmts))
+	}
+	return stmts, nil
+}
+
+// lookup checks for the statements in the cache in a synchronized manner.
+func (c *Cache) lookup(key cacheKey) (Statements, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	return v.(Statements), true
+}
+
+// add inserts the statements in the cache in a synchronized manner.
+func (c *Cache) add(key cacheKey, stmts Statements) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Del(key)
+	c.cache.Add(key, stmts)
+}
+
+// Len returns the number of SQL strings in the cache.
+func (c *Cache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cache.Len()
+}
+
+// Clear removes all the entries from the cache.
+func (c *Cache) Clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cache.Clear()
+}
+
+// copyStatements returns a deep copy of the given statements.
+func copyStatements(stmts Statements) Statements {
+	res := make(Statements, len(stmts))
+	for i := range stmts {
+		res[i] = stmts[i]
+		if stmts[i].AST != nil {
+			res[i].AST = copyAST(reflect.ValueOf(stmts[i].AST)).Interface().(tree.Statement)
+		}
+		res[i].Hints = copyAST(reflect.ValueOf(stmts[i].Hints)).Interface().(tree.StatementHints)
+	}
+	return res
+}
+
+var (
+	treePkgPath        = reflect.TypeOf(tree.Name("")).PkgPath()
+	datumType          = reflect.TypeOf((*tree.Datum)(nil)).Elem()
+	functionDefPtrType = reflect.TypeOf((*tree.FunctionDefinition)(nil))
+	overloadPtrType    = reflect.TypeOf((*tree.Overload)(nil))
+	funcPropsPtrType   = reflect.TypeOf((*tree.FunctionProperties)(nil))
+)
+
+// sharedInAST returns true if values of type t, which is a pointer or a
+// struct, can be shared between copies of an AST. This is the case for all
+// the types not defined in the tree package, such as column types and
+// constant.Value, which are immutable and some of which are compared by
+// identity (e.g. coltypes.Int8). This is also the case for datums (e.g.
+// tree.DNull) and function definitions, overloads and properties.
+func sharedInAST(t reflect.Type) bool {
+	if t == functionDefPtrType || t == overloadPtrType || t == funcPropsPtrType ||
+		t.Implements(datumType) {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	return t.PkgPath() != treePkgPath
+}
+
+// copyAST returns a deep copy of v, which is a part of an AST. This
+// includes the fields that are not exported, such as the type annotations
+// of expressions.
+func copyAST(v reflect.Value) reflect.Value {
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(t).Elem()
+		res.Set(copyAST(v.Elem()))
+		return res
+
+	case reflect.Ptr:
+		if v.IsNil() || sharedInAST(t) {
+			return v
+		}
+		res := reflect.New(t.Elem())
+		res.Elem().Set(copyAST(v.Elem()))
+		return res
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(copyAST(v.Index(i)))
+		}
+		return res
+
+	case reflect.Array:
+		res := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			res.Index(i).Set(copyAST(v.Index(i)))
+		}
+		return res
+
+	case reflect.Struct:
+		if sharedInAST(t) {
+			return v
+		}
+		res := reflect.New(t).Elem()
+		res.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			f := res.Field(i)
+			if !f.CanSet() {
+				// Reflection does not allow setting unexported fields, so go
+				// through their address.
+				f = reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem()
+			}
+			f.Set(copyAST(f))
+		}
+		return res
+
+	default:
+		return v
+	}
+}
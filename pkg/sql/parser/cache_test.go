@@ -0,0 +1,207 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestCacheCopies(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []string{
+		`SELECT 1`,
+		`SELECT a, b FROM t WHERE a = 'foo' AND b IS NOT NULL ORDER BY a LIMIT 10`,
+		`SELECT count(*), sum(DISTINCT x) FILTER (WHERE y > 1) FROM t GROUP BY z`,
+		`SELECT * FROM a JOIN b USING (x) LEFT JOIN (SELECT 1) AS c (y) ON true`,
+		`INSERT INTO t (a, b) VALUES (1, 'x'), (2, NULL) ON CONFLICT (a) DO UPDATE SET b = excluded.b`,
+		`INSERT INTO t VALUES (1) RETURNING NOTHING`,
+		`UPDATE t SET a = a + 1, (b, c) = (SELECT 1, 2) WHERE d IN (1, 2, 3) RETURNING *`,
+		`DELETE FROM t WHERE a @> '{"a": 1}'`,
+		`CREATE TABLE t (a INT PRIMARY KEY, b STRING DEFAULT 'x', c INT AS (a + 1) STORED, INDEX (b))`,
+		`SELECT CAST(a AS DECIMAL(10, 2)), b::INT4, ARRAY[1, 2][1] FROM t`,
+		`SELECT $1 + $2, current_date, x'ff', b'01', e'\n', 1.5e3`,
+		`BEGIN; SELECT 1; COMMIT`,
+	}
+	c := parser.NewCache(100)
+	for _, sql := range testData {
+		t.Run(sql, func(t *testing.T) {
+			expected, err := parser.Parse(sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			miss, err := c.Parse(sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			hit, err := c.Parse(sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, stmts := range []parser.Statements{miss, hit} {
+				if !reflect.DeepEqual(expected, stmts) {
+					t.Fatalf("expected\n%#v\ngot\n%#v", expected, stmts)
+				}
+			}
+			for i := range miss {
+				// Distinct zero-size values, like a COMMIT statement, may
+				// have the same address.
+				if reflect.TypeOf(miss[i].AST).Elem().Size() == 0 {
+					continue
+				}
+				if miss[i].AST == hit[i].AST {
+					t.Fatalf("%d: cache returned the same AST twice", i)
+				}
+			}
+		})
+	}
+	if c.Len() != len(testData) {
+		t.Fatalf("expected %d cached entries, got %d", len(testData), c.Len())
+	}
+}
+
+func TestCacheIsolation(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const sql = `SELECT a FROM t WHERE b = 1`
+	c := parser.NewCache(10)
+	mutate := func() {
+		stmts, err := c.Parse(sql)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := stmts.String(); s != sql {
+			t.Fatalf("expected %s, got %s", sql, s)
+		}
+		sel := stmts[0].AST.(*tree.Select).Select.(*tree.SelectClause)
+		sel.Where.Expr = tree.DBoolFalse
+		sel.Exprs[0].Expr = tree.NewUnresolvedName("z")
+	}
+	// Once for the miss, twice for hits.
+	for i := 0; i < 3; i++ {
+		mutate()
+	}
+}
+
+func TestCacheEviction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := parser.NewCache(2)
+	for i := 0; i < 5; i++ {
+		if _, err := c.Parse(fmt.Sprintf("SELECT %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+
+	// Errors are not cached.
+	if _, err := c.Parse("SELECT FROM FROM"); err == nil {
+		t.Fatal("expected error")
+	}
+	if c.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", c.Len())
+	}
+
+	// The INT type is part of the key.
+	const sql = `CREATE TABLE t (a INT)`
+	for _, typ := range []*coltypes.TInt{coltypes.Int8, coltypes.Int4} {
+		stmts, err := c.ParseWithInt(sql, typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		colType := stmts[0].AST.(*tree.CreateTable).Defs[0].(*tree.ColumnTableDef).Type
+		if colType != typ {
+			t.Fatalf("expected %s, got %s", typ, colType)
+		}
+	}
+
+	// So are the other options.
+	for _, retain := range []bool{false, true} {
+		stmts, err := c.ParseWithOptions(`SELECT 1 -- one`, parser.ParseOptions{RetainComments: retain})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(stmts[0].Comments); (n > 0) != retain {
+			t.Fatalf("expected comments to be retained: %t, got %d comments", retain, n)
+		}
+	}
+
+	c.Clear()
+	if c.Len() != 0 {
+		t.Fatalf("expected empty cache, got %d entries", c.Len())
+	}
+
+	// Shrinking the cache drops its entries; a zero size disables it.
+	for i := 0; i < 2; i++ {
+		if _, err := c.Parse(fmt.Sprintf("SELECT %d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.SetSize(1)
+	if c.Len() != 0 {
+		t.Fatalf("expected empty cache, got %d entries", c.Len())
+	}
+	c.SetSize(0)
+	if _, err := c.Parse(sql); err != nil {
+		t.Fatal(err)
+	}
+	if c.Len() != 0 {
+		t.Fatalf("expected disabled cache, got %d entries", c.Len())
+	}
+
+	// A nil cache is valid.
+	var nilCache *parser.Cache
+	if _, err := nilCache.Parse(sql); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	c := parser.NewCache(4)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				sql := fmt.Sprintf("SELECT a + %d FROM t", (g+i)%6)
+				stmts, err := c.Parse(sql)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if s := stmts.String(); s != sql {
+					t.Errorf("expected %s, got %s", sql, s)
+					return
+				}
+				// Mutate the result; this must not affect other goroutines.
+				stmts[0].AST.(*tree.Select).Select.(*tree.SelectClause).Exprs[0].Expr = tree.DNull
+			}
+		}(g)
+	}
+	wg.Wait()
+}
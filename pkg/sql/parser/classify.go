@@ -0,0 +1,133 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// TokenClass is the lexical class of a token, as reported by ClassifyTokens.
+type TokenClass int
+
+const (
+	// TokenKeyword is a keyword, reserved or not. The unreserved keywords
+	// used as names are keywords too, since only the grammar can tell them
+	// apart from identifiers.
+	TokenKeyword TokenClass = iota
+	// TokenIdentifier is an identifier, quoted or not.
+	TokenIdentifier
+	// TokenOperator is an operator or a punctuation character, such as a
+	// parenthesis, a comma or a semicolon.
+	TokenOperator
+	// TokenStringLiteral is a string, byte string or bit string literal, in
+	// any of its forms (e'...', $$...$$, x'...', etc.).
+	TokenStringLiteral
+	// TokenNumber is an integer or decimal literal.
+	TokenNumber
+	// TokenComment is a -- or /* */ comment, including the hints.
+	TokenComment
+	// TokenPlaceholder is a $n or :name placeholder.
+	TokenPlaceholder
+)
+
+var tokenClassNames = [...]string{
+	TokenKeyword:       "keyword",
+	TokenIdentifier:    "identifier",
+	TokenOperator:      "operator",
+	TokenStringLiteral: "string",
+	TokenNumber:        "number",
+	TokenComment:       "comment",
+	TokenPlaceholder:   "placeholder",
+}
+
+func (c TokenClass) String() string {
+	return tokenClassNames[c]
+}
+
+// ClassifiedToken is a token of the input of ClassifyTokens. Its text is
+// sql[Start:End].
+type ClassifiedToken struct {
+	Class      TokenClass
+	Start, End int
+}
+
+// ClassifyTokens labels each token of the given SQL with its lexical class,
+// for syntax highlighting. The tokens are those of the scanner used by the
+// parser, so that the text is highlighted the way it is understood, unlike
+// with regular expressions: e.g. nested comments, dollar-quoted strings and
+// escaped quotes are handled. The whitespace is not reported; the tokens are
+// ordered by position and do not overlap.
+//
+// If the input cannot be tokenized, for example because of an unterminated
+// string while it is being typed, the tokens which precede the error are
+// returned along with it.
+func ClassifyTokens(sql string) ([]ClassifiedToken, error) {
+	s := makeScanner(sql)
+	var res []ClassifiedToken
+	var lval sqlSymType
+	for {
+		end := s.pos
+		s.scan(&lval)
+		// The comments are skipped by the scanner; find them between the
+		// previous token and this one.
+		res = appendComments(res, sql, end, int(lval.pos))
+		var class TokenClass
+		switch lval.id {
+		case 0:
+			return res, nil
+		case ERROR:
+			return res, pgerror.NewErrorf(pgerror.CodeSyntaxError, "lexical error: %s", lval.str)
+		case IDENT:
+			class = TokenIdentifier
+		case SCONST, BCONST, BITCONST:
+			class = TokenStringLiteral
+		case ICONST, FCONST:
+			class = TokenNumber
+		case PLACEHOLDER:
+			class = TokenPlaceholder
+		default:
+			class = TokenOperator
+			if lex.GetKeywordID(lval.str) == lval.id {
+				class = TokenKeyword
+			}
+		}
+		res = append(res, ClassifiedToken{Class: class, Start: int(lval.pos), End: s.pos})
+	}
+}
+
+// appendComments appends the comments of sql[start:end], which only holds
+// whitespace and comments, to tokens.
+func appendComments(tokens []ClassifiedToken, sql string, start, end int) []ClassifiedToken {
+	s := scanner{in: sql[:end], pos: start}
+	var lval sqlSymType
+	for s.pos < end {
+		if ch := s.peek(); ch == ' ' || ch == '\t' || ch == '\r' || ch == '\f' || ch == '\n' {
+			s.pos++
+			continue
+		}
+		commentStart := s.pos
+		if present, ok := s.scanComment(&lval); !ok || !present {
+			break
+		}
+		// A -- comment ends with the newline, which is not part of it.
+		commentEnd := s.pos
+		if sql[commentEnd-1] == '\n' {
+			commentEnd--
+		}
+		tokens = append(tokens, ClassifiedToken{Class: TokenComment, Start: commentStart, End: commentEnd})
+	}
+	return tokens
+}
@@ -0,0 +1,78 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestClassifyTokens(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{``, ``},
+		{`SELECT a, "b c" FROM t`,
+			`keyword:SELECT identifier:a operator:, identifier:"b c" keyword:FROM identifier:t`},
+		{`select 1.5, -2, 'it''s', e'\n', $$a$$, x'ff', b'01'`,
+			`keyword:select number:1.5 operator:, operator:- number:2 operator:, string:'it''s' ` +
+				`operator:, string:e'\n' operator:, string:$$a$$ operator:, string:x'ff' operator:, string:b'01'`},
+		{`a::INT >= $1 || b; :name`,
+			`identifier:a operator::: keyword:INT operator:>= placeholder:$1 operator:|| identifier:b ` +
+				`operator:; placeholder::name`},
+		{"SELECT /* a /* nested */ comment */ 1 -- end\n;",
+			`keyword:SELECT comment:/* a /* nested */ comment */ number:1 comment:-- end operator:;`},
+		{`/*+ hint */ -- trailing`, `comment:/*+ hint */ comment:-- trailing`},
+		// Unreserved keywords are reported as keywords, even when used as names.
+		{`SELECT name FROM t`, `keyword:SELECT keyword:name keyword:FROM identifier:t`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			tokens, err := parser.ClassifyTokens(d.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res := formatTokens(d.sql, tokens); res != d.expected {
+				t.Errorf("expected\n%s\nfound\n%s", d.expected, res)
+			}
+		})
+	}
+
+	// The tokens which precede a lexical error are returned.
+	sql := `SELECT 'unterminated`
+	tokens, err := parser.ClassifyTokens(sql)
+	if !testutils.IsError(err, "lexical error: unterminated string") {
+		t.Fatalf("expected an error, found %v", err)
+	}
+	if res := formatTokens(sql, tokens); res != `keyword:SELECT` {
+		t.Fatalf("unexpected tokens %s", res)
+	}
+}
+
+func formatTokens(sql string, tokens []parser.ClassifiedToken) string {
+	res := make([]string, len(tokens))
+	for i, tok := range tokens {
+		res[i] = fmt.Sprintf("%s:%s", tok.Class, sql[tok.Start:tok.End])
+	}
+	return strings.Join(res, " ")
+}
@@ -0,0 +1,433 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// IdentifierKind describes the kind of object named by an identifier at the
+// cursor, as far as it can be told from the keywords which precede it.
+type IdentifierKind int
+
+const (
+	// UnknownIdentifier is used when the kind of object cannot be told.
+	UnknownIdentifier IdentifierKind = iota
+	// RelationIdentifier names a table, view or sequence, e.g. after FROM.
+	RelationIdentifier
+	// DatabaseIdentifier names a database, e.g. after USE.
+	DatabaseIdentifier
+	// ColumnIdentifier names a column or function in an expression, e.g.
+	// after WHERE.
+	ColumnIdentifier
+)
+
+// identifierKindKeywords maps the keywords which determine the kind of the
+// identifiers that follow them.
+var identifierKindKeywords = map[int32]IdentifierKind{
+	FROM:       RelationIdentifier,
+	JOIN:       RelationIdentifier,
+	INTO:       RelationIdentifier,
+	UPDATE:     RelationIdentifier,
+	TABLE:      RelationIdentifier,
+	VIEW:       RelationIdentifier,
+	SEQUENCE:   RelationIdentifier,
+	TRUNCATE:   RelationIdentifier,
+	REFERENCES: RelationIdentifier,
+
+	DATABASE: DatabaseIdentifier,
+	USE:      DatabaseIdentifier,
+
+	SELECT:    ColumnIdentifier,
+	DISTINCT:  ColumnIdentifier,
+	WHERE:     ColumnIdentifier,
+	AND:       ColumnIdentifier,
+	OR:        ColumnIdentifier,
+	NOT:       ColumnIdentifier,
+	ON:        ColumnIdentifier,
+	BY:        ColumnIdentifier,
+	HAVING:    ColumnIdentifier,
+	SET:       ColumnIdentifier,
+	RETURNING: ColumnIdentifier,
+	WHEN:      ColumnIdentifier,
+	THEN:      ColumnIdentifier,
+	ELSE:      ColumnIdentifier,
+}
+
+// IdentifierContext describes the identifier being completed.
+type IdentifierContext struct {
+	Kind IdentifierKind
+	// Qualifier contains the names that precede the identifier in a
+	// qualified name, e.g. ["db", "public"] for "db.public.<cursor>".
+	Qualifier []string
+	// Prefix is the part of the identifier that precedes the cursor, in the
+	// original case.
+	Prefix string
+}
+
+// CandidateKind is the kind of a completion candidate.
+type CandidateKind int
+
+const (
+	// KeywordCandidate is a SQL keyword.
+	KeywordCandidate CandidateKind = iota
+	// IdentifierCandidate is an identifier returned by
+	// Completer.Identifiers.
+	IdentifierCandidate
+)
+
+// Candidate is a completion candidate.
+type Candidate struct {
+	// Text replaces the word at the cursor when the candidate is chosen.
+	Text string
+	Kind CandidateKind
+}
+
+// Completions are the completion candidates at a cursor offset.
+type Completions struct {
+	// Start and End are the byte offsets of the word at the cursor, which
+	// the candidates replace. They are both the cursor offset if the cursor
+	// is not on a word.
+	Start, End int
+	// Candidates are the identifier candidates, followed by the keyword
+	// candidates sorted alphabetically.
+	Candidates []Candidate
+}
+
+// Completer computes the completions of SQL at a cursor offset. The keyword
+// candidates are computed from the state of the grammar at the cursor: they
+// are the keywords which can follow the tokens of the statement before the
+// cursor and which start with the part of the word before the cursor.
+// Keywords which would only be accepted at the cursor as identifiers are not
+// candidates.
+type Completer struct {
+	// Identifiers, if set, returns the candidate identifiers when an
+	// identifier can appear at the cursor, e.g. the names of the tables in
+	// the current database for a RelationIdentifier. The candidates need not
+	// be filtered by the prefix; those which don't start with it are
+	// ignored. They are quoted as needed.
+	Identifiers func(IdentifierContext) []string
+}
+
+// Complete returns the completion candidates at the given byte offset of the
+// sql, which can contain several statements. Only the statement that
+// contains the cursor is considered. There are no candidates if the cursor
+// is inside a literal or if there is a syntax error before the cursor.
+func (c *Completer) Complete(sql string, offset int) (Completions, error) {
+	if offset < 0 || offset > len(sql) {
+		return Completions{}, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"cursor offset %d out of bounds for input of length %d", offset, len(sql))
+	}
+	res := Completions{Start: offset, End: offset}
+
+	// Scan the tokens of the statement up to the cursor. If the cursor is on
+	// a word, the word is excluded from the tokens; it is the prefix of the
+	// candidates.
+	s := makeScanner(sql)
+	var tokens []sqlSymType
+	var lval sqlSymType
+	prefix := ""
+	for {
+		s.scan(&lval)
+		start, end := int(lval.pos), s.pos
+		if lval.id == 0 || start >= offset {
+			break
+		}
+		if lval.id == ';' {
+			tokens = tokens[:0]
+			continue
+		}
+		if end >= offset {
+			if lex.IsIdentStart(int(sql[start])) {
+				// The cursor is on (or just after) an unquoted word.
+				prefix = sql[start:offset]
+				res.Start, res.End = start, end
+				break
+			}
+			if end > offset || lval.id == ERROR {
+				// The cursor is inside a literal.
+				return res, nil
+			}
+		}
+		if lval.id == ERROR {
+			return res, nil
+		}
+		tokens = append(tokens, lval)
+	}
+
+	stacks := completionStacks(sql, tokens)
+	if len(stacks) == 0 {
+		return res, nil
+	}
+
+	// Try each token at the cursor.
+	var keywords []string
+	identOK := false
+	for _, stack := range stacks {
+		scratch := make([]int, 0, len(stack)+8)
+		for tok := sqlFirstToken; tok-1 < len(sqlToknames); tok++ {
+			kw, isKeyword := keywordsByToken[tok]
+			if !isKeyword && tok != sqlIdentToken {
+				continue
+			}
+			next := append(scratch[:0], stack...)
+			next, ok := sqlConsume(next, tok)
+			if !ok {
+				continue
+			}
+			if tok == sqlIdentToken {
+				identOK = true
+				continue
+			}
+			if isKeywordAsIdentifier(next) || !hasPrefixFold(kw, prefix) {
+				continue
+			}
+			keywords = append(keywords, kw)
+		}
+	}
+
+	if identOK && c.Identifiers != nil {
+		ictx := IdentifierContext{Prefix: prefix}
+		i := len(tokens)
+		// Collect the qualifier, e.g. "db.public." before the cursor.
+		for i >= 2 && tokens[i-1].id == '.' && tokens[i-2].id == IDENT {
+			ictx.Qualifier = append([]string{tokens[i-2].str}, ictx.Qualifier...)
+			i -= 2
+		}
+		for j := i - 1; j >= 0; j-- {
+			if kind, ok := identifierKindKeywords[tokens[j].id]; ok {
+				ictx.Kind = kind
+				break
+			}
+			if tokens[j].id != IDENT && tokens[j].id != '.' && tokens[j].id != ',' {
+				break
+			}
+		}
+		for _, name := range c.Identifiers(ictx) {
+			if hasPrefixFold(name, prefix) {
+				res.Candidates = append(res.Candidates, Candidate{
+					Text: tree.NameString(name),
+					Kind: IdentifierCandidate,
+				})
+			}
+		}
+	}
+
+	sort.Strings(keywords)
+	// Keywords are proposed in upper case, unless the user is typing them in
+	// lower case.
+	upper := strings.ToLower(prefix) != prefix || prefix == ""
+	for i, kw := range keywords {
+		if i > 0 && kw == keywords[i-1] {
+			// The keywords of lookahead tokens, e.g. NOT and NOT_LA, or the
+			// keywords found with several stacks.
+			continue
+		}
+		if upper {
+			kw = strings.ToUpper(kw)
+		}
+		res.Candidates = append(res.Candidates, Candidate{Text: kw, Kind: KeywordCandidate})
+	}
+	return res, nil
+}
+
+// Complete is a short-hand for (*Completer).Complete without identifier
+// candidates.
+func Complete(sql string, offset int) (Completions, error) {
+	var c Completer
+	return c.Complete(sql, offset)
+}
+
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// The completion engine simulates the parser generated by goyacc, using its
+// tables, without running the actions of the grammar rules. The tokens use
+// the internal numbering of the generated parser (see sqllex1).
+
+// sqlFirstToken is the first internal token number which denotes an actual
+// token; the first ones are the end of input, the error token and unknown
+// characters.
+const sqlFirstToken = 4
+
+// sqlIdentToken is the internal token number of IDENT.
+var sqlIdentToken = tokenNumber("IDENT")
+
+// keywordsByToken maps the internal token numbers of the keywords, including
+// the lookahead tokens such as NOT_LA, to the keywords.
+var keywordsByToken = func() map[int]string {
+	m := make(map[int]string, len(lex.KeywordNames))
+	for _, kw := range lex.KeywordNames {
+		for _, name := range []string{strings.ToUpper(kw), strings.ToUpper(kw) + "_LA"} {
+			if tok := tokenNumber(name); tok >= 0 {
+				m[tok] = kw
+			}
+		}
+	}
+	return m
+}()
+
+// tokenNumber returns the internal number of the token with the given name
+// in the grammar, or -1 if there is no such token.
+func tokenNumber(name string) int {
+	for i, n := range sqlToknames {
+		if n == name {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// keywordCategoryNonterminals is the set of nonterminals of the keyword
+// category lists of the grammar, such as unreserved_keyword. They are found
+// as the nonterminals to which the keywords are reduced after "SELECT 1 AS",
+// where any keyword can be used as a column alias.
+var keywordCategoryNonterminals = func() map[int]struct{} {
+	m := make(map[int]struct{})
+	stack := []int{0}
+	for _, name := range []string{"SELECT", "ICONST", "AS"} {
+		var ok bool
+		if stack, ok = sqlConsume(stack, tokenNumber(name)); !ok {
+			panic("unexpected grammar state for SELECT 1 AS")
+		}
+	}
+	for tok := range keywordsByToken {
+		next, ok := sqlConsume(append([]int(nil), stack...), tok)
+		if !ok {
+			continue
+		}
+		if r, ok := sqlDefaultReduction(next[len(next)-1]); ok && sqlR2[r] == 1 {
+			m[sqlR1[r]] = struct{}{}
+		}
+	}
+	return m
+}()
+
+// isKeywordAsIdentifier returns true if the keyword that was just shifted
+// onto the stack is used as an identifier, i.e. if it is unconditionally
+// reduced to a keyword category such as unreserved_keyword. A keyword that
+// is part of the syntax at this point, even if it could also be an
+// identifier, is not reduced without looking at the next token.
+func isKeywordAsIdentifier(stack []int) bool {
+	r, ok := sqlDefaultReduction(stack[len(stack)-1])
+	if !ok || sqlR2[r] != 1 {
+		return false
+	}
+	_, ok = keywordCategoryNonterminals[sqlR1[r]]
+	return ok
+}
+
+// sqlDefaultReduction returns the rule by which the given state is reduced
+// regardless of the next token, if any.
+func sqlDefaultReduction(state int) (rule int, ok bool) {
+	if sqlPact[state] > sqlFlag || sqlDef[state] <= 0 {
+		return 0, false
+	}
+	return sqlDef[state], true
+}
+
+// completionStacks returns the stacks of parser states after the given
+// tokens of a statement. There are none if there is a syntax error in the
+// tokens.
+//
+// The lexer translates the tokens which require more lookahead, e.g. NOT to
+// NOT_LA in NOT IN. The token that precedes the cursor has no lookahead yet,
+// so if it has a lookahead variant, both variants are tried: there is one
+// stack for each of them that is not a syntax error.
+func completionStacks(sql string, tokens []sqlSymType) [][]int {
+	var l lexer
+	l.init(sql, tokens, defaultNakedIntType, defaultNakedSerialType)
+	stack := []int{0}
+	var lval sqlSymType
+	for i := range tokens {
+		_, tok := sqllex1(&l, &lval)
+		if i == len(tokens)-1 {
+			return lastTokenStacks(stack, tok)
+		}
+		var ok bool
+		if stack, ok = sqlConsume(stack, tok); !ok {
+			return nil
+		}
+	}
+	return [][]int{stack}
+}
+
+// lastTokenStacks returns the stacks after consuming tok, and its lookahead
+// variant if any, on top of the given stack.
+func lastTokenStacks(stack []int, tok int) [][]int {
+	toks := []int{tok}
+	if kw, ok := keywordsByToken[tok]; ok {
+		for _, name := range []string{strings.ToUpper(kw), strings.ToUpper(kw) + "_LA"} {
+			if t := tokenNumber(name); t >= 0 && t != tok {
+				toks = append(toks, t)
+			}
+		}
+	}
+	var stacks [][]int
+	for _, t := range toks {
+		if next, ok := sqlConsume(append([]int(nil), stack...), t); ok {
+			stacks = append(stacks, next)
+		}
+	}
+	return stacks
+}
+
+// sqlConsume performs the reductions of the parser that precede the shift of
+// the given token, then shifts it. ok is false if the token is a syntax error
+// in the state at the top of the stack. The end of input is accepted without
+// being shifted.
+func sqlConsume(stack []int, tok int) (_ []int, ok bool) {
+	for {
+		state := stack[len(stack)-1]
+		n := sqlPact[state]
+		if n > sqlFlag {
+			if n += tok; n >= 0 && n < sqlLast && sqlChk[sqlAct[n]] == tok {
+				return append(stack, sqlAct[n]), true
+			}
+		}
+		rule := sqlDef[state]
+		if rule == -2 {
+			// Look through the exception table.
+			xi := 0
+			for sqlExca[xi] != -1 || sqlExca[xi+1] != state {
+				xi += 2
+			}
+			for xi += 2; sqlExca[xi] >= 0 && sqlExca[xi] != tok; xi += 2 {
+			}
+			if rule = sqlExca[xi+1]; rule < 0 {
+				// Accept.
+				return stack, true
+			}
+		}
+		if rule == 0 {
+			return stack, false
+		}
+		// Reduce, and consult the goto table for the next state.
+		stack = stack[:len(stack)-sqlR2[rule]]
+		lhs := sqlR1[rule]
+		g := sqlPgo[lhs]
+		next := sqlAct[g]
+		if j := g + stack[len(stack)-1] + 1; j < sqlLast && sqlChk[sqlAct[j]] == -lhs {
+			next = sqlAct[j]
+		}
+		stack = append(stack, next)
+	}
+}
@@ -0,0 +1,159 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// splitCursor returns the sql without the '|' that marks the cursor, and the
+// offset of the cursor.
+func splitCursor(t *testing.T, sql string) (string, int) {
+	i := strings.IndexByte(sql, '|')
+	if i < 0 {
+		t.Fatalf("no cursor in %q", sql)
+	}
+	return sql[:i] + sql[i+1:], i
+}
+
+func TestComplete(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql        string
+		start, end int
+		// includes and excludes are checked against the candidates.
+		includes []string
+		excludes []string
+		// exact, if set, is the exact list of candidates.
+		exact []string
+	}{
+		{sql: `SEL|`, start: 0, end: 3, exact: []string{`SELECT`}},
+		{sql: `sel|`, start: 0, end: 3, exact: []string{`select`}},
+		{sql: `S|EL`, start: 0, end: 3, includes: []string{`SELECT`, `SET`, `SHOW`}},
+		{sql: `|`, includes: []string{`SELECT`, `INSERT`, `CREATE`}, excludes: []string{`WHERE`}},
+		{sql: `SELECT * FROM t |`, start: 16, end: 16,
+			includes: []string{`WHERE`, `ORDER`, `LIMIT`, `JOIN`}, excludes: []string{`ABORT`, `SELECT`}},
+		{sql: `SELECT * FROM t WH|`, start: 16, end: 18, exact: []string{`WHERE`}},
+		// Keywords which would only be identifiers are not candidates.
+		{sql: `SET |`, includes: []string{`CLUSTER`, `TRANSACTION`}, excludes: []string{`ABORT`}},
+		// Lookahead tokens are translated.
+		{sql: `SELECT * FROM t WHERE a NOT |`, includes: []string{`IN`, `LIKE`, `BETWEEN`}},
+		// Only the statement at the cursor is considered.
+		{sql: `SELECT 1; CREATE |`, includes: []string{`TABLE`, `DATABASE`}, excludes: []string{`WHERE`}},
+		// No candidates in a literal or after a syntax error.
+		{sql: `SELECT 'ab|c'`, start: 10, end: 10, exact: []string{}},
+		{sql: `SELECT FROM WHERE |`, exact: []string{}},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			sql, offset := splitCursor(t, d.sql)
+			res, err := parser.Complete(sql, offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.start != 0 || d.end != 0 {
+				if res.Start != d.start || res.End != d.end {
+					t.Errorf("expected range [%d, %d), got [%d, %d)", d.start, d.end, res.Start, res.End)
+				}
+			}
+			candidates := make(map[string]struct{})
+			texts := []string{}
+			for _, c := range res.Candidates {
+				texts = append(texts, c.Text)
+				candidates[c.Text] = struct{}{}
+			}
+			if d.exact != nil && !reflect.DeepEqual(texts, d.exact) {
+				t.Errorf("expected %v, got %v", d.exact, texts)
+			}
+			for _, s := range d.includes {
+				if _, ok := candidates[s]; !ok {
+					t.Errorf("expected %s in %v", s, texts)
+				}
+			}
+			for _, s := range d.excludes {
+				if _, ok := candidates[s]; ok {
+					t.Errorf("unexpected %s in %v", s, texts)
+				}
+			}
+		})
+	}
+}
+
+func TestCompleteIdentifiers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		expected parser.IdentifierContext
+		// candidates are the identifier candidates.
+		candidates []string
+	}{
+		{`SELECT * FROM |`, parser.IdentifierContext{Kind: parser.RelationIdentifier}, []string{`abc`, `"a b"`, `xyz`}},
+		{`SELECT * FROM a|`, parser.IdentifierContext{Kind: parser.RelationIdentifier, Prefix: `a`}, []string{`abc`, `"a b"`}},
+		{`SELECT * FROM db.sch.|`, parser.IdentifierContext{
+			Kind: parser.RelationIdentifier, Qualifier: []string{`db`, `sch`},
+		}, []string{`abc`, `"a b"`, `xyz`}},
+		{`USE |`, parser.IdentifierContext{Kind: parser.DatabaseIdentifier}, []string{`abc`, `"a b"`, `xyz`}},
+		{`SELECT * FROM t WHERE x|`, parser.IdentifierContext{Kind: parser.ColumnIdentifier, Prefix: `x`}, []string{`xyz`}},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			sql, offset := splitCursor(t, d.sql)
+			var ictx *parser.IdentifierContext
+			c := parser.Completer{
+				Identifiers: func(ctx parser.IdentifierContext) []string {
+					ictx = &ctx
+					return []string{`abc`, `a b`, `xyz`}
+				},
+			}
+			res, err := c.Complete(sql, offset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ictx == nil {
+				t.Fatal("identifiers hook not called")
+			}
+			if !reflect.DeepEqual(*ictx, d.expected) {
+				t.Errorf("expected context %+v, got %+v", d.expected, *ictx)
+			}
+			var identifiers []string
+			for _, c := range res.Candidates {
+				if c.Kind == parser.IdentifierCandidate {
+					identifiers = append(identifiers, c.Text)
+				}
+			}
+			if !reflect.DeepEqual(identifiers, d.candidates) {
+				t.Errorf("expected %v, got %v", d.candidates, identifiers)
+			}
+		})
+	}
+}
+
+func TestCompleteError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, offset := range []int{-1, 5} {
+		if _, err := parser.Complete(`SEL`, offset); !testutils.IsError(err, `out of bounds`) {
+			t.Errorf("expected out of bounds error for offset %d, got %v", offset, err)
+		}
+	}
+}
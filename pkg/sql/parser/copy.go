@@ -0,0 +1,427 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// CopyFormat is the format of the data of a COPY FROM statement.
+type CopyFormat int
+
+const (
+	// CopyFormatText is the default, tab-separated format, in which special
+	// characters are escaped with backslashes.
+	CopyFormatText CopyFormat = iota
+	// CopyFormatCSV is the comma-separated values format, in which fields
+	// containing special characters are quoted.
+	CopyFormatCSV
+)
+
+// CopyOptions configures the decoding of COPY data. DefaultCopyOptions
+// returns the options that Postgres uses for each format.
+type CopyOptions struct {
+	Format CopyFormat
+	// Delimiter separates the fields of a row.
+	Delimiter byte
+	// Null is the string which represents a NULL value. For CSV, a quoted field
+	// is never NULL.
+	Null string
+	// Quote and Escape are the quoting character and the character which
+	// escapes the quoting character in quoted fields, for CSV.
+	Quote, Escape byte
+	// Header, for CSV, indicates that the first line contains the names of the
+	// columns and is to be ignored.
+	Header bool
+}
+
+// DefaultCopyOptions returns the default options for a COPY format.
+func DefaultCopyOptions(format CopyFormat) CopyOptions {
+	if format == CopyFormatCSV {
+		return CopyOptions{Format: format, Delimiter: ',', Quote: '"', Escape: '"'}
+	}
+	return CopyOptions{Format: format, Delimiter: '\t', Null: `\N`}
+}
+
+// copyEndMarker is the line which marks the end of the COPY data.
+var copyEndMarker = []byte(`\.`)
+
+// CopyRowError is the error returned by CopyReader.Read for a row which
+// cannot be decoded.
+type CopyRowError struct {
+	// Line is the line of the input, counting from 1, at which the row starts.
+	Line int
+	// Column is the field of the row, counting from 1, which cannot be decoded,
+	// or 0 if the error doesn't pertain to a particular field.
+	Column int
+	Err    error
+}
+
+func (e *CopyRowError) Error() string {
+	if e.Column == 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Cause returns the underlying error, so that its pgerror code is the one
+// reported to the client.
+func (e *CopyRowError) Cause() error {
+	return e.Err
+}
+
+// copyField is the position of a field in the raw data of a row for the text
+// format, or in the decoded data for CSV.
+type copyField struct {
+	start, end int
+	quoted     bool
+}
+
+// CopyReader decodes the rows of COPY FROM data from a reader, one at a time,
+// so that the data doesn't need to be buffered in its entirety.
+type CopyReader struct {
+	rd      *bufio.Reader
+	types   []types.T
+	evalCtx *tree.EvalContext
+	opts    CopyOptions
+
+	// line is the number of lines read so far, and rowLine the line at which
+	// the last row read started.
+	line, rowLine int
+	// raw is the data of the last row read, including its final newline.
+	raw []byte
+	// fields are the fields of the last row read. For CSV, their data is
+	// decoded in csvBuf.
+	fields []copyField
+	csvBuf []byte
+	// done is set once the end of the data has been reached.
+	done bool
+}
+
+// NewCopyReader creates a CopyReader decoding rows which have a column of each
+// of the given types. evalCtx is used to parse the datums.
+func NewCopyReader(
+	r io.Reader, typs []types.T, evalCtx *tree.EvalContext, opts CopyOptions,
+) *CopyReader {
+	return &CopyReader{
+		rd:      bufio.NewReader(r),
+		types:   typs,
+		evalCtx: evalCtx,
+		opts:    opts,
+	}
+}
+
+// Read decodes the next row. io.EOF is returned once the input or the
+// \. end-of-data marker is reached.
+//
+// If a row cannot be decoded, a *CopyRowError is returned. The next call
+// continues with the following row, so the caller can choose to skip the
+// malformed ones. Other errors come from the underlying reader and are final.
+func (r *CopyReader) Read() (tree.Datums, error) {
+	for {
+		if r.done {
+			return nil, io.EOF
+		}
+		r.raw = r.raw[:0]
+		if err := r.readLine(); err != nil {
+			r.done = true
+			return nil, err
+		}
+		r.rowLine = r.line
+		if r.opts.Format == CopyFormatCSV && r.opts.Header && r.rowLine == 1 {
+			continue
+		}
+		if bytes.Equal(trimEOL(r.raw), copyEndMarker) {
+			r.done = true
+			return nil, io.EOF
+		}
+
+		if r.opts.Format == CopyFormatCSV {
+			if rowErr, err := r.splitCSV(); err != nil {
+				r.done = true
+				return nil, err
+			} else if rowErr != nil {
+				return nil, &CopyRowError{Line: r.rowLine, Err: rowErr}
+			}
+		} else {
+			r.splitText()
+		}
+		if len(r.fields) != len(r.types) {
+			return nil, &CopyRowError{
+				Line: r.rowLine,
+				Err: pgerror.NewErrorf(pgerror.CodeBadCopyFileFormatError,
+					"expected %d values, got %d", len(r.types), len(r.fields)),
+			}
+		}
+
+		row := make(tree.Datums, len(r.fields))
+		for i, f := range r.fields {
+			d, err := r.decodeField(i, f)
+			if err != nil {
+				return nil, &CopyRowError{Line: r.rowLine, Column: i + 1, Err: err}
+			}
+			row[i] = d
+		}
+		return row, nil
+	}
+}
+
+// Raw returns the data of the row returned, or rejected, by the last call to
+// Read, without its final newline. It is only valid until the next call.
+func (r *CopyReader) Raw() []byte {
+	return trimEOL(r.raw)
+}
+
+// Line returns the line of the input, counting from 1, at which the row
+// returned, or rejected, by the last call to Read starts.
+func (r *CopyReader) Line() int {
+	return r.rowLine
+}
+
+// readLine appends the next line of the input, including its newline if any,
+// to r.raw. io.EOF is returned if there is no more input.
+func (r *CopyReader) readLine() error {
+	start := len(r.raw)
+	for {
+		b, err := r.rd.ReadSlice('\n')
+		r.raw = append(r.raw, b...)
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(r.raw) > start {
+			// The last line doesn't end with a newline.
+			break
+		}
+		if err != nil {
+			return err
+		}
+		break
+	}
+	r.line++
+	return nil
+}
+
+// trimEOL removes the newline, and a carriage return preceding it, from the
+// end of a line.
+func trimEOL(line []byte) []byte {
+	if len(line) > 0 && line[len(line)-1] == '\n' {
+		line = line[:len(line)-1]
+	}
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+	return line
+}
+
+// splitText finds the fields of a row in the text format. A backslash escapes
+// the character following it, which thus never separates fields; the escape
+// sequences are decoded afterwards, as the NULL marker is matched against the
+// raw field.
+func (r *CopyReader) splitText() {
+	line := trimEOL(r.raw)
+	r.fields = r.fields[:0]
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case r.opts.Delimiter:
+			r.fields = append(r.fields, copyField{start: start, end: i})
+			start = i + 1
+		}
+	}
+	r.fields = append(r.fields, copyField{start: start, end: len(line)})
+}
+
+// splitCSV finds and decodes the fields of a CSV row. A quoted field can
+// contain newlines, in which case the following lines are read as part of the
+// row. rowErr is set if the row is malformed, err if the input cannot be read.
+func (r *CopyReader) splitCSV() (rowErr error, err error) {
+	quote, escape, delim := r.opts.Quote, r.opts.Escape, r.opts.Delimiter
+	r.fields = r.fields[:0]
+	r.csvBuf = r.csvBuf[:0]
+	var inQuotes, quoted bool
+	start := 0
+	i, end := 0, len(trimEOL(r.raw))
+	for {
+		if i == end {
+			if !inQuotes {
+				break
+			}
+			// The newline is part of the quoted field.
+			r.csvBuf = append(r.csvBuf, r.raw[end:]...)
+			i = len(r.raw)
+			if err := r.readLine(); err == io.EOF {
+				return pgerror.NewErrorf(pgerror.CodeBadCopyFileFormatError,
+					"unterminated CSV quoted field"), nil
+			} else if err != nil {
+				return nil, err
+			}
+			end = len(trimEOL(r.raw))
+			continue
+		}
+		c := r.raw[i]
+		i++
+		switch {
+		case inQuotes && c == escape && i < end && (r.raw[i] == quote || r.raw[i] == escape):
+			r.csvBuf = append(r.csvBuf, r.raw[i])
+			i++
+		case inQuotes && c == quote:
+			inQuotes = false
+		case inQuotes:
+			r.csvBuf = append(r.csvBuf, c)
+		case c == quote:
+			inQuotes, quoted = true, true
+		case c == delim:
+			r.fields = append(r.fields, copyField{start: start, end: len(r.csvBuf), quoted: quoted})
+			start, quoted = len(r.csvBuf), false
+		default:
+			r.csvBuf = append(r.csvBuf, c)
+		}
+	}
+	r.fields = append(r.fields, copyField{start: start, end: len(r.csvBuf), quoted: quoted})
+	return nil, nil
+}
+
+// decodeField converts the i-th field of the last row read into a datum.
+func (r *CopyReader) decodeField(i int, f copyField) (tree.Datum, error) {
+	var s string
+	if r.opts.Format == CopyFormatCSV {
+		s = string(r.csvBuf[f.start:f.end])
+	} else {
+		s = string(r.raw[f.start:f.end])
+	}
+	if !f.quoted && s == r.opts.Null {
+		return tree.DNull, nil
+	}
+	if r.opts.Format == CopyFormatText {
+		var err error
+		if s, err = decodeCopy(s, r.opts.Delimiter); err != nil {
+			return nil, err
+		}
+	}
+	return tree.ParseStringAs(r.types[i], s, r.evalCtx)
+}
+
+// decodeCopy unescapes a single field of the text format. A backslash
+// followed by the delimiter stands for the delimiter itself.
+//
+// See: https://www.postgresql.org/docs/9.5/static/sql-copy.html#AEN74432
+func decodeCopy(in string, delim byte) (string, error) {
+	if strings.IndexByte(in, '\\') < 0 {
+		return in, nil
+	}
+	var buf bytes.Buffer
+	start := 0
+	for i, n := 0, len(in); i < n; i++ {
+		if in[i] != '\\' {
+			continue
+		}
+		buf.WriteString(in[start:i])
+		i++
+		if i >= n {
+			return "", pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"unknown escape sequence: %q", in[i-1:])
+		}
+
+		ch := in[i]
+		if decodedChar := decodeMap[ch]; decodedChar != 0 {
+			buf.WriteByte(decodedChar)
+		} else if ch == delim {
+			buf.WriteByte(delim)
+		} else if ch == 'x' {
+			// \x can be followed by 1 or 2 hex digits.
+			i++
+			if i >= n {
+				return "", pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"unknown escape sequence: %q", in[i-2:])
+			}
+			ch = in[i]
+			digit, ok := decodeHexDigit(ch)
+			if !ok {
+				return "", pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"unknown escape sequence: %q", in[i-2:i])
+			}
+			if i+1 < n {
+				if v, ok := decodeHexDigit(in[i+1]); ok {
+					i++
+					digit <<= 4
+					digit += v
+				}
+			}
+			buf.WriteByte(digit)
+		} else if ch >= '0' && ch <= '7' {
+			digit, _ := decodeOctDigit(ch)
+			// 1 to 2 more octal digits follow.
+			if i+1 < n {
+				if v, ok := decodeOctDigit(in[i+1]); ok {
+					i++
+					digit <<= 3
+					digit += v
+				}
+			}
+			if i+1 < n {
+				if v, ok := decodeOctDigit(in[i+1]); ok {
+					i++
+					digit <<= 3
+					digit += v
+				}
+			}
+			buf.WriteByte(digit)
+		} else {
+			return "", pgerror.NewErrorf(pgerror.CodeSyntaxError,
+				"unknown escape sequence: %q", in[i-1:i+1])
+		}
+		start = i + 1
+	}
+	buf.WriteString(in[start:])
+	return buf.String(), nil
+}
+
+func decodeDigit(c byte, onlyOctal bool) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '7':
+		return c - '0', true
+	case !onlyOctal && c >= '8' && c <= '9':
+		return c - '0', true
+	case !onlyOctal && c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case !onlyOctal && c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	default:
+		return 0, false
+	}
+}
+
+func decodeOctDigit(c byte) (byte, bool) { return decodeDigit(c, true) }
+func decodeHexDigit(c byte) (byte, bool) { return decodeDigit(c, false) }
+
+var decodeMap = map[byte]byte{
+	'b':  '\b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'\\': '\\',
+}
@@ -0,0 +1,191 @@
+// Copyright 2016 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestDecodeCopy(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	tests := []struct {
+		in     string
+		expect string
+		err    bool
+	}{
+		{
+			in:     `new\nline`,
+			expect: "new\nline",
+		},
+		{
+			in:     `\b\f\n\r\t\v\\`,
+			expect: "\b\f\n\r\t\v\\",
+		},
+		{
+			in:     `\0\12\123`,
+			expect: "\000\012\123",
+		},
+		{
+			in:     `\x1\xaf`,
+			expect: "\x01\xaf",
+		},
+		{
+			in:     `a\	b`,
+			expect: "a\tb",
+		},
+		{
+			in:     `T\n\07\xEV\x0fA\xb2C\1`,
+			expect: "T\n\007\x0eV\x0fA\xb2C\001",
+		},
+
+		// Error cases.
+
+		{
+			in:  `\x`,
+			err: true,
+		},
+		{
+			in:  `\xg`,
+			err: true,
+		},
+		{
+			in:  `\`,
+			err: true,
+		},
+		{
+			in:  `\8`,
+			err: true,
+		},
+		{
+			in:  `\a`,
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		out, err := decodeCopy(test.in, '\t')
+		if gotErr := err != nil; gotErr != test.err {
+			if gotErr {
+				t.Errorf("%q: unexpected error: %v", test.in, err)
+				continue
+			}
+			t.Errorf("%q: expected error", test.in)
+			continue
+		}
+		if out != test.expect {
+			t.Errorf("%q: got %q, expected %q", test.in, out, test.expect)
+		}
+	}
+}
+
+func TestCopyReader(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(context.Background())
+
+	csv := DefaultCopyOptions(CopyFormatCSV)
+	csvHeader := csv
+	csvHeader.Header = true
+
+	testCases := []struct {
+		name  string
+		opts  CopyOptions
+		input string
+		// expected contains, for each row, either the formatted datums or the
+		// error.
+		expected []string
+	}{
+		{
+			name:     "text",
+			opts:     DefaultCopyOptions(CopyFormatText),
+			input:    "1\ta\n1\t\\tb\n2\t\\N\r\n\\N\tc\\\td",
+			expected: []string{"(1, 'a')", "(1, e'\\tb')", "(2, NULL)", "(NULL, e'c\\td')"},
+		},
+		{
+			name:     "text end marker",
+			opts:     DefaultCopyOptions(CopyFormatText),
+			input:    "1\ta\n\\.\n2\tb\n",
+			expected: []string{"(1, 'a')"},
+		},
+		{
+			name:  "text errors",
+			opts:  DefaultCopyOptions(CopyFormatText),
+			input: "1\ta\tb\nx\ty\n2\t\\q\n3\tc\n",
+			expected: []string{
+				"line 1: expected 2 values, got 3",
+				`line 2, column 1: could not parse "x" as type int: strconv.ParseInt: parsing "x": invalid syntax`,
+				`line 3, column 2: unknown escape sequence: "\\q"`,
+				"(3, 'c')",
+			},
+		},
+		{
+			name:     "csv",
+			opts:     csv,
+			input:    "1,a\n2,\"b,\"\"c\"\"\"\n3,\n4,\"\"\n",
+			expected: []string{"(1, 'a')", "(2, 'b,\"c\"')", "(3, NULL)", "(4, '')"},
+		},
+		{
+			name:     "csv multi-line field",
+			opts:     csvHeader,
+			input:    "i,s\n1,\"a\nb\"\n2,c\n",
+			expected: []string{"(1, e'a\\nb')", "(2, 'c')"},
+		},
+		{
+			name:  "csv errors",
+			opts:  csv,
+			input: "1\n2,a\n3,\"b",
+			expected: []string{
+				"line 1: expected 2 values, got 1",
+				"(2, 'a')",
+				"line 3: unterminated CSV quoted field",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rd := NewCopyReader(
+				strings.NewReader(tc.input), []types.T{types.Int, types.String}, evalCtx, tc.opts)
+			var res []string
+			for {
+				row, err := rd.Read()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					if _, ok := err.(*CopyRowError); !ok {
+						t.Fatalf("unexpected error: %v", err)
+					}
+					res = append(res, err.Error())
+					continue
+				}
+				res = append(res, tree.AsString(&row))
+			}
+			if a, e := fmt.Sprint(res), fmt.Sprint(tc.expected); a != e {
+				t.Errorf("expected:\n%s\ngot:\n%s", e, a)
+			}
+		})
+	}
+}
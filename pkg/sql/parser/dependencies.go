@@ -0,0 +1,491 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// DependencyKind is the kind of object referenced by a statement.
+type DependencyKind int
+
+const (
+	// DependencyRelation is a table or a view. The two cannot be told apart
+	// from the text of a statement that reads from them, e.g. `SELECT * FROM t`.
+	DependencyRelation DependencyKind = iota
+	// DependencyTable is a table.
+	DependencyTable
+	// DependencyView is a view.
+	DependencyView
+	// DependencySequence is a sequence.
+	DependencySequence
+	// DependencyFunction is a function.
+	DependencyFunction
+	// DependencyProcedure is a stored procedure.
+	DependencyProcedure
+)
+
+var dependencyKindName = [...]string{
+	DependencyRelation:  "relation",
+	DependencyTable:     "table",
+	DependencyView:      "view",
+	DependencySequence:  "sequence",
+	DependencyFunction:  "function",
+	DependencyProcedure: "procedure",
+}
+
+func (k DependencyKind) String() string {
+	return dependencyKindName[k]
+}
+
+// DependencyAccess describes how a statement uses an object.
+type DependencyAccess int
+
+const (
+	// DependencyRead indicates that the statement only reads the object.
+	DependencyRead DependencyAccess = iota
+	// DependencyWrite indicates that the statement modifies the object, its
+	// data or its schema. The object may also be read.
+	DependencyWrite
+)
+
+func (a DependencyAccess) String() string {
+	if a == DependencyWrite {
+		return "write"
+	}
+	return "read"
+}
+
+// Dependency is an object referenced by a statement.
+type Dependency struct {
+	// Name is the name of the object, as written in the statement.
+	Name   string
+	Kind   DependencyKind
+	Access DependencyAccess
+}
+
+// Dependencies returns the tables, views, sequences and functions referenced
+// by a statement, in order of first reference. An object referenced several
+// times is only returned once; if any of its references writes to it, it is
+// reported as written. This is meant for tools which need to know what a
+// statement touches without resolving it against a schema, such as
+// authorization pre-checks and dependency graphs for migrations.
+//
+// Since the statement is not resolved, names are returned as written and the
+// same object may appear under different names (e.g. `t` and `public.t`).
+// Sequences are only detected when they are named in DDL statements or passed
+// as a string literal to nextval, currval or setval. The names of common table
+// expressions are not reported.
+func Dependencies(stmt tree.Statement) []Dependency {
+	var e dependencyExtractor
+	e.visitStmt(stmt)
+	return e.deps
+}
+
+// DependenciesSQL parses the given SQL, which must contain a single
+// statement, and returns its dependencies. See Dependencies.
+func DependenciesSQL(sql string) ([]Dependency, error) {
+	stmt, err := ParseOne(sql)
+	if err != nil {
+		return nil, err
+	}
+	return Dependencies(stmt.AST), nil
+}
+
+// dependencyExtractor walks a statement and accumulates its dependencies.
+type dependencyExtractor struct {
+	deps []Dependency
+	// ctes is the stack of the names of the common table expressions in
+	// scope.
+	ctes []tree.Name
+}
+
+var _ tree.Visitor = &dependencyExtractor{}
+
+func (e *dependencyExtractor) add(name string, kind DependencyKind, access DependencyAccess) {
+	for i := range e.deps {
+		if d := &e.deps[i]; d.Name == name && d.Kind == kind {
+			if access == DependencyWrite {
+				d.Access = DependencyWrite
+			}
+			return
+		}
+	}
+	e.deps = append(e.deps, Dependency{Name: name, Kind: kind, Access: access})
+}
+
+func (e *dependencyExtractor) addTableName(
+	tn *tree.TableName, kind DependencyKind, access DependencyAccess,
+) {
+	if kind == DependencyRelation && !tn.ExplicitSchema {
+		for _, cte := range e.ctes {
+			if cte == tn.TableName {
+				return
+			}
+		}
+	}
+	e.add(tn.String(), kind, access)
+}
+
+func (e *dependencyExtractor) addTableNames(
+	names tree.TableNames, kind DependencyKind, access DependencyAccess,
+) {
+	for i := range names {
+		e.addTableName(&names[i], kind, access)
+	}
+}
+
+func (e *dependencyExtractor) visitStmt(stmt tree.Statement) {
+	switch t := stmt.(type) {
+	case *tree.Select:
+		e.visitSelect(t)
+	case *tree.ParenSelect:
+		e.visitSelect(t.Select)
+	case *tree.SelectClause, *tree.UnionClause, *tree.ValuesClause:
+		e.visitSelectStmt(t.(tree.SelectStatement))
+
+	case *tree.Insert:
+		defer e.visitWith(t.With)()
+		e.visitTarget(t.Table)
+		e.visitSelect(t.Rows)
+		if t.OnConflict != nil {
+			e.visitUpdateExprs(t.OnConflict.Exprs)
+			e.visitWhere(t.OnConflict.Where)
+		}
+		e.visitReturning(t.Returning)
+	case *tree.Update:
+		defer e.visitWith(t.With)()
+		e.visitTarget(t.Table)
+		e.visitUpdateExprs(t.Exprs)
+		e.visitWhere(t.Where)
+		e.visitOrderBy(t.OrderBy)
+		e.visitLimit(t.Limit)
+		e.visitReturning(t.Returning)
+	case *tree.Delete:
+		defer e.visitWith(t.With)()
+		e.visitTarget(t.Table)
+		e.visitWhere(t.Where)
+		e.visitOrderBy(t.OrderBy)
+		e.visitLimit(t.Limit)
+		e.visitReturning(t.Returning)
+	case *tree.Truncate:
+		e.addTableNames(t.Tables, DependencyTable, DependencyWrite)
+
+	case *tree.CreateTable:
+		e.addTableName(&t.Table, DependencyTable, DependencyWrite)
+		for _, def := range t.Defs {
+			e.visitTableDef(def)
+		}
+		if t.AsSource != nil {
+			e.visitSelect(t.AsSource)
+		}
+	case *tree.AlterTable:
+		e.addTableName(&t.Table, DependencyTable, DependencyWrite)
+		for _, cmd := range t.Cmds {
+			switch c := cmd.(type) {
+			case *tree.AlterTableAddColumn:
+				e.visitTableDef(c.ColumnDef)
+			case *tree.AlterTableAddConstraint:
+				e.visitTableDef(c.ConstraintDef)
+			}
+		}
+	case *tree.CreateIndex:
+		e.addTableName(&t.Table, DependencyTable, DependencyWrite)
+	case *tree.DropTable:
+		e.addTableNames(t.Names, DependencyTable, DependencyWrite)
+	case *tree.CreateTrigger:
+		e.addTableName(&t.Table, DependencyTable, DependencyWrite)
+	case *tree.DropTrigger:
+		e.addTableName(&t.Table, DependencyTable, DependencyWrite)
+	case *tree.CreateFunction:
+		e.add(t.Name.String(), DependencyFunction, DependencyWrite)
+		for _, stmt := range t.SQLBody {
+			e.visitStmt(stmt)
+		}
+		if t.PLBody != nil {
+			e.visitRoutineStmt(t.PLBody)
+		}
+	case *tree.CreateProcedure:
+		e.addTableName(&t.Name, DependencyProcedure, DependencyWrite)
+		for _, stmt := range t.SQLBody {
+			e.visitStmt(stmt)
+		}
+		if t.PLBody != nil {
+			e.visitRoutineStmt(t.PLBody)
+		}
+	case *tree.DropProcedure:
+		e.addTableName(&t.Name, DependencyProcedure, DependencyWrite)
+	case *tree.Call:
+		e.addTableName(&t.Name, DependencyProcedure, DependencyRead)
+		e.visitExprs(t.Args)
+	case *tree.CreateView:
+		e.addTableName(&t.Name, DependencyView, DependencyWrite)
+		e.visitSelect(t.AsSource)
+	case *tree.DropView:
+		e.addTableNames(t.Names, DependencyView, DependencyWrite)
+	case *tree.CreateSequence:
+		e.addTableName(&t.Name, DependencySequence, DependencyWrite)
+	case *tree.AlterSequence:
+		e.addTableName(&t.Name, DependencySequence, DependencyWrite)
+	case *tree.DropSequence:
+		e.addTableNames(t.Names, DependencySequence, DependencyWrite)
+	case *tree.RenameTable:
+		kind := DependencyTable
+		if t.IsView {
+			kind = DependencyView
+		} else if t.IsSequence {
+			kind = DependencySequence
+		}
+		e.addTableName(&t.Name, kind, DependencyWrite)
+
+	case *tree.Explain:
+		e.visitStmt(t.Statement)
+	}
+}
+
+// visitWith pushes the names of the given common table expressions on the
+// stack after visiting them, and returns a function that pops them. The
+// names of recursive common table expressions are pushed before visiting
+// them, since they can refer to themselves.
+func (e *dependencyExtractor) visitWith(with *tree.With) (pop func()) {
+	n := len(e.ctes)
+	if with != nil {
+		for _, cte := range with.CTEList {
+			if with.Recursive {
+				e.ctes = append(e.ctes, cte.Name.Alias)
+				e.visitStmt(cte.Stmt)
+				continue
+			}
+			e.visitStmt(cte.Stmt)
+			e.ctes = append(e.ctes, cte.Name.Alias)
+		}
+	}
+	return func() { e.ctes = e.ctes[:n] }
+}
+
+func (e *dependencyExtractor) visitSelect(sel *tree.Select) {
+	if sel == nil {
+		return
+	}
+	defer e.visitWith(sel.With)()
+	e.visitSelectStmt(sel.Select)
+	e.visitOrderBy(sel.OrderBy)
+	e.visitLimit(sel.Limit)
+}
+
+func (e *dependencyExtractor) visitSelectStmt(stmt tree.SelectStatement) {
+	switch t := stmt.(type) {
+	case *tree.SelectClause:
+		if t.From != nil {
+			for _, te := range t.From.Tables {
+				e.visitTableExpr(te)
+			}
+		}
+		e.visitExprs(t.DistinctOn)
+		for _, se := range t.Exprs {
+			e.visitExpr(se.Expr)
+		}
+		e.visitWhere(t.Where)
+		e.visitExprs(t.GroupBy)
+		e.visitWhere(t.Having)
+	case *tree.UnionClause:
+		e.visitSelect(t.Left)
+		e.visitSelect(t.Right)
+	case *tree.ValuesClause:
+		for _, row := range t.Rows {
+			e.visitExprs(row)
+		}
+	case *tree.ParenSelect:
+		e.visitSelect(t.Select)
+	}
+}
+
+// visitTarget visits the table modified by an INSERT, UPDATE or DELETE.
+func (e *dependencyExtractor) visitTarget(te tree.TableExpr) {
+	switch t := te.(type) {
+	case *tree.TableName:
+		e.addTableName(t, DependencyRelation, DependencyWrite)
+	case *tree.AliasedTableExpr:
+		e.visitTarget(t.Expr)
+	default:
+		e.visitTableExpr(te)
+	}
+}
+
+func (e *dependencyExtractor) visitTableExpr(te tree.TableExpr) {
+	switch t := te.(type) {
+	case *tree.TableName:
+		e.addTableName(t, DependencyRelation, DependencyRead)
+	case *tree.AliasedTableExpr:
+		e.visitTableExpr(t.Expr)
+		if t.Sample != nil {
+			e.visitExpr(t.Sample.Percent)
+			e.visitExpr(t.Sample.Repeatable)
+		}
+	case *tree.ParenTableExpr:
+		e.visitTableExpr(t.Expr)
+	case *tree.JoinTableExpr:
+		e.visitTableExpr(t.Left)
+		e.visitTableExpr(t.Right)
+		if on, ok := t.Cond.(*tree.OnJoinCond); ok {
+			e.visitExpr(on.Expr)
+		}
+	case *tree.RowsFromExpr:
+		e.visitExprs(t.Items)
+	case *tree.Subquery:
+		e.visitSelectStmt(t.Select)
+	case *tree.StatementSource:
+		e.visitStmt(t.Statement)
+	}
+}
+
+func (e *dependencyExtractor) visitRoutineStmt(stmt tree.RoutineStmt) {
+	switch t := stmt.(type) {
+	case *tree.RoutineBlock:
+		for i := range t.Decls {
+			e.visitExpr(t.Decls[i].Default)
+		}
+		e.visitRoutineStmts(t.Stmts)
+	case *tree.RoutineAssign:
+		e.visitExpr(t.Expr)
+	case *tree.RoutineIf:
+		e.visitExpr(t.Cond)
+		e.visitRoutineStmts(t.Then)
+		for i := range t.ElseIfs {
+			e.visitExpr(t.ElseIfs[i].Cond)
+			e.visitRoutineStmts(t.ElseIfs[i].Stmts)
+		}
+		e.visitRoutineStmts(t.Else)
+	case *tree.RoutineLoop:
+		e.visitExpr(t.While)
+		e.visitRoutineStmts(t.Stmts)
+	case *tree.RoutineExit:
+		e.visitExpr(t.When)
+	case *tree.RoutineReturn:
+		e.visitExpr(t.Expr)
+	case *tree.RoutineRaise:
+		e.visitExprs(t.Args)
+	case *tree.RoutineExec:
+		e.visitStmt(t.Statement)
+	}
+}
+
+func (e *dependencyExtractor) visitRoutineStmts(stmts []tree.RoutineStmt) {
+	for _, stmt := range stmts {
+		e.visitRoutineStmt(stmt)
+	}
+}
+
+func (e *dependencyExtractor) visitTableDef(def tree.TableDef) {
+	switch t := def.(type) {
+	case *tree.ColumnTableDef:
+		e.visitExpr(t.DefaultExpr.Expr)
+		for _, c := range t.CheckExprs {
+			e.visitExpr(c.Expr)
+		}
+		e.visitExpr(t.Computed.Expr)
+		if t.References.Table != nil {
+			e.addTableName(t.References.Table, DependencyTable, DependencyRead)
+		}
+	case *tree.CheckConstraintTableDef:
+		e.visitExpr(t.Expr)
+	case *tree.ForeignKeyConstraintTableDef:
+		e.addTableName(&t.Table, DependencyTable, DependencyRead)
+	}
+}
+
+func (e *dependencyExtractor) visitUpdateExprs(exprs tree.UpdateExprs) {
+	for _, ue := range exprs {
+		e.visitExpr(ue.Expr)
+	}
+}
+
+func (e *dependencyExtractor) visitWhere(where *tree.Where) {
+	if where != nil {
+		e.visitExpr(where.Expr)
+	}
+}
+
+func (e *dependencyExtractor) visitOrderBy(orderBy tree.OrderBy) {
+	for _, o := range orderBy {
+		if o.OrderType == tree.OrderByIndex {
+			e.addTableName(&o.Table, DependencyRelation, DependencyRead)
+			continue
+		}
+		e.visitExpr(o.Expr)
+	}
+}
+
+func (e *dependencyExtractor) visitLimit(limit *tree.Limit) {
+	if limit != nil {
+		e.visitExpr(limit.Count)
+		e.visitExpr(limit.Offset)
+	}
+}
+
+func (e *dependencyExtractor) visitReturning(r tree.ReturningClause) {
+	if exprs, ok := r.(*tree.ReturningExprs); ok {
+		for _, se := range *exprs {
+			e.visitExpr(se.Expr)
+		}
+	}
+}
+
+func (e *dependencyExtractor) visitExprs(exprs []tree.Expr) {
+	for _, expr := range exprs {
+		e.visitExpr(expr)
+	}
+}
+
+func (e *dependencyExtractor) visitExpr(expr tree.Expr) {
+	if expr != nil {
+		tree.WalkExprConst(e, expr)
+	}
+}
+
+// sequenceFunctions maps the functions that take the name of a sequence as
+// their first argument to the way they access the sequence.
+var sequenceFunctions = map[string]DependencyAccess{
+	"nextval": DependencyWrite,
+	"setval":  DependencyWrite,
+	"currval": DependencyRead,
+}
+
+// VisitPre is part of the tree.Visitor interface.
+func (e *dependencyExtractor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch t := expr.(type) {
+	case *tree.Subquery:
+		e.visitSelectStmt(t.Select)
+		return false, expr
+	case *tree.FuncExpr:
+		name := t.Func.String()
+		e.add(name, DependencyFunction, DependencyRead)
+		if access, ok := sequenceFunctions[strings.ToLower(name)]; ok && len(t.Exprs) > 0 {
+			if s, ok := t.Exprs[0].(*tree.StrVal); ok {
+				seqName := s.RawString()
+				if tn, err := ParseTableName(seqName); err == nil {
+					seqName = tn.String()
+				}
+				e.add(seqName, DependencySequence, access)
+			}
+		}
+	}
+	return true, expr
+}
+
+// VisitPost is part of the tree.Visitor interface.
+func (e *dependencyExtractor) VisitPost(expr tree.Expr) tree.Expr { return expr }
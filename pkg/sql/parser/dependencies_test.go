@@ -0,0 +1,100 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestDependencies(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	type dep struct {
+		name   string
+		kind   parser.DependencyKind
+		access parser.DependencyAccess
+	}
+	const (
+		rel  = parser.DependencyRelation
+		tab  = parser.DependencyTable
+		view = parser.DependencyView
+		seq  = parser.DependencySequence
+		fn   = parser.DependencyFunction
+		proc = parser.DependencyProcedure
+		r    = parser.DependencyRead
+		w    = parser.DependencyWrite
+	)
+	testData := []struct {
+		sql      string
+		expected []dep
+	}{
+		{`SELECT 1`, nil},
+		{`SELECT * FROM a JOIN db.public.b ON a.x = b.x, (SELECT 1 FROM c) AS sq`,
+			[]dep{{"a", rel, r}, {"db.public.b", rel, r}, {"c", rel, r}}},
+		{`SELECT count(*) FROM a WHERE x IN (SELECT x FROM b) ORDER BY lower(y)`,
+			[]dep{{"a", rel, r}, {"count", fn, r}, {"b", rel, r}, {"lower", fn, r}}},
+		{`WITH w AS (SELECT * FROM a) SELECT * FROM w, public.w`,
+			[]dep{{"a", rel, r}, {"public.w", rel, r}}},
+		{`WITH RECURSIVE w AS (SELECT * FROM a UNION ALL SELECT * FROM w) SELECT * FROM w`,
+			[]dep{{"a", rel, r}}},
+		{`INSERT INTO a SELECT * FROM a UNION SELECT * FROM b RETURNING nextval('s')`,
+			[]dep{{"a", rel, w}, {"b", rel, r}, {"nextval", fn, r}, {"s", seq, w}}},
+		{`UPDATE a SET x = (SELECT max(x) FROM b) WHERE y = currval('db.s')`,
+			[]dep{{"a", rel, w}, {"b", rel, r}, {"max", fn, r}, {"currval", fn, r}, {"db.s", seq, r}}},
+		{`DELETE FROM a AS aa WHERE EXISTS (SELECT 1 FROM [SELECT * FROM b])`,
+			[]dep{{"a", rel, w}, {"b", rel, r}}},
+		{`CREATE TABLE a (x INT DEFAULT nextval('s') REFERENCES b (x), FOREIGN KEY (x) REFERENCES c (x))`,
+			[]dep{{"a", tab, w}, {"nextval", fn, r}, {"s", seq, w}, {"b", tab, r}, {"c", tab, r}}},
+		{`CREATE VIEW v AS SELECT * FROM a`, []dep{{"v", view, w}, {"a", rel, r}}},
+		{`DROP VIEW v, w`, []dep{{"v", view, w}, {"w", view, w}}},
+		{`DROP SEQUENCE s`, []dep{{"s", seq, w}}},
+		{`TRUNCATE a`, []dep{{"a", tab, w}}},
+		{`ALTER TABLE a ADD CONSTRAINT fk FOREIGN KEY (x) REFERENCES b (x)`,
+			[]dep{{"a", tab, w}, {"b", tab, r}}},
+		{`EXPLAIN SELECT * FROM a`, []dep{{"a", rel, r}}},
+		{`CREATE FUNCTION f(x INT) RETURNS INT LANGUAGE sql AS 'DELETE FROM a; SELECT g(x) FROM b'`,
+			[]dep{{"f", fn, w}, {"a", rel, w}, {"b", rel, r}, {"g", fn, r}}},
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE plpgsql AS '
+		   DECLARE n INT := (SELECT count(*) FROM a);
+		   BEGIN
+		     IF n > 0 THEN INSERT INTO b VALUES (n); END IF;
+		     RETURN nextval(''s'');
+		   END'`,
+			[]dep{{"f", fn, w}, {"a", rel, r}, {"count", fn, r}, {"b", rel, w}, {"nextval", fn, r}, {"s", seq, w}}},
+		{`CREATE PROCEDURE p(x INT) LANGUAGE sql AS 'INSERT INTO a VALUES (x); DELETE FROM b'`,
+			[]dep{{"p", proc, w}, {"a", rel, w}, {"b", rel, w}}},
+		{`CALL p((SELECT max(x) FROM a))`, []dep{{"p", proc, r}, {"a", rel, r}, {"max", fn, r}}},
+		{`DROP PROCEDURE IF EXISTS p`, []dep{{"p", proc, w}}},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			res, err := parser.DependenciesSQL(d.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var actual []dep
+			for _, dd := range res {
+				actual = append(actual, dep{dd.Name, dd.Kind, dd.Access})
+			}
+			if !reflect.DeepEqual(actual, d.expected) {
+				t.Errorf("expected %v, got %v", d.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+
+// Fingerprint returns the fingerprint of a statement: its canonical text,
+// with the literals replaced by the placeholder marker "_" and the lists of
+// literals shortened. Statements which only differ by their literals, the
+// case of their keywords and unquoted identifiers, or their whitespace and
+// comments have the same fingerprint; for example, both
+//
+//   select * from T where a = 1 and b in (1, 2, 3)
+//   SELECT * FROM t WHERE a = 'x' AND b IN (4, 5, 6)
+//
+// have the fingerprint
+//
+//   SELECT * FROM t WHERE (a = _) AND (b IN (_, _, __more1__))
+//
+// Placeholders are kept as is. The fingerprints are the statement keys
+// reported in the statement statistics of the server (e.g. in
+// crdb_internal.node_statement_statistics), so that client-side statements
+// can be matched against them.
+func Fingerprint(stmt tree.Statement) string {
+	return tree.AsStringWithFlags(stmt, tree.FmtHideConstants)
+}
+
+// FingerprintSQL parses the given SQL and returns the fingerprint of each of
+// the statements it contains. See Fingerprint.
+func FingerprintSQL(sql string) ([]string, error) {
+	stmts, err := Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, len(stmts))
+	for i := range stmts {
+		res[i] = Fingerprint(stmts[i].AST)
+	}
+	return res, nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestFingerprint(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      []string
+		expected string
+	}{
+		{[]string{
+			`SELECT * FROM t WHERE a = 1 AND b IN (1, 2, 3)`,
+			`select  *  from T where A = 'foo' and b in (4, 5, 6) -- comment`,
+			"SELECT *\n\tFROM t\n\tWHERE a = -1.5e3 AND b IN (7, 8, 9)",
+		}, `SELECT * FROM t WHERE (a = _) AND (b IN (_, _, __more1__))`},
+		// Like the elements of lists, the rows of VALUES beyond the first are
+		// counted.
+		{[]string{
+			`INSERT INTO t VALUES (1, 'a'), (2, 'b'), (3, 'c')`,
+		}, `INSERT INTO t VALUES (_, _), (__more2__)`},
+		{[]string{
+			`insert into t values (4, 'd'), (5, 'e')`,
+		}, `INSERT INTO t VALUES (_, _), (__more1__)`},
+		{[]string{
+			`SELECT x FROM t WHERE y = $1 LIMIT 10`,
+			`SELECT x FROM t WHERE y = $1 LIMIT 20`,
+		}, `SELECT x FROM t WHERE y = $1 LIMIT _`},
+		{[]string{
+			`UPDATE t SET a = 'x'::STRING WHERE b = ARRAY[1, 2, 3]`,
+		}, `UPDATE t SET a = _::STRING WHERE b = ARRAY[_, _, __more1__]`},
+		// Quoted identifiers keep their case.
+		{[]string{
+			`SELECT "A" FROM "T"`,
+		}, `SELECT "A" FROM "T"`},
+	}
+	for _, d := range testData {
+		for _, sql := range d.sql {
+			t.Run(sql, func(t *testing.T) {
+				fingerprints, err := parser.FingerprintSQL(sql)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if expected := []string{d.expected}; !reflect.DeepEqual(fingerprints, expected) {
+					t.Errorf("expected %q, got %q", expected, fingerprints)
+				}
+			})
+		}
+	}
+}
+
+func TestFingerprintMultipleStatements(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fingerprints, err := parser.FingerprintSQL(`BEGIN; SELECT 1; SET application_name = 'foo'; COMMIT`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{`BEGIN TRANSACTION`, `SELECT _`, `SET application_name = _`, `COMMIT TRANSACTION`}
+	if !reflect.DeepEqual(fingerprints, expected) {
+		t.Errorf("expected %q, got %q", expected, fingerprints)
+	}
+
+	if _, err := parser.FingerprintSQL(`SELECT * FROM`); err == nil {
+		t.Error("expected a syntax error")
+	}
+}
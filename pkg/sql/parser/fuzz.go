@@ -0,0 +1,130 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+)
+
+// FuzzOutcome is the outcome of ParseFuzz.
+type FuzzOutcome int
+
+const (
+	// FuzzParsed means that the input was parsed, and that each of its
+	// statements round-trips through formatting.
+	FuzzParsed FuzzOutcome = iota
+	// FuzzRejected means that the input was rejected with an error, e.g. a
+	// syntax error. This is not a bug of the parser.
+	FuzzRejected
+	// FuzzPanicked means that the parser panicked on the input. This is a
+	// bug of the parser.
+	FuzzPanicked
+	// FuzzRoundTripFailed means that the input was parsed, but that one of
+	// its statements does not parse back into the same AST once formatted.
+	// This is a bug of the parser or of the formatter. See
+	// VerifyStatementRoundTrip.
+	FuzzRoundTripFailed
+)
+
+var fuzzOutcomeNames = [...]string{
+	FuzzParsed:          "parsed",
+	FuzzRejected:        "rejected",
+	FuzzPanicked:        "panicked",
+	FuzzRoundTripFailed: "round trip failed",
+}
+
+func (o FuzzOutcome) String() string {
+	return fuzzOutcomeNames[o]
+}
+
+// IsBug returns true if the outcome reveals a bug, as opposed to an input
+// being accepted or rejected.
+func (o FuzzOutcome) IsBug() bool {
+	return o == FuzzPanicked || o == FuzzRoundTripFailed
+}
+
+// FuzzResult is the result of ParseFuzz.
+type FuzzResult struct {
+	Outcome FuzzOutcome
+	// Statements are the statements of the input, unless it was rejected or
+	// the parser panicked.
+	Statements Statements
+	// Err is the error of the parser if the input was rejected, a
+	// pgerror.Error with CodeInternalError holding the panic and its stack
+	// trace in its detail if the parser panicked, or the error of
+	// VerifyStatementRoundTrip if the round trip failed.
+	Err error
+}
+
+// Limits applied by ParseFuzz, which keep the work done for a single input
+// bounded: the formatting and the comparison of the ASTs are recursive, so a
+// deeply nested statement could exhaust the stack, which cannot be
+// recovered from.
+const (
+	fuzzMaxStatementSize = 1 << 16
+	fuzzMaxNestingDepth  = 200
+)
+
+// ParseFuzz parses an arbitrary input, including invalid UTF-8 and NUL
+// bytes, and classifies the result. It never panics: a panic of the parser
+// is recovered and reported as FuzzPanicked. It is meant to be the target of
+// fuzzers such as go-fuzz, which feed it byte slices, as well as to check
+// that the SQL generated by other tools (ORMs, query builders, etc.) is
+// accepted by the grammar and means what they intended:
+//
+//   if res := parser.ParseFuzz([]byte(sql)); res.Outcome != parser.FuzzParsed {
+//     t.Errorf("%s: %s: %v", sql, res.Outcome, res.Err)
+//   }
+//
+// The outcome only depends on the input, so that the inputs of a corpus can
+// be replayed. The inputs larger than 64KiB or nested more than 200 levels
+// deep are rejected.
+func ParseFuzz(data []byte) (res FuzzResult) {
+	sql := string(data)
+	defer func() {
+		if r := recover(); r != nil {
+			res = FuzzResult{
+				Outcome: FuzzPanicked,
+				Err: pgerror.NewErrorf(pgerror.CodeInternalError, "parser panic: %v", r).
+					SetDetailf("input: %q\n%s", sql, debug.Stack()),
+			}
+		}
+	}()
+
+	stmts, err := ParseWithOptions(sql, ParseOptions{
+		MaxStatementSize: fuzzMaxStatementSize,
+		MaxNestingDepth:  fuzzMaxNestingDepth,
+	})
+	if err != nil {
+		return FuzzResult{Outcome: FuzzRejected, Err: err}
+	}
+	for _, stmt := range stmts {
+		if err := VerifyStatementRoundTrip(stmt.AST); err != nil {
+			return FuzzResult{Outcome: FuzzRoundTripFailed, Statements: stmts, Err: err}
+		}
+	}
+	return FuzzResult{Outcome: FuzzParsed, Statements: stmts}
+}
+
+// String implements the fmt.Stringer interface.
+func (r FuzzResult) String() string {
+	if r.Err == nil {
+		return r.Outcome.String()
+	}
+	return fmt.Sprintf("%s: %v", r.Outcome, r.Err)
+}
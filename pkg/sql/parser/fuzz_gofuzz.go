@@ -0,0 +1,40 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build gofuzz
+
+package parser
+
+// Fuzz is the entry point for go-fuzz. The inputs which reveal a bug (see
+// FuzzOutcome.IsBug) panic, so that the fuzzer records them as crashers, and
+// the inputs which parse are given priority in the corpus.
+//
+// To run:
+//
+//     $ go get github.com/dvyukov/go-fuzz/...
+//     $ go-fuzz-build github.com/cockroachdb/cockroach/pkg/sql/parser
+//     $ go-fuzz -bin=parser-fuzz.zip -workdir=/tmp/parser-fuzz
+//
+// The corpus can be seeded with files holding a SQL statement each, e.g.
+// the statements of the logic tests.
+func Fuzz(data []byte) int {
+	res := ParseFuzz(data)
+	if res.Outcome.IsBug() {
+		panic(res.String())
+	}
+	if res.Outcome == FuzzParsed {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseFuzz(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		input    string
+		outcome  parser.FuzzOutcome
+		expected string
+	}{
+		{``, parser.FuzzParsed, ``},
+		{`SELECT 1; INSERT INTO t VALUES ('a')`, parser.FuzzParsed, ``},
+		{`SELEC 1`, parser.FuzzRejected, `syntax error`},
+		// A NUL does not end the input.
+		{"SELECT 1\x00; DROP TABLE t", parser.FuzzRejected, `invalid NUL character`},
+		{"SELECT \xff\xfe", parser.FuzzRejected, `invalid UTF-8 byte sequence`},
+		{"SELECT '\xff'", parser.FuzzRejected, `invalid UTF-8 byte sequence`},
+		{`SELECT ` + strings.Repeat(`(`, 300) + `1` + strings.Repeat(`)`, 300),
+			parser.FuzzRejected, `statement exceeds the maximum nesting depth`},
+	}
+	for _, d := range testData {
+		t.Run(d.input, func(t *testing.T) {
+			res := parser.ParseFuzz([]byte(d.input))
+			if res.Outcome != d.outcome {
+				t.Fatalf("expected %s, found %s", d.outcome, res)
+			}
+			if d.expected != "" && !testutils.IsError(res.Err, d.expected) {
+				t.Fatalf("expected error %q, found %v", d.expected, res.Err)
+			}
+			if res.Outcome.IsBug() {
+				t.Fatalf("unexpected bug: %s", res)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"sort"
+	"strings"
+)
+
+// HelpEntry is the structured form of the help message of a statement, or
+// of a group of statements, as displayed by \h in the CLI shell. It allows
+// shells and documentation tools to render the help without parsing the
+// text of HelpMessage.
+type HelpEntry struct {
+	// Command is the statement, e.g. "CREATE TABLE", or the group of
+	// statements, e.g. "CREATE".
+	Command string
+	// Category is the category of the statement, e.g. "schema
+	// manipulation". It is empty for a group.
+	Category         string
+	ShortDescription string
+	// Syntax is the syntax summary of the statement. For a group, it lists
+	// the statements of the group.
+	Syntax string
+	// Statements are the statements of a group, each of which has its own
+	// entry. It is empty for a statement.
+	Statements []string
+	// SeeAlso are the related statements, each of which can be looked up
+	// with LookupHelp.
+	SeeAlso []string
+	// DocsURLs are the links to the related documentation pages.
+	DocsURLs []string
+}
+
+// IsGroup returns true if the entry documents a group of statements.
+func (e *HelpEntry) IsGroup() bool {
+	return e.Category == hGroup
+}
+
+// helpRegistry is HelpMessages in structured form, sorted by command. The
+// pseudo-entries whose key is enclosed in angle brackets, e.g. <SOURCE>,
+// are left out: their text is already part of the entries that refer to
+// them.
+var helpRegistry = func(h map[string]HelpMessageBody) []HelpEntry {
+	res := make([]HelpEntry, 0, len(h))
+	for cmd, m := range h {
+		if strings.HasPrefix(cmd, "<") {
+			continue
+		}
+		res = append(res, makeHelpEntry(cmd, m))
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Command < res[j].Command })
+	return res
+}(HelpMessages)
+
+func makeHelpEntry(cmd string, m HelpMessageBody) HelpEntry {
+	e := HelpEntry{
+		Command:          cmd,
+		Category:         m.Category,
+		ShortDescription: m.ShortDescription,
+		Syntax:           m.Text,
+	}
+	if e.IsGroup() {
+		for _, s := range strings.Split(strings.Join(strings.Fields(m.Text), " "), ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				e.Statements = append(e.Statements, s)
+			}
+		}
+	}
+	// The "see also" items are separated by newlines, see HelpMessages.
+	for _, item := range strings.Split(m.SeeAlso, "\n") {
+		item = strings.TrimSpace(item)
+		switch {
+		case item == "":
+		case strings.Contains(item, "://"):
+			e.DocsURLs = append(e.DocsURLs, item)
+		default:
+			e.SeeAlso = append(e.SeeAlso, item)
+		}
+	}
+	return e
+}
+
+// HelpRegistry returns the help entries of all the statements and groups
+// of statements, sorted by command. The result is shared and must not be
+// modified.
+func HelpRegistry() []HelpEntry {
+	return helpRegistry
+}
+
+// LookupHelp returns the help entry of the given statement or group of
+// statements, e.g. "CREATE TABLE". The result is shared and must not be
+// modified.
+func LookupHelp(command string) (HelpEntry, bool) {
+	i := sort.Search(len(helpRegistry), func(i int) bool {
+		return helpRegistry[i].Command >= command
+	})
+	if i < len(helpRegistry) && helpRegistry[i].Command == command {
+		return helpRegistry[i], true
+	}
+	return HelpEntry{}, false
+}
+
+// HelpCategories returns the categories of the statements in HelpRegistry,
+// sorted, in the order in which they are listed by AllHelp.
+func HelpCategories() []string {
+	seen := make(map[string]struct{})
+	var res []string
+	for i := range helpRegistry {
+		c := helpRegistry[i].Category
+		if _, ok := seen[c]; ok || c == hGroup {
+			continue
+		}
+		seen[c] = struct{}{}
+		res = append(res, c)
+	}
+	sort.Strings(res)
+	return res
+}
@@ -15,6 +15,7 @@
 package parser
 
 import (
+	"sort"
 	"strings"
 	"testing"
 
@@ -106,6 +107,21 @@ func TestContextualHelp(t *testing.T) {
 
 		{`CREATE ROLE bleh ??`, `CREATE ROLE`},
 
+		{`CREATE TRIGGER ??`, `CREATE TRIGGER`},
+		{`CREATE TRIGGER blah BEFORE INSERT ON x ??`, `CREATE TRIGGER`},
+
+		{`CREATE FUNCTION ??`, `CREATE FUNCTION`},
+		{`CREATE OR REPLACE FUNCTION f(a INT) RETURNS ??`, `CREATE FUNCTION`},
+
+		{`CREATE PROCEDURE ??`, `CREATE PROCEDURE`},
+		{`CREATE OR REPLACE PROCEDURE p(a INT) LANGUAGE ??`, `CREATE PROCEDURE`},
+
+		{`CALL ??`, `CALL`},
+		{`CALL p(??`, `CALL`},
+
+		{`CREATE SCHEDULE ??`, `CREATE SCHEDULE`},
+		{`CREATE SCHEDULE blah FOR SQL STATEMENT 'SELECT 1' ??`, `CREATE SCHEDULE`},
+
 		{`CREATE VIEW blah (??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS (SELECT c FROM x) ??`, `CREATE VIEW`},
 		{`CREATE VIEW blah AS SELECT c FROM x ??`, `SELECT`},
@@ -151,6 +167,15 @@ func TestContextualHelp(t *testing.T) {
 		{`DROP TABLE IF ??`, `DROP TABLE`},
 		{`DROP TABLE IF EXISTS blih, bloh ??`, `DROP TABLE`},
 
+		{`DROP TRIGGER blah ??`, `DROP TRIGGER`},
+		{`DROP TRIGGER IF ??`, `DROP TRIGGER`},
+
+		{`DROP PROCEDURE ??`, `DROP PROCEDURE`},
+		{`DROP PROCEDURE IF ??`, `DROP PROCEDURE`},
+
+		{`DROP SCHEDULE ??`, `DROP SCHEDULE`},
+		{`DROP SCHEDULE IF ??`, `DROP SCHEDULE`},
+
 		{`DROP VIEW blah ??`, `DROP VIEW`},
 		{`DROP VIEW IF ??`, `DROP VIEW`},
 		{`DROP VIEW IF EXISTS blih, bloh ??`, `DROP VIEW`},
@@ -207,9 +232,13 @@ func TestContextualHelp(t *testing.T) {
 		{`GRANT ALL ON foo TO ??`, `GRANT`},
 		{`GRANT ALL ON foo TO bar ??`, `GRANT`},
 
-		{`PAUSE ??`, `PAUSE JOBS`},
+		{`PAUSE ??`, `PAUSE`},
+		{`PAUSE JOB ??`, `PAUSE JOBS`},
+		{`PAUSE SCHEDULE ??`, `PAUSE SCHEDULE`},
 
-		{`RESUME ??`, `RESUME JOBS`},
+		{`RESUME ??`, `RESUME`},
+		{`RESUME JOB ??`, `RESUME JOBS`},
+		{`RESUME SCHEDULE ??`, `RESUME SCHEDULE`},
 
 		{`REVOKE ALL ??`, `REVOKE`},
 		{`REVOKE ALL ON foo FROM ??`, `REVOKE`},
@@ -265,6 +294,7 @@ func TestContextualHelp(t *testing.T) {
 		{`SHOW CREATE SEQUENCE blah ??`, `SHOW CREATE`},
 
 		{`SHOW DATABASES ??`, `SHOW DATABASES`},
+		{`SHOW EVENTS ??`, `SHOW EVENTS`},
 
 		{`SHOW GRANTS ON ??`, `SHOW GRANTS`},
 		{`SHOW GRANTS ON foo FOR ??`, `SHOW GRANTS`},
@@ -281,6 +311,8 @@ func TestContextualHelp(t *testing.T) {
 
 		{`SHOW ROLES ??`, `SHOW ROLES`},
 
+		{`SHOW SCHEDULES ??`, `SHOW SCHEDULES`},
+
 		{`SHOW SCHEMAS FROM ??`, `SHOW SCHEMAS`},
 		{`SHOW SCHEMAS FROM blah ??`, `SHOW SCHEMAS`},
 
@@ -448,3 +480,50 @@ func TestHelpKeys(t *testing.T) {
 		})
 	}
 }
+
+func TestHelpRegistry(t *testing.T) {
+	registry := HelpRegistry()
+	for i, e := range registry {
+		if i > 0 && registry[i-1].Command >= e.Command {
+			t.Errorf("entries not sorted: %q before %q", registry[i-1].Command, e.Command)
+		}
+		body, ok := HelpMessages[e.Command]
+		if !ok {
+			t.Errorf("%q: no help message", e.Command)
+			continue
+		}
+		if e.Category != body.Category || e.Syntax != body.Text {
+			t.Errorf("%q: entry does not match its help message", e.Command)
+		}
+		if found, ok := LookupHelp(e.Command); !ok || found.Command != e.Command {
+			t.Errorf("%q: lookup failed", e.Command)
+		}
+	}
+	if _, ok := LookupHelp("<SOURCE>"); ok {
+		t.Errorf("unexpected entry for <SOURCE>")
+	}
+
+	e, _ := LookupHelp("SHOW TABLES")
+	if e.ShortDescription != "list tables" || e.Category != hDDL ||
+		len(e.DocsURLs) != 1 || !strings.HasSuffix(e.DocsURLs[0], "/show-tables.html") {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	e, _ = LookupHelp("CREATE TABLE")
+	if e.IsGroup() || len(e.SeeAlso) == 0 || e.SeeAlso[0] != "SHOW TABLES" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+	e, _ = LookupHelp("ALTER")
+	if !e.IsGroup() || len(e.Statements) == 0 || e.Statements[0] != "ALTER TABLE" {
+		t.Errorf("unexpected entry %+v", e)
+	}
+
+	categories := HelpCategories()
+	if len(categories) == 0 || !sort.StringsAreSorted(categories) {
+		t.Errorf("unexpected categories %v", categories)
+	}
+	for _, c := range categories {
+		if c == hGroup {
+			t.Errorf("unexpected group category")
+		}
+	}
+}
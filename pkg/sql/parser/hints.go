@@ -0,0 +1,147 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// parseHints parses the contents of the /*+ ... */ hint comments of a
+// statement. The hints are a whitespace-separated list of:
+//
+//   INDEX(<table> <index>)
+//   HASH_JOIN(<table> <table>...)
+//   LOOKUP_JOIN(<table> <table>...)
+//   MERGE_JOIN(<table> <table>...)
+//   ROWS(<table>... <rows>)
+//
+// The hint names are case-insensitive, and the names and arguments are
+// lexed like SQL identifiers and integers. The arguments may be separated
+// by commas.
+func parseHints(comments []string) (tree.StatementHints, error) {
+	var hints tree.StatementHints
+	for _, c := range comments {
+		p := hintParser{comment: c, s: makeScanner(c)}
+		for p.next(); p.tok.id != 0; {
+			h, err := p.parseHint()
+			if err != nil {
+				return nil, err
+			}
+			hints = append(hints, h)
+		}
+	}
+	return hints, nil
+}
+
+// hintParser parses the hints in a hint comment.
+type hintParser struct {
+	comment string
+	s       scanner
+	// tok is the current token.
+	tok sqlSymType
+}
+
+func (p *hintParser) next() {
+	p.s.scan(&p.tok)
+}
+
+func (p *hintParser) errorf(format string, args ...interface{}) error {
+	return pgerror.NewErrorf(pgerror.CodeSyntaxError, format, args...).SetDetailf(
+		"in hint comment /*+%s*/", p.comment)
+}
+
+// isWord returns whether the current token is an identifier or a keyword.
+func (p *hintParser) isWord() bool {
+	return p.tok.id == IDENT || p.tok.id == lex.GetKeywordID(p.tok.str)
+}
+
+func (p *hintParser) parseHint() (tree.StatementHint, error) {
+	if !p.isWord() {
+		return nil, p.unexpected()
+	}
+	name := p.tok.str
+	p.next()
+	if p.tok.id != '(' {
+		return nil, p.unexpected()
+	}
+	p.next()
+	var args tree.NameList
+	var rows *tree.NumVal
+	for p.tok.id != ')' {
+		switch {
+		case rows != nil:
+			return nil, p.unexpected()
+		case p.isWord():
+			args = append(args, tree.Name(p.tok.str))
+		case p.tok.id == ICONST && name == "rows":
+			rows = p.tok.union.numVal()
+		default:
+			return nil, p.unexpected()
+		}
+		p.next()
+		if p.tok.id == ',' {
+			p.next()
+		}
+	}
+	p.next()
+
+	switch name {
+	case "index":
+		if len(args) != 2 {
+			return nil, p.errorf("INDEX hint expects a table and an index")
+		}
+		return &tree.IndexHint{Table: args[0], Index: tree.UnrestrictedName(args[1])}, nil
+
+	case "hash_join", "lookup_join", "merge_join":
+		if len(args) < 2 {
+			return nil, p.errorf("%s hint expects at least two tables", strings.ToUpper(name))
+		}
+		method := map[string]string{
+			"hash_join":   tree.AstHash,
+			"lookup_join": tree.AstLookup,
+			"merge_join":  tree.AstMerge,
+		}[name]
+		return &tree.JoinHint{Method: method, Tables: args}, nil
+
+	case "rows":
+		if len(args) == 0 || rows == nil {
+			return nil, p.errorf("ROWS hint expects at least one table and a row count")
+		}
+		n, err := rows.AsInt64()
+		if err != nil {
+			return nil, p.errorf("invalid row count %s: %v", rows.OrigString, err)
+		}
+		return &tree.RowsHint{Tables: args, Rows: n}, nil
+
+	default:
+		return nil, p.errorf("unknown hint %s", name)
+	}
+}
+
+// unexpected returns an error for the current token.
+func (p *hintParser) unexpected() error {
+	switch p.tok.id {
+	case 0:
+		return p.errorf("unexpected end of hint comment")
+	case ERROR:
+		return p.errorf("%s", p.tok.str)
+	default:
+		return p.errorf("unexpected %q in hint", p.tok.str)
+	}
+}
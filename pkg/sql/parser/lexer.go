@@ -18,10 +18,13 @@ import (
 	"bytes"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util"
 )
 
 type lexer struct {
@@ -43,6 +46,14 @@ type lexer struct {
 	numPlaceholders int
 
 	lastError *pgerror.Error
+
+	// tokenEnds are the end positions of the tokens, if the source ranges
+	// of the statement are requested, and parser is the parser consuming
+	// the tokens, whose lookahead determines the end of the ranges. See
+	// recordRanges.
+	tokenEnds []int32
+	parser    sqlParser
+	ranges    map[SourceRangeKey]SourceRange
 }
 
 func (l *lexer) init(
@@ -54,6 +65,9 @@ func (l *lexer) init(
 	l.stmt = nil
 	l.numPlaceholders = 0
 	l.lastError = nil
+	l.tokenEnds = nil
+	l.parser = nil
+	l.ranges = nil
 
 	l.nakedIntType = nakedIntType
 	l.nakedSerialType = nakedSerialType
@@ -65,6 +79,9 @@ func (l *lexer) cleanup() {
 	l.tokens = nil
 	l.stmt = nil
 	l.lastError = nil
+	l.tokenEnds = nil
+	l.parser = nil
+	l.ranges = nil
 }
 
 // Lex lexes a token from input.
@@ -73,8 +90,9 @@ func (l *lexer) Lex(lval *sqlSymType) int {
 	// The core lexing takes place in the scanner. Here we do a small bit of post
 	// processing of the lexical tokens so that the grammar only requires
 	// one-token lookahead despite SQL requiring multi-token lookahead in some
-	// cases. These special cases are handled below and the returned tokens are
-	// adjusted to reflect the lookahead (LA) that occurred.
+	// cases. These special cases are described by the LOOKAHEAD rules of the
+	// grammar and the returned tokens are adjusted to reflect the lookahead
+	// (LA) that occurred.
 	if l.lastPos >= len(l.tokens) {
 		lval.id = 0
 		lval.pos = int32(len(l.in))
@@ -83,37 +101,38 @@ func (l *lexer) Lex(lval *sqlSymType) int {
 	}
 	*lval = l.tokens[l.lastPos]
 
-	switch lval.id {
-	case NOT, WITH, AS:
-		nextID := int32(0)
-		if l.lastPos+1 < len(l.tokens) {
-			nextID = l.tokens[l.lastPos+1].id
-		}
-
-		// If you update these cases, update lex.lookaheadKeywords.
-		switch lval.id {
-		case AS:
-			switch nextID {
-			case OF:
-				lval.id = AS_LA
-			}
-		case NOT:
-			switch nextID {
-			case BETWEEN, IN, LIKE, ILIKE, SIMILAR:
-				lval.id = NOT_LA
-			}
-
-		case WITH:
-			switch nextID {
-			case TIME, ORDINALITY:
-				lval.id = WITH_LA
-			}
+	for _, r := range lookaheadRules(lval.id) {
+		if l.lookaheadMatches(r.next) {
+			lval.id = r.id
+			break
 		}
 	}
 
 	return int(lval.id)
 }
 
+// lookaheadRule is a rule to translate a token into the lookahead token id
+// when it is followed by the tokens next. The rules are generated from the
+// LOOKAHEAD lines of sql.y by lookahead.awk, see lookaheadRules.
+type lookaheadRule struct {
+	next []int32
+	id   int32
+}
+
+// lookaheadMatches returns whether the tokens after the current one are the
+// given ones.
+func (l *lexer) lookaheadMatches(next []int32) bool {
+	if l.lastPos+len(next) >= len(l.tokens) {
+		return false
+	}
+	for i, id := range next {
+		if l.tokens[l.lastPos+1+i].id != id {
+			return false
+		}
+	}
+	return true
+}
+
 func (l *lexer) lastToken() sqlSymType {
 	if l.lastPos < 0 {
 		return sqlSymType{}
@@ -132,6 +151,7 @@ func (l *lexer) lastToken() sqlSymType {
 // SetStmt is called from the parser when the statement is constructed.
 func (l *lexer) SetStmt(stmt tree.Statement) {
 	l.stmt = stmt
+	l.recordStmtRange(stmt)
 }
 
 // UpdateNumPlaceholders is called from the parser when a placeholder is constructed.
@@ -197,8 +217,20 @@ func (l *lexer) populateErrorDetails() {
 			l.lastError.Message = fmt.Sprintf("syntax error: %s", l.lastError.Message)
 		}
 		l.lastError.Message = fmt.Sprintf("%s at or near \"%s\"", l.lastError.Message, lastTok.str)
+
+		// If the offending token is an identifier that looks like a
+		// misspelled keyword or function name, suggest the correct spelling.
+		if lastTok.id == IDENT && l.in[lastTok.pos] != identQuote && l.lastError.Hint == "" {
+			l.lastError.Hint = suggestSpelling(lastTok.str)
+		}
 	}
 
+	// Report the position of the last token, like Postgres does: the
+	// position is in characters and 1-based. It is relative to the
+	// statement here, and adjusted by the parser when the statement is
+	// part of a larger input.
+	l.lastError.Position = int32(utf8.RuneCountInString(l.in[:lastTok.pos])) + 1
+
 	// Find the end of the line containing the last token.
 	i := strings.IndexByte(l.in[lastTok.pos:], '\n')
 	if i == -1 {
@@ -229,11 +261,15 @@ func (l *lexer) SetHelp(msg HelpMessage) {
 	if lastTok := l.lastToken(); lastTok.id == HELPTOKEN {
 		l.populateHelpMsg(msg.String())
 	} else {
+		hint := `try \hf ` + msg.Function
 		if msg.Command != "" {
-			l.lastError.Hint = `try \h ` + msg.Command
-		} else {
-			l.lastError.Hint = `try \hf ` + msg.Function
+			hint = `try \h ` + msg.Command
 		}
+		if l.lastError.Hint != "" {
+			// Keep the spelling suggestion, if any.
+			hint = l.lastError.Hint + "\n" + hint
+		}
+		l.lastError.Hint = hint
 	}
 }
 
@@ -241,3 +277,24 @@ func (l *lexer) populateHelpMsg(msg string) {
 	l.lastError.Message = "help token in input"
 	l.lastError.Hint = msg
 }
+
+// suggestSpelling returns a hint suggesting the keyword or builtin function
+// that ident is most likely a misspelling of, or "" if there is none.
+// Keywords take precedence over functions at equal distance, since the
+// grammar expected a keyword if it stopped at an identifier.
+func suggestSpelling(ident string) string {
+	s := util.MakeSpellingSuggester(ident)
+	for _, kw := range lex.KeywordNames {
+		s.Add(kw)
+	}
+	kw, kwDist := s.Best()
+	if fn := tree.SuggestFunctionName(ident); fn != "" {
+		if kw == "" || util.EditDistance(ident, fn) < kwDist {
+			return fmt.Sprintf("did you mean %s()?", fn)
+		}
+	}
+	if kw != "" {
+		return fmt.Sprintf("did you mean %s?", strings.ToUpper(kw))
+	}
+	return ""
+}
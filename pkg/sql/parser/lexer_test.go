@@ -31,6 +31,15 @@ func TestLexer(t *testing.T) {
 		{`NOT IN`, []int{NOT_LA, IN}},
 		{`NOT SIMILAR`, []int{NOT_LA, SIMILAR}},
 		{`AS OF SYSTEM TIME`, []int{AS_LA, OF, SYSTEM, TIME}},
+		{`NOT LIKE`, []int{NOT_LA, LIKE}},
+		{`NOT ILIKE`, []int{NOT_LA, ILIKE}},
+		{`GENERATED ALWAYS`, []int{GENERATED_LA, ALWAYS}},
+		{`GENERATED BY DEFAULT`, []int{GENERATED_LA, BY, DEFAULT}},
+		{`NOT NULL`, []int{NOT, NULL}},
+		{`WITH RECURSIVE`, []int{WITH, RECURSIVE}},
+		{`AS SYSTEM`, []int{AS, SYSTEM}},
+		{`NOT`, []int{NOT}},
+		{`GENERATED`, []int{GENERATED}},
 	}
 	for i, d := range testData {
 		s := makeScanner(d.sql)
@@ -59,5 +68,39 @@ func TestLexer(t *testing.T) {
 			t.Errorf("%d: %q: expected %d, but found %d", i, d.sql, d.expected, lexTokens)
 		}
 	}
+}
 
+func TestLookaheadMatches(t *testing.T) {
+	// The rules of the grammar only look one token ahead; check that longer
+	// lookaheads work too.
+	const sql = `NOT NULL AND`
+	s := makeScanner(sql)
+	var tokens []sqlSymType
+	for {
+		var lval sqlSymType
+		s.scan(&lval)
+		if lval.id == 0 {
+			break
+		}
+		tokens = append(tokens, lval)
+	}
+	testData := []struct {
+		next     []int32
+		expected bool
+	}{
+		{[]int32{NULL}, true},
+		{[]int32{NULL, AND}, true},
+		{[]int32{NULL, OR}, false},
+		{[]int32{AND}, false},
+		{[]int32{NULL, AND, NOT}, false},
+	}
+	for i, d := range testData {
+		var l lexer
+		l.init(sql, tokens, defaultNakedIntType, defaultNakedSerialType)
+		// Position the lexer on NOT.
+		l.lastPos = 0
+		if res := l.lookaheadMatches(d.next); res != d.expected {
+			t.Errorf("%d: %v: expected %t, but found %t", i, d.next, d.expected, res)
+		}
+	}
 }
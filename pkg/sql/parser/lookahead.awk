@@ -0,0 +1,75 @@
+# This script generates the lookahead tables of the lexer from the
+# "LOOKAHEAD <LA token>: <token> <next tokens...>" lines of sql.y.
+#
+# By default it prints the rules for the parser package. With
+# -v output=keywords, it prints instead the list of the keywords which the
+# lexer looks ahead at, for the lex package.
+
+BEGIN {
+  ntokens = 0
+}
+
+/^\/\/ LOOKAHEAD [A-Z][_A-Z0-9]*: / {
+  if (NF < 5) {
+    printf("line %d: LOOKAHEAD rule without next tokens\n", NR) > "/dev/stderr"
+    exit 1
+  }
+  la = substr($3, 1, length($3) - 1)
+  tok = $4
+  next_toks = $5
+  for (i = 6; i <= NF; i++) {
+    next_toks = next_toks ", " $i
+  }
+  if (!(tok in rules)) {
+    tokens[ntokens++] = tok
+  }
+  rules[tok] = rules[tok] sprintf("\t{next: []int32{%s}, id: %s},\n", next_toks, la)
+  for (i = 5; i <= NF; i++) {
+    keywords[tolower($i)] = 1
+  }
+}
+
+END {
+  print "// Code generated by lookahead.awk. DO NOT EDIT."
+  print "// GENERATED FILE DO NOT EDIT"
+  print ""
+
+  if (output == "keywords") {
+    print "package lex"
+    print ""
+    print "// lookaheadKeywords are the keywords which the lexer looks ahead at to"
+    print "// determine the token type of the keyword before them, e.g. LIKE in NOT"
+    print "// LIKE. They are derived from the LOOKAHEAD rules in sql.y."
+    print "var lookaheadKeywords = []string{"
+    # This variable will be associated with a pipe for intermediate output.
+    sort = "env LC_ALL=C sort"
+    for (kw in keywords) {
+      printf("\"%s\",\n", kw) | sort
+    }
+    # Flush the intermediate output by closing the pipe.
+    close(sort)
+    print "}"
+    exit
+  }
+
+  print "package parser"
+  for (i = 0; i < ntokens; i++) {
+    tok = tokens[i]
+    print ""
+    printf("var lookaheadRules%s = []lookaheadRule{\n", tok)
+    printf("%s", rules[tok])
+    print "}"
+  }
+  print ""
+  print "// lookaheadRules returns the lookahead rules for the token with the given"
+  print "// id, in the order in which they must be tried."
+  print "func lookaheadRules(id int32) []lookaheadRule {"
+  print "\tswitch id {"
+  for (i = 0; i < ntokens; i++) {
+    printf("\tcase %s:\n", tokens[i])
+    printf("\t\treturn lookaheadRules%s\n", tokens[i])
+  }
+  print "\t}"
+  print "\treturn nil"
+  print "}"
+}
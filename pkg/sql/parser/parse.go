@@ -26,10 +26,14 @@ package parser
 import (
 	"fmt"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 )
 
 // Statement is the result of parsing a single statement. It contains the AST
@@ -41,8 +45,17 @@ type Statement struct {
 	// SQL is the original SQL from which the statement was parsed. Note that this
 	// is not appropriate for use in logging, as it may contain passwords and
 	// other sensitive data.
+	//
+	// SQL is a slice of the input, with the user's formatting: it spans from
+	// the first token of the statement to its last token, excluding the
+	// surrounding whitespace, comments and semicolons.
 	SQL string
 
+	// StartPos and EndPos are the byte offsets of SQL in the input of Parse:
+	// when several statements are parsed at once, input[StartPos:EndPos] is
+	// SQL.
+	StartPos, EndPos int
+
 	// NumPlaceholders indicates the number of arguments to the statement (which
 	// are referenced through placeholders). This corresponds to the highest
 	// argument position (i.e. the x in "$x") that appears in the query.
@@ -52,6 +65,51 @@ type Statement struct {
 	// NumPlaceholders is 3. These cases are malformed and will result in a
 	// type-check error.
 	NumPlaceholders int
+
+	// PlaceholderNames are the names of the placeholders of the statement, in
+	// index order, if it uses named placeholders (e.g. `:name`) rather than
+	// positional ones. The placeholders are numbered in the order of the
+	// first occurrence of their name: in `SELECT :b, :a, :b`, :b is $1 and :a
+	// is $2. See PlaceholderIdx.
+	PlaceholderNames []string
+
+	// QuestionMarkPositions are the byte offsets in SQL of the `?`
+	// placeholders of the statement, in index order, when it is parsed with
+	// ParseOptions.QuestionMarkPlaceholders: the nth `?` is rewritten to $n,
+	// and its offset is QuestionMarkPositions[n-1].
+	QuestionMarkPositions []int
+
+	// Comments contains the comments of the statement, including their
+	// delimiters, when it is parsed with ParseOptions.RetainComments. The
+	// comments that precede the statement belong to it.
+	Comments []string
+
+	// Hints are the optimizer hints specified in the /*+ ... */ comments of
+	// the statement. Like Comments, they include the hints which precede the
+	// statement.
+	Hints tree.StatementHints
+
+	// Notices are the notices raised while parsing the statement, which
+	// should be reported to the client, e.g. for the identifiers truncated
+	// because of ParseOptions.TruncateIdentifiers.
+	Notices []string
+
+	// SourceRanges are the ranges of SQL from which the nodes of the AST and
+	// their clauses were parsed, when the statement is parsed with
+	// ParseOptions.RecordSourceRanges. See SourceRange.
+	SourceRanges map[SourceRangeKey]SourceRange
+}
+
+// PlaceholderIdx returns the index of the named placeholder with the given
+// name, i.e. the Idx of the tree.Placeholder nodes it results in. ok is
+// false if the statement has no such placeholder.
+func (s Statement) PlaceholderIdx(name string) (idx types.PlaceholderIdx, ok bool) {
+	for i, n := range s.PlaceholderNames {
+		if n == name {
+			return types.PlaceholderIdx(i), true
+		}
+	}
+	return 0, false
 }
 
 // Statements is a list of parsed statements.
@@ -75,13 +133,86 @@ func (stmts Statements) StringWithFlags(flags tree.FmtFlags) string {
 }
 
 // Parser wraps a scanner, parser and other utilities present in the parser
-// package.
+// package. A Parser reuses its internal buffers across calls, so callers that
+// parse many statements (e.g. a SQL connection) should keep one around. Note
+// that the Statements returned by a Parser are only valid until the next
+// call.
 type Parser struct {
 	scanner    scanner
 	lexer      lexer
 	parserImpl sqlParserImpl
-	tokBuf     [8]sqlSymType
-	stmtBuf    [1]Statement
+	// tokBuf is the token buffer, whose capacity is preserved across
+	// statements.
+	tokBuf  []sqlSymType
+	stmtBuf [1]Statement
+	// limits are the limits enforced by scanOneStmt, and limitErr is set
+	// when the last statement it scanned exceeded one of them.
+	limits   stmtLimits
+	limitErr error
+	// recordRanges is set when the source ranges of the statements are
+	// requested, in which case tokEnds are the end positions of the tokens
+	// returned by scanOneStmt.
+	recordRanges bool
+	tokEnds      []int32
+}
+
+// stmtLimits are the limits on the statements scanned by a Parser. A zero
+// limit is not enforced. See ParseOptions.
+type stmtLimits struct {
+	maxSize         int
+	maxTokens       int
+	maxNestingDepth int
+}
+
+// sizeExceeded returns true if a statement of the given size exceeds the
+// limits.
+func (l *stmtLimits) sizeExceeded(size int) bool {
+	return l.maxSize > 0 && size > l.maxSize
+}
+
+// check returns an error if a statement with the given number of tokens and
+// nesting depth exceeds the limits.
+func (l *stmtLimits) check(numTokens, nestingDepth int) error {
+	if l.maxTokens > 0 && numTokens > l.maxTokens {
+		return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"statement exceeds the maximum of %d tokens", l.maxTokens)
+	}
+	if l.maxNestingDepth > 0 && nestingDepth > l.maxNestingDepth {
+		return pgerror.NewErrorf(pgerror.CodeStatementTooComplexError,
+			"statement exceeds the maximum nesting depth of %d", l.maxNestingDepth)
+	}
+	return nil
+}
+
+// maxPooledTokens is the capacity of the token buffer above which a Parser
+// is not returned to parserPool, so as to not hold on to the memory used
+// for a very large statement.
+const maxPooledTokens = 1024
+
+// parserPool holds the Parsers used by the package-level parsing functions.
+var parserPool = sync.Pool{
+	New: func() interface{} { return &Parser{} },
+}
+
+// getParser returns a Parser from the pool. It must be returned with
+// putParser once the results of parsing have been extracted from it.
+func getParser() *Parser {
+	return parserPool.Get().(*Parser)
+}
+
+// putParser resets the Parser and returns it to the pool.
+func putParser(p *Parser) {
+	if cap(p.tokBuf) > maxPooledTokens {
+		return
+	}
+	// Drop the references to the input string and the ASTs, which may
+	// otherwise be kept alive by the pool.
+	tokBuf := p.tokBuf[:cap(p.tokBuf)]
+	for i := range tokBuf {
+		tokBuf[i] = sqlSymType{}
+	}
+	*p = Parser{tokBuf: tokBuf[:0]}
+	parserPool.Put(p)
 }
 
 // INT8 is the historical interpretation of INT. This should be left
@@ -91,23 +222,96 @@ type Parser struct {
 var defaultNakedIntType = coltypes.Int8
 var defaultNakedSerialType = coltypes.Serial8
 
+// ParseOptions controls how ParseWithOptions parses SQL. The zero value
+// parses SQL like Parse.
+type ParseOptions struct {
+	// NakedIntType is the type which INT and INTEGER result in. It defaults
+	// to INT8.
+	NakedIntType *coltypes.TInt
+	// NakedSerialType is the type which SERIAL is normalized to. It defaults
+	// to the SERIAL type of the same width as NakedIntType.
+	NakedSerialType *coltypes.TSerial
+	// MaxStatementSize, if positive, is the maximum size in bytes of a
+	// statement. Parsing fails on a larger statement.
+	MaxStatementSize int
+	// MaxTokens, if positive, is the maximum number of tokens of a statement.
+	// Parsing fails on a statement with more tokens.
+	MaxTokens int
+	// MaxNestingDepth, if positive, is the maximum depth of the parentheses
+	// and brackets of a statement. Parsing fails on a more deeply nested
+	// statement, which could otherwise exhaust the stack of the recursive
+	// algorithms that process the resulting AST.
+	MaxNestingDepth int
+	// RetainComments, if set, populates the Comments of the statements.
+	RetainComments bool
+	// ErrorRecovery, if set, continues parsing after a statement with a
+	// syntax error. The statement is replaced by the best-effort AST computed
+	// by ParsePartial, or omitted if none could be computed. The first syntax
+	// error is returned along with the statements.
+	ErrorRecovery bool
+	// QuestionMarkPlaceholders, if set, accepts JDBC-style `?` placeholders,
+	// which are rewritten to sequential $n placeholders. The `?`, `?|` and
+	// `?&` operators must then be written `??`, `??|` and `??&`, and `??`
+	// does not request contextual help.
+	QuestionMarkPlaceholders bool
+	// TruncateIdentifiers, if set, truncates the identifiers longer than 63
+	// bytes like Postgres does with the default NAMEDATALEN, and reports each
+	// truncation in the Notices of the statement.
+	TruncateIdentifiers bool
+	// RecordSourceRanges, if set, populates the SourceRanges of the
+	// statements, so that tools rewriting SQL can replace the text of a
+	// clause while preserving the formatting of the rest of the statement.
+	RecordSourceRanges bool
+	// MySQLSyntax, if set, accepts the lexical syntax of MySQL, to ease the
+	// import of MySQL schema dumps: `backtick` quoted identifiers,
+	// "double-quoted" string literals, backslash escapes in string literals
+	// and # line comments. They result in the same AST as the standard
+	// syntax: a double-quoted identifier must then be written `ident`, and
+	// the # operator is not available.
+	MySQLSyntax bool
+}
+
+// nakedTypes returns the types that INT and SERIAL result in.
+func (o *ParseOptions) nakedTypes() (*coltypes.TInt, *coltypes.TSerial) {
+	nakedIntType, nakedSerialType := o.NakedIntType, o.NakedSerialType
+	if nakedIntType == nil {
+		nakedIntType = defaultNakedIntType
+	}
+	if nakedSerialType == nil {
+		nakedSerialType = nakedSerialTypeFor(nakedIntType)
+	}
+	return nakedIntType, nakedSerialType
+}
+
 // Parse parses the sql and returns a list of statements.
 func (p *Parser) Parse(sql string) (Statements, error) {
-	return p.parseWithDepth(1, sql, defaultNakedIntType, defaultNakedSerialType)
+	return p.parseWithDepth(1, sql, ParseOptions{})
 }
 
 // ParseWithInt parses a sql statement string and returns a list of
 // Statements. The INT token will result in the specified TInt type.
 func (p *Parser) ParseWithInt(sql string, nakedIntType *coltypes.TInt) (Statements, error) {
-	nakedSerialType := coltypes.Serial8
+	return p.parseWithDepth(1, sql, ParseOptions{NakedIntType: nakedIntType})
+}
+
+// ParseWithOptions parses the sql using the given options and returns a list
+// of statements. With ParseOptions.ErrorRecovery, statements can be returned
+// along with an error.
+func (p *Parser) ParseWithOptions(sql string, opts ParseOptions) (Statements, error) {
+	return p.parseWithDepth(1, sql, opts)
+}
+
+// nakedSerialTypeFor returns the interpretation of the SERIAL type that
+// matches the given interpretation of INT.
+func nakedSerialTypeFor(nakedIntType *coltypes.TInt) *coltypes.TSerial {
 	if nakedIntType == coltypes.Int4 {
-		nakedSerialType = coltypes.Serial4
+		return coltypes.Serial4
 	}
-	return p.parseWithDepth(1, sql, nakedIntType, nakedSerialType)
+	return coltypes.Serial8
 }
 
 func (p *Parser) parseOneWithDepth(depth int, sql string) (Statement, error) {
-	stmts, err := p.parseWithDepth(1, sql, defaultNakedIntType, defaultNakedSerialType)
+	stmts, err := p.parseWithDepth(1, sql, ParseOptions{})
 	if err != nil {
 		return Statement{}, err
 	}
@@ -117,59 +321,132 @@ func (p *Parser) parseOneWithDepth(depth int, sql string) (Statement, error) {
 	return stmts[0], nil
 }
 
-func (p *Parser) scanOneStmt() (sql string, tokens []sqlSymType, done bool) {
+// scanOneStmt scans the tokens of the next statement in the input. The
+// returned token positions are relative to the returned string, which starts
+// at byte offset startPos in the input.
+//
+// Once the statement exceeds one of p.limits, the rest of it is scanned
+// without retaining its tokens, so that their memory is not held, and
+// p.limitErr is set unless the limit exceeded is the size limit, which the
+// caller checks against the returned string.
+func (p *Parser) scanOneStmt() (sql string, startPos int32, tokens []sqlSymType, done bool) {
 	var lval sqlSymType
 	tokens = p.tokBuf[:0]
+	p.tokEnds = p.tokEnds[:0]
+	p.limitErr = nil
+	// The comments which precede the statement belong to it.
+	p.scanner.comments = nil
+	p.scanner.hints = nil
+	p.scanner.notices = nil
 
 	// Scan the first token.
 	for {
 		p.scanner.scan(&lval)
 		if lval.id == 0 {
-			return "", nil, true
+			return "", lval.pos, nil, true
 		}
 		if lval.id != ';' {
 			break
 		}
 	}
 
-	startPos := lval.pos
+	startPos = lval.pos
 	// We make the resulting token positions match the returned string.
 	lval.pos = 0
 	tokens = append(tokens, lval)
+	p.appendTokEnd(startPos)
 	for {
 		if lval.id == ERROR {
-			return p.scanner.in[startPos:], tokens, true
+			p.tokBuf = tokens[:0]
+			return p.scanner.in[startPos:], startPos, tokens, true
 		}
 		posBeforeScan := p.scanner.pos
 		p.scanner.scan(&lval)
 		if lval.id == 0 || lval.id == ';' {
-			return p.scanner.in[startPos:posBeforeScan], tokens, (lval.id == 0)
+			p.tokBuf = tokens[:0]
+			return p.scanner.in[startPos:posBeforeScan], startPos, tokens, (lval.id == 0)
 		}
 		lval.pos -= startPos
-		tokens = append(tokens, lval)
+		if p.limitErr == nil {
+			p.limitErr = p.limits.check(len(tokens)+1, len(p.scanner.brackets))
+		}
+		if p.limitErr == nil && !p.limits.sizeExceeded(p.scanner.pos-int(startPos)) {
+			tokens = append(tokens, lval)
+			p.appendTokEnd(startPos)
+		}
+	}
+}
+
+// appendTokEnd records the end position of the token that was just scanned,
+// relative to the statement starting at startPos, if the source ranges are
+// requested.
+func (p *Parser) appendTokEnd(startPos int32) {
+	if p.recordRanges {
+		p.tokEnds = append(p.tokEnds, int32(p.scanner.pos)-startPos)
 	}
 }
 
-func (p *Parser) parseWithDepth(
-	depth int, sql string, nakedIntType *coltypes.TInt, nakedSerialType *coltypes.TSerial,
-) (Statements, error) {
+func (p *Parser) parseWithDepth(depth int, sql string, opts ParseOptions) (Statements, error) {
+	nakedIntType, nakedSerialType := opts.nakedTypes()
 	stmts := Statements(p.stmtBuf[:0])
 	p.scanner.init(sql)
+	p.scanner.retainComments = opts.RetainComments
+	p.scanner.questionMarkPlaceholders = opts.QuestionMarkPlaceholders
+	p.scanner.truncateIdentifiers = opts.TruncateIdentifiers
+	p.scanner.mysqlSyntax = opts.MySQLSyntax
 	defer p.scanner.cleanup()
+	p.limits = stmtLimits{
+		maxSize:         opts.MaxStatementSize,
+		maxTokens:       opts.MaxTokens,
+		maxNestingDepth: opts.MaxNestingDepth,
+	}
+	defer func() { p.limits, p.limitErr = stmtLimits{}, nil }()
+	p.recordRanges = opts.RecordSourceRanges
+	defer func() { p.recordRanges, p.tokEnds = false, nil }()
+	var firstErr error
 	for {
-		sql, tokens, done := p.scanOneStmt()
+		sql, startPos, tokens, done := p.scanOneStmt()
+		if p.limits.sizeExceeded(len(sql)) {
+			return nil, pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+				"statement of %d bytes exceeds the maximum statement size of %d bytes",
+				len(sql), opts.MaxStatementSize)
+		}
+		if p.limitErr != nil {
+			return nil, p.limitErr
+		}
 		stmt, err := p.parse(depth+1, sql, tokens, nakedIntType, nakedSerialType)
 		if err != nil {
-			return nil, err
+			// Make the position of the error relative to the whole input.
+			if pgErr, ok := err.(*pgerror.Error); ok && pgErr.Position > 0 {
+				pgErr.Position += int32(utf8.RuneCountInString(p.scanner.in[:startPos]))
+			}
+			if !opts.ErrorRecovery {
+				return nil, err
+			}
+			if firstErr == nil {
+				firstErr = err
+			}
+			stmt = p.parsePartialStmt(sql, int(startPos), tokens, nakedIntType, nakedSerialType).Statement
 		}
 		if stmt.AST != nil {
+			stmt.StartPos, stmt.EndPos = int(startPos), int(startPos)+len(sql)
+			stmt.Comments = p.scanner.comments
+			stmt.Notices = p.scanner.notices
+			if stmt.Hints, err = parseHints(p.scanner.hints); err != nil {
+				if !opts.ErrorRecovery {
+					return nil, err
+				}
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
 			stmts = append(stmts, stmt)
 		}
 		if done {
 			break
 		}
 	}
-	return stmts, nil
+	return stmts, firstErr
 }
 
 // parse parses a statement from the given scanned tokens.
@@ -182,6 +459,12 @@ func (p *Parser) parse(
 ) (Statement, error) {
 	p.lexer.init(sql, tokens, nakedIntType, nakedSerialType)
 	defer p.lexer.cleanup()
+	// The error recovery of parsePartialStmt parses subsets of the tokens,
+	// which do not match tokEnds.
+	if p.recordRanges && len(p.tokEnds) == len(tokens) {
+		p.lexer.tokenEnds = p.tokEnds
+		p.lexer.parser = &p.parserImpl
+	}
 	if p.parserImpl.Parse(&p.lexer) != 0 {
 		if p.lexer.lastError == nil {
 			// This should never happen -- there should be an error object
@@ -199,12 +482,46 @@ func (p *Parser) parse(
 		return Statement{}, err
 	}
 	return Statement{
-		AST:             p.lexer.stmt,
-		SQL:             sql,
-		NumPlaceholders: p.lexer.numPlaceholders,
+		AST:                   p.lexer.stmt,
+		SQL:                   sql,
+		NumPlaceholders:       p.lexer.numPlaceholders,
+		PlaceholderNames:      placeholderNames(tokens),
+		QuestionMarkPositions: questionMarkPositions(tokens),
+		SourceRanges:          p.lexer.ranges,
 	}, nil
 }
 
+// placeholderNames returns the names of the named placeholders among the
+// scanned tokens of a statement, in index order, or nil if the statement
+// has no named placeholders.
+func placeholderNames(tokens []sqlSymType) []string {
+	var names []string
+	for i := range tokens {
+		t := &tokens[i]
+		if t.id != PLACEHOLDER || lex.IsDigit(int(t.str[0])) || t.str == "?" {
+			continue
+		}
+		// The indexes are assigned in the order of the first occurrences.
+		if int(t.union.val.(*tree.Placeholder).Idx) == len(names) {
+			names = append(names, t.str)
+		}
+	}
+	return names
+}
+
+// questionMarkPositions returns the offsets of the `?` placeholders among
+// the scanned tokens of a statement, or nil if the statement has no `?`
+// placeholders.
+func questionMarkPositions(tokens []sqlSymType) []int {
+	var positions []int
+	for i := range tokens {
+		if t := &tokens[i]; t.id == PLACEHOLDER && t.str == "?" {
+			positions = append(positions, int(t.pos))
+		}
+	}
+	return positions
+}
+
 // unaryNegation constructs an AST node for a negation. This attempts
 // to preserve constant NumVals and embed the negative sign inside
 // them instead of wrapping in an UnaryExpr. This in turn ensures
@@ -222,8 +539,27 @@ func unaryNegation(e tree.Expr) tree.Expr {
 
 // Parse parses a sql statement string and returns a list of Statements.
 func Parse(sql string) (Statements, error) {
-	var p Parser
-	return p.parseWithDepth(1, sql, defaultNakedIntType, defaultNakedSerialType)
+	return parseWithPooledParser(sql, ParseOptions{})
+}
+
+// ParseWithOptions is a short-hand for (*Parser).ParseWithOptions.
+func ParseWithOptions(sql string, opts ParseOptions) (Statements, error) {
+	return parseWithPooledParser(sql, opts)
+}
+
+// parseWithPooledParser parses the sql using a Parser from the pool. The
+// returned Statements are owned by the caller.
+func parseWithPooledParser(sql string, opts ParseOptions) (Statements, error) {
+	p := getParser()
+	defer putParser(p)
+	stmts, err := p.parseWithDepth(2, sql, opts)
+	if stmts == nil {
+		return nil, err
+	}
+	// The statements may be backed by p.stmtBuf.
+	res := make(Statements, len(stmts))
+	copy(res, stmts)
+	return res, err
 }
 
 // ParseOne parses a sql statement string, ensuring that it contains only a
@@ -233,7 +569,8 @@ func Parse(sql string) (Statements, error) {
 // bits of SQL from other nodes. In general, we expect that all
 // user-generated SQL has been run through the ParseWithInt() function.
 func ParseOne(sql string) (Statement, error) {
-	var p Parser
+	p := getParser()
+	defer putParser(p)
 	return p.parseOneWithDepth(1, sql)
 }
 
@@ -258,7 +595,7 @@ func ParseTableName(sql string) (*tree.TableName, error) {
 	// can only parse full statements.
 	stmt, err := ParseOne(fmt.Sprintf("ALTER TABLE %s RENAME TO x", sql))
 	if err != nil {
-		return nil, err
+		return nil, withoutPosition(err)
 	}
 	rename, ok := stmt.AST.(*tree.RenameTable)
 	if !ok {
@@ -271,7 +608,7 @@ func ParseTableName(sql string) (*tree.TableName, error) {
 func parseExprs(exprs []string) (tree.Exprs, error) {
 	stmt, err := ParseOne(fmt.Sprintf("SET ROW (%s)", strings.Join(exprs, ",")))
 	if err != nil {
-		return nil, err
+		return nil, withoutPosition(err)
 	}
 	set, ok := stmt.AST.(*tree.SetVar)
 	if !ok {
@@ -280,6 +617,16 @@ func parseExprs(exprs []string) (tree.Exprs, error) {
 	return set.Values, nil
 }
 
+// withoutPosition removes the position from a syntax error in a statement
+// synthesized around the input, since the position would not match the
+// input.
+func withoutPosition(err error) error {
+	if pgErr, ok := err.(*pgerror.Error); ok {
+		pgErr.Position = 0
+	}
+	return err
+}
+
 // ParseExprs is a short-hand for parseExprs(sql)
 func ParseExprs(sql []string) (tree.Exprs, error) {
 	if len(sql) == 0 {
@@ -126,7 +126,7 @@ func TestScanOneStmt(t *testing.T) {
 
 		var result []stmt
 		for {
-			sql, tokens, done := p.scanOneStmt()
+			sql, _, tokens, done := p.scanOneStmt()
 			if sql == "" {
 				break
 			}
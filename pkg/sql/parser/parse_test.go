@@ -22,6 +22,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	_ "github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
@@ -118,7 +119,7 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8 NULL)`},
 		{`CREATE TABLE a (b INT8 CONSTRAINT maybe NULL)`},
 		{`CREATE TABLE a (b INT8 NOT NULL)`},
-		{`CREATE TABLE a (b INT8 CONSTRAINT always NOT NULL)`},
+		{`CREATE TABLE a (b INT8 CONSTRAINT "always" NOT NULL)`},
 		{`CREATE TABLE a (b INT8 PRIMARY KEY)`},
 		{`CREATE TABLE a (b INT8 UNIQUE)`},
 		{`CREATE TABLE a (b INT8 NULL PRIMARY KEY)`},
@@ -209,6 +210,12 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a.b (b INT8)`},
 		{`CREATE TABLE IF NOT EXISTS a (b INT8)`},
 		{`CREATE TABLE a (b INT8 AS (a + b) STORED)`},
+		{`CREATE TABLE a (b INT8 AS (a + b) VIRTUAL)`},
+		{`CREATE TABLE a (b INT8 GENERATED ALWAYS AS IDENTITY)`},
+		{`CREATE TABLE a (b INT8 GENERATED BY DEFAULT AS IDENTITY)`},
+		{`CREATE TABLE a (b INT8 GENERATED ALWAYS AS IDENTITY (START 10 INCREMENT 2))`},
+		{`CREATE TABLE a (b INT8 NOT NULL GENERATED BY DEFAULT AS IDENTITY (MINVALUE 1 MAXVALUE 100 CYCLE))`},
+		{`CREATE TABLE generated (generated INT8 GENERATED ALWAYS AS IDENTITY)`},
 		{`CREATE TABLE view (view INT8)`},
 
 		{`CREATE TABLE a (b INT8 CONSTRAINT c PRIMARY KEY)`},
@@ -269,6 +276,21 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE VIEW a AS SELECT c FROM b WHERE c > 0 WITH CHECK OPTION`},
+		{`CREATE TRIGGER a BEFORE INSERT ON b FOR EACH ROW AS 'BEGIN RETURN NEW; END'`},
+		{`CREATE TRIGGER a AFTER INSERT OR UPDATE OR DELETE ON db.b FOR EACH ROW AS 'BEGIN END'`},
+		{`CREATE TRIGGER a BEFORE DELETE ON b FOR EACH STATEMENT AS 'BEGIN END'`},
+		{`CREATE FUNCTION f() RETURNS INT8 LANGUAGE sql AS 'SELECT 1'`},
+		{`CREATE OR REPLACE FUNCTION db.sc.f(a INT8, STRING) RETURNS STRING LANGUAGE sql AS 'SELECT $2 || a::STRING'`},
+		{`CREATE FUNCTION f(a DECIMAL(10,2)) RETURNS BOOL LANGUAGE plpgsql AS 'BEGIN RETURN a > 0; END'`},
+		{`CREATE PROCEDURE p() LANGUAGE sql AS 'INSERT INTO t VALUES (1)'`},
+		{`CREATE OR REPLACE PROCEDURE db.sc.p(a INT8, STRING) LANGUAGE plpgsql AS 'BEGIN UPDATE t SET v = $2 WHERE k = a; END'`},
+		{`CALL p()`},
+		{`CALL db.p(1, 'a', $1)`},
+
+		{`CREATE SCHEDULE a FOR SQL STATEMENT 'SELECT 1' RECURRING '@daily'`},
+		{`CREATE SCHEDULE IF NOT EXISTS a FOR SQL STATEMENT e'DELETE FROM t WHERE ts < now() - \'1d\'' RECURRING '*/5 * * * *'`},
+		{`CREATE SCHEDULE a FOR SQL STATEMENT 'SELECT 1' RECURRING '0 0 * * 1' EXECUTE AS b`},
 
 		{`CREATE SEQUENCE a`},
 		{`EXPLAIN CREATE SEQUENCE a`},
@@ -298,6 +320,10 @@ func TestParse(t *testing.T) {
 		{`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS THROTTLING 0.9`},
 		{`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS AS OF SYSTEM TIME '2016-01-01'`},
 		{`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS THROTTLING 0.1 AS OF SYSTEM TIME '2016-01-01'`},
+		{`CREATE STATISTICS a ON (extract('hour', ts)) FROM t`},
+		{`CREATE STATISTICS a ON (lower(b) || c) FROM d.t WITH OPTIONS THROTTLING 0.5`},
+		{`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS USING EXTREMES`},
+		{`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS THROTTLING 0.1 USING EXTREMES`},
 
 		{`DELETE FROM a`},
 		{`EXPLAIN DELETE FROM a`},
@@ -342,6 +368,13 @@ func TestParse(t *testing.T) {
 		{`DROP INDEX a.b@c CASCADE`},
 		{`DROP INDEX IF EXISTS a.b@c RESTRICT`},
 		{`DROP VIEW a`},
+		{`DROP TRIGGER a ON b`},
+		{`DROP TRIGGER IF EXISTS a ON db.b`},
+		{`DROP PROCEDURE p`},
+		{`DROP PROCEDURE IF EXISTS db.p`},
+
+		{`DROP SCHEDULE a`},
+		{`DROP SCHEDULE IF EXISTS a`},
 		{`DROP VIEW a.b`},
 		{`DROP VIEW a, b`},
 		{`DROP VIEW IF EXISTS a`},
@@ -371,6 +404,8 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN RESUME JOBS SELECT a`},
 		{`PAUSE JOBS SELECT a`},
 		{`EXPLAIN PAUSE JOBS SELECT a`},
+		{`PAUSE SCHEDULE a`},
+		{`RESUME SCHEDULE a`},
 
 		{`EXPLAIN SELECT 1`},
 		{`EXPLAIN EXPLAIN SELECT 1`},
@@ -391,6 +426,8 @@ func TestParse(t *testing.T) {
 
 		{`SHOW DATABASES`},
 		{`EXPLAIN SHOW DATABASES`},
+		{`SHOW EVENTS`},
+		{`SHOW EVENTS WITH type = 'create_table,drop_table', since = '1h'`},
 		{`SHOW SCHEMAS`},
 		{`EXPLAIN SHOW SCHEMAS`},
 		{`SHOW SCHEMAS FROM a`},
@@ -417,6 +454,7 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SHOW TABLES FROM a`},
 		{`SHOW ROLES`},
 		{`EXPLAIN SHOW ROLES`},
+		{`SHOW SCHEDULES`},
 		{`SHOW USERS`},
 		{`EXPLAIN SHOW USERS`},
 		{`SHOW JOBS`},
@@ -548,6 +586,8 @@ func TestParse(t *testing.T) {
 		{`GRANT SELECT, INSERT ON DATABASE bar TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO foo, bar, baz`},
 		{`GRANT SELECT, INSERT ON DATABASE db1, db2 TO "test-user"`},
+		{`GRANT SELECT (a, b) ON TABLE foo TO root`},
+		{`GRANT SELECT (a) ON TABLE foo, db.foo TO root, bar`},
 		{`GRANT rolea, roleb TO usera, userb`},
 		{`GRANT rolea, roleb TO usera, userb WITH ADMIN OPTION`},
 
@@ -559,6 +599,7 @@ func TestParse(t *testing.T) {
 		{`REVOKE ALL ON DATABASE foo FROM root, test`},
 		{`REVOKE SELECT, INSERT ON DATABASE bar FROM foo, bar, baz`},
 		{`REVOKE SELECT, INSERT ON DATABASE db1, db2 FROM foo, bar, baz`},
+		{`REVOKE SELECT (a, b) ON TABLE foo FROM root`},
 		{`REVOKE rolea, roleb FROM usera, userb`},
 		{`REVOKE ADMIN OPTION FOR rolea, roleb FROM usera, userb`},
 
@@ -570,6 +611,9 @@ func TestParse(t *testing.T) {
 		{`INSERT INTO a VALUES (1, 2), (3, 4)`},
 		{`INSERT INTO a VALUES (a + 1, 2 * 3)`},
 		{`INSERT INTO a(a, b) VALUES (1, 2)`},
+		{`INSERT INTO a OVERRIDING SYSTEM VALUE VALUES (1, 2)`},
+		{`INSERT INTO a(a, b) OVERRIDING SYSTEM VALUE VALUES (1, 2)`},
+		{`INSERT INTO a(a, b) OVERRIDING USER VALUE SELECT b, c FROM d`},
 		{`INSERT INTO a SELECT b, c FROM d`},
 		{`INSERT INTO a DEFAULT VALUES`},
 		{`INSERT INTO a VALUES (1) RETURNING a, b`},
@@ -745,6 +789,11 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t AS bar (bar1, bar2, bar3)`},
 		{`SELECT a FROM t WITH ORDINALITY`},
 		{`SELECT a FROM t WITH ORDINALITY AS bar`},
+		{`SELECT a FROM t TABLESAMPLE SYSTEM (10)`},
+		{`SELECT a FROM t TABLESAMPLE BERNOULLI (2.5) REPEATABLE (42)`},
+		{`SELECT a FROM t AS bar TABLESAMPLE SYSTEM ($1) REPEATABLE ($2)`},
+		{`SELECT a FROM t@idx WITH ORDINALITY AS bar TABLESAMPLE BERNOULLI (10 * 2)`},
+		{`SELECT a FROM t1 TABLESAMPLE SYSTEM (10), t2 TABLESAMPLE BERNOULLI (20)`},
 		{`SELECT a FROM (SELECT 1 FROM t)`},
 		{`SELECT a FROM (SELECT 1 FROM t) AS bar`},
 		{`SELECT a FROM (SELECT 1 FROM t) AS bar (bar1)`},
@@ -752,6 +801,9 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM (SELECT 1 FROM t) WITH ORDINALITY`},
 		{`SELECT a FROM (SELECT 1 FROM t) WITH ORDINALITY AS bar`},
 		{`SELECT a FROM ROWS FROM (a(x), b(y), c(z))`},
+		{`SELECT * FROM ab, LATERAL (SELECT * FROM kv WHERE k = a) WITH ORDINALITY AS s`},
+		{`SELECT * FROM ab JOIN LATERAL (SELECT * FROM kv WHERE k = a) ON true`},
+		{`SELECT * FROM ab, LATERAL ROWS FROM (generate_series(1, a)) AS s (x)`},
 		{`SELECT a FROM t1, t2`},
 		{`SELECT a FROM t AS t1`},
 		{`SELECT a FROM t AS t1 (c1)`},
@@ -839,6 +891,11 @@ func TestParse(t *testing.T) {
 
 		{`SELECT 1 FROM t GROUP BY a`},
 		{`SELECT 1 FROM t GROUP BY a, b`},
+		{`SELECT 1 FROM t GROUP BY ()`},
+		{`SELECT a, b, sum(c) FROM t GROUP BY ROLLUP (a, b)`},
+		{`SELECT a, b, sum(c) FROM t GROUP BY CUBE (a, (b, c))`},
+		{`SELECT a, grouping(a, b) FROM t GROUP BY GROUPING SETS ((a, b), (a), ())`},
+		{`SELECT a FROM t GROUP BY a, GROUPING SETS (b, ROLLUP (c), GROUPING SETS (d, ()))`},
 
 		{`SELECT a FROM t HAVING a = b`},
 
@@ -923,10 +980,17 @@ func TestParse(t *testing.T) {
 		{`SELECT avg(1) OVER (ORDER BY c GROUPS UNBOUNDED PRECEDING) FROM t`},
 		{`SELECT avg(1) OVER (PARTITION BY b ORDER BY c GROUPS UNBOUNDED PRECEDING) FROM t`},
 		{`SELECT avg(1) OVER (w PARTITION BY b ORDER BY c GROUPS UNBOUNDED PRECEDING) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c ROWS UNBOUNDED PRECEDING EXCLUDE CURRENT ROW) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c RANGE BETWEEN 1 PRECEDING AND 1 FOLLOWING EXCLUDE GROUP) FROM t`},
+		{`SELECT avg(1) OVER (ORDER BY c GROUPS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING EXCLUDE TIES) FROM t`},
 
 		{`SELECT avg(1) FILTER (WHERE a > b)`},
 		{`SELECT avg(1) FILTER (WHERE a > b) OVER (ORDER BY c)`},
 
+		{`SELECT percentile_disc(0.5) WITHIN GROUP (ORDER BY a) FROM t`},
+		{`SELECT percentile_cont(ARRAY[0.25, 0.75]) WITHIN GROUP (ORDER BY a DESC) FROM t`},
+		{`SELECT mode() WITHIN GROUP (ORDER BY a) FILTER (WHERE a > b) FROM t GROUP BY c`},
+
 		{`SELECT a FROM t UNION SELECT 1 FROM t`},
 		{`SELECT a FROM t UNION SELECT 1 FROM t UNION SELECT 1 FROM t`},
 		{`SELECT a FROM t UNION ALL SELECT 1 FROM t`},
@@ -935,6 +999,12 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t INTERSECT SELECT 1 FROM t`},
 		{`SELECT a FROM t INTERSECT ALL SELECT 1 FROM t`},
 
+		{`WITH t AS (SELECT 1) SELECT * FROM t`},
+		{`WITH RECURSIVE t (n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM t WHERE n < 10) SELECT n FROM t`},
+		{`WITH RECURSIVE t AS (SELECT 1), u AS (SELECT * FROM t UNION SELECT * FROM u) SELECT * FROM u`},
+		{`WITH t AS MATERIALIZED (SELECT 1) SELECT * FROM t`},
+		{`WITH t (x) AS NOT MATERIALIZED (SELECT 1), u AS (SELECT * FROM t) SELECT * FROM u`},
+
 		{`SELECT a FROM t1 JOIN t2 ON a = b`},
 		{`SELECT a FROM t1 JOIN t2 USING (a)`},
 		{`SELECT a FROM t1 INNER MERGE JOIN t2 USING (a)`},
@@ -959,6 +1029,19 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t LIMIT a`},
 		{`SELECT a FROM t OFFSET b`},
 		{`SELECT a FROM t LIMIT a OFFSET b`},
+		{`SELECT a FROM t ORDER BY a FETCH FIRST 3 ROWS WITH TIES`},
+		{`SELECT a FROM t ORDER BY a OFFSET b FETCH FIRST 3 ROWS WITH TIES`},
+		{`SELECT a FROM t FOR UPDATE`},
+		{`SELECT a FROM t FOR NO KEY UPDATE`},
+		{`SELECT a FROM t FOR SHARE`},
+		{`SELECT a FROM t FOR KEY SHARE`},
+		{`SELECT a FROM t FOR UPDATE SKIP LOCKED`},
+		{`SELECT a FROM t FOR SHARE NOWAIT`},
+		{`SELECT a FROM t, u FOR UPDATE OF t, u NOWAIT`},
+		{`SELECT a FROM t, u FOR UPDATE OF t FOR SHARE OF u SKIP LOCKED`},
+		{`SELECT a FROM t ORDER BY a LIMIT 1 FOR UPDATE SKIP LOCKED`},
+		{`WITH x AS (SELECT 1) SELECT a FROM t FOR UPDATE OF d.t`},
+		{`SELECT a FROM (SELECT a FROM t FOR UPDATE) FOR SHARE`},
 		{`SELECT DISTINCT * FROM t`},
 		{`SELECT DISTINCT a, b FROM t`},
 		{`SELECT DISTINCT ON (a, b) c FROM t`},
@@ -1040,6 +1123,8 @@ func TestParse(t *testing.T) {
 
 		{`ALTER DATABASE a RENAME TO b`},
 		{`EXPLAIN ALTER DATABASE a RENAME TO b`},
+		{`ALTER DATABASE a SET READ ONLY`},
+		{`ALTER DATABASE a SET READ WRITE`},
 
 		{`ALTER INDEX b RENAME TO b`},
 		{`EXPLAIN ALTER INDEX b RENAME TO b`},
@@ -1069,6 +1154,7 @@ func TestParse(t *testing.T) {
 		{`ALTER TABLE a ADD COLUMN b INT8 FAMILY fam_a`},
 		{`ALTER TABLE a ADD COLUMN b INT8 CREATE FAMILY`},
 		{`ALTER TABLE a ADD COLUMN b INT8 CREATE FAMILY fam_b`},
+		{`ALTER TABLE a ADD COLUMN b INT8 CREATE FAMILY generated`},
 		{`ALTER TABLE a ADD COLUMN b INT8 CREATE IF NOT EXISTS FAMILY fam_b`},
 
 		{`ALTER TABLE a DROP COLUMN b, DROP CONSTRAINT a_idx`},
@@ -1097,6 +1183,7 @@ func TestParse(t *testing.T) {
 
 		{`COPY t FROM STDIN`},
 		{`COPY t (a, b, c) FROM STDIN`},
+		{`COPY t FROM STDIN WITH max_rejected_rows = '10', rejected_rows_table = 'errors'`},
 
 		{`ALTER TABLE a SPLIT AT VALUES (1)`},
 		{`EXPLAIN ALTER TABLE a SPLIT AT VALUES (1)`},
@@ -1297,6 +1384,9 @@ func TestParse(t *testing.T) {
 				t.Errorf("expected \n%q\n, but found \n%q", d.sql, s)
 			}
 			sqlutils.VerifyStatementPrettyRoundtrip(t, d.sql)
+			if err := parser.VerifyRoundTrip(d.sql); err != nil {
+				t.Error(err)
+			}
 		})
 	}
 }
@@ -1311,10 +1401,30 @@ func TestParse2(t *testing.T) {
 	}{
 		{`CREATE DATABASE a WITH ENCODING = 'foo'`,
 			`CREATE DATABASE a ENCODING = 'foo'`},
+
+		// Dollar-quoted strings are formatted as standard string literals.
+		{`SELECT $$a'b$$`, `SELECT e'a\'b'`},
+		{`SELECT $tag$a$$b$tag$ || $x$$x$`, `SELECT 'a$$b' || ''`},
+		{`SELECT $1, $$$1$$`, `SELECT $1, '$1'`},
+		// The statement of a schedule is formatted as a string literal.
+		{`CREATE SCHEDULE a FOR SQL STATEMENT 'DELETE FROM t WHERE ts < now() - ''1d''' RECURRING '@daily'`,
+			`CREATE SCHEDULE a FOR SQL STATEMENT e'DELETE FROM t WHERE ts < now() - \'1d\'' RECURRING '@daily'`},
+		{"CREATE TRIGGER a BEFORE INSERT ON b FOR EACH ROW AS $body$\nBEGIN\n  RETURN NEW;\nEND\n$body$",
+			`CREATE TRIGGER a BEFORE INSERT ON b FOR EACH ROW AS e'\nBEGIN\n  RETURN NEW;\nEND\n'`},
+		{`create function f(x int) returns int as $$ select x + 1; $$ language SQL`,
+			`CREATE FUNCTION f(x INT8) RETURNS INT8 LANGUAGE sql AS ' select x + 1; '`},
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE 'plpgsql' AS 'BEGIN RETURN 1; END'`,
+			`CREATE FUNCTION f() RETURNS INT8 LANGUAGE plpgsql AS 'BEGIN RETURN 1; END'`},
+		{`create procedure p(x int) as $$ delete from t where k = x $$ language SQL`,
+			`CREATE PROCEDURE p(x INT8) LANGUAGE sql AS ' delete from t where k = x '`},
 		{`CREATE DATABASE a TEMPLATE = template0`,
 			`CREATE DATABASE a TEMPLATE = 'template0'`},
 		{`CREATE DATABASE a TEMPLATE = invalid`,
 			`CREATE DATABASE a TEMPLATE = 'invalid'`},
+		{`CREATE VIEW a AS SELECT c FROM b WITH LOCAL CHECK OPTION`,
+			`CREATE VIEW a AS SELECT c FROM b WITH CHECK OPTION`},
+		{`CREATE VIEW a AS SELECT c FROM b WITH CASCADED CHECK OPTION`,
+			`CREATE VIEW a AS SELECT c FROM b WITH CHECK OPTION`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b))`,
 			`CREATE TABLE a (b INT8, CONSTRAINT foo UNIQUE (b))`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) INTERLEAVE IN PARENT c (d))`,
@@ -1330,6 +1440,14 @@ func TestParse2(t *testing.T) {
 
 		{`CREATE TABLE a (b BIGSERIAL, c SMALLSERIAL, d SERIAL)`,
 			`CREATE TABLE a (b SERIAL8, c SERIAL2, d SERIAL8)`},
+		{`CREATE TABLE a (b INT GENERATED ALWAYS AS IDENTITY)`,
+			`CREATE TABLE a (b INT8 GENERATED ALWAYS AS IDENTITY)`},
+		{`ALTER TABLE a ADD COLUMN b INT CREATE FAMILY generated GENERATED ALWAYS AS IDENTITY`,
+			`ALTER TABLE a ADD COLUMN b INT8 GENERATED ALWAYS AS IDENTITY CREATE FAMILY generated`},
+		// The keywords which follow GENERATED in its lookahead rules are quoted when
+		// they are used as names.
+		{`CREATE TABLE a (b INT8 CONSTRAINT always NOT NULL)`,
+			`CREATE TABLE a (b INT8 CONSTRAINT "always" NOT NULL)`},
 		{`CREATE TABLE a (b BIGINT, c SMALLINT, d INTEGER, e INT)`,
 			`CREATE TABLE a (b INT8, c INT2, d INT8, e INT8)`},
 		{`CREATE TABLE a (b FLOAT, c FLOAT(10), d FLOAT(40), e REAL, f DOUBLE PRECISION)`,
@@ -1355,6 +1473,8 @@ func TestParse2(t *testing.T) {
 
 		{`CREATE STATISTICS a ON col1 FROM t AS OF SYSTEM TIME '2016-01-01'`,
 			`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS AS OF SYSTEM TIME '2016-01-01'`},
+		{`CREATE STATISTICS a ON col1 FROM t USING EXTREMES`,
+			`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS USING EXTREMES`},
 
 		{`SELECT TIMESTAMP WITHOUT TIME ZONE 'foo'`, `SELECT TIMESTAMP 'foo'`},
 		{`SELECT CAST('foo' AS TIMESTAMP WITHOUT TIME ZONE)`, `SELECT CAST('foo' AS TIMESTAMP)`},
@@ -1416,6 +1536,18 @@ func TestParse2(t *testing.T) {
 			`SELECT e'\n'`},
 		{"SELECT '\n\\'",
 			`SELECT e'\n\\'`},
+		{`SELECT e'\x41\101\u0041\U00000041\uD83D\uDE00'`,
+			`SELECT e'AAAA\U0001F600'`},
+		{`SELECT U&'d\0061t\+000061'`,
+			`SELECT 'data'`},
+		{`SELECT U&'d!0061t!+000061' UESCAPE '!'`,
+			`SELECT 'data'`},
+		{`SELECT U&'\00e9'`,
+			`SELECT e'\u00E9'`},
+		{`SELECT U&"d\0061t\+000061" FROM t`,
+			`SELECT data FROM t`},
+		{`SELECT U&"\0041" FROM t`,
+			`SELECT "A" FROM t`},
 		{`SELECT "a'a" FROM t`,
 			`SELECT "a'a" FROM t`},
 		// Hexadecimal literal strings are turned into regular strings.
@@ -1428,6 +1560,11 @@ func TestParse2(t *testing.T) {
 			`SELECT 1 FROM t`},
 		{`SELECT /* hello */ 1 FROM /* world */ t`,
 			`SELECT 1 FROM t`},
+		{`SELECT /* hello /* nested */ world */ 1 FROM t`,
+			`SELECT 1 FROM t`},
+		// Operators end before the start of a comment.
+		{`SELECT 4//* hello */2`, `SELECT 4 / 2`},
+		{"SELECT 5 #-- hello\n3", `SELECT 5 # 3`},
 		// Alias expressions are always output using AS.
 		{`SELECT 1 FROM t t1`,
 			`SELECT 1 FROM t AS t1`},
@@ -1494,6 +1631,33 @@ func TestParse2(t *testing.T) {
 			`SELECT a FROM t LIMIT 2 * a OFFSET b`},
 		{`SELECT a FROM t FETCH FIRST (2 * a) ROWS ONLY OFFSET b`,
 			`SELECT a FROM t LIMIT 2 * a OFFSET b`},
+		{`SELECT a FROM t ORDER BY a FETCH FIRST ROW WITH TIES`,
+			`SELECT a FROM t ORDER BY a FETCH FIRST 1 ROWS WITH TIES`},
+		{`SELECT a FROM t ORDER BY a FETCH NEXT (2 * a) ROWS WITH TIES`,
+			`SELECT a FROM t ORDER BY a FETCH FIRST (2 * a) ROWS WITH TIES`},
+		{`SELECT a FROM t ORDER BY a FETCH FIRST 3 ROWS WITH TIES OFFSET b ROWS`,
+			`SELECT a FROM t ORDER BY a OFFSET b FETCH FIRST 3 ROWS WITH TIES`},
+
+		{`SELECT a FROM t bar tablesample system(10) repeatable(1)`,
+			`SELECT a FROM t AS bar TABLESAMPLE SYSTEM (10) REPEATABLE (1)`},
+		{`SELECT a FROM [53 AS t] TABLESAMPLE Bernoulli (50)`,
+			`SELECT a FROM [53 AS t] TABLESAMPLE BERNOULLI (50)`},
+
+		// EXCLUDE NO OTHERS is the same as omitting the frame exclusion.
+		{`SELECT avg(1) OVER (ROWS CURRENT ROW EXCLUDE NO OTHERS) FROM t`,
+			`SELECT avg(1) OVER (ROWS CURRENT ROW) FROM t`},
+
+		// The locking clause can come before or after LIMIT, but is always
+		// output last.
+		{`SELECT a FROM t FOR UPDATE LIMIT 1`,
+			`SELECT a FROM t LIMIT 1 FOR UPDATE`},
+		{`SELECT a FROM t ORDER BY a FOR UPDATE OF t SKIP LOCKED OFFSET 2`,
+			`SELECT a FROM t ORDER BY a OFFSET 2 FOR UPDATE OF t SKIP LOCKED`},
+		// FOR READ ONLY does not lock any rows.
+		{`SELECT a FROM t FOR READ ONLY`,
+			`SELECT a FROM t`},
+		{`SELECT a FROM t LIMIT 1 FOR READ ONLY`,
+			`SELECT a FROM t LIMIT 1`},
 		// Double negation. See #1800.
 		{`SELECT *,-/* comment */-5`,
 			`SELECT *, 5`},
@@ -1593,6 +1757,9 @@ func TestParse2(t *testing.T) {
 			`SELECT overlay('w33333rce', 'resou', 3)`},
 		{`SELECT OVERLAY('w33333rce' PLACING 'resou' FROM 3 FOR 5)`,
 			`SELECT overlay('w33333rce', 'resou', 3, 5)`},
+		{`SELECT GROUPING (a,b)`, `SELECT grouping(a, b)`},
+		{`SELECT 1 FROM t GROUP BY rollup(a), cube(b)`,
+			`SELECT 1 FROM t GROUP BY ROLLUP (a), CUBE (b)`},
 		// Special extract syntax
 		{`SELECT EXTRACT(second from now())`,
 			`SELECT extract('second', now())`},
@@ -1630,6 +1797,8 @@ func TestParse2(t *testing.T) {
 			`SELECT a FROM ROWS FROM (generate_series(1, 32)) AS s (x)`},
 		{`SELECT a FROM generate_series(1, 32) WITH ORDINALITY AS s (x)`,
 			`SELECT a FROM ROWS FROM (generate_series(1, 32)) WITH ORDINALITY AS s (x)`},
+		{`SELECT * FROM ab, LATERAL generate_series(1, a)`,
+			`SELECT * FROM ab, LATERAL ROWS FROM (generate_series(1, a))`},
 
 		// Tuples
 		{`SELECT 1 IN (b)`, `SELECT 1 IN (b,)`},
@@ -1742,6 +1911,16 @@ func TestParse2(t *testing.T) {
 			`DROP USER IF EXISTS 'foo', 'bar'`},
 		{`ALTER USER foo WITH PASSWORD bar`,
 			`ALTER USER 'foo' WITH PASSWORD 'bar'`},
+		{`CREATE USER foo WITH PASSWORD bar VALID UNTIL '2030-01-01'`,
+			`CREATE USER 'foo' WITH PASSWORD 'bar' VALID UNTIL '2030-01-01'`},
+		{`CREATE USER IF NOT EXISTS foo VALID UNTIL $1`,
+			`CREATE USER IF NOT EXISTS 'foo' VALID UNTIL $1`},
+		{`ALTER USER foo WITH PASSWORD bar VALID UNTIL 'infinity'`,
+			`ALTER USER 'foo' WITH PASSWORD 'bar' VALID UNTIL 'infinity'`},
+		{`ALTER USER foo VALID UNTIL '2030-01-01'`,
+			`ALTER USER 'foo' VALID UNTIL '2030-01-01'`},
+		{`ALTER USER IF EXISTS foo VALID UNTIL '2030-01-01'`,
+			`ALTER USER IF EXISTS 'foo' VALID UNTIL '2030-01-01'`},
 
 		{`ALTER TABLE a RENAME b TO c`,
 			`ALTER TABLE a RENAME COLUMN b TO c`},
@@ -2106,7 +2285,34 @@ func TestParseError(t *testing.T) {
 		{`SELECT2 1`, `syntax error at or near "select2"
 SELECT2 1
 ^
-`},
+HINT: did you mean SELECT?`},
+		{`SELCT 1`, `syntax error at or near "selct"
+SELCT 1
+^
+HINT: did you mean SELECT?`},
+		{`SELECT * FORM t`, `syntax error at or near "form"
+SELECT * FORM t
+         ^
+HINT: did you mean FROM?`},
+		{`SELECT * FROM t WHERE x = 1 ORDR BY x`, `syntax error at or near "ordr"
+SELECT * FROM t WHERE x = 1 ORDR BY x
+                            ^
+HINT: did you mean ORDER?`},
+		{`DELETE FORM t`, `syntax error at or near "form"
+DELETE FORM t
+       ^
+HINT: did you mean FROM?
+try \h DELETE`},
+		{`INSERT INTO t VALUSE (1)`, `syntax error at or near "valuse"
+INSERT INTO t VALUSE (1)
+              ^
+HINT: did you mean VALUES?
+try \h INSERT`},
+		{`SELECT (1 FORM t)`, `syntax error at or near "form"
+SELECT (1 FORM t)
+          ^
+HINT: did you mean FROM?
+try \h SELECT`},
 		{`SELECT 1 FROM (t)`, `syntax error at or near ")"
 SELECT 1 FROM (t)
                 ^
@@ -2122,6 +2328,19 @@ SELECT INTERVAL 'foo'
 		{`SELECT 1 /* hello`, `lexical error: unterminated comment
 SELECT 1 /* hello
          ^
+`},
+		{`SELECT 1 /* hello /* nested */ world`, `lexical error: unterminated comment
+SELECT 1 /* hello /* nested */ world
+         ^
+`},
+		{`SELECT 1; SELECT 2 /* hello; SELECT 3`, `lexical error: unterminated comment
+SELECT 2 /* hello; SELECT 3
+         ^
+`},
+		{"SELECT 1\n/* hello\n/* nested */\nFROM t", `lexical error: unterminated comment
+SELECT 1
+/* hello
+^
 `},
 		{`SELECT '1`, `lexical error: unterminated string
 SELECT '1
@@ -2548,6 +2767,13 @@ CREATE STATISTICS a ON col1 FROM t WITH OPTIONS THROTTLING 0.1 THROTTLING 0.5
 			`syntax error: AS OF specified multiple times at or near "EOF"
 CREATE STATISTICS a ON col1 FROM t WITH OPTIONS AS OF SYSTEM TIME '-1s' THROTTLING 0.1 AS OF SYSTEM TIME '-2s'
                                                                                                               ^
+`,
+		},
+		{
+			`CREATE STATISTICS a ON col1 FROM t WITH OPTIONS USING EXTREMES USING EXTREMES`,
+			`syntax error: USING EXTREMES specified multiple times at or near "extremes"
+CREATE STATISTICS a ON col1 FROM t WITH OPTIONS USING EXTREMES USING EXTREMES
+                                                                     ^
 `,
 		},
 	}
@@ -2799,13 +3025,12 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE AGGREGATE a`, 0, `create aggregate`},
 		{`CREATE CAST a`, 0, `create cast`},
 		{`CREATE CONSTRAINT TRIGGER a`, 28296, `create constraint`},
+		{`CREATE TRIGGER a INSTEAD OF INSERT ON v FOR EACH ROW AS 'BEGIN END'`, 0, `instead of trigger`},
 		{`CREATE CONVERSION a`, 0, `create conversion`},
 		{`CREATE DEFAULT CONVERSION a`, 0, `create def conv`},
 		{`CREATE EXTENSION a`, 0, `create extension a`},
 		{`CREATE FOREIGN DATA WRAPPER a`, 0, `create fdw`},
 		{`CREATE FOREIGN TABLE a`, 0, `create foreign table`},
-		{`CREATE FUNCTION a`, 17511, `create`},
-		{`CREATE OR REPLACE FUNCTION a`, 17511, `create`},
 		{`CREATE LANGUAGE a`, 17511, `create language a`},
 		{`CREATE MATERIALIZED VIEW a`, 24747, ``},
 		{`CREATE OPERATOR a`, 0, `create operator`},
@@ -2815,7 +3040,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE SERVER a`, 0, `create server`},
 		{`CREATE SUBSCRIPTION a`, 0, `create subscription`},
 		{`CREATE TEXT SEARCH a`, 7821, `create text`},
-		{`CREATE TRIGGER a`, 28296, `create`},
 
 		{`DROP AGGREGATE a`, 0, `drop aggregate`},
 		{`DROP CAST a`, 0, `drop cast`},
@@ -2834,7 +3058,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`DROP SERVER a`, 0, `drop server`},
 		{`DROP SUBSCRIPTION a`, 0, `drop subscription`},
 		{`DROP TEXT SEARCH a`, 7821, `drop text`},
-		{`DROP TRIGGER a`, 28296, `drop`},
 		{`DROP TYPE a`, 27793, `drop type`},
 
 		{`DISCARD PLANS`, 0, `discard plans`},
@@ -2862,7 +3085,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 
 		{`CREATE TABLE a AS SELECT b WITH NO DATA`, 0, `create table as with no data`},
 
-		{`CREATE TABLE a(b INT8 AS (123) VIRTUAL)`, 0, `virtual computed columns`},
 		{`CREATE TABLE a(b INT8 REFERENCES c(x) MATCH PARTIAL`, 20305, `match partial`},
 		{`CREATE TABLE a(b INT8, FOREIGN KEY (b) REFERENCES c(x) MATCH PARTIAL)`, 20305, `match partial`},
 
@@ -2900,11 +3122,8 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`INSERT INTO foo(a, a.b) VALUES (1,2)`, 27792, ``},
 		{`INSERT INTO foo VALUES (1,2) ON CONFLICT ON CONSTRAINT a DO NOTHING`, 28161, ``},
 
-		{`SELECT * FROM ab, LATERAL (SELECT * FROM kv)`, 24560, `select`},
-		{`SELECT * FROM ab, LATERAL foo(a)`, 24560, `srf`},
 		{`SELECT max(a ORDER BY b) FROM ab`, 23620, ``},
 
-		{`SELECT * FROM a FOR UPDATE`, 6583, ``},
 		{`SELECT * FROM ROWS FROM (a(b) AS (d))`, 0, `ROWS FROM with col_def_list`},
 
 		{`SELECT 123 AT TIME ZONE 'b'`, 32005, ``},
@@ -2931,14 +3150,12 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`SELECT a(b) 'c'`, 0, `a(...) SCONST`},
 		{`SELECT (a,b) OVERLAPS (c,d)`, 0, `overlaps`},
 		{`SELECT UNIQUE (SELECT b)`, 0, `UNIQUE predicate`},
-		{`SELECT GROUPING (a,b,c)`, 0, `d_expr grouping`},
 		{`SELECT a(VARIADIC b)`, 0, `variadic`},
 		{`SELECT a(b, c, VARIADIC b)`, 0, `variadic`},
 		{`SELECT COLLATION FOR (a)`, 32563, ``},
 		{`SELECT CURRENT_TIME`, 26097, `current_time`},
 		{`SELECT CURRENT_TIME()`, 26097, `current_time`},
 		{`SELECT TREAT (a AS INT8)`, 0, `treat`},
-		{`SELECT a(b) WITHIN GROUP (ORDER BY c)`, 0, `within group`},
 
 		{`CREATE TABLE a(b BOX)`, 21286, `box`},
 		{`CREATE TABLE a(b CIDR)`, 18846, `cidr`},
@@ -2960,8 +3177,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 
 		{`INSERT INTO a VALUES (1) ON CONFLICT (x) WHERE x > 3 DO NOTHING`, 32557, ``},
 
-		{`WITH RECURSIVE a AS (TABLE b) SELECT c`, 21085, ``},
-
 		{`UPDATE foo SET (a, a.b) = (1, 2)`, 27792, ``},
 		{`UPDATE foo SET a.b = 1`, 27792, ``},
 		{`UPDATE foo SET x = y FROM a, b`, 7841, ``},
@@ -3040,6 +3255,66 @@ func TestParseSQL(t *testing.T) {
 	}
 }
 
+// TestParseStatementPositions verifies that the statements parsed from a
+// multi-statement string retain their original text and its offsets.
+func TestParseStatementPositions(t *testing.T) {
+	const in = "select  1 ;\n  -- comment\n  INSERT INTO t\n    VALUES (1, 'a;b');;SELECT\t2"
+	exp := []string{"select  1", "INSERT INTO t\n    VALUES (1, 'a;b')", "SELECT\t2"}
+	stmts, err := parser.Parse(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stmts) != len(exp) {
+		t.Fatalf("expected %d statements, found %d", len(exp), len(stmts))
+	}
+	for i, stmt := range stmts {
+		if stmt.SQL != exp[i] {
+			t.Errorf("%d: expected %q, found %q", i, exp[i], stmt.SQL)
+		}
+		if s := in[stmt.StartPos:stmt.EndPos]; s != stmt.SQL {
+			t.Errorf("%d: expected the offsets [%d, %d) to span %q, found %q",
+				i, stmt.StartPos, stmt.EndPos, stmt.SQL, s)
+		}
+	}
+}
+
+// TestParseErrorPosition verifies that syntax errors report the position of
+// the offending token in the whole input, in characters.
+func TestParseErrorPosition(t *testing.T) {
+	testData := []struct {
+		sql      string
+		position int32
+	}{
+		{`SELECT * FORM t`, 10},
+		{`SELECT 1; SELECT * FORM t`, 20},
+		{`SELECT 'é', * FORM t`, 15},
+		{`SELECT 'é'; SELECT * FORM t`, 22},
+		{`SELECT 1 +`, 11},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			_, err := parser.Parse(d.sql)
+			pgErr, ok := pgerror.GetPGCause(err)
+			if !ok {
+				t.Fatalf("expected a pgerror, found %v", err)
+			}
+			if pgErr.Code != pgerror.CodeSyntaxError {
+				t.Errorf("expected code %s, found %s", pgerror.CodeSyntaxError, pgErr.Code)
+			}
+			if pgErr.Position != d.position {
+				t.Errorf("expected position %d, found %d", d.position, pgErr.Position)
+			}
+		})
+	}
+
+	// The position is not reported for the SQL synthesized to parse
+	// expressions, since it would not match the input.
+	_, err := parser.ParseExpr(`1 +`)
+	if pgErr, ok := pgerror.GetPGCause(err); !ok || pgErr.Position != 0 {
+		t.Errorf("expected a syntax error without position, found %+v", err)
+	}
+}
+
 // TestParseNumPlaceholders verifies that Statement.NumPlaceholders is set
 // correctly.
 func TestParseNumPlaceholders(t *testing.T) {
@@ -3059,6 +3334,9 @@ func TestParseNumPlaceholders(t *testing.T) {
 
 		{in: `SELECT $1; SELECT $1`, exp: []int{1, 1}},
 		{in: `SELECT $1; SELECT $1 + $2 + $3; SELECT $1 + $2`, exp: []int{1, 3, 2}},
+
+		{in: `SELECT :a + :b + :a`, exp: []int{2}},
+		{in: `SELECT $1; SELECT :a`, exp: []int{1, 1}},
 	}
 
 	var p parser.Parser // Verify that the same parser can be reused.
@@ -3079,6 +3357,218 @@ func TestParseNumPlaceholders(t *testing.T) {
 	}
 }
 
+// TestParseNamedPlaceholders verifies that the named placeholders of a
+// statement are numbered in the order of their first occurrence.
+func TestParseNamedPlaceholders(t *testing.T) {
+	testData := []struct {
+		in    string
+		names []string
+		out   string
+	}{
+		{`SELECT 1`, nil, `SELECT 1`},
+		{`SELECT $1`, nil, `SELECT $1`},
+		{`SELECT :a`, []string{"a"}, `SELECT $1`},
+		{`SELECT :b, :a, :b, :Ab`, []string{"b", "a", "Ab"}, `SELECT $1, $2, $1, $3`},
+		{`SELECT * FROM t WHERE a = :a::INT AND b = :b_1`, []string{"a", "b_1"},
+			`SELECT * FROM t WHERE (a = $1::INT8) AND (b = $2)`},
+		{`INSERT INTO t VALUES (:x, :y) RETURNING :x`, []string{"x", "y"},
+			`INSERT INTO t VALUES ($1, $2) RETURNING $1`},
+		// Inside the brackets of an array subscript, ':' separates the bounds
+		// of a slice.
+		{`SELECT a[1:b], a[:b], a[b:], a[(:b):2]`, []string{"b"},
+			`SELECT a[1:b], a[:b], a[b:], a[($1):2]`},
+		{`SELECT ARRAY[:a, :b], a[f(:c)]`, []string{"a", "b", "c"},
+			`SELECT ARRAY[$1, $2], a[f($3)]`},
+	}
+	for _, d := range testData {
+		t.Run(d.in, func(t *testing.T) {
+			stmt, err := parser.ParseOne(d.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(stmt.PlaceholderNames, d.names) {
+				t.Errorf("expected names %v, but found %v", d.names, stmt.PlaceholderNames)
+			}
+			if d.names != nil && stmt.NumPlaceholders != len(d.names) {
+				t.Errorf("expected %d placeholders, but found %d", len(d.names), stmt.NumPlaceholders)
+			}
+			for i, name := range d.names {
+				if idx, ok := stmt.PlaceholderIdx(name); !ok || int(idx) != i {
+					t.Errorf("expected %s to have index %d, but found %d, %t", name, i, idx, ok)
+				}
+			}
+			if _, ok := stmt.PlaceholderIdx("missing"); ok {
+				t.Errorf("expected no placeholder named missing")
+			}
+			if s := stmt.AST.String(); s != d.out {
+				t.Errorf("expected %s, but found %s", d.out, s)
+			}
+		})
+	}
+
+	if _, err := parser.Parse(`SELECT $1, :a`); !testutils.IsError(err,
+		"cannot mix named and positional placeholders in a statement") {
+		t.Errorf("unexpected error %v", err)
+	}
+	if _, err := parser.Parse(`SELECT :a, $1`); !testutils.IsError(err,
+		"cannot mix named and positional placeholders in a statement") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestParseQuestionMarkPlaceholders(t *testing.T) {
+	testData := []struct {
+		in        string
+		positions []int
+		out       string
+	}{
+		{`SELECT 1`, nil, `SELECT 1`},
+		{`SELECT ?`, []int{7}, `SELECT $1`},
+		{`SELECT ?, ?,?`, []int{7, 10, 12}, `SELECT $1, $2, $3`},
+		{`INSERT INTO t VALUES (?, ?::INT) RETURNING ?`, []int{22, 25, 43},
+			`INSERT INTO t VALUES ($1, $2::INT8) RETURNING $3`},
+		// Question marks in strings, quoted identifiers and comments are not
+		// placeholders.
+		{`SELECT '?', "?" FROM t WHERE a = ? -- ?`, []int{33}, `SELECT '?', "?" FROM t WHERE a = $1`},
+		// The operators are escaped by doubling the question mark.
+		{`SELECT j ?? 'a', j ??| ARRAY['a'], j ??& ARRAY[?]`, []int{47},
+			`SELECT j ? 'a', j ?| ARRAY['a'], j ?& ARRAY[$1]`},
+	}
+	opts := parser.ParseOptions{QuestionMarkPlaceholders: true}
+	for _, d := range testData {
+		t.Run(d.in, func(t *testing.T) {
+			stmts, err := parser.ParseWithOptions(d.in, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stmts) != 1 {
+				t.Fatalf("expected 1 statement, but found %d", len(stmts))
+			}
+			stmt := stmts[0]
+			if !reflect.DeepEqual(stmt.QuestionMarkPositions, d.positions) {
+				t.Errorf("expected positions %v, but found %v", d.positions, stmt.QuestionMarkPositions)
+			}
+			if stmt.NumPlaceholders != len(d.positions) {
+				t.Errorf("expected %d placeholders, but found %d", len(d.positions), stmt.NumPlaceholders)
+			}
+			if s := stmt.AST.String(); s != d.out {
+				t.Errorf("expected %s, but found %s", d.out, s)
+			}
+		})
+	}
+
+	// The placeholders are numbered in each statement.
+	stmts, err := parser.ParseWithOptions(`SELECT ?, ?; SELECT ?`, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := stmts.String(); s != `SELECT $1, $2; SELECT $1` {
+		t.Errorf("unexpected statements %s", s)
+	}
+	if !reflect.DeepEqual(stmts[1].QuestionMarkPositions, []int{7}) {
+		t.Errorf("unexpected positions %v", stmts[1].QuestionMarkPositions)
+	}
+
+	for _, sql := range []string{`SELECT ?, $1`, `SELECT $1, ?`, `SELECT ?, :a`, `SELECT :a, ?`} {
+		if _, err := parser.ParseWithOptions(sql, opts); !testutils.IsError(err,
+			"cannot mix named and positional placeholders in a statement") {
+			t.Errorf("%s: unexpected error %v", sql, err)
+		}
+	}
+
+	// Without the option, ? is an operator.
+	if _, err := parser.Parse(`SELECT ?`); !testutils.IsError(err, "syntax error") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestParseTruncateIdentifiers(t *testing.T) {
+	a63, a64 := strings.Repeat("a", 63), strings.Repeat("a", 64)
+	// The truncation does not split multi-byte characters.
+	a62e, a62 := strings.Repeat("a", 62)+"é", strings.Repeat("a", 62)
+	testData := []struct {
+		in      string
+		out     string
+		notices []string
+	}{
+		{`SELECT ` + a63 + ` FROM t`, `SELECT ` + a63 + ` FROM t`, nil},
+		{`SELECT ` + a64 + `, "` + a64 + `" FROM t`, `SELECT ` + a63 + `, ` + a63 + ` FROM t`,
+			[]string{
+				`identifier "` + a64 + `" will be truncated to "` + a63 + `"`,
+				`identifier "` + a64 + `" will be truncated to "` + a63 + `"`,
+			}},
+		{`SELECT "` + a62e + `" FROM t`, `SELECT ` + a62 + ` FROM t`,
+			[]string{`identifier "` + a62e + `" will be truncated to "` + a62 + `"`}},
+		// String constants are not truncated.
+		{`SELECT '` + a64 + `'`, `SELECT '` + a64 + `'`, nil},
+	}
+	opts := parser.ParseOptions{TruncateIdentifiers: true}
+	for _, d := range testData {
+		t.Run(d.in, func(t *testing.T) {
+			stmts, err := parser.ParseWithOptions(d.in, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := stmts.String(); s != d.out {
+				t.Errorf("expected %s, but found %s", d.out, s)
+			}
+			if !reflect.DeepEqual(stmts[0].Notices, d.notices) {
+				t.Errorf("expected notices %q, but found %q", d.notices, stmts[0].Notices)
+			}
+		})
+	}
+
+	// Without the option, identifiers are not truncated.
+	stmts, err := parser.Parse(`SELECT ` + a64 + ` FROM t`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := stmts.String(); s != `SELECT `+a64+` FROM t` || stmts[0].Notices != nil {
+		t.Errorf("unexpected statements %s (notices %q)", s, stmts[0].Notices)
+	}
+}
+
+func TestParseMySQLSyntax(t *testing.T) {
+	testData := []struct {
+		in  string
+		out string
+	}{
+		{"SELECT `a`, `B`, `c``d` FROM `db`.`t`", `SELECT a, "B", "c` + "`" + `d" FROM db.t`},
+		{`SELECT "a", 'b', "c""d", "e'f"`, `SELECT 'a', 'b', 'c"d', e'e\'f'`},
+		{`SELECT 'a\'b', "c\"d\n"`, `SELECT e'a\'b', e'c"d\n'`},
+		{"SELECT 1 # comment\n, 2", `SELECT 1, 2`},
+		{"# comment\nCREATE TABLE `t` (`k` INT PRIMARY KEY, `v` STRING DEFAULT \"x\") # comment",
+			`CREATE TABLE t (k INT8 PRIMARY KEY, v STRING DEFAULT 'x')`},
+	}
+	opts := parser.ParseOptions{MySQLSyntax: true}
+	for _, d := range testData {
+		t.Run(d.in, func(t *testing.T) {
+			stmts, err := parser.ParseWithOptions(d.in, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := stmts.String(); s != d.out {
+				t.Errorf("expected %s, but found %s", d.out, s)
+			}
+		})
+	}
+
+	// Without the option, the MySQL syntax is rejected or has its standard
+	// meaning.
+	for _, in := range []string{"SELECT `a`", `SELECT 'a\'b'`} {
+		if _, err := parser.Parse(in); err == nil {
+			t.Errorf("%s: expected an error", in)
+		}
+	}
+	stmts, err := parser.Parse(`SELECT "a" # 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := stmts.String(); s != `SELECT a # 1` {
+		t.Errorf("unexpected statement %s", s)
+	}
+}
+
 func TestParseOne(t *testing.T) {
 	_, err := parser.ParseOne("SELECT 1; SELECT 2")
 	if !testutils.IsError(err, "expected 1 statement") {
@@ -3086,6 +3576,187 @@ func TestParseOne(t *testing.T) {
 	}
 }
 
+// TestParsePooledResults verifies that the statements returned by the
+// package-level parsing functions remain valid after the parser is reused.
+func TestParsePooledResults(t *testing.T) {
+	const sql1 = `SELECT a, b, c FROM t WHERE d = 'foo' ORDER BY e`
+	const sql2 = `INSERT INTO u VALUES (1, 2)`
+	stmts1, err := parser.Parse(sql1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmt2, err := parser.ParseOne(sql2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Parse a large statement to exercise token buffer growth and
+	// the pool size limit.
+	var buf strings.Builder
+	buf.WriteString("SELECT 1")
+	for i := 0; i < 2000; i++ {
+		buf.WriteString(", 1")
+	}
+	if _, err := parser.Parse(buf.String()); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if _, err := parser.Parse(sql2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s := stmts1.String(); s != sql1 || stmts1[0].SQL != sql1 {
+		t.Fatalf("expected %s, got %s (%s)", sql1, s, stmts1[0].SQL)
+	}
+	if s := stmt2.AST.String(); s != sql2 {
+		t.Fatalf("expected %s, got %s", sql2, s)
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	testData := []struct {
+		in       string
+		opts     parser.ParseOptions
+		expected string
+		comments [][]string
+		err      string
+	}{
+		{in: `CREATE TABLE t (a INT, b SERIAL)`,
+			expected: `CREATE TABLE t (a INT8, b SERIAL8)`},
+		{in: `CREATE TABLE t (a INT, b SERIAL)`,
+			opts:     parser.ParseOptions{NakedIntType: coltypes.Int4},
+			expected: `CREATE TABLE t (a INT4, b SERIAL4)`},
+		{in: `CREATE TABLE t (a INT, b SERIAL)`,
+			opts:     parser.ParseOptions{NakedSerialType: coltypes.Serial2},
+			expected: `CREATE TABLE t (a INT8, b SERIAL2)`},
+
+		{in: `SELECT 1; SELECT 'abcdefgh'`,
+			opts: parser.ParseOptions{MaxStatementSize: 10},
+			err:  `statement of 17 bytes exceeds the maximum statement size of 10 bytes`},
+		{in: `SELECT 1; SELECT 2`,
+			opts:     parser.ParseOptions{MaxStatementSize: 10},
+			expected: `SELECT 1; SELECT 2`},
+
+		{in: `SELECT 1, 2; SELECT 1, 2, 3`,
+			opts: parser.ParseOptions{MaxTokens: 4},
+			err:  `statement exceeds the maximum of 4 tokens`},
+		{in: `SELECT 1, 2; SELECT 3`,
+			opts:     parser.ParseOptions{MaxTokens: 4},
+			expected: `SELECT 1, 2; SELECT 3`},
+		{in: `SELECT ((1)); SELECT (((1)))`,
+			opts: parser.ParseOptions{MaxNestingDepth: 2},
+			err:  `statement exceeds the maximum nesting depth of 2`},
+		{in: `SELECT ARRAY[(1)]; SELECT ARRAY[ARRAY[(1)]]`,
+			opts: parser.ParseOptions{MaxNestingDepth: 2},
+			err:  `statement exceeds the maximum nesting depth of 2`},
+		{in: `SELECT ((1)); SELECT (1), (2)`,
+			opts:     parser.ParseOptions{MaxNestingDepth: 2},
+			expected: `SELECT ((1)); SELECT (1), (2)`},
+
+		{in: "/* a */ SELECT 1 -- b\n; -- c\nSELECT /* d /* e */ */ 2",
+			opts:     parser.ParseOptions{RetainComments: true},
+			expected: `SELECT 1; SELECT 2`,
+			comments: [][]string{{`/* a */`, `-- b`}, {`-- c`, `/* d /* e */ */`}}},
+		{in: `SELECT 1 -- b`,
+			expected: `SELECT 1`,
+			comments: [][]string{nil}},
+
+		{in: `SELECT 1; SELECT a, , b FROM t; SELECT 3`,
+			opts:     parser.ParseOptions{ErrorRecovery: true},
+			expected: `SELECT 1; SELECT a, b FROM t; SELECT 3`,
+			err:      `syntax error at or near ","`},
+		{in: `SELECT 1; FOO BAR; SELECT 3`,
+			opts:     parser.ParseOptions{ErrorRecovery: true},
+			expected: `SELECT 1; SELECT 3`,
+			err:      `syntax error at or near "foo"`},
+		{in: `SELECT 1; SELECT a, , b FROM t; SELECT 3`,
+			err: `syntax error at or near ","`},
+	}
+	var p parser.Parser
+	for _, d := range testData {
+		t.Run(d.in, func(t *testing.T) {
+			for _, parse := range []func(string, parser.ParseOptions) (parser.Statements, error){
+				parser.ParseWithOptions, p.ParseWithOptions,
+			} {
+				stmts, err := parse(d.in, d.opts)
+				if d.err == "" && err != nil {
+					t.Fatal(err)
+				}
+				if d.err != "" && !testutils.IsError(err, regexp.QuoteMeta(d.err)) {
+					t.Fatalf("expected error %q, got %v", d.err, err)
+				}
+				if s := stmts.String(); s != d.expected {
+					t.Errorf("expected %s, got %s", d.expected, s)
+				}
+				if d.comments != nil {
+					var comments [][]string
+					for _, stmt := range stmts {
+						comments = append(comments, stmt.Comments)
+					}
+					if !reflect.DeepEqual(comments, d.comments) {
+						t.Errorf("expected comments %q, got %q", d.comments, comments)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestParseHints(t *testing.T) {
+	testData := []struct {
+		in string
+		// expected are the formatted hints of each statement.
+		expected []string
+		err      string
+	}{
+		{`SELECT /*+ INDEX(t t_a_idx) */ * FROM t`, []string{`/*+ INDEX(t t_a_idx) */`}, ``},
+		{`SELECT /*+ index(T "Idx") hash_join(a, b c) */ * FROM a, b, c`,
+			[]string{`/*+ INDEX(t "Idx") HASH_JOIN(a b c) */`}, ``},
+		{`SELECT /*+ LOOKUP_JOIN(a b) */ * FROM a JOIN b USING (k) /*+ ROWS(a 10) ROWS(a b 1000) */`,
+			[]string{`/*+ LOOKUP_JOIN(a b) ROWS(a 10) ROWS(a b 1000) */`}, ``},
+		// The hints preceding a statement belong to it.
+		{`/*+ MERGE_JOIN(a b) */ SELECT 1; SELECT /*+ INDEX(t primary) */ 2; SELECT 3`,
+			[]string{`/*+ MERGE_JOIN(a b) */`, `/*+ INDEX(t primary) */`, ``}, ``},
+		// Other comments are not hints.
+		{`SELECT /* INDEX(t foo) */ /*+*/ 1 -- +`, []string{``}, ``},
+		// Hints nested in other comments are ignored.
+		{`SELECT /* /*+ INDEX(t foo) */ */ 1`, []string{``}, ``},
+
+		{`SELECT /*+ INDEX(t) */ 1`, nil, `INDEX hint expects a table and an index`},
+		{`SELECT /*+ HASH_JOIN(a) */ 1`, nil, `HASH_JOIN hint expects at least two tables`},
+		{`SELECT /*+ ROWS(a) */ 1`, nil, `ROWS hint expects at least one table and a row count`},
+		{`SELECT /*+ ROWS(a 10 b) */ 1`, nil, `unexpected "b" in hint`},
+		{`SELECT /*+ INDEX(t 1) */ 1`, nil, `unexpected "1" in hint`},
+		{`SELECT /*+ INDEX(t foo */ 1`, nil, `unexpected end of hint comment`},
+		{`SELECT /*+ FULL_SCAN(t) */ 1`, nil, `unknown hint full_scan`},
+		{`SELECT /*+ INDEX(t 'foo') */ 1`, nil, `unexpected "foo" in hint`},
+	}
+	for _, d := range testData {
+		t.Run(d.in, func(t *testing.T) {
+			stmts, err := parser.Parse(d.in)
+			if d.err != "" {
+				if !testutils.IsError(err, regexp.QuoteMeta(d.err)) {
+					t.Fatalf("expected error %q, got %v", d.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var hints []string
+			for _, stmt := range stmts {
+				var s string
+				if stmt.Hints != nil {
+					s = tree.AsString(&stmt.Hints)
+				}
+				hints = append(hints, s)
+			}
+			if !reflect.DeepEqual(hints, d.expected) {
+				t.Errorf("expected %q, got %q", d.expected, hints)
+			}
+		})
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	testCases := []struct {
 		name, query string
@@ -3113,11 +3784,29 @@ func BenchmarkParse(b *testing.B) {
 	}
 	for _, tc := range testCases {
 		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
 			for i := 0; i < b.N; i++ {
 				if _, err := parser.Parse(tc.query); err != nil {
 					b.Fatal(err)
 				}
 			}
 		})
+		b.Run(tc.name+"/reused", func(b *testing.B) {
+			b.ReportAllocs()
+			var p parser.Parser
+			for i := 0; i < b.N; i++ {
+				if _, err := p.Parse(tc.query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(tc.name+"/cached", func(b *testing.B) {
+			c := parser.NewCache(10)
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Parse(tc.query); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
 	}
 }
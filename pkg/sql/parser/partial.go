@@ -0,0 +1,174 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import "github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+
+// ErrorHole describes a region of the input that had to be skipped in order
+// to produce an AST for a statement containing a syntax error.
+type ErrorHole struct {
+	// Start and End are the byte offsets of the region in the string passed
+	// to ParsePartial. End is exclusive.
+	Start, End int
+	// Err is the syntax error reported for the statement.
+	Err error
+}
+
+// PartialStatement is the result of parsing a single statement in
+// error-tolerant mode.
+type PartialStatement struct {
+	Statement
+
+	// Holes lists the regions of the statement which were skipped to produce
+	// the AST. It is empty if the statement parsed without errors. If Holes is
+	// not empty, AST is a best-effort approximation of what the user intended
+	// and is nil if no part of the statement could be parsed.
+	Holes []ErrorHole
+}
+
+// HasErrors returns true if the statement did not parse cleanly.
+func (s *PartialStatement) HasErrors() bool {
+	return len(s.Holes) > 0
+}
+
+// maxPartialPrefixAttempts bounds the number of prefixes of a statement that
+// are re-parsed when looking for the longest valid one, so that the cost of
+// error recovery remains proportional to the size of the input.
+const maxPartialPrefixAttempts = 32
+
+// ParsePartial parses the sql like Parse, but does not stop at the first
+// syntax error. For every statement that fails to parse, it attempts to
+// recover a best-effort AST, first by skipping the token where the error was
+// detected, and then by parsing the longest valid prefix of the statement.
+// The skipped regions are reported as error holes. This is intended for
+// editor integrations, which need to provide assistance on statements that
+// are still being typed.
+func (p *Parser) ParsePartial(sql string) []PartialStatement {
+	var res []PartialStatement
+	p.scanner.init(sql)
+	defer p.scanner.cleanup()
+	for {
+		stmtSQL, startPos, tokens, done := p.scanOneStmt()
+		if stmtSQL == "" {
+			break
+		}
+		res = append(res, p.parsePartialStmt(
+			stmtSQL, int(startPos), tokens, defaultNakedIntType, defaultNakedSerialType,
+		))
+		if done {
+			break
+		}
+	}
+	return res
+}
+
+// ParsePartial is a short-hand for (*Parser).ParsePartial.
+func ParsePartial(sql string) []PartialStatement {
+	p := getParser()
+	defer putParser(p)
+	return p.ParsePartial(sql)
+}
+
+// parsePartialStmt parses a single statement in error-tolerant mode.
+// startPos is the offset of the statement in the original input.
+func (p *Parser) parsePartialStmt(
+	sql string,
+	startPos int,
+	tokens []sqlSymType,
+	nakedIntType *coltypes.TInt,
+	nakedSerialType *coltypes.TSerial,
+) PartialStatement {
+	stmt, err := p.parse(1, sql, tokens, nakedIntType, nakedSerialType)
+	if err == nil {
+		return PartialStatement{Statement: stmt}
+	}
+
+	// tokenEnd returns the end offset of the i-th token, which we approximate
+	// by the start of the next one.
+	tokenEnd := func(i int) int {
+		if i+1 < len(tokens) {
+			return int(tokens[i+1].pos)
+		}
+		return len(sql)
+	}
+	res := PartialStatement{Statement: Statement{SQL: sql}}
+
+	// The lexer remembers which token it returned last; this is the token at
+	// which the error was detected. If it is past the end, the statement is
+	// incomplete.
+	errIdx := p.lexer.lastPos
+	if errIdx < 0 {
+		errIdx = 0
+	}
+	if errIdx >= len(tokens) {
+		errIdx = len(tokens)
+	}
+	lexicalError := errIdx < len(tokens) && tokens[errIdx].id == ERROR
+
+	// First attempt: skip the offending token. This repairs stray tokens
+	// such as a duplicated comma or a misplaced keyword in the middle of an
+	// otherwise valid statement.
+	if errIdx < len(tokens) && !lexicalError {
+		repaired := make([]sqlSymType, 0, len(tokens)-1)
+		repaired = append(repaired, tokens[:errIdx]...)
+		repaired = append(repaired, tokens[errIdx+1:]...)
+		if s, rerr := p.parse(
+			1, sql, repaired, nakedIntType, nakedSerialType,
+		); rerr == nil && s.AST != nil {
+			res.AST = s.AST
+			res.NumPlaceholders = s.NumPlaceholders
+			res.Holes = []ErrorHole{{
+				Start: startPos + int(tokens[errIdx].pos),
+				End:   startPos + tokenEnd(errIdx),
+				Err:   err,
+			}}
+			return res
+		}
+	}
+
+	// Second attempt: find the longest prefix that forms a valid statement.
+	// Everything after it is reported as a single hole.
+	holeStart := len(sql)
+	if errIdx < len(tokens) {
+		holeStart = int(tokens[errIdx].pos)
+	}
+	k := errIdx
+	if k == len(tokens) {
+		// The full statement is already known to be invalid.
+		k--
+	}
+	for attempts := 0; k > 0 && attempts < maxPartialPrefixAttempts; k, attempts = k-1, attempts+1 {
+		s, perr := p.parse(1, sql, tokens[:k], nakedIntType, nakedSerialType)
+		if perr == nil && s.AST != nil {
+			res.AST = s.AST
+			res.NumPlaceholders = s.NumPlaceholders
+			if k < len(tokens) {
+				holeStart = int(tokens[k].pos)
+			}
+			break
+		}
+	}
+	if res.AST == nil {
+		// Nothing could be salvaged; the whole statement is a hole.
+		holeStart = 0
+	}
+	holeEnd := len(sql)
+	res.Holes = []ErrorHole{{
+		Start: startPos + holeStart,
+		End:   startPos + holeEnd,
+		Err:   err,
+	}}
+	return res
+}
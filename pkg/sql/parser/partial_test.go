@@ -0,0 +1,84 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParsePartial(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	type hole struct{ start, end int }
+	type result struct {
+		ast   string
+		holes []hole
+	}
+	testData := []struct {
+		sql      string
+		expected []result
+	}{
+		{`SELECT 1; SELECT 2`, []result{{ast: `SELECT 1`}, {ast: `SELECT 2`}}},
+		// A stray token is skipped.
+		{`SELECT a, , b FROM t`, []result{{ast: `SELECT a, b FROM t`, holes: []hole{{10, 12}}}}},
+		// An incomplete statement is truncated to its longest valid prefix.
+		{`SELECT * FROM`, []result{{ast: `SELECT *`, holes: []hole{{9, 13}}}}},
+		// Hole positions are relative to the whole input.
+		{`SELECT 1; SELECT * FROM`, []result{
+			{ast: `SELECT 1`},
+			{ast: `SELECT *`, holes: []hole{{19, 23}}},
+		}},
+		// Lexical errors are recovered from as well.
+		{`SELECT 1 + 0x`, []result{{ast: `SELECT 1`, holes: []hole{{9, 13}}}}},
+		// Nothing can be salvaged.
+		{`FOO BAR`, []result{{ast: ``, holes: []hole{{0, 7}}}}},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			stmts := parser.ParsePartial(d.sql)
+			if len(stmts) != len(d.expected) {
+				t.Fatalf("expected %d statements, got %d", len(d.expected), len(stmts))
+			}
+			for i, stmt := range stmts {
+				exp := d.expected[i]
+				ast := ""
+				if stmt.AST != nil {
+					ast = stmt.AST.String()
+				}
+				if ast != exp.ast {
+					t.Errorf("%d: expected AST %q, got %q", i, exp.ast, ast)
+				}
+				if stmt.HasErrors() != (len(exp.holes) > 0) {
+					t.Errorf("%d: expected errors: %t, got %t", i, len(exp.holes) > 0, stmt.HasErrors())
+				}
+				if len(stmt.Holes) != len(exp.holes) {
+					t.Fatalf("%d: expected %d holes, got %+v", i, len(exp.holes), stmt.Holes)
+				}
+				for j, h := range stmt.Holes {
+					if h.Start != exp.holes[j].start || h.End != exp.holes[j].end {
+						t.Errorf("%d: expected hole [%d, %d), got [%d, %d)",
+							i, exp.holes[j].start, exp.holes[j].end, h.Start, h.End)
+					}
+					if h.Err == nil {
+						t.Errorf("%d: expected an error for hole %d", i, j)
+					}
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+)
+
+// PlaceholderInfo describes a placeholder of a statement.
+type PlaceholderInfo struct {
+	// Idx is the index of the placeholder: $1 has index 0.
+	Idx types.PlaceholderIdx
+	// Name is the name of the placeholder if the statement uses named
+	// placeholders, e.g. a for :a. It is empty for $1.
+	Name string
+	// Positions are the byte offsets of the occurrences of the placeholder in
+	// the statement's SQL. It is empty if the placeholder is never used, for
+	// example $1 in `SELECT $2`.
+	Positions []int
+	// Type is the type of the placeholder, if it can be determined from the
+	// statement alone, or nil otherwise.
+	Type types.T
+}
+
+// Placeholders returns a description of each of the NumPlaceholders
+// placeholders of a statement, in index order. This is meant for clients that
+// need to describe a statement without a round trip to the server.
+//
+// The type of a placeholder is determined, with the same rules as the server,
+// from its type annotations (e.g. `$1:::INT`) or from casts if all its
+// occurrences are cast to the same type (e.g. `$1::INT`). A placeholder used as
+// the LIMIT or OFFSET of the statement is an INT. The types of other
+// placeholders depend on the schema and are left unknown. An error is returned
+// if the type annotations of a placeholder conflict.
+func Placeholders(stmt Statement) ([]PlaceholderInfo, error) {
+	res := make([]PlaceholderInfo, stmt.NumPlaceholders)
+	for i := range res {
+		res[i].Idx = types.PlaceholderIdx(i)
+		if i < len(stmt.PlaceholderNames) {
+			res[i].Name = stmt.PlaceholderNames[i]
+		}
+	}
+
+	s := makeScanner(stmt.SQL)
+	var lval sqlSymType
+	for {
+		s.scan(&lval)
+		if lval.id == 0 || lval.id == ERROR {
+			break
+		}
+		if lval.id != PLACEHOLDER {
+			continue
+		}
+		p := lval.union.val.(*tree.Placeholder)
+		if int(p.Idx) < len(res) {
+			res[p.Idx].Positions = append(res[p.Idx].Positions, int(lval.pos))
+		}
+	}
+
+	typeHints := make(tree.PlaceholderTypes, stmt.NumPlaceholders)
+	if sel, ok := stmt.AST.(*tree.Select); ok && sel.Limit != nil {
+		for _, e := range []tree.Expr{sel.Limit.Count, sel.Limit.Offset} {
+			if p, ok := e.(*tree.Placeholder); ok && int(p.Idx) < len(typeHints) {
+				typeHints[p.Idx] = types.Int
+			}
+		}
+	}
+	if err := tree.ProcessPlaceholderAnnotations(stmt.AST, typeHints); err != nil {
+		return nil, err
+	}
+	for i := range res {
+		res[i].Type = typeHints[i]
+	}
+	return res, nil
+}
+
+// PlaceholdersSQL parses the given SQL, which must contain a single
+// statement, and describes its placeholders. See Placeholders.
+func PlaceholdersSQL(sql string) ([]PlaceholderInfo, error) {
+	stmt, err := ParseOne(sql)
+	if err != nil {
+		return nil, err
+	}
+	return Placeholders(stmt)
+}
+
+// ParseExprWithTypes parses a scalar expression and infers the types of its
+// placeholders, e.g. for the validation of computed column and CHECK
+// constraint expressions outside of a statement, or for the client-side
+// evaluation of expressions. The types passed for the placeholders, in index
+// order, are used as hints like the parameter types of PREPARE; nil leaves
+// the type of a placeholder to be inferred.
+//
+// The returned types have an entry per placeholder, nil if the type could not
+// be inferred. If the expression does not reference any column or subquery,
+// it is type checked and the types are those that the server would infer;
+// for example, $1 is an INT in `$1 + 1`. An error is returned if it does not
+// type check; note that the function calls can only be resolved when the
+// builtins package is linked in. Otherwise, the types are only inferred from
+// the hints, type annotations and casts, as with Placeholders.
+func ParseExprWithTypes(
+	sql string, placeholderTypes ...types.T,
+) (tree.Expr, tree.PlaceholderTypes, error) {
+	stmt, err := ParseOne(fmt.Sprintf("SET ROW (%s)", sql))
+	if err != nil {
+		return nil, nil, err
+	}
+	set, ok := stmt.AST.(*tree.SetVar)
+	if !ok {
+		return nil, nil, pgerror.NewAssertionErrorf("expected a SET statement, but found %T", stmt.AST)
+	}
+	if len(set.Values) != 1 {
+		return nil, nil, pgerror.NewAssertionErrorf("expected 1 expression, found %d", len(set.Values))
+	}
+	expr := set.Values[0]
+
+	// Like PREPARE, accept types for more placeholders than the expression
+	// uses.
+	n := stmt.NumPlaceholders
+	if len(placeholderTypes) > n {
+		n = len(placeholderTypes)
+	}
+	typeHints := make(tree.PlaceholderTypes, n)
+	copy(typeHints, placeholderTypes)
+	if err := tree.ProcessPlaceholderAnnotations(stmt.AST, typeHints); err != nil {
+		return nil, nil, err
+	}
+	if !isContextFree(expr) {
+		return expr, typeHints, nil
+	}
+
+	semaCtx := tree.MakeSemaContext()
+	if err := semaCtx.Placeholders.Init(n, typeHints); err != nil {
+		return nil, nil, err
+	}
+	if _, err := tree.TypeCheck(expr, &semaCtx, types.Any); err != nil {
+		return nil, nil, err
+	}
+	res := make(tree.PlaceholderTypes, n)
+	for i := range res {
+		res[i], _ = semaCtx.Placeholders.Type(types.PlaceholderIdx(i))
+	}
+	return expr, res, nil
+}
+
+// isContextFree returns true if the expression can be type checked without
+// the context of a statement: it does not reference any column or subquery.
+func isContextFree(expr tree.Expr) bool {
+	v := contextFreeVisitor{contextFree: true}
+	tree.WalkExprConst(&v, expr)
+	return v.contextFree
+}
+
+type contextFreeVisitor struct {
+	contextFree bool
+}
+
+var _ tree.Visitor = &contextFreeVisitor{}
+
+func (v *contextFreeVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch expr.(type) {
+	case tree.VarName, *tree.IndexedVar, *tree.Subquery:
+		v.contextFree = false
+		return false, expr
+	}
+	return v.contextFree, expr
+}
+
+func (*contextFreeVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
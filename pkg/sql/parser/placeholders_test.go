@@ -0,0 +1,140 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestPlaceholders(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		expected []parser.PlaceholderInfo
+	}{
+		{`SELECT 1`, []parser.PlaceholderInfo{}},
+		{`SELECT $1::INT + $2, $2`, []parser.PlaceholderInfo{
+			{Idx: 0, Positions: []int{7}, Type: types.Int},
+			{Idx: 1, Positions: []int{17, 21}},
+		}},
+		{`SELECT * FROM t WHERE a = $1:::STRING AND b = $1 LIMIT $2 OFFSET $3`, []parser.PlaceholderInfo{
+			{Idx: 0, Positions: []int{26, 46}, Type: types.String},
+			{Idx: 1, Positions: []int{55}, Type: types.Int},
+			{Idx: 2, Positions: []int{65}, Type: types.Int},
+		}},
+		{`SELECT $1::INT, $1::STRING`, []parser.PlaceholderInfo{
+			{Idx: 0, Positions: []int{7, 16}},
+		}},
+		{`SELECT $2::DECIMAL`, []parser.PlaceholderInfo{
+			{Idx: 0},
+			{Idx: 1, Positions: []int{7}, Type: types.Decimal},
+		}},
+		{`INSERT INTO t VALUES ($1, $2::BYTES)`, []parser.PlaceholderInfo{
+			{Idx: 0, Positions: []int{22}},
+			{Idx: 1, Positions: []int{26}, Type: types.Bytes},
+		}},
+		{`SELECT * FROM t WHERE b = :b:::STRING AND a = :a AND c = :b LIMIT :lim`, []parser.PlaceholderInfo{
+			{Idx: 0, Name: "b", Positions: []int{26, 57}, Type: types.String},
+			{Idx: 1, Name: "a", Positions: []int{46}},
+			{Idx: 2, Name: "lim", Positions: []int{66}, Type: types.Int},
+		}},
+		{`SELECT :b::STRING, :b::STRING`, []parser.PlaceholderInfo{
+			{Idx: 0, Name: "b", Positions: []int{7, 19}, Type: types.String},
+		}},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			res, err := parser.PlaceholdersSQL(d.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, d.expected) {
+				t.Errorf("expected %+v, got %+v", d.expected, res)
+			}
+		})
+	}
+}
+
+func TestPlaceholdersError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	_, err := parser.PlaceholdersSQL(`SELECT $1:::INT, $1:::STRING`)
+	if !testutils.IsError(err, "multiple conflicting type annotations") {
+		t.Fatalf("expected conflicting annotations error, got %v", err)
+	}
+}
+
+func TestParseExprWithTypes(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		hints    []types.T
+		expected tree.PlaceholderTypes
+	}{
+		{`1 + 1`, nil, tree.PlaceholderTypes{}},
+		{`$1 + 1`, nil, tree.PlaceholderTypes{types.Int}},
+		{`$1 + 1`, []types.T{types.Decimal}, tree.PlaceholderTypes{types.Decimal}},
+		{`(lower($1) = 'a') OR $2`, nil, tree.PlaceholderTypes{types.String, types.Bool}},
+		{`$2::INT8`, nil, tree.PlaceholderTypes{nil, types.Int}},
+		{`1`, []types.T{types.Int}, tree.PlaceholderTypes{types.Int}},
+		// The expressions which reference columns are not type checked.
+		{`(a > $1::DECIMAL) AND (b = $2)`, nil, tree.PlaceholderTypes{types.Decimal, nil}},
+		{`a = $1`, []types.T{types.Bytes}, tree.PlaceholderTypes{types.Bytes}},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			expr, res, err := parser.ParseExprWithTypes(d.sql, d.hints...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := expr.String(); s != d.sql {
+				t.Errorf("expected expression %s, got %s", d.sql, s)
+			}
+			if !reflect.DeepEqual(res, d.expected) {
+				t.Errorf("expected %v, got %v", d.expected, res)
+			}
+		})
+	}
+}
+
+func TestParseExprWithTypesError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{`1 +`, `syntax error`},
+		{`SELECT 1`, `syntax error`},
+		{`$1:::INT + $1:::STRING`, `multiple conflicting type annotations`},
+		{`$1 = $2`, `could not determine data type of placeholder`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			if _, _, err := parser.ParseExprWithTypes(d.sql); !testutils.IsError(err, d.expected) {
+				t.Fatalf("expected %q, got %v", d.expected, err)
+			}
+		})
+	}
+}
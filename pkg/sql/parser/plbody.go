@@ -0,0 +1,258 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// This file contains the parts of the parser of procedural bodies (i.e. the
+// bodies of triggers and routines) which are shared between the different
+// kinds of bodies.
+
+// plToken is a token in a procedural body. start and end are the offsets of
+// the token in the body.
+type plToken struct {
+	id         int32
+	str        string
+	start, end int
+}
+
+// isWord returns true if the token is the given (lowercase) keyword or
+// identifier.
+func (t *plToken) isWord(word string) bool {
+	return t.str == word && (t.id == IDENT || t.id == lex.GetKeywordID(word))
+}
+
+// isName returns true if the token can be used as the name of a column or
+// variable.
+func (t *plToken) isName() bool {
+	return t.id == IDENT || (t.id == lex.GetKeywordID(t.str) && lex.KeywordsCategories[t.str] == "U")
+}
+
+type plBodyParser struct {
+	sql string
+	// kind is the kind of body being parsed (e.g. "trigger"), for use in
+	// error messages.
+	kind   string
+	tokens []plToken
+	pos    int
+}
+
+// plBodyError is used to propagate errors through the recursive descent
+// functions of plBodyParser.
+type plBodyError struct {
+	err error
+}
+
+// recoverPLBodyError is deferred by the entry points of the parsers of
+// procedural bodies to return the errors raised by their recursive descent
+// functions.
+func recoverPLBodyError(err *error) {
+	if r := recover(); r != nil {
+		bodyErr, ok := r.(plBodyError)
+		if !ok {
+			panic(r)
+		}
+		*err = bodyErr.err
+	}
+}
+
+func (p *plBodyParser) scan() error {
+	s := makeScanner(p.sql)
+	for {
+		var lval sqlSymType
+		s.scan(&lval)
+		if lval.id == ERROR {
+			return pgerror.NewErrorf(pgerror.CodeSyntaxError, "lexical error: %s", lval.str)
+		}
+		p.tokens = append(p.tokens, plToken{
+			id: lval.id, str: lval.str, start: int(lval.pos), end: s.pos,
+		})
+		if lval.id == 0 {
+			return nil
+		}
+	}
+}
+
+func (p *plBodyParser) peek() *plToken {
+	return &p.tokens[p.pos]
+}
+
+func (p *plBodyParser) expectWord(word string) {
+	if !p.peek().isWord(word) {
+		p.syntaxError()
+	}
+	p.pos++
+}
+
+func (p *plBodyParser) expect(id int32) *plToken {
+	t := p.peek()
+	if t.id != id {
+		p.syntaxError()
+	}
+	p.pos++
+	return t
+}
+
+// expectEnd checks that the body ends after the current position, allowing a
+// final semicolon.
+func (p *plBodyParser) expectEnd() {
+	if p.peek().id == ';' {
+		p.pos++
+	}
+	if p.peek().id != 0 {
+		p.syntaxError()
+	}
+}
+
+// expectAssign accepts both := and = as the assignment operator.
+func (p *plBodyParser) expectAssign() {
+	if p.peek().id == ':' {
+		p.pos++
+	}
+	p.expect('=')
+}
+
+// atAssign returns true if the current token is followed by an assignment
+// operator.
+func (p *plBodyParser) atAssign() bool {
+	next := p.tokens[p.pos+1].id
+	return p.peek().isName() && (next == ':' || next == '=')
+}
+
+func (p *plBodyParser) parseName() tree.Name {
+	t := p.peek()
+	if !t.isName() {
+		p.syntaxError()
+	}
+	p.pos++
+	return tree.Name(t.str)
+}
+
+func (p *plBodyParser) syntaxError() {
+	t := p.peek()
+	near := t.str
+	if t.id != 0 {
+		near = p.sql[t.start:t.end]
+	}
+	panic(plBodyError{pgerror.NewErrorf(pgerror.CodeSyntaxError,
+		"syntax error in %s body at or near %q", p.kind, near)})
+}
+
+// parseRaiseArgs parses a RAISE EXCEPTION statement, returning its message
+// and arguments.
+func (p *plBodyParser) parseRaiseArgs() (string, tree.Exprs) {
+	p.expectWord("raise")
+	if p.peek().isWord("exception") {
+		p.pos++
+	}
+	msg := p.expect(SCONST).str
+	var args tree.Exprs
+	for p.peek().id == ',' {
+		p.pos++
+		args = append(args, p.parseExpr())
+	}
+	if n := strings.Count(msg, "%") - 2*strings.Count(msg, "%%"); n != len(args) {
+		if n > len(args) {
+			panic(plBodyError{pgerror.NewError(pgerror.CodeSyntaxError,
+				"too few parameters specified for RAISE")})
+		}
+		panic(plBodyError{pgerror.NewError(pgerror.CodeSyntaxError,
+			"too many parameters specified for RAISE")})
+	}
+	return msg, args
+}
+
+// skipToSemicolon advances to the next semicolon.
+func (p *plBodyParser) skipToSemicolon() {
+	for t := p.peek(); t.id != ';'; t = p.peek() {
+		if t.id == 0 {
+			p.syntaxError()
+		}
+		p.pos++
+	}
+}
+
+// parseSQL parses the SQL statement made of the tokens in [start, end). Only
+// the statements which read or modify rows, and calls to procedures, are
+// supported.
+func (p *plBodyParser) parseSQL(start, end int) Statement {
+	if start == end {
+		p.syntaxError()
+	}
+	stmt, err := ParseOne(p.text(start, end))
+	if err != nil {
+		panic(plBodyError{err})
+	}
+	switch stmt.AST.(type) {
+	case *tree.Insert, *tree.Update, *tree.Delete, *tree.Select, *tree.Call:
+	default:
+		panic(plBodyError{pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"%s statements are not supported in %s bodies", stmt.AST.StatementTag(), p.kind)})
+	}
+	return stmt
+}
+
+// parseExpr parses an expression, which extends up to the next semicolon,
+// comma or END, or up to one of the given words, that is not nested in
+// parentheses, brackets or a CASE expression.
+func (p *plBodyParser) parseExpr(terminators ...string) tree.Expr {
+	start := p.pos
+	depth, caseDepth := 0, 0
+Loop:
+	for {
+		t := p.peek()
+		switch {
+		case t.id == 0:
+			break Loop
+		case t.id == '(' || t.id == '[':
+			depth++
+		case t.id == ')' || t.id == ']':
+			depth--
+		case t.isWord("case"):
+			caseDepth++
+		case t.isWord("end") && caseDepth > 0:
+			caseDepth--
+		case depth == 0 && caseDepth == 0:
+			if t.id == ';' || t.id == ',' || t.isWord("end") {
+				break Loop
+			}
+			for _, term := range terminators {
+				if t.isWord(term) {
+					break Loop
+				}
+			}
+		}
+		p.pos++
+	}
+	if start == p.pos {
+		p.syntaxError()
+	}
+	expr, err := ParseExpr(p.text(start, p.pos))
+	if err != nil {
+		panic(plBodyError{err})
+	}
+	return expr
+}
+
+// text returns the source of the tokens in [start, end).
+func (p *plBodyParser) text(start, end int) string {
+	return p.sql[p.tokens[start].start:p.tokens[end-1].end]
+}
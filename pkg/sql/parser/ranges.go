@@ -0,0 +1,194 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// SourceRange is the range [Start, End) of byte offsets in the SQL of a
+// Statement of the text from which an AST node or one of its clauses was
+// parsed. It excludes the surrounding whitespace and comments, so that
+// replacing SQL[Start:End] with a different fragment preserves the
+// formatting of the rest of the statement.
+type SourceRange struct {
+	Start, End int
+}
+
+// Clause identifies the part of an AST node described by a SourceRange.
+type Clause int
+
+const (
+	// ClauseStatement is the whole node: a statement, a SELECT clause or a
+	// subquery.
+	ClauseStatement Clause = iota
+	// ClauseWith is the WITH clause of a SELECT, INSERT, UPSERT, UPDATE or
+	// DELETE statement.
+	ClauseWith
+	// ClauseTargets is the list of expressions of a SELECT clause.
+	ClauseTargets
+	// ClauseFrom is the FROM clause of a SELECT clause.
+	ClauseFrom
+	// ClauseWhere is the WHERE clause of a SELECT clause or of an UPDATE or
+	// DELETE statement.
+	ClauseWhere
+	// ClauseGroupBy is the GROUP BY clause of a SELECT clause.
+	ClauseGroupBy
+	// ClauseHaving is the HAVING clause of a SELECT clause.
+	ClauseHaving
+	// ClauseWindow is the WINDOW clause of a SELECT clause.
+	ClauseWindow
+	// ClauseOrderBy is the ORDER BY clause of a SELECT, UPDATE or DELETE
+	// statement.
+	ClauseOrderBy
+	// ClauseLimit is the LIMIT and OFFSET clauses of a SELECT statement, or
+	// the LIMIT clause of an UPDATE or DELETE statement.
+	ClauseLimit
+	// ClauseLocking is the locking clause, like FOR UPDATE, of a SELECT
+	// statement.
+	ClauseLocking
+	// ClauseSet is the SET clause of an UPDATE statement.
+	ClauseSet
+	// ClauseOnConflict is the ON CONFLICT clause of an INSERT statement.
+	ClauseOnConflict
+	// ClauseReturning is the RETURNING clause of an INSERT, UPSERT, UPDATE
+	// or DELETE statement.
+	ClauseReturning
+
+	// noClause marks the symbols passed to lexer.recordRanges which only
+	// delimit the clauses.
+	noClause Clause = -1
+)
+
+// SourceRangeKey identifies a SourceRange in Statement.SourceRanges.
+type SourceRangeKey struct {
+	// Node is a node of the AST of the statement, such as a *tree.Select or
+	// a *tree.SelectClause.
+	Node   tree.NodeFormatter
+	Clause Clause
+}
+
+// SourceRange returns the range of the SQL of the statement from which the
+// given clause of an AST node was parsed, when the statement is parsed with
+// ParseOptions.RecordSourceRanges. ok is false if the node does not have
+// such a clause, or if its range was not recorded.
+//
+// The ranges are recorded for the statement itself and, throughout its AST,
+// for the SELECT, INSERT, UPSERT, UPDATE and DELETE statements and for the
+// SELECT clauses, along with their clauses. The nodes rewritten after
+// parsing lose their ranges.
+func (s Statement) SourceRange(node tree.NodeFormatter, clause Clause) (r SourceRange, ok bool) {
+	r, ok = s.SourceRanges[SourceRangeKey{Node: node, Clause: clause}]
+	return r, ok
+}
+
+// clausePos describes a symbol of the grammar rule being reduced for
+// lexer.recordRanges: pos is the position of its first token, which is only
+// meaningful if the symbol is present, i.e. it matched at least one token.
+type clausePos struct {
+	clause  Clause
+	pos     int32
+	present bool
+}
+
+// recordRanges records the source ranges of a node built by the grammar rule
+// being reduced, if they are requested. syms are the clauses of the rule,
+// in order, each of which extends up to the next present symbol, hence the
+// symbols which delimit the clauses must also be passed, using noClause.
+// The range of the whole node extends from the first present symbol to the
+// last token of the rule.
+func (l *lexer) recordRanges(node tree.NodeFormatter, syms ...clausePos) {
+	if l.tokenEnds == nil {
+		return
+	}
+	last := l.lastReducedToken()
+	// next is the index of the first token which follows the symbol.
+	next := last + 1
+	for i := len(syms) - 1; i >= 0; i-- {
+		s := &syms[i]
+		if !s.present {
+			continue
+		}
+		idx := l.tokenIndex(s.pos)
+		if s.clause != noClause {
+			l.addRange(node, s.clause, idx, next-1)
+		}
+		next = idx
+	}
+	l.addRange(node, ClauseStatement, next, last)
+}
+
+// recordStmtRange records the source range of the parsed statement, if the
+// ranges are requested.
+func (l *lexer) recordStmtRange(stmt tree.Statement) {
+	if l.tokenEnds == nil || stmt == nil {
+		return
+	}
+	l.addRange(stmt, ClauseStatement, 0, len(l.tokens)-1)
+}
+
+// addRange records the range spanning the tokens from first to last.
+func (l *lexer) addRange(node tree.NodeFormatter, clause Clause, first, last int) {
+	if first > last || first < 0 || last >= len(l.tokens) {
+		return
+	}
+	if l.ranges == nil {
+		l.ranges = make(map[SourceRangeKey]SourceRange)
+	}
+	l.ranges[SourceRangeKey{Node: node, Clause: clause}] = SourceRange{
+		Start: int(l.tokens[first].pos),
+		End:   int(l.tokenEnds[last]),
+	}
+}
+
+// lastReducedToken returns the index of the last token of the grammar rule
+// being reduced. The parser reads the token which follows the rule ahead of
+// the reduction only if it needs it to decide to reduce.
+func (l *lexer) lastReducedToken() int {
+	last := l.lastPos
+	if l.parser.Lookahead() >= 0 {
+		last--
+	}
+	if last >= len(l.tokens) {
+		last = len(l.tokens) - 1
+	}
+	return last
+}
+
+// tokenIndex returns the index of the token at the given position.
+func (l *lexer) tokenIndex(pos int32) int {
+	return sort.Search(len(l.tokens), func(i int) bool {
+		return l.tokens[i].pos >= pos
+	})
+}
+
+// recordSelectClauseRanges records the source ranges of a SELECT clause,
+// given the positions of the SELECT keyword and of the symbols of its
+// clauses.
+func (l *lexer) recordSelectClauseRanges(
+	n *tree.SelectClause, selectPos, targets, from, where, groupBy, having, window int32,
+) {
+	l.recordRanges(n,
+		clausePos{noClause, selectPos, true},
+		clausePos{ClauseTargets, targets, true},
+		clausePos{ClauseFrom, from, n.From != nil && len(n.From.Tables) > 0},
+		clausePos{ClauseWhere, where, n.Where != nil},
+		clausePos{ClauseGroupBy, groupBy, n.GroupBy != nil},
+		clausePos{ClauseHaving, having, n.Having != nil},
+		clausePos{ClauseWindow, window, n.Window != nil},
+	)
+}
@@ -0,0 +1,153 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSourceRanges(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	selectClause := func(stmt tree.Statement) tree.NodeFormatter {
+		return stmt.(*tree.Select).Select.(*tree.SelectClause)
+	}
+	subquery := func(stmt tree.Statement) tree.NodeFormatter {
+		from := selectClause(stmt).(*tree.SelectClause).From
+		sub := from.Tables[0].(*tree.AliasedTableExpr).Expr.(*tree.Subquery)
+		return sub.Select.(*tree.ParenSelect).Select
+	}
+	self := func(stmt tree.Statement) tree.NodeFormatter { return stmt }
+
+	testData := []struct {
+		sql      string
+		node     func(tree.Statement) tree.NodeFormatter
+		expected map[parser.Clause]string
+	}{
+		{
+			`SELECT  a, b FROM t  WHERE a > 1 GROUP BY a HAVING count(*) > 1 ORDER BY a LIMIT 10 OFFSET 2`,
+			self,
+			map[parser.Clause]string{
+				parser.ClauseStatement: `SELECT  a, b FROM t  WHERE a > 1 GROUP BY a HAVING count(*) > 1 ORDER BY a LIMIT 10 OFFSET 2`,
+				parser.ClauseOrderBy:   `ORDER BY a`,
+				parser.ClauseLimit:     `LIMIT 10 OFFSET 2`,
+			},
+		},
+		{
+			`SELECT  a, b FROM t  WHERE a > 1 GROUP BY a HAVING count(*) > 1 ORDER BY a LIMIT 10 OFFSET 2`,
+			selectClause,
+			map[parser.Clause]string{
+				parser.ClauseStatement: `SELECT  a, b FROM t  WHERE a > 1 GROUP BY a HAVING count(*) > 1`,
+				parser.ClauseTargets:   `a, b`,
+				parser.ClauseFrom:      `FROM t`,
+				parser.ClauseWhere:     `WHERE a > 1`,
+				parser.ClauseGroupBy:   `GROUP BY a`,
+				parser.ClauseHaving:    `HAVING count(*) > 1`,
+			},
+		},
+		{
+			`SELECT * FROM (SELECT a FROM t WHERE b ORDER BY a) AS s`,
+			subquery,
+			map[parser.Clause]string{
+				parser.ClauseStatement: `SELECT a FROM t WHERE b ORDER BY a`,
+				parser.ClauseOrderBy:   `ORDER BY a`,
+			},
+		},
+		{
+			`SELECT a FROM t ORDER BY a FOR UPDATE OF t SKIP LOCKED LIMIT 1`,
+			self,
+			map[parser.Clause]string{
+				parser.ClauseStatement: `SELECT a FROM t ORDER BY a FOR UPDATE OF t SKIP LOCKED LIMIT 1`,
+				parser.ClauseOrderBy:   `ORDER BY a`,
+				parser.ClauseLocking:   `FOR UPDATE OF t SKIP LOCKED`,
+				parser.ClauseLimit:     `LIMIT 1`,
+			},
+		},
+		{
+			`WITH x AS (SELECT 1) DELETE FROM t WHERE a = 1 /* c */ RETURNING a`,
+			self,
+			map[parser.Clause]string{
+				parser.ClauseStatement: `WITH x AS (SELECT 1) DELETE FROM t WHERE a = 1 /* c */ RETURNING a`,
+				parser.ClauseWith:      `WITH x AS (SELECT 1)`,
+				parser.ClauseWhere:     `WHERE a = 1`,
+				parser.ClauseReturning: `RETURNING a`,
+			},
+		},
+		{
+			`UPDATE t SET a = 1, b = 2 WHERE c LIMIT 1`,
+			self,
+			map[parser.Clause]string{
+				parser.ClauseStatement: `UPDATE t SET a = 1, b = 2 WHERE c LIMIT 1`,
+				parser.ClauseSet:       `SET a = 1, b = 2`,
+				parser.ClauseWhere:     `WHERE c`,
+				parser.ClauseLimit:     `LIMIT 1`,
+			},
+		},
+		{
+			`INSERT INTO t VALUES (1) ON CONFLICT (a) DO NOTHING RETURNING *`,
+			self,
+			map[parser.Clause]string{
+				parser.ClauseStatement:  `INSERT INTO t VALUES (1) ON CONFLICT (a) DO NOTHING RETURNING *`,
+				parser.ClauseOnConflict: `ON CONFLICT (a) DO NOTHING`,
+				parser.ClauseReturning:  `RETURNING *`,
+			},
+		},
+		{
+			`CREATE TABLE t (a INT)`,
+			self,
+			map[parser.Clause]string{
+				parser.ClauseStatement: `CREATE TABLE t (a INT)`,
+			},
+		},
+	}
+	allClauses := []parser.Clause{
+		parser.ClauseStatement, parser.ClauseWith, parser.ClauseTargets, parser.ClauseFrom,
+		parser.ClauseWhere, parser.ClauseGroupBy, parser.ClauseHaving, parser.ClauseWindow,
+		parser.ClauseOrderBy, parser.ClauseLimit, parser.ClauseLocking, parser.ClauseSet,
+		parser.ClauseOnConflict, parser.ClauseReturning,
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			stmts, err := parser.ParseWithOptions(d.sql, parser.ParseOptions{RecordSourceRanges: true})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmt := stmts[0]
+			node := d.node(stmt.AST)
+			for _, c := range allClauses {
+				r, ok := stmt.SourceRange(node, c)
+				expected, expectedOK := d.expected[c]
+				if ok != expectedOK {
+					t.Fatalf("clause %d: expected a range: %t, found %+v", c, expectedOK, r)
+				}
+				if ok && stmt.SQL[r.Start:r.End] != expected {
+					t.Errorf("clause %d: expected %q, found %q", c, expected, stmt.SQL[r.Start:r.End])
+				}
+			}
+		})
+	}
+
+	stmts, err := parser.Parse(`SELECT 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmts[0].SourceRanges != nil {
+		t.Fatalf("expected no source ranges, found %v", stmts[0].SourceRanges)
+	}
+}
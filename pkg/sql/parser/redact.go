@@ -0,0 +1,70 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+
+// RedactionMode determines how the literals of a statement are redacted by
+// Redact.
+type RedactionMode int
+
+const (
+	// RedactLiterals replaces every literal with the marker "_".
+	RedactLiterals RedactionMode = iota
+	// HashLiterals replaces every literal with a short hash of its value, so
+	// that the uses of a same value can be correlated. Values from a small
+	// domain can be recovered from their hash; use RedactLiterals when this
+	// matters.
+	HashLiterals
+)
+
+// Redact returns the canonical text of a statement with its literals
+// (strings, numbers, byte strings, etc.) redacted, so that it can be logged
+// or shipped off-cluster without disclosing user data. For example,
+//
+//   SELECT * FROM t WHERE name = 'bob' AND id IN (1, 2, 3)
+//
+// is redacted as
+//
+//   SELECT * FROM t WHERE (name = _) AND (id IN (_, _, _))
+//
+// with RedactLiterals, and as
+//
+//   SELECT * FROM t WHERE (name = _:43f4f452) AND (id IN (_:340ca71c, _:370cabd5, _:360caa42))
+//
+// with HashLiterals. Unlike Fingerprint, Redact preserves the structure of
+// the statement: lists of literals are not shortened. Placeholders and NULL
+// are kept as is, and passwords are always hidden.
+func Redact(stmt tree.Statement, mode RedactionMode) string {
+	f := tree.FmtRedactConstants
+	if mode == HashLiterals {
+		f = tree.FmtHashConstants
+	}
+	return tree.AsStringWithFlags(stmt, f)
+}
+
+// RedactSQL parses the given SQL and returns the redacted text of each of
+// the statements it contains. See Redact.
+func RedactSQL(sql string, mode RedactionMode) ([]string, error) {
+	stmts, err := Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, len(stmts))
+	for i := range stmts {
+		res[i] = Redact(stmts[i].AST, mode)
+	}
+	return res, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestRedact(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		redacted string
+		hashed   string
+	}{
+		{`SELECT * FROM t WHERE name = 'bob' AND id IN (1, 2, 3)`,
+			`SELECT * FROM t WHERE (name = _) AND (id IN (_, _, _))`,
+			`SELECT * FROM t WHERE (name = _:43f4f452) AND (id IN (_:340ca71c, _:370cabd5, _:360caa42))`},
+		{`INSERT INTO t VALUES (1, 'x'), (2, NULL)`,
+			`INSERT INTO t VALUES (_, _), (_, NULL)`,
+			`INSERT INTO t VALUES (_:340ca71c, _:5dbfab07), (_:370cabd5, NULL)`},
+		{`UPDATE t SET a = $1 WHERE b = ARRAY[1, 1] LIMIT 10`,
+			`UPDATE t SET a = $1 WHERE b = ARRAY[_, _] LIMIT _`,
+			`UPDATE t SET a = $1 WHERE b = ARRAY[_:340ca71c, _:340ca71c] LIMIT _:1beb2a44`},
+		{`COMMENT ON TABLE t IS 'secret comment'`,
+			`COMMENT ON TABLE t IS _`,
+			`COMMENT ON TABLE t IS _:11c9b8a0`},
+		{`CREATE USER foo WITH PASSWORD 'bar'`,
+			`CREATE USER _ WITH PASSWORD *****`,
+			`CREATE USER _:bf464483 WITH PASSWORD *****`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			for _, tc := range []struct {
+				mode     parser.RedactionMode
+				expected string
+			}{
+				{parser.RedactLiterals, d.redacted},
+				{parser.HashLiterals, d.hashed},
+			} {
+				res, err := parser.RedactSQL(d.sql, tc.mode)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if expected := []string{tc.expected}; !reflect.DeepEqual(res, expected) {
+					t.Errorf("expected %q, got %q", expected, res)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"reflect"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/pkg/errors"
+)
+
+// VerifyRoundTrip checks that every statement of the given SQL, once
+// formatted with tree.FmtRoundTrip, parses back into a structurally
+// identical AST. It returns an error describing the first difference, if
+// any. See VerifyStatementRoundTrip.
+func VerifyRoundTrip(sql string) error {
+	stmts, err := Parse(sql)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range stmts {
+		if err := VerifyStatementRoundTrip(stmt.AST); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyStatementRoundTrip checks that the given statement, once formatted
+// with tree.FmtRoundTrip, parses back into a structurally identical AST.
+// This is useful for the tools which rewrite SQL statements by modifying
+// their AST, to make sure that the resulting text means what the modified
+// AST does.
+//
+// Two ASTs are structurally identical if they are made of nodes of the same
+// types holding the same exported values. The parentheses are ignored:
+// since the formatter encloses the operands of operators within
+// parentheses, an AST built programmatically is identical to the AST it
+// parses into, which holds ParenExprs. The datums and the column types are
+// compared by type and formatted value.
+func VerifyStatementRoundTrip(stmt tree.Statement) error {
+	if stmt == nil {
+		return nil
+	}
+	sql := tree.AsStringWithFlags(stmt, tree.FmtRoundTrip)
+	reparsed, err := ParseOne(sql)
+	if err != nil {
+		return errors.Wrapf(err, "formatted statement %q does not parse", sql)
+	}
+	if err := compareAST("", reflect.ValueOf(&stmt).Elem(),
+		reflect.ValueOf(&reparsed.AST).Elem()); err != nil {
+		return errors.Wrapf(err, "formatted statement %q does not parse into the same AST", sql)
+	}
+	return nil
+}
+
+var parenExprPtrType = reflect.TypeOf((*tree.ParenExpr)(nil))
+
+// skipParens returns the expression enclosed within the parentheses of v,
+// an interface value, if v holds a ParenExpr.
+func skipParens(v reflect.Value) reflect.Value {
+	for !v.IsNil() && v.Elem().Type() == parenExprPtrType {
+		v = v.Elem().Elem().FieldByName("Expr")
+	}
+	return v
+}
+
+// compareAST returns an error if a and b, which are parts of two ASTs found
+// at the given path, are not structurally identical.
+func compareAST(path string, a, b reflect.Value) error {
+	t := a.Type()
+	switch a.Kind() {
+	case reflect.Interface:
+		a, b = skipParens(a), skipParens(b)
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return errors.Errorf("%s: %s differs from %s", path, describeAST(a), describeAST(b))
+			}
+			return nil
+		}
+		if a.Elem().Type() != b.Elem().Type() {
+			return errors.Errorf("%s: %s differs from %s", path, describeAST(a), describeAST(b))
+		}
+		return compareAST(path, a.Elem(), b.Elem())
+
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			if a.IsNil() != b.IsNil() {
+				return errors.Errorf("%s: %s differs from %s", path, describeAST(a), describeAST(b))
+			}
+			return nil
+		}
+		switch {
+		case t == functionDefPtrType:
+			if an, bn := a.Interface().(*tree.FunctionDefinition).Name,
+				b.Interface().(*tree.FunctionDefinition).Name; an != bn {
+				return errors.Errorf("%s: function %s differs from %s", path, an, bn)
+			}
+			return nil
+		case t.Implements(datumType), t.Implements(colTypeFormatter):
+			return compareFormatted(path, a, b)
+		}
+		return compareAST(path, a.Elem(), b.Elem())
+
+	case reflect.Struct:
+		switch {
+		case t == numValType:
+			an, bn := addr(a).Interface().(*tree.NumVal), addr(b).Interface().(*tree.NumVal)
+			if an.Negative != bn.Negative || an.OrigString != bn.OrigString ||
+				!constant.Compare(an.Value, token.EQL, bn.Value) {
+				return errors.Errorf("%s: constant %s differs from %s", path, an, bn)
+			}
+			return nil
+		case t == strValType:
+			as, bs := addr(a).Interface().(*tree.StrVal), addr(b).Interface().(*tree.StrVal)
+			if as.RawString() != bs.RawString() || as.ScannedAsBytes() != bs.ScannedAsBytes() {
+				return errors.Errorf("%s: constant %s differs from %s", path, as, bs)
+			}
+			return nil
+		case t.Implements(datumType), t.Implements(colTypeFormatter):
+			return compareFormatted(path, a, b)
+		}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" && !(f.Anonymous && f.Type.Kind() == reflect.Struct) {
+				// Unexported fields are not populated by the parser.
+				continue
+			}
+			if err := compareAST(path+"."+f.Name, a.Field(i), b.Field(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		// An empty slice is formatted as a missing one.
+		if a.Len() != b.Len() {
+			return errors.Errorf("%s: %d elements differ from %d", path, a.Len(), b.Len())
+		}
+		for i := 0; i < a.Len(); i++ {
+			if err := compareAST(fmt.Sprintf("%s[%d]", path, i), a.Index(i), b.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.String:
+		if a.String() != b.String() {
+			return errors.Errorf("%s: %q differs from %q", path, a.String(), b.String())
+		}
+		return nil
+	case reflect.Bool:
+		if a.Bool() != b.Bool() {
+			return errors.Errorf("%s: %t differs from %t", path, a.Bool(), b.Bool())
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if a.Int() != b.Int() {
+			return errors.Errorf("%s: %d differs from %d", path, a.Int(), b.Int())
+		}
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if a.Uint() != b.Uint() {
+			return errors.Errorf("%s: %d differs from %d", path, a.Uint(), b.Uint())
+		}
+		return nil
+	case reflect.Float32, reflect.Float64:
+		if a.Float() != b.Float() {
+			return errors.Errorf("%s: %g differs from %g", path, a.Float(), b.Float())
+		}
+		return nil
+	}
+	if a.CanInterface() && !reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return errors.Errorf("%s: %v differs from %v", path, a.Interface(), b.Interface())
+	}
+	return nil
+}
+
+// compareFormatted compares a and b, which are both datums or both column
+// types, by their formatted values.
+func compareFormatted(path string, a, b reflect.Value) error {
+	var as, bs string
+	if d, ok := a.Interface().(tree.Datum); ok {
+		as = tree.AsStringWithFlags(d, tree.FmtParsable)
+		bs = tree.AsStringWithFlags(b.Interface().(tree.Datum), tree.FmtParsable)
+	} else {
+		as = a.Interface().(coltypes.ColTypeFormatter).String()
+		bs = b.Interface().(coltypes.ColTypeFormatter).String()
+	}
+	if as != bs {
+		return errors.Errorf("%s: %s differs from %s", path, as, bs)
+	}
+	return nil
+}
+
+// describeAST returns a description of v, an interface or a pointer, for
+// the errors of compareAST.
+func describeAST(v reflect.Value) string {
+	if v.IsNil() {
+		return "nil"
+	}
+	var n tree.NodeFormatter
+	ok := false
+	if v.CanInterface() {
+		n, ok = v.Interface().(tree.NodeFormatter)
+	}
+	if !ok {
+		return v.Elem().Type().String()
+	}
+	return fmt.Sprintf("%s (%s)", tree.AsStringWithFlags(n, tree.FmtRoundTrip), v.Elem().Type())
+}
@@ -0,0 +1,82 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestVerifyRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []string{
+		`SELECT 1; SELECT 2`,
+		`select   a+b*c, -1.50, 1e10, x'ff', e'\n', $1 from t where (a) = 1`,
+		`SELECT count(*) FILTER (WHERE a > 1) OVER (PARTITION BY b) FROM t GROUP BY b`,
+		`SELECT a::INT4, CAST(b AS DECIMAL(10, 2)), c:::STRING FROM t`,
+		`INSERT INTO t VALUES (1, 'a') ON CONFLICT (a) DO UPDATE SET b = excluded.b RETURNING *`,
+		`UPDATE t SET (a, b) = (1, 2) WHERE a IN (SELECT 1)`,
+		`CREATE TABLE t (a INT PRIMARY KEY, b STRING AS (lower(c)) STORED, INDEX (b))`,
+		`CREATE USER foo WITH PASSWORD 'bar'`,
+	}
+	for _, sql := range testData {
+		t.Run(sql, func(t *testing.T) {
+			if err := parser.VerifyRoundTrip(sql); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	if err := parser.VerifyRoundTrip(`SELECT`); !testutils.IsError(err, "syntax error") {
+		t.Fatalf("expected a syntax error, found %v", err)
+	}
+}
+
+func TestVerifyStatementRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	col := func(name string) tree.Expr {
+		return &tree.UnresolvedName{NumParts: 1, Parts: tree.NameParts{name}}
+	}
+	sel := func(e tree.Expr) tree.Statement {
+		return &tree.Select{Select: &tree.SelectClause{
+			Exprs: tree.SelectExprs{{Expr: e}},
+			From:  &tree.From{},
+		}}
+	}
+
+	// A rewritten expression without ParenExprs is formatted with
+	// parentheses, which are ignored by the comparison.
+	stmt := sel(&tree.BinaryExpr{
+		Operator: tree.Mult,
+		Left:     &tree.BinaryExpr{Operator: tree.Plus, Left: col("a"), Right: col("b")},
+		Right:    col("c"),
+	})
+	if err := parser.VerifyStatementRoundTrip(stmt); err != nil {
+		t.Fatal(err)
+	}
+
+	// A datum is formatted as a constant, which parses into a different node.
+	stmt = sel(tree.NewDString("a"))
+	if err := parser.VerifyStatementRoundTrip(stmt); !testutils.IsError(err,
+		`formatted statement "SELECT 'a'" does not parse into the same AST`) {
+		t.Fatalf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,508 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// ParseRoutineBody parses the PL/pgSQL body of a routine. See tree.RoutineBlock
+// for the statements it can contain.
+//
+// As for trigger bodies, the body is split into statements using the scanner,
+// and the expressions and SQL statements it contains are then parsed
+// individually.
+func ParseRoutineBody(body string) (*tree.RoutineBlock, error) {
+	return parseRoutineBody(body, "function")
+}
+
+// ParseProcedureBody parses the body of a procedure written in the given
+// language into a block that can be executed by the plpgsql interpreter. The
+// statements of a sql body become the statements of the block.
+func ParseProcedureBody(language tree.FunctionLanguage, body string) (*tree.RoutineBlock, error) {
+	if language == tree.FunctionLangPLpgSQL {
+		return parseRoutineBody(body, "procedure")
+	}
+	stmts, err := parseSQLRoutineBody(body, "procedure")
+	if err != nil {
+		return nil, err
+	}
+	block := &tree.RoutineBlock{Stmts: make([]tree.RoutineStmt, len(stmts))}
+	for i, stmt := range stmts {
+		block.Stmts[i] = &tree.RoutineExec{Statement: stmt}
+	}
+	return block, nil
+}
+
+func parseRoutineBody(body string, kind string) (*tree.RoutineBlock, error) {
+	p := routineBodyParser{plBodyParser: plBodyParser{sql: body, kind: kind}}
+	if err := p.scan(); err != nil {
+		return nil, err
+	}
+	return p.parse()
+}
+
+// newCreateFunction returns the CREATE FUNCTION statement with the given
+// clauses, after parsing its body, so that a function is validated and its
+// dependencies are known when it is defined rather than when it is called.
+func newCreateFunction(
+	replace bool,
+	name *tree.UnresolvedName,
+	params tree.FunctionParams,
+	returnType coltypes.CastTargetType,
+	language string,
+	body string,
+) (*tree.CreateFunction, error) {
+	fn := &tree.CreateFunction{
+		Replace:    replace,
+		Name:       *name,
+		Params:     params,
+		ReturnType: returnType,
+		Body:       body,
+	}
+	var err error
+	if fn.Language, err = parseFunctionLanguage(language); err != nil {
+		return nil, err
+	}
+	switch fn.Language {
+	case tree.FunctionLangSQL:
+		fn.SQLBody, err = ParseSQLFunctionBody(body)
+	case tree.FunctionLangPLpgSQL:
+		fn.PLBody, err = ParseRoutineBody(body)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return fn, nil
+}
+
+// newCreateProcedure returns the CREATE PROCEDURE statement with the given
+// clauses, after parsing its body as for CREATE FUNCTION.
+func newCreateProcedure(
+	replace bool,
+	name tree.TableName,
+	params tree.FunctionParams,
+	language string,
+	body string,
+) (*tree.CreateProcedure, error) {
+	proc := &tree.CreateProcedure{
+		Replace: replace,
+		Name:    name,
+		Params:  params,
+		Body:    body,
+	}
+	var err error
+	if proc.Language, err = parseFunctionLanguage(language); err != nil {
+		return nil, err
+	}
+	switch proc.Language {
+	case tree.FunctionLangSQL:
+		proc.SQLBody, err = parseSQLRoutineBody(body, "procedure")
+	case tree.FunctionLangPLpgSQL:
+		if proc.PLBody, err = parseRoutineBody(body, "procedure"); err == nil {
+			err = checkProcedureReturns(proc.PLBody.Stmts)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return proc, nil
+}
+
+func parseFunctionLanguage(language string) (tree.FunctionLanguage, error) {
+	switch strings.ToLower(language) {
+	case "sql":
+		return tree.FunctionLangSQL, nil
+	case "plpgsql":
+		return tree.FunctionLangPLpgSQL, nil
+	default:
+		return 0, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+			"language %q does not exist", language)
+	}
+}
+
+// checkProcedureReturns checks that the RETURN statements of the PL/pgSQL body
+// of a procedure have no value, since procedures do not return values.
+func checkProcedureReturns(stmts []tree.RoutineStmt) error {
+	for _, stmt := range stmts {
+		var err error
+		switch t := stmt.(type) {
+		case *tree.RoutineBlock:
+			err = checkProcedureReturns(t.Stmts)
+		case *tree.RoutineIf:
+			err = checkProcedureReturns(t.Then)
+			for i := 0; err == nil && i < len(t.ElseIfs); i++ {
+				err = checkProcedureReturns(t.ElseIfs[i].Stmts)
+			}
+			if err == nil {
+				err = checkProcedureReturns(t.Else)
+			}
+		case *tree.RoutineLoop:
+			err = checkProcedureReturns(t.Stmts)
+		case *tree.RoutineReturn:
+			if t.Expr != nil {
+				err = pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+					"RETURN cannot have a value in a procedure")
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSQLRoutineBody parses the body of a SQL function or procedure. As in
+// PL/pgSQL bodies, only the statements which read or modify rows, and calls
+// to procedures, are supported.
+func parseSQLRoutineBody(body string, kind string) ([]tree.Statement, error) {
+	stmts, err := Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]tree.Statement, len(stmts))
+	for i, stmt := range stmts {
+		switch stmt.AST.(type) {
+		case *tree.Insert, *tree.Update, *tree.Delete, *tree.Select, *tree.Call:
+		default:
+			return nil, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+				"%s statements are not supported in %s bodies", stmt.AST.StatementTag(), kind)
+		}
+		res[i] = stmt.AST
+	}
+	return res, nil
+}
+
+// ParseSQLFunctionBody parses the body of a SQL function. Since a function
+// returns a value, the last statement must return rows.
+func ParseSQLFunctionBody(body string) ([]tree.Statement, error) {
+	res, err := parseSQLRoutineBody(body, "function")
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+			"function body must contain at least one statement")
+	}
+	if !returnsRows(res[len(res)-1]) {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+			"the last statement of a function body must be a SELECT or have a RETURNING clause")
+	}
+	return res, nil
+}
+
+// returnsRows returns whether a statement of a SQL function body returns
+// rows.
+func returnsRows(stmt tree.Statement) bool {
+	var returning tree.ReturningClause
+	switch t := stmt.(type) {
+	case *tree.Select:
+		return true
+	case *tree.Insert:
+		returning = t.Returning
+	case *tree.Update:
+		returning = t.Returning
+	case *tree.Delete:
+		returning = t.Returning
+	}
+	_, ok := returning.(*tree.ReturningExprs)
+	return ok
+}
+
+type routineBodyParser struct {
+	plBodyParser
+	// loopDepth is the number of loops around the current position.
+	loopDepth int
+}
+
+func (p *routineBodyParser) parse() (block *tree.RoutineBlock, err error) {
+	defer recoverPLBodyError(&err)
+
+	block = p.parseBlock()
+	p.expectEnd()
+	return block, nil
+}
+
+func (p *routineBodyParser) parseBlock() *tree.RoutineBlock {
+	var block tree.RoutineBlock
+	if p.peek().isWord("declare") {
+		p.pos++
+		for !p.peek().isWord("begin") {
+			block.Decls = append(block.Decls, p.parseDecl())
+			p.expect(';')
+		}
+	}
+	p.expectWord("begin")
+	block.Stmts = p.parseStmts("end")
+	p.expectWord("end")
+	return &block
+}
+
+func (p *routineBodyParser) parseDecl() tree.RoutineDecl {
+	decl := tree.RoutineDecl{Name: p.parseName()}
+	if p.peek().isWord("constant") {
+		p.pos++
+		decl.Constant = true
+	}
+
+	// The type extends up to the next token that can follow it.
+	start := p.pos
+	for depth := 0; ; p.pos++ {
+		t := p.peek()
+		if t.id == '(' {
+			depth++
+		} else if t.id == ')' {
+			depth--
+		} else if depth == 0 && (t.id == 0 || t.id == ';' || t.id == ':' || t.id == '=' ||
+			t.isWord("not") || t.isWord("default")) {
+			break
+		}
+	}
+	if start == p.pos {
+		p.syntaxError()
+	}
+	typ, err := ParseType(p.text(start, p.pos))
+	if err != nil {
+		panic(plBodyError{err})
+	}
+	decl.Type = typ
+
+	if p.peek().isWord("not") {
+		p.pos++
+		p.expectWord("null")
+		decl.NotNull = true
+	}
+	if p.peek().isWord("default") {
+		p.pos++
+		decl.Default = p.parseExpr()
+	} else if p.peek().id != ';' {
+		p.expectAssign()
+		decl.Default = p.parseExpr()
+	}
+	return decl
+}
+
+// parseStmts parses statements until one of the given terminator words is
+// found.
+func (p *routineBodyParser) parseStmts(terminators ...string) []tree.RoutineStmt {
+	var stmts []tree.RoutineStmt
+	for {
+		t := p.peek()
+		if t.id == 0 {
+			p.syntaxError()
+		}
+		for _, term := range terminators {
+			if t.isWord(term) {
+				return stmts
+			}
+		}
+		stmts = append(stmts, p.parseStmt())
+	}
+}
+
+func (p *routineBodyParser) parseStmt() tree.RoutineStmt {
+	t := p.peek()
+	var stmt tree.RoutineStmt
+	switch {
+	case t.isWord("declare") || t.isWord("begin"):
+		stmt = p.parseBlock()
+	case t.isWord("if"):
+		stmt = p.parseIf()
+	case t.isWord("loop") || t.isWord("while"):
+		stmt = p.parseLoop()
+	case t.isWord("exit") || t.isWord("continue"):
+		stmt = p.parseExit()
+	case t.isWord("raise"):
+		var raise tree.RoutineRaise
+		raise.Message, raise.Args = p.parseRaiseArgs()
+		stmt = &raise
+	case t.isWord("return"):
+		stmt = p.parseReturn()
+	case p.atAssign():
+		v := p.parseName()
+		p.expectAssign()
+		stmt = &tree.RoutineAssign{Var: v, Expr: p.parseExpr()}
+	default:
+		stmt = p.parseExec()
+	}
+	p.expect(';')
+	return stmt
+}
+
+func (p *routineBodyParser) parseIf() tree.RoutineStmt {
+	p.expectWord("if")
+	var stmt tree.RoutineIf
+	stmt.Cond = p.parseExpr("then")
+	p.expectWord("then")
+	stmt.Then = p.parseStmts("elsif", "else", "end")
+	for p.peek().isWord("elsif") {
+		p.pos++
+		var elseIf tree.RoutineElseIf
+		elseIf.Cond = p.parseExpr("then")
+		p.expectWord("then")
+		elseIf.Stmts = p.parseStmts("elsif", "else", "end")
+		stmt.ElseIfs = append(stmt.ElseIfs, elseIf)
+	}
+	if p.peek().isWord("else") {
+		p.pos++
+		stmt.Else = p.parseStmts("end")
+		if stmt.Else == nil {
+			stmt.Else = []tree.RoutineStmt{}
+		}
+	}
+	p.expectWord("end")
+	p.expectWord("if")
+	return &stmt
+}
+
+func (p *routineBodyParser) parseLoop() tree.RoutineStmt {
+	var stmt tree.RoutineLoop
+	if p.peek().isWord("while") {
+		p.pos++
+		stmt.While = p.parseExpr("loop")
+	}
+	p.expectWord("loop")
+	p.loopDepth++
+	stmt.Stmts = p.parseStmts("end")
+	p.loopDepth--
+	p.expectWord("end")
+	p.expectWord("loop")
+	return &stmt
+}
+
+func (p *routineBodyParser) parseExit() tree.RoutineStmt {
+	stmt := tree.RoutineExit{Continue: p.peek().isWord("continue")}
+	if p.loopDepth == 0 {
+		panic(plBodyError{pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"%s cannot be used outside a loop", strings.ToUpper(p.peek().str))})
+	}
+	p.pos++
+	if p.peek().isWord("when") {
+		p.pos++
+		stmt.When = p.parseExpr()
+	}
+	return &stmt
+}
+
+func (p *routineBodyParser) parseReturn() tree.RoutineStmt {
+	p.expectWord("return")
+	var stmt tree.RoutineReturn
+	if p.peek().id != ';' {
+		stmt.Expr = p.parseExpr()
+	}
+	return &stmt
+}
+
+// parseExec parses a SQL statement, which extends up to the next semicolon,
+// along with its INTO clause if it has one. As in PostgreSQL, the INTO clause
+// can appear anywhere in the statement outside of parentheses, but is usually
+// found after the select list or the RETURNING clause.
+func (p *routineBodyParser) parseExec() tree.RoutineStmt {
+	start := p.pos
+	intoStart, intoEnd := -1, -1
+	var into tree.NameList
+	for depth := 0; ; {
+		t := p.peek()
+		if t.id == 0 {
+			p.syntaxError()
+		}
+		if t.id == ';' {
+			break
+		}
+		switch {
+		case t.id == '(' || t.id == '[':
+			depth++
+		case t.id == ')' || t.id == ']':
+			depth--
+		case depth == 0 && intoStart == -1 && p.pos > start && t.isWord("into") &&
+			!p.tokens[p.pos-1].isWord("insert") && !p.tokens[p.pos-1].isWord("upsert"):
+			intoStart = p.pos
+			p.pos++
+			into = append(into, p.parseName())
+			for p.peek().id == ',' {
+				p.pos++
+				into = append(into, p.parseName())
+			}
+			intoEnd = p.pos
+			continue
+		}
+		p.pos++
+	}
+
+	var stmt Statement
+	if intoStart == -1 {
+		stmt = p.parseSQL(start, p.pos)
+	} else {
+		// Parse the statement without its INTO clause.
+		sql := p.sql[p.tokens[start].start:p.tokens[intoStart].start]
+		if intoEnd < p.pos {
+			sql += p.text(intoEnd, p.pos)
+		}
+		sub := plBodyParser{sql: sql, kind: p.kind}
+		if err := sub.scan(); err != nil {
+			panic(plBodyError{err})
+		}
+		stmt = sub.parseSQL(0, len(sub.tokens)-1)
+	}
+	return &tree.RoutineExec{Statement: stmt.AST, Into: into}
+}
+
+// RoutineVarRef is a reference to a variable or parameter of a routine, as
+// found by FindRoutineVarRefs.
+type RoutineVarRef struct {
+	// Name is the name of the variable, or the placeholder as written, e.g.
+	// $<n> for a reference to the n-th parameter by position.
+	Name string
+	// Start and End are the offsets of the reference in the text.
+	Start, End int
+}
+
+// FindRoutineVarRefs returns the references to the variables and parameters
+// of a routine in the given text of an expression or statement, in order.
+// isVar is called to determine whether an unqualified name is the name of a
+// variable. A name is not considered to be a reference to a variable when it
+// is qualified or used as a qualifier, when it is the name of a function in a
+// function call, or when it follows AS. Other names of variables take
+// precedence over the names of columns and tables, so variables should not be
+// named like them.
+func FindRoutineVarRefs(sql string, isVar func(string) bool) ([]RoutineVarRef, error) {
+	p := plBodyParser{sql: sql}
+	if err := p.scan(); err != nil {
+		return nil, err
+	}
+	var refs []RoutineVarRef
+	for i := 0; i+1 < len(p.tokens); i++ {
+		t := &p.tokens[i]
+		if t.id == PLACEHOLDER {
+			refs = append(refs, RoutineVarRef{Name: p.sql[t.start:t.end], Start: t.start, End: t.end})
+			continue
+		}
+		if !t.isName() || !isVar(t.str) {
+			continue
+		}
+		if i > 0 && (p.tokens[i-1].id == '.' || p.tokens[i-1].isWord("as")) {
+			continue
+		}
+		if next := p.tokens[i+1].id; next == '.' || next == '(' {
+			continue
+		}
+		refs = append(refs, RoutineVarRef{Name: t.str, Start: t.start, End: t.end})
+	}
+	return refs, nil
+}
@@ -0,0 +1,259 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseRoutineBody(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		body     string
+		expected string
+	}{
+		{`BEGIN END`, `BEGIN END`},
+		{`begin return; end;`, `BEGIN RETURN; END`},
+		{`BEGIN RETURN a + $1; END`, `BEGIN RETURN a + $1; END`},
+		{`DECLARE a INT; b CONSTANT DECIMAL(10, 2) NOT NULL DEFAULT 1.5; c STRING = 'x'; BEGIN a := b; c = a::STRING; END`,
+			`DECLARE a INT8; b CONSTANT DECIMAL(10,2) NOT NULL := 1.5; c STRING := 'x'; ` +
+				`BEGIN a := b; c := a::STRING; END`},
+		{`BEGIN
+		    WHILE a < 10 LOOP
+		      a := a + 1;
+		      CONTINUE WHEN a = 2;
+		      LOOP
+		        EXIT;
+		      END LOOP;
+		      IF a > 5 THEN EXIT WHEN b; ELSIF a < 0 THEN RAISE 'negative % (%%)', a; END IF;
+		    END LOOP;
+		  END`,
+			`BEGIN WHILE a < 10 LOOP a := a + 1; CONTINUE WHEN a = 2; LOOP EXIT; END LOOP; ` +
+				`IF a > 5 THEN EXIT WHEN b; ELSIF a < 0 THEN RAISE EXCEPTION 'negative % (%%)', a; END IF; ` +
+				`END LOOP; END`},
+		{`BEGIN DECLARE b INT := a; BEGIN RETURN b; END; END`,
+			`BEGIN DECLARE b INT8 := a; BEGIN RETURN b; END; END`},
+		{`BEGIN INSERT INTO t VALUES (a, $2); UPDATE t SET v = a WHERE k = b; END`,
+			`BEGIN INSERT INTO t VALUES (a, $2); UPDATE t SET v = a WHERE k = b; END`},
+		{`BEGIN SELECT count(*), max(v) INTO a, b FROM t WHERE k IN (SELECT k FROM u); END`,
+			`BEGIN SELECT count(*), max(v) FROM t WHERE k IN (SELECT k FROM u) INTO a, b; END`},
+		{`BEGIN INSERT INTO t VALUES (1) RETURNING k INTO a; END`,
+			`BEGIN INSERT INTO t VALUES (1) RETURNING k INTO a; END`},
+	}
+	for _, d := range testData {
+		t.Run(d.body, func(t *testing.T) {
+			body, err := parser.ParseRoutineBody(d.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := tree.AsString(body); s != d.expected {
+				t.Errorf("expected %s, got %s", d.expected, s)
+			}
+			// The formatted body must parse to the same body.
+			body2, err := parser.ParseRoutineBody(tree.AsString(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := tree.AsString(body2); s != d.expected {
+				t.Errorf("expected %s after round trip, got %s", d.expected, s)
+			}
+		})
+	}
+}
+
+func TestParseRoutineBodyError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		body     string
+		expected string
+	}{
+		{``, `syntax error in function body at or near "EOF"`},
+		{`DECLARE BEGIN END x`, `syntax error in function body at or near "x"`},
+		{`DECLARE a; BEGIN END`, `syntax error in function body at or near ";"`},
+		{`DECLARE a INT := ; BEGIN END`, `syntax error in function body at or near ";"`},
+		{`DECLARE a NOTATYPE; BEGIN END`, `type does not exist`},
+		{`BEGIN a := 1 END`, `syntax error in function body at or near "END"`},
+		{`BEGIN EXIT; END`, `EXIT cannot be used outside a loop`},
+		{`BEGIN IF true THEN CONTINUE; END IF; END`, `CONTINUE cannot be used outside a loop`},
+		{`BEGIN LOOP RETURN; END; END`, `syntax error in function body at or near ";"`},
+		{`BEGIN WHILE LOOP END LOOP; END`, `syntax error in function body at or near "LOOP"`},
+		{`BEGIN SELECT 1 INTO; END`, `syntax error in function body at or near ";"`},
+		{`BEGIN CREATE TABLE t (a INT); END`, `CREATE TABLE statements are not supported in function bodies`},
+	}
+	for _, d := range testData {
+		t.Run(d.body, func(t *testing.T) {
+			_, err := parser.ParseRoutineBody(d.body)
+			if !testutils.IsError(err, d.expected) {
+				t.Fatalf("expected %q, got %v", d.expected, err)
+			}
+		})
+	}
+}
+
+func TestFindRoutineVarRefs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	vars := map[string]bool{"a": true, "b": true, "count": true}
+	isVar := func(name string) bool { return vars[name] }
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{`SELECT 1`, ``},
+		{`a + $1 * b`, `a $1 b`},
+		{`SELECT count(*), t.a, a.x, c AS a, 'a', "b" FROM t WHERE count = $12`, `b count $12`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			refs, err := parser.FindRoutineVarRefs(d.sql, isVar)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var res []string
+			for _, ref := range refs {
+				res = append(res, ref.Name)
+				if s := strings.Trim(d.sql[ref.Start:ref.End], `"`); s != ref.Name {
+					t.Errorf("reference to %s has text %q", ref.Name, s)
+				}
+			}
+			if s := strings.Join(res, " "); s != d.expected {
+				t.Errorf("expected %q, got %q", d.expected, s)
+			}
+		})
+	}
+}
+
+func TestParseCreateFunction(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stmt, err := parser.ParseOne(
+		`CREATE FUNCTION f(a INT) RETURNS INT LANGUAGE sql AS 'UPDATE t SET v = a; SELECT count(*) FROM t'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := stmt.AST.(*tree.CreateFunction)
+	if len(fn.SQLBody) != 2 || fn.PLBody != nil {
+		t.Fatalf("unexpected body %v, %v", fn.SQLBody, fn.PLBody)
+	}
+	if s := tree.AsString(fn.SQLBody[1]); s != `SELECT count(*) FROM t` {
+		t.Errorf("unexpected statement %s", s)
+	}
+
+	stmt, err = parser.ParseOne(
+		`CREATE FUNCTION f(a INT) RETURNS INT LANGUAGE plpgsql AS 'BEGIN RETURN a + 1; END'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn = stmt.AST.(*tree.CreateFunction)
+	if fn.SQLBody != nil || fn.PLBody == nil {
+		t.Fatalf("unexpected body %v, %v", fn.SQLBody, fn.PLBody)
+	}
+	if s := tree.AsString(fn.PLBody); s != `BEGIN RETURN a + 1; END` {
+		t.Errorf("unexpected body %s", s)
+	}
+}
+
+func TestParseCreateFunctionError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE c AS 'f'`, `language "c" does not exist`},
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE sql AS ''`,
+			`function body must contain at least one statement`},
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE sql AS 'SELEC 1'`, `syntax error at or near "selec"`},
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE sql AS 'CREATE TABLE t (a INT); SELECT 1'`,
+			`CREATE TABLE statements are not supported in function bodies`},
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE sql AS 'SELECT 1; DELETE FROM t'`,
+			`the last statement of a function body must be a SELECT or have a RETURNING clause`},
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE sql AS 'INSERT INTO t VALUES (1) RETURNING NOTHING'`,
+			`the last statement of a function body must be a SELECT or have a RETURNING clause`},
+		{`CREATE FUNCTION f() RETURNS INT LANGUAGE plpgsql AS 'BEGIN RETURN 1 END'`,
+			`syntax error in function body at or near "END"`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			_, err := parser.ParseOne(d.sql)
+			if !testutils.IsError(err, d.expected) {
+				t.Fatalf("expected %q, got %v", d.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseProcedureBody(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	block, err := parser.ParseProcedureBody(tree.FunctionLangSQL, `INSERT INTO t VALUES (a); CALL p(a)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := tree.AsString(block); s != `BEGIN INSERT INTO t VALUES (a); CALL p(a); END` {
+		t.Errorf("unexpected body %s", s)
+	}
+
+	block, err = parser.ParseProcedureBody(tree.FunctionLangSQL, ``)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(block.Stmts) != 0 {
+		t.Errorf("expected an empty body, got %s", tree.AsString(block))
+	}
+
+	block, err = parser.ParseProcedureBody(tree.FunctionLangPLpgSQL, `BEGIN IF a THEN RETURN; END IF; END`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := tree.AsString(block); s != `BEGIN IF a THEN RETURN; END IF; END` {
+		t.Errorf("unexpected body %s", s)
+	}
+}
+
+func TestParseCreateProcedureError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{`CREATE PROCEDURE p() LANGUAGE c AS 'p'`, `language "c" does not exist`},
+		{`CREATE PROCEDURE p() LANGUAGE sql AS 'COMMIT'`,
+			`COMMIT statements are not supported in procedure bodies`},
+		{`CREATE PROCEDURE p() LANGUAGE plpgsql AS 'BEGIN CREATE TABLE t (a INT); END'`,
+			`CREATE TABLE statements are not supported in procedure bodies`},
+		{`CREATE PROCEDURE p() LANGUAGE plpgsql AS 'BEGIN LOOP IF a THEN RETURN 1; END IF; END LOOP; END'`,
+			`RETURN cannot have a value in a procedure`},
+		{`CREATE PROCEDURE p() LANGUAGE plpgsql AS 'BEGIN END END'`,
+			`syntax error in procedure body at or near "END"`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			_, err := parser.ParseOne(d.sql)
+			if !testutils.IsError(err, d.expected) {
+				t.Fatalf("expected %q, got %v", d.expected, err)
+			}
+		})
+	}
+}
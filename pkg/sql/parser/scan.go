@@ -18,18 +18,31 @@ import (
 	"fmt"
 	"go/constant"
 	"go/token"
-	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 	"unsafe"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/pkg/errors"
 )
 
 const eof = -1
 const errUnterminated = "unterminated string"
+const errUnterminatedDollarQuote = "unterminated dollar-quoted string"
 const errInvalidUTF8 = "invalid UTF-8 byte sequence"
+const errNULCharacter = "invalid NUL character"
 const errInvalidHexNumeric = "invalid hexadecimal numeric literal"
+const errMixedPlaceholders = "cannot mix named and positional placeholders in a statement"
+const errInvalidEscapedUnicode = `invalid Unicode escape: must be \uXXXX or \UXXXXXXXX`
+const errInvalidUnicodeEscape = `invalid Unicode escape: must be %cXXXX or %c+XXXXXX`
+const errInvalidUnicodeValue = "invalid Unicode escape value"
+const errInvalidSurrogatePair = "invalid Unicode surrogate pair"
+const errInvalidUnicodeEscapeChar = "invalid Unicode escape character"
+const errInvalidUEscape = "UESCAPE must be followed by a simple string literal"
 const singleQuote = '\''
 const identQuote = '"'
 
@@ -38,8 +51,49 @@ type scanner struct {
 	in            string
 	pos           int
 	bytesPrealloc []byte
+	// retainComments, if set, causes the comments to be appended to
+	// comments as they are scanned.
+	retainComments bool
+	comments       []string
+	// hints are the contents of the /*+ ... */ hint comments, without their
+	// delimiters. They are recorded regardless of retainComments.
+	hints []string
+
+	// lastID is the ID of the last scanned token.
+	lastID int32
+	// brackets has an element for each of the brackets and parentheses
+	// which are open at the current position. The element is set for the
+	// brackets of array subscripts, in which ':' separates the bounds of a
+	// slice rather than starting a named placeholder.
+	brackets []bool
+	// namedPlaceholders maps the names of the named placeholders of the
+	// current statement to their index, which is assigned in the order of
+	// their first occurrence. positionalPlaceholders is set if the current
+	// statement has $n placeholders; the two kinds cannot be mixed.
+	namedPlaceholders      map[string]types.PlaceholderIdx
+	positionalPlaceholders bool
+	// questionMarkPlaceholders, if set, causes `?` to be scanned as a
+	// placeholder, as in JDBC. numQuestionMarks is the number of `?`
+	// placeholders of the current statement so far.
+	questionMarkPlaceholders bool
+	numQuestionMarks         int
+	// truncateIdentifiers, if set, causes the identifiers longer than
+	// maxIdentifierLength to be truncated, as in Postgres. A notice is
+	// appended to notices for each truncated identifier.
+	truncateIdentifiers bool
+	notices             []string
+	// mysqlSyntax, if set, causes the scanner to accept the lexical syntax
+	// of MySQL: `backtick` quoted identifiers, "double-quoted" strings,
+	// backslash escapes in strings and # line comments. # is then not
+	// available as an operator.
+	mysqlSyntax bool
 }
 
+// maxIdentifierLength is the length in bytes above which identifiers are
+// truncated when scanner.truncateIdentifiers is set. It matches
+// NAMEDATALEN-1 in Postgres.
+const maxIdentifierLength = 63
+
 func makeScanner(str string) scanner {
 	var s scanner
 	s.init(str)
@@ -49,6 +103,16 @@ func makeScanner(str string) scanner {
 func (s *scanner) init(str string) {
 	s.in = str
 	s.pos = 0
+	s.retainComments = false
+	s.questionMarkPlaceholders = false
+	s.truncateIdentifiers = false
+	s.mysqlSyntax = false
+	s.comments = nil
+	s.notices = nil
+	s.hints = nil
+	s.lastID = 0
+	s.brackets = s.brackets[:0]
+	s.resetPlaceholders()
 	// Preallocate some buffer space for identifiers etc.
 	s.bytesPrealloc = make([]byte, len(str))
 }
@@ -57,6 +121,7 @@ func (s *scanner) init(str string) {
 // where we reuse a scanner).
 func (s *scanner) cleanup() {
 	s.bytesPrealloc = nil
+	s.resetPlaceholders()
 }
 
 func (s *scanner) allocBytes(length int) []byte {
@@ -92,7 +157,50 @@ func (s *scanner) finishString(buf []byte) string {
 	return str
 }
 
+// scan scans the next token.
 func (s *scanner) scan(lval *sqlSymType) {
+	s.scanToken(lval)
+	switch lval.id {
+	case IDENT:
+		if s.truncateIdentifiers && len(lval.str) > maxIdentifierLength {
+			truncated := truncateIdentifier(lval.str)
+			s.notices = append(s.notices,
+				fmt.Sprintf("identifier %q will be truncated to %q", lval.str, truncated))
+			lval.str = truncated
+		}
+	case '[':
+		s.brackets = append(s.brackets, s.lastID != ARRAY)
+	case '(':
+		s.brackets = append(s.brackets, false)
+	case ']', ')':
+		if n := len(s.brackets); n > 0 {
+			s.brackets = s.brackets[:n-1]
+		}
+	case ';':
+		s.brackets = s.brackets[:0]
+		s.resetPlaceholders()
+	}
+	s.lastID = lval.id
+}
+
+// truncateIdentifier truncates an identifier to maxIdentifierLength bytes,
+// without splitting a multi-byte character.
+func truncateIdentifier(ident string) string {
+	n := maxIdentifierLength
+	for n > 0 && !utf8.RuneStart(ident[n]) {
+		n--
+	}
+	return ident[:n]
+}
+
+// resetPlaceholders forgets the placeholders of the current statement.
+func (s *scanner) resetPlaceholders() {
+	s.namedPlaceholders = nil
+	s.positionalPlaceholders = false
+	s.numQuestionMarks = 0
+}
+
+func (s *scanner) scanToken(lval *sqlSymType) {
 	lval.id = 0
 	lval.pos = int32(s.pos)
 	lval.str = "EOF"
@@ -112,15 +220,31 @@ func (s *scanner) scan(lval *sqlSymType) {
 	lval.str = s.in[lval.pos:s.pos]
 
 	switch ch {
+	case 0:
+		// A NUL would otherwise be mistaken for the end of the input, and the
+		// rest of the input would be ignored.
+		lval.id = ERROR
+		lval.str = errNULCharacter
+		return
+
 	case '$':
 		// placeholder? $[0-9]+
 		if lex.IsDigit(s.peek()) {
 			s.scanPlaceholder(lval)
 			return
 		}
+		// dollar-quoted string? $tag$...$tag$
+		s.scanDollarQuotedString(lval)
 		return
 
 	case identQuote:
+		if s.mysqlSyntax {
+			// MySQL string: "[^"]"
+			if s.scanString(lval, identQuote, true /* allowEscapes */, true /* requireUTF8 */) {
+				lval.id = SCONST
+			}
+			return
+		}
 		// "[^"]"
 		if s.scanString(lval, identQuote, false /* allowEscapes */, true /* requireUTF8 */) {
 			lval.id = IDENT
@@ -129,11 +253,20 @@ func (s *scanner) scan(lval *sqlSymType) {
 
 	case singleQuote:
 		// '[^']'
-		if s.scanString(lval, ch, false /* allowEscapes */, true /* requireUTF8 */) {
+		if s.scanString(lval, ch, s.mysqlSyntax /* allowEscapes */, true /* requireUTF8 */) {
 			lval.id = SCONST
 		}
 		return
 
+	case '`':
+		// MySQL identifier: `[^`]`
+		if s.mysqlSyntax {
+			if s.scanString(lval, ch, false /* allowEscapes */, true /* requireUTF8 */) {
+				lval.id = IDENT
+			}
+		}
+		return
+
 	case 'b':
 		// Bytes?
 		if s.peek() == singleQuote {
@@ -164,6 +297,17 @@ func (s *scanner) scan(lval *sqlSymType) {
 		s.scanIdent(lval)
 		return
 
+	case 'u', 'U':
+		// Unicode escaped string or identifier?
+		if s.peek() == '&' && (s.peekN(1) == singleQuote || s.peekN(1) == identQuote) {
+			// [uU]&'[^']' or [uU]&"[^"]"
+			s.pos++
+			s.scanUnicodeEscapeString(lval, s.next())
+			return
+		}
+		s.scanIdent(lval)
+		return
+
 	case 'B':
 		// Bit array literal?
 		if s.peek() == singleQuote {
@@ -218,6 +362,10 @@ func (s *scanner) scan(lval *sqlSymType) {
 		return
 
 	case '?':
+		if s.questionMarkPlaceholders {
+			s.scanQuestionMark(lval)
+			return
+		}
 		switch s.peek() {
 		case '?': // ??
 			s.pos++
@@ -293,6 +441,13 @@ func (s *scanner) scan(lval *sqlSymType) {
 			lval.id = TYPECAST
 			return
 		}
+		// Named placeholder? :name
+		if lex.IsIdentStart(s.peek()) {
+			if n := len(s.brackets); n == 0 || !s.brackets[n-1] {
+				s.scanNamedPlaceholder(lval)
+				return
+			}
+		}
 		return
 
 	case '|':
@@ -307,6 +462,10 @@ func (s *scanner) scan(lval *sqlSymType) {
 	case '/':
 		switch s.peek() {
 		case '/': // //
+			if s.peekN(1) == '*' {
+				// The second '/' starts a comment: /(/*...*/).
+				return
+			}
 			s.pos++
 			lval.id = FLOORDIV
 			return
@@ -368,6 +527,10 @@ func (s *scanner) scan(lval *sqlSymType) {
 			lval.id = FETCHVAL_PATH
 			return
 		case '-': // #-
+			if s.peekN(1) == '-' {
+				// The '-' starts a comment: #(--...).
+				return
+			}
 			s.pos++
 			lval.id = REMOVE_PATH
 			return
@@ -426,9 +589,16 @@ func (s *scanner) skipWhitespace(lval *sqlSymType, allowComments bool) (newline,
 			continue
 		}
 		if allowComments {
+			start := s.pos
 			if present, cok := s.scanComment(lval); !cok {
 				return false, false
 			} else if present {
+				if s.retainComments {
+					s.comments = append(s.comments, strings.TrimSuffix(s.in[start:s.pos], "\n"))
+				}
+				if strings.HasPrefix(s.in[start:s.pos], "/*+") {
+					s.hints = append(s.hints, s.in[start+len("/*+"):s.pos-len("*/")])
+				}
 				continue
 			}
 		}
@@ -437,10 +607,23 @@ func (s *scanner) skipWhitespace(lval *sqlSymType, allowComments bool) (newline,
 	return newline, true
 }
 
+// scanComment scans a -- or /* */ comment, if any. As in Postgres, /* */
+// comments nest, and an unterminated comment is reported at its start. With
+// mysqlSyntax, # also starts a comment which extends to the end of the line.
 func (s *scanner) scanComment(lval *sqlSymType) (present, ok bool) {
 	start := s.pos
 	ch := s.peek()
 
+	if ch == '#' && s.mysqlSyntax {
+		s.pos++
+		for {
+			switch s.next() {
+			case eof, '\n':
+				return true, true
+			}
+		}
+	}
+
 	if ch == '/' {
 		s.pos++
 		if s.peek() != '*' {
@@ -494,6 +677,43 @@ func (s *scanner) scanComment(lval *sqlSymType) (present, ok bool) {
 	return false, true
 }
 
+// scanDollarQuotedString scans a dollar-quoted string literal, whose
+// opening '$' has been consumed: $$...$$ or $tag$...$tag$, where the tag
+// follows the rules of identifiers but cannot contain '$'. The contents of
+// the string are taken literally, up to the first occurrence of the opening
+// delimiter. If the input does not start a dollar-quoted string here, the
+// lone '$' is left as the token.
+func (s *scanner) scanDollarQuotedString(lval *sqlSymType) {
+	start := s.pos - 1
+	pos := s.pos
+	if pos < len(s.in) && lex.IsIdentStart(int(s.in[pos])) {
+		pos++
+		for pos < len(s.in) && (lex.IsIdentStart(int(s.in[pos])) || lex.IsDigit(int(s.in[pos]))) {
+			pos++
+		}
+	}
+	if pos >= len(s.in) || s.in[pos] != '$' {
+		return
+	}
+	delim := s.in[start : pos+1]
+	bodyStart := pos + 1
+	n := strings.Index(s.in[bodyStart:], delim)
+	if n < 0 {
+		s.pos = len(s.in)
+		lval.id = ERROR
+		lval.str = errUnterminatedDollarQuote
+		return
+	}
+	s.pos = bodyStart + n + len(delim)
+	lval.str = s.in[bodyStart : bodyStart+n]
+	if !utf8.ValidString(lval.str) {
+		lval.id = ERROR
+		lval.str = errInvalidUTF8
+		return
+	}
+	lval.id = SCONST
+}
+
 func (s *scanner) scanIdent(lval *sqlSymType) {
 	s.pos--
 	start := s.pos
@@ -524,6 +744,12 @@ func (s *scanner) scanIdent(lval *sqlSymType) {
 	}
 	//fmt.Println("parsed: ", s.in[start:s.pos], isASCII, isLower)
 
+	if !isASCII && !utf8.ValidString(s.in[start:s.pos]) {
+		lval.id = ERROR
+		lval.str = errInvalidUTF8
+		return
+	}
+
 	if isLower {
 		// Already lowercased - nothing to do.
 		lval.str = s.in[start:s.pos]
@@ -652,16 +878,90 @@ func (s *scanner) scanPlaceholder(lval *sqlSymType) {
 	}
 	lval.str = s.in[start:s.pos]
 
+	if s.namedPlaceholders != nil || s.numQuestionMarks > 0 {
+		lval.id = ERROR
+		lval.str = errMixedPlaceholders
+		return
+	}
 	placeholder, err := tree.NewPlaceholder(lval.str)
 	if err != nil {
 		lval.id = ERROR
 		lval.str = err.Error()
 		return
 	}
+	s.positionalPlaceholders = true
 	lval.id = PLACEHOLDER
 	lval.union.val = placeholder
 }
 
+// scanNamedPlaceholder scans a :name placeholder. The index of the
+// placeholder is the one of the first occurrence of the name in the
+// statement, or the next index if this is the first occurrence. The token
+// string is the name, without the colon.
+func (s *scanner) scanNamedPlaceholder(lval *sqlSymType) {
+	start := s.pos
+	for lex.IsIdentMiddle(s.peek()) {
+		s.pos++
+	}
+	lval.str = s.in[start:s.pos]
+
+	if s.positionalPlaceholders || s.numQuestionMarks > 0 {
+		lval.id = ERROR
+		lval.str = errMixedPlaceholders
+		return
+	}
+	idx, ok := s.namedPlaceholders[lval.str]
+	if !ok {
+		if len(s.namedPlaceholders) > types.MaxPlaceholderIdx {
+			lval.id = ERROR
+			lval.str = fmt.Sprintf("a statement can have at most %d placeholders", types.MaxPlaceholderIdx+1)
+			return
+		}
+		if s.namedPlaceholders == nil {
+			s.namedPlaceholders = make(map[string]types.PlaceholderIdx)
+		}
+		idx = types.PlaceholderIdx(len(s.namedPlaceholders))
+		s.namedPlaceholders[lval.str] = idx
+	}
+	lval.id = PLACEHOLDER
+	lval.union.val = &tree.Placeholder{Idx: idx}
+}
+
+// scanQuestionMark scans a `?` when questionMarkPlaceholders is set. A
+// single `?` is a placeholder, numbered after the previous `?` placeholders
+// of the statement: the first one is $1. As in JDBC, the `?` operators are
+// escaped by doubling the question mark: `??` is the `?` operator, and `??|`
+// and `??&` are the `?|` and `?&` operators.
+func (s *scanner) scanQuestionMark(lval *sqlSymType) {
+	if s.peek() == '?' {
+		s.pos++
+		switch s.peek() {
+		case '|': // ??|
+			s.pos++
+			lval.id = JSON_SOME_EXISTS
+		case '&': // ??&
+			s.pos++
+			lval.id = JSON_ALL_EXISTS
+		}
+		return
+	}
+
+	lval.str = "?"
+	if s.namedPlaceholders != nil || s.positionalPlaceholders {
+		lval.id = ERROR
+		lval.str = errMixedPlaceholders
+		return
+	}
+	if s.numQuestionMarks > types.MaxPlaceholderIdx {
+		lval.id = ERROR
+		lval.str = fmt.Sprintf("a statement can have at most %d placeholders", types.MaxPlaceholderIdx+1)
+		return
+	}
+	lval.id = PLACEHOLDER
+	lval.union.val = &tree.Placeholder{Idx: types.PlaceholderIdx(s.numQuestionMarks)}
+	s.numQuestionMarks++
+}
+
 // scanHexString scans the content inside x'....'.
 func (s *scanner) scanHexString(lval *sqlSymType, ch int) bool {
 	buf := s.buffer()
@@ -674,6 +974,7 @@ outer:
 		b := s.next()
 		switch b {
 		case ch:
+			end := s.pos
 			newline, ok := s.skipWhitespace(lval, false)
 			if !ok {
 				return false
@@ -686,6 +987,8 @@ outer:
 				s.pos++
 				continue
 			}
+			// The whitespace is not part of the token.
+			s.pos = end
 			break outer
 
 		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
@@ -727,6 +1030,7 @@ outer:
 		b := s.next()
 		switch b {
 		case ch:
+			end := s.pos
 			newline, ok := s.skipWhitespace(lval, false)
 			if !ok {
 				return false
@@ -739,6 +1043,8 @@ outer:
 				s.pos++
 				continue
 			}
+			// The whitespace is not part of the token.
+			s.pos = end
 			break outer
 
 		case '0', '1':
@@ -760,7 +1066,6 @@ outer:
 // scanHexString().
 func (s *scanner) scanString(lval *sqlSymType, ch int, allowEscapes, requireUTF8 bool) bool {
 	buf := s.buffer()
-	var runeTmp [utf8.UTFMax]byte
 	start := s.pos
 
 outer:
@@ -776,6 +1081,7 @@ outer:
 				continue
 			}
 
+			end := s.pos
 			newline, ok := s.skipWhitespace(lval, false)
 			if !ok {
 				return false
@@ -789,6 +1095,8 @@ outer:
 				start = s.pos
 				continue
 			}
+			// The whitespace is not part of the token.
+			s.pos = end
 			break outer
 
 		case '\\':
@@ -802,30 +1110,14 @@ outer:
 					continue
 				}
 
-				switch t {
-				case 'a', 'b', 'f', 'n', 'r', 't', 'v', 'x', 'X', 'u', 'U', '\\',
-					'0', '1', '2', '3', '4', '5', '6', '7':
-					var tmp string
-					if t == 'X' && len(s.in[s.pos:]) >= 3 {
-						// UnquoteChar doesn't handle 'X' so we create a temporary string
-						// for it to parse.
-						tmp = "\\x" + s.in[s.pos+1:s.pos+3]
-					} else {
-						tmp = s.in[s.pos-1:]
-					}
-					v, multibyte, tail, err := strconv.UnquoteChar(tmp, byte(ch))
-					if err != nil {
-						lval.id = ERROR
-						lval.str = err.Error()
-						return false
-					}
-					if v < utf8.RuneSelf || !multibyte {
-						buf = append(buf, byte(v))
-					} else {
-						n := utf8.EncodeRune(runeTmp[:], v)
-						buf = append(buf, runeTmp[:n]...)
-					}
-					s.pos += len(tmp) - len(tail) - 1
+				var n int
+				var err error
+				if buf, n, err = appendEscape(buf, s.in[s.pos:]); err != nil {
+					lval.id = ERROR
+					lval.str = err.Error()
+					return false
+				} else if n > 0 {
+					s.pos += n
 					start = s.pos
 					continue
 				}
@@ -854,6 +1146,262 @@ outer:
 	return true
 }
 
+// scanUnicodeEscapeString scans a U&'...' string or a U&"..." identifier,
+// followed by an optional UESCAPE clause, and decodes its Unicode escapes.
+func (s *scanner) scanUnicodeEscapeString(lval *sqlSymType, ch int) {
+	if !s.scanString(lval, ch, false /* allowEscapes */, true /* requireUTF8 */) {
+		return
+	}
+	str := lval.str
+	escape, ok := s.scanUEscape(lval)
+	if !ok {
+		return
+	}
+	str, err := decodeUnicodeEscapes(str, escape)
+	if err != nil {
+		lval.id = ERROR
+		lval.str = err.Error()
+		return
+	}
+	lval.str = str
+	if ch == identQuote {
+		lval.id = IDENT
+	} else {
+		lval.id = SCONST
+	}
+}
+
+// scanUEscape scans the UESCAPE 'c' clause which may follow a Unicode
+// escaped string or identifier, and returns the escape character: c, or a
+// backslash if there is no UESCAPE clause. UESCAPE is not a keyword, as it
+// is only recognized by the scanner right after such strings.
+func (s *scanner) scanUEscape(lval *sqlSymType) (escape byte, ok bool) {
+	const uescape = "uescape"
+	pos := s.pos
+	if _, ok := s.skipWhitespace(lval, false /* allowComments */); !ok {
+		return 0, false
+	}
+	if len(s.in)-s.pos < len(uescape) || !strings.EqualFold(s.in[s.pos:s.pos+len(uescape)], uescape) ||
+		lex.IsIdentMiddle(s.peekN(len(uescape))) {
+		s.pos = pos
+		return '\\', true
+	}
+	s.pos += len(uescape)
+	if _, ok := s.skipWhitespace(lval, false /* allowComments */); !ok {
+		return 0, false
+	}
+	if s.peek() != singleQuote || s.peekN(2) != singleQuote {
+		lval.id = ERROR
+		lval.str = errInvalidUEscape
+		return 0, false
+	}
+	escape = s.in[s.pos+1]
+	s.pos += 3
+	switch {
+	case lex.IsHexDigit(int(escape)), escape == '+', escape == singleQuote, escape == identQuote,
+		escape == ' ', escape == '\t', escape == '\n', escape == '\r', escape == '\f',
+		escape >= utf8.RuneSelf:
+		lval.id = ERROR
+		lval.str = errInvalidUnicodeEscapeChar
+		return 0, false
+	}
+	return escape, true
+}
+
+// decodeUnicodeEscapes decodes the escapes of a Unicode escaped string or
+// identifier: the escape character followed by 4 hexadecimal digits, or by
+// + and 6 hexadecimal digits, is a code point, and the escape character
+// twice is the escape character itself. The UTF-16 surrogate pairs written
+// as two consecutive escapes are combined.
+func decodeUnicodeEscapes(in string, escape byte) (string, error) {
+	if strings.IndexByte(in, escape) < 0 {
+		return in, nil
+	}
+	// decode decodes the escape at the start of in, without its escape
+	// character.
+	decode := func(in string) (rune, int, error) {
+		n := 4
+		if len(in) > 0 && in[0] == '+' {
+			in = in[1:]
+			n = 6
+		}
+		if len(in) < n {
+			return 0, 0, errors.Errorf(errInvalidUnicodeEscape, escape, escape)
+		}
+		v, ok := decodeHexCodePoint(in[:n])
+		if !ok {
+			return 0, 0, errors.Errorf(errInvalidUnicodeEscape, escape, escape)
+		}
+		if v == 0 || v > unicode.MaxRune {
+			return 0, 0, errors.New(errInvalidUnicodeValue)
+		}
+		if n == 6 {
+			n++
+		}
+		return rune(v), n, nil
+	}
+
+	buf := make([]byte, 0, len(in))
+	for i := 0; i < len(in); {
+		if in[i] != escape {
+			buf = append(buf, in[i])
+			i++
+			continue
+		}
+		i++
+		if i < len(in) && in[i] == escape {
+			buf = append(buf, escape)
+			i++
+			continue
+		}
+		r, n, err := decode(in[i:])
+		if err != nil {
+			return "", err
+		}
+		i += n
+		if utf16.IsSurrogate(r) {
+			// A high surrogate must be followed by a low one.
+			if r >= 0xDC00 || i >= len(in) || in[i] != escape {
+				return "", errors.New(errInvalidSurrogatePair)
+			}
+			low, n, err := decode(in[i+1:])
+			if err != nil {
+				return "", err
+			}
+			if r = utf16.DecodeRune(r, low); r == utf8.RuneError {
+				return "", errors.New(errInvalidSurrogatePair)
+			}
+			i += 1 + n
+		}
+		buf = appendRune(buf, r)
+	}
+	return string(buf), nil
+}
+
+// appendEscape decodes the escape sequence at the start of in, which
+// follows a backslash in an escaped string, and appends the result to buf.
+// It returns the number of bytes of in making up the escape sequence, or 0
+// if the escape is redundant, i.e. if the backslash is simply dropped.
+//
+// The escape sequences are the ones of Postgres: \b, \f, \n, \r and \t,
+// the octal byte values \o, \oo and \ooo, the hexadecimal byte values \xh and
+// \xhh, and the Unicode code points \uXXXX and \UXXXXXXXX, where the UTF-16
+// surrogate pairs written as two consecutive \u escapes are combined.
+func appendEscape(buf []byte, in string) ([]byte, int, error) {
+	if len(in) == 0 {
+		return buf, 0, nil
+	}
+	switch in[0] {
+	case '\\':
+		return append(buf, '\\'), 1, nil
+	case 'b':
+		return append(buf, '\b'), 1, nil
+	case 'f':
+		return append(buf, '\f'), 1, nil
+	case 'n':
+		return append(buf, '\n'), 1, nil
+	case 'r':
+		return append(buf, '\r'), 1, nil
+	case 't':
+		return append(buf, '\t'), 1, nil
+
+	case '0', '1', '2', '3', '4', '5', '6', '7':
+		// Postgres only keeps the low 8 bits of values above \377.
+		var v byte
+		n := 0
+		for ; n < 3 && n < len(in) && in[n] >= '0' && in[n] <= '7'; n++ {
+			v = v<<3 | (in[n] - '0')
+		}
+		return append(buf, v), n, nil
+
+	case 'x':
+		var v byte
+		n := 1
+		for ; n < 3 && n < len(in) && lex.IsHexDigit(int(in[n])); n++ {
+			v = v<<4 | unhex(in[n])
+		}
+		if n == 1 {
+			// \x without any hexadecimal digit is a redundant escape.
+			return buf, 0, nil
+		}
+		return append(buf, v), n, nil
+
+	case 'u', 'U':
+		r, n, err := decodeEscapedCodePoint(in)
+		if err != nil {
+			return buf, 0, err
+		}
+		if utf16.IsSurrogate(r) {
+			// A high surrogate must be followed by a low one.
+			if r >= 0xDC00 || len(in) <= n+1 || in[n] != '\\' || (in[n+1] != 'u' && in[n+1] != 'U') {
+				return buf, 0, errors.New(errInvalidSurrogatePair)
+			}
+			low, m, err := decodeEscapedCodePoint(in[n+1:])
+			if err != nil {
+				return buf, 0, err
+			}
+			if r = utf16.DecodeRune(r, low); r == utf8.RuneError {
+				return buf, 0, errors.New(errInvalidSurrogatePair)
+			}
+			n += 1 + m
+		}
+		return appendRune(buf, r), n, nil
+	}
+	return buf, 0, nil
+}
+
+// decodeEscapedCodePoint decodes the \uXXXX or \UXXXXXXXX escape at the start
+// of in, without its backslash. It returns the code point, which may be a
+// UTF-16 surrogate, and the number of bytes of the escape.
+func decodeEscapedCodePoint(in string) (rune, int, error) {
+	n := 5
+	if in[0] == 'U' {
+		n = 9
+	}
+	if len(in) < n {
+		return 0, 0, errors.New(errInvalidEscapedUnicode)
+	}
+	v, ok := decodeHexCodePoint(in[1:n])
+	if !ok {
+		return 0, 0, errors.New(errInvalidEscapedUnicode)
+	}
+	if v == 0 || v > unicode.MaxRune {
+		return 0, 0, errors.New(errInvalidUnicodeValue)
+	}
+	return rune(v), n, nil
+}
+
+// decodeHexCodePoint decodes a code point written with up to 8 hexadecimal
+// digits.
+func decodeHexCodePoint(digits string) (uint32, bool) {
+	var v uint32
+	for i := 0; i < len(digits); i++ {
+		if !lex.IsHexDigit(int(digits[i])) {
+			return 0, false
+		}
+		v = v<<4 | uint32(unhex(digits[i]))
+	}
+	return v, true
+}
+
+// unhex returns the value of a hexadecimal digit.
+func unhex(c byte) byte {
+	switch {
+	case c >= 'a':
+		return c - 'a' + 10
+	case c >= 'A':
+		return c - 'A' + 10
+	}
+	return c - '0'
+}
+
+// appendRune appends the UTF-8 encoding of r to buf.
+func appendRune(buf []byte, r rune) []byte {
+	var tmp [utf8.UTFMax]byte
+	n := utf8.EncodeRune(tmp[:], r)
+	return append(buf, tmp[:n]...)
+}
+
 // SplitFirstStatement returns the length of the prefix of the string up to and
 // including the first semicolon that separates statements. If there is no
 // semicolon, returns ok=false.
@@ -62,6 +62,8 @@ func TestScanner(t *testing.T) {
 		{`*`, []int{'*'}},
 		{`/`, []int{'/'}},
 		{`//`, []int{FLOORDIV}},
+		{`///**/`, []int{FLOORDIV}},
+		{`a//*b*/c`, []int{IDENT, '/', IDENT}},
 		{`%`, []int{'%'}},
 		{`^`, []int{'^'}},
 		{`$`, []int{'$'}},
@@ -70,12 +72,20 @@ func TestScanner(t *testing.T) {
 		{`|`, []int{'|'}},
 		{`||`, []int{CONCAT}},
 		{`#`, []int{'#'}},
+		{`#-`, []int{REMOVE_PATH}},
+		{`#--`, []int{'#'}},
+		{`#- -`, []int{REMOVE_PATH, '-'}},
 		{`~`, []int{'~'}},
 		{`!~`, []int{NOT_REGMATCH}},
 		{`~*`, []int{REGIMATCH}},
 		{`!~*`, []int{NOT_REGIMATCH}},
 		{`$1`, []int{PLACEHOLDER}},
 		{`$a`, []int{'$', IDENT}},
+		{`$$a$$`, []int{SCONST}},
+		{`$a$a$a$`, []int{SCONST}},
+		{`$$a$$ $b$b$b$`, []int{SCONST, SCONST}},
+		{`a$$b$$`, []int{IDENT}},
+		{`$1$$a$$`, []int{PLACEHOLDER, SCONST}},
 		{`a`, []int{IDENT}},
 		{`foo + bar`, []int{IDENT, '+', IDENT}},
 		{`select a from b`, []int{SELECT, IDENT, FROM, IDENT}},
@@ -86,6 +96,14 @@ func TestScanner(t *testing.T) {
 		{`B'10101'`, []int{BITCONST}},
 		{`e'a'`, []int{SCONST}},
 		{`E'a'`, []int{SCONST}},
+		{`U&'a'`, []int{SCONST}},
+		{`u&"a"`, []int{IDENT}},
+		{`U&'a' UESCAPE '!'`, []int{SCONST}},
+		{`U&'a' uescape`, []int{ERROR}},
+		{`U&'a' uescaped`, []int{SCONST, IDENT}},
+		{`U&'a' 'b'`, []int{SCONST, SCONST}},
+		{`U & a`, []int{IDENT, '&', IDENT}},
+		{`u&a`, []int{IDENT, '&', IDENT}},
 		{`NOT`, []int{NOT}},
 		{`NOT BETWEEN`, []int{NOT, BETWEEN}},
 		{`NOT IN`, []int{NOT, IN}},
@@ -135,9 +153,18 @@ func TestScanComment(t *testing.T) {
 comment */`, "", ""},
 		{`-- hello world
 foo`, "", "foo"},
+		{`/**/world`, "", "world"},
+		{`/***/world`, "", "world"},
+		{`/* ' -- " */world`, "", "world"},
+		{`/* -- */`, "", ""},
+		{`/* */ */`, "", " */"},
+		{strings.Repeat(`/*`, 10000) + strings.Repeat(`*/`, 10000) + `world`, "", "world"},
 		{`/*`, "unterminated comment", ""},
 		{`/*/`, "unterminated comment", ""},
 		{`/* /* */`, "unterminated comment", ""},
+		{`/*/**/`, "unterminated comment", ""},
+		{"/* --\n", "unterminated comment", ""},
+		{strings.Repeat(`/*`, 10000) + strings.Repeat(`*/`, 9999), "unterminated comment", ""},
 	}
 	for i, d := range testData {
 		s := makeScanner(d.sql)
@@ -259,27 +286,71 @@ func TestScanString(t *testing.T) {
 		{`e'\"'`, `"`}, // redundant escape
 		{"'\n\\'", "\n\\"},
 		{`e'foo\"\'\\\a\b\f\n\r\t\vbar'`,
-			strings.Join([]string{`foo"'\`, "\a\b\f\n\r\t\v", `bar`}, "")},
+			strings.Join([]string{`foo"'\`, "a\b\f\n\r\tv", `bar`}, "")},
 		{`e'\\0'`, `\0`},
 		{`'\0'`, `\0`},
-		{`e'\x'`, `invalid syntax`},
-		{`e'\x4'`, `invalid syntax`},
-		{`e'\xg'`, `invalid syntax`},
-		{`e'\X4'`, `invalid syntax`},
+		{`e'\x'`, `x`},
+		{`e'\x4'`, "\x04"},
+		{`e'\x4g'`, "\x04g"},
+		{`e'\xg'`, `xg`},
+		{`e'\X41'`, `X41`},
 		{`e'\x41'`, `A`},
-		{`e'\X41B'`, `AB`},
-		{`e'\0'`, `invalid syntax`},
-		{`e'\00'`, `invalid syntax`},
-		{`e'\009'`, `invalid syntax`},
+		{`e'\x41B'`, `AB`},
+		{`e'\0'`, "\x00"},
+		{`e'\00'`, "\x00"},
+		{`e'\009'`, "\x009"},
+		{`e'\7'`, "\x07"},
 		{`e'\101'`, `A`},
 		{`e'\101B'`, `AB`},
+		{`e'\1011'`, `A1`},
 		{`e'\xff'`, `invalid UTF-8 byte sequence`},
-		{`e'\u1'`, `invalid syntax`},
-		{`e'\U123'`, `invalid syntax`},
+		{`e'\777'`, `invalid UTF-8 byte sequence`},
+		{`e'\u1'`, `invalid Unicode escape: must be \uXXXX or \UXXXXXXXX`},
+		{`e'\U123'`, `invalid Unicode escape: must be \uXXXX or \UXXXXXXXX`},
+		{`e'\u00g1'`, `invalid Unicode escape: must be \uXXXX or \UXXXXXXXX`},
 		{`e'\u0041'`, `A`},
 		{`e'\u0041B'`, `AB`},
+		{`e'\u00e9'`, `é`},
 		{`e'\U00000041'`, `A`},
 		{`e'\U00000041B'`, `AB`},
+		{`e'\U0001F600'`, `😀`},
+		{`e'\uD83D\uDE00'`, `😀`},
+		{`e'\uD83D\U0000DE00'`, `😀`},
+		{`e'\uD83D'`, `invalid Unicode surrogate pair`},
+		{`e'\uD83Dx'`, `invalid Unicode surrogate pair`},
+		{`e'\uDE00\uD83D'`, `invalid Unicode surrogate pair`},
+		{`e'\uD83D\u0041'`, `invalid Unicode surrogate pair`},
+		{`e'\u0000'`, `invalid Unicode escape value`},
+		{`e'\U00110000'`, `invalid Unicode escape value`},
+		{`e'\UFFFFFFFF'`, `invalid Unicode escape value`},
+		{`b'\777\x4'`, "\xff\x04"},
+		{`U&'d\0061t\+000061'`, `data`},
+		{`u&'\0041\\\00e9'`, `A\é`},
+		{`U&"d\0061t\+000061"`, `data`},
+		{`U&'\D83D\DE00'`, `😀`},
+		{`U&'\+01F600'`, `😀`},
+		{`U&'a''b'`, `a'b`},
+		{`U&'a'
+	'\0041'`, `aA`},
+		{`U&'d!0061t!+000061!!\' UESCAPE '!'`, `data!\`},
+		{`U&'d!0061' uescape
+	'!'`, `da`},
+		{`U&'\12'`, `invalid Unicode escape: must be \XXXX or \+XXXXXX`},
+		{`U&'\+01234'`, `invalid Unicode escape: must be \XXXX or \+XXXXXX`},
+		{`U&'\004g'`, `invalid Unicode escape: must be \XXXX or \+XXXXXX`},
+		{`U&'\0041' UESCAPE '!'`, `\0041`},
+		{`U&'!004g' UESCAPE '!'`, `invalid Unicode escape: must be !XXXX or !+XXXXXX`},
+		{`U&'\0000'`, `invalid Unicode escape value`},
+		{`U&'\+110000'`, `invalid Unicode escape value`},
+		{`U&'\D83D'`, `invalid Unicode surrogate pair`},
+		{`U&'\DE00'`, `invalid Unicode surrogate pair`},
+		{`U&'\D83D\0041'`, `invalid Unicode surrogate pair`},
+		{`U&'a' UESCAPE 'ab'`, `UESCAPE must be followed by a simple string literal`},
+		{`U&'a' UESCAPE b`, `UESCAPE must be followed by a simple string literal`},
+		{`U&'a' UESCAPE '+'`, `invalid Unicode escape character`},
+		{`U&'a' UESCAPE 'a'`, `invalid Unicode escape character`},
+		{`U&'a' UESCAPE ' '`, `invalid Unicode escape character`},
+		{`U&'a' UESCAPE '"'`, `invalid Unicode escape character`},
 		{`"''"`, `''`},
 		{`'""'''`, `""'`},
 		{`""""`, `"`},
@@ -292,6 +363,19 @@ world`},
 		{`X'626172'`, `bar`},
 		{`X'FF'`, "\xff"},
 		{`B'100101'`, "100101"},
+		{`$$$$`, ``},
+		{`$$a$$`, `a`},
+		{`$$a'b''c\n$$`, `a'b''c\n`},
+		{"$$a\n$b$\n$$", "a\n$b$\n"},
+		{`$a$b$$c$a$`, `b$$c`},
+		// The body ends at the first occurrence of the delimiter, even if it
+		// overlaps a shorter tag, like Postgres does.
+		{`$a1_$b$a$a1_$`, `b$a`},
+		{`$a1_$b$a$$a1_$`, `b$a$`},
+		{`$A$b$a$c$A$`, `b$a$c`},
+		{`$é$b$é$`, `b`},
+		{`$$a$$b$$`, `a`},
+		{`$a$b$ab$c$a$`, `b$ab$c`},
 	}
 	for _, d := range testData {
 		s := makeScanner(d.sql)
@@ -325,6 +409,10 @@ func TestScanError(t *testing.T) {
 		{`$0`, "placeholder index must be between 1 and 65536"},
 		{`$9223372036854775809`, "placeholder index must be between 1 and 65536"},
 		{`B'123'`, `"2" is not a valid binary digit`},
+		{`$$`, "unterminated dollar-quoted string"},
+		{`$$a$`, "unterminated dollar-quoted string"},
+		{`$a$b$A$`, "unterminated dollar-quoted string"},
+		{"$$\xff$$", "invalid UTF-8 byte sequence"},
 	}
 	for _, d := range testData {
 		s := makeScanner(d.sql)
@@ -376,6 +464,14 @@ func TestSplitFirstStatement(t *testing.T) {
 			s:   "SELECT ';'",
 			res: "",
 		},
+		{
+			s:   "SELECT $$;$$; SELECT 2",
+			res: "SELECT $$;$$;",
+		},
+		{
+			s:   "SELECT $a$ $$; $a$",
+			res: "",
+		},
 	}
 
 	for i, tc := range tests {
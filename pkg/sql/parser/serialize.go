@@ -0,0 +1,872 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go/constant"
+	"go/token"
+	"reflect"
+	"strconv"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/pkg/errors"
+)
+
+// ASTJSONVersion is the version of the JSON encoding of statements produced
+// by StatementToJSON. It is incremented whenever the encoding changes in a
+// way that older decoders cannot understand.
+const ASTJSONVersion = 1
+
+// The JSON encoding of a statement is an object of the form:
+//
+//   {"version": 1, "sql": "SELECT 1", "ast": <node>}
+//
+// where "sql" is the canonical text of the statement, for the benefit of
+// consumers that only need it, and <node> is the encoding of the AST.
+//
+// Every value of an interface type in the AST (e.g. tree.Expr or
+// tree.TableExpr) is encoded as an object {"type": <name>, "value": <v>}
+// where <name> is the name of the type in the tree package, for instance
+// "ComparisonExpr", and <v> is the encoding of the node itself. The
+// remaining values are encoded directly: structs are encoded as objects
+// holding their exported fields, with the fields of embedded structs
+// promoted to the enclosing object; slices and arrays are encoded as
+// arrays; strings, booleans and numbers are encoded as such; and nil
+// pointers, slices and interfaces are encoded as null.
+//
+// A few types are encoded specially:
+// - column types use the type name "ColumnType" and their SQL spelling, e.g.
+//   {"type": "ColumnType", "value": "DECIMAL(10,2)"}.
+// - NumVal is encoded as {"Kind": "int"|"float", "Num": <n>, "Den": <d>,
+//   "Negative": <bool>, "OrigString": <s>}, where n and d are the decimal
+//   numerator and denominator of the exact value.
+// - StrVal is encoded as {"Value": <s>, "Bytes": <bool>}, where s is
+//   base64-encoded for byte strings.
+// - the datums produced by the parser (DNull, DBool and DInt) use their
+//   type name and a JSON value, e.g. {"type": "DBool", "value": true}.
+// - resolved function references are encoded by name, with the type name
+//   "FunctionDefinition".
+//
+// Only ASTs as produced by the parser are supported. In particular, trees
+// that have been type checked or normalized may contain nodes which cannot
+// be encoded.
+
+type jsonStatement struct {
+	Version int             `json:"version"`
+	SQL     string          `json:"sql"`
+	AST     json.RawMessage `json:"ast"`
+}
+
+// StatementToJSON returns the JSON encoding of the given statement. The
+// encoding is stable: a statement that can be encoded by a given version
+// of CockroachDB can be decoded by StatementFromJSON in later versions
+// until ASTJSONVersion changes.
+func StatementToJSON(stmt tree.Statement) ([]byte, error) {
+	var e astEncoder
+	if err := e.encode(reflect.ValueOf(&stmt).Elem()); err != nil {
+		return nil, err
+	}
+	sql := ""
+	if stmt != nil {
+		sql = tree.AsStringWithFlags(stmt, tree.FmtParsable)
+	}
+	return json.Marshal(jsonStatement{
+		Version: ASTJSONVersion,
+		SQL:     sql,
+		AST:     e.buf.Bytes(),
+	})
+}
+
+// StatementFromJSON decodes a statement encoded by StatementToJSON.
+func StatementFromJSON(data []byte) (tree.Statement, error) {
+	var js jsonStatement
+	if err := json.Unmarshal(data, &js); err != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError,
+			"invalid statement encoding: %v", err)
+	}
+	if js.Version != ASTJSONVersion {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"unsupported statement encoding version %d, expected %d", js.Version, ASTJSONVersion)
+	}
+	dec := json.NewDecoder(bytes.NewReader(js.AST))
+	dec.UseNumber()
+	var ast interface{}
+	if err := dec.Decode(&ast); err != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError,
+			"invalid statement encoding: %v", err)
+	}
+	var stmt tree.Statement
+	if err := decodeAST(ast, reflect.ValueOf(&stmt).Elem()); err != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidTextRepresentationError,
+			"invalid statement encoding: %v", err)
+	}
+	return stmt, nil
+}
+
+// astNodeTypes maps the names of the types in the tree package which can
+// be found behind an interface in an AST to their reflect.Type.
+var astNodeTypes = func() map[string]reflect.Type {
+	m := make(map[string]reflect.Type)
+	for _, p := range []interface{}{
+		(*tree.AliasClause)(nil),
+		(*tree.AliasedTableExpr)(nil),
+		(*tree.AllColumnsSelector)(nil),
+		(*tree.AllTablesSelector)(nil),
+		(*tree.AlterDatabaseSetReadMode)(nil),
+		(*tree.AlterIndex)(nil),
+		(*tree.AlterIndexCmds)(nil),
+		(*tree.AlterIndexPartitionBy)(nil),
+		(*tree.AlterSequence)(nil),
+		(*tree.AlterTable)(nil),
+		(*tree.AlterTableAddColumn)(nil),
+		(*tree.AlterTableAddConstraint)(nil),
+		(*tree.AlterTableAlterColumnType)(nil),
+		(*tree.AlterTableCmds)(nil),
+		(*tree.AlterTableDropColumn)(nil),
+		(*tree.AlterTableDropConstraint)(nil),
+		(*tree.AlterTableDropNotNull)(nil),
+		(*tree.AlterTableDropStored)(nil),
+		(*tree.AlterTableInjectStats)(nil),
+		(*tree.AlterTablePartitionBy)(nil),
+		(*tree.AlterTableRenameColumn)(nil),
+		(*tree.AlterTableRenameConstraint)(nil),
+		(*tree.AlterTableRenameTable)(nil),
+		(*tree.AlterTableSetAudit)(nil),
+		(*tree.AlterTableSetDefault)(nil),
+		(*tree.AlterTableValidateConstraint)(nil),
+		(*tree.AlterUserSetPassword)(nil),
+		(*tree.AndExpr)(nil),
+		(*tree.AnnotateTypeExpr)(nil),
+		(*tree.Array)(nil),
+		(*tree.ArrayFlatten)(nil),
+		(*tree.ArraySubscript)(nil),
+		(*tree.ArraySubscripts)(nil),
+		(*tree.AsOfClause)(nil),
+		(*tree.Backup)(nil),
+		(*tree.BeginTransaction)(nil),
+		(*tree.BinaryExpr)(nil),
+		(*tree.Call)(nil),
+		(*tree.CancelQueries)(nil),
+		(*tree.CancelSessions)(nil),
+		(*tree.CannedOptPlan)(nil),
+		(*tree.CaseExpr)(nil),
+		(*tree.CastExpr)(nil),
+		(*tree.CheckConstraintTableDef)(nil),
+		(*tree.CoalesceExpr)(nil),
+		(*tree.CollateExpr)(nil),
+		(*tree.ColumnAccessExpr)(nil),
+		(*tree.ColumnCheckConstraint)(nil),
+		(*tree.ColumnCollation)(nil),
+		(*tree.ColumnComputedDef)(nil),
+		(*tree.ColumnDefault)(nil),
+		(*tree.ColumnFKConstraint)(nil),
+		(*tree.ColumnFamilyConstraint)(nil),
+		(*tree.ColumnItem)(nil),
+		(*tree.ColumnTableDef)(nil),
+		(*tree.CommentOnColumn)(nil),
+		(*tree.CommentOnDatabase)(nil),
+		(*tree.CommentOnTable)(nil),
+		(*tree.CommitTransaction)(nil),
+		(*tree.ComparisonExpr)(nil),
+		(*tree.ControlJobs)(nil),
+		(*tree.ControlSchedule)(nil),
+		(*tree.CopyFrom)(nil),
+		(*tree.CreateChangefeed)(nil),
+		(*tree.CreateDatabase)(nil),
+		(*tree.CreateFunction)(nil),
+		(*tree.CreateIndex)(nil),
+		(*tree.CreateProcedure)(nil),
+		(*tree.CreateRole)(nil),
+		(*tree.CreateSchedule)(nil),
+		(*tree.CreateSequence)(nil),
+		(*tree.CreateStats)(nil),
+		(*tree.CreateStatsOptions)(nil),
+		(*tree.CreateTable)(nil),
+		(*tree.CreateTrigger)(nil),
+		(*tree.CreateUser)(nil),
+		(*tree.CreateView)(nil),
+		(*tree.Deallocate)(nil),
+		(*tree.DefaultVal)(nil),
+		(*tree.Delete)(nil),
+		(*tree.Discard)(nil),
+		(*tree.DistinctOn)(nil),
+		(*tree.DropDatabase)(nil),
+		(*tree.DropIndex)(nil),
+		(*tree.DropProcedure)(nil),
+		(*tree.DropRole)(nil),
+		(*tree.DropSchedule)(nil),
+		(*tree.DropSequence)(nil),
+		(*tree.DropTable)(nil),
+		(*tree.DropTrigger)(nil),
+		(*tree.DropUser)(nil),
+		(*tree.DropView)(nil),
+		(*tree.Execute)(nil),
+		(*tree.Explain)(nil),
+		(*tree.Export)(nil),
+		(*tree.Exprs)(nil),
+		(*tree.FamilyTableDef)(nil),
+		(*tree.ForeignKeyConstraintTableDef)(nil),
+		(*tree.From)(nil),
+		(*tree.FuncExpr)(nil),
+		(*tree.Grant)(nil),
+		(*tree.GrantRole)(nil),
+		(*tree.GroupBy)(nil),
+		(*tree.IfErrExpr)(nil),
+		(*tree.IfExpr)(nil),
+		(*tree.Import)(nil),
+		(*tree.IndexElem)(nil),
+		(*tree.IndexElemList)(nil),
+		(*tree.IndexFlags)(nil),
+		(*tree.IndexTableDef)(nil),
+		(*tree.IndirectionExpr)(nil),
+		(*tree.Insert)(nil),
+		(*tree.InterleaveDef)(nil),
+		(*tree.IsOfTypeExpr)(nil),
+		(*tree.JoinTableExpr)(nil),
+		(*tree.KVOptions)(nil),
+		(*tree.Limit)(nil),
+		(*tree.ListPartition)(nil),
+		(*tree.Name)(nil),
+		(*tree.NameList)(nil),
+		(*tree.NaturalJoinCond)(nil),
+		(*tree.NoReturningClause)(nil),
+		(*tree.NotExpr)(nil),
+		(*tree.NotNullConstraint)(nil),
+		(*tree.NullConstraint)(nil),
+		(*tree.NullIfExpr)(nil),
+		(*tree.NumVal)(nil),
+		(*tree.OnJoinCond)(nil),
+		(*tree.OrExpr)(nil),
+		(*tree.Order)(nil),
+		(*tree.OrderBy)(nil),
+		(*tree.ParenExpr)(nil),
+		(*tree.ParenSelect)(nil),
+		(*tree.ParenTableExpr)(nil),
+		(*tree.PartitionBy)(nil),
+		(*tree.PartitionMaxVal)(nil),
+		(*tree.PartitionMinVal)(nil),
+		(*tree.Placeholder)(nil),
+		(*tree.Prepare)(nil),
+		(*tree.PrimaryKeyConstraint)(nil),
+		(*tree.RangeCond)(nil),
+		(*tree.RangePartition)(nil),
+		(*tree.ReferenceActions)(nil),
+		(*tree.ReleaseSavepoint)(nil),
+		(*tree.Relocate)(nil),
+		(*tree.RenameColumn)(nil),
+		(*tree.RenameDatabase)(nil),
+		(*tree.RenameIndex)(nil),
+		(*tree.RenameTable)(nil),
+		(*tree.ResolvableFunctionReference)(nil),
+		(*tree.Restore)(nil),
+		(*tree.ReturningExprs)(nil),
+		(*tree.ReturningNothing)(nil),
+		(*tree.Revoke)(nil),
+		(*tree.RevokeRole)(nil),
+		(*tree.RollbackToSavepoint)(nil),
+		(*tree.RollbackTransaction)(nil),
+		(*tree.RoutineAssign)(nil),
+		(*tree.RoutineBlock)(nil),
+		(*tree.RoutineExec)(nil),
+		(*tree.RoutineExit)(nil),
+		(*tree.RoutineIf)(nil),
+		(*tree.RoutineLoop)(nil),
+		(*tree.RoutineRaise)(nil),
+		(*tree.RoutineReturn)(nil),
+		(*tree.RowsFromExpr)(nil),
+		(*tree.Savepoint)(nil),
+		(*tree.Scatter)(nil),
+		(*tree.Scrub)(nil),
+		(*tree.ScrubOptionConstraint)(nil),
+		(*tree.ScrubOptionIndex)(nil),
+		(*tree.ScrubOptionPhysical)(nil),
+		(*tree.ScrubOptions)(nil),
+		(*tree.Select)(nil),
+		(*tree.SelectClause)(nil),
+		(*tree.SelectExpr)(nil),
+		(*tree.SelectExprs)(nil),
+		(*tree.SequenceOptions)(nil),
+		(*tree.SetClusterSetting)(nil),
+		(*tree.SetSessionCharacteristics)(nil),
+		(*tree.SetTracing)(nil),
+		(*tree.SetTransaction)(nil),
+		(*tree.SetVar)(nil),
+		(*tree.SetZoneConfig)(nil),
+		(*tree.ShowBackup)(nil),
+		(*tree.ShowClusterSetting)(nil),
+		(*tree.ShowColumns)(nil),
+		(*tree.ShowConstraints)(nil),
+		(*tree.ShowCreate)(nil),
+		(*tree.ShowDatabases)(nil),
+		(*tree.ShowEvents)(nil),
+		(*tree.ShowFingerprints)(nil),
+		(*tree.ShowGrants)(nil),
+		(*tree.ShowHistogram)(nil),
+		(*tree.ShowIndex)(nil),
+		(*tree.ShowJobs)(nil),
+		(*tree.ShowQueries)(nil),
+		(*tree.ShowRanges)(nil),
+		(*tree.ShowRoleGrants)(nil),
+		(*tree.ShowRoles)(nil),
+		(*tree.ShowSchedules)(nil),
+		(*tree.ShowSchemas)(nil),
+		(*tree.ShowSequences)(nil),
+		(*tree.ShowSessions)(nil),
+		(*tree.ShowSyntax)(nil),
+		(*tree.ShowTableStats)(nil),
+		(*tree.ShowTables)(nil),
+		(*tree.ShowTraceForSession)(nil),
+		(*tree.ShowTransactionStatus)(nil),
+		(*tree.ShowUsers)(nil),
+		(*tree.ShowVar)(nil),
+		(*tree.ShowZoneConfig)(nil),
+		(*tree.Split)(nil),
+		(*tree.StatementSource)(nil),
+		(*tree.StrVal)(nil),
+		(*tree.Subquery)(nil),
+		(*tree.TableDefs)(nil),
+		(*tree.TableExprs)(nil),
+		(*tree.TableIndexName)(nil),
+		(*tree.TableIndexNames)(nil),
+		(*tree.TableName)(nil),
+		(*tree.TableNamePrefix)(nil),
+		(*tree.TableNames)(nil),
+		(*tree.TablePatterns)(nil),
+		(*tree.TableRef)(nil),
+		(*tree.TargetList)(nil),
+		(*tree.TransactionModes)(nil),
+		(*tree.Truncate)(nil),
+		(*tree.Tuple)(nil),
+		(*tree.TupleStar)(nil),
+		(*tree.UnaryExpr)(nil),
+		(*tree.UnionClause)(nil),
+		(*tree.UniqueConstraint)(nil),
+		(*tree.UniqueConstraintTableDef)(nil),
+		(*tree.UnqualifiedStar)(nil),
+		(*tree.UnresolvedName)(nil),
+		(*tree.UnresolvedObjectName)(nil),
+		(*tree.UnrestrictedName)(nil),
+		(*tree.Update)(nil),
+		(*tree.UpdateExpr)(nil),
+		(*tree.UpdateExprs)(nil),
+		(*tree.UsingJoinCond)(nil),
+		(*tree.ValuesClause)(nil),
+		(*tree.ValuesClauseWithNames)(nil),
+		(*tree.When)(nil),
+		(*tree.Where)(nil),
+		(*tree.Window)(nil),
+		(*tree.WindowDef)(nil),
+		(*tree.WindowFrame)(nil),
+		(*tree.WindowFrameBound)(nil),
+		(*tree.With)(nil),
+		(*tree.ZoneSpecifier)(nil),
+	} {
+		t := reflect.TypeOf(p).Elem()
+		m[t.Name()] = t
+	}
+	return m
+}()
+
+const (
+	columnTypeName = "ColumnType"
+	funcDefName    = "FunctionDefinition"
+)
+
+var (
+	numValType       = reflect.TypeOf(tree.NumVal{})
+	strValType       = reflect.TypeOf(tree.StrVal{})
+	colTypeType      = reflect.TypeOf((*coltypes.T)(nil)).Elem()
+	colTypeFormatter = reflect.TypeOf((*coltypes.ColTypeFormatter)(nil)).Elem()
+)
+
+// astEncoder produces the JSON encoding of an AST.
+type astEncoder struct {
+	buf bytes.Buffer
+}
+
+func (e *astEncoder) writeJSON(v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+// encode writes the encoding of v, which is a part of an AST.
+func (e *astEncoder) encode(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.encodeNode(v.Elem())
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.encode(v.Elem())
+
+	case reflect.Struct:
+		switch v.Type() {
+		case numValType:
+			return e.encodeNumVal(addr(v).Interface().(*tree.NumVal))
+		case strValType:
+			s := addr(v).Interface().(*tree.StrVal)
+			val := s.RawString()
+			if s.ScannedAsBytes() {
+				// Byte strings need not be valid UTF-8.
+				val = base64.StdEncoding.EncodeToString([]byte(val))
+			}
+			return e.writeJSON(struct {
+				Value string
+				Bytes bool
+			}{val, s.ScannedAsBytes()})
+		}
+		e.buf.WriteByte('{')
+		first := true
+		if err := e.encodeFields(v, &first); err != nil {
+			return err
+		}
+		e.buf.WriteByte('}')
+		return nil
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			e.buf.WriteString("null")
+			return nil
+		}
+		e.buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				e.buf.WriteByte(',')
+			}
+			if err := e.encode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+		e.buf.WriteByte(']')
+		return nil
+
+	case reflect.String:
+		return e.writeJSON(v.String())
+	case reflect.Bool:
+		e.buf.WriteString(strconv.FormatBool(v.Bool()))
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.buf.WriteString(strconv.FormatInt(v.Int(), 10))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		e.buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+		return nil
+	case reflect.Float32, reflect.Float64:
+		return e.writeJSON(v.Float())
+	}
+	return errors.Errorf("cannot encode value of type %s", v.Type())
+}
+
+// addr returns a pointer to v, or to a copy of v if it is not addressable.
+func addr(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p
+}
+
+// encodeFields writes the exported fields of the struct v, including the
+// ones of the structs it embeds.
+func (e *astEncoder) encodeFields(v reflect.Value, first *bool) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := e.encodeFields(v.Field(i), first); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			// Unexported fields are not populated by the parser.
+			continue
+		}
+		if !*first {
+			e.buf.WriteByte(',')
+		}
+		*first = false
+		if err := e.writeJSON(f.Name); err != nil {
+			return err
+		}
+		e.buf.WriteByte(':')
+		if err := e.encode(v.Field(i)); err != nil {
+			return errors.Wrapf(err, "%s.%s", t.Name(), f.Name)
+		}
+	}
+	return nil
+}
+
+// encodeNode writes the encoding of v, which was found behind an interface
+// in an AST, along with its type.
+func (e *astEncoder) encodeNode(v reflect.Value) error {
+	t := v.Type()
+	base := t
+	if base.Kind() == reflect.Ptr {
+		base = base.Elem()
+	}
+	// Check the node types first: some of them, like Placeholder, also
+	// implement tree.Datum.
+	if astNodeTypes[base.Name()] == base {
+		fmt.Fprintf(&e.buf, `{"type":%q,"value":`, base.Name())
+		if err := e.encode(v); err != nil {
+			return err
+		}
+		e.buf.WriteByte('}')
+		return nil
+	}
+
+	switch {
+	case t == functionDefPtrType:
+		return e.writeTypedJSON(funcDefName, v.Interface().(*tree.FunctionDefinition).Name)
+
+	case t.Implements(colTypeFormatter):
+		return e.writeTypedJSON(columnTypeName, v.Interface().(coltypes.ColTypeFormatter).String())
+
+	case t.Implements(datumType):
+		switch d := v.Interface().(tree.Datum); t := d.(type) {
+		case *tree.DBool:
+			return e.writeTypedJSON("DBool", bool(*t))
+		case *tree.DInt:
+			return e.writeTypedJSON("DInt", int64(*t))
+		default:
+			if d == tree.DNull {
+				return e.writeTypedJSON("DNull", nil)
+			}
+		}
+		return errors.Errorf("cannot encode datum of type %s", t)
+	}
+	return errors.Errorf("cannot encode node of type %s", t)
+}
+
+func (e *astEncoder) writeTypedJSON(typ string, value interface{}) error {
+	return e.writeJSON(struct {
+		Type  string      `json:"type"`
+		Value interface{} `json:"value"`
+	}{typ, value})
+}
+
+type jsonNumVal struct {
+	Kind       string
+	Num        string
+	Den        string `json:",omitempty"`
+	Negative   bool
+	OrigString string
+}
+
+func (e *astEncoder) encodeNumVal(n *tree.NumVal) error {
+	if n.Value == nil {
+		return errors.New("cannot encode numeric constant without a value")
+	}
+	res := jsonNumVal{Negative: n.Negative, OrigString: n.OrigString}
+	switch n.Value.Kind() {
+	case constant.Int:
+		res.Kind = "int"
+		res.Num = n.Value.ExactString()
+	case constant.Float:
+		res.Kind = "float"
+		res.Num = constant.Num(n.Value).ExactString()
+		res.Den = constant.Denom(n.Value).ExactString()
+	default:
+		return errors.Errorf("cannot encode numeric constant %s", n.Value)
+	}
+	return e.writeJSON(res)
+}
+
+// decodeAST decodes the value produced by a json.Decoder, using numbers,
+// into v, which must be settable and hold the zero value of its type.
+func decodeAST(data interface{}, v reflect.Value) error {
+	if data == nil {
+		return nil
+	}
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Interface:
+		n, err := decodeNode(data, t)
+		if err != nil {
+			return err
+		}
+		v.Set(n)
+		return nil
+
+	case reflect.Ptr:
+		p := reflect.New(t.Elem())
+		if err := decodeAST(data, p.Elem()); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+
+	case reflect.Struct:
+		switch t {
+		case numValType:
+			n, err := decodeNumVal(data)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(n).Elem())
+			return nil
+		case strValType:
+			obj, ok := data.(map[string]interface{})
+			if !ok {
+				return errors.Errorf("expected an object for %s, found %T", t, data)
+			}
+			s, _ := obj["Value"].(string)
+			res := tree.NewStrVal(s)
+			if b, _ := obj["Bytes"].(bool); b {
+				bs, err := base64.StdEncoding.DecodeString(s)
+				if err != nil {
+					return errors.Wrapf(err, "invalid byte string %q", s)
+				}
+				res = tree.NewBytesStrVal(string(bs))
+			}
+			v.Set(reflect.ValueOf(res).Elem())
+			return nil
+		}
+		obj, ok := data.(map[string]interface{})
+		if !ok {
+			return errors.Errorf("expected an object for %s, found %T", t, data)
+		}
+		return decodeFields(obj, v)
+
+	case reflect.Slice, reflect.Array:
+		arr, ok := data.([]interface{})
+		if !ok {
+			return errors.Errorf("expected an array for %s, found %T", t, data)
+		}
+		if v.Kind() == reflect.Slice {
+			v.Set(reflect.MakeSlice(t, len(arr), len(arr)))
+		} else if len(arr) != v.Len() {
+			return errors.Errorf("expected %d elements for %s, found %d", v.Len(), t, len(arr))
+		}
+		for i := range arr {
+			if err := decodeAST(arr[i], v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.String:
+		s, ok := data.(string)
+		if !ok {
+			return errors.Errorf("expected a string for %s, found %T", t, data)
+		}
+		v.SetString(s)
+		return nil
+
+	case reflect.Bool:
+		b, ok := data.(bool)
+		if !ok {
+			return errors.Errorf("expected a boolean for %s, found %T", t, data)
+		}
+		v.SetBool(b)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := data.(json.Number)
+		if !ok {
+			return errors.Errorf("expected a number for %s, found %T", t, data)
+		}
+		i, err := strconv.ParseInt(string(n), 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := data.(json.Number)
+		if !ok {
+			return errors.Errorf("expected a number for %s, found %T", t, data)
+		}
+		i, err := strconv.ParseUint(string(n), 10, t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(i)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		n, ok := data.(json.Number)
+		if !ok {
+			return errors.Errorf("expected a number for %s, found %T", t, data)
+		}
+		f, err := strconv.ParseFloat(string(n), t.Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	}
+	return errors.Errorf("cannot decode value of type %s", t)
+}
+
+// decodeFields is the inverse of encodeFields.
+func decodeFields(obj map[string]interface{}, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := decodeFields(obj, v.Field(i)); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if err := decodeAST(obj[f.Name], v.Field(i)); err != nil {
+			return errors.Wrapf(err, "%s.%s", t.Name(), f.Name)
+		}
+	}
+	return nil
+}
+
+// decodeNode decodes the encoding of a node of the given interface type.
+func decodeNode(data interface{}, iface reflect.Type) (reflect.Value, error) {
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return reflect.Value{}, errors.Errorf("expected an object for %s, found %T", iface, data)
+	}
+	typ, _ := obj["type"].(string)
+	value := obj["value"]
+
+	var res reflect.Value
+	switch typ {
+	case columnTypeName:
+		s, ok := value.(string)
+		if !ok {
+			return reflect.Value{}, errors.Errorf("expected a string for %s, found %T", typ, value)
+		}
+		t, err := parseColumnTypeForJSON(s, iface == colTypeType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		res = reflect.ValueOf(t)
+
+	case funcDefName:
+		name, _ := value.(string)
+		fd, ok := tree.FunDefs[name]
+		if !ok {
+			return reflect.Value{}, errors.Errorf("unknown function %q", name)
+		}
+		res = reflect.ValueOf(fd)
+
+	case "DNull":
+		res = reflect.ValueOf(tree.DNull)
+	case "DBool":
+		b, ok := value.(bool)
+		if !ok {
+			return reflect.Value{}, errors.Errorf("expected a boolean for %s, found %T", typ, value)
+		}
+		res = reflect.ValueOf(tree.MakeDBool(tree.DBool(b)))
+	case "DInt":
+		n, _ := value.(json.Number)
+		i, err := strconv.ParseInt(string(n), 10, 64)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		res = reflect.ValueOf(tree.NewDInt(tree.DInt(i)))
+
+	default:
+		base, ok := astNodeTypes[typ]
+		if !ok {
+			return reflect.Value{}, errors.Errorf("unknown node type %q", typ)
+		}
+		// The types whose values implement the interface are stored by
+		// value; the others by reference.
+		t := base
+		if !t.Implements(iface) {
+			t = reflect.PtrTo(base)
+		}
+		res = reflect.New(t).Elem()
+		if err := decodeAST(value, res); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	if !res.Type().Implements(iface) {
+		return reflect.Value{}, errors.Errorf("%s cannot be used as %s", typ, iface)
+	}
+	return res, nil
+}
+
+// parseColumnTypeForJSON parses the SQL spelling of a column type, as
+// produced by its String method. If columnType is set, the type is parsed
+// as the type of a column definition, which allows for e.g. SERIAL;
+// otherwise it is parsed as the target of a cast.
+func parseColumnTypeForJSON(s string, columnType bool) (coltypes.CastTargetType, error) {
+	if !columnType {
+		return ParseType(s)
+	}
+	stmt, err := ParseOne(fmt.Sprintf("CREATE TABLE t (x %s)", s))
+	if err != nil {
+		return nil, err
+	}
+	if ct, ok := stmt.AST.(*tree.CreateTable); ok && len(ct.Defs) == 1 {
+		if def, ok := ct.Defs[0].(*tree.ColumnTableDef); ok {
+			return def.Type, nil
+		}
+	}
+	return nil, errors.Errorf("invalid column type %q", s)
+}
+
+func decodeNumVal(data interface{}) (*tree.NumVal, error) {
+	var jn jsonNumVal
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.Errorf("expected an object for NumVal, found %T", data)
+	}
+	jn.Kind, _ = obj["Kind"].(string)
+	jn.Num, _ = obj["Num"].(string)
+	jn.Den, _ = obj["Den"].(string)
+	jn.Negative, _ = obj["Negative"].(bool)
+	jn.OrigString, _ = obj["OrigString"].(string)
+
+	val := constant.MakeFromLiteral(jn.Num, token.INT, 0)
+	switch jn.Kind {
+	case "int":
+	case "float":
+		den := constant.MakeFromLiteral(jn.Den, token.INT, 0)
+		if den.Kind() != constant.Int || constant.Sign(den) == 0 {
+			return nil, errors.Errorf("invalid denominator %q", jn.Den)
+		}
+		val = constant.ToFloat(constant.BinaryOp(val, token.QUO, den))
+	default:
+		return nil, errors.Errorf("invalid numeric constant kind %q", jn.Kind)
+	}
+	if val.Kind() == constant.Unknown {
+		return nil, errors.Errorf("invalid numeric constant %q", jn.Num)
+	}
+	return &tree.NumVal{Value: val, Negative: jn.Negative, OrigString: jn.OrigString}, nil
+}
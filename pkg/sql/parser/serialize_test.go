@@ -0,0 +1,177 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestStatementJSONRoundTrip(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []string{
+		`SELECT 1`,
+		`SELECT -1.5e3, 0.1, 123456789012345678901234567890, x'ff', b'01', e'\n', 'foo'`,
+		`SELECT a, b FROM t WHERE a = 'foo' AND b IS NOT NULL ORDER BY a DESC LIMIT 10 OFFSET 2`,
+		`SELECT DISTINCT ON (a) a, count(*), sum(DISTINCT x) FILTER (WHERE y > 1) FROM t GROUP BY a HAVING count(*) > 1`,
+		`SELECT * FROM a JOIN b USING (x) LEFT JOIN (SELECT 1) AS c (y) ON true NATURAL JOIN d`,
+		`SELECT t.*, *, (t).x, ARRAY[1, 2][1], (1, 'a'), ARRAY(SELECT 1) FROM t@idx AS OF SYSTEM TIME '-1s'`,
+		`SELECT CAST(a AS DECIMAL(10, 2)), b::INT4, c::STRING COLLATE en_US, d:::TIMESTAMP, e IS OF (INT) FROM t`,
+		`SELECT CASE WHEN a THEN 1 ELSE 2 END, COALESCE(a, b), NULLIF(a, b), IFERROR(a, b), a BETWEEN 1 AND 2 FROM t`,
+		`SELECT rank() OVER (PARTITION BY a ORDER BY b ROWS BETWEEN 1 PRECEDING AND CURRENT ROW) FROM t`,
+		`SELECT $1 + $2, NULL, true, current_date`,
+		`WITH x AS (SELECT 1) SELECT * FROM x UNION ALL SELECT 2 EXCEPT VALUES (3)`,
+		`SELECT * FROM [SHOW TABLES]`,
+		`SELECT * FROM ROWS FROM (generate_series(1, 2)) WITH ORDINALITY`,
+		`INSERT INTO t (a, b) VALUES (1, 'x'), (2, NULL) ON CONFLICT (a) DO UPDATE SET b = excluded.b`,
+		`INSERT INTO t DEFAULT VALUES RETURNING NOTHING`,
+		`UPSERT INTO t SELECT * FROM u`,
+		`UPDATE t SET a = a + 1, (b, c) = (SELECT 1, 2) WHERE d IN (1, 2, 3) RETURNING *`,
+		`DELETE FROM t WHERE a @> '{"a": 1}' ORDER BY a LIMIT 1`,
+		`CREATE TABLE t (a INT PRIMARY KEY, b STRING DEFAULT 'x' NOT NULL, c INT AS (a + 1) STORED, ` +
+			`d SERIAL, e INT REFERENCES u (x) ON DELETE CASCADE, INDEX (b) STORING (c), UNIQUE (c), ` +
+			`CHECK (a > 0), FAMILY (a, b))`,
+		`CREATE TABLE t (a INT PRIMARY KEY) PARTITION BY LIST (a) (PARTITION p VALUES IN (1, DEFAULT))`,
+		`CREATE TABLE u AS SELECT * FROM t`,
+		`CREATE INDEX ON t (a ASC, b DESC) INTERLEAVE IN PARENT p (a)`,
+		`CREATE VIEW v (x) AS SELECT a FROM t`,
+		`CREATE SEQUENCE s INCREMENT 2 START 10`,
+		`CREATE FUNCTION f(a INT, b STRING) RETURNS INT LANGUAGE sql AS 'UPDATE t SET b = b; SELECT a'`,
+		`CREATE FUNCTION f(a INT) RETURNS INT LANGUAGE plpgsql AS 'DECLARE b INT := 1; BEGIN ` +
+			`LOOP EXIT WHEN b > a; b := b * 2; END LOOP; IF b > 10 THEN RAISE EXCEPTION ''%'', b; END IF; ` +
+			`SELECT count(*) INTO b FROM t; RETURN b; END'`,
+		`CREATE PROCEDURE p(a INT) LANGUAGE plpgsql AS 'BEGIN INSERT INTO t VALUES (a); END'`,
+		`CALL p(1 + 2, (SELECT max(a) FROM t))`,
+		`ALTER TABLE t ADD COLUMN d INT, DROP COLUMN e, ALTER COLUMN f SET DEFAULT 1`,
+		`ALTER TABLE t RENAME TO u`,
+		`ALTER TABLE t ADD CONSTRAINT c CHECK (a > 0), VALIDATE CONSTRAINT c`,
+		`ALTER TABLE t PARTITION BY RANGE (a) (PARTITION p VALUES FROM (MINVALUE) TO (MAXVALUE))`,
+		`ALTER INDEX t@i CONFIGURE ZONE USING num_replicas = 3`,
+		`DROP TABLE IF EXISTS t, u CASCADE`,
+		`GRANT SELECT, INSERT ON TABLE t TO foo`,
+		`REVOKE ALL ON DATABASE d FROM bar`,
+		`GRANT admin TO foo WITH ADMIN OPTION`,
+		`BEGIN TRANSACTION PRIORITY HIGH, READ ONLY`,
+		`SET TRANSACTION ISOLATION LEVEL SERIALIZABLE`,
+		`SAVEPOINT cockroach_restart`,
+		`SET application_name = 'x'`,
+		`SET CLUSTER SETTING a.b = 1`,
+		`SHOW GRANTS ON t FOR foo`,
+		`SHOW COLUMNS FROM t`,
+		`EXPLAIN (VERBOSE) SELECT 1`,
+		`PREPARE p (INT) AS SELECT $1`,
+		`EXECUTE p (1)`,
+		`BACKUP TABLE t TO 'nodelocal:///foo' WITH revision_history`,
+		`CANCEL QUERIES SELECT 'foo'`,
+		`COMMENT ON TABLE t IS 'foo'`,
+		`TRUNCATE t`,
+	}
+	for _, sql := range testData {
+		t.Run(sql, func(t *testing.T) {
+			stmt, err := parser.ParseOne(sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := parser.StatementToJSON(stmt.AST)
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := parser.StatementFromJSON(data)
+			if err != nil {
+				t.Fatalf("%s: %v", data, err)
+			}
+			if expected, actual := stmt.AST.String(), res.String(); expected != actual {
+				t.Fatalf("expected %s, got %s", expected, actual)
+			}
+			// The encoding is deterministic.
+			data2, err := parser.StatementToJSON(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != string(data2) {
+				t.Fatalf("encoding is not stable:\n%s\n%s", data, data2)
+			}
+		})
+	}
+}
+
+func TestStatementJSONFormat(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	stmt, err := parser.ParseOne(`SELECT a FROM t WHERE b = 1`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := parser.StatementToJSON(stmt.AST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var js struct {
+		Version int
+		SQL     string
+		AST     struct {
+			Type  string
+			Value map[string]json.RawMessage
+		}
+	}
+	if err := json.Unmarshal(data, &js); err != nil {
+		t.Fatal(err)
+	}
+	if js.Version != parser.ASTJSONVersion {
+		t.Errorf("expected version %d, got %d", parser.ASTJSONVersion, js.Version)
+	}
+	if js.SQL != `SELECT a FROM t WHERE b = 1` {
+		t.Errorf("unexpected SQL %q", js.SQL)
+	}
+	if js.AST.Type != "Select" {
+		t.Errorf("expected a Select, got %q", js.AST.Type)
+	}
+	if _, ok := js.AST.Value["OrderBy"]; !ok {
+		t.Errorf("expected an OrderBy field, got %s", data)
+	}
+	for _, s := range []string{`"type":"ComparisonExpr"`, `"Kind":"int","Num":"1"`} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("expected %s in %s", s, data)
+		}
+	}
+}
+
+func TestStatementJSONErrors(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		data     string
+		expected string
+	}{
+		{`[]`, `invalid statement encoding`},
+		{`{"version": 0, "ast": null}`, `unsupported statement encoding version 0`},
+		{`{"version": 1, "ast": {"type": "Foo", "value": {}}}`, `unknown node type "Foo"`},
+		{`{"version": 1, "ast": {"type": "StrVal", "value": {}}}`, `StrVal cannot be used as tree.Statement`},
+		{`{"version": 1, "ast": {"type": "Select", "value": {"Limit": 1}}}`, `Select.Limit`},
+	}
+	for _, d := range testData {
+		t.Run(d.data, func(t *testing.T) {
+			_, err := parser.StatementFromJSON([]byte(d.data))
+			if err == nil || !strings.Contains(err.Error(), d.expected) {
+				t.Fatalf("expected error %q, got %v", d.expected, err)
+			}
+		})
+	}
+}
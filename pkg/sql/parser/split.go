@@ -0,0 +1,54 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import "github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+
+// SplitStatements splits the sql into the text of its individual statements,
+// without parsing them. Only the scanner is used to find the semicolons that
+// separate statements, so that semicolons inside string literals, quoted
+// identifiers and comments are handled correctly. This is much cheaper than
+// Parse for large inputs, such as migration files, and the resulting strings
+// are the same as the SQL of the statements returned by Parse. Empty
+// statements are omitted.
+//
+// Since the statements are not parsed, an error is only returned if the input
+// cannot be tokenized (for example because of an unterminated string).
+func SplitStatements(sql string) ([]string, error) {
+	s := makeScanner(sql)
+	var res []string
+	var lval sqlSymType
+	start := -1
+	for {
+		end := s.pos
+		s.scan(&lval)
+		switch lval.id {
+		case ERROR:
+			return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError, "lexical error: %s", lval.str)
+		case 0, ';':
+			if start >= 0 {
+				res = append(res, sql[start:end])
+				start = -1
+			}
+			if lval.id == 0 {
+				return res, nil
+			}
+		default:
+			if start < 0 {
+				start = int(lval.pos)
+			}
+		}
+	}
+}
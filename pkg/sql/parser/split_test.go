@@ -0,0 +1,88 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestSplitStatements(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		expected []string
+	}{
+		{``, nil},
+		{`;;`, nil},
+		{`SELECT 1`, []string{`SELECT 1`}},
+		{`SELECT 1; SELECT 2;`, []string{`SELECT 1`, `SELECT 2`}},
+		{"  SELECT 1 ;\n\n  SELECT 2  ", []string{`SELECT 1`, `SELECT 2`}},
+		{`SELECT 'a;b'; SELECT "c;d"`, []string{`SELECT 'a;b'`, `SELECT "c;d"`}},
+		{`SELECT e'\';'; SELECT 2`, []string{`SELECT e'\';'`, `SELECT 2`}},
+		{"SELECT 1 -- no; split\n; SELECT /* no; split */ 2",
+			[]string{`SELECT 1`, `SELECT /* no; split */ 2`}},
+		// Statements are not parsed.
+		{`SELEC 1; FROM`, []string{`SELEC 1`, `FROM`}},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			res, err := parser.SplitStatements(d.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(res, d.expected) {
+				t.Errorf("expected %q, got %q", d.expected, res)
+			}
+		})
+	}
+}
+
+func TestSplitStatementsMatchesParse(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sql := `CREATE TABLE t (a INT, b STRING DEFAULT ';');
+            INSERT INTO t VALUES (1, 'x;y') /* ; */;
+            SELECT * FROM t WHERE b = e'\'';`
+	stmts, err := parser.Parse(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := parser.SplitStatements(sql)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(stmts) {
+		t.Fatalf("expected %d statements, got %d: %q", len(stmts), len(res), res)
+	}
+	for i := range stmts {
+		if res[i] != stmts[i].SQL {
+			t.Errorf("%d: expected %q, got %q", i, stmts[i].SQL, res[i])
+		}
+	}
+}
+
+func TestSplitStatementsError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	if _, err := parser.SplitStatements(`SELECT 1; SELECT 'unterminated`); !testutils.IsError(err, "lexical error") {
+		t.Fatalf("expected lexical error, got %v", err)
+	}
+}
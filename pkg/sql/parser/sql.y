@@ -162,6 +162,9 @@ func (u *sqlSymUnion) tableNames() tree.TableNames {
 func (u *sqlSymUnion) indexFlags() *tree.IndexFlags {
     return u.val.(*tree.IndexFlags)
 }
+func (u *sqlSymUnion) tableSample() *tree.TableSample {
+    return u.val.(*tree.TableSample)
+}
 func (u *sqlSymUnion) arraySubscript() *tree.ArraySubscript {
     return u.val.(*tree.ArraySubscript)
 }
@@ -246,6 +249,9 @@ func (u *sqlSymUnion) seqOpt() tree.SequenceOption {
 func (u *sqlSymUnion) seqOpts() []tree.SequenceOption {
     return u.val.([]tree.SequenceOption)
 }
+func (u *sqlSymUnion) overridingKind() tree.OverridingKind {
+    return u.val.(tree.OverridingKind)
+}
 func (u *sqlSymUnion) expr() tree.Expr {
     if expr, ok := u.val.(tree.Expr); ok {
         return expr
@@ -300,6 +306,18 @@ func (u *sqlSymUnion) updateExprs() tree.UpdateExprs {
 func (u *sqlSymUnion) limit() *tree.Limit {
     return u.val.(*tree.Limit)
 }
+func (u *sqlSymUnion) lockingClause() tree.LockingClause {
+    return u.val.(tree.LockingClause)
+}
+func (u *sqlSymUnion) lockingItem() *tree.LockingItem {
+    return u.val.(*tree.LockingItem)
+}
+func (u *sqlSymUnion) lockingStrength() tree.LockingStrength {
+    return u.val.(tree.LockingStrength)
+}
+func (u *sqlSymUnion) lockingWaitPolicy() tree.LockingWaitPolicy {
+    return u.val.(tree.LockingWaitPolicy)
+}
 func (u *sqlSymUnion) targetList() tree.TargetList {
     return u.val.(tree.TargetList)
 }
@@ -333,6 +351,9 @@ func (u *sqlSymUnion) windowFrame() *tree.WindowFrame {
 func (u *sqlSymUnion) windowFrameBounds() tree.WindowFrameBounds {
     return u.val.(tree.WindowFrameBounds)
 }
+func (u *sqlSymUnion) windowFrameExclusion() tree.WindowFrameExclusion {
+    return u.val.(tree.WindowFrameExclusion)
+}
 func (u *sqlSymUnion) windowFrameBound() *tree.WindowFrameBound {
     return u.val.(*tree.WindowFrameBound)
 }
@@ -372,6 +393,24 @@ func (u *sqlSymUnion) idxElems() tree.IndexElemList {
 func (u *sqlSymUnion) dropBehavior() tree.DropBehavior {
     return u.val.(tree.DropBehavior)
 }
+func (u *sqlSymUnion) triggerActionTime() tree.TriggerActionTime {
+    return u.val.(tree.TriggerActionTime)
+}
+func (u *sqlSymUnion) triggerEvent() tree.TriggerEvent {
+    return u.val.(tree.TriggerEvent)
+}
+func (u *sqlSymUnion) triggerEvents() tree.TriggerEvents {
+    return u.val.(tree.TriggerEvents)
+}
+func (u *sqlSymUnion) triggerLevel() tree.TriggerLevel {
+    return u.val.(tree.TriggerLevel)
+}
+func (u *sqlSymUnion) functionParam() tree.FunctionParam {
+    return u.val.(tree.FunctionParam)
+}
+func (u *sqlSymUnion) functionParams() tree.FunctionParams {
+    return u.val.(tree.FunctionParams)
+}
 func (u *sqlSymUnion) validationBehavior() tree.ValidationBehavior {
     return u.val.(tree.ValidationBehavior)
 }
@@ -475,14 +514,14 @@ func newNameFromStr(s string) *tree.Name {
 // below; search this file for "Keyword category lists".
 
 // Ordinary key words in alphabetical order.
-%token <str> ABORT ACTION ADD ADMIN AGGREGATE
-%token <str> ALL ALTER ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
+%token <str> ABORT ACTION ADD ADMIN AFTER AGGREGATE
+%token <str> ALL ALTER ALWAYS ANALYSE ANALYZE AND ANY ANNOTATE_TYPE ARRAY AS ASC
 %token <str> ASYMMETRIC AT AUTOMATIC
 
-%token <str> BACKUP BEGIN BETWEEN BIGINT BIGSERIAL BIT
+%token <str> BACKUP BEFORE BEGIN BETWEEN BIGINT BIGSERIAL BIT
 %token <str> BLOB BOOL BOOLEAN BOTH BY BYTEA BYTES
 
-%token <str> CACHE CANCEL CASCADE CASE CAST CHANGEFEED CHAR
+%token <str> CACHE CALL CANCEL CASCADE CASCADED CASE CAST CHANGEFEED CHAR
 %token <str> CHARACTER CHARACTERISTICS CHECK
 %token <str> CLUSTER COALESCE COLLATE COLLATION COLUMN COLUMNS COMMENT COMMIT
 %token <str> COMMITTED COMPACT CONCAT CONFIGURATION CONFIGURATIONS CONFIGURE
@@ -495,24 +534,24 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> DEALLOCATE DEFERRABLE DEFERRED DELETE DESC
 %token <str> DISCARD DISTINCT DO DOMAIN DOUBLE DROP
 
-%token <str> ELSE ENCODING END ENUM ESCAPE EXCEPT
-%token <str> EXISTS EXECUTE EXPERIMENTAL
+%token <str> EACH ELSE ENCODING END ENUM ESCAPE EVENTS EXCEPT
+%token <str> EXCLUDE EXISTS EXECUTE EXPERIMENTAL
 %token <str> EXPERIMENTAL_FINGERPRINTS EXPERIMENTAL_REPLICA
 %token <str> EXPERIMENTAL_AUDIT
-%token <str> EXPLAIN EXPORT EXTENSION EXTRACT EXTRACT_DURATION
+%token <str> EXPLAIN EXPORT EXTENSION EXTRACT EXTRACT_DURATION EXTREMES
 
 %token <str> FALSE FAMILY FETCH FETCHVAL FETCHTEXT FETCHVAL_PATH FETCHTEXT_PATH
 %token <str> FILES FILTER
 %token <str> FIRST FLOAT FLOAT4 FLOAT8 FLOORDIV FOLLOWING FOR FORCE_INDEX FOREIGN FROM FULL FUNCTION
 
-%token <str> GLOBAL GRANT GRANTS GREATEST GROUP GROUPING GROUPS
+%token <str> GENERATED GLOBAL GRANT GRANTS GREATEST GROUP GROUPING GROUPS
 
 %token <str> HAVING HASH HIGH HISTOGRAM HOUR
 
-%token <str> IF IFERROR IFNULL ILIKE IMMEDIATE IMPORT IN INCREMENT INCREMENTAL
+%token <str> IDENTITY IF IFERROR IFNULL ILIKE IMMEDIATE IMPORT IN INCREMENT INCREMENTAL
 %token <str> INET INET_CONTAINED_BY_OR_EQUALS INET_CONTAINS_OR_CONTAINED_BY
 %token <str> INET_CONTAINS_OR_EQUALS INDEX INDEXES INJECT INTERLEAVE INITIALLY
-%token <str> INNER INSERT INT INT2VECTOR INT2 INT4 INT8 INT64 INTEGER
+%token <str> INNER INSERT INSTEAD INT INT2VECTOR INT2 INT4 INT8 INT64 INTEGER
 %token <str> INTERSECT INTERVAL INTO INVERTED IS ISERROR ISNULL ISOLATION
 
 %token <str> JOB JOBS JOIN JSON JSONB JSON_SOME_EXISTS JSON_ALL_EXISTS
@@ -521,42 +560,43 @@ func newNameFromStr(s string) *tree.Name {
 
 %token <str> LANGUAGE LATERAL LC_CTYPE LC_COLLATE
 %token <str> LEADING LEASE LEAST LEFT LESS LEVEL LIKE LIMIT LIST LOCAL
-%token <str> LOCALTIME LOCALTIMESTAMP LOOKUP LOW LSHIFT
+%token <str> LOCALTIME LOCALTIMESTAMP LOCKED LOOKUP LOW LSHIFT
 
 %token <str> MATCH MATERIALIZED MERGE MINVALUE MAXVALUE MINUTE MONTH
 
 %token <str> NAN NAME NAMES NATURAL NEXT NO NO_INDEX_JOIN NORMAL
-%token <str> NOT NOTHING NOTNULL NULL NULLIF NUMERIC
+%token <str> NOT NOTHING NOTNULL NOWAIT NULL NULLIF NUMERIC
 
 %token <str> OF OFF OFFSET OID OIDS OIDVECTOR ON ONLY OPT OPTION OPTIONS OR
-%token <str> ORDER ORDINALITY OUT OUTER OVER OVERLAPS OVERLAY OWNED OPERATOR
+%token <str> ORDER ORDINALITY OTHERS OUT OUTER OVER OVERLAPS OVERLAY OVERRIDING OWNED OPERATOR
 
 %token <str> PARENT PARTIAL PARTITION PASSWORD PAUSE PHYSICAL PLACING
 %token <str> PLAN PLANS POSITION PRECEDING PRECISION PREPARE PRIMARY PRIORITY
-%token <str> PROCEDURAL PUBLICATION
+%token <str> PROCEDURAL PROCEDURE PUBLICATION
 
 %token <str> QUERIES QUERY
 
-%token <str> RANGE RANGES READ REAL RECURSIVE REF REFERENCES
+%token <str> RANGE RANGES READ REAL RECURRING RECURSIVE REF REFERENCES
 %token <str> REGCLASS REGPROC REGPROCEDURE REGNAMESPACE REGTYPE
 %token <str> REMOVE_PATH RENAME REPEATABLE REPLACE
-%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING REVOKE RIGHT
+%token <str> RELEASE RESET RESTORE RESTRICT RESUME RETURNING RETURNS REVOKE RIGHT
 %token <str> ROLE ROLES ROLLBACK ROLLUP ROW ROWS RSHIFT RULE
 
-%token <str> SAVEPOINT SCATTER SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT SEQUENCE SEQUENCES
+%token <str> SAVEPOINT SCATTER SCHEDULE SCHEDULES SCHEMA SCHEMAS SCRUB SEARCH SECOND SELECT
+%token <str> SEQUENCE SEQUENCES
 %token <str> SERIAL SERIAL2 SERIAL4 SERIAL8
-%token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETTING SETTINGS
-%token <str> SHOW SIMILAR SIMPLE SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
+%token <str> SERIALIZABLE SERVER SESSION SESSIONS SESSION_USER SET SETS SETTING SETTINGS
+%token <str> SHARE SHOW SIMILAR SIMPLE SKIP SMALLINT SMALLSERIAL SNAPSHOT SOME SPLIT SQL
 
-%token <str> START STATISTICS STATUS STDIN STRICT STRING STORE STORED STORING SUBSTRING
+%token <str> START STATEMENT STATISTICS STATUS STDIN STRICT STRING STORE STORED STORING SUBSTRING
 %token <str> SYMMETRIC SYNTAX SYSTEM SUBSCRIPTION
 
-%token <str> TABLE TABLES TEMP TEMPLATE TEMPORARY TESTING_RANGES EXPERIMENTAL_RANGES TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
-%token <str> TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO THROTTLING TRAILING TRACE TRANSACTION TREAT TRIGGER TRIM TRUE
+%token <str> TABLE TABLES TABLESAMPLE TEMP TEMPLATE TEMPORARY TESTING_RANGES EXPERIMENTAL_RANGES TESTING_RELOCATE EXPERIMENTAL_RELOCATE TEXT THEN
+%token <str> TIES TIME TIMETZ TIMESTAMP TIMESTAMPTZ TO THROTTLING TRAILING TRACE TRANSACTION TREAT TRIGGER TRIM TRUE
 %token <str> TRUNCATE TRUSTED TYPE
 %token <str> TRACING
 
-%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED
+%token <str> UNBOUNDED UNCOMMITTED UNION UNIQUE UNKNOWN UNLOGGED UNTIL
 %token <str> UPDATE UPSERT USE USER USERS USING UUID
 
 %token <str> VALID VALIDATE VALUE VALUES VARBIT VARCHAR VARIADIC VIEW VARYING VIRTUAL
@@ -568,14 +608,34 @@ func newNameFromStr(s string) *tree.Name {
 %token <str> ZONE
 
 // The grammar thinks these are keywords, but they are not in any category
-// and so can never be entered directly. The filter in scan.go creates these
-// tokens when required (based on looking one token ahead).
+// and so can never be entered directly. The lexer creates these tokens when
+// required, based on looking ahead at the tokens that follow, according to
+// the LOOKAHEAD rules below.
 //
 // NOT_LA exists so that productions such as NOT LIKE can be given the same
 // precedence as LIKE; otherwise they'd effectively have the same precedence as
 // NOT, at least with respect to their left-hand subexpression. WITH_LA is
-// needed to make the grammar LALR(1).
-%token NOT_LA WITH_LA AS_LA
+// needed to make the grammar LALR(1). GENERATED_LA lets GENERATED remain
+// usable as a column family name in CREATE FAMILY.
+%token NOT_LA WITH_LA AS_LA GENERATED_LA
+
+// The LOOKAHEAD rules have the form "LOOKAHEAD <LA token>: <token> <next
+// tokens...>". The lexer replaces <token> by <LA token> when it is followed
+// by the given next tokens; any number of next tokens can be given. The
+// rules for the same token are tried in order, and the first one that
+// matches wins. lookahead.awk generates the lexer tables for these rules,
+// as well as lex.lookaheadKeywords, from the lines below.
+//
+// LOOKAHEAD AS_LA: AS OF
+// LOOKAHEAD NOT_LA: NOT BETWEEN
+// LOOKAHEAD NOT_LA: NOT IN
+// LOOKAHEAD NOT_LA: NOT LIKE
+// LOOKAHEAD NOT_LA: NOT ILIKE
+// LOOKAHEAD NOT_LA: NOT SIMILAR
+// LOOKAHEAD WITH_LA: WITH TIME
+// LOOKAHEAD WITH_LA: WITH ORDINALITY
+// LOOKAHEAD GENERATED_LA: GENERATED ALWAYS
+// LOOKAHEAD GENERATED_LA: GENERATED BY
 
 %union {
   id    int32
@@ -611,6 +671,7 @@ func newNameFromStr(s string) *tree.Name {
 
 // ALTER DATABASE
 %type <tree.Statement> alter_rename_database_stmt
+%type <tree.Statement> alter_read_mode_database_stmt
 %type <tree.Statement> alter_zone_database_stmt
 
 // ALTER USER
@@ -635,6 +696,8 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> backup_stmt
 %type <tree.Statement> begin_stmt
 
+%type <tree.Statement> call_stmt
+
 %type <tree.Statement> cancel_stmt
 %type <tree.Statement> cancel_jobs_stmt
 %type <tree.Statement> cancel_queries_stmt
@@ -663,6 +726,10 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> create_user_stmt
 %type <tree.Statement> create_view_stmt
 %type <tree.Statement> create_sequence_stmt
+%type <tree.Statement> create_trigger_stmt
+%type <tree.Statement> create_function_stmt
+%type <tree.Statement> create_procedure_stmt
+%type <tree.Statement> create_schedule_stmt
 
 %type <tree.Statement> create_stats_stmt
 %type <*tree.CreateStatsOptions> opt_create_stats_options
@@ -682,6 +749,9 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> drop_user_stmt
 %type <tree.Statement> drop_view_stmt
 %type <tree.Statement> drop_sequence_stmt
+%type <tree.Statement> drop_trigger_stmt
+%type <tree.Statement> drop_procedure_stmt
+%type <tree.Statement> drop_schedule_stmt
 
 %type <tree.Statement> explain_stmt
 %type <tree.Statement> prepare_stmt
@@ -693,9 +763,13 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> insert_stmt
 %type <tree.Statement> import_stmt
 %type <tree.Statement> pause_stmt
+%type <tree.Statement> pause_jobs_stmt
+%type <tree.Statement> pause_schedule_stmt
 %type <tree.Statement> release_stmt
 %type <tree.Statement> reset_stmt reset_session_stmt reset_csetting_stmt
 %type <tree.Statement> resume_stmt
+%type <tree.Statement> resume_jobs_stmt
+%type <tree.Statement> resume_schedule_stmt
 %type <tree.Statement> restore_stmt
 %type <tree.Statement> revoke_stmt
 %type <*tree.Select> select_stmt
@@ -719,6 +793,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> show_create_stmt
 %type <tree.Statement> show_csettings_stmt
 %type <tree.Statement> show_databases_stmt
+%type <tree.Statement> show_events_stmt
 %type <tree.Statement> show_fingerprints_stmt
 %type <tree.Statement> show_grants_stmt
 %type <tree.Statement> show_histogram_stmt
@@ -727,6 +802,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Statement> show_queries_stmt
 %type <tree.Statement> show_ranges_stmt
 %type <tree.Statement> show_roles_stmt
+%type <tree.Statement> show_schedules_stmt
 %type <tree.Statement> show_schemas_stmt
 %type <tree.Statement> show_sequences_stmt
 %type <tree.Statement> show_session_stmt
@@ -766,12 +842,21 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.AlterIndexCmds> alter_index_cmds
 
 %type <tree.DropBehavior> opt_drop_behavior
+%type <tree.TriggerActionTime> trigger_action_time
+%type <tree.TriggerEvent> trigger_event
+%type <tree.TriggerEvents> trigger_event_list
+%type <tree.TriggerLevel> trigger_level
+%type <bool> opt_or_replace
+%type <tree.FunctionParam> func_param
+%type <tree.FunctionParams> opt_func_param_list func_param_list
 %type <tree.DropBehavior> opt_interleave_drop_behavior
 
 %type <tree.ValidationBehavior> opt_validate_behavior
 
 %type <str> opt_template_clause opt_encoding_clause opt_lc_collate_clause opt_lc_ctype_clause
 %type <tree.Expr> opt_password
+%type <tree.Expr> opt_valid_until
+%type <str> opt_execute_as
 
 %type <tree.IsolationLevel> transaction_iso_level
 %type <tree.UserPriority> transaction_user_priority
@@ -829,11 +914,18 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.UpdateExpr> set_clause multiple_set_clause
 %type <tree.ArraySubscripts> array_subscripts
 %type <tree.GroupBy> group_clause
-%type <*tree.Limit> select_limit
+%type <tree.Exprs> group_by_list
+%type <tree.Expr> group_by_item
+%type <*tree.Limit> select_limit opt_select_limit
 %type <tree.TableNames> relation_expr_list
+%type <tree.LockingClause> for_locking_clause opt_for_locking_clause for_locking_items
+%type <*tree.LockingItem> for_locking_item
+%type <tree.LockingStrength> for_locking_strength
+%type <tree.LockingWaitPolicy> opt_nowait_or_skip
+%type <tree.TableNames> opt_locked_rels
 %type <tree.ReturningClause> returning_clause
 
-%type <[]tree.SequenceOption> sequence_option_list opt_sequence_option_list
+%type <[]tree.SequenceOption> sequence_option_list opt_sequence_option_list opt_identity_options
 %type <tree.SequenceOption> sequence_option_elem
 
 %type <bool> all_or_distinct
@@ -862,6 +954,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <empty> first_or_next
 
 %type <tree.Statement> insert_rest
+%type <tree.OverridingKind> overriding_kind
 %type <tree.NameList> opt_conf_expr opt_col_def_list
 %type <*tree.OnConflict> on_conflict
 
@@ -877,6 +970,8 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.IndexFlags> opt_index_flags
 %type <*tree.IndexFlags> index_flags_param
 %type <*tree.IndexFlags> index_flags_param_list
+%type <*tree.TableSample> opt_tablesample_clause
+%type <tree.Expr> opt_repeatable_clause
 %type <tree.Expr> a_expr b_expr c_expr d_expr
 %type <tree.Expr> substr_from substr_for
 %type <tree.Expr> in_expr
@@ -893,6 +988,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <tree.Expr> numeric_only
 %type <tree.AliasClause> alias_clause opt_alias_clause
 %type <bool> opt_ordinality opt_compact opt_automatic
+%type <bool> opt_view_check_option
 %type <*tree.Order> sortby
 %type <tree.IndexElem> index_elem
 %type <tree.TableExpr> table_ref func_table
@@ -954,8 +1050,9 @@ func newNameFromStr(s string) *tree.Name {
 %type <*tree.With> with_clause opt_with_clause
 %type <[]*tree.CTE> cte_list
 %type <*tree.CTE> common_table_expr
+%type <bool> materialize_clause
 
-%type <empty> within_group_clause
+%type <tree.OrderBy> within_group_clause
 %type <tree.Expr> filter_clause
 %type <tree.Exprs> opt_partition_clause
 %type <tree.Window> window_clause window_definition_list
@@ -963,6 +1060,7 @@ func newNameFromStr(s string) *tree.Name {
 %type <str> opt_existing_window_name
 %type <*tree.WindowFrame> opt_frame_clause
 %type <tree.WindowFrameBounds> frame_extent
+%type <tree.WindowFrameExclusion> opt_frame_exclusion
 %type <*tree.WindowFrameBound> frame_bound
 
 %type <[]tree.ColumnID> opt_tableref_col_list tableref_col_list
@@ -1181,7 +1279,8 @@ alter_sequence_options_stmt:
 // %Help: ALTER USER - change user properties
 // %Category: Priv
 // %Text:
-// ALTER USER [IF EXISTS] <name> WITH PASSWORD <password>
+// ALTER USER [IF EXISTS] <name> WITH PASSWORD <password> [VALID UNTIL <timestamp>]
+// ALTER USER [IF EXISTS] <name> VALID UNTIL <timestamp>
 // %SeeAlso: CREATE USER
 alter_user_stmt:
   alter_user_password_stmt
@@ -1191,10 +1290,12 @@ alter_user_stmt:
 // %Category: DDL
 // %Text:
 // ALTER DATABASE <name> RENAME TO <newname>
+// ALTER DATABASE <name> SET READ { ONLY | WRITE }
 // %SeeAlso: WEBDOCS/alter-database.html
 alter_database_stmt:
   alter_rename_database_stmt
 |  alter_zone_database_stmt
+|  alter_read_mode_database_stmt
 // ALTER DATABASE has its error help token here because the ALTER DATABASE
 // prefix is spread over multiple non-terminals.
 | ALTER DATABASE error // SHOW HELP: ALTER DATABASE
@@ -1346,6 +1447,12 @@ alter_zone_database_stmt:
      $$.val = s
   }
 
+alter_read_mode_database_stmt:
+  ALTER DATABASE database_name SET transaction_read_mode
+  {
+    $$.val = &tree.AlterDatabaseSetReadMode{Name: tree.Name($3), ReadOnly: $5.readWriteMode() == tree.ReadOnly}
+  }
+
 alter_zone_table_stmt:
   ALTER TABLE table_name set_zone_config
   {
@@ -1721,6 +1828,8 @@ import_format:
 //    delimiter = '...'      [CSV, PGCOPY-specific]
 //    nullif = '...'         [CSV, PGCOPY-specific]
 //    comment = '...'        [CSV-specific]
+//    max_rejected_rows = '...' [CSV-specific]
+//    rejected_rows_dir = '...' [CSV-specific]
 //
 // %SeeAlso: CREATE TABLE
 import_stmt:
@@ -1850,13 +1959,14 @@ opt_with_options:
   }
 
 copy_from_stmt:
-  COPY table_name opt_column_list FROM STDIN
+  COPY table_name opt_column_list FROM STDIN opt_with_options
   {
     name := $2.unresolvedObjectName().ToTableName()
     $$.val = &tree.CopyFrom{
        Table: name,
        Columns: $3.nameList(),
        Stdin: true,
+       Options: $6.kvOptions(),
     }
   }
 
@@ -2011,12 +2121,14 @@ comment_text:
 // %Text:
 // CREATE DATABASE, CREATE TABLE, CREATE INDEX, CREATE TABLE AS,
 // CREATE USER, CREATE VIEW, CREATE SEQUENCE, CREATE STATISTICS,
-// CREATE ROLE
+// CREATE ROLE, CREATE TRIGGER, CREATE FUNCTION, CREATE PROCEDURE,
+// CREATE SCHEDULE
 create_stmt:
   create_user_stmt     // EXTEND WITH HELP: CREATE USER
 | create_role_stmt     // EXTEND WITH HELP: CREATE ROLE
 | create_ddl_stmt      // help texts in sub-rule
 | create_stats_stmt    // EXTEND WITH HELP: CREATE STATISTICS
+| create_schedule_stmt // EXTEND WITH HELP: CREATE SCHEDULE
 | create_unsupported   {}
 | CREATE error         // SHOW HELP: CREATE
 
@@ -2030,8 +2142,6 @@ create_unsupported:
 | CREATE EXTENSION name error { return unimplemented(sqllex, "create extension " + $3) }
 | CREATE FOREIGN TABLE error { return unimplemented(sqllex, "create foreign table") }
 | CREATE FOREIGN DATA error { return unimplemented(sqllex, "create fdw") }
-| CREATE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
-| CREATE OR REPLACE FUNCTION error { return unimplementedWithIssueDetail(sqllex, 17511, "create function") }
 | CREATE opt_or_replace opt_trusted opt_procedural LANGUAGE name error { return unimplementedWithIssueDetail(sqllex, 17511, "create language " + $6) }
 | CREATE MATERIALIZED VIEW error { return unimplementedWithIssue(sqllex, 24747) }
 | CREATE OPERATOR error { return unimplemented(sqllex, "create operator") }
@@ -2041,11 +2151,16 @@ create_unsupported:
 | CREATE SERVER error { return unimplemented(sqllex, "create server") }
 | CREATE SUBSCRIPTION error { return unimplemented(sqllex, "create subscription") }
 | CREATE TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "create text") }
-| CREATE TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "create") }
 
 opt_or_replace:
-  OR REPLACE {}
-| /* EMPTY */ {}
+  OR REPLACE
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
 
 opt_trusted:
   TRUSTED {}
@@ -2075,7 +2190,6 @@ drop_unsupported:
 | DROP SUBSCRIPTION error { return unimplemented(sqllex, "drop subscription") }
 | DROP TEXT error { return unimplementedWithIssueDetail(sqllex, 7821, "drop text") }
 | DROP TYPE error { return unimplementedWithIssueDetail(sqllex, 27793, "drop type") }
-| DROP TRIGGER error { return unimplementedWithIssueDetail(sqllex, 28296, "drop") }
 
 create_ddl_stmt:
   create_changefeed_stmt
@@ -2088,13 +2202,16 @@ create_ddl_stmt:
 | create_type_stmt     { /* SKIP DOC */ }
 | create_view_stmt     // EXTEND WITH HELP: CREATE VIEW
 | create_sequence_stmt // EXTEND WITH HELP: CREATE SEQUENCE
+| create_trigger_stmt  // EXTEND WITH HELP: CREATE TRIGGER
+| create_function_stmt // EXTEND WITH HELP: CREATE FUNCTION
+| create_procedure_stmt // EXTEND WITH HELP: CREATE PROCEDURE
 
 // %Help: CREATE STATISTICS - create a new table statistic
 // %Category: Misc
 // %Text:
 // CREATE STATISTICS <statisticname>
-//   [ON <colname> [, ...]]
-//   FROM <tablename> [AS OF SYSTEM TIME <expr>]
+//   [ON <colname> [, ...] | ON ( <expr> )]
+//   FROM <tablename> [USING EXTREMES | AS OF SYSTEM TIME <expr>]
 create_stats_stmt:
   CREATE STATISTICS statistics_name opt_stats_columns FROM create_stats_target opt_create_stats_options
   {
@@ -2105,6 +2222,15 @@ create_stats_stmt:
       Options: *$7.createStatsOptions(),
     }
   }
+| CREATE STATISTICS statistics_name ON '(' a_expr ')' FROM create_stats_target opt_create_stats_options
+  {
+    $$.val = &tree.CreateStats{
+      Name: tree.Name($3),
+      Expr: $6.expr(),
+      Table: $9.tblExpr(),
+      Options: *$10.createStatsOptions(),
+    }
+  }
 | CREATE STATISTICS error // SHOW HELP: CREATE STATISTICS
 
 opt_stats_columns:
@@ -2145,6 +2271,12 @@ opt_create_stats_options:
       AsOf: $1.asOfClause(),
     }
   }
+| USING EXTREMES
+  {
+    $$.val = &tree.CreateStatsOptions{
+      UsingExtremes: true,
+    }
+  }
 | /* EMPTY */
   {
     $$.val = &tree.CreateStatsOptions{}
@@ -2184,6 +2316,12 @@ create_stats_option:
       AsOf: $1.asOfClause(),
     }
   }
+| USING EXTREMES
+  {
+    $$.val = &tree.CreateStatsOptions{
+      UsingExtremes: true,
+    }
+  }
 
 create_changefeed_stmt:
   CREATE CHANGEFEED FOR changefeed_targets opt_changefeed_sink opt_with_options
@@ -2253,6 +2391,14 @@ delete_stmt:
       Limit: $7.limit(),
       Returning: $8.retClause(),
     }
+    sqllex.(*lexer).recordRanges($$.stmt(),
+      clausePos{ClauseWith, $<pos>1, $1.with() != nil},
+      clausePos{noClause, $<pos>2, true},
+      clausePos{ClauseWhere, $<pos>5, $5.expr() != nil},
+      clausePos{ClauseOrderBy, $<pos>6, $6.orderBy() != nil},
+      clausePos{ClauseLimit, $<pos>7, $7.limit() != nil},
+      clausePos{ClauseReturning, $<pos>8, tree.HasReturningClause($8.retClause())},
+    )
   }
 | opt_with_clause DELETE error // SHOW HELP: DELETE
 
@@ -2274,11 +2420,12 @@ discard_stmt:
 // %Category: Group
 // %Text:
 // DROP DATABASE, DROP INDEX, DROP TABLE, DROP VIEW, DROP SEQUENCE,
-// DROP USER, DROP ROLE
+// DROP USER, DROP ROLE, DROP TRIGGER, DROP PROCEDURE, DROP SCHEDULE
 drop_stmt:
   drop_ddl_stmt      // help texts in sub-rule
 | drop_role_stmt     // EXTEND WITH HELP: DROP ROLE
 | drop_user_stmt     // EXTEND WITH HELP: DROP USER
+| drop_schedule_stmt // EXTEND WITH HELP: DROP SCHEDULE
 | drop_unsupported   {}
 | DROP error         // SHOW HELP: DROP
 
@@ -2288,6 +2435,53 @@ drop_ddl_stmt:
 | drop_table_stmt    // EXTEND WITH HELP: DROP TABLE
 | drop_view_stmt     // EXTEND WITH HELP: DROP VIEW
 | drop_sequence_stmt // EXTEND WITH HELP: DROP SEQUENCE
+| drop_trigger_stmt  // EXTEND WITH HELP: DROP TRIGGER
+| drop_procedure_stmt // EXTEND WITH HELP: DROP PROCEDURE
+
+// %Help: DROP TRIGGER - remove a trigger
+// %Category: DDL
+// %Text: DROP TRIGGER [IF EXISTS] <name> ON <tablename>
+// %SeeAlso: CREATE TRIGGER
+drop_trigger_stmt:
+  DROP TRIGGER name ON table_name
+  {
+    $$.val = &tree.DropTrigger{Name: tree.Name($3), Table: $5.unresolvedObjectName().ToTableName()}
+  }
+| DROP TRIGGER IF EXISTS name ON table_name
+  {
+    $$.val = &tree.DropTrigger{Name: tree.Name($5), Table: $7.unresolvedObjectName().ToTableName(), IfExists: true}
+  }
+| DROP TRIGGER error // SHOW HELP: DROP TRIGGER
+
+// %Help: DROP PROCEDURE - remove a stored procedure
+// %Category: DDL
+// %Text: DROP PROCEDURE [IF EXISTS] <name>
+// %SeeAlso: CREATE PROCEDURE, CALL
+drop_procedure_stmt:
+  DROP PROCEDURE db_object_name
+  {
+    $$.val = &tree.DropProcedure{Name: $3.unresolvedObjectName().ToTableName()}
+  }
+| DROP PROCEDURE IF EXISTS db_object_name
+  {
+    $$.val = &tree.DropProcedure{Name: $5.unresolvedObjectName().ToTableName(), IfExists: true}
+  }
+| DROP PROCEDURE error // SHOW HELP: DROP PROCEDURE
+
+// %Help: DROP SCHEDULE - remove a schedule
+// %Category: Misc
+// %Text: DROP SCHEDULE [IF EXISTS] <name>
+// %SeeAlso: CREATE SCHEDULE, SHOW SCHEDULES
+drop_schedule_stmt:
+  DROP SCHEDULE name
+  {
+    $$.val = &tree.DropSchedule{Name: tree.Name($3)}
+  }
+| DROP SCHEDULE IF EXISTS name
+  {
+    $$.val = &tree.DropSchedule{Name: tree.Name($5), IfExists: true}
+  }
+| DROP SCHEDULE error // SHOW HELP: DROP SCHEDULE
 
 // %Help: DROP VIEW - remove a view
 // %Category: DDL
@@ -2465,6 +2659,7 @@ explain_stmt:
 preparable_stmt:
   alter_stmt        // help texts in sub-rule
 | backup_stmt       // EXTEND WITH HELP: BACKUP
+| call_stmt         // EXTEND WITH HELP: CALL
 | cancel_stmt       // help texts in sub-rule
 | create_stmt       // help texts in sub-rule
 | delete_stmt       // EXTEND WITH HELP: DELETE
@@ -2472,10 +2667,10 @@ preparable_stmt:
 | explain_stmt      // EXTEND WITH HELP: EXPLAIN
 | import_stmt       // EXTEND WITH HELP: IMPORT
 | insert_stmt       // EXTEND WITH HELP: INSERT
-| pause_stmt        // EXTEND WITH HELP: PAUSE JOBS
+| pause_stmt        // help texts in sub-rule
 | reset_stmt        // help texts in sub-rule
 | restore_stmt      // EXTEND WITH HELP: RESTORE
-| resume_stmt       // EXTEND WITH HELP: RESUME JOBS
+| resume_stmt       // help texts in sub-rule
 | scrub_stmt        // help texts in sub-rule
 | select_stmt       // help texts in sub-rule
   {
@@ -2592,6 +2787,8 @@ deallocate_stmt:
 // %Text:
 // Grant privileges:
 //   GRANT {ALL | <privileges...> } ON <targets...> TO <grantees...>
+// Grant column privileges:
+//   GRANT SELECT (<columns...>) ON [TABLE] <tablename> [, ...] TO <grantees...>
 // Grant role membership (CCL only):
 //   GRANT <roles...> TO <grantees...> [WITH ADMIN OPTION]
 //
@@ -2608,6 +2805,10 @@ grant_stmt:
   {
     $$.val = &tree.Grant{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| GRANT privileges '(' name_list ')' ON targets TO name_list
+  {
+    $$.val = &tree.Grant{Privileges: $2.privilegeList(), Columns: $4.nameList(), Grantees: $9.nameList(), Targets: $7.targetList()}
+  }
 | GRANT privilege_list TO name_list
   {
     $$.val = &tree.GrantRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false}
@@ -2623,6 +2824,8 @@ grant_stmt:
 // %Text:
 // Revoke privileges:
 //   REVOKE {ALL | <privileges...> } ON <targets...> FROM <grantees...>
+// Revoke column privileges:
+//   REVOKE SELECT (<columns...>) ON [TABLE] <tablename> [, ...] FROM <grantees...>
 // Revoke role membership (CCL only):
 //   REVOKE [ADMIN OPTION FOR] <roles...> FROM <grantees...>
 //
@@ -2639,6 +2842,10 @@ revoke_stmt:
   {
     $$.val = &tree.Revoke{Privileges: $2.privilegeList(), Grantees: $6.nameList(), Targets: $4.targetList()}
   }
+| REVOKE privileges '(' name_list ')' ON targets FROM name_list
+  {
+    $$.val = &tree.Revoke{Privileges: $2.privilegeList(), Columns: $4.nameList(), Grantees: $9.nameList(), Targets: $7.targetList()}
+  }
 | REVOKE privilege_list FROM name_list
   {
     $$.val = &tree.RevokeRole{Roles: $2.nameList(), Members: $4.nameList(), AdminOption: false }
@@ -3088,10 +3295,10 @@ zone_value:
 // %Category: Group
 // %Text:
 // SHOW BACKUP, SHOW CLUSTER SETTING, SHOW COLUMNS, SHOW CONSTRAINTS,
-// SHOW CREATE, SHOW DATABASES, SHOW HISTOGRAM, SHOW INDEXES, SHOW
-// JOBS, SHOW QUERIES, SHOW ROLES, SHOW SCHEMAS, SHOW SEQUENCES, SHOW
-// SESSION, SHOW SESSIONS, SHOW STATISTICS, SHOW SYNTAX, SHOW TABLES,
-// SHOW TRACE SHOW TRANSACTION, SHOW USERS
+// SHOW CREATE, SHOW DATABASES, SHOW EVENTS, SHOW HISTOGRAM, SHOW
+// INDEXES, SHOW JOBS, SHOW QUERIES, SHOW ROLES, SHOW SCHEDULES, SHOW
+// SCHEMAS, SHOW SEQUENCES, SHOW SESSION, SHOW SESSIONS, SHOW STATISTICS,
+// SHOW SYNTAX, SHOW TABLES, SHOW TRACE SHOW TRANSACTION, SHOW USERS
 show_stmt:
   show_backup_stmt          // EXTEND WITH HELP: SHOW BACKUP
 | show_columns_stmt         // EXTEND WITH HELP: SHOW COLUMNS
@@ -3099,6 +3306,7 @@ show_stmt:
 | show_create_stmt          // EXTEND WITH HELP: SHOW CREATE
 | show_csettings_stmt       // EXTEND WITH HELP: SHOW CLUSTER SETTING
 | show_databases_stmt       // EXTEND WITH HELP: SHOW DATABASES
+| show_events_stmt          // EXTEND WITH HELP: SHOW EVENTS
 | show_fingerprints_stmt
 | show_grants_stmt          // EXTEND WITH HELP: SHOW GRANTS
 | show_histogram_stmt       // EXTEND WITH HELP: SHOW HISTOGRAM
@@ -3107,6 +3315,7 @@ show_stmt:
 | show_queries_stmt         // EXTEND WITH HELP: SHOW QUERIES
 | show_ranges_stmt          // EXTEND WITH HELP: SHOW RANGES
 | show_roles_stmt           // EXTEND WITH HELP: SHOW ROLES
+| show_schedules_stmt       // EXTEND WITH HELP: SHOW SCHEDULES
 | show_schemas_stmt         // EXTEND WITH HELP: SHOW SCHEMAS
 | show_sequences_stmt       // EXTEND WITH HELP: SHOW SEQUENCES
 | show_session_stmt         // EXTEND WITH HELP: SHOW SESSION
@@ -3262,6 +3471,25 @@ show_databases_stmt:
   }
 | SHOW DATABASES error // SHOW HELP: SHOW DATABASES
 
+// %Help: SHOW EVENTS - list the events of the event log
+// %Category: Misc
+// %Text:
+// SHOW EVENTS [WITH <option> [= <value>] [, ...]]
+//
+// Options:
+//    type = '<event type>[,...]'
+//    channel = '{OPS | SQL_SCHEMA | PRIVILEGES | SESSIONS}[,...]'
+//    target = '<target id>'
+//    since = '<interval>'
+//
+// %SeeAlso: SHOW JOBS
+show_events_stmt:
+  SHOW EVENTS opt_with_options
+  {
+    $$.val = &tree.ShowEvents{Options: $3.kvOptions()}
+  }
+| SHOW EVENTS error // SHOW HELP: SHOW EVENTS
+
 // %Help: SHOW GRANTS - list grants
 // %Category: Priv
 // %Text:
@@ -3364,6 +3592,17 @@ opt_automatic:
   AUTOMATIC { $$.val = true }
 | /* EMPTY */ { $$.val = false }
 
+// %Help: SHOW SCHEDULES - list schedules
+// %Category: Misc
+// %Text: SHOW SCHEDULES
+// %SeeAlso: CREATE SCHEDULE, PAUSE SCHEDULE, RESUME SCHEDULE, DROP SCHEDULE
+show_schedules_stmt:
+  SHOW SCHEDULES
+  {
+    $$.val = &tree.ShowSchedules{}
+  }
+| SHOW SCHEDULES error // SHOW HELP: SHOW SCHEDULES
+
 // %Help: SHOW TRACE - display an execution trace
 // %Category: Misc
 // %Text:
@@ -3827,13 +4066,21 @@ for_grantee_clause:
     $$.val = tree.NameList(nil)
   }
 
+// %Help: PAUSE
+// %Category: Group
+// %Text: PAUSE JOBS, PAUSE SCHEDULE
+pause_stmt:
+  pause_jobs_stmt     // EXTEND WITH HELP: PAUSE JOBS
+| pause_schedule_stmt // EXTEND WITH HELP: PAUSE SCHEDULE
+| PAUSE error         // SHOW HELP: PAUSE
+
 // %Help: PAUSE JOBS - pause background jobs
 // %Category: Misc
 // %Text:
 // PAUSE JOBS <selectclause>
 // PAUSE JOB <jobid>
 // %SeeAlso: SHOW JOBS, CANCEL JOBS, RESUME JOBS
-pause_stmt:
+pause_jobs_stmt:
   PAUSE JOB a_expr
   {
     $$.val = &tree.ControlJobs{
@@ -3843,11 +4090,23 @@ pause_stmt:
       Command: tree.PauseJob,
     }
   }
+| PAUSE JOB error // SHOW HELP: PAUSE JOBS
 | PAUSE JOBS select_stmt
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.PauseJob}
   }
-| PAUSE error // SHOW HELP: PAUSE JOBS
+| PAUSE JOBS error // SHOW HELP: PAUSE JOBS
+
+// %Help: PAUSE SCHEDULE - pause a schedule
+// %Category: Misc
+// %Text: PAUSE SCHEDULE <name>
+// %SeeAlso: SHOW SCHEDULES, RESUME SCHEDULE, CREATE SCHEDULE
+pause_schedule_stmt:
+  PAUSE SCHEDULE name
+  {
+    $$.val = &tree.ControlSchedule{Name: tree.Name($3), Command: tree.PauseSchedule}
+  }
+| PAUSE SCHEDULE error // SHOW HELP: PAUSE SCHEDULE
 
 // %Help: CREATE TABLE - create a new table
 // %Category: DDL
@@ -3873,7 +4132,8 @@ pause_stmt:
 //   FAMILY <familyname>, CREATE [IF NOT EXISTS] FAMILY [<familyname>]
 //   REFERENCES <tablename> [( <colnames...> )] [ON DELETE {NO ACTION | RESTRICT}] [ON UPDATE {NO ACTION | RESTRICT}]
 //   COLLATE <collationname>
-//   AS ( <expr> ) STORED
+//   AS ( <expr> ) {STORED | VIRTUAL}
+//   GENERATED {ALWAYS | BY DEFAULT} AS IDENTITY [( <sequence options...> )]
 //
 // Interleave clause:
 //    INTERLEAVE IN PARENT <tablename> ( <colnames...> ) [CASCADE | RESTRICT]
@@ -4212,13 +4472,31 @@ col_qualification_elem:
  }
 | AS '(' a_expr ')' VIRTUAL
  {
-    return unimplemented(sqllex, "virtual computed columns")
+    $$.val = &tree.ColumnComputedDef{Expr: $3.expr(), Virtual: true}
  }
 | AS error
  {
-    sqllex.Error("syntax error: use AS ( <expr> ) STORED")
+    sqllex.Error("syntax error: use AS ( <expr> ) STORED or AS ( <expr> ) VIRTUAL")
     return 1
  }
+| GENERATED_LA ALWAYS AS IDENTITY opt_identity_options
+ {
+    $$.val = &tree.ColumnIdentityDef{Always: true, SeqOptions: $5.seqOpts()}
+ }
+| GENERATED_LA BY DEFAULT AS IDENTITY opt_identity_options
+ {
+    $$.val = &tree.ColumnIdentityDef{SeqOptions: $6.seqOpts()}
+ }
+
+opt_identity_options:
+  '(' sequence_option_list ')'
+  {
+    $$.val = $2.seqOpts()
+  }
+| /* EMPTY */
+  {
+    $$.val = []tree.SequenceOption(nil)
+  }
 
 index_def:
   INDEX opt_index_name '(' index_params ')' opt_storing opt_interleave opt_partition_by
@@ -4537,16 +4815,16 @@ truncate_stmt:
 
 // %Help: CREATE USER - define a new user
 // %Category: Priv
-// %Text: CREATE USER [IF NOT EXISTS] <name> [ [WITH] PASSWORD <passwd> ]
+// %Text: CREATE USER [IF NOT EXISTS] <name> [ [WITH] PASSWORD <passwd> ] [VALID UNTIL <timestamp>]
 // %SeeAlso: DROP USER, SHOW USERS, WEBDOCS/create-user.html
 create_user_stmt:
-  CREATE USER string_or_placeholder opt_password
+  CREATE USER string_or_placeholder opt_password opt_valid_until
   {
-    $$.val = &tree.CreateUser{Name: $3.expr(), Password: $4.expr()}
+    $$.val = &tree.CreateUser{Name: $3.expr(), Password: $4.expr(), ValidUntil: $5.expr()}
   }
-| CREATE USER IF NOT EXISTS string_or_placeholder opt_password
+| CREATE USER IF NOT EXISTS string_or_placeholder opt_password opt_valid_until
   {
-    $$.val = &tree.CreateUser{Name: $6.expr(), Password: $7.expr(), IfNotExists: true}
+    $$.val = &tree.CreateUser{Name: $6.expr(), Password: $7.expr(), ValidUntil: $8.expr(), IfNotExists: true}
   }
 | CREATE USER error // SHOW HELP: CREATE USER
 
@@ -4560,6 +4838,16 @@ opt_password:
     $$.val = nil
   }
 
+opt_valid_until:
+  VALID UNTIL string_or_placeholder
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = nil
+  }
+
 // %Help: CREATE ROLE - define a new role
 // %Category: Priv
 // %Text: CREATE ROLE [IF NOT EXISTS] <name>
@@ -4581,18 +4869,253 @@ role_or_group:
   ROLE  { }
 | GROUP { /* SKIP DOC */ }
 
+// %Help: CREATE TRIGGER - create a new trigger
+// %Category: DDL
+// %Text:
+// CREATE TRIGGER <name> { BEFORE | AFTER } <event> [OR <event> ...]
+//   ON <tablename> FOR EACH { ROW | STATEMENT } AS '<body>'
+//
+// Events:
+//   INSERT, UPDATE, DELETE
+//
+// A row-level trigger is fired once for every modified row, and a
+// statement-level trigger once for every statement.
+//
+// The body is a block of statements:
+//   BEGIN
+//     NEW.<colname> := <expr>;
+//     IF <expr> THEN <statements> [ELSIF <expr> THEN <statements>] [ELSE <statements>] END IF;
+//     RAISE EXCEPTION '<message>' [, <expr> ...];
+//     RETURN { NEW | OLD | NULL };
+//     <INSERT, UPDATE, DELETE, UPSERT or SELECT statement>;
+//   END
+// %SeeAlso: DROP TRIGGER
+create_trigger_stmt:
+  CREATE TRIGGER name trigger_action_time trigger_event_list ON table_name FOR EACH trigger_level AS SCONST
+  {
+    $$.val = &tree.CreateTrigger{
+      Name: tree.Name($3),
+      ActionTime: $4.triggerActionTime(),
+      Events: $5.triggerEvents(),
+      Table: $7.unresolvedObjectName().ToTableName(),
+      Level: $10.triggerLevel(),
+      Body: $12,
+    }
+  }
+| CREATE TRIGGER error // SHOW HELP: CREATE TRIGGER
+
+trigger_action_time:
+  BEFORE
+  {
+    $$.val = tree.TriggerBefore
+  }
+| AFTER
+  {
+    $$.val = tree.TriggerAfter
+  }
+| INSTEAD OF
+  {
+    return unimplemented(sqllex, "instead of trigger")
+  }
+
+trigger_level:
+  ROW
+  {
+    $$.val = tree.TriggerRow
+  }
+| STATEMENT
+  {
+    $$.val = tree.TriggerStatement
+  }
+
+trigger_event_list:
+  trigger_event
+  {
+    $$.val = tree.TriggerEvents{$1.triggerEvent()}
+  }
+| trigger_event_list OR trigger_event
+  {
+    $$.val = append($1.triggerEvents(), $3.triggerEvent())
+  }
+
+trigger_event:
+  INSERT
+  {
+    $$.val = tree.TriggerInsert
+  }
+| UPDATE
+  {
+    $$.val = tree.TriggerUpdate
+  }
+| DELETE
+  {
+    $$.val = tree.TriggerDelete
+  }
+
+// %Help: CREATE FUNCTION - create a new user-defined function
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] FUNCTION <name> ( [[<paramname>] <type> [, ...]] )
+//   RETURNS <type> LANGUAGE { sql | plpgsql } AS '<body>'
+//
+// The body of a sql function is a list of INSERT, UPDATE, DELETE, UPSERT or
+// SELECT statements separated by semicolons; the function returns the first
+// row of the last one, which must return rows.
+//
+// The body of a plpgsql function is a block:
+//   [DECLARE <declarations>]
+//   BEGIN
+//     <statements>
+//   END
+//
+// The parameters can be referenced by name in the body, or by position as
+// $1, $2, etc.
+create_function_stmt:
+  CREATE opt_or_replace FUNCTION func_name '(' opt_func_param_list ')' RETURNS cast_target LANGUAGE non_reserved_word_or_sconst AS SCONST
+  {
+    fn, err := newCreateFunction($2.bool(), $4.unresolvedName(), $6.functionParams(), $9.castTargetType(), $11, $13)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = fn
+  }
+| CREATE opt_or_replace FUNCTION func_name '(' opt_func_param_list ')' RETURNS cast_target AS SCONST LANGUAGE non_reserved_word_or_sconst
+  {
+    fn, err := newCreateFunction($2.bool(), $4.unresolvedName(), $6.functionParams(), $9.castTargetType(), $13, $11)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = fn
+  }
+| CREATE opt_or_replace FUNCTION error // SHOW HELP: CREATE FUNCTION
+
+opt_func_param_list:
+  func_param_list
+| /* EMPTY */
+  {
+    $$.val = tree.FunctionParams(nil)
+  }
+
+func_param_list:
+  func_param
+  {
+    $$.val = tree.FunctionParams{$1.functionParam()}
+  }
+| func_param_list ',' func_param
+  {
+    $$.val = append($1.functionParams(), $3.functionParam())
+  }
+
+// The name of a parameter is restricted to identifiers, since most type names
+// are keywords.
+func_param:
+  IDENT cast_target
+  {
+    $$.val = tree.FunctionParam{Name: tree.Name($1), Type: $2.castTargetType()}
+  }
+| cast_target
+  {
+    $$.val = tree.FunctionParam{Type: $1.castTargetType()}
+  }
+
+// %Help: CREATE PROCEDURE - create a new stored procedure
+// %Category: DDL
+// %Text:
+// CREATE [OR REPLACE] PROCEDURE <name> ( [[<paramname>] <type> [, ...]] )
+//   LANGUAGE { sql | plpgsql } AS '<body>'
+//
+// The body of a sql procedure is a list of INSERT, UPDATE, DELETE, UPSERT or
+// SELECT statements separated by semicolons. The body of a plpgsql procedure
+// is a block, as for CREATE FUNCTION, whose RETURN statements have no value.
+//
+// The parameters can be referenced by name in the body, or by position as
+// $1, $2, etc.
+// %SeeAlso: CALL, DROP PROCEDURE, CREATE FUNCTION
+create_procedure_stmt:
+  CREATE opt_or_replace PROCEDURE db_object_name '(' opt_func_param_list ')' LANGUAGE non_reserved_word_or_sconst AS SCONST
+  {
+    proc, err := newCreateProcedure($2.bool(), $4.unresolvedObjectName().ToTableName(), $6.functionParams(), $9, $11)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = proc
+  }
+| CREATE opt_or_replace PROCEDURE db_object_name '(' opt_func_param_list ')' AS SCONST LANGUAGE non_reserved_word_or_sconst
+  {
+    proc, err := newCreateProcedure($2.bool(), $4.unresolvedObjectName().ToTableName(), $6.functionParams(), $11, $9)
+    if err != nil {
+      return setErr(sqllex, err)
+    }
+    $$.val = proc
+  }
+| CREATE opt_or_replace PROCEDURE error // SHOW HELP: CREATE PROCEDURE
+
+// %Help: CALL - invoke a stored procedure
+// %Category: Misc
+// %Text: CALL <name> ( [<expr> [, ...]] )
+// %SeeAlso: CREATE PROCEDURE, DROP PROCEDURE
+call_stmt:
+  CALL db_object_name '(' opt_expr_list ')'
+  {
+    $$.val = &tree.Call{Name: $2.unresolvedObjectName().ToTableName(), Args: $4.exprs()}
+  }
+| CALL error // SHOW HELP: CALL
+
+// %Help: CREATE SCHEDULE - run a statement on a recurrence
+// %Category: Misc
+// %Text:
+// CREATE SCHEDULE [IF NOT EXISTS] <name> FOR SQL STATEMENT '<statement>'
+//   RECURRING '<cron expression>' [EXECUTE AS <user>]
+//
+// The cron expression is evaluated in UTC and has five fields:
+//   <minute> <hour> <day of month> <month> <day of week>
+// or is one of @hourly, @daily, @weekly, @monthly and @yearly.
+// %SeeAlso: SHOW SCHEDULES, PAUSE SCHEDULE, RESUME SCHEDULE, DROP SCHEDULE
+create_schedule_stmt:
+  CREATE SCHEDULE name FOR SQL STATEMENT SCONST RECURRING SCONST opt_execute_as
+  {
+    $$.val = &tree.CreateSchedule{
+      Name: tree.Name($3),
+      Statement: $7,
+      Recurrence: $9,
+      Owner: tree.Name($10),
+    }
+  }
+| CREATE SCHEDULE IF NOT EXISTS name FOR SQL STATEMENT SCONST RECURRING SCONST opt_execute_as
+  {
+    $$.val = &tree.CreateSchedule{
+      Name: tree.Name($6),
+      IfNotExists: true,
+      Statement: $10,
+      Recurrence: $12,
+      Owner: tree.Name($13),
+    }
+  }
+| CREATE SCHEDULE error // SHOW HELP: CREATE SCHEDULE
+
+opt_execute_as:
+  EXECUTE AS name
+  {
+    $$ = $3
+  }
+| /* EMPTY */
+  {
+    $$ = ""
+  }
+
 // %Help: CREATE VIEW - create a new view
 // %Category: DDL
-// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source>
+// %Text: CREATE VIEW <viewname> [( <colnames...> )] AS <source> [WITH [CASCADED | LOCAL] CHECK OPTION]
 // %SeeAlso: CREATE TABLE, SHOW CREATE, WEBDOCS/create-view.html
 create_view_stmt:
-  CREATE opt_temp opt_view_recursive VIEW view_name opt_column_list AS select_stmt
+  CREATE opt_temp opt_view_recursive VIEW view_name opt_column_list AS select_stmt opt_view_check_option
   {
     name := $5.unresolvedObjectName().ToTableName()
     $$.val = &tree.CreateView{
       Name: name,
       ColumnNames: $6.nameList(),
       AsSource: $8.slct(),
+      CheckOption: $9.bool(),
     }
   }
 | CREATE OR REPLACE opt_temp opt_view_recursive VIEW error { return unimplementedWithIssue(sqllex, 24897) }
@@ -4602,6 +5125,26 @@ opt_view_recursive:
   /* EMPTY */ { /* no error */ }
 | RECURSIVE { return unimplemented(sqllex, "create recursive view") }
 
+// Views only support a single level of filtering on top of a table, so
+// CASCADED and LOCAL check options are equivalent.
+opt_view_check_option:
+  WITH CHECK OPTION
+  {
+    $$.val = true
+  }
+| WITH CASCADED CHECK OPTION
+  {
+    $$.val = true
+  }
+| WITH LOCAL CHECK OPTION
+  {
+    $$.val = true
+  }
+| /* EMPTY */
+  {
+    $$.val = false
+  }
+
 // CREATE TYPE/DOMAIN is not yet supported by CockroachDB but we
 // want to report it with the right issue number.
 create_type_stmt:
@@ -4777,13 +5320,21 @@ alter_rename_database_stmt:
 
 // https://www.postgresql.org/docs/10/static/sql-alteruser.html
 alter_user_password_stmt:
-  ALTER USER string_or_placeholder WITH PASSWORD string_or_placeholder
+  ALTER USER string_or_placeholder WITH PASSWORD string_or_placeholder opt_valid_until
+  {
+    $$.val = &tree.AlterUserSetPassword{Name: $3.expr(), Password: $6.expr(), ValidUntil: $7.expr()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder WITH PASSWORD string_or_placeholder opt_valid_until
   {
-    $$.val = &tree.AlterUserSetPassword{Name: $3.expr(), Password: $6.expr()}
+    $$.val = &tree.AlterUserSetPassword{Name: $5.expr(), Password: $8.expr(), ValidUntil: $9.expr(), IfExists: true}
   }
-| ALTER USER IF EXISTS string_or_placeholder WITH PASSWORD string_or_placeholder
+| ALTER USER string_or_placeholder VALID UNTIL string_or_placeholder
   {
-    $$.val = &tree.AlterUserSetPassword{Name: $5.expr(), Password: $8.expr(), IfExists: true}
+    $$.val = &tree.AlterUserSetPassword{Name: $3.expr(), ValidUntil: $6.expr()}
+  }
+| ALTER USER IF EXISTS string_or_placeholder VALID UNTIL string_or_placeholder
+  {
+    $$.val = &tree.AlterUserSetPassword{Name: $5.expr(), ValidUntil: $8.expr(), IfExists: true}
   }
 
 alter_rename_table_stmt:
@@ -4857,13 +5408,21 @@ release_stmt:
   }
 | RELEASE error // SHOW HELP: RELEASE
 
+// %Help: RESUME
+// %Category: Group
+// %Text: RESUME JOBS, RESUME SCHEDULE
+resume_stmt:
+  resume_jobs_stmt     // EXTEND WITH HELP: RESUME JOBS
+| resume_schedule_stmt // EXTEND WITH HELP: RESUME SCHEDULE
+| RESUME error         // SHOW HELP: RESUME
+
 // %Help: RESUME JOBS - resume background jobs
 // %Category: Misc
 // %Text:
 // RESUME JOBS <selectclause>
 // RESUME JOB <jobid>
 // %SeeAlso: SHOW JOBS, CANCEL JOBS, PAUSE JOBS
-resume_stmt:
+resume_jobs_stmt:
   RESUME JOB a_expr
   {
     $$.val = &tree.ControlJobs{
@@ -4873,11 +5432,23 @@ resume_stmt:
       Command: tree.ResumeJob,
     }
   }
+| RESUME JOB error // SHOW HELP: RESUME JOBS
 | RESUME JOBS select_stmt
   {
     $$.val = &tree.ControlJobs{Jobs: $3.slct(), Command: tree.ResumeJob}
   }
-| RESUME error // SHOW HELP: RESUME JOBS
+| RESUME JOBS error // SHOW HELP: RESUME JOBS
+
+// %Help: RESUME SCHEDULE - resume a paused schedule
+// %Category: Misc
+// %Text: RESUME SCHEDULE <name>
+// %SeeAlso: SHOW SCHEDULES, PAUSE SCHEDULE, CREATE SCHEDULE
+resume_schedule_stmt:
+  RESUME SCHEDULE name
+  {
+    $$.val = &tree.ControlSchedule{Name: tree.Name($3), Command: tree.ResumeSchedule}
+  }
+| RESUME SCHEDULE error // SHOW HELP: RESUME SCHEDULE
 
 // %Help: SAVEPOINT - start a retryable block
 // %Category: Txn
@@ -5146,7 +5717,7 @@ opt_equal:
 // %Category: DML
 // %Text:
 // INSERT INTO <tablename> [[AS] <name>] [( <colnames...> )]
-//        <selectclause>
+//        [OVERRIDING {SYSTEM | USER} VALUE] <selectclause>
 //        [ON CONFLICT [( <colnames...> )] {DO UPDATE SET ... [WHERE <expr>] | DO NOTHING}]
 //        [RETURNING <exprs...>]
 // %SeeAlso: UPSERT, UPDATE, DELETE, WEBDOCS/insert.html
@@ -5157,6 +5728,11 @@ insert_stmt:
     $$.val.(*tree.Insert).With = $1.with()
     $$.val.(*tree.Insert).Table = $4.tblExpr()
     $$.val.(*tree.Insert).Returning = $6.retClause()
+    sqllex.(*lexer).recordRanges($$.stmt(),
+      clausePos{ClauseWith, $<pos>1, $1.with() != nil},
+      clausePos{noClause, $<pos>2, true},
+      clausePos{ClauseReturning, $<pos>6, tree.HasReturningClause($6.retClause())},
+    )
   }
 | opt_with_clause INSERT INTO insert_target insert_rest on_conflict returning_clause
   {
@@ -5165,6 +5741,12 @@ insert_stmt:
     $$.val.(*tree.Insert).Table = $4.tblExpr()
     $$.val.(*tree.Insert).OnConflict = $6.onConflict()
     $$.val.(*tree.Insert).Returning = $7.retClause()
+    sqllex.(*lexer).recordRanges($$.stmt(),
+      clausePos{ClauseWith, $<pos>1, $1.with() != nil},
+      clausePos{noClause, $<pos>2, true},
+      clausePos{ClauseOnConflict, $<pos>6, true},
+      clausePos{ClauseReturning, $<pos>7, tree.HasReturningClause($7.retClause())},
+    )
   }
 | opt_with_clause INSERT error // SHOW HELP: INSERT
 
@@ -5183,6 +5765,11 @@ upsert_stmt:
     $$.val.(*tree.Insert).Table = $4.tblExpr()
     $$.val.(*tree.Insert).OnConflict = &tree.OnConflict{}
     $$.val.(*tree.Insert).Returning = $6.retClause()
+    sqllex.(*lexer).recordRanges($$.stmt(),
+      clausePos{ClauseWith, $<pos>1, $1.with() != nil},
+      clausePos{noClause, $<pos>2, true},
+      clausePos{ClauseReturning, $<pos>6, tree.HasReturningClause($6.retClause())},
+    )
   }
 | opt_with_clause UPSERT error // SHOW HELP: UPSERT
 
@@ -5211,11 +5798,29 @@ insert_rest:
   {
     $$.val = &tree.Insert{Columns: $2.nameList(), Rows: $4.slct()}
   }
+| OVERRIDING overriding_kind VALUE select_stmt
+  {
+    $$.val = &tree.Insert{Overriding: $2.overridingKind(), Rows: $4.slct()}
+  }
+| '(' insert_column_list ')' OVERRIDING overriding_kind VALUE select_stmt
+  {
+    $$.val = &tree.Insert{Columns: $2.nameList(), Overriding: $5.overridingKind(), Rows: $7.slct()}
+  }
 | DEFAULT VALUES
   {
     $$.val = &tree.Insert{Rows: &tree.Select{}}
   }
 
+overriding_kind:
+  SYSTEM
+  {
+    $$.val = tree.OverridingSystemValue
+  }
+| USER
+  {
+    $$.val = tree.OverridingUserValue
+  }
+
 insert_column_list:
   insert_column_item
   {
@@ -5304,6 +5909,15 @@ update_stmt:
       Limit: $9.limit(),
       Returning: $10.retClause(),
     }
+    sqllex.(*lexer).recordRanges($$.stmt(),
+      clausePos{ClauseWith, $<pos>1, $1.with() != nil},
+      clausePos{noClause, $<pos>2, true},
+      clausePos{ClauseSet, $<pos>4, true},
+      clausePos{ClauseWhere, $<pos>7, $7.expr() != nil},
+      clausePos{ClauseOrderBy, $<pos>8, $8.orderBy() != nil},
+      clausePos{ClauseLimit, $<pos>9, $9.limit() != nil},
+      clausePos{ClauseReturning, $<pos>10, tree.HasReturningClause($10.retClause())},
+    )
   }
 | opt_with_clause UPDATE error // SHOW HELP: UPDATE
 
@@ -5405,34 +6019,162 @@ select_with_parens:
 //      clause.
 //      - 2002-08-28 bjm
 select_no_parens:
-  simple_select opt_for
+  simple_select
   {
     $$.val = &tree.Select{Select: $1.selectStmt()}
+    sqllex.(*lexer).recordRanges($$.slct(), clausePos{noClause, $<pos>1, true})
   }
-| select_clause sort_clause opt_for
+| select_clause sort_clause
   {
     $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy()}
+    sqllex.(*lexer).recordRanges($$.slct(),
+      clausePos{noClause, $<pos>1, true},
+      clausePos{ClauseOrderBy, $<pos>2, true},
+    )
+  }
+| select_clause opt_sort_clause for_locking_clause opt_select_limit
+  {
+    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $4.limit(), Locking: $3.lockingClause()}
+    sqllex.(*lexer).recordRanges($$.slct(),
+      clausePos{noClause, $<pos>1, true},
+      clausePos{ClauseOrderBy, $<pos>2, $2.orderBy() != nil},
+      clausePos{ClauseLocking, $<pos>3, true},
+      clausePos{ClauseLimit, $<pos>4, $4.limit() != nil},
+    )
   }
-| select_clause opt_sort_clause select_limit opt_for
+| select_clause opt_sort_clause select_limit opt_for_locking_clause
   {
-    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $3.limit()}
+    $$.val = &tree.Select{Select: $1.selectStmt(), OrderBy: $2.orderBy(), Limit: $3.limit(), Locking: $4.lockingClause()}
+    sqllex.(*lexer).recordRanges($$.slct(),
+      clausePos{noClause, $<pos>1, true},
+      clausePos{ClauseOrderBy, $<pos>2, $2.orderBy() != nil},
+      clausePos{ClauseLimit, $<pos>3, true},
+      clausePos{ClauseLocking, $<pos>4, $4.lockingClause() != nil},
+    )
   }
-| with_clause select_clause opt_for
+| with_clause select_clause
   {
     $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt()}
+    sqllex.(*lexer).recordRanges($$.slct(),
+      clausePos{ClauseWith, $<pos>1, true},
+      clausePos{noClause, $<pos>2, true},
+    )
   }
-| with_clause select_clause sort_clause opt_for
+| with_clause select_clause sort_clause
   {
     $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy()}
+    sqllex.(*lexer).recordRanges($$.slct(),
+      clausePos{ClauseWith, $<pos>1, true},
+      clausePos{noClause, $<pos>2, true},
+      clausePos{ClauseOrderBy, $<pos>3, true},
+    )
   }
-| with_clause select_clause opt_sort_clause select_limit opt_for
+| with_clause select_clause opt_sort_clause for_locking_clause opt_select_limit
   {
-    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit()}
+    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $5.limit(), Locking: $4.lockingClause()}
+    sqllex.(*lexer).recordRanges($$.slct(),
+      clausePos{ClauseWith, $<pos>1, true},
+      clausePos{noClause, $<pos>2, true},
+      clausePos{ClauseOrderBy, $<pos>3, $3.orderBy() != nil},
+      clausePos{ClauseLocking, $<pos>4, true},
+      clausePos{ClauseLimit, $<pos>5, $5.limit() != nil},
+    )
+  }
+| with_clause select_clause opt_sort_clause select_limit opt_for_locking_clause
+  {
+    $$.val = &tree.Select{With: $1.with(), Select: $2.selectStmt(), OrderBy: $3.orderBy(), Limit: $4.limit(), Locking: $5.lockingClause()}
+    sqllex.(*lexer).recordRanges($$.slct(),
+      clausePos{ClauseWith, $<pos>1, true},
+      clausePos{noClause, $<pos>2, true},
+      clausePos{ClauseOrderBy, $<pos>3, $3.orderBy() != nil},
+      clausePos{ClauseLimit, $<pos>4, true},
+      clausePos{ClauseLocking, $<pos>5, $5.lockingClause() != nil},
+    )
   }
 
-opt_for:
-  /* EMPTY */ { /* no error */ }
-| FOR error { return unimplementedWithIssue(sqllex, 6583) }
+// The FOR READ ONLY form is accepted for compatibility with the standard, and
+// does not lock any rows.
+for_locking_clause:
+  for_locking_items
+  {
+    $$.val = $1.lockingClause()
+  }
+| FOR READ ONLY
+  {
+    $$.val = (tree.LockingClause)(nil)
+  }
+
+opt_for_locking_clause:
+  for_locking_clause
+  {
+    $$.val = $1.lockingClause()
+  }
+| /* EMPTY */
+  {
+    $$.val = (tree.LockingClause)(nil)
+  }
+
+for_locking_items:
+  for_locking_item
+  {
+    $$.val = tree.LockingClause{$1.lockingItem()}
+  }
+| for_locking_items for_locking_item
+  {
+    $$.val = append($1.lockingClause(), $2.lockingItem())
+  }
+
+for_locking_item:
+  for_locking_strength opt_locked_rels opt_nowait_or_skip
+  {
+    $$.val = &tree.LockingItem{
+      Strength:   $1.lockingStrength(),
+      Targets:    $2.tableNames(),
+      WaitPolicy: $3.lockingWaitPolicy(),
+    }
+  }
+
+for_locking_strength:
+  FOR UPDATE
+  {
+    $$.val = tree.ForUpdate
+  }
+| FOR NO KEY UPDATE
+  {
+    $$.val = tree.ForNoKeyUpdate
+  }
+| FOR SHARE
+  {
+    $$.val = tree.ForShare
+  }
+| FOR KEY SHARE
+  {
+    $$.val = tree.ForKeyShare
+  }
+
+opt_locked_rels:
+  OF table_name_list
+  {
+    $$.val = $2.tableNames()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.TableNames(nil)
+  }
+
+opt_nowait_or_skip:
+  SKIP LOCKED
+  {
+    $$.val = tree.LockWaitSkip
+  }
+| NOWAIT
+  {
+    $$.val = tree.LockWaitError
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.LockWaitBlock
+  }
 
 select_clause:
 // We only provide help if an open parenthesis is provided, because
@@ -5491,6 +6233,8 @@ simple_select:
 //        [ ORDER BY <expr> [ ASC | DESC ] [, ...] ]
 //        [ LIMIT { <expr> | ALL } ]
 //        [ OFFSET <expr> [ ROW | ROWS ] ]
+//        [ FOR { UPDATE | NO KEY UPDATE | SHARE | KEY SHARE } [ OF <tablename> [, ...] ]
+//              [ NOWAIT | SKIP LOCKED ] ]
 // %SeeAlso: WEBDOCS/select-clause.html
 simple_select_clause:
   SELECT opt_all_clause target_list
@@ -5505,6 +6249,7 @@ simple_select_clause:
       Having:  tree.NewWhere(tree.AstHaving, $7.expr()),
       Window:  $8.window(),
     }
+    sqllex.(*lexer).recordSelectClauseRanges($$.val.(*tree.SelectClause), $<pos>1, $<pos>3, $<pos>4, $<pos>5, $<pos>6, $<pos>7, $<pos>8)
   }
 | SELECT distinct_clause target_list
     from_clause opt_where_clause
@@ -5519,6 +6264,7 @@ simple_select_clause:
       Having:   tree.NewWhere(tree.AstHaving, $7.expr()),
       Window:   $8.window(),
     }
+    sqllex.(*lexer).recordSelectClauseRanges($$.val.(*tree.SelectClause), $<pos>1, $<pos>3, $<pos>4, $<pos>5, $<pos>6, $<pos>7, $<pos>8)
   }
 | SELECT distinct_on_clause target_list
     from_clause opt_where_clause
@@ -5534,6 +6280,7 @@ simple_select_clause:
       Having:     tree.NewWhere(tree.AstHaving, $7.expr()),
       Window:     $8.window(),
     }
+    sqllex.(*lexer).recordSelectClauseRanges($$.val.(*tree.SelectClause), $<pos>1, $<pos>3, $<pos>4, $<pos>5, $<pos>6, $<pos>7, $<pos>8)
   }
 | SELECT error // SHOW HELP: SELECT
 
@@ -5584,7 +6331,7 @@ table_clause:
 // SQL standard WITH clause looks like:
 //
 // WITH [ RECURSIVE ] <query name> [ (<column> [, ...]) ]
-//        AS (query) [ SEARCH or CYCLE clause ]
+//        AS [ [ NOT ] MATERIALIZED ] (query) [ SEARCH or CYCLE clause ]
 //
 // We don't currently support the SEARCH or CYCLE clause.
 //
@@ -5599,7 +6346,10 @@ with_clause:
     /* SKIP DOC */
     $$.val = &tree.With{CTEList: $2.ctes()}
   }
-| WITH RECURSIVE cte_list { return unimplementedWithIssue(sqllex, 21085) }
+| WITH RECURSIVE cte_list
+  {
+    $$.val = &tree.With{Recursive: true, CTEList: $3.ctes()}
+  }
 
 cte_list:
   common_table_expr
@@ -5619,6 +6369,24 @@ common_table_expr:
       Stmt: $5.stmt(),
     }
   }
+| table_alias_name opt_column_list AS materialize_clause '(' preparable_stmt ')'
+  {
+    $$.val = &tree.CTE{
+      Name: tree.AliasClause{Alias: tree.Name($1), Cols: $2.nameList() },
+      Mtr: tree.MaterializeClause{Set: true, Materialize: $4.bool()},
+      Stmt: $6.stmt(),
+    }
+  }
+
+materialize_clause:
+  MATERIALIZED
+  {
+    $$.val = true
+  }
+| NOT MATERIALIZED
+  {
+    $$.val = false
+  }
 
 opt_with:
   WITH {}
@@ -5737,11 +6505,16 @@ select_limit:
     $$.val = $1.limit()
     if $2.limit() != nil {
       $$.val.(*tree.Limit).Count = $2.limit().Count
+      $$.val.(*tree.Limit).WithTies = $2.limit().WithTies
     }
   }
 | limit_clause
 | offset_clause
 
+opt_select_limit:
+  select_limit { $$.val = $1.limit() }
+| /* EMPTY */  { $$.val = (*tree.Limit)(nil) }
+
 opt_limit_clause:
   limit_clause
 | /* EMPTY */ { $$.val = (*tree.Limit)(nil) }
@@ -5760,6 +6533,10 @@ limit_clause:
   {
     $$.val = &tree.Limit{Count: $3.expr()}
   }
+| FETCH first_or_next opt_select_fetch_first_value row_or_rows WITH TIES
+  {
+    $$.val = &tree.Limit{Count: $3.expr(), WithTies: true}
+  }
 
 offset_clause:
   OFFSET a_expr
@@ -5821,7 +6598,7 @@ first_or_next:
 // Each item in the group_clause list is either an expression tree or a
 // GroupingSet node of some type.
 group_clause:
-  GROUP BY expr_list
+  GROUP BY group_by_list
   {
     $$.val = tree.GroupBy($3.exprs())
   }
@@ -5830,6 +6607,31 @@ group_clause:
     $$.val = tree.GroupBy(nil)
   }
 
+group_by_list:
+  group_by_item
+  {
+    $$.val = tree.Exprs{$1.expr()}
+  }
+| group_by_list ',' group_by_item
+  {
+    $$.val = append($1.exprs(), $3.expr())
+  }
+
+group_by_item:
+  a_expr
+| ROLLUP '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.RollupGroupingSet, Exprs: $3.exprs()}
+  }
+| CUBE '(' expr_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.CubeGroupingSet, Exprs: $3.exprs()}
+  }
+| GROUPING SETS '(' group_by_list ')'
+  {
+    $$.val = &tree.GroupingSet{Type: tree.ExplicitGroupingSets, Exprs: $4.exprs()}
+  }
+
 having_clause:
   HAVING a_expr
   {
@@ -5980,7 +6782,7 @@ opt_index_flags:
 //
 // %SeeAlso: WEBDOCS/table-expressions.html
 table_ref:
-  '[' iconst64 opt_tableref_col_list alias_clause ']' opt_index_flags opt_ordinality opt_alias_clause
+  '[' iconst64 opt_tableref_col_list alias_clause ']' opt_index_flags opt_ordinality opt_alias_clause opt_tablesample_clause
   {
     /* SKIP DOC */
     $$.val = &tree.AliasedTableExpr{
@@ -5992,9 +6794,10 @@ table_ref:
         IndexFlags: $6.indexFlags(),
         Ordinality: $7.bool(),
         As:         $8.aliasClause(),
+        Sample:     $9.tableSample(),
     }
   }
-| relation_expr opt_index_flags opt_ordinality opt_alias_clause
+| relation_expr opt_index_flags opt_ordinality opt_alias_clause opt_tablesample_clause
   {
     name := $1.unresolvedObjectName().ToTableName()
     $$.val = &tree.AliasedTableExpr{
@@ -6002,6 +6805,7 @@ table_ref:
       IndexFlags: $2.indexFlags(),
       Ordinality: $3.bool(),
       As:         $4.aliasClause(),
+      Sample:     $5.tableSample(),
     }
   }
 | select_with_parens opt_ordinality opt_alias_clause
@@ -6012,7 +6816,15 @@ table_ref:
       As:         $3.aliasClause(),
     }
   }
-| LATERAL select_with_parens opt_ordinality opt_alias_clause { return unimplementedWithIssueDetail(sqllex, 24560, "select") }
+| LATERAL select_with_parens opt_ordinality opt_alias_clause
+  {
+    $$.val = &tree.AliasedTableExpr{
+      Expr:       &tree.Subquery{Select: $2.selectStmt()},
+      Ordinality: $3.bool(),
+      Lateral:    true,
+      As:         $4.aliasClause(),
+    }
+  }
 | joined_table
   {
     $$.val = $1.tblExpr()
@@ -6026,7 +6838,11 @@ table_ref:
     f := $1.tblExpr()
     $$.val = &tree.AliasedTableExpr{Expr: f, Ordinality: $2.bool(), As: $3.aliasClause()}
   }
-| LATERAL func_table opt_ordinality opt_alias_clause { return unimplementedWithIssueDetail(sqllex, 24560, "srf") }
+| LATERAL func_table opt_ordinality opt_alias_clause
+  {
+    f := $2.tblExpr()
+    $$.val = &tree.AliasedTableExpr{Expr: f, Ordinality: $3.bool(), Lateral: true, As: $4.aliasClause()}
+  }
 // The following syntax is a CockroachDB extension:
 //     SELECT ... FROM [ EXPLAIN .... ] WHERE ...
 //     SELECT ... FROM [ SHOW .... ] WHERE ...
@@ -6156,6 +6972,36 @@ opt_alias_clause:
     $$.val = tree.AliasClause{}
   }
 
+opt_tablesample_clause:
+  TABLESAMPLE name '(' a_expr ')' opt_repeatable_clause
+  {
+    var method tree.TableSampleMethod
+    switch $2 {
+      case "system":
+        method = tree.TableSampleSystem
+      case "bernoulli":
+        method = tree.TableSampleBernoulli
+      default:
+        sqllex.Error("tablesample method " + $2 + " does not exist")
+        return 1
+    }
+    $$.val = &tree.TableSample{Method: method, Percent: $4.expr(), Repeatable: $6.expr()}
+  }
+| /* EMPTY */
+  {
+    $$.val = (*tree.TableSample)(nil)
+  }
+
+opt_repeatable_clause:
+  REPEATABLE '(' a_expr ')'
+  {
+    $$.val = $3.expr()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.Expr(nil)
+  }
+
 as_of_clause:
   AS_LA OF SYSTEM TIME a_expr
   {
@@ -7495,7 +8341,11 @@ d_expr:
   {
     $$.val = $2.expr()
   }
-| GROUPING '(' expr_list ')' { return unimplemented(sqllex, "d_expr grouping") }
+| GROUPING '(' expr_list ')'
+  {
+    $$.val = &tree.FuncExpr{Func: tree.WrapFunction($1), Exprs: $3.exprs()}
+  }
+| GROUPING '(' error { return helpWithFunctionByName(sqllex, $1) }
 
 func_application:
   func_name '(' ')'
@@ -7533,6 +8383,15 @@ func_expr:
   func_application within_group_clause filter_clause over_clause
   {
     f := $1.expr().(*tree.FuncExpr)
+    w := $2.orderBy()
+    if len(w) > 0 {
+      if f.Type == tree.DistinctFuncType {
+        sqllex.Error("cannot use DISTINCT with WITHIN GROUP")
+        return 1
+      }
+      f.AggType = tree.OrderedSetAgg
+      f.OrderBy = w
+    }
     f.Filter = $3.expr()
     f.WindowDef = $4.windowDef()
     $$.val = f
@@ -7716,8 +8575,14 @@ special_function:
 
 // Aggregate decoration clauses
 within_group_clause:
-WITHIN GROUP '(' sort_clause ')' { return unimplemented(sqllex, "within group") }
-| /* EMPTY */ {}
+  WITHIN GROUP '(' sort_clause ')'
+  {
+    $$.val = $4.orderBy()
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.OrderBy(nil)
+  }
 
 filter_clause:
   FILTER '(' WHERE a_expr ')'
@@ -7809,31 +8674,31 @@ opt_partition_clause:
     $$.val = tree.Exprs(nil)
   }
 
-// For frame clauses, we return a tree.WindowDef, but only some fields are used:
-// frameOptions, startOffset, and endOffset.
-//
-// This is only a subset of the full SQL:2008 frame_clause grammar. We don't
-// support <window frame exclusion> yet.
+// For frame clauses, we return a tree.WindowFrame which contains the frame
+// mode, the frame bounds and the frame exclusion.
 opt_frame_clause:
-  RANGE frame_extent
+  RANGE frame_extent opt_frame_exclusion
   {
     $$.val = &tree.WindowFrame{
       Mode: tree.RANGE,
       Bounds: $2.windowFrameBounds(),
+      Exclusion: $3.windowFrameExclusion(),
     }
   }
-| ROWS frame_extent
+| ROWS frame_extent opt_frame_exclusion
   {
     $$.val = &tree.WindowFrame{
       Mode: tree.ROWS,
       Bounds: $2.windowFrameBounds(),
+      Exclusion: $3.windowFrameExclusion(),
     }
   }
-| GROUPS frame_extent
+| GROUPS frame_extent opt_frame_exclusion
   {
     $$.val = &tree.WindowFrame{
       Mode: tree.GROUPS,
       Bounds: $2.windowFrameBounds(),
+      Exclusion: $3.windowFrameExclusion(),
     }
   }
 | /* EMPTY */
@@ -7879,6 +8744,30 @@ frame_extent:
     $$.val = tree.WindowFrameBounds{StartBound: startBound, EndBound: endBound}
   }
 
+// Frame exclusion, as specified by SQL:2011. EXCLUDE NO OTHERS is the same as
+// omitting the clause.
+opt_frame_exclusion:
+  EXCLUDE CURRENT ROW
+  {
+    $$.val = tree.ExcludeCurrentRow
+  }
+| EXCLUDE GROUP
+  {
+    $$.val = tree.ExcludeGroup
+  }
+| EXCLUDE TIES
+  {
+    $$.val = tree.ExcludeTies
+  }
+| EXCLUDE NO OTHERS
+  {
+    $$.val = tree.NoExclusion
+  }
+| /* EMPTY */
+  {
+    $$.val = tree.NoExclusion
+  }
+
 // This is used for both frame start and frame end, with output set up on the
 // assumption it's frame start; the frame_extent productions must reject
 // invalid cases.
@@ -8729,11 +9618,14 @@ unreserved_keyword:
 | ACTION
 | ADD
 | ADMIN
+| AFTER
 | AGGREGATE
 | ALTER
+| ALWAYS
 | AT
 | AUTOMATIC
 | BACKUP
+| BEFORE
 | BEGIN
 | BIGSERIAL
 | BLOB
@@ -8742,8 +9634,10 @@ unreserved_keyword:
 | BYTEA
 | BYTES
 | CACHE
+| CALL
 | CANCEL
 | CASCADE
+| CASCADED
 | CHANGEFEED
 | CLUSTER
 | COLUMNS
@@ -8774,9 +9668,12 @@ unreserved_keyword:
 | DOMAIN
 | DOUBLE
 | DROP
+| EACH
 | ENCODING
 | ENUM
 | ESCAPE
+| EVENTS
+| EXCLUDE
 | EXECUTE
 | EXPERIMENTAL
 | EXPERIMENTAL_AUDIT
@@ -8787,6 +9684,7 @@ unreserved_keyword:
 | EXPLAIN
 | EXPORT
 | EXTENSION
+| EXTREMES
 | FILES
 | FILTER
 | FIRST
@@ -8795,6 +9693,7 @@ unreserved_keyword:
 | FOLLOWING
 | FORCE_INDEX
 | FUNCTION
+| GENERATED
 | GLOBAL
 | GRANTS
 | GROUPS
@@ -8802,6 +9701,7 @@ unreserved_keyword:
 | HIGH
 | HISTOGRAM
 | HOUR
+| IDENTITY
 | IMMEDIATE
 | IMPORT
 | INCREMENT
@@ -8810,6 +9710,7 @@ unreserved_keyword:
 | INET
 | INJECT
 | INSERT
+| INSTEAD
 | INT2
 | INT2VECTOR
 | INT4
@@ -8833,6 +9734,7 @@ unreserved_keyword:
 | LEVEL
 | LIST
 | LOCAL
+| LOCKED
 | LOOKUP
 | LOW
 | MATCH
@@ -8848,6 +9750,7 @@ unreserved_keyword:
 | NEXT
 | NO
 | NORMAL
+| NOWAIT
 | NO_INDEX_JOIN
 | OF
 | OFF
@@ -8859,7 +9762,9 @@ unreserved_keyword:
 | OPTION
 | OPTIONS
 | ORDINALITY
+| OTHERS
 | OVER
+| OVERRIDING
 | OWNED
 | PARENT
 | PARTIAL
@@ -8872,12 +9777,14 @@ unreserved_keyword:
 | PRECEDING
 | PREPARE
 | PRIORITY
+| PROCEDURE
 | PUBLICATION
 | QUERIES
 | QUERY
 | RANGE
 | RANGES
 | READ
+| RECURRING
 | RECURSIVE
 | REF
 | REGCLASS
@@ -8893,6 +9800,7 @@ unreserved_keyword:
 | RESTORE
 | RESTRICT
 | RESUME
+| RETURNS
 | REVOKE
 | ROLE
 | ROLES
@@ -8905,6 +9813,8 @@ unreserved_keyword:
 | STATUS
 | SAVEPOINT
 | SCATTER
+| SCHEDULE
+| SCHEDULES
 | SCHEMA
 | SCHEMAS
 | SCRUB
@@ -8921,12 +9831,16 @@ unreserved_keyword:
 | SESSION
 | SESSIONS
 | SET
+| SETS
+| SHARE
 | SHOW
 | SIMPLE
+| SKIP
 | SMALLSERIAL
 | SNAPSHOT
 | SQL
 | START
+| STATEMENT
 | STATISTICS
 | STDIN
 | STORE
@@ -8945,6 +9859,7 @@ unreserved_keyword:
 | TESTING_RANGES
 | TESTING_RELOCATE
 | TEXT
+| TIES
 | TRACE
 | TRANSACTION
 | TRIGGER
@@ -8956,6 +9871,7 @@ unreserved_keyword:
 | UNCOMMITTED
 | UNKNOWN
 | UNLOGGED
+| UNTIL
 | UPDATE
 | UPSERT
 | UUID
@@ -9059,6 +9975,7 @@ type_func_name_keyword:
 | OVERLAPS
 | RIGHT
 | SIMILAR
+| TABLESAMPLE
 | cockroachdb_extra_type_func_name_keyword
 
 // CockroachDB-specific keywords that can be used in type/function
@@ -0,0 +1,200 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// ParseTriggerBody parses the body of a trigger, as specified in a CREATE
+// TRIGGER statement. See tree.TriggerBody for the statements it can contain.
+//
+// The body is not part of the SQL grammar: it is split into statements using
+// the scanner, and the expressions and SQL statements it contains are then
+// parsed individually.
+func ParseTriggerBody(body string) (tree.TriggerBody, error) {
+	p := triggerBodyParser{plBodyParser{sql: body, kind: "trigger"}}
+	if err := p.scan(); err != nil {
+		return nil, err
+	}
+	return p.parse()
+}
+
+type triggerBodyParser struct {
+	plBodyParser
+}
+
+func (p *triggerBodyParser) parse() (body tree.TriggerBody, err error) {
+	defer recoverPLBodyError(&err)
+
+	p.expectWord("begin")
+	body = p.parseStmts("end")
+	p.expectWord("end")
+	p.expectEnd()
+	if body == nil {
+		body = tree.TriggerBody{}
+	}
+	return body, nil
+}
+
+// parseStmts parses statements until one of the given terminator words is
+// found.
+func (p *triggerBodyParser) parseStmts(terminators ...string) []tree.TriggerStmt {
+	var stmts []tree.TriggerStmt
+	for {
+		t := p.peek()
+		if t.id == 0 {
+			p.syntaxError()
+		}
+		for _, term := range terminators {
+			if t.isWord(term) {
+				return stmts
+			}
+		}
+		stmts = append(stmts, p.parseStmt())
+	}
+}
+
+func (p *triggerBodyParser) parseStmt() tree.TriggerStmt {
+	t := p.peek()
+	var stmt tree.TriggerStmt
+	switch {
+	case t.isWord("if"):
+		stmt = p.parseIf()
+	case t.isWord("raise"):
+		stmt = p.parseRaise()
+	case t.isWord("return"):
+		stmt = p.parseReturn()
+	case t.isWord("new") && p.tokens[p.pos+1].id == '.':
+		stmt = p.parseAssign()
+	default:
+		stmt = p.parseExec()
+	}
+	p.expect(';')
+	return stmt
+}
+
+func (p *triggerBodyParser) parseIf() tree.TriggerStmt {
+	p.expectWord("if")
+	var stmt tree.TriggerIf
+	stmt.Cond = p.parseExpr("then")
+	p.expectWord("then")
+	stmt.Then = p.parseStmts("elsif", "else", "end")
+	for p.peek().isWord("elsif") {
+		p.pos++
+		var elseIf tree.TriggerElseIf
+		elseIf.Cond = p.parseExpr("then")
+		p.expectWord("then")
+		elseIf.Stmts = p.parseStmts("elsif", "else", "end")
+		stmt.ElseIfs = append(stmt.ElseIfs, elseIf)
+	}
+	if p.peek().isWord("else") {
+		p.pos++
+		stmt.Else = p.parseStmts("end")
+		if stmt.Else == nil {
+			stmt.Else = []tree.TriggerStmt{}
+		}
+	}
+	p.expectWord("end")
+	p.expectWord("if")
+	return &stmt
+}
+
+func (p *triggerBodyParser) parseRaise() tree.TriggerStmt {
+	var stmt tree.TriggerRaise
+	stmt.Message, stmt.Args = p.parseRaiseArgs()
+	return &stmt
+}
+
+func (p *triggerBodyParser) parseReturn() tree.TriggerStmt {
+	p.expectWord("return")
+	var stmt tree.TriggerReturn
+	switch t := p.peek(); {
+	case t.isWord("new"):
+		stmt.Value = tree.TriggerReturnNew
+	case t.isWord("old"):
+		stmt.Value = tree.TriggerReturnOld
+	case t.isWord("null"):
+		stmt.Value = tree.TriggerReturnNull
+	default:
+		p.syntaxError()
+	}
+	p.pos++
+	return &stmt
+}
+
+func (p *triggerBodyParser) parseAssign() tree.TriggerStmt {
+	p.expectWord("new")
+	p.expect('.')
+	col := p.parseName()
+	p.expectAssign()
+	return &tree.TriggerAssign{Column: col, Expr: p.parseExpr()}
+}
+
+// parseExec parses a SQL statement, which extends up to the next semicolon.
+func (p *triggerBodyParser) parseExec() tree.TriggerStmt {
+	start := p.pos
+	p.skipToSemicolon()
+	stmt := p.parseSQL(start, p.pos)
+	if stmt.NumPlaceholders > 0 {
+		panic(plBodyError{pgerror.NewError(pgerror.CodeSyntaxError,
+			"placeholders are not supported in trigger bodies")})
+	}
+	return &tree.TriggerExec{Statement: stmt.AST}
+}
+
+// TriggerRecordRef is a reference to a column of the NEW or OLD record of a
+// trigger, as found by FindTriggerRecordRefs.
+type TriggerRecordRef struct {
+	// Record is either "new" or "old".
+	Record string
+	Column string
+	// Start and End are the offsets of the reference in the text.
+	Start, End int
+}
+
+// FindTriggerRecordRefs returns the references to columns of the NEW and OLD
+// records of a trigger in the given text of an expression or statement, in
+// order. A reference is any name of the form NEW.<column> or OLD.<column>
+// which is not itself qualified. As in PostgreSQL, such names refer to the
+// records of the trigger even within subqueries.
+func FindTriggerRecordRefs(sql string) ([]TriggerRecordRef, error) {
+	p := plBodyParser{sql: sql}
+	if err := p.scan(); err != nil {
+		return nil, err
+	}
+	var refs []TriggerRecordRef
+	for i := 0; i+2 < len(p.tokens); i++ {
+		t := &p.tokens[i]
+		if !t.isWord("new") && !t.isWord("old") {
+			continue
+		}
+		if p.tokens[i+1].id != '.' || (i > 0 && p.tokens[i-1].id == '.') {
+			continue
+		}
+		// Any identifier or keyword can be used as a column name after a dot.
+		col := &p.tokens[i+2]
+		if col.id != lex.GetKeywordID(col.str) || p.tokens[i+3].id == '.' {
+			continue
+		}
+		refs = append(refs, TriggerRecordRef{
+			Record: t.str, Column: col.str, Start: t.start, End: col.end,
+		})
+		i += 2
+	}
+	return refs, nil
+}
@@ -0,0 +1,147 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package parser_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestParseTriggerBody(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		body     string
+		expected string
+	}{
+		{`BEGIN END`, `BEGIN END`},
+		{`begin end;`, `BEGIN END`},
+		{`BEGIN RETURN NEW; END`, `BEGIN RETURN NEW; END`},
+		{`BEGIN RETURN old; END`, `BEGIN RETURN OLD; END`},
+		{`BEGIN RETURN NULL; END`, `BEGIN RETURN NULL; END`},
+		{`BEGIN NEW.a := NEW.b + 1; NEW.c = 'x'; END`,
+			`BEGIN NEW.a := new.b + 1; NEW.c := 'x'; END`},
+		{`BEGIN RAISE EXCEPTION 'bad value % for %', NEW.a, length(NEW.b); END`,
+			`BEGIN RAISE EXCEPTION 'bad value % for %', new.a, length(new.b); END`},
+		{`BEGIN RAISE 'done 100%%'; END`, `BEGIN RAISE EXCEPTION 'done 100%%'; END`},
+		{`BEGIN
+		    IF NEW.a > 0 THEN
+		      RETURN NEW;
+		    ELSIF CASE WHEN NEW.b THEN true ELSE false END THEN
+		      RETURN NULL;
+		    ELSE
+		    END IF;
+		  END`,
+			`BEGIN IF new.a > 0 THEN RETURN NEW; ` +
+				`ELSIF CASE WHEN new.b THEN true ELSE false END THEN RETURN NULL; ELSE END IF; END`},
+		{`BEGIN IF (SELECT count(*) FROM t) > 1 THEN IF true THEN RETURN NULL; END IF; END IF; END`,
+			`BEGIN IF (SELECT count(*) FROM t) > 1 THEN IF true THEN RETURN NULL; END IF; END IF; END`},
+		{`BEGIN INSERT INTO audit VALUES (OLD.a, NEW.a, 'then; end'); END`,
+			`BEGIN INSERT INTO audit VALUES (old.a, new.a, 'then; end'); END`},
+		{`BEGIN UPDATE totals SET n = n + 1 WHERE k = NEW.k; DELETE FROM t WHERE a = OLD.a; END`,
+			`BEGIN UPDATE totals SET n = n + 1 WHERE k = new.k; DELETE FROM t WHERE a = old.a; END`},
+	}
+	for _, d := range testData {
+		t.Run(d.body, func(t *testing.T) {
+			body, err := parser.ParseTriggerBody(d.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := tree.AsString(&body); s != d.expected {
+				t.Errorf("expected %s, got %s", d.expected, s)
+			}
+			// The formatted body must parse to the same body.
+			body2, err := parser.ParseTriggerBody(tree.AsString(&body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := tree.AsString(&body2); s != d.expected {
+				t.Errorf("expected %s after round trip, got %s", d.expected, s)
+			}
+		})
+	}
+}
+
+func TestParseTriggerBodyError(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		body     string
+		expected string
+	}{
+		{``, `syntax error in trigger body at or near "EOF"`},
+		{`RETURN NEW;`, `syntax error in trigger body at or near "RETURN"`},
+		{`BEGIN RETURN NEW; END x`, `syntax error in trigger body at or near "x"`},
+		{`BEGIN RETURN NEW END`, `syntax error in trigger body at or near "END"`},
+		{`BEGIN RETURN 1; END`, `syntax error in trigger body at or near "1"`},
+		{`BEGIN RETURN NEW;`, `syntax error in trigger body at or near "EOF"`},
+		{`BEGIN IF true THEN RETURN NULL; END; END`, `syntax error in trigger body at or near ";"`},
+		{`BEGIN IF THEN RETURN NULL; END IF; END`, `syntax error in trigger body at or near "THEN"`},
+		{`BEGIN NEW.a := ; END`, `syntax error in trigger body at or near ";"`},
+		{`BEGIN NEW.a + 1; END`, `syntax error in trigger body at or near "\+"`},
+		{`BEGIN RAISE EXCEPTION NEW.a; END`, `syntax error in trigger body at or near "NEW"`},
+		{`BEGIN RAISE EXCEPTION '% %', 1; END`, `too few parameters specified for RAISE`},
+		{`BEGIN RAISE EXCEPTION 'x', 1; END`, `too many parameters specified for RAISE`},
+		{`BEGIN CREATE TABLE t (a INT); END`, `CREATE TABLE statements are not supported in trigger bodies`},
+		{`BEGIN SELECT $1; END`, `placeholders are not supported in trigger bodies`},
+		{`BEGIN SELEC 1; END`, `syntax error at or near "selec"`},
+		{`BEGIN RAISE 'unterminated; END`, `lexical error: unterminated string`},
+	}
+	for _, d := range testData {
+		t.Run(d.body, func(t *testing.T) {
+			_, err := parser.ParseTriggerBody(d.body)
+			if !testutils.IsError(err, d.expected) {
+				t.Fatalf("expected %q, got %v", d.expected, err)
+			}
+		})
+	}
+}
+
+func TestFindTriggerRecordRefs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{`SELECT 1`, ``},
+		{`new.a + OLD.b`, `new.a old.b`},
+		{`INSERT INTO t VALUES (new.a, 'new.b', t.new.c, new.d.e, new.*)`, `new.a`},
+		{`(SELECT count(*) FROM t WHERE t.k = old."Key" AND t.v = new.select)`, `old.Key new.select`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			refs, err := parser.FindTriggerRecordRefs(d.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var res []string
+			for _, ref := range refs {
+				res = append(res, ref.Record+"."+ref.Column)
+				if s := d.sql[ref.Start:ref.End]; !strings.EqualFold(strings.Replace(s, `"`, ``, -1), ref.Record+"."+ref.Column) {
+					t.Errorf("reference %s.%s has text %q", ref.Record, ref.Column, s)
+				}
+			}
+			if s := strings.Join(res, " "); s != d.expected {
+				t.Errorf("expected %q, got %q", d.expected, s)
+			}
+		})
+	}
+}
@@ -78,6 +78,10 @@ type conn struct {
 	// parser is used to avoid allocating a parser each time.
 	parser parser.Parser
 
+	// parseCache, if set, is consulted before parsing the queries received
+	// from the client. It is shared with the other connections.
+	parseCache *parser.Cache
+
 	// stmtBuf is populated with commands queued for execution by this conn.
 	stmtBuf sql.StmtBuf
 
@@ -156,6 +160,7 @@ func serveConn(
 	metrics *ServerMetrics,
 	reserved mon.BoundAccount,
 	sqlServer *sql.Server,
+	parseCache *parser.Cache,
 	draining func() bool,
 	authOpt authOptions,
 	stopper *stop.Stopper,
@@ -167,6 +172,7 @@ func serveConn(
 	}
 
 	c := newConn(netConn, sArgs, metrics, &sqlServer.GetExecutorConfig().Settings.SV)
+	c.parseCache = parseCache
 
 	// Do the reading of commands from the network.
 	c.serveImpl(ctx, draining, sqlServer, reserved, authOpt, stopper)
@@ -639,6 +645,15 @@ func (c *conn) sendInitialConnData(
 	return connHandler, nil
 }
 
+// parse parses the query received from the client, consulting the parse
+// cache if there is one.
+func (c *conn) parse(query string, nakedIntSize *coltypes.TInt) (parser.Statements, error) {
+	if c.parseCache != nil {
+		return c.parseCache.ParseWithInt(query, nakedIntSize)
+	}
+	return c.parser.ParseWithInt(query, nakedIntSize)
+}
+
 // An error is returned iff the statement buffer has been closed. In that case,
 // the connection should be considered toast.
 func (c *conn) handleSimpleQuery(
@@ -655,7 +670,7 @@ func (c *conn) handleSimpleQuery(
 	tracing.AnnotateTrace()
 
 	startParse := timeutil.Now()
-	stmts, err := c.parser.ParseWithInt(query, unqualifiedIntSize)
+	stmts, err := c.parse(query, unqualifiedIntSize)
 	if err != nil {
 		return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
 	}
@@ -716,13 +731,8 @@ func (c *conn) handleSimpleQuery(
 func (c *conn) handleParse(
 	ctx context.Context, buf *pgwirebase.ReadBuffer, nakedIntSize *coltypes.TInt,
 ) error {
-	// protocolErr is set if a protocol error has to be sent to the client. A
-	// stanza at the bottom of the function pushes instructions for sending this
-	// error.
-	var protocolErr *pgerror.Error
-
 	name, err := buf.GetString()
-	if protocolErr != nil {
+	if err != nil {
 		return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
 	}
 	query, err := buf.GetString()
@@ -732,7 +742,7 @@ func (c *conn) handleParse(
 	// The client may provide type information for (some of) the placeholders.
 	numQArgTypes, err := buf.GetUint16()
 	if err != nil {
-		return err
+		return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
 	}
 	inTypeHints := make([]oid.Oid, numQArgTypes)
 	for i := range inTypeHints {
@@ -744,7 +754,7 @@ func (c *conn) handleParse(
 	}
 
 	startParse := timeutil.Now()
-	stmts, err := c.parser.ParseWithInt(query, nakedIntSize)
+	stmts, err := c.parse(query, nakedIntSize)
 	if err != nil {
 		return c.stmtBuf.Push(ctx, sql.SendError{Err: err})
 	}
@@ -1229,6 +1239,11 @@ func writeErr(
 		msgBuilder.writeTerminatedString(pgErr.Hint)
 	}
 
+	if ok && pgErr.Position > 0 {
+		msgBuilder.putErrFieldMsg(pgwirebase.ServerErrFieldPosition)
+		msgBuilder.writeTerminatedString(strconv.Itoa(int(pgErr.Position)))
+	}
+
 	if ok && pgErr.Source != nil {
 		errCtx := pgErr.Source
 		if errCtx.File != "" {
@@ -1333,7 +1348,10 @@ func (c *conn) Flush(pos sql.CmdPos) error {
 	}
 
 	c.writerState.fi.lastFlushed = pos
-	c.writerState.fi.cmdStarts = make(map[sql.CmdPos]int)
+	// Reuse the map; this runs at least once per batch of commands.
+	for k := range c.writerState.fi.cmdStarts {
+		delete(c.writerState.fi.cmdStarts, k)
+	}
 
 	_ /* n */, err := c.writerState.buf.WriteTo(c.conn)
 	if err != nil {
@@ -1521,7 +1539,15 @@ func (c *conn) handleAuthentication(
 	auth *hba.Conf,
 	execCfg *sql.ExecutorConfig,
 ) error {
+	// method is the authentication method, reported in the sessions log.
+	method := "insecure"
 	sendError := func(err error) error {
+		sql.LogAuthenticationEvent(ctx, execCfg, sql.AuthLogClientAuthenticationFailed, authLogInfo{
+			User:          c.sessionArgs.User,
+			RemoteAddress: c.conn.RemoteAddr().String(),
+			Method:        method,
+			Reason:        err.Error(),
+		})
 		_ /* err */ = writeErr(ctx, &execCfg.Settings.SV, err, &c.msgBuilder, c.conn)
 		return err
 	}
@@ -1545,12 +1571,14 @@ func (c *conn) handleAuthentication(
 
 		if auth == nil {
 			methodFn = authCertPassword
+			method = "cert-password"
 		} else if c.sessionArgs.User == security.RootUser {
 			// If a hba.conf file is specified, hard code the root user to always use
 			// cert auth. This prevents users from shooting themselves in the foot and
 			// making root not able to login, thus disallowing anyone from fixing the
 			// hba.conf file.
 			methodFn = authCert
+			method = "cert"
 		} else {
 			addr, _, err := net.SplitHostPort(c.conn.RemoteAddr().String())
 			if err != nil {
@@ -1589,6 +1617,7 @@ func (c *conn) handleAuthentication(
 					return sendError(errors.Errorf("unknown auth method %s", entry.Method))
 				}
 				hbaEntry = &entry
+				method = entry.Method
 				break
 			}
 			if methodFn == nil {
@@ -1605,11 +1634,26 @@ func (c *conn) handleAuthentication(
 		}
 	}
 
+	sql.LogAuthenticationEvent(ctx, execCfg, sql.AuthLogClientAuthenticationOk, authLogInfo{
+		User:          c.sessionArgs.User,
+		RemoteAddress: c.conn.RemoteAddr().String(),
+		Method:        method,
+	})
+
 	c.msgBuilder.initMsg(pgwirebase.ServerMsgAuth)
 	c.msgBuilder.putInt32(authOK)
 	return c.msgBuilder.finishMsg(c.conn)
 }
 
+// authLogInfo is the payload of the authentication events of the sessions
+// log.
+type authLogInfo struct {
+	User          string
+	RemoteAddress string
+	Method        string
+	Reason        string `json:",omitempty"`
+}
+
 const serverHBAConfSetting = "server.host_based_authentication.configuration"
 
 var connAuthConf = settings.RegisterValidatedStringSetting(
@@ -1822,9 +1866,16 @@ func authPassword(
 	if err != nil {
 		return nil, err
 	}
-	return security.UserAuthPasswordHook(
-		insecure, password, hashedPassword,
-	), nil
+	hook := security.UserAuthPasswordHook(insecure, password, hashedPassword)
+	if insecure {
+		return hook, nil
+	}
+	return func(requestedUser string, clientConnection bool) error {
+		ctx := execCfg.AmbientCtx.AnnotateCtx(context.Background())
+		return sql.CheckPasswordLogin(ctx, execCfg, requestedUser, func() error {
+			return hook(requestedUser, clientConnection)
+		})
+	}, nil
 }
 
 func authCert(
@@ -92,6 +92,8 @@ const (
 	CodeInvalidRegularExpressionError              = "2201B"
 	CodeInvalidRowCountInLimitClauseError          = "2201W"
 	CodeInvalidRowCountInResultOffsetClauseError   = "2201X"
+	CodeInvalidTablesampleArgumentError            = "2202H"
+	CodeInvalidTablesampleRepeatError              = "2202G"
 	CodeInvalidTimeZoneDisplacementValueError      = "22009"
 	CodeInvalidUseOfEscapeCharacterError           = "2200C"
 	CodeMostSpecificTypeMismatchError              = "2200G"
@@ -191,6 +193,7 @@ const (
 	CodeWindowingError                          = "42P20"
 	CodeInvalidRecursionError                   = "42P19"
 	CodeInvalidForeignKeyError                  = "42830"
+	CodeGeneratedAlwaysError                    = "428C9"
 	CodeInvalidNameError                        = "42602"
 	CodeNameTooLongError                        = "42622"
 	CodeReservedNameError                       = "42939"
@@ -317,4 +320,10 @@ var (
 	// CodeCCLValidLicenseRequired signals that a valid CCL license is
 	// required to complete this task.
 	CodeCCLValidLicenseRequired = "XXC02"
+
+	// CodeReadOnlyDatabaseError signals that a statement cannot be executed
+	// because it modifies a database that is in read-only mode.
+	// We're using the postgres "Invalid Transaction State" error class "25",
+	// which also contains read_only_sql_transaction.
+	CodeReadOnlyDatabaseError = "25C00"
 )
@@ -40,6 +40,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
@@ -448,6 +449,39 @@ func TestPGUnwrapError(t *testing.T) {
 	}
 }
 
+// TestPGSyntaxErrorFields verifies that syntax errors are reported with the
+// standard error fields, including the position of the error.
+func TestPGSyntaxErrorFields(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	s, _, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	pgURL, cleanupFn := sqlutils.PGUrl(t, s.ServingAddr(), t.Name(), url.User(security.RootUser))
+	defer cleanupFn()
+
+	db, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec("SELECT 1; SELECT * FORM t")
+	pqErr, ok := err.(*pq.Error)
+	if !ok {
+		t.Fatalf("expected a pq.Error, found %v", err)
+	}
+	if pqErr.Code != pgerror.CodeSyntaxError {
+		t.Errorf("expected code %s, found %s", pgerror.CodeSyntaxError, pqErr.Code)
+	}
+	if pqErr.Position != "20" {
+		t.Errorf("expected position 20, found %q", pqErr.Position)
+	}
+	if pqErr.Hint == "" || pqErr.Detail == "" {
+		t.Errorf("expected a hint and a detail, found %+v", pqErr)
+	}
+}
+
 func TestPGPrepareFail(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -2307,3 +2341,83 @@ func TestCancelRequest(t *testing.T) {
 		t.Fatalf("expected 1 cancel request, got %d", count)
 	}
 }
+
+// TestPipelining checks that extended protocol messages that the client sends
+// without waiting for their results are executed in order, and that an error
+// causes the rest of the batch, up to the next Sync, to be skipped.
+func TestPipelining(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params := base.TestServerArgs{Insecure: true}
+	s, _, _ := serverutils.StartServer(t, params)
+
+	ctx := context.TODO()
+	defer s.Stopper().Stop(ctx)
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", s.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	fe, err := pgproto3.NewFrontend(conn, conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// receiveUntilReady returns the types of the messages received up to and
+	// including the next ReadyForQuery.
+	receiveUntilReady := func() []string {
+		var res []string
+		for {
+			msg, err := fe.Receive()
+			if err != nil {
+				t.Fatal(err)
+			}
+			res = append(res, strings.TrimPrefix(fmt.Sprintf("%T", msg), "*pgproto3."))
+			if _, ok := msg.(*pgproto3.ReadyForQuery); ok {
+				return res
+			}
+		}
+	}
+
+	if err := fe.Send(&pgproto3.StartupMessage{
+		ProtocolVersion: pgproto3.ProtocolVersionNumber,
+		Parameters:      map[string]string{"user": security.RootUser},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	receiveUntilReady()
+
+	// Send two batches before reading any result. The second statement of the
+	// first batch fails to prepare, so the third one is skipped.
+	var msgs []pgproto3.FrontendMessage
+	for _, batch := range [][]string{
+		{"SELECT 1", "SELECT * FROM nonexistent", "SELECT 3"},
+		{"SELECT 4"},
+	} {
+		for _, q := range batch {
+			msgs = append(msgs, &pgproto3.Parse{Query: q}, &pgproto3.Bind{}, &pgproto3.Execute{})
+		}
+		msgs = append(msgs, &pgproto3.Sync{})
+	}
+	for _, msg := range msgs {
+		if err := fe.Send(msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := [][]string{
+		{
+			"ParseComplete", "BindComplete", "DataRow", "CommandComplete",
+			"ErrorResponse", "ReadyForQuery",
+		},
+		{"ParseComplete", "BindComplete", "DataRow", "CommandComplete", "ReadyForQuery"},
+	}
+	for i, exp := range expected {
+		if res := receiveUntilReady(); !reflect.DeepEqual(res, exp) {
+			t.Errorf("%d: expected %v, got %v", i, exp, res)
+		}
+	}
+}
@@ -9,13 +9,14 @@ const (
 	_ServerErrFieldType_name_1 = "ServerErrFieldSrcFile"
 	_ServerErrFieldType_name_2 = "ServerErrFileldHint"
 	_ServerErrFieldType_name_3 = "ServerErrFieldSrcLineServerErrFieldMsgPrimary"
-	_ServerErrFieldType_name_4 = "ServerErrFieldSrcFunctionServerErrFieldSeverity"
+	_ServerErrFieldType_name_4 = "ServerErrFieldPosition"
+	_ServerErrFieldType_name_5 = "ServerErrFieldSrcFunctionServerErrFieldSeverity"
 )
 
 var (
 	_ServerErrFieldType_index_0 = [...]uint8{0, 22, 43}
 	_ServerErrFieldType_index_3 = [...]uint8{0, 21, 45}
-	_ServerErrFieldType_index_4 = [...]uint8{0, 25, 47}
+	_ServerErrFieldType_index_5 = [...]uint8{0, 25, 47}
 )
 
 func (i ServerErrFieldType) String() string {
@@ -30,9 +31,11 @@ func (i ServerErrFieldType) String() string {
 	case 76 <= i && i <= 77:
 		i -= 76
 		return _ServerErrFieldType_name_3[_ServerErrFieldType_index_3[i]:_ServerErrFieldType_index_3[i+1]]
+	case i == 80:
+		return _ServerErrFieldType_name_4
 	case 82 <= i && i <= 83:
 		i -= 82
-		return _ServerErrFieldType_name_4[_ServerErrFieldType_index_4[i]:_ServerErrFieldType_index_4[i+1]]
+		return _ServerErrFieldType_name_5[_ServerErrFieldType_index_5[i]:_ServerErrFieldType_index_5[i+1]]
 	default:
 		return "ServerErrFieldType(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
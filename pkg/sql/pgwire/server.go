@@ -24,10 +24,13 @@ import (
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/rpc"
+	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/hba"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgwirebase"
@@ -42,6 +45,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/pkg/errors"
 )
 
@@ -65,6 +69,44 @@ var connResultsBufferSize = settings.RegisterByteSizeSetting(
 	16<<10, // 16 KiB
 )
 
+var parseCacheSize = settings.RegisterNonNegativeIntSetting(
+	"sql.parse_cache.size",
+	"maximum number of distinct statement texts whose parse results are cached "+
+		"on each node. Setting to 0 disables the cache.",
+	1000,
+)
+
+var sqlTLSMinVersion = settings.RegisterValidatedStringSetting(
+	"server.sql.tls.min_version",
+	"the minimum TLS version accepted from SQL clients, in addition to the restrictions "+
+		"of --tls-min-version (example: 1.2)",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := security.ParseTLSVersion(s)
+		return err
+	},
+)
+
+var sqlTLSCipherSuites = settings.RegisterValidatedStringSetting(
+	"server.sql.tls.cipher_suites",
+	"comma-separated list of the cipher suites accepted from SQL clients, in addition to "+
+		"the restrictions of --tls-cipher-suites (example: TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256)",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := security.ParseCipherSuites(s)
+		return err
+	},
+)
+
+// sqlTLSOptions returns the TLS restrictions of the SQL connections set
+// through cluster settings. The settings are validated when they are set.
+func sqlTLSOptions(sv *settings.Values) security.TLSOptions {
+	var opts security.TLSOptions
+	opts.MinVersion, _ = security.ParseTLSVersion(sqlTLSMinVersion.Get(sv))
+	opts.CipherSuites, _ = security.ParseCipherSuites(sqlTLSCipherSuites.Get(sv))
+	return opts
+}
+
 const (
 	// ErrSSLRequired is returned when a client attempts to connect to a
 	// secure server in cleartext.
@@ -136,6 +178,10 @@ type Server struct {
 
 	metrics ServerMetrics
 
+	// parseCache caches the parse results of the statements sent by the
+	// clients of all the connections.
+	parseCache *parser.Cache
+
 	mu struct {
 		syncutil.Mutex
 		// connCancelMap entries represent connections started when the server
@@ -225,6 +271,11 @@ func MakeServer(
 	server.mu.connCancelMap = make(cancelChanMap)
 	server.mu.Unlock()
 
+	server.parseCache = parser.NewCache(int(parseCacheSize.Get(&st.SV)))
+	parseCacheSize.SetOnChange(&st.SV, func() {
+		server.parseCache.SetSize(int(parseCacheSize.Get(&st.SV)))
+	})
+
 	connAuthConf.SetOnChange(&st.SV, func() {
 		val := connAuthConf.Get(&st.SV)
 		server.auth.Lock()
@@ -431,6 +482,17 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		defer s.metrics.Conns.Dec(1)
 	}
 
+	remoteAddr := conn.RemoteAddr().String()
+	sql.LogConnectionEvent(ctx, s.execCfg, sql.AuthLogClientConnectionStart, struct {
+		RemoteAddress string
+	}{remoteAddr})
+	defer func(start time.Time) {
+		sql.LogConnectionEvent(ctx, s.execCfg, sql.AuthLogClientConnectionEnd, struct {
+			RemoteAddress string
+			Duration      string
+		}{remoteAddr, timeutil.Since(start).String()})
+	}(timeutil.Now())
+
 	var buf pgwirebase.ReadBuffer
 	n, err := buf.ReadUntypedMsg(conn)
 	if err != nil {
@@ -459,6 +521,7 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 			if err != nil {
 				return err
 			}
+			tlsConfig = sqlTLSOptions(&s.execCfg.Settings.SV).Restrict(tlsConfig)
 			conn = tls.Server(conn, tlsConfig)
 		}
 
@@ -466,6 +529,11 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 		if err != nil {
 			return err
 		}
+		// The TLS handshake is complete once the first message was read.
+		if tlsConn, ok := conn.(*tls.Conn); ok && s.execCfg.RPCContext != nil {
+			defer s.execCfg.RPCContext.TLSConns.Register(
+				rpc.TLSConnSQL, tlsConn.RemoteAddr(), tlsConn.ConnectionState())()
+		}
 		s.metrics.BytesInCount.Inc(int64(n))
 		version, err = buf.GetUint32()
 		if err != nil {
@@ -527,7 +595,7 @@ func (s *Server) ServeConn(ctx context.Context, conn net.Conn) error {
 
 	serveConn(
 		ctx, conn, sArgs,
-		&s.metrics, reserved, s.SQLServer,
+		&s.metrics, reserved, s.SQLServer, s.parseCache,
 		s.IsDraining,
 		authOptions{
 			insecure: s.cfg.Insecure,
@@ -0,0 +1,167 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var planPinningEnabled = settings.RegisterBoolSetting(
+	"sql.plan_pinning.enabled",
+	"if set, statements whose fingerprint has a plan pinned in system.pinned_plans "+
+		"are planned with the pinned plan",
+	true,
+)
+
+var planPinningRefreshInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.plan_pinning.refresh_interval",
+	"the interval at which each node reloads the pinned plans from system.pinned_plans",
+	30*time.Second,
+)
+
+// PinnedPlanCache caches the contents of system.pinned_plans on a node. The
+// table is reloaded once planPinningRefreshInterval has elapsed since it was
+// last loaded, so changes to the pinned plans take up to that interval to
+// take effect. A nil PinnedPlanCache has no pinned plans.
+type PinnedPlanCache struct {
+	mu struct {
+		syncutil.Mutex
+		// pins maps statement fingerprints to their pinned plan gist.
+		pins map[string]string
+		// loaded is the time at which pins was last loaded.
+		loaded time.Time
+		// loading is set while pins is being reloaded. The query which reloads
+		// the pinned plans is itself planned while loading is set, and uses the
+		// previous pinned plans.
+		loading bool
+	}
+}
+
+// NewPinnedPlanCache creates an empty PinnedPlanCache.
+func NewPinnedPlanCache() *PinnedPlanCache {
+	return &PinnedPlanCache{}
+}
+
+// lookup returns the plan gist pinned for the given statement, if any.
+func (c *PinnedPlanCache) lookup(
+	ctx context.Context, sv *settings.Values, ie *InternalExecutor, stmt tree.Statement,
+) (gist string, ok bool) {
+	if c == nil || ie == nil || !planPinningEnabled.Get(sv) {
+		return "", false
+	}
+	c.maybeReload(ctx, sv, ie)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.mu.pins) == 0 {
+		return "", false
+	}
+	gist, ok = c.mu.pins[parser.Fingerprint(stmt)]
+	return gist, ok
+}
+
+// maybeReload reloads the pinned plans if the refresh interval has elapsed
+// since they were last loaded. Errors are logged, and the previous pinned
+// plans are kept.
+func (c *PinnedPlanCache) maybeReload(ctx context.Context, sv *settings.Values, ie *InternalExecutor) {
+	now := timeutil.Now()
+	c.mu.Lock()
+	fresh := !c.mu.loaded.IsZero() && now.Sub(c.mu.loaded) < planPinningRefreshInterval.Get(sv)
+	if c.mu.loading || fresh {
+		c.mu.Unlock()
+		return
+	}
+	c.mu.loading = true
+	c.mu.Unlock()
+
+	rows, err := ie.Query(
+		ctx, "load-pinned-plans", nil, /* txn */
+		`SELECT fingerprint, gist FROM system.pinned_plans`,
+	)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.loading = false
+	if err != nil {
+		log.Warningf(ctx, "unable to load pinned plans: %v", err)
+		return
+	}
+	pins := make(map[string]string, len(rows))
+	for _, row := range rows {
+		pins[string(tree.MustBeDString(row[0]))] = string(tree.MustBeDString(row[1]))
+	}
+	c.mu.pins = pins
+	c.mu.loaded = now
+}
+
+// pinStatementPlan pins the plan of the statement being planned, if a plan
+// is pinned for its fingerprint. It must be called after reset(). A statement
+// with a pinned plan is always optimized from scratch: its memo is neither
+// reused nor cached, since the cached memo would have been optimized without
+// the pinned plan.
+func (opc *optPlanningCtx) pinStatementPlan(ctx context.Context) {
+	p := opc.p
+	stmt := p.stmt.AST
+	if explain, ok := stmt.(*tree.Explain); ok {
+		// EXPLAIN shows the pinned plan of the explained statement.
+		stmt = explain.Statement
+	}
+	s, ok := p.execCfg.PinnedPlans.lookup(ctx, &p.execCfg.Settings.SV, p.execCfg.InternalExecutor, stmt)
+	if !ok {
+		return
+	}
+	gist, err := opt.ParsePlanGist(s)
+	if err != nil {
+		log.Warningf(ctx, "ignoring pinned plan: %v", err)
+		return
+	}
+	opc.optimizer.PinPlan(gist)
+	opc.pinnedGist = gist
+	opc.allowMemoReuse = false
+	opc.useCache = false
+}
+
+// checkPinnedPlan checks whether the optimized memo has the pinned plan of
+// the statement, if any. The optimizer chooses the pinned plan whenever it
+// is still possible, e.g. when the indexes it uses still exist; otherwise it
+// falls back to the plan closest to it.
+func (opc *optPlanningCtx) checkPinnedPlan(ctx context.Context, m *memo.Memo) {
+	if opc.pinnedGist == nil {
+		return
+	}
+	root := m.RootExpr().(memo.RelExpr)
+	if explain, ok := root.(*memo.ExplainExpr); ok {
+		root = explain.Input
+	}
+	pinned, planned := opc.pinnedGist.String(), memo.MakePlanGist(m, root).String()
+	if planned == pinned {
+		telemetry.Inc(sqltelemetry.PinnedPlanUseCounter)
+		opc.log(ctx, "using pinned plan")
+		return
+	}
+	telemetry.Inc(sqltelemetry.PinnedPlanFallbackCounter)
+	log.Warningf(ctx, "unable to use pinned plan %s, using %s instead: %s", pinned, planned, opc.p.stmt)
+}
@@ -166,12 +166,18 @@ var _ planNode = &alterIndexNode{}
 var _ planNode = &alterSequenceNode{}
 var _ planNode = &alterTableNode{}
 var _ planNode = &cancelQueriesNode{}
+var _ planNode = &callNode{}
 var _ planNode = &cancelSessionsNode{}
+var _ planNode = &controlScheduleNode{}
 var _ planNode = &createDatabaseNode{}
+var _ planNode = &createFunctionNode{}
 var _ planNode = &createIndexNode{}
+var _ planNode = &createProcedureNode{}
+var _ planNode = &createScheduleNode{}
 var _ planNode = &createSequenceNode{}
 var _ planNode = &createStatsNode{}
 var _ planNode = &createTableNode{}
+var _ planNode = &createTriggerNode{}
 var _ planNode = &CreateUserNode{}
 var _ planNode = &createViewNode{}
 var _ planNode = &delayedNode{}
@@ -180,8 +186,11 @@ var _ planNode = &deleteRangeNode{}
 var _ planNode = &distinctNode{}
 var _ planNode = &dropDatabaseNode{}
 var _ planNode = &dropIndexNode{}
+var _ planNode = &dropProcedureNode{}
+var _ planNode = &dropScheduleNode{}
 var _ planNode = &dropSequenceNode{}
 var _ planNode = &dropTableNode{}
+var _ planNode = &dropTriggerNode{}
 var _ planNode = &DropUserNode{}
 var _ planNode = &dropViewNode{}
 var _ planNode = &explainDistSQLNode{}
@@ -196,6 +205,7 @@ var _ planNode = &limitNode{}
 var _ planNode = &max1RowNode{}
 var _ planNode = &ordinalityNode{}
 var _ planNode = &projectSetNode{}
+var _ planNode = &recursiveCTENode{}
 var _ planNode = &relocateNode{}
 var _ planNode = &renameColumnNode{}
 var _ planNode = &renameDatabaseNode{}
@@ -230,6 +240,9 @@ var _ planNodeFastPath = &rowCountNode{}
 var _ planNodeFastPath = &serializeNode{}
 var _ planNodeFastPath = &setZoneConfigNode{}
 var _ planNodeFastPath = &controlJobsNode{}
+var _ planNodeFastPath = &controlScheduleNode{}
+var _ planNodeFastPath = &createScheduleNode{}
+var _ planNodeFastPath = &dropScheduleNode{}
 
 // planNodeRequireSpool serves as marker for nodes whose parent must
 // ensure that the node is fully run to completion (and the results
@@ -309,6 +322,10 @@ type planTop struct {
 	// avoidBuffering, when set, causes the execution to avoid buffering
 	// results.
 	avoidBuffering bool
+
+	// stats collects the resources consumed by the execution of the
+	// statement, for registration in statement statistics.
+	stats topLevelQueryStats
 }
 
 // makePlan implements the Planner interface. It populates the
@@ -326,8 +343,11 @@ func (p *planner) makePlan(ctx context.Context) error {
 
 	log.VEvent(ctx, 2, "heuristic planner starts")
 
-	var err error
-	p.curPlan.plan, err = p.newPlan(ctx, stmt.AST, nil /*desiredTypes*/)
+	ast, _, err := p.resolveUserDefinedFunctions(ctx, stmt.AST)
+	if err != nil {
+		return err
+	}
+	p.curPlan.plan, err = p.newPlan(ctx, ast, nil /*desiredTypes*/)
 	if err != nil {
 		return err
 	}
@@ -625,6 +645,8 @@ func (p *planner) newPlan(
 		return p.CancelQueries(ctx, n)
 	case *tree.CancelSessions:
 		return p.CancelSessions(ctx, n)
+	case *tree.Call:
+		return p.Call(ctx, n)
 	case *tree.CommentOnColumn:
 		return p.CommentOnColumn(ctx, n)
 	case *tree.CommentOnDatabase:
@@ -633,14 +655,24 @@ func (p *planner) newPlan(
 		return p.CommentOnTable(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
+	case *tree.ControlSchedule:
+		return p.ControlSchedule(ctx, n)
 	case *tree.Scrub:
 		return p.Scrub(ctx, n)
 	case *tree.CreateDatabase:
 		return p.CreateDatabase(ctx, n)
+	case *tree.CreateFunction:
+		return p.CreateFunction(ctx, n)
 	case *tree.CreateIndex:
 		return p.CreateIndex(ctx, n)
+	case *tree.CreateProcedure:
+		return p.CreateProcedure(ctx, n)
+	case *tree.CreateSchedule:
+		return p.CreateSchedule(ctx, n)
 	case *tree.CreateTable:
 		return p.CreateTable(ctx, n)
+	case *tree.CreateTrigger:
+		return p.CreateTrigger(ctx, n)
 	case *tree.CreateUser:
 		return p.CreateUser(ctx, n)
 	case *tree.CreateView:
@@ -659,8 +691,14 @@ func (p *planner) newPlan(
 		return p.DropDatabase(ctx, n)
 	case *tree.DropIndex:
 		return p.DropIndex(ctx, n)
+	case *tree.DropProcedure:
+		return p.DropProcedure(ctx, n)
+	case *tree.DropSchedule:
+		return p.DropSchedule(ctx, n)
 	case *tree.DropTable:
 		return p.DropTable(ctx, n)
+	case *tree.DropTrigger:
+		return p.DropTrigger(ctx, n)
 	case *tree.DropView:
 		return p.DropView(ctx, n)
 	case *tree.DropSequence:
@@ -679,6 +717,8 @@ func (p *planner) newPlan(
 		return p.Relocate(ctx, n)
 	case *tree.RenameColumn:
 		return p.RenameColumn(ctx, n)
+	case *tree.AlterDatabaseSetReadMode:
+		return p.AlterDatabaseSetReadMode(ctx, n)
 	case *tree.RenameDatabase:
 		return p.RenameDatabase(ctx, n)
 	case *tree.RenameIndex:
@@ -716,6 +756,8 @@ func (p *planner) newPlan(
 		return p.ShowCreate(ctx, n)
 	case *tree.ShowDatabases:
 		return p.ShowDatabases(ctx, n)
+	case *tree.ShowEvents:
+		return p.ShowEvents(ctx, n)
 	case *tree.ShowGrants:
 		return p.ShowGrants(ctx, n)
 	case *tree.ShowHistogram:
@@ -730,6 +772,8 @@ func (p *planner) newPlan(
 		return p.ShowRoleGrants(ctx, n)
 	case *tree.ShowRoles:
 		return p.ShowRoles(ctx, n)
+	case *tree.ShowSchedules:
+		return p.ShowSchedules(ctx, n)
 	case *tree.ShowSessions:
 		return p.ShowSessions(ctx, n)
 	case *tree.ShowTableStats:
@@ -783,6 +827,11 @@ func (p *planner) prepare(ctx context.Context, stmt tree.Statement) error {
 	// Reinitialize.
 	p.curPlan = planTop{AST: stmt}
 
+	stmt, _, err := p.resolveUserDefinedFunctions(ctx, stmt)
+	if err != nil {
+		return err
+	}
+
 	// Prepare the plan.
 	plan, err := p.doPrepare(ctx, stmt)
 	if err != nil {
@@ -808,6 +857,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.CancelQueries(ctx, n)
 	case *tree.CancelSessions:
 		return p.CancelSessions(ctx, n)
+	case *tree.Call:
+		return p.Call(ctx, n)
 	case *tree.ControlJobs:
 		return p.ControlJobs(ctx, n)
 	case *tree.CreateUser:
@@ -845,6 +896,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.ShowColumns(ctx, n)
 	case *tree.ShowDatabases:
 		return p.ShowDatabases(ctx, n)
+	case *tree.ShowEvents:
+		return p.ShowEvents(ctx, n)
 	case *tree.ShowGrants:
 		return p.ShowGrants(ctx, n)
 	case *tree.ShowIndex:
@@ -859,6 +912,8 @@ func (p *planner) doPrepare(ctx context.Context, stmt tree.Statement) (planNode,
 		return p.ShowRoleGrants(ctx, n)
 	case *tree.ShowRoles:
 		return p.ShowRoles(ctx, n)
+	case *tree.ShowSchedules:
+		return p.ShowSchedules(ctx, n)
 	case *tree.ShowSessions:
 		return p.ShowSessions(ctx, n)
 	case *tree.ShowTables:
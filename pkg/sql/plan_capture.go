@@ -0,0 +1,321 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	opentracing "github.com/opentracing/opentracing-go"
+	"github.com/pkg/errors"
+)
+
+// Plan capture periodically selects the statement fingerprints with the
+// highest mean service latency and saves the EXPLAIN ANALYZE plan of the
+// next execution of each of them. The captured plans are kept in memory,
+// subject to a retention period and to a per-fingerprint limit, so that
+// the plan of a regressed statement can be compared to its earlier
+// plans without having to request them beforehand.
+
+var planCaptureEnabled = settings.RegisterBoolSetting(
+	"sql.metrics.statement_details.plan_capture.enabled",
+	"periodically capture an EXPLAIN ANALYZE plan of the slowest statement fingerprints",
+	false,
+)
+
+var planCaptureInterval = settings.RegisterValidatedDurationSetting(
+	"sql.metrics.statement_details.plan_capture.interval",
+	"the interval at which the slowest fingerprints are selected for plan capture",
+	10*time.Minute,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("the plan capture interval must be positive: %s", v)
+		}
+		return nil
+	},
+)
+
+var planCaptureTopN = settings.RegisterNonNegativeIntSetting(
+	"sql.metrics.statement_details.plan_capture.top_n",
+	"the number of fingerprints, by mean service latency, whose plan is captured at each interval",
+	10,
+)
+
+var planCaptureRetention = settings.RegisterNonNegativeDurationSetting(
+	"sql.metrics.statement_details.plan_capture.retention",
+	"the duration for which captured plans are kept",
+	24*time.Hour,
+)
+
+var planCaptureMaxPerFingerprint = settings.RegisterPositiveIntSetting(
+	"sql.metrics.statement_details.plan_capture.max_per_fingerprint",
+	"the maximum number of captured plans kept for each fingerprint",
+	5,
+)
+
+// planCaptureKey identifies a fingerprint for plan capture.
+type planCaptureKey struct {
+	appName     string
+	fingerprint string
+}
+
+// capturedPlan is an EXPLAIN ANALYZE plan captured for a fingerprint.
+type capturedPlan struct {
+	planCaptureKey
+	capturedAt  time.Time
+	distributed bool
+	// runLat is the execution latency of the traced execution.
+	runLat   time.Duration
+	planURL  string
+	planJSON string
+}
+
+// topFingerprintsByLatency returns the n fingerprints with the highest
+// mean service latency. Internal applications and failed executions are
+// not considered. The fingerprints executed with and without DistSQL or
+// the optimizer are ranked by their slowest variant.
+func (s *sqlStats) topFingerprintsByLatency(n int) []planCaptureKey {
+	if n <= 0 {
+		return nil
+	}
+	lat := make(map[planCaptureKey]float64)
+	s.Lock()
+	for appName, a := range s.apps {
+		if strings.HasPrefix(appName, InternalAppNamePrefix) {
+			continue
+		}
+		a.Lock()
+		for k, stats := range a.stmts {
+			if k.failed {
+				continue
+			}
+			stats.Lock()
+			mean := stats.data.ServiceLat.Mean
+			stats.Unlock()
+			key := planCaptureKey{appName: appName, fingerprint: k.stmt}
+			if l, ok := lat[key]; !ok || mean > l {
+				lat[key] = mean
+			}
+		}
+		a.Unlock()
+	}
+	s.Unlock()
+
+	keys := make([]planCaptureKey, 0, len(lat))
+	for k := range lat {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if li, lj := lat[keys[i]], lat[keys[j]]; li != lj {
+			return li > lj
+		}
+		if keys[i].appName != keys[j].appName {
+			return keys[i].appName < keys[j].appName
+		}
+		return keys[i].fingerprint < keys[j].fingerprint
+	})
+	if len(keys) > n {
+		keys = keys[:n]
+	}
+	return keys
+}
+
+// selectPlansToCapture requests a plan capture for the slowest
+// fingerprints, replacing the requests of the previous interval that
+// were not served, and discards the expired plans.
+func (s *sqlStats) selectPlansToCapture(now time.Time) {
+	var keys []planCaptureKey
+	if planCaptureEnabled.Get(&s.st.SV) {
+		keys = s.topFingerprintsByLatency(int(planCaptureTopN.Get(&s.st.SV)))
+	}
+
+	s.plans.Lock()
+	defer s.plans.Unlock()
+	s.plans.requested = make(map[planCaptureKey]struct{}, len(keys))
+	for _, k := range keys {
+		s.plans.requested[k] = struct{}{}
+	}
+	cutoff := now.Add(-planCaptureRetention.Get(&s.st.SV))
+	for k, plans := range s.plans.captured {
+		i := 0
+		for i < len(plans) && plans[i].capturedAt.Before(cutoff) {
+			i++
+		}
+		if i == len(plans) {
+			delete(s.plans.captured, k)
+		} else {
+			s.plans.captured[k] = plans[i:]
+		}
+	}
+}
+
+// shouldCapturePlan returns true if a plan capture was requested for the
+// fingerprint. The request is then considered served.
+func (s *sqlStats) shouldCapturePlan(appName, fingerprint string) bool {
+	key := planCaptureKey{appName: appName, fingerprint: fingerprint}
+	s.plans.Lock()
+	defer s.plans.Unlock()
+	if _, ok := s.plans.requested[key]; !ok {
+		return false
+	}
+	delete(s.plans.requested, key)
+	return true
+}
+
+// recordCapturedPlan saves a captured plan, discarding the oldest plans
+// of the fingerprint beyond the limit.
+func (s *sqlStats) recordCapturedPlan(p capturedPlan) {
+	max := int(planCaptureMaxPerFingerprint.Get(&s.st.SV))
+	s.plans.Lock()
+	defer s.plans.Unlock()
+	if s.plans.captured == nil {
+		s.plans.captured = make(map[planCaptureKey][]capturedPlan)
+	}
+	plans := append(s.plans.captured[p.planCaptureKey], p)
+	if len(plans) > max {
+		plans = append([]capturedPlan(nil), plans[len(plans)-max:]...)
+	}
+	s.plans.captured[p.planCaptureKey] = plans
+}
+
+// getCapturedPlans returns the captured plans, ordered by application,
+// fingerprint and capture time.
+func (s *sqlStats) getCapturedPlans() []capturedPlan {
+	s.plans.Lock()
+	var ret []capturedPlan
+	for _, plans := range s.plans.captured {
+		ret = append(ret, plans...)
+	}
+	s.plans.Unlock()
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].appName != ret[j].appName {
+			return ret[i].appName < ret[j].appName
+		}
+		if ret[i].fingerprint != ret[j].fingerprint {
+			return ret[i].fingerprint < ret[j].fingerprint
+		}
+		return ret[i].capturedAt.Before(ret[j].capturedAt)
+	})
+	return ret
+}
+
+// periodicallySelectPlansToCapture runs a loop that selects the
+// fingerprints whose plan should be captured at every
+// sql.metrics.statement_details.plan_capture.interval.
+func (s *Server) periodicallySelectPlansToCapture(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		var timer timeutil.Timer
+		defer timer.Stop()
+		for {
+			timer.Reset(planCaptureInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-stopper.ShouldQuiesce():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			s.sqlStats.selectPlansToCapture(timeutil.Now())
+		}
+	})
+}
+
+// planCapture is a plan capture in progress.
+type planCapture struct {
+	key   planCaptureKey
+	sp    opentracing.Span
+	start time.Time
+	// diagram is the diagram of the physical plan of the statement. It is
+	// populated by DistSQLPlanner.Run.
+	diagram distsqlpb.FlowDiagram
+}
+
+// maybeStartPlanCapture starts a plan capture if one was requested for
+// the statement. In that case, the statement must be run with the
+// returned context, which has a recording span, and the PlanningCtx used
+// to run the statement must be set up with setupPlanningCtx.
+//
+// No capture is started if the execution is already being recorded, for
+// example because of session tracing.
+func (ex *connExecutor) maybeStartPlanCapture(
+	ctx context.Context, stmt *Statement,
+) (context.Context, *planCapture) {
+	if !planCaptureEnabled.Get(&ex.server.cfg.Settings.SV) || stmt == nil {
+		return ctx, nil
+	}
+	parentSp := opentracing.SpanFromContext(ctx)
+	if parentSp != nil && tracing.IsRecording(parentSp) {
+		return ctx, nil
+	}
+	key := planCaptureKey{
+		appName:     ex.sessionData.ApplicationName,
+		fingerprint: stmtFingerprint(stmt),
+	}
+	if !ex.server.sqlStats.shouldCapturePlan(key.appName, key.fingerprint) {
+		return ctx, nil
+	}
+
+	var sp opentracing.Span
+	if parentSp != nil {
+		sp = parentSp.Tracer().StartSpan(
+			"plan-capture", tracing.Recordable,
+			opentracing.ChildOf(parentSp.Context()),
+			tracing.LogTagsFromCtx(ctx))
+	} else {
+		sp = ex.server.cfg.AmbientCtx.Tracer.StartSpan(
+			"plan-capture", tracing.Recordable,
+			tracing.LogTagsFromCtx(ctx))
+	}
+	tracing.StartRecording(sp, tracing.SnowballRecording)
+	return opentracing.ContextWithSpan(ctx, sp), &planCapture{key: key, sp: sp, start: timeutil.Now()}
+}
+
+// setupPlanningCtx makes planCtx save the diagram of the physical plan.
+func (c *planCapture) setupPlanningCtx(planCtx *PlanningCtx) {
+	planCtx.saveDiagram = func(diagram distsqlpb.FlowDiagram) {
+		c.diagram = diagram
+	}
+}
+
+// finish ends the plan capture and, if the statement succeeded, saves the
+// plan annotated with the execution statistics.
+func (c *planCapture) finish(ctx context.Context, s *sqlStats, distributed bool, err error) {
+	runLat := timeutil.Since(c.start)
+	c.sp.Finish()
+	if err != nil || c.diagram == nil {
+		return
+	}
+	c.diagram.AddSpans(tracing.GetRecording(c.sp))
+	planJSON, planURL, err := c.diagram.ToURL()
+	if err != nil {
+		log.Warningf(ctx, "unable to save captured plan: %v", err)
+		return
+	}
+	s.recordCapturedPlan(capturedPlan{
+		planCaptureKey: c.key,
+		capturedAt:     c.start,
+		distributed:    distributed,
+		runLat:         runLat,
+		planURL:        planURL.String(),
+		planJSON:       planJSON,
+	})
+}
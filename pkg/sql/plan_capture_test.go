@@ -0,0 +1,140 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestSelectPlansToCapture(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	planCaptureEnabled.Override(&st.SV, true)
+	planCaptureTopN.Override(&st.SV, 2)
+	planCaptureMaxPerFingerprint.Override(&st.SV, 2)
+	planCaptureRetention.Override(&st.SV, time.Hour)
+
+	s := sqlStats{st: st, apps: make(map[string]*appStats)}
+	record := func(app string, key stmtKey, lat float64) {
+		stats := s.getStatsForApplication(app).getStatsForStmtWithKey(key, true /* createIfNonexistent */)
+		stats.data.ServiceLat.Mean = lat
+	}
+	record("app", stmtKey{stmt: "SELECT _"}, 1)
+	record("app", stmtKey{stmt: "SELECT _", distSQLUsed: true}, 4)
+	record("app", stmtKey{stmt: "INSERT INTO t VALUES (_)"}, 3)
+	record("app", stmtKey{stmt: "DELETE FROM t", failed: true}, 10)
+	record("other", stmtKey{stmt: "SELECT _"}, 2)
+	record(InternalAppNamePrefix+"-job", stmtKey{stmt: "UPDATE t SET a = _"}, 10)
+
+	expected := []planCaptureKey{
+		{appName: "app", fingerprint: "SELECT _"},
+		{appName: "app", fingerprint: "INSERT INTO t VALUES (_)"},
+	}
+	if keys := s.topFingerprintsByLatency(2); !reflect.DeepEqual(keys, expected) {
+		t.Fatalf("expected %v, got %v", expected, keys)
+	}
+
+	now := timeutil.Now()
+	s.selectPlansToCapture(now)
+	if s.shouldCapturePlan("other", "SELECT _") {
+		t.Fatal("unexpected capture request for a fingerprint which is not among the slowest")
+	}
+	if !s.shouldCapturePlan("app", "SELECT _") {
+		t.Fatal("expected a capture request for the slowest fingerprint")
+	}
+	if s.shouldCapturePlan("app", "SELECT _") {
+		t.Fatal("expected the capture request to be served only once")
+	}
+
+	// Only the most recent plans of each fingerprint are kept.
+	key := planCaptureKey{appName: "app", fingerprint: "SELECT _"}
+	for i := 0; i < 3; i++ {
+		s.recordCapturedPlan(capturedPlan{
+			planCaptureKey: key,
+			capturedAt:     now.Add(time.Duration(i) * time.Minute),
+			planURL:        fmt.Sprint(i),
+		})
+	}
+	plans := s.getCapturedPlans()
+	if len(plans) != 2 || plans[0].planURL != "1" || plans[1].planURL != "2" {
+		t.Fatalf("expected the two most recent plans, got %v", plans)
+	}
+
+	// Expired plans are discarded, and disabling the capture discards the
+	// pending requests.
+	planCaptureEnabled.Override(&st.SV, false)
+	s.selectPlansToCapture(now.Add(time.Hour + 90*time.Second))
+	plans = s.getCapturedPlans()
+	if len(plans) != 1 || plans[0].planURL != "2" {
+		t.Fatalf("expected the unexpired plan, got %v", plans)
+	}
+	if s.shouldCapturePlan("app", "INSERT INTO t VALUES (_)") {
+		t.Fatal("unexpected capture request while the capture is disabled")
+	}
+}
+
+func TestPlanCapture(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{UseDatabase: "test"})
+	defer s.Stopper().Stop(ctx)
+
+	sv := &s.ClusterSettings().SV
+	planCaptureEnabled.Override(sv, true)
+	planCaptureTopN.Override(sv, 100)
+	sqlServer := s.InternalExecutor().(*InternalExecutor).s
+
+	// Use a single connection so that the application name applies to all
+	// the statements.
+	db.SetMaxOpenConns(1)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE test`)
+	sqlDB.Exec(t, `CREATE TABLE kv (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO kv VALUES (1, 10), (2, 20)`)
+	sqlDB.Exec(t, `SET application_name = 'capture'`)
+
+	const query = `SELECT k FROM kv WHERE v = 10`
+	sqlDB.CheckQueryResults(t, query, [][]string{{"1"}})
+
+	// Nothing is captured until the fingerprint is selected.
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM crdb_internal.node_statement_plans WHERE application_name = 'capture'`,
+		[][]string{{"0"}})
+
+	sqlServer.sqlStats.selectPlansToCapture(timeutil.Now())
+	for i := 0; i < 2; i++ {
+		sqlDB.CheckQueryResults(t, query, [][]string{{"1"}})
+	}
+
+	// Only the first execution after the selection is captured.
+	sqlDB.CheckQueryResults(t, `
+SELECT key, plan_url LIKE 'https://cockroachdb.github.io/distsqlplan/decode.html#%', plan_json LIKE '%TableReader%'
+  FROM crdb_internal.node_statement_plans
+ WHERE application_name = 'capture'`,
+		[][]string{{"SELECT k FROM kv WHERE v = _", "true", "true"}})
+}
@@ -67,6 +67,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 		return n.columns
 	case *unionNode:
 		return n.columns
+	case *recursiveCTENode:
+		return n.columns
 	case *valuesNode:
 		return n.columns
 	case *virtualTableNode:
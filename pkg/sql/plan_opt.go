@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec/execbuilder"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/optbuilder"
@@ -56,6 +57,7 @@ func (p *planner) prepareUsingOptimizer(
 
 	opc := &p.optPlanningCtx
 	opc.reset()
+	opc.pinStatementPlan(ctx)
 
 	if opc.useCache {
 		cachedData, ok := p.execCfg.QueryCache.Find(&p.queryCacheSession, stmt.SQL)
@@ -65,7 +67,7 @@ func (p *planner) prepareUsingOptimizer(
 			if !pm.TypeHints.Equals(p.semaCtx.Placeholders.TypeHints) {
 				opc.log(ctx, "query cache hit but type hints don't match")
 			} else {
-				isStale, err := cachedData.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog)
+				isStale, err := opc.memoIsStale(ctx, cachedData.Memo)
 				if err != nil {
 					return 0, false, err
 				}
@@ -150,11 +152,13 @@ func (p *planner) makeOptimizerPlan(ctx context.Context) (_ *planTop, isCorrelat
 
 	opc := &p.optPlanningCtx
 	opc.reset()
+	opc.pinStatementPlan(ctx)
 
 	execMemo, isCorrelated, err := opc.buildExecMemo(ctx)
 	if err != nil {
 		return nil, isCorrelated, err
 	}
+	opc.checkPinnedPlan(ctx, execMemo)
 
 	// Build the plan tree.
 	root := execMemo.RootExpr()
@@ -212,6 +216,10 @@ type optPlanningCtx struct {
 	// allowMemoReuse is false.
 	useCache bool
 
+	// pinnedGist is the plan pinned for the statement, if any. See
+	// pinStatementPlan.
+	pinnedGist *opt.PlanGist
+
 	flags planFlags
 }
 
@@ -227,6 +235,7 @@ func (opc *optPlanningCtx) reset() {
 	p := opc.p
 	opc.catalog.reset()
 	opc.optimizer.Init(p.EvalContext())
+	opc.pinnedGist = nil
 	opc.flags = planFlagOptUsed
 
 	// We only allow memo caching for SELECT/INSERT/UPDATE/DELETE. We could
@@ -298,8 +307,12 @@ func (opc *optPlanningCtx) buildReusableMemo(
 	// contain placeholders, then also apply exploration rules to the Memo so
 	// that there's even less to do during the EXECUTE phase.
 	//
+	stmt, err := opc.resolveUserDefinedFunctions(ctx)
+	if err != nil {
+		return nil, false, err
+	}
 	f := opc.optimizer.Factory()
-	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
+	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, stmt)
 	bld.KeepPlaceholders = true
 	if err := bld.Build(); err != nil {
 		return nil, bld.IsCorrelated, err
@@ -357,6 +370,37 @@ func (opc *optPlanningCtx) reuseMemo(cachedMemo *memo.Memo) (*memo.Memo, error)
 	return f.Memo(), nil
 }
 
+// memoIsStale returns true if the given memo cannot be reused. A memo that
+// fails a privilege check is considered stale rather than returning an error:
+// the memo may have been built with column-level privileges, which have to be
+// checked again by the optbuilder when the memo is rebuilt.
+func (opc *optPlanningCtx) memoIsStale(ctx context.Context, m *memo.Memo) (bool, error) {
+	isStale, err := m.IsStale(ctx, opc.p.EvalContext(), &opc.catalog)
+	if err != nil {
+		if pgErr, ok := pgerror.GetPGCause(err); ok && pgErr.Code == pgerror.CodeInsufficientPrivilegeError {
+			return true, nil
+		}
+		return false, err
+	}
+	return isStale, nil
+}
+
+// resolveUserDefinedFunctions returns the statement to build, in which the
+// calls to user-defined functions are resolved. The memos of such statements
+// are neither cached nor reused: the definitions of the functions are not
+// tracked by the staleness checks of the memos.
+func (opc *optPlanningCtx) resolveUserDefinedFunctions(ctx context.Context) (tree.Statement, error) {
+	stmt, found, err := opc.p.resolveUserDefinedFunctions(ctx, opc.p.stmt.AST)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		opc.allowMemoReuse = false
+		opc.useCache = false
+	}
+	return stmt, nil
+}
+
 // buildExecMemo creates a fully optimized memo, possibly reusing a previously
 // cached memo as a starting point.
 //
@@ -376,7 +420,7 @@ func (opc *optPlanningCtx) buildExecMemo(
 
 		// If the prepared memo has been invalidated by schema or other changes,
 		// re-prepare it.
-		if isStale, err := prepared.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog); err != nil {
+		if isStale, err := opc.memoIsStale(ctx, prepared.Memo); err != nil {
 			return nil, false, err
 		} else if isStale {
 			prepared.Memo, isCorrelated, err = opc.buildReusableMemo(ctx)
@@ -394,7 +438,7 @@ func (opc *optPlanningCtx) buildExecMemo(
 		// Consult the query cache.
 		cachedData, ok := p.execCfg.QueryCache.Find(&p.queryCacheSession, opc.p.stmt.SQL)
 		if ok {
-			if isStale, err := cachedData.Memo.IsStale(ctx, p.EvalContext(), &opc.catalog); err != nil {
+			if isStale, err := opc.memoIsStale(ctx, cachedData.Memo); err != nil {
 				return nil, false, err
 			} else if isStale {
 				cachedData.Memo, _, err = opc.buildReusableMemo(ctx)
@@ -420,8 +464,12 @@ func (opc *optPlanningCtx) buildExecMemo(
 
 	// We are executing a statement for which there is no reusable memo
 	// available.
+	stmt, err := opc.resolveUserDefinedFunctions(ctx)
+	if err != nil {
+		return nil, false, err
+	}
 	f := opc.optimizer.Factory()
-	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, opc.p.stmt.AST)
+	bld := optbuilder.New(ctx, &p.semaCtx, p.EvalContext(), &opc.catalog, f, stmt)
 	if err := bld.Build(); err != nil {
 		return nil, bld.IsCorrelated, err
 	}
@@ -105,6 +105,11 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *createSequenceNode:
+	case *callNode:
+	case *createFunctionNode:
+	case *createProcedureNode:
+	case *createTriggerNode:
+	case *createScheduleNode:
 	case *createStatsNode:
 	case *createTableNode:
 	case *createViewNode:
@@ -112,11 +117,16 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *deleteRangeNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
+	case *dropProcedureNode:
 	case *dropSequenceNode:
+	case *dropTriggerNode:
+	case *dropScheduleNode:
+	case *controlScheduleNode:
 	case *dropTableNode:
 	case *dropViewNode:
 	case *explainDistSQLNode:
 	case *hookFnNode:
+	case *recursiveCTENode:
 	case *relocateNode:
 	case *renameColumnNode:
 	case *renameDatabaseNode:
@@ -99,6 +99,11 @@ func collectSpans(params runParams, plan planNode) (reads, writes roachpb.Spans,
 		return concatSpans(params, n.left.plan, n.right.plan)
 	case *unionNode:
 		return concatSpans(params, n.left, n.right)
+	case *recursiveCTENode:
+		// The recursive term is only planned during execution, so the spans
+		// it reads are not known yet.
+		_, writes, err := collectSpans(params, n.initial)
+		return roachpb.Spans{{Key: roachpb.KeyMin, EndKey: roachpb.KeyMax}}, writes, err
 	}
 
 	panic(fmt.Sprintf("don't know how to collect spans for node %T", plan))
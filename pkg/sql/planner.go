@@ -631,6 +631,7 @@ type sqlStatsCollector interface {
 		numRows int,
 		err error,
 		parseLat, planLat, runLat, svcLat, ovhLat float64,
+		stats topLevelQueryStats,
 	)
 
 	// SQLStats provides access to the global sqlStats object.
@@ -0,0 +1,603 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package plpgsql implements an interpreter for routines (i.e. functions and
+// procedures) written in a subset of PL/pgSQL. See tree.RoutineBlock for the
+// supported statements, and parser.ParseRoutineBody to parse the body of a
+// routine.
+package plpgsql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+)
+
+// Param is a parameter of a routine.
+type Param struct {
+	// Name is the name of the parameter, or empty if the parameter can only be
+	// referenced by position.
+	Name string
+	Type coltypes.CastTargetType
+}
+
+// Routine is a routine with a PL/pgSQL body, or a function with a SQL body.
+type Routine struct {
+	Name   string
+	Params []Param
+	// ReturnType is the type of the value returned by the routine, or nil if
+	// the routine does not return a value.
+	ReturnType coltypes.CastTargetType
+	Body       *tree.RoutineBlock
+	// SQLBody is the list of statements of the body of a SQL function, which
+	// is used when Body is nil. The function returns the first column of the
+	// first row returned by the last statement, or NULL if it returns no rows.
+	SQLBody []tree.Statement
+}
+
+// Exec executes a routine with the given arguments, and returns the value it
+// returned, or DNull if it does not return a value.
+//
+// The SQL statements of the routine are executed by ie in txn, after the
+// references to the variables and parameters of the routine have been
+// replaced by their values. Expressions that do not contain subqueries are
+// evaluated locally with evalCtx.
+func Exec(
+	ctx context.Context,
+	evalCtx *tree.EvalContext,
+	ie sqlutil.InternalExecutor,
+	txn *client.Txn,
+	r *Routine,
+	args tree.Datums,
+) (tree.Datum, error) {
+	if len(args) != len(r.Params) {
+		return nil, pgerror.NewAssertionErrorf(
+			"function %s expects %d arguments, got %d", r.Name, len(r.Params), len(args))
+	}
+	in := interpreter{
+		ctx:     ctx,
+		evalCtx: evalCtx,
+		ie:      ie,
+		txn:     txn,
+		r:       r,
+		opName:  "routine-" + r.Name,
+		scope:   &scope{vars: make(map[string]*variable)},
+		ret:     tree.DNull,
+	}
+	for i := range r.Params {
+		v := &variable{name: r.Params[i].Name, typ: r.Params[i].Type}
+		if err := in.setVar(v, args[i]); err != nil {
+			return nil, err
+		}
+		in.params = append(in.params, v)
+		if v.name != "" {
+			in.scope.vars[v.name] = v
+		}
+	}
+
+	if r.Body == nil {
+		return in.execSQLBody()
+	}
+	c, err := in.execBlock(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if c != controlReturn && r.ReturnType != nil {
+		return nil, pgerror.NewErrorf(
+			pgerror.CodeRoutineExceptionFunctionExecutedNoReturnStatementError,
+			"control reached end of function %s without RETURN", r.Name)
+	}
+	return in.ret, nil
+}
+
+// control indicates how the execution continues after a statement.
+type control int
+
+const (
+	// controlNext continues with the next statement.
+	controlNext control = iota
+	// controlExit leaves the innermost loop.
+	controlExit
+	// controlContinue starts the next iteration of the innermost loop.
+	controlContinue
+	// controlReturn leaves the routine.
+	controlReturn
+)
+
+type variable struct {
+	name     string
+	typ      coltypes.CastTargetType
+	constant bool
+	notNull  bool
+	val      tree.Datum
+}
+
+// scope holds the variables declared by a block.
+type scope struct {
+	parent *scope
+	vars   map[string]*variable
+}
+
+// interpreter is the state of an invocation of a routine.
+type interpreter struct {
+	ctx     context.Context
+	evalCtx *tree.EvalContext
+	ie      sqlutil.InternalExecutor
+	txn     *client.Txn
+	r       *Routine
+	opName  string
+
+	// params are the parameters of the routine, by position.
+	params []*variable
+	// scope is the innermost scope.
+	scope *scope
+	// ret is the value returned by the routine.
+	ret tree.Datum
+}
+
+func (in *interpreter) execBlock(block *tree.RoutineBlock) (control, error) {
+	in.scope = &scope{parent: in.scope, vars: make(map[string]*variable, len(block.Decls))}
+	defer func() { in.scope = in.scope.parent }()
+
+	for i := range block.Decls {
+		decl := &block.Decls[i]
+		v := &variable{name: string(decl.Name), typ: decl.Type, notNull: decl.NotNull}
+		val := tree.Datum(tree.DNull)
+		if decl.Default != nil {
+			var err error
+			if val, err = in.evalExpr(decl.Default, coltypes.CastTargetToDatumType(v.typ)); err != nil {
+				return controlNext, err
+			}
+		}
+		if err := in.setVar(v, val); err != nil {
+			return controlNext, err
+		}
+		// A variable is visible from the declarations that follow it.
+		v.constant = decl.Constant
+		in.scope.vars[v.name] = v
+	}
+	return in.execStmts(block.Stmts)
+}
+
+func (in *interpreter) execStmts(stmts []tree.RoutineStmt) (control, error) {
+	for _, stmt := range stmts {
+		c, err := in.execStmt(stmt)
+		if err != nil || c != controlNext {
+			return c, err
+		}
+	}
+	return controlNext, nil
+}
+
+func (in *interpreter) execStmt(stmt tree.RoutineStmt) (control, error) {
+	switch t := stmt.(type) {
+	case *tree.RoutineBlock:
+		return in.execBlock(t)
+
+	case *tree.RoutineAssign:
+		return controlNext, in.execAssign(t)
+
+	case *tree.RoutineIf:
+		branch, err := in.chooseBranch(t)
+		if err != nil {
+			return controlNext, err
+		}
+		return in.execStmts(branch)
+
+	case *tree.RoutineLoop:
+		return in.execLoop(t)
+
+	case *tree.RoutineExit:
+		if t.When != nil {
+			if ok, err := in.evalCond(t.When, "WHEN"); err != nil || !ok {
+				return controlNext, err
+			}
+		}
+		if t.Continue {
+			return controlContinue, nil
+		}
+		return controlExit, nil
+
+	case *tree.RoutineReturn:
+		return controlReturn, in.execReturn(t)
+
+	case *tree.RoutineRaise:
+		return controlNext, in.execRaise(t)
+
+	case *tree.RoutineExec:
+		return controlNext, in.execSQL(t)
+
+	default:
+		return controlNext, pgerror.NewAssertionErrorf("unexpected routine statement %T", stmt)
+	}
+}
+
+func (in *interpreter) execAssign(stmt *tree.RoutineAssign) error {
+	v, err := in.lookupVar(string(stmt.Var))
+	if err != nil {
+		return err
+	}
+	if v.constant {
+		return pgerror.NewErrorf(pgerror.CodeErrorInAssignmentError,
+			"variable %q is declared CONSTANT", v.name)
+	}
+	d, err := in.evalExpr(stmt.Expr, coltypes.CastTargetToDatumType(v.typ))
+	if err != nil {
+		return err
+	}
+	return in.setVar(v, d)
+}
+
+// chooseBranch returns the statements of the branch of an IF statement whose
+// condition is true, or of its ELSE branch.
+func (in *interpreter) chooseBranch(stmt *tree.RoutineIf) ([]tree.RoutineStmt, error) {
+	if ok, err := in.evalCond(stmt.Cond, "IF"); err != nil || ok {
+		return stmt.Then, err
+	}
+	for i := range stmt.ElseIfs {
+		if ok, err := in.evalCond(stmt.ElseIfs[i].Cond, "IF"); err != nil || ok {
+			return stmt.ElseIfs[i].Stmts, err
+		}
+	}
+	return stmt.Else, nil
+}
+
+func (in *interpreter) execLoop(stmt *tree.RoutineLoop) (control, error) {
+	for {
+		// Loops can be infinite: let the statement be canceled.
+		if err := in.ctx.Err(); err != nil {
+			return controlNext, pgerror.NewError(pgerror.CodeQueryCanceledError, err.Error())
+		}
+		if stmt.While != nil {
+			if ok, err := in.evalCond(stmt.While, "WHILE"); err != nil || !ok {
+				return controlNext, err
+			}
+		}
+		c, err := in.execStmts(stmt.Stmts)
+		if err != nil {
+			return controlNext, err
+		}
+		switch c {
+		case controlExit:
+			return controlNext, nil
+		case controlReturn:
+			return c, nil
+		}
+	}
+}
+
+func (in *interpreter) execReturn(stmt *tree.RoutineReturn) error {
+	if stmt.Expr == nil {
+		if in.r.ReturnType != nil {
+			return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"RETURN must have a value in function %s", in.r.Name)
+		}
+		return nil
+	}
+	if in.r.ReturnType == nil {
+		return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+			"RETURN cannot have a value in function %s, which does not return a value", in.r.Name)
+	}
+	// The value is cast to the return type below, so the expression is type
+	// checked on its own: the return type is not a valid desired type for
+	// e.g. an arithmetic expression returned by a function returning STRING.
+	d, err := in.evalExpr(stmt.Expr, types.Any)
+	if err != nil {
+		return err
+	}
+	if d != tree.DNull {
+		if d, err = tree.PerformCast(in.evalCtx, d, in.r.ReturnType); err != nil {
+			return err
+		}
+	}
+	in.ret = d
+	return nil
+}
+
+func (in *interpreter) execRaise(stmt *tree.RoutineRaise) error {
+	var buf bytes.Buffer
+	argIdx := 0
+	for i := 0; i < len(stmt.Message); i++ {
+		c := stmt.Message[i]
+		if c != '%' {
+			buf.WriteByte(c)
+			continue
+		}
+		if i+1 < len(stmt.Message) && stmt.Message[i+1] == '%' {
+			buf.WriteByte('%')
+			i++
+			continue
+		}
+		// The parser checks that the number of arguments matches the number of
+		// parameters in the message.
+		d, err := in.evalExpr(stmt.Args[argIdx], types.Any)
+		if err != nil {
+			return err
+		}
+		argIdx++
+		if d == tree.DNull {
+			buf.WriteString("<NULL>")
+		} else {
+			buf.WriteString(tree.AsStringWithFlags(d, tree.FmtBareStrings))
+		}
+	}
+	return pgerror.NewError(pgerror.CodeRaiseExceptionError, buf.String())
+}
+
+// execSQL executes a SQL statement. With INTO, the values of the first row
+// returned by the statement are assigned to the given variables, which are
+// set to NULL if no row is returned.
+func (in *interpreter) execSQL(stmt *tree.RoutineExec) error {
+	sql, err := in.bindVars(tree.AsStringWithFlags(stmt.Statement, tree.FmtParsable))
+	if err != nil {
+		return err
+	}
+	if len(stmt.Into) == 0 {
+		if _, ok := stmt.Statement.(*tree.Select); ok {
+			_, err = in.ie.Query(in.ctx, in.opName, in.txn, sql)
+		} else {
+			_, err = in.ie.Exec(in.ctx, in.opName, in.txn, sql)
+		}
+		return err
+	}
+
+	rows, err := in.ie.Query(in.ctx, in.opName, in.txn, sql)
+	if err != nil {
+		return err
+	}
+	var row tree.Datums
+	if len(rows) > 0 {
+		row = rows[0]
+		if len(row) != len(stmt.Into) {
+			return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+				"query returned %d columns, but INTO has %d variables", len(row), len(stmt.Into))
+		}
+	}
+	for i, name := range stmt.Into {
+		v, err := in.lookupVar(string(name))
+		if err != nil {
+			return err
+		}
+		if v.constant {
+			return pgerror.NewErrorf(pgerror.CodeErrorInAssignmentError,
+				"variable %q is declared CONSTANT", v.name)
+		}
+		val := tree.Datum(tree.DNull)
+		if row != nil {
+			val = row[i]
+		}
+		if err := in.setVar(v, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execSQLBody executes the statements of the body of a SQL function, and
+// returns the value of the first row returned by the last statement, cast to
+// the return type of the function.
+func (in *interpreter) execSQLBody() (tree.Datum, error) {
+	stmts := in.r.SQLBody
+	if len(stmts) == 0 {
+		return tree.DNull, nil
+	}
+	for _, stmt := range stmts[:len(stmts)-1] {
+		if err := in.execSQL(&tree.RoutineExec{Statement: stmt}); err != nil {
+			return nil, err
+		}
+	}
+
+	sql, err := in.bindVars(tree.AsStringWithFlags(stmts[len(stmts)-1], tree.FmtParsable))
+	if err != nil {
+		return nil, err
+	}
+	rows, cols, err := in.ie.QueryWithCols(in.ctx, in.opName, in.txn, sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(cols) != 1 {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+			"the last statement of function %s must return a single column, not %d", in.r.Name, len(cols))
+	}
+	if len(rows) == 0 || rows[0][0] == tree.DNull {
+		return tree.DNull, nil
+	}
+	return tree.PerformCast(in.evalCtx, rows[0][0], in.r.ReturnType)
+}
+
+func (in *interpreter) evalCond(expr tree.Expr, clause string) (bool, error) {
+	d, err := in.evalExpr(expr, types.Bool)
+	if err != nil || d == tree.DNull {
+		return false, err
+	}
+	b, ok := d.(*tree.DBool)
+	if !ok {
+		return false, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+			"argument of %s must be type bool, not type %s", clause, d.ResolvedType())
+	}
+	return bool(*b), nil
+}
+
+// evalExpr evaluates an expression in the body of the routine. Expressions
+// without subqueries are evaluated locally; the others are executed as a
+// SELECT statement.
+func (in *interpreter) evalExpr(expr tree.Expr, desired types.T) (tree.Datum, error) {
+	v := varVisitor{in: in}
+	newExpr, _ := tree.WalkExpr(&v, expr)
+	if v.err != nil {
+		return nil, v.err
+	}
+	if v.hasSubquery {
+		sql, err := in.bindVars(tree.AsStringWithFlags(newExpr, tree.FmtParsable))
+		if err != nil {
+			return nil, err
+		}
+		row, err := in.ie.QueryRow(in.ctx, in.opName, in.txn, "SELECT "+sql)
+		if err != nil {
+			return nil, err
+		}
+		if len(row) != 1 {
+			return nil, pgerror.NewAssertionErrorf("expected a single value, got %d", len(row))
+		}
+		return row[0], nil
+	}
+
+	semaCtx := tree.MakeSemaContext()
+	if in.evalCtx.SessionData != nil {
+		semaCtx.SearchPath = in.evalCtx.SessionData.SearchPath
+	}
+	semaCtx.Properties.Require("function bodies", tree.RejectSpecial)
+	typedExpr, err := tree.TypeCheck(newExpr, &semaCtx, desired)
+	if err != nil {
+		return nil, err
+	}
+	return typedExpr.Eval(in.evalCtx)
+}
+
+// bindVars replaces the references to the variables and parameters of the
+// routine in the given SQL text with their values.
+func (in *interpreter) bindVars(sql string) (string, error) {
+	refs, err := parser.FindRoutineVarRefs(sql, func(name string) bool {
+		_, err := in.lookupVar(name)
+		return err == nil
+	})
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	pos := 0
+	for _, ref := range refs {
+		val, err := in.lookupValue(ref.Name)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(sql[pos:ref.Start])
+		buf.WriteByte('(')
+		buf.WriteString(tree.AsStringWithFlags(val, tree.FmtParsable))
+		buf.WriteByte(')')
+		pos = ref.End
+	}
+	buf.WriteString(sql[pos:])
+	return buf.String(), nil
+}
+
+func (in *interpreter) lookupVar(name string) (*variable, error) {
+	for s := in.scope; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return v, nil
+		}
+	}
+	return nil, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+		"variable %q does not exist", name)
+}
+
+// lookupValue returns the value of a variable, or of a parameter referenced by
+// position ($<n>), as an expression. NULL values are typed, so that the
+// expressions in which they are substituted can be type checked.
+func (in *interpreter) lookupValue(name string) (tree.TypedExpr, error) {
+	var v *variable
+	if name[0] == '$' {
+		var idx int
+		if _, err := fmt.Sscanf(name, "$%d", &idx); err != nil || idx < 1 || idx > len(in.params) {
+			return nil, pgerror.NewErrorf(pgerror.CodeUndefinedParameterError,
+				"there is no parameter %s", name)
+		}
+		v = in.params[idx-1]
+	} else {
+		var err error
+		if v, err = in.lookupVar(name); err != nil {
+			return nil, err
+		}
+	}
+	if v.val == tree.DNull {
+		return tree.ReType(tree.DNull, coltypes.CastTargetToDatumType(v.typ))
+	}
+	return v.val, nil
+}
+
+// setVar assigns a value to a variable, casting it to the type of the
+// variable.
+func (in *interpreter) setVar(v *variable, d tree.Datum) error {
+	if d == tree.DNull {
+		if v.notNull {
+			return pgerror.NewErrorf(pgerror.CodeNullValueNotAllowedError,
+				"null value cannot be assigned to variable %q declared NOT NULL", v.name)
+		}
+		v.val = d
+		return nil
+	}
+	d, err := tree.PerformCast(in.evalCtx, d, v.typ)
+	if err != nil {
+		return err
+	}
+	v.val = d
+	return nil
+}
+
+// varVisitor replaces the references to the variables and parameters of the
+// routine in an expression with their values. Subqueries are not walked: when
+// one is found, hasSubquery is set, and the references it contains must be
+// replaced using bindVars.
+type varVisitor struct {
+	in          *interpreter
+	hasSubquery bool
+	err         error
+}
+
+var _ tree.Visitor = &varVisitor{}
+
+func (v *varVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if v.err != nil {
+		return false, expr
+	}
+	switch t := expr.(type) {
+	case *tree.Subquery:
+		v.hasSubquery = true
+		return false, expr
+
+	case *tree.Placeholder:
+		val, err := v.in.lookupValue(fmt.Sprintf("$%d", t.Idx+1))
+		if err != nil {
+			v.err = err
+			return false, expr
+		}
+		return false, val
+
+	case *tree.UnresolvedName:
+		if t.NumParts != 1 || t.Star {
+			return true, expr
+		}
+		if _, err := v.in.lookupVar(t.Parts[0]); err != nil {
+			// Not a variable: leave the name for type checking to report.
+			return true, expr
+		}
+		val, err := v.in.lookupValue(t.Parts[0])
+		if err != nil {
+			v.err = err
+			return false, expr
+		}
+		return false, val
+	}
+	return true, expr
+}
+
+func (*varVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
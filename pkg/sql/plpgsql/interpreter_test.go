@@ -0,0 +1,324 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package plpgsql_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/plpgsql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// fakeExecutor records the statements it is given, and returns rows for the
+// queries.
+type fakeExecutor struct {
+	stmts []string
+	rows  []tree.Datums
+}
+
+func (e *fakeExecutor) Exec(
+	_ context.Context, _ string, _ *client.Txn, stmt string, _ ...interface{},
+) (int, error) {
+	e.stmts = append(e.stmts, stmt)
+	return 1, nil
+}
+
+func (e *fakeExecutor) Query(
+	_ context.Context, _ string, _ *client.Txn, stmt string, _ ...interface{},
+) ([]tree.Datums, error) {
+	e.stmts = append(e.stmts, stmt)
+	return e.rows, nil
+}
+
+func (e *fakeExecutor) QueryWithCols(
+	ctx context.Context, opName string, txn *client.Txn, stmt string, qargs ...interface{},
+) ([]tree.Datums, sqlbase.ResultColumns, error) {
+	rows, err := e.Query(ctx, opName, txn, stmt, qargs...)
+	var cols sqlbase.ResultColumns
+	if len(rows) > 0 {
+		cols = make(sqlbase.ResultColumns, len(rows[0]))
+	}
+	return rows, cols, err
+}
+
+func (e *fakeExecutor) QueryRow(
+	ctx context.Context, opName string, txn *client.Txn, stmt string, qargs ...interface{},
+) (tree.Datums, error) {
+	rows, err := e.Query(ctx, opName, txn, stmt, qargs...)
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	return rows[0], nil
+}
+
+func TestExec(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params := []plpgsql.Param{{Name: "n", Type: coltypes.Int8}, {Type: coltypes.String}}
+	testData := []struct {
+		body       string
+		args       tree.Datums
+		returnType coltypes.CastTargetType
+		rows       []tree.Datums
+		expected   string
+		stmts      []string
+		err        string
+	}{
+		{
+			body:       `BEGIN RETURN n + 1; END`,
+			args:       tree.Datums{tree.NewDInt(1), tree.NewDString("a")},
+			returnType: coltypes.Int8,
+			expected:   `2`,
+		},
+		{
+			// Parameters can be referenced by position.
+			body:       `BEGIN RETURN $2 || 'b'; END`,
+			args:       tree.Datums{tree.NewDInt(1), tree.NewDString("a")},
+			returnType: coltypes.String,
+			expected:   `'ab'`,
+		},
+		{
+			// The returned value is cast to the return type.
+			body:       `BEGIN RETURN $1 * 2; END`,
+			args:       tree.Datums{tree.NewDInt(1), tree.NewDString("a")},
+			returnType: coltypes.String,
+			expected:   `'2'`,
+		},
+		{
+			body: `
+DECLARE
+  total INT := 0;
+  i INT NOT NULL DEFAULT 1;
+BEGIN
+  WHILE i <= n LOOP
+    total := total + i;
+    i := i + 1;
+  END LOOP;
+  LOOP
+    i := i - 1;
+    CONTINUE WHEN i % 2 = 0;
+    EXIT WHEN i < 3;
+    total := total + 100;
+  END LOOP;
+  RETURN total;
+END`,
+			args:       tree.Datums{tree.NewDInt(5), tree.DNull},
+			returnType: coltypes.Int8,
+			expected:   `215`,
+		},
+		{
+			body: `
+DECLARE
+  c INT;
+  s STRING;
+BEGIN
+  INSERT INTO log (k, v) VALUES (n, $2);
+  SELECT count(*), max(v) INTO c, s FROM log WHERE k = n;
+  DECLARE
+    n INT := c * 10;
+  BEGIN
+    UPDATE log SET v = s WHERE k = n AND log.n = $1;
+  END;
+  RETURN c;
+END`,
+			args:       tree.Datums{tree.NewDInt(7), tree.NewDString("x")},
+			returnType: coltypes.Int8,
+			rows:       []tree.Datums{{tree.NewDInt(3), tree.NewDString("y")}},
+			expected:   `3`,
+			stmts: []string{
+				`INSERT INTO log(k, v) VALUES ((7:::INT8), ('x':::STRING))`,
+				`SELECT count(*), max(v) FROM log WHERE k = (7:::INT8)`,
+				`UPDATE log SET v = ('y':::STRING) WHERE (k = (30:::INT8)) AND (log.n = (7:::INT8))`,
+			},
+		},
+		{
+			// Without rows, the variables of the INTO clause are set to NULL.
+			body: `
+DECLARE
+  c INT := 1;
+BEGIN
+  SELECT k INTO c FROM log;
+  IF c IS NULL THEN
+    RETURN -1;
+  END IF;
+  RETURN 0;
+END`,
+			args:       tree.Datums{tree.NewDInt(7), tree.DNull},
+			returnType: coltypes.Int8,
+			expected:   `-1`,
+			stmts:      []string{`SELECT k FROM log`},
+		},
+		{
+			// Expressions with subqueries are executed as a SELECT statement.
+			body:       `BEGIN RETURN (SELECT max(k) FROM log WHERE v = $2) + 1; END`,
+			args:       tree.Datums{tree.NewDInt(7), tree.DNull},
+			returnType: coltypes.Int8,
+			rows:       []tree.Datums{{tree.NewDInt(9)}},
+			expected:   `9`,
+			stmts: []string{
+				`SELECT (SELECT max(k) FROM log WHERE v = (CAST(NULL AS STRING))) + 1`,
+			},
+		},
+		{
+			body:     `BEGIN DELETE FROM log WHERE k = n; RETURN; END`,
+			args:     tree.Datums{tree.NewDInt(7), tree.DNull},
+			expected: `NULL`,
+			stmts:    []string{`DELETE FROM log WHERE k = (7:::INT8)`},
+		},
+		{
+			body:       `BEGIN IF n > 0 THEN RAISE EXCEPTION 'bad value % for %%', n; END IF; END`,
+			args:       tree.Datums{tree.NewDInt(7), tree.DNull},
+			returnType: coltypes.Int8,
+			err:        `bad value 7 for %`,
+		},
+		{
+			body:       `BEGIN IF n > 0 THEN RETURN 1; END IF; END`,
+			args:       tree.Datums{tree.NewDInt(-1), tree.DNull},
+			returnType: coltypes.Int8,
+			err:        `control reached end of function f without RETURN`,
+		},
+		{
+			body: `DECLARE c CONSTANT INT := 1; BEGIN c := 2; END`,
+			args: tree.Datums{tree.NewDInt(1), tree.DNull},
+			err:  `variable "c" is declared CONSTANT`,
+		},
+		{
+			body: `DECLARE c INT NOT NULL := 1; BEGIN c := NULL; END`,
+			args: tree.Datums{tree.NewDInt(1), tree.DNull},
+			err:  `null value cannot be assigned to variable "c" declared NOT NULL`,
+		},
+		{
+			body: `BEGIN IF n THEN RETURN; END IF; END`,
+			args: tree.Datums{tree.NewDInt(1), tree.DNull},
+			err:  `argument of IF must be type bool, not type int`,
+		},
+		{
+			body: `BEGIN RETURN n; END`,
+			args: tree.Datums{tree.NewDInt(1), tree.DNull},
+			err:  `RETURN cannot have a value in function f, which does not return a value`,
+		},
+		{
+			body: `BEGIN DELETE FROM log WHERE k = $3; END`,
+			args: tree.Datums{tree.NewDInt(1), tree.DNull},
+			err:  `there is no parameter \$3`,
+		},
+	}
+	for _, d := range testData {
+		t.Run(d.body, func(t *testing.T) {
+			ctx := context.Background()
+			evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+			defer evalCtx.Stop(ctx)
+
+			body, err := parser.ParseRoutineBody(d.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &plpgsql.Routine{Name: "f", Params: params, ReturnType: d.returnType, Body: body}
+			ie := &fakeExecutor{rows: d.rows}
+			res, err := plpgsql.Exec(ctx, evalCtx, ie, nil /* txn */, r, d.args)
+			if d.err != "" {
+				if !testutils.IsError(err, d.err) {
+					t.Fatalf("expected %q, got %v", d.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := tree.AsStringWithFlags(res, tree.FmtBareIdentifiers); s != d.expected {
+				t.Errorf("expected %s, got %s", d.expected, s)
+			}
+			if !reflect.DeepEqual(ie.stmts, d.stmts) {
+				t.Errorf("expected statements:\n%q\ngot:\n%q", d.stmts, ie.stmts)
+			}
+		})
+	}
+}
+
+func TestExecSQLBody(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	params := []plpgsql.Param{{Name: "n", Type: coltypes.Int8}, {Type: coltypes.String}}
+	testData := []struct {
+		body     string
+		rows     []tree.Datums
+		expected string
+		stmts    []string
+		err      string
+	}{
+		{
+			// The first column of the first row of the last statement is
+			// returned, cast to the return type.
+			body:     `INSERT INTO log VALUES (n, $2); SELECT k FROM log WHERE v = $2`,
+			rows:     []tree.Datums{{tree.NewDInt(3)}, {tree.NewDInt(4)}},
+			expected: `'3'`,
+			stmts: []string{
+				`INSERT INTO log VALUES ((7:::INT8), ('x':::STRING))`,
+				`SELECT k FROM log WHERE v = ('x':::STRING)`,
+			},
+		},
+		{
+			body:     `DELETE FROM log WHERE k = n RETURNING NULL`,
+			rows:     []tree.Datums{{tree.DNull}},
+			expected: `NULL`,
+			stmts:    []string{`DELETE FROM log WHERE k = (7:::INT8) RETURNING NULL`},
+		},
+		{
+			body: `SELECT k, v FROM log`,
+			rows: []tree.Datums{{tree.NewDInt(3), tree.NewDString("y")}},
+			err:  `the last statement of function f must return a single column, not 2`,
+		},
+	}
+	for _, d := range testData {
+		t.Run(d.body, func(t *testing.T) {
+			ctx := context.Background()
+			evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+			defer evalCtx.Stop(ctx)
+
+			body, err := parser.ParseSQLFunctionBody(d.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &plpgsql.Routine{Name: "f", Params: params, ReturnType: coltypes.String, SQLBody: body}
+			ie := &fakeExecutor{rows: d.rows}
+			args := tree.Datums{tree.NewDInt(7), tree.NewDString("x")}
+			res, err := plpgsql.Exec(ctx, evalCtx, ie, nil /* txn */, r, args)
+			if d.err != "" {
+				if !testutils.IsError(err, d.err) {
+					t.Fatalf("expected %q, got %v", d.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := tree.AsStringWithFlags(res, tree.FmtBareIdentifiers); s != d.expected {
+				t.Errorf("expected %s, got %s", d.expected, s)
+			}
+			if !reflect.DeepEqual(ie.stmts, d.stmts) {
+				t.Errorf("expected statements:\n%q\ngot:\n%q", d.stmts, ie.stmts)
+			}
+		})
+	}
+}
@@ -69,6 +69,12 @@ type projectSetNode struct {
 	// each entry in `exprs`.
 	numColsPerGen []int
 
+	// bounds contains, for every entry in `exprs`, the bounds derived by
+	// constrainGenerators from the filters applied to the results of the
+	// projectSetNode. They let the generators skip the values that the
+	// filters reject. It is nil if no bounds were derived.
+	bounds []tree.ValueGeneratorBounds
+
 	// props are the ordering, key props etc.
 	props physicalProps
 
@@ -279,6 +285,9 @@ func (n *projectSetNode) Next(params runParams) (bool, error) {
 					if gen == nil {
 						gen = builtins.EmptyGenerator()
 					}
+					if bg, ok := gen.(tree.BoundedValueGenerator); ok && n.bounds != nil {
+						bg.SetBounds(n.bounds[i])
+					}
 					if err := gen.Start(); err != nil {
 						return false, err
 					}
@@ -363,6 +372,76 @@ func (n *projectSetNode) Close(ctx context.Context) {
 	}
 }
 
+// constrainGenerators derives, from a filter applied to the results of the
+// projectSetNode, bounds on the values of the generators which produce a
+// single column. Only the conjuncts which compare such a column with a
+// constant of the same type are used.
+func (n *projectSetNode) constrainGenerators(evalCtx *tree.EvalContext, filter tree.TypedExpr) {
+	switch t := filter.(type) {
+	case *tree.AndExpr:
+		n.constrainGenerators(evalCtx, t.TypedLeft())
+		n.constrainGenerators(evalCtx, t.TypedRight())
+		return
+	case *tree.ComparisonExpr:
+		op := t.Operator
+		v, left := t.Left.(*tree.IndexedVar)
+		d, ok := t.Right.(tree.Datum)
+		if !left {
+			// The column may be on the right side, e.g. 10 > g.
+			v, _ = t.Right.(*tree.IndexedVar)
+			d, ok = t.Left.(tree.Datum)
+			switch op {
+			case tree.LT:
+				op = tree.GT
+			case tree.LE:
+				op = tree.GE
+			case tree.GT:
+				op = tree.LT
+			case tree.GE:
+				op = tree.LE
+			}
+		}
+		if v == nil || !ok || d == tree.DNull {
+			return
+		}
+		i := n.generatorForColumn(v.Idx)
+		if i < 0 || !d.ResolvedType().Equivalent(n.columns[v.Idx].Typ) {
+			return
+		}
+		if n.bounds == nil {
+			n.bounds = make([]tree.ValueGeneratorBounds, len(n.exprs))
+		}
+		b := &n.bounds[i]
+		if op == tree.EQ || op == tree.GT || op == tree.GE {
+			if b.Lo == nil || b.Lo.Compare(evalCtx, d) < 0 {
+				b.Lo = d
+			}
+		}
+		if op == tree.EQ || op == tree.LT || op == tree.LE {
+			if b.Hi == nil || b.Hi.Compare(evalCtx, d) > 0 {
+				b.Hi = d
+			}
+		}
+	}
+}
+
+// generatorForColumn returns the index in `exprs` of the generator which
+// produces the given column, if it is a generator producing a single
+// column, or -1 otherwise.
+func (n *projectSetNode) generatorForColumn(col int) int {
+	colIdx := n.numColsInSource
+	for i := range n.exprs {
+		if col == colIdx {
+			if n.funcs[i] != nil && n.numColsPerGen[i] == 1 {
+				return i
+			}
+			return -1
+		}
+		colIdx += n.numColsPerGen[i]
+	}
+	return -1
+}
+
 func (n *projectSetNode) computePhysicalProps() {
 	// We can pass through properties because projectSetNode preserves
 	// all input columns, and they come first.
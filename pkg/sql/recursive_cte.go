@@ -0,0 +1,381 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/server/telemetry"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// This file contains the implementation of recursive common table
+// expressions, of the form:
+//
+//   WITH RECURSIVE t AS (<initial> UNION [ALL] <recursive>) ...
+//
+// where the recursive term refers to t. The initial term is evaluated first,
+// and its rows are both returned and used as the "working table" to which t
+// refers in the recursive term. The recursive term is then evaluated
+// repeatedly, each time with the rows produced by the previous iteration as
+// the working table, until an iteration produces no rows. With UNION (as
+// opposed to UNION ALL) the rows which were already produced are discarded,
+// which ensures that the recursion on cyclic data terminates.
+//
+// Since the working table changes at every iteration, the recursive term is
+// planned anew for each one, in the same way as the right side of an apply
+// join, and the resulting plan is run inside the plan of the query.
+
+// recursiveCTEMaxIterations bounds the number of iterations of the recursive
+// terms, so that a recursion which does not terminate, e.g. with UNION ALL on
+// cyclic data, eventually fails instead of running forever.
+var recursiveCTEMaxIterations = settings.RegisterNonNegativeIntSetting(
+	"sql.recursive_cte.max_iterations",
+	"the maximum number of iterations of the recursive term of a WITH RECURSIVE "+
+		"query after which the query fails (0 to disable)",
+	10000,
+)
+
+// recursiveCTENode implements a recursive common table expression.
+type recursiveCTENode struct {
+	// initial is the plan of the non-recursive term.
+	initial planNode
+
+	// recursive is the recursive term, which is planned for each iteration.
+	recursive *tree.Select
+
+	// alias is the name of the common table expression, and the renaming of
+	// its columns if present.
+	alias tree.AliasClause
+
+	// env is the CTE name environment in which the recursive term is
+	// planned, without the frame of the working table. See
+	// cteNameEnvironment.hideForRecursiveTerm.
+	env cteNameEnvironment
+
+	// columns contains the metadata for the results of this node, which are
+	// the columns of the initial term.
+	columns sqlbase.ResultColumns
+
+	// emitAll is set for UNION ALL. Otherwise the duplicate rows are
+	// discarded.
+	emitAll bool
+
+	run recursiveCTERun
+}
+
+// recursiveCTERun contains the run-time state of recursiveCTENode during
+// local execution.
+type recursiveCTERun struct {
+	// workingRows collects the rows produced by the current iteration, which
+	// are the working table of the next one.
+	workingRows *rowcontainer.RowContainer
+	// iterationRows are the results of the current iteration of the
+	// recursive term. It is nil while the initial term is being read.
+	iterationRows *rowcontainer.RowContainer
+	// nextRow is the index of the next row of iterationRows to return.
+	nextRow int
+	// iterations is the number of iterations of the recursive term so far.
+	iterations int64
+
+	// seen contains the encodings of the rows produced so far, for UNION.
+	seen    map[string]struct{}
+	seenAcc mon.BoundAccount
+	scratch []byte
+
+	values tree.Datums
+	done   bool
+}
+
+// newRecursiveCTEPlan plans a common table expression of a WITH RECURSIVE
+// clause. The expressions of the form <initial> UNION [ALL] <recursive>,
+// where the recursive term refers to the expression, are planned as a
+// recursiveCTENode; the other ones are planned as regular common table
+// expressions.
+func (p *planner) newRecursiveCTEPlan(ctx context.Context, cte *tree.CTE) (planNode, error) {
+	sel, ok := cte.Stmt.(*tree.Select)
+	if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Locking != nil {
+		return p.newPlan(ctx, cte.Stmt, nil /* desiredTypes */)
+	}
+	union, ok := sel.Select.(*tree.UnionClause)
+	if !ok || union.Type != tree.UnionOp {
+		return p.newPlan(ctx, cte.Stmt, nil /* desiredTypes */)
+	}
+
+	initial, err := p.newPlan(ctx, union.Left, nil /* desiredTypes */)
+	if err != nil {
+		return nil, err
+	}
+	n := &recursiveCTENode{
+		initial:   initial,
+		recursive: union.Right,
+		alias:     cte.Name,
+		env:       p.curPlan.cteNameEnvironment.hideForRecursiveTerm(),
+		columns:   append(sqlbase.ResultColumns(nil), planColumns(initial)...),
+		emitAll:   union.All,
+	}
+
+	// Plan the recursive term a first time, to find out whether it refers to
+	// the working table and to check its columns.
+	working := n.newWorkingTable(nil /* rows */)
+	recursive, used, err := p.newRecursiveTermPlan(ctx, n, working)
+	if !used {
+		// The expression is not recursive after all; it is planned as a
+		// regular union. Errors are ignored, since they may come from the
+		// CTEs hidden from the recursive term.
+		if recursive != nil {
+			recursive.close(ctx)
+		}
+		right, err := p.newPlan(ctx, union.Right, nil /* desiredTypes */)
+		if err != nil {
+			initial.Close(ctx)
+			return nil, err
+		}
+		return p.newUnionNode(union.Type, union.All, initial, right)
+	}
+	if err != nil {
+		initial.Close(ctx)
+		return nil, err
+	}
+	defer recursive.close(ctx)
+	p.curPlan.hasStar = p.curPlan.hasStar || recursive.hasStar
+	p.curPlan.auditEvents = append(p.curPlan.auditEvents, recursive.auditEvents...)
+
+	if err := n.checkRecursiveColumns(recursive.columns()); err != nil {
+		initial.Close(ctx)
+		return nil, err
+	}
+
+	telemetry.Inc(sqltelemetry.RecursiveCteUseCounter)
+
+	return n, nil
+}
+
+// checkRecursiveColumns checks that the columns of the recursive term match
+// those of the initial term.
+func (n *recursiveCTENode) checkRecursiveColumns(cols sqlbase.ResultColumns) error {
+	if len(cols) != len(n.columns) {
+		return pgerror.NewErrorf(
+			pgerror.CodeSyntaxError,
+			"each UNION query must have the same number of columns: %d vs %d",
+			len(n.columns), len(cols),
+		)
+	}
+	for i := range cols {
+		if cols[i].Typ == types.Unknown || cols[i].Typ.Equivalent(n.columns[i].Typ) {
+			continue
+		}
+		return pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+			"recursive query %q column %d has type %s in non-recursive term but type %s overall",
+			tree.ErrString(&n.alias.Alias), i+1, n.columns[i].Typ, cols[i].Typ,
+		).SetHintf("Cast the output of the non-recursive term to the correct type.")
+	}
+	return nil
+}
+
+// newWorkingTable returns a plan which produces the given rows, to which
+// the name of the expression refers in the recursive term.
+func (n *recursiveCTENode) newWorkingTable(rows *rowcontainer.RowContainer) *valuesNode {
+	return &valuesNode{
+		columns: append(sqlbase.ResultColumns(nil), n.columns...),
+		isConst: true,
+		valuesRun: valuesRun{
+			rows: rows,
+		},
+	}
+}
+
+// newRecursiveTermPlan plans and optimizes the recursive term of n with a
+// copy of the planner, in which the name of the expression refers to the
+// given working table. used is set if the recursive term refers to the
+// working table, even if an error is returned.
+func (p *planner) newRecursiveTermPlan(
+	ctx context.Context, n *recursiveCTENode, working *valuesNode,
+) (_ *planTop, used bool, _ error) {
+	frame := cteNameEnvironmentFrame{
+		n.alias.Alias: &cteSource{plan: working, alias: n.alias},
+	}
+	plannerCopy := *p
+	plannerCopy.curPlan = planTop{
+		AST:                n.recursive,
+		deps:               p.curPlan.deps,
+		cteNameEnvironment: append(n.env[:len(n.env):len(n.env)], frame),
+	}
+	plan := &plannerCopy.curPlan
+
+	var err error
+	plan.plan, err = plannerCopy.newPlan(ctx, n.recursive, nil /* desiredTypes */)
+	used = frame[n.alias.Alias].used
+	if err != nil {
+		plan.close(ctx)
+		return nil, used, err
+	}
+	plan.plan, err = plannerCopy.hideHiddenColumns(ctx, plan.plan, plan.columns())
+	if err == nil {
+		plan.plan, err = plannerCopy.optimizePlan(ctx, plan.plan, allColumns(plan.plan))
+	}
+	for i := range plan.subqueryPlans {
+		if err != nil {
+			break
+		}
+		err = plannerCopy.optimizeSubquery(ctx, &plan.subqueryPlans[i])
+	}
+	if err != nil {
+		plan.close(ctx)
+		return nil, used, err
+	}
+	return plan, used, nil
+}
+
+func (n *recursiveCTENode) startExec(params runParams) error {
+	n.run.workingRows = rowcontainer.NewRowContainer(
+		params.EvalContext().Mon.MakeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(n.columns),
+		0, /* rowCapacity */
+	)
+	if !n.emitAll {
+		n.run.seen = make(map[string]struct{})
+		n.run.seenAcc = params.EvalContext().Mon.MakeBoundAccount()
+	}
+	return nil
+}
+
+func (n *recursiveCTENode) Next(params runParams) (bool, error) {
+	if n.run.done {
+		return false, nil
+	}
+	for {
+		if err := params.p.cancelChecker.Check(); err != nil {
+			return false, err
+		}
+
+		var row tree.Datums
+		var ok bool
+		if n.run.iterationRows == nil {
+			var err error
+			if ok, err = n.initial.Next(params); err != nil {
+				return false, err
+			}
+			if ok {
+				row = n.initial.Values()
+			}
+		} else if n.run.nextRow < n.run.iterationRows.Len() {
+			ok = true
+			row = n.run.iterationRows.At(n.run.nextRow)
+			n.run.nextRow++
+		}
+
+		if !ok {
+			// The current iteration is exhausted.
+			if n.run.workingRows.Len() == 0 {
+				n.run.done = true
+				return false, nil
+			}
+			if err := n.runIteration(params); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		if !n.emitAll {
+			var err error
+			n.run.scratch, err = sqlbase.EncodeDatumsKeyAscending(n.run.scratch[:0], row)
+			if err != nil {
+				return false, err
+			}
+			if _, ok := n.run.seen[string(n.run.scratch)]; ok {
+				continue
+			}
+			if err := n.run.seenAcc.Grow(params.ctx, int64(len(n.run.scratch))); err != nil {
+				return false, err
+			}
+			n.run.seen[string(n.run.scratch)] = struct{}{}
+		}
+
+		if _, err := n.run.workingRows.AddRow(params.ctx, row); err != nil {
+			return false, err
+		}
+		n.run.values = row
+		return true, nil
+	}
+}
+
+// runIteration runs the next iteration of the recursive term, with the rows
+// produced by the previous one as working table.
+func (n *recursiveCTENode) runIteration(params runParams) error {
+	n.run.iterations++
+	if max := recursiveCTEMaxIterations.Get(&params.ExecCfg().Settings.SV); max > 0 &&
+		n.run.iterations > max {
+		return pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"recursive query %q exceeded the maximum of %d iterations",
+			tree.ErrString(&n.alias.Alias), max,
+		).SetHintf("The maximum is set by the cluster setting sql.recursive_cte.max_iterations.")
+	}
+
+	// The working table takes over the rows of the previous iteration, and
+	// closes them when the plan of the iteration is closed.
+	working := n.newWorkingTable(n.run.workingRows)
+	n.run.workingRows = rowcontainer.NewRowContainer(
+		params.EvalContext().Mon.MakeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(n.columns),
+		0, /* rowCapacity */
+	)
+
+	plan, _, err := params.p.newRecursiveTermPlan(params.ctx, n, working)
+	if err != nil {
+		working.Close(params.ctx)
+		return err
+	}
+	defer plan.close(params.ctx)
+
+	if n.run.iterationRows == nil {
+		n.run.iterationRows = rowcontainer.NewRowContainer(
+			params.EvalContext().Mon.MakeBoundAccount(),
+			sqlbase.ColTypeInfoFromResCols(n.columns),
+			0, /* rowCapacity */
+		)
+	} else {
+		n.run.iterationRows.Clear(params.ctx)
+	}
+	n.run.nextRow = 0
+	return runPlanInsidePlan(params, plan, n.run.iterationRows)
+}
+
+func (n *recursiveCTENode) Values() tree.Datums {
+	return n.run.values
+}
+
+func (n *recursiveCTENode) Close(ctx context.Context) {
+	n.initial.Close(ctx)
+	if n.run.workingRows != nil {
+		n.run.workingRows.Close(ctx)
+		n.run.workingRows = nil
+	}
+	if n.run.iterationRows != nil {
+		n.run.iterationRows.Close(ctx)
+		n.run.iterationRows = nil
+	}
+	if n.run.seen != nil {
+		n.run.seen = nil
+		n.run.seenAcc.Close(ctx)
+	}
+}
@@ -96,6 +96,7 @@ func (p *planner) Select(
 	limit := n.Limit
 	orderBy := n.OrderBy
 	with := n.With
+	locking := n.Locking
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		wrapped = s.Select.Select
@@ -122,6 +123,17 @@ func (p *planner) Select(
 			}
 			limit = s.Select.Limit
 		}
+		locking = append(locking, s.Select.Locking...)
+	}
+
+	// The heuristic planner does not acquire row-level locks, so it can
+	// ignore the locking strengths; it does not know how to skip locked rows
+	// or to fail on them, though.
+	for _, li := range locking {
+		if li.WaitPolicy != tree.LockWaitBlock {
+			return nil, pgerror.UnimplementedWithIssueDetailErrorf(6583, "locking wait policy",
+				"%s is only supported by the cost-based optimizer", li.WaitPolicy)
+		}
 	}
 
 	switch s := wrapped.(type) {
@@ -155,6 +167,11 @@ func (p *planner) Select(
 			return nil, err
 		}
 		if limit != nil {
+			if limit.withTies {
+				if err := limit.initTies(sort); err != nil {
+					return nil, err
+				}
+			}
 			limit.plan = plan
 			plan = limit
 		}
@@ -191,6 +208,11 @@ func (p *planner) SelectClause(
 	scalarProps := &p.semaCtx.Properties
 	defer scalarProps.Restore(*scalarProps)
 
+	parsed, orderBy, err = tree.ExpandGroupingSets(p.SessionData().SearchPath, parsed, orderBy)
+	if err != nil {
+		return nil, err
+	}
+
 	r := &renderNode{}
 
 	resetter, err := p.initWith(ctx, with)
@@ -315,6 +337,11 @@ func (p *planner) SelectClause(
 	if err != nil {
 		return nil, err
 	}
+	if limitPlan != nil && limitPlan.withTies {
+		if err := limitPlan.initTies(sort); err != nil {
+			return nil, err
+		}
+	}
 
 	result = planNode(r)
 	if groupComplex != nil {
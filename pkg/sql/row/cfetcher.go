@@ -178,6 +178,10 @@ type CFetcher struct {
 	// If set, GetRangesInfo() can be used to retrieve the accumulated info.
 	returnRangeInfo bool
 
+	// lockWaitPolicy specifies the behavior of the scans when they encounter
+	// rows locked by other transactions. See SetLockWaitPolicy.
+	lockWaitPolicy roachpb.WaitPolicy
+
 	// traceKV indicates whether or not session tracing is enabled. It is set
 	// when beginning a new scan.
 	traceKV bool
@@ -393,6 +397,13 @@ func (rf *CFetcher) Init(
 	return nil
 }
 
+// SetLockWaitPolicy sets the behavior of the scans started afterwards when
+// they encounter rows locked by other transactions. It must be called after
+// Init.
+func (rf *CFetcher) SetLockWaitPolicy(waitPolicy roachpb.WaitPolicy) {
+	rf.lockWaitPolicy = waitPolicy
+}
+
 // StartScan initializes and starts the key-value scan. Can be used multiple
 // times.
 func (rf *CFetcher) StartScan(
@@ -423,7 +434,9 @@ func (rf *CFetcher) StartScan(
 		firstBatchLimit++
 	}
 
-	f, err := makeKVBatchFetcher(txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo)
+	f, err := makeKVBatchFetcher(
+		txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo, rf.lockWaitPolicy,
+	)
 	if err != nil {
 		return err
 	}
@@ -1010,7 +1023,8 @@ func (rf *CFetcher) fillNulls() error {
 		return nil
 	}
 	for i, ok := rf.machine.remainingValueColsByIdx.Next(0); ok; i, ok = rf.machine.remainingValueColsByIdx.Next(i + 1) {
-		if !table.cols[i].Nullable {
+		// Virtual columns are never stored, so they never have a value.
+		if !table.cols[i].Nullable && !table.cols[i].Virtual {
 			var indexColValues []string
 			for _, idx := range table.indexColOrdinals {
 				if idx != -1 {
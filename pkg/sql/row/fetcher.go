@@ -200,6 +200,10 @@ type Fetcher struct {
 	// If set, GetRangesInfo() can be used to retrieve the accumulated info.
 	returnRangeInfo bool
 
+	// lockWaitPolicy specifies the behavior of the scans when they encounter
+	// rows locked by other transactions. See SetLockWaitPolicy.
+	lockWaitPolicy roachpb.WaitPolicy
+
 	// traceKV indicates whether or not session tracing is enabled. It is set
 	// when beginning a new scan.
 	traceKV bool
@@ -208,6 +212,11 @@ type Fetcher struct {
 
 	kvFetcher      kvFetcher
 	indexKey       []byte // the index key of the current row
+	rowKey         []byte // the index key of the last row returned by NextRow
+
+	// bytesRead accumulates the bytes read by the kvFetchers of the previous
+	// scans.
+	bytesRead int64
 	prettyValueBuf *bytes.Buffer
 
 	valueColsFound int // how many needed cols we've found so far in the value
@@ -434,6 +443,13 @@ func (rf *Fetcher) Init(
 	return nil
 }
 
+// SetLockWaitPolicy sets the behavior of the scans started afterwards when
+// they encounter rows locked by other transactions. It must be called after
+// Init.
+func (rf *Fetcher) SetLockWaitPolicy(waitPolicy roachpb.WaitPolicy) {
+	rf.lockWaitPolicy = waitPolicy
+}
+
 // StartScan initializes and starts the key-value scan. Can be used multiple
 // times.
 func (rf *Fetcher) StartScan(
@@ -464,7 +480,9 @@ func (rf *Fetcher) StartScan(
 		firstBatchLimit++
 	}
 
-	f, err := makeKVBatchFetcher(txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo)
+	f, err := makeKVBatchFetcher(
+		txn, spans, rf.reverse, limitBatches, firstBatchLimit, rf.returnRangeInfo, rf.lockWaitPolicy,
+	)
 	if err != nil {
 		return err
 	}
@@ -475,6 +493,7 @@ func (rf *Fetcher) StartScan(
 // used multiple times.
 func (rf *Fetcher) StartScanFrom(ctx context.Context, f kvBatchFetcher) error {
 	rf.indexKey = nil
+	rf.bytesRead += rf.kvFetcher.bytesRead
 	rf.kvFetcher = newKVFetcher(f)
 	// Retrieve the first key.
 	_, err := rf.NextKey(ctx)
@@ -675,6 +694,7 @@ func (rf *Fetcher) processKV(
 	if rf.indexKey == nil {
 		// This is the first key for the row.
 		rf.indexKey = []byte(kv.Key[:len(kv.Key)-len(rf.keyRemainingBytes)])
+		rf.rowKey = rf.indexKey
 
 		// Reset the row to nil; it will get filled in with the column
 		// values as we decode the key-value pairs for the row.
@@ -1241,8 +1261,9 @@ func (rf *Fetcher) finalizeRow() error {
 		}
 		if table.neededCols.Contains(int(table.cols[i].ID)) && table.row[i].IsUnset() {
 			// If the row was deleted, we'll be missing any non-primary key
-			// columns, including nullable ones, but this is expected.
-			if !table.cols[i].Nullable && !table.rowIsDeleted {
+			// columns, including nullable ones, but this is expected. Virtual
+			// columns are never stored, so they never have a value either.
+			if !table.cols[i].Nullable && !table.cols[i].Virtual && !table.rowIsDeleted {
 				var indexColValues []string
 				for _, idx := range table.indexColIdx {
 					if idx != -1 {
@@ -1295,12 +1316,24 @@ func (rf *Fetcher) PartialKey(nCols int) (roachpb.Key, error) {
 	return rf.kv.Key[:n+rf.currentTable.knownPrefixLength], nil
 }
 
+// RowKey returns the index key of the last row returned by NextRow. It is
+// only valid until the next call to NextRow.
+func (rf *Fetcher) RowKey() []byte {
+	return rf.rowKey
+}
+
 // GetRangesInfo returns information about the ranges where the rows came from.
 // The RangeInfo's are deduped and not ordered.
 func (rf *Fetcher) GetRangesInfo() []roachpb.RangeInfo {
 	return rf.kvFetcher.getRangesInfo()
 }
 
+// GetBytesRead returns the number of bytes read from KV by all the scans of
+// this Fetcher.
+func (rf *Fetcher) GetBytesRead() int64 {
+	return rf.bytesRead + rf.kvFetcher.bytesRead
+}
+
 // Only unique secondary indexes have extra columns to decode (namely the
 // primary index columns).
 func hasExtraCols(table *tableInfo) bool {
@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
@@ -174,6 +175,19 @@ func makeFkExistenceCheckBaseHelper(
 	}, nil
 }
 
+// searchedValues extracts from the given row the values searched by the
+// existence check, in the order of the columns of searchIdx.
+//
+// TODO(knz): re-allocating a datum slice in every check
+// is super inefficient and expensive. Factor this.
+func (f fkExistenceCheckBaseHelper) searchedValues(row tree.Datums) tree.Datums {
+	fkValues := make(tree.Datums, f.prefixLen)
+	for valueIdx, colID := range f.searchIdx.ColumnIDs[:f.prefixLen] {
+		fkValues[valueIdx] = row[f.ids[colID]]
+	}
+	return fkValues
+}
+
 // computeFkCheckColumnIDs determines the set of column IDs to use for
 // the existence check, depending on the MATCH style.
 //
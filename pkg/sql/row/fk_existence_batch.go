@@ -41,6 +41,27 @@ type fkExistenceBatchChecker struct {
 	// batchIdxToFk maps the index of the check request/response in the kv batch
 	// to the fkExistenceCheckBaseHelper that created it.
 	batchIdxToFk []*fkExistenceCheckBaseHelper
+
+	// inWriteBatch is the list of checks that were moved to a caller-provided
+	// kv batch by moveToWriteBatch(), and which must be verified by
+	// runWriteBatchChecks() once that batch has run.
+	inWriteBatch []fkCheckInWriteBatch
+
+	// kvBuf is a scratch buffer used by runWriteBatchChecks().
+	kvBuf []roachpb.KeyValue
+}
+
+// fkCheckInWriteBatch describes an existence check that was added to a kv
+// batch alongside row writes.
+type fkCheckInWriteBatch struct {
+	// resultIdx is the index of the scan in the Results of the kv batch.
+	resultIdx int
+	// fk is the helper that created the check.
+	fk *fkExistenceCheckBaseHelper
+	// fkValues are the values being searched, kept for error messages
+	// since the mutated row may not be available any more by the time
+	// the check is verified.
+	fkValues tree.Datums
 }
 
 // reset starts a new batch.
@@ -103,16 +124,7 @@ func (f *fkExistenceBatchChecker) runCheck(
 		case CheckInserts:
 			// If we're inserting, then there's a violation if the scan found nothing.
 			if fk.rf.kvEnd {
-				// TODO(knz): re-allocating a datum slice in every check
-				// is super inefficient and expensive. Factor this.
-				fkValues := make(tree.Datums, fk.prefixLen)
-
-				for valueIdx, colID := range fk.searchIdx.ColumnIDs[:fk.prefixLen] {
-					fkValues[valueIdx] = newRow[fk.ids[colID]]
-				}
-				return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
-					"foreign key violation: value %s not found in %s@%s %s (txn=%s)",
-					fkValues, fk.searchTable.Name, fk.searchIdx.Name, fk.searchIdx.ColumnNames[:fk.prefixLen], f.txn.ID())
+				return f.insertViolationError(fk, fk.searchedValues(newRow))
 			}
 
 		case CheckDeletes:
@@ -144,6 +156,84 @@ func (f *fkExistenceBatchChecker) runCheck(
 	return nil
 }
 
+// insertViolationError produces the error reported when fkValues were not
+// found in the table searched by fk.
+func (f *fkExistenceBatchChecker) insertViolationError(
+	fk *fkExistenceCheckBaseHelper, fkValues tree.Datums,
+) error {
+	return pgerror.NewErrorf(pgerror.CodeForeignKeyViolationError,
+		"foreign key violation: value %s not found in %s@%s %s (txn=%s)",
+		fkValues, fk.searchTable.Name, fk.searchIdx.Name, fk.searchIdx.ColumnNames[:fk.prefixLen], f.txn.ID())
+}
+
+// moveToWriteBatch transfers the accumulated checks for an inserted row to
+// the given kv batch, so that the lookups are sent in the same round-trip as
+// the writes already in it. This is only valid for CheckInserts helpers that
+// search a table other than the one being written to: scans and writes in a
+// single batch are not ordered with respect to each other.
+//
+// The checks must be verified with runWriteBatchChecks() after the batch has
+// run.
+func (f *fkExistenceBatchChecker) moveToWriteBatch(
+	b *client.Batch, newRow tree.Datums,
+) error {
+	if len(f.batch.Requests) == 0 {
+		return nil
+	}
+	defer f.reset()
+
+	for i := range f.batch.Requests {
+		fk := f.batchIdxToFk[i]
+		if fk.dir != CheckInserts {
+			return pgerror.NewAssertionErrorf("cannot defer FK check with dir=%v", fk.dir)
+		}
+		span := f.batch.Requests[i].GetInner().Header().Span()
+		f.inWriteBatch = append(f.inWriteBatch, fkCheckInWriteBatch{
+			resultIdx: len(b.Results),
+			fk:        fk,
+			fkValues:  fk.searchedValues(newRow),
+		})
+		b.Scan(span.Key, span.EndKey)
+	}
+	return nil
+}
+
+// hasWriteBatchChecks returns true if some checks were moved to a kv batch
+// and have not been verified yet.
+func (f *fkExistenceBatchChecker) hasWriteBatchChecks() bool {
+	return len(f.inWriteBatch) > 0
+}
+
+// runWriteBatchChecks verifies the checks previously transferred to b by
+// moveToWriteBatch(), after b has run successfully. A
+// pgerror.CodeForeignKeyViolationError is returned for the first check that
+// was added to the batch and failed.
+func (f *fkExistenceBatchChecker) runWriteBatchChecks(ctx context.Context, b *client.Batch) error {
+	defer func() { f.inWriteBatch = f.inWriteBatch[:0] }()
+
+	fetcher := SpanKVFetcher{}
+	for i := range f.inWriteBatch {
+		c := &f.inWriteBatch[i]
+		if c.resultIdx >= len(b.Results) {
+			return pgerror.NewAssertionErrorf("FK check result %d missing from batch with %d results",
+				c.resultIdx, len(b.Results))
+		}
+		rows := b.Results[c.resultIdx].Rows
+		f.kvBuf = f.kvBuf[:0]
+		for j := range rows {
+			f.kvBuf = append(f.kvBuf, roachpb.KeyValue{Key: rows[j].Key, Value: *rows[j].Value})
+		}
+		fetcher.KVs = f.kvBuf
+		if err := c.fk.rf.StartScanFrom(ctx, &fetcher); err != nil {
+			return err
+		}
+		if c.fk.rf.kvEnd {
+			return f.insertViolationError(c.fk, c.fkValues)
+		}
+	}
+	return nil
+}
+
 // SpanKVFetcher is an kvBatchFetcher that returns a set slice of kvs.
 type SpanKVFetcher struct {
 	KVs []roachpb.KeyValue
@@ -106,3 +106,17 @@ func (h fkExistenceCheckForInsert) CollectSpansForValues(
 ) (roachpb.Spans, error) {
 	return collectSpansForValuesWithFKMap(h.fks, values)
 }
+
+// canCheckInWriteBatch returns true if none of the existence checks
+// search the table being mutated, so that the lookups can be sent
+// together with the row writes without observing them.
+func (h fkExistenceCheckForInsert) canCheckInWriteBatch(tableID sqlbase.ID) bool {
+	for idx := range h.fks {
+		for i := range h.fks[idx] {
+			if h.fks[idx][i].searchTable.ID == tableID {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -20,6 +20,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -53,6 +54,9 @@ type txnKVFetcher struct {
 	// returnRangeInfo, if set, causes the kvBatchFetcher to populate rangeInfos.
 	// See also rowFetcher.returnRangeInfo.
 	returnRangeInfo bool
+	// waitPolicy specifies the behavior of the scans when they encounter
+	// intents of other transactions.
+	waitPolicy roachpb.WaitPolicy
 
 	fetchEnd bool
 	batchIdx int
@@ -143,6 +147,7 @@ func makeKVBatchFetcher(
 	useBatchLimit bool,
 	firstBatchLimit int64,
 	returnRangeInfo bool,
+	waitPolicy roachpb.WaitPolicy,
 ) (txnKVFetcher, error) {
 	if firstBatchLimit < 0 || (!useBatchLimit && firstBatchLimit != 0) {
 		return txnKVFetcher{}, errors.Errorf("invalid batch limit %d (useBatchLimit: %t)",
@@ -194,6 +199,7 @@ func makeKVBatchFetcher(
 		useBatchLimit:   useBatchLimit,
 		firstBatchLimit: firstBatchLimit,
 		returnRangeInfo: returnRangeInfo,
+		waitPolicy:      waitPolicy,
 	}, nil
 }
 
@@ -202,6 +208,7 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 	var ba roachpb.BatchRequest
 	ba.Header.MaxSpanRequestKeys = f.getBatchSize()
 	ba.Header.ReturnRangeInfo = f.returnRangeInfo
+	ba.Header.WaitPolicy = f.waitPolicy
 	ba.Requests = make([]roachpb.RequestUnion, len(f.spans))
 	if f.reverse {
 		scans := make([]roachpb.ReverseScanRequest, len(f.spans))
@@ -241,6 +248,10 @@ func (f *txnKVFetcher) fetch(ctx context.Context) error {
 
 	br, err := f.txn.Send(ctx, ba)
 	if err != nil {
+		if _, ok := err.GetDetail().(*roachpb.WriteIntentError); ok && f.waitPolicy == roachpb.WaitPolicy_ERROR {
+			return pgerror.NewErrorf(pgerror.CodeLockNotAvailableError,
+				"could not obtain lock on row")
+		}
 		return err.GoError()
 	}
 	if br != nil {
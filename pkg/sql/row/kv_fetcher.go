@@ -31,6 +31,9 @@ type kvFetcher struct {
 	batchResponse []byte
 	span          roachpb.Span
 	newSpan       bool
+
+	// bytesRead is the number of bytes of the KVs fetched so far.
+	bytesRead int64
 }
 
 func newKVFetcher(batchFetcher kvBatchFetcher) kvFetcher {
@@ -76,6 +79,10 @@ func (f *kvFetcher) nextKV(
 		if !ok {
 			return false, kv, false, nil
 		}
+		for i := range f.kvs {
+			f.bytesRead += int64(len(f.kvs[i].Key) + len(f.kvs[i].Value.RawBytes))
+		}
+		f.bytesRead += int64(len(f.batchResponse))
 		f.newSpan = true
 	}
 }
@@ -45,6 +45,9 @@ type Inserter struct {
 	InsertColIDtoRowIndex map[sqlbase.ColumnID]int
 	Fks                   fkExistenceCheckForInsert
 
+	// fkChecksInBatch is set by EnableFKChecksInBatch.
+	fkChecksInBatch bool
+
 	// For allocation avoidance.
 	marshaled []roachpb.Value
 	key       roachpb.Key
@@ -168,7 +171,11 @@ func (ri *Inserter) InsertRow(
 		if err := ri.Fks.addAllIdxChecks(ctx, values, traceKV); err != nil {
 			return err
 		}
-		if err := ri.Fks.checker.runCheck(ctx, nil, values); err != nil {
+		if batch, ok := b.(*client.Batch); ok && ri.fkChecksInBatch {
+			if err := ri.Fks.checker.moveToWriteBatch(batch, values); err != nil {
+				return err
+			}
+		} else if err := ri.Fks.checker.runCheck(ctx, nil, values); err != nil {
 			return err
 		}
 	}
@@ -197,6 +204,39 @@ func (ri *Inserter) InsertRow(
 	return nil
 }
 
+// EnableFKChecksInBatch requests that subsequent calls to InsertRow add
+// the FK existence lookups to the *client.Batch they are given, instead of
+// sending a separate KV request for every row. This saves one round-trip
+// per inserted row, at the cost of reporting FK violations only after the
+// batch has run; the caller must then call CheckFKsInBatch before reusing
+// the batch or committing the transaction.
+//
+// It returns false, and leaves the Inserter unchanged, if some foreign key
+// references the table being inserted into: those lookups must observe the
+// rows written previously by the same statement.
+func (ri *Inserter) EnableFKChecksInBatch() bool {
+	if ri.Fks.checker == nil || !ri.Fks.canCheckInWriteBatch(ri.Helper.TableDesc.ID) {
+		return false
+	}
+	ri.fkChecksInBatch = true
+	return true
+}
+
+// HasFKChecksInBatch returns true if FK existence lookups were added to a
+// batch by InsertRow and have not been verified with CheckFKsInBatch yet.
+func (ri *Inserter) HasFKChecksInBatch() bool {
+	return ri.Fks.checker != nil && ri.Fks.checker.hasWriteBatchChecks()
+}
+
+// CheckFKsInBatch verifies the results of the FK existence lookups added to
+// b by InsertRow, after b has run successfully.
+func (ri *Inserter) CheckFKsInBatch(ctx context.Context, b *client.Batch) error {
+	if !ri.HasFKChecksInBatch() {
+		return nil
+	}
+	return ri.Fks.checker.runWriteBatchChecks(ctx, b)
+}
+
 // prepareInsertOrUpdateBatch constructs a KV batch that inserts or
 // updates a row in KV.
 // - batch is the KV batch where commands should be appended.
@@ -85,9 +85,9 @@ func (r *rowSourceToPlanNode) Next(params runParams) (bool, error) {
 				r.forwarder.forwardMetadata(p)
 				continue
 			}
-			if p.TraceData != nil {
-				// We drop trace metadata since we have no reasonable way to propagate
-				// it in local SQL execution.
+			if p.TraceData != nil || p.Metrics != nil {
+				// We drop trace and metrics metadata since we have no reasonable way
+				// to propagate it in local SQL execution.
 				continue
 			}
 			return false, fmt.Errorf("unexpected producer metadata: %+v", p)
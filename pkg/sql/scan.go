@@ -123,6 +123,15 @@ type scanNode struct {
 
 	// Indicates if this scan is the source for a delete node.
 	isDeleteSource bool
+
+	// lockingStrength and lockingWaitPolicy represent the row-level locking
+	// mode of the scan, as requested by a locking clause like FOR UPDATE.
+	lockingStrength   tree.LockingStrength
+	lockingWaitPolicy tree.LockingWaitPolicy
+
+	// If set, the scan only returns a random sample of the rows, as requested
+	// by a TABLESAMPLE clause.
+	sample *distsqlpb.TableSampleSpec
 }
 
 // scanVisibility represents which table columns should be included in a scan.
@@ -147,6 +156,19 @@ func (s scanVisibility) toDistSQLScanVisibility() distsqlpb.ScanVisibility {
 	}
 }
 
+func toDistSQLScanLockingWaitPolicy(p tree.LockingWaitPolicy) distsqlpb.ScanLockingWaitPolicy {
+	switch p {
+	case tree.LockWaitBlock:
+		return distsqlpb.ScanLockingWaitPolicy_BLOCK
+	case tree.LockWaitSkip:
+		return distsqlpb.ScanLockingWaitPolicy_SKIP_LOCKED
+	case tree.LockWaitError:
+		return distsqlpb.ScanLockingWaitPolicy_ERROR
+	default:
+		panic(fmt.Sprintf("unknown wait policy %s", p))
+	}
+}
+
 // scanColumnsConfig controls the "schema" of a scan node. The zero value is the
 // default: all "public" columns.
 // Note that not all columns in the schema are read and decoded; that is further
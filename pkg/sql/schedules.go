@@ -0,0 +1,258 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/json"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+var schedulesEnabled = settings.RegisterBoolSetting(
+	"sql.schedules.enabled",
+	"if set, each node periodically runs the schedules of system.schedules which are due",
+	true,
+)
+
+var schedulesPollInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.schedules.poll_interval",
+	"the interval at which each node checks system.schedules for schedules which are due",
+	30*time.Second,
+)
+
+const (
+	// scheduleRunsRetained is the number of runs of each schedule kept in
+	// system.schedule_runs. Older runs are deleted after each run.
+	scheduleRunsRetained = 100
+	// scheduleResultRows is the number of rows returned by a scheduled
+	// statement which are kept in system.schedule_runs.
+	scheduleResultRows = 100
+)
+
+// StatementScheduler runs the statements of system.schedules on their
+// recurrence. Every node runs a StatementScheduler, which polls for the
+// schedules which are due. A node claims a run of a schedule by advancing
+// its next_run in a transaction, so each run is performed by a single node;
+// the runs missed while no node was polling, e.g. while the cluster was
+// down, are skipped rather than caught up.
+//
+// A scheduled statement is executed outside of any transaction, as the owner
+// of the schedule and with the database of the schedule as current database.
+// Each run is recorded in system.schedule_runs.
+type StatementScheduler struct {
+	cfg *ExecutorConfig
+}
+
+// NewStatementScheduler creates a StatementScheduler.
+func NewStatementScheduler(cfg *ExecutorConfig) *StatementScheduler {
+	return &StatementScheduler{cfg: cfg}
+}
+
+// Start starts the polling loop of the scheduler.
+func (s *StatementScheduler) Start(ctx context.Context, stopper *stop.Stopper) {
+	ctx = s.cfg.AmbientCtx.AnnotateCtx(ctx)
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		ctx, cancel := stopper.WithCancelOnQuiesce(ctx)
+		defer cancel()
+
+		timer := timeutil.NewTimer()
+		defer timer.Stop()
+		for {
+			timer.Reset(schedulesPollInterval.Get(&s.cfg.Settings.SV))
+			select {
+			case <-timer.C:
+				timer.Read = true
+				if schedulesEnabled.Get(&s.cfg.Settings.SV) {
+					s.runDueSchedules(ctx)
+				}
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// runDueSchedules runs the schedules whose next run is due. Errors are
+// logged.
+func (s *StatementScheduler) runDueSchedules(ctx context.Context) {
+	rows, err := s.cfg.InternalExecutor.Query(
+		ctx, "find-due-schedules", nil, /* txn */
+		`SELECT name FROM system.schedules WHERE next_run <= now() ORDER BY next_run`,
+	)
+	if err != nil {
+		log.Warningf(ctx, "unable to find due schedules: %v", err)
+		return
+	}
+	for _, row := range rows {
+		name := string(tree.MustBeDString(row[0]))
+		if err := s.maybeRunSchedule(ctx, name); err != nil {
+			log.Warningf(ctx, "unable to run schedule %q: %v", name, err)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// claimedSchedule is a schedule whose run was claimed by this node.
+type claimedSchedule struct {
+	name, owner, database, statement string
+}
+
+// maybeRunSchedule claims the due run of the schedule and runs it, unless
+// the schedule was paused, dropped or run by another node in the meantime.
+func (s *StatementScheduler) maybeRunSchedule(ctx context.Context, name string) error {
+	ie := s.cfg.InternalExecutor
+	var claimed *claimedSchedule
+	if err := s.cfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		claimed = nil
+		row, err := ie.QueryRow(
+			ctx, "claim-schedule", txn,
+			`SELECT owner, database_name, statement, recurrence, next_run
+			FROM system.schedules WHERE name = $1`, name,
+		)
+		if err != nil {
+			return err
+		}
+		if row == nil || row[4] == tree.DNull {
+			return nil
+		}
+		now := timeutil.Now()
+		if tree.MustBeDTimestamp(row[4]).Time.After(now) {
+			return nil
+		}
+
+		// The next run is computed from the current time, so that the runs
+		// missed since the due one are skipped. A schedule whose recurrence
+		// does not match anymore is paused.
+		var nextRun interface{}
+		recurrence := string(tree.MustBeDString(row[3]))
+		if c, err := cron.Parse(recurrence); err != nil {
+			log.Warningf(ctx, "pausing schedule %q: invalid recurrence %q: %v", name, recurrence, err)
+		} else if next := c.Next(now); !next.IsZero() {
+			nextRun = next
+		}
+		if nextRun == nil {
+			_, err = ie.Exec(ctx, "claim-schedule", txn,
+				`UPDATE system.schedules SET next_run = NULL WHERE name = $1`, name)
+		} else {
+			_, err = ie.Exec(ctx, "claim-schedule", txn,
+				`UPDATE system.schedules SET next_run = $2 WHERE name = $1`, name, nextRun)
+		}
+		if err != nil {
+			return err
+		}
+		claimed = &claimedSchedule{
+			name:      name,
+			owner:     string(tree.MustBeDString(row[0])),
+			database:  string(tree.MustBeDString(row[1])),
+			statement: string(tree.MustBeDString(row[2])),
+		}
+		return nil
+	}); err != nil || claimed == nil {
+		return err
+	}
+	return s.runSchedule(ctx, claimed)
+}
+
+// runSchedule runs the statement of a claimed schedule and records the run.
+func (s *StatementScheduler) runSchedule(ctx context.Context, sched *claimedSchedule) error {
+	log.VEventf(ctx, 2, "running schedule %q", sched.name)
+	started := timeutil.Now()
+	res, err := s.cfg.InternalExecutor.execInternal(
+		ctx, "scheduled-statement", nil, /* txn */
+		internalExecFixedUserSession,
+		SessionArgs{User: sched.owner, SessionDefaults: map[string]string{"database": sched.database}},
+		sched.statement,
+	)
+	if err == nil {
+		err = res.err
+	}
+	finished := timeutil.Now()
+
+	// The run is recorded with the columns which are not NULL only, as NULL
+	// arguments cannot be typed.
+	cols := []string{"schedule_name", "started", "finished", "node_id"}
+	args := []interface{}{sched.name, started, finished, int64(s.cfg.NodeID.Get())}
+	if err != nil {
+		cols = append(cols, "error")
+		args = append(args, err.Error())
+	} else if len(res.cols) == 0 {
+		cols = append(cols, "row_count")
+		args = append(args, res.rowsAffected)
+	} else {
+		j, jsonErr := scheduleResultToJSON(res)
+		if jsonErr != nil {
+			return jsonErr
+		}
+		cols = append(cols, "row_count", "result")
+		args = append(args, len(res.rows), tree.NewDJSON(j))
+	}
+	placeholders := make([]string, len(args))
+	for i := range placeholders {
+		placeholders[i] = fmt.Sprintf("$%d", i+1)
+	}
+	ie := s.cfg.InternalExecutor
+	if _, err := ie.Exec(
+		ctx, "record-schedule-run", nil, /* txn */
+		fmt.Sprintf(`INSERT INTO system.schedule_runs (%s) VALUES (%s)`,
+			strings.Join(cols, ", "), strings.Join(placeholders, ", ")),
+		args...,
+	); err != nil {
+		return err
+	}
+
+	_, err = ie.Exec(
+		ctx, "prune-schedule-runs", nil, /* txn */
+		`DELETE FROM system.schedule_runs WHERE schedule_name = $1 AND started < (
+			SELECT started FROM system.schedule_runs WHERE schedule_name = $1
+			ORDER BY started DESC LIMIT 1 OFFSET $2
+		)`,
+		sched.name, scheduleRunsRetained-1,
+	)
+	return err
+}
+
+// scheduleResultToJSON converts the first rows returned by a scheduled
+// statement to a JSON array of objects keyed by column name.
+func scheduleResultToJSON(res result) (json.JSON, error) {
+	rows := res.rows
+	if len(rows) > scheduleResultRows {
+		rows = rows[:scheduleResultRows]
+	}
+	b := json.NewArrayBuilder(len(rows))
+	for _, row := range rows {
+		o := json.NewObjectBuilder(len(row))
+		for i, d := range row {
+			j, err := tree.AsJSON(d)
+			if err != nil {
+				return nil, err
+			}
+			o.Add(res.cols[i].Name, j)
+		}
+		b.Add(o.Build())
+	}
+	return b.Build(), nil
+}
@@ -0,0 +1,115 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestStatementScheduler(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	schedulesPollInterval.Override(&st.SV, 10*time.Millisecond)
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{Settings: st, UseDatabase: "test"})
+	defer s.Stopper().Stop(ctx)
+
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE DATABASE test`)
+	sqlDB.Exec(t, `CREATE TABLE kv (k INT PRIMARY KEY, v INT)`)
+	sqlDB.Exec(t, `INSERT INTO kv VALUES (1, 10), (2, 20), (3, 30)`)
+	sqlDB.Exec(t, `CREATE USER testuser`)
+	sqlDB.Exec(t, `GRANT SELECT, DELETE ON kv TO testuser`)
+
+	// runSchedule makes the schedule due and waits for it to run. The
+	// recurrence of the schedules is yearly, so they only run when they are
+	// made due.
+	runSchedule := func(t *testing.T, name string) {
+		t.Helper()
+		var before int
+		sqlDB.QueryRow(t,
+			`SELECT count(*) FROM system.schedule_runs WHERE schedule_name = $1`, name,
+		).Scan(&before)
+		sqlDB.Exec(t,
+			`UPDATE system.schedules SET next_run = now() - '1m'::INTERVAL WHERE name = $1`, name)
+		testutils.SucceedsSoon(t, func() error {
+			var after int
+			sqlDB.QueryRow(t,
+				`SELECT count(*) FROM system.schedule_runs WHERE schedule_name = $1`, name,
+			).Scan(&after)
+			if after == before {
+				return errors.Errorf("schedule %q has not run", name)
+			}
+			return nil
+		})
+		// The next run is scheduled according to the recurrence.
+		var scheduled bool
+		sqlDB.QueryRow(t,
+			`SELECT next_run > now() FROM system.schedules WHERE name = $1`, name,
+		).Scan(&scheduled)
+		if !scheduled {
+			t.Fatalf("schedule %q was not rescheduled", name)
+		}
+	}
+	// checkLastRun checks the node ID, the row count, the result and the error
+	// of the last run of the schedule.
+	checkLastRun := func(t *testing.T, name string, expected []string) {
+		t.Helper()
+		res := sqlDB.QueryStr(t,
+			`SELECT node_id, row_count, result, error FROM system.schedule_runs
+			WHERE schedule_name = $1 ORDER BY started DESC LIMIT 1`, name)
+		if !reflect.DeepEqual(res, [][]string{expected}) {
+			t.Fatalf("expected %v, got %v", [][]string{expected}, res)
+		}
+	}
+
+	t.Run("rows", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE SCHEDULE sel FOR SQL STATEMENT 'SELECT k, v FROM kv ORDER BY k'
+			RECURRING '@yearly' EXECUTE AS testuser`)
+		runSchedule(t, "sel")
+		checkLastRun(t, "sel",
+			[]string{"1", "3", `[{"k": 1, "v": 10}, {"k": 2, "v": 20}, {"k": 3, "v": 30}]`, "NULL"})
+	})
+
+	t.Run("rows affected", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE SCHEDULE del FOR SQL STATEMENT 'DELETE FROM kv WHERE k = 3'
+			RECURRING '@yearly' EXECUTE AS testuser`)
+		runSchedule(t, "del")
+		checkLastRun(t, "del", []string{"1", "1", "NULL", "NULL"})
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM kv`, [][]string{{"2"}})
+	})
+
+	t.Run("error", func(t *testing.T) {
+		sqlDB.Exec(t, `CREATE SCHEDULE ins FOR SQL STATEMENT 'INSERT INTO kv VALUES (4, 40)'
+			RECURRING '@yearly' EXECUTE AS testuser`)
+		runSchedule(t, "ins")
+		sqlDB.CheckQueryResults(t,
+			`SELECT row_count, result, error LIKE '%user testuser does not have INSERT privilege on relation kv'
+			FROM system.schedule_runs WHERE schedule_name = 'ins'`,
+			[][]string{{"NULL", "NULL", "true"}})
+	})
+}
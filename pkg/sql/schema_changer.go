@@ -42,6 +42,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/grpcutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/log/logtags"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/cockroachdb/cockroach/pkg/util/stop"
@@ -1126,8 +1127,14 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 		}
 
 		schemaChangeEventType := EventLogFinishSchemaChange
+		var event eventpb.EventPayload = &eventpb.FinishSchemaChange{
+			MutationID: uint32(sc.mutationID),
+		}
 		if isRollback {
 			schemaChangeEventType = EventLogFinishSchemaRollback
+			event = &eventpb.FinishSchemaChangeRollback{
+				MutationID: uint32(sc.mutationID),
+			}
 		}
 
 		// Log "Finish Schema Change" or "Finish Schema Change Rollback"
@@ -1140,9 +1147,7 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 			schemaChangeEventType,
 			int32(sc.tableID),
 			int32(sc.nodeID),
-			struct {
-				MutationID uint32
-			}{uint32(sc.mutationID)},
+			event,
 		)
 	})
 }
@@ -1298,10 +1303,10 @@ func (sc *SchemaChanger) reverseMutations(ctx context.Context, causingError erro
 			EventLogReverseSchemaChange,
 			int32(sc.tableID),
 			int32(sc.nodeID),
-			struct {
-				Error      string
-				MutationID uint32
-			}{fmt.Sprintf("%+v", causingError), uint32(sc.mutationID)},
+			&eventpb.ReverseSchemaChange{
+				Error:      fmt.Sprintf("%+v", causingError),
+				MutationID: uint32(sc.mutationID),
+			},
 		)
 	})
 	if err != nil {
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"unsafe"
 
 	"github.com/cockroachdb/apd"
@@ -294,6 +295,50 @@ var aggregates = map[string]builtinDefinition{
 	"json_object_agg":  makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 33285, Class: tree.AggregateClass, Impure: true}),
 	"jsonb_object_agg": makeBuiltin(tree.FunctionProperties{UnsupportedWithIssue: 33285, Class: tree.AggregateClass, Impure: true}),
 
+	// The ordered-set aggregates must be called with a WITHIN GROUP clause,
+	// and they are computed by the impl aggregates below. See
+	// tree.FunctionProperties.OrderedSetImpl.
+	"percentile_disc": makeBuiltin(orderedSetAggProps("percentile_disc_impl"),
+		makeAggOverload([]types.T{types.Float}, types.Any, orderedSetAggregateMustNotRun,
+			"Discrete percentile: returns the first input value whose position in the "+
+				"ordering equals or exceeds the specified fraction."),
+		makeAggOverload([]types.T{types.TArray{Typ: types.Float}}, types.AnyArray,
+			orderedSetAggregateMustNotRun,
+			"Discrete percentile: returns an array of the first input values whose "+
+				"positions in the ordering equal or exceed each of the specified fractions."),
+	),
+
+	"percentile_cont": makeBuiltin(orderedSetAggProps("percentile_cont_impl"),
+		makeAggOverload([]types.T{types.Float}, types.Any, orderedSetAggregateMustNotRun,
+			"Continuous percentile: returns a float or interval value corresponding to the "+
+				"specified fraction in the ordering, interpolating between adjacent input "+
+				"values if needed."),
+		makeAggOverload([]types.T{types.TArray{Typ: types.Float}}, types.AnyArray,
+			orderedSetAggregateMustNotRun,
+			"Continuous percentile: returns an array of float or interval values "+
+				"corresponding to each of the specified fractions in the ordering, "+
+				"interpolating between adjacent input values if needed."),
+	),
+
+	"mode": makeBuiltin(orderedSetAggProps("mode_impl"),
+		makeAggOverload([]types.T{}, types.Any, orderedSetAggregateMustNotRun,
+			"Returns the most frequent input value, choosing the first one in the "+
+				"ordering if there are several equally frequent values."),
+	),
+
+	// The input signature of the impl aggregates is: the value of the WITHIN
+	// GROUP ordering, the direct arguments, and whether the ordering is
+	// descending.
+	"percentile_disc_impl": makePrivate(makeBuiltin(aggProps(), percentileDiscImplOverloads()...)),
+
+	"percentile_cont_impl": makePrivate(makeBuiltin(aggProps(), percentileContImplOverloads()...)),
+
+	"mode_impl": makePrivate(collectOverloads(aggProps(), types.AnyNonArray,
+		func(t types.T) tree.Overload {
+			return makeAggOverload([]types.T{t, types.Bool}, t, newModeAggregate,
+				"Implementation of mode.")
+		})),
+
 	AnyNotNull: makePrivate(makeBuiltin(aggProps(),
 		makeAggOverloadWithReturnType(
 			[]types.T{types.Any},
@@ -306,6 +351,58 @@ var aggregates = map[string]builtinDefinition{
 // AnyNotNull is the name of the aggregate returned by NewAnyNotNullAggregate.
 const AnyNotNull = "any_not_null"
 
+// percentileDiscImplOverloads returns the overloads of percentile_disc_impl:
+// one for each type with a single fraction, and one for each type that can be
+// an array element with an array of fractions.
+func percentileDiscImplOverloads() []tree.Overload {
+	var overloads []tree.Overload
+	for _, t := range types.AnyNonArray {
+		overloads = append(overloads, makeAggOverload(
+			[]types.T{t, types.Float, types.Bool}, t, newPercentileDiscAggregate,
+			"Implementation of percentile_disc.",
+		))
+		if ok, _ := types.IsValidArrayElementType(t); ok {
+			overloads = append(overloads, makeAggOverload(
+				[]types.T{t, types.TArray{Typ: types.Float}, types.Bool}, types.TArray{Typ: t},
+				newPercentileDiscAggregate,
+				"Implementation of percentile_disc.",
+			))
+		}
+	}
+	return overloads
+}
+
+// percentileContImplOverloads returns the overloads of percentile_cont_impl,
+// which interpolates float values (to which numeric values are converted)
+// and intervals.
+func percentileContImplOverloads() []tree.Overload {
+	var overloads []tree.Overload
+	for _, t := range []types.T{types.Int, types.Float, types.Decimal, types.Interval} {
+		ret := types.Float
+		if t == types.Interval {
+			ret = types.Interval
+		}
+		overloads = append(overloads,
+			makeAggOverload(
+				[]types.T{t, types.Float, types.Bool}, ret, newPercentileContAggregate,
+				"Implementation of percentile_cont.",
+			),
+			makeAggOverload(
+				[]types.T{t, types.TArray{Typ: types.Float}, types.Bool}, types.TArray{Typ: ret},
+				newPercentileContAggregate,
+				"Implementation of percentile_cont.",
+			),
+		)
+	}
+	return overloads
+}
+
+func orderedSetAggProps(impl string) tree.FunctionProperties {
+	f := aggProps()
+	f.OrderedSetImpl = impl
+	return f
+}
+
 func makePrivate(b builtinDefinition) builtinDefinition {
 	b.props.Private = true
 	return b
@@ -409,6 +506,9 @@ var _ tree.AggregateFunc = &boolOrAggregate{}
 var _ tree.AggregateFunc = &bytesXorAggregate{}
 var _ tree.AggregateFunc = &intXorAggregate{}
 var _ tree.AggregateFunc = &jsonAggregate{}
+var _ tree.AggregateFunc = &percentileDiscAggregate{}
+var _ tree.AggregateFunc = &percentileContAggregate{}
+var _ tree.AggregateFunc = &modeAggregate{}
 
 const sizeOfArrayAggregate = int64(unsafe.Sizeof(arrayAggregate{}))
 const sizeOfAvgAggregate = int64(unsafe.Sizeof(avgAggregate{}))
@@ -437,6 +537,9 @@ const sizeOfBoolOrAggregate = int64(unsafe.Sizeof(boolOrAggregate{}))
 const sizeOfBytesXorAggregate = int64(unsafe.Sizeof(bytesXorAggregate{}))
 const sizeOfIntXorAggregate = int64(unsafe.Sizeof(intXorAggregate{}))
 const sizeOfJSONAggregate = int64(unsafe.Sizeof(jsonAggregate{}))
+const sizeOfPercentileDiscAggregate = int64(unsafe.Sizeof(percentileDiscAggregate{}))
+const sizeOfPercentileContAggregate = int64(unsafe.Sizeof(percentileContAggregate{}))
+const sizeOfModeAggregate = int64(unsafe.Sizeof(modeAggregate{}))
 
 // See NewAnyNotNullAggregate.
 type anyNotNullAggregate struct {
@@ -1083,7 +1186,7 @@ func (a *decimalSumAggregate) Add(ctx context.Context, datum tree.Datum, _ ...tr
 		return nil
 	}
 	t := datum.(*tree.DDecimal)
-	_, err := tree.ExactCtx.Add(&a.sum, &a.sum, &t.Decimal)
+	_, err := tree.DecimalAdd(tree.ExactCtx, &a.sum, &a.sum, &t.Decimal)
 	if err != nil {
 		return err
 	}
@@ -1944,3 +2047,251 @@ func (a *jsonAggregate) Close(ctx context.Context) {
 func (a *jsonAggregate) Size() int64 {
 	return sizeOfJSONAggregate
 }
+
+// orderedSetAggregateMustNotRun is the constructor of the ordered-set
+// aggregates. Their applications are replaced by applications of their impl
+// aggregates during type checking, so it is never called.
+func orderedSetAggregateMustNotRun(
+	_ []types.T, _ *tree.EvalContext, _ tree.Datums,
+) tree.AggregateFunc {
+	panic(pgerror.NewAssertionErrorf("ordered-set aggregate was not replaced by its implementation"))
+}
+
+// orderedSetAggregate accumulates the non-NULL values of the WITHIN GROUP
+// ordering of an ordered-set aggregate. The values are sorted when the result
+// of the aggregate is computed.
+type orderedSetAggregate struct {
+	evalCtx *tree.EvalContext
+	desc    bool
+	values  tree.Datums
+	acc     mon.BoundAccount
+}
+
+// makeOrderedSetAggregate returns an orderedSetAggregate for the constant
+// arguments of an impl aggregate, the last of which is whether the ordering is
+// descending.
+func makeOrderedSetAggregate(
+	evalCtx *tree.EvalContext, arguments tree.Datums,
+) orderedSetAggregate {
+	a := orderedSetAggregate{
+		evalCtx: evalCtx,
+		acc:     evalCtx.Mon.MakeBoundAccount(),
+	}
+	if n := len(arguments); n > 0 && arguments[n-1] != tree.DNull {
+		a.desc = bool(tree.MustBeDBool(arguments[n-1]))
+	}
+	return a
+}
+
+// Add accumulates the passed datum.
+func (a *orderedSetAggregate) Add(ctx context.Context, datum tree.Datum, _ ...tree.Datum) error {
+	if datum == tree.DNull {
+		return nil
+	}
+	if err := a.acc.Grow(ctx, int64(datum.Size())); err != nil {
+		return err
+	}
+	a.values = append(a.values, datum)
+	return nil
+}
+
+// sort sorts the accumulated values in the WITHIN GROUP ordering.
+func (a *orderedSetAggregate) sort() {
+	sort.Slice(a.values, func(i, j int) bool {
+		c := a.values[i].Compare(a.evalCtx, a.values[j])
+		if a.desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+// Close allows the aggregate to release the memory it requested during
+// operation.
+func (a *orderedSetAggregate) Close(ctx context.Context) {
+	a.acc.Close(ctx)
+}
+
+// evalPercentiles evaluates a percentile function for the fraction argument of
+// percentile_disc or percentile_cont, which is either a single fraction or an
+// array of fractions. The percentile of a NULL fraction is NULL.
+func evalPercentiles(
+	fraction tree.Datum, typ types.T, percentile func(float64) (tree.Datum, error),
+) (tree.Datum, error) {
+	eval := func(d tree.Datum) (tree.Datum, error) {
+		if d == tree.DNull {
+			return tree.DNull, nil
+		}
+		f := float64(*d.(*tree.DFloat))
+		// This condition also rejects NaN.
+		if !(f >= 0 && f <= 1) {
+			return nil, pgerror.NewErrorf(pgerror.CodeNumericValueOutOfRangeError,
+				"percentile value %g is not between 0 and 1", f)
+		}
+		return percentile(f)
+	}
+	arr, ok := fraction.(*tree.DArray)
+	if !ok {
+		return eval(fraction)
+	}
+	res := tree.NewDArray(typ)
+	for _, d := range arr.Array {
+		v, err := eval(d)
+		if err != nil {
+			return nil, err
+		}
+		if err := res.Append(v); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+type percentileDiscAggregate struct {
+	orderedSetAggregate
+	typ      types.T
+	fraction tree.Datum
+}
+
+func newPercentileDiscAggregate(
+	params []types.T, evalCtx *tree.EvalContext, arguments tree.Datums,
+) tree.AggregateFunc {
+	a := &percentileDiscAggregate{
+		orderedSetAggregate: makeOrderedSetAggregate(evalCtx, arguments),
+		typ:                 params[0],
+		fraction:            tree.DNull,
+	}
+	if len(arguments) > 0 {
+		a.fraction = arguments[0]
+	}
+	return a
+}
+
+// Result returns, for each fraction, the first value whose position in the
+// ordering equals or exceeds it.
+func (a *percentileDiscAggregate) Result() (tree.Datum, error) {
+	if len(a.values) == 0 {
+		return tree.DNull, nil
+	}
+	a.sort()
+	return evalPercentiles(a.fraction, a.typ, func(f float64) (tree.Datum, error) {
+		idx := int(math.Ceil(f*float64(len(a.values)))) - 1
+		if idx < 0 {
+			idx = 0
+		}
+		return a.values[idx], nil
+	})
+}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *percentileDiscAggregate) Size() int64 {
+	return sizeOfPercentileDiscAggregate
+}
+
+type percentileContAggregate struct {
+	orderedSetAggregate
+	typ      types.T
+	fraction tree.Datum
+}
+
+func newPercentileContAggregate(
+	params []types.T, evalCtx *tree.EvalContext, arguments tree.Datums,
+) tree.AggregateFunc {
+	a := &percentileContAggregate{
+		orderedSetAggregate: makeOrderedSetAggregate(evalCtx, arguments),
+		typ:                 types.Float,
+		fraction:            tree.DNull,
+	}
+	if params[0] == types.Interval {
+		a.typ = types.Interval
+	}
+	if len(arguments) > 0 {
+		a.fraction = arguments[0]
+	}
+	return a
+}
+
+// Add accumulates the passed datum, converting integers and decimals to
+// floats.
+func (a *percentileContAggregate) Add(ctx context.Context, datum tree.Datum, _ ...tree.Datum) error {
+	switch t := datum.(type) {
+	case *tree.DInt:
+		datum = tree.NewDFloat(tree.DFloat(*t))
+	case *tree.DDecimal:
+		f, err := t.Float64()
+		if err != nil {
+			return err
+		}
+		datum = tree.NewDFloat(tree.DFloat(f))
+	}
+	return a.orderedSetAggregate.Add(ctx, datum)
+}
+
+// Result returns, for each fraction, the value corresponding to it in the
+// ordering, interpolating linearly between the two nearest values if needed.
+func (a *percentileContAggregate) Result() (tree.Datum, error) {
+	if len(a.values) == 0 {
+		return tree.DNull, nil
+	}
+	a.sort()
+	return evalPercentiles(a.fraction, a.typ, func(f float64) (tree.Datum, error) {
+		pos := f * float64(len(a.values)-1)
+		lowerIdx, upperIdx := math.Floor(pos), math.Ceil(pos)
+		lower, upper := a.values[int(lowerIdx)], a.values[int(upperIdx)]
+		if lowerIdx == upperIdx {
+			return lower, nil
+		}
+		proportion := pos - lowerIdx
+		switch t := lower.(type) {
+		case *tree.DFloat:
+			u := *upper.(*tree.DFloat)
+			return tree.NewDFloat(*t + tree.DFloat(proportion)*(u-*t)), nil
+		case *tree.DInterval:
+			u := upper.(*tree.DInterval).Duration
+			return &tree.DInterval{Duration: t.Add(u.Sub(t.Duration).MulFloat(proportion))}, nil
+		default:
+			return nil, pgerror.NewAssertionErrorf("unexpected percentile_cont type: %s", t.ResolvedType())
+		}
+	})
+}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *percentileContAggregate) Size() int64 {
+	return sizeOfPercentileContAggregate
+}
+
+type modeAggregate struct {
+	orderedSetAggregate
+}
+
+func newModeAggregate(
+	_ []types.T, evalCtx *tree.EvalContext, arguments tree.Datums,
+) tree.AggregateFunc {
+	return &modeAggregate{orderedSetAggregate: makeOrderedSetAggregate(evalCtx, arguments)}
+}
+
+// Result returns the most frequent value. If there are several, the first one
+// in the ordering is returned.
+func (a *modeAggregate) Result() (tree.Datum, error) {
+	if len(a.values) == 0 {
+		return tree.DNull, nil
+	}
+	a.sort()
+	mode, modeFreq := a.values[0], 0
+	for i := 0; i < len(a.values); {
+		j := i + 1
+		for j < len(a.values) && a.values[j].Compare(a.evalCtx, a.values[i]) == 0 {
+			j++
+		}
+		if j-i > modeFreq {
+			mode, modeFreq = a.values[i], j-i
+		}
+		i = j
+	}
+	return mode, nil
+}
+
+// Size is part of the tree.AggregateFunc interface.
+func (a *modeAggregate) Size() int64 {
+	return sizeOfModeAggregate
+}
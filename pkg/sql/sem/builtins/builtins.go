@@ -16,10 +16,13 @@ package builtins
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/binary"
+	gojson "encoding/json"
 	"fmt"
 	"hash"
 	"hash/crc32"
@@ -37,11 +40,14 @@ import (
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/build"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
@@ -52,6 +58,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/ipaddr"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
@@ -682,7 +690,7 @@ var builtins = map[string]builtinDefinition{
 					return nil, errStringTooLarge
 				}
 
-				return tree.NewDString(strings.Repeat(s, count)), nil
+				return repeatString(evalCtx, s, count)
 			},
 			Info: "Concatenates `input` `repeat_counter` number of times.\n\nFor example, " +
 				"`repeat('dog', 2)` returns `dogdog`.",
@@ -1585,6 +1593,26 @@ CockroachDB supports the following flags:
 		},
 	),
 
+	// grouping is replaced by its value when the SELECT clause it is used in is
+	// planned; see tree.ExpandGroupingSets.
+	"grouping": makeBuiltin(
+		tree.FunctionProperties{
+			NullableArgs: true,
+		},
+		tree.Overload{
+			Types:      tree.VariadicType{VarType: types.Any},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(_ *tree.EvalContext, _ tree.Datums) (tree.Datum, error) {
+				return nil, pgerror.NewErrorf(pgerror.CodeGroupingError,
+					"GROUPING() must be used in the SELECT list, HAVING or ORDER BY clause of "+
+						"a query with GROUP BY")
+			},
+			Info: "Returns a bit mask indicating which of the grouping expressions " +
+				"given as arguments are not part of the grouping set of the current " +
+				"group. The first argument corresponds to the most significant bit.",
+		},
+	),
+
 	// Timestamp/Date functions.
 
 	"experimental_strftime": makeBuiltin(
@@ -2782,6 +2810,46 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	"gateway_region": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlacklist: true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				region, ok := ctx.Locality.Find("region")
+				if !ok {
+					return nil, pgerror.NewError(pgerror.CodeObjectNotInPrerequisiteStateError,
+						"no region set on the locality flag on this node")
+				}
+				return tree.NewDString(region), nil
+			},
+			Info: "Returns the region of the node the current session is connected to.",
+		},
+	),
+
+	"crdb_internal.locality_value": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			DistsqlBlacklist: true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"key", types.String}},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				value, ok := ctx.Locality.Find(string(tree.MustBeDString(args[0])))
+				if !ok {
+					return tree.DNull, nil
+				}
+				return tree.NewDString(value), nil
+			},
+			Info: "Returns the value of the specified locality key of the node the current " +
+				"session is connected to, or NULL if the locality of the node has no such key.",
+		},
+	),
+
 	"crdb_internal.force_error": makeBuiltin(
 		tree.FunctionProperties{
 			Category: categorySystemInfo,
@@ -2886,6 +2954,227 @@ may increase either contention or retry errors, or both.`,
 		},
 	),
 
+	"crdb_internal.fingerprint": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+			Impure:   true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"start_key", types.Bytes},
+				{"end_key", types.Bytes},
+			},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				start := roachpb.Key(tree.MustBeDBytes(args[0]))
+				end := roachpb.Key(tree.MustBeDBytes(args[1]))
+				return fingerprintSpan(ctx, start, end, 0 /* stripPrefixLen */)
+			},
+			Info: "Computes a fingerprint of the live key/value pairs in the span [start_key, end_key), " +
+				"as of the transaction timestamp. The fingerprint ignores MVCC timestamps and value " +
+				"checksums, so it can be used with AS OF SYSTEM TIME to compare data between clusters.",
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"table_id", types.Int}},
+			ReturnType: tree.FixedReturnType(types.Int),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				tableID := int64(tree.MustBeDInt(args[0]))
+				if tableID < 0 {
+					return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+						"invalid table ID: %d", tableID)
+				}
+				prefix := roachpb.Key(keys.MakeTablePrefix(uint32(tableID)))
+				return fingerprintSpan(ctx, prefix, prefix.PrefixEnd(), len(prefix))
+			},
+			Info: "Computes a fingerprint of the data in all the indexes of the table with the given ID, " +
+				"as of the transaction timestamp. The table ID is not part of the fingerprint, so the " +
+				"fingerprints of a table and of its restored copy can be compared.",
+		},
+	),
+
+	"crdb_internal.pin_plan": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+			Impure:   true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"statement", types.String},
+				{"gist", types.String},
+			},
+			ReturnType: tree.FixedReturnType(types.String),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				fingerprint, err := pinnedPlanFingerprint(string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				gist, err := opt.ParsePlanGist(string(tree.MustBeDString(args[1])))
+				if err != nil {
+					return nil, err
+				}
+				if _, err := ctx.InternalExecutor.Query(
+					ctx.Ctx(), "pin-plan", ctx.Txn,
+					`UPSERT INTO system.pinned_plans (fingerprint, gist, created) VALUES ($1, $2, now())`,
+					fingerprint, gist.String(),
+				); err != nil {
+					return nil, err
+				}
+				return tree.NewDString(fingerprint), nil
+			},
+			Info: "Pins the plan with the given gist, as shown by EXPLAIN (GIST), for the statements " +
+				"with the same fingerprint as the given statement, and returns the fingerprint. These " +
+				"statements are planned with the pinned plan whenever it remains possible, e.g. as long " +
+				"as the indexes it uses exist. Pinned plans take up to " +
+				"sql.plan_pinning.refresh_interval to take effect.",
+		},
+	),
+
+	"crdb_internal.unpin_plan": makeBuiltin(
+		tree.FunctionProperties{
+			Category: categorySystemInfo,
+			Impure:   true,
+		},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"statement", types.String}},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				if err := checkPrivilegedUser(ctx); err != nil {
+					return nil, err
+				}
+				fingerprint, err := pinnedPlanFingerprint(string(tree.MustBeDString(args[0])))
+				if err != nil {
+					return nil, err
+				}
+				rows, err := ctx.InternalExecutor.Query(
+					ctx.Ctx(), "unpin-plan", ctx.Txn,
+					`DELETE FROM system.pinned_plans WHERE fingerprint = $1 RETURNING fingerprint`,
+					fingerprint,
+				)
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDBool(len(rows) > 0), nil
+			},
+			Info: "Removes the plan pinned for the statements with the same fingerprint as the given " +
+				"statement, and returns whether there was such a plan.",
+		},
+	),
+
+	// The crdb_internal.unsafe_* builtins write the system.descriptor and
+	// system.namespace tables directly, to repair clusters whose descriptors
+	// or namespace entries were corrupted. Unless force is true, they refuse
+	// changes which would make the schema inconsistent, as described in
+	// their Info. Every change is recorded in the event log.
+	"crdb_internal.unsafe_upsert_descriptor": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			Impure:           true,
+			DistsqlBlacklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"id", types.Int},
+				{"descriptor", types.Bytes},
+				{"force", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return unsafeUpsertDescriptor(ctx,
+					tree.MustBeDInt(args[0]),
+					[]byte(tree.MustBeDBytes(args[1])),
+					bool(tree.MustBeDBool(args[2])))
+			},
+			Info: "Writes the encoded descriptor under the given ID, and returns true. Unless force " +
+				"is true, the descriptor must have the given ID and pass validation. Other nodes only " +
+				"use a new table descriptor if its version is greater than the one they have leased.",
+		},
+	),
+
+	"crdb_internal.unsafe_delete_descriptor": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			Impure:           true,
+			DistsqlBlacklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"id", types.Int},
+				{"force", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return unsafeDeleteDescriptor(ctx,
+					tree.MustBeDInt(args[0]),
+					bool(tree.MustBeDBool(args[1])))
+			},
+			Info: "Deletes the descriptor with the given ID, and returns whether it existed. Unless " +
+				"force is true, the descriptor must exist and its name must not resolve to it.",
+		},
+	),
+
+	"crdb_internal.unsafe_upsert_namespace_entry": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			Impure:           true,
+			DistsqlBlacklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"parent_id", types.Int},
+				{"name", types.String},
+				{"desc_id", types.Int},
+				{"force", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return unsafeUpsertNamespaceEntry(ctx,
+					tree.MustBeDInt(args[0]),
+					string(tree.MustBeDString(args[1])),
+					tree.MustBeDInt(args[2]),
+					bool(tree.MustBeDBool(args[3])))
+			},
+			Info: "Makes the name under the given parent ID resolve to the descriptor with the given " +
+				"ID, and returns true. Unless force is true, the name must not resolve to another " +
+				"descriptor, and the descriptor must exist and have this name and parent.",
+		},
+	),
+
+	"crdb_internal.unsafe_delete_namespace_entry": makeBuiltin(
+		tree.FunctionProperties{
+			Category:         categorySystemInfo,
+			Impure:           true,
+			DistsqlBlacklist: true,
+		},
+		tree.Overload{
+			Types: tree.ArgTypes{
+				{"parent_id", types.Int},
+				{"name", types.String},
+				{"desc_id", types.Int},
+				{"force", types.Bool},
+			},
+			ReturnType: tree.FixedReturnType(types.Bool),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				return unsafeDeleteNamespaceEntry(ctx,
+					tree.MustBeDInt(args[0]),
+					string(tree.MustBeDString(args[1])),
+					tree.MustBeDInt(args[2]),
+					bool(tree.MustBeDBool(args[3])))
+			},
+			Info: "Deletes the name under the given parent ID, and returns whether it existed. Unless " +
+				"force is true, the name must resolve to the descriptor with the given ID, and this " +
+				"descriptor must not exist or be a dropped table.",
+		},
+	),
+
 	// Fetches the corresponding lease_holder for the request key.
 	"crdb_internal.lease_holder": makeBuiltin(
 		tree.FunctionProperties{
@@ -3478,8 +3767,8 @@ var jsonBuildObjectImpl = tree.Overload{
 var toJSONImpl = tree.Overload{
 	Types:      tree.ArgTypes{{"val", types.Any}},
 	ReturnType: tree.FixedReturnType(types.JSON),
-	Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
-		return toJSONObject(args[0])
+	Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+		return toJSONObject(evalCtx, args[0])
 	},
 	Info: "Returns the value as JSON or JSONB.",
 }
@@ -3496,12 +3785,12 @@ var arrayToJSONImpls = makeBuiltin(jsonProps(),
 	tree.Overload{
 		Types:      tree.ArgTypes{{"array", types.AnyArray}, {"pretty_bool", types.Bool}},
 		ReturnType: tree.FixedReturnType(types.JSON),
-		Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+		Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
 			prettyPrint := bool(tree.MustBeDBool(args[1]))
 			if prettyPrint {
 				return nil, prettyPrintNotSupportedError
 			}
-			return toJSONObject(args[0])
+			return toJSONObject(evalCtx, args[0])
 		},
 		Info: "Returns the array as JSON or JSONB.",
 	},
@@ -3528,13 +3817,17 @@ var jsonObjectImpls = makeBuiltin(jsonProps(),
 	tree.Overload{
 		Types:      tree.ArgTypes{{"texts", types.TArray{Typ: types.String}}},
 		ReturnType: tree.FixedReturnType(types.JSON),
-		Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+		Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
 			arr := tree.MustBeDArray(args[0])
 			if arr.Len()%2 != 0 {
 				return nil, errJSONObjectNotEvenNumberOfElements
 			}
 			builder := json.NewObjectBuilder(arr.Len() / 2)
+			cancelChecker := newCancelChecker(evalCtx)
 			for i := 0; i < arr.Len(); i += 2 {
+				if err := cancelChecker.Check(); err != nil {
+					return nil, err
+				}
 				if arr.Array[i] == tree.DNull {
 					return nil, errJSONObjectNullValueForKey
 				}
@@ -3558,14 +3851,18 @@ var jsonObjectImpls = makeBuiltin(jsonProps(),
 		Types: tree.ArgTypes{{"keys", types.TArray{Typ: types.String}},
 			{"values", types.TArray{Typ: types.String}}},
 		ReturnType: tree.FixedReturnType(types.JSON),
-		Fn: func(_ *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+		Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
 			keys := tree.MustBeDArray(args[0])
 			values := tree.MustBeDArray(args[1])
 			if keys.Len() != values.Len() {
 				return nil, errJSONObjectMismatchedArrayDim
 			}
 			builder := json.NewObjectBuilder(keys.Len())
+			cancelChecker := newCancelChecker(evalCtx)
 			for i := 0; i < keys.Len(); i++ {
+				if err := cancelChecker.Check(); err != nil {
+					return nil, err
+				}
 				if keys.Array[i] == tree.DNull {
 					return nil, errJSONObjectNullValueForKey
 				}
@@ -3941,7 +4238,12 @@ func regexpReplace(ctx *tree.EvalContext, s, pattern, to, sqlFlags string) (tree
 	// the start and end index in s of the matched pattern. Subsequent pairs ([n][2] & [n][3],
 	// and so on) represent the start and end index in s of matched subexpressions within the
 	// pattern.
+	cancelChecker := newCancelChecker(ctx)
 	for _, matchIndex := range patternRe.FindAllStringSubmatchIndex(s, matchCount) {
+		if err := cancelChecker.Check(); err != nil {
+			return nil, err
+		}
+
 		// matchStart and matchEnd are the boundaries of the current regexp match
 		// in the searched text.
 		matchStart := matchIndex[0]
@@ -3994,6 +4296,46 @@ func regexpReplace(ctx *tree.EvalContext, s, pattern, to, sqlFlags string) (tree
 	return tree.NewDString(newString.String()), nil
 }
 
+// newCancelChecker returns a checker for the cancellation of the query which
+// evaluates a builtin. The builtins whose running time grows with the size of
+// their input check it as they progress, so that a canceled query does not
+// have to wait for them to return.
+func newCancelChecker(evalCtx *tree.EvalContext) *sqlbase.CancelChecker {
+	var ctx context.Context
+	if evalCtx != nil {
+		ctx = evalCtx.Ctx()
+	}
+	if ctx == nil {
+		// Some evaluations, like those of default expressions during imports,
+		// have no context and cannot be canceled.
+		ctx = context.Background()
+	}
+	return sqlbase.NewCancelChecker(ctx)
+}
+
+// repeatChunkSize is the approximate size of the chunks in which repeat builds
+// its result, checking for cancellation before each one.
+const repeatChunkSize = 4 * 1024
+
+func repeatString(evalCtx *tree.EvalContext, s string, count int) (tree.Datum, error) {
+	if len(s) == 0 || len(s)*count <= repeatChunkSize {
+		return tree.NewDString(strings.Repeat(s, count)), nil
+	}
+	perChunk := repeatChunkSize/len(s) + 1
+	chunk := strings.Repeat(s, perChunk)
+	var buf bytes.Buffer
+	buf.Grow(len(s) * count)
+	cancelChecker := newCancelChecker(evalCtx)
+	for ; count >= perChunk; count -= perChunk {
+		if err := cancelChecker.Check(); err != nil {
+			return nil, err
+		}
+		buf.WriteString(chunk)
+	}
+	buf.WriteString(chunk[:len(s)*count])
+	return tree.NewDString(buf.String()), nil
+}
+
 var flagToByte = map[syntax.Flags]byte{
 	syntax.FoldCase: 'i',
 	syntax.DotNL:    's',
@@ -4490,12 +4832,30 @@ func asJSONObjectKey(d tree.Datum) (string, error) {
 	}
 }
 
-func toJSONObject(d tree.Datum) (tree.Datum, error) {
-	j, err := tree.AsJSON(d)
-	if err != nil {
-		return nil, err
+func toJSONObject(evalCtx *tree.EvalContext, d tree.Datum) (tree.Datum, error) {
+	arr, ok := d.(*tree.DArray)
+	if !ok {
+		j, err := tree.AsJSON(d)
+		if err != nil {
+			return nil, err
+		}
+		return tree.NewDJSON(j), nil
 	}
-	return tree.NewDJSON(j), nil
+	// Arrays are converted element by element, like tree.AsJSON does, so that
+	// the conversion of a large array can be canceled.
+	builder := json.NewArrayBuilder(arr.Len())
+	cancelChecker := newCancelChecker(evalCtx)
+	for _, e := range arr.Array {
+		if err := cancelChecker.Check(); err != nil {
+			return nil, err
+		}
+		j, err := tree.AsJSON(e)
+		if err != nil {
+			return nil, err
+		}
+		builder.Add(j)
+	}
+	return tree.NewDJSON(builder.Build()), nil
 }
 
 // padMaybeTruncate truncates the input string to length if the string is
@@ -4585,6 +4945,386 @@ var errInsufficientPriv = pgerror.NewError(
 	pgerror.CodeInsufficientPrivilegeError, "insufficient privilege",
 )
 
+// fingerprintScanBatchSize is the maximum number of keys read per KV
+// request by fingerprintSpan.
+const fingerprintScanBatchSize = 10000
+
+// fingerprintSpan computes an order-independent hash of the key/value pairs
+// visible to the current transaction in [start, end). Every key/value pair is
+// hashed on its own, without the first stripPrefixLen bytes of the key and
+// without the value checksum, and the hashes are combined with XOR. Since
+// the reads are performed at the transaction timestamp, only the latest
+// version of each key as of that timestamp is included, regardless of the
+// MVCC history.
+func fingerprintSpan(
+	ctx *tree.EvalContext, start, end roachpb.Key, stripPrefixLen int,
+) (tree.Datum, error) {
+	if ctx.Txn == nil {
+		return nil, pgerror.NewAssertionErrorf("fingerprint requires a transaction")
+	}
+	var fingerprint uint64
+	h := fnv.New64a()
+	var lenBuf [binary.MaxVarintLen64]byte
+	for start.Compare(end) < 0 {
+		kvs, err := ctx.Txn.Scan(ctx.Ctx(), start, end, fingerprintScanBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for i := range kvs {
+			key := kvs[i].Key
+			if len(key) < stripPrefixLen {
+				return nil, pgerror.NewAssertionErrorf("key %s shorter than %d bytes", key, stripPrefixLen)
+			}
+			key = key[stripPrefixLen:]
+			h.Reset()
+			// Prefix the key with its length so that the boundary between key
+			// and value does not matter.
+			n := binary.PutUvarint(lenBuf[:], uint64(len(key)))
+			_, _ = h.Write(lenBuf[:n])
+			_, _ = h.Write(key)
+			_, _ = h.Write(kvs[i].Value.TagAndDataBytes())
+			fingerprint ^= h.Sum64()
+		}
+		if len(kvs) < fingerprintScanBatchSize {
+			break
+		}
+		start = kvs[len(kvs)-1].Key.Next()
+	}
+	return tree.NewDInt(tree.DInt(int64(fingerprint))), nil
+}
+
+// pinnedPlanFingerprint returns the fingerprint of the given statement, which
+// can also be given as a fingerprint, under which its plans are pinned.
+func pinnedPlanFingerprint(sql string) (string, error) {
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		return "", err
+	}
+	return parser.Fingerprint(stmt.AST), nil
+}
+
+// The event types recorded by the crdb_internal.unsafe_* builtins. They
+// match the sql.EventLogUnsafe* event types, whose payload is an
+// eventpb.UnsafeRepair.
+const (
+	eventLogUnsafeUpsertDescriptor     = "unsafe_upsert_descriptor"
+	eventLogUnsafeDeleteDescriptor     = "unsafe_delete_descriptor"
+	eventLogUnsafeUpsertNamespaceEntry = "unsafe_upsert_namespace_entry"
+	eventLogUnsafeDeleteNamespaceEntry = "unsafe_delete_namespace_entry"
+)
+
+// makeRepairID checks that d is a valid descriptor ID.
+func makeRepairID(d tree.DInt, name string) (sqlbase.ID, error) {
+	if d < 0 || d > math.MaxUint32 {
+		return 0, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"invalid %s: %d", name, d)
+	}
+	return sqlbase.ID(d), nil
+}
+
+// startUnsafeRepair checks that the crdb_internal.unsafe_* builtins can be
+// run, and makes the transaction refresh the schema of all the nodes when
+// it commits.
+func startUnsafeRepair(ctx *tree.EvalContext) error {
+	if err := checkPrivilegedUser(ctx); err != nil {
+		return err
+	}
+	if ctx.Txn == nil {
+		return pgerror.NewAssertionErrorf("descriptor repair requires a transaction")
+	}
+	if err := ctx.Txn.SetSystemConfigTrigger(); err != nil {
+		return pgerror.UnimplementedWithIssueErrorf(26508,
+			"descriptor repair cannot follow a statement that has written in the same transaction: %v", err)
+	}
+	return nil
+}
+
+// unsafeRepairCheckf returns the error of a failed check of the
+// crdb_internal.unsafe_* builtins, or nil if the checks are skipped with
+// force.
+func unsafeRepairCheckf(force bool, format string, args ...interface{}) error {
+	if force {
+		return nil
+	}
+	return pgerror.NewErrorf(pgerror.CodeObjectNotInPrerequisiteStateError, format, args...).
+		SetHintf("pass true as the force argument to skip this check")
+}
+
+// getRepairDescriptor reads the descriptor with the given ID. The returned
+// encoded descriptor is nil if there is no descriptor with this ID; the
+// decoded descriptor is also nil if it cannot be decoded.
+func getRepairDescriptor(
+	ctx *tree.EvalContext, id sqlbase.ID,
+) (*sqlbase.Descriptor, []byte, error) {
+	kv, err := ctx.Txn.Get(ctx.Ctx(), sqlbase.MakeDescMetadataKey(id))
+	if err != nil || kv.Value == nil {
+		return nil, nil, err
+	}
+	encoded, err := kv.Value.GetBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	var desc sqlbase.Descriptor
+	if err := protoutil.Unmarshal(encoded, &desc); err != nil {
+		return nil, encoded, nil
+	}
+	return &desc, encoded, nil
+}
+
+// getRepairNamespaceEntry returns the ID the name under the given parent
+// resolves to, if any.
+func getRepairNamespaceEntry(
+	ctx *tree.EvalContext, parentID sqlbase.ID, name string,
+) (_ sqlbase.ID, exists bool, _ error) {
+	kv, err := ctx.Txn.Get(ctx.Ctx(), sqlbase.MakeNameMetadataKey(parentID, name))
+	if err != nil || kv.Value == nil {
+		return 0, false, err
+	}
+	return sqlbase.ID(kv.ValueInt()), true, nil
+}
+
+// descriptorNamespaceKey returns the parent ID and the name under which the
+// descriptor is expected to be found in system.namespace.
+func descriptorNamespaceKey(desc *sqlbase.Descriptor) (sqlbase.ID, string) {
+	if table := desc.GetTable(); table != nil {
+		return table.ParentID, table.Name
+	}
+	return keys.RootNamespaceID, desc.GetName()
+}
+
+// recordUnsafeRepair records a change made by a crdb_internal.unsafe_*
+// builtin in the event log, as part of the transaction making the change.
+func recordUnsafeRepair(
+	ctx *tree.EvalContext, eventType string, detail *eventpb.UnsafeRepair,
+) error {
+	detail.User = ctx.SessionData.User
+	info, err := gojson.Marshal(detail)
+	if err != nil {
+		return err
+	}
+	ctx.Txn.AddCommitTrigger(func(c context.Context) {
+		log.Infof(c, "Event: %q, target: %d, info: %s", eventType, detail.ID, info)
+	})
+	_, err = ctx.InternalExecutor.Query(
+		ctx.Ctx(), "log-event", ctx.Txn,
+		`INSERT INTO system.eventlog (timestamp, "eventType", "targetID", "reportingID", info)
+VALUES (now(), $1, $2, $3, $4)`,
+		eventType, int32(detail.ID), int32(ctx.NodeID), string(info),
+	)
+	return err
+}
+
+func unsafeUpsertDescriptor(
+	ctx *tree.EvalContext, idDatum tree.DInt, encoded []byte, force bool,
+) (tree.Datum, error) {
+	id, err := makeRepairID(idDatum, "descriptor ID")
+	if err != nil {
+		return nil, err
+	}
+	if err := startUnsafeRepair(ctx); err != nil {
+		return nil, err
+	}
+
+	var desc sqlbase.Descriptor
+	if err := protoutil.Unmarshal(encoded, &desc); err != nil {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"invalid descriptor: %v", err)
+	}
+	if descID := desc.GetID(); descID != id {
+		if err := unsafeRepairCheckf(force,
+			"descriptor has ID %d instead of %d", descID, id); err != nil {
+			return nil, err
+		}
+	}
+	var validationErr error
+	switch {
+	case desc.GetTable() != nil:
+		validationErr = desc.GetTable().ValidateTable(ctx.Settings)
+	case desc.GetDatabase() != nil:
+		validationErr = desc.GetDatabase().Validate()
+	default:
+		validationErr = errors.New("empty descriptor")
+	}
+	if validationErr != nil {
+		if err := unsafeRepairCheckf(force, "invalid descriptor: %v", validationErr); err != nil {
+			return nil, err
+		}
+	}
+
+	_, prev, err := getRepairDescriptor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Txn.Put(ctx.Ctx(), sqlbase.MakeDescMetadataKey(id), encoded); err != nil {
+		return nil, err
+	}
+	if err := recordUnsafeRepair(ctx, eventLogUnsafeUpsertDescriptor, &eventpb.UnsafeRepair{
+		ID: uint32(id), Force: force, PreviousDescriptor: prev,
+	}); err != nil {
+		return nil, err
+	}
+	return tree.DBoolTrue, nil
+}
+
+func unsafeDeleteDescriptor(
+	ctx *tree.EvalContext, idDatum tree.DInt, force bool,
+) (tree.Datum, error) {
+	id, err := makeRepairID(idDatum, "descriptor ID")
+	if err != nil {
+		return nil, err
+	}
+	if err := startUnsafeRepair(ctx); err != nil {
+		return nil, err
+	}
+
+	desc, prev, err := getRepairDescriptor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if prev == nil {
+		if err := unsafeRepairCheckf(force, "descriptor %d does not exist", id); err != nil {
+			return nil, err
+		}
+		return tree.DBoolFalse, nil
+	}
+	if desc != nil {
+		parentID, name := descriptorNamespaceKey(desc)
+		nameID, exists, err := getRepairNamespaceEntry(ctx, parentID, name)
+		if err != nil {
+			return nil, err
+		}
+		if exists && nameID == id {
+			if err := unsafeRepairCheckf(force,
+				"name %q under parent %d still resolves to descriptor %d", name, parentID, id,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := ctx.Txn.Del(ctx.Ctx(), sqlbase.MakeDescMetadataKey(id)); err != nil {
+		return nil, err
+	}
+	if err := recordUnsafeRepair(ctx, eventLogUnsafeDeleteDescriptor, &eventpb.UnsafeRepair{
+		ID: uint32(id), Force: force, PreviousDescriptor: prev,
+	}); err != nil {
+		return nil, err
+	}
+	return tree.DBoolTrue, nil
+}
+
+func unsafeUpsertNamespaceEntry(
+	ctx *tree.EvalContext, parentIDDatum tree.DInt, name string, idDatum tree.DInt, force bool,
+) (tree.Datum, error) {
+	parentID, err := makeRepairID(parentIDDatum, "parent ID")
+	if err != nil {
+		return nil, err
+	}
+	id, err := makeRepairID(idDatum, "descriptor ID")
+	if err != nil {
+		return nil, err
+	}
+	if err := startUnsafeRepair(ctx); err != nil {
+		return nil, err
+	}
+
+	prevID, exists, err := getRepairNamespaceEntry(ctx, parentID, name)
+	if err != nil {
+		return nil, err
+	}
+	if exists && prevID != id {
+		if err := unsafeRepairCheckf(force,
+			"name %q under parent %d already resolves to descriptor %d", name, parentID, prevID,
+		); err != nil {
+			return nil, err
+		}
+	}
+	desc, encoded, err := getRepairDescriptor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	var checkErr error
+	if encoded == nil {
+		checkErr = unsafeRepairCheckf(force, "descriptor %d does not exist", id)
+	} else if desc == nil {
+		checkErr = unsafeRepairCheckf(force, "descriptor %d cannot be decoded", id)
+	} else if descParentID, descName := descriptorNamespaceKey(desc); descParentID != parentID || descName != name {
+		checkErr = unsafeRepairCheckf(force,
+			"descriptor %d has name %q under parent %d", id, descName, descParentID)
+	}
+	if checkErr != nil {
+		return nil, checkErr
+	}
+
+	if err := ctx.Txn.Put(
+		ctx.Ctx(), sqlbase.MakeNameMetadataKey(parentID, name), int64(id),
+	); err != nil {
+		return nil, err
+	}
+	if err := recordUnsafeRepair(ctx, eventLogUnsafeUpsertNamespaceEntry, &eventpb.UnsafeRepair{
+		ParentID: uint32(parentID), Name: name, ID: uint32(id), Force: force,
+		PreviousID: uint32(prevID),
+	}); err != nil {
+		return nil, err
+	}
+	return tree.DBoolTrue, nil
+}
+
+func unsafeDeleteNamespaceEntry(
+	ctx *tree.EvalContext, parentIDDatum tree.DInt, name string, idDatum tree.DInt, force bool,
+) (tree.Datum, error) {
+	parentID, err := makeRepairID(parentIDDatum, "parent ID")
+	if err != nil {
+		return nil, err
+	}
+	id, err := makeRepairID(idDatum, "descriptor ID")
+	if err != nil {
+		return nil, err
+	}
+	if err := startUnsafeRepair(ctx); err != nil {
+		return nil, err
+	}
+
+	prevID, exists, err := getRepairNamespaceEntry(ctx, parentID, name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if err := unsafeRepairCheckf(force,
+			"name %q under parent %d does not exist", name, parentID); err != nil {
+			return nil, err
+		}
+		return tree.DBoolFalse, nil
+	}
+	if prevID != id {
+		if err := unsafeRepairCheckf(force,
+			"name %q under parent %d resolves to descriptor %d instead of %d", name, parentID, prevID, id,
+		); err != nil {
+			return nil, err
+		}
+	}
+	desc, encoded, err := getRepairDescriptor(ctx, prevID)
+	if err != nil {
+		return nil, err
+	}
+	if encoded != nil && (desc == nil || desc.GetTable() == nil || !desc.GetTable().Dropped()) {
+		if err := unsafeRepairCheckf(force, "descriptor %d still exists", prevID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ctx.Txn.Del(ctx.Ctx(), sqlbase.MakeNameMetadataKey(parentID, name)); err != nil {
+		return nil, err
+	}
+	if err := recordUnsafeRepair(ctx, eventLogUnsafeDeleteNamespaceEntry, &eventpb.UnsafeRepair{
+		ParentID: uint32(parentID), Name: name, ID: uint32(id), Force: force,
+		PreviousID: uint32(prevID),
+	}); err != nil {
+		return nil, err
+	}
+	return tree.DBoolTrue, nil
+}
+
 func checkPrivilegedUser(ctx *tree.EvalContext) error {
 	if ctx.SessionData.User != security.RootUser {
 		return errInsufficientPriv
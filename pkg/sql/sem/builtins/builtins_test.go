@@ -16,13 +16,16 @@ package builtins
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/rand"
+	"strings"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
 func TestCategory(t *testing.T) {
@@ -238,3 +241,130 @@ func TestLPadRPad(t *testing.T) {
 		}
 	}
 }
+
+func TestSeriesGeneratorBounds(t *testing.T) {
+	const maxInt = 1<<63 - 1
+	testCases := []struct {
+		start, stop, step int64
+		bounds            tree.ValueGeneratorBounds
+		expected          []int64
+	}{
+		{1, 10, 1, tree.ValueGeneratorBounds{}, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{1, 10, 1, tree.ValueGeneratorBounds{Hi: tree.NewDInt(3)}, []int64{1, 2, 3}},
+		{1, 10, 3, tree.ValueGeneratorBounds{Lo: tree.NewDInt(5)}, []int64{7, 10}},
+		{1, 10, 3, tree.ValueGeneratorBounds{Lo: tree.NewDInt(4), Hi: tree.NewDInt(8)}, []int64{4, 7}},
+		{10, 1, -4, tree.ValueGeneratorBounds{Hi: tree.NewDInt(7)}, []int64{6, 2}},
+		{10, 1, -1, tree.ValueGeneratorBounds{Lo: tree.NewDInt(8)}, []int64{10, 9, 8}},
+		{1, 10, 1, tree.ValueGeneratorBounds{Lo: tree.NewDInt(20)}, nil},
+		{1, 1000000, 1, tree.ValueGeneratorBounds{Lo: tree.NewDInt(5), Limit: 2}, []int64{5, 6}},
+		{maxInt - 7, maxInt, 5, tree.ValueGeneratorBounds{Lo: tree.NewDInt(maxInt - 1)}, nil},
+		{-maxInt, maxInt, maxInt, tree.ValueGeneratorBounds{Lo: tree.NewDInt(1)}, []int64{maxInt}},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%d,%d,%d,%+v", tc.start, tc.stop, tc.step, tc.bounds), func(t *testing.T) {
+			gen, err := makeSeriesGenerator(nil, tree.Datums{
+				tree.NewDInt(tree.DInt(tc.start)), tree.NewDInt(tree.DInt(tc.stop)), tree.NewDInt(tree.DInt(tc.step)),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			gen.(tree.BoundedValueGenerator).SetBounds(tc.bounds)
+			// The bounds must apply to every restart of the generator.
+			for i := 0; i < 2; i++ {
+				if err := gen.Start(); err != nil {
+					t.Fatal(err)
+				}
+				var res []int64
+				for {
+					ok, err := gen.Next()
+					if err != nil {
+						t.Fatal(err)
+					}
+					if !ok {
+						break
+					}
+					res = append(res, int64(tree.MustBeDInt(gen.Values()[0])))
+				}
+				if fmt.Sprint(res) != fmt.Sprint(tc.expected) {
+					t.Fatalf("expected %v, found %v", tc.expected, res)
+				}
+			}
+			gen.Close()
+		})
+	}
+}
+
+func TestRepeat(t *testing.T) {
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(context.Background())
+	for _, tc := range []struct {
+		s     string
+		count int
+	}{
+		{"", 1 << 40},
+		{"abc", 0},
+		{"abc", 3},
+		{"x", repeatChunkSize + 1},
+		{"abc", 10000},
+		{strings.Repeat("y", repeatChunkSize*2), 3},
+	} {
+		res, err := repeatString(evalCtx, tc.s, tc.count)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := strings.Repeat(tc.s, tc.count); string(tree.MustBeDString(res)) != expected {
+			t.Errorf("repeat(%q, %d): expected a string of length %d, found length %d",
+				tc.s, tc.count, len(expected), len(tree.MustBeDString(res)))
+		}
+	}
+}
+
+func TestBuiltinCancellation(t *testing.T) {
+	evalCtx := tree.NewTestingEvalContext(cluster.MakeTestingClusterSettings())
+	defer evalCtx.Stop(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	evalCtx.Context = ctx
+
+	arr := tree.NewDArray(types.Int)
+	for i := 0; i < 10; i++ {
+		if err := arr.Append(tree.NewDInt(tree.DInt(i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tc := range []struct {
+		name string
+		args tree.Datums
+	}{
+		{"repeat", tree.Datums{tree.NewDString("abc"), tree.NewDInt(100000)}},
+		{"regexp_replace", tree.Datums{
+			tree.NewDString("abcabc"), tree.NewDString("b"), tree.NewDString("x"), tree.NewDString("g"),
+		}},
+		{"to_json", tree.Datums{arr}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, o := range builtins[tc.name].overloads {
+				if o.Types.Length() != len(tc.args) {
+					continue
+				}
+				if _, err := o.Fn(evalCtx, tc.args); !sqlbase.IsQueryCanceledError(err) {
+					t.Fatalf("expected the query to be canceled, found %v", err)
+				}
+			}
+		})
+	}
+
+	t.Run("generate_series", func(t *testing.T) {
+		gen, err := makeSeriesGenerator(evalCtx, tree.Datums{tree.NewDInt(1), tree.NewDInt(10)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := gen.Start(); err != nil {
+			t.Fatal(err)
+		}
+		defer gen.Close()
+		if _, err := gen.Next(); !sqlbase.IsQueryCanceledError(err) {
+			t.Fatalf("expected the query to be canceled, found %v", err)
+		}
+	})
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -27,6 +28,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/arith"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/json"
@@ -235,6 +237,7 @@ func makeGeneratorOverloadWithReturnType(
 
 // keywordsValueGenerator supports the execution of pg_get_keywords().
 type keywordsValueGenerator struct {
+	keywords   []lex.KeywordInfo
 	curKeyword int
 }
 
@@ -255,40 +258,46 @@ func (*keywordsValueGenerator) Close() {}
 
 // Start implements the tree.ValueGenerator interface.
 func (k *keywordsValueGenerator) Start() error {
+	k.keywords = lex.Keywords()
 	k.curKeyword = -1
 	return nil
 }
 func (k *keywordsValueGenerator) Next() (bool, error) {
 	k.curKeyword++
-	return k.curKeyword < len(lex.KeywordNames), nil
+	return k.curKeyword < len(k.keywords), nil
 }
 
 // Values implements the tree.ValueGenerator interface.
 func (k *keywordsValueGenerator) Values() tree.Datums {
-	kw := lex.KeywordNames[k.curKeyword]
-	cat := lex.KeywordsCategories[kw]
-	desc := keywordCategoryDescriptions[cat]
-	return tree.Datums{tree.NewDString(kw), tree.NewDString(cat), tree.NewDString(desc)}
-}
-
-var keywordCategoryDescriptions = map[string]string{
-	"R": "reserved",
-	"C": "unreserved (cannot be function or type name)",
-	"T": "reserved (can be function or type name)",
-	"U": "unreserved",
+	kw := k.keywords[k.curKeyword]
+	return tree.Datums{
+		tree.NewDString(kw.Name),
+		tree.NewDString(kw.Category.Code()),
+		tree.NewDString(kw.Category.Description()),
+	}
 }
 
 // seriesValueGenerator supports the execution of generate_series()
 // with integer bounds.
 type seriesValueGenerator struct {
-	ctx                                 *tree.EvalContext
-	origStart, value, start, stop, step interface{}
-	nextOK                              bool
-	genType                             types.T
-	next                                func(*seriesValueGenerator) (bool, error)
-	genValue                            func(*seriesValueGenerator) tree.Datums
+	ctx                                           *tree.EvalContext
+	origStart, origStop, value, start, stop, step interface{}
+	nextOK                                        bool
+	genType                                       types.T
+	next                                          func(*seriesValueGenerator) (bool, error)
+	genValue                                      func(*seriesValueGenerator) tree.Datums
+	applyBounds                                   func(*seriesValueGenerator)
+
+	// bounds are the bounds set by SetBounds, and count is the number of
+	// values produced since the generator was started.
+	bounds tree.ValueGeneratorBounds
+	count  int64
+
+	cancelChecker *sqlbase.CancelChecker
 }
 
+var _ tree.BoundedValueGenerator = &seriesValueGenerator{}
+
 var seriesValueGeneratorLabels = []string{"generate_series"}
 
 var seriesValueGeneratorType = types.Int
@@ -320,6 +329,57 @@ func seriesGenIntValue(s *seriesValueGenerator) tree.Datums {
 	return tree.Datums{tree.NewDInt(tree.DInt(s.value.(int64)))}
 }
 
+// seriesIntApplyBounds skips the values of the series which precede the
+// bound in the direction of the step, and stops the series at the bound
+// in the other direction.
+func seriesIntApplyBounds(s *seriesValueGenerator) {
+	step := s.step.(int64)
+	first, last := s.bounds.Lo, s.bounds.Hi
+	if step < 0 {
+		first, last = last, first
+	}
+	if d, ok := last.(*tree.DInt); ok {
+		if stop := int64(*d); (step > 0) == (stop < s.stop.(int64)) {
+			s.stop = stop
+		}
+	}
+	if d, ok := first.(*tree.DInt); ok {
+		s.start, s.nextOK = seriesIntSkip(s.start.(int64), int64(*d), step)
+	}
+}
+
+// seriesIntSkip returns the first value of the series starting at start
+// with the given step which does not precede bound in the direction of the
+// step. ok is false if there is no such value in the range of int64.
+func seriesIntSkip(start, bound, step int64) (_ int64, ok bool) {
+	// The distances and the number of steps are computed using uint64,
+	// which holds the difference of any two int64 values exactly.
+	var dist, room, stepSize uint64
+	if step > 0 {
+		if bound <= start {
+			return start, true
+		}
+		dist, room, stepSize = uint64(bound)-uint64(start), uint64(math.MaxInt64)-uint64(start), uint64(step)
+	} else {
+		if bound >= start {
+			return start, true
+		}
+		// The room is start - MinInt64, that is start + 2^63.
+		dist, room, stepSize = uint64(start)-uint64(bound), uint64(start)+(1<<63), -uint64(step)
+	}
+	steps := dist / stepSize
+	if dist%stepSize != 0 {
+		steps++
+	}
+	if steps > room/stepSize {
+		return 0, false
+	}
+	if step > 0 {
+		return int64(uint64(start) + steps*stepSize), true
+	}
+	return int64(uint64(start) - steps*stepSize), true
+}
+
 // seriesTSNext performs calendar-aware math.
 func seriesTSNext(s *seriesValueGenerator) (bool, error) {
 	step := s.step.(duration.Duration)
@@ -347,6 +407,22 @@ func seriesGenTSValue(s *seriesValueGenerator) tree.Datums {
 	return tree.Datums{tree.MakeDTimestamp(s.value.(time.Time), time.Microsecond)}
 }
 
+// seriesTSApplyBounds stops the series at the bound in the direction of the
+// step. Since the steps are calendar-aware, the values which precede the
+// bound in the other direction cannot be skipped without computing them.
+func seriesTSApplyBounds(s *seriesValueGenerator) {
+	forward := s.step.(duration.Duration).Compare(duration.Duration{}) > 0
+	last := s.bounds.Hi
+	if !forward {
+		last = s.bounds.Lo
+	}
+	if d, ok := last.(*tree.DTimestamp); ok {
+		if stop := d.Time; forward == stop.Before(s.stop.(time.Time)) {
+			s.stop = stop
+		}
+	}
+}
+
 func makeSeriesGenerator(ctx *tree.EvalContext, args tree.Datums) (tree.ValueGenerator, error) {
 	start := int64(tree.MustBeDInt(args[0]))
 	stop := int64(tree.MustBeDInt(args[1]))
@@ -358,13 +434,14 @@ func makeSeriesGenerator(ctx *tree.EvalContext, args tree.Datums) (tree.ValueGen
 		return nil, errStepCannotBeZero
 	}
 	return &seriesValueGenerator{
-		ctx:       ctx,
-		origStart: start,
-		stop:      stop,
-		step:      step,
-		genType:   seriesValueGeneratorType,
-		genValue:  seriesGenIntValue,
-		next:      seriesIntNext,
+		ctx:         ctx,
+		origStart:   start,
+		origStop:    stop,
+		step:        step,
+		genType:     seriesValueGeneratorType,
+		genValue:    seriesGenIntValue,
+		next:        seriesIntNext,
+		applyBounds: seriesIntApplyBounds,
 	}, nil
 }
 
@@ -378,13 +455,14 @@ func makeTSSeriesGenerator(ctx *tree.EvalContext, args tree.Datums) (tree.ValueG
 	}
 
 	return &seriesValueGenerator{
-		ctx:       ctx,
-		origStart: start,
-		stop:      stop,
-		step:      step,
-		genType:   seriesTSValueGeneratorType,
-		genValue:  seriesGenTSValue,
-		next:      seriesTSNext,
+		ctx:         ctx,
+		origStart:   start,
+		origStop:    stop,
+		step:        step,
+		genType:     seriesTSValueGeneratorType,
+		genValue:    seriesGenTSValue,
+		next:        seriesTSNext,
+		applyBounds: seriesTSApplyBounds,
 	}, nil
 }
 
@@ -397,15 +475,31 @@ func (s *seriesValueGenerator) ResolvedType() types.T {
 func (s *seriesValueGenerator) Start() error {
 	s.nextOK = true
 	s.start = s.origStart
-	s.value = s.origStart
+	s.stop = s.origStop
+	s.count = 0
+	s.applyBounds(s)
+	s.value = s.start
+	s.cancelChecker = newCancelChecker(s.ctx)
 	return nil
 }
 
+// SetBounds implements the tree.BoundedValueGenerator interface.
+func (s *seriesValueGenerator) SetBounds(bounds tree.ValueGeneratorBounds) {
+	s.bounds = bounds
+}
+
 // Close implements the tree.ValueGenerator interface.
 func (s *seriesValueGenerator) Close() {}
 
 // Next implements the tree.ValueGenerator interface.
 func (s *seriesValueGenerator) Next() (bool, error) {
+	if s.bounds.Limit > 0 && s.count >= s.bounds.Limit {
+		return false, nil
+	}
+	if err := s.cancelChecker.Check(); err != nil {
+		return false, err
+	}
+	s.count++
 	return s.next(s)
 }
 
@@ -660,35 +754,40 @@ var jsonArrayGeneratorType = types.JSON
 var jsonArrayTextGeneratorType = types.String
 
 type jsonArrayGenerator struct {
-	json      tree.DJSON
-	nextIndex int
-	asText    bool
-	buf       [1]tree.Datum
+	evalCtx       *tree.EvalContext
+	json          tree.DJSON
+	nextIndex     int
+	asText        bool
+	buf           [1]tree.Datum
+	cancelChecker *sqlbase.CancelChecker
 }
 
 var errJSONCallOnNonArray = pgerror.NewError(pgerror.CodeInvalidParameterValueError,
 	"cannot be called on a non-array")
 
 func makeJSONArrayAsJSONGenerator(
-	_ *tree.EvalContext, args tree.Datums,
+	evalCtx *tree.EvalContext, args tree.Datums,
 ) (tree.ValueGenerator, error) {
-	return makeJSONArrayGenerator(args, false)
+	return makeJSONArrayGenerator(evalCtx, args, false)
 }
 
 func makeJSONArrayAsTextGenerator(
-	_ *tree.EvalContext, args tree.Datums,
+	evalCtx *tree.EvalContext, args tree.Datums,
 ) (tree.ValueGenerator, error) {
-	return makeJSONArrayGenerator(args, true)
+	return makeJSONArrayGenerator(evalCtx, args, true)
 }
 
-func makeJSONArrayGenerator(args tree.Datums, asText bool) (tree.ValueGenerator, error) {
+func makeJSONArrayGenerator(
+	evalCtx *tree.EvalContext, args tree.Datums, asText bool,
+) (tree.ValueGenerator, error) {
 	target := tree.MustBeDJSON(args[0])
 	if target.Type() != json.ArrayJSONType {
 		return nil, errJSONCallOnNonArray
 	}
 	return &jsonArrayGenerator{
-		json:   target,
-		asText: asText,
+		evalCtx: evalCtx,
+		json:    target,
+		asText:  asText,
 	}, nil
 }
 
@@ -705,6 +804,7 @@ func (g *jsonArrayGenerator) Start() error {
 	g.nextIndex = -1
 	g.json.JSON = g.json.JSON.MaybeDecode()
 	g.buf[0] = nil
+	g.cancelChecker = newCancelChecker(g.evalCtx)
 	return nil
 }
 
@@ -713,6 +813,9 @@ func (g *jsonArrayGenerator) Close() {}
 
 // Next implements the tree.ValueGenerator interface.
 func (g *jsonArrayGenerator) Next() (bool, error) {
+	if err := g.cancelChecker.Check(); err != nil {
+		return false, err
+	}
 	g.nextIndex++
 	next, err := g.json.FetchValIdx(g.nextIndex)
 	if err != nil || next == nil {
@@ -819,30 +922,37 @@ var jsonEachTextGeneratorType = types.TTuple{
 }
 
 type jsonEachGenerator struct {
-	target tree.DJSON
-	iter   *json.ObjectIterator
-	key    tree.Datum
-	value  tree.Datum
-	asText bool
+	evalCtx       *tree.EvalContext
+	target        tree.DJSON
+	iter          *json.ObjectIterator
+	key           tree.Datum
+	value         tree.Datum
+	asText        bool
+	cancelChecker *sqlbase.CancelChecker
 }
 
-func makeJSONEachImplGenerator(_ *tree.EvalContext, args tree.Datums) (tree.ValueGenerator, error) {
-	return makeJSONEachGenerator(args, false)
+func makeJSONEachImplGenerator(
+	evalCtx *tree.EvalContext, args tree.Datums,
+) (tree.ValueGenerator, error) {
+	return makeJSONEachGenerator(evalCtx, args, false)
 }
 
 func makeJSONEachTextImplGenerator(
-	_ *tree.EvalContext, args tree.Datums,
+	evalCtx *tree.EvalContext, args tree.Datums,
 ) (tree.ValueGenerator, error) {
-	return makeJSONEachGenerator(args, true)
+	return makeJSONEachGenerator(evalCtx, args, true)
 }
 
-func makeJSONEachGenerator(args tree.Datums, asText bool) (tree.ValueGenerator, error) {
+func makeJSONEachGenerator(
+	evalCtx *tree.EvalContext, args tree.Datums, asText bool,
+) (tree.ValueGenerator, error) {
 	target := tree.MustBeDJSON(args[0])
 	return &jsonEachGenerator{
-		target: target,
-		key:    nil,
-		value:  nil,
-		asText: asText,
+		evalCtx: evalCtx,
+		target:  target,
+		key:     nil,
+		value:   nil,
+		asText:  asText,
 	}, nil
 }
 
@@ -869,6 +979,7 @@ func (g *jsonEachGenerator) Start() error {
 		}
 	}
 	g.iter = iter
+	g.cancelChecker = newCancelChecker(g.evalCtx)
 	return nil
 }
 
@@ -877,6 +988,9 @@ func (g *jsonEachGenerator) Close() {}
 
 // Next implements the tree.ValueGenerator interface.
 func (g *jsonEachGenerator) Next() (bool, error) {
+	if err := g.cancelChecker.Check(); err != nil {
+		return false, err
+	}
 	if !g.iter.Next() {
 		return false, nil
 	}
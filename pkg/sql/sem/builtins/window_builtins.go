@@ -176,6 +176,35 @@ var _ tree.WindowFunc = &nthValueWindow{}
 
 const noFilterIdx = -1
 
+// isRowSkipped returns whether the row at index idx must be skipped when
+// computing a window function over the frame of the current row, either
+// because it doesn't pass the filter or because it is excluded from the
+// frame.
+func isRowSkipped(ctx context.Context, wfr *tree.WindowFrameRun, idx int) (bool, error) {
+	if wfr.IsRowExcluded(idx) {
+		return true, nil
+	}
+	passes, err := passesFilter(ctx, wfr, idx)
+	return !passes, err
+}
+
+// passesFilter returns whether the row at index idx passes the filter of the
+// window function, if there is one.
+func passesFilter(ctx context.Context, wfr *tree.WindowFrameRun, idx int) (bool, error) {
+	if wfr.FilterColIdx == noFilterIdx {
+		return true, nil
+	}
+	row, err := wfr.Rows.GetRow(ctx, idx)
+	if err != nil {
+		return false, err
+	}
+	datum, err := row.GetDatum(wfr.FilterColIdx)
+	if err != nil {
+		return false, err
+	}
+	return datum == tree.DBoolTrue, nil
+}
+
 // aggregateWindowFunc aggregates over the the current row's window frame, using
 // the internal tree.AggregateFunc to perform the aggregation.
 type aggregateWindowFunc struct {
@@ -273,7 +302,7 @@ func newFramableAggregateWindow(
 func (w *framableAggregateWindowFunc) Compute(
 	ctx context.Context, evalCtx *tree.EvalContext, wfr *tree.WindowFrameRun,
 ) (tree.Datum, error) {
-	if !wfr.FirstInPeerGroup() {
+	if !wfr.FirstInPeerGroup() && !wfr.FrameExclusionDependsOnCurrentRow() {
 		return w.agg.peerRes, nil
 	}
 	if !w.shouldReset {
@@ -298,18 +327,10 @@ func (w *framableAggregateWindowFunc) Compute(
 		return nil, err
 	}
 	for i := frameStartIdx; i < frameEndIdx; i++ {
-		if wfr.FilterColIdx != noFilterIdx {
-			row, err := wfr.Rows.GetRow(ctx, i)
-			if err != nil {
-				return nil, err
-			}
-			datum, err := row.GetDatum(wfr.FilterColIdx)
-			if err != nil {
-				return nil, err
-			}
-			if datum != tree.DBoolTrue {
-				continue
-			}
+		if skipped, err := isRowSkipped(ctx, wfr, i); err != nil {
+			return nil, err
+		} else if skipped {
+			continue
 		}
 		args, err := wfr.ArgsByRowIdx(ctx, i)
 		if err != nil {
@@ -594,6 +615,21 @@ func (firstValueWindow) Compute(
 	if err != nil {
 		return nil, err
 	}
+	if wfr.FrameHasExclusions() {
+		// The first rows of the frame might be excluded, so we need to find the
+		// first row that isn't.
+		frameEndIdx, err := wfr.FrameEndIdx(ctx, evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		for frameStartIdx < frameEndIdx && wfr.IsRowExcluded(frameStartIdx) {
+			frameStartIdx++
+		}
+		if frameStartIdx == frameEndIdx {
+			// Spec: the frame is empty, so we return NULL.
+			return tree.DNull, nil
+		}
+	}
 	row, err := wfr.Rows.GetRow(ctx, frameStartIdx)
 	if err != nil {
 		return nil, err
@@ -617,6 +653,21 @@ func (lastValueWindow) Compute(
 	if err != nil {
 		return nil, err
 	}
+	if wfr.FrameHasExclusions() {
+		// The last rows of the frame might be excluded, so we need to find the
+		// last row that isn't.
+		frameStartIdx, err := wfr.FrameStartIdx(ctx, evalCtx)
+		if err != nil {
+			return nil, err
+		}
+		for frameEndIdx > frameStartIdx && wfr.IsRowExcluded(frameEndIdx-1) {
+			frameEndIdx--
+		}
+		if frameEndIdx <= frameStartIdx {
+			// Spec: the frame is empty, so we return NULL.
+			return tree.DNull, nil
+		}
+	}
 	row, err := wfr.Rows.GetRow(ctx, frameEndIdx-1)
 	if err != nil {
 		return nil, err
@@ -666,7 +717,20 @@ func (nthValueWindow) Compute(
 	if err != nil {
 		return nil, err
 	}
-	row, err := wfr.Rows.GetRow(ctx, frameStartIdx+nth-1)
+	idx := frameStartIdx + nth - 1
+	if wfr.FrameHasExclusions() {
+		// We need to skip over the excluded rows, so we count the rows from the
+		// start of the frame until we reach the nth row which isn't excluded.
+		idx = frameStartIdx
+		for remaining := nth; ; idx++ {
+			if !wfr.IsRowExcluded(idx) {
+				if remaining--; remaining == 0 {
+					break
+				}
+			}
+		}
+	}
+	row, err := wfr.Rows.GetRow(ctx, idx)
 	if err != nil {
 		return nil, err
 	}
@@ -107,6 +107,12 @@ func (w *slidingWindowFunc) Compute(
 		return nil, err
 	}
 
+	if wfr.FrameHasExclusions() {
+		// The excluded rows make the frame non-contiguous, so the sliding window
+		// approach doesn't apply: we compute the result over the whole frame.
+		return w.computeWithExclusions(ctx, wfr, frameStartIdx, frameEndIdx)
+	}
+
 	// We need to discard all values that are no longer in the frame.
 	w.sw.removeAllBefore(frameStartIdx)
 
@@ -144,6 +150,32 @@ func (w *slidingWindowFunc) Compute(
 	return w.sw.values.GetFirst().(*indexedValue).value, nil
 }
 
+// computeWithExclusions returns the value with "highest priority" among the
+// rows of the frame which are neither filtered out nor excluded.
+func (w *slidingWindowFunc) computeWithExclusions(
+	ctx context.Context, wfr *tree.WindowFrameRun, frameStartIdx, frameEndIdx int,
+) (tree.Datum, error) {
+	var res tree.Datum = tree.DNull
+	for idx := frameStartIdx; idx < frameEndIdx; idx++ {
+		if skipped, err := isRowSkipped(ctx, wfr, idx); err != nil {
+			return nil, err
+		} else if skipped {
+			continue
+		}
+		args, err := wfr.ArgsByRowIdx(ctx, idx)
+		if err != nil {
+			return nil, err
+		}
+		if args[0] == tree.DNull {
+			continue
+		}
+		if res == tree.DNull || w.sw.cmp(w.sw.evalCtx, args[0], res) > 0 {
+			res = args[0]
+		}
+	}
+	return res, nil
+}
+
 func max(a, b int) int {
 	if a > b {
 		return a
@@ -191,28 +223,85 @@ func (w *slidingWindowSumFunc) removeAllBefore(
 		if err != nil {
 			return err
 		}
-		value := args[0]
-		switch v := value.(type) {
-		case *tree.DInt:
-			err = w.agg.Add(ctx, tree.NewDInt(-*v))
-		case *tree.DDecimal:
-			d := tree.DDecimal{}
-			d.Neg(&v.Decimal)
-			err = w.agg.Add(ctx, &d)
-		case *tree.DFloat:
-			err = w.agg.Add(ctx, tree.NewDFloat(-*v))
-		case *tree.DInterval:
-			err = w.agg.Add(ctx, &tree.DInterval{Duration: duration.Duration{}.Sub(v.Duration)})
-		default:
-			err = pgerror.NewAssertionErrorf("unexpected value %v", v)
-		}
-		if err != nil {
+		if err := w.subtract(ctx, args[0]); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// subtract removes the given value from the sum.
+func (w *slidingWindowSumFunc) subtract(ctx context.Context, value tree.Datum) error {
+	switch v := value.(type) {
+	case *tree.DInt:
+		return w.agg.Add(ctx, tree.NewDInt(-*v))
+	case *tree.DDecimal:
+		d := tree.DDecimal{}
+		d.Neg(&v.Decimal)
+		return w.agg.Add(ctx, &d)
+	case *tree.DFloat:
+		return w.agg.Add(ctx, tree.NewDFloat(-*v))
+	case *tree.DInterval:
+		return w.agg.Add(ctx, &tree.DInterval{Duration: duration.Duration{}.Sub(v.Duration)})
+	default:
+		return pgerror.NewAssertionErrorf("unexpected value %v", v)
+	}
+}
+
+// resultWithExclusions returns the sum over the frame without the values of
+// the excluded rows. The sliding window always contains all the rows within
+// the frame bounds, so the values of the excluded rows are temporarily
+// subtracted from the sum.
+func (w *slidingWindowSumFunc) resultWithExclusions(
+	ctx context.Context, evalCtx *tree.EvalContext, wfr *tree.WindowFrameRun,
+	frameStartIdx, frameEndIdx int,
+) (tree.Datum, error) {
+	frameSize, err := wfr.FrameSize(ctx, evalCtx)
+	if err != nil {
+		return nil, err
+	}
+	if frameSize == 0 {
+		// Spec: all the rows of the frame are excluded, so we return NULL.
+		return tree.DNull, nil
+	}
+	// The excluded rows are always peers of the current row.
+	peersStartIdx := wfr.PeerHelper.GetFirstPeerIdx(wfr.CurRowPeerGroupNum)
+	peersEndIdx := peersStartIdx + wfr.PeerHelper.GetRowCount(wfr.CurRowPeerGroupNum)
+	var excluded tree.Datums
+	for idx := max(peersStartIdx, frameStartIdx); idx < frameEndIdx && idx < peersEndIdx; idx++ {
+		if !wfr.IsRowExcluded(idx) {
+			continue
+		}
+		if passes, err := passesFilter(ctx, wfr, idx); err != nil {
+			return nil, err
+		} else if !passes {
+			// The row is filtered out, so it isn't a part of the sum.
+			continue
+		}
+		args, err := wfr.ArgsByRowIdx(ctx, idx)
+		if err != nil {
+			return nil, err
+		}
+		if args[0] == tree.DNull {
+			continue
+		}
+		if err := w.subtract(ctx, args[0]); err != nil {
+			return nil, err
+		}
+		excluded = append(excluded, args[0])
+	}
+	res, err := w.agg.Result()
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range excluded {
+		if err := w.agg.Add(ctx, value); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
 // Compute implements WindowFunc interface.
 func (w *slidingWindowSumFunc) Compute(
 	ctx context.Context, evalCtx *tree.EvalContext, wfr *tree.WindowFrameRun,
@@ -263,6 +352,9 @@ func (w *slidingWindowSumFunc) Compute(
 		// Spec: the frame is empty, so we return NULL.
 		return tree.DNull, nil
 	}
+	if wfr.FrameHasExclusions() {
+		return w.resultWithExclusions(ctx, evalCtx, wfr, frameStartIdx, frameEndIdx)
+	}
 	return w.agg.Result()
 }
 
@@ -302,15 +394,9 @@ func (w *avgWindowFunc) Compute(
 			return nil, err
 		}
 		for idx := frameStartIdx; idx < frameEndIdx; idx++ {
-			row, err := wfr.Rows.GetRow(ctx, idx)
-			if err != nil {
+			if skipped, err := isRowSkipped(ctx, wfr, idx); err != nil {
 				return nil, err
-			}
-			datum, err := row.GetDatum(wfr.FilterColIdx)
-			if err != nil {
-				return nil, err
-			}
-			if datum != tree.DBoolTrue {
+			} else if skipped {
 				continue
 			}
 			frameSize++
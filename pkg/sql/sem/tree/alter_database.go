@@ -0,0 +1,33 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+// AlterDatabaseSetReadMode represents an ALTER DATABASE ... SET READ
+// {ONLY|WRITE} statement.
+type AlterDatabaseSetReadMode struct {
+	Name     Name
+	ReadOnly bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterDatabaseSetReadMode) Format(ctx *FmtCtx) {
+	ctx.WriteString("ALTER DATABASE ")
+	ctx.FormatNode(&node.Name)
+	if node.ReadOnly {
+		ctx.WriteString(" SET READ ONLY")
+	} else {
+		ctx.WriteString(" SET READ WRITE")
+	}
+}
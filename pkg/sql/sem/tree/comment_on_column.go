@@ -14,8 +14,6 @@
 
 package tree
 
-import "github.com/cockroachdb/cockroach/pkg/sql/lex"
-
 // CommentOnColumn represents an COMMENT ON COLUMN statement.
 type CommentOnColumn struct {
 	*ColumnItem
@@ -28,7 +26,7 @@ func (n *CommentOnColumn) Format(ctx *FmtCtx) {
 	ctx.FormatNode(n.ColumnItem)
 	ctx.WriteString(" IS ")
 	if n.Comment != nil {
-		lex.EncodeSQLStringWithFlags(&ctx.Buffer, *n.Comment, ctx.flags.EncodeFlags())
+		ctx.formatStringLiteral(*n.Comment)
 	} else {
 		ctx.WriteString("NULL")
 	}
@@ -14,8 +14,6 @@
 
 package tree
 
-import "github.com/cockroachdb/cockroach/pkg/sql/lex"
-
 // CommentOnDatabase represents an COMMENT ON DATABASE statement.
 type CommentOnDatabase struct {
 	Name    Name
@@ -28,7 +26,7 @@ func (n *CommentOnDatabase) Format(ctx *FmtCtx) {
 	ctx.FormatNode(&n.Name)
 	ctx.WriteString(" IS ")
 	if n.Comment != nil {
-		lex.EncodeSQLStringWithFlags(&ctx.Buffer, *n.Comment, ctx.flags.EncodeFlags())
+		ctx.formatStringLiteral(*n.Comment)
 	} else {
 		ctx.WriteString("NULL")
 	}
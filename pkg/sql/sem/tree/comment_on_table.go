@@ -14,8 +14,6 @@
 
 package tree
 
-import "github.com/cockroachdb/cockroach/pkg/sql/lex"
-
 // CommentOnTable represents an COMMENT ON TABLE statement.
 type CommentOnTable struct {
 	Table   TableName
@@ -28,7 +26,7 @@ func (n *CommentOnTable) Format(ctx *FmtCtx) {
 	ctx.FormatNode(&n.Table)
 	ctx.WriteString(" IS ")
 	if n.Comment != nil {
-		lex.EncodeSQLStringWithFlags(&ctx.Buffer, *n.Comment, ctx.flags.EncodeFlags())
+		ctx.formatStringLiteral(*n.Comment)
 	} else {
 		ctx.WriteString("NULL")
 	}
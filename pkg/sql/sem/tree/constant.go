@@ -393,6 +393,12 @@ func (expr *StrVal) RawString() string {
 	return expr.s
 }
 
+// ScannedAsBytes returns true iff the StrVal was constructed from the
+// b'...' or x'...' syntax, that is, with NewBytesStrVal.
+func (expr *StrVal) ScannedAsBytes() bool {
+	return expr.scannedAsBytes
+}
+
 // Format implements the NodeFormatter interface.
 func (expr *StrVal) Format(ctx *FmtCtx) {
 	buf, f := &ctx.Buffer, ctx.flags
@@ -214,6 +214,12 @@ type ColumnTableDef struct {
 	Computed struct {
 		Computed bool
 		Expr     Expr
+		Virtual  bool
+	}
+	Identity struct {
+		Identity   bool
+		Always     bool
+		SeqOptions SequenceOptions
 	}
 	Family struct {
 		Name        Name
@@ -319,6 +325,15 @@ func NewColumnTableDef(
 		case *ColumnComputedDef:
 			d.Computed.Computed = true
 			d.Computed.Expr = t.Expr
+			d.Computed.Virtual = t.Virtual
+		case *ColumnIdentityDef:
+			if d.IsIdentity() {
+				return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+					"multiple identity specifications for column %q", name)
+			}
+			d.Identity.Identity = true
+			d.Identity.Always = t.Always
+			d.Identity.SeqOptions = t.SeqOptions
 		case *ColumnFamilyConstraint:
 			if d.HasColumnFamily() {
 				return nil, pgerror.NewErrorf(pgerror.CodeInvalidTableDefinitionError,
@@ -354,6 +369,16 @@ func (node *ColumnTableDef) IsComputed() bool {
 	return node.Computed.Computed
 }
 
+// IsVirtual returns if the ColumnTableDef is a virtual computed column.
+func (node *ColumnTableDef) IsVirtual() bool {
+	return node.Computed.Computed && node.Computed.Virtual
+}
+
+// IsIdentity returns if the ColumnTableDef is an identity column.
+func (node *ColumnTableDef) IsIdentity() bool {
+	return node.Identity.Identity
+}
+
 // HasColumnFamily returns if the ColumnTableDef has a column family.
 func (node *ColumnTableDef) HasColumnFamily() bool {
 	return node.Family.Name != "" || node.Family.Create
@@ -423,7 +448,28 @@ func (node *ColumnTableDef) Format(ctx *FmtCtx) {
 	if node.IsComputed() {
 		ctx.WriteString(" AS (")
 		ctx.FormatNode(node.Computed.Expr)
-		ctx.WriteString(") STORED")
+		if node.Computed.Virtual {
+			ctx.WriteString(") VIRTUAL")
+		} else {
+			ctx.WriteString(") STORED")
+		}
+	}
+	if node.IsIdentity() {
+		if node.Identity.Always {
+			ctx.WriteString(" GENERATED ALWAYS AS IDENTITY")
+		} else {
+			ctx.WriteString(" GENERATED BY DEFAULT AS IDENTITY")
+		}
+		if len(node.Identity.SeqOptions) > 0 {
+			ctx.WriteString(" (")
+			for i := range node.Identity.SeqOptions {
+				if i > 0 {
+					ctx.WriteByte(' ')
+				}
+				ctx.FormatNode(&node.Identity.SeqOptions[i])
+			}
+			ctx.WriteByte(')')
+		}
 	}
 	if node.HasColumnFamily() {
 		if node.Family.Create {
@@ -462,6 +508,7 @@ func (PrimaryKeyConstraint) columnQualification()    {}
 func (UniqueConstraint) columnQualification()        {}
 func (*ColumnCheckConstraint) columnQualification()  {}
 func (*ColumnComputedDef) columnQualification()      {}
+func (*ColumnIdentityDef) columnQualification()      {}
 func (*ColumnFKConstraint) columnQualification()     {}
 func (*ColumnFamilyConstraint) columnQualification() {}
 
@@ -501,6 +548,18 @@ type ColumnFKConstraint struct {
 // ColumnComputedDef represents the description of a computed column.
 type ColumnComputedDef struct {
 	Expr Expr
+	// Virtual is set for a virtual computed column, whose values are computed
+	// when the table is read instead of being stored.
+	Virtual bool
+}
+
+// ColumnIdentityDef represents a GENERATED ... AS IDENTITY clause for a
+// column.
+type ColumnIdentityDef struct {
+	// Always is set for GENERATED ALWAYS AS IDENTITY, which only accepts
+	// values provided by INSERT if OVERRIDING SYSTEM VALUE is specified.
+	Always     bool
+	SeqOptions SequenceOptions
 }
 
 // ColumnFamilyConstraint represents FAMILY on a column.
@@ -1006,43 +1065,8 @@ type SequenceOptions []SequenceOption
 // Format implements the NodeFormatter interface.
 func (node *SequenceOptions) Format(ctx *FmtCtx) {
 	for i := range *node {
-		option := &(*node)[i]
 		ctx.WriteByte(' ')
-		switch option.Name {
-		case SeqOptCycle, SeqOptNoCycle:
-			ctx.WriteString(option.Name)
-		case SeqOptCache:
-			ctx.WriteString(option.Name)
-			ctx.WriteByte(' ')
-			ctx.Printf("%d", *option.IntVal)
-		case SeqOptMaxValue, SeqOptMinValue:
-			if option.IntVal == nil {
-				ctx.WriteString("NO ")
-				ctx.WriteString(option.Name)
-			} else {
-				ctx.WriteString(option.Name)
-				ctx.WriteByte(' ')
-				ctx.Printf("%d", *option.IntVal)
-			}
-		case SeqOptStart:
-			ctx.WriteString(option.Name)
-			ctx.WriteByte(' ')
-			if option.OptionalWord {
-				ctx.WriteString("WITH ")
-			}
-			ctx.Printf("%d", *option.IntVal)
-		case SeqOptIncrement:
-			ctx.WriteString(option.Name)
-			ctx.WriteByte(' ')
-			if option.OptionalWord {
-				ctx.WriteString("BY ")
-			}
-			ctx.Printf("%d", *option.IntVal)
-		case SeqOptVirtual:
-			ctx.WriteString(option.Name)
-		default:
-			panic(pgerror.NewAssertionErrorf("unexpected SequenceOption: %v", option))
-		}
+		ctx.FormatNode(&(*node)[i])
 	}
 }
 
@@ -1055,6 +1079,45 @@ type SequenceOption struct {
 	OptionalWord bool
 }
 
+// Format implements the NodeFormatter interface.
+func (option *SequenceOption) Format(ctx *FmtCtx) {
+	switch option.Name {
+	case SeqOptCycle, SeqOptNoCycle:
+		ctx.WriteString(option.Name)
+	case SeqOptCache:
+		ctx.WriteString(option.Name)
+		ctx.WriteByte(' ')
+		ctx.Printf("%d", *option.IntVal)
+	case SeqOptMaxValue, SeqOptMinValue:
+		if option.IntVal == nil {
+			ctx.WriteString("NO ")
+			ctx.WriteString(option.Name)
+		} else {
+			ctx.WriteString(option.Name)
+			ctx.WriteByte(' ')
+			ctx.Printf("%d", *option.IntVal)
+		}
+	case SeqOptStart:
+		ctx.WriteString(option.Name)
+		ctx.WriteByte(' ')
+		if option.OptionalWord {
+			ctx.WriteString("WITH ")
+		}
+		ctx.Printf("%d", *option.IntVal)
+	case SeqOptIncrement:
+		ctx.WriteString(option.Name)
+		ctx.WriteByte(' ')
+		if option.OptionalWord {
+			ctx.WriteString("BY ")
+		}
+		ctx.Printf("%d", *option.IntVal)
+	case SeqOptVirtual:
+		ctx.WriteString(option.Name)
+	default:
+		panic(pgerror.NewAssertionErrorf("unexpected SequenceOption: %v", option))
+	}
+}
+
 // Names of options on CREATE SEQUENCE.
 const (
 	SeqOptAs        = "AS"
@@ -1077,6 +1140,7 @@ const (
 type CreateUser struct {
 	Name        Expr
 	Password    Expr // nil if no password specified
+	ValidUntil  Expr // nil if no expiration specified
 	IfNotExists bool
 }
 
@@ -1100,13 +1164,19 @@ func (node *CreateUser) Format(ctx *FmtCtx) {
 			ctx.WriteString("*****")
 		}
 	}
+	if node.ValidUntil != nil {
+		ctx.WriteString(" VALID UNTIL ")
+		ctx.FormatNode(node.ValidUntil)
+	}
 }
 
-// AlterUserSetPassword represents an ALTER USER ... WITH PASSWORD statement.
+// AlterUserSetPassword represents an ALTER USER ... WITH PASSWORD or an
+// ALTER USER ... VALID UNTIL statement.
 type AlterUserSetPassword struct {
-	Name     Expr
-	Password Expr
-	IfExists bool
+	Name       Expr
+	Password   Expr // nil if the password is not changed
+	ValidUntil Expr // nil if the expiration is not changed
+	IfExists   bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -1116,11 +1186,17 @@ func (node *AlterUserSetPassword) Format(ctx *FmtCtx) {
 		ctx.WriteString("IF EXISTS ")
 	}
 	ctx.FormatNode(node.Name)
-	ctx.WriteString(" WITH PASSWORD ")
-	if ctx.flags.HasFlags(FmtShowPasswords) {
-		ctx.FormatNode(node.Password)
-	} else {
-		ctx.WriteString("*****")
+	if node.Password != nil {
+		ctx.WriteString(" WITH PASSWORD ")
+		if ctx.flags.HasFlags(FmtShowPasswords) {
+			ctx.FormatNode(node.Password)
+		} else {
+			ctx.WriteString("*****")
+		}
+	}
+	if node.ValidUntil != nil {
+		ctx.WriteString(" VALID UNTIL ")
+		ctx.FormatNode(node.ValidUntil)
 	}
 }
 
@@ -1144,6 +1220,8 @@ type CreateView struct {
 	Name        TableName
 	ColumnNames NameList
 	AsSource    *Select
+	// CheckOption is set for views created WITH CHECK OPTION.
+	CheckOption bool
 }
 
 // Format implements the NodeFormatter interface.
@@ -1160,14 +1238,20 @@ func (node *CreateView) Format(ctx *FmtCtx) {
 
 	ctx.WriteString(" AS ")
 	ctx.FormatNode(node.AsSource)
+	if node.CheckOption {
+		ctx.WriteString(" WITH CHECK OPTION")
+	}
 }
 
 // CreateStats represents a CREATE STATISTICS statement.
 type CreateStats struct {
 	Name        Name
 	ColumnNames NameList
-	Table       TableExpr
-	Options     CreateStatsOptions
+	// Expr, if set, is the expression of the columns of the table which the
+	// statistics are created on, instead of ColumnNames.
+	Expr    Expr
+	Table   TableExpr
+	Options CreateStatsOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -1175,7 +1259,11 @@ func (node *CreateStats) Format(ctx *FmtCtx) {
 	ctx.WriteString("CREATE STATISTICS ")
 	ctx.FormatNode(&node.Name)
 
-	if len(node.ColumnNames) > 0 {
+	if node.Expr != nil {
+		ctx.WriteString(" ON (")
+		ctx.FormatNode(node.Expr)
+		ctx.WriteByte(')')
+	} else if len(node.ColumnNames) > 0 {
 		ctx.WriteString(" ON ")
 		ctx.FormatNode(&node.ColumnNames)
 	}
@@ -1197,11 +1285,17 @@ type CreateStatsOptions struct {
 
 	// AsOf performs a historical read at the given timestamp.
 	AsOf AsOfClause
+
+	// UsingExtremes restricts the statistics to the rows whose values are
+	// outside of the range of the values seen by the most recent full
+	// statistics on the same column, e.g. the rows recently inserted at the
+	// end of an ascending key.
+	UsingExtremes bool
 }
 
 // Empty returns true if no options were provided.
 func (o *CreateStatsOptions) Empty() bool {
-	return o.Throttling == 0 && o.AsOf.Expr == nil
+	return o.Throttling == 0 && o.AsOf.Expr == nil && !o.UsingExtremes
 }
 
 // Format implements the NodeFormatter interface.
@@ -1216,6 +1310,10 @@ func (o *CreateStatsOptions) Format(ctx *FmtCtx) {
 		ctx.FormatNode(&o.AsOf)
 		sep = " "
 	}
+	if o.UsingExtremes {
+		ctx.WriteString(sep)
+		ctx.WriteString("USING EXTREMES")
+	}
 }
 
 // CombineWith combines two options, erroring out if the two options contain
@@ -1233,5 +1331,11 @@ func (o *CreateStatsOptions) CombineWith(other *CreateStatsOptions) error {
 		}
 		o.AsOf = other.AsOf
 	}
+	if other.UsingExtremes {
+		if o.UsingExtremes {
+			return errors.New("USING EXTREMES specified multiple times")
+		}
+		o.UsingExtremes = true
+	}
 	return nil
 }
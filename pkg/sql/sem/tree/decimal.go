@@ -17,9 +17,11 @@ package tree
 import (
 	"fmt"
 	"math"
+	"sync"
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/util/arith"
 )
 
 var (
@@ -52,6 +54,12 @@ var (
 	}()
 
 	errScaleOutOfRange = pgerror.NewError(pgerror.CodeNumericValueOutOfRangeError, "scale out of range")
+
+	// decimalCtxPool pools the contexts used by LimitDecimalWidth, whose
+	// precision depends on the type of the column.
+	decimalCtxPool = sync.Pool{
+		New: func() interface{} { return new(apd.Context) },
+	}
 )
 
 // LimitDecimalWidth limits d's precision (total number of digits) and scale
@@ -77,10 +85,14 @@ func LimitDecimalWidth(d *apd.Decimal, precision, scale int) error {
 	// exceeds the declared precision minus the declared scale, an
 	// error is raised."
 
-	c := DecimalCtx.WithPrecision(uint32(precision))
+	c := decimalCtxPool.Get().(*apd.Context)
+	*c = *DecimalCtx
+	c.Precision = uint32(precision)
 	c.Traps = apd.InvalidOperation
 
-	if _, err := c.Quantize(d, d, -int32(scale)); err != nil {
+	_, err := c.Quantize(d, d, -int32(scale))
+	decimalCtxPool.Put(c)
+	if err != nil {
 		var lt string
 		switch v := precision - scale; v {
 		case 0:
@@ -92,3 +104,148 @@ func LimitDecimalWidth(d *apd.Decimal, precision, scale int) error {
 	}
 	return nil
 }
+
+// DecimalAdd sets d to x + y in the given context. It computes the same result
+// as c.Add, but uses int64 arithmetic when the coefficients of the operands and
+// of the result fit in an int64, which avoids the allocations of big.Int
+// arithmetic.
+func DecimalAdd(c *apd.Context, d, x, y *apd.Decimal) (apd.Condition, error) {
+	if addDecimalInt64(c, d, x, y, false /* subtract */) {
+		return 0, nil
+	}
+	return c.Add(d, x, y)
+}
+
+// DecimalSub sets d to x - y in the given context. See DecimalAdd.
+func DecimalSub(c *apd.Context, d, x, y *apd.Decimal) (apd.Condition, error) {
+	if addDecimalInt64(c, d, x, y, true /* subtract */) {
+		return 0, nil
+	}
+	return c.Sub(d, x, y)
+}
+
+// DecimalMul sets d to x * y in the given context. See DecimalAdd.
+func DecimalMul(c *apd.Context, d, x, y *apd.Decimal) (apd.Condition, error) {
+	if mulDecimalInt64(c, d, x, y) {
+		return 0, nil
+	}
+	return c.Mul(d, x, y)
+}
+
+// pow10Int64 contains the powers of ten which fit in an int64.
+var pow10Int64 = func() (r [19]int64) {
+	r[0] = 1
+	for i := 1; i < len(r); i++ {
+		r[i] = r[i-1] * 10
+	}
+	return r
+}()
+
+// decimalInt64 returns the coefficient of x if x is finite and its coefficient
+// fits in an int64. Note that the coefficient of a decimal is never negative.
+func decimalInt64(x *apd.Decimal) (int64, bool) {
+	if x.Form != apd.Finite || !x.Coeff.IsInt64() {
+		return 0, false
+	}
+	return x.Coeff.Int64(), true
+}
+
+// int64ResultIsExact returns true if a result whose coefficient fits in an
+// int64 and whose exponent is exp is neither rounded nor out of the range of
+// exponents of the given context. An int64 has at most 19 digits.
+func int64ResultIsExact(c *apd.Context, exp int64) bool {
+	return (c.Precision == 0 || c.Precision >= 19) &&
+		exp >= int64(c.MinExponent) && exp+18 <= int64(c.MaxExponent)
+}
+
+// addDecimalInt64 sets d to x + y, or to x - y if subtract is true, and returns
+// true if the operation can be computed using int64 arithmetic. Otherwise, d is
+// not modified.
+func addDecimalInt64(c *apd.Context, d, x, y *apd.Decimal, subtract bool) bool {
+	a, ok := decimalInt64(x)
+	if !ok {
+		return false
+	}
+	b, ok := decimalInt64(y)
+	if !ok {
+		return false
+	}
+	// Bring the coefficients to the smallest of the two exponents.
+	exp := x.Exponent
+	if diff := int64(x.Exponent) - int64(y.Exponent); diff > 0 {
+		if a, ok = scaleInt64(a, diff); !ok {
+			return false
+		}
+		exp = y.Exponent
+	} else if diff < 0 {
+		if b, ok = scaleInt64(b, -diff); !ok {
+			return false
+		}
+	}
+	if !int64ResultIsExact(c, int64(exp)) {
+		return false
+	}
+
+	// The sign of the result follows the rules of the General Decimal
+	// Arithmetic specification, like apd: an exact zero resulting from the sum
+	// of operands with opposite signs is positive, except when rounding
+	// towards negative infinity.
+	xNeg, yNeg := x.Negative, y.Negative != subtract
+	neg := xNeg
+	var r int64
+	if xNeg == yNeg {
+		if r, ok = arith.AddWithOverflow(a, b); !ok {
+			return false
+		}
+	} else {
+		r = a - b
+		switch {
+		case r < 0:
+			neg = !neg
+			r = -r
+		case r == 0:
+			neg = c.Rounding == apd.RoundFloor
+		}
+	}
+	d.SetFinite(r, exp)
+	d.Negative = neg
+	return true
+}
+
+// mulDecimalInt64 sets d to x * y and returns true if the operation can be
+// computed using int64 arithmetic. Otherwise, d is not modified.
+func mulDecimalInt64(c *apd.Context, d, x, y *apd.Decimal) bool {
+	a, ok := decimalInt64(x)
+	if !ok {
+		return false
+	}
+	b, ok := decimalInt64(y)
+	if !ok {
+		return false
+	}
+	exp := int64(x.Exponent) + int64(y.Exponent)
+	if !int64ResultIsExact(c, exp) {
+		return false
+	}
+	var r int64
+	if b != 0 {
+		if r, ok = arith.MulHalfPositiveWithOverflow(a, b); !ok {
+			return false
+		}
+	}
+	neg := x.Negative != y.Negative
+	d.SetFinite(r, int32(exp))
+	d.Negative = neg
+	return true
+}
+
+// scaleInt64 returns a * 10^exp, if it fits in an int64.
+func scaleInt64(a int64, exp int64) (int64, bool) {
+	if a == 0 {
+		return 0, true
+	}
+	if exp >= int64(len(pow10Int64)) {
+		return 0, false
+	}
+	return arith.MulHalfPositiveWithOverflow(a, pow10Int64[exp])
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// TestDecimalArithmetic checks that DecimalAdd, DecimalSub and DecimalMul
+// compute the same results as apd, including the exponent and the sign of
+// zero, whether or not they use their int64 fast paths.
+func TestDecimalArithmetic(t *testing.T) {
+	values := []string{
+		"0", "-0", "0.000", "-0.00", "1", "-1", "1.5", "-2.25", "10", "1E+3", "-1E-3",
+		"123456789012345678", "-0.123456789012345678", "9223372036854775807",
+		"-9223372036854775807", "9223372036854775808", "3037000499", "3037000500",
+		"99999999999999999999", "1E+18", "1E-18", "1E+1990", "-1E-1990",
+		"NaN", "Infinity", "-Infinity",
+	}
+	decimals := make([]*apd.Decimal, len(values))
+	for i, v := range values {
+		d, _, err := apd.NewFromString(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decimals[i] = d
+	}
+
+	type decimalOp func(c *apd.Context, d, x, y *apd.Decimal) (apd.Condition, error)
+	ops := []struct {
+		name     string
+		op       decimalOp
+		expected decimalOp
+	}{
+		{"+", tree.DecimalAdd, (*apd.Context).Add},
+		{"-", tree.DecimalSub, (*apd.Context).Sub},
+		{"*", tree.DecimalMul, (*apd.Context).Mul},
+	}
+	ctxs := []*apd.Context{tree.ExactCtx, tree.DecimalCtx, tree.IntermediateCtx}
+
+	format := func(d *apd.Decimal, err error) string {
+		if err != nil {
+			return fmt.Sprintf("error: %v", err)
+		}
+		return fmt.Sprintf("%s (negative: %t, exponent: %d)", d, d.Negative, d.Exponent)
+	}
+	for _, c := range ctxs {
+		for _, op := range ops {
+			for _, x := range decimals {
+				for _, y := range decimals {
+					var expected, res apd.Decimal
+					_, expectedErr := op.expected(c, &expected, x, y)
+					_, err := op.op(c, &res, x, y)
+					if e, r := format(&expected, expectedErr), format(&res, err); e != r {
+						t.Errorf("precision %d: %s %s %s: expected %s, got %s",
+							c.Precision, x, op.name, y, e, r)
+					}
+				}
+			}
+		}
+	}
+
+	// The result can alias the operands.
+	x, _, _ := apd.NewFromString("1.5")
+	if _, err := tree.DecimalAdd(tree.ExactCtx, x, x, x); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.DecimalMul(tree.ExactCtx, x, x, x); err != nil {
+		t.Fatal(err)
+	}
+	if s := x.String(); s != "9.00" {
+		t.Errorf("expected 9.00, got %s", s)
+	}
+}
+
+func BenchmarkDecimalAdd(b *testing.B) {
+	for _, v := range []string{"1.25", "123456789012345678901234567890.5"} {
+		y, _, err := apd.NewFromString(v)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(v, func(b *testing.B) {
+			var sum apd.Decimal
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := tree.DecimalAdd(tree.ExactCtx, &sum, &sum, y); err != nil {
+					b.Fatal(err)
+				}
+				if i%1000 == 0 {
+					sum.SetFinite(0, 0)
+				}
+			}
+		})
+	}
+}
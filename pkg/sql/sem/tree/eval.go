@@ -500,7 +500,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				l := &left.(*DDecimal).Decimal
 				r := &right.(*DDecimal).Decimal
 				dd := &DDecimal{}
-				_, err := ExactCtx.Add(&dd.Decimal, l, r)
+				_, err := DecimalAdd(ExactCtx, &dd.Decimal, l, r)
 				return dd, err
 			},
 		},
@@ -513,7 +513,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				r := MustBeDInt(right)
 				dd := &DDecimal{}
 				dd.SetFinite(int64(r), 0)
-				_, err := ExactCtx.Add(&dd.Decimal, l, &dd.Decimal)
+				_, err := DecimalAdd(ExactCtx, &dd.Decimal, l, &dd.Decimal)
 				return dd, err
 			},
 		},
@@ -526,7 +526,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				r := &right.(*DDecimal).Decimal
 				dd := &DDecimal{}
 				dd.SetFinite(int64(l), 0)
-				_, err := ExactCtx.Add(&dd.Decimal, &dd.Decimal, r)
+				_, err := DecimalAdd(ExactCtx, &dd.Decimal, &dd.Decimal, r)
 				return dd, err
 			},
 		},
@@ -704,7 +704,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				l := &left.(*DDecimal).Decimal
 				r := &right.(*DDecimal).Decimal
 				dd := &DDecimal{}
-				_, err := ExactCtx.Sub(&dd.Decimal, l, r)
+				_, err := DecimalSub(ExactCtx, &dd.Decimal, l, r)
 				return dd, err
 			},
 		},
@@ -717,7 +717,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				r := MustBeDInt(right)
 				dd := &DDecimal{}
 				dd.SetFinite(int64(r), 0)
-				_, err := ExactCtx.Sub(&dd.Decimal, l, &dd.Decimal)
+				_, err := DecimalSub(ExactCtx, &dd.Decimal, l, &dd.Decimal)
 				return dd, err
 			},
 		},
@@ -730,7 +730,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				r := &right.(*DDecimal).Decimal
 				dd := &DDecimal{}
 				dd.SetFinite(int64(l), 0)
-				_, err := ExactCtx.Sub(&dd.Decimal, &dd.Decimal, r)
+				_, err := DecimalSub(ExactCtx, &dd.Decimal, &dd.Decimal, r)
 				return dd, err
 			},
 		},
@@ -966,7 +966,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				l := &left.(*DDecimal).Decimal
 				r := &right.(*DDecimal).Decimal
 				dd := &DDecimal{}
-				_, err := ExactCtx.Mul(&dd.Decimal, l, r)
+				_, err := DecimalMul(ExactCtx, &dd.Decimal, l, r)
 				return dd, err
 			},
 		},
@@ -982,7 +982,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				r := MustBeDInt(right)
 				dd := &DDecimal{}
 				dd.SetFinite(int64(r), 0)
-				_, err := ExactCtx.Mul(&dd.Decimal, l, &dd.Decimal)
+				_, err := DecimalMul(ExactCtx, &dd.Decimal, l, &dd.Decimal)
 				return dd, err
 			},
 		},
@@ -995,7 +995,7 @@ var BinOps = map[BinaryOperator]binOpOverload{
 				r := &right.(*DDecimal).Decimal
 				dd := &DDecimal{}
 				dd.SetFinite(int64(l), 0)
-				_, err := ExactCtx.Mul(&dd.Decimal, &dd.Decimal, r)
+				_, err := DecimalMul(ExactCtx, &dd.Decimal, &dd.Decimal, r)
 				return dd, err
 			},
 		},
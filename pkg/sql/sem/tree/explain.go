@@ -69,8 +69,8 @@ type ExplainOptions struct {
 	Flags util.FastIntSet
 }
 
-// ExplainMode indicates the mode of the explain. Currently there are two modes:
-// PLAN (the default) and DISTSQL.
+// ExplainMode indicates the mode of the explain: PLAN (the default), DISTSQL,
+// OPT or GIST.
 type ExplainMode uint8
 
 const (
@@ -85,12 +85,17 @@ const (
 	// ExplainOpt shows the optimized relational expression (from the cost-based
 	// optimizer).
 	ExplainOpt
+
+	// ExplainGist shows the gist of the plan chosen by the cost-based
+	// optimizer, which can be used to pin the plan (see opt.PlanGist).
+	ExplainGist
 )
 
 var explainModeStrings = map[string]ExplainMode{
 	"plan":    ExplainPlan,
 	"distsql": ExplainDistSQL,
 	"opt":     ExplainOpt,
+	"gist":    ExplainGist,
 }
 
 // ExplainModeName returns the human-readable name of a given ExplainMode.
@@ -1224,6 +1224,12 @@ type FuncExpr struct {
 	Filter    Expr
 	WindowDef *WindowDef
 
+	// AggType is used to specify the type of aggregation.
+	AggType AggType
+	// OrderBy is used for the WITHIN GROUP clause of ordered-set aggregates:
+	// percentile_disc(0.5) WITHIN GROUP (ORDER BY k)
+	OrderBy OrderBy
+
 	typeAnnotation
 	fnProps *FunctionProperties
 	fn      *Overload
@@ -1318,6 +1324,20 @@ var funcTypeName = [...]string{
 	AllFuncType:      "ALL",
 }
 
+// AggType specifies the type of aggregation.
+type AggType int
+
+// FuncExpr.AggType
+const (
+	_ AggType = iota
+	// GeneralAgg is used for general-purpose aggregate functions.
+	// sum(k)
+	GeneralAgg
+	// OrderedSetAgg is used for ordered-set aggregate functions.
+	// percentile_disc(0.5) WITHIN GROUP (ORDER BY k)
+	OrderedSetAgg
+)
+
 // Format implements the NodeFormatter interface.
 func (node *FuncExpr) Format(ctx *FmtCtx) {
 	var typ string
@@ -1348,6 +1368,11 @@ func (node *FuncExpr) Format(ctx *FmtCtx) {
 			}
 		}
 	}
+	if node.AggType == OrderedSetAgg {
+		ctx.WriteString(" WITHIN GROUP (")
+		ctx.FormatNode(&node.OrderBy)
+		ctx.WriteString(")")
+	}
 	if node.Filter != nil {
 		ctx.WriteString(" FILTER (WHERE ")
 		ctx.FormatNode(node.Filter)
@@ -1723,6 +1748,7 @@ func (node *DOidWrapper) String() string      { return AsString(node) }
 func (node *Exprs) String() string            { return AsString(node) }
 func (node *ArrayFlatten) String() string     { return AsString(node) }
 func (node *FuncExpr) String() string         { return AsString(node) }
+func (node *GroupingSet) String() string      { return AsString(node) }
 func (node *IfExpr) String() string           { return AsString(node) }
 func (node *IfErrExpr) String() string        { return AsString(node) }
 func (node *IndexedVar) String() string       { return AsString(node) }
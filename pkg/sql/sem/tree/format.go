@@ -121,6 +121,20 @@ const (
 	// representation like -Inf. Negative values are preserved "inside"
 	// the numeric by enclosing them within parentheses.
 	FmtParsableNumerics
+
+	// FmtRedactConstants instructs the pretty-printer to replace every
+	// literal with the marker "_", so that the query text can be logged
+	// without disclosing user data. Unlike FmtHideConstants, lists of
+	// literals are not shortened, so that the structure of the query is
+	// preserved. NULL and placeholders are kept as is.
+	FmtRedactConstants
+
+	// FmtHashConstants is like FmtRedactConstants, except that every
+	// literal is replaced by a short hash of its value, e.g. _:1a2b3c4d.
+	// This makes it possible to tell whether two redacted queries use the
+	// same values. Note that values from a small domain (e.g. booleans or
+	// small integers) can be recovered from their hash.
+	FmtHashConstants
 )
 
 // Composite/derived flag definitions follow.
@@ -135,6 +149,13 @@ const (
 	// expressions).
 	FmtParsable FmtFlags = fmtDisambiguateDatumTypes | FmtParsableNumerics
 
+	// FmtRoundTrip instructs the pretty-printer to produce a representation
+	// of an AST produced by the parser which parses back into a structurally
+	// identical AST. Unlike FmtParsable, it does not add type annotations,
+	// which would introduce new nodes, and unlike FmtSimple, it shows the
+	// passwords. See parser.VerifyRoundTrip.
+	FmtRoundTrip FmtFlags = FmtShowPasswords | FmtParsableNumerics
+
 	// FmtCheckEquivalence instructs the pretty-printer to produce a representation
 	// that can be used to check equivalence of expressions. Specifically:
 	//  - IndexedVars are formatted using symbolic notation (to disambiguate
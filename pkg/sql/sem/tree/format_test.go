@@ -80,6 +80,16 @@ func TestFormatStatement(t *testing.T) {
 		{`SELECT 1+COALESCE(NULL, 'a', x)-ARRAY[3.14]`, tree.FmtHideConstants,
 			`SELECT (_ + COALESCE(_, _, x)) - ARRAY[_]`},
 
+		{`INSERT INTO a VALUES (0, 0, 0), (0, 0, 0)`,
+			tree.FmtRedactConstants,
+			`INSERT INTO a VALUES (_, _, _), (_, _, _)`},
+		{`SELECT 1+COALESCE(NULL, 'a', x)-ARRAY[3.14]`, tree.FmtRedactConstants,
+			`SELECT (_ + COALESCE(NULL, _, x)) - ARRAY[_]`},
+		{`SELECT 'a', 'a', 'b', $1`, tree.FmtHashConstants,
+			`SELECT _:ad863d66, _:ad863d66, _:3d7ed151, $1`},
+		{`COMMENT ON COLUMN t.a IS 'foo'`, tree.FmtRedactConstants,
+			`COMMENT ON COLUMN t.a IS _`},
+
 		// This here checks encodeSQLString on non-tree.DString strings also
 		// calls encodeSQLString with the right formatter.
 		// See TestFormatExprs below for the test on DStrings.
@@ -259,6 +269,8 @@ func TestFormatExpr(t *testing.T) {
 
 		{`(1, COALESCE(NULL, 123), ARRAY[45.6])`, tree.FmtHideConstants,
 			`(_, COALESCE(_, _), ARRAY[_])`},
+		{`(1, COALESCE(NULL, 123), ARRAY[45.6])`, tree.FmtRedactConstants,
+			`(_, COALESCE(NULL, _), ARRAY[_])`},
 	}
 
 	for i, test := range testData {
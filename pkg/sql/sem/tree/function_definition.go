@@ -14,7 +14,12 @@
 
 package tree
 
-import "github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+import (
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqltelemetry"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
 
 // FunctionDefinition implements a reference to the (possibly several)
 // overloads for a built-in function.
@@ -94,6 +99,13 @@ type FunctionProperties struct {
 	// determined without extra context. This is used for formatting builtins
 	// with the FmtParsable directive.
 	AmbiguousReturnType bool
+
+	// OrderedSetImpl, if non-empty, indicates that the builtin is an
+	// ordered-set aggregate, which must be called with a WITHIN GROUP clause.
+	// Its applications are replaced during type checking by applications of
+	// the private aggregate with this name, which receives the WITHIN GROUP
+	// argument, the direct arguments and whether the ordering is descending.
+	OrderedSetImpl string
 }
 
 // FunctionClass specifies the class of the builtin function.
@@ -137,10 +149,44 @@ func NewFunctionDefinition(
 	}
 }
 
+// NewUserDefinedFunctionDefinition allocates a function definition for the
+// overloads of a user-defined function. Unlike built-in functions, whose
+// definitions are allocated once, user-defined functions are resolved for
+// every statement, so no telemetry counters are registered for them.
+func NewUserDefinedFunctionDefinition(
+	name string, props *FunctionProperties, def []Overload,
+) *FunctionDefinition {
+	overloads := make([]overloadImpl, len(def))
+	for i := range def {
+		overloads[i] = &def[i]
+	}
+	return &FunctionDefinition{
+		Name:               name,
+		Definition:         overloads,
+		FunctionProperties: *props,
+	}
+}
+
 // FunDefs holds pre-allocated FunctionDefinition instances
 // for every builtin function. Initialized by builtins.init().
 var FunDefs map[string]*FunctionDefinition
 
+// SuggestFunctionName returns the name of the builtin function that name is
+// most likely a misspelling of, or "" if there is no such function.
+func SuggestFunctionName(name string) string {
+	s := util.MakeSpellingSuggester(name)
+	for candidate, def := range FunDefs {
+		if def.Private || strings.Contains(candidate, ".") {
+			// Private functions should not be advertised, and qualified
+			// names are not a plausible spelling of an unqualified one.
+			continue
+		}
+		s.Add(candidate)
+	}
+	best, _ := s.Best()
+	return best
+}
+
 // Format implements the NodeFormatter interface.
 func (fd *FunctionDefinition) Format(ctx *FmtCtx) {
 	ctx.WriteString(fd.Name)
@@ -58,6 +58,29 @@ type ValueGenerator interface {
 	Close()
 }
 
+// ValueGeneratorBounds describes the values that the consumer of a
+// ValueGenerator needs: the rows after the first Limit ones, and the rows
+// whose first value is less than Lo or greater than Hi, are discarded.
+// A nil bound or a zero Limit does not restrict the values.
+type ValueGeneratorBounds struct {
+	Lo, Hi Datum
+	Limit  int64
+}
+
+// BoundedValueGenerator is implemented by the ValueGenerators which can
+// avoid producing values that their consumer is going to discard, e.g.
+// generate_series() whose values are monotonic.
+type BoundedValueGenerator interface {
+	ValueGenerator
+
+	// SetBounds informs the generator of the values needed by its
+	// consumer, until the next call to SetBounds. It must be called
+	// before Start(), and takes effect when the generator is (re)started.
+	// The generator may still produce some of the values which are not
+	// needed; the consumer remains responsible for discarding them.
+	SetBounds(ValueGeneratorBounds)
+}
+
 // GeneratorFactory is the type of constructor functions for
 // ValueGenerator objects.
 type GeneratorFactory func(ctx *EvalContext, args Datums) (ValueGenerator, error)
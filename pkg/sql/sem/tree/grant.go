@@ -28,8 +28,12 @@ import "github.com/cockroachdb/cockroach/pkg/sql/privilege"
 // Grant represents a GRANT statement.
 type Grant struct {
 	Privileges privilege.List
-	Targets    TargetList
-	Grantees   NameList
+	// Columns is the list of columns the privileges are granted on, for
+	// column-level privileges. It is nil for privileges granted on the targets
+	// as a whole.
+	Columns  NameList
+	Targets  TargetList
+	Grantees NameList
 }
 
 // TargetList represents a list of targets.
@@ -60,6 +64,11 @@ func (tl *TargetList) Format(ctx *FmtCtx) {
 func (node *Grant) Format(ctx *FmtCtx) {
 	ctx.WriteString("GRANT ")
 	node.Privileges.Format(&ctx.Buffer)
+	if node.Columns != nil {
+		ctx.WriteString(" (")
+		ctx.FormatNode(&node.Columns)
+		ctx.WriteByte(')')
+	}
 	ctx.WriteString(" ON ")
 	ctx.FormatNode(&node.Targets)
 	ctx.WriteString(" TO ")
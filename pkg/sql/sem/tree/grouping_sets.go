@@ -0,0 +1,517 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/util"
+)
+
+// This file implements grouping sets (GROUPING SETS, ROLLUP, CUBE and the
+// empty grouping set) and the GROUPING() function by rewriting the SELECT
+// clause which uses them into a SELECT clause with a plain GROUP BY, which
+// both planners know how to plan.
+//
+// The grouping sets are numbered from 1 to n, and every input row is
+// replicated once per grouping set by a cross join with a VALUES clause
+// providing the set numbers. In the copy of a row for a given set, the
+// grouping expressions that are not part of the set are replaced by NULL:
+//
+//   SELECT a, b, sum(c) FROM t GROUP BY ROLLUP (a, b)
+//
+// becomes:
+//
+//   SELECT CASE WHEN s.set_id IN (1, 2) THEN a END AS a,
+//          CASE WHEN s.set_id IN (1) THEN b END AS b,
+//          sum(c)
+//   FROM t, (VALUES (1), (2), (3)) AS s (set_id)
+//   GROUP BY s.set_id,
+//            CASE WHEN s.set_id IN (1, 2) THEN a END,
+//            CASE WHEN s.set_id IN (1) THEN b END
+//
+// The grouping expressions that are part of all the sets are left as is.
+// The arguments to the aggregate functions are not rewritten, since they
+// refer to the input rows. GROUPING() is replaced by its value for each set.
+//
+// Note that, unlike the grouping sets of PostgreSQL, the empty grouping set
+// does not produce a row if the input of the aggregation is empty, unless it
+// is the only grouping set.
+
+const (
+	// maxGroupingSets is the maximum number of grouping sets that a GROUP BY
+	// clause can specify.
+	maxGroupingSets = 4096
+	// maxCubeItems is the maximum number of items of a CUBE.
+	maxCubeItems = 12
+
+	groupingSetsTableName  = "crdb_internal_grouping_sets"
+	groupingSetsColumnName = "set_id"
+	groupingFuncName       = "grouping"
+)
+
+// ExpandGroupingSets rewrites a SELECT clause which uses grouping sets or
+// GROUPING() into an equivalent SELECT clause which does not. orderBy is the
+// ORDER BY clause applying to the SELECT clause, if any. The SELECT clause and
+// ORDER BY clause are returned unchanged if they use neither.
+func ExpandGroupingSets(
+	searchPath sessiondata.SearchPath, sel *SelectClause, orderBy OrderBy,
+) (*SelectClause, OrderBy, error) {
+	hasGroupingSets := false
+	for _, item := range sel.GroupBy {
+		if _, ok := item.(*GroupingSet); ok || isEmptyGroupingSet(item) {
+			hasGroupingSets = true
+			break
+		}
+	}
+	if sel.Where != nil && containsGroupingFunc(sel.Where.Expr) {
+		return nil, nil, pgerror.NewErrorf(pgerror.CodeGroupingError,
+			"GROUPING() is not allowed in WHERE")
+	}
+	if !hasGroupingSets && !selectContainsGroupingFunc(sel, orderBy) {
+		return sel, orderBy, nil
+	}
+
+	e := groupingSetsExpander{
+		searchPath: searchPath,
+		sel:        sel,
+		exprIdx:    make(map[string]int),
+	}
+	var err error
+	if hasGroupingSets {
+		err = e.expandGroupBy()
+	} else {
+		// A plain GROUP BY clause is a single grouping set.
+		var set util.FastIntSet
+		for _, item := range sel.GroupBy {
+			set.Add(e.addExpr(item))
+		}
+		e.sets = []util.FastIntSet{set}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return e.rewrite(orderBy)
+}
+
+// groupingSetsExpander holds the state of ExpandGroupingSets.
+type groupingSetsExpander struct {
+	searchPath sessiondata.SearchPath
+	sel        *SelectClause
+
+	// exprs are the distinct grouping expressions, and exprIdx maps their
+	// string representation to their index in exprs.
+	exprs   []Expr
+	exprIdx map[string]int
+	// sets are the grouping sets, as sets of indexes in exprs. Set i has
+	// number i+1.
+	sets []util.FastIntSet
+	// replacements[i] is the expression which replaces exprs[i] in the
+	// rewritten clause, or nil if exprs[i] is part of all the sets.
+	replacements []Expr
+	// setID refers to the number of the set of the current group.
+	setID Expr
+}
+
+// addExpr adds a grouping expression and returns its index.
+func (e *groupingSetsExpander) addExpr(expr Expr) int {
+	expr = StripParens(expr)
+	s := AsString(expr)
+	if i, ok := e.exprIdx[s]; ok {
+		return i
+	}
+	e.exprIdx[s] = len(e.exprs)
+	e.exprs = append(e.exprs, expr)
+	return len(e.exprs) - 1
+}
+
+// resolveOrdinal replaces a reference to an item of the SELECT list by its
+// position by the item, like the planners do for GROUP BY.
+func (e *groupingSetsExpander) resolveOrdinal(expr Expr) (Expr, error) {
+	n, ok := StripParens(expr).(*NumVal)
+	if !ok {
+		return expr, nil
+	}
+	pos, err := n.AsInt64()
+	if err != nil {
+		return expr, nil
+	}
+	if pos < 1 || pos > int64(len(e.sel.Exprs)) {
+		return nil, pgerror.NewErrorf(pgerror.CodeInvalidColumnReferenceError,
+			"GROUP BY position %d is not in select list", pos)
+	}
+	return e.sel.Exprs[pos-1].Expr, nil
+}
+
+// columnGroup returns the set of grouping expressions of an item of ROLLUP,
+// CUBE or GROUPING SETS. A tuple groups its expressions together.
+func (e *groupingSetsExpander) columnGroup(item Expr) (util.FastIntSet, error) {
+	var set util.FastIntSet
+	var exprs Exprs
+	if t, ok := StripParens(item).(*Tuple); ok && !t.Row {
+		exprs = t.Exprs
+	} else {
+		exprs = Exprs{item}
+	}
+	for _, expr := range exprs {
+		expr, err := e.resolveOrdinal(expr)
+		if err != nil {
+			return util.FastIntSet{}, err
+		}
+		set.Add(e.addExpr(expr))
+	}
+	return set, nil
+}
+
+// itemSets returns the grouping sets specified by an item of the GROUP BY
+// clause.
+func (e *groupingSetsExpander) itemSets(item Expr, topLevel bool) ([]util.FastIntSet, error) {
+	gs, ok := item.(*GroupingSet)
+	if !ok {
+		if isEmptyGroupingSet(item) {
+			return []util.FastIntSet{{}}, nil
+		}
+		if topLevel {
+			// An expression of the GROUP BY clause itself is a single grouping
+			// expression, even if it is a tuple.
+			expr, err := e.resolveOrdinal(item)
+			if err != nil {
+				return nil, err
+			}
+			var set util.FastIntSet
+			set.Add(e.addExpr(expr))
+			return []util.FastIntSet{set}, nil
+		}
+		set, err := e.columnGroup(item)
+		return []util.FastIntSet{set}, err
+	}
+
+	if gs.Type == ExplicitGroupingSets {
+		var sets []util.FastIntSet
+		for _, sub := range gs.Exprs {
+			subSets, err := e.itemSets(sub, false /* topLevel */)
+			if err != nil {
+				return nil, err
+			}
+			sets = append(sets, subSets...)
+		}
+		return sets, nil
+	}
+
+	groups := make([]util.FastIntSet, len(gs.Exprs))
+	for i := range gs.Exprs {
+		var err error
+		if groups[i], err = e.columnGroup(gs.Exprs[i]); err != nil {
+			return nil, err
+		}
+	}
+	var sets []util.FastIntSet
+	switch gs.Type {
+	case RollupGroupingSet:
+		// ROLLUP (a, b) is GROUPING SETS ((a, b), (a), ()).
+		for n := len(groups); n >= 0; n-- {
+			var set util.FastIntSet
+			for _, group := range groups[:n] {
+				set = set.Union(group)
+			}
+			sets = append(sets, set)
+		}
+
+	case CubeGroupingSet:
+		// CUBE (a, b) is GROUPING SETS ((a, b), (a), (b), ()).
+		if len(groups) > maxCubeItems {
+			return nil, pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+				"CUBE is limited to %d elements", maxCubeItems)
+		}
+		for mask := (1 << uint(len(groups))) - 1; mask >= 0; mask-- {
+			var set util.FastIntSet
+			for i, group := range groups {
+				if mask&(1<<uint(len(groups)-1-i)) != 0 {
+					set = set.Union(group)
+				}
+			}
+			sets = append(sets, set)
+		}
+
+	default:
+		return nil, pgerror.NewAssertionErrorf("unknown grouping set type %d", gs.Type)
+	}
+	return sets, nil
+}
+
+// expandGroupBy computes the grouping sets of the GROUP BY clause, which is
+// the cartesian product of the grouping sets of its items.
+func (e *groupingSetsExpander) expandGroupBy() error {
+	e.sets = []util.FastIntSet{{}}
+	for _, item := range e.sel.GroupBy {
+		itemSets, err := e.itemSets(item, true /* topLevel */)
+		if err != nil {
+			return err
+		}
+		if len(e.sets)*len(itemSets) > maxGroupingSets {
+			return pgerror.NewErrorf(pgerror.CodeStatementTooComplexError,
+				"too many grouping sets present")
+		}
+		product := make([]util.FastIntSet, 0, len(e.sets)*len(itemSets))
+		for _, set := range e.sets {
+			for _, itemSet := range itemSets {
+				product = append(product, set.Union(itemSet))
+			}
+		}
+		e.sets = product
+	}
+	return nil
+}
+
+// rewrite builds the rewritten SELECT and ORDER BY clauses.
+func (e *groupingSetsExpander) rewrite(orderBy OrderBy) (*SelectClause, OrderBy, error) {
+	sel := e.sel.copyNode()
+	e.replacements = make([]Expr, len(e.exprs))
+
+	if len(e.sets) == 1 {
+		sel.GroupBy = append(GroupBy(nil), e.exprs...)
+		if len(sel.GroupBy) == 0 && sel.Having == nil {
+			// GROUP BY () aggregates all the rows into one group, even if there
+			// are no aggregate functions.
+			sel.Having = &Where{Type: AstHaving, Expr: DBoolTrue}
+		}
+	} else {
+		e.setID = NewUnresolvedName(groupingSetsTableName, groupingSetsColumnName)
+		values := &ValuesClause{Rows: make([]Exprs, len(e.sets))}
+		for i := range e.sets {
+			values.Rows[i] = Exprs{NewDInt(DInt(i + 1))}
+		}
+		sel.From.Tables = append(sel.From.Tables, &AliasedTableExpr{
+			Expr: &Subquery{Select: &ParenSelect{Select: &Select{Select: values}}},
+			As: AliasClause{
+				Alias: groupingSetsTableName,
+				Cols:  NameList{groupingSetsColumnName},
+			},
+		})
+
+		sel.GroupBy = GroupBy{e.setID}
+		for i, expr := range e.exprs {
+			var ids Exprs
+			for j := range e.sets {
+				if e.sets[j].Contains(i) {
+					ids = append(ids, NewDInt(DInt(j+1)))
+				}
+			}
+			if len(ids) < len(e.sets) {
+				e.replacements[i] = &CaseExpr{Whens: []*When{{
+					Cond: &ComparisonExpr{Operator: In, Left: e.setID, Right: &Tuple{Exprs: ids}},
+					Val:  expr,
+				}}}
+				expr = e.replacements[i]
+			}
+			sel.GroupBy = append(sel.GroupBy, expr)
+		}
+	}
+
+	for i := range sel.Exprs {
+		expr, err := e.replace(sel.Exprs[i].Expr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if expr != sel.Exprs[i].Expr && sel.Exprs[i].As == "" {
+			// Keep the name the expression would have had.
+			name, err := GetRenderColName(e.searchPath, sel.Exprs[i])
+			if err != nil {
+				return nil, nil, err
+			}
+			sel.Exprs[i].As = UnrestrictedName(name)
+		}
+		sel.Exprs[i].Expr = expr
+	}
+	if sel.Having != nil {
+		expr, err := e.replace(sel.Having.Expr)
+		if err != nil {
+			return nil, nil, err
+		}
+		sel.Having.Expr = expr
+	}
+
+	var newOrderBy OrderBy
+	if orderBy != nil {
+		newOrderBy = make(OrderBy, len(orderBy))
+	}
+	for i, o := range orderBy {
+		newOrderBy[i] = o
+		if o.OrderType != OrderByColumn {
+			continue
+		}
+		if n, ok := o.Expr.(*UnresolvedName); ok && n.NumParts == 1 && !n.Star {
+			// The name may refer to an item of the SELECT list.
+			continue
+		}
+		expr, err := e.replace(o.Expr)
+		if err != nil {
+			return nil, nil, err
+		}
+		if expr != o.Expr {
+			oCopy := *o
+			oCopy.Expr = expr
+			newOrderBy[i] = &oCopy
+		}
+	}
+	return sel, newOrderBy, nil
+}
+
+// replace rewrites the grouping expressions and the calls to GROUPING() in
+// expr.
+func (e *groupingSetsExpander) replace(expr Expr) (Expr, error) {
+	v := groupingSetsReplacer{e: e}
+	newExpr, _ := WalkExpr(&v, expr)
+	return newExpr, v.err
+}
+
+// grouping returns the expression which replaces a call to GROUPING(). Its
+// value has one bit per argument, the first argument being the most
+// significant bit; the bit is set if the argument is not part of the grouping
+// set of the current group.
+func (e *groupingSetsExpander) grouping(f *FuncExpr) (Expr, error) {
+	args := make([]int, len(f.Exprs))
+	for i, arg := range f.Exprs {
+		idx, ok := e.exprIdx[AsString(StripParens(arg))]
+		if !ok {
+			return nil, pgerror.NewErrorf(pgerror.CodeGroupingError,
+				"arguments to GROUPING must be grouping expressions of the associated query level")
+		}
+		args[i] = idx
+	}
+	values := make([]DInt, len(e.sets))
+	for j, set := range e.sets {
+		for _, idx := range args {
+			values[j] <<= 1
+			if !set.Contains(idx) {
+				values[j] |= 1
+			}
+		}
+	}
+
+	same := true
+	for j := range values {
+		same = same && values[j] == values[0]
+	}
+	if same {
+		return NewDInt(values[0]), nil
+	}
+	c := &CaseExpr{Expr: e.setID, Whens: make([]*When, len(values))}
+	for j := range values {
+		c.Whens[j] = &When{Cond: NewDInt(DInt(j + 1)), Val: NewDInt(values[j])}
+	}
+	return c, nil
+}
+
+// groupingSetsReplacer is the Visitor used by groupingSetsExpander.replace.
+type groupingSetsReplacer struct {
+	e   *groupingSetsExpander
+	err error
+}
+
+var _ Visitor = &groupingSetsReplacer{}
+
+// VisitPre implements the Visitor interface.
+func (v *groupingSetsReplacer) VisitPre(expr Expr) (recurse bool, newExpr Expr) {
+	if v.err != nil {
+		return false, expr
+	}
+	switch t := expr.(type) {
+	case *FuncExpr:
+		if isGroupingFunc(t) {
+			newExpr, v.err = v.e.grouping(t)
+			if v.err != nil {
+				return false, expr
+			}
+			return false, newExpr
+		}
+		if t.WindowDef == nil {
+			// The arguments to an aggregate function refer to the input rows.
+			if def, err := t.Func.Resolve(v.e.searchPath); err == nil && def.Class == AggregateClass {
+				return false, expr
+			}
+		}
+
+	case *Subquery:
+		return false, expr
+	}
+	if v.e.setID != nil {
+		if i, ok := v.e.exprIdx[AsString(expr)]; ok && v.e.replacements[i] != nil {
+			return false, v.e.replacements[i]
+		}
+	}
+	return true, expr
+}
+
+// VisitPost implements the Visitor interface.
+func (*groupingSetsReplacer) VisitPost(expr Expr) Expr { return expr }
+
+// isEmptyGroupingSet returns whether an item of a GROUP BY clause or of
+// GROUPING SETS is the empty grouping set, ().
+func isEmptyGroupingSet(item Expr) bool {
+	t, ok := item.(*Tuple)
+	return ok && !t.Row && len(t.Exprs) == 0
+}
+
+// isGroupingFunc returns whether f is a call to GROUPING().
+func isGroupingFunc(f *FuncExpr) bool {
+	def, ok := f.Func.FunctionReference.(*FunctionDefinition)
+	return ok && def.Name == groupingFuncName
+}
+
+// groupingFuncFinder is a Visitor which looks for calls to GROUPING().
+type groupingFuncFinder struct {
+	found bool
+}
+
+var _ Visitor = &groupingFuncFinder{}
+
+// VisitPre implements the Visitor interface.
+func (v *groupingFuncFinder) VisitPre(expr Expr) (recurse bool, newExpr Expr) {
+	if v.found {
+		return false, expr
+	}
+	if f, ok := expr.(*FuncExpr); ok && isGroupingFunc(f) {
+		v.found = true
+		return false, expr
+	}
+	return true, expr
+}
+
+// VisitPost implements the Visitor interface.
+func (*groupingFuncFinder) VisitPost(expr Expr) Expr { return expr }
+
+func containsGroupingFunc(expr Expr) bool {
+	var v groupingFuncFinder
+	WalkExprConst(&v, expr)
+	return v.found
+}
+
+func selectContainsGroupingFunc(sel *SelectClause, orderBy OrderBy) bool {
+	for _, expr := range sel.Exprs {
+		if containsGroupingFunc(expr.Expr) {
+			return true
+		}
+	}
+	if sel.Having != nil && containsGroupingFunc(sel.Having.Expr) {
+		return true
+	}
+	for _, o := range orderBy {
+		if o.OrderType == OrderByColumn && containsGroupingFunc(o.Expr) {
+			return true
+		}
+	}
+	return false
+}
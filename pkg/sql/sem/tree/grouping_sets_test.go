@@ -0,0 +1,129 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	_ "github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestExpandGroupingSets(t *testing.T) {
+	testData := []struct {
+		sql      string
+		expected string
+		err      string
+	}{
+		{sql: `SELECT a, sum(b) FROM t GROUP BY a`, expected: `SELECT a, sum(b) FROM t GROUP BY a`},
+		{
+			sql: `SELECT a, b, sum(c) FROM t GROUP BY ROLLUP (a, b)`,
+			expected: `SELECT CASE WHEN crdb_internal_grouping_sets.set_id IN (1, 2) THEN a END AS a,
+			  CASE WHEN crdb_internal_grouping_sets.set_id IN (1,) THEN b END AS b, sum(c)
+			FROM t, (VALUES (1), (2), (3)) AS crdb_internal_grouping_sets (set_id)
+			GROUP BY crdb_internal_grouping_sets.set_id,
+			  CASE WHEN crdb_internal_grouping_sets.set_id IN (1, 2) THEN a END,
+			  CASE WHEN crdb_internal_grouping_sets.set_id IN (1,) THEN b END`,
+		},
+		{
+			sql: `SELECT a, grouping(a, b), count(*) FROM t GROUP BY CUBE (a, b)`,
+			expected: `SELECT CASE WHEN crdb_internal_grouping_sets.set_id IN (1, 2) THEN a END AS a,
+			  CASE crdb_internal_grouping_sets.set_id WHEN 1 THEN 0 WHEN 2 THEN 1 WHEN 3 THEN 2 WHEN 4 THEN 3 END AS grouping,
+			  count(*)
+			FROM t, (VALUES (1), (2), (3), (4)) AS crdb_internal_grouping_sets (set_id)
+			GROUP BY crdb_internal_grouping_sets.set_id,
+			  CASE WHEN crdb_internal_grouping_sets.set_id IN (1, 2) THEN a END,
+			  CASE WHEN crdb_internal_grouping_sets.set_id IN (1, 3) THEN b END`,
+		},
+		{
+			sql: `SELECT a, b FROM t GROUP BY a, GROUPING SETS ((b), ())`,
+			expected: `SELECT a, CASE WHEN crdb_internal_grouping_sets.set_id IN (1,) THEN b END AS b
+			FROM t, (VALUES (1), (2)) AS crdb_internal_grouping_sets (set_id)
+			GROUP BY crdb_internal_grouping_sets.set_id, a,
+			  CASE WHEN crdb_internal_grouping_sets.set_id IN (1,) THEN b END`,
+		},
+		{
+			sql: `SELECT a, sum(a) FROM t GROUP BY ROLLUP (1) ORDER BY a, a + 1`,
+			expected: `SELECT CASE WHEN crdb_internal_grouping_sets.set_id IN (1,) THEN a END AS a, sum(a)
+			FROM t, (VALUES (1), (2)) AS crdb_internal_grouping_sets (set_id)
+			GROUP BY crdb_internal_grouping_sets.set_id,
+			  CASE WHEN crdb_internal_grouping_sets.set_id IN (1,) THEN a END
+			ORDER BY a, CASE WHEN crdb_internal_grouping_sets.set_id IN (1,) THEN a END + 1`,
+		},
+		{
+			sql:      `SELECT a, grouping(a) FROM t GROUP BY a HAVING grouping(a) = 0`,
+			expected: `SELECT a, 0 AS grouping FROM t GROUP BY a HAVING 0 = 0`,
+		},
+		{sql: `SELECT count(*) FROM t GROUP BY ()`, expected: `SELECT count(*) FROM t HAVING true`},
+
+		{
+			sql: `SELECT grouping(b) FROM t GROUP BY a`,
+			err: `arguments to GROUPING must be grouping expressions of the associated query level`,
+		},
+		{
+			sql: `SELECT a FROM t WHERE grouping(a) = 0 GROUP BY a`,
+			err: `GROUPING\(\) is not allowed in WHERE`,
+		},
+		{
+			sql: `SELECT a FROM t GROUP BY ROLLUP (2)`,
+			err: `GROUP BY position 2 is not in select list`,
+		},
+		{
+			sql: `SELECT 1 FROM t GROUP BY CUBE (a, b, c, d, e, f, g, h, i, j, k, l, m)`,
+			err: `CUBE is limited to 12 elements`,
+		},
+	}
+
+	searchPath := sessiondata.MakeSearchPath([]string{"pg_catalog"})
+	parse := func(t *testing.T, sql string) *tree.Select {
+		t.Helper()
+		stmt, err := parser.ParseOne(sql)
+		if err != nil {
+			t.Fatalf("%s: %v", sql, err)
+		}
+		return stmt.AST.(*tree.Select)
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			stmt := parse(t, d.sql)
+			before := stmt.String()
+			sel := stmt.Select.(*tree.SelectClause)
+			newSel, orderBy, err := tree.ExpandGroupingSets(searchPath, sel, stmt.OrderBy)
+			if d.err != "" {
+				if !testutils.IsError(err, d.err) {
+					t.Fatalf("expected %s, but found %v", d.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			res := &tree.Select{Select: newSel, OrderBy: orderBy}
+			if s := res.String(); s != parse(t, d.expected).String() {
+				t.Errorf("expected %s, got %s", parse(t, d.expected), s)
+			}
+			// The input tree must not be modified.
+			if s := stmt.String(); s != before {
+				t.Errorf("input modified: expected %s, got %s", before, s)
+			}
+			if d.sql == d.expected && newSel != sel {
+				t.Errorf("expected the input to be returned as is when there are no grouping sets")
+			}
+		})
+	}
+}
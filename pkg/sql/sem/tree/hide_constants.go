@@ -18,12 +18,15 @@ package tree
 
 import (
 	"fmt"
+	"hash/fnv"
 	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 )
 
 // formatNodeOrHideConstants recurses into a node for pretty-printing,
-// unless hideConstants is set in the flags and the node is a datum or
-// a literal.
+// unless hideConstants (or the redaction of constants) is set in the flags
+// and the node is a datum or a literal.
 func (ctx *FmtCtx) formatNodeOrHideConstants(n NodeFormatter) {
 	if ctx.flags.HasFlags(FmtHideConstants) {
 		switch v := n.(type) {
@@ -44,9 +47,48 @@ func (ctx *FmtCtx) formatNodeOrHideConstants(n NodeFormatter) {
 			return
 		}
 	}
+	if ctx.redactConstants() {
+		switch n.(type) {
+		case *Placeholder, dNull:
+			// Placeholders and NULL do not disclose any data.
+		case *DTuple, *DArray:
+			// The elements are redacted individually.
+		case Datum, Constant:
+			ctx.formatRedacted(AsStringWithFlags(n, FmtSimple))
+			return
+		}
+	}
 	n.Format(ctx)
 }
 
+// redactConstants returns true if the literals must be redacted.
+func (ctx *FmtCtx) redactConstants() bool {
+	return ctx.flags.HasFlags(FmtRedactConstants) || ctx.flags.HasFlags(FmtHashConstants)
+}
+
+// formatRedacted prints the replacement of a redacted literal, given the
+// literal's representation.
+func (ctx *FmtCtx) formatRedacted(repr string) {
+	if !ctx.flags.HasFlags(FmtHashConstants) {
+		ctx.WriteByte('_')
+		return
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(repr))
+	ctx.Printf("_:%08x", h.Sum32())
+}
+
+// formatStringLiteral prints a string literal which is not a node of the
+// AST (e.g. a comment). The string is redacted like the constants of the
+// AST if FmtRedactConstants or FmtHashConstants is set.
+func (ctx *FmtCtx) formatStringLiteral(s string) {
+	if ctx.redactConstants() {
+		ctx.formatRedacted(lex.EscapeSQLString(s))
+		return
+	}
+	lex.EncodeSQLStringWithFlags(&ctx.Buffer, s, ctx.flags.EncodeFlags())
+}
+
 // formatHideConstants shortens multi-valued VALUES clauses to a
 // VALUES clause with a single value.
 // e.g. VALUES (a,b,c), (d,e,f) -> VALUES (_, _, _), (__more__)
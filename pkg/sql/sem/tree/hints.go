@@ -0,0 +1,100 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "strconv"
+
+// StatementHints are the optimizer hints of a statement, specified in
+// /*+ ... */ comments. The tables are referred to by the names under which
+// they appear in the statement, i.e. their alias if they have one.
+type StatementHints []StatementHint
+
+// Format implements the NodeFormatter interface.
+func (node *StatementHints) Format(ctx *FmtCtx) {
+	ctx.WriteString("/*+")
+	for _, h := range *node {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(h)
+	}
+	ctx.WriteString(" */")
+}
+
+// StatementHint is an optimizer hint.
+type StatementHint interface {
+	NodeFormatter
+	statementHint()
+}
+
+func (*IndexHint) statementHint() {}
+func (*JoinHint) statementHint()  {}
+func (*RowsHint) statementHint()  {}
+
+// IndexHint is INDEX(<table> <index>): the table is scanned using the given
+// index.
+type IndexHint struct {
+	Table Name
+	Index UnrestrictedName
+}
+
+// Format implements the NodeFormatter interface.
+func (node *IndexHint) Format(ctx *FmtCtx) {
+	ctx.WriteString("INDEX(")
+	ctx.FormatNode(&node.Table)
+	ctx.WriteByte(' ')
+	ctx.FormatNode(&node.Index)
+	ctx.WriteByte(')')
+}
+
+// JoinHint is <method>_JOIN(<table> <table>...): the given tables are joined
+// using the given method.
+type JoinHint struct {
+	// Method is one of AstHash, AstLookup and AstMerge.
+	Method string
+	Tables NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *JoinHint) Format(ctx *FmtCtx) {
+	ctx.WriteString(node.Method)
+	ctx.WriteString("_JOIN(")
+	formatHintTables(ctx, node.Tables)
+	ctx.WriteByte(')')
+}
+
+// RowsHint is ROWS(<table>... <rows>): the join of the given tables, or the
+// given table if there is only one, is estimated to produce the given number
+// of rows.
+type RowsHint struct {
+	Tables NameList
+	Rows   int64
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RowsHint) Format(ctx *FmtCtx) {
+	ctx.WriteString("ROWS(")
+	formatHintTables(ctx, node.Tables)
+	ctx.WriteByte(' ')
+	ctx.WriteString(strconv.FormatInt(node.Rows, 10))
+	ctx.WriteByte(')')
+}
+
+func formatHintTables(ctx *FmtCtx, tables NameList) {
+	for i := range tables {
+		if i > 0 {
+			ctx.WriteByte(' ')
+		}
+		ctx.FormatNode(&tables[i])
+	}
+}
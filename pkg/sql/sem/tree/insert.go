@@ -28,6 +28,7 @@ type Insert struct {
 	With       *With
 	Table      TableExpr
 	Columns    NameList
+	Overriding OverridingKind
 	Rows       *Select
 	OnConflict *OnConflict
 	Returning  ReturningClause
@@ -48,6 +49,10 @@ func (node *Insert) Format(ctx *FmtCtx) {
 		ctx.FormatNode(&node.Columns)
 		ctx.WriteByte(')')
 	}
+	if node.Overriding != OverridingNone {
+		ctx.WriteString(" OVERRIDING ")
+		ctx.WriteString(node.Overriding.String())
+	}
 	if node.DefaultValues() {
 		ctx.WriteString(" DEFAULT VALUES")
 	} else {
@@ -83,6 +88,31 @@ func (node *Insert) DefaultValues() bool {
 	return node.Rows.Select == nil
 }
 
+// OverridingKind represents the OVERRIDING clause of an INSERT statement,
+// which controls the values inserted in identity columns.
+type OverridingKind int
+
+const (
+	// OverridingNone is used when there is no OVERRIDING clause.
+	OverridingNone OverridingKind = iota
+	// OverridingSystemValue allows the statement to provide values for the
+	// GENERATED ALWAYS AS IDENTITY columns.
+	OverridingSystemValue
+	// OverridingUserValue makes the statement ignore the values provided for
+	// the identity columns, which are generated instead.
+	OverridingUserValue
+)
+
+var overridingKindName = [...]string{
+	OverridingNone:        "",
+	OverridingSystemValue: "SYSTEM VALUE",
+	OverridingUserValue:   "USER VALUE",
+}
+
+func (k OverridingKind) String() string {
+	return overridingKindName[k]
+}
+
 // OnConflict represents an `ON CONFLICT (columns) DO UPDATE SET exprs WHERE
 // where` clause.
 //
@@ -100,3 +130,35 @@ type OnConflict struct {
 func (oc *OnConflict) IsUpsertAlias() bool {
 	return oc != nil && oc.Columns == nil && oc.Exprs == nil && oc.Where == nil && !oc.DoNothing
 }
+
+// ExcludedPassthroughCols returns the names of the columns assigned by a DO
+// UPDATE SET clause in which every assignment has the form `c = excluded.c`
+// and there is no WHERE clause. An ON CONFLICT clause of this shape
+// overwrites conflicting rows with the inserted values, exactly like the
+// UPSERT short form, so it is a candidate for the same blind-write fast
+// path. ok is false if the clause has any other shape; columns assigned more
+// than once are reported as not ok, so that the regular error path can deal
+// with them.
+func (oc *OnConflict) ExcludedPassthroughCols() (cols NameList, ok bool) {
+	if oc == nil || oc.DoNothing || oc.Where != nil || len(oc.Exprs) == 0 {
+		return nil, false
+	}
+	cols = make(NameList, 0, len(oc.Exprs))
+	seen := make(map[Name]struct{}, len(oc.Exprs))
+	for _, ue := range oc.Exprs {
+		if ue.Tuple || len(ue.Names) != 1 {
+			return nil, false
+		}
+		ref, isName := ue.Expr.(*UnresolvedName)
+		if !isName || ref.Star || ref.NumParts != 2 ||
+			ref.Parts[1] != "excluded" || ref.Parts[0] != string(ue.Names[0]) {
+			return nil, false
+		}
+		if _, dup := seen[ue.Names[0]]; dup {
+			return nil, false
+		}
+		seen[ue.Names[0]] = struct{}{}
+		cols = append(cols, ue.Names[0])
+	}
+	return cols, true
+}
@@ -206,6 +206,12 @@ func MakeUnresolvedName(args ...string) UnresolvedName {
 	return n
 }
 
+// ToTableName converts the unresolved name of a user-defined function to a
+// table name, so that its prefix can be resolved like that of a table.
+func (u *UnresolvedName) ToTableName() TableName {
+	return makeTableNameFromUnresolvedName(u)
+}
+
 // UnresolvedObjectName is an unresolved qualified name for a database object
 // (table, view, etc). It is like UnresolvedName but more restrictive.
 // It should only be constructed via NewUnresolvedObjectName.
@@ -413,17 +413,13 @@ func (tp *TableNamePrefix) Resolve(
 
 // ResolveFunction transforms an UnresolvedName to a FunctionDefinition.
 //
-// Function resolution currently takes a "short path" using the
-// assumption that there are no stored functions in the database. That
-// is, only functions in the (virtual) global namespace and virtual
-// schemas can be used. This in turn implies that the current
-// database does not matter and no resolver is needed.
-//
-// TODO(whoever): this needs to be revisited when there can be stored functions.
-// When that is the case, function names must be first normalized to e.g.
-// TableName (or whatever an object name will be called by then)
-// and then undergo regular name resolution via ResolveExisting(). When
-// that happens, the following function can be removed.
+// Function resolution takes a "short path" for built-in functions: they
+// are in the (virtual) global namespace and virtual schemas, so the
+// current database does not matter and no resolver is needed. The names
+// of user-defined functions, which are stored in their database, are
+// resolved by the planner before type checking, and the references to
+// them replaced by their definitions; this method only finds the
+// built-in functions.
 func (n *UnresolvedName) ResolveFunction(
 	searchPath sessiondata.SearchPath,
 ) (*FunctionDefinition, error) {
@@ -435,14 +431,43 @@ func (n *UnresolvedName) ResolveFunction(
 			"invalid function name: %s", n)
 	}
 
-	// We ignore the catalog part. Like explained above, we currently
-	// only support functions in virtual schemas, which always exist
+	if def := n.ResolveBuiltinFunction(searchPath); def != nil {
+		return def, nil
+	}
+
+	function := n.Parts[0]
+	extraMsg := ""
+	// Try a little harder.
+	if rdef, ok := FunDefs[strings.ToLower(function)]; ok {
+		extraMsg = fmt.Sprintf(", but %s() exists", rdef.Name)
+	}
+	err := pgerror.NewErrorf(
+		pgerror.CodeUndefinedFunctionError, "unknown function: %s()%s", ErrString(n), extraMsg)
+	if extraMsg == "" {
+		if suggestion := SuggestFunctionName(function); suggestion != "" {
+			err.Hint = fmt.Sprintf("did you mean %s()?", suggestion)
+		}
+	}
+	return nil, err
+}
+
+// ResolveBuiltinFunction returns the built-in function with the given
+// name, or nil if there is no such function. Unlike ResolveFunction, it
+// reports no error for unknown names, so it can be used to find out
+// whether a name can refer to a user-defined function.
+func (n *UnresolvedName) ResolveBuiltinFunction(searchPath sessiondata.SearchPath) *FunctionDefinition {
+	if n.NumParts > 3 || len(n.Parts[0]) == 0 || n.Star {
+		return nil
+	}
+
+	// We ignore the catalog part. Like explained above, built-in
+	// functions are only in virtual schemas, which always exist
 	// independently of the database/catalog prefix.
 	function, prefix := n.Parts[0], n.Parts[1]
 
 	if d, ok := FunDefs[function]; ok && prefix == "" {
 		// Fast path: return early.
-		return d, nil
+		return d
 	}
 
 	fullName := function
@@ -456,33 +481,20 @@ func (n *UnresolvedName) ResolveFunction(
 	if prefix != "" {
 		fullName = prefix + "." + function
 	}
-	def, ok := FunDefs[fullName]
-	if !ok {
-		found := false
-		if prefix == "" {
-			// The function wasn't qualified, so we must search for it via
-			// the search path first.
-			iter := searchPath.Iter()
-			for alt, ok := iter.Next(); ok; alt, ok = iter.Next() {
-				fullName = alt + "." + function
-				if def, ok = FunDefs[fullName]; ok {
-					found = true
-					break
-				}
-			}
-		}
-		if !found {
-			extraMsg := ""
-			// Try a little harder.
-			if rdef, ok := FunDefs[strings.ToLower(function)]; ok {
-				extraMsg = fmt.Sprintf(", but %s() exists", rdef.Name)
+	if def, ok := FunDefs[fullName]; ok {
+		return def
+	}
+	if prefix == "" {
+		// The function wasn't qualified, so we must search for it via
+		// the search path first.
+		iter := searchPath.Iter()
+		for alt, ok := iter.Next(); ok; alt, ok = iter.Next() {
+			if def, ok := FunDefs[alt+"."+function]; ok {
+				return def
 			}
-			return nil, pgerror.NewErrorf(
-				pgerror.CodeUndefinedFunctionError, "unknown function: %s()%s", ErrString(n), extraMsg)
 		}
 	}
-
-	return def, nil
+	return nil
 }
 
 func newInvColRef(fmt string, n *UnresolvedName) error {
@@ -64,6 +64,12 @@ func (pt PlaceholderTypes) AssertAllSet() error {
 type QueryArguments []TypedExpr
 
 func (qa QueryArguments) String() string {
+	return qa.StringWithFlags(FmtSimple)
+}
+
+// StringWithFlags is like String, formatting the arguments with the given
+// flags.
+func (qa QueryArguments) StringWithFlags(f FmtFlags) string {
 	if len(qa) == 0 {
 		return "{}"
 	}
@@ -71,7 +77,7 @@ func (qa QueryArguments) String() string {
 	buf.WriteByte('{')
 	sep := ""
 	for k, v := range qa {
-		fmt.Fprintf(&buf, "%s%s:%q", sep, types.PlaceholderIdx(k), v)
+		fmt.Fprintf(&buf, "%s%s:%q", sep, types.PlaceholderIdx(k), AsStringWithFlags(v, f))
 		sep = ", "
 	}
 	buf.WriteByte('}')
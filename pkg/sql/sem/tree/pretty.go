@@ -470,6 +470,23 @@ func (node *Limit) docTable(p *PrettyCfg) []pretty.RLTableRow {
 		return nil
 	}
 	res := make([]pretty.RLTableRow, 0, 2)
+	if node.WithTies {
+		// WITH TIES can only be expressed with the SQL:2008 syntax.
+		if node.Offset != nil {
+			e := node.Offset
+			if p.Simplify {
+				e = StripParens(e)
+			}
+			res = append(res, p.row("OFFSET", p.Doc(e)))
+		}
+		e := node.Count
+		if p.Simplify {
+			e = StripParens(e)
+		}
+		res = append(res, p.row("FETCH FIRST",
+			pretty.ConcatSpace(p.Doc(fetchFirstValue(e)), pretty.Keyword("ROWS WITH TIES"))))
+		return res
+	}
 	if node.Count != nil {
 		e := node.Count
 		if p.Simplify {
@@ -504,6 +521,25 @@ func (node *OrderBy) docRow(p *PrettyCfg) pretty.RLTableRow {
 	return p.row("ORDER BY", pretty.Join(",", d...))
 }
 
+func (node *LockingClause) docTable(p *PrettyCfg) []pretty.RLTableRow {
+	items := make([]pretty.RLTableRow, len(*node))
+	for i, n := range *node {
+		items[i] = p.row("", n.doc(p))
+	}
+	return items
+}
+
+func (node *LockingItem) doc(p *PrettyCfg) pretty.Doc {
+	d := pretty.Keyword(node.Strength.String())
+	if len(node.Targets) > 0 {
+		d = pretty.ConcatSpace(d, pretty.ConcatSpace(pretty.Keyword("OF"), p.Doc(&node.Targets)))
+	}
+	if node.WaitPolicy != LockWaitBlock {
+		d = pretty.ConcatSpace(d, pretty.Keyword(node.WaitPolicy.String()))
+	}
+	return d
+}
+
 func (node *Select) doc(p *PrettyCfg) pretty.Doc {
 	return p.rlTable(node.docTable(p)...)
 }
@@ -518,6 +554,7 @@ func (node *Select) docTable(p *PrettyCfg) []pretty.RLTableRow {
 	}
 	items = append(items, node.OrderBy.docRow(p))
 	items = append(items, node.Limit.docTable(p)...)
+	items = append(items, node.Locking.docTable(p)...)
 	return items
 }
 
@@ -594,12 +631,24 @@ func (node *With) docRow(p *PrettyCfg) pretty.RLTableRow {
 	}
 	d := make([]pretty.Doc, len(node.CTEList))
 	for i, cte := range node.CTEList {
+		asKw := "AS"
+		if cte.Mtr.Set {
+			if cte.Mtr.Materialize {
+				asKw = "AS MATERIALIZED"
+			} else {
+				asKw = "AS NOT MATERIALIZED"
+			}
+		}
 		d[i] = p.nestUnder(
 			p.Doc(&cte.Name),
-			prettyBracketKeyword("AS", " (", p.Doc(cte.Stmt), ")", ""),
+			prettyBracketKeyword(asKw, " (", p.Doc(cte.Stmt), ")", ""),
 		)
 	}
-	return p.row("WITH", pretty.Join(",", d...))
+	kw := "WITH"
+	if node.Recursive {
+		kw = "WITH RECURSIVE"
+	}
+	return p.row(kw, pretty.Join(",", d...))
 }
 
 func (node *Subquery) doc(p *PrettyCfg) pretty.Doc {
@@ -618,6 +667,9 @@ func (node *Subquery) doc(p *PrettyCfg) pretty.Doc {
 
 func (node *AliasedTableExpr) doc(p *PrettyCfg) pretty.Doc {
 	d := p.Doc(node.Expr)
+	if node.Lateral {
+		d = pretty.Concat(prettyKeywordWithText("", "LATERAL", " "), d)
+	}
 	if node.IndexFlags != nil {
 		d = pretty.Concat(
 			d,
@@ -639,6 +691,29 @@ func (node *AliasedTableExpr) doc(p *PrettyCfg) pretty.Doc {
 			),
 		)
 	}
+	if node.Sample != nil {
+		d = p.nestUnder(d, p.Doc(node.Sample))
+	}
+	return d
+}
+
+func (node *TableSample) doc(p *PrettyCfg) pretty.Doc {
+	d := pretty.ConcatSpace(
+		pretty.Keyword("TABLESAMPLE"),
+		pretty.ConcatSpace(
+			pretty.Keyword(node.Method.String()),
+			pretty.Bracket("(", p.Doc(node.Percent), ")"),
+		),
+	)
+	if node.Repeatable != nil {
+		d = pretty.ConcatSpace(
+			d,
+			pretty.ConcatSpace(
+				pretty.Keyword("REPEATABLE"),
+				pretty.Bracket("(", p.Doc(node.Repeatable), ")"),
+			),
+		)
+	}
 	return d
 }
 
@@ -657,6 +732,12 @@ func (node *FuncExpr) doc(p *PrettyCfg) pretty.Doc {
 	} else {
 		d = pretty.Concat(d, pretty.Text("()"))
 	}
+	if node.AggType == OrderedSetAgg {
+		d = pretty.Fold(pretty.ConcatSpace,
+			d,
+			pretty.Keyword("WITHIN GROUP"),
+			pretty.Bracket("(", p.Doc(&node.OrderBy), ")"))
+	}
 	if node.Filter != nil {
 		d = pretty.Fold(pretty.ConcatSpace,
 			d,
@@ -715,6 +796,9 @@ func (wf *WindowFrame) docRow(p *PrettyCfg) pretty.RLTableRow {
 			p.row("AND", p.Doc(wf.Bounds.EndBound)),
 		)
 	}
+	if wf.Exclusion != NoExclusion {
+		d = pretty.Stack(d, pretty.Keyword(AsString(wf.Exclusion)))
+	}
 	return p.row(kw, d)
 }
 
@@ -834,6 +918,10 @@ func (node *Insert) doc(p *PrettyCfg) pretty.Doc {
 	}
 	items = append(items, p.row("INTO", into))
 
+	if node.Overriding != OverridingNone {
+		items = append(items, p.row("OVERRIDING", pretty.Keyword(node.Overriding.String())))
+	}
+
 	if node.DefaultValues() {
 		items = append(items, p.row("", pretty.Keyword("DEFAULT VALUES")))
 	} else {
@@ -1110,13 +1198,17 @@ func (node *CreateView) doc(p *PrettyCfg) pretty.Doc {
 			pretty.Bracket("(", p.Doc(&node.ColumnNames), ")"),
 		)
 	}
-	return p.nestUnder(
+	d = p.nestUnder(
 		d,
 		p.nestUnder(
 			pretty.Keyword("AS"),
 			p.Doc(node.AsSource),
 		),
 	)
+	if node.CheckOption {
+		d = pretty.Stack(d, pretty.Keyword("WITH CHECK OPTION"))
+	}
+	return d
 }
 
 func (node *TableDefs) doc(p *PrettyCfg) pretty.Doc {
@@ -1412,12 +1504,27 @@ func (node *ColumnTableDef) doc(p *PrettyCfg) pretty.Doc {
 		docs = append(docs, d)
 	}
 	if node.IsComputed() {
+		kw := "STORED"
+		if node.Computed.Virtual {
+			kw = "VIRTUAL"
+		}
 		docs = append(docs, prettyBracketKeyword(
 			"AS", " (",
 			p.Doc(node.Computed.Expr),
-			") ", "STORED",
+			") ", kw,
 		))
 	}
+	if node.IsIdentity() {
+		kw := "GENERATED BY DEFAULT AS IDENTITY"
+		if node.Identity.Always {
+			kw = "GENERATED ALWAYS AS IDENTITY"
+		}
+		d := pretty.Keyword(kw)
+		if len(node.Identity.SeqOptions) > 0 {
+			d = pretty.ConcatSpace(d, pretty.Bracket("(", p.Doc(&node.Identity.SeqOptions), ")"))
+		}
+		docs = append(docs, d)
+	}
 	if node.HasColumnFamily() {
 		d := pretty.Nil
 		if node.Family.Create {
@@ -29,14 +29,22 @@ import "github.com/cockroachdb/cockroach/pkg/sql/privilege"
 // PrivilegeList and TargetList are defined in grant.go
 type Revoke struct {
 	Privileges privilege.List
-	Targets    TargetList
-	Grantees   NameList
+	// Columns is the list of columns the privileges are revoked from, for
+	// column-level privileges. See Grant.
+	Columns  NameList
+	Targets  TargetList
+	Grantees NameList
 }
 
 // Format implements the NodeFormatter interface.
 func (node *Revoke) Format(ctx *FmtCtx) {
 	ctx.WriteString("REVOKE ")
 	node.Privileges.Format(&ctx.Buffer)
+	if node.Columns != nil {
+		ctx.WriteString(" (")
+		ctx.FormatNode(&node.Columns)
+		ctx.WriteByte(')')
+	}
 	ctx.WriteString(" ON ")
 	ctx.FormatNode(&node.Targets)
 	ctx.WriteString(" FROM ")
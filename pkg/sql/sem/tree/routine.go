@@ -0,0 +1,388 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import "github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+
+// RoutineBlock is a block in the PL/pgSQL body of a routine:
+//
+//   [DECLARE <declarations>]
+//   BEGIN
+//     <statements>
+//   END
+//
+// The variables declared by the block, and the parameters of the routine, can
+// be referenced by name in the expressions and SQL statements of the block.
+// The parameters can also be referenced by position, as $1, $2, etc.
+type RoutineBlock struct {
+	Decls []RoutineDecl
+	Stmts []RoutineStmt
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineBlock) Format(ctx *FmtCtx) {
+	if len(node.Decls) > 0 {
+		ctx.WriteString("DECLARE ")
+		for i := range node.Decls {
+			ctx.FormatNode(&node.Decls[i])
+			ctx.WriteString("; ")
+		}
+	}
+	ctx.WriteString("BEGIN ")
+	formatRoutineStmts(ctx, node.Stmts)
+	ctx.WriteString("END")
+}
+
+func formatRoutineStmts(ctx *FmtCtx, stmts []RoutineStmt) {
+	for _, stmt := range stmts {
+		ctx.FormatNode(stmt)
+		ctx.WriteString("; ")
+	}
+}
+
+// RoutineDecl is the declaration of a variable in a RoutineBlock:
+//
+//   <name> [CONSTANT] <type> [NOT NULL] [:= <expr>]
+type RoutineDecl struct {
+	Name     Name
+	Constant bool
+	Type     coltypes.CastTargetType
+	NotNull  bool
+	// Default is the initial value of the variable, or nil if the variable is
+	// initially NULL.
+	Default Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineDecl) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.Name)
+	if node.Constant {
+		ctx.WriteString(" CONSTANT")
+	}
+	ctx.WriteByte(' ')
+	node.Type.Format(&ctx.Buffer, ctx.flags.EncodeFlags())
+	if node.NotNull {
+		ctx.WriteString(" NOT NULL")
+	}
+	if node.Default != nil {
+		ctx.WriteString(" := ")
+		ctx.FormatNode(node.Default)
+	}
+}
+
+// RoutineStmt is a statement in the body of a routine.
+type RoutineStmt interface {
+	NodeFormatter
+	routineStmt()
+}
+
+func (*RoutineBlock) routineStmt()  {}
+func (*RoutineAssign) routineStmt() {}
+func (*RoutineIf) routineStmt()     {}
+func (*RoutineLoop) routineStmt()   {}
+func (*RoutineExit) routineStmt()   {}
+func (*RoutineReturn) routineStmt() {}
+func (*RoutineRaise) routineStmt()  {}
+func (*RoutineExec) routineStmt()   {}
+
+// RoutineAssign represents the assignment of a value to a variable:
+// <name> := <expr>.
+type RoutineAssign struct {
+	Var  Name
+	Expr Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineAssign) Format(ctx *FmtCtx) {
+	ctx.FormatNode(&node.Var)
+	ctx.WriteString(" := ")
+	ctx.FormatNode(node.Expr)
+}
+
+// RoutineIf represents an IF statement:
+//
+//   IF <cond> THEN <stmts>
+//   [ELSIF <cond> THEN <stmts> ...]
+//   [ELSE <stmts>]
+//   END IF
+type RoutineIf struct {
+	Cond    Expr
+	Then    []RoutineStmt
+	ElseIfs []RoutineElseIf
+	Else    []RoutineStmt
+}
+
+// RoutineElseIf represents an ELSIF branch of a RoutineIf.
+type RoutineElseIf struct {
+	Cond  Expr
+	Stmts []RoutineStmt
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineIf) Format(ctx *FmtCtx) {
+	ctx.WriteString("IF ")
+	ctx.FormatNode(node.Cond)
+	ctx.WriteString(" THEN ")
+	formatRoutineStmts(ctx, node.Then)
+	for i := range node.ElseIfs {
+		ctx.WriteString("ELSIF ")
+		ctx.FormatNode(node.ElseIfs[i].Cond)
+		ctx.WriteString(" THEN ")
+		formatRoutineStmts(ctx, node.ElseIfs[i].Stmts)
+	}
+	if node.Else != nil {
+		ctx.WriteString("ELSE ")
+		formatRoutineStmts(ctx, node.Else)
+	}
+	ctx.WriteString("END IF")
+}
+
+// RoutineLoop represents a loop:
+//
+//   [WHILE <cond>] LOOP <stmts> END LOOP
+//
+// Without a condition, the loop runs until it is left with EXIT or RETURN.
+type RoutineLoop struct {
+	// While is the condition of a WHILE loop, or nil.
+	While Expr
+	Stmts []RoutineStmt
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineLoop) Format(ctx *FmtCtx) {
+	if node.While != nil {
+		ctx.WriteString("WHILE ")
+		ctx.FormatNode(node.While)
+		ctx.WriteByte(' ')
+	}
+	ctx.WriteString("LOOP ")
+	formatRoutineStmts(ctx, node.Stmts)
+	ctx.WriteString("END LOOP")
+}
+
+// RoutineExit represents an EXIT or CONTINUE statement, which respectively
+// leaves the innermost loop or starts its next iteration:
+//
+//   {EXIT | CONTINUE} [WHEN <cond>]
+type RoutineExit struct {
+	Continue bool
+	// When is the condition under which the statement takes effect, or nil.
+	When Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineExit) Format(ctx *FmtCtx) {
+	if node.Continue {
+		ctx.WriteString("CONTINUE")
+	} else {
+		ctx.WriteString("EXIT")
+	}
+	if node.When != nil {
+		ctx.WriteString(" WHEN ")
+		ctx.FormatNode(node.When)
+	}
+}
+
+// RoutineReturn represents a RETURN statement, which ends the execution of
+// the routine.
+type RoutineReturn struct {
+	// Expr is the returned value, or nil.
+	Expr Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineReturn) Format(ctx *FmtCtx) {
+	ctx.WriteString("RETURN")
+	if node.Expr != nil {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(node.Expr)
+	}
+}
+
+// RoutineRaise represents a RAISE EXCEPTION statement, which aborts the
+// routine with an error. Every % in the message is replaced by the value of
+// the corresponding argument.
+type RoutineRaise struct {
+	Message string
+	Args    Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineRaise) Format(ctx *FmtCtx) {
+	ctx.WriteString("RAISE EXCEPTION ")
+	ctx.formatStringLiteral(node.Message)
+	if len(node.Args) > 0 {
+		ctx.WriteString(", ")
+		ctx.FormatNode(&node.Args)
+	}
+}
+
+// RoutineExec represents a SQL statement that is executed by the routine:
+//
+//   <statement> [INTO <names>]
+//
+// With INTO, the values of the first row returned by the statement are
+// assigned to the given variables.
+type RoutineExec struct {
+	Statement Statement
+	Into      NameList
+}
+
+// Format implements the NodeFormatter interface.
+func (node *RoutineExec) Format(ctx *FmtCtx) {
+	ctx.FormatNode(node.Statement)
+	if len(node.Into) > 0 {
+		ctx.WriteString(" INTO ")
+		ctx.FormatNode(&node.Into)
+	}
+}
+
+// FunctionLanguage is the language of the body of a user-defined function.
+type FunctionLanguage int
+
+// FunctionLanguage values.
+const (
+	// FunctionLangSQL is a body made of SQL statements. The function returns
+	// the first row of the last statement.
+	FunctionLangSQL FunctionLanguage = iota
+	// FunctionLangPLpgSQL is a PL/pgSQL body. See RoutineBlock.
+	FunctionLangPLpgSQL
+)
+
+var functionLanguageName = [...]string{
+	FunctionLangSQL:     "sql",
+	FunctionLangPLpgSQL: "plpgsql",
+}
+
+func (l FunctionLanguage) String() string {
+	return functionLanguageName[l]
+}
+
+// FunctionParam is a parameter of a user-defined function. Name is empty if
+// the parameter can only be referenced by position.
+type FunctionParam struct {
+	Name Name
+	Type coltypes.CastTargetType
+}
+
+// FunctionParams is a list of function parameters.
+type FunctionParams []FunctionParam
+
+// Format implements the NodeFormatter interface.
+func (node *FunctionParams) Format(ctx *FmtCtx) {
+	for i := range *node {
+		p := &(*node)[i]
+		if i > 0 {
+			ctx.WriteString(", ")
+		}
+		if p.Name != "" {
+			ctx.FormatNode(&p.Name)
+			ctx.WriteByte(' ')
+		}
+		p.Type.Format(&ctx.Buffer, ctx.flags.EncodeFlags())
+	}
+}
+
+// CreateFunction represents a CREATE FUNCTION statement.
+type CreateFunction struct {
+	Replace    bool
+	Name       UnresolvedName
+	Params     FunctionParams
+	ReturnType coltypes.CastTargetType
+	Language   FunctionLanguage
+	// Body is the source of the body of the function. It is parsed when the
+	// statement is parsed, into SQLBody for the SQL functions and into PLBody
+	// for the PL/pgSQL functions.
+	Body    string
+	SQLBody []Statement
+	PLBody  *RoutineBlock
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateFunction) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("FUNCTION ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Params)
+	ctx.WriteString(") RETURNS ")
+	node.ReturnType.Format(&ctx.Buffer, ctx.flags.EncodeFlags())
+	ctx.WriteString(" LANGUAGE ")
+	ctx.WriteString(node.Language.String())
+	ctx.WriteString(" AS ")
+	ctx.formatStringLiteral(node.Body)
+}
+
+// CreateProcedure represents a CREATE PROCEDURE statement.
+type CreateProcedure struct {
+	Replace  bool
+	Name     TableName
+	Params   FunctionParams
+	Language FunctionLanguage
+	// Body is the source of the body of the procedure. As for CreateFunction,
+	// it is parsed when the statement is parsed, into SQLBody or PLBody.
+	Body    string
+	SQLBody []Statement
+	PLBody  *RoutineBlock
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateProcedure) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE ")
+	if node.Replace {
+		ctx.WriteString("OR REPLACE ")
+	}
+	ctx.WriteString("PROCEDURE ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Params)
+	ctx.WriteString(") LANGUAGE ")
+	ctx.WriteString(node.Language.String())
+	ctx.WriteString(" AS ")
+	ctx.formatStringLiteral(node.Body)
+}
+
+// DropProcedure represents a DROP PROCEDURE statement.
+type DropProcedure struct {
+	Name     TableName
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropProcedure) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP PROCEDURE ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+}
+
+// Call represents a CALL statement, which invokes a procedure.
+type Call struct {
+	Name TableName
+	Args Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *Call) Format(ctx *FmtCtx) {
+	ctx.WriteString("CALL ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Args)
+	ctx.WriteByte(')')
+}
@@ -0,0 +1,97 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+// CreateSchedule represents a CREATE SCHEDULE statement.
+type CreateSchedule struct {
+	Name        Name
+	IfNotExists bool
+	// Statement is the SQL statement run by the schedule.
+	Statement string
+	// Recurrence is the cron expression of the schedule.
+	Recurrence string
+	// Owner is the user the statement is executed as. It is empty if EXECUTE
+	// AS is not specified, in which case it is the user creating the schedule.
+	Owner Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateSchedule) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE SCHEDULE ")
+	if node.IfNotExists {
+		ctx.WriteString("IF NOT EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" FOR SQL STATEMENT ")
+	ctx.formatStringLiteral(node.Statement)
+	ctx.WriteString(" RECURRING ")
+	ctx.formatStringLiteral(node.Recurrence)
+	if node.Owner != "" {
+		ctx.WriteString(" EXECUTE AS ")
+		ctx.FormatNode(&node.Owner)
+	}
+}
+
+// DropSchedule represents a DROP SCHEDULE statement.
+type DropSchedule struct {
+	Name     Name
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropSchedule) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP SCHEDULE ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+}
+
+// ControlSchedule represents a PAUSE/RESUME SCHEDULE statement.
+type ControlSchedule struct {
+	Name    Name
+	Command ScheduleCommand
+}
+
+// ScheduleCommand determines which type of action to effect on the schedule.
+type ScheduleCommand int
+
+// ScheduleCommand values
+const (
+	PauseSchedule ScheduleCommand = iota
+	ResumeSchedule
+)
+
+// ScheduleCommandToStatement translates a schedule command integer to a
+// statement prefix.
+var ScheduleCommandToStatement = map[ScheduleCommand]string{
+	PauseSchedule:  "PAUSE",
+	ResumeSchedule: "RESUME",
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ControlSchedule) Format(ctx *FmtCtx) {
+	ctx.WriteString(ScheduleCommandToStatement[node.Command])
+	ctx.WriteString(" SCHEDULE ")
+	ctx.FormatNode(&node.Name)
+}
+
+// ShowSchedules represents a SHOW SCHEDULES statement.
+type ShowSchedules struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowSchedules) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW SCHEDULES")
+}
@@ -48,6 +48,7 @@ type Select struct {
 	Select  SelectStatement
 	OrderBy OrderBy
 	Limit   *Limit
+	Locking LockingClause
 }
 
 // Format implements the NodeFormatter interface.
@@ -62,6 +63,7 @@ func (node *Select) Format(ctx *FmtCtx) {
 		ctx.WriteByte(' ')
 		ctx.FormatNode(node.Limit)
 	}
+	ctx.FormatNode(&node.Locking)
 }
 
 // ParenSelect represents a parenthesized SELECT/UNION/VALUES statement.
@@ -105,7 +107,7 @@ func (node *SelectClause) Format(ctx *FmtCtx) {
 			}
 		}
 		ctx.FormatNode(&node.Exprs)
-		if len(node.From.Tables) > 0 {
+		if node.From != nil && len(node.From.Tables) > 0 {
 			ctx.WriteByte(' ')
 			ctx.FormatNode(node.From)
 		}
@@ -365,11 +367,20 @@ type AliasedTableExpr struct {
 	Expr       TableExpr
 	IndexFlags *IndexFlags
 	Ordinality bool
-	As         AliasClause
+	// Lateral is set for a LATERAL subquery or function call, which can refer
+	// to the columns of the preceding FROM items.
+	Lateral bool
+	As      AliasClause
+	// Sample is set when the rows of the table are sampled with a
+	// TABLESAMPLE clause.
+	Sample *TableSample
 }
 
 // Format implements the NodeFormatter interface.
 func (node *AliasedTableExpr) Format(ctx *FmtCtx) {
+	if node.Lateral {
+		ctx.WriteString("LATERAL ")
+	}
 	ctx.FormatNode(node.Expr)
 	if node.IndexFlags != nil {
 		ctx.FormatNode(node.IndexFlags)
@@ -381,6 +392,60 @@ func (node *AliasedTableExpr) Format(ctx *FmtCtx) {
 		ctx.WriteString(" AS ")
 		ctx.FormatNode(&node.As)
 	}
+	if node.Sample != nil {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(node.Sample)
+	}
+}
+
+// TableSampleMethod is the sampling method of a TABLESAMPLE clause.
+type TableSampleMethod int
+
+const (
+	// TableSampleSystem represents the SYSTEM sampling method, which returns
+	// all the rows of a random sample of the ranges of the table.
+	TableSampleSystem TableSampleMethod = iota
+	// TableSampleBernoulli represents the BERNOULLI sampling method, which
+	// returns a random sample of the rows of the table.
+	TableSampleBernoulli
+)
+
+// String implements the Stringer interface.
+func (m TableSampleMethod) String() string {
+	switch m {
+	case TableSampleSystem:
+		return "SYSTEM"
+	case TableSampleBernoulli:
+		return "BERNOULLI"
+	default:
+		panic(pgerror.NewAssertionErrorf("unhandled case: %d", log.Safe(m)))
+	}
+}
+
+// TableSample represents a TABLESAMPLE clause:
+//   TABLESAMPLE <method> (<percent>) [REPEATABLE (<seed>)]
+type TableSample struct {
+	Method TableSampleMethod
+	// Percent is the percentage of the table to return.
+	Percent Expr
+	// Repeatable is the seed of the sample, if one was specified. The same
+	// sample is returned every time the same seed is used, as long as the
+	// table has not changed.
+	Repeatable Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TableSample) Format(ctx *FmtCtx) {
+	ctx.WriteString("TABLESAMPLE ")
+	ctx.WriteString(node.Method.String())
+	ctx.WriteString(" (")
+	ctx.FormatNode(node.Percent)
+	ctx.WriteByte(')')
+	if node.Repeatable != nil {
+		ctx.WriteString(" REPEATABLE (")
+		ctx.FormatNode(node.Repeatable)
+		ctx.WriteByte(')')
+	}
 }
 
 // ParenTableExpr represents a parenthesized TableExpr.
@@ -547,6 +612,44 @@ func (node *GroupBy) Format(ctx *FmtCtx) {
 	}
 }
 
+// GroupingSetType is the type of a GroupingSet.
+type GroupingSetType int
+
+// GroupingSetType values.
+const (
+	// RollupGroupingSet is ROLLUP (a, b, ...).
+	RollupGroupingSet GroupingSetType = iota
+	// CubeGroupingSet is CUBE (a, b, ...).
+	CubeGroupingSet
+	// ExplicitGroupingSets is GROUPING SETS (...).
+	ExplicitGroupingSets
+)
+
+// GroupingSet represents an item of a GROUP BY clause which specifies
+// multiple grouping sets. The items of ROLLUP and CUBE, and the items of
+// GROUPING SETS which are not themselves grouping sets, are either
+// expressions or tuples of expressions which are grouped together. The empty
+// grouping set is the empty tuple.
+type GroupingSet struct {
+	Type  GroupingSetType
+	Exprs Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *GroupingSet) Format(ctx *FmtCtx) {
+	switch node.Type {
+	case RollupGroupingSet:
+		ctx.WriteString("ROLLUP ")
+	case CubeGroupingSet:
+		ctx.WriteString("CUBE ")
+	case ExplicitGroupingSets:
+		ctx.WriteString("GROUPING SETS ")
+	}
+	ctx.WriteByte('(')
+	ctx.FormatNode(&node.Exprs)
+	ctx.WriteByte(')')
+}
+
 // DistinctOn represents a DISTINCT ON clause.
 type DistinctOn []Expr
 
@@ -638,10 +741,25 @@ func (node *Order) Format(ctx *FmtCtx) {
 // Limit represents a LIMIT clause.
 type Limit struct {
 	Offset, Count Expr
+	// WithTies is set for FETCH FIRST ... ROWS WITH TIES, in which case the
+	// rows which are peers of the last row within Count are also returned.
+	WithTies bool
 }
 
 // Format implements the NodeFormatter interface.
 func (node *Limit) Format(ctx *FmtCtx) {
+	if node.WithTies {
+		// WITH TIES can only be expressed with the SQL:2008 syntax.
+		if node.Offset != nil {
+			ctx.WriteString("OFFSET ")
+			ctx.FormatNode(node.Offset)
+			ctx.WriteByte(' ')
+		}
+		ctx.WriteString("FETCH FIRST ")
+		ctx.FormatNode(fetchFirstValue(node.Count))
+		ctx.WriteString(" ROWS WITH TIES")
+		return
+	}
 	needSpace := false
 	if node.Count != nil {
 		ctx.WriteString("LIMIT ")
@@ -657,6 +775,136 @@ func (node *Limit) Format(ctx *FmtCtx) {
 	}
 }
 
+// fetchFirstValue returns the count of a FETCH FIRST clause, parenthesized
+// unless it is a constant. The SQL:2008 syntax does not allow arbitrary
+// expressions without parentheses.
+func fetchFirstValue(count Expr) Expr {
+	switch count.(type) {
+	case *NumVal, *ParenExpr:
+		return count
+	}
+	return &ParenExpr{Expr: count}
+}
+
+// LockingClause represents a locking clause, like FOR UPDATE.
+type LockingClause []*LockingItem
+
+// Format implements the NodeFormatter interface.
+func (node *LockingClause) Format(ctx *FmtCtx) {
+	for _, n := range *node {
+		ctx.FormatNode(n)
+	}
+}
+
+// LockingItem represents a single locking item in a locking clause.
+type LockingItem struct {
+	Strength   LockingStrength
+	Targets    TableNames
+	WaitPolicy LockingWaitPolicy
+}
+
+// Format implements the NodeFormatter interface.
+func (node *LockingItem) Format(ctx *FmtCtx) {
+	ctx.FormatNode(node.Strength)
+	if len(node.Targets) > 0 {
+		ctx.WriteString(" OF ")
+		ctx.FormatNode(&node.Targets)
+	}
+	ctx.FormatNode(node.WaitPolicy)
+}
+
+// LockingStrength represents the possible row-level lock modes for a SELECT
+// statement. The strengths are ordered from the weakest to the strongest.
+type LockingStrength byte
+
+// The ordering of the variants is important, because the highest numerical
+// value takes precedence when row-level locking is specified multiple ways.
+const (
+	// ForNone represents the default - no for statement at all.
+	// LockingItem AST nodes are never created with this strength.
+	ForNone LockingStrength = iota
+	// ForKeyShare represents FOR KEY SHARE.
+	ForKeyShare
+	// ForShare represents FOR SHARE.
+	ForShare
+	// ForNoKeyUpdate represents FOR NO KEY UPDATE.
+	ForNoKeyUpdate
+	// ForUpdate represents FOR UPDATE.
+	ForUpdate
+)
+
+var lockingStrengthName = [...]string{
+	ForNone:        "",
+	ForKeyShare:    "FOR KEY SHARE",
+	ForShare:       "FOR SHARE",
+	ForNoKeyUpdate: "FOR NO KEY UPDATE",
+	ForUpdate:      "FOR UPDATE",
+}
+
+func (s LockingStrength) String() string {
+	return lockingStrengthName[s]
+}
+
+// Format implements the NodeFormatter interface.
+func (s LockingStrength) Format(ctx *FmtCtx) {
+	if s != ForNone {
+		ctx.WriteByte(' ')
+		ctx.WriteString(s.String())
+	}
+}
+
+// Max returns the maximum of the two locking strengths.
+func (s LockingStrength) Max(s2 LockingStrength) LockingStrength {
+	if s2 > s {
+		return s2
+	}
+	return s
+}
+
+// LockingWaitPolicy represents the possible policies for dealing with rows
+// being locked by FOR UPDATE/SHARE clauses (i.e., it represents the NOWAIT
+// and SKIP LOCKED options).
+type LockingWaitPolicy byte
+
+// The ordering of the variants is important, because the highest numerical
+// value takes precedence when row-level locking is specified multiple ways.
+const (
+	// LockWaitBlock represents the default - wait for the lock to become
+	// available.
+	LockWaitBlock LockingWaitPolicy = iota
+	// LockWaitSkip represents SKIP LOCKED - skip rows that can't be locked.
+	LockWaitSkip
+	// LockWaitError represents NOWAIT - raise an error if a row cannot be
+	// locked.
+	LockWaitError
+)
+
+var lockingWaitPolicyName = [...]string{
+	LockWaitBlock: "",
+	LockWaitSkip:  "SKIP LOCKED",
+	LockWaitError: "NOWAIT",
+}
+
+func (p LockingWaitPolicy) String() string {
+	return lockingWaitPolicyName[p]
+}
+
+// Format implements the NodeFormatter interface.
+func (p LockingWaitPolicy) Format(ctx *FmtCtx) {
+	if p != LockWaitBlock {
+		ctx.WriteByte(' ')
+		ctx.WriteString(p.String())
+	}
+}
+
+// Max returns the maximum of the two locking wait policies.
+func (p LockingWaitPolicy) Max(p2 LockingWaitPolicy) LockingWaitPolicy {
+	if p2 > p {
+		return p2
+	}
+	return p
+}
+
 // RowsFromExpr represents a ROWS FROM(...) expression.
 type RowsFromExpr struct {
 	Items Exprs
@@ -776,10 +1024,26 @@ func (node *WindowFrameBounds) HasOffset() bool {
 	return node.StartBound.HasOffset() || (node.EndBound != nil && node.EndBound.HasOffset())
 }
 
+// WindowFrameExclusion indicates which mode of exclusion is used.
+type WindowFrameExclusion int
+
+const (
+	// NoExclusion represents an omitted frame exclusion clause as well as
+	// EXCLUDE NO OTHERS.
+	NoExclusion WindowFrameExclusion = iota
+	// ExcludeCurrentRow represents EXCLUDE CURRENT ROW mode of frame exclusion.
+	ExcludeCurrentRow
+	// ExcludeGroup represents EXCLUDE GROUP mode of frame exclusion.
+	ExcludeGroup
+	// ExcludeTies represents EXCLUDE TIES mode of frame exclusion.
+	ExcludeTies
+)
+
 // WindowFrame represents static state of window frame over which calculations are made.
 type WindowFrame struct {
-	Mode   WindowFrameMode   // the mode of framing being used
-	Bounds WindowFrameBounds // the bounds of the frame
+	Mode      WindowFrameMode      // the mode of framing being used
+	Bounds    WindowFrameBounds    // the bounds of the frame
+	Exclusion WindowFrameExclusion // optional frame exclusion
 }
 
 // Format implements the NodeFormatter interface.
@@ -822,4 +1086,24 @@ func (node *WindowFrame) Format(ctx *FmtCtx) {
 	} else {
 		ctx.FormatNode(node.Bounds.StartBound)
 	}
+	if node.Exclusion != NoExclusion {
+		ctx.WriteByte(' ')
+		ctx.FormatNode(node.Exclusion)
+	}
+}
+
+// Format implements the NodeFormatter interface.
+func (node WindowFrameExclusion) Format(ctx *FmtCtx) {
+	switch node {
+	case NoExclusion:
+		ctx.WriteString("EXCLUDE NO OTHERS")
+	case ExcludeCurrentRow:
+		ctx.WriteString("EXCLUDE CURRENT ROW")
+	case ExcludeGroup:
+		ctx.WriteString("EXCLUDE GROUP")
+	case ExcludeTies:
+		ctx.WriteString("EXCLUDE TIES")
+	default:
+		panic(pgerror.NewAssertionErrorf("unhandled case: %d", log.Safe(node)))
+	}
 }
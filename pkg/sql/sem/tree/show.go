@@ -104,6 +104,20 @@ func (node *ShowDatabases) Format(ctx *FmtCtx) {
 	ctx.WriteString("SHOW DATABASES")
 }
 
+// ShowEvents represents a SHOW EVENTS statement.
+type ShowEvents struct {
+	Options KVOptions
+}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowEvents) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW EVENTS")
+	if node.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
+}
+
 // ShowTraceType is an enum of SHOW TRACE variants.
 type ShowTraceType string
 
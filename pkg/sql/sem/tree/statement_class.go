@@ -0,0 +1,214 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+// StatementClass is a coarse classification of statements by their effect
+// on the database. Unlike StatementType, which describes what a statement
+// returns to the client, StatementClass describes what it does, so that
+// components such as proxies and replication filters can route or filter
+// statements without semantic analysis.
+type StatementClass int
+
+//go:generate stringer -type=StatementClass
+const (
+	// ReadOnlyStmt indicates that the statement only reads data or session
+	// state, or only modifies session state. Examples include SELECT, SHOW
+	// and SET.
+	ReadOnlyStmt StatementClass = iota
+	// MutationStmt indicates that the statement modifies rows of tables.
+	// Examples include INSERT, UPDATE, DELETE and SELECT statements with
+	// data-modifying subqueries.
+	MutationStmt
+	// DDLStmt indicates that the statement modifies the database schema.
+	// Examples include CREATE TABLE, ALTER INDEX and TRUNCATE.
+	DDLStmt
+	// TransactionStmt indicates a transaction control statement. Examples
+	// include BEGIN, COMMIT and SAVEPOINT.
+	TransactionStmt
+	// AdminStmt indicates that the statement administers the cluster, its
+	// users or its data in bulk. Examples include GRANT, CREATE USER, SET
+	// CLUSTER SETTING, BACKUP and ALTER TABLE ... SPLIT AT.
+	AdminStmt
+)
+
+// StatementClassification is the result of ClassifyStatement.
+type StatementClassification struct {
+	Class StatementClass
+	// AllowedInReadOnlyTxn is true if the statement can run in a read-only
+	// transaction.
+	AllowedInReadOnlyTxn bool
+}
+
+// ClassifyStatement returns the classification of a statement. The
+// classification is purely syntactic: in particular, functions with side
+// effects such as nextval() are not taken into account, and EXECUTE is
+// conservatively classified as a mutation that is not allowed in a read-only
+// transaction, since the prepared statement is not known.
+func ClassifyStatement(stmt Statement) StatementClassification {
+	switch s := stmt.(type) {
+	case *BeginTransaction, *CommitTransaction, *RollbackTransaction, *SetTransaction,
+		*Savepoint, *ReleaseSavepoint, *RollbackToSavepoint:
+		return StatementClassification{Class: TransactionStmt, AllowedInReadOnlyTxn: true}
+
+	case *Execute:
+		return StatementClassification{Class: MutationStmt}
+
+	case *Explain:
+		// EXPLAIN only executes the statement with the ANALYZE option, but the
+		// statement is planned in all cases.
+		c := ClassifyStatement(s.Statement)
+		if opts, err := s.ParseOptions(); err != nil || !opts.Flags.Contains(ExplainFlagAnalyze) {
+			c.Class = ReadOnlyStmt
+		}
+		return c
+
+	case *Grant, *Revoke, *GrantRole, *RevokeRole,
+		*CreateUser, *CreateRole, *DropUser, *DropRole, *AlterUserSetPassword,
+		*SetClusterSetting, *SetZoneConfig,
+		*Backup, *Restore, *Import, *Export, *CreateChangefeed, *CreateStats, *Scrub,
+		*Split, *Relocate, *Scatter,
+		*ControlJobs, *CancelQueries, *CancelSessions:
+		return classifyWrites(stmt, AdminStmt)
+
+	case *Insert, *Update, *Delete, *CopyFrom:
+		return classifyWrites(stmt, MutationStmt)
+	}
+
+	if CanModifySchema(stmt) {
+		return classifyWrites(stmt, DDLStmt)
+	}
+	if stmtContainsMutation(stmt) {
+		return StatementClassification{Class: MutationStmt}
+	}
+	return classifyWrites(stmt, ReadOnlyStmt)
+}
+
+// classifyWrites returns a classification with the given class, which is
+// allowed in a read-only transaction if the statement does not write.
+func classifyWrites(stmt Statement, class StatementClass) StatementClassification {
+	return StatementClassification{
+		Class:                class,
+		AllowedInReadOnlyTxn: !CanModifySchema(stmt) && !CanWriteData(stmt) && !stmtContainsMutation(stmt),
+	}
+}
+
+// stmtContainsMutation returns true if the statement contains a nested
+// statement that modifies rows, either in a WITH clause or as a data source
+// (e.g. [INSERT ... RETURNING ...]).
+func stmtContainsMutation(stmt Statement) bool {
+	switch s := stmt.(type) {
+	case *Insert, *Update, *Delete:
+		return true
+	case *Select:
+		return withContainsMutation(s.With) || selectContainsMutation(s.Select)
+	case *ParenSelect:
+		return stmtContainsMutation(s.Select)
+	}
+	return false
+}
+
+func withContainsMutation(with *With) bool {
+	if with == nil {
+		return false
+	}
+	for _, cte := range with.CTEList {
+		if stmtContainsMutation(cte.Stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+func selectContainsMutation(stmt SelectStatement) bool {
+	switch s := stmt.(type) {
+	case *ParenSelect:
+		return stmtContainsMutation(s.Select)
+	case *UnionClause:
+		return stmtContainsMutation(s.Left) || stmtContainsMutation(s.Right)
+	case *ValuesClause:
+		for _, row := range s.Rows {
+			for _, expr := range row {
+				if exprContainsMutation(expr) {
+					return true
+				}
+			}
+		}
+	case *SelectClause:
+		if s.From != nil {
+			for _, tbl := range s.From.Tables {
+				if tableExprContainsMutation(tbl) {
+					return true
+				}
+			}
+		}
+		for _, expr := range s.Exprs {
+			if exprContainsMutation(expr.Expr) {
+				return true
+			}
+		}
+		if s.Where != nil && exprContainsMutation(s.Where.Expr) {
+			return true
+		}
+		if s.Having != nil && exprContainsMutation(s.Having.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func tableExprContainsMutation(expr TableExpr) bool {
+	switch t := expr.(type) {
+	case *AliasedTableExpr:
+		return tableExprContainsMutation(t.Expr)
+	case *ParenTableExpr:
+		return tableExprContainsMutation(t.Expr)
+	case *JoinTableExpr:
+		if on, ok := t.Cond.(*OnJoinCond); ok && exprContainsMutation(on.Expr) {
+			return true
+		}
+		return tableExprContainsMutation(t.Left) || tableExprContainsMutation(t.Right)
+	case *StatementSource:
+		return stmtContainsMutation(t.Statement)
+	case *Subquery:
+		return selectContainsMutation(t.Select)
+	}
+	return false
+}
+
+func exprContainsMutation(expr Expr) bool {
+	v := mutationVisitor{}
+	WalkExprConst(&v, expr)
+	return v.found
+}
+
+// mutationVisitor looks for subqueries that contain a mutation.
+type mutationVisitor struct {
+	found bool
+}
+
+var _ Visitor = &mutationVisitor{}
+
+func (v *mutationVisitor) VisitPre(expr Expr) (recurse bool, newExpr Expr) {
+	if v.found {
+		return false, expr
+	}
+	if sub, ok := expr.(*Subquery); ok {
+		v.found = selectContainsMutation(sub.Select)
+		return false, expr
+	}
+	return true, expr
+}
+
+func (*mutationVisitor) VisitPost(expr Expr) Expr { return expr }
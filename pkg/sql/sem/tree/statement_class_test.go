@@ -0,0 +1,79 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree_test
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func TestClassifyStatement(t *testing.T) {
+	testCases := []struct {
+		sql      string
+		class    tree.StatementClass
+		readOnly bool
+	}{
+		{`SELECT 1`, tree.ReadOnlyStmt, true},
+		{`SELECT * FROM t WHERE a IN (SELECT b FROM u) UNION VALUES (1)`, tree.ReadOnlyStmt, true},
+		{`SHOW TABLES`, tree.ReadOnlyStmt, true},
+		{`SET application_name = 'x'`, tree.ReadOnlyStmt, true},
+		{`PREPARE p AS INSERT INTO t VALUES (1)`, tree.ReadOnlyStmt, true},
+		{`EXPLAIN SELECT 1`, tree.ReadOnlyStmt, true},
+		{`EXPLAIN INSERT INTO t VALUES (1)`, tree.ReadOnlyStmt, false},
+		{`EXPLAIN ANALYZE (DISTSQL) DELETE FROM t`, tree.MutationStmt, false},
+
+		{`INSERT INTO t VALUES (1)`, tree.MutationStmt, false},
+		{`UPSERT INTO t VALUES (1)`, tree.MutationStmt, false},
+		{`UPDATE t SET a = 1`, tree.MutationStmt, false},
+		{`DELETE FROM t`, tree.MutationStmt, false},
+		{`EXECUTE p`, tree.MutationStmt, false},
+		{`WITH x AS (INSERT INTO t VALUES (1) RETURNING a) SELECT * FROM x`, tree.MutationStmt, false},
+		{`SELECT * FROM t JOIN [DELETE FROM u RETURNING b] ON true`, tree.MutationStmt, false},
+		{`SELECT (SELECT a FROM [UPDATE t SET a = 1 RETURNING a])`, tree.MutationStmt, false},
+
+		{`CREATE TABLE t (a INT)`, tree.DDLStmt, false},
+		{`CREATE TABLE t AS SELECT 1`, tree.DDLStmt, false},
+		{`ALTER INDEX t@i RENAME TO j`, tree.DDLStmt, false},
+		{`TRUNCATE t`, tree.DDLStmt, false},
+		{`DROP TABLE t`, tree.DDLStmt, false},
+
+		{`BEGIN`, tree.TransactionStmt, true},
+		{`SAVEPOINT cockroach_restart`, tree.TransactionStmt, true},
+		{`COMMIT`, tree.TransactionStmt, true},
+
+		{`GRANT ALL ON t TO u`, tree.AdminStmt, false},
+		{`CREATE USER u`, tree.AdminStmt, true},
+		{`SET CLUSTER SETTING a = 1`, tree.AdminStmt, true},
+		{`ALTER TABLE t SPLIT AT VALUES (1)`, tree.AdminStmt, false},
+		{`CANCEL QUERY 'x'`, tree.AdminStmt, true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, err := parser.ParseOne(tc.sql)
+			if err != nil {
+				t.Fatal(err)
+			}
+			c := tree.ClassifyStatement(stmt.AST)
+			if c.Class != tc.class {
+				t.Errorf("expected class %s, got %s", tc.class, c.Class)
+			}
+			if c.AllowedInReadOnlyTxn != tc.readOnly {
+				t.Errorf("expected allowed in read-only txn %t, got %t", tc.readOnly, c.AllowedInReadOnlyTxn)
+			}
+		})
+	}
+}
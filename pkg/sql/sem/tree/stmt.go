@@ -183,6 +183,12 @@ var _ CCLOnlyStatement = &DropRole{}
 var _ CCLOnlyStatement = &GrantRole{}
 var _ CCLOnlyStatement = &RevokeRole{}
 
+// StatementType implements the Statement interface.
+func (*AlterDatabaseSetReadMode) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*AlterDatabaseSetReadMode) StatementTag() string { return "ALTER DATABASE" }
+
 // StatementType implements the Statement interface.
 func (*AlterIndex) StatementType() StatementType { return DDL }
 
@@ -229,6 +235,12 @@ func (*BeginTransaction) StatementType() StatementType { return Ack }
 // StatementTag returns a short string identifying the type of statement.
 func (*BeginTransaction) StatementTag() string { return "BEGIN" }
 
+// StatementType implements the Statement interface.
+func (*Call) StatementType() StatementType { return Ack }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*Call) StatementTag() string { return "CALL" }
+
 // StatementType implements the Statement interface.
 func (*ControlJobs) StatementType() StatementType { return RowsAffected }
 
@@ -239,6 +251,14 @@ func (n *ControlJobs) StatementTag() string {
 
 func (*ControlJobs) independentFromParallelizedPriors() {}
 
+// StatementType implements the Statement interface.
+func (*ControlSchedule) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (n *ControlSchedule) StatementTag() string {
+	return fmt.Sprintf("%s SCHEDULE", ScheduleCommandToStatement[n.Command])
+}
+
 // StatementType implements the Statement interface.
 func (*CancelQueries) StatementType() StatementType { return RowsAffected }
 
@@ -308,12 +328,24 @@ func (*CreateDatabase) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*CreateDatabase) StatementTag() string { return "CREATE DATABASE" }
 
+// StatementType implements the Statement interface.
+func (*CreateFunction) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateFunction) StatementTag() string { return "CREATE FUNCTION" }
+
 // StatementType implements the Statement interface.
 func (*CreateIndex) StatementType() StatementType { return DDL }
 
 // StatementTag returns a short string identifying the type of statement.
 func (*CreateIndex) StatementTag() string { return "CREATE INDEX" }
 
+// StatementType implements the Statement interface.
+func (*CreateProcedure) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateProcedure) StatementTag() string { return "CREATE PROCEDURE" }
+
 // StatementType implements the Statement interface.
 func (n *CreateTable) StatementType() StatementType {
 	if n.As() {
@@ -351,6 +383,18 @@ func (*CreateRole) cclOnlyStatement() {}
 
 func (*CreateRole) hiddenFromShowQueries() {}
 
+// StatementType implements the Statement interface.
+func (*CreateSchedule) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateSchedule) StatementTag() string { return "CREATE SCHEDULE" }
+
+// StatementType implements the Statement interface.
+func (*CreateTrigger) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*CreateTrigger) StatementTag() string { return "CREATE TRIGGER" }
+
 // StatementType implements the Statement interface.
 func (*CreateView) StatementType() StatementType { return DDL }
 
@@ -411,6 +455,24 @@ func (*DropTable) StatementType() StatementType { return DDL }
 // StatementTag returns a short string identifying the type of statement.
 func (*DropTable) StatementTag() string { return "DROP TABLE" }
 
+// StatementType implements the Statement interface.
+func (*DropProcedure) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropProcedure) StatementTag() string { return "DROP PROCEDURE" }
+
+// StatementType implements the Statement interface.
+func (*DropTrigger) StatementType() StatementType { return DDL }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropTrigger) StatementTag() string { return "DROP TRIGGER" }
+
+// StatementType implements the Statement interface.
+func (*DropSchedule) StatementType() StatementType { return RowsAffected }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*DropSchedule) StatementTag() string { return "DROP SCHEDULE" }
+
 // StatementType implements the Statement interface.
 func (*DropView) StatementType() StatementType { return DDL }
 
@@ -688,6 +750,12 @@ func (*ShowDatabases) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*ShowDatabases) StatementTag() string { return "SHOW DATABASES" }
 
+// StatementType implements the Statement interface.
+func (*ShowEvents) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowEvents) StatementTag() string { return "SHOW EVENTS" }
+
 // StatementType implements the Statement interface.
 func (*ShowTraceForSession) StatementType() StatementType { return Rows }
 
@@ -814,6 +882,12 @@ func (*ShowSchemas) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*ShowSchemas) StatementTag() string { return "SHOW SCHEMAS" }
 
+// StatementType implements the Statement interface.
+func (*ShowSchedules) StatementType() StatementType { return Rows }
+
+// StatementTag returns a short string identifying the type of statement.
+func (*ShowSchedules) StatementTag() string { return "SHOW SCHEDULES" }
+
 // StatementType implements the Statement interface.
 func (*ShowSequences) StatementType() StatementType { return Rows }
 
@@ -853,6 +927,7 @@ func (*ValuesClause) StatementType() StatementType { return Rows }
 // StatementTag returns a short string identifying the type of statement.
 func (*ValuesClause) StatementTag() string { return "VALUES" }
 
+func (n *AlterDatabaseSetReadMode) String() string  { return AsString(n) }
 func (n *AlterIndex) String() string                { return AsString(n) }
 func (n *AlterTable) String() string                { return AsString(n) }
 func (n *AlterTableCmds) String() string            { return AsString(n) }
@@ -868,7 +943,9 @@ func (n *AlterUserSetPassword) String() string      { return AsString(n) }
 func (n *AlterSequence) String() string             { return AsString(n) }
 func (n *Backup) String() string                    { return AsString(n) }
 func (n *BeginTransaction) String() string          { return AsString(n) }
+func (n *Call) String() string                      { return AsString(n) }
 func (n *ControlJobs) String() string               { return AsString(n) }
+func (n *ControlSchedule) String() string           { return AsString(n) }
 func (n *CancelQueries) String() string             { return AsString(n) }
 func (n *CancelSessions) String() string            { return AsString(n) }
 func (n *CannedOptPlan) String() string             { return AsString(n) }
@@ -879,19 +956,26 @@ func (n *CommitTransaction) String() string         { return AsString(n) }
 func (n *CopyFrom) String() string                  { return AsString(n) }
 func (n *CreateChangefeed) String() string          { return AsString(n) }
 func (n *CreateDatabase) String() string            { return AsString(n) }
+func (n *CreateFunction) String() string            { return AsString(n) }
 func (n *CreateIndex) String() string               { return AsString(n) }
+func (n *CreateProcedure) String() string           { return AsString(n) }
 func (n *CreateRole) String() string                { return AsString(n) }
+func (n *CreateSchedule) String() string            { return AsString(n) }
 func (n *CreateTable) String() string               { return AsString(n) }
 func (n *CreateSequence) String() string            { return AsString(n) }
 func (n *CreateStats) String() string               { return AsString(n) }
+func (n *CreateTrigger) String() string             { return AsString(n) }
 func (n *CreateUser) String() string                { return AsString(n) }
 func (n *CreateView) String() string                { return AsString(n) }
 func (n *Deallocate) String() string                { return AsString(n) }
 func (n *Delete) String() string                    { return AsString(n) }
 func (n *DropDatabase) String() string              { return AsString(n) }
 func (n *DropIndex) String() string                 { return AsString(n) }
+func (n *DropProcedure) String() string             { return AsString(n) }
 func (n *DropRole) String() string                  { return AsString(n) }
+func (n *DropSchedule) String() string              { return AsString(n) }
 func (n *DropTable) String() string                 { return AsString(n) }
+func (n *DropTrigger) String() string               { return AsString(n) }
 func (n *DropView) String() string                  { return AsString(n) }
 func (n *DropSequence) String() string              { return AsString(n) }
 func (n *DropUser) String() string                  { return AsString(n) }
@@ -932,6 +1016,7 @@ func (n *ShowColumns) String() string               { return AsString(n) }
 func (n *ShowConstraints) String() string           { return AsString(n) }
 func (n *ShowCreate) String() string                { return AsString(n) }
 func (n *ShowDatabases) String() string             { return AsString(n) }
+func (n *ShowEvents) String() string                { return AsString(n) }
 func (n *ShowGrants) String() string                { return AsString(n) }
 func (n *ShowHistogram) String() string             { return AsString(n) }
 func (n *ShowIndex) String() string                 { return AsString(n) }
@@ -940,6 +1025,7 @@ func (n *ShowQueries) String() string               { return AsString(n) }
 func (n *ShowRanges) String() string                { return AsString(n) }
 func (n *ShowRoleGrants) String() string            { return AsString(n) }
 func (n *ShowRoles) String() string                 { return AsString(n) }
+func (n *ShowSchedules) String() string             { return AsString(n) }
 func (n *ShowSchemas) String() string               { return AsString(n) }
 func (n *ShowSequences) String() string             { return AsString(n) }
 func (n *ShowSessions) String() string              { return AsString(n) }
@@ -0,0 +1,281 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+// TriggerActionTime indicates whether a trigger fires before or after the row
+// or the statement is executed.
+type TriggerActionTime int
+
+// TriggerActionTime values.
+const (
+	TriggerBefore TriggerActionTime = iota
+	TriggerAfter
+)
+
+var triggerActionTimeName = [...]string{
+	TriggerBefore: "BEFORE",
+	TriggerAfter:  "AFTER",
+}
+
+func (t TriggerActionTime) String() string {
+	return triggerActionTimeName[t]
+}
+
+// TriggerEvent is a kind of row modification that fires a trigger.
+type TriggerEvent int
+
+// TriggerEvent values.
+const (
+	TriggerInsert TriggerEvent = iota
+	TriggerUpdate
+	TriggerDelete
+)
+
+var triggerEventName = [...]string{
+	TriggerInsert: "INSERT",
+	TriggerUpdate: "UPDATE",
+	TriggerDelete: "DELETE",
+}
+
+func (e TriggerEvent) String() string {
+	return triggerEventName[e]
+}
+
+// TriggerLevel indicates whether a trigger fires for each row or for each
+// statement.
+type TriggerLevel int
+
+// TriggerLevel values.
+const (
+	TriggerRow TriggerLevel = iota
+	TriggerStatement
+)
+
+var triggerLevelName = [...]string{
+	TriggerRow:       "ROW",
+	TriggerStatement: "STATEMENT",
+}
+
+func (l TriggerLevel) String() string {
+	return triggerLevelName[l]
+}
+
+// TriggerEvents is a list of trigger events.
+type TriggerEvents []TriggerEvent
+
+// Format implements the NodeFormatter interface.
+func (l *TriggerEvents) Format(ctx *FmtCtx) {
+	for i, e := range *l {
+		if i > 0 {
+			ctx.WriteString(" OR ")
+		}
+		ctx.WriteString(e.String())
+	}
+}
+
+// CreateTrigger represents a CREATE TRIGGER statement.
+type CreateTrigger struct {
+	Name       Name
+	ActionTime TriggerActionTime
+	Events     TriggerEvents
+	Table      TableName
+	Level      TriggerLevel
+	// Body is the source of the trigger body, which is parsed with
+	// parser.ParseTriggerBody.
+	Body string
+}
+
+// Format implements the NodeFormatter interface.
+func (node *CreateTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE TRIGGER ")
+	ctx.FormatNode(&node.Name)
+	ctx.WriteByte(' ')
+	ctx.WriteString(node.ActionTime.String())
+	ctx.WriteByte(' ')
+	ctx.FormatNode(&node.Events)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(&node.Table)
+	ctx.WriteString(" FOR EACH ")
+	ctx.WriteString(node.Level.String())
+	ctx.WriteString(" AS ")
+	ctx.formatStringLiteral(node.Body)
+}
+
+// DropTrigger represents a DROP TRIGGER statement.
+type DropTrigger struct {
+	Name     Name
+	Table    TableName
+	IfExists bool
+}
+
+// Format implements the NodeFormatter interface.
+func (node *DropTrigger) Format(ctx *FmtCtx) {
+	ctx.WriteString("DROP TRIGGER ")
+	if node.IfExists {
+		ctx.WriteString("IF EXISTS ")
+	}
+	ctx.FormatNode(&node.Name)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(&node.Table)
+}
+
+// TriggerBody is the parsed body of a trigger:
+//
+//   BEGIN
+//     <statements>
+//   END
+//
+// Within the body, NEW and OLD refer to the new and old values of the row that
+// fired the trigger, and their columns can be referenced as NEW.<column> and
+// OLD.<column>. In a statement-level trigger, all their columns are NULL.
+type TriggerBody []TriggerStmt
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerBody) Format(ctx *FmtCtx) {
+	ctx.WriteString("BEGIN ")
+	formatTriggerStmts(ctx, *node)
+	ctx.WriteString("END")
+}
+
+func formatTriggerStmts(ctx *FmtCtx, stmts []TriggerStmt) {
+	for _, stmt := range stmts {
+		ctx.FormatNode(stmt)
+		ctx.WriteString("; ")
+	}
+}
+
+// TriggerStmt is a statement in the body of a trigger.
+type TriggerStmt interface {
+	NodeFormatter
+	triggerStmt()
+}
+
+func (*TriggerAssign) triggerStmt() {}
+func (*TriggerIf) triggerStmt()     {}
+func (*TriggerRaise) triggerStmt()  {}
+func (*TriggerReturn) triggerStmt() {}
+func (*TriggerExec) triggerStmt()   {}
+
+// TriggerAssign represents the assignment of a value to a column of the new
+// row: NEW.<column> := <expr>.
+type TriggerAssign struct {
+	Column Name
+	Expr   Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerAssign) Format(ctx *FmtCtx) {
+	ctx.WriteString("NEW.")
+	ctx.FormatNode(&node.Column)
+	ctx.WriteString(" := ")
+	ctx.FormatNode(node.Expr)
+}
+
+// TriggerIf represents an IF statement:
+//
+//   IF <cond> THEN <stmts>
+//   [ELSIF <cond> THEN <stmts> ...]
+//   [ELSE <stmts>]
+//   END IF
+type TriggerIf struct {
+	Cond    Expr
+	Then    []TriggerStmt
+	ElseIfs []TriggerElseIf
+	Else    []TriggerStmt
+}
+
+// TriggerElseIf represents an ELSIF branch of a TriggerIf.
+type TriggerElseIf struct {
+	Cond  Expr
+	Stmts []TriggerStmt
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerIf) Format(ctx *FmtCtx) {
+	ctx.WriteString("IF ")
+	ctx.FormatNode(node.Cond)
+	ctx.WriteString(" THEN ")
+	formatTriggerStmts(ctx, node.Then)
+	for i := range node.ElseIfs {
+		ctx.WriteString("ELSIF ")
+		ctx.FormatNode(node.ElseIfs[i].Cond)
+		ctx.WriteString(" THEN ")
+		formatTriggerStmts(ctx, node.ElseIfs[i].Stmts)
+	}
+	if node.Else != nil {
+		ctx.WriteString("ELSE ")
+		formatTriggerStmts(ctx, node.Else)
+	}
+	ctx.WriteString("END IF")
+}
+
+// TriggerRaise represents a RAISE EXCEPTION statement, which aborts the
+// statement that fired the trigger with an error. Every % in the message is
+// replaced by the value of the corresponding argument.
+type TriggerRaise struct {
+	Message string
+	Args    Exprs
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerRaise) Format(ctx *FmtCtx) {
+	ctx.WriteString("RAISE EXCEPTION ")
+	ctx.formatStringLiteral(node.Message)
+	if len(node.Args) > 0 {
+		ctx.WriteString(", ")
+		ctx.FormatNode(&node.Args)
+	}
+}
+
+// TriggerReturnValue is the value returned by a TriggerReturn.
+type TriggerReturnValue int
+
+// TriggerReturnValue values.
+const (
+	// TriggerReturnNew proceeds with the operation, using the new row.
+	TriggerReturnNew TriggerReturnValue = iota
+	// TriggerReturnOld proceeds with the operation, using the old row.
+	TriggerReturnOld
+	// TriggerReturnNull skips the operation for the current row.
+	TriggerReturnNull
+)
+
+var triggerReturnValueName = [...]string{
+	TriggerReturnNew:  "NEW",
+	TriggerReturnOld:  "OLD",
+	TriggerReturnNull: "NULL",
+}
+
+// TriggerReturn represents a RETURN statement, which ends the execution of the
+// trigger body.
+type TriggerReturn struct {
+	Value TriggerReturnValue
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerReturn) Format(ctx *FmtCtx) {
+	ctx.WriteString("RETURN ")
+	ctx.WriteString(triggerReturnValueName[node.Value])
+}
+
+// TriggerExec represents a SQL statement that is executed by the trigger.
+type TriggerExec struct {
+	Statement Statement
+}
+
+// Format implements the NodeFormatter interface.
+func (node *TriggerExec) Format(ctx *FmtCtx) {
+	ctx.FormatNode(node.Statement)
+}
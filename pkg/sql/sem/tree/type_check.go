@@ -669,12 +669,13 @@ func (expr *ComparisonExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedE
 }
 
 var (
-	errOrderByIndexInWindow = pgerror.NewError(pgerror.CodeFeatureNotSupportedError, "ORDER BY INDEX in window definition is not supported")
-	errStarNotAllowed       = pgerror.NewError(pgerror.CodeSyntaxError, "cannot use \"*\" in this context")
-	errInvalidDefaultUsage  = pgerror.NewError(pgerror.CodeSyntaxError, "DEFAULT can only appear in a VALUES list within INSERT or on the right side of a SET")
-	errInvalidMaxUsage      = pgerror.NewError(pgerror.CodeSyntaxError, "MAXVALUE can only appear within a range partition expression")
-	errInvalidMinUsage      = pgerror.NewError(pgerror.CodeSyntaxError, "MINVALUE can only appear within a range partition expression")
-	errPrivateFunction      = pgerror.NewError(pgerror.CodeReservedNameError, "function reserved for internal use")
+	errOrderByIndexInWindow      = pgerror.NewError(pgerror.CodeFeatureNotSupportedError, "ORDER BY INDEX in window definition is not supported")
+	errOrderByIndexInWithinGroup = pgerror.NewError(pgerror.CodeFeatureNotSupportedError, "ORDER BY INDEX in WITHIN GROUP is not supported")
+	errStarNotAllowed            = pgerror.NewError(pgerror.CodeSyntaxError, "cannot use \"*\" in this context")
+	errInvalidDefaultUsage       = pgerror.NewError(pgerror.CodeSyntaxError, "DEFAULT can only appear in a VALUES list within INSERT or on the right side of a SET")
+	errInvalidMaxUsage           = pgerror.NewError(pgerror.CodeSyntaxError, "MAXVALUE can only appear within a range partition expression")
+	errInvalidMinUsage           = pgerror.NewError(pgerror.CodeSyntaxError, "MINVALUE can only appear within a range partition expression")
+	errPrivateFunction           = pgerror.NewError(pgerror.CodeReservedNameError, "function reserved for internal use")
 )
 
 // NewAggInAggError creates an error for the case when an aggregate function is
@@ -719,7 +720,10 @@ func (sc *SemaContext) checkFunctionUsage(expr *FuncExpr, def *FunctionDefinitio
 		}
 		return pgerror.UnimplementedWithIssueDetailError(def.UnsupportedWithIssue, def.Name, msg)
 	}
-	if def.Private {
+	// An application which was already resolved by an earlier type check, like
+	// the implementation of an ordered-set aggregate, may use a private
+	// function.
+	if def.Private && expr.fn == nil {
 		return pgerror.Wrapf(errPrivateFunction, pgerror.CodeReservedNameError,
 			"%s()", log.Safe(def.Name))
 	}
@@ -798,6 +802,10 @@ func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, e
 		ctx.Properties.Derived.inFuncExpr = true
 	}
 
+	if expr.AggType == OrderedSetAgg || def.OrderedSetImpl != "" {
+		return expr.typeCheckOrderedSetAgg(ctx, desired, def)
+	}
+
 	typedSubExprs, fns, err := typeCheckOverloadedExprs(ctx, desired, def.Definition, false, expr.Exprs...)
 	if err != nil {
 		return nil, pgerror.Wrapf(err, pgerror.CodeInvalidParameterValueError,
@@ -879,19 +887,8 @@ func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, e
 		}
 	}
 
-	if expr.Filter != nil {
-		if def.Class != AggregateClass {
-			// Same error message as Postgres. If we have a window function, only
-			// aggregates accept a FILTER clause.
-			return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
-				"FILTER specified but %s() is not an aggregate function", &expr.Func)
-		}
-
-		typedFilter, err := typeCheckAndRequireBoolean(ctx, expr.Filter, "FILTER expression")
-		if err != nil {
-			return nil, err
-		}
-		expr.Filter = typedFilter
+	if err := expr.typeCheckFilter(ctx, def); err != nil {
+		return nil, err
 	}
 
 	for i, subExpr := range typedSubExprs {
@@ -918,6 +915,151 @@ func (expr *FuncExpr) TypeCheck(ctx *SemaContext, desired types.T) (TypedExpr, e
 	return expr, nil
 }
 
+// typeCheckFilter type checks the FILTER clause of the function application,
+// if any.
+func (expr *FuncExpr) typeCheckFilter(ctx *SemaContext, def *FunctionDefinition) error {
+	if expr.Filter == nil {
+		return nil
+	}
+	if def.Class != AggregateClass {
+		// Same error message as Postgres. If we have a window function, only
+		// aggregates accept a FILTER clause.
+		return pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"FILTER specified but %s() is not an aggregate function", &expr.Func)
+	}
+
+	typedFilter, err := typeCheckAndRequireBoolean(ctx, expr.Filter, "FILTER expression")
+	if err != nil {
+		return err
+	}
+	expr.Filter = typedFilter
+	return nil
+}
+
+// typeCheckOrderedSetAgg type checks an application of an ordered-set
+// aggregate. It is replaced by an application of the aggregate named by the
+// OrderedSetImpl property of the function, e.g.
+//
+//   percentile_disc(0.5) WITHIN GROUP (ORDER BY k DESC)
+//
+// is replaced by percentile_disc_impl(k, 0.5, true).
+func (expr *FuncExpr) typeCheckOrderedSetAgg(
+	ctx *SemaContext, desired types.T, def *FunctionDefinition,
+) (TypedExpr, error) {
+	switch {
+	case def.Class != AggregateClass:
+		return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"WITHIN GROUP specified, but %s() is not an aggregate function", &expr.Func)
+	case def.OrderedSetImpl == "":
+		return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"%s() is not an ordered-set aggregate, so it cannot have WITHIN GROUP", &expr.Func)
+	case expr.AggType != OrderedSetAgg:
+		return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"WITHIN GROUP is required for ordered-set aggregate %s()", &expr.Func)
+	case expr.IsWindowFunctionApplication():
+		return nil, pgerror.NewErrorf(pgerror.CodeWrongObjectTypeError,
+			"OVER is not supported for ordered-set aggregate %s()", &expr.Func)
+	}
+
+	// The overloads of the ordered-set aggregates take either a fraction or an
+	// array of fractions, so they are tried one at a time: this way, the direct
+	// arguments are typed with the parameter types of the overload, e.g. an
+	// ARRAY[0.25, 0.75] literal as an array of floats.
+	var typedArgs []TypedExpr
+	var fns []overloadImpl
+	var err error
+	for _, o := range def.Definition {
+		typedArgs, fns, err = typeCheckOverloadedExprs(
+			ctx, types.Any, []overloadImpl{o}, false, expr.Exprs...,
+		)
+		if err == nil && len(fns) == 1 {
+			break
+		}
+	}
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgerror.CodeInvalidParameterValueError,
+			"%s()", def.Name)
+	}
+	typedOrder := make([]TypedExpr, len(expr.OrderBy))
+	for i, order := range expr.OrderBy {
+		if order.OrderType != OrderByColumn {
+			return nil, errOrderByIndexInWithinGroup
+		}
+		if typedOrder[i], err = order.Expr.TypeCheck(ctx, types.Any); err != nil {
+			return nil, err
+		}
+		expr.OrderBy[i].Expr = typedOrder[i]
+	}
+
+	// A NULL direct argument is given the parameter type of the chosen overload,
+	// so that it doesn't make the overload of the impl aggregate ambiguous.
+	if len(fns) == 1 {
+		paramTypes := fns[0].params()
+		for i, e := range typedArgs {
+			if e.ResolvedType() == types.Unknown {
+				if typedArgs[i], err = ReType(e, paramTypes.GetAt(i)); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	impl := WrapFunction(def.OrderedSetImpl)
+	implDef := impl.FunctionReference.(*FunctionDefinition)
+	var typedImplArgs []TypedExpr
+	var implFns []overloadImpl
+	if len(fns) == 1 && len(typedOrder) == 1 {
+		implArgs := make(Exprs, 0, len(typedArgs)+2)
+		implArgs = append(implArgs, typedOrder[0])
+		for _, e := range typedArgs {
+			implArgs = append(implArgs, e)
+		}
+		implArgs = append(implArgs, MakeDBool(expr.OrderBy[0].Direction == Descending))
+		typedImplArgs, implFns, err = typeCheckOverloadedExprs(
+			ctx, desired, implDef.Definition, false, implArgs...,
+		)
+		if err != nil {
+			return nil, pgerror.Wrapf(err, pgerror.CodeInvalidParameterValueError,
+				"%s()", def.Name)
+		}
+	}
+	if len(implFns) != 1 {
+		typeNames := make([]string, len(typedArgs))
+		for i, e := range typedArgs {
+			typeNames[i] = e.ResolvedType().String()
+		}
+		orderTypeNames := make([]string, len(typedOrder))
+		for i, e := range typedOrder {
+			orderTypeNames[i] = e.ResolvedType().String()
+		}
+		return nil, pgerror.NewErrorf(pgerror.CodeUndefinedFunctionError,
+			"unknown signature: %s(%s) WITHIN GROUP (ORDER BY %s)", &expr.Func,
+			strings.Join(typeNames, ", "), strings.Join(orderTypeNames, ", "))
+	}
+	overloadImpl := implFns[0].(*Overload)
+
+	if err := expr.typeCheckFilter(ctx, def); err != nil {
+		return nil, err
+	}
+
+	res := &FuncExpr{
+		Func:    impl,
+		Type:    expr.Type,
+		Exprs:   make(Exprs, len(typedImplArgs)),
+		Filter:  expr.Filter,
+		fnProps: &implDef.FunctionProperties,
+		fn:      overloadImpl,
+	}
+	for i, e := range typedImplArgs {
+		res.Exprs[i] = e
+	}
+	res.typ = overloadImpl.returnType()(typedImplArgs)
+	if overloadImpl.counter != nil {
+		telemetry.Inc(overloadImpl.counter)
+	}
+	return res, nil
+}
+
 // TypeCheck checks that offsets of the window frame (if present) are of the
 // appropriate type.
 func (f *WindowFrame) TypeCheck(ctx *SemaContext, windowDef *WindowDef) error {
@@ -1199,6 +1341,12 @@ func (expr DefaultVal) TypeCheck(_ *SemaContext, desired types.T) (TypedExpr, er
 	return nil, errInvalidDefaultUsage
 }
 
+// TypeCheck implements the Expr interface.
+func (expr *GroupingSet) TypeCheck(_ *SemaContext, desired types.T) (TypedExpr, error) {
+	return nil, pgerror.NewErrorf(pgerror.CodeSyntaxError,
+		"grouping sets are only allowed in GROUP BY")
+}
+
 // TypeCheck implements the Expr interface.
 func (expr PartitionMinVal) TypeCheck(_ *SemaContext, desired types.T) (TypedExpr, error) {
 	return nil, errInvalidMinUsage
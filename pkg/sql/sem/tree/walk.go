@@ -307,6 +307,15 @@ func (expr *FuncExpr) Walk(v Visitor) Expr {
 		}
 		ret.Exprs = exprs
 	}
+	if len(expr.OrderBy) > 0 {
+		order, changed := walkOrderBy(v, expr.OrderBy)
+		if changed {
+			if ret == expr {
+				ret = expr.copyNode()
+			}
+			ret.OrderBy = order
+		}
+	}
 	if expr.WindowDef != nil {
 		windowDef, changed := walkWindowDef(v, expr.WindowDef)
 		if changed {
@@ -553,6 +562,16 @@ func (expr *Tuple) Walk(v Visitor) Expr {
 	return expr
 }
 
+// Walk implements the Expr interface.
+func (expr *GroupingSet) Walk(v Visitor) Expr {
+	if exprs, changed := walkExprSlice(v, expr.Exprs); changed {
+		exprCopy := *expr
+		exprCopy.Exprs = exprs
+		return &exprCopy
+	}
+	return expr
+}
+
 // Walk implements the Expr interface.
 func (expr *Array) Walk(v Visitor) Expr {
 	if exprs, changed := walkExprSlice(v, expr.Exprs); changed {
@@ -1339,6 +1358,28 @@ func (stmt *BeginTransaction) walkStmt(v Visitor) Statement {
 	return ret
 }
 
+// copyNode makes a copy of this Statement without recursing in any child Statements.
+func (stmt *Call) copyNode() *Call {
+	stmtCopy := *stmt
+	stmtCopy.Args = append(Exprs(nil), stmt.Args...)
+	return &stmtCopy
+}
+
+// walkStmt is part of the walkableStmt interface.
+func (stmt *Call) walkStmt(v Visitor) Statement {
+	ret := stmt
+	for i, expr := range stmt.Args {
+		e, changed := WalkExpr(v, expr)
+		if changed {
+			if ret == stmt {
+				ret = stmt.copyNode()
+			}
+			ret.Args[i] = e
+		}
+	}
+	return ret
+}
+
 var _ walkableStmt = &CreateTable{}
 var _ walkableStmt = &Backup{}
 var _ walkableStmt = &Delete{}
@@ -1357,6 +1398,7 @@ var _ walkableStmt = &CancelQueries{}
 var _ walkableStmt = &CancelSessions{}
 var _ walkableStmt = &ControlJobs{}
 var _ walkableStmt = &BeginTransaction{}
+var _ walkableStmt = &Call{}
 
 // walkStmt walks the entire parsed stmt calling WalkExpr on each
 // expression, and replacing each expression with the one returned
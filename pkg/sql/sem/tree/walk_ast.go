@@ -0,0 +1,383 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ASTVisitor defines the methods that are called for the nodes of an AST
+// during a WalkAST.
+//
+// Unlike Visitor, which only sees expressions, an ASTVisitor sees every
+// node of the tree: statements, table expressions, expressions, names,
+// etc. Nodes can be replaced using Cursor.Replace.
+type ASTVisitor interface {
+	// VisitPre is called for each node before recursing into its children.
+	// If recurse is false, the children of the node are not visited, and
+	// VisitPost is not called for this node.
+	//
+	// If the node is replaced by VisitPre, the children of the replacement
+	// are visited instead.
+	VisitPre(c *Cursor) (recurse bool)
+
+	// VisitPost is called for each node after its children have been
+	// visited. At that point, Cursor.Node reflects the rewrites performed
+	// on the children.
+	VisitPost(c *Cursor)
+}
+
+// ASTVisitorFuncs is an ASTVisitor implemented by a pair of functions. Pre
+// and Post can be nil, in which case all the nodes are recursed into and
+// VisitPost does nothing.
+type ASTVisitorFuncs struct {
+	Pre  func(c *Cursor) (recurse bool)
+	Post func(c *Cursor)
+}
+
+var _ ASTVisitor = ASTVisitorFuncs{}
+
+// VisitPre implements the ASTVisitor interface.
+func (f ASTVisitorFuncs) VisitPre(c *Cursor) bool {
+	if f.Pre == nil {
+		return true
+	}
+	return f.Pre(c)
+}
+
+// VisitPost implements the ASTVisitor interface.
+func (f ASTVisitorFuncs) VisitPost(c *Cursor) {
+	if f.Post != nil {
+		f.Post(c)
+	}
+}
+
+// Cursor describes a node encountered during a WalkAST, together with its
+// position in the tree.
+type Cursor struct {
+	w *astWalker
+
+	node     NodeFormatter
+	name     string
+	index    int
+	typ      reflect.Type
+	replaced bool
+}
+
+// Node returns the current node. If the node has been replaced, this is
+// the replacement.
+//
+// Nodes which are held by value in their parent (e.g. a Name field) are
+// presented as a pointer to a copy of the value. Modifying the node in
+// place has no effect on the tree; use Replace instead.
+func (c *Cursor) Node() NodeFormatter { return c.node }
+
+// Parent returns the closest ancestor of the current node, or nil if the
+// current node is the root of the walk. The parent is given as it was
+// before any of its children were rewritten.
+func (c *Cursor) Parent() NodeFormatter {
+	if n := len(c.w.stack); n > 0 {
+		return c.w.stack[n-1]
+	}
+	return nil
+}
+
+// Ancestors returns all the ancestors of the current node, from the root of
+// the walk to the parent.
+func (c *Cursor) Ancestors() []NodeFormatter {
+	return append([]NodeFormatter(nil), c.w.stack...)
+}
+
+// Name returns the name of the struct field holding the current node, or
+// of the struct field holding the slice which holds the current node. It is
+// empty for the root, and for the elements of slices which are nodes
+// themselves (e.g. the elements of an Exprs).
+func (c *Cursor) Name() string { return c.name }
+
+// Index returns the index of the current node in the slice holding it, or
+// -1 if the node is not held by a slice.
+func (c *Cursor) Index() int { return c.index }
+
+// Replace replaces the current node with n. The replacement must be valid
+// in the position of the current node; for example, an Expr held in a
+// field of type Expr can be replaced with another Expr, but a *TableName
+// held in a field of type TableName can only be replaced with another
+// *TableName. Nodes in optional positions (i.e. interface or pointer fields)
+// can be removed by passing nil. Replace panics if n is not valid in the
+// position of the current node.
+//
+// The node and its ancestors are never modified in place: the ancestors of
+// rewritten nodes are copied, so that the tree passed to WalkAST remains
+// untouched.
+func (c *Cursor) Replace(n NodeFormatter) {
+	// Check that the replacement fits.
+	_ = c.w.convert(n, c.typ)
+	c.node = n
+	c.replaced = true
+}
+
+// WalkAST walks the AST rooted at node in depth-first order, calling the
+// methods of v for every node. It returns the resulting tree, which is
+// node itself if no node was replaced; otherwise, it is a copy sharing all
+// the subtrees which were not rewritten.
+//
+// The walk recurses into the exported fields of the nodes defined in this
+// package. Datums, function definitions and the values of types defined in
+// other packages (e.g. column types) are visited if they are nodes, but not
+// recursed into.
+func WalkAST(v ASTVisitor, node NodeFormatter) NodeFormatter {
+	if node == nil {
+		return nil
+	}
+	w := astWalker{v: v}
+	// The root can be replaced with any node.
+	res, changed := w.walk(reflect.ValueOf(&node).Elem(), "", -1)
+	if !changed {
+		return node
+	}
+	if res.IsNil() {
+		return nil
+	}
+	return res.Interface().(NodeFormatter)
+}
+
+// WalkStatementAST is like WalkAST for a statement. It panics if the
+// statement is replaced with a node which is not a Statement.
+func WalkStatementAST(v ASTVisitor, stmt Statement) Statement {
+	if stmt == nil {
+		return nil
+	}
+	res := WalkAST(v, stmt)
+	if res == nil {
+		return nil
+	}
+	return res.(Statement)
+}
+
+var (
+	nodeFormatterType  = reflect.TypeOf((*NodeFormatter)(nil)).Elem()
+	astDatumType       = reflect.TypeOf((*Datum)(nil)).Elem()
+	astFunctionDefType = reflect.TypeOf((*FunctionDefinition)(nil))
+	astPkgPath         = reflect.TypeOf(Name("")).PkgPath()
+)
+
+type astWalker struct {
+	v ASTVisitor
+	// stack contains the ancestors of the node being visited.
+	stack []NodeFormatter
+}
+
+// opaque returns true if WalkAST must not recurse into values of type t.
+func (w *astWalker) opaque(t reflect.Type) bool {
+	if t == astFunctionDefType || t.Implements(astDatumType) {
+		return true
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.PkgPath() != astPkgPath
+}
+
+// asNode returns the node represented by v, if any. byRef is true if v is
+// not a node itself but a pointer to it is, in which case the node is a
+// pointer to a copy of v.
+func (w *astWalker) asNode(v reflect.Value) (node NodeFormatter, byRef bool) {
+	t := v.Type()
+	if (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) && v.IsNil() {
+		// Absent nodes are not visited.
+		return nil, false
+	}
+	if t.Implements(nodeFormatterType) {
+		return v.Interface().(NodeFormatter), false
+	}
+	if t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(nodeFormatterType) {
+		p := reflect.New(t)
+		p.Elem().Set(v)
+		return p.Interface().(NodeFormatter), true
+	}
+	return nil, false
+}
+
+// convert returns the value to store in a position of type t for the node
+// n. It panics if n is not valid in a position of type t.
+func (w *astWalker) convert(n NodeFormatter, t reflect.Type) reflect.Value {
+	if n == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice:
+			return reflect.Zero(t)
+		}
+		panic(fmt.Sprintf("cannot remove a node of type %s", t))
+	}
+	v := reflect.ValueOf(n)
+	if v.Type().AssignableTo(t) {
+		return v
+	}
+	if v.Kind() == reflect.Ptr && v.Type().Elem() == t {
+		if v.IsNil() {
+			panic(fmt.Sprintf("cannot replace a node of type %s with nil", t))
+		}
+		return v.Elem()
+	}
+	panic(fmt.Sprintf("cannot replace a node of type %s with %T", t, n))
+}
+
+// walk visits v, which is stored in a position of type v.Type() in the
+// field name of its parent, at position index if the field is a slice. It
+// returns the value to store in place of v, and whether it differs from v.
+func (w *astWalker) walk(v reflect.Value, name string, index int) (reflect.Value, bool) {
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return v, false
+		}
+		// The node is the dynamic value, but replacements must be valid for
+		// the static type of the position.
+		res, changed := w.walkValue(v.Elem(), v.Type(), name, index)
+		if !changed {
+			return v, false
+		}
+		iface := reflect.New(v.Type()).Elem()
+		if res.IsValid() {
+			iface.Set(res)
+		}
+		return iface, true
+	}
+	return w.walkValue(v, v.Type(), name, index)
+}
+
+// walkValue is like walk for a value v which is not an interface, stored in
+// a position of type t.
+func (w *astWalker) walkValue(
+	v reflect.Value, t reflect.Type, name string, index int,
+) (reflect.Value, bool) {
+	node, byRef := w.asNode(v)
+	if node == nil {
+		return w.walkChildren(v, name)
+	}
+
+	c := Cursor{w: w, node: node, name: name, index: index, typ: t}
+	if !w.v.VisitPre(&c) {
+		if !c.replaced {
+			return v, false
+		}
+		return w.convert(c.node, t), true
+	}
+	changed := c.replaced
+	if c.node == nil {
+		return w.convert(nil, t), true
+	}
+
+	// Recurse into the children of the node, or of its replacement.
+	cur := reflect.ValueOf(c.node)
+	if !changed && byRef {
+		cur = v
+	}
+	w.stack = append(w.stack, c.node)
+	res, childChanged := w.walkChildren(cur, "" /* name */)
+	w.stack = w.stack[:len(w.stack)-1]
+	if childChanged {
+		changed = true
+		if res.Kind() == reflect.Ptr {
+			c.node = res.Interface().(NodeFormatter)
+		} else if n, _ := w.asNode(res); n != nil {
+			c.node = n
+		}
+	}
+
+	c.replaced = false
+	w.v.VisitPost(&c)
+	if !changed && !c.replaced {
+		return v, false
+	}
+	return w.convert(c.node, t), true
+}
+
+// walkChildren visits the children of v, which is either a node or a value
+// containing nodes (e.g. a slice of nodes). name is the name of the field
+// holding v, if v is not a node itself.
+func (w *astWalker) walkChildren(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	switch v.Kind() {
+	case reflect.Interface:
+		return w.walk(v, name, -1)
+
+	case reflect.Ptr:
+		if v.IsNil() || w.opaque(t) {
+			return v, false
+		}
+		res, changed := w.walkChildren(v.Elem(), name)
+		if !changed {
+			return v, false
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(res)
+		return p, true
+
+	case reflect.Struct:
+		if w.opaque(t) {
+			return v, false
+		}
+		var res reflect.Value
+		for i := 0; i < v.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				// Unexported fields are not part of the AST.
+				continue
+			}
+			nf, changed := w.walk(v.Field(i), f.Name, -1)
+			if !changed {
+				continue
+			}
+			if !res.IsValid() {
+				res = reflect.New(t).Elem()
+				res.Set(v)
+			}
+			res.Field(i).Set(nf)
+		}
+		if !res.IsValid() {
+			return v, false
+		}
+		return res, true
+
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return v, false
+		}
+		var res reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			ne, changed := w.walk(v.Index(i), name, i)
+			if !changed {
+				continue
+			}
+			if !res.IsValid() {
+				if v.Kind() == reflect.Slice {
+					res = reflect.MakeSlice(t, v.Len(), v.Len())
+					reflect.Copy(res, v)
+				} else {
+					res = reflect.New(t).Elem()
+					res.Set(v)
+				}
+			}
+			res.Index(i).Set(ne)
+		}
+		if !res.IsValid() {
+			return v, false
+		}
+		return res, true
+
+	default:
+		return v, false
+	}
+}
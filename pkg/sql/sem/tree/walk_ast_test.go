@@ -0,0 +1,196 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tree_test
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+func parseForWalk(t *testing.T, sql string) tree.Statement {
+	t.Helper()
+	stmt, err := parser.ParseOne(sql)
+	if err != nil {
+		t.Fatalf("%s: %v", sql, err)
+	}
+	return stmt.AST
+}
+
+func TestWalkASTQualifyTableNames(t *testing.T) {
+	qualify := tree.ASTVisitorFuncs{
+		Post: func(c *tree.Cursor) {
+			if tn, ok := c.Node().(*tree.TableName); ok && !tn.ExplicitSchema {
+				qualified := tree.MakeTableNameWithSchema("db", "public", tn.TableName)
+				c.Replace(&qualified)
+			}
+		},
+	}
+
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{`SELECT a FROM t`, `SELECT a FROM db.public.t`},
+		{`SELECT a FROM t, (SELECT b FROM u WHERE c IN (SELECT d FROM v)) AS s`,
+			`SELECT a FROM db.public.t, (SELECT b FROM db.public.u WHERE c IN (SELECT d FROM db.public.v)) AS s`},
+		{`SELECT a FROM other.public.t JOIN u USING (a)`,
+			`SELECT a FROM other.public.t JOIN db.public.u USING (a)`},
+		{`INSERT INTO t SELECT * FROM u`, `INSERT INTO db.public.t SELECT * FROM db.public.u`},
+		{`DELETE FROM t WHERE a = 1`, `DELETE FROM db.public.t WHERE a = 1`},
+		{`SELECT 1`, `SELECT 1`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			stmt := parseForWalk(t, d.sql)
+			before := stmt.String()
+			res := tree.WalkStatementAST(qualify, stmt)
+			if s := res.String(); s != parseForWalk(t, d.expected).String() {
+				t.Errorf("expected %s, got %s", d.expected, s)
+			}
+			// The input tree must not be modified.
+			if s := stmt.String(); s != before {
+				t.Errorf("input modified: expected %s, got %s", before, s)
+			}
+			if d.sql == d.expected && res != stmt {
+				t.Errorf("expected the input to be returned as is when nothing is replaced")
+			}
+		})
+	}
+}
+
+func TestWalkASTReplaceLiterals(t *testing.T) {
+	placeholderize := func() tree.ASTVisitor {
+		n := 0
+		return tree.ASTVisitorFuncs{
+			Pre: func(c *tree.Cursor) bool {
+				switch c.Node().(type) {
+				case *tree.NumVal, *tree.StrVal:
+					n++
+					p, err := tree.NewPlaceholder(strconv.Itoa(n))
+					if err != nil {
+						panic(err)
+					}
+					c.Replace(p)
+					return false
+				}
+				return true
+			},
+		}
+	}
+
+	testData := []struct {
+		sql      string
+		expected string
+	}{
+		{`SELECT a + 1 FROM t WHERE b = 'foo' AND c IN (2, 3)`,
+			`SELECT a + $1 FROM t WHERE b = $2 AND c IN ($3, $4)`},
+		{`INSERT INTO t VALUES (1, 'a'), (2, 'b')`, `INSERT INTO t VALUES ($1, $2), ($3, $4)`},
+		{`UPDATE t SET a = 1 WHERE b > 2 LIMIT 3`, `UPDATE t SET a = $1 WHERE b > $2 LIMIT $3`},
+		{`SELECT CASE WHEN a THEN 'x' ELSE 'y' END FROM t`,
+			`SELECT CASE WHEN a THEN $1 ELSE $2 END FROM t`},
+	}
+	for _, d := range testData {
+		t.Run(d.sql, func(t *testing.T) {
+			res := tree.WalkStatementAST(placeholderize(), parseForWalk(t, d.sql))
+			if s := res.String(); s != parseForWalk(t, d.expected).String() {
+				t.Errorf("expected %s, got %s", d.expected, s)
+			}
+		})
+	}
+}
+
+func TestWalkASTParents(t *testing.T) {
+	stmt := parseForWalk(t, `SELECT a, f(b) FROM t WHERE c > 1`)
+
+	var positions []string
+	tree.WalkStatementAST(tree.ASTVisitorFuncs{
+		Pre: func(c *tree.Cursor) bool {
+			if n, ok := c.Node().(*tree.UnresolvedName); ok {
+				positions = append(positions, fmt.Sprintf("%s: %T %s %d (depth %d)",
+					n, c.Parent(), c.Name(), c.Index(), len(c.Ancestors())))
+			}
+			return true
+		},
+	}, stmt)
+
+	expected := []string{
+		`a: *tree.SelectExpr Expr -1 (depth 4)`,
+		`f: *tree.ResolvableFunctionReference FunctionReference -1 (depth 6)`,
+		`b: *tree.Exprs  0 (depth 6)`,
+		`c: *tree.ComparisonExpr Left -1 (depth 4)`,
+	}
+	if !reflect.DeepEqual(positions, expected) {
+		t.Errorf("expected:\n%q\ngot:\n%q", expected, positions)
+	}
+}
+
+func TestWalkASTPruneAndRemove(t *testing.T) {
+	stmt := parseForWalk(t, `SELECT a FROM t WHERE a IN (SELECT b FROM u WHERE c)`)
+
+	// Names in subqueries are not visited when the walk does not recurse
+	// into them.
+	var names []string
+	tree.WalkStatementAST(tree.ASTVisitorFuncs{
+		Pre: func(c *tree.Cursor) bool {
+			switch n := c.Node().(type) {
+			case *tree.Subquery:
+				return false
+			case *tree.UnresolvedName:
+				names = append(names, n.String())
+			}
+			return true
+		},
+	}, stmt)
+	if expected := []string{"a", "a"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v, got %v", expected, names)
+	}
+
+	// Optional nodes can be removed.
+	res := tree.WalkStatementAST(tree.ASTVisitorFuncs{
+		Pre: func(c *tree.Cursor) bool {
+			if _, ok := c.Node().(*tree.Where); ok {
+				c.Replace(nil)
+				return false
+			}
+			return true
+		},
+	}, stmt)
+	if s, expected := res.String(), `SELECT a FROM t`; s != expected {
+		t.Errorf("expected %s, got %s", expected, s)
+	}
+}
+
+func TestWalkASTInvalidReplacement(t *testing.T) {
+	stmt := parseForWalk(t, `SELECT a FROM t`)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected a panic")
+		}
+	}()
+	tree.WalkStatementAST(tree.ASTVisitorFuncs{
+		Pre: func(c *tree.Cursor) bool {
+			if _, ok := c.Node().(*tree.TableName); ok {
+				// A table name cannot be replaced with an expression.
+				c.Replace(tree.NewDInt(1))
+			}
+			return true
+		},
+	}, stmt)
+}
@@ -258,6 +258,9 @@ func (wfr *WindowFrameRun) IsDefaultFrame() bool {
 	if wfr.Frame == nil {
 		return true
 	}
+	if wfr.Frame.Exclusion != NoExclusion {
+		return false
+	}
 	if wfr.Frame.Bounds.StartBound.BoundType == UnboundedPreceding {
 		return wfr.Frame.Bounds.EndBound == nil || wfr.Frame.Bounds.EndBound.BoundType == CurrentRow
 	}
@@ -439,11 +442,82 @@ func (wfr *WindowFrameRun) FrameSize(ctx context.Context, evalCtx *EvalContext)
 	}
 	size := frameEndIdx - frameStartIdx
 	if size <= 0 {
-		size = 0
+		return 0, nil
+	}
+	if wfr.FrameHasExclusions() {
+		size -= wfr.numExcludedRows(frameStartIdx, frameEndIdx)
 	}
 	return size, nil
 }
 
+// FrameHasExclusions returns whether the frame of the window excludes some of
+// the rows within its bounds.
+func (wfr *WindowFrameRun) FrameHasExclusions() bool {
+	return wfr.Frame != nil && wfr.Frame.Exclusion != NoExclusion
+}
+
+// FrameExclusionDependsOnCurrentRow returns whether the rows excluded from
+// the frame differ between the current row and its peers, which is the case
+// for EXCLUDE CURRENT ROW and EXCLUDE TIES.
+func (wfr *WindowFrameRun) FrameExclusionDependsOnCurrentRow() bool {
+	return wfr.Frame != nil &&
+		(wfr.Frame.Exclusion == ExcludeCurrentRow || wfr.Frame.Exclusion == ExcludeTies)
+}
+
+// IsRowExcluded returns whether the row at index idx is excluded from the
+// frame of the current row by the frame exclusion clause.
+func (wfr *WindowFrameRun) IsRowExcluded(idx int) bool {
+	if !wfr.FrameHasExclusions() {
+		return false
+	}
+	switch wfr.Frame.Exclusion {
+	case ExcludeCurrentRow:
+		return idx == wfr.RowIdx
+	case ExcludeGroup, ExcludeTies:
+		if wfr.Frame.Exclusion == ExcludeTies && idx == wfr.RowIdx {
+			// Spec: EXCLUDE TIES excludes the peers of the current row, but not
+			// the current row itself.
+			return false
+		}
+		firstPeerIdx := wfr.PeerHelper.GetFirstPeerIdx(wfr.CurRowPeerGroupNum)
+		return idx >= firstPeerIdx && idx < firstPeerIdx+wfr.PeerHelper.GetRowCount(wfr.CurRowPeerGroupNum)
+	default:
+		panic(pgerror.NewAssertionErrorf(
+			"unexpected WindowFrameExclusion: %d", log.Safe(wfr.Frame.Exclusion)))
+	}
+}
+
+// numExcludedRows returns the number of rows with indices in [startIdx,
+// endIdx) that are excluded from the frame of the current row. The excluded
+// rows are always peers of the current row, so they occupy a contiguous range
+// of indices (apart from the current row itself in case of EXCLUDE TIES).
+func (wfr *WindowFrameRun) numExcludedRows(startIdx, endIdx int) int {
+	var exclStartIdx, exclEndIdx int
+	switch wfr.Frame.Exclusion {
+	case ExcludeCurrentRow:
+		exclStartIdx, exclEndIdx = wfr.RowIdx, wfr.RowIdx+1
+	case ExcludeGroup, ExcludeTies:
+		exclStartIdx = wfr.PeerHelper.GetFirstPeerIdx(wfr.CurRowPeerGroupNum)
+		exclEndIdx = exclStartIdx + wfr.PeerHelper.GetRowCount(wfr.CurRowPeerGroupNum)
+	default:
+		return 0
+	}
+	if exclStartIdx < startIdx {
+		exclStartIdx = startIdx
+	}
+	if exclEndIdx > endIdx {
+		exclEndIdx = endIdx
+	}
+	if exclStartIdx >= exclEndIdx {
+		return 0
+	}
+	num := exclEndIdx - exclStartIdx
+	if wfr.Frame.Exclusion == ExcludeTies && wfr.RowIdx >= exclStartIdx && wfr.RowIdx < exclEndIdx {
+		num--
+	}
+	return num
+}
+
 // Rank returns the rank of the current row.
 func (wfr *WindowFrameRun) Rank() int {
 	return wfr.RowIdx + 1
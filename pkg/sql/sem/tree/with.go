@@ -16,28 +16,52 @@ package tree
 
 // With represents a WITH statement.
 type With struct {
-	CTEList []*CTE
+	// Recursive is set for WITH RECURSIVE, in which the common table
+	// expressions can refer to themselves.
+	Recursive bool
+	CTEList   []*CTE
 }
 
 // CTE represents a common table expression inside of a WITH clause.
 type CTE struct {
 	Name AliasClause
+	Mtr  MaterializeClause
 	Stmt Statement
 }
 
+// MaterializeClause represents the [NOT] MATERIALIZED modifier of a CTE.
+type MaterializeClause struct {
+	// Set is true if the modifier was specified, in which case Materialize
+	// overrides the default behavior.
+	Set bool
+	// Materialize is true for MATERIALIZED and false for NOT MATERIALIZED.
+	Materialize bool
+}
+
 // Format implements the NodeFormatter interface.
 func (node *With) Format(ctx *FmtCtx) {
 	if node == nil {
 		return
 	}
 	ctx.WriteString("WITH ")
+	if node.Recursive {
+		ctx.WriteString("RECURSIVE ")
+	}
 	for i, cte := range node.CTEList {
 		if i != 0 {
 			ctx.WriteString(", ")
 		}
 		ctx.FormatNode(&cte.Name)
-		ctx.WriteString(" AS (")
+		ctx.WriteString(" AS ")
+		if cte.Mtr.Set {
+			if !cte.Mtr.Materialize {
+				ctx.WriteString("NOT ")
+			}
+			ctx.WriteString("MATERIALIZED ")
+		}
+		ctx.WriteString("(")
 		ctx.FormatNode(cte.Stmt)
-		ctx.WriteString(") ")
+		ctx.WriteString(")")
 	}
+	ctx.WriteByte(' ')
 }
@@ -42,7 +42,8 @@ var virtualSequenceOpts = tree.SequenceOptions{
 }
 
 // processSerialInColumnDef analyzes a column definition and determines
-// whether to use a sequence if the requested type is SERIAL-like.
+// whether to use a sequence if the requested type is SERIAL-like, or if
+// the column is an identity column.
 // If a sequence must be created, it returns an ObjectName to use
 // to create the new sequence and the DatabaseDescriptor of the
 // parent database where it should be created.
@@ -50,6 +51,10 @@ var virtualSequenceOpts = tree.SequenceOptions{
 func (p *planner) processSerialInColumnDef(
 	ctx context.Context, d *tree.ColumnTableDef, tableName *ObjectName,
 ) (*tree.ColumnTableDef, *DatabaseDescriptor, *ObjectName, tree.SequenceOptions, error) {
+	if d.IsIdentity() {
+		return p.processIdentityInColumnDef(ctx, d, tableName)
+	}
+
 	t, ok := d.Type.(*coltypes.TSerial)
 	if !ok {
 		// Column is not SERIAL: nothing to do.
@@ -96,8 +101,64 @@ func (p *planner) processSerialInColumnDef(
 
 	log.VEventf(ctx, 2, "creating sequence for new column %q of %q", d, tableName)
 
-	// We want a sequence; for this we need to generate a new sequence name.
-	// The constraint on the name is that an object of this name must not exist already.
+	dbDesc, seqName, err := p.makeColumnSequenceName(ctx, d, tableName)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	defaultExpr := makeNextvalExpr(seqName)
+
+	seqType := ""
+	seqOpts := realSequenceOpts
+	if serialNormalizationMode == sessiondata.SerialUsesVirtualSequences {
+		seqType = "virtual "
+		seqOpts = virtualSequenceOpts
+	}
+	log.VEventf(ctx, 2, "new column %q of %q will have %ssequence name %q and default %q",
+		d, tableName, seqType, seqName, defaultExpr)
+
+	newSpec.DefaultExpr.Expr = defaultExpr
+
+	return &newSpec, dbDesc, seqName, seqOpts, nil
+}
+
+// processIdentityInColumnDef is the part of processSerialInColumnDef for
+// identity columns. An identity column always gets its values from a new SQL
+// sequence, created with the sequence options of the column definition,
+// regardless of the serial normalization mode.
+func (p *planner) processIdentityInColumnDef(
+	ctx context.Context, d *tree.ColumnTableDef, tableName *ObjectName,
+) (*tree.ColumnTableDef, *DatabaseDescriptor, *ObjectName, tree.SequenceOptions, error) {
+	if err := assertValidIdentityColumnDef(d, tableName); err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	newSpec := *d
+
+	// Identity columns are implicitly NOT NULL, as in PostgreSQL.
+	newSpec.Nullable.Nullability = tree.NotNull
+
+	telemetry.Inc(sqltelemetry.IdentityColumnCounter)
+
+	log.VEventf(ctx, 2, "creating sequence for new identity column %q of %q", d, tableName)
+
+	dbDesc, seqName, err := p.makeColumnSequenceName(ctx, d, tableName)
+	if err != nil {
+		return nil, nil, nil, nil, err
+	}
+
+	newSpec.DefaultExpr.Expr = makeNextvalExpr(seqName)
+
+	return &newSpec, dbDesc, seqName, d.Identity.SeqOptions, nil
+}
+
+// makeColumnSequenceName generates the name of the sequence created for a
+// SERIAL or identity column, along with the DatabaseDescriptor of the parent
+// database where it should be created. The constraint on the name is that
+// an object of this name must not exist already.
+func (p *planner) makeColumnSequenceName(
+	ctx context.Context, d *tree.ColumnTableDef, tableName *ObjectName,
+) (*DatabaseDescriptor, *ObjectName, error) {
 	seqName := tree.NewUnqualifiedTableName(
 		tree.Name(tableName.Table() + "_" + string(d.Name) + "_seq"))
 
@@ -109,7 +170,7 @@ func (p *planner) processSerialInColumnDef(
 	// descriptor was written already in an early txn attempt.
 	dbDesc, err := p.ResolveUncachedDatabase(ctx, seqName)
 	if err != nil {
-		return nil, nil, nil, nil, err
+		return nil, nil, err
 	}
 	// Now skip over all names that are already taken.
 	nameBase := seqName.TableName
@@ -119,30 +180,22 @@ func (p *planner) processSerialInColumnDef(
 		}
 		res, err := p.ResolveUncachedTableDescriptor(ctx, seqName, false /*required*/, anyDescType)
 		if err != nil {
-			return nil, nil, nil, nil, err
+			return nil, nil, err
 		}
 		if res == nil {
 			break
 		}
 	}
+	return dbDesc, seqName, nil
+}
 
-	defaultExpr := &tree.FuncExpr{
+// makeNextvalExpr returns the default expression of a column taking its
+// values from the given sequence.
+func makeNextvalExpr(seqName *ObjectName) tree.Expr {
+	return &tree.FuncExpr{
 		Func:  tree.WrapFunction("nextval"),
 		Exprs: tree.Exprs{tree.NewStrVal(seqName.Table())},
 	}
-
-	seqType := ""
-	seqOpts := realSequenceOpts
-	if serialNormalizationMode == sessiondata.SerialUsesVirtualSequences {
-		seqType = "virtual "
-		seqOpts = virtualSequenceOpts
-	}
-	log.VEventf(ctx, 2, "new column %q of %q will have %ssequence name %q and default %q",
-		d, tableName, seqType, seqName, defaultExpr)
-
-	newSpec.DefaultExpr.Expr = defaultExpr
-
-	return &newSpec, dbDesc, seqName, seqOpts, nil
 }
 
 // SimplifySerialInColumnDefWithRowID analyzes a column definition and
@@ -201,3 +254,39 @@ func assertValidSerialColumnDef(d *tree.ColumnTableDef, tableName *ObjectName) e
 
 	return nil
 }
+
+func assertValidIdentityColumnDef(d *tree.ColumnTableDef, tableName *ObjectName) error {
+	if d.HasDefaultExpr() {
+		// This is the error produced by pg in such case.
+		return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"both default and identity specified for column %q of table %q",
+			tree.ErrString(&d.Name), tree.ErrString(tableName))
+	}
+
+	if d.Nullable.Nullability == tree.Null {
+		return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"conflicting NULL/NOT NULL declarations for column %q of table %q",
+			tree.ErrString(&d.Name), tree.ErrString(tableName))
+	}
+
+	if d.IsComputed() {
+		return pgerror.NewErrorf(pgerror.CodeSyntaxError,
+			"both generation expression and identity specified for column %q of table %q",
+			tree.ErrString(&d.Name), tree.ErrString(tableName))
+	}
+
+	if _, ok := d.Type.(*coltypes.TInt); !ok {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"identity column type must be INT2, INT4 or INT8, not %s", d.Type)
+	}
+
+	for _, opt := range d.Identity.SeqOptions {
+		if opt.Name == tree.SeqOptVirtual {
+			return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"identity column %q of table %q cannot use a virtual sequence",
+				tree.ErrString(&d.Name), tree.ErrString(tableName))
+		}
+	}
+
+	return nil
+}
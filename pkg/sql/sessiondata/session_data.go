@@ -52,11 +52,17 @@ type SessionData struct {
 	// StmtTimeout is the duration a query is permitted to run before it is
 	// canceled by the session. If set to 0, there is no timeout.
 	StmtTimeout time.Duration
+	// TraceParent is the W3C Trace Context provided by the client. If set, the
+	// traces of the session's transactions are part of the client's trace.
+	TraceParent string
 	// User is the name of the user logged into the session.
 	User string
 	// SafeUpdates causes errors when the client
 	// sends syntax that may have unwanted side effects.
 	SafeUpdates bool
+	// EnforceGatewayRegion causes errors for statements which access tables
+	// whose replicas are not all constrained to the region of the gateway.
+	EnforceGatewayRegion bool
 	// RemoteAddr is used to generate logging events.
 	RemoteAddr net.Addr
 	// ZigzagJoinEnabled indicates whether the optimizer should try and plan a
@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/stats"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/retry"
 	"github.com/pkg/errors"
 )
@@ -179,7 +180,13 @@ func (n *setClusterSettingNode) startExec(params runParams) error {
 			EventLogSetClusterSetting,
 			0, /* no target */
 			int32(params.extendedEvalCtx.NodeID),
-			EventLogSetClusterSettingDetail{n.name, reportedValue, params.SessionData().User},
+			&eventpb.SetClusterSetting{
+				SettingName: n.name,
+				Value:       reportedValue,
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					User: params.SessionData().User,
+				},
+			},
 		)
 	}); err != nil {
 		return err
@@ -29,6 +29,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/gogo/protobuf/proto"
 	yaml "gopkg.in/yaml.v2"
@@ -414,6 +415,12 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 			return err
 		}
 
+		// Validate that the replicas stay inside the super regions in which
+		// the zone is homed.
+		if err := validateZoneSuperRegions(&params.ExecCfg().Settings.SV, &newZone); err != nil {
+			return err
+		}
+
 		// Are we operating on an index?
 		if index == nil {
 			// No: the final zone config is the one we just processed.
@@ -489,22 +496,21 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 	}
 
 	// Record that the change has occurred for auditing.
-	var eventLogType EventLogType
-	info := struct {
-		Target  string
-		Config  string `json:",omitempty"`
-		Options string `json:",omitempty"`
-		User    string
-	}{
-		Target:  config.CLIZoneSpecifier(&n.zoneSpecifier),
-		Config:  strings.TrimSpace(yamlConfig),
-		Options: optionStr.String(),
-		User:    params.SessionData().User,
+	target := config.CLIZoneSpecifier(&n.zoneSpecifier)
+	sqlDetails := eventpb.CommonSQLEventDetails{User: params.SessionData().User}
+	eventLogType := EventLogSetZoneConfig
+	var event eventpb.EventPayload = &eventpb.SetZoneConfig{
+		Target:                target,
+		Config:                strings.TrimSpace(yamlConfig),
+		Options:               optionStr.String(),
+		CommonSQLEventDetails: sqlDetails,
 	}
 	if deleteZone {
 		eventLogType = EventLogRemoveZoneConfig
-	} else {
-		eventLogType = EventLogSetZoneConfig
+		event = &eventpb.RemoveZoneConfig{
+			Target:                target,
+			CommonSQLEventDetails: sqlDetails,
+		}
 	}
 	return MakeEventLogger(params.extendedEvalCtx.ExecCfg).InsertEventRecord(
 		params.ctx,
@@ -512,7 +518,7 @@ func (n *setZoneConfigNode) startExec(params runParams) error {
 		eventLogType,
 		int32(targetID),
 		int32(params.extendedEvalCtx.NodeID),
-		info,
+		event,
 	)
 }
 
@@ -57,6 +57,9 @@ func ShowCreateView(
 	}
 	f.WriteString(") AS ")
 	f.WriteString(desc.ViewQuery)
+	if desc.ViewCheckOption {
+		f.WriteString(" WITH CHECK OPTION")
+	}
 	return f.CloseAndGetString(), nil
 }
 
@@ -0,0 +1,151 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/lex"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+)
+
+const (
+	showEventsOptType    = "type"
+	showEventsOptChannel = "channel"
+	showEventsOptTarget  = "target"
+	showEventsOptSince   = "since"
+)
+
+var showEventsOptionExpectValues = map[string]KVStringOptValidate{
+	showEventsOptType:    KVStringOptRequireValue,
+	showEventsOptChannel: KVStringOptRequireValue,
+	showEventsOptTarget:  KVStringOptRequireValue,
+	showEventsOptSince:   KVStringOptRequireValue,
+}
+
+// ShowEvents returns the events of the event log, most recent first, along
+// with their logging channel. The events can be filtered by type, channel,
+// target and age.
+// Privileges: superuser.
+func (p *planner) ShowEvents(ctx context.Context, n *tree.ShowEvents) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "SHOW EVENTS"); err != nil {
+		return nil, err
+	}
+	optsFn, err := p.TypeAsStringOpts(n.Options, showEventsOptionExpectValues)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := optsFn()
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []string
+	if types, ok := opts[showEventsOptType]; ok {
+		var list []string
+		for _, t := range strings.Split(types, ",") {
+			t = strings.TrimSpace(t)
+			if _, ok := EventLogChannel(EventLogType(t)); !ok {
+				return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+					"unknown event type: %q", t)
+			}
+			list = append(list, lex.EscapeSQLString(t))
+		}
+		filters = append(filters, fmt.Sprintf("event_type IN (%s)", strings.Join(list, ", ")))
+	}
+	if channels, ok := opts[showEventsOptChannel]; ok {
+		var list []string
+		for _, c := range strings.Split(channels, ",") {
+			c = strings.ToUpper(strings.TrimSpace(c))
+			if _, ok := eventChannelsByName[c]; !ok {
+				return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+					"unknown channel: %q", c)
+			}
+			list = append(list, lex.EscapeSQLString(c))
+		}
+		filters = append(filters, fmt.Sprintf("channel IN (%s)", strings.Join(list, ", ")))
+	}
+	if target, ok := opts[showEventsOptTarget]; ok {
+		id, err := strconv.ParseInt(target, 10, 32)
+		if err != nil {
+			return nil, pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+				"invalid target: %q", target)
+		}
+		filters = append(filters, fmt.Sprintf("target_id = %d", id))
+	}
+	if since, ok := opts[showEventsOptSince]; ok {
+		if _, err := tree.ParseDInterval(since); err != nil {
+			return nil, err
+		}
+		filters = append(filters, fmt.Sprintf("timestamp >= now() - %s::INTERVAL",
+			lex.EscapeSQLString(since)))
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`SELECT * FROM (
+  SELECT timestamp, "eventType" AS event_type, `)
+	buf.WriteString(eventChannelExpr())
+	buf.WriteString(` AS channel,
+    "targetID" AS target_id, "reportingID" AS reporting_id, info::JSONB AS info
+  FROM system.eventlog
+)`)
+	if len(filters) > 0 {
+		buf.WriteString(" WHERE ")
+		buf.WriteString(strings.Join(filters, " AND "))
+	}
+	buf.WriteString(" ORDER BY timestamp DESC")
+	return p.delegateQuery(ctx, "SHOW EVENTS", buf.String(), nil, nil)
+}
+
+var eventChannelsByName = func() map[string]eventpb.Channel {
+	m := make(map[string]eventpb.Channel, len(eventpb.Channels))
+	for _, c := range eventpb.Channels {
+		m[c.String()] = c
+	}
+	return m
+}()
+
+// eventChannelExpr returns a SQL expression computing the logging channel
+// of the event types registered with RegisterEventType from the eventType
+// column of system.eventlog.
+func eventChannelExpr() string {
+	typesByChannel := make(map[eventpb.Channel][]string)
+	for eventType, payload := range eventTypes {
+		c := payload.LoggingChannel()
+		typesByChannel[c] = append(typesByChannel[c], lex.EscapeSQLString(string(eventType)))
+	}
+	var buf bytes.Buffer
+	buf.WriteString("CASE")
+	for _, c := range eventpb.Channels {
+		if c == eventpb.ChannelOps || len(typesByChannel[c]) == 0 {
+			continue
+		}
+		types := typesByChannel[c]
+		sort.Strings(types)
+		fmt.Fprintf(&buf, ` WHEN "eventType" IN (%s) THEN %s`,
+			strings.Join(types, ", "), lex.EscapeSQLString(c.String()))
+	}
+	// The events of the other types, including those recorded by earlier
+	// versions, belong to the OPS channel.
+	fmt.Fprintf(&buf, " ELSE %s END", lex.EscapeSQLString(eventpb.ChannelOps.String()))
+	return buf.String()
+}
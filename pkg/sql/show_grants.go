@@ -18,10 +18,12 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/lex"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 )
 
 // ShowGrants returns grant details for the specified objects and users.
@@ -138,13 +140,59 @@ FROM "".information_schema.table_privileges`
 	}
 
 	if n.Grantees != nil {
+		// Also show the privileges the grantees inherit from the roles they
+		// are members of, including public. The grantee column then shows
+		// the role the privilege was granted to.
 		params = params[:0]
+		seen := make(map[string]struct{})
 		for _, grantee := range n.Grantees.ToStrings() {
-			params = append(params, lex.EscapeSQLString(grantee))
+			grantees, err := p.effectiveGrantees(ctx, grantee)
+			if err != nil {
+				return nil, err
+			}
+			for _, g := range grantees {
+				if _, ok := seen[g]; ok || g == sqlbase.PublicRole {
+					continue
+				}
+				seen[g] = struct{}{}
+				params = append(params, lex.EscapeSQLString(g))
+			}
 		}
-		fmt.Fprintf(&cond, ` AND grantee IN (%s)`, strings.Join(params, ","))
+		// Every virtual table grants SELECT to public; listing these would
+		// drown out the privileges that were actually granted.
+		schemaNames := p.getVirtualTabler().getSchemaNames()
+		virtualNames := make([]string, 0, len(schemaNames))
+		for _, name := range schemaNames {
+			virtualNames = append(virtualNames, lex.EscapeSQLString(name))
+		}
+		sort.Strings(virtualNames)
+		fmt.Fprintf(&cond, ` AND (grantee IN (%s) OR (grantee = %s AND schema_name NOT IN (%s)))`,
+			strings.Join(params, ","), lex.EscapeSQLString(sqlbase.PublicRole),
+			strings.Join(virtualNames, ","))
 	}
 	return p.delegateQuery(ctx, "SHOW GRANTS",
 		fmt.Sprintf("SELECT * FROM (%s) %s ORDER BY %s", source.String(), cond.String(), orderBy),
 		initCheck, nil)
 }
+
+// effectiveGrantees returns the sorted list of users and roles whose
+// privileges are held by the given user: the user itself, the roles it is
+// a member of, directly or indirectly, and public.
+func (p *planner) effectiveGrantees(ctx context.Context, user string) ([]string, error) {
+	memberOf, err := p.MemberOfWithAdminOption(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]string, 0, len(memberOf)+2)
+	res = append(res, user)
+	for role := range memberOf {
+		if role != user {
+			res = append(res, role)
+		}
+	}
+	if user != sqlbase.PublicRole {
+		res = append(res, sqlbase.PublicRole)
+	}
+	sort.Strings(res)
+	return res, nil
+}
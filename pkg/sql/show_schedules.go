@@ -0,0 +1,39 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// ShowSchedules returns all the schedules, along with the time of their
+// last run.
+// Privileges: superuser.
+func (p *planner) ShowSchedules(ctx context.Context, n *tree.ShowSchedules) (planNode, error) {
+	if err := p.RequireSuperUser(ctx, "SHOW SCHEDULES"); err != nil {
+		return nil, err
+	}
+	return p.delegateQuery(ctx, "SHOW SCHEDULES",
+		`SELECT s.name, s.owner, s.database_name, s.statement, s.recurrence,
+            IF(s.next_run IS NULL, 'paused', 'active') AS state, s.next_run, r.last_run, s.created
+		FROM system.schedules AS s
+		LEFT JOIN (
+			SELECT schedule_name, max(started) AS last_run FROM system.schedule_runs GROUP BY schedule_name
+		) AS r ON r.schedule_name = s.name
+		ORDER BY s.name`,
+		nil, nil)
+}
@@ -34,6 +34,8 @@ var showTableStatsColumns = sqlbase.ResultColumns{
 	{Name: "distinct_count", Typ: types.Int},
 	{Name: "null_count", Typ: types.Int},
 	{Name: "histogram_id", Typ: types.Int},
+	{Name: "expression", Typ: types.String},
+	{Name: "partial_predicate", Typ: types.String},
 }
 
 var showTableStatsJSONColumns = sqlbase.ResultColumns{
@@ -76,7 +78,9 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 					      "rowCount",
 					      "distinctCount",
 					      "nullCount",
-					      histogram
+					      histogram,
+					      expression,
+					      predicate
 				 FROM system.table_statistics
 				 WHERE "tableID" = $1
 				 ORDER BY "createdAt"`,
@@ -95,6 +99,8 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 				distinctCountIdx
 				nullCountIdx
 				histogramIdx
+				expressionIdx
+				predicateIdx
 				numCols
 			)
 
@@ -109,6 +115,12 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 					if r[nameIdx] != tree.DNull {
 						result[i].Name = string(*r[nameIdx].(*tree.DString))
 					}
+					if r[expressionIdx] != tree.DNull {
+						result[i].Expression = string(*r[expressionIdx].(*tree.DString))
+					}
+					if r[predicateIdx] != tree.DNull {
+						result[i].Predicate = string(*r[predicateIdx].(*tree.DString))
+					}
 					colIDs := r[columnIDsIdx].(*tree.DArray).Array
 					result[i].Columns = make([]string, len(colIDs))
 					for j, d := range colIDs {
@@ -162,6 +174,8 @@ func (p *planner) ShowTableStats(ctx context.Context, n *tree.ShowTableStats) (p
 					r[distinctCountIdx],
 					r[nullCountIdx],
 					histogramID,
+					r[expressionIdx],
+					r[predicateIdx],
 				}
 				if _, err := v.rows.AddRow(ctx, res); err != nil {
 					v.Close(ctx)
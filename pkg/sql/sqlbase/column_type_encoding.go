@@ -443,7 +443,7 @@ func decodeUntaggedDatum(a *DatumAlloc, t types.T, buf []byte) (tree.Datum, []by
 		if err != nil {
 			return nil, b, err
 		}
-		return a.NewDString(tree.DString(data)), b, nil
+		return a.NewDStringFromBytes(data), b, nil
 	case types.BitArray:
 		b, data, err := encoding.DecodeUntaggedBitArrayValue(buf)
 		return a.NewDBitArray(tree.DBitArray{BitArray: data}), b, err
@@ -472,7 +472,7 @@ func decodeUntaggedDatum(a *DatumAlloc, t types.T, buf []byte) (tree.Datum, []by
 		if err != nil {
 			return nil, b, err
 		}
-		return a.NewDBytes(tree.DBytes(data)), b, nil
+		return a.NewDBytesFromBytes(data), b, nil
 	case types.Date:
 		b, data, err := encoding.DecodeUntaggedIntValue(buf)
 		if err != nil {
@@ -752,13 +752,13 @@ func UnmarshalColumnValue(a *DatumAlloc, typ ColumnType, value roachpb.Value) (t
 		if err != nil {
 			return nil, err
 		}
-		return a.NewDString(tree.DString(v)), nil
+		return a.NewDStringFromBytes(v), nil
 	case ColumnType_BYTES:
 		v, err := value.GetBytes()
 		if err != nil {
 			return nil, err
 		}
-		return a.NewDBytes(tree.DBytes(v)), nil
+		return a.NewDBytesFromBytes(v), nil
 	case ColumnType_DATE:
 		v, err := value.GetInt()
 		if err != nil {
@@ -129,5 +129,18 @@ const (
 	PgCatalogStatActivityTableID
 	PgCatalogSecurityLabelTableID
 	PgCatalogSharedSecurityLabelTableID
-	MinVirtualID = PgCatalogSharedSecurityLabelTableID
+	CrdbInternalPrivilegesTableID
+	CrdbInternalClusterLocksTableID
+	CrdbInternalDeadlocksTableID
+	CrdbInternalSuperRegionViolationsTableID
+	CrdbInternalNodeTLSConnectionsTableID
+	CrdbInternalNodeInflightTraceSpansTableID
+	CrdbInternalClusterInflightTraceSpansTableID
+	CrdbInternalNodeNetworkLatenciesTableID
+	CrdbInternalClusterNetworkLatenciesTableID
+	CrdbInternalNodeMemoryMonitorsTableID
+	CrdbInternalConstraintViolationsTableID
+	CrdbInternalNodeRoleStatsTableID
+	CrdbInternalNodeStmtPlansTableID
+	MinVirtualID = CrdbInternalNodeStmtPlansTableID
 )
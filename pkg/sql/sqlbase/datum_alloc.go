@@ -14,7 +14,10 @@
 
 package sqlbase
 
-import "github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+)
 
 // DatumAlloc provides batch allocation of datum pointers, amortizing the cost
 // of the allocations.
@@ -38,16 +41,85 @@ type DatumAlloc struct {
 	doidAlloc         []tree.DOid
 	scratch           []byte
 	env               tree.CollationEnvironment
+
+	// growBatches is set by EnableBatchGrowth; batchSize is then the number of
+	// datums allocated in the next batch.
+	growBatches bool
+	batchSize   int
+
+	// referenceBuffers is set by ReferenceDecodedBuffers.
+	referenceBuffers bool
 }
 
 const datumAllocSize = 16      // Arbitrary, could be tuned.
 const datumAllocMultiplier = 4 // Arbitrary, could be tuned.
+const datumAllocMaxSize = 256  // Arbitrary, could be tuned.
+
+// The range of the integers for which NewDInt returns an interned DInt.
+const (
+	minInternedDInt = -128
+	maxInternedDInt = 1023
+)
+
+var (
+	// internedDInts holds the DInts between minInternedDInt and maxInternedDInt.
+	// Datums are immutable, so a single allocation of each of these common
+	// values can be shared by all rows.
+	internedDInts = func() (r [maxInternedDInt - minInternedDInt + 1]tree.DInt) {
+		for i := range r {
+			r[i] = tree.DInt(i + minInternedDInt)
+		}
+		return r
+	}()
+	internedEmptyDString = tree.NewDString("")
+	internedEmptyDBytes  = tree.NewDBytes("")
+)
+
+// EnableBatchGrowth makes the allocator double the size of its batches each
+// time it allocates one, up to datumAllocMaxSize datums. It is meant for the
+// allocators of execution components that create datums for many rows, for
+// which it amortizes the allocations further. Since a batch can only be
+// garbage collected once none of its datums are referenced anymore, it should
+// not be used by allocators whose datums are retained for a long time.
+func (a *DatumAlloc) EnableBatchGrowth() {
+	a.growBatches = true
+	if a.batchSize < datumAllocSize {
+		a.batchSize = datumAllocSize
+	}
+}
+
+// ReferenceDecodedBuffers makes the STRING and BYTES datums value-decoded with
+// the allocator share storage with the buffers they are decoded from instead
+// of copying them.
+//
+// The caller takes ownership of the lifetime of those buffers: it may only be
+// used if every buffer decoded with the allocator is never modified nor
+// reused. The buffers of KV responses satisfy this, so the allocators only
+// used by a row fetcher can enable it to avoid a copy per fetched value. Note
+// that a datum referencing a buffer keeps the whole buffer alive, while its
+// memory is only accounted for its own size (as if it had been copied), so this
+// should not be used by allocators whose datums are retained for a long time.
+func (a *DatumAlloc) ReferenceDecodedBuffers() {
+	a.referenceBuffers = true
+}
+
+// allocSize returns the number of datums to allocate in a new batch.
+func (a *DatumAlloc) allocSize() int {
+	if !a.growBatches {
+		return datumAllocSize
+	}
+	size := a.batchSize
+	if size < datumAllocMaxSize {
+		a.batchSize = size * 2
+	}
+	return size
+}
 
 // NewDatums allocates Datums of the specified size.
 func (a *DatumAlloc) NewDatums(num int) tree.Datums {
 	buf := &a.datumAlloc
 	if len(*buf) < num {
-		extensionSize := datumAllocSize
+		extensionSize := a.allocSize()
 		if extTupleLen := num * datumAllocMultiplier; extensionSize < extTupleLen {
 			extensionSize = extTupleLen
 		}
@@ -58,11 +130,14 @@ func (a *DatumAlloc) NewDatums(num int) tree.Datums {
 	return r
 }
 
-// NewDInt allocates a DInt.
+// NewDInt allocates a DInt, or returns an interned one for small integers.
 func (a *DatumAlloc) NewDInt(v tree.DInt) *tree.DInt {
+	if v >= minInternedDInt && v <= maxInternedDInt {
+		return &internedDInts[v-minInternedDInt]
+	}
 	buf := &a.dintAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DInt, datumAllocSize)
+		*buf = make([]tree.DInt, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -74,7 +149,7 @@ func (a *DatumAlloc) NewDInt(v tree.DInt) *tree.DInt {
 func (a *DatumAlloc) NewDFloat(v tree.DFloat) *tree.DFloat {
 	buf := &a.dfloatAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DFloat, datumAllocSize)
+		*buf = make([]tree.DFloat, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -82,11 +157,15 @@ func (a *DatumAlloc) NewDFloat(v tree.DFloat) *tree.DFloat {
 	return r
 }
 
-// NewDString allocates a DString.
+// NewDString allocates a DString, or returns an interned one for the empty
+// string.
 func (a *DatumAlloc) NewDString(v tree.DString) *tree.DString {
+	if v == "" {
+		return internedEmptyDString
+	}
 	buf := &a.dstringAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DString, datumAllocSize)
+		*buf = make([]tree.DString, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -99,11 +178,24 @@ func (a *DatumAlloc) NewDName(v tree.DString) tree.Datum {
 	return tree.NewDNameFromDString(a.NewDString(v))
 }
 
-// NewDBytes allocates a DBytes.
+// NewDStringFromBytes allocates a DString holding the given bytes, which are
+// referenced instead of copied if ReferenceDecodedBuffers was called.
+func (a *DatumAlloc) NewDStringFromBytes(b []byte) *tree.DString {
+	if a.referenceBuffers {
+		return a.NewDString(tree.DString(encoding.UnsafeConvertBytesToString(b)))
+	}
+	return a.NewDString(tree.DString(b))
+}
+
+// NewDBytes allocates a DBytes, or returns an interned one for the empty byte
+// string.
 func (a *DatumAlloc) NewDBytes(v tree.DBytes) *tree.DBytes {
+	if v == "" {
+		return internedEmptyDBytes
+	}
 	buf := &a.dbytesAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DBytes, datumAllocSize)
+		*buf = make([]tree.DBytes, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -111,11 +203,20 @@ func (a *DatumAlloc) NewDBytes(v tree.DBytes) *tree.DBytes {
 	return r
 }
 
+// NewDBytesFromBytes allocates a DBytes holding the given bytes, which are
+// referenced instead of copied if ReferenceDecodedBuffers was called.
+func (a *DatumAlloc) NewDBytesFromBytes(b []byte) *tree.DBytes {
+	if a.referenceBuffers {
+		return a.NewDBytes(tree.DBytes(encoding.UnsafeConvertBytesToString(b)))
+	}
+	return a.NewDBytes(tree.DBytes(b))
+}
+
 // NewDBitArray allocates a DBitArray.
 func (a *DatumAlloc) NewDBitArray(v tree.DBitArray) *tree.DBitArray {
 	buf := &a.dbitArrayAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DBitArray, datumAllocSize)
+		*buf = make([]tree.DBitArray, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -127,7 +228,7 @@ func (a *DatumAlloc) NewDBitArray(v tree.DBitArray) *tree.DBitArray {
 func (a *DatumAlloc) NewDDecimal(v tree.DDecimal) *tree.DDecimal {
 	buf := &a.ddecimalAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DDecimal, datumAllocSize)
+		*buf = make([]tree.DDecimal, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -139,7 +240,7 @@ func (a *DatumAlloc) NewDDecimal(v tree.DDecimal) *tree.DDecimal {
 func (a *DatumAlloc) NewDDate(v tree.DDate) *tree.DDate {
 	buf := &a.ddateAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DDate, datumAllocSize)
+		*buf = make([]tree.DDate, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -151,7 +252,7 @@ func (a *DatumAlloc) NewDDate(v tree.DDate) *tree.DDate {
 func (a *DatumAlloc) NewDTime(v tree.DTime) *tree.DTime {
 	buf := &a.dtimeAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DTime, datumAllocSize)
+		*buf = make([]tree.DTime, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -163,7 +264,7 @@ func (a *DatumAlloc) NewDTime(v tree.DTime) *tree.DTime {
 func (a *DatumAlloc) NewDTimestamp(v tree.DTimestamp) *tree.DTimestamp {
 	buf := &a.dtimestampAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DTimestamp, datumAllocSize)
+		*buf = make([]tree.DTimestamp, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -175,7 +276,7 @@ func (a *DatumAlloc) NewDTimestamp(v tree.DTimestamp) *tree.DTimestamp {
 func (a *DatumAlloc) NewDTimestampTZ(v tree.DTimestampTZ) *tree.DTimestampTZ {
 	buf := &a.dtimestampTzAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DTimestampTZ, datumAllocSize)
+		*buf = make([]tree.DTimestampTZ, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -187,7 +288,7 @@ func (a *DatumAlloc) NewDTimestampTZ(v tree.DTimestampTZ) *tree.DTimestampTZ {
 func (a *DatumAlloc) NewDInterval(v tree.DInterval) *tree.DInterval {
 	buf := &a.dintervalAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DInterval, datumAllocSize)
+		*buf = make([]tree.DInterval, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -199,7 +300,7 @@ func (a *DatumAlloc) NewDInterval(v tree.DInterval) *tree.DInterval {
 func (a *DatumAlloc) NewDUuid(v tree.DUuid) *tree.DUuid {
 	buf := &a.duuidAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DUuid, datumAllocSize)
+		*buf = make([]tree.DUuid, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -211,7 +312,7 @@ func (a *DatumAlloc) NewDUuid(v tree.DUuid) *tree.DUuid {
 func (a *DatumAlloc) NewDIPAddr(v tree.DIPAddr) *tree.DIPAddr {
 	buf := &a.dipnetAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DIPAddr, datumAllocSize)
+		*buf = make([]tree.DIPAddr, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -223,7 +324,7 @@ func (a *DatumAlloc) NewDIPAddr(v tree.DIPAddr) *tree.DIPAddr {
 func (a *DatumAlloc) NewDJSON(v tree.DJSON) *tree.DJSON {
 	buf := &a.djsonAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DJSON, datumAllocSize)
+		*buf = make([]tree.DJSON, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -235,7 +336,7 @@ func (a *DatumAlloc) NewDJSON(v tree.DJSON) *tree.DJSON {
 func (a *DatumAlloc) NewDTuple(v tree.DTuple) *tree.DTuple {
 	buf := &a.dtupleAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DTuple, datumAllocSize)
+		*buf = make([]tree.DTuple, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
@@ -247,7 +348,7 @@ func (a *DatumAlloc) NewDTuple(v tree.DTuple) *tree.DTuple {
 func (a *DatumAlloc) NewDOid(v tree.DOid) tree.Datum {
 	buf := &a.doidAlloc
 	if len(*buf) == 0 {
-		*buf = make([]tree.DOid, datumAllocSize)
+		*buf = make([]tree.DOid, a.allocSize())
 	}
 	r := &(*buf)[0]
 	*r = v
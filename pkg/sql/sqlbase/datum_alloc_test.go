@@ -0,0 +1,141 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sqlbase
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+func TestDatumAllocInterning(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var a, b DatumAlloc
+	for _, v := range []tree.DInt{minInternedDInt, -1, 0, 1, maxInternedDInt} {
+		d1, d2 := a.NewDInt(v), b.NewDInt(v)
+		if d1 != d2 {
+			t.Errorf("expected %d to be interned", v)
+		}
+		if *d1 != v {
+			t.Errorf("expected %d, got %d", v, *d1)
+		}
+	}
+	for _, v := range []tree.DInt{minInternedDInt - 1, maxInternedDInt + 1} {
+		d1, d2 := a.NewDInt(v), b.NewDInt(v)
+		if d1 == d2 {
+			t.Errorf("expected %d not to be interned", v)
+		}
+		if *d1 != v || *d2 != v {
+			t.Errorf("expected %d, got %d and %d", v, *d1, *d2)
+		}
+	}
+	if a.NewDString("") != b.NewDString("") {
+		t.Error("expected the empty string to be interned")
+	}
+	if a.NewDString("a") == b.NewDString("a") {
+		t.Error("expected a non-empty string not to be interned")
+	}
+	if a.NewDBytes("") != b.NewDBytes("") {
+		t.Error("expected the empty byte string to be interned")
+	}
+}
+
+func TestDatumAllocBatchGrowth(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	var a DatumAlloc
+	for i := 0; i < 3; i++ {
+		if size := a.allocSize(); size != datumAllocSize {
+			t.Fatalf("expected batches of %d datums, got %d", datumAllocSize, size)
+		}
+	}
+
+	a.EnableBatchGrowth()
+	expected := datumAllocSize
+	for i := 0; i < 10; i++ {
+		if size := a.allocSize(); size != expected {
+			t.Fatalf("%d: expected a batch of %d datums, got %d", i, expected, size)
+		}
+		if expected < datumAllocMaxSize {
+			expected *= 2
+		}
+	}
+
+	// The datums of a batch must be distinct.
+	d1, d2 := a.NewDString("a"), a.NewDString("b")
+	if d1 == d2 || *d1 != "a" || *d2 != "b" {
+		t.Fatalf("unexpected datums %s and %s", d1, d2)
+	}
+}
+
+func TestDatumAllocReferenceDecodedBuffers(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, reference := range []bool{false, true} {
+		for _, d := range []tree.Datum{tree.NewDString("abc"), tree.NewDBytes("abc")} {
+			buf, err := EncodeTableValue(nil, ColumnID(encoding.NoColumnID), d, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var a DatumAlloc
+			if reference {
+				a.ReferenceDecodedBuffers()
+			}
+			decoded, _, err := DecodeTableValue(&a, d.ResolvedType(), buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Overwrite the buffer, which the decoded datum only observes if it
+			// references it.
+			buf[len(buf)-1] = 'x'
+			expected := "abc"
+			if reference {
+				expected = "abx"
+			}
+			var s string
+			switch v := decoded.(type) {
+			case *tree.DString:
+				s = string(*v)
+			case *tree.DBytes:
+				s = string(*v)
+			}
+			if s != expected {
+				t.Errorf("reference %t: expected %q, got %q", reference, expected, s)
+			}
+		}
+	}
+}
+
+func BenchmarkDatumAllocNewDInt(b *testing.B) {
+	for _, growth := range []bool{false, true} {
+		name := "fixed"
+		if growth {
+			name = "growth"
+		}
+		b.Run(name, func(b *testing.B) {
+			var a DatumAlloc
+			if growth {
+				a.EnableBatchGrowth()
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = a.NewDInt(tree.DInt(i))
+			}
+		})
+	}
+}
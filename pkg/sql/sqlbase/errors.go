@@ -58,6 +58,24 @@ func NewNonNullViolationError(columnName string) error {
 	return pgerror.NewErrorf(pgerror.CodeNotNullViolationError, "null value in column %q violates not-null constraint", columnName)
 }
 
+// NewGeneratedAlwaysAsIdentityColumnOverrideError creates an error for an
+// INSERT providing a value for a GENERATED ALWAYS AS IDENTITY column without
+// OVERRIDING SYSTEM VALUE.
+func NewGeneratedAlwaysAsIdentityColumnOverrideError(columnName string) error {
+	return pgerror.NewErrorf(pgerror.CodeGeneratedAlwaysError,
+		"cannot insert a non-DEFAULT value into column %q", columnName).
+		SetDetailf("Column %q is an identity column defined as GENERATED ALWAYS.", columnName).
+		SetHintf("Use OVERRIDING SYSTEM VALUE to override.")
+}
+
+// NewGeneratedAlwaysAsIdentityColumnUpdateError creates an error for an UPDATE
+// assigning a value other than DEFAULT to a GENERATED ALWAYS AS IDENTITY column.
+func NewGeneratedAlwaysAsIdentityColumnUpdateError(columnName string) error {
+	return pgerror.NewErrorf(pgerror.CodeGeneratedAlwaysError,
+		"column %q can only be updated to DEFAULT", columnName).
+		SetDetailf("Column %q is an identity column defined as GENERATED ALWAYS.", columnName)
+}
+
 // IsUniquenessConstraintViolationError returns true if the error is for a
 // uniqueness constraint violation.
 func IsUniquenessConstraintViolationError(err error) bool {
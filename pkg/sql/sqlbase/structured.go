@@ -974,6 +974,10 @@ func (desc *MutableTableDescriptor) allocateColumnFamilyIDs(columnNames map[stri
 	}
 
 	ensureColumnInFamily := func(col *ColumnDescriptor) {
+		if col.Virtual {
+			// Virtual columns are not stored, and so don't belong to a family.
+			return
+		}
 		if _, ok := columnsInFamilies[col.ID]; ok {
 			return
 		}
@@ -1347,6 +1351,10 @@ func (desc *TableDescriptor) ValidateTable(st *cluster.Settings) error {
 		}
 	}
 
+	if err := desc.validateTriggers(); err != nil {
+		return err
+	}
+
 	// Fill in any incorrect privileges that may have been missed due to mixed-versions.
 	// TODO(mberhault): remove this in 2.1 (maybe 2.2) when privilege-fixing migrations have been
 	// run again and mixed-version clusters always write "good" descriptors.
@@ -1356,6 +1364,27 @@ func (desc *TableDescriptor) ValidateTable(st *cluster.Settings) error {
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+func (desc *TableDescriptor) validateTriggers() error {
+	if len(desc.Triggers) > 0 && !desc.IsTable() {
+		return pgerror.NewAssertionErrorf("%q is not a table but has triggers", desc.Name)
+	}
+	triggerNames := make(map[string]struct{}, len(desc.Triggers))
+	for i := range desc.Triggers {
+		trigger := &desc.Triggers[i]
+		if err := validateName(trigger.Name, "trigger"); err != nil {
+			return err
+		}
+		if _, ok := triggerNames[trigger.Name]; ok {
+			return fmt.Errorf("duplicate trigger name: %q", trigger.Name)
+		}
+		triggerNames[trigger.Name] = struct{}{}
+		if len(trigger.Events) == 0 {
+			return fmt.Errorf("trigger %q has no events", trigger.Name)
+		}
+	}
+	return nil
+}
+
 func (desc *TableDescriptor) validateColumnFamilies(
 	columnIDs map[ColumnID]string,
 ) (map[ColumnID]FamilyID, error) {
@@ -1366,6 +1395,7 @@ func (desc *TableDescriptor) validateColumnFamilies(
 		return nil, fmt.Errorf("the 0th family must have ID 0")
 	}
 
+	virtualColIDs := desc.virtualColumnIDs()
 	familyNames := map[string]struct{}{}
 	familyIDs := map[FamilyID]string{}
 	colIDToFamilyID := map[ColumnID]FamilyID{}
@@ -1404,6 +1434,9 @@ func (desc *TableDescriptor) validateColumnFamilies(
 				return nil, fmt.Errorf("family %q column %d should have name %q, but found name %q",
 					family.Name, colID, name, family.ColumnNames[i])
 			}
+			if _, ok := virtualColIDs[colID]; ok {
+				return nil, fmt.Errorf("family %q contains virtual computed column %q", family.Name, name)
+			}
 		}
 
 		for _, colID := range family.ColumnIDs {
@@ -1414,6 +1447,9 @@ func (desc *TableDescriptor) validateColumnFamilies(
 		}
 	}
 	for colID := range columnIDs {
+		if _, ok := virtualColIDs[colID]; ok {
+			continue
+		}
 		if _, ok := colIDToFamilyID[colID]; !ok {
 			return nil, fmt.Errorf("column %d is not in any column family", colID)
 		}
@@ -1421,6 +1457,23 @@ func (desc *TableDescriptor) validateColumnFamilies(
 	return colIDToFamilyID, nil
 }
 
+// virtualColumnIDs returns the IDs of the virtual computed columns of the
+// table, including those being added or dropped.
+func (desc *TableDescriptor) virtualColumnIDs() map[ColumnID]struct{} {
+	virtualColIDs := make(map[ColumnID]struct{})
+	for i := range desc.Columns {
+		if desc.Columns[i].Virtual {
+			virtualColIDs[desc.Columns[i].ID] = struct{}{}
+		}
+	}
+	for _, m := range desc.Mutations {
+		if col := m.GetColumn(); col != nil && col.Virtual {
+			virtualColIDs[col.ID] = struct{}{}
+		}
+	}
+	return virtualColIDs
+}
+
 // validateTableIndexes validates that indexes are well formed. Checks include
 // validating the columns involved in the index, verifying the index names and
 // IDs are unique, and the family of the primary key is 0. This does not check
@@ -1433,6 +1486,7 @@ func (desc *TableDescriptor) validateTableIndexes(
 		return ErrMissingPrimaryKey
 	}
 
+	virtualColIDs := desc.virtualColumnIDs()
 	indexNames := map[string]struct{}{}
 	indexIDs := map[IndexID]string{}
 	for _, index := range desc.AllNonDropIndexes() {
@@ -1491,6 +1545,14 @@ func (desc *TableDescriptor) validateTableIndexes(
 				return fmt.Errorf("index %q contains duplicate column %q", index.Name, name)
 			}
 			validateIndexDup[colID] = struct{}{}
+			if _, ok := virtualColIDs[colID]; ok {
+				return fmt.Errorf("index %q contains virtual computed column %q", index.Name, name)
+			}
+		}
+		for _, name := range index.StoreColumnNames {
+			if _, ok := virtualColIDs[columnNames[name]]; ok {
+				return fmt.Errorf("index %q stores virtual computed column %q", index.Name, name)
+			}
 		}
 	}
 
@@ -1767,11 +1829,22 @@ func notIndexableError(cols []ColumnDescriptor, inverted bool) error {
 	return pgerror.UnimplementedWithIssueDetailErrorf(35730, typInfo, msg)
 }
 
+// notIndexableVirtualColumnError is returned when a virtual computed column is
+// used in an index. The values of these columns are not stored, so there is
+// nothing to index.
+func notIndexableVirtualColumnError(col *ColumnDescriptor) error {
+	return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+		"virtual computed column %q cannot be indexed", col.Name)
+}
+
 func checkColumnsValidForIndex(tableDesc *MutableTableDescriptor, indexColNames []string) error {
 	invalidColumns := make([]ColumnDescriptor, 0, len(indexColNames))
 	for _, indexCol := range indexColNames {
 		for _, col := range tableDesc.AllNonDropColumns() {
 			if col.Name == indexCol {
+				if col.Virtual {
+					return notIndexableVirtualColumnError(&col)
+				}
 				if !columnTypeIsIndexable(col.Type) {
 					invalidColumns = append(invalidColumns, col)
 				}
@@ -1794,6 +1867,9 @@ func checkColumnsValidForInvertedIndex(
 	for _, indexCol := range indexColNames {
 		for _, col := range tableDesc.AllNonDropColumns() {
 			if col.Name == indexCol {
+				if col.Virtual {
+					return notIndexableVirtualColumnError(&col)
+				}
 				if !columnTypeIsInvertedIndexable(col.Type) {
 					invalidColumns = append(invalidColumns, col)
 				}
@@ -2102,6 +2178,39 @@ func (desc *TableDescriptor) FindCheckByName(
 	return nil, fmt.Errorf("check %q does not exist", name)
 }
 
+// FindTriggerByName finds the trigger with the specified name, and returns its
+// position in desc.Triggers.
+func (desc *TableDescriptor) FindTriggerByName(name string) (*TriggerDescriptor, int, error) {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].Name == name {
+			return &desc.Triggers[i], i, nil
+		}
+	}
+	return nil, -1, pgerror.NewErrorf(pgerror.CodeUndefinedObjectError,
+		"trigger %q does not exist", name)
+}
+
+// HasTrigger returns true if any of the table's triggers fire on the given
+// event.
+func (desc *TableDescriptor) HasTrigger(event TriggerDescriptor_Event) bool {
+	for i := range desc.Triggers {
+		if desc.Triggers[i].HasEvent(event) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasEvent returns true if the trigger fires on the given event.
+func (desc *TriggerDescriptor) HasEvent(event TriggerDescriptor_Event) bool {
+	for _, e := range desc.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
 // RenameIndexDescriptor renames an index descriptor.
 func (desc *MutableTableDescriptor) RenameIndexDescriptor(
 	index *IndexDescriptor, name string,
@@ -2415,6 +2524,11 @@ func (desc *ImmutableTableDescriptor) MakeFirstMutationPublic() (*MutableTableDe
 // ColumnNeedsBackfill returns true if adding the given column requires a
 // backfill (dropping a column always requires a backfill).
 func ColumnNeedsBackfill(desc *ColumnDescriptor) bool {
+	if desc.Virtual {
+		// The values of a virtual column are not stored. The backfill is only
+		// needed to check that the existing rows satisfy NOT NULL.
+		return !desc.Nullable
+	}
 	return desc.DefaultExpr != nil || !desc.Nullable || desc.IsComputed()
 }
 
@@ -2538,10 +2652,55 @@ func (desc *DatabaseDescriptor) Validate() error {
 	// run again and mixed-version clusters always write "good" descriptors.
 	desc.Privileges.MaybeFixPrivileges(desc.GetID())
 
+	routinesByName := make(map[string][]*RoutineDescriptor, len(desc.Routines))
+	for i := range desc.Routines {
+		routine := &desc.Routines[i]
+		if err := validateName(routine.Name, "routine"); err != nil {
+			return err
+		}
+		// Functions can be overloaded with different parameter types; the
+		// other routines must have distinct names.
+		for _, prev := range routinesByName[routine.Name] {
+			if routine.Kind != RoutineDescriptor_FUNCTION || prev.Kind != RoutineDescriptor_FUNCTION {
+				return fmt.Errorf("duplicate routine name: %q", routine.Name)
+			}
+			if routine.HasSameParamTypes(prev) {
+				return fmt.Errorf("duplicate function %q with the same parameter types", routine.Name)
+			}
+		}
+		routinesByName[routine.Name] = append(routinesByName[routine.Name], routine)
+	}
+
 	// Validate the privilege descriptor.
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+// FindRoutineByName finds the routine with the specified name, and returns its
+// position in desc.Routines, or nil and -1 if the database has no such routine.
+func (desc *DatabaseDescriptor) FindRoutineByName(name string) (*RoutineDescriptor, int) {
+	for i := range desc.Routines {
+		if desc.Routines[i].Name == name {
+			return &desc.Routines[i], i
+		}
+	}
+	return nil, -1
+}
+
+// HasSameParamTypes returns whether the parameters of two routines have the
+// same types, in which case they cannot be told apart by the types of the
+// arguments of a call.
+func (desc *RoutineDescriptor) HasSameParamTypes(other *RoutineDescriptor) bool {
+	if len(desc.Params) != len(other.Params) {
+		return false
+	}
+	for i := range desc.Params {
+		if !desc.Params[i].Type.ToDatumType().Equivalent(other.Params[i].Type.ToDatumType()) {
+			return false
+		}
+	}
+	return true
+}
+
 // GetID returns the ID of the descriptor.
 func (desc *Descriptor) GetID() ID {
 	switch t := desc.Union.(type) {
@@ -2638,14 +2797,25 @@ func (desc *ColumnDescriptor) SQLString() string {
 	} else {
 		f.WriteString(" NOT NULL")
 	}
-	if desc.DefaultExpr != nil {
-		f.WriteString(" DEFAULT ")
-		f.WriteString(*desc.DefaultExpr)
+	switch desc.GeneratedAsIdentityType {
+	case ColumnDescriptor_GENERATED_ALWAYS:
+		f.WriteString(" GENERATED ALWAYS AS IDENTITY")
+	case ColumnDescriptor_GENERATED_BY_DEFAULT:
+		f.WriteString(" GENERATED BY DEFAULT AS IDENTITY")
+	default:
+		if desc.DefaultExpr != nil {
+			f.WriteString(" DEFAULT ")
+			f.WriteString(*desc.DefaultExpr)
+		}
 	}
 	if desc.IsComputed() {
 		f.WriteString(" AS (")
 		f.WriteString(*desc.ComputeExpr)
-		f.WriteString(") STORED")
+		if desc.Virtual {
+			f.WriteString(") VIRTUAL")
+		} else {
+			f.WriteString(") STORED")
+		}
 	}
 	return f.CloseAndGetString()
 }
@@ -2824,6 +2994,21 @@ func (desc *ColumnDescriptor) IsComputed() bool {
 	return desc.ComputeExpr != nil
 }
 
+// IsVirtual is part of the cat.Column interface.
+func (desc *ColumnDescriptor) IsVirtual() bool {
+	return desc.Virtual
+}
+
+// IsIdentity is part of the cat.Column interface.
+func (desc *ColumnDescriptor) IsIdentity() bool {
+	return desc.GeneratedAsIdentityType != ColumnDescriptor_NOT_IDENTITY_COLUMN
+}
+
+// IsGeneratedAlwaysAsIdentity is part of the cat.Column interface.
+func (desc *ColumnDescriptor) IsGeneratedAlwaysAsIdentity() bool {
+	return desc.GeneratedAsIdentityType == ColumnDescriptor_GENERATED_ALWAYS
+}
+
 // DefaultExprStr is part of the cat.Column interface.
 func (desc *ColumnDescriptor) DefaultExprStr() string {
 	return *desc.DefaultExpr
@@ -130,6 +130,8 @@ func TestAllocateIDs(t *testing.T) {
 func TestValidateTableDesc(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
+	computedExpr := "bar + 1"
+
 	testData := []struct {
 		err  string
 		desc TableDescriptor
@@ -359,6 +361,22 @@ func TestValidateTableDesc(t *testing.T) {
 				NextColumnID: 2,
 				NextFamilyID: 2,
 			}},
+		{`family "baz" contains virtual computed column "qux"`,
+			TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: FamilyFormatVersion,
+				Columns: []ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+					{ID: 2, Name: "qux", ComputeExpr: &computedExpr, Virtual: true},
+				},
+				Families: []ColumnFamilyDescriptor{
+					{ID: 0, Name: "baz", ColumnIDs: []ColumnID{1, 2}, ColumnNames: []string{"bar", "qux"}},
+				},
+				NextColumnID: 3,
+				NextFamilyID: 1,
+			}},
 		{`primary key column 1 is not in column family 0`,
 			TableDescriptor{
 				ID:            2,
@@ -550,6 +568,33 @@ func TestValidateTableDesc(t *testing.T) {
 				NextFamilyID: 1,
 				NextIndexID:  2,
 			}},
+		{`index "bar" contains virtual computed column "qux"`,
+			TableDescriptor{
+				ID:            2,
+				ParentID:      1,
+				Name:          "foo",
+				FormatVersion: FamilyFormatVersion,
+				Columns: []ColumnDescriptor{
+					{ID: 1, Name: "bar"},
+					{ID: 2, Name: "qux", ComputeExpr: &computedExpr, Virtual: true},
+				},
+				Families: []ColumnFamilyDescriptor{
+					{ID: 0, Name: "primary", ColumnIDs: []ColumnID{1}, ColumnNames: []string{"bar"}},
+				},
+				PrimaryIndex: IndexDescriptor{ID: 1, Name: "primary", ColumnIDs: []ColumnID{1},
+					ColumnNames:      []string{"bar"},
+					ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC},
+				},
+				Indexes: []IndexDescriptor{
+					{ID: 2, Name: "bar", ColumnIDs: []ColumnID{2},
+						ColumnNames:      []string{"qux"},
+						ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC},
+					},
+				},
+				NextColumnID: 3,
+				NextFamilyID: 1,
+				NextIndexID:  3,
+			}},
 		{`index "bar" contains unknown column "blah"`,
 			TableDescriptor{
 				ID:            2,
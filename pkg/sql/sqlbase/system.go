@@ -226,6 +226,56 @@ CREATE TABLE system.comments (
    comment   STRING NOT NULL, -- the comment
    PRIMARY KEY (type, object_id, sub_id)
 );`
+
+	// user_auth_state stores the expiration of the password of users, as
+	// set with VALID UNTIL, and the state used to throttle failed login
+	// attempts.
+	UserAuthStateTableSchema = `
+CREATE TABLE system.user_auth_state (
+  username        STRING PRIMARY KEY,
+  valid_until     TIMESTAMP,             -- NULL if the password never expires
+  failed_attempts INT NOT NULL DEFAULT 0, -- consecutive failed login attempts
+  locked_until    TIMESTAMP,             -- logins are rejected until then
+  FAMILY "primary" (username, valid_until, failed_attempts, locked_until)
+);`
+
+	// pinned_plans stores the plans pinned for statement fingerprints with
+	// crdb_internal.pin_plan.
+	PinnedPlansTableSchema = `
+CREATE TABLE system.pinned_plans (
+  fingerprint STRING PRIMARY KEY,
+  gist        STRING NOT NULL,
+  created     TIMESTAMP NOT NULL DEFAULT now(),
+  FAMILY "primary" (fingerprint, gist, created)
+);`
+
+	// schedules stores the statements run on a recurrence, created with
+	// CREATE SCHEDULE.
+	SchedulesTableSchema = `
+CREATE TABLE system.schedules (
+  name          STRING PRIMARY KEY,
+  owner         STRING NOT NULL,       -- the user the statement is executed as
+  database_name STRING NOT NULL,       -- the current database of the statement
+  statement     STRING NOT NULL,
+  recurrence    STRING NOT NULL,       -- a cron expression, evaluated in UTC
+  next_run      TIMESTAMP,             -- NULL if the schedule is paused
+  created       TIMESTAMP NOT NULL DEFAULT now(),
+  FAMILY "primary" (name, owner, database_name, statement, recurrence, next_run, created)
+);`
+
+	// schedule_runs stores the history of the runs of the schedules.
+	ScheduleRunsTableSchema = `
+CREATE TABLE system.schedule_runs (
+  schedule_name STRING NOT NULL,
+  started       TIMESTAMP NOT NULL,
+  finished      TIMESTAMP NOT NULL,
+  node_id       INT NOT NULL,
+  row_count     INT,                   -- NULL if the run failed
+  result        JSONB,                 -- the first rows, if the statement returns rows
+  error         STRING,                -- NULL if the run succeeded
+  PRIMARY KEY (schedule_name, started),
+  FAMILY "primary" (schedule_name, started, finished, node_id, row_count, result, error)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -266,6 +316,10 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.LocationsTableID:       privilege.ReadWriteData,
 	keys.RoleMembersTableID:     privilege.ReadWriteData,
 	keys.CommentsTableID:        privilege.ReadWriteData,
+	keys.UserAuthStateTableID:   privilege.ReadWriteData,
+	keys.PinnedPlansTableID:     privilege.ReadWriteData,
+	keys.SchedulesTableID:       privilege.ReadWriteData,
+	keys.ScheduleRunsTableID:    privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -275,6 +329,7 @@ var (
 	colTypeString    = ColumnType{SemanticType: ColumnType_STRING}
 	colTypeBytes     = ColumnType{SemanticType: ColumnType_BYTES}
 	colTypeTimestamp = ColumnType{SemanticType: ColumnType_TIMESTAMP}
+	colTypeJSONB     = ColumnType{SemanticType: ColumnType_JSONB}
 	colTypeIntArray  = ColumnType{
 		SemanticType:    ColumnType_ARRAY,
 		ArrayContents:   &colTypeInt.SemanticType,
@@ -877,6 +932,136 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	zeroIntString = "0:::INT8"
+
+	// UserAuthStateTable is the descriptor for the user_auth_state table.
+	UserAuthStateTable = TableDescriptor{
+		Name:     "user_auth_state",
+		ID:       keys.UserAuthStateTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "username", ID: 1, Type: colTypeString},
+			{Name: "valid_until", ID: 2, Type: colTypeTimestamp, Nullable: true},
+			{Name: "failed_attempts", ID: 3, Type: colTypeInt, DefaultExpr: &zeroIntString},
+			{Name: "locked_until", ID: 4, Type: colTypeTimestamp, Nullable: true},
+		},
+		NextColumnID: 5,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"username", "valid_until", "failed_attempts", "locked_until"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("username"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.UserAuthStateTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// PinnedPlansTable is the descriptor for the pinned_plans table.
+	PinnedPlansTable = TableDescriptor{
+		Name:     "pinned_plans",
+		ID:       keys.PinnedPlansTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "fingerprint", ID: 1, Type: colTypeString},
+			{Name: "gist", ID: 2, Type: colTypeString},
+			{Name: "created", ID: 3, Type: colTypeTimestamp, DefaultExpr: &nowString},
+		},
+		NextColumnID: 4,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"fingerprint", "gist", "created"},
+				ColumnIDs:   []ColumnID{1, 2, 3},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("fingerprint"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.PinnedPlansTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// SchedulesTable is the descriptor for the schedules table.
+	SchedulesTable = TableDescriptor{
+		Name:     "schedules",
+		ID:       keys.SchedulesTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "name", ID: 1, Type: colTypeString},
+			{Name: "owner", ID: 2, Type: colTypeString},
+			{Name: "database_name", ID: 3, Type: colTypeString},
+			{Name: "statement", ID: 4, Type: colTypeString},
+			{Name: "recurrence", ID: 5, Type: colTypeString},
+			{Name: "next_run", ID: 6, Type: colTypeTimestamp, Nullable: true},
+			{Name: "created", ID: 7, Type: colTypeTimestamp, DefaultExpr: &nowString},
+		},
+		NextColumnID: 8,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"name", "owner", "database_name", "statement", "recurrence", "next_run", "created"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4, 5, 6, 7},
+			},
+		},
+		NextFamilyID:   1,
+		PrimaryIndex:   pk("name"),
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.SchedulesTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
+
+	// ScheduleRunsTable is the descriptor for the schedule_runs table.
+	ScheduleRunsTable = TableDescriptor{
+		Name:     "schedule_runs",
+		ID:       keys.ScheduleRunsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "schedule_name", ID: 1, Type: colTypeString},
+			{Name: "started", ID: 2, Type: colTypeTimestamp},
+			{Name: "finished", ID: 3, Type: colTypeTimestamp},
+			{Name: "node_id", ID: 4, Type: colTypeInt},
+			{Name: "row_count", ID: 5, Type: colTypeInt, Nullable: true},
+			{Name: "result", ID: 6, Type: colTypeJSONB, Nullable: true},
+			{Name: "error", ID: 7, Type: colTypeString, Nullable: true},
+		},
+		NextColumnID: 8,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name:        "primary",
+				ID:          0,
+				ColumnNames: []string{"schedule_name", "started", "finished", "node_id", "row_count", "result", "error"},
+				ColumnIDs:   []ColumnID{1, 2, 3, 4, 5, 6, 7},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: IndexDescriptor{
+			Name:             "primary",
+			ID:               1,
+			Unique:           true,
+			ColumnNames:      []string{"schedule_name", "started"},
+			ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC, IndexDescriptor_ASC},
+			ColumnIDs:        []ColumnID{1, 2},
+		},
+		NextIndexID:    2,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ScheduleRunsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
@@ -922,6 +1107,19 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	// The CommentsTable has been introduced in 2.2. It was added here since it
 	// was introduced, but it's also created as a migration for older clusters.
 	target.AddDescriptor(keys.SystemDatabaseID, &CommentsTable)
+
+	// The UserAuthStateTable has been introduced in 19.1. It is also created
+	// as a migration for older clusters.
+	target.AddDescriptor(keys.SystemDatabaseID, &UserAuthStateTable)
+
+	// The PinnedPlansTable has been introduced in 19.1. It is also created as
+	// a migration for older clusters.
+	target.AddDescriptor(keys.SystemDatabaseID, &PinnedPlansTable)
+
+	// The SchedulesTable and ScheduleRunsTable have been introduced in 19.1.
+	// They are also created as a migration for older clusters.
+	target.AddDescriptor(keys.SystemDatabaseID, &SchedulesTable)
+	target.AddDescriptor(keys.SystemDatabaseID, &ScheduleRunsTable)
 }
 
 // addSystemDatabaseToSchema populates the supplied MetadataSchema with the
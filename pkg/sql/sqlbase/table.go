@@ -91,6 +91,12 @@ func MakeColumnDefDescs(
 		return nil, nil, nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
 			"SERIAL cannot be used in this context")
 	}
+	if d.IsIdentity() && !d.HasDefaultExpr() {
+		// Likewise, processSerialInColumnDef() must have created the sequence
+		// of the identity column and used it as its default expression.
+		return nil, nil, nil, pgerror.NewError(pgerror.CodeFeatureNotSupportedError,
+			"identity columns cannot be used in this context")
+	}
 
 	if len(d.CheckExprs) > 0 {
 		// Should never happen since `HoistConstraints` moves these to table level
@@ -138,6 +144,14 @@ func MakeColumnDefDescs(
 	if d.IsComputed() {
 		s := tree.Serialize(d.Computed.Expr)
 		col.ComputeExpr = &s
+		col.Virtual = d.Computed.Virtual
+	}
+
+	if d.IsIdentity() {
+		col.GeneratedAsIdentityType = ColumnDescriptor_GENERATED_BY_DEFAULT
+		if d.Identity.Always {
+			col.GeneratedAsIdentityType = ColumnDescriptor_GENERATED_ALWAYS
+		}
 	}
 
 	var idx *IndexDescriptor
@@ -24,6 +24,10 @@ import (
 // is planned without error in a query.
 var CteUseCounter = telemetry.GetCounterOnce("sql.plan.cte")
 
+// RecursiveCteUseCounter is to be incremented every time a recursive CTE
+// (WITH RECURSIVE ...) is planned without error in a query.
+var RecursiveCteUseCounter = telemetry.GetCounterOnce("sql.plan.cte.recursive")
+
 // SubqueryUseCounter is to be incremented every time a subquery is
 // planned.
 var SubqueryUseCounter = telemetry.GetCounterOnce("sql.plan.subquery")
@@ -32,6 +36,10 @@ var SubqueryUseCounter = telemetry.GetCounterOnce("sql.plan.subquery")
 // correlated subquery has been processed during planning.
 var CorrelatedSubqueryUseCounter = telemetry.GetCounterOnce("sql.plan.subquery.correlated")
 
+// LateralJoinUseCounter is to be incremented every time a LATERAL subquery
+// or function call is planned.
+var LateralJoinUseCounter = telemetry.GetCounterOnce("sql.plan.lateral")
+
 // HashJoinHintUseCounter is to be incremented whenever a query specifies a
 // hash join via a query hint.
 var HashJoinHintUseCounter = telemetry.GetCounterOnce("sql.plan.hints.hash-join")
@@ -65,6 +73,26 @@ var ExplainOptUseCounter = telemetry.GetCounterOnce("sql.plan.explain-opt")
 // EXPLAIN (OPT, VERBOSE) is run.
 var ExplainOptVerboseUseCounter = telemetry.GetCounterOnce("sql.plan.explain-opt-verbose")
 
+// ExplainGistUseCounter is to be incremented whenever EXPLAIN (GIST) is run.
+var ExplainGistUseCounter = telemetry.GetCounterOnce("sql.plan.explain-gist")
+
+// PinnedPlanUseCounter is to be incremented whenever a statement with a
+// pinned plan is planned with its pinned plan.
+var PinnedPlanUseCounter = telemetry.GetCounterOnce("sql.plan.pinned")
+
+// PinnedPlanFallbackCounter is to be incremented whenever a statement with a
+// pinned plan cannot be planned with its pinned plan.
+var PinnedPlanFallbackCounter = telemetry.GetCounterOnce("sql.plan.pinned.fallback")
+
+// HistoricalResultCacheHitCounter is to be incremented whenever the result
+// of a statement run AS OF SYSTEM TIME is served from the historical result
+// cache.
+var HistoricalResultCacheHitCounter = telemetry.GetCounterOnce("sql.exec.historical_result_cache.hit")
+
+// HistoricalResultCacheMissCounter is to be incremented whenever a statement
+// run AS OF SYSTEM TIME whose result can be cached is executed.
+var HistoricalResultCacheMissCounter = telemetry.GetCounterOnce("sql.exec.historical_result_cache.miss")
+
 // CreateStatisticsUseCounter is to be incremented whenever a non-automatic
 // run of CREATE STATISTICS occurs.
 var CreateStatisticsUseCounter = telemetry.GetCounterOnce("sql.plan.stats.created")
@@ -27,3 +27,7 @@ import (
 func SerialColumnNormalizationCounter(inputType, normType string) telemetry.Counter {
 	return telemetry.GetCounter(fmt.Sprintf("sql.schema.serial.%s.%s", normType, inputType))
 }
+
+// IdentityColumnCounter is to be incremented every time an identity column
+// is processed in a column definition.
+var IdentityColumnCounter = telemetry.GetCounterOnce("sql.schema.identity_column")
@@ -16,6 +16,7 @@ package stats
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
@@ -37,12 +38,19 @@ const (
 // system.table_statistics table. For the given tableID and columnIDs,
 // DeleteOldStatsForColumns keeps the most recent keepCount automatic
 // statistics and deletes all the others.
+//
+// The expression and the predicate are those of the new statistic which
+// supersedes the old ones (see TableStatistic). Only the statistics on the
+// same expression are deleted. A partial statistic only supersedes the older
+// partial statistics, while a full statistic supersedes both the full and the
+// partial ones.
 func DeleteOldStatsForColumns(
 	ctx context.Context,
 	executor sqlutil.InternalExecutor,
 	txn *client.Txn,
 	tableID sqlbase.ID,
 	columnIDs []sqlbase.ColumnID,
+	expression, predicate string,
 ) error {
 	columnIDsVal := tree.NewDArray(types.Int)
 	for _, c := range columnIDs {
@@ -51,26 +59,35 @@ func DeleteOldStatsForColumns(
 		}
 	}
 
+	args := []interface{}{tableID, AutoStatsName, columnIDsVal, keepCount}
+	cond := `AND expression IS NULL`
+	if expression != "" {
+		cond = `AND expression = $5`
+		args = append(args, expression)
+	}
+	if predicate != "" {
+		cond += ` AND predicate IS NOT NULL`
+	}
+
 	// This will delete all old statistics for the given table and columns,
 	// including stats created manually (except for a few automatic statistics,
 	// which are identified by the name AutoStatsName).
 	_, err := executor.Exec(
 		ctx, "delete-statistics", txn,
-		`DELETE FROM system.table_statistics
+		fmt.Sprintf(`DELETE FROM system.table_statistics
                WHERE "tableID" = $1
                AND "columnIDs" = $3
+               %[1]s
                AND "statisticID" NOT IN (
                    SELECT "statisticID" FROM system.table_statistics
                    WHERE "tableID" = $1
                    AND "name" = $2
                    AND "columnIDs" = $3
+                   %[1]s
                    ORDER BY "createdAt" DESC
                    LIMIT $4
-               )`,
-		tableID,
-		AutoStatsName,
-		columnIDsVal,
-		keepCount,
+               )`, cond),
+		args...,
 	)
 	return err
 }
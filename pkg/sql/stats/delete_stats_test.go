@@ -255,7 +255,8 @@ func TestDeleteOldStatsForColumns(t *testing.T) {
 		tableID sqlbase.ID, columnIDs []sqlbase.ColumnID, expectDeleted map[uint64]struct{},
 	) error {
 		if err := s.DB().Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
-			return DeleteOldStatsForColumns(ctx, ex, txn, tableID, columnIDs)
+			return DeleteOldStatsForColumns(
+				ctx, ex, txn, tableID, columnIDs, "" /* expression */, "" /* predicate */)
 		}); err != nil {
 			return err
 		}
@@ -26,7 +26,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 )
 
-// InsertNewStat inserts a new statistic in the system table.
+// InsertNewStat inserts a new statistic in the system table. The expression
+// and the predicate are empty unless the statistic is an expression or a
+// partial statistic (see TableStatistic).
 // The caller is responsible for calling GossipTableStatAdded to notify the stat
 // caches.
 func InsertNewStat(
@@ -36,14 +38,21 @@ func InsertNewStat(
 	tableID sqlbase.ID,
 	name string,
 	columnIDs []sqlbase.ColumnID,
+	expression, predicate string,
 	rowCount, distinctCount, nullCount int64,
 	h *HistogramData,
 ) error {
 	// We must pass a nil interface{} if we want to insert a NULL.
-	var nameVal, histogramVal interface{}
+	var nameVal, expressionVal, predicateVal, histogramVal interface{}
 	if name != "" {
 		nameVal = name
 	}
+	if expression != "" {
+		expressionVal = expression
+	}
+	if predicate != "" {
+		predicateVal = predicate
+	}
 	if h != nil {
 		var err error
 		histogramVal, err = protoutil.Marshal(h)
@@ -68,8 +77,10 @@ func InsertNewStat(
 					"rowCount",
 					"distinctCount",
 					"nullCount",
-					histogram
-				) VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+					histogram,
+					expression,
+					predicate
+				) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
 		tableID,
 		nameVal,
 		columnIDsVal,
@@ -77,6 +88,8 @@ func InsertNewStat(
 		distinctCount,
 		nullCount,
 		histogramVal,
+		expressionVal,
+		predicateVal,
 	)
 	return err
 }
@@ -51,6 +51,14 @@ type TableStatistic struct {
 	// The column ID(s) for which this statistic is generated.
 	ColumnIDs []sqlbase.ColumnID
 
+	// For an expression statistic, the SQL text of the expression of the
+	// columns in ColumnIDs which the statistic is on. Empty otherwise.
+	Expression string
+
+	// For a partial statistic, the SQL text of the predicate satisfied by the
+	// rows which the statistic was collected on. Empty otherwise.
+	Predicate string
+
 	// The time at which the statistic was created.
 	CreatedAt time.Time
 
@@ -267,6 +275,8 @@ const (
 	distinctCountIndex
 	nullCountIndex
 	histogramIndex
+	expressionIndex
+	predicateIndex
 	statsLen
 )
 
@@ -297,6 +307,8 @@ func parseStats(datums tree.Datums) (*TableStatistic, error) {
 		{"distinctCount", distinctCountIndex, types.Int, false},
 		{"nullCount", nullCountIndex, types.Int, false},
 		{"histogram", histogramIndex, types.Bytes, true},
+		{"expression", expressionIndex, types.String, true},
+		{"predicate", predicateIndex, types.String, true},
 	}
 	for _, v := range expectedTypes {
 		if datums[v.fieldIndex].ResolvedType() != v.expectedType &&
@@ -323,6 +335,12 @@ func parseStats(datums tree.Datums) (*TableStatistic, error) {
 	if datums[nameIndex] != tree.DNull {
 		tableStatistic.Name = string(*datums[nameIndex].(*tree.DString))
 	}
+	if datums[expressionIndex] != tree.DNull {
+		tableStatistic.Expression = string(*datums[expressionIndex].(*tree.DString))
+	}
+	if datums[predicateIndex] != tree.DNull {
+		tableStatistic.Predicate = string(*datums[predicateIndex].(*tree.DString))
+	}
 	if datums[histogramIndex] != tree.DNull {
 		tableStatistic.Histogram = &HistogramData{}
 		if err := protoutil.Unmarshal(
@@ -351,7 +369,9 @@ SELECT
 	"rowCount",
 	"distinctCount",
 	"nullCount",
-	histogram
+	histogram,
+	expression,
+	predicate
 FROM system.table_statistics
 WHERE "tableID" = $1
 ORDER BY "createdAt" DESC
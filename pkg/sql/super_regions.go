@@ -0,0 +1,144 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/config"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/pkg/errors"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// superRegionsSetting defines the super regions of the cluster. A super
+// region is a named group of regions; a zone config which constrains replicas
+// or leaseholders to one of the regions of a super region is homed in the
+// super region, and must keep all its replicas inside it.
+var superRegionsSetting = settings.RegisterValidatedStringSetting(
+	"sql.zone_configs.super_regions",
+	"the super regions of the cluster, as a YAML map from super region names to lists of regions "+
+		"(example: '{eu: [europe-west1, europe-west2]}'); zone configs which constrain replicas or "+
+		"leaseholders to a region of a super region must constrain all their replicas to the super region",
+	"",
+	func(_ *settings.Values, s string) error {
+		_, err := parseSuperRegions(s)
+		return err
+	},
+)
+
+// superRegions maps the names of super regions to their regions.
+type superRegions map[string][]string
+
+// parseSuperRegions parses and validates the value of the
+// sql.zone_configs.super_regions setting.
+func parseSuperRegions(s string) (superRegions, error) {
+	var res superRegions
+	if err := yaml.UnmarshalStrict([]byte(s), &res); err != nil {
+		return nil, errors.Wrap(err, "could not parse super regions")
+	}
+	owners := make(map[string]string)
+	for name, regions := range res {
+		if name == "" {
+			return nil, errors.New("super region names must not be empty")
+		}
+		if len(regions) == 0 {
+			return nil, errors.Errorf("super region %q has no regions", name)
+		}
+		for _, region := range regions {
+			if region == "" {
+				return nil, errors.Errorf("super region %q has an empty region", name)
+			}
+			if owner, ok := owners[region]; ok {
+				if owner == name {
+					return nil, errors.Errorf("region %q is listed twice in super region %q", region, name)
+				}
+				return nil, errors.Errorf(
+					"region %q belongs to both super regions %q and %q", region, owner, name)
+			}
+			owners[region] = name
+		}
+	}
+	return res, nil
+}
+
+// homes returns the sorted names of the super regions in which the zone
+// config is homed, that is the super regions with a region to which the zone
+// config requires replicas or prefers leaseholders to be.
+func (s superRegions) homes(zone *config.ZoneConfig) []string {
+	owners := make(map[string]string)
+	for name, regions := range s {
+		for _, region := range regions {
+			owners[region] = name
+		}
+	}
+	homes := make(map[string]struct{})
+	addHomes := func(constraints []config.Constraint) {
+		for _, c := range constraints {
+			if c.Type == config.Constraint_REQUIRED && c.Key == "region" {
+				if name, ok := owners[c.Value]; ok {
+					homes[name] = struct{}{}
+				}
+			}
+		}
+	}
+	for _, c := range zone.Constraints {
+		addHomes(c.Constraints)
+	}
+	for _, p := range zone.LeasePreferences {
+		addHomes(p.Constraints)
+	}
+	res := make([]string, 0, len(homes))
+	for name := range homes {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// check returns the first super region in which the complete zone config is
+// homed, but which does not contain all its replicas, along with an error
+// describing the violation. It returns an empty name and a nil error if the
+// zone config satisfies the super regions.
+func (s superRegions) check(zone *config.ZoneConfig) (string, error) {
+	var numReplicas int32
+	if zone.NumReplicas != nil {
+		numReplicas = *zone.NumReplicas
+	}
+	for _, name := range s.homes(zone) {
+		if !constrainedToRegions(zone, numReplicas, s[name]...) {
+			return name, pgerror.NewErrorf(pgerror.CodeCheckViolationError,
+				"zone config is homed in super region %s, but does not constrain all its replicas "+
+					"to the regions of the super region (%s)", name, strings.Join(s[name], ", "),
+			).SetHintf("constrain all the replicas to the super region, "+
+				"for example with constraints = '[+region=%s]'", s[name][0])
+		}
+	}
+	return "", nil
+}
+
+// validateZoneSuperRegions checks that the complete zone config keeps all its
+// replicas inside the super regions in which it is homed, according to the
+// sql.zone_configs.super_regions setting.
+func validateZoneSuperRegions(sv *settings.Values, zone *config.ZoneConfig) error {
+	s, err := parseSuperRegions(superRegionsSetting.Get(sv))
+	if err != nil {
+		return err
+	}
+	_, err = s.check(zone)
+	return err
+}
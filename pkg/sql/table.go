@@ -173,6 +173,10 @@ type TableCollection struct {
 	// an uncommitted transaction.
 	uncommittedDatabases []uncommittedDatabase
 
+	// Database descriptors whose read mode was changed by the uncommitted
+	// transaction, by database ID.
+	uncommittedDatabaseReadModes map[sqlbase.ID]*sqlbase.DatabaseDescriptor
+
 	// allDescriptors is a slice of all available descriptors. The descriptors
 	// are cached to avoid repeated lookups by users like virtual tables. The
 	// cache is purged whenever events would cause a scan of all descriptors to
@@ -455,6 +459,7 @@ func (tc *TableCollection) releaseTables(ctx context.Context) {
 	tc.releaseLeases(ctx)
 	tc.uncommittedTables = nil
 	tc.uncommittedDatabases = nil
+	tc.uncommittedDatabaseReadModes = nil
 	tc.releaseAllDescriptors()
 }
 
@@ -491,6 +496,21 @@ func (tc *TableCollection) waitForCacheToDropDatabases(ctx context.Context) {
 	}
 }
 
+// Wait until the database cache has been updated to reflect the read mode
+// of the databases altered by the transaction, so that future commands on
+// the same gateway node observe the new read mode.
+func (tc *TableCollection) waitForCacheToUpdateDatabaseReadModes(ctx context.Context) {
+	for id, desc := range tc.uncommittedDatabaseReadModes {
+		id, readOnly := id, desc.ReadOnly
+		tc.dbCacheSubscriber.waitForCacheState(
+			func(dc *databaseCache) bool {
+				cached, err := dc.getCachedDatabaseDescByID(id)
+				// A database that can't be found has been dropped since.
+				return err != nil || cached == nil || cached.ReadOnly == readOnly
+			})
+	}
+}
+
 func (tc *TableCollection) hasUncommittedTables() bool {
 	return len(tc.uncommittedTables) > 0
 }
@@ -544,6 +564,34 @@ func (tc *TableCollection) addUncommittedDatabase(name string, id sqlbase.ID, ac
 	tc.releaseAllDescriptors()
 }
 
+// addUncommittedDatabaseReadMode records that the read mode of the given
+// database was changed within the transaction.
+func (tc *TableCollection) addUncommittedDatabaseReadMode(desc *sqlbase.DatabaseDescriptor) {
+	if tc.uncommittedDatabaseReadModes == nil {
+		tc.uncommittedDatabaseReadModes = make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
+	}
+	tc.uncommittedDatabaseReadModes[desc.ID] = desc
+}
+
+// getDatabaseDescForReadMode returns the descriptor of the database with the
+// given ID, for the purpose of checking its read mode. The descriptor is
+// looked up in the database cache, unless the read mode of the database was
+// changed within the transaction.
+func (tc *TableCollection) getDatabaseDescForReadMode(
+	ctx context.Context, txn *client.Txn, id sqlbase.ID,
+) (*sqlbase.DatabaseDescriptor, error) {
+	if desc, ok := tc.uncommittedDatabaseReadModes[id]; ok {
+		return desc, nil
+	}
+	desc, err := tc.databaseCache.getDatabaseDescByID(ctx, txn, id)
+	if err == nil && desc == nil {
+		// The database is not in the cache yet, e.g. because it was created
+		// in this transaction.
+		desc, err = MustGetDatabaseDescByID(ctx, txn, id)
+	}
+	return desc, err
+}
+
 // getUncommittedDatabaseID returns a database ID for the requested tablename
 // if the requested tablename is for a database modified within the transaction
 // affiliated with the LeaseCollection.
@@ -666,6 +714,7 @@ func (tc *TableCollection) copyModifiedSchema(to *TableCollection) {
 	}
 	to.uncommittedTables = tc.uncommittedTables
 	to.uncommittedDatabases = tc.uncommittedDatabases
+	to.uncommittedDatabaseReadModes = tc.uncommittedDatabaseReadModes
 	// Do not copy the leased descriptors because we do not want
 	// the leased descriptors to be released by the "to" TableCollection.
 	// The "to" TableCollection can re-lease the same descriptors.
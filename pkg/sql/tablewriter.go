@@ -128,6 +128,11 @@ type tableWriterBase struct {
 	b *client.Batch
 	// batchSize is the current batch size (when known).
 	batchSize int
+	// checkBatch, if set, is called after the current batch has run
+	// successfully, to verify the results of the reads that were added
+	// to it alongside the writes. It must not be set when autoCommit is
+	// enabled, as the reads would then be verified after the commit.
+	checkBatch func(context.Context, *client.Batch) error
 }
 
 func (tb *tableWriterBase) init(txn *client.Txn) {
@@ -143,6 +148,11 @@ func (tb *tableWriterBase) flushAndStartNewBatch(
 	if err := tb.txn.Run(ctx, tb.b); err != nil {
 		return row.ConvertBatchError(ctx, tableDesc, tb.b)
 	}
+	if tb.checkBatch != nil {
+		if err := tb.checkBatch(ctx, tb.b); err != nil {
+			return err
+		}
+	}
 	tb.b = tb.txn.NewBatch()
 	tb.batchSize = 0
 	return nil
@@ -167,6 +177,9 @@ func (tb *tableWriterBase) finalize(
 	if err != nil {
 		return row.ConvertBatchError(ctx, tableDesc, tb.b)
 	}
+	if tb.checkBatch != nil {
+		return tb.checkBatch(ctx, tb.b)
+	}
 	return nil
 }
 
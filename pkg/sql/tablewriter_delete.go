@@ -118,7 +118,9 @@ func (td *tableDeleter) deleteAllRows(
 // table, resulting in substantial write amplification. When possible, the
 // schema changer avoids using a tableDeleter entirely in favor of the
 // ClearRange KV request, which uses RocksDB range deletion tombstones to avoid
-// write amplification.
+// write amplification. Otherwise, the DelRange leaves a GC hint on the ranges
+// it empties, which lets the GC queue clear them the same way once the
+// deleted rows expire.
 func (td *tableDeleter) deleteAllRowsFast(
 	ctx context.Context, resume roachpb.Span, limit int64, traceKV bool,
 ) (roachpb.Span, error) {
@@ -134,7 +136,7 @@ func (td *tableDeleter) deleteAllRowsFast(
 	}
 
 	log.VEventf(ctx, 2, "DelRange %s - %s", resume.Key, resume.EndKey)
-	td.b.DelRange(resume.Key, resume.EndKey, false /* returnKeys */)
+	td.b.DelRangeWithGCHint(resume.Key, resume.EndKey, false /* returnKeys */)
 	td.b.Header.MaxSpanRequestKeys = limit
 	if _, err := td.finalize(ctx, traceKV); err != nil {
 		return resume, err
@@ -227,7 +229,7 @@ func (td *tableDeleter) deleteIndexFast(
 	if traceKV {
 		log.VEventf(ctx, 2, "DelRange %s - %s", resume.Key, resume.EndKey)
 	}
-	td.b.DelRange(resume.Key, resume.EndKey, false /* returnKeys */)
+	td.b.DelRangeWithGCHint(resume.Key, resume.EndKey, false /* returnKeys */)
 	td.b.Header.MaxSpanRequestKeys = limit
 	if _, err := td.finalize(ctx, traceKV); err != nil {
 		return resume, err
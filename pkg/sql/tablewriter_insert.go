@@ -36,6 +36,14 @@ func (*tableInserter) desc() string { return "inserter" }
 // init is part of the tableWriter interface.
 func (ti *tableInserter) init(txn *client.Txn, _ *tree.EvalContext) error {
 	ti.tableWriterBase.init(txn)
+	// When the transaction is not committed together with the last batch,
+	// the FK existence checks can be sent in the same KV batches as the
+	// inserted rows, which saves one round-trip per row. Otherwise, the
+	// checks must be verified before the commit, so they are run separately
+	// to keep the one-phase commit of the final batch possible.
+	if ti.autoCommit != autoCommitEnabled && ti.ri.EnableFKChecksInBatch() {
+		ti.checkBatch = ti.ri.CheckFKsInBatch
+	}
 	return nil
 }
 
@@ -95,7 +95,13 @@ func (tu *optTableUpserter) init(txn *client.Txn, evalCtx *tree.EvalContext) err
 }
 
 // desc is part of the tableWriter interface.
-func (*optTableUpserter) desc() string { return "opt upserter" }
+func (tu *optTableUpserter) desc() string {
+	if tu.canaryOrdinal == -1 {
+		// Existing rows are blindly overwritten; see row() below.
+		return "opt fast upserter"
+	}
+	return "opt upserter"
+}
 
 // row is part of the tableWriter interface.
 func (tu *optTableUpserter) row(ctx context.Context, row tree.Datums, traceKV bool) error {
@@ -258,7 +258,8 @@ func TestRandomSyntaxFunctions(t *testing.T) {
 		for {
 			for _, name := range builtins.AllBuiltinNames {
 				lower := strings.ToLower(name)
-				if strings.HasPrefix(lower, "crdb_internal.force_") {
+				if strings.HasPrefix(lower, "crdb_internal.force_") ||
+					strings.HasPrefix(lower, "crdb_internal.unsafe_") {
 					continue
 				}
 				switch lower {
@@ -120,6 +120,10 @@ func TestSystemTableLiterals(t *testing.T) {
 		{keys.LocationsTableID, sqlbase.LocationsTableSchema, sqlbase.LocationsTable},
 		{keys.RoleMembersTableID, sqlbase.RoleMembersTableSchema, sqlbase.RoleMembersTable},
 		{keys.CommentsTableID, sqlbase.CommentsTableSchema, sqlbase.CommentsTable},
+		{keys.UserAuthStateTableID, sqlbase.UserAuthStateTableSchema, sqlbase.UserAuthStateTable},
+		{keys.PinnedPlansTableID, sqlbase.PinnedPlansTableSchema, sqlbase.PinnedPlansTable},
+		{keys.SchedulesTableID, sqlbase.SchedulesTableSchema, sqlbase.SchedulesTable},
+		{keys.ScheduleRunsTableID, sqlbase.ScheduleRunsTableSchema, sqlbase.ScheduleRunsTable},
 	} {
 		privs := *test.pkg.Privileges
 		gen, err := sql.CreateTestTableDescriptor(
@@ -0,0 +1,691 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// triggerMaxDepth is the maximum nesting depth of triggers: the statements
+// executed by a trigger can fire other triggers, or the same trigger again.
+const triggerMaxDepth = 32
+
+// triggerDepthKey is the context key under which the current nesting depth of
+// triggers is stored.
+type triggerDepthKey struct{}
+
+// rowTriggers fires the triggers of a table for the rows that are modified by
+// an INSERT, UPDATE, or DELETE statement.
+//
+// The BEFORE triggers are fired for each row before it is written, and can
+// modify the new row or skip the row altogether. The AFTER triggers are fired
+// once the batch containing the row has been written, so that they observe
+// the effects of the statement; their return value is ignored.
+//
+// The statement-level triggers are fired once per statement, even if it does
+// not modify any row: the BEFORE triggers before the first row is processed,
+// and the AFTER triggers after the last one has been written.
+//
+// Triggers are not fired for the rows modified by foreign key cascades, and
+// UPSERT and INSERT ... ON CONFLICT are not supported on tables with triggers.
+type rowTriggers struct {
+	tableDesc *sqlbase.ImmutableTableDescriptor
+	event     sqlbase.TriggerDescriptor_Event
+
+	before []rowTrigger
+	after  []rowTrigger
+
+	// beforeStatement and afterStatement are the statement-level triggers.
+	beforeStatement []rowTrigger
+	afterStatement  []rowTrigger
+
+	// pendingAfter contains the rows for which the AFTER triggers have yet to
+	// be fired.
+	pendingAfter []triggerRows
+
+	// ie is used to execute the SQL statements in the bodies of the triggers.
+	// It is initialized when first needed.
+	ie *SessionBoundInternalExecutor
+}
+
+// rowTrigger is a trigger whose body has been parsed.
+type rowTrigger struct {
+	desc *sqlbase.TriggerDescriptor
+	body tree.TriggerBody
+}
+
+// triggerRows contains the OLD and NEW records of an invocation of a trigger.
+// Each record contains the values of the public columns of the table, in
+// order. The record which does not exist for the event (OLD for INSERT, NEW for
+// DELETE) is nil, and all its columns are NULL.
+type triggerRows struct {
+	old, new tree.Datums
+}
+
+// makeRowTriggers returns the triggers of the given table which are fired by
+// the given event, or nil if there are none.
+func makeRowTriggers(
+	tableDesc *sqlbase.ImmutableTableDescriptor, event sqlbase.TriggerDescriptor_Event,
+) (*rowTriggers, error) {
+	if !tableDesc.HasTrigger(event) {
+		return nil, nil
+	}
+	rt := &rowTriggers{tableDesc: tableDesc, event: event}
+	for i := range tableDesc.Triggers {
+		desc := &tableDesc.Triggers[i]
+		if !desc.HasEvent(event) {
+			continue
+		}
+		body, err := parser.ParseTriggerBody(desc.Body)
+		if err != nil {
+			return nil, pgerror.Wrapf(err, pgerror.CodeDataExceptionError,
+				"error parsing the body of trigger %q", desc.Name)
+		}
+		t := rowTrigger{desc: desc, body: body}
+		switch {
+		case desc.Level == sqlbase.TriggerDescriptor_STATEMENT &&
+			desc.ActionTime == sqlbase.TriggerDescriptor_BEFORE:
+			rt.beforeStatement = append(rt.beforeStatement, t)
+		case desc.Level == sqlbase.TriggerDescriptor_STATEMENT:
+			rt.afterStatement = append(rt.afterStatement, t)
+		case desc.ActionTime == sqlbase.TriggerDescriptor_BEFORE:
+			rt.before = append(rt.before, t)
+		default:
+			rt.after = append(rt.after, t)
+		}
+	}
+	return rt, nil
+}
+
+// makeRow returns a record containing the given values. colIDtoRowIndex maps
+// the IDs of the columns to their position in vals; the columns which are not
+// in the map are NULL.
+func (rt *rowTriggers) makeRow(
+	colIDtoRowIndex map[sqlbase.ColumnID]int, vals tree.Datums,
+) tree.Datums {
+	cols := rt.tableDesc.Columns
+	row := make(tree.Datums, len(cols))
+	for i := range cols {
+		if idx, ok := colIDtoRowIndex[cols[i].ID]; ok {
+			row[i] = vals[idx]
+		} else {
+			row[i] = tree.DNull
+		}
+	}
+	return row
+}
+
+// overlayRow replaces the values in the given record with those of the
+// columns in colIDtoRowIndex.
+func (rt *rowTriggers) overlayRow(
+	row tree.Datums, colIDtoRowIndex map[sqlbase.ColumnID]int, vals tree.Datums,
+) {
+	for i := range rt.tableDesc.Columns {
+		if idx, ok := colIDtoRowIndex[rt.tableDesc.Columns[i].ID]; ok {
+			row[i] = vals[idx]
+		}
+	}
+}
+
+// fireBefore fires the BEFORE triggers for a row, and returns false if the row
+// must be skipped. The triggers can assign the columns of the new row which
+// are in colIDtoRowIndex; the new row is then stored back into vals.
+func (rt *rowTriggers) fireBefore(
+	params runParams, rows triggerRows, colIDtoRowIndex map[sqlbase.ColumnID]int, vals tree.Datums,
+) (bool, error) {
+	if len(rt.before) == 0 {
+		return true, nil
+	}
+	ctx, err := enterTrigger(params.ctx)
+	if err != nil {
+		return false, err
+	}
+	for i := range rt.before {
+		r := triggerRun{
+			ctx:      ctx,
+			params:   params,
+			rt:       rt,
+			trigger:  &rt.before[i],
+			rows:     &rows,
+			writable: colIDtoRowIndex,
+		}
+		returned, val, err := r.execStmts(r.trigger.body)
+		if err != nil {
+			return false, err
+		}
+		if !returned {
+			return false, pgerror.NewErrorf(
+				pgerror.CodeRoutineExceptionFunctionExecutedNoReturnStatementError,
+				"control reached end of trigger %q without RETURN", r.trigger.desc.Name)
+		}
+		switch val {
+		case tree.TriggerReturnOld:
+			if rows.old == nil {
+				return false, nil
+			}
+			rows.new = append(tree.Datums(nil), rows.old...)
+		case tree.TriggerReturnNew:
+			if rows.new == nil {
+				return false, nil
+			}
+		default:
+			return false, nil
+		}
+	}
+	if rt.event != sqlbase.TriggerDescriptor_DELETE {
+		for i := range rt.tableDesc.Columns {
+			if idx, ok := colIDtoRowIndex[rt.tableDesc.Columns[i].ID]; ok {
+				vals[idx] = rows.new[i]
+			}
+		}
+	}
+	return true, nil
+}
+
+// queueAfter queues the AFTER triggers to be fired for a row, once the batch
+// containing the row has been written.
+func (rt *rowTriggers) queueAfter(rows triggerRows) {
+	if len(rt.after) > 0 {
+		rt.pendingAfter = append(rt.pendingAfter, rows)
+	}
+}
+
+// fireAfter fires the AFTER triggers for the rows queued by queueAfter.
+func (rt *rowTriggers) fireAfter(params runParams) error {
+	if len(rt.pendingAfter) == 0 {
+		return nil
+	}
+	ctx, err := enterTrigger(params.ctx)
+	if err != nil {
+		return err
+	}
+	for i := range rt.pendingAfter {
+		for j := range rt.after {
+			r := triggerRun{
+				ctx:     ctx,
+				params:  params,
+				rt:      rt,
+				trigger: &rt.after[j],
+				rows:    &rt.pendingAfter[i],
+			}
+			if _, _, err := r.execStmts(r.trigger.body); err != nil {
+				return err
+			}
+		}
+	}
+	rt.pendingAfter = rt.pendingAfter[:0]
+	return nil
+}
+
+// fireBeforeStatement fires the statement-level BEFORE triggers.
+func (rt *rowTriggers) fireBeforeStatement(params runParams) error {
+	return rt.fireStatement(params, rt.beforeStatement)
+}
+
+// fireAfterStatement fires the statement-level AFTER triggers. It must be
+// called after the AFTER triggers of the last rows have been fired.
+func (rt *rowTriggers) fireAfterStatement(params runParams) error {
+	return rt.fireStatement(params, rt.afterStatement)
+}
+
+// fireStatement fires the given statement-level triggers. All the columns of
+// their NEW and OLD records are NULL, and their return value is ignored.
+func (rt *rowTriggers) fireStatement(params runParams, triggers []rowTrigger) error {
+	if len(triggers) == 0 {
+		return nil
+	}
+	ctx, err := enterTrigger(params.ctx)
+	if err != nil {
+		return err
+	}
+	for i := range triggers {
+		r := triggerRun{
+			ctx:     ctx,
+			params:  params,
+			rt:      rt,
+			trigger: &triggers[i],
+			rows:    &triggerRows{},
+		}
+		if _, _, err := r.execStmts(r.trigger.body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// enterTrigger returns the context in which the statements of a trigger are
+// executed, which records the nesting depth of triggers.
+func enterTrigger(ctx context.Context) (context.Context, error) {
+	depth, _ := ctx.Value(triggerDepthKey{}).(int)
+	if depth >= triggerMaxDepth {
+		return nil, pgerror.NewErrorf(pgerror.CodeProgramLimitExceededError,
+			"trigger nesting depth exceeds the maximum of %d", triggerMaxDepth)
+	}
+	return context.WithValue(ctx, triggerDepthKey{}, depth+1), nil
+}
+
+// internalExecutor returns the executor used to run the SQL statements in the
+// bodies of the triggers. The statements are executed in the transaction of
+// the statement that fired the triggers, as the current user, and with the
+// database of the table as the current database.
+func (rt *rowTriggers) internalExecutor(params runParams) (*SessionBoundInternalExecutor, error) {
+	if rt.ie == nil {
+		dbDesc, err := getDatabaseDescByID(params.ctx, params.p.txn, rt.tableDesc.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		rt.ie = routineInternalExecutor(params.p, dbDesc.Name)
+	}
+	return rt.ie, nil
+}
+
+// triggerRun is the state of an invocation of a trigger for a row.
+type triggerRun struct {
+	ctx     context.Context
+	params  runParams
+	rt      *rowTriggers
+	trigger *rowTrigger
+	rows    *triggerRows
+
+	// writable maps the IDs of the columns which can be assigned by the
+	// trigger to their position in the row written by the statement.
+	writable map[sqlbase.ColumnID]int
+}
+
+// execStmts executes the given statements. If a RETURN statement is executed,
+// it returns true along with the returned value.
+func (r *triggerRun) execStmts(
+	stmts []tree.TriggerStmt,
+) (returned bool, val tree.TriggerReturnValue, err error) {
+	for _, stmt := range stmts {
+		switch t := stmt.(type) {
+		case *tree.TriggerAssign:
+			err = r.execAssign(t)
+
+		case *tree.TriggerIf:
+			var branch []tree.TriggerStmt
+			if branch, err = r.chooseBranch(t); err == nil {
+				returned, val, err = r.execStmts(branch)
+			}
+
+		case *tree.TriggerRaise:
+			err = r.execRaise(t)
+
+		case *tree.TriggerReturn:
+			return true, t.Value, nil
+
+		case *tree.TriggerExec:
+			err = r.execSQL(t.Statement)
+
+		default:
+			err = pgerror.NewAssertionErrorf("unexpected trigger statement %T", stmt)
+		}
+		if err != nil || returned {
+			return returned, val, err
+		}
+	}
+	return false, 0, nil
+}
+
+func (r *triggerRun) execAssign(stmt *tree.TriggerAssign) error {
+	ord, col, err := r.lookupColumn("new", string(stmt.Column))
+	if err != nil {
+		return err
+	}
+	if _, ok := r.writable[col.ID]; !ok || r.rows.new == nil {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"trigger %q cannot assign column %q, which is not written by the statement",
+			r.trigger.desc.Name, col.Name).SetHintf(
+			"assign the column in the statement instead")
+	}
+	d, err := r.evalExpr(stmt.Expr, col.Type.ToDatumType())
+	if err != nil {
+		return err
+	}
+	if err := sqlbase.CheckDatumTypeFitsColumnType(*col, d.ResolvedType(), nil /* pmap */); err != nil {
+		return err
+	}
+	if d, err = sqlbase.LimitValueWidth(col.Type, d, &col.Name); err != nil {
+		return err
+	}
+	if d == tree.DNull && !col.Nullable {
+		return sqlbase.NewNonNullViolationError(col.Name)
+	}
+	r.rows.new[ord] = d
+	return nil
+}
+
+// chooseBranch returns the statements of the branch of an IF statement whose
+// condition is true, or of its ELSE branch.
+func (r *triggerRun) chooseBranch(stmt *tree.TriggerIf) ([]tree.TriggerStmt, error) {
+	if ok, err := r.evalCond(stmt.Cond); err != nil || ok {
+		return stmt.Then, err
+	}
+	for i := range stmt.ElseIfs {
+		if ok, err := r.evalCond(stmt.ElseIfs[i].Cond); err != nil || ok {
+			return stmt.ElseIfs[i].Stmts, err
+		}
+	}
+	return stmt.Else, nil
+}
+
+func (r *triggerRun) evalCond(expr tree.Expr) (bool, error) {
+	d, err := r.evalExpr(expr, types.Bool)
+	if err != nil || d == tree.DNull {
+		return false, err
+	}
+	b, ok := d.(*tree.DBool)
+	if !ok {
+		return false, pgerror.NewErrorf(pgerror.CodeDatatypeMismatchError,
+			"argument of IF must be type bool, not type %s", d.ResolvedType())
+	}
+	return bool(*b), nil
+}
+
+func (r *triggerRun) execRaise(stmt *tree.TriggerRaise) error {
+	var buf bytes.Buffer
+	argIdx := 0
+	for i := 0; i < len(stmt.Message); i++ {
+		c := stmt.Message[i]
+		if c != '%' {
+			buf.WriteByte(c)
+			continue
+		}
+		if i+1 < len(stmt.Message) && stmt.Message[i+1] == '%' {
+			buf.WriteByte('%')
+			i++
+			continue
+		}
+		// The parser checks that the number of arguments matches the number of
+		// parameters in the message.
+		d, err := r.evalExpr(stmt.Args[argIdx], types.Any)
+		if err != nil {
+			return err
+		}
+		argIdx++
+		if d == tree.DNull {
+			buf.WriteString("<NULL>")
+		} else {
+			buf.WriteString(tree.AsStringWithFlags(d, tree.FmtBareStrings))
+		}
+	}
+	return pgerror.NewError(pgerror.CodeRaiseExceptionError, buf.String())
+}
+
+// execSQL executes a SQL statement, after replacing the references to the
+// columns of the NEW and OLD records with their values. The results of SELECT
+// statements are discarded.
+func (r *triggerRun) execSQL(stmt tree.Statement) error {
+	sql, err := r.bindRecords(tree.AsStringWithFlags(stmt, tree.FmtParsable))
+	if err != nil {
+		return err
+	}
+	ie, err := r.rt.internalExecutor(r.params)
+	if err != nil {
+		return err
+	}
+	opName := "trigger-" + r.trigger.desc.Name
+	if _, ok := stmt.(*tree.Select); ok {
+		_, err = ie.Query(r.ctx, opName, r.params.p.txn, sql)
+	} else {
+		_, err = ie.Exec(r.ctx, opName, r.params.p.txn, sql)
+	}
+	return err
+}
+
+// evalExpr evaluates an expression in the body of the trigger. Expressions
+// without subqueries are evaluated locally; the others are executed as a
+// SELECT statement.
+func (r *triggerRun) evalExpr(expr tree.Expr, desired types.T) (tree.Datum, error) {
+	v := triggerRecordVisitor{run: r}
+	newExpr, _ := tree.WalkExpr(&v, expr)
+	if v.err != nil {
+		return nil, v.err
+	}
+	if v.hasSubquery {
+		sql, err := r.bindRecords(tree.AsStringWithFlags(newExpr, tree.FmtParsable))
+		if err != nil {
+			return nil, err
+		}
+		ie, err := r.rt.internalExecutor(r.params)
+		if err != nil {
+			return nil, err
+		}
+		row, err := ie.QueryRow(r.ctx, "trigger-"+r.trigger.desc.Name, r.params.p.txn, "SELECT "+sql)
+		if err != nil {
+			return nil, err
+		}
+		return row[0], nil
+	}
+
+	semaCtx := tree.MakeSemaContext()
+	semaCtx.SearchPath = r.params.SessionData().SearchPath
+	semaCtx.Properties.Require("trigger bodies", tree.RejectSpecial)
+	typedExpr, err := tree.TypeCheck(newExpr, &semaCtx, desired)
+	if err != nil {
+		return nil, err
+	}
+	return typedExpr.Eval(r.params.EvalContext())
+}
+
+// bindRecords replaces the references to the columns of the NEW and OLD
+// records in the given SQL text with their values.
+func (r *triggerRun) bindRecords(sql string) (string, error) {
+	refs, err := parser.FindTriggerRecordRefs(sql)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	pos := 0
+	for _, ref := range refs {
+		val, err := r.lookupValue(ref.Record, ref.Column)
+		if err != nil {
+			return "", err
+		}
+		buf.WriteString(sql[pos:ref.Start])
+		buf.WriteByte('(')
+		buf.WriteString(tree.AsStringWithFlags(val, tree.FmtParsable))
+		buf.WriteByte(')')
+		pos = ref.End
+	}
+	buf.WriteString(sql[pos:])
+	return buf.String(), nil
+}
+
+// lookupColumn returns the position and descriptor of a column of the given
+// record.
+func (r *triggerRun) lookupColumn(
+	record string, name string,
+) (int, *sqlbase.ColumnDescriptor, error) {
+	cols := r.rt.tableDesc.Columns
+	for i := range cols {
+		if cols[i].Name == name {
+			return i, &cols[i], nil
+		}
+	}
+	return 0, nil, pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+		"record %q has no field %q", record, name)
+}
+
+// lookupValue returns the value of a column of the given record, as a typed
+// expression.
+func (r *triggerRun) lookupValue(record string, name string) (tree.TypedExpr, error) {
+	ord, col, err := r.lookupColumn(record, name)
+	if err != nil {
+		return nil, err
+	}
+	row := r.rows.new
+	if record == "old" {
+		row = r.rows.old
+	}
+	if row == nil || row[ord] == tree.DNull {
+		// Annotate NULL values with the type of the column, so that they can be
+		// type checked like any other value of the column.
+		return tree.ReType(tree.DNull, col.Type.ToDatumType())
+	}
+	return row[ord], nil
+}
+
+// triggerRecordVisitor replaces the references to the columns of the NEW and
+// OLD records in an expression with their values. It does not descend into
+// subqueries.
+type triggerRecordVisitor struct {
+	run         *triggerRun
+	hasSubquery bool
+	err         error
+}
+
+var _ tree.Visitor = &triggerRecordVisitor{}
+
+func (v *triggerRecordVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if v.err != nil {
+		return false, expr
+	}
+	switch t := expr.(type) {
+	case *tree.Subquery:
+		v.hasSubquery = true
+		return false, expr
+	case *tree.UnresolvedName:
+		if t.NumParts != 2 || t.Star || (t.Parts[1] != "new" && t.Parts[1] != "old") {
+			return false, expr
+		}
+		val, err := v.run.lookupValue(t.Parts[1], t.Parts[0])
+		if err != nil {
+			v.err = err
+			return false, expr
+		}
+		return false, val
+	}
+	return true, expr
+}
+
+func (*triggerRecordVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// validateTriggerBody checks that the body of a trigger can be executed for
+// the rows of the given table.
+//
+// Assignments to the columns of the NEW record are only allowed in row-level
+// BEFORE triggers which are not fired by DELETE. The assigned columns cannot be
+// computed, nor be referenced by computed columns or CHECK constraints, as
+// those are evaluated before the triggers are fired.
+func validateTriggerBody(
+	tableDesc *sqlbase.TableDescriptor, trigger *sqlbase.TriggerDescriptor, body tree.TriggerBody,
+) error {
+	var validateStmts func(stmts []tree.TriggerStmt) error
+	validateStmts = func(stmts []tree.TriggerStmt) error {
+		for _, stmt := range stmts {
+			switch t := stmt.(type) {
+			case *tree.TriggerIf:
+				if err := validateStmts(t.Then); err != nil {
+					return err
+				}
+				for i := range t.ElseIfs {
+					if err := validateStmts(t.ElseIfs[i].Stmts); err != nil {
+						return err
+					}
+				}
+				if err := validateStmts(t.Else); err != nil {
+					return err
+				}
+
+			case *tree.TriggerAssign:
+				if err := validateTriggerAssign(tableDesc, trigger, t); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return validateStmts(body)
+}
+
+func validateTriggerAssign(
+	tableDesc *sqlbase.TableDescriptor, trigger *sqlbase.TriggerDescriptor, stmt *tree.TriggerAssign,
+) error {
+	if trigger.Level != sqlbase.TriggerDescriptor_ROW {
+		return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+			"cannot assign to NEW in a statement-level trigger")
+	}
+	if trigger.ActionTime != sqlbase.TriggerDescriptor_BEFORE {
+		return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+			"cannot assign to NEW in an AFTER trigger")
+	}
+	if trigger.HasEvent(sqlbase.TriggerDescriptor_DELETE) {
+		return pgerror.NewErrorf(pgerror.CodeInvalidFunctionDefinitionError,
+			"cannot assign to NEW in a trigger fired by DELETE")
+	}
+	col, err := tableDesc.FindActiveColumnByName(string(stmt.Column))
+	if err != nil {
+		return pgerror.NewErrorf(pgerror.CodeUndefinedColumnError,
+			"record \"new\" has no field %q", stmt.Column)
+	}
+	if col.IsComputed() {
+		return sqlbase.CannotWriteToComputedColError(col.Name)
+	}
+
+	notSupported := func(kind string) error {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"cannot assign to column %q in a trigger, because it is referenced by %s",
+			col.Name, kind)
+	}
+	for i := range tableDesc.Columns {
+		if c := &tableDesc.Columns[i]; c.IsComputed() {
+			if used, err := exprReferencesColumn(*c.ComputeExpr, col.Name); err != nil {
+				return err
+			} else if used {
+				return notSupported("computed column " + tree.ErrString((*tree.Name)(&c.Name)))
+			}
+		}
+	}
+	for _, check := range tableDesc.AllActiveAndInactiveChecks() {
+		if used, err := exprReferencesColumn(check.Expr, col.Name); err != nil {
+			return err
+		} else if used {
+			return notSupported("check constraint " + tree.ErrString((*tree.Name)(&check.Name)))
+		}
+	}
+	return nil
+}
+
+// exprReferencesColumn returns true if the given expression, as stored in a
+// table descriptor, references the column with the given name.
+func exprReferencesColumn(exprStr string, colName string) (bool, error) {
+	expr, err := parser.ParseExpr(exprStr)
+	if err != nil {
+		return false, err
+	}
+	found := false
+	_, err = tree.SimpleVisit(expr, func(expr tree.Expr) (err error, recurse bool, newExpr tree.Expr) {
+		if vBase, ok := expr.(tree.VarName); ok {
+			v, err := vBase.NormalizeVarName()
+			if err != nil {
+				return err, false, nil
+			}
+			if c, ok := v.(*tree.ColumnItem); ok && string(c.ColumnName) == colName {
+				found = true
+			}
+			return nil, false, v
+		}
+		return nil, true, expr
+	})
+	return found, err
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
 	"github.com/pkg/errors"
 )
 
@@ -161,11 +162,13 @@ func (t *truncateNode) startExec(params runParams) error {
 			EventLogTruncateTable,
 			int32(id),
 			int32(p.extendedEvalCtx.NodeID),
-			struct {
-				TableName string
-				Statement string
-				User      string
-			}{name, n.String(), p.SessionData().User},
+			&eventpb.TruncateTable{
+				TableName: name,
+				CommonSQLEventDetails: eventpb.CommonSQLEventDetails{
+					Statement: n.String(),
+					User:      p.SessionData().User,
+				},
+			},
 		); err != nil {
 			return err
 		}
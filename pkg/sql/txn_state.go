@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/util/contextutil"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -176,6 +177,13 @@ func (ts *txnState) resetForNewSQLTxn(
 			opentracing.ChildOf(parentSp.Context()), tracing.Recordable,
 			tracing.LogTagsFromCtx(connCtx),
 		)
+	} else if remoteCtx := tranCtx.clientSpanContext(); remoteCtx != nil {
+		// Create a child span of the client's trace for this SQL txn.
+		sp = tranCtx.tracer.StartSpan(
+			opName,
+			opentracing.ChildOf(remoteCtx), tracing.Recordable,
+			tracing.LogTagsFromCtx(connCtx),
+		)
 	} else {
 		// Create a root span for this SQL txn.
 		sp = tranCtx.tracer.(*tracing.Tracer).StartRootSpan(
@@ -419,7 +427,25 @@ type transitionCtx struct {
 	// sessionTracing provides access to the session's tracing interface. The
 	// state machine needs to see if session tracing is enabled.
 	sessionTracing *SessionTracing
-	settings       *cluster.Settings
+	// sessionData gives access to the session's variables. The state machine
+	// needs to see the trace context provided by the client, if any. It can be
+	// nil.
+	sessionData *sessiondata.SessionData
+	settings    *cluster.Settings
+}
+
+// clientSpanContext returns the span context of the trace provided by the
+// client through the traceparent session variable, or nil if there is none.
+func (tc *transitionCtx) clientSpanContext() opentracing.SpanContext {
+	if tc.sessionData == nil || tc.sessionData.TraceParent == "" {
+		return nil
+	}
+	// The session variable is validated when it is set.
+	sc, err := tc.tracer.(*tracing.Tracer).SpanContextFromTraceParent(tc.sessionData.TraceParent)
+	if err != nil {
+		return nil
+	}
+	return sc
 }
 
 var noRewind = rewindCapability{}
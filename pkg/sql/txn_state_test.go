@@ -25,6 +25,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	// We dot-import fsm to use common names such as fsm.True/False.
 	. "github.com/cockroachdb/cockroach/pkg/util/fsm"
@@ -812,3 +813,46 @@ func TestTransitions(t *testing.T) {
 		})
 	}
 }
+
+// TestTxnSpanClientTraceParent checks that the span of a txn is part of the
+// trace provided by the client through the traceparent session variable.
+func TestTxnSpanClientTraceParent(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testCon := makeTestContext()
+	tranCtx := transitionCtx{
+		db:             testCon.mockDB,
+		nodeID:         roachpb.NodeID(5),
+		clock:          testCon.clock,
+		tracer:         tracing.NewTracer(),
+		connMon:        &testCon.mon,
+		sessionTracing: &SessionTracing{},
+		sessionData: &sessiondata.SessionData{
+			TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		},
+		settings: testCon.settings,
+	}
+
+	_, ts := testCon.createNoTxnState()
+	ts.resetForNewSQLTxn(
+		testCon.ctx,
+		explicitTxn,
+		timeutil.Now(),
+		nil, /* historicalTimestamp */
+		roachpb.NormalUserPriority,
+		tree.ReadWrite,
+		nil, /* txn */
+		tranCtx,
+	)
+	tracing.StartRecording(ts.sp, tracing.SingleNodeRecording)
+	rec := tracing.GetRecording(ts.sp)
+	ts.finishSQLTxn()
+
+	if len(rec) != 1 {
+		t.Fatalf("expected a single span, got %v", rec)
+	}
+	if rec[0].TraceID != 0xa3ce929d0e0e4736 || rec[0].ParentSpanID != 0x00f067aa0ba902b7 {
+		t.Errorf("expected the txn span to be a child of the client span, got trace %x parent %x",
+			rec[0].TraceID, rec[0].ParentSpanID)
+	}
+}
@@ -145,6 +145,9 @@ func (p *planner) Update(
 	if err := checkHasNoComputedCols(updateCols); err != nil {
 		return nil, err
 	}
+	if err := checkIdentityColsForUpdate(updateCols, setExprs); err != nil {
+		return nil, err
+	}
 
 	// Extract the pre-analyzed, pre-typed default expressions for all
 	// the updated columns. There are as many defaultExprs as there are
@@ -418,6 +421,9 @@ type updateRun struct {
 	checkHelper *sqlbase.CheckHelper
 	rowsNeeded  bool
 
+	// triggers are the triggers fired for the updated rows, if any.
+	triggers *rowTriggers
+
 	// rowCount is the number of rows in the current batch.
 	rowCount int
 
@@ -501,6 +507,18 @@ func (u *updateNode) startExec(params runParams) error {
 			params.EvalContext().Mon.MakeBoundAccount(),
 			sqlbase.ColTypeInfoFromResCols(u.columns), 0)
 	}
+
+	var err error
+	u.run.triggers, err = makeRowTriggers(u.run.tu.tableDesc(), sqlbase.TriggerDescriptor_UPDATE)
+	if err != nil {
+		return err
+	}
+	if u.run.triggers != nil {
+		if err := u.run.triggers.fireBeforeStatement(params); err != nil {
+			return err
+		}
+	}
+
 	return u.run.tu.init(params.p.txn, params.EvalContext())
 }
 
@@ -549,8 +567,6 @@ func (u *updateNode) BatchedNext(params runParams) (bool, error) {
 			return false, err
 		}
 
-		u.run.rowCount++
-
 		// Are we done yet with the current batch?
 		if u.run.tu.curBatchSize() >= maxUpdateBatchSize {
 			break
@@ -579,6 +595,19 @@ func (u *updateNode) BatchedNext(params runParams) (bool, error) {
 		u.run.done = true
 	}
 
+	// Now that the rows of the batch have been written, fire the AFTER
+	// triggers, and the statement-level ones after the last batch.
+	if u.run.triggers != nil {
+		if err := u.run.triggers.fireAfter(params); err != nil {
+			return false, err
+		}
+		if u.run.done {
+			if err := u.run.triggers.fireAfterStatement(params); err != nil {
+				return false, err
+			}
+		}
+	}
+
 	// Possibly initiate a run of CREATE STATISTICS.
 	params.ExecCfg().StatsRefresher.NotifyMutation(
 		u.run.tu.tableDesc().ID,
@@ -589,7 +618,8 @@ func (u *updateNode) BatchedNext(params runParams) (bool, error) {
 }
 
 // processSourceRow processes one row from the source for update and, if
-// result rows are needed, saves it in the result row container.
+// result rows are needed, saves it in the result row container. The row is
+// skipped if a BEFORE trigger returns NULL.
 func (u *updateNode) processSourceRow(params runParams, sourceVals tree.Datums) error {
 	// sourceVals contains values for the columns from the table, in the order of the
 	// table descriptor. (One per column in u.tw.ru.FetchCols)
@@ -654,6 +684,20 @@ func (u *updateNode) processSourceRow(params runParams, sourceVals tree.Datums)
 		params.EvalContext().PopIVarContainer()
 	}
 
+	// Fire the BEFORE triggers, which can modify the updated columns or skip
+	// the row.
+	var oldRow tree.Datums
+	if u.run.triggers != nil {
+		oldRow = u.run.triggers.makeRow(u.run.tu.ru.FetchColIDtoRowIndex, oldValues)
+		newRow := append(tree.Datums(nil), oldRow...)
+		u.run.triggers.overlayRow(newRow, u.run.updateColsIdx, u.run.updateValues)
+		if ok, err := u.run.triggers.fireBefore(
+			params, triggerRows{old: oldRow, new: newRow}, u.run.updateColsIdx, u.run.updateValues,
+		); err != nil || !ok {
+			return err
+		}
+	}
+
 	// Verify the schema constraints. For consistency with INSERT/UPSERT
 	// and compatibility with PostgreSQL, we must do this before
 	// processing the CHECK constraints.
@@ -692,6 +736,14 @@ func (u *updateNode) processSourceRow(params runParams, sourceVals tree.Datums)
 	if err != nil {
 		return err
 	}
+	u.run.rowCount++
+
+	if u.run.triggers != nil {
+		u.run.triggers.queueAfter(triggerRows{
+			old: oldRow,
+			new: u.run.triggers.makeRow(u.run.tu.ru.FetchColIDtoRowIndex, newValues),
+		})
+	}
 
 	// If result rows need to be accumulated, do it.
 	if u.run.rows != nil {
@@ -730,7 +782,11 @@ func (u *updateNode) Close(ctx context.Context) {
 
 // enableAutoCommit implements the autoCommitNode interface.
 func (u *updateNode) enableAutoCommit() {
-	u.run.tu.enableAutoCommit()
+	// The AFTER triggers are fired after the last batch has been written, so
+	// the transaction cannot be committed along with it.
+	if !u.run.tu.tableDesc().HasTrigger(sqlbase.TriggerDescriptor_UPDATE) {
+		u.run.tu.enableAutoCommit()
+	}
 }
 
 // sourceSlot abstracts the idea that our update sources can either be tuples
@@ -908,6 +964,30 @@ func checkHasNoComputedCols(cols []sqlbase.ColumnDescriptor) error {
 	return nil
 }
 
+// checkIdentityColsForUpdate ensures that the GENERATED ALWAYS AS IDENTITY
+// columns among cols, the columns assigned by the given SET expressions, are
+// only updated to DEFAULT.
+func checkIdentityColsForUpdate(cols []sqlbase.ColumnDescriptor, exprs tree.UpdateExprs) error {
+	i := 0
+	for _, expr := range exprs {
+		for j := range expr.Names {
+			col := &cols[i]
+			i++
+			if !col.IsGeneratedAlwaysAsIdentity() {
+				continue
+			}
+			e := expr.Expr
+			if t, ok := e.(*tree.Tuple); ok && expr.Tuple {
+				e = t.Exprs[j]
+			}
+			if _, ok := e.(tree.DefaultVal); !ok {
+				return sqlbase.NewGeneratedAlwaysAsIdentityColumnUpdateError(col.Name)
+			}
+		}
+	}
+	return nil
+}
+
 // enforceLocalColumnConstraints asserts the column constraints that
 // do not require data validation from other sources than the row data
 // itself. This includes:
@@ -155,6 +155,11 @@ func (p *planner) newUpsertNode(
 		if err := checkHasNoComputedCols(updateCols); err != nil {
 			return nil, err
 		}
+		if !autoGenUpdates {
+			if err := checkIdentityColsForUpdate(updateCols, updateExprs); err != nil {
+				return nil, err
+			}
+		}
 
 		// We also need to include any computed columns in the set of UpdateCols.
 		// They can't have been set explicitly so there's no chance of
@@ -178,28 +183,7 @@ func (p *planner) newUpsertNode(
 		}
 
 		// Determine whether to use the fast path or the slow path.
-		// TODO(dan): The fast path is currently only enabled when the UPSERT alias
-		// is explicitly selected by the user. It's possible to fast path some
-		// queries of the form INSERT ... ON CONFLICT, but the utility is low and
-		// there are lots of edge cases (that caused real correctness bugs #13437
-		// #13962). As a result, we've decided to remove this until after 1.0 and
-		// re-enable it then. See #14482.
-		enableFastPath := n.OnConflict.IsUpsertAlias() &&
-			// Tables with secondary indexes are not eligible for fast path (it
-			// would be easy to add the new secondary index entry but we can't clean
-			// up the old one without the previous values).
-			len(desc.Indexes) == 0 &&
-			// When adding or removing a column in a schema change (mutation), the user
-			// can't specify it, which means we need to do a lookup and so we can't use
-			// the fast path. When adding or removing an index, same result, so the fast
-			// path is disabled during all mutations.
-			len(desc.MutationColumns()) == 0 &&
-			len(desc.MutationIndexes()) == 0 &&
-			// For the fast path, all columns must be specified in the insert.
-			len(ri.InsertCols) == len(desc.Columns) &&
-			// We cannot use the fast path if we also have a RETURNING clause, because
-			// RETURNING wants to see only the updated rows.
-			!needRows
+		enableFastPath := canUseFastUpsert(n.OnConflict, desc, conflictIndex, ri.InsertCols, needRows)
 
 		if enableFastPath {
 			// We then use the super-simple, super-fast writer. There's not
@@ -229,6 +213,79 @@ func (p *planner) newUpsertNode(
 	return un, nil
 }
 
+// canUseFastUpsert returns true if the upsert can be performed using blind
+// KV Puts, without first reading the existing rows. This is possible when:
+//
+//   1. The statement is the UPSERT short form, or an INSERT ... ON CONFLICT
+//      on the primary key whose DO UPDATE SET clause assigns `c = excluded.c`
+//      to every inserted non-key column and has no WHERE clause. Both forms
+//      overwrite conflicting rows with the inserted values.
+//   2. There are no secondary indexes. It would be easy to add the new
+//      secondary index entry, but we can't clean up the old one without the
+//      previous values.
+//   3. No schema change is in progress. When adding or removing a column in a
+//      schema change (mutation), the user can't specify it, which means we
+//      need to do a lookup. When adding or removing an index, same result.
+//   4. All columns are specified in the insert.
+//   5. There is no RETURNING clause, because RETURNING wants to see only the
+//      updated rows.
+//
+// Foreign keys do not prevent the fast path: the primary key of an overwritten
+// row is unchanged so no inbound reference can be affected, and the outbound
+// existence checks are performed by the inserter like for a plain INSERT.
+//
+// TODO(dan): INSERT ... ON CONFLICT statements of other shapes could also be
+// fast pathed, but the utility is low and there are lots of edge cases (that
+// caused real correctness bugs #13437 #13962). See #14482.
+func canUseFastUpsert(
+	onConflict *tree.OnConflict,
+	desc *sqlbase.ImmutableTableDescriptor,
+	conflictIndex *sqlbase.IndexDescriptor,
+	insertCols []sqlbase.ColumnDescriptor,
+	needRows bool,
+) bool {
+	if needRows ||
+		len(desc.Indexes) != 0 ||
+		len(desc.MutationColumns()) != 0 ||
+		len(desc.MutationIndexes()) != 0 ||
+		len(insertCols) != len(desc.Columns) {
+		return false
+	}
+	if onConflict.IsUpsertAlias() {
+		return true
+	}
+	if conflictIndex == nil || conflictIndex.ID != desc.PrimaryIndex.ID {
+		return false
+	}
+	assigned, ok := onConflict.ExcludedPassthroughCols()
+	if !ok {
+		return false
+	}
+	// Every column which is not part of the primary key and not computed must
+	// be assigned its inserted value, otherwise the blind Put would clobber the
+	// existing value of a column that the statement does not update.
+	assignedSet := make(map[tree.Name]struct{}, len(assigned))
+	for _, name := range assigned {
+		assignedSet[name] = struct{}{}
+	}
+	pkCols := make(map[sqlbase.ColumnID]struct{}, len(desc.PrimaryIndex.ColumnIDs))
+	for _, colID := range desc.PrimaryIndex.ColumnIDs {
+		pkCols[colID] = struct{}{}
+	}
+	numNonKey := 0
+	for i := range insertCols {
+		col := &insertCols[i]
+		if _, isKey := pkCols[col.ID]; isKey || col.IsComputed() {
+			continue
+		}
+		if _, ok := assignedSet[tree.Name(col.Name)]; !ok {
+			return false
+		}
+		numNonKey++
+	}
+	return numNonKey == len(assigned)
+}
+
 // upsertRun contains the run-time state of upsertNode during local execution.
 type upsertRun struct {
 	// In contrast with the run part of insert/delete/update, the
@@ -268,6 +325,12 @@ func (n *upsertNode) startExec(params runParams) error {
 		return err
 	}
 
+	if len(n.run.tw.tableDesc().Triggers) > 0 {
+		return pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
+			"UPSERT and INSERT ... ON CONFLICT are not supported on table %q, which has triggers",
+			n.run.tw.tableDesc().Name)
+	}
+
 	// cache traceKV during execution, to avoid re-evaluating it for every row.
 	n.run.traceKV = params.p.ExtendedEvalContext().Tracing.KVTracingEnabled()
 
@@ -0,0 +1,265 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/log/eventpb"
+	"github.com/pkg/errors"
+)
+
+var passwordMinLength = settings.RegisterNonNegativeIntSetting(
+	"server.user_login.password_min_length",
+	"the minimum number of characters of the passwords set with CREATE USER or ALTER USER",
+	1,
+)
+
+var passwordComplexityEnabled = settings.RegisterBoolSetting(
+	"server.user_login.password_complexity.enabled",
+	"if set, passwords must contain lowercase and uppercase letters, digits and symbols, "+
+		"and must not contain the user name",
+	false,
+)
+
+var loginLockoutThreshold = settings.RegisterNonNegativeIntSetting(
+	"server.user_login.lockout.threshold",
+	"the number of consecutive failed password logins after which further logins "+
+		"by the same user are delayed (0 to disable); root is never locked out",
+	0,
+)
+
+var loginLockoutBaseDelay = settings.RegisterNonNegativeDurationSetting(
+	"server.user_login.lockout.base_delay",
+	"the delay imposed on password logins once server.user_login.lockout.threshold is reached; "+
+		"it is doubled with every further failed attempt",
+	time.Second,
+)
+
+var loginLockoutMaxDelay = settings.RegisterNonNegativeDurationSetting(
+	"server.user_login.lockout.max_delay",
+	"the maximum delay imposed on password logins after failed attempts",
+	time.Hour,
+)
+
+// validatePassword checks that the given password conforms to the password
+// rules configured in the cluster settings.
+func validatePassword(sv *settings.Values, username, password string) error {
+	if minLength := passwordMinLength.Get(sv); int64(utf8.RuneCountInString(password)) < minLength {
+		return pgerror.NewErrorf(pgerror.CodeInvalidParameterValueError,
+			"password must be at least %d characters long", minLength)
+	}
+	if !passwordComplexityEnabled.Get(sv) {
+		return nil
+	}
+	var hasLower, hasUpper, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	if !(hasLower && hasUpper && hasDigit && hasSymbol) {
+		return pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"password must contain lowercase and uppercase letters, digits and symbols")
+	}
+	if strings.Contains(strings.ToLower(password), username) {
+		return pgerror.NewError(pgerror.CodeInvalidParameterValueError,
+			"password must not contain the user name")
+	}
+	return nil
+}
+
+// resolveValidUntil evaluates the expiration of a password given with VALID
+// UNTIL. It returns DNull for 'infinity', i.e. if the password never expires.
+func resolveValidUntil(validUntil func() (string, error)) (tree.Datum, error) {
+	s, err := validUntil()
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(s, "infinity") {
+		return tree.DNull, nil
+	}
+	ts, err := tree.ParseDTimestamp(nil, s, time.Microsecond)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgerror.CodeInvalidDatetimeFormatError,
+			"invalid VALID UNTIL value")
+	}
+	return ts, nil
+}
+
+// setPasswordValidUntil records the expiration of the password of a user in
+// system.user_auth_state.
+func setPasswordValidUntil(
+	ctx context.Context, ie *InternalExecutor, txn *client.Txn, username string, validUntil tree.Datum,
+) error {
+	_, err := ie.Exec(
+		ctx, "set-password-valid-until", txn,
+		`INSERT INTO system.user_auth_state (username, valid_until) VALUES ($1, $2) `+
+			`ON CONFLICT (username) DO UPDATE SET valid_until = excluded.valid_until`,
+		username, validUntil,
+	)
+	return err
+}
+
+// lockoutDelay returns the delay imposed after the given number of
+// consecutive failed login attempts, or 0 if logins are not delayed.
+func lockoutDelay(sv *settings.Values, failedAttempts int64) time.Duration {
+	threshold := loginLockoutThreshold.Get(sv)
+	if threshold == 0 || failedAttempts < threshold {
+		return 0
+	}
+	delay, maxDelay := loginLockoutBaseDelay.Get(sv), loginLockoutMaxDelay.Get(sv)
+	for i := threshold; i < failedAttempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
+
+// CheckPasswordLogin enforces the password policies around a password login
+// of the given user: logins are rejected while the user is locked out after
+// too many failed attempts, and with an expired password. checkPassword is
+// called to verify the password; the failures it reports are recorded in
+// system.user_auth_state, and lock out the user once
+// server.user_login.lockout.threshold is reached.
+func CheckPasswordLogin(
+	ctx context.Context, execCfg *ExecutorConfig, username string, checkPassword func() error,
+) error {
+	ie := execCfg.InternalExecutor
+	now := execCfg.Clock.PhysicalTime()
+
+	row, err := ie.QueryRow(
+		ctx, "get-user-auth-state", nil, /* txn */
+		`SELECT valid_until, failed_attempts, locked_until FROM system.user_auth_state WHERE username = $1`,
+		username,
+	)
+	if err != nil {
+		return pgerror.Wrapf(err, pgerror.CodeDataExceptionError,
+			"error looking up user %s", username)
+	}
+	var failedAttempts int64
+	var validUntil, lockedUntil *tree.DTimestamp
+	if row != nil {
+		validUntil, _ = row[0].(*tree.DTimestamp)
+		failedAttempts = int64(tree.MustBeDInt(row[1]))
+		lockedUntil, _ = row[2].(*tree.DTimestamp)
+	}
+
+	if lockedUntil != nil && now.Before(lockedUntil.Time) {
+		return pgerror.NewErrorf(pgerror.CodeInvalidAuthorizationSpecificationError,
+			"too many failed login attempts for user %s; try again later", username)
+	}
+
+	if err := checkPassword(); err != nil {
+		if loginLockoutThreshold.Get(&execCfg.Settings.SV) == 0 {
+			return err
+		}
+		if recordErr := recordFailedLogin(ctx, execCfg, username, now); recordErr != nil {
+			return errors.Wrapf(recordErr, "error recording failed login (%v)", err)
+		}
+		return err
+	}
+
+	if failedAttempts > 0 || lockedUntil != nil {
+		if _, err := ie.Exec(
+			ctx, "reset-failed-logins", nil, /* txn */
+			`UPDATE system.user_auth_state SET failed_attempts = 0, locked_until = NULL WHERE username = $1`,
+			username,
+		); err != nil {
+			return err
+		}
+	}
+
+	// The expiration is checked after the password so as not to reveal
+	// anything about the account to clients which do not know it.
+	if validUntil != nil && !now.Before(validUntil.Time) {
+		return errors.Errorf(security.ErrPasswordUserAuthFailed, username)
+	}
+	return nil
+}
+
+// recordFailedLogin counts a failed login attempt for the given user, and
+// locks the user out if this brings the number of consecutive failures to
+// the threshold. Nothing is recorded for names that are not those of
+// existing users, so that clients cannot fill system.user_auth_state by
+// trying to log in with arbitrary names. Nothing is recorded for root
+// either, so that clients cannot lock the administrator out of the cluster.
+func recordFailedLogin(
+	ctx context.Context, execCfg *ExecutorConfig, username string, now time.Time,
+) error {
+	if username == security.RootUser {
+		return nil
+	}
+	return execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		row, err := execCfg.InternalExecutor.QueryRow(
+			ctx, "record-failed-login", txn,
+			`INSERT INTO system.user_auth_state (username, failed_attempts) `+
+				`SELECT username, 1 FROM system.users WHERE username = $1 AND NOT "isRole" `+
+				`ON CONFLICT (username) DO UPDATE SET failed_attempts = user_auth_state.failed_attempts + 1 `+
+				`RETURNING failed_attempts`,
+			username,
+		)
+		if err != nil {
+			return err
+		}
+		if row == nil {
+			// Not a user.
+			return nil
+		}
+		failedAttempts := int64(tree.MustBeDInt(row[0]))
+		delay := lockoutDelay(&execCfg.Settings.SV, failedAttempts)
+		if delay == 0 {
+			return nil
+		}
+		lockedUntil := tree.MakeDTimestamp(now.Add(delay), time.Microsecond)
+		if _, err := execCfg.InternalExecutor.Exec(
+			ctx, "lock-out-user", txn,
+			`UPDATE system.user_auth_state SET locked_until = $2 WHERE username = $1`,
+			username, lockedUntil,
+		); err != nil {
+			return err
+		}
+		return MakeEventLogger(execCfg).InsertEventRecord(
+			ctx,
+			txn,
+			EventLogUserLoginLocked,
+			0, /* targetID */
+			int32(execCfg.NodeID.Get()),
+			&eventpb.UserLoginLocked{
+				User:           username,
+				FailedAttempts: failedAttempts,
+				LockedUntil:    lockedUntil.Time.Format(tree.TimestampOutputFormat),
+			},
+		)
+	})
+}
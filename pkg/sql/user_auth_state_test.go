@@ -0,0 +1,115 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+)
+
+func TestValidatePassword(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	passwordMinLength.Override(&st.SV, 6)
+
+	testCases := []struct {
+		complexity bool
+		password   string
+		err        string
+	}{
+		{false, "abc", "password must be at least 6 characters long"},
+		// The length is counted in characters, not bytes.
+		{false, "蟑螂蟑螂蟑", "password must be at least 6 characters long"},
+		{false, "蟑螂蟑螂蟑螂", ""},
+		{false, "abcdef", ""},
+		{true, "abcdef", "password must contain lowercase and uppercase letters, digits and symbols"},
+		{true, "Abcde1", "password must contain lowercase and uppercase letters, digits and symbols"},
+		{true, "Abcd1!", ""},
+		{true, "Carl1-pass", "password must not contain the user name"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.password, func(t *testing.T) {
+			passwordComplexityEnabled.Override(&st.SV, tc.complexity)
+			err := validatePassword(&st.SV, "carl1", tc.password)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestLockoutDelay(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	st := cluster.MakeTestingClusterSettings()
+	if d := lockoutDelay(&st.SV, 100); d != 0 {
+		t.Fatalf("expected no delay when the lockout is disabled, got %s", d)
+	}
+
+	loginLockoutThreshold.Override(&st.SV, 3)
+	loginLockoutBaseDelay.Override(&st.SV, time.Second)
+	loginLockoutMaxDelay.Override(&st.SV, 10*time.Second)
+	testCases := []struct {
+		failedAttempts int64
+		expected       time.Duration
+	}{
+		{0, 0},
+		{2, 0},
+		{3, time.Second},
+		{4, 2 * time.Second},
+		{6, 8 * time.Second},
+		{7, 10 * time.Second},
+		{1000, 10 * time.Second},
+	}
+	for _, tc := range testCases {
+		if d := lockoutDelay(&st.SV, tc.failedAttempts); d != tc.expected {
+			t.Errorf("%d failed attempts: expected %s, got %s", tc.failedAttempts, tc.expected, d)
+		}
+	}
+}
+
+// TestRecordFailedLogin verifies that failed logins are only recorded for
+// existing users other than root.
+func TestRecordFailedLogin(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	s, sqlDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	r := sqlutils.MakeSQLRunner(sqlDB)
+	r.Exec(t, `CREATE USER carl`)
+
+	execCfg := s.ExecutorConfig().(ExecutorConfig)
+	now := timeutil.Now()
+	for _, username := range []string{"carl", "nobody", "root", "carl"} {
+		if err := recordFailedLogin(ctx, &execCfg, username, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.CheckQueryResults(t,
+		`SELECT username, failed_attempts FROM system.user_auth_state ORDER BY username`,
+		[][]string{{"carl", "2"}},
+	)
+}
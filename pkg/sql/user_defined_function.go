@@ -0,0 +1,786 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/coltypes"
+	"github.com/cockroachdb/cockroach/pkg/sql/plpgsql"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// userDefinedFunction is a user-defined function, resolved for the planning of
+// a statement.
+type userDefinedFunction struct {
+	// def is the definition of the function which replaces its name in the
+	// calls, so that type checking selects one of its overloads.
+	def *tree.FunctionDefinition
+	// overloads are the routines of the overloads of def.
+	overloads []udfOverload
+}
+
+type udfOverload struct {
+	routine *plpgsql.Routine
+	// body is the expression which replaces the calls to the overload when
+	// they are inlined, or nil if the overload cannot be inlined.
+	body tree.Expr
+	// paramRefs is the number of references to each parameter in body.
+	paramRefs []int
+}
+
+// resolveUserDefinedFunction returns the user-defined function with the given
+// name, or nil if there is no such function.
+func (p *planner) resolveUserDefinedFunction(
+	ctx context.Context, name *tree.UnresolvedName,
+) (*userDefinedFunction, error) {
+	tn := name.ToTableName()
+	dbDesc, err := p.resolveRoutineDatabase(ctx, &tn)
+	if err != nil || dbDesc == nil {
+		return nil, err
+	}
+	var descs []*sqlbase.RoutineDescriptor
+	for i := range dbDesc.Routines {
+		desc := &dbDesc.Routines[i]
+		if desc.Name == tn.Table() && desc.Kind == sqlbase.RoutineDescriptor_FUNCTION {
+			descs = append(descs, desc)
+		}
+	}
+	if len(descs) == 0 {
+		return nil, nil
+	}
+	if err := p.CheckAnyPrivilege(ctx, dbDesc); err != nil {
+		return nil, err
+	}
+
+	fn := &userDefinedFunction{overloads: make([]udfOverload, len(descs))}
+	overloads := make([]tree.Overload, len(descs))
+	for i, desc := range descs {
+		routine, err := makeRoutine(desc)
+		if err != nil {
+			return nil, err
+		}
+		fn.overloads[i] = makeUDFOverload(routine, p.CurrentSearchPath())
+
+		argTypes := make(tree.ArgTypes, len(routine.Params))
+		for j, param := range routine.Params {
+			argTypes[j].Name = param.Name
+			if argTypes[j].Name == "" {
+				argTypes[j].Name = fmt.Sprintf("$%d", j+1)
+			}
+			argTypes[j].Typ = coltypes.CastTargetToDatumType(param.Type)
+		}
+		database := dbDesc.Name
+		overloads[i] = tree.Overload{
+			Types:      argTypes,
+			ReturnType: tree.FixedReturnType(coltypes.CastTargetToDatumType(routine.ReturnType)),
+			Fn: func(evalCtx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				ctx, err := enterRoutine(evalCtx.Ctx(), "function")
+				if err != nil {
+					return nil, err
+				}
+				ie := routineInternalExecutor(p, database)
+				return plpgsql.Exec(ctx, evalCtx, ie, evalCtx.Txn, routine, args)
+			},
+		}
+	}
+	fn.def = tree.NewUserDefinedFunctionDefinition(tn.Table(), &tree.FunctionProperties{
+		// As in postgres, functions are called with NULL arguments, and are
+		// volatile: they can read and modify the database.
+		NullableArgs: true,
+		Impure:       true,
+		// The body is executed with the internal executor of the session.
+		DistsqlBlacklist: true,
+	}, overloads)
+	return fn, nil
+}
+
+// makeUDFOverload returns the overload for a routine, which can be inlined
+// when its body is a SQL statement of the form SELECT <expr>, where <expr>
+// only refers to the parameters and to built-in scalar functions.
+func makeUDFOverload(routine *plpgsql.Routine, searchPath sessiondata.SearchPath) udfOverload {
+	o := udfOverload{routine: routine}
+	if len(routine.SQLBody) != 1 {
+		return o
+	}
+	sel, ok := routine.SQLBody[0].(*tree.Select)
+	if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil || sel.Locking != nil {
+		return o
+	}
+	clause, ok := sel.Select.(*tree.SelectClause)
+	if !ok || clause.Distinct || clause.DistinctOn != nil || len(clause.Exprs) != 1 ||
+		(clause.From != nil && (len(clause.From.Tables) > 0 || clause.From.AsOf.Expr != nil)) ||
+		clause.Where != nil || clause.GroupBy != nil || clause.Having != nil ||
+		clause.Window != nil || clause.TableSelect {
+		return o
+	}
+	v := inlineCheckVisitor{
+		routine: routine, searchPath: searchPath, paramRefs: make([]int, len(routine.Params)),
+	}
+	tree.WalkExprConst(&v, clause.Exprs[0].Expr)
+	if v.notInlinable {
+		return o
+	}
+	o.body, o.paramRefs = clause.Exprs[0].Expr, v.paramRefs
+	return o
+}
+
+// inlineCheckVisitor checks whether an expression in the body of a function
+// can be inlined, and counts the references to the parameters.
+type inlineCheckVisitor struct {
+	routine      *plpgsql.Routine
+	searchPath   sessiondata.SearchPath
+	paramRefs    []int
+	notInlinable bool
+}
+
+var _ tree.Visitor = &inlineCheckVisitor{}
+
+func (v *inlineCheckVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if v.notInlinable {
+		return false, expr
+	}
+	switch t := expr.(type) {
+	case *tree.UnresolvedName:
+		if i := udfParamIdx(v.routine, t); i >= 0 {
+			v.paramRefs[i]++
+			return false, expr
+		}
+		v.notInlinable = true
+	case *tree.Placeholder:
+		if int(t.Idx) < len(v.paramRefs) {
+			v.paramRefs[t.Idx]++
+			return false, expr
+		}
+		v.notInlinable = true
+	case *tree.FuncExpr:
+		// Aggregates and generators behave differently in a SELECT without a
+		// FROM clause, and user-defined functions are not inlined
+		// recursively.
+		name, ok := t.Func.FunctionReference.(*tree.UnresolvedName)
+		if !ok || t.WindowDef != nil || t.Filter != nil {
+			v.notInlinable = true
+			break
+		}
+		if def := name.ResolveBuiltinFunction(v.searchPath); def == nil || def.Class != tree.NormalClass {
+			v.notInlinable = true
+		}
+	case *tree.Subquery, tree.UnqualifiedStar, *tree.AllColumnsSelector, *tree.TupleStar,
+		*tree.ColumnItem, tree.DefaultVal:
+		v.notInlinable = true
+	}
+	return !v.notInlinable, expr
+}
+
+func (*inlineCheckVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// udfParamIdx returns the position of the parameter referenced by a name in
+// the body of a function, or -1 if the name is not a parameter.
+func udfParamIdx(routine *plpgsql.Routine, name *tree.UnresolvedName) int {
+	if name.NumParts != 1 || name.Star {
+		return -1
+	}
+	for i := range routine.Params {
+		if routine.Params[i].Name != "" && routine.Params[i].Name == name.Parts[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+// inline returns the expression which replaces a call to the function, or nil
+// if the call cannot be inlined. The call is inlined if only one overload
+// accepts its number of arguments, and if that overload can be inlined. The
+// arguments are annotated with the types of the parameters, so that they are
+// type checked as when overloads are resolved, and the body is cast to the
+// return type of the function.
+func (fn *userDefinedFunction) inline(call *tree.FuncExpr) tree.Expr {
+	if call.Type != 0 || call.Filter != nil || call.WindowDef != nil || call.OrderBy != nil {
+		return nil
+	}
+	var o *udfOverload
+	for i := range fn.overloads {
+		if len(fn.overloads[i].routine.Params) == len(call.Exprs) {
+			if o != nil {
+				return nil
+			}
+			o = &fn.overloads[i]
+		}
+	}
+	if o == nil || o.body == nil {
+		return nil
+	}
+	args := make(tree.Exprs, len(call.Exprs))
+	for i, arg := range call.Exprs {
+		// Arguments which are evaluated more than once, or not at all, must
+		// have no side effects nor errors.
+		if o.paramRefs[i] != 1 && !isSimpleUDFArg(arg) {
+			return nil
+		}
+		args[i] = &tree.ParenExpr{Expr: &tree.AnnotateTypeExpr{
+			Expr:       arg,
+			Type:       o.routine.Params[i].Type,
+			SyntaxMode: tree.AnnotateShort,
+		}}
+	}
+	body, _ := tree.WalkExpr(&udfParamReplacer{routine: o.routine, args: args}, o.body)
+	return &tree.ParenExpr{Expr: &tree.CastExpr{
+		Expr:       &tree.ParenExpr{Expr: body},
+		Type:       o.routine.ReturnType,
+		SyntaxMode: tree.CastShort,
+	}}
+}
+
+// isSimpleUDFArg returns whether the argument of a call can be evaluated any
+// number of times when the call is inlined.
+func isSimpleUDFArg(arg tree.Expr) bool {
+	switch t := arg.(type) {
+	case tree.Datum, *tree.NumVal, *tree.StrVal, *tree.Placeholder, *tree.ColumnItem,
+		*tree.IndexedVar:
+		return true
+	case *tree.UnresolvedName:
+		return !t.Star
+	}
+	return false
+}
+
+// udfParamReplacer replaces the references to the parameters in the body of
+// an inlined function with the arguments of the call.
+type udfParamReplacer struct {
+	routine *plpgsql.Routine
+	args    tree.Exprs
+}
+
+var _ tree.Visitor = &udfParamReplacer{}
+
+func (v *udfParamReplacer) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	switch t := expr.(type) {
+	case *tree.UnresolvedName:
+		if i := udfParamIdx(v.routine, t); i >= 0 {
+			return false, v.args[i]
+		}
+	case *tree.Placeholder:
+		return false, v.args[t.Idx]
+	}
+	return true, expr
+}
+
+func (*udfParamReplacer) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
+// resolveUserDefinedFunctions replaces the names of the user-defined functions
+// called by a statement with their definitions, or inlines the calls, and
+// returns the resulting statement and whether it calls user-defined functions.
+//
+// Type checking only resolves the names of built-in functions, so this is done
+// before the statement is planned. The statement is not modified: the nodes
+// which contain calls to user-defined functions are copied, since the
+// statement can be a prepared statement which is planned again later, when the
+// functions may have been replaced.
+func (p *planner) resolveUserDefinedFunctions(
+	ctx context.Context, stmt tree.Statement,
+) (_ tree.Statement, found bool, _ error) {
+	v := udfResolver{ctx: ctx, p: p}
+	stmt, _ = v.walkStmt(stmt)
+	if v.err != nil {
+		return nil, false, v.err
+	}
+	return stmt, v.found, nil
+}
+
+// udfResolver walks a statement to resolve the calls to user-defined
+// functions. Unlike tree.WalkExpr, it walks the statements in the FROM
+// clauses and in the WITH clauses.
+type udfResolver struct {
+	ctx context.Context
+	p   *planner
+	// funcs caches the user-defined functions resolved so far, by name. The
+	// names which are not the names of user-defined functions are mapped to
+	// nil.
+	funcs map[string]*userDefinedFunction
+	// noInline is set to only replace the names of the functions.
+	noInline bool
+	found    bool
+	inlined  int
+	err      error
+}
+
+var _ tree.Visitor = &udfResolver{}
+
+func (v *udfResolver) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if v.err != nil {
+		return false, expr
+	}
+	if sub, ok := expr.(*tree.Subquery); ok {
+		sel, changed := v.walkSelectStatement(sub.Select)
+		if !changed {
+			return false, expr
+		}
+		cpy := *sub
+		cpy.Select = sel
+		return false, &cpy
+	}
+	return true, expr
+}
+
+func (v *udfResolver) VisitPost(expr tree.Expr) tree.Expr {
+	f, ok := expr.(*tree.FuncExpr)
+	if !ok || v.err != nil {
+		return expr
+	}
+	name, ok := f.Func.FunctionReference.(*tree.UnresolvedName)
+	if !ok || name.NumParts > 3 || name.Star ||
+		name.ResolveBuiltinFunction(v.p.CurrentSearchPath()) != nil {
+		return expr
+	}
+	key := name.String()
+	fn, ok := v.funcs[key]
+	if !ok {
+		if fn, v.err = v.p.resolveUserDefinedFunction(v.ctx, name); v.err != nil {
+			return expr
+		}
+		if v.funcs == nil {
+			v.funcs = make(map[string]*userDefinedFunction)
+		}
+		v.funcs[key] = fn
+	}
+	if fn == nil {
+		// Type checking reports the unknown function.
+		return expr
+	}
+	v.found = true
+	if !v.noInline {
+		if inlined := fn.inline(f); inlined != nil {
+			v.inlined++
+			return inlined
+		}
+	}
+	cpy := *f
+	cpy.Func = tree.ResolvableFunctionReference{FunctionReference: fn.def}
+	return &cpy
+}
+
+func (v *udfResolver) walkExpr(expr tree.Expr) (tree.Expr, bool) {
+	if expr == nil || v.err != nil {
+		return expr, false
+	}
+	return tree.WalkExpr(v, expr)
+}
+
+func (v *udfResolver) walkExprs(exprs tree.Exprs) (tree.Exprs, bool) {
+	res := exprs
+	for i, expr := range exprs {
+		if e, changed := v.walkExpr(expr); changed {
+			if len(res) > 0 && &res[0] == &exprs[0] {
+				res = append(tree.Exprs(nil), exprs...)
+			}
+			res[i] = e
+		}
+	}
+	return res, len(res) > 0 && &res[0] != &exprs[0]
+}
+
+func (v *udfResolver) walkWhere(where *tree.Where) (*tree.Where, bool) {
+	if where == nil {
+		return nil, false
+	}
+	e, changed := v.walkExpr(where.Expr)
+	if !changed {
+		return where, false
+	}
+	return &tree.Where{Type: where.Type, Expr: e}, true
+}
+
+func (v *udfResolver) walkStmt(stmt tree.Statement) (tree.Statement, bool) {
+	switch t := stmt.(type) {
+	case *tree.Select:
+		return v.walkSelect(t)
+	case tree.SelectStatement:
+		return v.walkSelectStatement(t)
+	case *tree.Insert:
+		return v.walkInsert(t)
+	case *tree.Update:
+		return v.walkUpdate(t)
+	case *tree.Delete:
+		return v.walkDelete(t)
+	case *tree.Explain:
+		s, changed := v.walkStmt(t.Statement)
+		if !changed {
+			return stmt, false
+		}
+		cpy := *t
+		cpy.Statement = s
+		return &cpy, true
+	case *tree.Call:
+		args, changed := v.walkExprs(t.Args)
+		if !changed {
+			return stmt, false
+		}
+		cpy := *t
+		cpy.Args = args
+		return &cpy, true
+	}
+	return stmt, false
+}
+
+func (v *udfResolver) walkSelect(sel *tree.Select) (*tree.Select, bool) {
+	if sel == nil {
+		return nil, false
+	}
+	with, withChanged := v.walkWith(sel.With)
+	s, selChanged := v.walkSelectStatement(sel.Select)
+	orderBy, orderByChanged := v.walkOrderBy(sel.OrderBy)
+	limit, limitChanged := v.walkLimit(sel.Limit)
+	if !withChanged && !selChanged && !orderByChanged && !limitChanged {
+		return sel, false
+	}
+	cpy := *sel
+	cpy.With, cpy.Select, cpy.OrderBy, cpy.Limit = with, s, orderBy, limit
+	return &cpy, true
+}
+
+func (v *udfResolver) walkSelectStatement(stmt tree.SelectStatement) (tree.SelectStatement, bool) {
+	switch t := stmt.(type) {
+	case *tree.ParenSelect:
+		if sel, changed := v.walkSelect(t.Select); changed {
+			return &tree.ParenSelect{Select: sel}, true
+		}
+	case *tree.UnionClause:
+		left, leftChanged := v.walkSelect(t.Left)
+		right, rightChanged := v.walkSelect(t.Right)
+		if leftChanged || rightChanged {
+			cpy := *t
+			cpy.Left, cpy.Right = left, right
+			return &cpy, true
+		}
+	case *tree.ValuesClause:
+		rows := t.Rows
+		for i := range t.Rows {
+			if row, changed := v.walkExprs(t.Rows[i]); changed {
+				if &rows[0] == &t.Rows[0] {
+					rows = append([]tree.Exprs(nil), t.Rows...)
+				}
+				rows[i] = row
+			}
+		}
+		if len(rows) > 0 && &rows[0] != &t.Rows[0] {
+			return &tree.ValuesClause{Rows: rows}, true
+		}
+	case *tree.SelectClause:
+		return v.walkSelectClause(t)
+	}
+	return stmt, false
+}
+
+func (v *udfResolver) walkSelectClause(clause *tree.SelectClause) (*tree.SelectClause, bool) {
+	cpy := *clause
+	changed := false
+	if e, ok := v.walkExprs(tree.Exprs(clause.DistinctOn)); ok {
+		cpy.DistinctOn, changed = tree.DistinctOn(e), true
+	}
+	if e, ok := v.walkSelectExprs(clause.Exprs); ok {
+		cpy.Exprs, changed = e, true
+	}
+	if clause.From != nil {
+		tables, tablesChanged := v.walkTableExprs(clause.From.Tables)
+		if tablesChanged {
+			cpy.From, changed = &tree.From{Tables: tables, AsOf: clause.From.AsOf}, true
+		}
+	}
+	if w, ok := v.walkWhere(clause.Where); ok {
+		cpy.Where, changed = w, true
+	}
+	if e, ok := v.walkExprs(tree.Exprs(clause.GroupBy)); ok {
+		cpy.GroupBy, changed = tree.GroupBy(e), true
+	}
+	if w, ok := v.walkWhere(clause.Having); ok {
+		cpy.Having, changed = w, true
+	}
+	for i, def := range clause.Window {
+		partitions, partitionsChanged := v.walkExprs(def.Partitions)
+		orderBy, orderByChanged := v.walkOrderBy(def.OrderBy)
+		if partitionsChanged || orderByChanged {
+			if &cpy.Window[0] == &clause.Window[0] {
+				cpy.Window = append(tree.Window(nil), clause.Window...)
+			}
+			defCpy := *def
+			defCpy.Partitions, defCpy.OrderBy = partitions, orderBy
+			cpy.Window[i], changed = &defCpy, true
+		}
+	}
+	if !changed {
+		return clause, false
+	}
+	return &cpy, true
+}
+
+// walkSelectExprs walks the expressions of a SELECT or RETURNING clause. The
+// expressions containing inlined calls are given the names that their columns
+// would have without inlining.
+func (v *udfResolver) walkSelectExprs(exprs tree.SelectExprs) (tree.SelectExprs, bool) {
+	var res tree.SelectExprs
+	for i := range exprs {
+		inlined := v.inlined
+		e, changed := v.walkExpr(exprs[i].Expr)
+		if !changed {
+			continue
+		}
+		if res == nil {
+			res = append(tree.SelectExprs(nil), exprs...)
+		}
+		res[i].Expr = e
+		if v.inlined != inlined && exprs[i].As == "" {
+			v.noInline = true
+			resolved, _ := v.walkExpr(exprs[i].Expr)
+			v.noInline = false
+			name, err := tree.GetRenderColName(v.p.CurrentSearchPath(), tree.SelectExpr{Expr: resolved})
+			if err != nil {
+				v.err = err
+				return exprs, false
+			}
+			res[i].As = tree.UnrestrictedName(name)
+		}
+	}
+	if res == nil {
+		return exprs, false
+	}
+	return res, true
+}
+
+func (v *udfResolver) walkTableExprs(tables tree.TableExprs) (tree.TableExprs, bool) {
+	var res tree.TableExprs
+	for i, table := range tables {
+		if t, changed := v.walkTableExpr(table); changed {
+			if res == nil {
+				res = append(tree.TableExprs(nil), tables...)
+			}
+			res[i] = t
+		}
+	}
+	if res == nil {
+		return tables, false
+	}
+	return res, true
+}
+
+func (v *udfResolver) walkTableExpr(table tree.TableExpr) (tree.TableExpr, bool) {
+	switch t := table.(type) {
+	case *tree.AliasedTableExpr:
+		if e, changed := v.walkTableExpr(t.Expr); changed {
+			cpy := *t
+			cpy.Expr = e
+			return &cpy, true
+		}
+	case *tree.ParenTableExpr:
+		if e, changed := v.walkTableExpr(t.Expr); changed {
+			return &tree.ParenTableExpr{Expr: e}, true
+		}
+	case *tree.JoinTableExpr:
+		left, leftChanged := v.walkTableExpr(t.Left)
+		right, rightChanged := v.walkTableExpr(t.Right)
+		cond, condChanged := t.Cond, false
+		if on, ok := t.Cond.(*tree.OnJoinCond); ok {
+			var e tree.Expr
+			if e, condChanged = v.walkExpr(on.Expr); condChanged {
+				cond = &tree.OnJoinCond{Expr: e}
+			}
+		}
+		if leftChanged || rightChanged || condChanged {
+			cpy := *t
+			cpy.Left, cpy.Right, cpy.Cond = left, right, cond
+			return &cpy, true
+		}
+	case *tree.Subquery:
+		if e, changed := v.walkExpr(t); changed {
+			return e.(*tree.Subquery), true
+		}
+	case *tree.StatementSource:
+		if s, changed := v.walkStmt(t.Statement); changed {
+			return &tree.StatementSource{Statement: s}, true
+		}
+	case *tree.RowsFromExpr:
+		if items, changed := v.walkExprs(t.Items); changed {
+			return &tree.RowsFromExpr{Items: items}, true
+		}
+	}
+	return table, false
+}
+
+func (v *udfResolver) walkWith(with *tree.With) (*tree.With, bool) {
+	if with == nil {
+		return nil, false
+	}
+	var res *tree.With
+	for i, cte := range with.CTEList {
+		if s, changed := v.walkStmt(cte.Stmt); changed {
+			if res == nil {
+				res = &tree.With{
+					Recursive: with.Recursive,
+					CTEList:   append([]*tree.CTE(nil), with.CTEList...),
+				}
+			}
+			cteCpy := *cte
+			cteCpy.Stmt = s
+			res.CTEList[i] = &cteCpy
+		}
+	}
+	if res == nil {
+		return with, false
+	}
+	return res, true
+}
+
+func (v *udfResolver) walkOrderBy(orderBy tree.OrderBy) (tree.OrderBy, bool) {
+	var res tree.OrderBy
+	for i, order := range orderBy {
+		if e, changed := v.walkExpr(order.Expr); changed {
+			if res == nil {
+				res = append(tree.OrderBy(nil), orderBy...)
+			}
+			orderCpy := *order
+			orderCpy.Expr = e
+			res[i] = &orderCpy
+		}
+	}
+	if res == nil {
+		return orderBy, false
+	}
+	return res, true
+}
+
+func (v *udfResolver) walkLimit(limit *tree.Limit) (*tree.Limit, bool) {
+	if limit == nil {
+		return nil, false
+	}
+	count, countChanged := v.walkExpr(limit.Count)
+	offset, offsetChanged := v.walkExpr(limit.Offset)
+	if !countChanged && !offsetChanged {
+		return limit, false
+	}
+	cpy := *limit
+	cpy.Count, cpy.Offset = count, offset
+	return &cpy, true
+}
+
+func (v *udfResolver) walkReturning(returning tree.ReturningClause) (tree.ReturningClause, bool) {
+	exprs, ok := returning.(*tree.ReturningExprs)
+	if !ok {
+		return returning, false
+	}
+	res, changed := v.walkSelectExprs(tree.SelectExprs(*exprs))
+	if !changed {
+		return returning, false
+	}
+	ret := tree.ReturningExprs(res)
+	return &ret, true
+}
+
+func (v *udfResolver) walkUpdateExprs(exprs tree.UpdateExprs) (tree.UpdateExprs, bool) {
+	var res tree.UpdateExprs
+	for i, expr := range exprs {
+		if e, changed := v.walkExpr(expr.Expr); changed {
+			if res == nil {
+				res = append(tree.UpdateExprs(nil), exprs...)
+			}
+			exprCpy := *expr
+			exprCpy.Expr = e
+			res[i] = &exprCpy
+		}
+	}
+	if res == nil {
+		return exprs, false
+	}
+	return res, true
+}
+
+func (v *udfResolver) walkInsert(ins *tree.Insert) (*tree.Insert, bool) {
+	cpy := *ins
+	changed := false
+	if with, ok := v.walkWith(ins.With); ok {
+		cpy.With, changed = with, true
+	}
+	if rows, ok := v.walkSelect(ins.Rows); ok {
+		cpy.Rows, changed = rows, true
+	}
+	if ins.OnConflict != nil {
+		exprs, exprsChanged := v.walkUpdateExprs(ins.OnConflict.Exprs)
+		where, whereChanged := v.walkWhere(ins.OnConflict.Where)
+		if exprsChanged || whereChanged {
+			onConflict := *ins.OnConflict
+			onConflict.Exprs, onConflict.Where = exprs, where
+			cpy.OnConflict, changed = &onConflict, true
+		}
+	}
+	if returning, ok := v.walkReturning(ins.Returning); ok {
+		cpy.Returning, changed = returning, true
+	}
+	if !changed {
+		return ins, false
+	}
+	return &cpy, true
+}
+
+func (v *udfResolver) walkUpdate(upd *tree.Update) (*tree.Update, bool) {
+	cpy := *upd
+	changed := false
+	if with, ok := v.walkWith(upd.With); ok {
+		cpy.With, changed = with, true
+	}
+	if exprs, ok := v.walkUpdateExprs(upd.Exprs); ok {
+		cpy.Exprs, changed = exprs, true
+	}
+	if where, ok := v.walkWhere(upd.Where); ok {
+		cpy.Where, changed = where, true
+	}
+	if orderBy, ok := v.walkOrderBy(upd.OrderBy); ok {
+		cpy.OrderBy, changed = orderBy, true
+	}
+	if limit, ok := v.walkLimit(upd.Limit); ok {
+		cpy.Limit, changed = limit, true
+	}
+	if returning, ok := v.walkReturning(upd.Returning); ok {
+		cpy.Returning, changed = returning, true
+	}
+	if !changed {
+		return upd, false
+	}
+	return &cpy, true
+}
+
+func (v *udfResolver) walkDelete(del *tree.Delete) (*tree.Delete, bool) {
+	cpy := *del
+	changed := false
+	if with, ok := v.walkWith(del.With); ok {
+		cpy.With, changed = with, true
+	}
+	if where, ok := v.walkWhere(del.Where); ok {
+		cpy.Where, changed = where, true
+	}
+	if orderBy, ok := v.walkOrderBy(del.OrderBy); ok {
+		cpy.OrderBy, changed = orderBy, true
+	}
+	if limit, ok := v.walkLimit(del.Limit); ok {
+		cpy.Limit, changed = limit, true
+	}
+	if returning, ok := v.walkReturning(del.Returning); ok {
+		cpy.Returning, changed = returning, true
+	}
+	if !changed {
+		return del, false
+	}
+	return &cpy, true
+}
@@ -308,6 +308,23 @@ var varGen = map[string]sessionVar{
 		},
 	},
 
+	// CockroachDB extension.
+	`enforce_gateway_region`: {
+		GetStringVal: makeBoolGetStringValFn(`enforce_gateway_region`),
+		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
+			b, err := parsePostgresBool(s)
+			if err != nil {
+				return err
+			}
+			m.SetEnforceGatewayRegion(b)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return formatBoolAsPostgresSetting(evalCtx.SessionData.EnforceGatewayRegion)
+		},
+		GlobalDefault: globalFalse,
+	},
+
 	// CockroachDB extension.
 	`experimental_force_split_at`: {
 		GetStringVal: makeBoolGetStringValFn(`experimental_force_split_at`),
@@ -646,6 +663,27 @@ var varGen = map[string]sessionVar{
 		GlobalDefault: func(_ *settings.Values) string { return "UTC" },
 	},
 
+	// CockroachDB extension. The W3C Trace Context of the client, which links
+	// the traces of the session's transactions to the client's trace.
+	// See https://www.w3.org/TR/trace-context/#traceparent-header
+	`traceparent`: {
+		Set: func(
+			_ context.Context, m *sessionDataMutator, s string,
+		) error {
+			if s != "" {
+				if _, err := tracing.ParseTraceParent(s); err != nil {
+					return wrapSetVarError("traceparent", s, "%v", err)
+				}
+			}
+			m.SetTraceParent(s)
+			return nil
+		},
+		Get: func(evalCtx *extendedEvalContext) string {
+			return evalCtx.SessionData.TraceParent
+		},
+		GlobalDefault: func(_ *settings.Values) string { return "" },
+	},
+
 	// This is not directly documented in PG's docs but does indeed behave this way.
 	// See https://github.com/postgres/postgres/blob/REL_10_STABLE/src/backend/utils/misc/guc.c#L3401-L3409
 	`transaction_isolation`: {
@@ -205,6 +205,15 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 			if n.hardLimit > 0 && isFilterTrue(n.filter) {
 				v.observer.attr(name, "limit", fmt.Sprintf("%d", n.hardLimit))
 			}
+			if n.lockingStrength != tree.ForNone {
+				v.observer.attr(name, "locking strength", n.lockingStrength.String())
+			}
+			if n.lockingWaitPolicy != tree.LockWaitBlock {
+				v.observer.attr(name, "locking wait policy", n.lockingWaitPolicy.String())
+			}
+			if n.sample != nil {
+				v.observer.attr(name, "sample", fmt.Sprintf("%s (%g%%)", n.sample.Method, n.sample.Percent))
+			}
 		}
 		if v.observer.expr != nil {
 			v.expr(name, "filter", -1, n.filter)
@@ -316,6 +325,9 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 			v.expr(name, "count", -1, n.countExpr)
 			v.expr(name, "offset", -1, n.offsetExpr)
 		}
+		if v.observer.attr != nil && n.withTies {
+			v.observer.attr(name, "with ties", "")
+		}
 		n.plan = v.visit(n.plan)
 
 	case *max1RowNode:
@@ -420,6 +432,12 @@ func (v *planVisitor) visitInternal(plan planNode, name string) {
 		n.left = v.visit(n.left)
 		n.right = v.visit(n.right)
 
+	case *recursiveCTENode:
+		if v.observer.attr != nil {
+			v.observer.attr(name, "name", n.alias.Alias.String())
+		}
+		n.initial = v.visit(n.initial)
+
 	case *splitNode:
 		n.rows = v.visit(n.rows)
 
@@ -722,13 +740,19 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&commentOnDatabaseNode{}):    "comment on database",
 	reflect.TypeOf(&commentOnTableNode{}):       "comment on table",
 	reflect.TypeOf(&cancelQueriesNode{}):        "cancel queries",
+	reflect.TypeOf(&callNode{}):                 "call",
 	reflect.TypeOf(&cancelSessionsNode{}):       "cancel sessions",
 	reflect.TypeOf(&controlJobsNode{}):          "control jobs",
+	reflect.TypeOf(&controlScheduleNode{}):      "control schedule",
 	reflect.TypeOf(&createDatabaseNode{}):       "create database",
+	reflect.TypeOf(&createFunctionNode{}):       "create function",
 	reflect.TypeOf(&createIndexNode{}):          "create index",
+	reflect.TypeOf(&createProcedureNode{}):      "create procedure",
+	reflect.TypeOf(&createScheduleNode{}):       "create schedule",
 	reflect.TypeOf(&createSequenceNode{}):       "create sequence",
 	reflect.TypeOf(&createStatsNode{}):          "create statistics",
 	reflect.TypeOf(&createTableNode{}):          "create table",
+	reflect.TypeOf(&createTriggerNode{}):        "create trigger",
 	reflect.TypeOf(&CreateUserNode{}):           "create user/role",
 	reflect.TypeOf(&createViewNode{}):           "create view",
 	reflect.TypeOf(&delayedNode{}):              "virtual table",
@@ -737,8 +761,11 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&distinctNode{}):             "distinct",
 	reflect.TypeOf(&dropDatabaseNode{}):         "drop database",
 	reflect.TypeOf(&dropIndexNode{}):            "drop index",
+	reflect.TypeOf(&dropProcedureNode{}):        "drop procedure",
+	reflect.TypeOf(&dropScheduleNode{}):         "drop schedule",
 	reflect.TypeOf(&dropSequenceNode{}):         "drop sequence",
 	reflect.TypeOf(&dropTableNode{}):            "drop table",
+	reflect.TypeOf(&dropTriggerNode{}):          "drop trigger",
 	reflect.TypeOf(&DropUserNode{}):             "drop user/role",
 	reflect.TypeOf(&dropViewNode{}):             "drop view",
 	reflect.TypeOf(&explainDistSQLNode{}):       "explain distsql",
@@ -754,6 +781,7 @@ var planNodeNames = map[reflect.Type]string{
 	reflect.TypeOf(&max1RowNode{}):              "max1row",
 	reflect.TypeOf(&ordinalityNode{}):           "ordinality",
 	reflect.TypeOf(&projectSetNode{}):           "project set",
+	reflect.TypeOf(&recursiveCTENode{}):         "recursive cte",
 	reflect.TypeOf(&relocateNode{}):             "relocate",
 	reflect.TypeOf(&renameColumnNode{}):         "rename column",
 	reflect.TypeOf(&renameDatabaseNode{}):       "rename database",
@@ -45,7 +45,7 @@ import (
 type cteNameEnvironment []cteNameEnvironmentFrame
 
 // cteNameEnvironmentFrame is a map from CTE name to datasource.
-type cteNameEnvironmentFrame map[tree.Name]cteSource
+type cteNameEnvironmentFrame map[tree.Name]*cteSource
 
 // cteSource is the value part of an entry in an environment frame. It holds
 // the plan that will be used to retrieve the data that's named by the CTE.
@@ -58,6 +58,13 @@ type cteSource struct {
 	// alias holds the name of the CTE and the renaming of its columns, if
 	// present.
 	alias tree.AliasClause
+	// inline is set for CTEs declared as NOT MATERIALIZED. Each use of such a
+	// CTE after the first one plans def again in env, the environment the CTE
+	// was declared in.
+	inline    bool
+	def       *tree.CTE
+	recursive bool
+	env       cteNameEnvironment
 }
 
 func (e cteNameEnvironment) push(frame cteNameEnvironmentFrame) cteNameEnvironment {
@@ -68,6 +75,34 @@ func (e cteNameEnvironment) pop() cteNameEnvironment {
 	return e[:len(e)-1]
 }
 
+// hideForRecursiveTerm returns a copy of the environment in which all the
+// CTEs are marked as used. The recursive term of a recursive CTE is planned
+// once per iteration, so that the CTEs it would refer to would be used
+// multiple times, which is not supported.
+func (e cteNameEnvironment) hideForRecursiveTerm() cteNameEnvironment {
+	res := make(cteNameEnvironment, len(e))
+	for i, frame := range e {
+		res[i] = make(cteNameEnvironmentFrame, len(frame))
+		for name, src := range frame {
+			res[i][name] = &cteSource{used: true, alias: src.alias}
+		}
+	}
+	return res
+}
+
+// snapshot returns a copy of the environment whose top frame only contains
+// the CTEs defined so far. The other frames are shared.
+func (e cteNameEnvironment) snapshot() cteNameEnvironment {
+	res := make(cteNameEnvironment, len(e))
+	copy(res, e)
+	top := make(cteNameEnvironmentFrame, len(e[len(e)-1]))
+	for name, src := range e[len(e)-1] {
+		top[name] = src
+	}
+	res[len(res)-1] = top
+	return res
+}
+
 func popCteNameEnvironment(p *planner) error {
 	e := p.curPlan.cteNameEnvironment
 	for alias, src := range e[len(e)-1] {
@@ -116,11 +151,24 @@ func (p *planner) initWith(ctx context.Context, with *tree.With) (func(p *planne
 					"WITH query name %s specified more than once",
 					cte.Name.Alias)
 			}
-			ctePlan, err := p.newPlan(ctx, cte.Stmt, nil)
+			ctePlan, err := p.newCTEPlan(ctx, cte, with.Recursive)
 			if err != nil {
 				return nil, err
 			}
-			frame[cte.Name.Alias] = cteSource{plan: ctePlan, alias: cte.Name}
+			if cte.Mtr.Set && cte.Mtr.Materialize {
+				// A MATERIALIZED CTE is evaluated to completion on its own. The
+				// spool also prevents the filters of the enclosing query from
+				// being pushed into it.
+				ctePlan = p.makeSpool(ctePlan)
+			}
+			src := &cteSource{plan: ctePlan, alias: cte.Name}
+			if cte.Mtr.Set && !cte.Mtr.Materialize {
+				src.inline = true
+				src.def = cte
+				src.recursive = with.Recursive
+				src.env = p.curPlan.cteNameEnvironment.snapshot()
+			}
+			frame[cte.Name.Alias] = src
 		}
 		return popCteNameEnvironment, nil
 	}
@@ -130,10 +178,20 @@ func (p *planner) initWith(ctx context.Context, with *tree.With) (func(p *planne
 	return nil, nil
 }
 
+// newCTEPlan plans the statement of a CTE.
+func (p *planner) newCTEPlan(ctx context.Context, cte *tree.CTE, recursive bool) (planNode, error) {
+	if recursive {
+		return p.newRecursiveCTEPlan(ctx, cte)
+	}
+	return p.newPlan(ctx, cte.Stmt, nil)
+}
+
 // getCTEDataSource looks up the table name in the planner's CTE name
 // environment, returning the planDataSource corresponding to the CTE if it was
 // found. The second return parameter returns true if a CTE was found.
-func (p *planner) getCTEDataSource(tn *tree.TableName) (planDataSource, bool, error) {
+func (p *planner) getCTEDataSource(
+	ctx context.Context, tn *tree.TableName,
+) (planDataSource, bool, error) {
 	if p.curPlan.cteNameEnvironment == nil {
 		return planDataSource{}, false, nil
 	}
@@ -146,16 +204,29 @@ func (p *planner) getCTEDataSource(tn *tree.TableName) (planDataSource, bool, er
 	for i := len(env) - 1; i >= 0; i-- {
 		frame := p.curPlan.cteNameEnvironment[i]
 		if cteSource, ok := frame[tn.TableName]; ok {
+			plan := cteSource.plan
 			if cteSource.used {
-				// TODO(jordan): figure out how to lift this restriction.
-				// CTE expressions that are used more than once will need to be
-				// pre-evaluated like subqueries, I think.
-				return planDataSource{}, false, pgerror.UnimplementedWithIssueErrorf(21084,
-					"unsupported multiple use of CTE clause %q", tree.ErrString(tn))
+				seenMutation, err := containsMutations(plan)
+				if err != nil {
+					return planDataSource{}, false, err
+				}
+				if !cteSource.inline || seenMutation {
+					// TODO(jordan): figure out how to lift this restriction.
+					// CTE expressions that are used more than once will need to be
+					// pre-evaluated like subqueries, I think.
+					return planDataSource{}, false, pgerror.UnimplementedWithIssueErrorf(21084,
+						"unsupported multiple use of CTE clause %q", tree.ErrString(tn))
+				}
+				// The CTE is inlined: plan another copy of its statement.
+				saved := p.curPlan.cteNameEnvironment
+				p.curPlan.cteNameEnvironment = cteSource.env
+				plan, err = p.newCTEPlan(ctx, cteSource.def, cteSource.recursive)
+				p.curPlan.cteNameEnvironment = saved
+				if err != nil {
+					return planDataSource{}, false, err
+				}
 			}
 			cteSource.used = true
-			frame[tn.TableName] = cteSource
-			plan := cteSource.plan
 			cols := planColumns(plan)
 			if len(cols) == 0 {
 				return planDataSource{}, false, pgerror.NewErrorf(pgerror.CodeFeatureNotSupportedError,
@@ -208,6 +208,32 @@ var backwardCompatibleMigrations = []migrationDescriptor{
 		name:   "propagate the ts purge interval to the new setting names",
 		workFn: retireOldTsPurgeIntervalSettings,
 	},
+	{
+		// Introduced in v19.1.
+		name:                "create system.user_auth_state table",
+		workFn:              createUserAuthStateTable,
+		includedInBootstrap: true,
+		newDescriptorIDs:    staticIDs(keys.UserAuthStateTableID),
+	},
+	{
+		// Introduced in v19.1.
+		name:                "create system.pinned_plans table",
+		workFn:              createPinnedPlansTable,
+		includedInBootstrap: true,
+		newDescriptorIDs:    staticIDs(keys.PinnedPlansTableID),
+	},
+	{
+		// Introduced in v19.1.
+		name:                "create system.schedules and system.schedule_runs tables",
+		workFn:              createSchedulesTables,
+		includedInBootstrap: true,
+		newDescriptorIDs:    staticIDs(keys.SchedulesTableID, keys.ScheduleRunsTableID),
+	},
+	{
+		// Introduced in v19.1.
+		name:   "add expression and predicate to system.table_statistics",
+		workFn: addTableStatisticsExpressionAndPredicate,
+	},
 }
 
 func staticIDs(ids ...sqlbase.ID) func(ctx context.Context, db db) ([]sqlbase.ID, error) {
@@ -549,6 +575,21 @@ func createCommentTable(ctx context.Context, r runner) error {
 	return createSystemTable(ctx, r, sqlbase.CommentsTable)
 }
 
+func createUserAuthStateTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.UserAuthStateTable)
+}
+
+func createPinnedPlansTable(ctx context.Context, r runner) error {
+	return createSystemTable(ctx, r, sqlbase.PinnedPlansTable)
+}
+
+func createSchedulesTables(ctx context.Context, r runner) error {
+	if err := createSystemTable(ctx, r, sqlbase.SchedulesTable); err != nil {
+		return err
+	}
+	return createSystemTable(ctx, r, sqlbase.ScheduleRunsTable)
+}
+
 var reportingOptOut = envutil.EnvOrDefaultBool("COCKROACH_SKIP_ENABLING_DIAGNOSTIC_REPORTING", false)
 
 func runStmtAsRootWithRetry(
@@ -882,6 +923,45 @@ func addJobsProgress(ctx context.Context, r runner) error {
 	})
 }
 
+func addTableStatisticsExpressionAndPredicate(ctx context.Context, r runner) error {
+	// Like in addJobsProgress, we change the schema manually rather than with
+	// an ALTER TABLE, whose schema change job would not be able to run if the
+	// jobs table has not been migrated yet.
+	return r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		if err := txn.SetSystemConfigTrigger(); err != nil {
+			return err
+		}
+		desc, err := sqlbase.GetMutableTableDescFromID(ctx, txn, keys.TableStatisticsTableID)
+		if err != nil {
+			return err
+		}
+		changed := false
+		for _, name := range []string{"expression", "predicate"} {
+			if _, err := desc.FindActiveColumnByName(name); err == nil {
+				continue
+			}
+			desc.AddColumn(&sqlbase.ColumnDescriptor{
+				Name:     name,
+				Type:     sqlbase.ColumnType{SemanticType: sqlbase.ColumnType_STRING},
+				Nullable: true,
+			})
+			if err := desc.AddColumnToFamilyMaybeCreate(
+				name, desc.Families[0].Name, false /* create */, false, /* ifNotExists */
+			); err != nil {
+				return err
+			}
+			changed = true
+		}
+		if !changed {
+			return nil
+		}
+		if err := desc.AllocateIDs(); err != nil {
+			return err
+		}
+		return txn.Put(ctx, sqlbase.MakeDescMetadataKey(desc.ID), sqlbase.WrapDescriptor(desc))
+	})
+}
+
 func retireOldTsPurgeIntervalSettings(ctx context.Context, r runner) error {
 	// We are going to deprecate `timeseries.storage.10s_resolution_ttl`
 	// into `timeseries.storage.resolution_10s.ttl` if the latter is not
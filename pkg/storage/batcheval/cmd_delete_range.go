@@ -17,14 +17,27 @@ package batcheval
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
 func init() {
-	RegisterCommand(roachpb.DeleteRange, DefaultDeclareKeys, DeleteRange)
+	RegisterCommand(roachpb.DeleteRange, declareKeysDeleteRange, DeleteRange)
+}
+
+func declareKeysDeleteRange(
+	desc roachpb.RangeDescriptor, header roachpb.Header, req roachpb.Request, spans *spanset.SpanSet,
+) {
+	DefaultDeclareKeys(desc, header, req, spans)
+	// Only the requests which may update the GC hint declare it, so that the
+	// other ones don't contend on it.
+	if req.(*roachpb.DeleteRangeRequest).UpdateRangeDeleteGCHint {
+		spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: keys.RangeGCHintKey(header.RangeID)})
+	}
 }
 
 // DeleteRange deletes the range of key/value pairs specified by
@@ -51,5 +64,33 @@ func DeleteRange(
 		reply.ResumeSpan = resumeSpan
 		reply.ResumeReason = roachpb.RESUME_KEY_LIMIT
 	}
+	if err == nil && args.UpdateRangeDeleteGCHint && !args.Inline {
+		err = updateRangeDeleteGCHint(ctx, batch, cArgs, timestamp)
+	}
 	return result.Result{}, err
 }
+
+// updateRangeDeleteGCHint forwards the GC hint of the range to the timestamp
+// of the deletion if the range has no live data left, see roachpb.GCHint. The
+// hint is only used to prioritize the work of the GC queue, which verifies
+// that the range can be cleared at once before doing so: a transactional
+// deletion could still be aborted or pushed, and the range could be written
+// to concurrently.
+func updateRangeDeleteGCHint(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, timestamp hlc.Timestamp,
+) error {
+	ms := cArgs.EvalCtx.GetMVCCStats()
+	ms.Add(*cArgs.Stats)
+	if ms.LiveCount != 0 {
+		return nil
+	}
+	sl := MakeStateLoader(cArgs.EvalCtx)
+	hint, err := sl.LoadGCHint(ctx, batch)
+	if err != nil {
+		return err
+	}
+	if !hint.LatestRangeDeleteTimestamp.Forward(timestamp) {
+		return nil
+	}
+	return sl.SetGCHint(ctx, batch, cArgs.Stats, hint)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/pkg/errors"
 )
 
 func init() {
@@ -39,6 +40,11 @@ func declareKeysGC(
 	for _, key := range gcr.Keys {
 		spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: key.Key})
 	}
+	// Clearing the range blocks the writes to all of its user keys, which are
+	// not expected since the range is left without live data.
+	if gcr.ClearRange {
+		spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: gcr.Key, EndKey: gcr.EndKey})
+	}
 	// Be smart here about blocking on the threshold keys. The GC queue can send an empty
 	// request first to bump the thresholds, and then another one that actually does work
 	// but can avoid declaring these keys below.
@@ -62,12 +68,17 @@ func declareKeysGC(
 // GC iterates through the list of keys to garbage collect
 // specified in the arguments. MVCCGarbageCollect is invoked on each
 // listed key along with the expiration timestamp. The GC metadata
-// specified in the args is persisted after GC.
+// specified in the args is persisted after GC. If ClearRange is set,
+// all the user keys of the range are cleared instead, see
+// clearRangeData.
 func GC(
 	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
 ) (result.Result, error) {
 	args := cArgs.Args.(*roachpb.GCRequest)
 	h := cArgs.Header
+	if args.ClearRange && (len(args.Keys) != 0 || args.Threshold == (hlc.Timestamp{})) {
+		return result.Result{}, errors.New("GC with clear range requires a threshold and no keys")
+	}
 
 	// All keys must be inside the current replica range. Keys outside
 	// of this range in the GC request are dropped silently, which is
@@ -108,6 +119,14 @@ func GC(
 	var pd result.Result
 	stateLoader := MakeStateLoader(cArgs.EvalCtx)
 
+	if args.ClearRange {
+		compaction, err := clearRangeData(ctx, batch, cArgs, newThreshold)
+		if err != nil {
+			return result.Result{}, err
+		}
+		pd.Replicated.SuggestedCompactions = []storagepb.SuggestedCompaction{compaction}
+	}
+
 	// Don't write these keys unless we have to. We also don't declare these
 	// keys unless we have to (to allow the GC queue to batch requests more
 	// efficiently), and we must honor what we declare.
@@ -135,3 +154,71 @@ func GC(
 	}
 	return pd, nil
 }
+
+// clearRangeData removes all the MVCC versions of the user keys of the range
+// with a range deletion. This is only correct if none of them can be read
+// once the GC threshold is set to the given one, i.e. if the range has no live
+// data or intents, and no version newer than the threshold. This is checked
+// with a time-bound iterator, which is expected to be cheap provided the
+// range is not written to since its data was deleted (see roachpb.GCHint).
+func clearRangeData(
+	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, threshold hlc.Timestamp,
+) (storagepb.SuggestedCompaction, error) {
+	args := cArgs.Args.(*roachpb.GCRequest)
+	desc := cArgs.EvalCtx.Desc()
+	if !desc.StartKey.Equal(args.Key) || !desc.EndKey.Equal(args.EndKey) {
+		return storagepb.SuggestedCompaction{}, errors.Errorf(
+			"GC clear range span %s does not match range %s", args.Span(), desc)
+	}
+	if desc.StartKey.Less(roachpb.RKey(keys.LocalMax)) {
+		return storagepb.SuggestedCompaction{}, errors.Errorf(
+			"cannot clear range %s which contains local keys", desc)
+	}
+	if ms := cArgs.EvalCtx.GetMVCCStats(); ms.ContainsEstimates || ms.LiveCount != 0 || ms.IntentCount != 0 {
+		return storagepb.SuggestedCompaction{}, errors.Errorf(
+			"cannot clear range %s with %d live keys and %d intents (estimates: %t)",
+			desc, ms.LiveCount, ms.IntentCount, ms.ContainsEstimates)
+	}
+
+	from := engine.MVCCKey{Key: args.Key}
+	to := engine.MVCCKey{Key: args.EndKey}
+	iter := batch.NewIterator(engine.IterOptions{
+		UpperBound:       to.Key,
+		MinTimestampHint: threshold.Next(),
+		MaxTimestampHint: hlc.MaxTimestamp,
+	})
+	for iter.Seek(from); ; iter.Next() {
+		if ok, err := iter.Valid(); err != nil {
+			iter.Close()
+			return storagepb.SuggestedCompaction{}, err
+		} else if !ok {
+			break
+		}
+		// The time bounds are only a hint, which is why the timestamps are
+		// checked here.
+		if key := iter.UnsafeKey(); threshold.Less(key.Timestamp) {
+			iter.Close()
+			return storagepb.SuggestedCompaction{}, errors.Errorf(
+				"cannot clear range %s: key %s is newer than the GC threshold %s",
+				desc, key, threshold)
+		}
+	}
+	iter.Close()
+
+	statsDelta, err := computeStatsDelta(ctx, batch, cArgs, from, to)
+	if err != nil {
+		return storagepb.SuggestedCompaction{}, err
+	}
+	cArgs.Stats.Subtract(statsDelta)
+	if err := batch.ClearRange(from, to); err != nil {
+		return storagepb.SuggestedCompaction{}, err
+	}
+	return storagepb.SuggestedCompaction{
+		StartKey: from.Key,
+		EndKey:   to.Key,
+		Compaction: storagepb.Compaction{
+			Bytes:            statsDelta.Total(),
+			SuggestedAtNanos: cArgs.Header.Timestamp.WallTime,
+		},
+	}, nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// writeDeletedKeys writes n keys at timestamp 1, and deletes them at
+// timestamp 2.
+func writeDeletedKeys(
+	t *testing.T, eng engine.ReadWriter, ms *enginepb.MVCCStats, prefix string, n int,
+) {
+	ctx := context.Background()
+	var value roachpb.Value
+	value.SetString("value")
+	for i := 0; i < n; i++ {
+		key := roachpb.Key(fmt.Sprintf("%s%04d", prefix, i))
+		if err := engine.MVCCPut(ctx, eng, ms, key, hlc.Timestamp{WallTime: 1}, value, nil); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.MVCCDelete(ctx, eng, ms, key, hlc.Timestamp{WallTime: 2}, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestGCClearRange verifies that a GC request with ClearRange set removes all
+// the data of the range when it can't be read anymore, and fails otherwise.
+func TestGCClearRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	startKey := roachpb.Key("a")
+	endKey := roachpb.Key("z")
+	desc := roachpb.RangeDescriptor{
+		RangeID:  99,
+		StartKey: roachpb.RKey(startKey),
+		EndKey:   roachpb.RKey(endKey),
+	}
+
+	testCases := []struct {
+		name      string
+		live      bool
+		threshold int64
+		expErr    string
+	}{
+		{name: "expired", threshold: 3},
+		{name: "not expired", threshold: 1, expErr: "newer than the GC threshold"},
+		{name: "live data", live: true, threshold: 3, expErr: "with 1 live keys"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+			defer eng.Close()
+
+			var stats enginepb.MVCCStats
+			writeDeletedKeys(t, eng, &stats, "b", 10)
+			if tc.live {
+				var value roachpb.Value
+				value.SetString("live")
+				if err := engine.MVCCPut(
+					ctx, eng, &stats, roachpb.Key("c"), hlc.Timestamp{WallTime: 1}, value, nil,
+				); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			batch := eng.NewBatch()
+			defer batch.Close()
+
+			cArgs := CommandArgs{Header: roachpb.Header{RangeID: desc.RangeID, Timestamp: hlc.Timestamp{WallTime: 4}}}
+			cArgs.EvalCtx = &mockEvalCtx{desc: &desc, stats: stats}
+			cArgs.Args = &roachpb.GCRequest{
+				RequestHeader: roachpb.RequestHeader{Key: startKey, EndKey: endKey},
+				Threshold:     hlc.Timestamp{WallTime: tc.threshold},
+				ClearRange:    true,
+			}
+			cArgs.Stats = &enginepb.MVCCStats{}
+
+			res, err := GC(ctx, batch, cArgs, &roachpb.GCResponse{})
+			if !testutils.IsError(err, tc.expErr) {
+				t.Fatalf("expected error %q, got %v", tc.expErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(res.Replicated.SuggestedCompactions) != 1 {
+				t.Errorf("expected a suggested compaction, got %+v", res.Replicated.SuggestedCompactions)
+			}
+			kvs, err := engine.Scan(batch, engine.MakeMVCCMetadataKey(startKey), engine.MakeMVCCMetadataKey(endKey), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(kvs) != 0 {
+				t.Errorf("expected the range to be cleared, found %d keys", len(kvs))
+			}
+
+			// The stats of the user data are negated; the GC threshold is
+			// written to a system key.
+			newStats := stats
+			newStats.Add(*cArgs.Stats)
+			newStats.SysBytes, newStats.SysCount = 0, 0
+			newStats.AgeTo(0)
+			if !newStats.Equal(enginepb.MVCCStats{}) {
+				t.Errorf("expected the stats to be negated, got %+v", newStats)
+			}
+		})
+	}
+}
+
+// TestDeleteRangeGCHint verifies that a DeleteRange request with
+// UpdateRangeDeleteGCHint set leaves a GC hint iff it deletes all of the live
+// data of the range.
+func TestDeleteRangeGCHint(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	desc := roachpb.RangeDescriptor{
+		RangeID:  99,
+		StartKey: roachpb.RKey("a"),
+		EndKey:   roachpb.RKey("z"),
+	}
+
+	testCases := []struct {
+		name    string
+		endKey  roachpb.Key
+		gcHint  bool
+		expHint bool
+	}{
+		{name: "all data", endKey: roachpb.Key("d"), gcHint: true, expHint: true},
+		{name: "some data", endKey: roachpb.Key("c"), gcHint: true, expHint: false},
+		{name: "no hint requested", endKey: roachpb.Key("d"), gcHint: false, expHint: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+			defer eng.Close()
+
+			var stats enginepb.MVCCStats
+			var value roachpb.Value
+			value.SetString("value")
+			for _, key := range []string{"b", "c"} {
+				if err := engine.MVCCPut(
+					ctx, eng, &stats, roachpb.Key(key), hlc.Timestamp{WallTime: 1}, value, nil,
+				); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ts := hlc.Timestamp{WallTime: 5}
+			cArgs := CommandArgs{Header: roachpb.Header{RangeID: desc.RangeID, Timestamp: ts}}
+			cArgs.EvalCtx = &mockEvalCtx{desc: &desc, stats: stats}
+			cArgs.Args = &roachpb.DeleteRangeRequest{
+				RequestHeader:           roachpb.RequestHeader{Key: roachpb.Key("a"), EndKey: tc.endKey},
+				UpdateRangeDeleteGCHint: tc.gcHint,
+			}
+			cArgs.MaxKeys = math.MaxInt64
+			cArgs.Stats = &enginepb.MVCCStats{}
+			if _, err := DeleteRange(ctx, eng, cArgs, &roachpb.DeleteRangeResponse{}); err != nil {
+				t.Fatal(err)
+			}
+
+			hint, err := MakeStateLoader(cArgs.EvalCtx).LoadGCHint(ctx, eng)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var expHint roachpb.GCHint
+			if tc.expHint {
+				expHint.LatestRangeDeleteTimestamp = ts
+			}
+			if !hint.Equal(expHint) {
+				t.Errorf("expected hint %+v, got %+v", expHint, hint)
+			}
+		})
+	}
+}
@@ -38,41 +38,50 @@ func Scan(
 	h := cArgs.Header
 	reply := resp.(*roachpb.ScanResponse)
 
+	scan := func(
+		key, endKey roachpb.Key, max int64,
+	) (int64, *roachpb.Span, []roachpb.Intent, error) {
+		opts := engine.MVCCScanOptions{
+			Inconsistent:   h.ReadConsistency != roachpb.CONSISTENT,
+			IgnoreSequence: shouldIgnoreSequenceNums(cArgs.EvalCtx),
+			Txn:            h.Txn,
+		}
+		switch args.ScanFormat {
+		case roachpb.BATCH_RESPONSE:
+			kvData, numKvs, resumeSpan, intents, err := engine.MVCCScanToBytes(
+				ctx, batch, key, endKey, max, h.Timestamp, opts)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			reply.BatchResponses = append(reply.BatchResponses, kvData)
+			return numKvs, resumeSpan, intents, nil
+		case roachpb.KEY_VALUES:
+			rows, resumeSpan, intents, err := engine.MVCCScan(
+				ctx, batch, key, endKey, max, h.Timestamp, opts)
+			if err != nil {
+				return 0, nil, nil, err
+			}
+			reply.Rows = append(reply.Rows, rows...)
+			return int64(len(rows)), resumeSpan, intents, nil
+		default:
+			panic(fmt.Sprintf("Unknown scanFormat %d", args.ScanFormat))
+		}
+	}
+
 	var err error
 	var intents []roachpb.Intent
 	var resumeSpan *roachpb.Span
 
-	switch args.ScanFormat {
-	case roachpb.BATCH_RESPONSE:
-		var kvData []byte
-		var numKvs int64
-		kvData, numKvs, resumeSpan, intents, err = engine.MVCCScanToBytes(
-			ctx, batch, args.Key, args.EndKey, cArgs.MaxKeys, h.Timestamp,
-			engine.MVCCScanOptions{
-				Inconsistent:   h.ReadConsistency != roachpb.CONSISTENT,
-				IgnoreSequence: shouldIgnoreSequenceNums(cArgs.EvalCtx),
-				Txn:            h.Txn,
-			})
-		if err != nil {
-			return result.Result{}, err
-		}
-		reply.NumKeys = numKvs
-		reply.BatchResponses = [][]byte{kvData}
-	case roachpb.KEY_VALUES:
-		var rows []roachpb.KeyValue
-		rows, resumeSpan, intents, err = engine.MVCCScan(
-			ctx, batch, args.Key, args.EndKey, cArgs.MaxKeys, h.Timestamp, engine.MVCCScanOptions{
-				Inconsistent:   h.ReadConsistency != roachpb.CONSISTENT,
-				IgnoreSequence: shouldIgnoreSequenceNums(cArgs.EvalCtx),
-				Txn:            h.Txn,
-			})
-		if err != nil {
-			return result.Result{}, err
-		}
-		reply.NumKeys = int64(len(rows))
-		reply.Rows = rows
-	default:
-		panic(fmt.Sprintf("Unknown scanFormat %d", args.ScanFormat))
+	if h.WaitPolicy == roachpb.WaitPolicy_SKIP_LOCKED && h.ReadConsistency == roachpb.CONSISTENT {
+		// The returned intents are the skipped ones, which are cleaned up
+		// asynchronously below.
+		reply.NumKeys, resumeSpan, intents, err = scanSkipLocked(
+			args.Span(), cArgs.MaxKeys, false /* reverse */, scan)
+	} else {
+		reply.NumKeys, resumeSpan, intents, err = scan(args.Key, args.EndKey, cArgs.MaxKeys)
+	}
+	if err != nil {
+		return result.Result{}, err
 	}
 
 	if resumeSpan != nil {
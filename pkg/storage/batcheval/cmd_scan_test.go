@@ -0,0 +1,158 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package batcheval
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// TestScanSkipLocked verifies that scans with the SKIP_LOCKED wait policy
+// skip the rows that have a conflicting intent on any of their column
+// families.
+func TestScanSkipLocked(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	db := engine.NewInMem(roachpb.Attributes{}, 10<<20)
+	defer db.Close()
+
+	rowKey := func(row uint64, family uint32) roachpb.Key {
+		k := keys.MakeTablePrefix(53)
+		k = encoding.EncodeUvarintAscending(k, 1 /* index ID */)
+		k = encoding.EncodeUvarintAscending(k, row)
+		return keys.MakeFamilyKey(k, family)
+	}
+	v := roachpb.MakeValueFromString("v")
+	ts1 := hlc.Timestamp{WallTime: 1}
+	ts2 := hlc.Timestamp{WallTime: 2}
+	for row := uint64(1); row <= 5; row++ {
+		for family := uint32(0); family < 2; family++ {
+			if err := engine.MVCCPut(ctx, db, nil, rowKey(row, family), ts1, v, nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	// Rows 2 and 4 are locked by another transaction.
+	txn := &roachpb.Transaction{
+		TxnMeta: enginepb.TxnMeta{
+			Key:       rowKey(2, 1),
+			ID:        uuid.MakeV4(),
+			Timestamp: ts1,
+		},
+		OrigTimestamp: ts1,
+	}
+	for _, k := range []roachpb.Key{rowKey(2, 1), rowKey(4, 0)} {
+		if err := engine.MVCCPut(ctx, db, nil, k, ts1, v, txn); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	span := roachpb.RequestHeader{Key: rowKey(1, 0), EndKey: rowKey(6, 0)}
+	rows := func(kvs []roachpb.KeyValue) []roachpb.Key {
+		var res []roachpb.Key
+		for _, kv := range kvs {
+			res = append(res, kv.Key)
+		}
+		return res
+	}
+	header := roachpb.Header{Timestamp: ts2, WaitPolicy: roachpb.WaitPolicy_SKIP_LOCKED}
+	evalCtx := &mockEvalCtx{clusterSettings: cluster.MakeTestingClusterSettings()}
+
+	t.Run("forward", func(t *testing.T) {
+		var resp roachpb.ScanResponse
+		res, err := Scan(ctx, db, CommandArgs{
+			EvalCtx: evalCtx,
+			Header:  header,
+			Args:    &roachpb.ScanRequest{RequestHeader: span},
+			MaxKeys: math.MaxInt64,
+		}, &resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []roachpb.Key{rowKey(1, 0), rowKey(1, 1), rowKey(3, 0), rowKey(3, 1), rowKey(5, 0), rowKey(5, 1)}
+		if actual := rows(resp.Rows); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+		if resp.NumKeys != int64(len(expected)) || resp.ResumeSpan != nil {
+			t.Fatalf("unexpected response %+v", resp)
+		}
+		if res.Local.Intents == nil || len((*res.Local.Intents)[0].Intents) != 2 {
+			t.Fatalf("expected the skipped intents to be cleaned up, got %+v", res.Local.Intents)
+		}
+	})
+
+	t.Run("reverse", func(t *testing.T) {
+		var resp roachpb.ReverseScanResponse
+		if _, err := ReverseScan(ctx, db, CommandArgs{
+			EvalCtx: evalCtx,
+			Header:  header,
+			Args:    &roachpb.ReverseScanRequest{RequestHeader: span},
+			MaxKeys: math.MaxInt64,
+		}, &resp); err != nil {
+			t.Fatal(err)
+		}
+		expected := []roachpb.Key{rowKey(5, 1), rowKey(5, 0), rowKey(3, 1), rowKey(3, 0), rowKey(1, 1), rowKey(1, 0)}
+		if actual := rows(resp.Rows); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		var resp roachpb.ScanResponse
+		if _, err := Scan(ctx, db, CommandArgs{
+			EvalCtx: evalCtx,
+			Header:  header,
+			Args:    &roachpb.ScanRequest{RequestHeader: span},
+			MaxKeys: 3,
+		}, &resp); err != nil {
+			t.Fatal(err)
+		}
+		expected := []roachpb.Key{rowKey(1, 0), rowKey(1, 1), rowKey(3, 0)}
+		if actual := rows(resp.Rows); !reflect.DeepEqual(actual, expected) {
+			t.Fatalf("expected %v, got %v", expected, actual)
+		}
+		if resp.ResumeSpan == nil || !resp.ResumeSpan.Key.Equal(rowKey(3, 1)) ||
+			!resp.ResumeSpan.EndKey.Equal(span.EndKey) {
+			t.Fatalf("unexpected resume span %v", resp.ResumeSpan)
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		var resp roachpb.ScanResponse
+		_, err := Scan(ctx, db, CommandArgs{
+			EvalCtx: evalCtx,
+			Header:  roachpb.Header{Timestamp: ts2},
+			Args:    &roachpb.ScanRequest{RequestHeader: span},
+			MaxKeys: math.MaxInt64,
+		}, &resp)
+		if _, ok := err.(*roachpb.WriteIntentError); !ok {
+			t.Fatalf("expected a WriteIntentError, got %v", err)
+		}
+	})
+}
@@ -17,6 +17,7 @@ package batcheval
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 )
@@ -53,3 +54,87 @@ func CollectIntentRows(
 	}
 	return res, nil
 }
+
+// scanFunc scans the span [key, endKey) for up to max results, accumulating
+// them into the response, and returns the number of results scanned.
+type scanFunc func(
+	key, endKey roachpb.Key, max int64,
+) (numKeys int64, resumeSpan *roachpb.Span, intents []roachpb.Intent, err error)
+
+// scanSkipLocked implements the SKIP_LOCKED wait policy of consistent scans.
+// It scans the span using the provided function and, whenever a conflicting
+// intent is found, skips the row that the intent belongs to and continues
+// with the rest of the span. The returned intents are the skipped ones; the
+// caller hands them off for asynchronous cleanup so that the intents of
+// abandoned transactions don't linger.
+func scanSkipLocked(
+	span roachpb.Span, max int64, reverse bool, scan scanFunc,
+) (numKeys int64, resumeSpan *roachpb.Span, skipped []roachpb.Intent, err error) {
+	cur := span
+	for {
+		n, resume, _, scanErr := scan(cur.Key, cur.EndKey, max)
+		wiErr, ok := scanErr.(*roachpb.WriteIntentError)
+		if !ok {
+			if scanErr != nil {
+				return 0, nil, nil, scanErr
+			}
+			return numKeys + n, resume, skipped, nil
+		}
+
+		// The scan failed without producing results. Find the first locked row
+		// in scan order; the part of the span before it is free of conflicts.
+		intent := wiErr.Intents[0]
+		for _, in := range wiErr.Intents[1:] {
+			if (in.Key.Compare(intent.Key) < 0) != reverse {
+				intent = in
+			}
+		}
+		locked := lockedRowSpan(intent.Key)
+		if locked.Key.Compare(cur.Key) < 0 {
+			locked.Key = cur.Key
+		}
+		if locked.EndKey.Compare(cur.EndKey) > 0 {
+			locked.EndKey = cur.EndKey
+		}
+		before := roachpb.Span{Key: cur.Key, EndKey: locked.Key}
+		after := roachpb.Span{Key: locked.EndKey, EndKey: cur.EndKey}
+		if reverse {
+			before, after = after, before
+		}
+		skipped = append(skipped, intent)
+
+		if before.Key.Compare(before.EndKey) < 0 {
+			n, resume, _, scanErr = scan(before.Key, before.EndKey, max)
+			if scanErr != nil {
+				return 0, nil, nil, scanErr
+			}
+			numKeys += n
+			max -= n
+			if resume != nil {
+				if reverse {
+					resume.Key = cur.Key
+				} else {
+					resume.EndKey = cur.EndKey
+				}
+				return numKeys, resume, skipped, nil
+			}
+		}
+		if after.Key.Compare(after.EndKey) >= 0 {
+			return numKeys, nil, skipped, nil
+		}
+		if max <= 0 {
+			return numKeys, &after, skipped, nil
+		}
+		cur = after
+	}
+}
+
+// lockedRowSpan returns the span of the SQL row that the given key belongs
+// to, or the span of the key itself if it isn't part of a SQL table.
+func lockedRowSpan(key roachpb.Key) roachpb.Span {
+	rowKey, err := keys.EnsureSafeSplitKey(key)
+	if err != nil || len(rowKey) == len(key) {
+		return roachpb.Span{Key: key, EndKey: key.Next()}
+	}
+	return roachpb.Span{Key: rowKey, EndKey: rowKey.PrefixEnd()}
+}
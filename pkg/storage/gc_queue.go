@@ -31,7 +31,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/storage/rditer"
+	"github.com/cockroachdb/cockroach/pkg/storage/stateloader"
 	"github.com/cockroachdb/cockroach/pkg/storage/storagebase"
+	"github.com/cockroachdb/cockroach/pkg/storage/storagepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -56,6 +58,10 @@ const (
 	// on keys and intents.
 	gcKeyScoreThreshold    = 2
 	gcIntentScoreThreshold = 10
+	// gcClearRangeScore is added to the score of the replicas which can be
+	// cleared at once thanks to their GC hint. This puts them ahead of the
+	// others: their GC is cheap, and it removes all of their data.
+	gcClearRangeScore = 100
 
 	// gcKeyVersionChunkBytes is the threshold size for splitting
 	// GCRequests into multiple batches.
@@ -121,6 +127,11 @@ type gcQueueScore struct {
 	FuzzFactor          float64
 	FinalScore          float64
 	ShouldQueue         bool
+	// Hint is the GC hint of the replica. ClearRange is set if it indicates
+	// that all the MVCC versions of the replica can be removed with a range
+	// deletion, see gcHintAllowsClearRange.
+	Hint       roachpb.GCHint
+	ClearRange bool
 
 	GCBytes                  int64
 	GCByteAge                int64
@@ -139,10 +150,10 @@ func (r gcQueueScore) String() string {
 		likelyLastGC = fmt.Sprintf("%s ago", r.LikelyLastGC)
 	}
 	return fmt.Sprintf("queue=%t with %.2f/fuzz(%.2f)=%.2f=valScaleScore(%.2f)*deadFrac(%.2f)+intentScore(%.2f)\n"+
-		"likely last GC: %s, %s non-live, curr. age %s*s, min exp. reduction: %s*s",
+		"likely last GC: %s, %s non-live, curr. age %s*s, min exp. reduction: %s*s, clear range: %t",
 		r.ShouldQueue, r.FinalScore, r.FuzzFactor, r.FinalScore/r.FuzzFactor, r.ValuesScalableScore,
 		r.DeadFraction, r.IntentScore, likelyLastGC, humanizeutil.IBytes(r.GCBytes),
-		humanizeutil.IBytes(r.GCByteAge), humanizeutil.IBytes(r.ExpMinGCByteAgeReduction))
+		humanizeutil.IBytes(r.GCByteAge), humanizeutil.IBytes(r.ExpMinGCByteAgeReduction), r.ClearRange)
 }
 
 // shouldQueue determines whether a replica should be queued for garbage
@@ -175,9 +186,49 @@ func makeGCQueueScore(
 	if (gcThreshold != hlc.Timestamp{}) {
 		r.LikelyLastGC = time.Duration(now.WallTime - gcThreshold.Add(r.TTL.Nanoseconds(), 0).WallTime)
 	}
+
+	hint, err := stateloader.Make(desc.RangeID).LoadGCHint(ctx, repl.store.Engine())
+	if err != nil {
+		log.Warningf(ctx, "unable to load GC hint: %s", err)
+		return r
+	}
+	r.Hint = *hint
+	newThreshold := engine.MakeGarbageCollector(now, *zone.GC).Threshold
+	if gcHintAllowsClearRange(*hint, gcThreshold, newThreshold, ms) {
+		r.ClearRange = true
+		r.ShouldQueue = true
+		r.FinalScore += gcClearRangeScore
+	}
 	return r
 }
 
+// gcHintAllowsClearRange returns true if the GC hint of a replica indicates
+// that all of its data was deleted before the new GC threshold, and that it
+// has not been cleared since. The stats are used to skip the replicas which
+// were written to since their data was deleted: their GC can't be done with
+// a range deletion.
+func gcHintAllowsClearRange(
+	hint roachpb.GCHint, gcThreshold, newThreshold hlc.Timestamp, ms enginepb.MVCCStats,
+) bool {
+	deleteTS := hint.LatestRangeDeleteTimestamp
+	return deleteTS != (hlc.Timestamp{}) &&
+		gcThreshold.Less(deleteTS) && !newThreshold.Less(deleteTS) &&
+		ms.KeyCount > 0 && ms.LiveCount == 0 && ms.IntentCount == 0 && !ms.ContainsEstimates
+}
+
+// makeRangeGCInfo returns the GC state of the replica reported by
+// Replica.State.
+func makeRangeGCInfo(ctx context.Context, repl *Replica, now hlc.Timestamp) storagepb.RangeGCInfo {
+	r := makeGCQueueScore(ctx, repl, now, nil /* sysCfg */)
+	return storagepb.RangeGCInfo{
+		Hint:              r.Hint,
+		GCBytes:           r.GCBytes,
+		Score:             r.FinalScore,
+		ShouldQueue:       r.ShouldQueue,
+		LikelyLastGCNanos: r.LikelyLastGC.Nanoseconds(),
+	}
+}
+
 // makeGCQueueScoreImpl is used to compute when to trigger the GC Queue. It's
 // important that we don't queue a replica before a relevant amount of data is
 // actually deletable, or the queue might run in a tight loop. To this end, we
@@ -500,9 +551,29 @@ func (gcq *gcQueue) process(ctx context.Context, repl *Replica, sysCfg *config.S
 	}
 
 	log.Eventf(ctx, "processing replica with score %s", r)
+	if r.ClearRange {
+		// The regular GC below still runs, for the range-local keys and in case
+		// the range was not cleared. It has no user keys to go through after
+		// the range is cleared.
+		if err := gcq.clearRange(ctx, repl, now); err != nil {
+			gcq.store.metrics.GCClearRangeFailed.Inc(1)
+			log.VEventf(ctx, 2, "unable to clear range, falling back to regular GC: %s", err)
+		} else {
+			gcq.store.metrics.GCClearRangeSuccess.Inc(1)
+		}
+	}
 	return gcq.processImpl(ctx, repl, sysCfg, now)
 }
 
+// clearRange removes all the MVCC versions of the user keys of the replica
+// with a range deletion, after bumping its GC threshold. It fails if any of
+// them is live or newer than the threshold.
+func (gcq *gcQueue) clearRange(ctx context.Context, repl *Replica, now hlc.Timestamp) error {
+	_, zone := repl.DescAndZone()
+	gc := engine.MakeGarbageCollector(now, *zone.GC)
+	return (&replicaGCer{repl: repl}).ClearRange(ctx, gc.Threshold)
+}
+
 // NoopGCer implements GCer by doing nothing.
 type NoopGCer struct{}
 
@@ -555,6 +626,15 @@ func (r *replicaGCer) SetGCThreshold(ctx context.Context, thresh GCThreshold) er
 	return r.send(ctx, req)
 }
 
+// ClearRange sends a GCRequest which clears all the user keys of the range,
+// see batcheval.GC.
+func (r *replicaGCer) ClearRange(ctx context.Context, threshold hlc.Timestamp) error {
+	req := r.template()
+	req.Threshold = threshold
+	req.ClearRange = true
+	return r.send(ctx, req)
+}
+
 func (r *replicaGCer) GC(ctx context.Context, keys []roachpb.GCRequest_GCKey) error {
 	if len(keys) == 0 {
 		return nil
@@ -71,10 +71,10 @@ func TestGCQueueScoreString(t *testing.T) {
 			LikelyLastGC:             5 * time.Second,
 		},
 			`queue=true with 4.31/fuzz(1.25)=3.45=valScaleScore(4.00)*deadFrac(0.25)+intentScore(0.45)
-likely last GC: 5s ago, 3.0 KiB non-live, curr. age 512 KiB*s, min exp. reduction: 256 KiB*s`},
+likely last GC: 5s ago, 3.0 KiB non-live, curr. age 512 KiB*s, min exp. reduction: 256 KiB*s, clear range: false`},
 		// Check case of empty GCThreshold.
 		{gcQueueScore{ShouldQueue: true}, `queue=true with 0.00/fuzz(0.00)=NaN=valScaleScore(0.00)*deadFrac(0.00)+intentScore(0.00)
-likely last GC: never, 0 B non-live, curr. age 0 B*s, min exp. reduction: 0 B*s`},
+likely last GC: never, 0 B non-live, curr. age 0 B*s, min exp. reduction: 0 B*s, clear range: false`},
 	} {
 		if act := c.r.String(); act != c.exp {
 			t.Errorf("%d: wanted:\n'%s'\ngot:\n'%s'", i, c.exp, act)
@@ -82,6 +82,42 @@ likely last GC: never, 0 B non-live, curr. age 0 B*s, min exp. reduction: 0 B*s`
 	}
 }
 
+func TestGCHintAllowsClearRange(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ts := func(wallTime int64) hlc.Timestamp { return hlc.Timestamp{WallTime: wallTime} }
+	deleted := enginepb.MVCCStats{KeyCount: 10, ValCount: 20, KeyBytes: 100, ValBytes: 100}
+	live := deleted
+	live.LiveCount, live.LiveBytes = 1, 10
+	intents := deleted
+	intents.IntentCount = 1
+	for i, c := range []struct {
+		hint                      hlc.Timestamp
+		gcThreshold, newThreshold hlc.Timestamp
+		ms                        enginepb.MVCCStats
+		exp                       bool
+	}{
+		{hint: ts(5), gcThreshold: ts(1), newThreshold: ts(6), ms: deleted, exp: true},
+		{hint: ts(5), gcThreshold: ts(1), newThreshold: ts(5), ms: deleted, exp: true},
+		// No hint.
+		{gcThreshold: ts(1), newThreshold: ts(6), ms: deleted, exp: false},
+		// The deleted data has not expired yet.
+		{hint: ts(5), gcThreshold: ts(1), newThreshold: ts(4), ms: deleted, exp: false},
+		// The range was already GC'ed since the deletion.
+		{hint: ts(5), gcThreshold: ts(5), newThreshold: ts(6), ms: deleted, exp: false},
+		// The range was written to since the deletion.
+		{hint: ts(5), gcThreshold: ts(1), newThreshold: ts(6), ms: live, exp: false},
+		{hint: ts(5), gcThreshold: ts(1), newThreshold: ts(6), ms: intents, exp: false},
+		// Nothing to clear.
+		{hint: ts(5), gcThreshold: ts(1), newThreshold: ts(6), ms: enginepb.MVCCStats{}, exp: false},
+	} {
+		hint := roachpb.GCHint{LatestRangeDeleteTimestamp: c.hint}
+		if act := gcHintAllowsClearRange(hint, c.gcThreshold, c.newThreshold, c.ms); act != c.exp {
+			t.Errorf("%d: expected %t, got %t", i, c.exp, act)
+		}
+	}
+}
+
 func TestGCQueueMakeGCScoreInvariantQuick(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -18,6 +18,7 @@ package intentresolver
 import (
 	"container/list"
 	"context"
+	"sort"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -26,18 +27,21 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 type pusher struct {
-	txn      *roachpb.Transaction
-	waitCh   chan *enginepb.TxnMeta
-	detectCh chan struct{}
+	txn       *roachpb.Transaction
+	waitCh    chan *enginepb.TxnMeta
+	detectCh  chan struct{}
+	waitStart time.Time
 }
 
 func newPusher(txn *roachpb.Transaction) *pusher {
 	p := &pusher{
-		txn:    txn,
-		waitCh: make(chan *enginepb.TxnMeta, 1),
+		txn:       txn,
+		waitCh:    make(chan *enginepb.TxnMeta, 1),
+		waitStart: timeutil.Now(),
 	}
 	if p.activeTxn() {
 		p.detectCh = make(chan struct{}, 1)
@@ -118,6 +122,52 @@ func (cq *contentionQueue) numContended(key roachpb.Key) int {
 	return ck.ll.Len()
 }
 
+// ContendedKey describes a key with a contended intent, i.e. an intent on
+// which one or more requests are queued in the contention queue.
+type ContendedKey struct {
+	Key roachpb.Key
+	// Holder is the most recent transaction known to own the intent.
+	Holder enginepb.TxnMeta
+	// Waiters are the requests queued on the key, in queue order. The first
+	// waiter is the one pushing the holder; the others wait for it.
+	Waiters []ContentionWaiter
+}
+
+// ContentionWaiter describes a request queued on a contended key.
+type ContentionWaiter struct {
+	// Txn is the transaction of the request, or nil if the request is not
+	// transactional.
+	Txn *enginepb.TxnMeta
+	// WaitStart is the time at which the request entered the queue.
+	WaitStart time.Time
+}
+
+// contendedKeys returns a snapshot of the contended keys, ordered by key.
+func (cq *contentionQueue) contendedKeys() []ContendedKey {
+	cq.mu.Lock()
+	defer cq.mu.Unlock()
+	res := make([]ContendedKey, 0, len(cq.mu.keys))
+	for key, contended := range cq.mu.keys {
+		ck := ContendedKey{
+			Key:     roachpb.Key(key),
+			Holder:  *contended.lastTxnMeta,
+			Waiters: make([]ContentionWaiter, 0, contended.ll.Len()),
+		}
+		for e := contended.ll.Front(); e != nil; e = e.Next() {
+			p := e.Value.(*pusher)
+			w := ContentionWaiter{WaitStart: p.waitStart}
+			if p.txn != nil {
+				txnMeta := p.txn.TxnMeta
+				w.Txn = &txnMeta
+			}
+			ck.Waiters = append(ck.Waiters, w)
+		}
+		res = append(res, ck)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Key.Compare(res[j].Key) < 0 })
+	return res
+}
+
 func newContentionQueue(clock *hlc.Clock, db *client.DB) *contentionQueue {
 	cq := &contentionQueue{
 		clock: clock,
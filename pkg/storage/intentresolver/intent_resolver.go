@@ -68,6 +68,25 @@ const (
 	// TODO(ajwerner): justify this value
 	intentResolverBatchSize = 100
 
+	// intentResolverRangeBatchSize is the maximum number of ranged intent
+	// resolutions that will be sent in a single batch. It is lower than
+	// intentResolverBatchSize since each of them may touch many keys.
+	intentResolverRangeBatchSize = 10
+
+	// intentResolverRangeRequestSize is the maximum number of intents that a
+	// batch of ranged intent resolutions will resolve. The ranged resolutions
+	// which are cut short by this limit are resumed in a later batch, so that a
+	// span holding millions of intents is resolved incrementally, without
+	// risking to hit intentResolverTimeout.
+	intentResolverRangeRequestSize = 200
+
+	// intentResolverPageSize is the maximum number of intents whose
+	// resolution requests are in flight at the same time for a call to
+	// ResolveIntents. The intents of larger calls are resolved one page after
+	// the other, which bounds the memory used by the requests and applies
+	// backpressure to the callers which resolve many intents at once.
+	intentResolverPageSize = 1000
+
 	// cleanupIntentsTxnsPerBatch is the number of transactions whose
 	// corresponding intents will be resolved at a time. Intents are batched
 	// by transaction to avoid timeouts while resolving intents and ensure that
@@ -127,8 +146,9 @@ type IntentResolver struct {
 
 	rdc kvbase.RangeDescriptorCache
 
-	gcBatcher *requestbatcher.RequestBatcher
-	irBatcher *requestbatcher.RequestBatcher
+	gcBatcher      *requestbatcher.RequestBatcher
+	irBatcher      *requestbatcher.RequestBatcher
+	irRangeBatcher *requestbatcher.RequestBatcher
 
 	mu struct {
 		syncutil.Mutex
@@ -212,6 +232,15 @@ func New(c Config) *IntentResolver {
 		Stopper:         c.Stopper,
 		Sender:          c.DB.NonTransactionalSender(),
 	})
+	ir.irRangeBatcher = requestbatcher.New(requestbatcher.Config{
+		Name:               "intent_resolver_ir_range_batcher",
+		MaxMsgsPerBatch:    intentResolverRangeBatchSize,
+		MaxKeysPerBatchReq: intentResolverRangeRequestSize,
+		MaxWait:            c.MaxIntentResolutionBatchWait,
+		MaxIdle:            c.MaxIntentResolutionBatchIdle,
+		Stopper:            c.Stopper,
+		Sender:             c.DB.NonTransactionalSender(),
+	})
 	return ir
 }
 
@@ -221,6 +250,13 @@ func (ir *IntentResolver) NumContended(key roachpb.Key) int {
 	return ir.contentionQ.numContended(key)
 }
 
+// ContendedKeys returns the keys on which requests are currently waiting for
+// a conflicting intent to be resolved, along with the transactions owning
+// the intents and the waiting requests.
+func (ir *IntentResolver) ContendedKeys() []ContendedKey {
+	return ir.contentionQ.contendedKeys()
+}
+
 // ProcessWriteIntentError tries to push the conflicting
 // transaction(s) responsible for the given WriteIntentError, and to
 // resolve those intents if possible. Returns a cleanup function and
@@ -246,9 +282,11 @@ func (ir *IntentResolver) ProcessWriteIntentError(
 	}
 
 	// Possibly queue this processing if the write intent error is for a
-	// single intent affecting a unitary key.
+	// single intent affecting a unitary key. Touch pushes never wait, so
+	// they don't need to be queued.
 	var cleanup func(*roachpb.WriteIntentError, *enginepb.TxnMeta)
-	if len(wiErr.Intents) == 1 && len(wiErr.Intents[0].Span.EndKey) == 0 {
+	if len(wiErr.Intents) == 1 && len(wiErr.Intents[0].Span.EndKey) == 0 &&
+		pushType != roachpb.PUSH_TOUCH {
 		var done bool
 		// Note that the write intent error may be mutated here in the event
 		// that this pusher is queued to wait for a different transaction
@@ -262,6 +300,11 @@ func (ir *IntentResolver) ProcessWriteIntentError(
 		ctx, wiErr.Intents, h, pushType, false, /* skipIfInFlight */
 	)
 	if pErr != nil {
+		if _, ok := pErr.GetDetail().(*roachpb.TransactionPushError); ok && pushType == roachpb.PUSH_TOUCH {
+			// A conflicting transaction is still live, which is reported to the
+			// caller through the original error.
+			return cleanup, wiPErr
+		}
 		return cleanup, pErr
 	}
 
@@ -877,43 +920,68 @@ func (ir *IntentResolver) ResolveIntents(
 	log.Eventf(ctx, "resolving intents [wait=%t]", opts.Wait)
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	type resolveReq struct {
-		rangeID roachpb.RangeID
-		req     roachpb.Request
+	for len(intents) > 0 {
+		page := intents
+		if len(page) > intentResolverPageSize {
+			page = page[:intentResolverPageSize]
+		}
+		if err := ir.resolveIntentsPage(ctx, page, opts); err != nil {
+			return err
+		}
+		intents = intents[len(page):]
 	}
-	var resolveReqs []resolveReq
-	var resolveRangeReqs []roachpb.Request
+	return nil
+}
+
+// resolveIntentsPage resolves a page of the intents of ResolveIntents. The
+// point intents are resolved through irBatcher and the intent ranges through
+// irRangeBatcher, all of them concurrently.
+func (ir *IntentResolver) resolveIntentsPage(
+	ctx context.Context, intents []roachpb.Intent, opts ResolveOptions,
+) error {
+	// rangeReq is a ranged intent resolution, which is resent with its resume
+	// span until it is complete.
+	type rangeReq struct {
+		rangeID  roachpb.RangeID
+		req      *roachpb.ResolveIntentRangeRequest
+		respChan chan requestbatcher.Response
+	}
+	var rangeReqs []rangeReq
+	numPointReqs := 0
+	respChan := make(chan requestbatcher.Response, len(intents))
 	for i := range intents {
 		intent := intents[i] // avoids a race in `i, intent := range ...`
+		rangeID := ir.lookupRangeID(ctx, intent.Key)
 		if len(intent.EndKey) == 0 {
-			resolveReqs = append(resolveReqs,
-				resolveReq{
-					rangeID: ir.lookupRangeID(ctx, intent.Key),
-					req: &roachpb.ResolveIntentRequest{
-						RequestHeader: roachpb.RequestHeaderFromSpan(intent.Span),
-						IntentTxn:     intent.Txn,
-						Status:        intent.Status,
-						Poison:        opts.Poison,
-					},
-				})
-		} else {
-			resolveRangeReqs = append(resolveRangeReqs, &roachpb.ResolveIntentRangeRequest{
+			if err := ir.irBatcher.SendWithChan(ctx, respChan, rangeID, &roachpb.ResolveIntentRequest{
 				RequestHeader: roachpb.RequestHeaderFromSpan(intent.Span),
 				IntentTxn:     intent.Txn,
 				Status:        intent.Status,
 				Poison:        opts.Poison,
-				MinTimestamp:  opts.MinTimestamp,
-			})
+			}); err != nil {
+				return err
+			}
+			numPointReqs++
+		} else {
+			r := rangeReq{
+				rangeID: rangeID,
+				req: &roachpb.ResolveIntentRangeRequest{
+					RequestHeader: roachpb.RequestHeaderFromSpan(intent.Span),
+					IntentTxn:     intent.Txn,
+					Status:        intent.Status,
+					Poison:        opts.Poison,
+					MinTimestamp:  opts.MinTimestamp,
+				},
+				respChan: make(chan requestbatcher.Response, 1),
+			}
+			if err := ir.irRangeBatcher.SendWithChan(ctx, r.respChan, r.rangeID, r.req); err != nil {
+				return err
+			}
+			rangeReqs = append(rangeReqs, r)
 		}
 	}
 
-	respChan := make(chan requestbatcher.Response, len(resolveReqs))
-	for _, req := range resolveReqs {
-		if err := ir.irBatcher.SendWithChan(ctx, respChan, req.rangeID, req.req); err != nil {
-			return err
-		}
-	}
-	for seen := 0; seen < len(resolveReqs); seen++ {
+	for seen := 0; seen < numPointReqs; seen++ {
 		select {
 		case resp := <-respChan:
 			if resp.Err != nil {
@@ -925,31 +993,43 @@ func (ir *IntentResolver) ResolveIntents(
 		}
 	}
 
-	// Resolve spans differently. We don't know how many intents will be
-	// swept up with each request, so we limit the spanning resolve
-	// requests to a maximum number of keys and resume as necessary.
-	for _, req := range resolveRangeReqs {
-		for {
-			b := &client.Batch{}
-			b.Header.MaxSpanRequestKeys = intentResolverBatchSize
-			b.AddRawRequest(req)
-			if err := contextutil.RunWithTimeout(ctx, "resolve span intents", intentResolverTimeout,
-				func(ctx context.Context) error {
-					return ir.db.Run(ctx, b)
-				}); err != nil {
-				return err
-			}
-			// Check response to see if it must be resumed.
-			resp := b.RawResponse().Responses[0].GetInner().(*roachpb.ResolveIntentRangeResponse)
-			if resp.ResumeSpan == nil {
-				break
-			}
-			reqCopy := *(req.(*roachpb.ResolveIntentRangeRequest))
-			reqCopy.SetSpan(*resp.ResumeSpan)
-			req = &reqCopy
+	// We don't know how many intents will be swept up by each ranged
+	// resolution, so they are limited to a maximum number of keys per batch
+	// and resumed as necessary. Each round, which waits for the responses of
+	// the requests in flight and resends the incomplete ones, is bounded by
+	// intentResolverTimeout.
+	for len(rangeReqs) > 0 {
+		var resumed []rangeReq
+		if err := contextutil.RunWithTimeout(ctx, "resolve span intents", intentResolverTimeout,
+			func(ctx context.Context) error {
+				for _, r := range rangeReqs {
+					var resp requestbatcher.Response
+					select {
+					case resp = <-r.respChan:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+					if resp.Err != nil {
+						return resp.Err
+					}
+					resumeSpan := resp.Resp.(*roachpb.ResolveIntentRangeResponse).ResumeSpan
+					if resumeSpan == nil {
+						continue
+					}
+					reqCopy := *r.req
+					reqCopy.SetSpan(*resumeSpan)
+					r.req = &reqCopy
+					if err := ir.irRangeBatcher.SendWithChan(ctx, r.respChan, r.rangeID, r.req); err != nil {
+						return err
+					}
+					resumed = append(resumed, r)
+				}
+				return nil
+			}); err != nil {
+			return err
 		}
+		rangeReqs = resumed
 	}
-
 	return nil
 }
 
@@ -446,6 +446,21 @@ func TestContendedIntent(t *testing.T) {
 				if lc, let := ir.NumContended(keyA), len(tc.expTxns); lc != let {
 					return errors.Errorf("expected len %d; got %d", let, lc)
 				}
+				contendedKeys := ir.ContendedKeys()
+				if len(contendedKeys) != 1 || !contendedKeys[0].Key.Equal(keyA) {
+					return errors.Errorf("expected only %s to be contended; got %v", keyA, contendedKeys)
+				}
+				if holder := contendedKeys[0].Holder.ID; holder != origTxn.ID {
+					return errors.Errorf("expected holder %s; got %s", origTxn.ID, holder)
+				}
+				if lw, let := len(contendedKeys[0].Waiters), len(tc.expTxns); lw != let {
+					return errors.Errorf("expected %d waiters; got %d", let, lw)
+				}
+				for idx, w := range contendedKeys[0].Waiters {
+					if w.Txn == nil || w.Txn.ID != tc.expTxns[idx].ID {
+						return errors.Errorf("expected waiter %s at index %d; got %v", tc.expTxns[idx], idx, w.Txn)
+					}
+				}
 				ir.contentionQ.mu.Lock()
 				defer ir.contentionQ.mu.Unlock()
 				contended, ok := ir.contentionQ.mu.keys[string(keyA)]
@@ -825,6 +840,59 @@ func TestCleanupIntents(t *testing.T) {
 	}
 }
 
+// TestResolveIntentsRangeResume verifies that the ranged intent resolutions
+// are limited in the number of keys they resolve per batch, and resumed until
+// they are complete.
+func TestResolveIntentsRangeResume(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+	stopper := stop.NewStopper()
+	defer stopper.Stop(ctx)
+	clock := hlc.NewClock(hlc.UnixNano, time.Nanosecond)
+	txn := newTransaction("txn", roachpb.Key("a"), 1, clock)
+	txn.Status = roachpb.COMMITTED
+
+	span := roachpb.Span{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")}
+	resumeSpans := []roachpb.Span{
+		{Key: roachpb.Key("f"), EndKey: roachpb.Key("z")},
+		{Key: roachpb.Key("p"), EndKey: roachpb.Key("z")},
+	}
+	var sent []roachpb.Span
+	resolveRangeSendFunc := func(resumeSpan *roachpb.Span) sendFunc {
+		return func(ba roachpb.BatchRequest) (*roachpb.BatchResponse, *roachpb.Error) {
+			if ba.MaxSpanRequestKeys != intentResolverRangeRequestSize {
+				t.Errorf("expected MaxSpanRequestKeys %d, got %d",
+					intentResolverRangeRequestSize, ba.MaxSpanRequestKeys)
+			}
+			resp := &roachpb.BatchResponse{}
+			for _, r := range ba.Requests {
+				req, ok := r.GetInner().(*roachpb.ResolveIntentRangeRequest)
+				if !ok {
+					t.Errorf("unexpected request type %T, expected ResolveIntentRangeRequest", r.GetInner())
+					continue
+				}
+				sent = append(sent, req.Span())
+				rangeResp := &roachpb.ResolveIntentRangeResponse{}
+				rangeResp.ResumeSpan = resumeSpan
+				resp.Add(rangeResp)
+			}
+			return resp, nil
+		}
+	}
+	sf := newSendFuncs(t,
+		resolveRangeSendFunc(&resumeSpans[0]),
+		resolveRangeSendFunc(&resumeSpans[1]),
+		resolveRangeSendFunc(nil),
+	)
+	ir := newIntentResolverWithSendFuncs(Config{Stopper: stopper, Clock: clock}, sf)
+	intents := []roachpb.Intent{{Span: span, Txn: txn.TxnMeta, Status: txn.Status}}
+	if err := ir.ResolveIntents(ctx, intents, ResolveOptions{Wait: true}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []roachpb.Span{span, resumeSpans[0], resumeSpans[1]}, sent)
+	assert.Equal(t, 0, sf.len())
+}
+
 func newTransaction(
 	name string, baseKey roachpb.Key, userPriority roachpb.UserPriority, clock *hlc.Clock,
 ) *roachpb.Transaction {
@@ -895,6 +895,18 @@ var (
 		Measurement: "Intent Resolutions",
 		Unit:        metric.Unit_COUNT,
 	}
+	metaGCClearRangeSuccess = metric.Metadata{
+		Name:        "queue.gc.info.clearrangesuccess",
+		Help:        "Number of replicas whose data was entirely removed with a range deletion thanks to their GC hint",
+		Measurement: "Replicas",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaGCClearRangeFailed = metric.Metadata{
+		Name:        "queue.gc.info.clearrangefailed",
+		Help:        "Number of replicas with a GC hint which could not be cleared with a range deletion",
+		Measurement: "Replicas",
+		Unit:        metric.Unit_COUNT,
+	}
 
 	// Slow request metrics.
 	metaLatchRequests = metric.Metadata{
@@ -1143,6 +1155,8 @@ type StoreMetrics struct {
 	GCPushTxn                    *metric.Counter
 	GCResolveTotal               *metric.Counter
 	GCResolveSuccess             *metric.Counter
+	GCClearRangeSuccess          *metric.Counter
+	GCClearRangeFailed           *metric.Counter
 
 	// Slow request counts.
 	SlowLatchRequests *metric.Gauge
@@ -1350,6 +1364,8 @@ func newStoreMetrics(histogramWindow time.Duration) *StoreMetrics {
 		GCPushTxn:                    metric.NewCounter(metaGCPushTxn),
 		GCResolveTotal:               metric.NewCounter(metaGCResolveTotal),
 		GCResolveSuccess:             metric.NewCounter(metaGCResolveSuccess),
+		GCClearRangeSuccess:          metric.NewCounter(metaGCClearRangeSuccess),
+		GCClearRangeFailed:           metric.NewCounter(metaGCClearRangeFailed),
 
 		// Wedge request counters.
 		SlowLatchRequests: metric.NewGauge(metaLatchRequests),
@@ -870,6 +870,8 @@ func (r *Replica) State() storagepb.RangeInfo {
 	// this first before RLocking below. Performance of this extra lock
 	// acquisition is not a concern.
 	ri.ActiveClosedTimestamp = r.maxClosed(context.Background())
+	// Ditto.
+	ri.GCInfo = makeRangeGCInfo(context.Background(), r, r.store.Clock().Now())
 
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -495,6 +495,24 @@ func (rsl StateLoader) SetTxnSpanGCThreshold(
 
 // The rest is not technically part of ReplicaState.
 
+// LoadGCHint loads the GC hint.
+func (rsl StateLoader) LoadGCHint(
+	ctx context.Context, reader engine.Reader,
+) (*roachpb.GCHint, error) {
+	var h roachpb.GCHint
+	_, err := engine.MVCCGetProto(ctx, reader, rsl.RangeGCHintKey(),
+		hlc.Timestamp{}, &h, engine.MVCCGetOptions{})
+	return &h, err
+}
+
+// SetGCHint overwrites the GC hint.
+func (rsl StateLoader) SetGCHint(
+	ctx context.Context, eng engine.ReadWriter, ms *enginepb.MVCCStats, hint *roachpb.GCHint,
+) error {
+	return engine.MVCCPutProto(ctx, eng, ms,
+		rsl.RangeGCHintKey(), hlc.Timestamp{}, nil, hint)
+}
+
 // LoadLastIndex loads the last index.
 func (rsl StateLoader) LoadLastIndex(ctx context.Context, reader engine.Reader) (uint64, error) {
 	prefix := rsl.RaftLogPrefix()
@@ -401,6 +401,7 @@ type Store struct {
 	raftEntryCache     *raftentry.Cache
 	limiters           batcheval.Limiters
 	txnWaitMetrics     *txnwait.Metrics
+	txnWaitDeadlocks   *txnwait.DeadlockLog
 
 	// gossipRangeCountdown and leaseRangeCountdown are countdowns of
 	// changes to range and leaseholder counts, after which the store
@@ -821,6 +822,7 @@ func NewStore(
 
 	s.txnWaitMetrics = txnwait.NewMetrics(cfg.HistogramWindowInterval)
 	s.metrics.registry.AddMetricStruct(s.txnWaitMetrics)
+	s.txnWaitDeadlocks = txnwait.NewDeadlockLog()
 
 	s.compactor = compactor.NewCompactor(
 		s.cfg.Settings,
@@ -2022,6 +2024,9 @@ func (s *Store) Gossip() *gossip.Gossip { return s.cfg.Gossip }
 // Compactor accessor.
 func (s *Store) Compactor() *compactor.Compactor { return s.compactor }
 
+// IntentResolver accessor.
+func (s *Store) IntentResolver() *intentresolver.IntentResolver { return s.intentResolver }
+
 // Stopper accessor.
 func (s *Store) Stopper() *stop.Stopper { return s.stopper }
 
@@ -3000,6 +3005,11 @@ func (s *Store) Send(
 				} else {
 					pushType = roachpb.PUSH_TIMESTAMP
 				}
+				if ba.WaitPolicy == roachpb.WaitPolicy_ERROR {
+					// Don't wait on the conflicting transactions. Only clean up the
+					// intents of abandoned ones and return the error otherwise.
+					pushType = roachpb.PUSH_TOUCH
+				}
 
 				index := pErr.Index
 				args := ba.Requests[index.Index].GetInner()
@@ -4398,6 +4408,12 @@ func (s *Store) GetTxnWaitMetrics() *txnwait.Metrics {
 	return s.txnWaitMetrics
 }
 
+// GetTxnWaitDeadlocks is called by txnwait.Queue instances to get a reference
+// to the log of the deadlocks detected on the store.
+func (s *Store) GetTxnWaitDeadlocks() *txnwait.DeadlockLog {
+	return s.txnWaitDeadlocks
+}
+
 func init() {
 	tracing.RegisterTagRemapping("s", "store")
 }
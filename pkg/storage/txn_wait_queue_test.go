@@ -729,6 +729,21 @@ func TestTxnWaitQueueDependencyCycle(t *testing.T) {
 		}
 		return nil
 	})
+
+	// The deadlock was recorded, along with the push which broke it.
+	deadlocks := tc.store.GetTxnWaitDeadlocks().Deadlocks()
+	if len(deadlocks) == 0 {
+		t.Errorf("expected the deadlock to be recorded")
+	}
+	for _, d := range deadlocks {
+		var found bool
+		for _, req := range []*roachpb.PushTxnRequest{reqA, reqB, reqC} {
+			found = found || (d.Pusher.ID == req.PusherTxn.ID && d.Victim.ID == req.PusheeTxn.ID)
+		}
+		if !found || d.Policy != txnwait.DeadlockVictimPriority {
+			t.Errorf("unexpected deadlock %+v", d)
+		}
+	}
 	cancel()
 	for i := 0; i < 2; i++ {
 		<-retCh
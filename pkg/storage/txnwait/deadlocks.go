@@ -0,0 +1,155 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package txnwait
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+)
+
+// DeadlockVictimPolicy controls which transaction of a dependency cycle is
+// aborted to break the deadlock.
+type DeadlockVictimPolicy int64
+
+const (
+	// DeadlockVictimPriority aborts the transaction with the lowest priority.
+	DeadlockVictimPriority DeadlockVictimPolicy = iota
+	// DeadlockVictimYoungest aborts the transaction which started last.
+	DeadlockVictimYoungest
+	// DeadlockVictimLeastWork aborts the transaction which performed the
+	// fewest writes.
+	DeadlockVictimLeastWork
+)
+
+var deadlockVictimPolicyNames = map[int64]string{
+	int64(DeadlockVictimPriority):  "priority",
+	int64(DeadlockVictimYoungest):  "youngest",
+	int64(DeadlockVictimLeastWork): "least_work",
+}
+
+func (p DeadlockVictimPolicy) String() string {
+	return deadlockVictimPolicyNames[int64(p)]
+}
+
+// deadlockVictimPolicy is the policy used to break deadlocks. Whatever the
+// policy, ties are broken by priority and then by transaction ID.
+var deadlockVictimPolicy = settings.RegisterEnumSetting(
+	"kv.txn_wait_queue.deadlock_victim_policy",
+	"the transaction aborted to break a deadlock: the lowest priority one, the youngest one "+
+		"or the one which performed the least work",
+	"priority",
+	deadlockVictimPolicyNames,
+)
+
+// deadlockParticipant holds the attributes of a transaction of a dependency
+// cycle which are used to select the victim.
+type deadlockParticipant struct {
+	id       uuid.UUID
+	priority enginepb.TxnPriority
+	// origTimestamp is the original timestamp found in the transaction record.
+	origTimestamp hlc.Timestamp
+	// sequence approximates the number of writes performed by the
+	// transaction.
+	sequence enginepb.TxnSeq
+}
+
+// isDeadlockVictim returns whether the pushee is to be aborted to break the
+// deadlock between it and the pusher.
+//
+// Every push of the cycle makes this decision independently, so the order
+// has to be consistent across them lest no one break the deadlock. The
+// original timestamps come from the transaction records, which are seen
+// identically by all the pushes. The sequence of the pusher is its current
+// one, while the sequence of the pushee is that of one of its intents or of
+// its record, i.e. a lower bound of its current one; so when two pushes see
+// each other's sequences differently, they can both decide to abort their
+// pushee but never both decide to keep waiting.
+func isDeadlockVictim(policy DeadlockVictimPolicy, pusher, pushee deadlockParticipant) bool {
+	switch policy {
+	case DeadlockVictimYoungest:
+		if pushee.origTimestamp != pusher.origTimestamp {
+			return pusher.origTimestamp.Less(pushee.origTimestamp)
+		}
+	case DeadlockVictimLeastWork:
+		if pushee.sequence != pusher.sequence {
+			return pushee.sequence < pusher.sequence
+		}
+	}
+	if pushee.priority != pusher.priority {
+		return pushee.priority < pusher.priority
+	}
+	return bytes.Compare(pushee.id.GetBytes(), pusher.id.GetBytes()) < 0
+}
+
+// Deadlock describes a dependency cycle detected by a Queue, which was broken
+// by aborting the pushee of one of the pushes of the cycle.
+type Deadlock struct {
+	// Time at which the deadlock was detected.
+	Time time.Time
+	// Policy is the victim selection policy which was in effect.
+	Policy DeadlockVictimPolicy
+	// Pusher is the transaction whose push detected the deadlock.
+	Pusher enginepb.TxnMeta
+	// Victim is the transaction pushed by Pusher, which was aborted.
+	Victim enginepb.TxnMeta
+	// Dependents are the transactions known to be transitively waiting on
+	// Pusher. They include the other transactions of the cycle.
+	Dependents []uuid.UUID
+}
+
+// deadlockLogSize is the number of deadlocks kept by a DeadlockLog.
+const deadlockLogSize = 100
+
+// DeadlockLog keeps the most recent deadlocks detected on a store.
+//
+// DeadlockLog is thread safe.
+type DeadlockLog struct {
+	mu struct {
+		syncutil.Mutex
+		deadlocks []Deadlock
+	}
+}
+
+// NewDeadlockLog creates an empty DeadlockLog.
+func NewDeadlockLog() *DeadlockLog {
+	return &DeadlockLog{}
+}
+
+// record adds a deadlock to the log, evicting the oldest one if the log is
+// full. Recording to a nil log is a no-op.
+func (l *DeadlockLog) record(d Deadlock) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.mu.deadlocks) == deadlockLogSize {
+		l.mu.deadlocks = append(l.mu.deadlocks[:0], l.mu.deadlocks[1:]...)
+	}
+	l.mu.deadlocks = append(l.mu.deadlocks, d)
+}
+
+// Deadlocks returns the recorded deadlocks, oldest first.
+func (l *DeadlockLog) Deadlocks() []Deadlock {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Deadlock(nil), l.mu.deadlocks...)
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 func TestShouldPushImmediately(t *testing.T) {
@@ -129,6 +130,71 @@ func TestIsPushed(t *testing.T) {
 	}
 }
 
+func TestIsDeadlockVictim(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	participant := func(id byte, pri enginepb.TxnPriority, ts int64, seq enginepb.TxnSeq) deadlockParticipant {
+		return deadlockParticipant{
+			id:            uuid.UUID{id},
+			priority:      pri,
+			origTimestamp: makeTS(ts, 0),
+			sequence:      seq,
+		}
+	}
+	testCases := []struct {
+		policy   DeadlockVictimPolicy
+		pusher   deadlockParticipant
+		pushee   deadlockParticipant
+		isVictim bool
+	}{
+		{DeadlockVictimPriority, participant(1, 2, 1, 1), participant(2, 1, 2, 2), true},
+		{DeadlockVictimPriority, participant(1, 1, 2, 2), participant(2, 2, 1, 1), false},
+		{DeadlockVictimPriority, participant(2, 1, 1, 1), participant(1, 1, 2, 2), true},
+		{DeadlockVictimPriority, participant(1, 1, 1, 1), participant(2, 1, 2, 2), false},
+		{DeadlockVictimYoungest, participant(1, 2, 1, 1), participant(2, 1, 2, 2), true},
+		{DeadlockVictimYoungest, participant(1, 1, 2, 2), participant(2, 2, 1, 1), false},
+		{DeadlockVictimYoungest, participant(1, 1, 1, 1), participant(2, 2, 2, 2), true},
+		{DeadlockVictimYoungest, participant(1, 1, 2, 1), participant(2, 2, 1, 2), false},
+		{DeadlockVictimYoungest, participant(1, 2, 1, 1), participant(2, 1, 1, 2), true},
+		{DeadlockVictimLeastWork, participant(1, 2, 1, 2), participant(2, 1, 2, 1), true},
+		{DeadlockVictimLeastWork, participant(1, 1, 2, 1), participant(2, 2, 1, 2), false},
+		{DeadlockVictimLeastWork, participant(1, 1, 1, 2), participant(2, 2, 2, 1), true},
+		{DeadlockVictimLeastWork, participant(1, 2, 2, 1), participant(2, 1, 1, 2), false},
+		{DeadlockVictimLeastWork, participant(2, 1, 1, 1), participant(1, 1, 1, 1), true},
+	}
+	for _, test := range testCases {
+		t.Run(test.policy.String(), func(t *testing.T) {
+			if isVictim := isDeadlockVictim(test.policy, test.pusher, test.pushee); isVictim != test.isVictim {
+				t.Errorf("expected %t; got %t", test.isVictim, isVictim)
+			}
+			// The pushes of the cycle must agree on the victim.
+			if isVictim := isDeadlockVictim(test.policy, test.pushee, test.pusher); isVictim == test.isVictim {
+				t.Errorf("expected %t with swapped roles; got %t", !test.isVictim, isVictim)
+			}
+		})
+	}
+}
+
+func TestDeadlockLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	// Recording to a nil log is allowed.
+	var nilLog *DeadlockLog
+	nilLog.record(Deadlock{})
+
+	l := NewDeadlockLog()
+	for i := 0; i < deadlockLogSize+10; i++ {
+		l.record(Deadlock{Pusher: enginepb.TxnMeta{Sequence: enginepb.TxnSeq(i)}})
+	}
+	deadlocks := l.Deadlocks()
+	if len(deadlocks) != deadlockLogSize {
+		t.Fatalf("expected %d deadlocks, got %d", deadlockLogSize, len(deadlocks))
+	}
+	for i, d := range deadlocks {
+		if expected := enginepb.TxnSeq(i + 10); d.Pusher.Sequence != expected {
+			t.Errorf("%d: expected deadlock %d, got %d", i, expected, d.Pusher.Sequence)
+		}
+	}
+}
+
 type mockRepl struct{}
 
 func (mockRepl) ContainsKey(_ roachpb.Key) bool { return true }
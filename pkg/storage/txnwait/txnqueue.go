@@ -15,7 +15,6 @@
 package txnwait
 
 import (
-	"bytes"
 	"context"
 	"sync/atomic"
 	"time"
@@ -23,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -136,8 +136,10 @@ type StoreInterface interface {
 	Clock() *hlc.Clock
 	Stopper() *stop.Stopper
 	DB() *client.DB
+	ClusterSettings() *cluster.Settings
 	GetTxnWaitKnobs() TestingKnobs
 	GetTxnWaitMetrics() *Metrics
+	GetTxnWaitDeadlocks() *DeadlockLog
 }
 
 // ReplicaInterface provides some parts of a Replica without incurring a dependency.
@@ -387,7 +389,8 @@ var ErrDeadlock = roachpb.NewErrorf("deadlock detected")
 // the first return value is a non-nil PushTxnResponse object.
 //
 // In the event of a dependency cycle of pushers leading to deadlock,
-// this method will return an ErrDeadlock error.
+// this method will return an ErrDeadlock error if the pushee is the
+// victim selected by the deadlock victim policy.
 func (q *Queue) MaybeWaitForPush(
 	ctx context.Context, repl ReplicaInterface, req *roachpb.PushTxnRequest,
 ) (*roachpb.PushTxnResponse, *roachpb.Error) {
@@ -582,8 +585,10 @@ func (q *Queue) MaybeWaitForPush(
 			push.mu.Lock()
 			_, haveDependency := push.mu.dependents[req.PusheeTxn.ID]
 			dependents := make([]string, 0, len(push.mu.dependents))
+			dependentIDs := make([]uuid.UUID, 0, len(push.mu.dependents))
 			for id := range push.mu.dependents {
 				dependents = append(dependents, id.Short())
+				dependentIDs = append(dependentIDs, id)
 			}
 			log.VEventf(
 				ctx,
@@ -604,17 +609,44 @@ func (q *Queue) MaybeWaitForPush(
 			q.mu.Unlock()
 
 			if haveDependency {
-				// Break the deadlock if the pusher has higher priority.
-				p1, p2 := pusheePriority, pusherPriority
-				if p1 < p2 || (p1 == p2 && bytes.Compare(req.PusheeTxn.ID.GetBytes(), req.PusherTxn.ID.GetBytes()) < 0) {
-					log.VEventf(
+				// Break the deadlock if the pushee is the victim chosen by the
+				// policy.
+				policy := DeadlockVictimPolicy(deadlockVictimPolicy.Get(&q.store.ClusterSettings().SV))
+				pushee := pending.getTxn()
+				pusheeSeq := req.PusheeTxn.Sequence
+				if pushee.Sequence > pusheeSeq {
+					pusheeSeq = pushee.Sequence
+				}
+				if isDeadlockVictim(
+					policy,
+					deadlockParticipant{
+						id:            req.PusherTxn.ID,
+						priority:      pusherPriority,
+						origTimestamp: updatedPusher.OrigTimestamp,
+						sequence:      req.PusherTxn.Sequence,
+					},
+					deadlockParticipant{
+						id:            req.PusheeTxn.ID,
+						priority:      pusheePriority,
+						origTimestamp: pushee.OrigTimestamp,
+						sequence:      pusheeSeq,
+					},
+				) {
+					log.Infof(
 						ctx,
-						1,
-						"%s breaking deadlock by force push of %s; dependencies=%s",
+						"%s breaking deadlock by force push of %s (victim policy %s); dependencies=%s",
 						req.PusherTxn.ID.Short(),
 						req.PusheeTxn.ID.Short(),
+						policy,
 						dependents,
 					)
+					q.store.GetTxnWaitDeadlocks().record(Deadlock{
+						Time:       timeutil.Now(),
+						Policy:     policy,
+						Pusher:     req.PusherTxn.TxnMeta,
+						Victim:     req.PusheeTxn,
+						Dependents: dependentIDs,
+					})
 					metrics.DeadlocksTotal.Inc(1)
 					return nil, ErrDeadlock
 				}
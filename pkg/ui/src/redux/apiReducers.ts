@@ -257,6 +257,13 @@ const dataDistributionReducerObj = new CachedDataReducer(
 );
 export const refreshDataDistribution = dataDistributionReducerObj.refresh;
 
+const networkLatenciesReducerObj = new CachedDataReducer(
+  api.getNetworkLatencies,
+  "networkLatencies",
+  moment.duration(10, "s"),
+);
+export const refreshNetworkLatencies = networkLatenciesReducerObj.refresh;
+
 export interface APIReducersState {
   cluster: CachedDataReducerState<api.ClusterResponseMessage>;
   events: CachedDataReducerState<api.EventsResponseMessage>;
@@ -283,6 +290,7 @@ export interface APIReducersState {
   stores: KeyedCachedDataReducerState<api.StoresResponseMessage>;
   statements: CachedDataReducerState<api.StatementsResponseMessage>;
   dataDistribution: CachedDataReducerState<api.DataDistributionResponseMessage>;
+  networkLatencies: CachedDataReducerState<api.NetworkLatenciesResponseMessage>;
 }
 
 export const apiReducersReducer = combineReducers<APIReducersState>({
@@ -311,6 +319,7 @@ export const apiReducersReducer = combineReducers<APIReducersState>({
   [storesReducerObj.actionNamespace]: storesReducerObj.reducer,
   [queriesReducerObj.actionNamespace]: queriesReducerObj.reducer,
   [dataDistributionReducerObj.actionNamespace]: dataDistributionReducerObj.reducer,
+  [networkLatenciesReducerObj.actionNamespace]: networkLatenciesReducerObj.reducer,
 });
 
 export { CachedDataReducerState, KeyedCachedDataReducerState };
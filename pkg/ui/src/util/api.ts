@@ -108,6 +108,8 @@ export type StatementsResponseMessage = protos.cockroach.server.serverpb.Stateme
 
 export type DataDistributionResponseMessage = protos.cockroach.server.serverpb.DataDistributionResponse;
 
+export type NetworkLatenciesResponseMessage = protos.cockroach.server.serverpb.ListNetworkLatenciesResponse;
+
 export type EnqueueRangeRequestMessage = protos.cockroach.server.serverpb.EnqueueRangeRequest;
 export type EnqueueRangeResponseMessage = protos.cockroach.server.serverpb.EnqueueRangeResponse;
 
@@ -348,6 +350,12 @@ export function getStatements(timeout?: moment.Duration): Promise<StatementsResp
   return timeoutFetch(serverpb.StatementsResponse, `${STATUS_PREFIX}/statements`, null, timeout);
 }
 
+// getNetworkLatencies returns the round-trip latencies measured by each node
+// to its peers, along with their recent history.
+export function getNetworkLatencies(timeout?: moment.Duration): Promise<NetworkLatenciesResponseMessage> {
+  return timeoutFetch(serverpb.ListNetworkLatenciesResponse, `${STATUS_PREFIX}/network_latencies`, null, timeout);
+}
+
 // getDataDistribution returns information about how replicas are distributed across nodes.
 export function getDataDistribution(timeout?: moment.Duration): Promise<DataDistributionResponseMessage> {
   return timeoutFetch(serverpb.DataDistributionResponse, `${API_PREFIX}/data_distribution`, null, timeout);
@@ -156,6 +156,7 @@ function randomStats(sensitiveInfo?: ISensitiveInfo): StatementStatistics {
   // tslint:disable:variable-name
   const first_attempt_count = randomInt(count);
   const max_retries = randomInt(count - first_attempt_count);
+  const cpu_time_count = 1 + randomInt(count);
   // tslint:enable:variable-name
 
   return {
@@ -168,6 +169,10 @@ function randomStats(sensitiveInfo?: ISensitiveInfo): StatementStatistics {
     run_lat: randomStat(),
     service_lat: randomStat(),
     overhead_lat: randomStat(),
+    bytes_read: randomStat(1000),
+    bytes_written: randomStat(1000),
+    cpu_time: randomStat(),
+    cpu_time_count: Long.fromNumber(cpu_time_count),
     sensitive_info: sensitiveInfo || makeSensitiveInfo(null, null),
   };
 }
@@ -254,6 +259,17 @@ describe("combineStatementStats", () => {
     assert.approximately(ab_c.overhead_lat.mean, bc_a.overhead_lat.mean, 0.0000001);
     assert.approximately(ab_c.overhead_lat.squared_diffs, ac_b.overhead_lat.squared_diffs, 0.0000001);
     assert.approximately(ab_c.overhead_lat.squared_diffs, bc_a.overhead_lat.squared_diffs, 0.0000001);
+
+    assert.approximately(ab_c.bytes_read.mean, ac_b.bytes_read.mean, 0.0000001);
+    assert.approximately(ab_c.bytes_read.mean, bc_a.bytes_read.mean, 0.0000001);
+    assert.approximately(ab_c.bytes_read.squared_diffs, ac_b.bytes_read.squared_diffs, 0.0000001);
+    assert.approximately(ab_c.bytes_read.squared_diffs, bc_a.bytes_read.squared_diffs, 0.0000001);
+
+    assert.equal(ab_c.cpu_time_count.toString(), ac_b.cpu_time_count.toString());
+    assert.approximately(ab_c.cpu_time.mean, ac_b.cpu_time.mean, 0.0000001);
+    assert.approximately(ab_c.cpu_time.mean, bc_a.cpu_time.mean, 0.0000001);
+    assert.approximately(ab_c.cpu_time.squared_diffs, ac_b.cpu_time.squared_diffs, 0.0000001);
+    assert.approximately(ab_c.cpu_time.squared_diffs, bc_a.cpu_time.squared_diffs, 0.0000001);
   });
 
   describe("when sensitiveInfo has data", () => {
@@ -54,6 +54,8 @@ export function addNumericStats(a: NumericStat, b: NumericStat, countA: number,
 export function addStatementStats(a: StatementStatistics, b: StatementStatistics) {
   const countA = FixLong(a.count).toInt();
   const countB = FixLong(b.count).toInt();
+  const cpuTimeCountA = FixLong(a.cpu_time_count).toInt();
+  const cpuTimeCountB = FixLong(b.cpu_time_count).toInt();
   return {
     count: a.count.add(b.count),
     first_attempt_count: a.first_attempt_count.add(b.first_attempt_count),
@@ -64,6 +66,13 @@ export function addStatementStats(a: StatementStatistics, b: StatementStatistics
     run_lat: addNumericStats(a.run_lat, b.run_lat, countA, countB),
     service_lat: addNumericStats(a.service_lat, b.service_lat, countA, countB),
     overhead_lat: addNumericStats(a.overhead_lat, b.overhead_lat, countA, countB),
+    bytes_read: addNumericStats(a.bytes_read, b.bytes_read, countA, countB),
+    bytes_written: addNumericStats(a.bytes_written, b.bytes_written, countA, countB),
+    // The CPU time is only measured for some of the executions.
+    cpu_time: cpuTimeCountA + cpuTimeCountB > 0
+      ? addNumericStats(a.cpu_time, b.cpu_time, cpuTimeCountA, cpuTimeCountB)
+      : a.cpu_time,
+    cpu_time_count: FixLong(a.cpu_time_count).add(FixLong(b.cpu_time_count)),
     sensitive_info: coalesceSensitiveInfo(a.sensitive_info, b.sensitive_info),
   };
 }
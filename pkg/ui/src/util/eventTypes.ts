@@ -24,6 +24,8 @@ type Event = protos.cockroach.server.serverpb.EventsResponse.Event;
 export const CREATE_DATABASE = "create_database";
 // Recorded when a database is dropped.
 export const DROP_DATABASE = "drop_database";
+// Recorded when a database is altered.
+export const ALTER_DATABASE = "alter_database";
 // Recorded when a table is created.
 export const CREATE_TABLE = "create_table";
 // Recorded when a table is dropped.
@@ -48,6 +50,16 @@ export const CREATE_SEQUENCE = "create_sequence";
 export const ALTER_SEQUENCE = "alter_sequence";
 // Recorded when a sequence is dropped.
 export const DROP_SEQUENCE = "drop_sequence";
+// Recorded when a trigger is created.
+export const CREATE_TRIGGER = "create_trigger";
+// Recorded when a trigger is dropped.
+export const DROP_TRIGGER = "drop_trigger";
+// Recorded when a procedure is created.
+export const CREATE_PROCEDURE = "create_procedure";
+// Recorded when a procedure is dropped.
+export const DROP_PROCEDURE = "drop_procedure";
+// Recorded when a user-defined function is created.
+export const CREATE_FUNCTION = "create_function";
 // Recorded when an in-progress schema change encounters a problem and is
 // reversed.
 export const REVERSE_SCHEMA_CHANGE = "reverse_schema_change";
@@ -71,14 +83,34 @@ export const SET_ZONE_CONFIG = "set_zone_config";
 export const REMOVE_ZONE_CONFIG = "remove_zone_config";
 // Recorded when statistics are collected for a table.
 export const CREATE_STATISTICS = "create_statistics";
+// Recorded when a user is locked out after too many failed login attempts.
+export const USER_LOGIN_LOCKED = "user_login_locked";
+// Recorded when a descriptor is written by crdb_internal.unsafe_upsert_descriptor().
+export const UNSAFE_UPSERT_DESCRIPTOR = "unsafe_upsert_descriptor";
+// Recorded when a descriptor is deleted by crdb_internal.unsafe_delete_descriptor().
+export const UNSAFE_DELETE_DESCRIPTOR = "unsafe_delete_descriptor";
+// Recorded when a namespace entry is written by
+// crdb_internal.unsafe_upsert_namespace_entry().
+export const UNSAFE_UPSERT_NAMESPACE_ENTRY = "unsafe_upsert_namespace_entry";
+// Recorded when a namespace entry is deleted by
+// crdb_internal.unsafe_delete_namespace_entry().
+export const UNSAFE_DELETE_NAMESPACE_ENTRY = "unsafe_delete_namespace_entry";
+// Recorded when privileges are granted on or revoked from a database.
+export const CHANGE_DATABASE_PRIVILEGE = "change_database_privilege";
+// Recorded when privileges are granted on or revoked from a table.
+export const CHANGE_TABLE_PRIVILEGE = "change_table_privilege";
 
 // Node Event Types
 export const nodeEvents = [NODE_JOIN, NODE_RESTART, NODE_DECOMMISSIONED, NODE_RECOMMISSIONED];
-export const databaseEvents = [CREATE_DATABASE, DROP_DATABASE];
+export const databaseEvents = [
+  CREATE_DATABASE, DROP_DATABASE, ALTER_DATABASE, CREATE_PROCEDURE, DROP_PROCEDURE,
+  CREATE_FUNCTION, CHANGE_DATABASE_PRIVILEGE,
+];
 export const tableEvents = [
   CREATE_TABLE, DROP_TABLE, TRUNCATE_TABLE, ALTER_TABLE, CREATE_INDEX,
-  ALTER_INDEX, DROP_INDEX, CREATE_VIEW, DROP_VIEW, REVERSE_SCHEMA_CHANGE,
-  FINISH_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE_ROLLBACK,
+  ALTER_INDEX, DROP_INDEX, CREATE_VIEW, DROP_VIEW, CREATE_TRIGGER, DROP_TRIGGER,
+  REVERSE_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE, FINISH_SCHEMA_CHANGE_ROLLBACK,
+  CHANGE_TABLE_PRIVILEGE,
 ];
 export const settingsEvents = [SET_CLUSTER_SETTING, SET_ZONE_CONFIG, REMOVE_ZONE_CONFIG];
 export const allEvents = [...nodeEvents, ...databaseEvents, ...tableEvents, ...settingsEvents];
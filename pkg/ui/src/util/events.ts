@@ -32,6 +32,8 @@ export function getEventDescription(e: Event$Properties): string {
     case eventTypes.DROP_DATABASE:
       const tableDropText = getDroppedObjectsText(info);
       return `Database Dropped: User ${info.User} dropped database ${info.DatabaseName}. ${tableDropText}`;
+    case eventTypes.ALTER_DATABASE:
+      return `Database Altered: User ${info.User} executed "${info.Statement}" on database ${info.DatabaseName}`;
     case eventTypes.CREATE_TABLE:
       return `Table Created: User ${info.User} created table ${info.TableName}`;
     case eventTypes.DROP_TABLE:
@@ -56,6 +58,16 @@ export function getEventDescription(e: Event$Properties): string {
       return `Sequence Altered: User ${info.User} altered sequence ${info.SequenceName}`;
     case eventTypes.DROP_SEQUENCE:
       return `Sequence Dropped: User ${info.User} dropped sequence ${info.SequenceName}`;
+    case eventTypes.CREATE_TRIGGER:
+      return `Trigger Created: User ${info.User} created trigger ${info.TriggerName} on table ${info.TableName}`;
+    case eventTypes.DROP_TRIGGER:
+      return `Trigger Dropped: User ${info.User} dropped trigger ${info.TriggerName} on table ${info.TableName}`;
+    case eventTypes.CREATE_PROCEDURE:
+      return `Procedure Created: User ${info.User} created procedure ${info.ProcedureName}`;
+    case eventTypes.DROP_PROCEDURE:
+      return `Procedure Dropped: User ${info.User} dropped procedure ${info.ProcedureName}`;
+    case eventTypes.CREATE_FUNCTION:
+      return `Function Created: User ${info.User} created function ${info.FunctionName}`;
     case eventTypes.REVERSE_SCHEMA_CHANGE:
       return `Schema Change Reversed: Schema change with ID ${info.MutationID} was reversed.`;
     case eventTypes.FINISH_SCHEMA_CHANGE:
@@ -81,6 +93,20 @@ export function getEventDescription(e: Event$Properties): string {
       return `Zone Config Removed: User ${info.User} removed the zone config for ${info.Target}`;
     case eventTypes.CREATE_STATISTICS:
       return `Table statistics refreshed for ${info.TableName}`;
+    case eventTypes.USER_LOGIN_LOCKED:
+      return `User Locked Out: User ${info.User} was locked out until ${info.LockedUntil} after ${info.FailedAttempts} failed login attempts`;
+    case eventTypes.UNSAFE_UPSERT_DESCRIPTOR:
+      return `Descriptor Repaired: User ${info.User} wrote descriptor ${info.ID}${getForcedText(info)}`;
+    case eventTypes.UNSAFE_DELETE_DESCRIPTOR:
+      return `Descriptor Repaired: User ${info.User} deleted descriptor ${info.ID}${getForcedText(info)}`;
+    case eventTypes.UNSAFE_UPSERT_NAMESPACE_ENTRY:
+      return `Namespace Repaired: User ${info.User} made name ${info.Name} under parent ${info.ParentID || 0} resolve to descriptor ${info.ID}${getForcedText(info)}`;
+    case eventTypes.UNSAFE_DELETE_NAMESPACE_ENTRY:
+      return `Namespace Repaired: User ${info.User} deleted name ${info.Name} under parent ${info.ParentID || 0}${getForcedText(info)}`;
+    case eventTypes.CHANGE_DATABASE_PRIVILEGE:
+      return `Privileges Changed: User ${info.User} ${getPrivilegeChangeText(info)} on database ${info.DatabaseName}`;
+    case eventTypes.CHANGE_TABLE_PRIVILEGE:
+      return `Privileges Changed: User ${info.User} ${getPrivilegeChangeText(info)} on table ${info.TableName}`;
     default:
       return `Unknown Event Type: ${e.event_type}, content: ${JSON.stringify(info, null, 2)}`;
   }
@@ -96,11 +122,23 @@ export interface EventInfo {
   MutationID?: string;
   ViewName?: string;
   SequenceName?: string;
+  TriggerName?: string;
+  ProcedureName?: string;
+  FunctionName?: string;
   SettingName?: string;
   Value?: string;
   Target?: string;
   Config?: string;
   Statement?: string;
+  FailedAttempts?: number;
+  LockedUntil?: string;
+  ID?: number;
+  ParentID?: number;
+  Name?: string;
+  Force?: boolean;
+  Grantees?: string[];
+  GrantedPrivileges?: string[];
+  RevokedPrivileges?: string[];
   // The following are three names for the same key (it was renamed twice).
   // All ar included for backwards compatibility.
   DroppedTables?: string[];
@@ -108,6 +146,18 @@ export interface EventInfo {
   DroppedSchemaObjects?: string[];
 }
 
+function getForcedText(eventInfo: EventInfo): string {
+  return eventInfo.Force ? " (forced)" : "";
+}
+
+function getPrivilegeChangeText(eventInfo: EventInfo): string {
+  const grantees = (eventInfo.Grantees || []).join(", ");
+  if (eventInfo.RevokedPrivileges) {
+    return `revoked ${eventInfo.RevokedPrivileges.join(", ")} from ${grantees}`;
+  }
+  return `granted ${(eventInfo.GrantedPrivileges || []).join(", ")} to ${grantees}`;
+}
+
 export function getDroppedObjectsText(eventInfo: EventInfo): string {
   const droppedObjects =
     eventInfo.DroppedSchemaObjects || eventInfo.DroppedTablesAndViews || eventInfo.DroppedTables;
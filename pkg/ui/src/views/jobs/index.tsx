@@ -65,6 +65,7 @@ const typeOptions = [
   { value: JobType.CHANGEFEED.toString(), label: "Changefeed"},
   { value: JobType.CREATE_STATS.toString(), label: "Statistics Creation"},
   { value: JobType.AUTO_CREATE_STATS.toString(), label: "Auto-Statistics Creation"},
+  { value: JobType.CONSTRAINT_CONFORMANCE.toString(), label: "Constraint Conformance"},
 ];
 
 const typeSetting = new LocalSetting<AdminUIState, number>(
@@ -15,6 +15,7 @@
 import classNames from "classnames";
 import { deviation as d3Deviation, mean as d3Mean } from "d3";
 import _ from "lodash";
+import Long from "long";
 import moment from "moment";
 import React from "react";
 import { Helmet } from "react-helmet";
@@ -22,7 +23,12 @@ import { connect } from "react-redux";
 import { RouterState } from "react-router";
 import { createSelector } from "reselect";
 
-import { refreshLiveness, refreshNodes } from "src/redux/apiReducers";
+import {
+  CachedDataReducerState,
+  refreshLiveness,
+  refreshNetworkLatencies,
+  refreshNodes,
+} from "src/redux/apiReducers";
 import {
   LivenessStatus,
   NodesSummary,
@@ -31,6 +37,7 @@ import {
   selectNodeRequestStatus,
 } from "src/redux/nodes";
 import { AdminUIState } from "src/redux/state";
+import { NetworkLatenciesResponseMessage } from "src/util/api";
 import { LongToMoment, NanoToMilli } from "src/util/convert";
 import { FixLong } from "src/util/fixLong";
 import {
@@ -44,8 +51,10 @@ import Loading from "src/views/shared/components/loading";
 interface NetworkOwnProps {
   nodesSummary: NodesSummary;
   nodeSummaryErrors: Error[];
+  networkLatencies: CachedDataReducerState<NetworkLatenciesResponseMessage>;
   refreshNodes: typeof refreshNodes;
   refreshLiveness: typeof refreshLiveness;
+  refreshNetworkLatencies: typeof refreshNetworkLatencies;
 }
 
 interface Identity {
@@ -60,8 +69,24 @@ interface NoConnection {
   to: Identity;
 }
 
+interface AsymmetricLatency {
+  from: number;
+  to: number;
+  forward: number;
+  backward: number;
+  forwardMax: number;
+  backwardMax: number;
+}
+
 type NetworkProps = NetworkOwnProps & RouterState;
 
+// asymmetryRatio and asymmetryMinDiffMs are the thresholds above which the
+// latencies measured in both directions between two nodes are reported as
+// asymmetric. Both must be exceeded, so that the jitter between nodes which
+// are close to each other is not reported.
+const asymmetryRatio = 2;
+const asymmetryMinDiffMs = 10;
+
 // staleTable is a table of all stale nodes.
 function staleTable(staleIdentities: Identity[]) {
   if (_.isEmpty(staleIdentities)) {
@@ -172,6 +197,103 @@ function noConnectionTable(noConnections: NoConnection[]) {
   );
 }
 
+// findAsymmetricLatencies returns the pairs of nodes, among the given ones,
+// whose latency measured by one node is much higher than the one measured by
+// the other, which usually points at a degraded link or an overloaded node.
+function findAsymmetricLatencies(
+  resp: NetworkLatenciesResponseMessage,
+  nodeIDs: number[],
+): AsymmetricLatency[] {
+  if (_.isNil(resp)) {
+    return [];
+  }
+  const included = new Set(nodeIDs);
+  const latencyMs = (nanos: Long | number) => NanoToMilli(FixLong(nanos).toNumber());
+  const byPair: Map<string, { latency: number, max: number }> = new Map();
+  _.forEach(resp.latencies, l => {
+    byPair.set(`${l.node_id}-${l.peer_node_id}`, {
+      latency: latencyMs(l.latency_nanos),
+      max: _.max(_.map(l.history, sample => latencyMs(sample.latency_nanos))) || 0,
+    });
+  });
+  const res: AsymmetricLatency[] = [];
+  _.forEach(resp.latencies, l => {
+    if (l.node_id >= l.peer_node_id || !included.has(l.node_id) || !included.has(l.peer_node_id)) {
+      return;
+    }
+    const forward = byPair.get(`${l.node_id}-${l.peer_node_id}`);
+    const backward = byPair.get(`${l.peer_node_id}-${l.node_id}`);
+    if (_.isNil(backward) || forward.latency <= 0 || backward.latency <= 0) {
+      return;
+    }
+    const lo = Math.min(forward.latency, backward.latency);
+    const hi = Math.max(forward.latency, backward.latency);
+    if (hi / lo < asymmetryRatio || hi - lo < asymmetryMinDiffMs) {
+      return;
+    }
+    res.push({
+      from: l.node_id,
+      to: l.peer_node_id,
+      forward: forward.latency,
+      backward: backward.latency,
+      forwardMax: forward.max,
+      backwardMax: backward.max,
+    });
+  });
+  return _.sortBy(res, a => -Math.abs(a.forward - a.backward));
+}
+
+// asymmetricLatencyTable is a table of the pairs of nodes with asymmetric
+// latencies.
+function asymmetricLatencyTable(asymmetries: AsymmetricLatency[]) {
+  if (_.isEmpty(asymmetries)) {
+    return null;
+  }
+  const ms = (v: number) => `${v.toFixed(2)}ms`;
+
+  return (
+    <div>
+      <h2>Asymmetric Latencies</h2>
+      <table className="failure-table">
+        <tbody>
+          <tr className="failure-table__row failure-table__row--header">
+            <td className="failure-table__cell failure-table__cell--header">
+              Node A
+              </td>
+            <td className="failure-table__cell failure-table__cell--header">
+              Node B
+              </td>
+            <td className="failure-table__cell failure-table__cell--header">
+              Measured by A (max)
+              </td>
+            <td className="failure-table__cell failure-table__cell--header">
+              Measured by B (max)
+              </td>
+          </tr>
+          {
+            _.map(asymmetries, (a) => (
+              <tr className="failure-table__row" key={`${a.from}-${a.to}`}>
+                <td className="failure-table__cell">
+                  n{a.from}
+                </td>
+                <td className="failure-table__cell">
+                  n{a.to}
+                </td>
+                <td className="failure-table__cell">
+                  {ms(a.forward)} ({ms(a.forwardMax)})
+                </td>
+                <td className="failure-table__cell">
+                  {ms(a.backward)} ({ms(a.backwardMax)})
+                </td>
+              </tr>
+            ))
+          }
+        </tbody>
+      </table>
+    </div>
+  );
+}
+
 // createHeaderCell creates and decorates a header cell.
 function createHeaderCell(staleIDs: Set<number>, id: Identity, key: string) {
   const node = `n${id.nodeID.toString()}`;
@@ -200,6 +322,7 @@ class Network extends React.Component<NetworkProps, {}> {
   refresh(props = this.props) {
     props.refreshLiveness();
     props.refreshNodes();
+    props.refreshNetworkLatencies();
   }
 
   componentWillMount() {
@@ -363,6 +486,80 @@ class Network extends React.Component<NetworkProps, {}> {
     ];
   }
 
+  renderClockOffsetTable(
+    staleIDs: Set<number>,
+    nodesSummary: NodesSummary,
+    displayIdentities: Identity[],
+  ) {
+    // getClockOffsetCell creates a cell with the clock offset of node b as
+    // measured by node a.
+    function getClockOffsetCell(nodeIDa: number, nodeIDb: number) {
+      const key = `${nodeIDa}-${nodeIDb}`;
+      if (nodeIDa === nodeIDb) {
+        return <td key={key} className="network-table__cell network-table__cell--self">
+          -
+        </td>;
+      }
+      if (staleIDs.has(nodeIDa) || staleIDs.has(nodeIDb)) {
+        return <td key={key} className="network-table__cell network-table__cell--no-connection">
+          X
+        </td>;
+      }
+      const a = nodesSummary.nodeStatusByID[nodeIDa].activity;
+      if (_.isNil(a) || _.isNil(a[nodeIDb])) {
+        return <td key={key} className="network-table__cell network-table__cell--no-connection">
+          X
+        </td>;
+      }
+      const nano = FixLong(a[nodeIDb].clock_offset);
+      if (nano.eq(0)) {
+        return <td key={key} className="network-table__cell network-table__cell--stddev-even">
+          ?
+        </td>;
+      }
+      const offset = NanoToMilli(nano.toNumber());
+      const title = `n${nodeIDa} -> n${nodeIDb}\n${offset.toString()}ms`;
+      return <td key={key} className="network-table__cell network-table__cell--stddev-even" title={title}>
+        {offset.toFixed(2)}ms
+      </td>;
+    }
+
+    return (
+      <div key="clock-offset-table">
+        <h2>Clock Offsets</h2>
+        <table className="network-table">
+          <tbody>
+            <tr className="network-table__row">
+              <td className="network-table__cell network-table__cell--spacer" />
+              {
+                _.map(displayIdentities, (identity) => createHeaderCell(
+                  staleIDs,
+                  identity,
+                  `0-${identity.nodeID}`,
+                ))
+              }
+            </tr>
+            {
+              _.map(displayIdentities, (identityA) => (
+                <tr key={identityA.nodeID} className="network-table__row">
+                  {
+                    createHeaderCell(staleIDs, identityA, `${identityA.nodeID}-0`)
+                  }
+                  {
+                    _.map(displayIdentities, (identityB) => getClockOffsetCell(
+                      identityA.nodeID,
+                      identityB.nodeID,
+                    ))
+                  }
+                </tr>
+              ))
+            }
+          </tbody>
+        </table>
+      </div>
+    );
+  }
+
   renderContent(nodesSummary: NodesSummary, filters: NodeFilterListProps) {
     if (!contentAvailable(nodesSummary)) {
       return null;
@@ -444,10 +641,14 @@ class Network extends React.Component<NetworkProps, {}> {
     } else if (latencies.length < 1) {
       content = <h2>Cannot show latency chart without two healthy nodes.</h2>;
     } else {
-      content = this.renderLatencyTable(latencies, staleIDs, nodesSummary, displayIdentities);
+      content = _.concat(
+        this.renderLatencyTable(latencies, staleIDs, nodesSummary, displayIdentities),
+        this.renderClockOffsetTable(staleIDs, nodesSummary, displayIdentities),
+      );
     }
     return [
       content,
+      asymmetricLatencyTable(findAsymmetricLatencies(this.props.networkLatencies.data, healthyIDs)),
       staleTable(staleIdentities),
       noConnectionTable(noConnections),
     ];
@@ -488,12 +689,14 @@ function mapStateToProps(state: AdminUIState) {
   return {
     nodesSummary: nodesSummarySelector(state),
     nodeSummaryErrors: nodeSummaryErrors(state),
+    networkLatencies: state.cachedData.networkLatencies,
   };
 }
 
 const actions = {
   refreshNodes,
   refreshLiveness,
+  refreshNetworkLatencies,
 };
 
 export default connect(mapStateToProps, actions)(Network);
@@ -86,6 +86,10 @@ const rangeTableDisplayList: RangeTableRow[] = [
   { variable: "mvccValueBytesCount", display: "MVCC Value Bytes/Count", compareToLeader: true },
   { variable: "mvccIntentBytesCount", display: "MVCC Intent Bytes/Count", compareToLeader: true },
   { variable: "mvccSystemBytesCount", display: "MVCC System Bytes/Count", compareToLeader: true },
+  { variable: "gcBytes", display: "GC Bytes", compareToLeader: true },
+  { variable: "gcScore", display: "GC Score", compareToLeader: false },
+  { variable: "gcLikelyLastRun", display: "GC Likely Last Run", compareToLeader: false },
+  { variable: "gcHint", display: "GC Hint", compareToLeader: true },
   { variable: "rangeMaxBytes", display: "Max Range Size Before Split", compareToLeader: true },
   { variable: "writeLatches", display: "Write Latches Local/Global", compareToLeader: false },
   { variable: "readLatches", display: "Read Latches Local/Global", compareToLeader: false },
@@ -423,6 +427,7 @@ export default class RangeTable extends React.Component<RangeTableProps, {}> {
       const raftLeader = !awaitingGC && FixLong(info.raft_state.lead).eq(localReplica.replica_id);
       const leaseHolder = !awaitingGC && localReplica.replica_id === lease.replica.replica_id;
       const mvcc = info.state.state.stats;
+      const gc = info.state.gc_info;
       const raftState = this.contentRaftState(info.raft_state.state);
       const vote = FixLong(info.raft_state.hard_state.vote);
       let leaseState: RangeTableCellContent;
@@ -494,6 +499,15 @@ export default class RangeTable extends React.Component<RangeTableProps, {}> {
         mvccValueBytesCount: this.contentMVCC(FixLong(mvcc.val_bytes), FixLong(mvcc.val_count)),
         mvccIntentBytesCount: this.contentMVCC(FixLong(mvcc.intent_bytes), FixLong(mvcc.intent_count)),
         mvccSystemBytesCount: this.contentMVCC(FixLong(mvcc.sys_bytes), FixLong(mvcc.sys_count)),
+        gcBytes: this.contentBytes(FixLong(gc.gc_bytes)),
+        gcScore: this.createContent(
+          gc.score.toFixed(4),
+          gc.should_queue ? "range-table__cell--warning" : "",
+        ),
+        gcLikelyLastRun: FixLong(gc.likely_last_gc_nanos).isZero() ? this.createContent("never") :
+          this.contentDuration(FixLong(gc.likely_last_gc_nanos)),
+        gcHint: FixLong(gc.hint.latest_range_delete_timestamp.wall_time).isZero() ? rangeTableEmptyContent :
+          this.contentTimestamp(gc.hint.latest_range_delete_timestamp),
         rangeMaxBytes: this.contentBytes(FixLong(info.state.range_max_bytes)),
         writeLatches: this.contentLatchInfo(
           FixLong(info.latches_local.write_count),
@@ -392,6 +392,10 @@ function makeStats() {
     run_lat: makeStat(),
     overhead_lat: makeStat(),
     service_lat: makeStat(),
+    bytes_read: makeStat(),
+    bytes_written: makeStat(),
+    cpu_time: makeStat(),
+    cpu_time_count: Long.fromNumber(1),
     sensitive_info: makeEmptySensitiveInfo(),
   };
 }
@@ -0,0 +1,224 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cron parses cron expressions and computes the times they match.
+package cron
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Schedule is a parsed cron expression. The expression has five fields:
+//
+//   minute        0-59
+//   hour          0-23
+//   day of month  1-31
+//   month         1-12 or JAN-DEC
+//   day of week   0-7 or SUN-SAT (0 and 7 are Sunday)
+//
+// Each field is *, a value, a range a-b, any of these followed by /<step>,
+// or a comma-separated list of the above. A value followed by a step, as in
+// 5/15, stands for the range from the value to the maximum of the field. As
+// in most cron implementations, when both the day of month and the day of
+// week are restricted, i.e. neither starts with *, a day matches if either
+// matches.
+//
+// The expression can also be one of @yearly (or @annually), @monthly,
+// @weekly, @daily (or @midnight) and @hourly.
+//
+// Schedules are evaluated in UTC.
+type Schedule struct {
+	minute, hour, dom, month, dow bits
+	// domStar and dowStar are set if the day of month, respectively the day
+	// of week, field starts with *.
+	domStar, dowStar bool
+}
+
+// bits is a set of values of a field.
+type bits uint64
+
+func (b bits) has(v int) bool {
+	return b&(1<<uint(v)) != 0
+}
+
+type fieldBounds struct {
+	name     string
+	min, max int
+	// names maps the names of the values, if any, to the values.
+	names map[string]int
+}
+
+var (
+	minuteBounds = fieldBounds{name: "minute", min: 0, max: 59}
+	hourBounds   = fieldBounds{name: "hour", min: 0, max: 23}
+	domBounds    = fieldBounds{name: "day of month", min: 1, max: 31}
+	monthBounds  = fieldBounds{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowBounds = fieldBounds{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var aliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Parse parses a cron expression.
+func Parse(expr string) (*Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "@") {
+		e, ok := aliases[strings.ToLower(expr)]
+		if !ok {
+			return nil, errors.Errorf("unknown cron expression %s", expr)
+		}
+		expr = e
+	}
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, errors.Errorf("cron expression %q has %d fields, expected 5", expr, len(fields))
+	}
+	var s Schedule
+	var err error
+	for i, f := range []struct {
+		bits   *bits
+		bounds fieldBounds
+	}{
+		{&s.minute, minuteBounds},
+		{&s.hour, hourBounds},
+		{&s.dom, domBounds},
+		{&s.month, monthBounds},
+		{&s.dow, dowBounds},
+	} {
+		if *f.bits, err = parseField(fields[i], f.bounds); err != nil {
+			return nil, err
+		}
+	}
+	// Sunday is both 0 and 7.
+	if s.dow.has(7) {
+		s.dow |= 1
+	}
+	s.domStar = strings.HasPrefix(fields[2], "*")
+	s.dowStar = strings.HasPrefix(fields[4], "*")
+	return &s, nil
+}
+
+func parseField(field string, b fieldBounds) (bits, error) {
+	var res bits
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		hasStep := false
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			var err error
+			rng, hasStep = part[:i], true
+			if step, err = strconv.Atoi(part[i+1:]); err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step in %s field %q", b.name, part)
+			}
+		}
+		var lo, hi int
+		var err error
+		switch {
+		case rng == "*":
+			lo, hi = b.min, b.max
+		case strings.IndexByte(rng, '-') >= 0:
+			i := strings.IndexByte(rng, '-')
+			if lo, err = parseValue(rng[:i], b); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(rng[i+1:], b); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, errors.Errorf("invalid range in %s field %q", b.name, part)
+			}
+		default:
+			if lo, err = parseValue(rng, b); err != nil {
+				return 0, err
+			}
+			hi = lo
+			if hasStep {
+				hi = b.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			res |= 1 << uint(v)
+		}
+	}
+	return res, nil
+}
+
+func parseValue(s string, b fieldBounds) (int, error) {
+	if v, ok := b.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.Errorf("invalid %s %q", b.name, s)
+	}
+	if v < b.min || v > b.max {
+		return 0, errors.Errorf("%s %d out of range [%d, %d]", b.name, v, b.min, b.max)
+	}
+	return v, nil
+}
+
+// maxSearchYears bounds the search for the next matching time. Five years are
+// enough to reach the next leap day.
+const maxSearchYears = 5
+
+// Next returns the first time strictly after t which matches the schedule,
+// truncated to the minute. It returns the zero time if the schedule never
+// matches, e.g. for the 30th of February.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(limit) {
+		if !s.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.hour.has(t.Hour()) {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !s.minute.has(t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+func (s *Schedule) matchesDay(t time.Time) bool {
+	dom, dow := s.dom.has(t.Day()), s.dow.has(int(t.Weekday()))
+	if !s.domStar && !s.dowStar {
+		return dom || dow
+	}
+	return dom && dow
+}
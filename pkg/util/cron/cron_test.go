@@ -0,0 +1,122 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestNext(t *testing.T) {
+	// 2019-03-15 is a Friday.
+	from := time.Date(2019, 3, 15, 10, 30, 45, 0, time.UTC)
+	testData := []struct {
+		expr string
+		exp  string
+	}{
+		{`* * * * *`, `2019-03-15T10:31:00Z`},
+		{`30 * * * *`, `2019-03-15T11:30:00Z`},
+		{`*/15 * * * *`, `2019-03-15T10:45:00Z`},
+		{`5/20 * * * *`, `2019-03-15T10:45:00Z`},
+		{`0,10-12 * * * *`, `2019-03-15T11:00:00Z`},
+		{`0 0 * * *`, `2019-03-16T00:00:00Z`},
+		{`0 9 * * *`, `2019-03-16T09:00:00Z`},
+		{`0 9-17/4 * * *`, `2019-03-15T13:00:00Z`},
+		{`0 0 1 * *`, `2019-04-01T00:00:00Z`},
+		{`0 0 31 * *`, `2019-03-31T00:00:00Z`},
+		{`0 0 31 4-6 *`, `2019-05-31T00:00:00Z`},
+		{`0 0 * * 1`, `2019-03-18T00:00:00Z`},
+		{`0 0 * * MON`, `2019-03-18T00:00:00Z`},
+		{`0 0 * * 7`, `2019-03-17T00:00:00Z`},
+		{`0 0 * * sat,sun`, `2019-03-16T00:00:00Z`},
+		{`0 0 1 jan *`, `2020-01-01T00:00:00Z`},
+		{`0 0 29 2 *`, `2020-02-29T00:00:00Z`},
+		// The day of month and the day of week are restricted: either matches.
+		{`0 0 20 * 1`, `2019-03-18T00:00:00Z`},
+		{`0 0 16 * 1`, `2019-03-16T00:00:00Z`},
+		// Only the day of month is restricted.
+		{`0 0 20 * *`, `2019-03-20T00:00:00Z`},
+		// A day of month starting with * is not restricted: both must match.
+		{`0 0 */10 * 1`, `2019-04-01T00:00:00Z`},
+		{`@hourly`, `2019-03-15T11:00:00Z`},
+		{`@daily`, `2019-03-16T00:00:00Z`},
+		{`@weekly`, `2019-03-17T00:00:00Z`},
+		{`@monthly`, `2019-04-01T00:00:00Z`},
+		{`@yearly`, `2020-01-01T00:00:00Z`},
+		{`0 0 30 2 *`, `0001-01-01T00:00:00Z`},
+	}
+	for _, d := range testData {
+		t.Run(d.expr, func(t *testing.T) {
+			s, err := Parse(d.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next := s.Next(from).Format(time.RFC3339); next != d.exp {
+				t.Errorf("expected %s, got %s", d.exp, next)
+			}
+		})
+	}
+
+	// The next time is strictly after the given time.
+	s, err := Parse(`30 10 * * *`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := time.Date(2019, 3, 16, 10, 30, 0, 0, time.UTC)
+	if next := s.Next(time.Date(2019, 3, 15, 10, 30, 0, 0, time.UTC)); !next.Equal(exp) {
+		t.Errorf("expected %s, got %s", exp, next)
+	}
+	// Times are converted to UTC.
+	exp = time.Date(2019, 3, 15, 10, 30, 0, 0, time.UTC)
+	if next := s.Next(from.Add(-time.Hour).In(time.FixedZone("", 3600))); !next.Equal(exp) {
+		t.Errorf("expected %s, got %s", exp, next)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	testData := []struct {
+		expr string
+		err  string
+	}{
+		{``, `has 0 fields, expected 5`},
+		{`* * * *`, `has 4 fields, expected 5`},
+		{`* * * * * *`, `has 6 fields, expected 5`},
+		{`@reboot`, `unknown cron expression @reboot`},
+		{`60 * * * *`, `minute 60 out of range \[0, 59\]`},
+		{`* 24 * * *`, `hour 24 out of range \[0, 23\]`},
+		{`* * 0 * *`, `day of month 0 out of range \[1, 31\]`},
+		{`* * * 13 *`, `month 13 out of range \[1, 12\]`},
+		{`* * * * 8`, `day of week 8 out of range \[0, 7\]`},
+		{`* * * * mon-foo`, `invalid day of week "foo"`},
+		{`* * * jan-sun *`, `invalid month "sun"`},
+		{`* * * * sat-sun`, `invalid range in day of week field "sat-sun"`},
+		{`a * * * *`, `invalid minute "a"`},
+		{`5- * * * *`, `invalid minute ""`},
+		{`,5 * * * *`, `invalid minute ""`},
+		{`10-5 * * * *`, `invalid range in minute field "10-5"`},
+		{`*/0 * * * *`, `invalid step in minute field "\*/0"`},
+		{`*/-1 * * * *`, `invalid step in minute field "\*/-1"`},
+		{`*/ * * * *`, `invalid step in minute field "\*/"`},
+	}
+	for _, d := range testData {
+		t.Run(d.expr, func(t *testing.T) {
+			if _, err := Parse(d.expr); !testutils.IsError(err, d.err) {
+				t.Errorf("expected %q, got %v", d.err, err)
+			}
+		})
+	}
+}
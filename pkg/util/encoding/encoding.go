@@ -762,6 +762,13 @@ func EncodeStringDescending(b []byte, s string) []byte {
 // returned string will share the underlying memory with the []byte which thus
 // allows the string to be mutable through the []byte. We're careful to use
 // this method only in situations in which the []byte will not be modified.
+// UnsafeConvertBytesToString performs an unsafe conversion from a []byte to a
+// string. The returned string shares storage with the input slice, so the
+// slice must not be modified for as long as the string is referenced.
+func UnsafeConvertBytesToString(b []byte) string {
+	return unsafeString(b)
+}
+
 func unsafeString(b []byte) string {
 	return *(*string)(unsafe.Pointer(&b))
 }
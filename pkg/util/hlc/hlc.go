@@ -75,6 +75,10 @@ type Clock struct {
 		// clock jumps
 		forwardClockJumpCheckEnabled bool
 
+		// backwardClockJumpPolicy specifies how to react to backward clock
+		// jumps greater than max_offset/10.
+		backwardClockJumpPolicy BackwardClockJumpPolicy
+
 		// isMonitoringForwardClockJumps is a flag to ensure that only one jump monitoring
 		// goroutine is running per clock
 		isMonitoringForwardClockJumps bool
@@ -88,6 +92,21 @@ type Clock struct {
 	}
 }
 
+// BackwardClockJumpPolicy determines how a Clock reacts when its physical
+// clock is observed to jump backwards by more than max_offset/10.
+type BackwardClockJumpPolicy int
+
+const (
+	// BackwardClockJumpWarn logs a warning. The HLC stays monotonic since its
+	// logical component ticks until the physical clock catches up.
+	BackwardClockJumpWarn BackwardClockJumpPolicy = iota
+	// BackwardClockJumpPause blocks the clock until the physical clock has
+	// caught up with the last physical time it reported.
+	BackwardClockJumpPause
+	// BackwardClockJumpFatal terminates the process.
+	BackwardClockJumpFatal
+)
+
 // ManualClock is a convenience type to facilitate
 // creating a hybrid logical clock whose physical clock
 // is manually controlled. ManualClock is thread safe.
@@ -236,7 +255,24 @@ func (c *Clock) getPhysicalClockAndCheckLocked() int64 {
 		interval := c.mu.lastPhysicalTime - newTime
 		if interval > int64(c.maxOffset/10) {
 			c.mu.monotonicityErrorsCount++
-			log.Warningf(context.TODO(), "backward time jump detected (%f seconds)", float64(-interval)/1e9)
+			switch c.mu.backwardClockJumpPolicy {
+			case BackwardClockJumpFatal:
+				log.Fatalf(
+					context.TODO(),
+					"detected backward time jump of %f seconds is not allowed with tolerance of %f seconds",
+					float64(-interval)/1e9,
+					float64(c.maxOffset/10)/1e9,
+				)
+			case BackwardClockJumpPause:
+				log.Warningf(context.TODO(),
+					"backward time jump detected (%f seconds), pausing until the physical clock catches up",
+					float64(-interval)/1e9)
+				timeutil.SleepUntil(c.mu.lastPhysicalTime, c.physicalClock)
+				newTime = c.physicalClock()
+				interval = c.mu.lastPhysicalTime - newTime
+			default:
+				log.Warningf(context.TODO(), "backward time jump detected (%f seconds)", float64(-interval)/1e9)
+			}
 		}
 
 		if c.mu.forwardClockJumpCheckEnabled {
@@ -388,6 +424,14 @@ func (c *Clock) setForwardJumpCheckEnabled(forwardJumpCheckEnabled bool) {
 	c.mu.forwardClockJumpCheckEnabled = forwardJumpCheckEnabled
 }
 
+// SetBackwardClockJumpPolicy sets how the clock reacts to backward jumps of
+// its physical clock. See BackwardClockJumpPolicy.
+func (c *Clock) SetBackwardClockJumpPolicy(policy BackwardClockJumpPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.mu.backwardClockJumpPolicy = policy
+}
+
 // setMonitoringClockJump atomically sets isMonitoringForwardClockJumps to true and
 // returns the old value. This is used to ensure that only one monitoring
 // goroutine is launched
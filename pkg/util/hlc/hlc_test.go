@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -369,6 +370,63 @@ func TestHLCMonotonicityCheck(t *testing.T) {
 	}
 }
 
+func TestHLCBackwardClockJumpPolicy(t *testing.T) {
+	var fatal bool
+	defer log.ResetExitFunc()
+	log.SetExitFunc(true /* hideStack */, func(r int) {
+		defer log.Flush()
+		if r != 0 {
+			fatal = true
+		}
+	})
+
+	t.Run("fatal", func(t *testing.T) {
+		m := NewManualClock(100000)
+		c := NewClock(m.UnixNano, 100*time.Nanosecond)
+		c.SetBackwardClockJumpPolicy(BackwardClockJumpFatal)
+
+		fatal = false
+		c.Now()
+		m.Increment(-5)
+		c.Now()
+		if fatal {
+			t.Fatal("backward clock jump below threshold caused a fatal error")
+		}
+		m.Increment(-110)
+		c.Now()
+		if !fatal {
+			t.Fatal("backward clock jump did not cause a fatal error")
+		}
+	})
+
+	t.Run("pause", func(t *testing.T) {
+		m := NewManualClock(100000)
+		c := NewClock(m.UnixNano, 100*time.Nanosecond)
+		c.SetBackwardClockJumpPolicy(BackwardClockJumpPause)
+
+		c.Now()
+		m.Increment(-110)
+
+		var caughtUp int32
+		go func() {
+			time.Sleep(10 * time.Millisecond)
+			atomic.StoreInt32(&caughtUp, 1)
+			m.Set(100010)
+		}()
+
+		ts := c.Now()
+		if atomic.LoadInt32(&caughtUp) == 0 {
+			t.Fatal("clock did not pause until the physical clock caught up")
+		}
+		if ts.WallTime != 100010 || ts.Logical != 0 {
+			t.Fatalf("unexpected timestamp %s", ts)
+		}
+		if c.lastPhysicalTime() != 100010 {
+			t.Fatalf("unexpected last physical time %d", c.lastPhysicalTime())
+		}
+	})
+}
+
 func TestHLCEnforceWallTimeWithinBoundsInNow(t *testing.T) {
 	var fatal bool
 	defer log.ResetExitFunc()
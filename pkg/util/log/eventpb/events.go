@@ -0,0 +1,176 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package eventpb defines the payloads of the structured events recorded in
+// system.eventlog.
+package eventpb
+
+import "github.com/cockroachdb/cockroach/pkg/util/protoutil"
+
+// Channel is the logging channel an event is exported to, in addition to
+// system.eventlog and the main log.
+type Channel int
+
+const (
+	// ChannelOps is used for the events relating to the operation of the
+	// cluster. These events are only written to the main log.
+	ChannelOps Channel = iota
+	// ChannelSQLSchema is used for the changes to the SQL schema.
+	ChannelSQLSchema
+	// ChannelPrivileges is used for the changes to privileges.
+	ChannelPrivileges
+	// ChannelSessions is used for the events relating to client sessions.
+	ChannelSessions
+)
+
+var channelNames = [...]string{
+	ChannelOps:        "OPS",
+	ChannelSQLSchema:  "SQL_SCHEMA",
+	ChannelPrivileges: "PRIVILEGES",
+	ChannelSessions:   "SESSIONS",
+}
+
+func (c Channel) String() string {
+	return channelNames[c]
+}
+
+// Channels lists all the logging channels.
+var Channels = []Channel{ChannelOps, ChannelSQLSchema, ChannelPrivileges, ChannelSessions}
+
+// EventPayload is implemented by the payloads of all the events.
+type EventPayload interface {
+	protoutil.Message
+	// CommonDetails gives access to the fields common to all the events.
+	CommonDetails() *CommonEventDetails
+	// LoggingChannel returns the channel the event is exported to.
+	LoggingChannel() Channel
+}
+
+// CommonDetails implements the EventPayload interface. The method is
+// promoted to all the events, which embed CommonEventDetails.
+func (m *CommonEventDetails) CommonDetails() *CommonEventDetails { return m }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateDatabase) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *DropDatabase) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *AlterDatabase) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CommentOnDatabase) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateTable) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *DropTable) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *TruncateTable) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *AlterTable) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CommentOnTable) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CommentOnColumn) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateStatistics) LoggingChannel() Channel { return ChannelOps }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateIndex) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *DropIndex) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *AlterIndex) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateView) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *DropView) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateSequence) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *DropSequence) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *AlterSequence) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateTrigger) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *DropTrigger) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateProcedure) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *DropProcedure) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *CreateFunction) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *ReverseSchemaChange) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *FinishSchemaChange) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *FinishSchemaChangeRollback) LoggingChannel() Channel { return ChannelSQLSchema }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *ChangeDatabasePrivilege) LoggingChannel() Channel { return ChannelPrivileges }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *ChangeTablePrivilege) LoggingChannel() Channel { return ChannelPrivileges }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *UserLoginLocked) LoggingChannel() Channel { return ChannelSessions }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *SetClusterSetting) LoggingChannel() Channel { return ChannelOps }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *SetZoneConfig) LoggingChannel() Channel { return ChannelOps }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *RemoveZoneConfig) LoggingChannel() Channel { return ChannelOps }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *NodeJoin) LoggingChannel() Channel { return ChannelOps }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *NodeRestart) LoggingChannel() Channel { return ChannelOps }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *NodeDecommissioned) LoggingChannel() Channel { return ChannelOps }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *NodeRecommissioned) LoggingChannel() Channel { return ChannelOps }
+
+// LoggingChannel implements the EventPayload interface.
+func (m *UnsafeRepair) LoggingChannel() Channel { return ChannelOps }
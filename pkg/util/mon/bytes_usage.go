@@ -185,6 +185,16 @@ type BytesMonitor struct {
 		// curBudget represents the budget allocated at the pool on behalf of
 		// this monitor.
 		curBudget BoundAccount
+
+		// head is the first of the started monitors which use this monitor as
+		// their pool. The others are linked through their parentMu.
+		head *BytesMonitor
+	}
+
+	// parentMu links this monitor to the other started monitors which use the
+	// same pool, for TraverseTree. It is protected by the mutex of the pool.
+	parentMu struct {
+		prevSibling, nextSibling *BytesMonitor
 	}
 
 	// name identifies this monitor in logging messages.
@@ -332,6 +342,15 @@ func (mm *BytesMonitor) Start(ctx context.Context, pool *BytesMonitor, reserved
 	mm.mu.maxAllocated = 0
 	mm.mu.curBudget = pool.MakeBoundAccount()
 	mm.reserved = reserved
+	if pool != nil {
+		pool.mu.Lock()
+		if next := pool.mu.head; next != nil {
+			next.parentMu.prevSibling = mm
+			mm.parentMu.nextSibling = next
+		}
+		pool.mu.head = mm
+		pool.mu.Unlock()
+	}
 	if log.V(2) {
 		poolname := "(none)"
 		if pool != nil {
@@ -401,6 +420,22 @@ func (mm *BytesMonitor) doStop(ctx context.Context, check bool) {
 
 	mm.releaseBudget(ctx)
 
+	if pool := mm.mu.curBudget.mon; pool != nil {
+		pool.mu.Lock()
+		prev, next := mm.parentMu.prevSibling, mm.parentMu.nextSibling
+		if pool.mu.head == mm {
+			pool.mu.head = next
+		}
+		if prev != nil {
+			prev.parentMu.nextSibling = next
+		}
+		if next != nil {
+			next.parentMu.prevSibling = prev
+		}
+		mm.parentMu.prevSibling, mm.parentMu.nextSibling = nil, nil
+		pool.mu.Unlock()
+	}
+
 	if mm.maxBytesHist != nil && mm.mu.maxAllocated > 0 {
 		// TODO(knz): We record the logarithm because the UI doesn't know
 		// how to do logarithmic y-axes yet. See the explanatory comments
@@ -432,6 +467,74 @@ func (mm *BytesMonitor) AllocBytes() int64 {
 	return mm.mu.curAllocated
 }
 
+// MonitorState describes a monitor, as reported by TraverseTree.
+type MonitorState struct {
+	// Level is the depth of the monitor in the traversed tree; the monitor on
+	// which TraverseTree is called is at level 0.
+	Level int
+	// ID identifies the monitor during the traversal: the monitors are
+	// numbered from 1 in the order in which they are reported. ParentID is the
+	// ID of the pool of the monitor, or 0 for the root of the traversal.
+	ID, ParentID int
+	// Name is the name of the monitor.
+	Name string
+	// Used is the number of bytes currently allocated by the clients of the
+	// monitor, and MaxUsed the high water mark of the allocations since the
+	// monitor was started.
+	Used, MaxUsed int64
+	// Reserved is the number of bytes that were reserved for the monitor
+	// before it was started, and Budget the number of bytes it has currently
+	// reserved from its pool to serve the allocations beyond that.
+	Reserved, Budget int64
+}
+
+// TraverseTree reports the state of this monitor, then recursively of the
+// started monitors which use it as their pool, depth first. The traversal
+// stops at the first error returned by fn.
+//
+// The monitors are not locked during the whole traversal, so the reported
+// states are not a consistent snapshot; a monitor started or stopped during
+// the traversal may or may not be reported.
+func (mm *BytesMonitor) TraverseTree(fn func(MonitorState) error) error {
+	nextID := 1
+	return mm.traverseTree(0 /* level */, 0 /* parentID */, &nextID, fn)
+}
+
+func (mm *BytesMonitor) traverseTree(
+	level, parentID int, nextID *int, fn func(MonitorState) error,
+) error {
+	state := MonitorState{
+		Level:    level,
+		ID:       *nextID,
+		ParentID: parentID,
+		Name:     mm.name,
+	}
+	*nextID++
+	// The children are collected before they are visited, since the lock of a
+	// monitor cannot be held while the lock of a child is acquired: a child
+	// acquires the lock of its pool while holding its own to grow its budget.
+	var children []*BytesMonitor
+	mm.mu.Lock()
+	state.Used = mm.mu.curAllocated
+	state.MaxUsed = mm.mu.maxAllocated
+	state.Reserved = mm.reserved.used
+	state.Budget = mm.mu.curBudget.used
+	for c := mm.mu.head; c != nil; c = c.parentMu.nextSibling {
+		children = append(children, c)
+	}
+	mm.mu.Unlock()
+
+	if err := fn(state); err != nil {
+		return err
+	}
+	for _, c := range children {
+		if err := c.traverseTree(level+1, state.ID, nextID, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // BoundAccount tracks the cumulated allocations for one client of a pool or
 // monitor. BytesMonitor has an account to its pool; BytesMonitor clients have
 // an account to the monitor. This allows each client to release all the bytes
@@ -19,12 +19,14 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"reflect"
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/pkg/errors"
 )
 
 // randomSize generates a size greater or equal to zero, with a random
@@ -331,6 +333,81 @@ func TestBytesMonitor(t *testing.T) {
 	m.Stop(ctx)
 }
 
+func TestBytesMonitorTraverseTree(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	st := cluster.MakeTestingClusterSettings()
+	newMonitor := func(name string, pool *BytesMonitor, reserved BoundAccount) *BytesMonitor {
+		m := MakeMonitor(name, MemoryResource, nil, nil, 1, math.MaxInt64, st)
+		m.Start(ctx, pool, reserved)
+		return &m
+	}
+	root := newMonitor("root", nil, MakeStandaloneBudget(100))
+	a := newMonitor("a", root, BoundAccount{})
+	b := newMonitor("b", root, BoundAccount{})
+	c := newMonitor("c", a, BoundAccount{})
+	if err := c.reserveBytes(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.reserveBytes(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	traverse := func() []MonitorState {
+		var res []MonitorState
+		if err := root.TraverseTree(func(s MonitorState) error {
+			res = append(res, s)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		return res
+	}
+
+	// The children of a monitor are reported in the reverse order of their
+	// start.
+	expected := []MonitorState{
+		{Level: 0, ID: 1, ParentID: 0, Name: "root", Used: 8, MaxUsed: 8, Reserved: 100},
+		{Level: 1, ID: 2, ParentID: 1, Name: "b", Used: 3, MaxUsed: 3, Budget: 3},
+		{Level: 1, ID: 3, ParentID: 1, Name: "a", Used: 5, MaxUsed: 5, Budget: 5},
+		{Level: 2, ID: 4, ParentID: 3, Name: "c", Used: 5, MaxUsed: 5, Budget: 5},
+	}
+	if res := traverse(); !reflect.DeepEqual(res, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, res)
+	}
+
+	// The traversal stops at the first error.
+	expectedErr := errors.New("stop")
+	var names []string
+	err := root.TraverseTree(func(s MonitorState) error {
+		names = append(names, s.Name)
+		if s.Name == "b" {
+			return expectedErr
+		}
+		return nil
+	})
+	if err != expectedErr || !reflect.DeepEqual(names, []string{"root", "b"}) {
+		t.Fatalf("unexpected traversal %v: %v", names, err)
+	}
+
+	// The stopped monitors are not reported anymore.
+	c.releaseBytes(ctx, 5)
+	c.Stop(ctx)
+	b.releaseBytes(ctx, 3)
+	b.Stop(ctx)
+	names = nil
+	for _, s := range traverse() {
+		names = append(names, s.Name)
+	}
+	if expected := []string{"root", "a"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected %v, got %v", expected, names)
+	}
+
+	a.Stop(ctx)
+	root.Stop(ctx)
+}
+
 func TestMemoryAllocationEdgeCases(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
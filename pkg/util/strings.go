@@ -34,3 +34,107 @@ func GetSingleRune(s string) (rune, error) {
 	}
 	return r, nil
 }
+
+// EditDistance returns the optimal string alignment distance between a and
+// b, that is the number of single-byte insertions, deletions, substitutions
+// and transpositions of two adjacent bytes needed to turn a into b.
+func EditDistance(a, b string) int {
+	// d[i][j] is the distance between a[:i] and b[:j]. We only need to keep
+	// the last three rows around.
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d := prev[j] + 1
+			if ins := cur[j-1] + 1; ins < d {
+				d = ins
+			}
+			if sub := prev[j-1] + cost; sub < d {
+				d = sub
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				if tr := prev2[j-2] + 1; tr < d {
+					d = tr
+				}
+			}
+			cur[j] = d
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
+
+// LikelyTypo returns the edit distance between word and candidate, and
+// whether that distance is small enough for word to be a plausible
+// misspelling of candidate. Words shorter than four bytes are never
+// considered misspellings, as almost any short word is close to some other.
+func LikelyTypo(word, candidate string) (distance int, ok bool) {
+	if len(word) < 4 {
+		return 0, false
+	}
+	maxDist := 1
+	if len(word) > 5 {
+		maxDist = 2
+	}
+	if diff := len(word) - len(candidate); diff > maxDist || -diff > maxDist {
+		return 0, false
+	}
+	distance = EditDistance(word, candidate)
+	return distance, distance > 0 && distance <= maxDist
+}
+
+// SpellingSuggester retains, among a set of candidates, the one that a word
+// is most likely a misspelling of. Candidates at a smaller edit distance are
+// preferred; at equal distance, candidates of a length closer to that of the
+// word are preferred (i.e. "form" suggests "from" rather than "for"), and
+// remaining ties are broken alphabetically.
+type SpellingSuggester struct {
+	word     string
+	best     string
+	bestDist int
+}
+
+// MakeSpellingSuggester creates a SpellingSuggester for the given word.
+func MakeSpellingSuggester(word string) SpellingSuggester {
+	return SpellingSuggester{word: word}
+}
+
+// Add considers the given candidate.
+func (s *SpellingSuggester) Add(candidate string) {
+	d, ok := LikelyTypo(s.word, candidate)
+	if !ok {
+		return
+	}
+	if s.best != "" {
+		lenDiff := func(c string) int {
+			if diff := len(s.word) - len(c); diff >= 0 {
+				return diff
+			}
+			return len(c) - len(s.word)
+		}
+		if d > s.bestDist {
+			return
+		}
+		if d == s.bestDist {
+			if ld, bld := lenDiff(candidate), lenDiff(s.best); ld > bld || (ld == bld && candidate > s.best) {
+				return
+			}
+		}
+	}
+	s.best, s.bestDist = candidate, d
+}
+
+// Best returns the best candidate seen so far and its edit distance to the
+// word, or "" if no candidate was a plausible correction.
+func (s *SpellingSuggester) Best() (suggestion string, distance int) {
+	return s.best, s.bestDist
+}
@@ -39,3 +39,73 @@ func TestGetSingleRune(t *testing.T) {
 		})
 	}
 }
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"select", "select", 0},
+		{"selct", "select", 1},
+		{"form", "from", 1},
+		{"kitten", "sitting", 3},
+		{"ca", "abc", 3},
+	}
+	for _, tc := range tests {
+		if d := EditDistance(tc.a, tc.b); d != tc.expected {
+			t.Errorf("%q, %q: expected %d, got %d", tc.a, tc.b, tc.expected, d)
+		}
+		if d := EditDistance(tc.b, tc.a); d != tc.expected {
+			t.Errorf("%q, %q: expected %d, got %d", tc.b, tc.a, tc.expected, d)
+		}
+	}
+}
+
+func TestLikelyTypo(t *testing.T) {
+	tests := []struct {
+		word, candidate string
+		expected        bool
+	}{
+		{"selct", "select", true},
+		{"form", "from", true},
+		{"select", "select", false},
+		{"ab", "as", false},
+		{"tabel", "table", true},
+		{"tble", "table", true},
+		{"foo", "for", false},
+		{"foo", "bar", false},
+		{"craete", "create", true},
+		{"upsrt", "insert", false},
+	}
+	for _, tc := range tests {
+		if _, ok := LikelyTypo(tc.word, tc.candidate); ok != tc.expected {
+			t.Errorf("%q, %q: expected %t, got %t", tc.word, tc.candidate, tc.expected, ok)
+		}
+	}
+}
+
+func TestSpellingSuggester(t *testing.T) {
+	candidates := []string{"for", "format", "from", "select", "selects", "table"}
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{"form", "from"},
+		{"selct", "select"},
+		{"tabel", "table"},
+		{"select", "selects"},
+		{"xyzzy", ""},
+	}
+	for _, tc := range tests {
+		s := MakeSpellingSuggester(tc.word)
+		for _, c := range candidates {
+			s.Add(c)
+		}
+		if best, _ := s.Best(); best != tc.expected {
+			t.Errorf("%q: expected %q, got %q", tc.word, tc.expected, best)
+		}
+	}
+}
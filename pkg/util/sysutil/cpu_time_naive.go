@@ -0,0 +1,29 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// +build !linux
+
+package sysutil
+
+import "time"
+
+// ThreadCPUTimeSupported is set if ThreadCPUTime measures the CPU time of
+// the thread on this platform.
+const ThreadCPUTimeSupported = false
+
+// ThreadCPUTime returns the CPU time consumed so far by the calling OS thread.
+// On this platform, it always returns zero.
+func ThreadCPUTime() time.Duration {
+	return 0
+}
@@ -16,7 +16,9 @@ package sysutil
 
 import (
 	"os/exec"
+	"runtime"
 	"testing"
+	"time"
 )
 
 func TestExitStatus(t *testing.T) {
@@ -33,3 +35,28 @@ func TestExitStatus(t *testing.T) {
 		t.Fatalf("expected exit status 42, but got %d", status)
 	}
 }
+
+func TestThreadCPUTime(t *testing.T) {
+	if !ThreadCPUTimeSupported {
+		t.Skip("thread CPU time is not supported on this platform")
+	}
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	// Spin until the thread has consumed some CPU time. The measurement must
+	// not go backwards in the meantime.
+	const minCPUTime = 10 * time.Millisecond
+	start := ThreadCPUTime()
+	prev := start
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); {
+		cur := ThreadCPUTime()
+		if cur < prev {
+			t.Fatalf("thread CPU time went backwards: %s -> %s", prev, cur)
+		}
+		if cur-start >= minCPUTime {
+			return
+		}
+		prev = cur
+	}
+	t.Fatalf("expected the thread to consume %s of CPU time, got %s", minCPUTime, prev-start)
+}
@@ -0,0 +1,102 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+// maxActiveSpans bounds the number of spans tracked by a Tracer until they
+// finish, so that spans which are never finished cannot grow the registry
+// indefinitely. The spans started beyond that are not tracked.
+const maxActiveSpans = 10000
+
+// activeSpans is the registry of the spans of a Tracer which have not
+// finished yet. Only real spans are tracked: noop spans do not do anything.
+type activeSpans struct {
+	syncutil.Mutex
+	m map[*span]struct{}
+}
+
+// register adds a span to the registry.
+func (a *activeSpans) register(s *span) {
+	a.Lock()
+	defer a.Unlock()
+	if a.m == nil {
+		a.m = make(map[*span]struct{})
+	}
+	if len(a.m) < maxActiveSpans {
+		a.m[s] = struct{}{}
+	}
+}
+
+// unregister removes a span from the registry, if it is present.
+func (a *activeSpans) unregister(s *span) {
+	a.Lock()
+	defer a.Unlock()
+	delete(a.m, s)
+}
+
+// ActiveSpan describes a span which has not finished yet.
+type ActiveSpan struct {
+	TraceID      uint64
+	SpanID       uint64
+	ParentSpanID uint64
+	Operation    string
+	StartTime    time.Time
+	// Tags are the tags of the span, formatted as strings.
+	Tags map[string]string
+}
+
+// ActiveSpans returns the spans of the tracer which have not finished yet,
+// ordered by start time. Noop spans are not reported, hence when tracing is
+// not enabled, the only spans are those which are recorded, e.g. because of
+// SET tracing or of a snowball trace, and those of operations which always
+// start a real span.
+func (t *Tracer) ActiveSpans() []ActiveSpan {
+	t.activeSpans.Lock()
+	spans := make([]*span, 0, len(t.activeSpans.m))
+	for s := range t.activeSpans.m {
+		spans = append(spans, s)
+	}
+	t.activeSpans.Unlock()
+
+	res := make([]ActiveSpan, len(spans))
+	for i, s := range spans {
+		res[i] = ActiveSpan{
+			TraceID:      s.TraceID,
+			SpanID:       s.SpanID,
+			ParentSpanID: s.parentSpanID,
+			Operation:    s.operation,
+			StartTime:    s.startTime,
+		}
+		s.mu.Lock()
+		if len(s.mu.tags) > 0 {
+			res[i].Tags = make(map[string]string, len(s.mu.tags))
+			for k, v := range s.mu.tags {
+				res[i].Tags[k] = fmt.Sprint(v)
+			}
+		}
+		s.mu.Unlock()
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].StartTime.Before(res[j].StartTime)
+	})
+	return res
+}
@@ -0,0 +1,660 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+//
+// This file implements a minimal opentracing.Tracer that exports its spans to
+// an OpenTelemetry collector, using the JSON encoding of the OTLP/HTTP
+// protocol. It is used as a shadow tracer (see shadow.go). Span contexts are
+// propagated using the W3C Trace Context format, which lets the spans of a
+// trace started by a client application be linked to ours.
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	opentracing "github.com/opentracing/opentracing-go"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+)
+
+// fieldNameTraceParent is the key of the W3C Trace Context in carriers.
+const fieldNameTraceParent = "traceparent"
+
+// TraceParent is a W3C Trace Context, as found in the traceparent header.
+// See https://www.w3.org/TR/trace-context/#traceparent-header.
+type TraceParent struct {
+	TraceID  [16]byte
+	ParentID [8]byte
+	Flags    byte
+}
+
+// ParseTraceParent parses a traceparent header, e.g.
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func ParseTraceParent(s string) (TraceParent, error) {
+	var tp TraceParent
+	// Headers of later versions can have additional fields, which we ignore.
+	if len(s) < 55 || (len(s) > 55 && s[55] != '-') {
+		return tp, errors.Errorf("invalid traceparent %q", s)
+	}
+	if s[2] != '-' || s[35] != '-' || s[52] != '-' || strings.ToLower(s) != s {
+		return tp, errors.Errorf("invalid traceparent %q", s)
+	}
+	var version [1]byte
+	if _, err := hex.Decode(version[:], []byte(s[:2])); err != nil {
+		return tp, errors.Wrapf(err, "invalid traceparent version %q", s[:2])
+	}
+	if version[0] == 0xff || (version[0] == 0 && len(s) != 55) {
+		return tp, errors.Errorf("invalid traceparent %q", s)
+	}
+	if _, err := hex.Decode(tp.TraceID[:], []byte(s[3:35])); err != nil {
+		return tp, errors.Wrapf(err, "invalid traceparent trace id %q", s[3:35])
+	}
+	if _, err := hex.Decode(tp.ParentID[:], []byte(s[36:52])); err != nil {
+		return tp, errors.Wrapf(err, "invalid traceparent parent id %q", s[36:52])
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(s[53:55])); err != nil {
+		return tp, errors.Wrapf(err, "invalid traceparent flags %q", s[53:55])
+	}
+	tp.Flags = flags[0]
+	if tp.TraceID == ([16]byte{}) || tp.ParentID == ([8]byte{}) {
+		return tp, errors.Errorf("invalid traceparent %q: ids must not be zero", s)
+	}
+	return tp, nil
+}
+
+// String formats the TraceParent for a version 00 traceparent header.
+func (tp TraceParent) String() string {
+	return fmt.Sprintf("00-%s-%s-%02x",
+		hex.EncodeToString(tp.TraceID[:]), hex.EncodeToString(tp.ParentID[:]), tp.Flags)
+}
+
+// SpanContextFromTraceParent returns the remote span context described by a
+// traceparent header, which can be used as the parent of spans created by t.
+// The trace id of the spans is derived from the trace id of the header; if
+// the shadow tracer exports to OpenTelemetry, the shadow spans are part of the
+// trace of the header itself.
+func (t *Tracer) SpanContextFromTraceParent(traceParent string) (opentracing.SpanContext, error) {
+	tp, err := ParseTraceParent(traceParent)
+	if err != nil {
+		return noopSpanContext{}, err
+	}
+	sc := &spanContext{
+		spanMeta: spanMeta{
+			TraceID: binary.BigEndian.Uint64(tp.TraceID[8:]),
+			SpanID:  binary.BigEndian.Uint64(tp.ParentID[:]),
+		},
+	}
+	if shadowTr := t.getShadowTracer(); shadowTr != nil {
+		sc.shadowTr = shadowTr
+		if _, ok := shadowTr.manager.(*otlpManager); ok {
+			sc.shadowCtx = &otlpSpanContext{TraceParent: tp}
+		}
+	}
+	return sc, nil
+}
+
+type otlpManager struct {
+	exporter *otlpExporter
+}
+
+func (*otlpManager) Name() string {
+	return "opentelemetry"
+}
+
+func (m *otlpManager) Close(tr opentracing.Tracer) {
+	m.exporter.close()
+}
+
+func createOTLPTracer(collectorAddr string) (shadowTracerManager, opentracing.Tracer) {
+	exporter := newOTLPExporter(fmt.Sprintf("http://%s/v1/traces", collectorAddr))
+	return &otlpManager{exporter: exporter}, &otlpTracer{exporter: exporter}
+}
+
+// otlpTracer is an opentracing.Tracer whose finished spans are sent to an
+// otlpExporter.
+type otlpTracer struct {
+	exporter *otlpExporter
+}
+
+var _ opentracing.Tracer = &otlpTracer{}
+
+// StartSpan is part of the opentracing.Tracer interface.
+func (t *otlpTracer) StartSpan(
+	operationName string, opts ...opentracing.StartSpanOption,
+) opentracing.Span {
+	var sso opentracing.StartSpanOptions
+	for _, o := range opts {
+		o.Apply(&sso)
+	}
+	s := &otlpSpan{
+		tracer:    t,
+		startTime: sso.StartTime,
+	}
+	if s.startTime.IsZero() {
+		s.startTime = timeutil.Now()
+	}
+	s.mu.name = operationName
+
+	var parentCtx *otlpSpanContext
+	for _, r := range sso.References {
+		if pc, ok := r.ReferencedContext.(*otlpSpanContext); ok {
+			parentCtx = pc
+			break
+		}
+	}
+	if parentCtx != nil {
+		s.ctx.TraceID = parentCtx.TraceID
+		s.ctx.Flags = parentCtx.Flags
+		s.parentID = parentCtx.ParentID
+		if l := len(parentCtx.baggage); l > 0 {
+			s.ctx.baggage = make(map[string]string, l)
+			for k, v := range parentCtx.baggage {
+				s.ctx.baggage[k] = v
+			}
+		}
+	} else {
+		binary.BigEndian.PutUint64(s.ctx.TraceID[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(s.ctx.TraceID[8:], rand.Uint64())
+		// The spans we start are always exported, so they are sampled.
+		s.ctx.Flags = 1
+	}
+	binary.BigEndian.PutUint64(s.ctx.ParentID[:], rand.Uint64())
+
+	for k, v := range sso.Tags {
+		s.SetTag(k, v)
+	}
+	return s
+}
+
+// Inject is part of the opentracing.Tracer interface.
+func (t *otlpTracer) Inject(
+	osc opentracing.SpanContext, format interface{}, carrier interface{},
+) error {
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return opentracing.ErrUnsupportedFormat
+	}
+	mapWriter, ok := carrier.(opentracing.TextMapWriter)
+	if !ok {
+		return opentracing.ErrInvalidCarrier
+	}
+	sc, ok := osc.(*otlpSpanContext)
+	if !ok {
+		return opentracing.ErrInvalidSpanContext
+	}
+	mapWriter.Set(fieldNameTraceParent, sc.TraceParent.String())
+	return nil
+}
+
+// Extract is part of the opentracing.Tracer interface.
+func (t *otlpTracer) Extract(
+	format interface{}, carrier interface{},
+) (opentracing.SpanContext, error) {
+	if format != opentracing.HTTPHeaders && format != opentracing.TextMap {
+		return nil, opentracing.ErrUnsupportedFormat
+	}
+	mapReader, ok := carrier.(opentracing.TextMapReader)
+	if !ok {
+		return nil, opentracing.ErrInvalidCarrier
+	}
+	var sc *otlpSpanContext
+	err := mapReader.ForeachKey(func(k, v string) error {
+		if strings.ToLower(k) != fieldNameTraceParent {
+			return nil
+		}
+		tp, err := ParseTraceParent(v)
+		if err != nil {
+			return opentracing.ErrSpanContextCorrupted
+		}
+		sc = &otlpSpanContext{TraceParent: tp}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if sc == nil {
+		return nil, opentracing.ErrSpanContextNotFound
+	}
+	return sc, nil
+}
+
+// otlpSpanContext is the span context of an otlpSpan. The ParentID of the
+// TraceParent is the id of the span itself.
+type otlpSpanContext struct {
+	TraceParent
+	baggage map[string]string
+}
+
+var _ opentracing.SpanContext = &otlpSpanContext{}
+
+// ForeachBaggageItem is part of the opentracing.SpanContext interface.
+func (sc *otlpSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range sc.baggage {
+		if !handler(k, v) {
+			break
+		}
+	}
+}
+
+type otlpSpan struct {
+	tracer    *otlpTracer
+	ctx       otlpSpanContext
+	parentID  [8]byte
+	startTime time.Time
+
+	mu struct {
+		syncutil.Mutex
+		name       string
+		attributes []otlpKeyValue
+		events     []otlpEvent
+		finished   bool
+	}
+}
+
+var _ opentracing.Span = &otlpSpan{}
+
+// Finish is part of the opentracing.Span interface.
+func (s *otlpSpan) Finish() {
+	s.FinishWithOptions(opentracing.FinishOptions{})
+}
+
+// FinishWithOptions is part of the opentracing.Span interface.
+func (s *otlpSpan) FinishWithOptions(opts opentracing.FinishOptions) {
+	finishTime := opts.FinishTime
+	if finishTime.IsZero() {
+		finishTime = timeutil.Now()
+	}
+	for _, lr := range opts.LogRecords {
+		s.logFields(lr.Timestamp, lr.Fields...)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.mu.finished {
+		return
+	}
+	s.mu.finished = true
+	js := &otlpJSONSpan{
+		TraceID:           hex.EncodeToString(s.ctx.TraceID[:]),
+		SpanID:            hex.EncodeToString(s.ctx.ParentID[:]),
+		Name:              s.mu.name,
+		Kind:              otlpSpanKindInternal,
+		StartTimeUnixNano: strconv.FormatInt(s.startTime.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(finishTime.UnixNano(), 10),
+		Attributes:        s.mu.attributes,
+		Events:            s.mu.events,
+	}
+	if s.parentID != ([8]byte{}) {
+		js.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	s.tracer.exporter.enqueue(js)
+}
+
+// Context is part of the opentracing.Span interface.
+func (s *otlpSpan) Context() opentracing.SpanContext {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sc := &otlpSpanContext{TraceParent: s.ctx.TraceParent}
+	if l := len(s.ctx.baggage); l > 0 {
+		sc.baggage = make(map[string]string, l)
+		for k, v := range s.ctx.baggage {
+			sc.baggage[k] = v
+		}
+	}
+	return sc
+}
+
+// SetOperationName is part of the opentracing.Span interface.
+func (s *otlpSpan) SetOperationName(operationName string) opentracing.Span {
+	s.mu.Lock()
+	s.mu.name = operationName
+	s.mu.Unlock()
+	return s
+}
+
+// SetTag is part of the opentracing.Span interface.
+func (s *otlpSpan) SetTag(key string, value interface{}) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.mu.attributes {
+		if s.mu.attributes[i].Key == key {
+			s.mu.attributes[i].Value = makeOTLPAnyValue(value)
+			return s
+		}
+	}
+	s.mu.attributes = append(s.mu.attributes, otlpKeyValue{Key: key, Value: makeOTLPAnyValue(value)})
+	return s
+}
+
+// LogFields is part of the opentracing.Span interface.
+func (s *otlpSpan) LogFields(fields ...otlog.Field) {
+	s.logFields(timeutil.Now(), fields...)
+}
+
+func (s *otlpSpan) logFields(timestamp time.Time, fields ...otlog.Field) {
+	if timestamp.IsZero() {
+		timestamp = timeutil.Now()
+	}
+	event := otlpEvent{
+		TimeUnixNano: strconv.FormatInt(timestamp.UnixNano(), 10),
+		Name:         "log",
+		Attributes:   make([]otlpKeyValue, 0, len(fields)),
+	}
+	for _, f := range fields {
+		if f.Key() == "event" {
+			event.Name = fmt.Sprint(f.Value())
+			continue
+		}
+		event.Attributes = append(event.Attributes, otlpKeyValue{
+			Key: f.Key(), Value: makeOTLPAnyValue(f.Value()),
+		})
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.mu.events) < maxLogsPerSpan {
+		s.mu.events = append(s.mu.events, event)
+	}
+}
+
+// LogKV is part of the opentracing.Span interface.
+func (s *otlpSpan) LogKV(alternatingKeyValues ...interface{}) {
+	fields, err := otlog.InterleavedKVToFields(alternatingKeyValues...)
+	if err != nil {
+		s.LogFields(otlog.Error(err), otlog.String("function", "LogKV"))
+		return
+	}
+	s.LogFields(fields...)
+}
+
+// SetBaggageItem is part of the opentracing.Span interface.
+func (s *otlpSpan) SetBaggageItem(restrictedKey, value string) opentracing.Span {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.baggage == nil {
+		s.ctx.baggage = make(map[string]string)
+	}
+	s.ctx.baggage[restrictedKey] = value
+	return s
+}
+
+// BaggageItem is part of the opentracing.Span interface.
+func (s *otlpSpan) BaggageItem(restrictedKey string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx.baggage[restrictedKey]
+}
+
+// Tracer is part of the opentracing.Span interface.
+func (s *otlpSpan) Tracer() opentracing.Tracer {
+	return s.tracer
+}
+
+// LogEvent is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) LogEvent(event string) {
+	s.LogFields(otlog.String("event", event))
+}
+
+// LogEventWithPayload is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) LogEventWithPayload(event string, payload interface{}) {
+	s.LogFields(otlog.String("event", event), otlog.Object("payload", payload))
+}
+
+// Log is part of the opentracing.Span interface. Deprecated.
+func (s *otlpSpan) Log(data opentracing.LogData) {
+	lr := data.ToLogRecord()
+	s.logFields(lr.Timestamp, lr.Fields...)
+}
+
+// The types below mirror the JSON encoding of the OTLP trace protocol. See
+// https://github.com/open-telemetry/opentelemetry-proto.
+
+const otlpSpanKindInternal = 1
+
+type otlpJSONRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScope       `json:"scope"`
+	Spans []*otlpJSONSpan `json:"spans"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpJSONSpan struct {
+	TraceID           string         `json:"traceId"`
+	SpanID            string         `json:"spanId"`
+	ParentSpanID      string         `json:"parentSpanId,omitempty"`
+	Name              string         `json:"name"`
+	Kind              int            `json:"kind"`
+	StartTimeUnixNano string         `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+	Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+	Events            []otlpEvent    `json:"events,omitempty"`
+}
+
+type otlpEvent struct {
+	TimeUnixNano string         `json:"timeUnixNano"`
+	Name         string         `json:"name"`
+	Attributes   []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+func makeOTLPAnyValue(v interface{}) otlpAnyValue {
+	var intVal int64
+	switch t := v.(type) {
+	case string:
+		return otlpAnyValue{StringValue: &t}
+	case bool:
+		return otlpAnyValue{BoolValue: &t}
+	case float64:
+		return otlpAnyValue{DoubleValue: &t}
+	case float32:
+		f := float64(t)
+		return otlpAnyValue{DoubleValue: &f}
+	case int:
+		intVal = int64(t)
+	case int32:
+		intVal = int64(t)
+	case int64:
+		intVal = t
+	case uint32:
+		intVal = int64(t)
+	default:
+		s := fmt.Sprint(v)
+		return otlpAnyValue{StringValue: &s}
+	}
+	s := strconv.FormatInt(intVal, 10)
+	return otlpAnyValue{IntValue: &s}
+}
+
+const (
+	// otlpMaxQueuedSpans is the number of finished spans that can wait to be
+	// exported; spans finished while the queue is full are dropped.
+	otlpMaxQueuedSpans = 10000
+	// otlpMaxBatchSize is the maximum number of spans sent in one request.
+	otlpMaxBatchSize = 512
+	// otlpFlushInterval is the maximum time a span waits to be exported.
+	otlpFlushInterval = time.Second
+)
+
+var otlpLogEveryN = util.Every(5 * time.Second)
+
+// otlpExporter sends batches of spans to an OpenTelemetry collector from a
+// background goroutine.
+type otlpExporter struct {
+	url    string
+	client *http.Client
+
+	spansC   chan *otlpJSONSpan
+	stopC    chan struct{}
+	doneC    chan struct{}
+	stopOnce sync.Once
+
+	mu struct {
+		syncutil.Mutex
+		dropped int
+	}
+}
+
+func newOTLPExporter(url string) *otlpExporter {
+	e := &otlpExporter{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		spansC: make(chan *otlpJSONSpan, otlpMaxQueuedSpans),
+		stopC:  make(chan struct{}),
+		doneC:  make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+// enqueue queues a span to be exported. It never blocks.
+func (e *otlpExporter) enqueue(s *otlpJSONSpan) {
+	select {
+	case <-e.stopC:
+		return
+	default:
+	}
+	select {
+	case e.spansC <- s:
+	default:
+		e.mu.Lock()
+		e.mu.dropped++
+		e.mu.Unlock()
+	}
+}
+
+// close stops the exporter after sending the spans that have been queued.
+func (e *otlpExporter) close() {
+	e.stopOnce.Do(func() { close(e.stopC) })
+	<-e.doneC
+}
+
+func (e *otlpExporter) run() {
+	defer close(e.doneC)
+	ticker := time.NewTicker(otlpFlushInterval)
+	defer ticker.Stop()
+
+	var batch []*otlpJSONSpan
+	flush := func() {
+		if len(batch) > 0 {
+			e.send(batch)
+			batch = nil
+		}
+	}
+	for {
+		select {
+		case s := <-e.spansC:
+			batch = append(batch, s)
+			if len(batch) >= otlpMaxBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stopC:
+			for {
+				select {
+				case s := <-e.spansC:
+					batch = append(batch, s)
+					if len(batch) >= otlpMaxBatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (e *otlpExporter) send(spans []*otlpJSONSpan) {
+	serviceName := "cockroach"
+	req := otlpJSONRequest{
+		ResourceSpans: []otlpResourceSpans{{
+			Resource: otlpResource{
+				Attributes: []otlpKeyValue{
+					{Key: "service.name", Value: otlpAnyValue{StringValue: &serviceName}},
+				},
+			},
+			ScopeSpans: []otlpScopeSpans{{
+				Scope: otlpScope{Name: "github.com/cockroachdb/cockroach/pkg/util/tracing"},
+				Spans: spans,
+			}},
+		}},
+	}
+	body, err := json.Marshal(&req)
+	if err == nil {
+		var resp *http.Response
+		resp, err = e.client.Post(e.url, "application/json", bytes.NewReader(body))
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				err = errors.Errorf("unexpected status %s", resp.Status)
+			}
+		}
+	}
+
+	e.mu.Lock()
+	dropped := e.mu.dropped
+	e.mu.Unlock()
+	if (err != nil || dropped > 0) && otlpLogEveryN.ShouldProcess(timeutil.Now()) {
+		// We can't use `log` from this package so print to stderr.
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "OpenTelemetry exporter: error sending spans:", err)
+		}
+		if dropped > 0 {
+			fmt.Fprintln(os.Stderr, "OpenTelemetry exporter: dropped spans:", dropped)
+			e.mu.Lock()
+			e.mu.dropped -= dropped
+			e.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or
+// implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package tracing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	opentracing "github.com/opentracing/opentracing-go"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	testCases := []struct {
+		in  string
+		err bool
+	}{
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		// Later versions can have additional fields.
+		{in: "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra"},
+		{in: "", err: true},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra", err: true},
+		{in: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", err: true},
+		{in: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", err: true},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e473z-00f067aa0ba902b7-01", err: true},
+		{in: "00_4bf92f3577b34da6a3ce929d0e0e4736_00f067aa0ba902b7_01", err: true},
+		{in: "00-00000000000000000000000000000000-00f067aa0ba902b7-01", err: true},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", err: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.in, func(t *testing.T) {
+			tp, err := ParseTraceParent(tc.in)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got %s", tp)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exp := "00" + tc.in[2:55]; tp.String() != exp {
+				t.Errorf("expected %s, got %s", exp, tp)
+			}
+		})
+	}
+}
+
+// otlpTestCollector is an OpenTelemetry collector that records the spans it
+// receives.
+type otlpTestCollector struct {
+	*httptest.Server
+
+	mu    syncutil.Mutex
+	spans map[string]*otlpJSONSpan
+}
+
+func newOTLPTestCollector(t *testing.T) *otlpTestCollector {
+	c := &otlpTestCollector{spans: make(map[string]*otlpJSONSpan)}
+	c.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req otlpJSONRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for _, rs := range req.ResourceSpans {
+			for _, ss := range rs.ScopeSpans {
+				for _, s := range ss.Spans {
+					c.spans[s.Name] = s
+				}
+			}
+		}
+	}))
+	return c
+}
+
+func (c *otlpTestCollector) addr() string {
+	return strings.TrimPrefix(c.URL, "http://")
+}
+
+func TestOTLPExport(t *testing.T) {
+	c := newOTLPTestCollector(t)
+	defer c.Close()
+
+	tr := NewTracer()
+	tr.setShadowTracer(createOTLPTracer(c.addr()))
+
+	parent := tr.StartSpan("parent")
+	parent.SetTag("x", 1)
+	child := tr.StartSpan("child", opentracing.ChildOf(parent.Context()))
+	child.LogKV("event", "hello", "y", "z")
+	child.Finish()
+
+	// The context of a span can be propagated to other nodes.
+	carrier := make(opentracing.HTTPHeadersCarrier)
+	if err := tr.Inject(parent.Context(), opentracing.HTTPHeaders, carrier); err != nil {
+		t.Fatal(err)
+	}
+	wireContext, err := tr.Extract(opentracing.HTTPHeaders, carrier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := tr.StartSpan("remote", opentracing.ChildOf(wireContext))
+	remote.Finish()
+	parent.Finish()
+
+	// A client can provide the context of its own trace.
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	clientCtx, err := tr.SpanContextFromTraceParent(traceParent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := tr.StartSpan("txn", opentracing.ChildOf(clientCtx))
+	txn.Finish()
+
+	// Closing the tracer flushes the exporter.
+	tr.Close()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, name := range []string{"parent", "child", "remote", "txn"} {
+		if c.spans[name] == nil {
+			t.Fatalf("span %s was not exported; got %v", name, c.spans)
+		}
+	}
+	p := c.spans["parent"]
+	if p.ParentSpanID != "" {
+		t.Errorf("expected no parent, got %s", p.ParentSpanID)
+	}
+	if len(p.Attributes) != 1 || p.Attributes[0].Key != "x" || *p.Attributes[0].Value.IntValue != "1" {
+		t.Errorf("unexpected attributes %+v", p.Attributes)
+	}
+	for _, name := range []string{"child", "remote"} {
+		s := c.spans[name]
+		if s.TraceID != p.TraceID || s.ParentSpanID != p.SpanID {
+			t.Errorf("expected %s to be a child of %+v, got %+v", name, p, s)
+		}
+	}
+	if events := c.spans["child"].Events; len(events) != 1 || events[0].Name != "hello" {
+		t.Errorf("unexpected events %+v", events)
+	}
+	if s := c.spans["txn"]; s.TraceID != traceParent[3:35] || s.ParentSpanID != traceParent[36:52] {
+		t.Errorf("expected txn to be part of the client trace, got %+v", s)
+	}
+}
@@ -78,6 +78,12 @@ var zipkinCollector = settings.RegisterStringSetting(
 	envutil.EnvOrDefaultString("COCKROACH_TEST_ZIPKIN_COLLECTOR", ""),
 )
 
+var otlpCollector = settings.RegisterStringSetting(
+	"trace.opentelemetry.collector",
+	"if set, traces go to the given OpenTelemetry collector using OTLP/HTTP (example: '127.0.0.1:4318'); ignored if trace.lightstep.token or trace.zipkin.collector is set",
+	envutil.EnvOrDefaultString("COCKROACH_TEST_OPENTELEMETRY_COLLECTOR", ""),
+)
+
 // Tracer is our own custom implementation of opentracing.Tracer. It supports:
 //
 //  - forwarding events to x/net/trace instances
@@ -87,7 +93,8 @@ var zipkinCollector = settings.RegisterStringSetting(
 //    events can be retrieved at any time.
 //
 //  - lightstep traces. This is implemented by maintaining a "shadow" lightstep
-//    span inside each of our spans.
+//    span inside each of our spans. Zipkin and OpenTelemetry are supported in
+//    the same way.
 //
 // Even when tracing is disabled, we still use this Tracer (with x/net/trace and
 // lightstep disabled) because of its recording capability (snowball
@@ -112,6 +119,9 @@ type Tracer struct {
 
 	// Pointer to shadowTracer, if using one.
 	shadowTracer unsafe.Pointer
+
+	// activeSpans are the spans which have not finished yet.
+	activeSpans activeSpans
 }
 
 var _ opentracing.Tracer = &Tracer{}
@@ -133,6 +143,8 @@ func (t *Tracer) Configure(sv *settings.Values) {
 			t.setShadowTracer(createLightStepTracer(lsToken))
 		} else if zipkinAddr := zipkinCollector.Get(sv); zipkinAddr != "" {
 			t.setShadowTracer(createZipkinTracer(zipkinAddr))
+		} else if otlpAddr := otlpCollector.Get(sv); otlpAddr != "" {
+			t.setShadowTracer(createOTLPTracer(otlpAddr))
 		} else {
 			t.setShadowTracer(nil, nil)
 		}
@@ -148,6 +160,7 @@ func (t *Tracer) Configure(sv *settings.Values) {
 	enableNetTrace.SetOnChange(sv, reconfigure)
 	lightstepToken.SetOnChange(sv, reconfigure)
 	zipkinCollector.SetOnChange(sv, reconfigure)
+	otlpCollector.SetOnChange(sv, reconfigure)
 }
 
 func (t *Tracer) useNetTrace() bool {
@@ -334,6 +347,7 @@ func (t *Tracer) StartSpan(
 		s.SetTag(k, v)
 	}
 
+	t.activeSpans.register(s)
 	return s
 }
 
@@ -401,6 +415,7 @@ func (t *Tracer) StartRootSpan(
 		}
 	}
 
+	t.activeSpans.register(s)
 	return s
 }
 
@@ -477,6 +492,7 @@ func StartChildSpan(
 	}
 
 	pSpan.mu.Unlock()
+	tr.activeSpans.register(s)
 	return s
 }
 
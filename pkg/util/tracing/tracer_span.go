@@ -304,6 +304,7 @@ func (s *span) FinishWithOptions(opts opentracing.FinishOptions) {
 	s.mu.Lock()
 	s.mu.duration = finishTime.Sub(s.startTime)
 	s.mu.Unlock()
+	s.tracer.activeSpans.unregister(s)
 	if s.shadowTr != nil {
 		s.shadowSpan.Finish()
 	}
@@ -178,6 +178,44 @@ func TestStartChildSpan(t *testing.T) {
 	}
 }
 
+func TestActiveSpans(t *testing.T) {
+	tr := NewTracer()
+	noop := tr.StartSpan("noop")
+	sp1 := tr.StartSpan("parent", Recordable)
+	// Children of spans which are not recording are noop spans when tracing is
+	// not enabled.
+	StartRecording(sp1, SingleNodeRecording)
+	sp2 := StartChildSpan("child", sp1, logtags.SingleTagBuffer("key", "val"), false /*separateRecording*/)
+	spans := tr.ActiveSpans()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 active spans, found %+v", spans)
+	}
+	// The spans may have the same start time.
+	parent, child := spans[0], spans[1]
+	if parent.Operation == "child" {
+		parent, child = child, parent
+	}
+	if parent.Operation != "parent" || child.Operation != "child" {
+		t.Fatalf("unexpected spans %+v", spans)
+	}
+	if child.ParentSpanID != parent.SpanID || child.TraceID != parent.TraceID {
+		t.Fatalf("expected child of %+v, found %+v", parent, child)
+	}
+	if v := child.Tags["key"]; v != "val" {
+		t.Fatalf("expected tag key=val, found %+v", child.Tags)
+	}
+
+	sp2.Finish()
+	if spans := tr.ActiveSpans(); len(spans) != 1 || spans[0].Operation != "parent" {
+		t.Fatalf("expected the parent span only, found %+v", spans)
+	}
+	sp1.Finish()
+	noop.Finish()
+	if spans := tr.ActiveSpans(); len(spans) != 0 {
+		t.Fatalf("expected no active spans, found %+v", spans)
+	}
+}
+
 func TestTracerInjectExtract(t *testing.T) {
 	tr := NewTracer()
 	tr2 := NewTracer()